@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
 	"strings"
 
+	"github.com/rancher/rancher-ai-mcp/internal/config"
 	"github.com/rancher/rancher-ai-mcp/pkg/version"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -10,7 +13,8 @@ import (
 )
 
 var (
-	logLevel string
+	logLevel   string
+	configFile string
 )
 
 var rootCmd = &cobra.Command{
@@ -18,8 +22,12 @@ var rootCmd = &cobra.Command{
 	Short: "Rancher Model Context Protocol (MCP) Server",
 	Long: `The MCP server allows the Rancher AI agent to securely retrieve 
 or update Kubernetes and Rancher resources across local and downstream clusters.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
 		initLogger()
+		return nil
 	},
 	Version: version.GetVersion(),
 }
@@ -31,6 +39,25 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Set the log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "YAML config file with the settings of the flags, e.g. 'read-only: true' - defaults to the "+config.EnvName("config")+" env var")
+}
+
+// loadConfig sets the flags that weren't set in the command line from the config file and the environment variables.
+func loadConfig(cmd *cobra.Command) error {
+	file := configFile
+	if !cmd.Flags().Changed("config") {
+		file = os.Getenv(config.EnvName("config"))
+	}
+	if err := config.Load(cmd.Flags(), file); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(logLevel) {
+	case "", "debug", "info", "warn", "error":
+		return nil
+	default:
+		return fmt.Errorf("invalid log level %q, must be debug, info, warn or error", logLevel)
+	}
 }
 
 func initLogger() {
@@ -40,6 +67,9 @@ func initLogger() {
 		config := zap.NewProductionConfig()
 		// remove the "caller" key from the log output
 		config.EncoderConfig.CallerKey = zapcore.OmitKey
+		if level, err := zapcore.ParseLevel(logLevel); err == nil && logLevel != "" {
+			config.Level = zap.NewAtomicLevelAt(level)
+		}
 		zap.ReplaceGlobals(zap.Must(config.Build()))
 	}
 }
@@ -3,35 +3,112 @@ package cmd
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rancher/dynamiclistener"
 	"github.com/rancher/dynamiclistener/server"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/resources"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+	_ "github.com/rancher/rancher-ai-mcp/pkg/toolsets/builtin"
+	coretools "github.com/rancher/rancher-ai-mcp/pkg/toolsets/core"
 	"github.com/rancher/wrangler/pkg/generated/controllers/core"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 const (
-	tlsName       = "rancher-mcp-server.cattle-ai-agent-system.svc"
-	certNamespace = "cattle-ai-agent-system"
-	certName      = "cattle-mcp-tls"
-	caName        = "cattle-mcp-ca"
+	transportHTTP  = "http"
+	transportStdio = "stdio"
+)
+
+// The sources of the credentials used by the tools.
+const (
+	credentialsFromHeader = "header"
+	credentialsFromEnv    = "env"
+	credentialsFromSecret = "secret"
+	credentialsFromExec   = "exec"
 )
 
 var (
 	port           int
 	insecure       bool
+	tlsName        string
+	certNamespace  string
+	certName       string
+	caName         string
 	authzServerURL string
 	jwksURL        string
 	resourceURL    string
+	resourceDocURL string
+
+	signingMethods                []string
+	jwksRefreshInterval           time.Duration
+	jwksUnknownKIDRefreshInterval time.Duration
+
+	tokenValidationMode       string
+	introspectionURL          string
+	introspectionClientID     string
+	introspectionClientSecret string
+	introspectionCacheTTL     time.Duration
+
+	rancherURL               string
+	impersonationToken       string
+	impersonationTokenFile   string
+	impersonationUserClaim   string
+	impersonationGroupsClaim string
+
+	toolsetNames        []string
+	features            []string
+	execAllowlist       []string
+	debugImage          string
+	debugAllowlist      []string
+	rawGetAllowlist     []string
+	imageAllowlist      []string
+	maxResponseBytes    int
+	responseProfile     string
+	readOnly            bool
+	showSensitiveValues bool
+	sensitiveFields     []string
+	otlpTracesEndpoint  string
+	sessionTTL          time.Duration
+
+	userRateLimit   float64
+	userRateBurst   int
+	globalRateLimit float64
+	globalRateBurst int
+	maxFanOut       int64
+	cacheTTL        time.Duration
+
+	connectionIdleTimeout time.Duration
+	clusterIDCacheTTL     time.Duration
+
+	changeHistoryNamespace string
+	changeHistorySize      int
+
+	confirmTools    []string
+	confirmationTTL time.Duration
+
+	toolTimeout      time.Duration
+	toolTimeoutsList []string
+
+	transport         string
+	credentials       string
+	credentialsSecret string
+	credentialsExec   string
 )
 
 var serveCmd = &cobra.Command{
@@ -44,19 +121,159 @@ var serveCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(serveCmd)
 
+	serveCmd.Flags().StringVar(&transport, "transport", transportHTTP, "MCP transport: http for the Rancher AI agent, or stdio to run as a local process with the credentials from the environment (RANCHER_URL and RANCHER_TOKEN, or a kubeconfig generated by Rancher)")
+	serveCmd.Flags().StringVar(&credentials, "credentials", "", "Source of the Rancher URL and token of the tools: header (R_url and R_token headers or Auth token), env (RANCHER_URL and RANCHER_TOKEN, or a kubeconfig generated by Rancher), secret (--credentials-secret) or exec (--credentials-exec) - defaults to header with the http transport and env with stdio")
+	serveCmd.Flags().StringVar(&credentialsSecret, "credentials-secret", "", "Secret with the url and token keys used by the secret credentials, as <namespace>/<name>, read again every minute")
+	serveCmd.Flags().StringVar(&credentialsExec, "credentials-exec", "", "Command printing an ExecCredential with the token used by the exec credentials for the Rancher URL of --rancher-url, like a kubectl credential plugin")
 	serveCmd.Flags().IntVar(&port, "port", 9092, "Port to listen on")
 	serveCmd.Flags().BoolVar(&insecure, "insecure", false, "Skip TLS verification")
+	serveCmd.Flags().StringVar(&tlsName, "tls-name", "rancher-mcp-server.cattle-ai-agent-system.svc", "DNS name of the TLS certificate, the only CN accepted for client certificates")
+	serveCmd.Flags().StringVar(&certNamespace, "cert-namespace", "cattle-ai-agent-system", "Namespace of the Secrets storing the TLS certificate and its CA")
+	serveCmd.Flags().StringVar(&certName, "cert-name", "cattle-mcp-tls", "Name of the Secret storing the TLS certificate")
+	serveCmd.Flags().StringVar(&caName, "ca-name", "cattle-mcp-ca", "Name of the Secret storing the CA of the TLS certificate")
 
 	serveCmd.Flags().StringVar(&authzServerURL, "authz-server-url", "", "Authorization Server URL - used to generate the OIDC urls")
 	serveCmd.Flags().StringVar(&jwksURL, "jwks-url", "", "JWKS URL - from the OAuth2 server")
 	serveCmd.Flags().StringVar(&resourceURL, "resource-url", "", "Resource URL for this server - this should be the address to access the MCP server")
+	serveCmd.Flags().StringVar(&resourceDocURL, "resource-documentation-url", "", "URL of the documentation of this server, advertised in the protected resource metadata")
+	serveCmd.Flags().StringSliceVar(&signingMethods, "signing-methods", []string{"RS256"}, "JWT signing methods accepted for Auth tokens (e.g. RS256,ES256,ES384,EdDSA)")
+	serveCmd.Flags().DurationVar(&jwksRefreshInterval, "jwks-refresh-interval", time.Hour, "How often the JWKS is refreshed in the background")
+	serveCmd.Flags().DurationVar(&jwksUnknownKIDRefreshInterval, "jwks-unknown-kid-refresh-interval", 5*time.Minute, "Minimum time between JWKS refreshes triggered by tokens with an unknown key ID")
+	serveCmd.Flags().StringVar(&tokenValidationMode, "token-validation-mode", string(middleware.ValidationModeJWT), "How Auth tokens are validated: jwt, introspection or hybrid (JWTs locally, opaque tokens with introspection)")
+	serveCmd.Flags().StringVar(&introspectionURL, "introspection-url", "", "OAuth token introspection endpoint (RFC 7662) - required for the introspection and hybrid validation modes")
+	serveCmd.Flags().StringVar(&introspectionClientID, "introspection-client-id", "", "Client ID used to authenticate against the introspection endpoint")
+	serveCmd.Flags().StringVar(&introspectionClientSecret, "introspection-client-secret", os.Getenv("INTROSPECTION_CLIENT_SECRET"), "Client secret used to authenticate against the introspection endpoint - defaults to the INTROSPECTION_CLIENT_SECRET env var")
+	serveCmd.Flags().DurationVar(&introspectionCacheTTL, "introspection-cache-ttl", 30*time.Second, "How long token introspection results are cached")
+	serveCmd.Flags().StringVar(&impersonationToken, "impersonation-token", os.Getenv("IMPERSONATION_TOKEN"), "Service account token making the requests of the Auth token users with Impersonate-User/Impersonate-Group headers - defaults to the IMPERSONATION_TOKEN env var, impersonation is disabled if empty")
+	serveCmd.Flags().StringVar(&impersonationTokenFile, "impersonation-token-file", "", "File with the impersonation token, e.g. the token of a mounted ServiceAccount Secret, read again when it's rotated")
+	serveCmd.Flags().StringVar(&rancherURL, "rancher-url", "", "Rancher URL used by the requests without the R_url header, e.g. of IDE agents and CI bots authenticated with an Auth token when impersonation is enabled")
+	serveCmd.Flags().StringVar(&impersonationUserClaim, "impersonation-user-claim", "sub", "JWT claim holding the Rancher user impersonated by the impersonation token")
+	serveCmd.Flags().StringVar(&impersonationGroupsClaim, "impersonation-groups-claim", "groups", "JWT claim holding the Rancher groups impersonated by the impersonation token")
+	serveCmd.Flags().StringSliceVar(&toolsetNames, "toolsets", nil, "Toolsets to add, all by default ("+strings.Join(toolsets.Names(), ", ")+")")
+	serveCmd.Flags().StringSliceVar(&features, "features", nil, "Feature flags enabling experimental toolsets and tools")
+	serveCmd.Flags().StringSliceVar(&execAllowlist, "exec-allowlist", coretools.DefaultExecAllowlist, "Commands the execInPod tool is allowed to run - a trailing '*' allows any additional arguments (e.g. 'ls *') and a trailing '?' a single one that isn't an option (e.g. 'curl -s -o /dev/null -w %{http_code} ?')")
+	serveCmd.Flags().StringVar(&debugImage, "debug-image", "", "Image of the ephemeral containers the runDebugContainer tool attaches to the Pods (e.g. busybox or nicolaka/netshoot) - the tool is disabled if empty")
+	serveCmd.Flags().StringSliceVar(&debugAllowlist, "debug-allowlist", coretools.DefaultDebugAllowlist, "Commands the runDebugContainer tool is allowed to run - a trailing '*' allows any additional arguments (e.g. 'nslookup *') and a trailing '?' a single one that isn't an option")
+	serveCmd.Flags().StringSliceVar(&rawGetAllowlist, "raw-get-allowlist", coretools.DefaultRawGetAllowlist, "API server paths the rawGet tool is allowed to read - a trailing '*' allows any path with this prefix (e.g. '/healthz*')")
+	serveCmd.Flags().StringSliceVar(&imageAllowlist, "image-allowlist", nil, "Image registries and repositories the checkImageCompliance tool allows - a registry allows all its images and a trailing '*' allows any repository with this prefix (e.g. 'registry.rancher.com,docker.io/rancher/*')")
+	serveCmd.Flags().IntVar(&maxResponseBytes, "max-response-bytes", response.DefaultMaxBytes, "Size limit of the tool responses - bigger lists are summarized, 0 disables the limit")
+	serveCmd.Flags().StringVar(&responseProfile, "response-profile", string(response.ProfileFull), "Fields of the resources kept in the tool responses: full, summary for their spec without their status, or minimal for their name and key status - overridable with the responseProfile argument of each tool call")
+	serveCmd.Flags().BoolVar(&readOnly, "read-only", false, "Only add the tools that don't create, modify or delete resources")
+	serveCmd.Flags().BoolVar(&showSensitiveValues, "show-sensitive-values", false, "Return the values of the Secrets and the sensitive fields to the LLM instead of their keys and sizes")
+	serveCmd.Flags().Float64Var(&userRateLimit, "user-rate-limit", 5, "Tool calls per second allowed for each user - 0 disables the limit")
+	serveCmd.Flags().IntVar(&userRateBurst, "user-rate-burst", 20, "Tool calls a user can make at once")
+	serveCmd.Flags().Float64Var(&globalRateLimit, "global-rate-limit", 50, "Tool calls per second allowed for all the users - 0 disables the limit")
+	serveCmd.Flags().IntVar(&globalRateBurst, "global-rate-burst", 100, "Tool calls all the users can make at once")
+	serveCmd.Flags().Int64Var(&maxFanOut, "max-fan-out", coretools.DefaultMaxFanOut, "Clusters queried at the same time by all the multi-cluster tool calls")
+	serveCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "How long the resources read by the tools are cached, per user - writes clear the cache, 0 disables it")
+	serveCmd.Flags().DurationVar(&connectionIdleTimeout, "connection-idle-timeout", client.DefaultConnectionIdleTimeout, "How long the clients and connections to a cluster are reused by the requests with the same token after their last use, 0 disables it")
+	serveCmd.Flags().DurationVar(&clusterIDCacheTTL, "cluster-id-cache-ttl", client.DefaultClusterIDCacheTTL, "How long the IDs of the clusters found by their display name are cached, 0 caches them until the cluster isn't found")
+	serveCmd.Flags().StringVar(&changeHistoryNamespace, "change-history-namespace", client.DefaultChangeHistoryNamespace, "Namespace of the ConfigMaps recording the changes made by the tools for each user in each cluster")
+	serveCmd.Flags().IntVar(&changeHistorySize, "change-history-size", client.DefaultChangeHistorySize, "Changes kept per user and cluster to undo them, Secrets aren't recorded, 0 disables it")
+	serveCmd.Flags().StringSliceVar(&confirmTools, "confirm-tools", middleware.DefaultConfirmationTools, "Destructive tools that only run when called again with the confirmation token returned by their first call")
+	serveCmd.Flags().DurationVar(&confirmationTTL, "confirmation-ttl", middleware.DefaultConfirmationTTL, "How long the confirmation tokens of the destructive tools are valid")
+	serveCmd.Flags().DurationVar(&toolTimeout, "tool-timeout", middleware.DefaultToolTimeout, "How long a tool call can run before it's cancelled and returns the fetches that completed")
+	serveCmd.Flags().StringSliceVar(&toolTimeoutsList, "tool-timeouts", nil, "Time limits of specific tools overriding tool-timeout, as <tool>:<duration> (e.g. getClusterImages:2m)")
+	serveCmd.Flags().StringSliceVar(&sensitiveFields, "sensitive-fields", nil, "Fields redacted in addition to the Secret data, as <kind>:<path> (e.g. configmap:data.password,*:spec.token)")
+	serveCmd.Flags().DurationVar(&sessionTTL, "session-ttl", middleware.DefaultSessionTTL, "How long the cluster and namespace of the last tool calls of an MCP session are remembered after its last request, for the next calls requiring them without setting them")
+	serveCmd.Flags().StringVar(&otlpTracesEndpoint, "otlp-traces-endpoint", "", "OTLP/HTTP endpoint of an OpenTelemetry collector receiving the spans of the MCP requests (e.g. http://otel-collector:4318/v1/traces), disabled if empty")
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
-	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "rancher mcp server", Version: "v1.0.0"}, nil)
+	if err := validateServeFlags(); err != nil {
+		return err
+	}
+
+	client.SetClusterIDCacheTTL(clusterIDCacheTTL)
 	client := client.NewClient(insecure)
+	if cacheTTL > 0 {
+		client.EnableCache(cacheTTL)
+	}
+	if connectionIdleTimeout > 0 {
+		client.EnableConnectionPool(connectionIdleTimeout)
+	}
+	if changeHistorySize > 0 {
+		client.EnableChangeHistory(changeHistoryNamespace, changeHistorySize)
+	}
+	if impersonationToken != "" {
+		client.EnableImpersonation(impersonationToken)
+	}
+	if impersonationTokenFile != "" {
+		client.EnableImpersonationTokenFile(impersonationTokenFile)
+	}
+	if (impersonationToken != "" || impersonationTokenFile != "") && rancherURL != "" {
+		if err := client.CheckImpersonation(cmd.Context(), rancherURL); err != nil {
+			return fmt.Errorf("failed to check the impersonation token: %w", err)
+		}
+	}
+	k8sResources := resources.NewResources(client)
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "rancher mcp server", Version: "v1.0.0"}, k8sResources.ServerOptions())
+	k8sResources.AddResources(mcpServer)
+
+	response.SetMaxBytes(maxResponseBytes)
+	profile, err := response.ParseProfile(responseProfile)
+	if err != nil {
+		return err
+	}
+	response.SetProfile(profile)
+	response.SetShowSensitiveValues(showSensitiveValues)
+	if err := response.SetSensitiveFields(sensitiveFields); err != nil {
+		return err
+	}
+	toolsets.AddAllTools(mcpServer, toolsetNames, toolsets.Deps{
+		Client:          client,
+		ExecAllowlist:   execAllowlist,
+		DebugImage:      debugImage,
+		DebugAllowlist:  debugAllowlist,
+		RawGetAllowlist: rawGetAllowlist,
+		ImageAllowlist:  imageAllowlist,
+		ReadOnly:        readOnly,
+		MaxFanOut:       maxFanOut,
+		Features:        features,
+	})
+
+	rateLimit := middleware.RateLimitMiddleware(middleware.RateLimitConfig{
+		UserRate:    userRateLimit,
+		UserBurst:   userRateBurst,
+		GlobalRate:  globalRateLimit,
+		GlobalBurst: globalRateBurst,
+	})
+	confirmation := middleware.ConfirmationMiddleware(middleware.ConfirmationConfig{
+		Tools: confirmTools,
+		TTL:   confirmationTTL,
+	})
 
-	toolsets.AddAllTools(client, mcpServer)
+	timeouts, err := toolTimeouts()
+	if err != nil {
+		return err
+	}
+	timeout := middleware.TimeoutMiddleware(middleware.TimeoutConfig{
+		Default: toolTimeout,
+		Tools:   timeouts,
+	})
+
+	provider, err := newCredentialProvider()
+	if err != nil {
+		return err
+	}
+	tracingConfig := middleware.TracingConfig{}
+	if otlpTracesEndpoint != "" {
+		exporter := middleware.NewOTLPExporter(otlpTracesEndpoint, "rancher-mcp-server")
+		go exporter.Start(cmd.Context())
+		tracingConfig.Exporter = exporter
+	}
+	tracing := middleware.TracingMiddleware(tracingConfig)
+	session := middleware.SessionMiddleware(middleware.SessionConfig{TTL: sessionTTL})
+	mcpServer.AddReceivingMiddleware(tracing, middleware.CredentialsMiddleware(provider), session, rateLimit, middleware.ResponseProfileMiddleware(), confirmation, timeout)
+	// the credentials of the other sources don't depend on the request, so they can watch the clusters
+	if credentialsSource() != credentialsFromHeader {
+		go client.SyncClusterIDs(cmd.Context(), provider)
+	}
+	if transport == transportStdio {
+		zap.L().Info("MCP Server started!", zap.String("transport", transportStdio), zap.String("credentials", credentialsSource()))
+
+		return mcpServer.Run(cmd.Context(), &mcp.StdioTransport{})
+	}
 
 	handler := mcp.NewStreamableHTTPHandler(func(request *http.Request) *mcp.Server {
 		return mcpServer
@@ -66,10 +283,22 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if insecure {
 		oauthConfig.InsecureTLS = true
 	}
+	oauthConfig.ResourceDocumentationURL = resourceDocURL
+	oauthConfig.SigningMethods = signingMethods
+	oauthConfig.JWKSRefreshInterval = jwksRefreshInterval
+	oauthConfig.JWKSUnknownKIDRefreshInterval = jwksUnknownKIDRefreshInterval
+	oauthConfig.ValidationMode = middleware.ValidationMode(tokenValidationMode)
+	oauthConfig.IntrospectionURL = introspectionURL
+	oauthConfig.IntrospectionClientID = introspectionClientID
+	oauthConfig.IntrospectionClientSecret = introspectionClientSecret
+	oauthConfig.IntrospectionCacheTTL = introspectionCacheTTL
+	oauthConfig.UserClaim = impersonationUserClaim
+	oauthConfig.GroupsClaim = impersonationGroupsClaim
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/.well-known/oauth-protected-resource", oauthConfig.HandleProtectedResourceMetadata)
-	mux.Handle("/", oauthConfig.OAuthMiddleware(handler))
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/", middleware.TracingHTTPMiddleware(oauthConfig.OAuthMiddleware(handler)))
 
 	if err := oauthConfig.LoadJWKS(cmd.Context()); err != nil {
 		log.Fatalf("failed to load JWKS: %s", err)
@@ -82,6 +311,133 @@ func runServe(cmd *cobra.Command, args []string) error {
 	return startTLSServer(mux)
 }
 
+// credentialsSource returns the source of the credentials, the default one of the transport if not set.
+func credentialsSource() string {
+	if credentials != "" {
+		return credentials
+	}
+	if transport == transportStdio {
+		return credentialsFromEnv
+	}
+
+	return credentialsFromHeader
+}
+
+// newCredentialProvider creates the provider of the credentials of the source set with the --credentials flag.
+func newCredentialProvider() (client.CredentialProvider, error) {
+	switch credentialsSource() {
+	case credentialsFromEnv:
+		return client.CredentialsFromEnvironment()
+	case credentialsFromSecret:
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error creating in-cluster config: %v", err)
+		}
+		clientSet, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("creating clientset: %v", err)
+		}
+		namespace, name, _ := strings.Cut(credentialsSecret, "/")
+		return client.NewSecretCredentials(clientSet, namespace, name), nil
+	case credentialsFromExec:
+		return client.NewExecCredentials(rancherURL, strings.Fields(credentialsExec)), nil
+	default:
+		return client.HeaderCredentials{URL: rancherURL}, nil
+	}
+}
+
+// toolTimeouts parses the time limits of the tool-timeouts flag, by tool name.
+func toolTimeouts() (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, toolTimeout := range toolTimeoutsList {
+		tool, value, _ := strings.Cut(toolTimeout, ":")
+		timeout, err := time.ParseDuration(value)
+		if tool == "" || err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid tool-timeouts %q, must be <tool>:<duration> with a duration more than 0", toolTimeout)
+		}
+		timeouts[tool] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// validateServeFlags checks the settings of the serve command, which can also come from the config file and the
+// environment variables, and returns all the invalid ones.
+func validateServeFlags() error {
+	var errs []error
+	if transport != transportHTTP && transport != transportStdio {
+		errs = append(errs, fmt.Errorf("invalid transport %q, must be %s or %s", transport, transportHTTP, transportStdio))
+	}
+	switch credentialsSource() {
+	case credentialsFromHeader, credentialsFromEnv:
+	case credentialsFromSecret:
+		if namespace, name, ok := strings.Cut(credentialsSecret, "/"); !ok || namespace == "" || name == "" {
+			errs = append(errs, fmt.Errorf("invalid credentials-secret %q, must be <namespace>/<name> with the secret credentials", credentialsSecret))
+		}
+	case credentialsFromExec:
+		if strings.TrimSpace(credentialsExec) == "" || rancherURL == "" {
+			errs = append(errs, errors.New("credentials-exec and rancher-url are required with the exec credentials"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid credentials %q, must be header, env, secret or exec", credentials))
+	}
+	if port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid port %d, must be between 1 and 65535", port))
+	}
+	switch middleware.ValidationMode(tokenValidationMode) {
+	case middleware.ValidationModeJWT:
+	case middleware.ValidationModeIntrospection, middleware.ValidationModeHybrid:
+		if introspectionURL == "" {
+			errs = append(errs, fmt.Errorf("introspection-url is required with the %s token validation mode", tokenValidationMode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid token validation mode %q, must be jwt, introspection or hybrid", tokenValidationMode))
+	}
+	for _, name := range toolsetNames {
+		if !slices.Contains(toolsets.Names(), name) {
+			errs = append(errs, fmt.Errorf("unknown toolset %q, must be one of %s", name, strings.Join(toolsets.Names(), ", ")))
+		}
+	}
+	if maxResponseBytes < 0 {
+		errs = append(errs, fmt.Errorf("invalid max-response-bytes %d, must be 0 or more", maxResponseBytes))
+	}
+	if _, err := response.ParseProfile(responseProfile); err != nil {
+		errs = append(errs, err)
+	}
+	if userRateLimit < 0 || globalRateLimit < 0 {
+		errs = append(errs, errors.New("invalid rate limit, must be 0 or more"))
+	}
+	if cacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid cache-ttl %s, must be 0 or more", cacheTTL))
+	}
+	if clusterIDCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid cluster-id-cache-ttl %s, must be 0 or more", clusterIDCacheTTL))
+	}
+	if connectionIdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid connection-idle-timeout %s, must be 0 or more", connectionIdleTimeout))
+	}
+	if impersonationToken != "" && impersonationTokenFile != "" {
+		errs = append(errs, errors.New("impersonation-token and impersonation-token-file can't be set together"))
+	}
+	if confirmationTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid confirmation-ttl %s, must be more than 0", confirmationTTL))
+	}
+	if toolTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid tool-timeout %s, must be more than 0", toolTimeout))
+	}
+	if _, err := toolTimeouts(); err != nil {
+		errs = append(errs, err)
+	}
+	if changeHistorySize < 0 {
+		errs = append(errs, fmt.Errorf("invalid change-history-size %d, must be 0 or more", changeHistorySize))
+	}
+	if maxFanOut < 1 {
+		errs = append(errs, fmt.Errorf("invalid max-fan-out %d, must be 1 or more", maxFanOut))
+	}
+
+	return errors.Join(errs...)
+}
+
 func startInsecureServer(handler http.Handler) error {
 	zap.L().Info("MCP Server started!", zap.Int("port", port), zap.Bool("insecure", true))
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"testing"
 
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -35,3 +36,96 @@ func TestRunServeCommand(t *testing.T) {
 	require.NotNil(t, insecureFlag)
 	assert.Equal(t, "false", insecureFlag.DefValue)
 }
+
+func TestValidateServeFlags(t *testing.T) {
+	tests := map[string]struct {
+		set           func()
+		expectedError string
+	}{
+		"defaults": {
+			set: func() {},
+		},
+		"invalid transport": {
+			set:           func() { transport = "sse" },
+			expectedError: `invalid transport "sse", must be http or stdio`,
+		},
+		"invalid port": {
+			set:           func() { port = 70000 },
+			expectedError: "invalid port 70000, must be between 1 and 65535",
+		},
+		"introspection without url": {
+			set:           func() { tokenValidationMode = "hybrid" },
+			expectedError: "introspection-url is required with the hybrid token validation mode",
+		},
+		"unknown toolset": {
+			set:           func() { toolsetNames = []string{"core", "monitoring"} },
+			expectedError: `unknown toolset "monitoring"`,
+		},
+		"negative rate limit": {
+			set:           func() { userRateLimit = -1 },
+			expectedError: "invalid rate limit, must be 0 or more",
+		},
+		"no fan-out": {
+			set:           func() { maxFanOut = 0 },
+			expectedError: "invalid max-fan-out 0, must be 1 or more",
+		},
+		"impersonation token and token file": {
+			set:           func() { impersonationToken, impersonationTokenFile = "token", "/var/run/secrets/rancher/token" },
+			expectedError: "impersonation-token and impersonation-token-file can't be set together",
+		},
+		"invalid credentials": {
+			set:           func() { credentials = "vault" },
+			expectedError: `invalid credentials "vault", must be header, env, secret or exec`,
+		},
+		"secret credentials": {
+			set: func() { credentials, credentialsSecret = "secret", "cattle-ai-agent-system/rancher-credentials" },
+		},
+		"secret credentials without namespace": {
+			set:           func() { credentials, credentialsSecret = "secret", "rancher-credentials" },
+			expectedError: `invalid credentials-secret "rancher-credentials", must be <namespace>/<name> with the secret credentials`,
+		},
+		"exec credentials without rancher url": {
+			set:           func() { credentials, credentialsExec = "exec", "rancher-token --cluster local" },
+			expectedError: "credentials-exec and rancher-url are required with the exec credentials",
+		},
+		"tool timeouts": {
+			set: func() { toolTimeoutsList = []string{"getClusterImages:2m", "inspectPod:45s"} },
+		},
+		"invalid tool timeout": {
+			set:           func() { toolTimeoutsList = []string{"getClusterImages"} },
+			expectedError: `invalid tool-timeouts "getClusterImages", must be <tool>:<duration> with a duration more than 0`,
+		},
+		"no tool timeout": {
+			set:           func() { toolTimeout = 0 },
+			expectedError: "invalid tool-timeout 0s, must be more than 0",
+		},
+		"negative max response bytes": {
+			set:           func() { maxResponseBytes = -1 },
+			expectedError: "invalid max-response-bytes -1, must be 0 or more",
+		},
+		"invalid response profile": {
+			set:           func() { responseProfile = "compact" },
+			expectedError: `invalid response profile "compact", must be full, summary or minimal`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			transport, port, tokenValidationMode, introspectionURL, toolsetNames, maxResponseBytes = "http", 9092, "jwt", "", nil, 0
+			userRateLimit, globalRateLimit, maxFanOut = 5, 50, 50
+			impersonationToken, impersonationTokenFile = "", ""
+			credentials, credentialsSecret, credentialsExec, rancherURL = "", "", "", ""
+			toolTimeout, toolTimeoutsList = middleware.DefaultToolTimeout, nil
+			responseProfile = "full"
+			test.set()
+
+			err := validateServeFlags()
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Package config loads the settings of the MCP server from a config file and environment variables.
+//
+// The settings have the names of the command-line flags, so any flag can be set in the config file:
+//
+//	port: 9443
+//	read-only: true
+//	log-level: debug
+//	toolsets: [core, fleet]
+//
+// or with an environment variable named after the flag with the MCP_ prefix, e.g. MCP_READ_ONLY=true.
+// Flags set in the command line have the highest precedence, followed by the environment variables
+// and the config file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the settings.
+const EnvPrefix = "MCP_"
+
+// EnvName returns the environment variable overriding the setting of a flag (e.g. MCP_READ_ONLY for read-only).
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// Load sets the flags that weren't set in the command line from the environment variables and the config file.
+// The config file is optional, an empty path only loads the environment variables. Unknown settings and invalid
+// values are reported with the name of the setting.
+func Load(flags *pflag.FlagSet, file string) error {
+	settings, err := readFile(file)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for name := range settings {
+		if flags.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("unknown setting %q in %s", name, file))
+		}
+	}
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			return
+		}
+		if value, ok := os.LookupEnv(EnvName(flag.Name)); ok {
+			if err := flags.Set(flag.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q of %s: %w", value, EnvName(flag.Name), err))
+			}
+			return
+		}
+		if value, ok := settings[flag.Name]; ok {
+			if err := setValue(flag, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value of %s in %s: %w", flag.Name, file, err))
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// readFile returns the settings of the YAML config file.
+func readFile(file string) (map[string]any, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", file, err)
+	}
+
+	return settings, nil
+}
+
+// setValue sets the flag to a value of the config file. Lists are only supported by flags with several values.
+func setValue(flag *pflag.Flag, value any) error {
+	if list, ok := value.([]any); ok {
+		sliceValue, ok := flag.Value.(pflag.SliceValue)
+		if !ok {
+			return errors.New("must be a single value, not a list")
+		}
+		values := make([]string, len(list))
+		for i, item := range list {
+			values[i] = formatValue(item)
+		}
+		if err := sliceValue.Replace(values); err != nil {
+			return err
+		}
+		flag.Changed = true
+		return nil
+	}
+	if _, ok := value.(map[string]any); ok {
+		return errors.New("must be a single value or a list")
+	}
+
+	if err := flag.Value.Set(formatValue(value)); err != nil {
+		return err
+	}
+	flag.Changed = true
+
+	return nil
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
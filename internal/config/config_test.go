@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSettings struct {
+	port     int
+	readOnly bool
+	toolsets []string
+	interval time.Duration
+	logLevel string
+}
+
+func newTestFlags(settings *testSettings) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.IntVar(&settings.port, "port", 9092, "")
+	flags.BoolVar(&settings.readOnly, "read-only", false, "")
+	flags.StringSliceVar(&settings.toolsets, "toolsets", nil, "")
+	flags.DurationVar(&settings.interval, "jwks-refresh-interval", time.Hour, "")
+	flags.StringVar(&settings.logLevel, "log-level", "", "")
+	return flags
+}
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		file          string
+		env           map[string]string
+		args          []string
+		expected      testSettings
+		expectedError string
+	}{
+		"defaults without config": {
+			expected: testSettings{port: 9092, interval: time.Hour},
+		},
+		"config file": {
+			file: "port: 9443\nread-only: true\ntoolsets: [core, fleet]\njwks-refresh-interval: 10m\nlog-level: debug\n",
+			expected: testSettings{
+				port:     9443,
+				readOnly: true,
+				toolsets: []string{"core", "fleet"},
+				interval: 10 * time.Minute,
+				logLevel: "debug",
+			},
+		},
+		"env vars override the config file": {
+			file:     "port: 9443\ntoolsets: [core]\n",
+			env:      map[string]string{"MCP_PORT": "8080", "MCP_TOOLSETS": "rbac,catalog"},
+			expected: testSettings{port: 8080, toolsets: []string{"rbac", "catalog"}, interval: time.Hour},
+		},
+		"flags override env vars and the config file": {
+			file:     "port: 9443\nread-only: true\n",
+			env:      map[string]string{"MCP_PORT": "8080"},
+			args:     []string{"--port=7070"},
+			expected: testSettings{port: 7070, readOnly: true, interval: time.Hour},
+		},
+		"unknown setting": {
+			file:          "prot: 9443\n",
+			expectedError: `unknown setting "prot"`,
+		},
+		"invalid value in the config file": {
+			file:          "port: nine\n",
+			expectedError: "invalid value of port",
+		},
+		"list for a single value": {
+			file:          "port: [9443]\n",
+			expectedError: "invalid value of port in",
+		},
+		"invalid env var": {
+			env:           map[string]string{"MCP_READ_ONLY": "maybe"},
+			expectedError: `invalid value "maybe" of MCP_READ_ONLY`,
+		},
+		"invalid yaml": {
+			file:          "port: [\n",
+			expectedError: "failed to parse config file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+			var file string
+			if test.file != "" {
+				file = filepath.Join(t.TempDir(), "config.yaml")
+				require.NoError(t, os.WriteFile(file, []byte(test.file), 0o600))
+			}
+			var settings testSettings
+			flags := newTestFlags(&settings)
+			require.NoError(t, flags.Parse(test.args))
+
+			err := Load(flags, file)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, settings)
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	var settings testSettings
+
+	err := Load(newTestFlags(&settings), filepath.Join(t.TempDir(), "missing.yaml"))
+
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MCP_READ_ONLY", EnvName("read-only"))
+	assert.Equal(t, "MCP_PORT", EnvName("port"))
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// ConfirmationTokenArg is the argument of the tool calls carrying the confirmation token.
+	ConfirmationTokenArg = "confirmationToken"
+	// DefaultConfirmationTTL is how long a confirmation token is valid by default.
+	DefaultConfirmationTTL = 5 * time.Minute
+)
+
+// DefaultConfirmationTools contains the destructive tools requiring a confirmation token by default.
+var DefaultConfirmationTools = []string{
+	"deleteKubernetesResource",
+	"deleteNamespace",
+	"clearNamespaceFinalizers",
+	"rollbackDeployment",
+	"restoreBackup",
+	"undoLastChange",
+	"scaleWorkload",
+	"bulkLabelResources",
+	"cloneNamespace",
+	"rotateClusterRegistrationToken",
+	"setNamespacePodSecurity",
+}
+
+// ConfirmationConfig configures the tools requiring a confirmation before they are run.
+type ConfirmationConfig struct {
+	// Tools contains the names of the tools requiring a confirmation token.
+	Tools []string
+	// TTL is how long a confirmation token is valid. If 0, DefaultConfirmationTTL is used.
+	TTL time.Duration
+}
+
+// pendingConfirmation is a tool call waiting to be confirmed.
+type pendingConfirmation struct {
+	user      string
+	tool      string
+	arguments string
+	expiresAt time.Time
+}
+
+// confirmations holds the confirmation tokens returned by the first calls of the tools.
+type confirmations struct {
+	config ConfirmationConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+}
+
+// confirmationRequired is the result of the first call of a tool requiring a confirmation.
+type confirmationRequired struct {
+	Tool              string         `json:"tool"`
+	Arguments         map[string]any `json:"arguments"`
+	ConfirmationToken string         `json:"confirmationToken"`
+	ExpiresAt         string         `json:"expiresAt"`
+}
+
+// ConfirmationMiddleware returns an MCP middleware enforcing a two-phase protocol for the destructive tools of the
+// config. The first call of one of these tools doesn't run it, but returns a summary of the call and a short-lived
+// confirmation token. The tool only runs when it's called again by the same user with the same arguments and the
+// token, which can only be used once. The confirmationToken argument is added to the input schema of these tools
+// when they are listed, and removed from the arguments before they are run. It must run after CredentialsMiddleware.
+func ConfirmationMiddleware(config ConfirmationConfig) mcp.Middleware {
+	return newConfirmations(config).middleware
+}
+
+func newConfirmations(config ConfirmationConfig) *confirmations {
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfirmationTTL
+	}
+
+	return &confirmations{
+		config:  config,
+		now:     time.Now,
+		pending: map[string]pendingConfirmation{},
+	}
+}
+
+func (c *confirmations) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch req := req.(type) {
+		case *mcp.ListToolsRequest:
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+			if listResult, ok := result.(*mcp.ListToolsResult); ok {
+				c.addTokenArg(listResult)
+			}
+			return result, nil
+		case *mcp.CallToolRequest:
+			if !slices.Contains(c.config.Tools, req.Params.Name) {
+				return next(ctx, method, req)
+			}
+			return c.callTool(ctx, method, req, next)
+		default:
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// callTool returns a confirmation token if the call has none, or runs the tool without the token if it's valid.
+func (c *confirmations) callTool(ctx context.Context, method string, req *mcp.CallToolRequest, next mcp.MethodHandler) (mcp.Result, error) {
+	tool := req.Params.Name
+	arguments := map[string]any{}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &arguments); err != nil {
+			return response.CreateMcpErrorResult(apierrors.NewBadRequest(fmt.Sprintf("invalid arguments: %v", err))), nil
+		}
+	}
+	token, _ := arguments[ConfirmationTokenArg].(string)
+	delete(arguments, ConfirmationTokenArg)
+	// maps are marshaled with sorted keys, so the same arguments always give the same JSON
+	canonical, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+	user := userKey(ctx)
+
+	if token == "" {
+		summary, err := c.request(ctx, user, tool, arguments, string(canonical))
+		if err != nil {
+			return nil, err
+		}
+		return summary, nil
+	}
+
+	if err := c.confirm(token, user, tool, string(canonical)); err != nil {
+		Logger(ctx).Warn("tool call not confirmed", zap.String("tool", tool), zap.String("user", user), zap.Error(err))
+		return response.CreateMcpErrorResult(apierrors.NewBadRequest(err.Error())), nil
+	}
+	if session := SessionFrom(ctx); session != nil {
+		session.removeConfirmation(token)
+	}
+	params := *req.Params
+	params.Arguments = canonical
+	confirmed := *req
+	confirmed.Params = &params
+
+	return next(ctx, method, &confirmed)
+}
+
+// request creates a confirmation token for a tool call and returns the summary of the call asking to confirm it.
+// The token is also recorded in the session of the request, if any.
+func (c *confirmations) request(ctx context.Context, user string, tool string, arguments map[string]any, canonical string) (*mcp.CallToolResult, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	expiresAt := c.now().Add(c.config.TTL)
+
+	c.mu.Lock()
+	c.removeExpired()
+	c.pending[token] = pendingConfirmation{user: user, tool: tool, arguments: canonical, expiresAt: expiresAt}
+	c.mu.Unlock()
+	if session := SessionFrom(ctx); session != nil {
+		session.addConfirmation(PendingConfirmation{Tool: tool, Token: token, ExpiresAt: expiresAt})
+	}
+
+	summary, err := json.Marshal(map[string]any{
+		"confirmationRequired": confirmationRequired{
+			Tool:              tool,
+			Arguments:         arguments,
+			ConfirmationToken: token,
+			ExpiresAt:         expiresAt.UTC().Format(time.RFC3339),
+		},
+		"message": fmt.Sprintf("%s was not run. Show these arguments to the user and, once they confirm, call %s again with the same arguments and %s set to %s.", tool, tool, ConfirmationTokenArg, token),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(summary)}},
+	}, nil
+}
+
+// confirm checks that a token was returned for the same call of the same user and hasn't expired, and removes it.
+func (c *confirmations) confirm(token string, user string, tool string, canonical string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.pending[token]
+	if !ok || pending.user != user || c.now().After(pending.expiresAt) {
+		return fmt.Errorf("invalid or expired confirmation token, call %s again without %s to get a new one", tool, ConfirmationTokenArg)
+	}
+	if pending.tool != tool || pending.arguments != canonical {
+		return fmt.Errorf("the confirmation token was returned for a different call, call %s again without %s to confirm these arguments", tool, ConfirmationTokenArg)
+	}
+	delete(c.pending, token)
+
+	return nil
+}
+
+// removeExpired removes the expired tokens. c.mu must be held.
+func (c *confirmations) removeExpired() {
+	now := c.now()
+	for token, pending := range c.pending {
+		if now.After(pending.expiresAt) {
+			delete(c.pending, token)
+		}
+	}
+}
+
+// addTokenArg adds the confirmationToken argument to the input schema of the tools requiring a confirmation. The
+// tools are copied, since the result holds the tools registered in the server.
+func (c *confirmations) addTokenArg(result *mcp.ListToolsResult) {
+	for i, tool := range result.Tools {
+		if !slices.Contains(c.config.Tools, tool.Name) {
+			continue
+		}
+		data, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			zap.L().Warn("failed to marshal input schema", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+		schema := map[string]any{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			zap.L().Warn("failed to unmarshal input schema", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		if properties == nil {
+			properties = map[string]any{}
+		}
+		properties[ConfirmationTokenArg] = map[string]any{
+			"type":        "string",
+			"description": "the confirmation token returned by the previous call of the tool with the same arguments, once the user confirmed it",
+		}
+		schema["properties"] = properties
+
+		copied := *tool
+		copied.InputSchema = schema
+		result.Tools[i] = &copied
+	}
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// confirmationToken returns the token of the result of a first tool call.
+func confirmationToken(t *testing.T, result mcp.Result) string {
+	t.Helper()
+	callResult, ok := result.(*mcp.CallToolResult)
+	require.True(t, ok)
+	require.False(t, callResult.IsError)
+	var summary struct {
+		ConfirmationRequired confirmationRequired `json:"confirmationRequired"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(callResult.Content[0].(*mcp.TextContent).Text), &summary))
+	require.NotEmpty(t, summary.ConfirmationRequired.ConfirmationToken)
+
+	return summary.ConfirmationRequired.ConfirmationToken
+}
+
+func TestConfirmationMiddleware(t *testing.T) {
+	type call struct {
+		tool      string
+		user      string
+		arguments map[string]any
+		// withToken sends the token returned by the first call
+		withToken bool
+		// wait moves the clock forward before the call
+		wait time.Duration
+	}
+	deleteArgs := map[string]any{"kind": "pod", "name": "web", "namespace": "default", "cluster": "local"}
+
+	tests := map[string]struct {
+		calls         []call
+		expectedRun   []map[string]any
+		expectedError string
+	}{
+		"confirmed call": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs},
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs, withToken: true},
+			},
+			expectedRun: []map[string]any{deleteArgs},
+		},
+		"tool not requiring confirmation": {
+			calls: []call{
+				{tool: "getKubernetesResource", user: "user-a", arguments: deleteArgs},
+			},
+			expectedRun: []map[string]any{deleteArgs},
+		},
+		"not confirmed": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs},
+			},
+		},
+		"token used twice": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs},
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs, withToken: true},
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs, withToken: true},
+			},
+			expectedRun:   []map[string]any{deleteArgs},
+			expectedError: "invalid or expired confirmation token, call deleteKubernetesResource again without confirmationToken to get a new one",
+		},
+		"expired token": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs},
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs, withToken: true, wait: 6 * time.Minute},
+			},
+			expectedError: "invalid or expired confirmation token, call deleteKubernetesResource again without confirmationToken to get a new one",
+		},
+		"token of another user": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs},
+				{tool: "deleteKubernetesResource", user: "user-b", arguments: deleteArgs, withToken: true},
+			},
+			expectedError: "invalid or expired confirmation token, call deleteKubernetesResource again without confirmationToken to get a new one",
+		},
+		"token of another user with the same unverified jwt subject": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: testJWT(t, "u-1", "a"), arguments: deleteArgs},
+				{tool: "deleteKubernetesResource", user: testJWT(t, "u-1", "b"), arguments: deleteArgs, withToken: true},
+			},
+			expectedError: "invalid or expired confirmation token, call deleteKubernetesResource again without confirmationToken to get a new one",
+		},
+		"different arguments": {
+			calls: []call{
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: deleteArgs},
+				{tool: "deleteKubernetesResource", user: "user-a", arguments: map[string]any{"kind": "pod", "name": "db", "namespace": "default", "cluster": "local"}, withToken: true},
+			},
+			expectedError: "the confirmation token was returned for a different call, call deleteKubernetesResource again without confirmationToken to confirm these arguments",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var run []map[string]any
+			next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				arguments := map[string]any{}
+				require.NoError(t, json.Unmarshal(req.(*mcp.CallToolRequest).Params.Arguments, &arguments))
+				run = append(run, arguments)
+				return &mcp.CallToolResult{}, nil
+			}
+			now := time.Now()
+			c := newConfirmations(ConfirmationConfig{Tools: DefaultConfirmationTools})
+			c.now = func() time.Time { return now }
+			handler := c.middleware(next)
+
+			var token, errorText string
+			for _, call := range tt.calls {
+				arguments := map[string]any{}
+				for k, v := range call.arguments {
+					arguments[k] = v
+				}
+				if call.withToken {
+					arguments[ConfirmationTokenArg] = token
+				}
+				data, err := json.Marshal(arguments)
+				require.NoError(t, err)
+				now = now.Add(call.wait)
+				ctx := WithToken(t.Context(), call.user)
+
+				result, err := handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: call.tool, Arguments: data}})
+				require.NoError(t, err)
+				callResult := result.(*mcp.CallToolResult)
+				if callResult.IsError {
+					errorText = callResult.Content[0].(*mcp.TextContent).Text
+					continue
+				}
+				if !call.withToken && call.tool == "deleteKubernetesResource" {
+					token = confirmationToken(t, result)
+				}
+			}
+
+			assert.Equal(t, tt.expectedRun, run)
+			if tt.expectedError == "" {
+				assert.Empty(t, errorText)
+			} else {
+				assert.Contains(t, errorText, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfirmationMiddlewareListTools(t *testing.T) {
+	schema := map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}}
+	registered := []*mcp.Tool{
+		{Name: "deleteKubernetesResource", InputSchema: schema},
+		{Name: "getKubernetesResource", InputSchema: schema},
+	}
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.ListToolsResult{Tools: slices.Clone(registered)}, nil
+	}
+	handler := ConfirmationMiddleware(ConfirmationConfig{Tools: DefaultConfirmationTools})(next)
+
+	result, err := handler(t.Context(), "tools/list", &mcp.ListToolsRequest{})
+
+	require.NoError(t, err)
+	tools := result.(*mcp.ListToolsResult).Tools
+	require.Len(t, tools, 2)
+	assert.Contains(t, tools[0].InputSchema.(map[string]any)["properties"], ConfirmationTokenArg)
+	assert.NotContains(t, tools[1].InputSchema.(map[string]any)["properties"], ConfirmationTokenArg)
+	assert.NotContains(t, schema["properties"], ConfirmationTokenArg, "the registered tools should not be modified")
+}
@@ -2,34 +2,21 @@ package middleware
 
 import (
 	"context"
-)
-
-// contextKey is a custom type for context keys to avoid collisions with
-// other packages that might use string keys. Using a struct pointer ensures
-// uniqueness since each instance has a unique memory address.
-type contextKey struct{ name string }
 
-var (
-	// tokenCtxKey is the context key for storing the JWT bearer token.
-	// It's unexported to prevent external packages from accessing it directly.
-	tokenCtxKey = &contextKey{"token"}
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
 )
 
-// Token context helpers.
+// Token context helpers. The token is stored in the context by the client package, so the client can read it
+// without depending on the middlewares.
 
 // WithToken sets the token into the context.
 func WithToken(ctx context.Context, token string) context.Context {
-	return context.WithValue(ctx, tokenCtxKey, token)
+	return client.WithToken(ctx, token)
 }
 
 // Token gets the token from the context.
 //
 // Returns empty string if no token is found.
 func Token(ctx context.Context) string {
-	token, ok := ctx.Value(tokenCtxKey).(string)
-	if ok {
-		return token
-	}
-
-	return ""
+	return client.Token(ctx)
 }
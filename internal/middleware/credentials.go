@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+)
+
+// CredentialsMiddleware returns an MCP middleware that resolves the credentials of each tool call, resource read and
+// resource subscription with the provider. The handlers read the URL from the R_url header and the token from the
+// context, so they don't depend on the transport.
+func CredentialsMiddleware(provider client.CredentialProvider) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			var extra **mcp.RequestExtra
+			switch r := req.(type) {
+			case *mcp.CallToolRequest:
+				extra = &r.Extra
+			case *mcp.ReadResourceRequest:
+				extra = &r.Extra
+			case *mcp.SubscribeRequest:
+				extra = &r.Extra
+			case *mcp.UnsubscribeRequest:
+				extra = &r.Extra
+			default:
+				return next(ctx, method, req)
+			}
+
+			credentials, err := provider.Credentials(ctx, *extra)
+			if err != nil {
+				return nil, err
+			}
+			if *extra == nil {
+				*extra = &mcp.RequestExtra{}
+			}
+			if (*extra).Header == nil {
+				(*extra).Header = http.Header{}
+			}
+			(*extra).Header.Set(client.URLHeader, credentials.URL)
+
+			return next(WithToken(ctx, credentials.Token), method, req)
+		}
+	}
+}
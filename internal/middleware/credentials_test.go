@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+)
+
+func TestCredentialsMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		provider      client.CredentialProvider
+		ctx           context.Context
+		req           mcp.Request
+		expectedURL   string
+		expectedToken string
+	}{
+		"static credentials without headers": {
+			provider:      client.StaticCredentials{URL: testOtherURL, Token: "token-abc"},
+			ctx:           context.Background(),
+			req:           &mcp.CallToolRequest{},
+			expectedURL:   testOtherURL,
+			expectedToken: "token-abc",
+		},
+		"header credentials": {
+			provider:      client.HeaderCredentials{},
+			ctx:           WithToken(context.Background(), "token-abc"),
+			req:           &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{client.URLHeader: {testOtherURL}}}},
+			expectedURL:   testOtherURL,
+			expectedToken: "token-abc",
+		},
+		"header credentials with a default url": {
+			provider:      client.HeaderCredentials{URL: testAnotherURL},
+			ctx:           WithToken(context.Background(), "token-abc"),
+			req:           &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{}}},
+			expectedURL:   testAnotherURL,
+			expectedToken: "token-abc",
+		},
+		"header credentials overriding the default url": {
+			provider:      client.HeaderCredentials{URL: testAnotherURL},
+			ctx:           WithToken(context.Background(), "token-abc"),
+			req:           &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{client.URLHeader: {testOtherURL}}}},
+			expectedURL:   testOtherURL,
+			expectedToken: "token-abc",
+		},
+		"static credentials for a resource read": {
+			provider:      client.StaticCredentials{URL: testOtherURL, Token: "token-abc"},
+			ctx:           context.Background(),
+			req:           &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "k8s://local/pod/default/nginx"}},
+			expectedURL:   testOtherURL,
+			expectedToken: "token-abc",
+		},
+		"header credentials for a resource subscription": {
+			provider:      client.HeaderCredentials{},
+			ctx:           WithToken(context.Background(), "token-abc"),
+			req:           &mcp.SubscribeRequest{Extra: &mcp.RequestExtra{Header: http.Header{client.URLHeader: {testOtherURL}}}},
+			expectedURL:   testOtherURL,
+			expectedToken: "token-abc",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var url, token string
+			next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				url = req.GetExtra().Header.Get(client.URLHeader)
+				token = Token(ctx)
+				return &mcp.CallToolResult{}, nil
+			}
+
+			_, err := CredentialsMiddleware(tt.provider)(next)(tt.ctx, "tools/call", tt.req)
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if url != tt.expectedURL {
+				t.Errorf("Expected URL %q, got %q", tt.expectedURL, url)
+			}
+			if token != tt.expectedToken {
+				t.Errorf("Expected token %q, got %q", tt.expectedToken, token)
+			}
+		})
+	}
+}
@@ -13,6 +13,19 @@
 //   - Scope validation requiring at least one supported scope be present
 //   - Expiration checking with configurable clock skew tolerance (10s leeway)
 //
+// # Token Introspection
+//
+// For deployments where the authorization server issues opaque tokens, the
+// ValidationMode can be set to validate tokens with an OAuth token introspection
+// endpoint (RFC 7662) instead of, or in addition to, local JWT verification:
+//   - ValidationModeJWT (default) validates tokens locally using the JWKS
+//   - ValidationModeIntrospection validates all tokens with the IntrospectionURL
+//   - ValidationModeHybrid validates JWTs locally and opaque tokens with the IntrospectionURL
+//
+// Introspection requests authenticate with IntrospectionClientID and
+// IntrospectionClientSecret, and results are cached for IntrospectionCacheTTL
+// (30s by default) or until the token expires.
+//
 // # Usage
 //
 // Create and configure the OAuth middleware:
@@ -41,11 +54,67 @@
 //	    // Use token as needed
 //	}
 //
+// The Rancher user and groups of a validated JWT, read from the UserClaim and GroupsClaim, are also set
+// in the context with client.WithUser, so the client can impersonate them with a service account token.
+//
+// # Credentials
+//
+// Tools and resources read the Rancher URL from the R_url header and the token from the context.
+// CredentialsMiddleware is an MCP middleware that sets both for each tool call, resource read and
+// resource subscription using a client.CredentialProvider, so they work with any transport:
+//   - client.HeaderCredentials uses the headers of the StreamableHTTP requests
+//   - client.StaticCredentials uses the same credentials for every call, for the stdio transport
+//   - client.SecretCredentials reads them from a Secret, and picks up their rotation
+//   - client.ExecCredentials runs a command printing an ExecCredential, like kubectl credential plugins
+//
+// client.CredentialsFromEnvironment reads the static credentials from RANCHER_URL and
+// RANCHER_TOKEN or from a kubeconfig generated by Rancher:
+//
+//	credentials, err := client.CredentialsFromEnvironment()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	mcpServer.AddReceivingMiddleware(middleware.CredentialsMiddleware(credentials))
+//
+// # Confirmation of Destructive Tools
+//
+// ConfirmationMiddleware is an MCP middleware enforcing a two-phase protocol for the destructive tools,
+// DefaultConfirmationTools unless configured otherwise. The first call of these tools returns a summary of
+// the call and a confirmation token valid for 5 minutes, without running the tool. The tool only runs when
+// the same user calls it again with the same arguments and the token in the confirmationToken argument.
+//
+// # Response Profiles
+//
+// ResponseProfileMiddleware is an MCP middleware adding the responseProfile argument to all the tools. A
+// call setting it gets the fields of the resources of this response profile instead of the one of the server,
+// set in the context with response.WithProfile.
+//
+// # Tool Timeouts
+//
+// TimeoutMiddleware is an MCP middleware cancelling the tool calls running for longer than their time
+// limit, DefaultToolTimeout unless configured otherwise. A call that times out returns a Timeout error
+// listing the fetches of the tool that completed and the ones still running, recorded by pkg/fetch. The
+// waitSeconds argument of the tools waiting for their changes is added to their time limit.
+//
+// # Session Memory
+//
+// SessionMiddleware is an MCP middleware remembering the cluster and the namespace of the successful tool
+// calls of each session, and the confirmation tokens returned in it. The tool calls of the session missing a
+// required cluster or namespace use the last one. The tools read the session with SessionFrom.
+//
+// # Request Tracing
+//
+// TracingMiddleware is an MCP middleware setting a request ID and the W3C trace context of the
+// X-Request-Id and traceparent headers into the context of every request, or new ones without them.
+// Logger returns a logger with both IDs, and the client sends them to Rancher in the same headers.
+// The spans of the sampled requests are sent to a SpanExporter, e.g. the OTLPExporter.
+//
 // # Protected Resource Metadata
 //
 // The package also provides a metadata endpoint handler that exposes OAuth 2.0
-// Protected Resource Metadata as defined in RFC 8414, including the authorization
-// server URL, JWKS URL, resource server URL, and supported scopes:
+// Protected Resource Metadata as defined in RFC 9728, including the resource server
+// URL, the authorization servers, the supported scopes and bearer methods, and the
+// resource documentation URL when configured:
 //
 //	http.HandleFunc("/.well-known/oauth-protected-resource",
 //	    config.HandleProtectedResourceMetadata)
@@ -53,9 +122,15 @@
 // # Security Considerations
 //
 // The middleware enforces strict security requirements:
-//   - Only RS256 (RSA Signature with SHA-256) signing method is accepted
+//   - Only asymmetric signing methods are accepted, RS256 by default. ES256, ES384,
+//     EdDSA and the other RSA variants can be enabled with SigningMethods
+//   - The JWKS is refreshed periodically and when a token with an unknown key ID is
+//     received, so keys can be rotated at the authorization server without a restart
 //   - Token expiration is validated with a 10-second leeway for clock skew
-//   - All validation failures result in HTTP 401 Unauthorized responses
+//   - Validation failures result in HTTP 401 Unauthorized responses with the
+//     invalid_token error, or HTTP 403 Forbidden responses with the insufficient_scope
+//     error and the required scopes when the token lacks the supported scopes, in the
+//     WWW-Authenticate challenge pointing to the protected resource metadata
 //   - Failed validations are logged with structured logging (logrus/zap)
 //
 // # Scope Validation Strategy
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ValidationMode defines how bearer tokens are validated.
+type ValidationMode string
+
+const (
+	// ValidationModeJWT validates tokens locally as JWTs using the JWKS.
+	ValidationModeJWT ValidationMode = "jwt"
+	// ValidationModeIntrospection validates tokens with the OAuth token introspection endpoint (RFC 7662).
+	ValidationModeIntrospection ValidationMode = "introspection"
+	// ValidationModeHybrid validates JWTs locally and opaque tokens with the introspection endpoint.
+	ValidationModeHybrid ValidationMode = "hybrid"
+)
+
+const (
+	// defaultIntrospectionCacheTTL is how long introspection results are cached when no TTL is configured.
+	defaultIntrospectionCacheTTL = 30 * time.Second
+	// introspectionTimeout is the maximum time allowed for an introspection request.
+	introspectionTimeout = 10 * time.Second
+)
+
+// introspectionResponse is the subset of the RFC 7662 introspection response used to validate tokens.
+// https://datatracker.ietf.org/doc/html/rfc7662#section-2.2
+type introspectionResponse struct {
+	Active bool   `json:"active"`
+	Scope  string `json:"scope"`
+	Issuer string `json:"iss"`
+	Exp    int64  `json:"exp"`
+}
+
+// introspectionCacheEntry holds the cached validation result of a token, err is nil when the token is valid.
+type introspectionCacheEntry struct {
+	err       error
+	expiresAt time.Time
+}
+
+// introspectionCache caches introspection results by token hash, so the introspection
+// endpoint isn't called on every request.
+type introspectionCache struct {
+	mu      sync.Mutex
+	entries map[string]introspectionCacheEntry
+}
+
+// get returns the cached result for the token, and whether a non expired result was found.
+func (c *introspectionCache) get(key string, now time.Time) (error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if now.After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.err, true
+}
+
+// set stores the result for the token until expiresAt, removing any other expired entries.
+func (c *introspectionCache) set(key string, err error, expiresAt time.Time, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]introspectionCacheEntry{}
+	}
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = introspectionCacheEntry{err: err, expiresAt: expiresAt}
+}
+
+// introspectToken validates the token by calling the introspection endpoint. Results are cached
+// for IntrospectionCacheTTL, or until the token expires if that happens first.
+func (c *OAuthConfig) introspectToken(ctx context.Context, token string) error {
+	now := time.Now()
+	hash := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(hash[:])
+	if err, ok := c.introspectionCache.get(key, now); ok {
+		return err
+	}
+
+	resp, err := c.callIntrospectionEndpoint(ctx, token)
+	if err != nil {
+		// errors calling the endpoint aren't cached, so the next request retries
+		zap.L().Error("Failed to introspect token", zap.Error(err))
+		return errInvalidToken
+	}
+
+	err = c.validateIntrospectionResponse(resp, now)
+	ttl := c.IntrospectionCacheTTL
+	if ttl <= 0 {
+		ttl = defaultIntrospectionCacheTTL
+	}
+	expiresAt := now.Add(ttl)
+	if resp.Exp > 0 && time.Unix(resp.Exp, 0).Before(expiresAt) {
+		expiresAt = time.Unix(resp.Exp, 0)
+	}
+	c.introspectionCache.set(key, err, expiresAt, now)
+
+	return err
+}
+
+// callIntrospectionEndpoint sends the token to the introspection endpoint authenticating with the client credentials.
+func (c *OAuthConfig) callIntrospectionEndpoint(ctx context.Context, token string) (*introspectionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, introspectionTimeout)
+	defer cancel()
+
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.IntrospectionClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(c.IntrospectionClientID), url.QueryEscape(c.IntrospectionClientSecret))
+	}
+
+	client := &http.Client{}
+	if c.InsecureTLS {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.InsecureTLS},
+		}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call introspection endpoint: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", res.StatusCode)
+	}
+
+	var resp introspectionResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// validateIntrospectionResponse checks that the token is active, was issued by the configured
+// authorization server, hasn't expired and contains all of the SupportedScopes.
+func (c *OAuthConfig) validateIntrospectionResponse(resp *introspectionResponse, now time.Time) error {
+	if !resp.Active {
+		zap.L().Error("Inactive token")
+		return errInvalidToken
+	}
+
+	if resp.Issuer != "" && resp.Issuer != c.AuthorizationServerURL {
+		zap.L().Error("Invalid token issuer", zap.String("iss", resp.Issuer))
+		return errInvalidToken
+	}
+
+	if resp.Exp > 0 && now.After(time.Unix(resp.Exp, 0).Add(expirationLeeway)) {
+		zap.L().Error("Expired token")
+		return errInvalidToken
+	}
+
+	tokenScopes := strings.Fields(resp.Scope)
+	for _, scope := range c.SupportedScopes {
+		if !slices.Contains(tokenScopes, scope) {
+			zap.L().Error("Insufficient scope")
+			return errInsufficientScope
+		}
+	}
+
+	return nil
+}
+
+// isJWT reports whether the token has the structure of a JWT (three dot-separated segments).
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
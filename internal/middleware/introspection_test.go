@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testOpaqueToken  = "opaque-token"
+	testClientID     = "mcp-client"
+	testClientSecret = "mcp-secret"
+)
+
+// createFakeIntrospectionServer creates a fake introspection endpoint that returns the provided
+// response for testOpaqueToken and an inactive response for any other token. The number of calls
+// is recorded in calls.
+func createFakeIntrospectionServer(t *testing.T, resp map[string]any, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		body := map[string]any{"active": false}
+		if r.PostForm.Get("token") == testOpaqueToken {
+			body = resp
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			t.Errorf("encoding the introspection response: %s", err)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestOAuthMiddlewareIntrospection(t *testing.T) {
+	tests := map[string]struct {
+		resp         map[string]any
+		token        string
+		clientSecret string
+		expectedCode int
+	}{
+		"active token": {
+			resp:         map[string]any{"active": true, "scope": "openid " + testScope, "iss": testAuthServerURL, "exp": time.Now().Add(time.Hour).Unix()},
+			token:        testOpaqueToken,
+			clientSecret: testClientSecret,
+			expectedCode: http.StatusOK,
+		},
+		"active token without issuer": {
+			resp:         map[string]any{"active": true, "scope": testScope},
+			token:        testOpaqueToken,
+			clientSecret: testClientSecret,
+			expectedCode: http.StatusOK,
+		},
+		"inactive token": {
+			resp:         map[string]any{"active": true, "scope": testScope},
+			token:        "unknown-token",
+			clientSecret: testClientSecret,
+			expectedCode: http.StatusUnauthorized,
+		},
+		"wrong issuer": {
+			resp:         map[string]any{"active": true, "scope": testScope, "iss": testWrongAuthURL},
+			token:        testOpaqueToken,
+			clientSecret: testClientSecret,
+			expectedCode: http.StatusUnauthorized,
+		},
+		"insufficient scope": {
+			resp:         map[string]any{"active": true, "scope": testInvalidScope},
+			token:        testOpaqueToken,
+			clientSecret: testClientSecret,
+			expectedCode: http.StatusForbidden,
+		},
+		"expired token": {
+			resp:         map[string]any{"active": true, "scope": testScope, "exp": time.Now().Add(-time.Hour).Unix()},
+			token:        testOpaqueToken,
+			clientSecret: testClientSecret,
+			expectedCode: http.StatusUnauthorized,
+		},
+		"invalid client credentials": {
+			resp:         map[string]any{"active": true, "scope": testScope},
+			token:        testOpaqueToken,
+			clientSecret: "wrong-secret",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := createFakeIntrospectionServer(t, tt.resp, &calls)
+			config := &OAuthConfig{
+				AuthorizationServerURL:    testAuthServerURL,
+				ResourceURL:               testResourceURL,
+				SupportedScopes:           []string{testScope},
+				ValidationMode:            ValidationModeIntrospection,
+				IntrospectionURL:          srv.URL,
+				IntrospectionClientID:     testClientID,
+				IntrospectionClientSecret: tt.clientSecret,
+			}
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+
+			config.OAuthMiddleware(testHandler()).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, rr.Code)
+			}
+			if tt.expectedCode == http.StatusOK && rr.Body.String() != "success with token "+tt.token {
+				t.Errorf("Expected token in context, got body %q", rr.Body)
+			}
+		})
+	}
+}
+
+func TestOAuthMiddlewareIntrospectionCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := createFakeIntrospectionServer(t, map[string]any{"active": true, "scope": testScope}, &calls)
+	config := &OAuthConfig{
+		AuthorizationServerURL:    testAuthServerURL,
+		ResourceURL:               testResourceURL,
+		SupportedScopes:           []string{testScope},
+		ValidationMode:            ValidationModeIntrospection,
+		IntrospectionURL:          srv.URL,
+		IntrospectionClientID:     testClientID,
+		IntrospectionClientSecret: testClientSecret,
+		IntrospectionCacheTTL:     time.Minute,
+	}
+	handler := config.OAuthMiddleware(testHandler())
+
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+testOpaqueToken)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rr.Code)
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected introspection endpoint to be called once, got %d", calls.Load())
+	}
+}
+
+func TestOAuthMiddlewareHybridValidation(t *testing.T) {
+	config := setupTestConfig(t, privateKey)
+	var calls atomic.Int32
+	srv := createFakeIntrospectionServer(t, map[string]any{"active": true, "scope": testScope}, &calls)
+	config.ValidationMode = ValidationModeHybrid
+	config.IntrospectionURL = srv.URL
+	config.IntrospectionClientID = testClientID
+	config.IntrospectionClientSecret = testClientSecret
+	handler := config.OAuthMiddleware(testHandler())
+
+	jwtToken := createTestToken(t, privateKey, jwt.MapClaims{
+		"iss":   config.AuthorizationServerURL,
+		"aud":   config.ResourceURL,
+		"scope": []any{testScope},
+		"exp":   time.Now().Add(1 * time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	})
+
+	for _, token := range []string{jwtToken, testOpaqueToken} {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rr.Code)
+		}
+	}
+
+	// only the opaque token is sent to the introspection endpoint
+	if calls.Load() != 1 {
+		t.Errorf("Expected introspection endpoint to be called once, got %d", calls.Load())
+	}
+}
+
+func TestOAuthMiddlewareIntrospectionURLNotConfigured(t *testing.T) {
+	config := &OAuthConfig{
+		AuthorizationServerURL: testAuthServerURL,
+		ResourceURL:            testResourceURL,
+		SupportedScopes:        []string{testScope},
+		ValidationMode:         ValidationModeIntrospection,
+	}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+testOpaqueToken)
+	rr := httptest.NewRecorder()
+
+	config.OAuthMiddleware(testHandler()).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rr.Code)
+	}
+}
@@ -15,17 +15,39 @@ import (
 	"github.com/MicahParks/keyfunc/v3"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/modelcontextprotocol/go-sdk/oauthex"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 // expirationLeeway defines the allowed clock skew when validating token expiration.
 const expirationLeeway = 10 * time.Second
 
-// signingMethod defines the JWT signing algorithm accepted by this server.
-const signingMethod = "RS256"
+// The default claims of the Rancher user and its groups.
+const (
+	defaultUserClaim   = "sub"
+	defaultGroupsClaim = "groups"
+)
 
-// tokenHeader is an alternative header with a token
-const tokenHeader = "R_token"
+// defaultSigningMethods defines the JWT signing algorithms accepted by this server when none are configured.
+var defaultSigningMethods = []string{"RS256"}
+
+// supportedSigningMethods defines the asymmetric JWT signing algorithms that can be configured.
+// Symmetric algorithms (HS256...) are never accepted as the keys are retrieved from a public JWKS.
+var supportedSigningMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+const (
+	// defaultJWKSRefreshInterval is how often the JWKS is refreshed in the background.
+	defaultJWKSRefreshInterval = time.Hour
+	// defaultJWKSUnknownKIDRefreshInterval is the minimum time between JWKS refreshes
+	// triggered by tokens signed with an unknown key ID.
+	defaultJWKSUnknownKIDRefreshInterval = 5 * time.Minute
+)
 
 // CORS constants for the protected resource metadata endpoint.
 const (
@@ -35,10 +57,16 @@ const (
 )
 
 var (
-	errInvalidToken = errors.New("invalid Bearer token")
-	errMissingToken = errors.New("missing authorization header")
+	errInvalidToken      = errors.New("invalid Bearer token")
+	errMissingToken      = errors.New("missing authorization header")
+	errInsufficientScope = errors.New("the token doesn't have the required scopes")
 )
 
+// bearerMethodsSupported are the methods of sending the Bearer tokens accepted by this server, only the
+// Authorization header.
+// https://datatracker.ietf.org/doc/html/rfc9728#section-2
+var bearerMethodsSupported = []string{"header"}
+
 // NewOAuthConfig creates and returns a new OAuthConfig value.
 func NewOAuthConfig(authorizationServerURL, jwksURL, resourceURL string, supportedScopes []string) *OAuthConfig {
 	return &OAuthConfig{
@@ -62,6 +90,11 @@ type OAuthConfig struct {
 	// ResourceURL is the user-facing URL for this resource server.
 	ResourceURL string
 
+	// ResourceDocumentationURL is the URL of the documentation of this resource server for the developers of the
+	// MCP clients, advertised as resource_documentation in the protected resource metadata.
+	// https://datatracker.ietf.org/doc/html/rfc9728#section-2
+	ResourceDocumentationURL string
+
 	// SupportedScopes is the list of OAuth 2.0 scopes that this resource server supports.
 	// All of the Supported Scopes MUST be in the Auth Token Scope.
 	// https://modelcontextprotocol.io/specification/draft/basic/authorization#scope-selection-strategy
@@ -71,16 +104,79 @@ type OAuthConfig struct {
 	// This should ONLY be used for testing purposes.
 	InsecureTLS bool
 
-	jwks keyfunc.Keyfunc
+	// SigningMethods is the list of JWT signing algorithms accepted by this server (e.g. RS256, ES256, EdDSA).
+	// Defaults to RS256 when empty.
+	SigningMethods []string
+
+	// JWKSRefreshInterval is how often the JWKS is refreshed in the background, so
+	// rotated keys are picked up without a restart. Defaults to 1 hour.
+	JWKSRefreshInterval time.Duration
+
+	// JWKSUnknownKIDRefreshInterval is the minimum time between JWKS refreshes triggered
+	// by a token signed with an unknown key ID. Defaults to 5 minutes.
+	JWKSUnknownKIDRefreshInterval time.Duration
+
+	// ValidationMode defines how bearer tokens are validated. Defaults to ValidationModeJWT.
+	ValidationMode ValidationMode
+
+	// IntrospectionURL is the OAuth token introspection endpoint (RFC 7662), used to
+	// validate opaque tokens.
+	// See introspection_endpoint in https://datatracker.ietf.org/doc/html/rfc8414.
+	IntrospectionURL string
+
+	// IntrospectionClientID and IntrospectionClientSecret are the client credentials
+	// used to authenticate against the introspection endpoint.
+	IntrospectionClientID     string
+	IntrospectionClientSecret string
+
+	// IntrospectionCacheTTL is how long introspection results are cached. Defaults to 30 seconds.
+	IntrospectionCacheTTL time.Duration
+
+	// UserClaim and GroupsClaim are the JWT claims holding the Rancher user and its groups, set in the context
+	// with client.WithUser so the requests impersonate them when the client impersonation is enabled. They
+	// default to sub and groups. Opaque tokens validated with introspection don't set a user.
+	UserClaim   string
+	GroupsClaim string
+
+	jwks               keyfunc.Keyfunc
+	introspectionCache introspectionCache
 }
 
 // LoadJWKS initializes the JWKS client.
+//
+// The keys are cached and refreshed in the background every JWKSRefreshInterval, and
+// whenever a token signed with an unknown key ID is received (rate limited by
+// JWKSUnknownKIDRefreshInterval), so key rotation at the authorization server doesn't
+// require a restart.
 func (c *OAuthConfig) LoadJWKS(ctx context.Context) error {
 	if c.JwksURL == "" {
 		return nil
 	}
 
-	var override keyfunc.Override
+	for _, method := range c.SigningMethods {
+		if !slices.Contains(supportedSigningMethods, method) {
+			return fmt.Errorf("unsupported signing method %q, must be one of %s", method, strings.Join(supportedSigningMethods, ", "))
+		}
+	}
+
+	refreshInterval := c.JWKSRefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	unknownKIDRefreshInterval := c.JWKSUnknownKIDRefreshInterval
+	if unknownKIDRefreshInterval <= 0 {
+		unknownKIDRefreshInterval = defaultJWKSUnknownKIDRefreshInterval
+	}
+
+	override := keyfunc.Override{
+		RefreshInterval:   refreshInterval,
+		RefreshUnknownKID: rate.NewLimiter(rate.Every(unknownKIDRefreshInterval), 1),
+		RefreshErrorHandlerFunc: func(u string) func(ctx context.Context, err error) {
+			return func(ctx context.Context, err error) {
+				zap.L().Error("Failed to refresh JWKS", zap.String("jwksURL", u), zap.Error(err))
+			}
+		},
+	}
 	if c.InsecureTLS {
 		tr := &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.InsecureTLS},
@@ -92,7 +188,10 @@ func (c *OAuthConfig) LoadJWKS(ctx context.Context) error {
 		return fmt.Errorf("failed to create JWKS client: %w", err)
 	}
 	c.jwks = jwks
-	zap.L().Info("Initialized JWKS", zap.String("jwksURL", c.JwksURL))
+	zap.L().Info("Initialized JWKS",
+		zap.String("jwksURL", c.JwksURL),
+		zap.Strings("signingMethods", c.signingMethods()),
+		zap.Duration("refreshInterval", refreshInterval))
 
 	return nil
 }
@@ -102,32 +201,43 @@ func (c *OAuthConfig) OAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// If the token comes in the header no validation is done, it's passed
 		// through directly.
-		if token := r.Header.Get(tokenHeader); token != "" {
+		if token := r.Header.Get(client.TokenHeader); token != "" {
 			next.ServeHTTP(w, r.Clone(WithToken(r.Context(), token)))
 			return
 		}
 
 		// the Keyfunc is only needed to validate Auth tokens.
-		if c.jwks == nil {
+		if c.ValidationMode != ValidationModeIntrospection && c.jwks == nil {
 			zap.L().Error("JWKS not initialized - call LoadJWKS() before using middleware with Auth tokens")
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
 
+		if c.ValidationMode != ValidationModeJWT && c.ValidationMode != "" && c.IntrospectionURL == "" {
+			zap.L().Error("Introspection URL not configured - it is required for the introspection and hybrid validation modes")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
 		token, err := c.extractToken(r)
 		if err != nil {
-			c.sendUnauthorized(w)
+			c.sendUnauthorized(w, err)
 			return
 		}
 
-		if err := c.validateJWT(token); err != nil {
-			c.sendUnauthorized(w)
+		claims, err := c.validateToken(r.Context(), token)
+		if err != nil {
+			c.sendUnauthorized(w, err)
 			return
 		}
 
 		// Authorization successful - proceed to next handler providing
-		// the token in context.
-		next.ServeHTTP(w, r.Clone(WithToken(r.Context(), token)))
+		// the token and its user in context.
+		ctx := WithToken(r.Context(), token)
+		if user, ok := c.user(claims); ok {
+			ctx = client.WithUser(ctx, user)
+		}
+		next.ServeHTTP(w, r.Clone(ctx))
 	})
 }
 
@@ -150,34 +260,89 @@ func (c *OAuthConfig) extractToken(r *http.Request) (string, error) {
 	return tokenString, nil
 }
 
-func (c *OAuthConfig) validateJWT(tokenString string) error {
+// validateToken validates the token according to the ValidationMode, and returns its claims if it's a JWT.
+func (c *OAuthConfig) validateToken(ctx context.Context, token string) (jwt.MapClaims, error) {
+	switch c.ValidationMode {
+	case ValidationModeIntrospection:
+		return nil, c.introspectToken(ctx, token)
+	case ValidationModeHybrid:
+		if isJWT(token) {
+			return c.validateJWT(token)
+		}
+		return nil, c.introspectToken(ctx, token)
+	default:
+		return c.validateJWT(token)
+	}
+}
+
+func (c *OAuthConfig) validateJWT(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, c.jwks.Keyfunc,
-		jwt.WithValidMethods([]string{signingMethod}),
+		jwt.WithValidMethods(c.signingMethods()),
 		jwt.WithLeeway(expirationLeeway),
 		jwt.WithIssuer(c.AuthorizationServerURL),
 	)
 	if err != nil {
 		zap.L().Error("Failed to parse token", zap.Error(err))
-		return errInvalidToken
+		return nil, fmt.Errorf("%w: %w", errInvalidToken, err)
 	}
 
 	if !token.Valid {
 		zap.L().Error("Invalid token")
-		return errInvalidToken
+		return nil, errInvalidToken
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		zap.L().Error("Invalid claims type")
-		return errInvalidToken
+		return nil, errInvalidToken
 	}
 
 	if !c.validateTokenScopes(claims) {
+		// a scope claim that isn't a list is malformed, the client can't fix it by requesting more scopes
+		if _, ok := claims["scope"]; ok {
+			if _, ok := claims["scope"].([]any); !ok {
+				return nil, errInvalidToken
+			}
+		}
 		zap.L().Error("Insufficient scope")
-		return errInvalidToken
+		return nil, errInsufficientScope
 	}
 
-	return nil
+	return claims, nil
+}
+
+// user returns the Rancher user and groups of the claims of a validated JWT.
+func (c *OAuthConfig) user(claims jwt.MapClaims) (client.User, bool) {
+	userClaim, groupsClaim := c.UserClaim, c.GroupsClaim
+	if userClaim == "" {
+		userClaim = defaultUserClaim
+	}
+	if groupsClaim == "" {
+		groupsClaim = defaultGroupsClaim
+	}
+
+	name, _ := claims[userClaim].(string)
+	if name == "" {
+		return client.User{}, false
+	}
+	user := client.User{Name: name}
+	groups, _ := claims[groupsClaim].([]any)
+	for _, group := range groups {
+		if group, ok := group.(string); ok && group != "" {
+			user.Groups = append(user.Groups, group)
+		}
+	}
+
+	return user, true
+}
+
+// signingMethods returns the configured signing methods, or the default ones if none are configured.
+func (c *OAuthConfig) signingMethods() []string {
+	if len(c.SigningMethods) == 0 {
+		return defaultSigningMethods
+	}
+
+	return c.SigningMethods
 }
 
 func (c *OAuthConfig) validateTokenScopes(claims jwt.MapClaims) bool {
@@ -201,8 +366,12 @@ func (c *OAuthConfig) validateTokenScopes(claims jwt.MapClaims) bool {
 	return true
 }
 
-// sendUnauthorized sends a 401 response with WWW-Authenticate header.
-func (c *OAuthConfig) sendUnauthorized(w http.ResponseWriter) {
+// sendUnauthorized sends the WWW-Authenticate challenge of a request that isn't authorized. Following RFC 6750 the
+// requests without a token only get the challenge, the invalid tokens get a 401 response with the invalid_token error
+// and the tokens missing the supported scopes a 403 response with the insufficient_scope error and the scopes to
+// request, so the MCP clients know whether to authenticate again or to ask for more scopes.
+// https://datatracker.ietf.org/doc/html/rfc6750#section-3.1
+func (c *OAuthConfig) sendUnauthorized(w http.ResponseWriter, validationErr error) {
 	metadataURL, err := url.JoinPath(c.ResourceURL, "/.well-known/oauth-protected-resource")
 	if err != nil {
 		zap.L().Error("Failed to construct metadata URL", zap.Error(err))
@@ -210,9 +379,20 @@ func (c *OAuthConfig) sendUnauthorized(w http.ResponseWriter) {
 		return
 	}
 
-	w.Header().Set("WWW-Authenticate",
-		fmt.Sprintf("Bearer resource_metadata=%q", metadataURL))
-	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	challenge := fmt.Sprintf("Bearer resource_metadata=%q", metadataURL)
+	status := http.StatusUnauthorized
+	switch {
+	case errors.Is(validationErr, errMissingToken):
+	case errors.Is(validationErr, errInsufficientScope):
+		status = http.StatusForbidden
+		challenge += fmt.Sprintf(`, error="insufficient_scope", error_description=%q, scope=%q`,
+			validationErr.Error(), strings.Join(c.SupportedScopes, " "))
+	default:
+		challenge += fmt.Sprintf(`, error="invalid_token", error_description=%q`, validationErr.Error())
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(status), status)
 }
 
 // HandleProtectedResourceMetadata handles the protected resource metadata endpoint
@@ -230,9 +410,13 @@ func (c *OAuthConfig) HandleProtectedResourceMetadata(w http.ResponseWriter, r *
 	}
 
 	metadata := oauthex.ProtectedResourceMetadata{
-		Resource:             c.ResourceURL,
-		ScopesSupported:      c.SupportedScopes,
-		AuthorizationServers: []string{c.AuthorizationServerURL},
+		Resource:               c.ResourceURL,
+		ScopesSupported:        c.SupportedScopes,
+		BearerMethodsSupported: bearerMethodsSupported,
+		ResourceDocumentation:  c.ResourceDocumentationURL,
+	}
+	if c.AuthorizationServerURL != "" {
+		metadata.AuthorizationServers = append(metadata.AuthorizationServers, c.AuthorizationServerURL)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
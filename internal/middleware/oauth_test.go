@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/base64"
@@ -14,7 +18,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MicahParks/jwkset"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
 )
 
 const (
@@ -309,6 +315,36 @@ func TestOAuthMiddlewareInvalidScope(t *testing.T) {
 	}
 }
 
+func TestOAuthMiddlewareInsufficientScope(t *testing.T) {
+	config := setupTestConfig(t, privateKey)
+	claims := jwt.MapClaims{
+		"iss":   config.AuthorizationServerURL,
+		"aud":   config.ResourceURL,
+		"scope": []any{testInvalidScope},
+		"exp":   time.Now().Add(1 * time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+
+	token := createTestToken(t, privateKey, claims)
+
+	handler := config.OAuthMiddleware(testHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rr.Code)
+	}
+
+	expectedHeader := fmt.Sprintf(`Bearer resource_metadata=%q, error="insufficient_scope", error_description="the token doesn't have the required scopes", scope=%q`,
+		config.ResourceURL+"/.well-known/oauth-protected-resource", strings.Join(config.SupportedScopes, " "))
+	if authHeader := rr.Header().Get("WWW-Authenticate"); authHeader != expectedHeader {
+		t.Errorf("Expected WWW-Authenticate header %q, got %q", expectedHeader, authHeader)
+	}
+}
+
 func TestOAuthMiddlewareExpiredToken(t *testing.T) {
 	config := setupTestConfig(t, privateKey)
 
@@ -445,6 +481,76 @@ func TestOAuthMiddlewareMultipleScopes(t *testing.T) {
 	}
 }
 
+func TestOAuthMiddlewareUser(t *testing.T) {
+	tests := map[string]struct {
+		userClaim    string
+		groupsClaim  string
+		claims       jwt.MapClaims
+		legacyHeader bool
+		expectedUser *client.User
+	}{
+		"default claims": {
+			claims:       jwt.MapClaims{"sub": "u-abc", "groups": []any{"local://g-1", "github_team://2"}},
+			expectedUser: &client.User{Name: "u-abc", Groups: []string{"local://g-1", "github_team://2"}},
+		},
+		"custom claims": {
+			userClaim:    "rancher_user",
+			groupsClaim:  "rancher_groups",
+			claims:       jwt.MapClaims{"sub": "someone", "rancher_user": "u-abc", "rancher_groups": []any{"local://g-1"}},
+			expectedUser: &client.User{Name: "u-abc", Groups: []string{"local://g-1"}},
+		},
+		"no user claim": {
+			claims: jwt.MapClaims{"groups": []any{"local://g-1"}},
+		},
+		"legacy token header": {
+			claims:       jwt.MapClaims{"sub": "u-abc"},
+			legacyHeader: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := setupTestConfig(t, privateKey)
+			config.UserClaim = tt.userClaim
+			config.GroupsClaim = tt.groupsClaim
+			claims := jwt.MapClaims{
+				"iss":   config.AuthorizationServerURL,
+				"aud":   config.ResourceURL,
+				"scope": []any{testScope},
+				"exp":   time.Now().Add(1 * time.Hour).Unix(),
+				"iat":   time.Now().Unix(),
+			}
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+			token := createTestToken(t, privateKey, claims)
+			var user *client.User
+			handler := config.OAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if u, ok := client.UserFrom(r.Context()); ok {
+					user = &u
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.legacyHeader {
+				req.Header.Set("R_token", token)
+			} else {
+				req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", rr.Code)
+			}
+			if !reflect.DeepEqual(user, tt.expectedUser) {
+				t.Errorf("Expected user %+v, got %+v", tt.expectedUser, user)
+			}
+		})
+	}
+}
+
 func TestHandleProtectedResourceMetadata(t *testing.T) {
 	config := &OAuthConfig{
 		AuthorizationServerURL: testAuthServerURL,
@@ -484,6 +590,40 @@ func TestHandleProtectedResourceMetadata(t *testing.T) {
 	if metadata["resource"] != config.ResourceURL {
 		t.Errorf("Expected resource '%s', got '%v'", config.ResourceURL, metadata["resource"])
 	}
+
+	if servers := metadata["authorization_servers"]; !reflect.DeepEqual(servers, []any{testAuthServerURL}) {
+		t.Errorf("Expected authorization_servers [%s], got %v", testAuthServerURL, servers)
+	}
+
+	if methods := metadata["bearer_methods_supported"]; !reflect.DeepEqual(methods, []any{"header"}) {
+		t.Errorf("Expected bearer_methods_supported [header], got %v", methods)
+	}
+
+	if _, ok := metadata["resource_documentation"]; ok {
+		t.Errorf("Expected no resource_documentation when not configured, got %v", metadata["resource_documentation"])
+	}
+}
+
+func TestHandleProtectedResourceMetadataDocumentation(t *testing.T) {
+	config := &OAuthConfig{
+		AuthorizationServerURL:   testAuthServerURL,
+		ResourceURL:              testResourceURL,
+		ResourceDocumentationURL: testResourceURL + "/docs",
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/oauth-protected-resource", nil)
+	rr := httptest.NewRecorder()
+
+	config.HandleProtectedResourceMetadata(rr, req)
+
+	var metadata map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&metadata); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if metadata["resource_documentation"] != config.ResourceDocumentationURL {
+		t.Errorf("Expected resource_documentation '%s', got '%v'", config.ResourceDocumentationURL, metadata["resource_documentation"])
+	}
 }
 
 func TestHandleProtectedResourceMetadataOPTIONS(t *testing.T) {
@@ -804,13 +944,57 @@ func TestSendUnauthorizedURLJoinError(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	config.sendUnauthorized(rr)
+	config.sendUnauthorized(rr, errInvalidToken)
 
 	if rr.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status 500 for URL join error, got %d", rr.Code)
 	}
 }
 
+func TestSendUnauthorizedChallenges(t *testing.T) {
+	config := &OAuthConfig{
+		ResourceURL:     testResourceURL,
+		SupportedScopes: []string{testCustomScope1, testCustomScope2},
+	}
+	metadataURL := testResourceURL + "/.well-known/oauth-protected-resource"
+
+	tests := map[string]struct {
+		err            error
+		expectedCode   int
+		expectedHeader string
+	}{
+		"missing token": {
+			err:            errMissingToken,
+			expectedCode:   http.StatusUnauthorized,
+			expectedHeader: fmt.Sprintf(`Bearer resource_metadata=%q`, metadataURL),
+		},
+		"invalid token": {
+			err:            fmt.Errorf("%w: token has invalid claims: token is expired", errInvalidToken),
+			expectedCode:   http.StatusUnauthorized,
+			expectedHeader: fmt.Sprintf(`Bearer resource_metadata=%q, error="invalid_token", error_description="invalid Bearer token: token has invalid claims: token is expired"`, metadataURL),
+		},
+		"insufficient scope": {
+			err:            errInsufficientScope,
+			expectedCode:   http.StatusForbidden,
+			expectedHeader: fmt.Sprintf(`Bearer resource_metadata=%q, error="insufficient_scope", error_description="the token doesn't have the required scopes", scope="api:read api:write"`, metadataURL),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			config.sendUnauthorized(rr, tt.err)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, rr.Code)
+			}
+			if authHeader := rr.Header().Get("WWW-Authenticate"); authHeader != tt.expectedHeader {
+				t.Errorf("Expected WWW-Authenticate header %q, got %q", tt.expectedHeader, authHeader)
+			}
+		})
+	}
+}
+
 func TestHandleProtectedResourceMetadataCORSHeaders(t *testing.T) {
 	config := &OAuthConfig{
 		AuthorizationServerURL: testAuthServerURL,
@@ -939,3 +1123,190 @@ func mustGenerateRSAKey(l int) *rsa.PrivateKey {
 
 	return key
 }
+
+func TestOAuthMiddlewareSigningMethods(t *testing.T) {
+	es256Key := mustGenerateECDSAKey(elliptic.P256())
+	es384Key := mustGenerateECDSAKey(elliptic.P384())
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+
+	tests := map[string]struct {
+		signingMethods []string
+		method         jwt.SigningMethod
+		kid            string
+		alg            jwkset.ALG
+		key            crypto.Signer
+		expectedCode   int
+	}{
+		"ES256 token accepted": {
+			signingMethods: []string{"RS256", "ES256"},
+			method:         jwt.SigningMethodES256,
+			kid:            "es256-key",
+			alg:            jwkset.AlgES256,
+			key:            es256Key,
+			expectedCode:   http.StatusOK,
+		},
+		"ES384 token accepted": {
+			signingMethods: []string{"ES384"},
+			method:         jwt.SigningMethodES384,
+			kid:            "es384-key",
+			alg:            jwkset.AlgES384,
+			key:            es384Key,
+			expectedCode:   http.StatusOK,
+		},
+		"EdDSA token accepted": {
+			signingMethods: []string{"EdDSA"},
+			method:         jwt.SigningMethodEdDSA,
+			kid:            "eddsa-key",
+			alg:            jwkset.AlgEdDSA,
+			key:            edKey,
+			expectedCode:   http.StatusOK,
+		},
+		"ES256 token rejected with default signing methods": {
+			method:       jwt.SigningMethodES256,
+			kid:          "es256-key",
+			alg:          jwkset.AlgES256,
+			key:          es256Key,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := jwkset.NewMemoryStorage()
+			writeTestJWK(t, storage, tt.key.Public(), tt.kid, tt.alg)
+			srv := createJWKSetServer(t, storage)
+			config := &OAuthConfig{
+				AuthorizationServerURL: testAuthServerURL,
+				JwksURL:                srv.URL,
+				ResourceURL:            testResourceURL,
+				SupportedScopes:        []string{testScope},
+				SigningMethods:         tt.signingMethods,
+			}
+			if err := config.LoadJWKS(t.Context()); err != nil {
+				t.Fatalf("Failed to initialize JWKS: %v", err)
+			}
+
+			token := createSignedTestToken(t, tt.method, tt.kid, tt.key)
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			config.OAuthMiddleware(testHandler()).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestLoadJWKSUnsupportedSigningMethod(t *testing.T) {
+	config := &OAuthConfig{
+		JwksURL:        testAuthServerURL + "/jwks.json",
+		SigningMethods: []string{"RS256", "HS256"},
+	}
+
+	err := config.LoadJWKS(t.Context())
+	if err == nil {
+		t.Fatal("Expected error for unsupported signing method")
+	}
+	if !strings.Contains(err.Error(), `unsupported signing method "HS256"`) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestOAuthMiddlewareJWKSKeyRotation(t *testing.T) {
+	storage := jwkset.NewMemoryStorage()
+	writeTestJWK(t, storage, privateKey.Public(), "test-key-id", jwkset.AlgRS256)
+	srv := createJWKSetServer(t, storage)
+	config := &OAuthConfig{
+		AuthorizationServerURL: testAuthServerURL,
+		JwksURL:                srv.URL,
+		ResourceURL:            testResourceURL,
+		SupportedScopes:        []string{testScope},
+	}
+	if err := config.LoadJWKS(t.Context()); err != nil {
+		t.Fatalf("Failed to initialize JWKS: %v", err)
+	}
+
+	// the authorization server rotates its signing key after the JWKS was loaded
+	rotatedKey := mustGenerateRSAKey(2048)
+	writeTestJWK(t, storage, rotatedKey.Public(), "rotated-key-id", jwkset.AlgRS256)
+
+	token := createSignedTestToken(t, jwt.SigningMethodRS256, "rotated-key-id", rotatedKey)
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+
+	config.OAuthMiddleware(testHandler()).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200 after key rotation, got %d", rr.Code)
+	}
+}
+
+// createJWKSetServer creates a JWKS server that returns the public keys in the storage,
+// keys can be added to the storage while the server is running to simulate key rotation.
+func createJWKSetServer(t *testing.T, storage jwkset.Storage) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := storage.JSONPublic(r.Context())
+		if err != nil {
+			t.Errorf("encoding the jwks: %s", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(raw)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// writeTestJWK adds the public key to the storage with the given key ID and algorithm.
+func writeTestJWK(t *testing.T, storage jwkset.Storage, key crypto.PublicKey, kid string, alg jwkset.ALG) {
+	t.Helper()
+	jwk, err := jwkset.NewJWKFromKey(key, jwkset.JWKOptions{
+		Metadata: jwkset.JWKMetadataOptions{KID: kid, ALG: alg, USE: jwkset.UseSig},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create JWK: %v", err)
+	}
+	if err := storage.KeyWrite(t.Context(), jwk); err != nil {
+		t.Fatalf("Failed to write JWK: %v", err)
+	}
+}
+
+// createSignedTestToken creates a valid JWT token signed with the given method and key.
+func createSignedTestToken(t *testing.T, method jwt.SigningMethod, kid string, key crypto.Signer) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"iss":   testAuthServerURL,
+		"aud":   testResourceURL,
+		"scope": []any{testScope},
+		"exp":   time.Now().Add(1 * time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	})
+	token.Header["kid"] = kid
+
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	return tokenString
+}
+
+func mustGenerateECDSAKey(curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	return key
+}
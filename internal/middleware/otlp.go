@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// otlpQueueSize is the number of spans waiting to be exported, the spans are dropped when the queue is full.
+	otlpQueueSize = 2048
+	// otlpBatchSize is the number of spans sent at most in an export request.
+	otlpBatchSize = 512
+	// otlpFlushInterval is how often the queued spans are exported.
+	otlpFlushInterval = 5 * time.Second
+	// otlpExportTimeout is the time limit of an export request.
+	otlpExportTimeout = 10 * time.Second
+)
+
+// The OTLP span kind and status codes.
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+const (
+	otlpSpanKindServer  = 2
+	otlpStatusCodeOK    = 1
+	otlpStatusCodeError = 2
+)
+
+// Span is the span of an MCP request.
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+	// Error is the error of the request, empty if it succeeded.
+	Error string
+}
+
+// SpanExporter exports the spans of the MCP requests. Export must not block the request.
+type SpanExporter interface {
+	Export(span Span)
+}
+
+// OTLPExporter is a SpanExporter sending the spans in batches to an OpenTelemetry collector with OTLP over HTTP,
+// in the JSON encoding.
+// https://opentelemetry.io/docs/specs/otlp/#otlphttp
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	httpClient  *http.Client
+	queue       chan Span
+}
+
+// NewOTLPExporter creates an OTLPExporter sending the spans of the service to the traces endpoint, e.g.
+// http://otel-collector:4318/v1/traces. The spans are only sent once Start is called.
+func NewOTLPExporter(endpoint string, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		httpClient:  &http.Client{Timeout: otlpExportTimeout},
+		queue:       make(chan Span, otlpQueueSize),
+	}
+}
+
+// Export queues the span to be sent, it's dropped if the queue is full.
+func (e *OTLPExporter) Export(span Span) {
+	select {
+	case e.queue <- span:
+	default:
+		zap.L().Warn("dropping span, the OTLP export queue is full", zap.String("traceId", span.TraceID))
+	}
+}
+
+// Start sends the queued spans until the context is done, the spans still queued are then sent.
+func (e *OTLPExporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Span, 0, otlpBatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(ctx, batch); err != nil {
+			zap.L().Warn("failed to export spans", zap.Int("spans", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == otlpBatchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+			defer cancel()
+			for {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+					if len(batch) == otlpBatchSize {
+						flush(shutdownCtx)
+					}
+				default:
+					flush(shutdownCtx)
+					return
+				}
+			}
+		}
+	}
+}
+
+// send exports the spans in an OTLP request.
+func (e *OTLPExporter) send(ctx context.Context, spans []Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the collector returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+type otlpKeyValue struct {
+	Key   string          `json:"key"`
+	Value otlpStringValue `json:"value"`
+}
+
+type otlpStringValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// request returns the OTLP export request of the spans.
+func (e *OTLPExporter) request(spans []Span) otlpTraceRequest {
+	scopeSpans := otlpScopeSpans{Spans: make([]otlpSpan, 0, len(spans))}
+	scopeSpans.Scope.Name = "github.com/rancher/rancher-ai-mcp"
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              otlpSpanKindServer,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusCodeOK},
+		}
+		if span.Error != "" {
+			s.Status = otlpStatus{Code: otlpStatusCodeError, Message: span.Error}
+		}
+		for _, key := range slices.Sorted(maps.Keys(span.Attributes)) {
+			s.Attributes = append(s.Attributes, otlpKeyValue{Key: key, Value: otlpStringValue{StringValue: span.Attributes[key]}})
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, s)
+	}
+
+	resourceSpans := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{scopeSpans}}
+	resourceSpans.Resource.Attributes = []otlpKeyValue{{Key: "service.name", Value: otlpStringValue{StringValue: e.serviceName}}}
+
+	return otlpTraceRequest{ResourceSpans: []otlpResourceSpans{resourceSpans}}
+}
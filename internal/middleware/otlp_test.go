@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan otlpTraceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON request, got %q", r.Header.Get("Content-Type"))
+		}
+		var request otlpTraceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode the request: %v", err)
+		}
+		received <- request
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, "rancher-mcp-server")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Start(ctx)
+		close(done)
+	}()
+	start := time.Unix(1700000000, 0)
+	exporter.Export(Span{
+		TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:       "00f067aa0ba902b7",
+		ParentSpanID: "b7ad6b7169203331",
+		Name:         "tools/call inspectPod",
+		Start:        start,
+		End:          start.Add(time.Second),
+		Attributes:   map[string]string{"mcp.method.name": "tools/call", "gen_ai.tool.name": "inspectPod"},
+		Error:        "pod not found",
+	})
+	// the spans still queued are sent when the exporter stops
+	cancel()
+	<-done
+
+	var request otlpTraceRequest
+	select {
+	case request = <-received:
+	default:
+		t.Fatal("expected the span to be exported")
+	}
+	if len(request.ResourceSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans[0].Spans) != 1 {
+		t.Fatalf("expected 1 span, got %+v", request)
+	}
+	if attributes := request.ResourceSpans[0].Resource.Attributes; len(attributes) != 1 || attributes[0].Value.StringValue != "rancher-mcp-server" {
+		t.Errorf("expected the service.name of the resource, got %+v", attributes)
+	}
+	span := request.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	expected := otlpSpan{
+		TraceID:           "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:            "00f067aa0ba902b7",
+		ParentSpanID:      "b7ad6b7169203331",
+		Name:              "tools/call inspectPod",
+		Kind:              otlpSpanKindServer,
+		StartTimeUnixNano: "1700000000000000000",
+		EndTimeUnixNano:   "1700000001000000000",
+		Status:            otlpStatus{Code: otlpStatusCodeError, Message: "pod not found"},
+		Attributes: []otlpKeyValue{
+			{Key: "gen_ai.tool.name", Value: otlpStringValue{StringValue: "inspectPod"}},
+			{Key: "mcp.method.name", Value: otlpStringValue{StringValue: "tools/call"}},
+		},
+	}
+	if !reflect.DeepEqual(span, expected) {
+		t.Errorf("expected span %+v, got %+v", expected, span)
+	}
+}
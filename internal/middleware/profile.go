@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ResponseProfileArg is the argument of the tool calls overriding the response profile of the server.
+const ResponseProfileArg = "responseProfile"
+
+// ResponseProfileMiddleware returns an MCP middleware letting the tool calls override the response profile of the
+// server, which controls the fields of the resources kept in the responses. The responseProfile argument is added
+// to the input schema of the tools when they are listed, and removed from the arguments before they are run, with
+// its profile set in the context of the call. It must run before ConfirmationMiddleware, so the profile isn't part
+// of the arguments that are confirmed.
+func ResponseProfileMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch req := req.(type) {
+			case *mcp.ListToolsRequest:
+				result, err := next(ctx, method, req)
+				if err != nil {
+					return result, err
+				}
+				if listResult, ok := result.(*mcp.ListToolsResult); ok {
+					addProfileArg(listResult)
+				}
+				return result, nil
+			case *mcp.CallToolRequest:
+				return callToolWithProfile(ctx, method, req, next)
+			default:
+				return next(ctx, method, req)
+			}
+		}
+	}
+}
+
+// callToolWithProfile runs the tool without the responseProfile argument, with its profile set in the context.
+func callToolWithProfile(ctx context.Context, method string, req *mcp.CallToolRequest, next mcp.MethodHandler) (mcp.Result, error) {
+	arguments := map[string]any{}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &arguments); err != nil {
+			return response.CreateMcpErrorResult(apierrors.NewBadRequest(fmt.Sprintf("invalid arguments: %v", err))), nil
+		}
+	}
+	value, ok := arguments[ResponseProfileArg]
+	if !ok {
+		return next(ctx, method, req)
+	}
+	name, _ := value.(string)
+	profile, err := response.ParseProfile(name)
+	if err != nil {
+		return response.CreateMcpErrorResult(apierrors.NewBadRequest(err.Error())), nil
+	}
+	delete(arguments, ResponseProfileArg)
+	stripped, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+
+	params := *req.Params
+	params.Arguments = stripped
+	shaped := *req
+	shaped.Params = &params
+
+	return next(response.WithProfile(ctx, profile), method, &shaped)
+}
+
+// addProfileArg adds the responseProfile argument to the input schema of the tools. The tools are copied, since the
+// result holds the tools registered in the server.
+func addProfileArg(result *mcp.ListToolsResult) {
+	for i, tool := range result.Tools {
+		data, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			zap.L().Warn("failed to marshal input schema", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+		schema := map[string]any{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			zap.L().Warn("failed to unmarshal input schema", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		if properties == nil {
+			properties = map[string]any{}
+		}
+		properties[ResponseProfileArg] = map[string]any{
+			"type": "string",
+			"enum": response.Profiles,
+			"description": "the fields of the Kubernetes resources returned by the tool: full for the whole resources, summary for their spec " +
+				"without their status, or minimal for their name and key status. Defaults to the response profile of the server",
+		}
+		schema["properties"] = properties
+
+		copied := *tool
+		copied.InputSchema = schema
+		result.Tools[i] = &copied
+	}
+}
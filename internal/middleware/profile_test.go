@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestResponseProfileMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		arguments         map[string]any
+		expectedArguments map[string]any
+		expectedLLM       string
+		expectedError     string
+	}{
+		"profile of the server": {
+			arguments:         map[string]any{"name": "web"},
+			expectedArguments: map[string]any{"name": "web"},
+			expectedLLM:       `[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"web","namespace":"default"},"spec":{"nodeName":"node-1"},"status":{"phase":"Running"}}]`,
+		},
+		"profile of the tool call": {
+			arguments:         map[string]any{"name": "web", ResponseProfileArg: "minimal"},
+			expectedArguments: map[string]any{"name": "web"},
+			expectedLLM:       `[{"kind":"Pod","name":"web","namespace":"default","status":"Running"}]`,
+		},
+		"invalid profile": {
+			arguments:     map[string]any{"name": "web", ResponseProfileArg: "compact"},
+			expectedError: `invalid response profile \"compact\", must be full, summary or minimal`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var run map[string]any
+			next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				require.NoError(t, json.Unmarshal(req.(*mcp.CallToolRequest).Params.Arguments, &run))
+				text, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{{Object: map[string]any{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"metadata":   map[string]any{"name": "web", "namespace": "default"},
+					"spec":       map[string]any{"nodeName": "node-1"},
+					"status":     map[string]any{"phase": "Running"},
+				}}}, "local")
+				require.NoError(t, err)
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+			}
+			handler := ResponseProfileMiddleware()(next)
+			data, err := json.Marshal(test.arguments)
+			require.NoError(t, err)
+
+			result, err := handler(t.Context(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "getKubernetesResource", Arguments: data}})
+
+			require.NoError(t, err)
+			callResult := result.(*mcp.CallToolResult)
+			text := callResult.Content[0].(*mcp.TextContent).Text
+			if test.expectedError != "" {
+				assert.True(t, callResult.IsError)
+				assert.Contains(t, text, test.expectedError)
+				return
+			}
+			assert.Equal(t, test.expectedArguments, run)
+			var resp struct {
+				LLM json.RawMessage `json:"llm"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(text), &resp))
+			assert.JSONEq(t, test.expectedLLM, string(resp.LLM))
+		})
+	}
+}
+
+func TestResponseProfileMiddlewareListTools(t *testing.T) {
+	schema := map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}}
+	registered := []*mcp.Tool{
+		{Name: "getKubernetesResource", InputSchema: schema},
+		{Name: "listKubernetesResources", InputSchema: schema},
+	}
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.ListToolsResult{Tools: slices.Clone(registered)}, nil
+	}
+	handler := ResponseProfileMiddleware()(next)
+
+	result, err := handler(t.Context(), "tools/list", &mcp.ListToolsRequest{})
+
+	require.NoError(t, err)
+	tools := result.(*mcp.ListToolsResult).Tools
+	require.Len(t, tools, 2)
+	for _, tool := range tools {
+		assert.Contains(t, tool.InputSchema.(map[string]any)["properties"], ResponseProfileArg)
+	}
+	assert.NotContains(t, schema["properties"], ResponseProfileArg, "the registered tools should not be modified")
+}
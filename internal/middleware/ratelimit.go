@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxIdleUserLimiters is the number of user limiters kept before the ones of idle users are removed.
+const maxIdleUserLimiters = 1000
+
+// RateLimitConfig configures the rate limits of the tool calls. A zero rate disables the limit.
+type RateLimitConfig struct {
+	// UserRate is the number of tool calls per second allowed for each user.
+	UserRate float64
+	// UserBurst is the number of tool calls a user can make at once.
+	UserBurst int
+	// GlobalRate is the number of tool calls per second allowed for all the users.
+	GlobalRate float64
+	// GlobalBurst is the number of tool calls that can be made at once by all the users.
+	GlobalBurst int
+}
+
+// rateLimiter enforces the limits of a RateLimitConfig.
+type rateLimiter struct {
+	config RateLimitConfig
+	global *rate.Limiter
+
+	mu    sync.Mutex
+	users map[string]*rate.Limiter
+}
+
+// RateLimitMiddleware returns an MCP middleware that limits the rate of the tool calls of each user and of all of
+// them. Users are identified by the user of the claims validated by OAuthMiddleware, or by their token otherwise, so
+// it must run after CredentialsMiddleware. Calls over the limit fail with a structured TooManyRequests error telling the LLM
+// when to retry, instead of reaching Rancher.
+func RateLimitMiddleware(config RateLimitConfig) mcp.Middleware {
+	limiter := &rateLimiter{
+		config: config,
+		users:  map[string]*rate.Limiter{},
+	}
+	if config.GlobalRate > 0 {
+		limiter.global = rate.NewLimiter(rate.Limit(config.GlobalRate), max(config.GlobalBurst, 1))
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			toolReq, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+
+			user := userKey(ctx)
+			if delay := limiter.reserve(user); delay > 0 {
+				Logger(ctx).Warn("tool call rate limited", zap.String("tool", toolReq.Params.Name), zap.String("user", user), zap.Duration("retryAfter", delay))
+				retryAfter := int(math.Ceil(delay.Seconds()))
+				return response.CreateMcpErrorResult(apierrors.NewTooManyRequests(fmt.Sprintf("rate limited, retry after %ds", retryAfter), retryAfter)), nil
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// reserve takes a tool call from the limits of the user and the global one. If any of them is exhausted, nothing
+// is taken and the time to wait until the call is allowed is returned.
+func (l *rateLimiter) reserve(user string) time.Duration {
+	now := time.Now()
+	var reservations []*rate.Reservation
+	for _, limiter := range []*rate.Limiter{l.userLimiter(user), l.global} {
+		if limiter == nil {
+			continue
+		}
+		reservation := limiter.ReserveN(now, 1)
+		reservations = append(reservations, reservation)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			return delay
+		}
+	}
+
+	return 0
+}
+
+// userLimiter returns the limiter of the user, or nil if users are not limited.
+func (l *rateLimiter) userLimiter(user string) *rate.Limiter {
+	if l.config.UserRate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.users[user]
+	if !ok {
+		if len(l.users) >= maxIdleUserLimiters {
+			l.removeIdleUsers()
+		}
+		limiter = rate.NewLimiter(rate.Limit(l.config.UserRate), max(l.config.UserBurst, 1))
+		l.users[user] = limiter
+	}
+
+	return limiter
+}
+
+// removeIdleUsers removes the limiters whose burst is full, which behave like new ones.
+func (l *rateLimiter) removeIdleUsers() {
+	for user, limiter := range l.users {
+		if limiter.Tokens() >= float64(limiter.Burst()) {
+			delete(l.users, user)
+		}
+	}
+}
+
+// userKey identifies the user of a tool call for the rate limits, the sessions and the confirmations: by the user
+// of the JWT claims validated by OAuthMiddleware and set in the context, or by the hash of the token otherwise. The
+// claims of the tokens passed through without validation, like the Rancher tokens, aren't read, since anyone can
+// forge them to use the limits, sessions or confirmations of another user.
+func userKey(ctx context.Context) string {
+	if user, ok := client.UserFrom(ctx); ok {
+		return "user:" + user.Name
+	}
+	hash := sha256.Sum256([]byte(Token(ctx)))
+
+	return "token:" + hex.EncodeToString(hash[:8])
+}
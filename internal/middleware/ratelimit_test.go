@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"golang.org/x/time/rate"
+)
+
+func testJWT(t *testing.T, subject string, id string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: subject, ID: id}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		config RateLimitConfig
+		tokens []string
+		// users are the users of the claims validated by OAuthMiddleware, if any.
+		users           []string
+		expectedLimited []bool
+	}{
+		"user limit": {
+			config:          RateLimitConfig{UserRate: 0.01, UserBurst: 2},
+			tokens:          []string{"user-a", "user-a", "user-a", "user-b"},
+			expectedLimited: []bool{false, false, true, false},
+		},
+		"users are identified by their validated claims": {
+			config:          RateLimitConfig{UserRate: 0.01, UserBurst: 1},
+			tokens:          []string{testJWT(t, "u-1", "a"), testJWT(t, "u-1", "b"), testJWT(t, "u-2", "c")},
+			users:           []string{"u-1", "u-1", "u-2"},
+			expectedLimited: []bool{false, true, false},
+		},
+		"the subject of the tokens passed through isn't trusted": {
+			config:          RateLimitConfig{UserRate: 0.01, UserBurst: 1},
+			tokens:          []string{testJWT(t, "u-1", "a"), testJWT(t, "u-1", "b"), testJWT(t, "u-1", "a")},
+			expectedLimited: []bool{false, false, true},
+		},
+		"global limit": {
+			config:          RateLimitConfig{UserRate: 0.01, UserBurst: 5, GlobalRate: 0.01, GlobalBurst: 2},
+			tokens:          []string{"user-a", "user-b", "user-c"},
+			expectedLimited: []bool{false, false, true},
+		},
+		"no limits": {
+			tokens:          []string{"user-a", "user-a", "user-a"},
+			expectedLimited: []bool{false, false, false},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				calls++
+				return &mcp.CallToolResult{}, nil
+			}
+			handler := RateLimitMiddleware(tt.config)(next)
+
+			expectedCalls := 0
+			for i, token := range tt.tokens {
+				req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "getKubernetesResource"}}
+				ctx := WithToken(context.Background(), token)
+				if tt.users != nil {
+					ctx = client.WithUser(ctx, client.User{Name: tt.users[i]})
+				}
+				result, err := handler(ctx, "tools/call", req)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				toolResult := result.(*mcp.CallToolResult)
+				if toolResult.IsError != tt.expectedLimited[i] {
+					t.Fatalf("Call %d: expected limited %v, got %v", i, tt.expectedLimited[i], toolResult.IsError)
+				}
+				if !tt.expectedLimited[i] {
+					expectedCalls++
+					continue
+				}
+				text := toolResult.Content[0].(*mcp.TextContent).Text
+				if !strings.Contains(text, `"reason":"TooManyRequests"`) || !strings.Contains(text, "rate limited, retry after") || !strings.Contains(text, `"retryAfterSeconds"`) {
+					t.Errorf("Call %d: expected a TooManyRequests error with the retry delay, got %s", i, text)
+				}
+			}
+			if calls != expectedCalls {
+				t.Errorf("Expected %d calls to reach the tools, got %d", expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRateLimiterCancelsUserReservation(t *testing.T) {
+	limiter := &rateLimiter{
+		config: RateLimitConfig{UserRate: 0.01, UserBurst: 1},
+		users:  map[string]*rate.Limiter{},
+	}
+	limiter.global = rate.NewLimiter(0.01, 1)
+
+	if delay := limiter.reserve("user-a"); delay != 0 {
+		t.Fatalf("Expected first call to be allowed, got delay %s", delay)
+	}
+	if delay := limiter.reserve("user-b"); delay == 0 {
+		t.Fatal("Expected call over the global limit to be rejected")
+	}
+
+	if tokens := limiter.users["user-b"].Tokens(); tokens < 0.99 {
+		t.Errorf("Expected the rejected call not to be taken from the user limit, got %f tokens", tokens)
+	}
+}
+
+func TestRateLimitMiddlewareIgnoresOtherMethods(t *testing.T) {
+	var calls int
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		calls++
+		return &mcp.ListToolsResult{}, nil
+	}
+	handler := RateLimitMiddleware(RateLimitConfig{UserRate: 0.01, UserBurst: 1})(next)
+
+	for range 3 {
+		if _, err := handler(WithToken(context.Background(), "user-a"), "tools/list", &mcp.ListToolsRequest{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
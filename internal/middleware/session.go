@@ -0,0 +1,296 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// DefaultSessionTTL is how long the memory of an MCP session is kept after its last request by default.
+const DefaultSessionTTL = time.Hour
+
+// sessionArgs are the arguments of the tool calls remembered by the sessions, and used by the next calls of the
+// session requiring them without setting them.
+var sessionArgs = []string{"cluster", "namespace"}
+
+// sessionCtxKey is the context key of the session of the request.
+var sessionCtxKey = &contextKey{"session"}
+
+// contextKey is the type of the context keys of the package.
+type contextKey struct {
+	name string
+}
+
+// SessionConfig configures the memory of the MCP sessions.
+type SessionConfig struct {
+	// TTL is how long the memory of a session is kept after its last request. If 0, DefaultSessionTTL is used.
+	TTL time.Duration
+}
+
+// PendingConfirmation is a confirmation token returned in a session, for a call not confirmed yet.
+type PendingConfirmation struct {
+	Tool      string    `json:"tool"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Session holds the entities the tools of an MCP session recently operated on.
+type Session struct {
+	mu            sync.Mutex
+	lastUsed      time.Time
+	args          map[string]string
+	confirmations map[string]PendingConfirmation
+}
+
+// Cluster returns the cluster of the last successful tool call of the session setting one.
+func (s *Session) Cluster() string {
+	return s.arg("cluster")
+}
+
+// Namespace returns the namespace of the last successful tool call of the session setting one.
+func (s *Session) Namespace() string {
+	return s.arg("namespace")
+}
+
+// PendingConfirmations returns the confirmation tokens returned in the session that weren't used and haven't
+// expired, sorted by tool.
+func (s *Session) PendingConfirmations() []PendingConfirmation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	pending := []PendingConfirmation{}
+	for token, confirmation := range s.confirmations {
+		if now.After(confirmation.ExpiresAt) {
+			delete(s.confirmations, token)
+			continue
+		}
+		pending = append(pending, confirmation)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Tool != pending[j].Tool {
+			return pending[i].Tool < pending[j].Tool
+		}
+		return pending[i].ExpiresAt.Before(pending[j].ExpiresAt)
+	})
+
+	return pending
+}
+
+func (s *Session) arg(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.args[name]
+}
+
+// remember records the session arguments of a tool call.
+func (s *Session) remember(arguments map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range sessionArgs {
+		if value, _ := arguments[name].(string); value != "" {
+			s.args[name] = value
+		}
+	}
+}
+
+func (s *Session) addConfirmation(confirmation PendingConfirmation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.confirmations[confirmation.Token] = confirmation
+}
+
+func (s *Session) removeConfirmation(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.confirmations, token)
+}
+
+// SessionFrom returns the session of the request set in the context by the SessionMiddleware, nil if there is
+// none.
+func SessionFrom(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionCtxKey).(*Session)
+
+	return session
+}
+
+// sessions holds the memory of the MCP sessions, and the session arguments required by the tools.
+type sessions struct {
+	config SessionConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+	// required contains the session arguments required by each tool, found in the input schemas of the listed tools
+	required map[string][]string
+}
+
+// SessionMiddleware returns an MCP middleware remembering the cluster and the namespace of the successful tool
+// calls of each session, and the confirmation tokens returned by the ConfirmationMiddleware in it. The next tool
+// calls of the session requiring a cluster or a namespace without setting it use the last one, which avoids
+// guessing it again in multi-step workflows. The session is set into the context of the requests, so the tools can
+// read it with SessionFrom. Sessions are per user, and forgotten after the TTL of the config without requests. It
+// must run after CredentialsMiddleware and before ConfirmationMiddleware.
+func SessionMiddleware(config SessionConfig) mcp.Middleware {
+	return newSessions(config).middleware
+}
+
+func newSessions(config SessionConfig) *sessions {
+	if config.TTL <= 0 {
+		config.TTL = DefaultSessionTTL
+	}
+
+	return &sessions{
+		config:   config,
+		now:      time.Now,
+		sessions: map[string]*Session{},
+		required: map[string][]string{},
+	}
+}
+
+func (s *sessions) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch req := req.(type) {
+		case *mcp.ListToolsRequest:
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+			if listResult, ok := result.(*mcp.ListToolsResult); ok {
+				s.recordRequiredArgs(listResult)
+			}
+			return result, nil
+		case *mcp.CallToolRequest:
+			return s.callTool(ctx, method, req, next)
+		default:
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// callTool sets the missing session arguments of the call, and remembers its arguments if it succeeds.
+func (s *sessions) callTool(ctx context.Context, method string, req *mcp.CallToolRequest, next mcp.MethodHandler) (mcp.Result, error) {
+	session := s.session(ctx, req)
+	ctx = context.WithValue(ctx, sessionCtxKey, session)
+
+	arguments := map[string]any{}
+	if len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &arguments); err != nil {
+			// the tool returns the error of the invalid arguments
+			return next(ctx, method, req)
+		}
+	}
+
+	defaulted := false
+	for _, name := range s.requiredArgs(req.Params.Name) {
+		// an argument set by the call, even empty, is kept, e.g. the empty namespace of a cluster-scoped resource
+		if _, ok := arguments[name]; ok {
+			continue
+		}
+		if value := session.arg(name); value != "" {
+			arguments[name] = value
+			defaulted = true
+			Logger(ctx).Debug("using the argument of the session", zap.String("tool", req.Params.Name), zap.String("argument", name), zap.String("value", value))
+		}
+	}
+	if defaulted {
+		data, err := json.Marshal(arguments)
+		if err != nil {
+			return nil, err
+		}
+		params := *req.Params
+		params.Arguments = data
+		defaultedReq := *req
+		defaultedReq.Params = &params
+		req = &defaultedReq
+	}
+
+	result, err := next(ctx, method, req)
+	if err != nil {
+		return result, err
+	}
+	if callResult, ok := result.(*mcp.CallToolResult); ok && !callResult.IsError {
+		session.remember(arguments)
+	}
+
+	return result, nil
+}
+
+// session returns the session of the user of the request, creating it if needed, and forgets the expired ones.
+func (s *sessions) session(ctx context.Context, req *mcp.CallToolRequest) *Session {
+	key := userKey(ctx)
+	if req.Session != nil {
+		key += "/" + req.Session.ID()
+	}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, session := range s.sessions {
+		session.mu.Lock()
+		expired := now.Sub(session.lastUsed) > s.config.TTL
+		session.mu.Unlock()
+		if expired {
+			delete(s.sessions, k)
+		}
+	}
+	session, ok := s.sessions[key]
+	if !ok {
+		session = &Session{args: map[string]string{}, confirmations: map[string]PendingConfirmation{}}
+		s.sessions[key] = session
+	}
+	session.mu.Lock()
+	session.lastUsed = now
+	session.mu.Unlock()
+
+	return session
+}
+
+func (s *sessions) requiredArgs(tool string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.required[tool]
+}
+
+// recordRequiredArgs records the session arguments required by the input schemas of the tools. Only the required
+// arguments are set by the session, since an empty optional one has its own meaning, e.g. all the namespaces.
+func (s *sessions) recordRequiredArgs(result *mcp.ListToolsResult) {
+	required := map[string][]string{}
+	for _, tool := range result.Tools {
+		data, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			zap.L().Warn("failed to marshal input schema", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+		var schema struct {
+			Required []string `json:"required"`
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			zap.L().Warn("failed to unmarshal input schema", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+		for _, name := range sessionArgs {
+			if slices.Contains(schema.Required, name) {
+				required[tool.Name] = append(required[tool.Name], name)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for tool, args := range required {
+		s.required[tool] = args
+	}
+}
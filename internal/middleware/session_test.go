@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionMiddleware(t *testing.T) {
+	type call struct {
+		tool      string
+		user      string
+		arguments map[string]any
+		// fails makes the tool return an error
+		fails bool
+	}
+	podArgs := map[string]any{"name": "web", "namespace": "default", "cluster": "prod"}
+
+	tests := map[string]struct {
+		calls       []call
+		expectedRun []map[string]any
+	}{
+		"required arguments set by the session": {
+			calls: []call{
+				{tool: "inspectPod", user: "user-a", arguments: podArgs},
+				{tool: "getPodLogs", user: "user-a", arguments: map[string]any{"name": "web"}},
+			},
+			expectedRun: []map[string]any{
+				podArgs,
+				{"name": "web", "namespace": "default", "cluster": "prod"},
+			},
+		},
+		"arguments of the call override the session": {
+			calls: []call{
+				{tool: "inspectPod", user: "user-a", arguments: podArgs},
+				{tool: "getPodLogs", user: "user-a", arguments: map[string]any{"name": "web", "namespace": "kube-system", "cluster": ""}},
+			},
+			expectedRun: []map[string]any{
+				podArgs,
+				{"name": "web", "namespace": "kube-system", "cluster": ""},
+			},
+		},
+		"explicit empty arguments not set by the session": {
+			calls: []call{
+				{tool: "inspectPod", user: "user-a", arguments: podArgs},
+				{tool: "getPodLogs", user: "user-a", arguments: map[string]any{"name": "web", "namespace": ""}},
+			},
+			expectedRun: []map[string]any{
+				podArgs,
+				{"name": "web", "namespace": "", "cluster": "prod"},
+			},
+		},
+		"optional arguments not set by the session": {
+			calls: []call{
+				{tool: "inspectPod", user: "user-a", arguments: podArgs},
+				{tool: "listKubernetesResources", user: "user-a", arguments: map[string]any{"kind": "pod", "cluster": "prod"}},
+			},
+			expectedRun: []map[string]any{
+				podArgs,
+				{"kind": "pod", "cluster": "prod"},
+			},
+		},
+		"failed calls not remembered": {
+			calls: []call{
+				{tool: "inspectPod", user: "user-a", arguments: podArgs, fails: true},
+				{tool: "getPodLogs", user: "user-a", arguments: map[string]any{"name": "web"}},
+			},
+			expectedRun: []map[string]any{
+				podArgs,
+				{"name": "web"},
+			},
+		},
+		"sessions of other users not used": {
+			calls: []call{
+				{tool: "inspectPod", user: "user-a", arguments: podArgs},
+				{tool: "getPodLogs", user: "user-b", arguments: map[string]any{"name": "web"}},
+			},
+			expectedRun: []map[string]any{
+				podArgs,
+				{"name": "web"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newSessions(SessionConfig{})
+			var run []map[string]any
+			var failing bool
+			handler := s.middleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				switch req := req.(type) {
+				case *mcp.ListToolsRequest:
+					return &mcp.ListToolsResult{Tools: []*mcp.Tool{
+						{Name: "inspectPod", InputSchema: map[string]any{"type": "object", "required": []string{"name", "namespace", "cluster"}}},
+						{Name: "getPodLogs", InputSchema: map[string]any{"type": "object", "required": []string{"name", "namespace", "cluster"}}},
+						{Name: "listKubernetesResources", InputSchema: map[string]any{"type": "object", "required": []string{"kind", "cluster"}}},
+					}}, nil
+				case *mcp.CallToolRequest:
+					require.NotNil(t, SessionFrom(ctx))
+					arguments := map[string]any{}
+					require.NoError(t, json.Unmarshal(req.Params.Arguments, &arguments))
+					run = append(run, arguments)
+					return &mcp.CallToolResult{IsError: failing}, nil
+				}
+				return nil, nil
+			})
+			_, err := handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+			require.NoError(t, err)
+
+			for _, call := range test.calls {
+				arguments, err := json.Marshal(call.arguments)
+				require.NoError(t, err)
+				failing = call.fails
+				_, err = handler(WithToken(context.Background(), call.user), "tools/call", &mcp.CallToolRequest{
+					Params: &mcp.CallToolParamsRaw{Name: call.tool, Arguments: arguments},
+				})
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedRun, run)
+		})
+	}
+}
+
+func TestSessionExpiration(t *testing.T) {
+	s := newSessions(SessionConfig{TTL: time.Minute})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := WithToken(context.Background(), "user-a")
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "inspectPod"}}
+
+	session := s.session(ctx, req)
+	session.remember(map[string]any{"cluster": "prod", "namespace": 3})
+	assert.Equal(t, "prod", session.Cluster())
+	assert.Empty(t, session.Namespace())
+	assert.Same(t, session, s.session(ctx, req))
+
+	now = now.Add(2 * time.Minute)
+	assert.Empty(t, s.session(ctx, req).Cluster(), "the session is forgotten after its TTL")
+}
+
+func TestSessionPendingConfirmations(t *testing.T) {
+	c := newConfirmations(ConfirmationConfig{Tools: []string{"deleteNamespace"}})
+	s := newSessions(SessionConfig{})
+	var session *Session
+	handler := s.middleware(c.middleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	}))
+	callTool := func(arguments map[string]any) mcp.Result {
+		data, err := json.Marshal(arguments)
+		require.NoError(t, err)
+		result, err := handler(WithToken(context.Background(), "user-a"), "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "deleteNamespace", Arguments: data},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	token := confirmationToken(t, callTool(map[string]any{"name": "web", "cluster": "prod"}))
+	session = s.session(WithToken(context.Background(), "user-a"), &mcp.CallToolRequest{})
+	pending := session.PendingConfirmations()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "deleteNamespace", pending[0].Tool)
+	assert.Equal(t, token, pending[0].Token)
+
+	callTool(map[string]any{"name": "web", "cluster": "prod", ConfirmationTokenArg: token})
+	assert.Empty(t, session.PendingConfirmations(), "the confirmed token is removed from the session")
+}
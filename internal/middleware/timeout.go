@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DefaultToolTimeout is the default time limit of a tool call.
+const DefaultToolTimeout = 30 * time.Second
+
+// MaxWaitSeconds is the longest waitSeconds argument added to the time limit of a tool call, for the tools waiting
+// for the changes they made. It's kept below the timeouts of the MCP clients.
+const MaxWaitSeconds = 300
+
+// TimeoutConfig configures the time limits of the tool calls.
+type TimeoutConfig struct {
+	// Default is the time limit of the tools without one in Tools. If 0, DefaultToolTimeout is used.
+	Default time.Duration
+	// Tools contains the time limits of specific tools, by name.
+	Tools map[string]time.Duration
+}
+
+// toolTimedOut is the result of a tool call that didn't complete in time.
+type toolTimedOut struct {
+	Error     response.ToolError `json:"error"`
+	Completed []string           `json:"completed"`
+	Pending   []string           `json:"pending"`
+}
+
+// TimeoutMiddleware returns an MCP middleware that cancels the context of the tool calls running for longer than
+// their time limit, so a cluster that doesn't answer doesn't block the session. The call then returns at once,
+// even if the tool doesn't stop, with a structured Timeout error listing the fetches of the tool that completed
+// and the ones still running. The fetches are the ones of the fetch Groups of the tool.
+//
+// The waitSeconds argument of a call, up to MaxWaitSeconds, is added to its time limit, so a tool waiting for the
+// change it made isn't cancelled after the change was applied.
+func TimeoutMiddleware(config TimeoutConfig) mcp.Middleware {
+	if config.Default <= 0 {
+		config.Default = DefaultToolTimeout
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			toolReq, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+
+			timeout := config.Default
+			if toolTimeout, ok := config.Tools[toolReq.Params.Name]; ok {
+				timeout = toolTimeout
+			}
+			timeout += waitDuration(toolReq)
+			toolCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			toolCtx, progress := fetch.WithProgress(toolCtx)
+
+			type callResult struct {
+				result mcp.Result
+				err    error
+			}
+			done := make(chan callResult, 1)
+			go func() {
+				result, err := next(toolCtx, method, req)
+				done <- callResult{result: result, err: err}
+			}()
+
+			select {
+			case call := <-done:
+				return call.result, call.err
+			case <-toolCtx.Done():
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+			}
+
+			Logger(ctx).Warn("tool call timed out", zap.String("tool", toolReq.Params.Name), zap.Duration("timeout", timeout), zap.Strings("pending", progress.Pending()))
+			timeoutErr := apierrors.NewTimeoutError(fmt.Sprintf("the tool didn't complete in %s", timeout), 0)
+			bytes, err := json.Marshal(toolTimedOut{
+				Error:     response.NewToolError(timeoutErr),
+				Completed: progress.Completed(),
+				Pending:   progress.Pending(),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: string(bytes)}},
+			}, nil
+		}
+	}
+}
+
+// waitDuration returns the waitSeconds argument of a tool call, 0 if it has none or it isn't between 0 and
+// MaxWaitSeconds.
+func waitDuration(req *mcp.CallToolRequest) time.Duration {
+	if req.Params == nil || len(req.Params.Arguments) == 0 {
+		return 0
+	}
+	var args struct {
+		WaitSeconds int64 `json:"waitSeconds"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil || args.WaitSeconds < 0 || args.WaitSeconds > MaxWaitSeconds {
+		return 0
+	}
+
+	return time.Duration(args.WaitSeconds) * time.Second
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		config            TimeoutConfig
+		tool              string
+		expectedTimedOut  bool
+		expectedCompleted []string
+		expectedPending   []string
+	}{
+		"tool completes in time": {
+			config: TimeoutConfig{Default: time.Second},
+			tool:   "getKubernetesResource",
+		},
+		"tool times out with a partial result": {
+			config:            TimeoutConfig{Default: 50 * time.Millisecond},
+			tool:              "inspectPod",
+			expectedTimedOut:  true,
+			expectedCompleted: []string{"owners"},
+			expectedPending:   []string{"logs"},
+		},
+		"tool timeout overrides the default": {
+			config:            TimeoutConfig{Default: time.Minute, Tools: map[string]time.Duration{"inspectPod": 50 * time.Millisecond}},
+			tool:              "inspectPod",
+			expectedTimedOut:  true,
+			expectedCompleted: []string{"owners"},
+			expectedPending:   []string{"logs"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			release := make(chan struct{})
+			defer close(release)
+			next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				if req.(*mcp.CallToolRequest).Params.Name != "inspectPod" {
+					return &mcp.CallToolResult{}, nil
+				}
+				g := fetch.New(ctx, 0)
+				g.Go("owners", func(context.Context) error { return nil })
+				// the logs fetch ignores the cancellation of its context
+				g.GoOptional("logs", func(context.Context) error {
+					<-release
+					return nil
+				})
+				_, err := g.Wait()
+				return &mcp.CallToolResult{}, err
+			}
+			handler := TimeoutMiddleware(tt.config)(next)
+
+			start := time.Now()
+			req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: tt.tool}}
+			result, err := handler(context.Background(), "tools/call", req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if time.Since(start) > time.Second {
+				t.Errorf("Expected the call to return when it times out, took %s", time.Since(start))
+			}
+
+			toolResult := result.(*mcp.CallToolResult)
+			if toolResult.IsError != tt.expectedTimedOut {
+				t.Fatalf("Expected timed out %v, got %v", tt.expectedTimedOut, toolResult.IsError)
+			}
+			if !tt.expectedTimedOut {
+				return
+			}
+			var timedOut toolTimedOut
+			if err := json.Unmarshal([]byte(toolResult.Content[0].(*mcp.TextContent).Text), &timedOut); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if timedOut.Error.Reason != "Timeout" {
+				t.Errorf("Expected a Timeout error, got %s", timedOut.Error.Reason)
+			}
+			if !slices.Equal(timedOut.Completed, tt.expectedCompleted) || !slices.Equal(timedOut.Pending, tt.expectedPending) {
+				t.Errorf("Expected completed %v and pending %v, got %v and %v", tt.expectedCompleted, tt.expectedPending, timedOut.Completed, timedOut.Pending)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddlewareCancelledCall(t *testing.T) {
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	handler := TimeoutMiddleware(TimeoutConfig{Default: time.Minute})(next)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "getKubernetesResource"}}
+	if _, err := handler(ctx, "tools/call", req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the call to be cancelled, got %v", err)
+	}
+}
+
+func TestTimeoutMiddlewareWaitSeconds(t *testing.T) {
+	tests := map[string]struct {
+		config           TimeoutConfig
+		arguments        map[string]any
+		sleep            time.Duration
+		expectedTimeout  time.Duration
+		expectedTimedOut bool
+	}{
+		"wait added to the default time limit": {
+			arguments:       map[string]any{"waitSeconds": 45},
+			expectedTimeout: DefaultToolTimeout + 45*time.Second,
+		},
+		"wait added to the time limit of the tool": {
+			config:          TimeoutConfig{Tools: map[string]time.Duration{"waitingTool": time.Minute}},
+			arguments:       map[string]any{"waitSeconds": 120},
+			expectedTimeout: 3 * time.Minute,
+		},
+		"wait longer than the maximum ignored": {
+			arguments:       map[string]any{"waitSeconds": 1000},
+			expectedTimeout: DefaultToolTimeout,
+		},
+		"no wait": {
+			arguments:       map[string]any{},
+			expectedTimeout: DefaultToolTimeout,
+		},
+		"tool waiting longer than its time limit": {
+			config:          TimeoutConfig{Default: 50 * time.Millisecond},
+			arguments:       map[string]any{"waitSeconds": 1},
+			sleep:           200 * time.Millisecond,
+			expectedTimeout: time.Second + 50*time.Millisecond,
+		},
+		"tool waiting longer than its wait": {
+			config:           TimeoutConfig{Default: 50 * time.Millisecond},
+			arguments:        map[string]any{"waitSeconds": 0},
+			sleep:            200 * time.Millisecond,
+			expectedTimeout:  50 * time.Millisecond,
+			expectedTimedOut: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			timeouts := make(chan time.Duration, 1)
+			mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+			mcp.AddTool(mcpServer, &mcp.Tool{Name: "waitingTool"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+				deadline, _ := ctx.Deadline()
+				timeouts <- time.Until(deadline)
+				select {
+				case <-ctx.Done():
+				case <-time.After(tt.sleep):
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+			})
+			mcpServer.AddReceivingMiddleware(TimeoutMiddleware(tt.config))
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer ss.Close()
+			cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer cs.Close()
+
+			result, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "waitingTool", Arguments: tt.arguments})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.IsError != tt.expectedTimedOut {
+				t.Errorf("Expected timed out %v, got %v", tt.expectedTimedOut, result.IsError)
+			}
+			if timeout := <-timeouts; timeout > tt.expectedTimeout || timeout < tt.expectedTimeout-time.Second {
+				t.Errorf("Expected a time limit of %s, got %s", tt.expectedTimeout, timeout)
+			}
+		})
+	}
+}
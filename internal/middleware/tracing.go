@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+// traceparentRE matches the version 00 traceparent headers, capturing the trace ID, the parent span ID and the flags.
+// https://www.w3.org/TR/trace-context/#traceparent-header-field-values
+var traceparentRE = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+const (
+	invalidTraceID = "00000000000000000000000000000000"
+	invalidSpanID  = "0000000000000000"
+)
+
+// TracingConfig configures the tracing of the MCP requests.
+type TracingConfig struct {
+	// Exporter receives the spans of the sampled requests. If nil, the spans aren't exported and the requests are
+	// only logged.
+	Exporter SpanExporter
+}
+
+// TracingHTTPMiddleware returns an HTTP middleware giving every request a request ID, the one of its X-Request-Id
+// header if set, returned in the X-Request-Id header of the response. The MCP requests read it from their headers
+// with the TracingMiddleware.
+func TracingHTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(client.RequestIDHeader)
+		if requestID == "" {
+			requestID = newID(16)
+			r.Header.Set(client.RequestIDHeader, requestID)
+		}
+		w.Header().Set(client.RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TracingMiddleware returns an MCP middleware setting the trace of the requests into their context, so their log
+// lines and the requests they make to Rancher can be correlated. The request ID and the W3C trace context are the
+// ones of the X-Request-Id and traceparent headers of the request, a new trace is started without them, e.g. with
+// the stdio transport. Every request gets its own span, logged when it completes and sent to the exporter of the
+// config if the trace is sampled.
+func TracingMiddleware(config TracingConfig) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			var header http.Header
+			if extra := req.GetExtra(); extra != nil {
+				header = extra.Header
+			}
+			trace, parentSpanID := newTrace(header)
+			ctx = client.WithTrace(ctx, trace)
+
+			var tool string
+			if toolReq, ok := req.(*mcp.CallToolRequest); ok && toolReq.Params != nil {
+				tool = toolReq.Params.Name
+			}
+
+			start := time.Now()
+			result, err := next(ctx, method, req)
+			end := time.Now()
+
+			fields := []zap.Field{zap.String("method", method), zap.Duration("duration", end.Sub(start))}
+			if tool != "" {
+				fields = append(fields, zap.String("tool", tool))
+			}
+			if err != nil {
+				fields = append(fields, zap.Error(err))
+			}
+			Logger(ctx).Debug("handled MCP request", fields...)
+
+			if config.Exporter != nil && trace.Sampled {
+				span := Span{
+					TraceID:      trace.TraceID,
+					SpanID:       trace.SpanID,
+					ParentSpanID: parentSpanID,
+					Name:         method,
+					Start:        start,
+					End:          end,
+					Attributes:   map[string]string{"mcp.method.name": method, "mcp.request.id": trace.RequestID},
+				}
+				if tool != "" {
+					span.Name = method + " " + tool
+					span.Attributes["gen_ai.tool.name"] = tool
+				}
+				if err != nil {
+					span.Error = err.Error()
+				} else if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult.IsError {
+					span.Error = "the tool returned an error"
+				}
+				config.Exporter.Export(span)
+			}
+
+			return result, err
+		}
+	}
+}
+
+// Logger returns the logger of the request of the context, with the request and trace IDs set by the
+// TracingMiddleware.
+func Logger(ctx context.Context) *zap.Logger {
+	return zap.L().With(client.TraceFields(ctx)...)
+}
+
+// newTrace returns the trace of a request with the headers, and the ID of its parent span, empty if it starts a
+// new trace.
+func newTrace(header http.Header) (client.Trace, string) {
+	trace := client.Trace{
+		RequestID: header.Get(client.RequestIDHeader),
+		SpanID:    newID(8),
+		Sampled:   true,
+	}
+	if trace.RequestID == "" {
+		trace.RequestID = newID(16)
+	}
+
+	matches := traceparentRE.FindStringSubmatch(header.Get(client.TraceparentHeader))
+	if matches == nil || matches[1] == invalidTraceID || matches[2] == invalidSpanID {
+		trace.TraceID = newID(16)
+		return trace, ""
+	}
+	flags, err := hex.DecodeString(matches[3])
+	if err != nil {
+		trace.TraceID = newID(16)
+		return trace, ""
+	}
+	trace.TraceID = matches[1]
+	trace.Sampled = flags[0]&0x01 == 0x01
+
+	return trace, matches[2]
+}
+
+// newID returns a random hex encoded ID of size bytes.
+func newID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+)
+
+type fakeExporter struct {
+	spans []Span
+}
+
+func (e *fakeExporter) Export(span Span) {
+	e.spans = append(e.spans, span)
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		header               http.Header
+		err                  error
+		expectedRequestID    string
+		expectedTraceID      string
+		expectedParentSpanID string
+		expectedSampled      bool
+		expectedError        string
+	}{
+		"request with a traceparent": {
+			header: http.Header{
+				"X-Request-Id": {"req-1"},
+				"Traceparent":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			},
+			expectedRequestID:    "req-1",
+			expectedTraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParentSpanID: "00f067aa0ba902b7",
+			expectedSampled:      true,
+		},
+		"request with a trace that isn't sampled": {
+			header:               http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}},
+			expectedTraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParentSpanID: "00f067aa0ba902b7",
+		},
+		"request with an invalid traceparent starts a new trace": {
+			header:          http.Header{"Traceparent": {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+			expectedSampled: true,
+		},
+		"request without headers starts a new trace": {
+			expectedSampled: true,
+		},
+		"failed request": {
+			err:             errors.New("failed"),
+			expectedSampled: true,
+			expectedError:   "failed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			exporter := &fakeExporter{}
+			var trace client.Trace
+			handler := TracingMiddleware(TracingConfig{Exporter: exporter})(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				trace, _ = client.TraceFrom(ctx)
+				return &mcp.CallToolResult{}, test.err
+			})
+
+			_, _ = handler(context.Background(), "tools/call", &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Name: "getKubernetesResource"},
+				Extra:  &mcp.RequestExtra{Header: test.header},
+			})
+
+			if test.expectedRequestID != "" && trace.RequestID != test.expectedRequestID {
+				t.Errorf("expected request ID %q, got %q", test.expectedRequestID, trace.RequestID)
+			}
+			if len(trace.RequestID) != 32 && test.expectedRequestID == "" {
+				t.Errorf("expected a new request ID, got %q", trace.RequestID)
+			}
+			if test.expectedTraceID != "" && trace.TraceID != test.expectedTraceID {
+				t.Errorf("expected trace ID %q, got %q", test.expectedTraceID, trace.TraceID)
+			}
+			if len(trace.TraceID) != 32 || trace.TraceID == invalidTraceID {
+				t.Errorf("expected a valid trace ID, got %q", trace.TraceID)
+			}
+			if len(trace.SpanID) != 16 || trace.SpanID == test.expectedParentSpanID {
+				t.Errorf("expected a new span ID, got %q", trace.SpanID)
+			}
+			if trace.Sampled != test.expectedSampled {
+				t.Errorf("expected sampled %t, got %t", test.expectedSampled, trace.Sampled)
+			}
+
+			if !test.expectedSampled {
+				if len(exporter.spans) != 0 {
+					t.Errorf("expected no exported span, got %d", len(exporter.spans))
+				}
+				return
+			}
+			if len(exporter.spans) != 1 {
+				t.Fatalf("expected 1 exported span, got %d", len(exporter.spans))
+			}
+			span := exporter.spans[0]
+			if span.TraceID != trace.TraceID || span.SpanID != trace.SpanID || span.ParentSpanID != test.expectedParentSpanID {
+				t.Errorf("expected span %s/%s with parent %q, got %s/%s with parent %q", trace.TraceID, trace.SpanID, test.expectedParentSpanID, span.TraceID, span.SpanID, span.ParentSpanID)
+			}
+			if span.Name != "tools/call getKubernetesResource" || span.Attributes["gen_ai.tool.name"] != "getKubernetesResource" {
+				t.Errorf("expected the span of the getKubernetesResource tool, got %q with %v", span.Name, span.Attributes)
+			}
+			if span.Error != test.expectedError {
+				t.Errorf("expected span error %q, got %q", test.expectedError, span.Error)
+			}
+		})
+	}
+}
+
+func TestTracingHTTPMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		requestID string
+	}{
+		"request with a request ID": {
+			requestID: "req-1",
+		},
+		"request without a request ID": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var forwarded string
+			handler := TracingHTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				forwarded = r.Header.Get(client.RequestIDHeader)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if test.requestID != "" {
+				req.Header.Set(client.RequestIDHeader, test.requestID)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if forwarded == "" || rec.Header().Get(client.RequestIDHeader) != forwarded {
+				t.Errorf("expected the request ID %q in the response, got %q", forwarded, rec.Header().Get(client.RequestIDHeader))
+			}
+			if test.requestID != "" && forwarded != test.requestID {
+				t.Errorf("expected request ID %q, got %q", test.requestID, forwarded)
+			}
+		})
+	}
+}
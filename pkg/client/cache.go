@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// cacheRequests counts the reads served from the cache (hit) and from the API server (miss).
+var cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "rancher_mcp_client_cache_requests_total",
+	Help: "Number of Kubernetes reads served from the cache (result=hit) or the Rancher API (result=miss).",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(cacheRequests)
+}
+
+// readCacheKey identifies a get or a list. The token is part of the key, so users never get resources cached for
+// other users that they may not have access to.
+type readCacheKey struct {
+	token     string
+	url       string
+	cluster   string
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+	// list contains the options of a list, it's empty for a get.
+	list metav1.ListOptions
+	// metadataOnly is true for the lists of the metadata of the resources.
+	metadataOnly bool
+}
+
+type readCacheEntry struct {
+	objs          []*unstructured.Unstructured
+	continueToken string
+	expiresAt     time.Time
+}
+
+// readCache is a read-through cache of the resources got and listed by the Client, so the repeated reads of a
+// conversation don't reach the Rancher proxy. Any write through the Client clears it, since a write can have
+// effects on other resources (e.g. the Pods of a patched Deployment).
+type readCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[readCacheKey]readCacheEntry
+}
+
+func newReadCache(ttl time.Duration) *readCache {
+	return &readCache{
+		ttl:     ttl,
+		entries: map[readCacheKey]readCacheEntry{},
+	}
+}
+
+// get returns a copy of the cached resources, so callers can modify them.
+func (c *readCache) get(key readCacheKey) ([]*unstructured.Unstructured, string, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		cacheRequests.WithLabelValues("miss").Inc()
+		return nil, "", false
+	}
+	cacheRequests.WithLabelValues("hit").Inc()
+
+	return deepCopyObjects(entry.objs), entry.continueToken, true
+}
+
+func (c *readCache) set(key readCacheKey, objs []*unstructured.Unstructured, continueToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = readCacheEntry{
+		objs:          deepCopyObjects(objs),
+		continueToken: continueToken,
+		expiresAt:     now.Add(c.ttl),
+	}
+}
+
+func (c *readCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+func deepCopyObjects(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	copies := make([]*unstructured.Unstructured, len(objs))
+	for i, obj := range objs {
+		copies[i] = obj.DeepCopy()
+	}
+
+	return copies
+}
+
+// hashToken returns the hash of the token used in the cache keys, so tokens aren't kept in memory longer than needed.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// EnableCache caches the resources returned by GetResource, GetResourceByGVR, GetResources and GetResourcesPage for
+// the given time. The cache is cleared by any write made with a resource interface returned by GetResourceInterface
+// or a clientset returned by CreateClientSet.
+func (c *Client) EnableCache(ttl time.Duration) {
+	c.cache = newReadCache(ttl)
+}
+
+// cachedGet returns the resource from the cache, or gets it and caches it.
+func (c *Client) cachedGet(params GetParams, gvr schema.GroupVersionResource, get func() (*unstructured.Unstructured, error)) (*unstructured.Unstructured, error) {
+	if c.cache == nil {
+		return get()
+	}
+
+	key := readCacheKey{
+		token:     hashToken(params.Token),
+		url:       params.URL,
+		cluster:   params.Cluster,
+		gvr:       gvr,
+		namespace: params.Namespace,
+		name:      params.Name,
+	}
+	if objs, _, ok := c.cache.get(key); ok {
+		return objs[0], nil
+	}
+	obj, err := get()
+	if err != nil {
+		return nil, err
+	}
+	c.cache.set(key, []*unstructured.Unstructured{obj}, "")
+
+	return obj, nil
+}
+
+// cachedList returns the resources from the cache, or lists them and caches them.
+func (c *Client) cachedList(params ListParams, gvr schema.GroupVersionResource, opts metav1.ListOptions, list func() ([]*unstructured.Unstructured, string, error)) ([]*unstructured.Unstructured, string, error) {
+	if c.cache == nil {
+		return list()
+	}
+
+	key := readCacheKey{
+		token:        hashToken(params.Token),
+		url:          params.URL,
+		cluster:      params.Cluster,
+		gvr:          gvr,
+		namespace:    params.Namespace,
+		list:         opts,
+		metadataOnly: params.MetadataOnly,
+	}
+	if objs, continueToken, ok := c.cache.get(key); ok {
+		return objs, continueToken, nil
+	}
+	objs, continueToken, err := list()
+	if err != nil {
+		return nil, "", err
+	}
+	c.cache.set(key, objs, continueToken)
+
+	return objs, continueToken, nil
+}
+
+// invalidatingTransport clears the cache of the Client after every request that isn't a read, so the writes of the
+// clientsets returned by CreateClientSet, e.g. the update of the finalizers of a namespace, aren't followed by stale
+// reads.
+type invalidatingTransport struct {
+	next  http.RoundTripper
+	cache *readCache
+}
+
+func (t *invalidatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		defer t.cache.clear()
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// invalidatingResourceInterface clears the cache of the Client after every write.
+type invalidatingResourceInterface struct {
+	dynamic.ResourceInterface
+	cache *readCache
+}
+
+func (r invalidatingResourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	defer r.cache.clear()
+	return r.ResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (r invalidatingResourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	defer r.cache.clear()
+	return r.ResourceInterface.Update(ctx, obj, options, subresources...)
+}
+
+func (r invalidatingResourceInterface) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	defer r.cache.clear()
+	return r.ResourceInterface.UpdateStatus(ctx, obj, options)
+}
+
+func (r invalidatingResourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	defer r.cache.clear()
+	return r.ResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+func (r invalidatingResourceInterface) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	defer r.cache.clear()
+	return r.ResourceInterface.DeleteCollection(ctx, options, listOptions)
+}
+
+func (r invalidatingResourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	defer r.cache.clear()
+	return r.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func (r invalidatingResourceInterface) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	defer r.cache.clear()
+	return r.ResourceInterface.Apply(ctx, name, obj, options, subresources...)
+}
+
+func (r invalidatingResourceInterface) ApplyStatus(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	defer r.cache.clear()
+	return r.ResourceInterface.ApplyStatus(ctx, name, obj, options)
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCache(t *testing.T) {
+	fakePod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+	getParams := GetParams{Cluster: "local", Kind: "pod", Namespace: "default", Name: "test-pod", URL: fakeUrl, Token: fakeToken}
+	listParams := ListParams{Cluster: "local", Kind: "pod", Namespace: "default", URL: fakeUrl, Token: fakeToken}
+
+	tests := map[string]struct {
+		ttl              time.Duration
+		reads            func(t *testing.T, c *Client)
+		expectedRequests int
+		expectedHits     float64
+	}{
+		"repeated get is cached": {
+			ttl: time.Minute,
+			reads: func(t *testing.T, c *Client) {
+				for range 3 {
+					_, err := c.GetResource(t.Context(), getParams)
+					require.NoError(t, err)
+				}
+			},
+			expectedRequests: 1,
+			expectedHits:     2,
+		},
+		"repeated list is cached": {
+			ttl: time.Minute,
+			reads: func(t *testing.T, c *Client) {
+				for range 2 {
+					_, err := c.GetResources(t.Context(), listParams)
+					require.NoError(t, err)
+				}
+			},
+			expectedRequests: 1,
+			expectedHits:     1,
+		},
+		"lists with other selectors are not shared": {
+			ttl: time.Minute,
+			reads: func(t *testing.T, c *Client) {
+				_, err := c.GetResources(t.Context(), listParams)
+				require.NoError(t, err)
+				withSelector := listParams
+				withSelector.LabelSelector = "app=nginx"
+				_, err = c.GetResources(t.Context(), withSelector)
+				require.NoError(t, err)
+			},
+			expectedRequests: 2,
+		},
+		"other tokens are not shared": {
+			ttl: time.Minute,
+			reads: func(t *testing.T, c *Client) {
+				_, err := c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+				otherUser := getParams
+				otherUser.Token = "token-yyy"
+				_, err = c.GetResource(t.Context(), otherUser)
+				require.NoError(t, err)
+			},
+			expectedRequests: 2,
+		},
+		"writes clear the cache": {
+			ttl: time.Minute,
+			reads: func(t *testing.T, c *Client) {
+				_, err := c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+				resourceInterface, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["pod"])
+				require.NoError(t, err)
+				_, err = resourceInterface.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"nginx"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+				pod, err := c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+				assert.Equal(t, "nginx", pod.GetLabels()["app"])
+			},
+			expectedRequests: 2,
+		},
+		"entries expire": {
+			ttl: time.Millisecond,
+			reads: func(t *testing.T, c *Client) {
+				_, err := c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+				time.Sleep(5 * time.Millisecond)
+				_, err = c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+			},
+			expectedRequests: 2,
+		},
+		"cached resources can be modified": {
+			ttl: time.Minute,
+			reads: func(t *testing.T, c *Client) {
+				pod, err := c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+				pod.SetName("modified")
+				pod, err = c.GetResource(t.Context(), getParams)
+				require.NoError(t, err)
+				assert.Equal(t, "test-pod", pod.GetName())
+			},
+			expectedRequests: 1,
+			expectedHits:     1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), fakePod)
+			c := &Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			c.EnableCache(test.ttl)
+			hits := testutil.ToFloat64(cacheRequests.WithLabelValues("hit"))
+
+			test.reads(t, c)
+
+			var requests int
+			for _, action := range fakeDynClient.Actions() {
+				if action.GetVerb() == "get" || action.GetVerb() == "list" {
+					requests++
+				}
+			}
+			assert.Equal(t, test.expectedRequests, requests)
+			assert.Equal(t, test.expectedHits, testutil.ToFloat64(cacheRequests.WithLabelValues("hit"))-hits)
+		})
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}})
+	c := &Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+
+	for range 2 {
+		_, err := c.GetResource(context.Background(), GetParams{Cluster: "local", Kind: "pod", Namespace: "default", Name: "test-pod", URL: fakeUrl, Token: fakeToken})
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, fakeDynClient.Actions(), 2)
+}
+
+func TestCacheClearedByClientSetWrites(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"payments"}}`))
+	}))
+	defer server.Close()
+	c := NewClient(true)
+	c.EnableCache(time.Minute)
+	restConfig, err := c.newRestConfig(server.URL, "local", &clientcmdapi.AuthInfo{Token: "token"})
+	require.NoError(t, err)
+	clientSet, err := kubernetes.NewForConfig(restConfig)
+	require.NoError(t, err)
+	key := readCacheKey{token: hashToken(fakeToken), url: fakeUrl, cluster: "local", gvr: converter.K8sKindsToGVRs["pod"], namespace: "default", name: "test-pod"}
+	c.cache.set(key, nil, "")
+
+	namespace, err := clientSet.CoreV1().Namespaces().Get(t.Context(), "payments", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, _, ok := c.cache.get(key)
+	assert.True(t, ok, "reads must not clear the cache")
+
+	_, err = clientSet.CoreV1().Namespaces().Update(t.Context(), namespace, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, _, ok = c.cache.get(key)
+	assert.False(t, ok, "writes must clear the cache")
+}
@@ -3,29 +3,43 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
-	"sync"
 
 	"github.com/rancher/rancher-ai-mcp/pkg/converter"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/remotecommand"
 )
 
-var clusterIdsCache = sync.Map{}
-var clustersDisplayNameToIDCache = sync.Map{}
-
 // Client is a struct that provides methods for interacting with Kubernetes clusters.
 type Client struct {
 	insecure         bool
 	DynClientCreator func(*rest.Config) (dynamic.Interface, error)
 	ClientSetCreator func(*rest.Config) (kubernetes.Interface, error)
+	ExecutorCreator  func(*rest.Config, *url.URL) (remotecommand.Executor, error)
+	// MetadataClientCreator creates the client of the metadata-only lists. If nil, they list the full resources with
+	// the dynamic client.
+	MetadataClientCreator func(*rest.Config) (metadata.Interface, error)
+	// cache is the read-through cache enabled with EnableCache, nil if disabled.
+	cache *readCache
+	// history records the changes made with the resource interfaces, enabled with EnableChangeHistory, nil if disabled.
+	history *changeHistory
+	// impersonation is the service account credential enabled with EnableImpersonation or
+	// EnableImpersonationTokenFile, nil if disabled.
+	impersonation *clientcmdapi.AuthInfo
+	// pool keeps the connections to the clusters, enabled with EnableConnectionPool, nil if disabled.
+	pool *connectionPool
 }
 
 // GetParams holds the parameters required to get a resource from k8s.
@@ -47,6 +61,12 @@ type ListParams struct {
 	URL           string // The base URL of the Rancher server.
 	Token         string // The authentication Token for Steve.
 	LabelSelector string // Optional LabelSelector string for the request.
+	FieldSelector string // Optional FieldSelector string for the request.
+	Limit         int64  // Optional maximum number of resources returned, a continue token is returned if there are more.
+	Continue      string // Optional continue token returned by a previous request to get the next page.
+	// MetadataOnly only lists the metadata of the resources, as PartialObjectMetadata, for the callers only needing
+	// their names, labels, annotations or owners. It cuts the size of the lists of big resources like Nodes or Pods.
+	MetadataOnly bool
 }
 
 // NewClient creates and returns a new instance of the Client struct.
@@ -59,6 +79,10 @@ func NewClient(insecure bool) *Client {
 		ClientSetCreator: func(cfg *rest.Config) (kubernetes.Interface, error) {
 			return kubernetes.NewForConfig(cfg)
 		},
+		ExecutorCreator: newExecutor,
+		MetadataClientCreator: func(cfg *rest.Config) (metadata.Interface, error) {
+			return metadata.NewForConfig(cfg)
+		},
 	}
 }
 
@@ -68,25 +92,17 @@ func (c *Client) CreateClientSet(ctx context.Context, token string, url string,
 	if err != nil {
 		return nil, err
 	}
-	restConfig, err := c.createRestConfig(token, url, clusterID)
+	conns, err := c.connections(ctx, token, url, clusterID)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.ClientSetCreator(restConfig)
+	return conns.kubernetes(c.ClientSetCreator)
 }
 
 // GetResourceInterface returns a dynamic resource interface for the given Token, URL, Namespace, and GroupVersionResource.
 func (c *Client) GetResourceInterface(ctx context.Context, token string, url string, namespace string, cluster string, gvr schema.GroupVersionResource) (dynamic.ResourceInterface, error) {
-	clusterID, err := c.getClusterId(ctx, token, url, cluster)
-	if err != nil {
-		return nil, err
-	}
-	restConfig, err := c.createRestConfig(token, url, clusterID)
-	if err != nil {
-		return nil, err
-	}
-	dynClient, err := c.DynClientCreator(restConfig)
+	dynClient, err := c.dynamicClient(ctx, token, url, cluster)
 	if err != nil {
 		return nil, err
 	}
@@ -94,38 +110,62 @@ func (c *Client) GetResourceInterface(ctx context.Context, token string, url str
 	if namespace != "" {
 		resourceInterface = dynClient.Resource(gvr).Namespace(namespace)
 	}
+	if c.history != nil && gvr != secretsGVR {
+		resourceInterface = recordingResourceInterface{
+			ResourceInterface: resourceInterface,
+			history:           c.history,
+			store:             dynClient.Resource(configMapsGVR).Namespace(c.history.namespace),
+			user:              historyUser(ctx, token),
+			gvr:               gvr,
+			namespace:         namespace,
+		}
+	}
+	if c.cache != nil {
+		resourceInterface = invalidatingResourceInterface{ResourceInterface: resourceInterface, cache: c.cache}
+	}
 
 	return resourceInterface, nil
 }
 
-// GetResource retrieves a single Kubernetes resource by name.
-// It returns the resource as an unstructured object or an error if the resource is not found.
-func (c *Client) GetResource(ctx context.Context, params GetParams) (*unstructured.Unstructured, error) {
-	resourceInterface, err := c.GetResourceInterface(ctx, params.Token, params.URL, params.Namespace, params.Cluster, converter.K8sKindsToGVRs[strings.ToLower(params.Kind)])
+// dynamicClient returns a dynamic client for the given Token, URL and cluster.
+func (c *Client) dynamicClient(ctx context.Context, token string, url string, cluster string) (dynamic.Interface, error) {
+	clusterID, err := c.getClusterId(ctx, token, url, cluster)
 	if err != nil {
 		return nil, err
 	}
-
-	obj, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	conns, err := c.connections(ctx, token, url, clusterID)
 	if err != nil {
 		return nil, err
 	}
 
-	return obj, err
+	return conns.dynamic(c.DynClientCreator)
 }
 
-func (c *Client) GetResourceByGVR(ctx context.Context, params GetParams, gvr schema.GroupVersionResource) (*unstructured.Unstructured, error) {
-	resourceInterface, err := c.GetResourceInterface(ctx, params.Token, params.URL, params.Namespace, params.Cluster, gvr)
+// GetResource retrieves a single Kubernetes resource by name.
+// It returns the resource as an unstructured object or an error if the resource is not found.
+func (c *Client) GetResource(ctx context.Context, params GetParams) (*unstructured.Unstructured, error) {
+	gvr, err := c.ResolveGVR(ctx, params.Token, params.URL, params.Cluster, params.Kind)
 	if err != nil {
 		return nil, err
 	}
 
-	obj, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
+	return c.GetResourceByGVR(ctx, params, gvr)
+}
+
+// GetResourceByGVR retrieves a single Kubernetes resource by name like GetResource, using the given
+// GroupVersionResource instead of resolving the kind.
+func (c *Client) GetResourceByGVR(ctx context.Context, params GetParams, gvr schema.GroupVersionResource) (*unstructured.Unstructured, error) {
+	return c.cachedGet(params, gvr, func() (*unstructured.Unstructured, error) {
+		resourceInterface, err := c.GetResourceInterface(ctx, params.Token, params.URL, params.Namespace, params.Cluster, gvr)
+		if err != nil {
+			return nil, err
+		}
 
-	return obj, err
+		obj, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+		invalidateClusterNotFound(params.Cluster, err)
+
+		return obj, err
+	})
 }
 
 // GetResourceAtAnyAPIVersion queries the API server for all supported versions of the group and resource related to the passed kind. It then attempts to get the
@@ -174,28 +214,85 @@ func (c *Client) GetResourceAtAnyAPIVersion(ctx context.Context, params GetParam
 }
 
 // GetResources lists Kubernetes resources matching the provided parameters.
-// It supports optional label selectors for filtering and returns a slice of unstructured objects.
+// It supports optional label and field selectors for filtering and returns a slice of unstructured objects, only
+// holding their metadata with MetadataOnly.
 func (c *Client) GetResources(ctx context.Context, params ListParams) ([]*unstructured.Unstructured, error) {
-	resourceInterface, err := c.GetResourceInterface(ctx, params.Token, params.URL, params.Namespace, params.Cluster, converter.K8sKindsToGVRs[strings.ToLower(params.Kind)])
+	objs, _, err := c.GetResourcesPage(ctx, params)
+
+	return objs, err
+}
+
+// GetResourcesPage lists Kubernetes resources like GetResources, and also returns the continue token to get
+// the next page when params.Limit is reached. The token is empty on the last page.
+func (c *Client) GetResourcesPage(ctx context.Context, params ListParams) ([]*unstructured.Unstructured, string, error) {
+	gvr, err := c.ResolveGVR(ctx, params.Token, params.URL, params.Cluster, params.Kind)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	opts := metav1.ListOptions{
+		LabelSelector: params.LabelSelector,
+		FieldSelector: params.FieldSelector,
+		Limit:         params.Limit,
+		Continue:      params.Continue,
 	}
 
-	opts := metav1.ListOptions{}
-	if params.LabelSelector != "" {
-		opts.LabelSelector = params.LabelSelector
+	return c.cachedList(params, gvr, opts, func() ([]*unstructured.Unstructured, string, error) {
+		if params.MetadataOnly && c.MetadataClientCreator != nil {
+			return c.listMetadata(ctx, params, gvr, opts)
+		}
+		resourceInterface, err := c.GetResourceInterface(ctx, params.Token, params.URL, params.Namespace, params.Cluster, gvr)
+		if err != nil {
+			return nil, "", err
+		}
+		list, err := resourceInterface.List(ctx, opts)
+		if err != nil {
+			invalidateClusterNotFound(params.Cluster, err)
+			return nil, "", err
+		}
+
+		objs := make([]*unstructured.Unstructured, len(list.Items))
+		for i := range list.Items {
+			objs[i] = &list.Items[i]
+		}
+
+		return objs, list.GetContinue(), nil
+	})
+}
+
+// listMetadata lists the metadata of the resources with the metadata client, as unstructured PartialObjectMetadata.
+func (c *Client) listMetadata(ctx context.Context, params ListParams, gvr schema.GroupVersionResource, opts metav1.ListOptions) ([]*unstructured.Unstructured, string, error) {
+	clusterID, err := c.getClusterId(ctx, params.Token, params.URL, params.Cluster)
+	if err != nil {
+		return nil, "", err
 	}
-	list, err := resourceInterface.List(ctx, opts)
+	conns, err := c.connections(ctx, params.Token, params.URL, clusterID)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	metadataClient, err := conns.metadata(c.MetadataClientCreator)
+	if err != nil {
+		return nil, "", err
+	}
+	var resourceInterface metadata.ResourceInterface = metadataClient.Resource(gvr)
+	if params.Namespace != "" {
+		resourceInterface = metadataClient.Resource(gvr).Namespace(params.Namespace)
 	}
 
+	list, err := resourceInterface.List(ctx, opts)
+	if err != nil {
+		invalidateClusterNotFound(params.Cluster, err)
+		return nil, "", err
+	}
 	objs := make([]*unstructured.Unstructured, len(list.Items))
 	for i := range list.Items {
-		objs[i] = &list.Items[i]
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&list.Items[i])
+		if err != nil {
+			return nil, "", err
+		}
+		objs[i] = &unstructured.Unstructured{Object: obj}
 	}
 
-	return objs, err
+	return objs, list.GetContinue(), nil
 }
 
 // GetResourcesAtAnyAPIVersion queries the API server for all supported versions of the group and resource related to the passed kind. It then attempts to get the
@@ -251,8 +348,14 @@ func (c *Client) GetResourcesAtAnyAPIVersion(ctx context.Context, params ListPar
 	return objs, err
 }
 
+// GetClusterID returns the ID of the cluster given either its ID or its display name.
+func (c *Client) GetClusterID(ctx context.Context, token string, url string, clusterNameOrID string) (string, error) {
+	return c.getClusterId(ctx, token, url, clusterNameOrID)
+}
+
 // getClusterId returns the cluster's unique ID given either its cluster ID (metadata.name)
-// or its display name (spec.displayName). It uses local caches to avoid redundant lookups.
+// or its display name (spec.displayName). It uses local caches to avoid redundant lookups, whose entries expire
+// after the cluster ID cache TTL.
 //
 // The lookup order is:
 //  1. If the input is "local", return immediately.
@@ -268,13 +371,13 @@ func (c *Client) getClusterId(ctx context.Context, token string, url string, clu
 	}
 
 	// check if the provided identifier is already known to be a cluster ID
-	if _, ok := clusterIdsCache.Load(clusterNameOrID); ok {
+	if _, ok := loadClusterID(&clusterIdsCache, clusterNameOrID); ok {
 		return clusterNameOrID, nil // it is a cluster ID
 	}
 
 	// check if the provided identifier matches a display name cached earlier
-	if clusterID, exists := clustersDisplayNameToIDCache.Load(clusterNameOrID); exists {
-		return clusterID.(string), nil
+	if clusterID, exists := loadClusterID(&clustersDisplayNameToIDCache, clusterNameOrID); exists {
+		return clusterID, nil
 	}
 
 	// try to fetch the cluster directly by its ID
@@ -294,63 +397,47 @@ func (c *Client) getClusterId(ctx context.Context, token string, url string, clu
 		if err != nil {
 			return "", err
 		}
-		for _, cluster := range clusters.Items {
-			clusterID := cluster.GetName()
-			clusterIdsCache.Store(clusterID, struct{}{})
-
-			displayName, found, err := unstructured.NestedString(
-				cluster.Object,
-				"spec",
-				"displayName",
-			)
-			if err != nil {
+		for i := range clusters.Items {
+			if err := storeCluster(&clusters.Items[i]); err != nil {
 				return "", err
 			}
-
-			if found {
-				clustersDisplayNameToIDCache.Store(displayName, clusterID)
-
-				// If the given identifier matches this display name, return its ID.
-				if displayName == clusterNameOrID {
-					return clusterID, nil
-				}
-			}
+		}
+		// If the given identifier matches a display name, return its ID.
+		if clusterID, exists := loadClusterID(&clustersDisplayNameToIDCache, clusterNameOrID); exists {
+			return clusterID, nil
 		}
 
 		return "", fmt.Errorf("cluster '%s' not found", clusterNameOrID)
 	}
 
 	// clusterNameOrIDInput contains the cluster ID. Store it in the cache.
-	clusterID := clusterNameOrID
-	clusterIdsCache.Store(clusterID, struct{}{})
-
-	displayName, found, err := unstructured.NestedString(
-		cluster.Object,
-		"spec",
-		"displayName",
-	)
-	if err != nil {
+	if err := storeCluster(cluster); err != nil {
 		return "", err
 	}
-	if found {
-		clustersDisplayNameToIDCache.Store(displayName, clusterID)
+
+	return clusterNameOrID, nil
+}
+
+// createRestConfig returns the rest.Config for accessing a Kubernetes cluster through Rancher. It's shared by the
+// requests made with the same token when the connection pool is enabled, so it must not be modified.
+func (c *Client) createRestConfig(ctx context.Context, token string, url string, clusterID string) (*rest.Config, error) {
+	conns, err := c.connections(ctx, token, url, clusterID)
+	if err != nil {
+		return nil, err
 	}
 
-	return clusterID, nil
+	return conns.restConfig, nil
 }
 
-// createRestConfig creates a new rest.Config for accessing a Kubernetes cluster through Rancher.
-// It configures the cluster URL, authentication token, and TLS settings based on environment variables.
-func (c *Client) createRestConfig(token string, url string, clusterID string) (*rest.Config, error) {
+// newRestConfig creates a new rest.Config for accessing a Kubernetes cluster through Rancher with the given credentials.
+func (c *Client) newRestConfig(url string, clusterID string, authInfo *clientcmdapi.AuthInfo) (*rest.Config, error) {
 	clusterURL := url + "/k8s/clusters/" + clusterID
 	kubeconfig := clientcmdapi.NewConfig()
 	kubeconfig.Clusters["Cluster"] = &clientcmdapi.Cluster{
 		Server:                clusterURL,
 		InsecureSkipTLSVerify: c.insecure,
 	}
-	kubeconfig.AuthInfos["mcp"] = &clientcmdapi.AuthInfo{
-		Token: token,
-	}
+	kubeconfig.AuthInfos["mcp"] = authInfo
 	kubeconfig.Contexts["Cluster"] = &clientcmdapi.Context{
 		Cluster:  "Cluster",
 		AuthInfo: "mcp",
@@ -365,6 +452,14 @@ func (c *Client) createRestConfig(token string, url string, clusterID string) (*
 	if err != nil {
 		return nil, err
 	}
+	restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &traceTransport{next: rt}
+	})
+	if c.cache != nil {
+		restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &invalidatingTransport{next: rt, cache: c.cache}
+		})
+	}
 
 	return restConfig, nil
 }
@@ -376,12 +471,12 @@ func (c *Client) getAPIVersionsForGR(ctx context.Context, token, url, cluster st
 	if err != nil {
 		return nil, err
 	}
-	restConfig, err := c.createRestConfig(token, url, clusterID)
+	conns, err := c.connections(ctx, token, url, clusterID)
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := c.ClientSetCreator(restConfig)
+	client, err := conns.kubernetes(c.ClientSetCreator)
 	if err != nil {
 		return nil, err
 	}
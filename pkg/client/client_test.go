@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 
@@ -13,6 +14,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/metadata"
+	metadatafake "k8s.io/client-go/metadata/fake"
 	"k8s.io/client-go/rest"
 )
 
@@ -46,61 +49,61 @@ func TestGetClusterId(t *testing.T) {
 	tests := map[string]struct {
 		clusterNameOrIDInput                 string
 		fakeDynClient                        *dynamicfake.FakeDynamicClient
-		clusterIdsCache                      map[string]any
-		clustersDisplayNameToIDCache         map[string]any
-		expectedClusterIdsCache              map[string]any
-		expectedClustersDisplayNameToIDCache map[string]any
+		clusterIdsCache                      map[string]string
+		clustersDisplayNameToIDCache         map[string]string
+		expectedClusterIdsCache              map[string]string
+		expectedClustersDisplayNameToIDCache map[string]string
 		expectedID                           string
 		expectErr                            string
 	}{
 		"should return clusterID if input is a clusterID": {
 			clusterNameOrIDInput:                 clusterID,
 			fakeDynClient:                        dynamicfake.NewSimpleDynamicClient(scheme(), newFakeCluster(clusterID, clusterDN)),
-			expectedClusterIdsCache:              map[string]any{clusterID: struct{}{}},
-			expectedClustersDisplayNameToIDCache: map[string]any{clusterDN: clusterID},
+			expectedClusterIdsCache:              map[string]string{clusterID: clusterID},
+			expectedClustersDisplayNameToIDCache: map[string]string{clusterDN: clusterID},
 			expectedID:                           clusterID,
 		},
 
 		"should return clusterID if input is a cluster displayName": {
 			clusterNameOrIDInput:                 clusterDN,
 			fakeDynClient:                        dynamicfake.NewSimpleDynamicClient(scheme(), newFakeCluster(clusterID, clusterDN)),
-			expectedClusterIdsCache:              map[string]any{clusterID: struct{}{}},
-			expectedClustersDisplayNameToIDCache: map[string]any{clusterDN: clusterID},
+			expectedClusterIdsCache:              map[string]string{clusterID: clusterID},
+			expectedClustersDisplayNameToIDCache: map[string]string{clusterDN: clusterID},
 			expectedID:                           clusterID,
 		},
 
 		"should return clusterID if clusterID is in the cache": {
 			clusterNameOrIDInput:                 clusterID,
-			clusterIdsCache:                      map[string]any{clusterID: struct{}{}},
-			clustersDisplayNameToIDCache:         map[string]any{clusterDN: clusterID},
+			clusterIdsCache:                      map[string]string{clusterID: clusterID},
+			clustersDisplayNameToIDCache:         map[string]string{clusterDN: clusterID},
 			fakeDynClient:                        dynamicfake.NewSimpleDynamicClient(scheme()),
-			expectedClusterIdsCache:              map[string]any{clusterID: struct{}{}},
-			expectedClustersDisplayNameToIDCache: map[string]any{clusterDN: clusterID},
+			expectedClusterIdsCache:              map[string]string{clusterID: clusterID},
+			expectedClustersDisplayNameToIDCache: map[string]string{clusterDN: clusterID},
 			expectedID:                           clusterID,
 		},
 
 		"should return clusterID if displayName is in the cache": {
 			clusterNameOrIDInput:                 clusterDN,
-			clusterIdsCache:                      map[string]any{clusterID: struct{}{}},
-			clustersDisplayNameToIDCache:         map[string]any{clusterDN: clusterID},
+			clusterIdsCache:                      map[string]string{clusterID: clusterID},
+			clustersDisplayNameToIDCache:         map[string]string{clusterDN: clusterID},
 			fakeDynClient:                        dynamicfake.NewSimpleDynamicClient(scheme()),
-			expectedClusterIdsCache:              map[string]any{clusterID: struct{}{}},
-			expectedClustersDisplayNameToIDCache: map[string]any{clusterDN: clusterID},
+			expectedClusterIdsCache:              map[string]string{clusterID: clusterID},
+			expectedClustersDisplayNameToIDCache: map[string]string{clusterDN: clusterID},
 			expectedID:                           clusterID,
 		},
 
 		"local": {
 			clusterNameOrIDInput:                 "local",
-			expectedClusterIdsCache:              map[string]any{},
-			expectedClustersDisplayNameToIDCache: map[string]any{},
+			expectedClusterIdsCache:              map[string]string{},
+			expectedClustersDisplayNameToIDCache: map[string]string{},
 			expectedID:                           "local",
 		},
 
 		"cluster not found": {
 			clusterNameOrIDInput:                 clusterDN,
 			fakeDynClient:                        dynamicfake.NewSimpleDynamicClient(scheme(), newFakeCluster(clusterID, "another cluster")),
-			expectedClusterIdsCache:              map[string]any{clusterID: struct{}{}},
-			expectedClustersDisplayNameToIDCache: map[string]any{"another cluster": clusterID},
+			expectedClusterIdsCache:              map[string]string{clusterID: clusterID},
+			expectedClustersDisplayNameToIDCache: map[string]string{"another cluster": clusterID},
 			expectErr:                            "cluster 'my-display-name' not found",
 		},
 	}
@@ -110,13 +113,13 @@ func TestGetClusterId(t *testing.T) {
 			clusterIdsCache = sync.Map{}
 			if test.clusterIdsCache != nil {
 				for key, value := range test.clusterIdsCache {
-					clusterIdsCache.Store(key, value)
+					storeClusterID(&clusterIdsCache, key, value)
 				}
 			}
 			clustersDisplayNameToIDCache = sync.Map{}
 			if test.clustersDisplayNameToIDCache != nil {
 				for key, value := range test.clustersDisplayNameToIDCache {
-					clustersDisplayNameToIDCache.Store(key, value)
+					storeClusterID(&clustersDisplayNameToIDCache, key, value)
 				}
 			}
 
@@ -140,10 +143,10 @@ func TestGetClusterId(t *testing.T) {
 	}
 }
 
-func syncMapToMap(syncMap *sync.Map) map[string]any {
-	result := make(map[string]any)
+func syncMapToMap(syncMap *sync.Map) map[string]string {
+	result := make(map[string]string)
 	syncMap.Range(func(key, value any) bool {
-		result[key.(string)] = value
+		result[key.(string)] = value.(clusterIDEntry).id
 		return true
 	})
 	return result
@@ -329,3 +332,46 @@ func TestGetResources(t *testing.T) {
 		})
 	}
 }
+
+func TestGetResourcesMetadataOnly(t *testing.T) {
+	fakeNamespace := func(name string, project string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"field.cattle.io/projectId": project},
+			},
+		}
+	}
+	metadataScheme := metadatafake.NewTestScheme()
+	metadataScheme.AddKnownTypeWithName(v1.SchemeGroupVersion.WithKind("Namespace"), &metav1.PartialObjectMetadata{})
+	metadataScheme.AddKnownTypeWithName(v1.SchemeGroupVersion.WithKind("NamespaceList"), &metav1.PartialObjectMetadataList{})
+	fakeMetadataClient := metadatafake.NewSimpleMetadataClient(metadataScheme,
+		fakeNamespace("team-a", "p-1"), fakeNamespace("team-b", "p-1"), fakeNamespace("team-c", "p-2"))
+	c := &Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return nil, errors.New("the metadata-only lists must not use the dynamic client")
+		},
+		MetadataClientCreator: func(inConfig *rest.Config) (metadata.Interface, error) {
+			return fakeMetadataClient, nil
+		},
+	}
+
+	results, err := c.GetResources(context.Background(), ListParams{
+		Cluster:       "local",
+		Kind:          "namespace",
+		URL:           fakeUrl,
+		Token:         fakeToken,
+		LabelSelector: "field.cattle.io/projectId=p-1",
+		MetadataOnly:  true,
+	})
+
+	require.NoError(t, err)
+	actualNames := make([]string, len(results))
+	for i, result := range results {
+		actualNames[i] = result.GetName()
+		assert.Equal(t, "p-1", result.GetLabels()["field.cattle.io/projectId"])
+		assert.NotContains(t, result.Object, "spec")
+	}
+	assert.ElementsMatch(t, []string{"team-a", "team-b"}, actualNames)
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// DefaultClusterIDCacheTTL is how long the cluster IDs and display names are cached by default.
+	DefaultClusterIDCacheTTL = 10 * time.Minute
+	// clusterIDSyncRetryInterval is the time to wait before restarting a management cluster watch that failed.
+	clusterIDSyncRetryInterval = 30 * time.Second
+)
+
+// clusterIdsCache contains the known cluster IDs, and clustersDisplayNameToIDCache the cluster IDs by display name.
+// Both hold clusterIDEntry values.
+var clusterIdsCache = sync.Map{}
+var clustersDisplayNameToIDCache = sync.Map{}
+
+// clusterIDCacheTTL is how long the entries of the cluster ID caches are used. A value lower or equal to 0 disables
+// the expiration.
+var clusterIDCacheTTL = DefaultClusterIDCacheTTL
+
+// clusterIDEntry is a cluster ID cached until it expires, so renamed and deleted clusters are eventually looked up
+// again.
+type clusterIDEntry struct {
+	id        string
+	expiresAt time.Time
+}
+
+// SetClusterIDCacheTTL sets how long the cluster IDs and display names are cached. A value lower or equal to 0
+// disables the expiration, the entries are then only removed when a cluster is not found or by SyncClusterIDs.
+func SetClusterIDCacheTTL(ttl time.Duration) {
+	clusterIDCacheTTL = ttl
+}
+
+// loadClusterID returns the cluster ID cached with the key, removing it if it expired.
+func loadClusterID(cache *sync.Map, key string) (string, bool) {
+	value, ok := cache.Load(key)
+	if !ok {
+		return "", false
+	}
+	entry := value.(clusterIDEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		cache.CompareAndDelete(key, value)
+		return "", false
+	}
+
+	return entry.id, true
+}
+
+// storeClusterID caches the cluster ID with the key.
+func storeClusterID(cache *sync.Map, key string, clusterID string) {
+	entry := clusterIDEntry{id: clusterID}
+	if clusterIDCacheTTL > 0 {
+		entry.expiresAt = time.Now().Add(clusterIDCacheTTL)
+	}
+	cache.Store(key, entry)
+}
+
+// storeCluster caches the ID of the management cluster and its display name. Other display names cached for the
+// cluster are removed, since it may have been renamed.
+func storeCluster(cluster *unstructured.Unstructured) error {
+	clusterID := cluster.GetName()
+	displayName, found, err := unstructured.NestedString(cluster.Object, "spec", "displayName")
+	if err != nil {
+		return err
+	}
+
+	storeClusterID(&clusterIdsCache, clusterID, clusterID)
+	clustersDisplayNameToIDCache.Range(func(key, value any) bool {
+		if value.(clusterIDEntry).id == clusterID && key != displayName {
+			clustersDisplayNameToIDCache.Delete(key)
+		}
+		return true
+	})
+	if found {
+		storeClusterID(&clustersDisplayNameToIDCache, displayName, clusterID)
+	}
+
+	return nil
+}
+
+// InvalidateClusterID removes a cluster, given either its ID or its display name, from the cluster ID caches, so
+// the next request looks it up again.
+func InvalidateClusterID(clusterNameOrID string) {
+	clusterID, ok := loadClusterID(&clustersDisplayNameToIDCache, clusterNameOrID)
+	if !ok {
+		clusterID = clusterNameOrID
+	}
+	clusterIdsCache.Delete(clusterID)
+	clustersDisplayNameToIDCache.Range(func(key, value any) bool {
+		if key == clusterNameOrID || value.(clusterIDEntry).id == clusterID {
+			clustersDisplayNameToIDCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// invalidateClusterNotFound removes the cluster from the cluster ID caches if the error returned by the Rancher
+// proxy is that the cluster itself doesn't exist anymore, not one of its resources.
+func invalidateClusterNotFound(clusterNameOrID string, err error) {
+	if clusterNameOrID == "local" || !apierrors.IsNotFound(err) {
+		return
+	}
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return
+	}
+	details := status.Status().Details
+	if details.Group == converter.ManagementGroup && details.Kind == "clusters" {
+		InvalidateClusterID(clusterNameOrID)
+	}
+}
+
+// SyncClusterIDs keeps the cluster ID caches in sync with the management clusters of the local cluster until the
+// context is cancelled, so renamed and deleted clusters are updated without waiting for their expiration. The
+// clusters are listed again with the credentials returned by the provider each time the watch is closed.
+func (c *Client) SyncClusterIDs(ctx context.Context, provider CredentialProvider) {
+	for ctx.Err() == nil {
+		err := c.syncClusterIDs(ctx, provider)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		zap.L().Warn("failed to sync cluster IDs", zap.Error(err))
+		select {
+		case <-ctx.Done():
+		case <-time.After(clusterIDSyncRetryInterval):
+		}
+	}
+}
+
+// syncClusterIDs caches all the management clusters and watches them until the watch is closed.
+func (c *Client) syncClusterIDs(ctx context.Context, provider CredentialProvider) error {
+	credentials, err := provider.Credentials(ctx, nil)
+	if err != nil {
+		return err
+	}
+	clusterInterface, err := c.GetResourceInterface(ctx, credentials.Token, credentials.URL, "", "local", converter.K8sKindsToGVRs["managementcluster"])
+	if err != nil {
+		return err
+	}
+	clusters, err := clusterInterface.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	listed := map[string]bool{}
+	for i := range clusters.Items {
+		listed[clusters.Items[i].GetName()] = true
+		if err := storeCluster(&clusters.Items[i]); err != nil {
+			return err
+		}
+	}
+	clusterIdsCache.Range(func(key, _ any) bool {
+		if !listed[key.(string)] {
+			InvalidateClusterID(key.(string))
+		}
+		return true
+	})
+
+	watcher, err := clusterInterface.Watch(ctx, metav1.ListOptions{ResourceVersion: clusters.GetResourceVersion()})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for event := range watcher.ResultChan() {
+		cluster, ok := event.Object.(*unstructured.Unstructured)
+		switch {
+		case event.Type == watch.Error:
+			return apierrors.FromObject(event.Object)
+		case !ok:
+		case event.Type == watch.Added || event.Type == watch.Modified:
+			if err := storeCluster(cluster); err != nil {
+				return err
+			}
+		case event.Type == watch.Deleted:
+			InvalidateClusterID(cluster.GetName())
+		}
+	}
+
+	return nil
+}
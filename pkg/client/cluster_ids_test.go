@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// resetClusterIDCaches empties the cluster ID caches before and after the test.
+func resetClusterIDCaches(t *testing.T) {
+	clusterIdsCache, clustersDisplayNameToIDCache = sync.Map{}, sync.Map{}
+	t.Cleanup(func() {
+		clusterIdsCache, clustersDisplayNameToIDCache = sync.Map{}, sync.Map{}
+		SetClusterIDCacheTTL(DefaultClusterIDCacheTTL)
+	})
+}
+
+func TestClusterIDCacheTTL(t *testing.T) {
+	resetClusterIDCaches(t)
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), newFakeCluster("c-m-1", "prod"))
+	c := &Client{
+		DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+
+	SetClusterIDCacheTTL(time.Hour)
+	clusterID, err := c.getClusterId(t.Context(), fakeToken, fakeUrl, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "c-m-1", clusterID)
+	assert.Equal(t, map[string]string{"prod": "c-m-1"}, syncMapToMap(&clustersDisplayNameToIDCache))
+
+	// the cluster is renamed, the cached display name is used until it expires
+	_, err = fakeDynClient.Resource(converter.K8sKindsToGVRs["managementcluster"]).Update(t.Context(), newFakeCluster("c-m-1", "production"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	clusterID, err = c.getClusterId(t.Context(), fakeToken, fakeUrl, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "c-m-1", clusterID)
+
+	clustersDisplayNameToIDCache.Store("prod", clusterIDEntry{id: "c-m-1", expiresAt: time.Now().Add(-time.Second)})
+	_, err = c.getClusterId(t.Context(), fakeToken, fakeUrl, "prod")
+	assert.EqualError(t, err, "cluster 'prod' not found")
+	assert.Equal(t, map[string]string{"production": "c-m-1"}, syncMapToMap(&clustersDisplayNameToIDCache), "the old display name should be removed")
+}
+
+func TestInvalidateClusterNotFound(t *testing.T) {
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	tests := map[string]struct {
+		err              error
+		expectedIdsCache map[string]string
+	}{
+		"cluster not found": {
+			err:              apierrors.NewNotFound(schema.GroupResource{Group: "management.cattle.io", Resource: "clusters"}, "c-m-1"),
+			expectedIdsCache: map[string]string{},
+		},
+		"resource not found": {
+			err:              apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"),
+			expectedIdsCache: map[string]string{"c-m-1": "c-m-1"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resetClusterIDCaches(t)
+			storeClusterID(&clusterIdsCache, "c-m-1", "c-m-1")
+			storeClusterID(&clustersDisplayNameToIDCache, "prod", "c-m-1")
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme())
+			fakeDynClient.PrependReactor("get", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, test.err
+			})
+			c := &Client{
+				DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+
+			_, err := c.GetResourceByGVR(t.Context(), GetParams{Cluster: "prod", Namespace: "default", Name: "web", URL: fakeUrl, Token: fakeToken}, podsGVR)
+
+			assert.True(t, apierrors.IsNotFound(err))
+			assert.Equal(t, test.expectedIdsCache, syncMapToMap(&clusterIdsCache))
+		})
+	}
+}
+
+func TestSyncClusterIDs(t *testing.T) {
+	resetClusterIDCaches(t)
+	storeClusterID(&clusterIdsCache, "c-m-deleted", "c-m-deleted")
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), newFakeCluster("c-m-1", "prod"), newFakeCluster("c-m-2", "staging"))
+	watchers := make(chan *watch.FakeWatcher, 1)
+	fakeDynClient.PrependWatchReactor("clusters", func(k8stesting.Action) (bool, watch.Interface, error) {
+		watcher := watch.NewFake()
+		watchers <- watcher
+		return true, watcher, nil
+	})
+	c := &Client{
+		DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go c.SyncClusterIDs(ctx, StaticCredentials{URL: fakeUrl, Token: fakeToken})
+
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		assert.Equal(collect, map[string]string{"c-m-1": "c-m-1", "c-m-2": "c-m-2"}, syncMapToMap(&clusterIdsCache))
+		assert.Equal(collect, map[string]string{"prod": "c-m-1", "staging": "c-m-2"}, syncMapToMap(&clustersDisplayNameToIDCache))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	watcher := <-watchers
+	watcher.Modify(newFakeCluster("c-m-1", "production"))
+	watcher.Delete(newFakeCluster("c-m-2", "staging"))
+
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		assert.Equal(collect, map[string]string{"c-m-1": "c-m-1"}, syncMapToMap(&clusterIdsCache))
+		assert.Equal(collect, map[string]string{"production": "c-m-1"}, syncMapToMap(&clustersDisplayNameToIDCache))
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,249 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// URLHeader is the header with the URL of the Rancher server. It's sent by the Rancher AI agent, and set by the
+	// CredentialsMiddleware for the other providers, so the tools read the URL from it with any transport.
+	URLHeader = "R_url"
+	// TokenHeader is the header with a Rancher token, passed through without validation by the OAuthMiddleware.
+	TokenHeader = "R_token"
+
+	// secretCredentialsTTL is how long the credentials read from a Secret are used before reading it again.
+	secretCredentialsTTL = time.Minute
+	// execCredentialsTimeout is the maximum time the command of the ExecCredentials can run.
+	execCredentialsTimeout = 30 * time.Second
+)
+
+// The keys of the Secret read by the SecretCredentials.
+const (
+	SecretURLKey   = "url"
+	SecretTokenKey = "token"
+)
+
+// rancherClusterPath matches the path of the Rancher proxy to a cluster in the server of a kubeconfig
+// generated by Rancher (e.g. https://rancher.example.com/k8s/clusters/local).
+var rancherClusterPath = regexp.MustCompile(`/k8s/clusters/[^/]+/?$`)
+
+// tokenCtxKey is the context key of the token of the request.
+var tokenCtxKey = &contextKey{"token"}
+
+// WithToken sets the token of the request into the context.
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenCtxKey, token)
+}
+
+// Token gets the token of the request from the context, or an empty string if there is none.
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(tokenCtxKey).(string)
+
+	return token
+}
+
+// Credentials are the URL of the Rancher server and the token used to access it.
+type Credentials struct {
+	URL   string
+	Token string
+}
+
+// CredentialProvider returns the credentials used by a request. extra is nil if the transport doesn't have it.
+type CredentialProvider interface {
+	Credentials(ctx context.Context, extra *mcp.RequestExtra) (Credentials, error)
+}
+
+// HeaderCredentials returns the URL from the R_url header and the token set in the context by the OAuthMiddleware.
+// It's the provider used with the StreamableHTTP transport, where the Rancher AI agent sends them with each request.
+type HeaderCredentials struct {
+	// URL is the URL of the Rancher server used by the requests without the R_url header, e.g. the ones of the MCP
+	// clients authenticated with an Auth token when the client impersonates their users.
+	URL string
+}
+
+// Credentials implements CredentialProvider.
+func (c HeaderCredentials) Credentials(ctx context.Context, extra *mcp.RequestExtra) (Credentials, error) {
+	url := c.URL
+	if extra != nil && extra.Header.Get(URLHeader) != "" {
+		url = extra.Header.Get(URLHeader)
+	}
+
+	return Credentials{URL: url, Token: Token(ctx)}, nil
+}
+
+// StaticCredentials returns the same credentials for every request. It's the provider used with the stdio
+// transport, where requests don't have headers.
+type StaticCredentials Credentials
+
+// Credentials implements CredentialProvider.
+func (c StaticCredentials) Credentials(context.Context, *mcp.RequestExtra) (Credentials, error) {
+	return Credentials(c), nil
+}
+
+// CredentialsFromEnvironment returns the credentials set with the RANCHER_URL and RANCHER_TOKEN env vars. If they
+// are not set, the Rancher URL and the token are taken from the current context of the kubeconfig, which must be
+// generated by Rancher. The kubeconfig is found like kubectl does, using the KUBECONFIG env var or ~/.kube/config.
+func CredentialsFromEnvironment() (StaticCredentials, error) {
+	url, token := os.Getenv("RANCHER_URL"), os.Getenv("RANCHER_TOKEN")
+	if url != "" && token != "" {
+		return StaticCredentials{URL: url, Token: token}, nil
+	}
+	if url != "" || token != "" {
+		return StaticCredentials{}, errors.New("RANCHER_URL and RANCHER_TOKEN must be set together")
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return StaticCredentials{}, fmt.Errorf("failed to load kubeconfig, set RANCHER_URL and RANCHER_TOKEN instead: %w", err)
+	}
+	if !rancherClusterPath.MatchString(config.Host) {
+		return StaticCredentials{}, fmt.Errorf("the kubeconfig server %s is not a Rancher cluster URL (https://<rancher>/k8s/clusters/<cluster>)", config.Host)
+	}
+	token = config.BearerToken
+	if token == "" && config.BearerTokenFile != "" {
+		data, err := os.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			return StaticCredentials{}, fmt.Errorf("failed to read the kubeconfig token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token == "" {
+		return StaticCredentials{}, errors.New("the kubeconfig user must authenticate with a Rancher token")
+	}
+
+	return StaticCredentials{URL: rancherClusterPath.ReplaceAllString(config.Host, ""), Token: token}, nil
+}
+
+// cachedCredentials are credentials shared by the requests until they expire.
+type cachedCredentials struct {
+	mu          sync.Mutex
+	credentials Credentials
+	expiresAt   time.Time
+	now         func() time.Time
+}
+
+// get returns the cached credentials, or refreshes them if they expired. The credentials without expiration are
+// kept until the process stops.
+func (c *cachedCredentials) get(refresh func() (Credentials, time.Time, error)) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.credentials.Token != "" && (c.expiresAt.IsZero() || c.now().Before(c.expiresAt)) {
+		return c.credentials, nil
+	}
+	credentials, expiresAt, err := refresh()
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.credentials, c.expiresAt = credentials, expiresAt
+
+	return credentials, nil
+}
+
+// SecretCredentials returns the URL and the token stored in the url and token keys of a Secret, e.g. a Secret with
+// the API key of a Rancher ServiceAccount in the cluster the server runs in. The Secret is read again every minute,
+// so the token can be rotated without a restart.
+type SecretCredentials struct {
+	clientSet kubernetes.Interface
+	namespace string
+	name      string
+	cache     cachedCredentials
+}
+
+// NewSecretCredentials creates a SecretCredentials reading the Secret with the clientset.
+func NewSecretCredentials(clientSet kubernetes.Interface, namespace string, name string) *SecretCredentials {
+	return &SecretCredentials{
+		clientSet: clientSet,
+		namespace: namespace,
+		name:      name,
+		cache:     cachedCredentials{now: time.Now},
+	}
+}
+
+// Credentials implements CredentialProvider.
+func (c *SecretCredentials) Credentials(ctx context.Context, _ *mcp.RequestExtra) (Credentials, error) {
+	return c.cache.get(func() (Credentials, time.Time, error) {
+		secret, err := c.clientSet.CoreV1().Secrets(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+		if err != nil {
+			return Credentials{}, time.Time{}, fmt.Errorf("failed to get the credentials Secret %s/%s: %w", c.namespace, c.name, err)
+		}
+		credentials := Credentials{
+			URL:   strings.TrimSpace(string(secret.Data[SecretURLKey])),
+			Token: strings.TrimSpace(string(secret.Data[SecretTokenKey])),
+		}
+		if credentials.URL == "" || credentials.Token == "" {
+			return Credentials{}, time.Time{}, fmt.Errorf("the credentials Secret %s/%s must have the %s and %s keys", c.namespace, c.name, SecretURLKey, SecretTokenKey)
+		}
+
+		return credentials, c.cache.now().Add(secretCredentialsTTL), nil
+	})
+}
+
+// ExecCredentials returns the token printed by a command, like the exec credential plugins of kubectl. The command
+// must print an ExecCredential of the client.authentication.k8s.io/v1 API group, and is run again when the
+// expirationTimestamp of its status is reached.
+type ExecCredentials struct {
+	url     string
+	command []string
+	cache   cachedCredentials
+}
+
+// NewExecCredentials creates an ExecCredentials running the command, with its arguments, for the Rancher server URL.
+func NewExecCredentials(url string, command []string) *ExecCredentials {
+	return &ExecCredentials{
+		url:     url,
+		command: command,
+		cache:   cachedCredentials{now: time.Now},
+	}
+}
+
+// Credentials implements CredentialProvider.
+func (c *ExecCredentials) Credentials(ctx context.Context, _ *mcp.RequestExtra) (Credentials, error) {
+	if len(c.command) == 0 {
+		return Credentials{}, errors.New("the credentials command is empty")
+	}
+
+	return c.cache.get(func() (Credentials, time.Time, error) {
+		ctx, cancel := context.WithTimeout(ctx, execCredentialsTimeout)
+		defer cancel()
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...)
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			return Credentials{}, time.Time{}, fmt.Errorf("failed to run the credentials command %s: %w: %s", c.command[0], err, strings.TrimSpace(stderr.String()))
+		}
+
+		var execCredential clientauthenticationv1.ExecCredential
+		if err := json.Unmarshal(stdout.Bytes(), &execCredential); err != nil {
+			return Credentials{}, time.Time{}, fmt.Errorf("failed to decode the ExecCredential printed by %s: %w", c.command[0], err)
+		}
+		if execCredential.Status == nil || execCredential.Status.Token == "" {
+			return Credentials{}, time.Time{}, fmt.Errorf("the ExecCredential printed by %s has no token", c.command[0])
+		}
+		var expiresAt time.Time
+		if execCredential.Status.ExpirationTimestamp != nil {
+			expiresAt = execCredential.Status.ExpirationTimestamp.Time
+		}
+
+		return Credentials{URL: c.url, Token: execCredential.Status.Token}, expiresAt, nil
+	})
+}
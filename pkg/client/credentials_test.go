@@ -0,0 +1,222 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: %s
+users:
+- name: local
+  user:
+    token: %s
+contexts:
+- name: local
+  context:
+    cluster: local
+    user: local
+current-context: local
+`
+
+func TestCredentialsFromEnvironment(t *testing.T) {
+	tests := map[string]struct {
+		env              map[string]string
+		kubeconfigServer string
+		kubeconfigToken  string
+		expected         StaticCredentials
+		expectedErr      string
+	}{
+		"rancher url and token": {
+			env:      map[string]string{"RANCHER_URL": fakeUrl, "RANCHER_TOKEN": "token-abc"},
+			expected: StaticCredentials{URL: fakeUrl, Token: "token-abc"},
+		},
+		"rancher url without token": {
+			env:         map[string]string{"RANCHER_URL": fakeUrl},
+			expectedErr: "RANCHER_URL and RANCHER_TOKEN must be set together",
+		},
+		"rancher kubeconfig": {
+			kubeconfigServer: fakeUrl + "/k8s/clusters/c-m-abc",
+			kubeconfigToken:  "kubeconfig-u-abc:secret",
+			expected:         StaticCredentials{URL: fakeUrl, Token: "kubeconfig-u-abc:secret"},
+		},
+		"kubeconfig of other server": {
+			kubeconfigServer: "https://10.0.0.1:6443",
+			kubeconfigToken:  "token-abc",
+			expectedErr:      "the kubeconfig server https://10.0.0.1:6443 is not a Rancher cluster URL",
+		},
+		"kubeconfig without token": {
+			kubeconfigServer: fakeUrl + "/k8s/clusters/local",
+			expectedErr:      "the kubeconfig user must authenticate with a Rancher token",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("RANCHER_URL", tt.env["RANCHER_URL"])
+			t.Setenv("RANCHER_TOKEN", tt.env["RANCHER_TOKEN"])
+			kubeconfig := filepath.Join(t.TempDir(), "config")
+			content := fmt.Sprintf(testKubeconfig, tt.kubeconfigServer, tt.kubeconfigToken)
+			require.NoError(t, os.WriteFile(kubeconfig, []byte(content), 0o600))
+			t.Setenv("KUBECONFIG", kubeconfig)
+
+			credentials, err := CredentialsFromEnvironment()
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, credentials)
+		})
+	}
+}
+
+func TestSecretCredentials(t *testing.T) {
+	secret := func(data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "rancher-credentials", Namespace: "cattle-ai-agent-system"}, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+
+	tests := map[string]struct {
+		secret        *corev1.Secret
+		expected      Credentials
+		expectedError string
+	}{
+		"url and token": {
+			secret:   secret(map[string]string{"url": fakeUrl + "\n", "token": "token-abc:secret\n"}),
+			expected: Credentials{URL: fakeUrl, Token: "token-abc:secret"},
+		},
+		"missing token": {
+			secret:        secret(map[string]string{"url": fakeUrl}),
+			expectedError: "the credentials Secret cattle-ai-agent-system/rancher-credentials must have the url and token keys",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := NewSecretCredentials(fake.NewClientset(test.secret), "cattle-ai-agent-system", "rancher-credentials")
+
+			credentials, err := provider.Credentials(t.Context(), nil)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, credentials)
+		})
+	}
+}
+
+func TestSecretCredentialsRotation(t *testing.T) {
+	clientSet := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rancher-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte(fakeUrl), "token": []byte("token-1")},
+	})
+	provider := NewSecretCredentials(clientSet, "default", "rancher-credentials")
+	now := time.Now()
+	provider.cache.now = func() time.Time { return now }
+
+	credentials, err := provider.Credentials(t.Context(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", credentials.Token)
+
+	_, err = clientSet.CoreV1().Secrets("default").Update(t.Context(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rancher-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte(fakeUrl), "token": []byte("token-2")},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	credentials, err = provider.Credentials(t.Context(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", credentials.Token, "the cached token should be used")
+
+	now = now.Add(secretCredentialsTTL + time.Second)
+	credentials, err = provider.Credentials(t.Context(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", credentials.Token, "the rotated token should be read")
+}
+
+func TestExecCredentials(t *testing.T) {
+	writeScript := func(t *testing.T, output string) string {
+		dir := t.TempDir()
+		script := filepath.Join(dir, "credentials.sh")
+		content := fmt.Sprintf("#!/bin/sh\necho run >> %s\ncat <<'EOF'\n%s\nEOF\n", filepath.Join(dir, "runs"), output)
+		require.NoError(t, os.WriteFile(script, []byte(content), 0o700))
+		return script
+	}
+	runs := func(t *testing.T, script string) int {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(script), "runs"))
+		require.NoError(t, err)
+		return strings.Count(string(data), "run")
+	}
+
+	tests := map[string]struct {
+		output        string
+		calls         int
+		expected      Credentials
+		expectedRuns  int
+		expectedError string
+	}{
+		"token without expiration": {
+			output:       `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"token-abc:secret"}}`,
+			calls:        2,
+			expected:     Credentials{URL: fakeUrl, Token: "token-abc:secret"},
+			expectedRuns: 1,
+		},
+		"expired token": {
+			output:       `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"token-abc:secret","expirationTimestamp":"2020-01-01T00:00:00Z"}}`,
+			calls:        2,
+			expected:     Credentials{URL: fakeUrl, Token: "token-abc:secret"},
+			expectedRuns: 2,
+		},
+		"no token": {
+			output:        `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{}}`,
+			calls:         1,
+			expectedError: "has no token",
+		},
+		"invalid output": {
+			output:        "token-abc:secret",
+			calls:         1,
+			expectedError: "failed to decode the ExecCredential",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			script := writeScript(t, test.output)
+			provider := NewExecCredentials(fakeUrl, []string{script})
+
+			var credentials Credentials
+			var err error
+			for range test.calls {
+				credentials, err = provider.Credentials(t.Context(), nil)
+			}
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, credentials)
+			assert.Equal(t, test.expectedRuns, runs(t, script))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// discoveryCacheTTL is how long the resources discovered in a cluster are cached.
+	discoveryCacheTTL = 10 * time.Minute
+	// discoveryMinRefreshInterval is the minimum time between refreshes triggered by kinds not found in the cache,
+	// so resources installed recently are found without querying the API server for every unknown kind.
+	discoveryMinRefreshInterval = 30 * time.Second
+)
+
+// discoveryCache holds the discoveredResources of each cluster, keyed by Rancher URL and cluster ID.
+var discoveryCache = sync.Map{}
+
+// discoveredResources holds the resources served by a cluster and when they were discovered.
+type discoveredResources struct {
+	resources    []discoveredResource
+	discoveredAt time.Time
+}
+
+// discoveredResource is a resource served by a cluster at the preferred version of its group.
+type discoveredResource struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	singular   string
+	shortNames []string
+}
+
+// matches returns true if the name is the kind, the plural or singular name, or a short name of the resource.
+// The name may be qualified by the group of the resource (e.g. certificate.cert-manager.io).
+func (r discoveredResource) matches(name string) bool {
+	if r.gvr.Group != "" {
+		name = strings.TrimSuffix(name, "."+r.gvr.Group)
+	}
+
+	return name == strings.ToLower(r.kind) || name == r.gvr.Resource || name == r.singular || slices.Contains(r.shortNames, name)
+}
+
+// ResolveGVR returns the GroupVersionResource of the given kind. Kinds known by the converter package are resolved
+// directly, other kinds are discovered in the cluster, so any custom resource can be used by its kind, plural name,
+// short name or kind qualified by its group (e.g. certificate.cert-manager.io). Discovered resources are cached per cluster.
+func (c *Client) ResolveGVR(ctx context.Context, token string, url string, cluster string, kind string) (schema.GroupVersionResource, error) {
+	kind = strings.ToLower(kind)
+	if gvr, ok := converter.K8sKindsToGVRs[kind]; ok {
+		return gvr, nil
+	}
+
+	clusterID, err := c.getClusterId(ctx, token, url, cluster)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+
+	cacheKey := url + "/" + clusterID
+	if value, ok := discoveryCache.Load(cacheKey); ok && time.Since(value.(*discoveredResources).discoveredAt) < discoveryCacheTTL {
+		cached := value.(*discoveredResources)
+		if gvr, err := findResource(cached.resources, kind); err != nil || gvr != (schema.GroupVersionResource{}) {
+			return gvr, err
+		}
+		if time.Since(cached.discoveredAt) < discoveryMinRefreshInterval {
+			return schema.GroupVersionResource{}, fmt.Errorf("unknown kind: %s in cluster %s", kind, cluster)
+		}
+	}
+
+	resources, err := c.discoverResources(ctx, token, url, clusterID)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to discover the resources of cluster %s: %w", cluster, err)
+	}
+	discoveryCache.Store(cacheKey, &discoveredResources{resources: resources, discoveredAt: time.Now()})
+
+	gvr, err := findResource(resources, kind)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	if gvr == (schema.GroupVersionResource{}) {
+		return schema.GroupVersionResource{}, fmt.Errorf("unknown kind: %s in cluster %s", kind, cluster)
+	}
+
+	return gvr, nil
+}
+
+// findResource returns the GroupVersionResource of the resource matching the name, or an empty one if none matches.
+// It returns an error if the name matches resources of several groups.
+func findResource(resources []discoveredResource, name string) (schema.GroupVersionResource, error) {
+	var matches []schema.GroupVersionResource
+	for _, resource := range resources {
+		if resource.matches(name) {
+			matches = append(matches, resource.gvr)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return schema.GroupVersionResource{}, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	var candidates []string
+	for _, gvr := range matches {
+		candidates = append(candidates, gvr.Resource+"."+gvr.Group)
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("kind %s is ambiguous, qualify it with its group: %s", name, strings.Join(candidates, ", "))
+}
+
+// discoverResources returns the resources served by the cluster at the preferred version of their group.
+func (c *Client) discoverResources(ctx context.Context, token string, url string, clusterID string) ([]discoveredResource, error) {
+	conns, err := c.connections(ctx, token, url, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	clientSet, err := conns.kubernetes(c.ClientSetCreator)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, resourceLists, err := clientSet.Discovery().ServerGroupsAndResources()
+	// the resources of the groups that could be discovered are still usable
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	preferredVersions := map[string]string{}
+	for _, group := range groups {
+		preferredVersions[group.Name] = group.PreferredVersion.Version
+	}
+
+	var resources []discoveredResource
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil || gv.Version != preferredVersions[gv.Group] {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			// skip subresources such as pods/log
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			resources = append(resources, discoveredResource{
+				gvr:        gv.WithResource(resource.Name),
+				kind:       resource.Kind,
+				singular:   resource.SingularName,
+				shortNames: resource.ShortNames,
+			})
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// fakeDiscoveryResources are the resources served by the fake cluster used in the discovery tests.
+var fakeDiscoveryResources = []*metav1.APIResourceList{
+	{
+		GroupVersion: "cert-manager.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "certificates", SingularName: "certificate", Kind: "Certificate", ShortNames: []string{"cert", "certs"}, Namespaced: true},
+			{Name: "certificates/status", Kind: "Certificate", Namespaced: true},
+			{Name: "issuers", SingularName: "issuer", Kind: "Issuer", Namespaced: true},
+		},
+	},
+	{
+		GroupVersion: "networking.istio.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "virtualservices", SingularName: "virtualservice", Kind: "VirtualService", ShortNames: []string{"vs"}, Namespaced: true},
+		},
+	},
+	{
+		GroupVersion: "networking.istio.io/v1beta1",
+		APIResources: []metav1.APIResource{
+			{Name: "virtualservices", SingularName: "virtualservice", Kind: "VirtualService", ShortNames: []string{"vs"}, Namespaced: true},
+		},
+	},
+	{
+		GroupVersion: "acme.cert-manager.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "issuers", SingularName: "issuer", Kind: "Issuer", Namespaced: true},
+		},
+	},
+}
+
+func TestResolveGVR(t *testing.T) {
+	tests := map[string]struct {
+		kind          string
+		expectedGVR   schema.GroupVersionResource
+		expectedError string
+	}{
+		"known kind": {
+			kind:        "Deployment",
+			expectedGVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		"discovered kind": {
+			kind:        "Certificate",
+			expectedGVR: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		},
+		"discovered kind at the preferred version": {
+			kind:        "VirtualService",
+			expectedGVR: schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1", Resource: "virtualservices"},
+		},
+		"plural name": {
+			kind:        "virtualservices",
+			expectedGVR: schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1", Resource: "virtualservices"},
+		},
+		"short name": {
+			kind:        "cert",
+			expectedGVR: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		},
+		"kind qualified by its group": {
+			kind:        "Issuer.acme.cert-manager.io",
+			expectedGVR: schema.GroupVersionResource{Group: "acme.cert-manager.io", Version: "v1", Resource: "issuers"},
+		},
+		"ambiguous kind": {
+			kind:          "Issuer",
+			expectedError: "kind issuer is ambiguous, qualify it with its group: issuers.cert-manager.io, issuers.acme.cert-manager.io",
+		},
+		"unknown kind": {
+			kind:          "Gateway",
+			expectedError: "unknown kind: gateway in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			discoveryCache = sync.Map{}
+			c := &Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					clientset := fake.NewClientset()
+					clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = fakeDiscoveryResources
+					return clientset, nil
+				},
+			}
+
+			gvr, err := c.ResolveGVR(t.Context(), fakeToken, fakeUrl, "local", test.kind)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedGVR, gvr)
+		})
+	}
+}
+
+func TestResolveGVRCache(t *testing.T) {
+	discoveryCache = sync.Map{}
+	discoveries := 0
+	c := &Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			discoveries++
+			clientset := fake.NewClientset()
+			clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = fakeDiscoveryResources
+			return clientset, nil
+		},
+	}
+
+	_, err := c.ResolveGVR(t.Context(), fakeToken, fakeUrl, "local", "Certificate")
+	require.NoError(t, err)
+	_, err = c.ResolveGVR(t.Context(), fakeToken, fakeUrl, "local", "VirtualService")
+	require.NoError(t, err)
+	assert.Equal(t, 1, discoveries, "resources are discovered once")
+
+	_, err = c.ResolveGVR(t.Context(), fakeToken, fakeUrl, "local", "Gateway")
+	require.Error(t, err)
+	assert.Equal(t, 1, discoveries, "unknown kinds don't refresh recently discovered resources")
+
+	cached, _ := discoveryCache.Load(fakeUrl + "/local")
+	cached.(*discoveredResources).discoveredAt = time.Now().Add(-discoveryMinRefreshInterval)
+	_, err = c.ResolveGVR(t.Context(), fakeToken, fakeUrl, "local", "Gateway")
+	require.Error(t, err)
+	assert.Equal(t, 2, discoveries, "unknown kinds refresh the resources discovered before the minimum refresh interval")
+}
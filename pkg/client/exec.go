@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecParams holds the parameters required to run a command in a container.
+type ExecParams struct {
+	Cluster   string   // The Cluster ID.
+	Namespace string   // The Namespace of the Pod.
+	Name      string   // The Name of the Pod.
+	Container string   // The Container where the command is run.
+	Command   []string // The Command and its arguments. It isn't run in a shell.
+	URL       string   // The base URL of the Rancher server.
+	Token     string   // The authentication Token for Steve.
+}
+
+// newExecutor creates an executor for the exec subresource that uses WebSockets, falling back to SPDY
+// for API servers that don't support them.
+func newExecutor(cfg *rest.Config, execURL *url.URL) (remotecommand.Executor, error) {
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(cfg, "POST", execURL)
+	if err != nil {
+		return nil, err
+	}
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(cfg, "GET", execURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
+
+// ExecInPod runs a command in a container using the exec subresource, writing its output to stdout and stderr.
+func (c *Client) ExecInPod(ctx context.Context, params ExecParams, stdout io.Writer, stderr io.Writer) error {
+	clusterID, err := c.getClusterId(ctx, params.Token, params.URL, params.Cluster)
+	if err != nil {
+		return err
+	}
+	restConfig, err := c.createRestConfig(ctx, params.Token, params.URL, clusterID)
+	if err != nil {
+		return err
+	}
+
+	execURL, err := url.Parse(restConfig.Host)
+	if err != nil {
+		return err
+	}
+	execURL.Path = path.Join(execURL.Path, "/api/v1/namespaces", params.Namespace, "pods", params.Name, "exec")
+	query := url.Values{}
+	for _, arg := range params.Command {
+		query.Add("command", arg)
+	}
+	query.Set("container", params.Container)
+	query.Set("stdout", "true")
+	query.Set("stderr", "true")
+	execURL.RawQuery = query.Encode()
+
+	executor, err := c.ExecutorCreator(restConfig, execURL)
+	if err != nil {
+		return err
+	}
+
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
@@ -0,0 +1,451 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// ChangeHistoryConfigMap is the prefix of the names of the ConfigMaps storing the changes made through the Client
+	// by each user in each cluster, followed by the hash of the user.
+	ChangeHistoryConfigMap = "rancher-ai-mcp-changes"
+	// DefaultChangeHistoryNamespace is the default namespace of the ChangeHistoryConfigMap.
+	DefaultChangeHistoryNamespace = "cattle-ai-agent-system"
+	// DefaultChangeHistorySize is the default number of changes kept in each cluster.
+	DefaultChangeHistorySize = 10
+
+	changesKey = "changes"
+	// maxChangeHistoryBytes keeps the ChangeHistoryConfigMap under the size limit of the objects stored in etcd, the
+	// oldest changes are dropped above it.
+	maxChangeHistoryBytes = 900 * 1024
+	// scaleSubresource is the only subresource whose writes are recorded, since they change the spec of the resource.
+	scaleSubresource = "scale"
+)
+
+// The operations of the recorded changes.
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// ErrChangeHistoryDisabled is returned by Changes and UndoChange when EnableChangeHistory wasn't called.
+var ErrChangeHistoryDisabled = errors.New("the change history is disabled")
+
+var (
+	configMapsGVR = corev1.SchemeGroupVersion.WithResource("configmaps")
+	secretsGVR    = corev1.SchemeGroupVersion.WithResource("secrets")
+)
+
+// Change is a create, update or delete made through a resource interface returned by GetResourceInterface.
+type Change struct {
+	ID        string `json:"id"`
+	Time      string `json:"time"`
+	Operation string `json:"operation"`
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// User identifies the user who made the change, by the name of the validated user or by the hash of its token.
+	User string `json:"user"`
+	// Hash is the hash of the spec of the resource after the change, empty for a delete. Undoing a create or an
+	// update fails if the spec changed since, unless it's forced.
+	Hash string `json:"hash,omitempty"`
+	// Before is the state of the resource before the change, without its status and with its sensitive fields
+	// redacted. It's empty for a create.
+	Before map[string]any `json:"before,omitempty"`
+	// Redacted is true if sensitive fields were redacted from Before, the change can't be undone then.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// GVR returns the group, version and resource of the changed resource.
+func (c Change) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: c.Group, Version: c.Version, Resource: c.Resource}
+}
+
+// UndoParams holds the parameters required to undo a change.
+type UndoParams struct {
+	Cluster string // The Cluster ID.
+	URL     string // The base URL of the Rancher server.
+	Token   string // The authentication Token for Steve.
+	ID      string // The ID of the change.
+	Force   bool   // Undo the change even if the resource changed since.
+}
+
+// changeHistory is the ring buffer of the changes made by each user in each cluster, stored in a ConfigMap of the
+// cluster with the credentials of the user making the change.
+type changeHistory struct {
+	namespace string
+	size      int
+}
+
+// EnableChangeHistory records the state of the resources before every create, update, patch, apply and delete made
+// with a resource interface returned by GetResourceInterface, keeping the last size changes of each user in each
+// cluster in a ConfigMap of the namespace. Secrets aren't recorded, so their data isn't copied to the ConfigMap, and
+// the other sensitive fields are redacted.
+func (c *Client) EnableChangeHistory(namespace string, size int) {
+	c.history = &changeHistory{namespace: namespace, size: size}
+}
+
+// Changes returns the changes recorded in a cluster for the user of the token, newest first.
+func (c *Client) Changes(ctx context.Context, token string, url string, cluster string) ([]Change, error) {
+	if c.history == nil {
+		return nil, ErrChangeHistoryDisabled
+	}
+	dynClient, err := c.dynamicClient(ctx, token, url, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, _, err := c.history.load(ctx, dynClient.Resource(configMapsGVR).Namespace(c.history.namespace), historyUser(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(changes)
+
+	return changes, nil
+}
+
+// UndoChange restores the state of a resource before a change recorded for the user of the token and removes the
+// change from the history: the created resource is deleted, the updated resource gets its previous spec back and the
+// deleted resource is created again. It returns the restored resource, or the deleted one when undoing a create.
+func (c *Client) UndoChange(ctx context.Context, params UndoParams) (*unstructured.Unstructured, error) {
+	if c.history == nil {
+		return nil, ErrChangeHistoryDisabled
+	}
+	dynClient, err := c.dynamicClient(ctx, params.Token, params.URL, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	store := dynClient.Resource(configMapsGVR).Namespace(c.history.namespace)
+	user := historyUser(ctx, params.Token)
+
+	changes, _, err := c.history.load(ctx, store, user)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(changes, func(change Change) bool { return change.ID == params.ID })
+	if i < 0 {
+		return nil, fmt.Errorf("change %s not found", params.ID)
+	}
+	change := changes[i]
+	if change.User != user {
+		return nil, fmt.Errorf("change %s was made by another user", params.ID)
+	}
+	if change.Redacted && change.Operation != OperationCreate {
+		return nil, fmt.Errorf("change %s can't be undone, the sensitive fields of %s %s weren't recorded", params.ID, change.Resource, change.Name)
+	}
+
+	// the undo itself isn't recorded, so undoing again goes further back in the history
+	var resourceInterface dynamic.ResourceInterface = dynClient.Resource(change.GVR())
+	if change.Namespace != "" {
+		resourceInterface = dynClient.Resource(change.GVR()).Namespace(change.Namespace)
+	}
+	if c.cache != nil {
+		resourceInterface = invalidatingResourceInterface{ResourceInterface: resourceInterface, cache: c.cache}
+	}
+
+	obj, err := undo(ctx, resourceInterface, change, params.Force)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.history.update(ctx, store, user, func(changes []Change) []Change {
+		return slices.DeleteFunc(changes, func(c Change) bool { return c.ID == change.ID })
+	}); err != nil {
+		zap.L().Warn("failed to remove undone change from the history", zap.String("id", change.ID), zap.Error(err))
+	}
+
+	return obj, nil
+}
+
+func undo(ctx context.Context, resourceInterface dynamic.ResourceInterface, change Change, force bool) (*unstructured.Unstructured, error) {
+	// the state is decoded again as an unstructured object, so its integers aren't float64
+	before := &unstructured.Unstructured{}
+	if change.Operation != OperationCreate {
+		data, err := json.Marshal(change.Before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the state of %s %s: %w", change.Resource, change.Name, err)
+		}
+		if err := before.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to read the state of %s %s: %w", change.Resource, change.Name, err)
+		}
+	}
+
+	if change.Operation == OperationDelete {
+		obj, err := resourceInterface.Create(ctx, before, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s %s again: %w", change.Resource, change.Name, err)
+		}
+		return obj, nil
+	}
+
+	current, err := resourceInterface.Get(ctx, change.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", change.Resource, change.Name, err)
+	}
+	if !force && specHash(current) != change.Hash {
+		return nil, fmt.Errorf("%s %s changed after change %s, undoing it would discard the later changes, force the undo to restore it anyway", change.Resource, change.Name, change.ID)
+	}
+
+	if change.Operation == OperationCreate {
+		if err := resourceInterface.Delete(ctx, change.Name, metav1.DeleteOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to delete %s %s: %w", change.Resource, change.Name, err)
+		}
+		return current, nil
+	}
+
+	before.SetResourceVersion(current.GetResourceVersion())
+	obj, err := resourceInterface.Update(ctx, before, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore %s %s: %w", change.Resource, change.Name, err)
+	}
+
+	return obj, nil
+}
+
+// load returns the changes of the ConfigMap of the user, oldest first, and whether the ConfigMap exists.
+func (h *changeHistory) load(ctx context.Context, store dynamic.ResourceInterface, user string) ([]Change, *unstructured.Unstructured, error) {
+	configMap, err := store.Get(ctx, historyConfigMap(user), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return []Change{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the change history: %w", err)
+	}
+
+	changes := []Change{}
+	data, _, _ := unstructured.NestedString(configMap.Object, "data", changesKey)
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &changes); err != nil {
+			return nil, nil, fmt.Errorf("failed to read the change history: %w", err)
+		}
+	}
+
+	return changes, configMap, nil
+}
+
+// update modifies the changes of the ConfigMap of the user, creating it if needed, and keeps the last size changes
+// that fit in it.
+func (h *changeHistory) update(ctx context.Context, store dynamic.ResourceInterface, user string, modify func([]Change) []Change) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		changes, configMap, err := h.load(ctx, store, user)
+		if err != nil {
+			return err
+		}
+		changes = modify(changes)
+		if len(changes) > h.size {
+			changes = changes[len(changes)-h.size:]
+		}
+		var data []byte
+		for {
+			data, err = json.Marshal(changes)
+			if err != nil {
+				return fmt.Errorf("failed to marshal the change history: %w", err)
+			}
+			if len(data) <= maxChangeHistoryBytes || len(changes) == 0 {
+				break
+			}
+			changes = changes[1:]
+		}
+
+		if configMap == nil {
+			configMap = &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name":      historyConfigMap(user),
+					"namespace": h.namespace,
+				},
+			}}
+			if err := unstructured.SetNestedField(configMap.Object, string(data), "data", changesKey); err != nil {
+				return err
+			}
+			_, err = store.Create(ctx, configMap, metav1.CreateOptions{})
+			return err
+		}
+		if err := unstructured.SetNestedField(configMap.Object, string(data), "data", changesKey); err != nil {
+			return err
+		}
+		_, err = store.Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// record adds a change to the history. Failures are only logged, so the history never blocks a change.
+func (h *changeHistory) record(ctx context.Context, store dynamic.ResourceInterface, change Change) {
+	change.ID = string(uuid.NewUUID())
+	change.Time = time.Now().UTC().Format(time.RFC3339)
+	err := h.update(ctx, store, change.User, func(changes []Change) []Change {
+		return append(changes, change)
+	})
+	if err != nil {
+		zap.L().Warn("failed to record change", zap.String("resource", change.Resource), zap.String("name", change.Name), zap.Error(err))
+	}
+}
+
+// recordingResourceInterface records the state of the resources before they're changed in the change history.
+// Dry-run requests and writes to subresources other than scale aren't recorded.
+type recordingResourceInterface struct {
+	dynamic.ResourceInterface
+	history   *changeHistory
+	store     dynamic.ResourceInterface
+	user      string
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (r recordingResourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	created, err := r.ResourceInterface.Create(ctx, obj, options, subresources...)
+	if err == nil && recorded(options.DryRun, subresources) {
+		r.history.record(ctx, r.store, r.change(OperationCreate, created.GetName(), specHash(created), nil))
+	}
+	return created, err
+}
+
+func (r recordingResourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.recordUpdate(ctx, obj.GetName(), recorded(options.DryRun, subresources), func() (*unstructured.Unstructured, error) {
+		return r.ResourceInterface.Update(ctx, obj, options, subresources...)
+	})
+}
+
+func (r recordingResourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.recordUpdate(ctx, name, recorded(options.DryRun, subresources), func() (*unstructured.Unstructured, error) {
+		return r.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+	})
+}
+
+func (r recordingResourceInterface) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return r.recordUpdate(ctx, name, recorded(options.DryRun, subresources), func() (*unstructured.Unstructured, error) {
+		return r.ResourceInterface.Apply(ctx, name, obj, options, subresources...)
+	})
+}
+
+func (r recordingResourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	if !recorded(options.DryRun, subresources) {
+		return r.ResourceInterface.Delete(ctx, name, options, subresources...)
+	}
+
+	before, getErr := r.ResourceInterface.Get(ctx, name, metav1.GetOptions{})
+	if err := r.ResourceInterface.Delete(ctx, name, options, subresources...); err != nil {
+		return err
+	}
+	if getErr != nil {
+		zap.L().Warn("failed to get resource before deleting it, the change isn't recorded", zap.String("resource", r.gvr.Resource), zap.String("name", name), zap.Error(getErr))
+		return nil
+	}
+	r.history.record(ctx, r.store, r.change(OperationDelete, name, "", before))
+
+	return nil
+}
+
+// recordUpdate gets the resource before the write and records the change if it succeeds. A write creating the
+// resource, such as an apply, is recorded as a create.
+func (r recordingResourceInterface) recordUpdate(ctx context.Context, name string, record bool, write func() (*unstructured.Unstructured, error)) (*unstructured.Unstructured, error) {
+	if !record {
+		return write()
+	}
+
+	before, getErr := r.ResourceInterface.Get(ctx, name, metav1.GetOptions{})
+	obj, err := write()
+	if err != nil {
+		return obj, err
+	}
+	switch {
+	case apierrors.IsNotFound(getErr):
+		r.history.record(ctx, r.store, r.change(OperationCreate, name, specHash(obj), nil))
+	case getErr != nil:
+		zap.L().Warn("failed to get resource before changing it, the change isn't recorded", zap.String("resource", r.gvr.Resource), zap.String("name", name), zap.Error(getErr))
+	default:
+		r.history.record(ctx, r.store, r.change(OperationUpdate, name, specHash(obj), before))
+	}
+
+	return obj, nil
+}
+
+// change returns the change of a resource, with its state before the change stripped of the fields set by the server.
+func (r recordingResourceInterface) change(operation string, name string, hash string, before *unstructured.Unstructured) Change {
+	change := Change{
+		Operation: operation,
+		Group:     r.gvr.Group,
+		Version:   r.gvr.Version,
+		Resource:  r.gvr.Resource,
+		Namespace: r.namespace,
+		Name:      name,
+		User:      r.user,
+		Hash:      hash,
+	}
+	if before != nil {
+		state := before.DeepCopy()
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink", "deletionTimestamp", "deletionGracePeriodSeconds"} {
+			unstructured.RemoveNestedField(state.Object, "metadata", field)
+		}
+		unstructured.RemoveNestedField(state.Object, "status")
+		redacted := state.DeepCopy()
+		response.Redact(redacted)
+		change.Before = redacted.Object
+		change.Redacted = !reflect.DeepEqual(redacted.Object, state.Object)
+	}
+
+	return change
+}
+
+// historyUser identifies the user of the token in the change history: by the name of the user set in the context
+// with WithUser, whose claims were validated, or by the hash of the token.
+func historyUser(ctx context.Context, token string) string {
+	if user, ok := UserFrom(ctx); ok {
+		return "user:" + user.Name
+	}
+	hash := sha256.Sum256([]byte(token))
+
+	return "token:" + hex.EncodeToString(hash[:8])
+}
+
+// historyConfigMap returns the name of the ConfigMap storing the changes of the user.
+func historyConfigMap(user string) string {
+	hash := sha256.Sum256([]byte(user))
+
+	return ChangeHistoryConfigMap + "-" + hex.EncodeToString(hash[:8])
+}
+
+// specHash returns the hash of the fields of a resource other than its metadata and status, e.g. its spec or data,
+// and of its labels. The status and the annotations are left out, since the controllers update them after the
+// changes, like the revision annotation of the Deployments, and so is the resourceVersion changed by their writes.
+func specHash(obj *unstructured.Unstructured) string {
+	spec := map[string]any{}
+	for field, value := range obj.Object {
+		if field != "metadata" && field != "status" {
+			spec[field] = value
+		}
+	}
+	spec["metadata"] = map[string]any{"labels": obj.GetLabels()}
+	// maps are marshaled with sorted keys, so the hash doesn't depend on the order of the fields
+	data, _ := json.Marshal(spec)
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// recorded returns whether a write with the given dry-run option and subresources is recorded.
+func recorded(dryRun []string, subresources []string) bool {
+	return len(dryRun) == 0 && (len(subresources) == 0 || slices.Equal(subresources, []string{scaleSubresource}))
+}
@@ -0,0 +1,407 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+// fakeTokenUser identifies the user of fakeToken in the change history.
+var fakeTokenUser = historyUser(context.Background(), fakeToken)
+
+// newHistoryClient returns a Client recording the last size changes, with a fake dynamic client containing a Pod.
+func newHistoryClient(size int) (*Client, *dynamicfake.FakeDynamicClient) {
+	fakePod := &v1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default", ResourceVersion: "1", Labels: map[string]string{"app": "nginx"}},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "nginx", Image: "nginx:1.27"}}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), fakePod)
+	c := &Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+	c.EnableChangeHistory(DefaultChangeHistoryNamespace, size)
+
+	return c, fakeDynClient
+}
+
+func newConfigMap(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": name, "namespace": "default"},
+		"data":       map[string]any{"key": "value"},
+	}}
+}
+
+func TestChangeHistory(t *testing.T) {
+	tests := map[string]struct {
+		size            int
+		writes          func(t *testing.T, c *Client)
+		expectedChanges []Change
+	}{
+		"patch": {
+			size: 10,
+			writes: func(t *testing.T, c *Client) {
+				pods, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["pod"])
+				require.NoError(t, err)
+				_, err = pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+			},
+			expectedChanges: []Change{{
+				Operation: OperationUpdate,
+				Version:   "v1",
+				Resource:  "pods",
+				Namespace: "default",
+				Name:      "test-pod",
+				User:      fakeTokenUser,
+				Before: map[string]any{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"metadata":   map[string]any{"name": "test-pod", "namespace": "default", "labels": map[string]any{"app": "nginx"}},
+					"spec":       map[string]any{"containers": []any{map[string]any{"name": "nginx", "image": "nginx:1.27", "resources": map[string]any{}}}},
+				},
+			}},
+		},
+		"create and delete": {
+			size: 10,
+			writes: func(t *testing.T, c *Client) {
+				configMaps, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["configmap"])
+				require.NoError(t, err)
+				_, err = configMaps.Create(t.Context(), newConfigMap("test-config"), metav1.CreateOptions{})
+				require.NoError(t, err)
+				require.NoError(t, configMaps.Delete(t.Context(), "test-config", metav1.DeleteOptions{}))
+			},
+			expectedChanges: []Change{
+				{
+					Operation: OperationDelete,
+					Version:   "v1",
+					Resource:  "configmaps",
+					Namespace: "default",
+					Name:      "test-config",
+					User:      fakeTokenUser,
+					Before: map[string]any{
+						"apiVersion": "v1",
+						"kind":       "ConfigMap",
+						"metadata":   map[string]any{"name": "test-config", "namespace": "default"},
+						"data":       map[string]any{"key": "value"},
+					},
+				},
+				{Operation: OperationCreate, Version: "v1", Resource: "configmaps", Namespace: "default", Name: "test-config", User: fakeTokenUser},
+			},
+		},
+		"dry runs, secrets and status updates aren't recorded": {
+			size: 10,
+			writes: func(t *testing.T, c *Client) {
+				pods, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["pod"])
+				require.NoError(t, err)
+				_, err = pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}})
+				require.NoError(t, err)
+				_, err = pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"status":{"phase":"Failed"}}`), metav1.PatchOptions{}, "status")
+				require.NoError(t, err)
+				secrets, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["secret"])
+				require.NoError(t, err)
+				secret := newConfigMap("test-secret")
+				secret.SetKind("Secret")
+				_, err = secrets.Create(t.Context(), secret, metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			expectedChanges: []Change{},
+		},
+		"sensitive fields are redacted": {
+			size: 10,
+			writes: func(t *testing.T, c *Client) {
+				require.NoError(t, response.SetSensitiveFields([]string{"configmap:data.key"}))
+				t.Cleanup(func() { _ = response.SetSensitiveFields(nil) })
+				configMaps, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["configmap"])
+				require.NoError(t, err)
+				_, err = configMaps.Create(t.Context(), newConfigMap("test-config"), metav1.CreateOptions{})
+				require.NoError(t, err)
+				require.NoError(t, configMaps.Delete(t.Context(), "test-config", metav1.DeleteOptions{}))
+			},
+			expectedChanges: []Change{
+				{
+					Operation: OperationDelete,
+					Version:   "v1",
+					Resource:  "configmaps",
+					Namespace: "default",
+					Name:      "test-config",
+					User:      fakeTokenUser,
+					Before: map[string]any{
+						"apiVersion": "v1",
+						"kind":       "ConfigMap",
+						"metadata":   map[string]any{"name": "test-config", "namespace": "default"},
+						"data":       map[string]any{"key": "<redacted, 5 bytes>"},
+					},
+					Redacted: true,
+				},
+				{Operation: OperationCreate, Version: "v1", Resource: "configmaps", Namespace: "default", Name: "test-config", User: fakeTokenUser},
+			},
+		},
+		"only the last changes are kept": {
+			size: 2,
+			writes: func(t *testing.T, c *Client) {
+				configMaps, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["configmap"])
+				require.NoError(t, err)
+				for _, name := range []string{"config-1", "config-2", "config-3"} {
+					_, err = configMaps.Create(t.Context(), newConfigMap(name), metav1.CreateOptions{})
+					require.NoError(t, err)
+				}
+			},
+			expectedChanges: []Change{
+				{Operation: OperationCreate, Version: "v1", Resource: "configmaps", Namespace: "default", Name: "config-3", User: fakeTokenUser},
+				{Operation: OperationCreate, Version: "v1", Resource: "configmaps", Namespace: "default", Name: "config-2", User: fakeTokenUser},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newHistoryClient(test.size)
+
+			test.writes(t, c)
+
+			changes, err := c.Changes(t.Context(), fakeToken, fakeUrl, "local")
+			require.NoError(t, err)
+			for i := range changes {
+				assert.NotEmpty(t, changes[i].ID)
+				assert.NotEmpty(t, changes[i].Time)
+				assert.Equal(t, changes[i].Operation != OperationDelete, changes[i].Hash != "")
+				changes[i].ID, changes[i].Time, changes[i].Hash = "", "", ""
+			}
+			assert.Equal(t, test.expectedChanges, changes)
+		})
+	}
+}
+
+func TestUndoChange(t *testing.T) {
+	tests := map[string]struct {
+		write         func(t *testing.T, pods dynamic.ResourceInterface, configMaps dynamic.ResourceInterface)
+		force         bool
+		check         func(t *testing.T, pods dynamic.ResourceInterface, configMaps dynamic.ResourceInterface)
+		expectedError string
+	}{
+		"undo patch": {
+			write: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				_, err := pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+			},
+			check: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				pod, err := pods.Get(t.Context(), "test-pod", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "nginx", pod.GetLabels()["app"])
+			},
+		},
+		"undo create": {
+			write: func(t *testing.T, _ dynamic.ResourceInterface, configMaps dynamic.ResourceInterface) {
+				_, err := configMaps.Create(t.Context(), newConfigMap("test-config"), metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			check: func(t *testing.T, _ dynamic.ResourceInterface, configMaps dynamic.ResourceInterface) {
+				_, err := configMaps.Get(t.Context(), "test-config", metav1.GetOptions{})
+				assert.True(t, apierrors.IsNotFound(err))
+			},
+		},
+		"undo delete": {
+			write: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				require.NoError(t, pods.Delete(t.Context(), "test-pod", metav1.DeleteOptions{}))
+			},
+			check: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				pod, err := pods.Get(t.Context(), "test-pod", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "nginx", pod.GetLabels()["app"])
+			},
+		},
+		"resource changed since": {
+			write: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				_, err := pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+				changed := pods.(recordingResourceInterface).ResourceInterface
+				_, err = changed.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"resourceVersion":"2","labels":{"app":"apache"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+			},
+			expectedError: "pods test-pod changed after change",
+		},
+		"forced undo of a resource changed since": {
+			write: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				_, err := pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+				changed := pods.(recordingResourceInterface).ResourceInterface
+				_, err = changed.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"resourceVersion":"2","labels":{"app":"apache"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+			},
+			force: true,
+			check: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				pod, err := pods.Get(t.Context(), "test-pod", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "nginx", pod.GetLabels()["app"])
+			},
+		},
+		"status and annotations changed since": {
+			write: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				_, err := pods.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+				changed := pods.(recordingResourceInterface).ResourceInterface
+				_, err = changed.Patch(t.Context(), "test-pod", types.MergePatchType, []byte(`{"metadata":{"resourceVersion":"2","annotations":{"example.io/reconciled":"true"}},"status":{"phase":"Succeeded"}}`), metav1.PatchOptions{}, "status")
+				require.NoError(t, err)
+			},
+			check: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				pod, err := pods.Get(t.Context(), "test-pod", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "nginx", pod.GetLabels()["app"])
+			},
+		},
+		"redacted change": {
+			write: func(t *testing.T, pods dynamic.ResourceInterface, _ dynamic.ResourceInterface) {
+				require.NoError(t, response.SetSensitiveFields([]string{"pod:metadata.labels"}))
+				t.Cleanup(func() { _ = response.SetSensitiveFields(nil) })
+				require.NoError(t, pods.Delete(t.Context(), "test-pod", metav1.DeleteOptions{}))
+			},
+			expectedError: "the sensitive fields of pods test-pod weren't recorded",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newHistoryClient(10)
+			pods, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["pod"])
+			require.NoError(t, err)
+			configMaps, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["configmap"])
+			require.NoError(t, err)
+			test.write(t, pods, configMaps)
+			changes, err := c.Changes(t.Context(), fakeToken, fakeUrl, "local")
+			require.NoError(t, err)
+			require.Len(t, changes, 1)
+
+			_, err = c.UndoChange(t.Context(), UndoParams{Cluster: "local", URL: fakeUrl, Token: fakeToken, ID: changes[0].ID, Force: test.force})
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			test.check(t, pods, configMaps)
+			changes, err = c.Changes(t.Context(), fakeToken, fakeUrl, "local")
+			require.NoError(t, err)
+			assert.Empty(t, changes, "the undone change must be removed from the history")
+		})
+	}
+}
+
+func TestChangeHistoryUsers(t *testing.T) {
+	c, fakeDynClient := newHistoryClient(10)
+	userCtx := WithUser(t.Context(), User{Name: "u-abc"})
+	pods, err := c.GetResourceInterface(userCtx, fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["pod"])
+	require.NoError(t, err)
+	_, err = pods.Patch(userCtx, "test-pod", types.MergePatchType, []byte(`{"metadata":{"labels":{"app":"httpd"}}}`), metav1.PatchOptions{})
+	require.NoError(t, err)
+
+	changes, err := c.Changes(userCtx, fakeToken, fakeUrl, "local")
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "user:u-abc", changes[0].User)
+
+	for name, ctx := range map[string]context.Context{
+		"token of the user":  t.Context(),
+		"another user":       WithUser(t.Context(), User{Name: "u-def"}),
+		"another user token": context.Background(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			token := fakeToken
+			if name == "another user token" {
+				token = "otherToken"
+			}
+			others, err := c.Changes(ctx, token, fakeUrl, "local")
+			require.NoError(t, err)
+			assert.Empty(t, others)
+			_, err = c.UndoChange(ctx, UndoParams{Cluster: "local", URL: fakeUrl, Token: token, ID: changes[0].ID})
+			assert.EqualError(t, err, "change "+changes[0].ID+" not found")
+		})
+	}
+
+	// a change copied to the history of another user can't be undone by them
+	otherUser := historyUser(context.Background(), "otherToken")
+	history, err := fakeDynClient.Resource(configMapsGVR).Namespace(DefaultChangeHistoryNamespace).Get(t.Context(), historyConfigMap("user:u-abc"), metav1.GetOptions{})
+	require.NoError(t, err)
+	history.SetName(historyConfigMap(otherUser))
+	history.SetResourceVersion("")
+	_, err = fakeDynClient.Resource(configMapsGVR).Namespace(DefaultChangeHistoryNamespace).Create(t.Context(), history, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = c.UndoChange(t.Context(), UndoParams{Cluster: "local", URL: fakeUrl, Token: "otherToken", ID: changes[0].ID})
+	assert.EqualError(t, err, "change "+changes[0].ID+" was made by another user")
+
+	_, err = c.UndoChange(userCtx, UndoParams{Cluster: "local", URL: fakeUrl, Token: fakeToken, ID: changes[0].ID})
+	require.NoError(t, err)
+	pod, err := pods.Get(userCtx, "test-pod", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", pod.GetLabels()["app"])
+}
+
+func TestSpecHash(t *testing.T) {
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]any{"name": "test-pod", "resourceVersion": "1", "labels": map[string]any{"app": "nginx"}},
+		"spec":       map[string]any{"containers": []any{map[string]any{"name": "nginx", "image": "nginx:1.27"}}},
+		"status":     map[string]any{"phase": "Pending"},
+	}}
+	hash := specHash(pod)
+
+	tests := map[string]struct {
+		change       func(obj *unstructured.Unstructured)
+		expectedSame bool
+	}{
+		"status": {
+			change:       func(obj *unstructured.Unstructured) { obj.Object["status"] = map[string]any{"phase": "Running"} },
+			expectedSame: true,
+		},
+		"resource version and annotations": {
+			change: func(obj *unstructured.Unstructured) {
+				obj.SetResourceVersion("2")
+				obj.SetAnnotations(map[string]string{"example.io/reconciled": "true"})
+			},
+			expectedSame: true,
+		},
+		"labels": {
+			change: func(obj *unstructured.Unstructured) { obj.SetLabels(map[string]string{"app": "httpd"}) },
+		},
+		"spec": {
+			change: func(obj *unstructured.Unstructured) {
+				obj.Object["spec"] = map[string]any{"containers": []any{map[string]any{"name": "nginx", "image": "nginx:1.28"}}}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			changed := pod.DeepCopy()
+			test.change(changed)
+
+			assert.Equal(t, test.expectedSame, specHash(changed) == hash)
+		})
+	}
+}
+
+func TestChangeHistoryDisabled(t *testing.T) {
+	c := &Client{}
+
+	_, err := c.Changes(context.Background(), fakeToken, fakeUrl, "local")
+	assert.ErrorIs(t, err, ErrChangeHistoryDisabled)
+	_, err = c.UndoChange(context.Background(), UndoParams{Cluster: "local", URL: fakeUrl, Token: fakeToken, ID: "id"})
+	assert.ErrorIs(t, err, ErrChangeHistoryDisabled)
+}
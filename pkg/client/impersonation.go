@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// contextKey is the type of the context keys of the package, a pointer to it is unique.
+type contextKey struct{ name string }
+
+// userCtxKey is the context key of the user the requests are made for.
+var userCtxKey = &contextKey{"user"}
+
+// User is the Rancher user, and its groups, derived from a validated token.
+type User struct {
+	Name   string
+	Groups []string
+}
+
+// WithUser sets the user the requests made with the context are made for. It must only be set for tokens whose
+// claims were validated, since the service account credential enabled with EnableImpersonation acts as this user.
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userCtxKey, user)
+}
+
+// UserFrom returns the user set in the context with WithUser, if any.
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userCtxKey).(User)
+
+	return user, ok && user.Name != ""
+}
+
+// EnableImpersonation makes the requests with the service account token instead of the token of the user, with
+// the Impersonate-User and Impersonate-Group headers of the user set in the context with WithUser. The RBAC of the
+// user is enforced by the downstream clusters, and the service account must be allowed to impersonate users and
+// groups. Requests without a user in the context are still made with the token of the request.
+func (c *Client) EnableImpersonation(serviceAccountToken string) {
+	c.impersonation = &clientcmdapi.AuthInfo{Token: serviceAccountToken}
+}
+
+// EnableImpersonationTokenFile is like EnableImpersonation with the service account token read from a file, like
+// the token of a mounted Secret. The file is read again periodically, so the token can be rotated.
+func (c *Client) EnableImpersonationTokenFile(path string) {
+	c.impersonation = &clientcmdapi.AuthInfo{TokenFile: path}
+}
+
+// CheckImpersonation returns an error if the service account credential enabled with EnableImpersonation or
+// EnableImpersonationTokenFile isn't allowed to impersonate users and groups in the local cluster, so a
+// misconfigured service account is reported at startup instead of failing every request.
+func (c *Client) CheckImpersonation(ctx context.Context, url string) error {
+	if c.impersonation == nil {
+		return errors.New("impersonation is disabled")
+	}
+	restConfig, err := c.newRestConfig(url, "local", c.impersonation.DeepCopy())
+	if err != nil {
+		return err
+	}
+	clientSet, err := c.ClientSetCreator(restConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range []string{"users", "groups"} {
+		review, err := clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "impersonate", Resource: resource},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to check the impersonation of %s: %w", resource, err)
+		}
+		if !review.Status.Allowed {
+			return fmt.Errorf("the service account is not allowed to impersonate %s: %s", resource, review.Status.Reason)
+		}
+	}
+
+	return nil
+}
+
+// authInfo returns the credentials of the requests made with the context and the impersonation config of its user.
+func (c *Client) authInfo(ctx context.Context, token string) (*clientcmdapi.AuthInfo, rest.ImpersonationConfig) {
+	if c.impersonation == nil {
+		return &clientcmdapi.AuthInfo{Token: token}, rest.ImpersonationConfig{}
+	}
+	user, ok := UserFrom(ctx)
+	if !ok {
+		return &clientcmdapi.AuthInfo{Token: token}, rest.ImpersonationConfig{}
+	}
+
+	return c.impersonation.DeepCopy(), rest.ImpersonationConfig{UserName: user.Name, Groups: user.Groups}
+}
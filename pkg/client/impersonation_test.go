@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCreateRestConfigImpersonation(t *testing.T) {
+	user := User{Name: "u-abc", Groups: []string{"local://g-1"}}
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-file-token"), 0o600))
+
+	tests := map[string]struct {
+		serviceAccountToken string
+		tokenFile           string
+		ctx                 context.Context
+		expectedToken       string
+		expectedImpersonate rest.ImpersonationConfig
+	}{
+		"impersonation disabled": {
+			ctx:           WithUser(context.Background(), user),
+			expectedToken: fakeToken,
+		},
+		"impersonated user": {
+			serviceAccountToken: "sa-token",
+			ctx:                 WithUser(context.Background(), user),
+			expectedToken:       "sa-token",
+			expectedImpersonate: rest.ImpersonationConfig{UserName: "u-abc", Groups: []string{"local://g-1"}},
+		},
+		"impersonated user with a token file": {
+			tokenFile:           tokenFile,
+			ctx:                 WithUser(context.Background(), user),
+			expectedToken:       "sa-file-token",
+			expectedImpersonate: rest.ImpersonationConfig{UserName: "u-abc", Groups: []string{"local://g-1"}},
+		},
+		"no user in the context": {
+			serviceAccountToken: "sa-token",
+			ctx:                 context.Background(),
+			expectedToken:       fakeToken,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(true)
+			if test.serviceAccountToken != "" {
+				c.EnableImpersonation(test.serviceAccountToken)
+			}
+			if test.tokenFile != "" {
+				c.EnableImpersonationTokenFile(test.tokenFile)
+			}
+
+			restConfig, err := c.createRestConfig(test.ctx, fakeToken, fakeUrl, "local")
+
+			require.NoError(t, err)
+			assert.Equal(t, fakeUrl+"/k8s/clusters/local", restConfig.Host)
+			assert.Equal(t, test.expectedToken, restConfig.BearerToken)
+			assert.Equal(t, test.expectedImpersonate, restConfig.Impersonate)
+		})
+	}
+}
+
+func TestCheckImpersonation(t *testing.T) {
+	tests := map[string]struct {
+		allowed       map[string]bool
+		expectedError string
+	}{
+		"allowed": {
+			allowed: map[string]bool{"users": true, "groups": true},
+		},
+		"groups not allowed": {
+			allowed:       map[string]bool{"users": true},
+			expectedError: "the service account is not allowed to impersonate groups: no RBAC policy matched",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var restConfig *rest.Config
+			clientSet := fake.NewClientset()
+			clientSet.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				review.Status.Allowed = test.allowed[review.Spec.ResourceAttributes.Resource]
+				if !review.Status.Allowed {
+					review.Status.Reason = "no RBAC policy matched"
+				}
+				return true, review, nil
+			})
+			c := NewClient(true)
+			c.ClientSetCreator = func(cfg *rest.Config) (kubernetes.Interface, error) {
+				restConfig = cfg
+				return clientSet, nil
+			}
+			c.EnableImpersonation("sa-token")
+
+			err := c.CheckImpersonation(t.Context(), fakeUrl)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			require.NotNil(t, restConfig)
+			assert.Equal(t, "sa-token", restConfig.BearerToken)
+			assert.Empty(t, restConfig.Impersonate)
+		})
+	}
+}
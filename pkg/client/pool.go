@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+)
+
+// DefaultConnectionIdleTimeout is how long the connections to a cluster are kept by default after their last use.
+const DefaultConnectionIdleTimeout = 5 * time.Minute
+
+// connectionsKey identifies the connections to a cluster made with a token, and the user they impersonate if any.
+type connectionsKey struct {
+	url       string
+	clusterID string
+	token     string
+	user      string
+	groups    string
+}
+
+// connections are the rest config of a cluster and the clients created for it, which keep their connections open
+// between the requests of a tool call and of the following ones. The clients are created on their first use.
+type connections struct {
+	restConfig *rest.Config
+
+	mu         sync.Mutex
+	dynClient  dynamic.Interface
+	clientSet  kubernetes.Interface
+	metaClient metadata.Interface
+	httpClient *http.Client
+	lastUsed   time.Time
+}
+
+// dynamic returns the dynamic client of the connections, created with the creator on its first use.
+func (c *connections) dynamic(creator func(*rest.Config) (dynamic.Interface, error)) (dynamic.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dynClient == nil {
+		dynClient, err := creator(c.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		c.dynClient = dynClient
+	}
+
+	return c.dynClient, nil
+}
+
+// kubernetes returns the clientset of the connections, created with the creator on its first use.
+func (c *connections) kubernetes(creator func(*rest.Config) (kubernetes.Interface, error)) (kubernetes.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.clientSet == nil {
+		clientSet, err := creator(c.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		c.clientSet = clientSet
+	}
+
+	return c.clientSet, nil
+}
+
+// metadata returns the metadata client of the connections, created with the creator on its first use.
+func (c *connections) metadata(creator func(*rest.Config) (metadata.Interface, error)) (metadata.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.metaClient == nil {
+		metaClient, err := creator(c.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		c.metaClient = metaClient
+	}
+
+	return c.metaClient, nil
+}
+
+// http returns the HTTP client of the connections, used for the requests that are not made with a Kubernetes client.
+func (c *connections) http() (*http.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.httpClient == nil {
+		httpClient, err := rest.HTTPClientFor(c.restConfig)
+		if err != nil {
+			return nil, err
+		}
+		c.httpClient = httpClient
+	}
+
+	return c.httpClient, nil
+}
+
+// connectionPool keeps the connections to the clusters per token, so the requests of multi-step tools like
+// analyzeCluster don't set up a new rest config, client and TLS connection each time. The connections that
+// weren't used for the idle timeout are closed.
+type connectionPool struct {
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[connectionsKey]*connections
+}
+
+func newConnectionPool(idleTimeout time.Duration) *connectionPool {
+	return &connectionPool{
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		entries:     map[connectionsKey]*connections{},
+	}
+}
+
+// get returns the connections of the key, or creates them with create and adds them to the pool.
+func (p *connectionPool) get(key connectionsKey, create func() (*rest.Config, error)) (*connections, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.removeIdle(now)
+	conns, ok := p.entries[key]
+	if !ok {
+		restConfig, err := create()
+		if err != nil {
+			return nil, err
+		}
+		conns = &connections{restConfig: restConfig}
+		p.entries[key] = conns
+	}
+	conns.lastUsed = now
+
+	return conns, nil
+}
+
+// removeIdle removes the connections that weren't used for the idle timeout, and closes their idle HTTP
+// connections. p.mu must be held.
+func (p *connectionPool) removeIdle(now time.Time) {
+	for key, conns := range p.entries {
+		if now.Sub(conns.lastUsed) < p.idleTimeout {
+			continue
+		}
+		delete(p.entries, key)
+		if conns.httpClient != nil {
+			conns.httpClient.CloseIdleConnections()
+		}
+	}
+}
+
+// EnableConnectionPool reuses the rest configs, the clients and their connections to a cluster for the requests
+// made with the same token, until they aren't used for the idle timeout.
+func (c *Client) EnableConnectionPool(idleTimeout time.Duration) {
+	c.pool = newConnectionPool(idleTimeout)
+}
+
+// connections returns the connections to a cluster for the credentials of the request, from the pool if enabled.
+func (c *Client) connections(ctx context.Context, token string, url string, clusterID string) (*connections, error) {
+	authInfo, impersonate := c.authInfo(ctx, token)
+	create := func() (*rest.Config, error) {
+		restConfig, err := c.newRestConfig(url, clusterID, authInfo)
+		if err != nil {
+			return nil, err
+		}
+		restConfig.Impersonate = impersonate
+
+		return restConfig, nil
+	}
+	if c.pool == nil {
+		restConfig, err := create()
+		if err != nil {
+			return nil, err
+		}
+		return &connections{restConfig: restConfig}, nil
+	}
+
+	return c.pool.get(connectionsKey{
+		url:       url,
+		clusterID: clusterID,
+		token:     hashToken(token),
+		user:      impersonate.UserName,
+		groups:    strings.Join(impersonate.Groups, "\n"),
+	}, create)
+}
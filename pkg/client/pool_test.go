@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestConnectionPool(t *testing.T) {
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	user := User{Name: "u-abc", Groups: []string{"local://g-1"}}
+
+	tests := map[string]struct {
+		enabled         bool
+		requests        func(t *testing.T, c *Client, wait func(time.Duration))
+		expectedCreated int
+		expectedBearers []string
+	}{
+		"requests with the same token share a client": {
+			enabled: true,
+			requests: func(t *testing.T, c *Client, _ func(time.Duration)) {
+				for range 3 {
+					_, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", podsGVR)
+					require.NoError(t, err)
+				}
+			},
+			expectedCreated: 1,
+			expectedBearers: []string{fakeToken},
+		},
+		"disabled pool creates a client per request": {
+			requests: func(t *testing.T, c *Client, _ func(time.Duration)) {
+				for range 2 {
+					_, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", podsGVR)
+					require.NoError(t, err)
+				}
+			},
+			expectedCreated: 2,
+			expectedBearers: []string{fakeToken, fakeToken},
+		},
+		"other tokens don't share a client": {
+			enabled: true,
+			requests: func(t *testing.T, c *Client, _ func(time.Duration)) {
+				for _, token := range []string{fakeToken, "other-token", fakeToken} {
+					_, err := c.GetResourceInterface(t.Context(), token, fakeUrl, "default", "local", podsGVR)
+					require.NoError(t, err)
+				}
+			},
+			expectedCreated: 2,
+			expectedBearers: []string{fakeToken, "other-token"},
+		},
+		"impersonated users don't share a client": {
+			enabled: true,
+			requests: func(t *testing.T, c *Client, _ func(time.Duration)) {
+				c.EnableImpersonation("sa-token")
+				for _, ctx := range []context.Context{WithUser(t.Context(), user), WithUser(t.Context(), User{Name: "u-def"}), WithUser(t.Context(), user)} {
+					_, err := c.GetResourceInterface(ctx, fakeToken, fakeUrl, "default", "local", podsGVR)
+					require.NoError(t, err)
+				}
+			},
+			expectedCreated: 2,
+			expectedBearers: []string{"sa-token", "sa-token"},
+		},
+		"idle connections are removed": {
+			enabled: true,
+			requests: func(t *testing.T, c *Client, wait func(time.Duration)) {
+				_, err := c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", podsGVR)
+				require.NoError(t, err)
+				wait(4 * time.Minute)
+				_, err = c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", podsGVR)
+				require.NoError(t, err)
+				wait(DefaultConnectionIdleTimeout)
+				_, err = c.GetResourceInterface(t.Context(), fakeToken, fakeUrl, "default", "local", podsGVR)
+				require.NoError(t, err)
+			},
+			expectedCreated: 2,
+			expectedBearers: []string{fakeToken, fakeToken},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var bearers []string
+			c := NewClient(true)
+			c.DynClientCreator = func(cfg *rest.Config) (dynamic.Interface, error) {
+				bearers = append(bearers, cfg.BearerToken)
+				return dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), nil
+			}
+			now := time.Now()
+			if test.enabled {
+				c.EnableConnectionPool(DefaultConnectionIdleTimeout)
+				c.pool.now = func() time.Time { return now }
+			}
+
+			test.requests(t, c, func(d time.Duration) { now = now.Add(d) })
+
+			assert.Len(t, bearers, test.expectedCreated)
+			assert.Equal(t, test.expectedBearers, bearers)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ProxyGetParams holds the parameters required to send a request to a Service or Pod through the API server proxy.
+type ProxyGetParams struct {
+	Cluster   string // The Cluster ID.
+	Kind      string // The Kind of the target, "service" or "pod".
+	Namespace string // The Namespace of the target.
+	Name      string // The Name of the target.
+	Scheme    string // The Scheme used to connect to the target, "http" or "https" (optional).
+	Port      string // The Port name or number of the target (optional).
+	Path      string // The Path of the request, it may include a query string (optional).
+	URL       string // The base URL of the Rancher server.
+	Token     string // The authentication Token for Steve.
+	// Header contains the headers of the request sent to the target, e.g. its own authentication (optional).
+	Header http.Header
+}
+
+// ProxyGet sends a GET request to a Service or Pod using the proxy subresource of the API server.
+// The caller is responsible for closing the response body.
+func (c *Client) ProxyGet(ctx context.Context, params ProxyGetParams) (*http.Response, error) {
+	return c.proxyRequest(ctx, http.MethodGet, params, nil)
+}
+
+// ProxyPost sends a POST request with a JSON body to a Service or Pod using the proxy subresource of the API server.
+// The caller is responsible for closing the response body.
+func (c *Client) ProxyPost(ctx context.Context, params ProxyGetParams, body []byte) (*http.Response, error) {
+	return c.proxyRequest(ctx, http.MethodPost, params, bytes.NewReader(body))
+}
+
+func (c *Client) proxyRequest(ctx context.Context, method string, params ProxyGetParams, body io.Reader) (*http.Response, error) {
+	var resource string
+	switch params.Kind {
+	case "service":
+		resource = "services"
+	case "pod":
+		resource = "pods"
+	default:
+		return nil, fmt.Errorf("unsupported proxy kind: %s", params.Kind)
+	}
+
+	clusterID, err := c.getClusterId(ctx, params.Token, params.URL, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	conns, err := c.connections(ctx, params.Token, params.URL, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := params.Name
+	if params.Port != "" {
+		target += ":" + params.Port
+	}
+	if params.Scheme != "" {
+		target = params.Scheme + ":" + target
+	}
+	requestPath, err := url.Parse(params.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", params.Path, err)
+	}
+
+	proxyURL, err := url.Parse(conns.restConfig.Host)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL.Path = path.Join(proxyURL.Path, "/api/v1/namespaces", params.Namespace, resource, target, "proxy", requestPath.Path)
+	if requestPath.Path == "" || strings.HasSuffix(requestPath.Path, "/") {
+		proxyURL.Path += "/"
+	}
+	proxyURL.RawQuery = requestPath.RawQuery
+
+	httpClient, err := conns.http()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, proxyURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range params.Header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return httpClient.Do(req)
+}
+
+// RawGetParams holds the parameters required to send a GET request to a path of the API server of a cluster.
+type RawGetParams struct {
+	Cluster string // The Cluster ID.
+	Path    string // The Path of the request, it may include a query string.
+	URL     string // The base URL of the Rancher server.
+	Token   string // The authentication Token for Steve.
+}
+
+// RawGet sends a GET request to a path of the API server of a cluster, through the cluster proxy of Rancher.
+// The caller is responsible for closing the response body.
+func (c *Client) RawGet(ctx context.Context, params RawGetParams) (*http.Response, error) {
+	clusterID, err := c.getClusterId(ctx, params.Token, params.URL, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	conns, err := c.connections(ctx, params.Token, params.URL, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	requestPath, err := url.Parse(params.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", params.Path, err)
+	}
+	rawURL, err := url.Parse(conns.restConfig.Host)
+	if err != nil {
+		return nil, err
+	}
+	rawURL.Path = path.Join(rawURL.Path, requestPath.Path)
+	rawURL.RawQuery = requestPath.RawQuery
+
+	httpClient, err := conns.http()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return httpClient.Do(req)
+}
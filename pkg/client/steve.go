@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// steveMaxResponseBytes limits the size of the Steve responses read into memory.
+const steveMaxResponseBytes = 32 * 1024 * 1024
+
+// SteveParams holds the parameters required to send a request to the Steve API of a cluster.
+type SteveParams struct {
+	Cluster string     // The Cluster ID.
+	Method  string     // The HTTP Method of the request.
+	Path    string     // The Path of the request relative to /v1 (e.g. catalog.cattle.io.clusterrepos/rancher-charts).
+	Query   url.Values // The Query parameters of the request (optional).
+	Body    []byte     // The JSON Body of the request (optional).
+	URL     string     // The base URL of the Rancher server.
+	Token   string     // The authentication Token for Steve.
+}
+
+// SteveRequest sends a request to the Steve API of a cluster, used for the links and actions that are not part of
+// the Kubernetes API (e.g. the chart index of a ClusterRepo). It returns the body of the response, or an error
+// including the body if the status code is not 2xx.
+func (c *Client) SteveRequest(ctx context.Context, params SteveParams) ([]byte, error) {
+	clusterID, err := c.getClusterId(ctx, params.Token, params.URL, params.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	conns, err := c.connections(ctx, params.Token, params.URL, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	steveURL, err := url.Parse(conns.restConfig.Host)
+	if err != nil {
+		return nil, err
+	}
+	steveURL.Path = path.Join(steveURL.Path, "/v1", params.Path)
+	steveURL.RawQuery = params.Query.Encode()
+
+	return sendRequest(ctx, conns, params.Method, steveURL.String(), params.Path, params.Body)
+}
+
+// RancherParams holds the parameters required to send a request to an API of the Rancher server that is not served
+// for a cluster.
+type RancherParams struct {
+	Path  string     // The absolute Path of the request (e.g. /v1-rke2-release/releases).
+	Query url.Values // The Query parameters of the request (optional).
+	URL   string     // The base URL of the Rancher server.
+	Token string     // The authentication Token for Rancher.
+}
+
+// RancherGet sends a GET request to an API of the Rancher server itself, like the RKE2 and K3s releases of the
+// Kontainer Driver Metadata (KDM). It returns the body of the response, or an error including the body if the status
+// code is not 2xx.
+func (c *Client) RancherGet(ctx context.Context, params RancherParams) ([]byte, error) {
+	conns, err := c.connections(ctx, params.Token, params.URL, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	rancherURL, err := url.Parse(params.URL)
+	if err != nil {
+		return nil, err
+	}
+	rancherURL.Path = path.Join(rancherURL.Path, params.Path)
+	rancherURL.RawQuery = params.Query.Encode()
+
+	return sendRequest(ctx, conns, http.MethodGet, rancherURL.String(), params.Path, nil)
+}
+
+// sendRequest sends a JSON request with the HTTP client of the connections, and returns the body of the response or
+// an error including the body if the status code is not 2xx. The path is only used in the error message.
+func sendRequest(ctx context.Context, conns *connections, method string, reqURL string, reqPath string, reqBody []byte) ([]byte, error) {
+	httpClient, err := conns.http()
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, steveMaxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s failed with status %s: %s", method, reqPath, res.Status, resBody)
+	}
+
+	return resBody, nil
+}
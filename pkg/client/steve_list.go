@@ -0,0 +1,256 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// kindPrefixes contains the prefixes of the kinds of the converter package differentiating the kinds of several
+// groups, by group.
+var kindPrefixes = map[string]string{
+	converter.CAPIGroup:         converter.CAPIKindPrefix,
+	converter.ProvisioningGroup: converter.ProvisioningKindPrefix,
+	converter.ManagementGroup:   converter.ManagementKindPrefix,
+}
+
+// steveKinds contains the lowercase kinds of the kinds of the converter package which are aliases.
+var steveKinds = map[string]string{
+	"vpa":            "verticalpodautoscaler",
+	"crd":            "customresourcedefinition",
+	"endpointslices": "endpointslice",
+	"k3kcluster":     "cluster",
+	"fleetcluster":   "cluster",
+}
+
+// SteveListParams holds the parameters required to list resources with the Steve API of a cluster.
+type SteveListParams struct {
+	Cluster       string // The Cluster ID.
+	Kind          string // The Kind of the Kubernetes resource (e.g., "pod", "deployment").
+	Namespace     string // The Namespace of the resources (optional).
+	URL           string // The base URL of the Rancher server.
+	Token         string // The authentication Token for Steve.
+	LabelSelector string // Optional LabelSelector string for the request.
+	// Filter only returns the resources whose fields contain the values, as comma separated <field path>=<value>
+	// (e.g. metadata.name=nginx,spec.nodeName=node-1), any of them must match.
+	Filter string
+	// Sort orders the resources by the comma separated field paths, descending if prefixed with - (e.g.
+	// -metadata.creationTimestamp).
+	Sort     string
+	Limit    int64  // Optional maximum number of resources returned, a continue token is returned if there are more.
+	Continue string // Optional continue token returned by a previous request to get the next page.
+}
+
+// steveCollection is a list of resources returned by the Steve API.
+type steveCollection struct {
+	Data     []map[string]any `json:"data"`
+	Continue string           `json:"continue"`
+}
+
+// ListWithSteve lists the resources of a kind with the Steve API of the cluster, which filters, sorts and paginates
+// them in Rancher, so the response of a cluster with tens of thousands of objects only holds the ones needed. If
+// Steve can't be used, e.g. the cluster isn't reached through Rancher, the resources are listed with the dynamic
+// client instead, and the page returned is filtered and sorted the same way.
+func (c *Client) ListWithSteve(ctx context.Context, params SteveListParams) ([]*unstructured.Unstructured, string, error) {
+	objs, continueToken, err := c.steveList(ctx, params)
+	if err == nil || ctx.Err() != nil {
+		return objs, continueToken, err
+	}
+	zap.L().Warn("failed to list with Steve, falling back to the Kubernetes API", zap.String("kind", params.Kind), zap.String("cluster", params.Cluster), zap.Error(err))
+
+	objs, continueToken, err = c.GetResourcesPage(ctx, ListParams{
+		Cluster:       params.Cluster,
+		Kind:          params.Kind,
+		Namespace:     params.Namespace,
+		URL:           params.URL,
+		Token:         params.Token,
+		LabelSelector: params.LabelSelector,
+		Limit:         params.Limit,
+		Continue:      params.Continue,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	// the lists may be cached, they must not be modified
+	objs = FilterResources(slices.Clone(objs), params.Filter)
+	SortResources(objs, params.Sort)
+
+	return objs, continueToken, nil
+}
+
+func (c *Client) steveList(ctx context.Context, params SteveListParams) ([]*unstructured.Unstructured, string, error) {
+	steveType, err := c.steveType(ctx, params.Token, params.URL, params.Cluster, params.Kind)
+	if err != nil {
+		return nil, "", err
+	}
+	steveType = url.PathEscape(steveType)
+	if params.Namespace != "" {
+		steveType += "/" + url.PathEscape(params.Namespace)
+	}
+	query := url.Values{}
+	if params.LabelSelector != "" {
+		query.Set("labelSelector", params.LabelSelector)
+	}
+	if params.Filter != "" {
+		query.Set("filter", params.Filter)
+	}
+	if params.Sort != "" {
+		query.Set("sort", params.Sort)
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(params.Limit, 10))
+	}
+	if params.Continue != "" {
+		query.Set("continue", params.Continue)
+	}
+
+	body, err := c.SteveRequest(ctx, SteveParams{
+		Cluster: params.Cluster,
+		Method:  http.MethodGet,
+		Path:    steveType,
+		Query:   query,
+		URL:     params.URL,
+		Token:   params.Token,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	var collection steveCollection
+	if err := json.Unmarshal(body, &collection); err != nil {
+		return nil, "", fmt.Errorf("failed to decode the Steve collection: %w", err)
+	}
+
+	objs := make([]*unstructured.Unstructured, 0, len(collection.Data))
+	for _, item := range collection.Data {
+		obj := &unstructured.Unstructured{Object: item}
+		removeSteveFields(obj)
+		objs = append(objs, obj)
+	}
+
+	return objs, collection.Continue, nil
+}
+
+// steveType returns the type of the resources of the kind in the Steve API, their group and lowercase kind (e.g.
+// apps.deployment), or only the lowercase kind in the core group (e.g. pod).
+func (c *Client) steveType(ctx context.Context, token string, url string, cluster string, kind string) (string, error) {
+	gvr, err := c.ResolveGVR(ctx, token, url, cluster, kind)
+	if err != nil {
+		return "", err
+	}
+
+	lowerKind, err := c.lowerKind(ctx, token, url, cluster, kind, gvr)
+	if err != nil {
+		return "", err
+	}
+	if gvr.Group == "" {
+		return lowerKind, nil
+	}
+
+	return gvr.Group + "." + lowerKind, nil
+}
+
+// lowerKind returns the lowercase kind of the resource resolved for the kind. The kinds of the converter package
+// are lowercase kinds, some of them prefixed to differentiate the kinds of several groups or aliases, the other kinds
+// are found in the resources discovered in the cluster.
+func (c *Client) lowerKind(ctx context.Context, token string, url string, cluster string, kind string, gvr schema.GroupVersionResource) (string, error) {
+	kind = strings.ToLower(kind)
+	if steveKind, ok := steveKinds[kind]; ok {
+		return steveKind, nil
+	}
+	if _, ok := converter.K8sKindsToGVRs[kind]; ok {
+		return strings.TrimPrefix(kind, kindPrefixes[gvr.Group]), nil
+	}
+
+	clusterID, err := c.getClusterId(ctx, token, url, cluster)
+	if err != nil {
+		return "", err
+	}
+	value, ok := discoveryCache.Load(url + "/" + clusterID)
+	if !ok {
+		return "", errors.New("the resources of the cluster weren't discovered")
+	}
+	for _, resource := range value.(*discoveredResources).resources {
+		if resource.gvr == gvr {
+			return strings.ToLower(resource.kind), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown kind: %s in cluster %s", kind, cluster)
+}
+
+// removeSteveFields removes the fields added by Steve to the resources, only used by the Rancher UI.
+func removeSteveFields(obj *unstructured.Unstructured) {
+	for _, field := range []string{"id", "type", "links", "actions"} {
+		delete(obj.Object, field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "fields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "relationships")
+}
+
+// FilterResources returns the resources matching the Steve filter, comma separated <field path>=<value>: a resource
+// matches if the value of any of the fields contains the value.
+func FilterResources(objs []*unstructured.Unstructured, filter string) []*unstructured.Unstructured {
+	if filter == "" {
+		return objs
+	}
+	var conditions [][2]string
+	for _, condition := range strings.Split(filter, ",") {
+		path, value, ok := strings.Cut(condition, "=")
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, [2]string{strings.TrimSpace(path), strings.TrimSpace(value)})
+	}
+
+	return slices.DeleteFunc(objs, func(obj *unstructured.Unstructured) bool {
+		return !slices.ContainsFunc(conditions, func(condition [2]string) bool {
+			return strings.Contains(fieldValue(obj, condition[0]), condition[1])
+		})
+	})
+}
+
+// SortResources sorts the resources like Steve, by the comma separated field paths, descending if prefixed with -.
+func SortResources(objs []*unstructured.Unstructured, sort string) {
+	if sort == "" {
+		return
+	}
+	fields := strings.Split(sort, ",")
+	slices.SortStableFunc(objs, func(a *unstructured.Unstructured, b *unstructured.Unstructured) int {
+		for _, field := range fields {
+			path, descending := strings.CutPrefix(strings.TrimSpace(field), "-")
+			result := cmp.Compare(fieldValue(a, path), fieldValue(b, path))
+			if descending {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+}
+
+// fieldValue returns the value of the field at the dot separated path of the resource, as a string.
+func fieldValue(obj *unstructured.Unstructured, path string) string {
+	value, ok, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(path, ".")...)
+	if !ok || err != nil || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestListWithSteve(t *testing.T) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(),
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-1", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node-2"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-1", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node-1"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-2", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node-1"}},
+	)
+
+	tests := map[string]struct {
+		params        SteveListParams
+		steveStatus   int
+		expectedPath  string
+		expectedQuery url.Values
+		expectedNames []string
+		expectedToken string
+	}{
+		"list with Steve": {
+			params: SteveListParams{
+				Cluster:       "local",
+				Kind:          "deployment",
+				Namespace:     "default",
+				LabelSelector: "app=nginx",
+				Filter:        "metadata.name=nginx",
+				Sort:          "-metadata.name",
+				Limit:         2,
+				Continue:      "page-1",
+			},
+			steveStatus:  http.StatusOK,
+			expectedPath: "/k8s/clusters/local/v1/apps.deployment/default",
+			expectedQuery: url.Values{
+				"labelSelector": {"app=nginx"},
+				"filter":        {"metadata.name=nginx"},
+				"sort":          {"-metadata.name"},
+				"limit":         {"2"},
+				"continue":      {"page-1"},
+			},
+			expectedNames: []string{"nginx-2", "nginx-1"},
+			expectedToken: "page-2",
+		},
+		"list a core kind with Steve": {
+			params:        SteveListParams{Cluster: "local", Kind: "pod"},
+			steveStatus:   http.StatusOK,
+			expectedPath:  "/k8s/clusters/local/v1/pod",
+			expectedQuery: url.Values{},
+			expectedNames: []string{"nginx-2", "nginx-1"},
+			expectedToken: "page-2",
+		},
+		"list a prefixed kind with Steve": {
+			params:        SteveListParams{Cluster: "local", Kind: "provisioningcluster", Namespace: "fleet-default"},
+			steveStatus:   http.StatusOK,
+			expectedPath:  "/k8s/clusters/local/v1/provisioning.cattle.io.cluster/fleet-default",
+			expectedQuery: url.Values{},
+			expectedNames: []string{"nginx-2", "nginx-1"},
+			expectedToken: "page-2",
+		},
+		"fall back to the Kubernetes API": {
+			params: SteveListParams{
+				Cluster:   "local",
+				Kind:      "pod",
+				Namespace: "default",
+				Filter:    "metadata.name=nginx,spec.nodeName=node-3",
+				Sort:      "spec.nodeName,-metadata.name",
+			},
+			steveStatus:   http.StatusNotFound,
+			expectedPath:  "/k8s/clusters/local/v1/pod/default",
+			expectedQuery: url.Values{"filter": {"metadata.name=nginx,spec.nodeName=node-3"}, "sort": {"spec.nodeName,-metadata.name"}},
+			expectedNames: []string{"nginx-2", "nginx-1"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var path string
+			var query url.Values
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				query = r.URL.Query()
+				w.WriteHeader(test.steveStatus)
+				if test.steveStatus != http.StatusOK {
+					return
+				}
+				require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+					"type":     "collection",
+					"continue": "page-2",
+					"data": []map[string]any{
+						{
+							"id":       "default/nginx-2",
+							"type":     "apps.deployment",
+							"links":    map[string]any{"self": "https://rancher/v1/apps.deployments/default/nginx-2"},
+							"metadata": map[string]any{"name": "nginx-2", "namespace": "default", "fields": []any{"nginx-2", 1}, "relationships": []any{}},
+						},
+						{
+							"id":       "default/nginx-1",
+							"type":     "apps.deployment",
+							"metadata": map[string]any{"name": "nginx-1", "namespace": "default", "state": map[string]any{"name": "active"}},
+						},
+					},
+				}))
+			}))
+			defer server.Close()
+			c := NewClient(true)
+			c.DynClientCreator = func(inConfig *rest.Config) (dynamic.Interface, error) {
+				return fakeDynClient, nil
+			}
+			test.params.URL = server.URL
+			test.params.Token = fakeToken
+
+			objs, continueToken, err := c.ListWithSteve(t.Context(), test.params)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedPath, path)
+			assert.Equal(t, test.expectedQuery, query)
+			assert.Equal(t, test.expectedToken, continueToken)
+			names := make([]string, len(objs))
+			for i, obj := range objs {
+				names[i] = obj.GetName()
+				assert.NotContains(t, obj.Object, "id")
+				assert.NotContains(t, obj.Object, "links")
+				assert.NotContains(t, obj.Object["metadata"], "fields")
+				assert.NotContains(t, obj.Object["metadata"], "relationships")
+			}
+			assert.Equal(t, test.expectedNames, names)
+		})
+	}
+}
+
+func TestSortResources(t *testing.T) {
+	pod := func(name string, node string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"metadata": map[string]any{"name": name},
+			"spec":     map[string]any{"nodeName": node},
+		}}
+	}
+	objs := []*unstructured.Unstructured{pod("a", "node-2"), pod("b", "node-1"), pod("c", "node-2"), pod("d", "")}
+
+	SortResources(objs, "-spec.nodeName,metadata.name")
+
+	names := make([]string, len(objs))
+	for i, obj := range objs {
+		names[i] = obj.GetName()
+	}
+	assert.Equal(t, []string{"a", "c", "b", "d"}, names)
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// The headers of the requests made to Rancher correlating them with the MCP request they're made for.
+const (
+	RequestIDHeader   = "X-Request-Id"
+	TraceparentHeader = "traceparent"
+)
+
+// traceCtxKey is the context key of the trace of the request.
+var traceCtxKey = &contextKey{"trace"}
+
+// Trace identifies an MCP request and the W3C trace context it's part of, so the requests made to Rancher for it
+// and its log lines can be correlated.
+type Trace struct {
+	// RequestID identifies the MCP request.
+	RequestID string
+	// TraceID is the hex encoded ID of the trace the request is part of.
+	TraceID string
+	// SpanID is the hex encoded ID of the span of the request, the parent of the requests made to Rancher.
+	SpanID string
+	// Sampled is the sampled flag of the trace, whether its spans are recorded.
+	Sampled bool
+}
+
+// WithTrace sets the trace of the request into the context.
+func WithTrace(ctx context.Context, trace Trace) context.Context {
+	return context.WithValue(ctx, traceCtxKey, trace)
+}
+
+// TraceFrom returns the trace set in the context with WithTrace, if any.
+func TraceFrom(ctx context.Context) (Trace, bool) {
+	trace, ok := ctx.Value(traceCtxKey).(Trace)
+
+	return trace, ok
+}
+
+// Traceparent returns the traceparent header of the trace.
+// https://www.w3.org/TR/trace-context/#traceparent-header
+func (t Trace) Traceparent() string {
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+
+	return "00-" + t.TraceID + "-" + t.SpanID + "-" + flags
+}
+
+// TraceFields returns the log fields of the trace set in the context, so the log lines of a request can be
+// correlated with it. It's empty if there is no trace.
+func TraceFields(ctx context.Context) []zap.Field {
+	trace, ok := TraceFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	return []zap.Field{zap.String("requestId", trace.RequestID), zap.String("traceId", trace.TraceID)}
+}
+
+// traceTransport sets the request ID and the traceparent headers of the trace of the context to the requests.
+type traceTransport struct {
+	next http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace, ok := TraceFrom(req.Context())
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	// a RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set(RequestIDHeader, trace.RequestID)
+	if trace.TraceID != "" && trace.SpanID != "" {
+		req.Header.Set(TraceparentHeader, trace.Traceparent())
+	}
+
+	return t.next.RoundTrip(req)
+}
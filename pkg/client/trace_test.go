@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestTraceHeaders(t *testing.T) {
+	tests := map[string]struct {
+		ctx                 context.Context
+		expectedRequestID   string
+		expectedTraceparent string
+	}{
+		"request with a sampled trace": {
+			ctx: WithTrace(context.Background(), Trace{
+				RequestID: "req-1",
+				TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:    "00f067aa0ba902b7",
+				Sampled:   true,
+			}),
+			expectedRequestID:   "req-1",
+			expectedTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		"request with a trace that isn't sampled": {
+			ctx: WithTrace(context.Background(), Trace{
+				RequestID: "req-2",
+				TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:    "00f067aa0ba902b7",
+			}),
+			expectedRequestID:   "req-2",
+			expectedTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		},
+		"request without a trace": {
+			ctx: context.Background(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+			}))
+			defer server.Close()
+			c := NewClient(true)
+			restConfig, err := c.newRestConfig(server.URL, "local", &clientcmdapi.AuthInfo{Token: "token"})
+			require.NoError(t, err)
+			httpClient, err := rest.HTTPClientFor(restConfig)
+			require.NoError(t, err)
+
+			req, err := http.NewRequestWithContext(test.ctx, http.MethodGet, server.URL+"/k8s/clusters/local/version", nil)
+			require.NoError(t, err)
+			resp, err := httpClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, test.expectedRequestID, header.Get(RequestIDHeader))
+			assert.Equal(t, test.expectedTraceparent, header.Get(TraceparentHeader))
+		})
+	}
+}
+
+func TestTraceFields(t *testing.T) {
+	assert.Empty(t, TraceFields(context.Background()))
+
+	fields := TraceFields(WithTrace(context.Background(), Trace{RequestID: "req-1", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"}))
+	require.Len(t, fields, 2)
+	assert.Equal(t, "requestId", fields[0].Key)
+	assert.Equal(t, "req-1", fields[0].String)
+	assert.Equal(t, "traceId", fields[1].Key)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[1].String)
+}
@@ -16,6 +16,7 @@ const (
 	CAPIClusterResourceKind           = CAPIKindPrefix + "cluster"
 	CAPIMachineSetResourceKind        = CAPIKindPrefix + "machineset"
 	CAPIMachineDeploymentResourceKind = CAPIKindPrefix + "machinedeployment"
+	CAPIClusterClassResourceKind      = CAPIKindPrefix + "clusterclass"
 
 	// ProvisioningKindPrefix is used to differentiate between resources which
 	// share the same kind but are a part of different groups
@@ -51,10 +52,11 @@ var K8sKindsToGVRs = map[string]schema.GroupVersionResource{
 	"limitrange":            {Group: "", Version: "v1", Resource: "limitranges"},
 
 	// --- Apps Resources (Group: "apps") ---
-	"deployment":  {Group: "apps", Version: "v1", Resource: "deployments"},
-	"statefulset": {Group: "apps", Version: "v1", Resource: "statefulsets"},
-	"daemonset":   {Group: "apps", Version: "v1", Resource: "daemonsets"},
-	"replicaset":  {Group: "apps", Version: "v1", Resource: "replicasets"},
+	"deployment":         {Group: "apps", Version: "v1", Resource: "deployments"},
+	"statefulset":        {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"daemonset":          {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	"replicaset":         {Group: "apps", Version: "v1", Resource: "replicasets"},
+	"controllerrevision": {Group: "apps", Version: "v1", Resource: "controllerrevisions"},
 
 	// --- Batch Resources (Group: "batch") ---
 	"job":     {Group: "batch", Version: "v1", Resource: "jobs"},
@@ -65,6 +67,12 @@ var K8sKindsToGVRs = map[string]schema.GroupVersionResource{
 	"networkpolicy": {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
 	"ingressclass":  {Group: "networking.k8s.io", Version: "v1", Resource: "ingressclasses"},
 
+	// --- INGRESS CONTROLLER Resources (Groups: "traefik.io", "networking.istio.io") ---
+	// The Istio kinds are prefixed to avoid a collision with the Gateway API gateways, their unprefixed kinds are discovered.
+	"ingressroute":        {Group: "traefik.io", Version: "v1alpha1", Resource: "ingressroutes"},
+	"istiogateway":        {Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"},
+	"istiovirtualservice": {Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"},
+
 	// --- Autoscaling Resources (Group: "autoscaling") ---
 	"horizontalpodautoscaler": {Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
 	"vpa":                     {Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}, // Note: VPA is separate group
@@ -103,11 +111,33 @@ var K8sKindsToGVRs = map[string]schema.GroupVersionResource{
 	"projectroletemplatebinding":  {Group: ManagementGroup, Version: "v3", Resource: "projectroletemplatebindings"},
 	"nodetemplate":                {Group: ManagementGroup, Version: "v3", Resource: "nodetemplates"},
 	"nodedriver":                  {Group: ManagementGroup, Version: "v3", Resource: "nodedrivers"},
+	"clusterregistrationtoken":    {Group: ManagementGroup, Version: "v3", Resource: "clusterregistrationtokens"},
+	"authconfig":                  {Group: ManagementGroup, Version: "v3", Resource: "authconfigs"},
+	"userattribute":               {Group: ManagementGroup, Version: "v3", Resource: "userattributes"},
+	"multiclusterapp":             {Group: ManagementGroup, Version: "v3", Resource: "multiclusterapps"},
+	"globaldns":                   {Group: ManagementGroup, Version: "v3", Resource: "globaldnses"},
+	"globaldnsprovider":           {Group: ManagementGroup, Version: "v3", Resource: "globaldnsproviders"},
 
 	// --- RANCHER PROVISIONING Resources (Group: "provisioning.cattle.io") ---
 	ProvisioningClusterResourceKind: {Group: ProvisioningGroup, Version: "v1", Resource: "clusters"},
 	"k3kcluster":                    {Group: "k3k.io", Version: "v1beta1", Resource: "clusters"},
 
+	// --- RANCHER RKE Resources (Group: "rke.cattle.io") ---
+	"rkecontrolplane": {Group: "rke.cattle.io", Version: "v1", Resource: "rkecontrolplanes"},
+	"rkebootstrap":    {Group: "rke.cattle.io", Version: "v1", Resource: "rkebootstraps"},
+
+	// --- RANCHER MACHINE CONFIG Resources (Group: "rke-machine-config.cattle.io") ---
+	"amazonec2config":    {Group: MachineConfigGroup, Version: "v1", Resource: "amazonec2configs"},
+	"azureconfig":        {Group: MachineConfigGroup, Version: "v1", Resource: "azureconfigs"},
+	"digitaloceanconfig": {Group: MachineConfigGroup, Version: "v1", Resource: "digitaloceanconfigs"},
+	"harvesterconfig":    {Group: MachineConfigGroup, Version: "v1", Resource: "harvesterconfigs"},
+
+	// --- HARVESTER Resources (Groups: "kubevirt.io", "harvesterhci.io", "k8s.cni.cncf.io"), read in the Harvester clusters ---
+	"virtualmachine":              {Group: "kubevirt.io", Version: "v1", Resource: "virtualmachines"},
+	"virtualmachineinstance":      {Group: "kubevirt.io", Version: "v1", Resource: "virtualmachineinstances"},
+	"virtualmachineimage":         {Group: "harvesterhci.io", Version: "v1beta1", Resource: "virtualmachineimages"},
+	"networkattachmentdefinition": {Group: "k8s.cni.cncf.io", Version: "v1", Resource: "network-attachment-definitions"},
+
 	// --- RANCHER FLEET Resources (Group: "fleet.cattle.io") ---
 	"bundle":           {Group: "fleet.cattle.io", Version: "v1alpha1", Resource: "bundles"},
 	"gitrepo":          {Group: "fleet.cattle.io", Version: "v1alpha1", Resource: "gitrepos"},
@@ -118,6 +148,29 @@ var K8sKindsToGVRs = map[string]schema.GroupVersionResource{
 	// --- RANCHER CATTLE Resources (Group: "cattle.io") ---
 	"setting": {Group: ManagementGroup, Version: "v3", Resource: "settings"},
 
+	// --- RANCHER CATALOG Resources (Group: "catalog.cattle.io") ---
+	"clusterrepo": {Group: "catalog.cattle.io", Version: "v1", Resource: "clusterrepos"},
+	"app":         {Group: "catalog.cattle.io", Version: "v1", Resource: "apps"},
+
+	// --- RANCHER BACKUP Resources (Group: "resources.cattle.io") ---
+	"backup":      {Group: "resources.cattle.io", Version: "v1", Resource: "backups"},
+	"restore":     {Group: "resources.cattle.io", Version: "v1", Resource: "restores"},
+	"resourceset": {Group: "resources.cattle.io", Version: "v1", Resource: "resourcesets"},
+
+	// --- SYSTEM UPGRADE CONTROLLER Resources (Group: "upgrade.cattle.io") ---
+	"plan": {Group: "upgrade.cattle.io", Version: "v1", Resource: "plans"},
+
+	// --- TRIVY OPERATOR Resources (Group: "aquasecurity.github.io") ---
+	"vulnerabilityreport": {Group: "aquasecurity.github.io", Version: "v1alpha1", Resource: "vulnerabilityreports"},
+
+	// --- POLICY REPORT Resources (Group: "wgpolicyk8s.io"), created by the Kubewarden audit scanner ---
+	"policyreport":        {Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"},
+	"clusterpolicyreport": {Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"},
+
+	// --- KUBEWARDEN Resources (Group: "policies.kubewarden.io") ---
+	"clusteradmissionpolicy": {Group: "policies.kubewarden.io", Version: "v1", Resource: "clusteradmissionpolicies"},
+	"admissionpolicy":        {Group: "policies.kubewarden.io", Version: "v1", Resource: "admissionpolicies"},
+
 	// --- CLUSTER API Resources (Group: "cluster.x-k8s.io") ---
 	// NB: version is intentionally left empty as it can vary (v1beta1, v1beta2, etc.) depending on the version
 	// of Rancher being used. Instead of hardcoding the version, we instead query all available versions when looking
@@ -126,4 +179,5 @@ var K8sKindsToGVRs = map[string]schema.GroupVersionResource{
 	CAPIMachineResourceKind:           {Group: CAPIGroup, Version: "", Resource: "machines"},
 	CAPIMachineSetResourceKind:        {Group: CAPIGroup, Version: "", Resource: "machinesets"},
 	CAPIMachineDeploymentResourceKind: {Group: CAPIGroup, Version: "", Resource: "machinedeployments"},
+	CAPIClusterClassResourceKind:      {Group: CAPIGroup, Version: "", Resource: "clusterclasses"},
 }
@@ -0,0 +1,102 @@
+// Package deprecations is the database of the Kubernetes APIs deprecated and removed by the Kubernetes releases. It's
+// embedded in the binary, so manifests can be checked against a Kubernetes version without a cluster running it.
+package deprecations
+
+import (
+	_ "embed"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RemovedStatus is the status of an API that isn't served anymore by the Kubernetes version.
+	RemovedStatus = "removed"
+	// DeprecatedStatus is the status of an API that is still served by the Kubernetes version, but deprecated.
+	DeprecatedStatus = "deprecated"
+)
+
+//go:embed deprecations.yaml
+var deprecationsYAML []byte
+
+// Deprecation is an API version of a kind deprecated by a Kubernetes release.
+type Deprecation struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+	// DeprecatedIn is the Kubernetes release deprecating the API, e.g. 1.21.
+	DeprecatedIn string `json:"deprecatedIn"`
+	// RemovedIn is the Kubernetes release not serving the API anymore. It's empty if the removal isn't planned.
+	RemovedIn string `json:"removedIn,omitempty"`
+	// Replacement is the API to migrate to, e.g. batch/v1.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+var deprecations = mustLoad(deprecationsYAML)
+
+func mustLoad(data []byte) []Deprecation {
+	var all []Deprecation
+	if err := yaml.Unmarshal(data, &all); err != nil {
+		panic(fmt.Sprintf("deprecations: invalid database: %v", err))
+	}
+	for _, d := range all {
+		if _, err := version.ParseGeneric(d.DeprecatedIn); err != nil {
+			panic(fmt.Sprintf("deprecations: invalid deprecatedIn of %s/%s %s: %v", d.Group, d.Version, d.Kind, err))
+		}
+		if _, err := version.ParseGeneric(d.RemovedIn); d.RemovedIn != "" && err != nil {
+			panic(fmt.Sprintf("deprecations: invalid removedIn of %s/%s %s: %v", d.Group, d.Version, d.Kind, err))
+		}
+	}
+
+	return all
+}
+
+// Lookup returns the deprecation of the kind in the API version, e.g. batch/v1beta1 and CronJob.
+func Lookup(apiVersion string, kind string) (Deprecation, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return Deprecation{}, false
+	}
+	for _, d := range deprecations {
+		if d.Group == gv.Group && d.Version == gv.Version && d.Kind == kind {
+			return d, true
+		}
+	}
+
+	return Deprecation{}, false
+}
+
+// LookupResource returns the deprecation of the resource in the group and the version, e.g. batch, v1beta1 and
+// cronjobs.
+func LookupResource(group string, version string, resource string) (Deprecation, bool) {
+	for _, d := range deprecations {
+		if d.Group == group && d.Version == version && d.Resource == resource {
+			return d, true
+		}
+	}
+
+	return Deprecation{}, false
+}
+
+// APIVersion returns the API version of the deprecated API, e.g. batch/v1beta1.
+func (d Deprecation) APIVersion() string {
+	return schema.GroupVersion{Group: d.Group, Version: d.Version}.String()
+}
+
+// Status returns RemovedStatus if the API isn't served by the Kubernetes version, DeprecatedStatus if it's deprecated
+// in it, or an empty string if it's not deprecated yet.
+func (d Deprecation) Status(k8sVersion *version.Version) string {
+	// Only the minor release matters, e.g. v1.25.0-rc.1 doesn't serve the APIs removed in 1.25.
+	release := version.MajorMinor(k8sVersion.Major(), k8sVersion.Minor())
+	if d.RemovedIn != "" && !release.LessThan(version.MustParseGeneric(d.RemovedIn)) {
+		return RemovedStatus
+	}
+	if !release.LessThan(version.MustParseGeneric(d.DeprecatedIn)) {
+		return DeprecatedStatus
+	}
+
+	return ""
+}
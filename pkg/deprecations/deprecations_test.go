@@ -0,0 +1,66 @@
+package deprecations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestLookup(t *testing.T) {
+	d, ok := Lookup("batch/v1beta1", "CronJob")
+	require.True(t, ok)
+	assert.Equal(t, Deprecation{Group: "batch", Version: "v1beta1", Kind: "CronJob", Resource: "cronjobs", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "batch/v1"}, d)
+	assert.Equal(t, "batch/v1beta1", d.APIVersion())
+
+	d, ok = LookupResource("", "v1", "endpoints")
+	require.True(t, ok)
+	assert.Equal(t, "v1", d.APIVersion())
+
+	_, ok = Lookup("batch/v1", "CronJob")
+	assert.False(t, ok)
+	_, ok = Lookup("a/b/c", "CronJob")
+	assert.False(t, ok)
+}
+
+func TestStatus(t *testing.T) {
+	cronJob, _ := Lookup("batch/v1beta1", "CronJob")
+	endpoints, _ := Lookup("v1", "Endpoints")
+	tests := map[string]struct {
+		deprecation    Deprecation
+		k8sVersion     string
+		expectedStatus string
+	}{
+		"not deprecated yet": {
+			deprecation: cronJob,
+			k8sVersion:  "v1.20.15",
+		},
+		"deprecated": {
+			deprecation:    cronJob,
+			k8sVersion:     "v1.24.17+rke2r1",
+			expectedStatus: DeprecatedStatus,
+		},
+		"removed": {
+			deprecation:    cronJob,
+			k8sVersion:     "v1.25.0",
+			expectedStatus: RemovedStatus,
+		},
+		"removed in a pre-release": {
+			deprecation:    cronJob,
+			k8sVersion:     "v1.25.0-rc.1",
+			expectedStatus: RemovedStatus,
+		},
+		"deprecated without removal": {
+			deprecation:    endpoints,
+			k8sVersion:     "v1.40.0",
+			expectedStatus: DeprecatedStatus,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedStatus, test.deprecation.Status(version.MustParseGeneric(test.k8sVersion)))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package fetch runs the independent reads of the composite tools concurrently, so a tool returns in about the
+// time of its slowest read instead of the sum of all of them.
+package fetch
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultTimeout is the default time limit of each fetch.
+const DefaultTimeout = 30 * time.Second
+
+// Failure is an optional fetch that failed. It's returned to the LLM with the resources that could be read.
+type Failure struct {
+	Fetch string `json:"fetch"`
+	Error string `json:"error"`
+}
+
+// Unstructured returns the failure as an object of a tool response, alongside the resources.
+func (f Failure) Unstructured() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{"fetch": f.Fetch, "error": f.Error}}
+}
+
+// progressKey is the context key of the Progress of a tool call.
+type progressKey struct{}
+
+// Progress records the fetches of a tool call that completed and the ones still running, so a tool call that times
+// out can tell which of its fetches completed.
+type Progress struct {
+	mu        sync.Mutex
+	completed []string
+	pending   map[string]int
+}
+
+// WithProgress returns a context recording the progress of the fetches of the Groups created with it.
+func WithProgress(ctx context.Context) (context.Context, *Progress) {
+	progress := &Progress{pending: map[string]int{}}
+
+	return context.WithValue(ctx, progressKey{}, progress), progress
+}
+
+// progressFrom returns the Progress of the context, or nil if it doesn't record one.
+func progressFrom(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(progressKey{}).(*Progress)
+	return progress
+}
+
+// start records a fetch as running.
+func (p *Progress) start(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[name]++
+}
+
+// done records a running fetch as completed.
+func (p *Progress) done(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[name]--
+	if p.pending[name] == 0 {
+		delete(p.pending, name)
+	}
+	p.completed = append(p.completed, name)
+}
+
+// Completed returns the names of the fetches that completed, successfully or not, sorted.
+func (p *Progress) Completed() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	completed := slices.Clone(p.completed)
+	slices.Sort(completed)
+
+	return slices.Compact(completed)
+}
+
+// Pending returns the names of the fetches still running, sorted.
+func (p *Progress) Pending() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending := make([]string, 0, len(p.pending))
+	for name := range p.pending {
+		pending = append(pending, name)
+	}
+	slices.Sort(pending)
+
+	return pending
+}
+
+// Group runs fetches concurrently, each with its own timeout. A required fetch that fails cancels the others and
+// its error is returned by Wait. An optional fetch that fails is only recorded, so the tool can still return the
+// resources read by the other fetches.
+type Group struct {
+	g        *errgroup.Group
+	ctx      context.Context
+	timeout  time.Duration
+	progress *Progress
+
+	mu       sync.Mutex
+	failures []Failure
+}
+
+// New creates a Group whose fetches are cancelled with the context, or after the timeout. If the timeout is 0,
+// DefaultTimeout is used. The fetches are recorded in the Progress of the context, if any.
+func New(ctx context.Context, timeout time.Duration) *Group {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+
+	return &Group{g: g, ctx: gCtx, timeout: timeout, progress: progressFrom(ctx)}
+}
+
+// Go runs a required fetch, named in the Progress of the context.
+func (g *Group) Go(name string, fetch func(ctx context.Context) error) {
+	g.progress.start(name)
+	g.g.Go(func() error {
+		ctx, cancel := context.WithTimeout(g.ctx, g.timeout)
+		defer cancel()
+		defer g.progress.done(name)
+
+		return fetch(ctx)
+	})
+}
+
+// GoOptional runs an optional fetch, whose error is returned by Wait as a Failure with the name.
+func (g *Group) GoOptional(name string, fetch func(ctx context.Context) error) {
+	g.progress.start(name)
+	g.g.Go(func() error {
+		ctx, cancel := context.WithTimeout(g.ctx, g.timeout)
+		defer cancel()
+		defer g.progress.done(name)
+
+		if err := fetch(ctx); err != nil {
+			g.mu.Lock()
+			g.failures = append(g.failures, Failure{Fetch: name, Error: err.Error()})
+			g.mu.Unlock()
+		}
+		return nil
+	})
+}
+
+// Wait waits for all the fetches, and returns the failures of the optional ones sorted by name, and the first
+// error of the required ones.
+func (g *Group) Wait() ([]Failure, error) {
+	err := g.g.Wait()
+	sort.Slice(g.failures, func(i, j int) bool {
+		return g.failures[i].Fetch < g.failures[j].Fetch
+	})
+
+	return g.failures, err
+}
@@ -0,0 +1,110 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	sleep := func(d time.Duration) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+				return nil
+			}
+		}
+	}
+	fail := func(msg string) func(ctx context.Context) error {
+		return func(context.Context) error { return errors.New(msg) }
+	}
+
+	tests := map[string]struct {
+		timeout          time.Duration
+		required         []func(ctx context.Context) error
+		optional         map[string]func(ctx context.Context) error
+		expectedFailures []Failure
+		expectedError    string
+		maxDuration      time.Duration
+	}{
+		"fetches run concurrently": {
+			required:    []func(ctx context.Context) error{sleep(100 * time.Millisecond), sleep(100 * time.Millisecond), sleep(100 * time.Millisecond)},
+			optional:    map[string]func(ctx context.Context) error{"metrics": sleep(100 * time.Millisecond)},
+			maxDuration: 250 * time.Millisecond,
+		},
+		"optional fetches fail without an error": {
+			required: []func(ctx context.Context) error{sleep(0)},
+			optional: map[string]func(ctx context.Context) error{
+				"logs":    fail("container not started"),
+				"events":  fail("forbidden"),
+				"metrics": sleep(0),
+			},
+			expectedFailures: []Failure{{Fetch: "events", Error: "forbidden"}, {Fetch: "logs", Error: "container not started"}},
+		},
+		"required fetch fails and cancels the others": {
+			required:      []func(ctx context.Context) error{fail("pods is forbidden"), sleep(time.Minute)},
+			expectedError: "pods is forbidden",
+			maxDuration:   time.Second,
+		},
+		"slow fetch times out": {
+			timeout:          50 * time.Millisecond,
+			optional:         map[string]func(ctx context.Context) error{"logs": sleep(time.Minute)},
+			expectedFailures: []Failure{{Fetch: "logs", Error: "context deadline exceeded"}},
+			maxDuration:      time.Second,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			g := New(t.Context(), test.timeout)
+			for i, fetch := range test.required {
+				g.Go(fmt.Sprintf("required-%d", i), fetch)
+			}
+			for name, fetch := range test.optional {
+				g.GoOptional(name, fetch)
+			}
+
+			failures, err := g.Wait()
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedFailures, failures)
+			if test.maxDuration > 0 {
+				assert.Less(t, time.Since(start), test.maxDuration)
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	ctx, progress := WithProgress(t.Context())
+	release := make(chan struct{})
+	g := New(ctx, 0)
+	g.Go("pod", func(context.Context) error { return nil })
+	g.GoOptional("logs", func(context.Context) error { return errors.New("container not started") })
+	g.GoOptional("events", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	assert.EventuallyWithT(t, func(collect *assert.CollectT) {
+		assert.Equal(collect, []string{"logs", "pod"}, progress.Completed())
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"events"}, progress.Pending())
+
+	close(release)
+	_, err := g.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"events", "logs", "pod"}, progress.Completed())
+	assert.Empty(t, progress.Pending())
+}
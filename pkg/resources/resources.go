@@ -0,0 +1,286 @@
+// Package resources exposes Kubernetes resources as MCP resources, so clients can read them and subscribe to their
+// changes instead of polling them with repeated tool calls.
+//
+// A resource is identified by a URI with the cluster, the kind, the namespace and the name of the Kubernetes resource:
+//
+//	k8s://{cluster}/{kind}/{namespace}/{name}
+//	k8s://{cluster}/{kind}/{name}
+//
+// The second form is used for cluster-scoped resources. Subscriptions are backed by a Kubernetes watch of the
+// resource, and subscribed clients receive a resources/updated notification every time it changes.
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// urlHeader is the header with the URL of the Rancher server, set by the CredentialsMiddleware.
+	urlHeader = client.URLHeader
+	scheme    = "k8s"
+	// watchRetryInterval is the time to wait before restarting a watch that failed.
+	watchRetryInterval = 5 * time.Second
+)
+
+type resourcesClient interface {
+	GetResourceInterface(ctx context.Context, token string, url string, namespace string, cluster string, gvr schema.GroupVersionResource) (dynamic.ResourceInterface, error)
+	ResolveGVR(ctx context.Context, token string, url string, cluster string, kind string) (schema.GroupVersionResource, error)
+}
+
+// Resources reads the Kubernetes resources of the MCP resource URIs and watches the subscribed ones.
+type Resources struct {
+	client resourcesClient
+	// notify sends the resources/updated notification of the URI to the subscribed sessions.
+	notify func(ctx context.Context, uri string)
+
+	mu      sync.Mutex
+	watches map[string]*resourceWatch
+}
+
+// resourceWatch is the watch of a subscribed URI, shared by all the sessions subscribed to it.
+type resourceWatch struct {
+	cancel   context.CancelFunc
+	sessions map[*mcp.ServerSession]bool
+}
+
+// resourceRef is the Kubernetes resource identified by a URI.
+type resourceRef struct {
+	cluster   string
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewResources creates and returns a new Resources instance.
+func NewResources(client *client.Client) *Resources {
+	return &Resources{
+		client:  client,
+		watches: map[string]*resourceWatch{},
+	}
+}
+
+// ServerOptions returns the options of the MCP server with the handlers of the resource subscriptions.
+func (r *Resources) ServerOptions() *mcp.ServerOptions {
+	return &mcp.ServerOptions{
+		SubscribeHandler:   r.subscribe,
+		UnsubscribeHandler: r.unsubscribe,
+	}
+}
+
+// AddResources registers the resource templates with the provided MCP server, which must be created with
+// ServerOptions to support subscriptions.
+func (r *Resources) AddResources(mcpServer *mcp.Server) {
+	r.notify = func(ctx context.Context, uri string) {
+		if err := mcpServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+			zap.L().Error("failed to notify resource update", zap.String("uri", uri), zap.Error(err))
+		}
+	}
+
+	mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "namespacedKubernetesResource",
+		URITemplate: scheme + "://{cluster}/{kind}/{namespace}/{name}",
+		MIMEType:    "application/json",
+		Description: `A namespaced Kubernetes resource of a cluster managed by Rancher (e.g. k8s://local/deployment/default/nginx).
+		Subscribe to it to be notified when it changes, e.g. when a deployment finishes rolling out, instead of getting it repeatedly.`,
+	}, r.read)
+	mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "clusterKubernetesResource",
+		URITemplate: scheme + "://{cluster}/{kind}/{name}",
+		MIMEType:    "application/json",
+		Description: `A cluster-scoped Kubernetes resource of a cluster managed by Rancher (e.g. k8s://local/node/worker-1).
+		Subscribe to it to be notified when it changes instead of getting it repeatedly.`,
+	}, r.read)
+}
+
+// read returns the JSON representation of the resource, like the getKubernetesResource tool. The resource isn't
+// read from the client cache, so clients notified of a change always read the new version.
+func (r *Resources) read(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	ref, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	resourceInterface, err := r.resourceInterface(ctx, req.Extra, ref)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := resourceInterface.Get(ctx, ref.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if err != nil {
+		zap.L().Error("failed to read resource", zap.String("uri", uri), zap.Error(err))
+		return nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, ref.cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: mcpResponse}},
+	}, nil
+}
+
+// subscribe starts watching the resource if the session is the first one subscribed to it. The resource is read
+// with the credentials of the subscriber first, so users can't subscribe to resources they can't get.
+func (r *Resources) subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	ref, err := parseURI(uri)
+	if err != nil {
+		return err
+	}
+	resourceInterface, err := r.resourceInterface(ctx, req.Extra, ref)
+	if err != nil {
+		return err
+	}
+	obj, err := resourceInterface.Get(ctx, ref.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.watches[uri]
+	if !ok {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		w = &resourceWatch{cancel: cancel, sessions: map[*mcp.ServerSession]bool{}}
+		r.watches[uri] = w
+		go r.watch(watchCtx, uri, resourceInterface, ref.name, obj.GetResourceVersion())
+	}
+	if !w.sessions[req.Session] {
+		w.sessions[req.Session] = true
+		if req.Session != nil {
+			// Sessions can end without unsubscribing, so their subscriptions are removed when they are closed.
+			go func(session *mcp.ServerSession) {
+				_ = session.Wait()
+				r.removeSubscription(uri, session)
+			}(req.Session)
+		}
+	}
+
+	return nil
+}
+
+// unsubscribe stops watching the resource if the session was the last one subscribed to it.
+func (r *Resources) unsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	r.removeSubscription(req.Params.URI, req.Session)
+
+	return nil
+}
+
+func (r *Resources) removeSubscription(uri string, session *mcp.ServerSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.watches[uri]
+	if !ok {
+		return
+	}
+	delete(w.sessions, session)
+	if len(w.sessions) == 0 {
+		w.cancel()
+		delete(r.watches, uri)
+	}
+}
+
+// watch notifies the changes of the resource until the context is cancelled. Watches closed by the API server are
+// restarted from the last resource version seen, or from the current version if it's too old.
+func (r *Resources) watch(ctx context.Context, uri string, resourceInterface dynamic.ResourceInterface, name, resourceVersion string) {
+	for ctx.Err() == nil {
+		watcher, err := resourceInterface.Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			zap.L().Warn("failed to watch resource", zap.String("uri", uri), zap.Error(err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchRetryInterval):
+			}
+			continue
+		}
+		resourceVersion = r.notifyEvents(ctx, uri, watcher, resourceVersion)
+		watcher.Stop()
+	}
+}
+
+// notifyEvents notifies the changes received by the watcher until it's closed, and returns the resource version
+// the next watch must start from.
+func (r *Resources) notifyEvents(ctx context.Context, uri string, watcher watch.Interface, resourceVersion string) string {
+	for event := range watcher.ResultChan() {
+		switch event.Type {
+		case watch.Error:
+			zap.L().Debug("resource watch failed", zap.String("uri", uri), zap.Error(apierrors.FromObject(event.Object)))
+			// The resource version may be too old, restart from the current version. The first event of the new
+			// watch is notified as a change, since the resource may have changed in the meantime.
+			return ""
+		case watch.Bookmark:
+		default:
+			r.notify(ctx, uri)
+		}
+		if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+			resourceVersion = obj.GetResourceVersion()
+		}
+	}
+
+	return resourceVersion
+}
+
+func (r *Resources) resourceInterface(ctx context.Context, extra *mcp.RequestExtra, ref resourceRef) (dynamic.ResourceInterface, error) {
+	var url string
+	if extra != nil {
+		url = extra.Header.Get(urlHeader)
+	}
+	token := middleware.Token(ctx)
+	gvr, err := r.client.ResolveGVR(ctx, token, url, ref.cluster, ref.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.client.GetResourceInterface(ctx, token, url, ref.namespace, ref.cluster, gvr)
+}
+
+// parseURI returns the Kubernetes resource identified by a k8s://{cluster}/{kind}/[{namespace}/]{name} URI.
+func parseURI(uri string) (resourceRef, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != scheme || parsed.Host == "" {
+		return resourceRef{}, fmt.Errorf("invalid resource URI %q, must be %s://<cluster>/<kind>/[<namespace>/]<name>", uri, scheme)
+	}
+
+	ref := resourceRef{cluster: parsed.Host}
+	segments := strings.Split(strings.TrimPrefix(parsed.Path, "/"), "/")
+	switch len(segments) {
+	case 2:
+		ref.kind, ref.name = segments[0], segments[1]
+	case 3:
+		ref.kind, ref.namespace, ref.name = segments[0], segments[1], segments[2]
+	}
+	if ref.kind == "" || ref.name == "" {
+		return resourceRef{}, fmt.Errorf("invalid resource URI %q, must be %s://<cluster>/<kind>/[<namespace>/]<name>", uri, scheme)
+	}
+
+	return ref, nil
+}
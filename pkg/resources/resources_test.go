@@ -0,0 +1,163 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+var fakePod = &corev1.Pod{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "rancher",
+		Namespace: "default",
+	},
+	Spec: corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "rancher-container",
+				Image: "rancher:latest",
+			},
+		},
+	},
+}
+
+func TestParseURI(t *testing.T) {
+	tests := map[string]struct {
+		uri           string
+		expectedRef   resourceRef
+		expectedError string
+	}{
+		"namespaced resource": {
+			uri:         "k8s://local/deployment/default/nginx",
+			expectedRef: resourceRef{cluster: "local", kind: "deployment", namespace: "default", name: "nginx"},
+		},
+		"cluster-scoped resource": {
+			uri:         "k8s://c-m-abc123/node/worker-1",
+			expectedRef: resourceRef{cluster: "c-m-abc123", kind: "node", name: "worker-1"},
+		},
+		"invalid scheme": {
+			uri:           "https://local/node/worker-1",
+			expectedError: `invalid resource URI "https://local/node/worker-1"`,
+		},
+		"missing name": {
+			uri:           "k8s://local/node",
+			expectedError: `invalid resource URI "k8s://local/node"`,
+		},
+		"too many segments": {
+			uri:           "k8s://local/pod/default/nginx/logs",
+			expectedError: `invalid resource URI "k8s://local/pod/default/nginx/logs"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := parseURI(test.uri)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedRef, ref)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme, fakePod)
+	resources := NewResources(&client.Client{
+		DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	})
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test"}, resources.ServerOptions())
+	resources.AddResources(mcpServer)
+
+	updated := make(chan string, 10)
+	mcpClient := mcp.NewClient(&mcp.Implementation{Name: "test"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+	session, err := mcpClient.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	uri := "k8s://local/pod/default/rancher"
+	result, err := session.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: uri})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"llm":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"rancher","namespace":"default"},"spec":{"containers":[{"image":"rancher:latest","name":"rancher-container","resources":{}}]},"status":{}}],"uiContext":[{"namespace":"default","kind":"Pod","cluster":"local","name":"rancher","type":"pod"}]}`, result.Contents[0].Text)
+
+	_, err = session.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: "k8s://local/pod/default/missing"})
+	assert.ErrorContains(t, err, "Resource not found")
+	assert.ErrorContains(t, session.Subscribe(t.Context(), &mcp.SubscribeParams{URI: "k8s://local/pod/default/missing"}), "Resource not found")
+
+	require.NoError(t, session.Subscribe(t.Context(), &mcp.SubscribeParams{URI: uri}))
+	require.Eventually(t, func() bool {
+		pods := fakeDynClient.Resource(converter.K8sKindsToGVRs["pod"]).Namespace("default")
+		pod, err := pods.Get(t.Context(), "rancher", metav1.GetOptions{})
+		require.NoError(t, err)
+		pod.SetLabels(map[string]string{"updated": "true"})
+		_, err = pods.Update(t.Context(), pod, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		select {
+		case notifiedURI := <-updated:
+			return notifiedURI == uri
+		case <-time.After(100 * time.Millisecond):
+			// The watch may not be started yet, update the pod again.
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, session.Unsubscribe(t.Context(), &mcp.UnsubscribeParams{URI: uri}))
+	resources.mu.Lock()
+	defer resources.mu.Unlock()
+	assert.Empty(t, resources.watches)
+}
+
+func TestSubscriptionsRemovedWhenSessionCloses(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme, fakePod)
+	resources := NewResources(&client.Client{
+		DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	})
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test"}, resources.ServerOptions())
+	resources.AddResources(mcpServer)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, session.Subscribe(t.Context(), &mcp.SubscribeParams{URI: "k8s://local/pod/default/rancher"}))
+	require.NoError(t, session.Close())
+	_ = serverSession.Wait()
+
+	assert.Eventually(t, func() bool {
+		resources.mu.Lock()
+		defer resources.mu.Unlock()
+		return len(resources.watches) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
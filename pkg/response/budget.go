@@ -0,0 +1,169 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// DefaultMaxBytes is the default size limit of the responses sent to the LLM, around 50k tokens.
+	DefaultMaxBytes = 200 * 1024
+	// maxDataFieldBytes is the size above which the values of data fields are omitted from oversized responses.
+	maxDataFieldBytes = 1024
+)
+
+// maxBytes is the size limit of the responses. A value lower or equal to 0 disables the limit.
+var maxBytes = DefaultMaxBytes
+
+// SetMaxBytes sets the size limit of the responses created by this package. Responses exceeding it are compacted
+// and, if they are still too big, replaced by a summary of the resources. A value lower or equal to 0 disables the limit.
+func SetMaxBytes(n int) {
+	maxBytes = n
+}
+
+// summary is sent to the LLM instead of the resources when they don't fit in the response size limit.
+type summary struct {
+	Summary   string           `json:"summary"`
+	Resources []map[string]any `json:"resources"`
+}
+
+// marshalResponse marshals the response with the given items as the LLM content, reducing it to fit in maxBytes.
+// Items are first compacted by removing the old status conditions and the large data fields, and a list is
+// replaced by a summary of its resources when it's still too big.
+func marshalResponse(resp MCPResponse, items []map[string]any) (string, error) {
+	if len(items) == 0 {
+		resp.LLM = "no resources found"
+		return marshal(resp)
+	}
+
+	resp.LLM = items
+	bytes, err := marshal(resp)
+	if err != nil || maxBytes <= 0 || len(bytes) <= maxBytes {
+		return bytes, err
+	}
+
+	for _, item := range items {
+		compact(item)
+	}
+	bytes, err = marshal(resp)
+	if err != nil || len(bytes) <= maxBytes || len(items) == 1 {
+		return bytes, err
+	}
+
+	rows := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, summarize(item))
+	}
+	resp.LLM = summary{
+		Summary: fmt.Sprintf("the response exceeded %d bytes, only a summary of the %d resources is shown. "+
+			"Get the resources individually, or use a label selector, a field selector or a limit to see their details", maxBytes, len(items)),
+		Resources: rows,
+	}
+
+	return marshal(resp)
+}
+
+func marshal(resp MCPResponse) (string, error) {
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return string(bytes), nil
+}
+
+// compact keeps only the latest status condition and omits the values of the data fields bigger than maxDataFieldBytes.
+func compact(item map[string]any) {
+	if status, ok := item["status"].(map[string]any); ok {
+		if conditions, ok := status["conditions"].([]any); ok && len(conditions) > 1 {
+			status["conditions"] = []any{latestCondition(conditions)}
+		}
+	}
+
+	for _, field := range []string{"data", "binaryData", "stringData"} {
+		data, ok := item[field].(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, value := range data {
+			if s, ok := value.(string); ok && len(s) > maxDataFieldBytes {
+				data[key] = fmt.Sprintf("<%d bytes omitted>", len(s))
+			}
+		}
+	}
+}
+
+// latestCondition returns the condition with the most recent lastTransitionTime, or the last one if they have none.
+func latestCondition(conditions []any) any {
+	latest := conditions[len(conditions)-1]
+	latestTime := ""
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]any)
+		if !ok {
+			continue
+		}
+		// RFC 3339 timestamps in UTC can be compared as strings
+		if t, _ := c["lastTransitionTime"].(string); t > latestTime {
+			latest, latestTime = condition, t
+		}
+	}
+
+	return latest
+}
+
+// summarize returns the kind, name, namespace and key status of the item. The cluster and error fields
+// added to the items of multi-cluster responses are kept.
+func summarize(item map[string]any) map[string]any {
+	row := map[string]any{}
+	for _, field := range []string{"cluster", "error", "kind"} {
+		if value, ok := item[field]; ok {
+			row[field] = value
+		}
+	}
+	if metadata, ok := item["metadata"].(map[string]any); ok {
+		for _, field := range []string{"name", "namespace"} {
+			if value, ok := metadata[field]; ok {
+				row[field] = value
+			}
+		}
+	}
+	if status := keyStatus(item); status != "" {
+		row["status"] = status
+	}
+
+	return row
+}
+
+// keyStatus returns a short description of the status of the item: its phase, ready replicas or Ready condition.
+func keyStatus(item map[string]any) string {
+	status, ok := item["status"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	if phase, ok := status["phase"].(string); ok && phase != "" {
+		return phase
+	}
+	if replicas, ok := status["replicas"]; ok {
+		readyReplicas, ok := status["readyReplicas"]
+		if !ok {
+			readyReplicas = 0
+		}
+		return fmt.Sprintf("%v/%v ready", readyReplicas, replicas)
+	}
+
+	conditions, _ := status["conditions"].([]any)
+	var fallback string
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]any)
+		if !ok {
+			continue
+		}
+		description := fmt.Sprintf("%v=%v", c["type"], c["status"])
+		if c["type"] == "Ready" || c["type"] == "Available" {
+			return description
+		}
+		fallback = description
+	}
+
+	return fallback
+}
@@ -0,0 +1,135 @@
+package response
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateMcpResponseWithMaxBytes(t *testing.T) {
+	largeValue := strings.Repeat("a", maxDataFieldBytes+1)
+	conditions := []any{
+		map[string]any{"type": "Available", "status": "True", "lastTransitionTime": "2025-01-02T00:00:00Z"},
+		map[string]any{"type": "Progressing", "status": "True", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+	}
+	deployment := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+			"status":     map[string]any{"replicas": int64(2), "readyReplicas": int64(1), "conditions": conditions},
+		}}
+	}
+	configMap := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+			"data":       map[string]any{"small": "value", "large": largeValue},
+		}}
+	}
+
+	tests := map[string]struct {
+		objs     []*unstructured.Unstructured
+		maxBytes int
+		expected string
+	}{
+		"response under the limit is not modified": {
+			objs:     []*unstructured.Unstructured{deployment("nginx")},
+			maxBytes: DefaultMaxBytes,
+			expected: `{"llm":[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"nginx","namespace":"default"},"status":{"conditions":[{"lastTransitionTime":"2025-01-02T00:00:00Z","status":"True","type":"Available"},{"lastTransitionTime":"2025-01-01T00:00:00Z","status":"True","type":"Progressing"}],"readyReplicas":1,"replicas":2}}],"uiContext":[{"namespace":"default","kind":"Deployment","cluster":"local","name":"nginx","type":"apps.deployment"}]}`,
+		},
+		"limit disabled": {
+			objs:     []*unstructured.Unstructured{configMap("config")},
+			maxBytes: 0,
+			expected: `{"llm":[{"apiVersion":"v1","data":{"large":"` + largeValue + `","small":"value"},"kind":"ConfigMap","metadata":{"name":"config","namespace":"default"}}],"uiContext":[{"namespace":"default","kind":"ConfigMap","cluster":"local","name":"config","type":"configmap"}]}`,
+		},
+		"oversized single resource is compacted": {
+			objs:     []*unstructured.Unstructured{configMap("config")},
+			maxBytes: 500,
+			expected: `{"llm":[{"apiVersion":"v1","data":{"large":"<1025 bytes omitted>","small":"value"},"kind":"ConfigMap","metadata":{"name":"config","namespace":"default"}}],"uiContext":[{"namespace":"default","kind":"ConfigMap","cluster":"local","name":"config","type":"configmap"}]}`,
+		},
+		"oversized list fitting after compaction": {
+			objs:     []*unstructured.Unstructured{deployment("nginx"), deployment("redis")},
+			maxBytes: 700,
+			expected: `{"llm":[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"nginx","namespace":"default"},"status":{"conditions":[{"lastTransitionTime":"2025-01-02T00:00:00Z","status":"True","type":"Available"}],"readyReplicas":1,"replicas":2}},{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"redis","namespace":"default"},"status":{"conditions":[{"lastTransitionTime":"2025-01-02T00:00:00Z","status":"True","type":"Available"}],"readyReplicas":1,"replicas":2}}],"uiContext":[{"namespace":"default","kind":"Deployment","cluster":"local","name":"nginx","type":"apps.deployment"},{"namespace":"default","kind":"Deployment","cluster":"local","name":"redis","type":"apps.deployment"}]}`,
+		},
+		"oversized list is summarized": {
+			objs:     []*unstructured.Unstructured{deployment("nginx"), configMap("config")},
+			maxBytes: 300,
+			expected: `{"llm":{"summary":"the response exceeded 300 bytes, only a summary of the 2 resources is shown. Get the resources individually, or use a label selector, a field selector or a limit to see their details","resources":[{"kind":"Deployment","name":"nginx","namespace":"default","status":"1/2 ready"},{"kind":"ConfigMap","name":"config","namespace":"default"}]},"uiContext":[{"namespace":"default","kind":"Deployment","cluster":"local","name":"nginx","type":"apps.deployment"},{"namespace":"default","kind":"ConfigMap","cluster":"local","name":"config","type":"configmap"}]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			SetMaxBytes(test.maxBytes)
+			t.Cleanup(func() { SetMaxBytes(DefaultMaxBytes) })
+
+			result, err := CreateMcpResponse(t.Context(), test.objs, "local")
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, result)
+		})
+	}
+}
+
+func TestCreateMultiClusterMcpResponseWithMaxBytes(t *testing.T) {
+	SetMaxBytes(200)
+	t.Cleanup(func() { SetMaxBytes(DefaultMaxBytes) })
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]any{"name": "nginx", "namespace": "default"},
+		"status":     map[string]any{"phase": "Running"},
+	}}
+
+	result, err := CreateMultiClusterMcpResponse(t.Context(), []ClusterResources{
+		{Cluster: "local", Objects: []*unstructured.Unstructured{pod}},
+		{Cluster: "downstream", Error: assert.AnError},
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"llm":{"summary":"the response exceeded 200 bytes, only a summary of the 2 resources is shown. Get the resources individually, or use a label selector, a field selector or a limit to see their details","resources":[{"cluster":"local","kind":"Pod","name":"nginx","namespace":"default","status":"Running"},{"cluster":"downstream","error":"`+assert.AnError.Error()+`"}]},"uiContext":[{"namespace":"default","kind":"Pod","cluster":"local","name":"nginx","type":"pod"}]}`, result)
+}
+
+func TestKeyStatus(t *testing.T) {
+	tests := map[string]struct {
+		status   map[string]any
+		expected string
+	}{
+		"phase": {
+			status:   map[string]any{"phase": "Pending"},
+			expected: "Pending",
+		},
+		"replicas without ready replicas": {
+			status:   map[string]any{"replicas": int64(3)},
+			expected: "0/3 ready",
+		},
+		"ready condition": {
+			status:   map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": "False"}, map[string]any{"type": "Updated", "status": "True"}}},
+			expected: "Ready=False",
+		},
+		"other conditions": {
+			status:   map[string]any{"conditions": []any{map[string]any{"type": "Provisioned", "status": "True"}, map[string]any{"type": "Updated", "status": "Unknown"}}},
+			expected: "Updated=Unknown",
+		},
+		"no status": {
+			expected: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			item := map[string]any{}
+			if test.status != nil {
+				item["status"] = test.status
+			}
+
+			assert.Equal(t, test.expected, keyStatus(item))
+		})
+	}
+}
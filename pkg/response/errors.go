@@ -0,0 +1,104 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ToolError describes why a tool call failed, so the LLM can decide how to recover from it.
+type ToolError struct {
+	// Code is the HTTP status code of the failure.
+	Code int32 `json:"code"`
+	// Reason is a machine-readable description of the failure (e.g. "NotFound", "Forbidden").
+	Reason metav1.StatusReason `json:"reason"`
+	// Message is the human-readable description of the failure.
+	Message string `json:"message"`
+	// SuggestedTools lists the tools that may help the LLM recover from the failure.
+	SuggestedTools []string `json:"suggestedTools,omitempty"`
+	// Status is the original Kubernetes status returned by the API server, if any.
+	Status *metav1.Status `json:"status,omitempty"`
+}
+
+// MCPErrorResponse represents the error response returned by the MCP server when a tool fails.
+type MCPErrorResponse struct {
+	Error ToolError `json:"error"`
+}
+
+// suggestedToolsForReason maps Kubernetes status reasons to the tools that may help the LLM recover.
+var suggestedToolsForReason = map[metav1.StatusReason][]string{
+	metav1.StatusReasonNotFound:      {"listKubernetesResources"},
+	metav1.StatusReasonAlreadyExists: {"getKubernetesResource", "patchKubernetesResource"},
+	metav1.StatusReasonConflict:      {"getKubernetesResource"},
+	metav1.StatusReasonInvalid:       {"getKubernetesResource"},
+	metav1.StatusReasonBadRequest:    {"getKubernetesResource"},
+}
+
+// NewToolError translates an error returned by a tool into a ToolError. Kubernetes API errors keep
+// their original status, any other error is reported as an internal error.
+func NewToolError(err error) ToolError {
+	var apiStatus apierrors.APIStatus
+	if !errors.As(err, &apiStatus) {
+		return ToolError{
+			Code:    http.StatusInternalServerError,
+			Reason:  metav1.StatusReasonInternalError,
+			Message: err.Error(),
+		}
+	}
+
+	status := apiStatus.Status()
+	reason := status.Reason
+	if reason == "" {
+		reason = metav1.StatusReasonUnknown
+	}
+
+	return ToolError{
+		Code:           status.Code,
+		Reason:         reason,
+		Message:        err.Error(),
+		SuggestedTools: suggestedToolsForReason[reason],
+		Status:         &status,
+	}
+}
+
+// CreateMcpErrorResult constructs a CallToolResult flagged as an error, whose content is the JSON representation of the error.
+func CreateMcpErrorResult(err error) *mcp.CallToolResult {
+	text := err.Error()
+	if bytes, marshalErr := json.Marshal(MCPErrorResponse{Error: NewToolError(err)}); marshalErr == nil {
+		text = string(bytes)
+	}
+
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
+
+// WithStructuredErrors wraps a tool handler so errors returned by it are sent to the LLM as
+// structured error results instead of raw error messages. The parameters are checked with the rules of
+// their validate tags before the handler is called, see the validation package.
+func WithStructuredErrors[In, Out any](handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, toolReq *mcp.CallToolRequest, params In) (*mcp.CallToolResult, Out, error) {
+		if errs := validation.Validate(params); len(errs) > 0 {
+			var zero Out
+			tool := "the tool"
+			if toolReq != nil && toolReq.Params != nil {
+				tool = toolReq.Params.Name
+			}
+			return CreateMcpErrorResult(apierrors.NewBadRequest(validation.Error(tool, errs))), zero, nil
+		}
+		result, out, err := handler(ctx, toolReq, params)
+		if err != nil {
+			var zero Out
+			return CreateMcpErrorResult(err), zero, nil
+		}
+
+		return result, out, nil
+	}
+}
@@ -0,0 +1,111 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCreateMcpErrorResult(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected string
+	}{
+		"not found": {
+			err:      apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "rancher"),
+			expected: `{"error":{"code":404,"reason":"NotFound","message":"pods \"rancher\" not found","suggestedTools":["listKubernetesResources"],"status":{"metadata":{},"status":"Failure","message":"pods \"rancher\" not found","reason":"NotFound","details":{"name":"rancher","kind":"pods"},"code":404}}}`,
+		},
+		"wrapped already exists": {
+			err:      fmt.Errorf("failed to create resource rancher: %w", apierrors.NewAlreadyExists(schema.GroupResource{Resource: "pods"}, "rancher")),
+			expected: `{"error":{"code":409,"reason":"AlreadyExists","message":"failed to create resource rancher: pods \"rancher\" already exists","suggestedTools":["getKubernetesResource","patchKubernetesResource"],"status":{"metadata":{},"status":"Failure","message":"pods \"rancher\" already exists","reason":"AlreadyExists","details":{"name":"rancher","kind":"pods"},"code":409}}}`,
+		},
+		"forbidden": {
+			err:      apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "token", fmt.Errorf("access denied")),
+			expected: `{"error":{"code":403,"reason":"Forbidden","message":"secrets \"token\" is forbidden: access denied","status":{"metadata":{},"status":"Failure","message":"secrets \"token\" is forbidden: access denied","reason":"Forbidden","details":{"name":"token","kind":"secrets"},"code":403}}}`,
+		},
+		"non kubernetes error": {
+			err:      fmt.Errorf("unknown kind: foo"),
+			expected: `{"error":{"code":500,"reason":"InternalError","message":"unknown kind: foo"}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := CreateMcpErrorResult(test.err)
+
+			assert.True(t, result.IsError)
+			require.Len(t, result.Content, 1)
+			assert.JSONEq(t, test.expected, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestWithStructuredErrors(t *testing.T) {
+	successResult := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}
+
+	tests := map[string]struct {
+		handler         mcp.ToolHandlerFor[string, any]
+		expectedResult  *mcp.CallToolResult
+		expectedIsError bool
+	}{
+		"success is returned unchanged": {
+			handler: func(ctx context.Context, toolReq *mcp.CallToolRequest, params string) (*mcp.CallToolResult, any, error) {
+				return successResult, nil, nil
+			},
+			expectedResult: successResult,
+		},
+		"error is translated": {
+			handler: func(ctx context.Context, toolReq *mcp.CallToolRequest, params string) (*mcp.CallToolResult, any, error) {
+				return nil, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "rancher")
+			},
+			expectedIsError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, _, err := WithStructuredErrors(test.handler)(t.Context(), &mcp.CallToolRequest{}, "")
+
+			require.NoError(t, err)
+			if test.expectedResult != nil {
+				assert.Equal(t, test.expectedResult, result)
+			}
+			assert.Equal(t, test.expectedIsError, result.IsError)
+		})
+	}
+}
+
+func TestWithStructuredErrorsValidation(t *testing.T) {
+	type params struct {
+		Kind string `json:"kind" validate:"required,enum=Deployment|StatefulSet"`
+	}
+	called := false
+	handler := WithStructuredErrors(func(ctx context.Context, toolReq *mcp.CallToolRequest, params params) (*mcp.CallToolResult, any, error) {
+		called = true
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	toolReq := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "rolloutWorkload"}}
+
+	result, _, err := handler(t.Context(), toolReq, params{Kind: "CronJob"})
+
+	require.NoError(t, err)
+	assert.False(t, called, "the handler isn't called with invalid parameters")
+	assert.True(t, result.IsError)
+	var response MCPErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &response))
+	assert.Equal(t, metav1.StatusReasonBadRequest, response.Error.Reason)
+	assert.Equal(t, `invalid arguments of rolloutWorkload, fix them and call it again: kind: Unsupported value: "CronJob": supported values: "Deployment", "StatefulSet"`, response.Error.Message)
+
+	_, _, err = handler(t.Context(), toolReq, params{Kind: "deployment"})
+
+	require.NoError(t, err)
+	assert.True(t, called)
+}
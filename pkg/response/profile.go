@@ -0,0 +1,89 @@
+package response
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Profile controls which fields of the resources are kept in the responses sent to the LLM, so LLMs with smaller
+// context windows can be served by the same server.
+type Profile string
+
+const (
+	// ProfileFull keeps the whole resources.
+	ProfileFull Profile = "full"
+	// ProfileSummary keeps the kind, name, namespace, labels and spec of the resources, without their status.
+	ProfileSummary Profile = "summary"
+	// ProfileMinimal keeps the kind, name, namespace and key status of the resources.
+	ProfileMinimal Profile = "minimal"
+)
+
+// Profiles contains the names of the response profiles.
+var Profiles = []string{string(ProfileFull), string(ProfileSummary), string(ProfileMinimal)}
+
+// profile is the response profile of the tool calls that don't set one.
+var profile = ProfileFull
+
+// ParseProfile returns the response profile with the given name.
+func ParseProfile(name string) (Profile, error) {
+	if !slices.Contains(Profiles, name) {
+		return "", fmt.Errorf("invalid response profile %q, must be full, summary or minimal", name)
+	}
+
+	return Profile(name), nil
+}
+
+// SetProfile sets the response profile of the responses created by this package, when the context of the tool call
+// doesn't have one set with WithProfile.
+func SetProfile(p Profile) {
+	profile = p
+}
+
+type profileKey struct{}
+
+// WithProfile returns a context overriding the response profile set with SetProfile for a tool call.
+func WithProfile(ctx context.Context, p Profile) context.Context {
+	return context.WithValue(ctx, profileKey{}, p)
+}
+
+// profileFrom returns the response profile of the context, or the one set with SetProfile.
+func profileFrom(ctx context.Context) Profile {
+	if p, ok := ctx.Value(profileKey{}).(Profile); ok {
+		return p
+	}
+
+	return profile
+}
+
+// shape removes the fields of the item that aren't kept by the profile. The items without a kind, e.g. the
+// summaries and the logs added by the tools, are kept whole since they aren't resources.
+func shape(item map[string]any, p Profile) map[string]any {
+	if _, ok := item["kind"]; !ok {
+		return item
+	}
+
+	switch p {
+	case ProfileSummary:
+		shaped := map[string]any{}
+		for _, field := range []string{"cluster", "apiVersion", "kind", "spec"} {
+			if value, ok := item[field]; ok {
+				shaped[field] = value
+			}
+		}
+		if metadata, ok := item["metadata"].(map[string]any); ok {
+			shapedMetadata := map[string]any{}
+			for _, field := range []string{"name", "namespace", "labels", "ownerReferences"} {
+				if value, ok := metadata[field]; ok {
+					shapedMetadata[field] = value
+				}
+			}
+			shaped["metadata"] = shapedMetadata
+		}
+		return shaped
+	case ProfileMinimal:
+		return summarize(item)
+	default:
+		return item
+	}
+}
@@ -0,0 +1,102 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateMcpResponseWithProfile(t *testing.T) {
+	deployment := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"name":              "nginx",
+				"namespace":         "default",
+				"labels":            map[string]any{"app": "nginx"},
+				"uid":               "1234",
+				"creationTimestamp": "2025-01-01T00:00:00Z",
+			},
+			"spec":   map[string]any{"replicas": int64(2)},
+			"status": map[string]any{"replicas": int64(2), "readyReplicas": int64(1)},
+		}}
+	}
+	rolloutStatus := &unstructured.Unstructured{Object: map[string]any{"rolloutStatus": "progressing"}}
+	uiContext := `"uiContext":[{"namespace":"default","kind":"Deployment","cluster":"local","name":"nginx","type":"apps.deployment"}]`
+
+	tests := map[string]struct {
+		serverProfile Profile
+		callProfile   Profile
+		expected      string
+	}{
+		"full": {
+			serverProfile: ProfileFull,
+			expected: `{"llm":[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"nginx","namespace":"default","labels":{"app":"nginx"},"uid":"1234","creationTimestamp":"2025-01-01T00:00:00Z"},
+				"spec":{"replicas":2},"status":{"replicas":2,"readyReplicas":1}},{"rolloutStatus":"progressing"}],` + uiContext + `}`,
+		},
+		"summary": {
+			serverProfile: ProfileSummary,
+			expected: `{"llm":[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"nginx","namespace":"default","labels":{"app":"nginx"}},
+				"spec":{"replicas":2}},{"rolloutStatus":"progressing"}],` + uiContext + `}`,
+		},
+		"minimal": {
+			serverProfile: ProfileMinimal,
+			expected:      `{"llm":[{"kind":"Deployment","name":"nginx","namespace":"default","status":"1/2 ready"},{"rolloutStatus":"progressing"}],` + uiContext + `}`,
+		},
+		"profile of the tool call": {
+			serverProfile: ProfileMinimal,
+			callProfile:   ProfileFull,
+			expected: `{"llm":[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"nginx","namespace":"default","labels":{"app":"nginx"},"uid":"1234","creationTimestamp":"2025-01-01T00:00:00Z"},
+				"spec":{"replicas":2},"status":{"replicas":2,"readyReplicas":1}},{"rolloutStatus":"progressing"}],` + uiContext + `}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			SetProfile(test.serverProfile)
+			t.Cleanup(func() { SetProfile(ProfileFull) })
+			ctx := t.Context()
+			if test.callProfile != "" {
+				ctx = WithProfile(ctx, test.callProfile)
+			}
+
+			result, err := CreateMcpResponse(ctx, []*unstructured.Unstructured{deployment(), rolloutStatus}, "local")
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, result)
+		})
+	}
+}
+
+func TestCreateMultiClusterMcpResponseWithProfile(t *testing.T) {
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]any{"name": "web", "namespace": "default"},
+		"spec":       map[string]any{"nodeName": "node-1"},
+		"status":     map[string]any{"phase": "Running"},
+	}}
+
+	result, err := CreateMultiClusterMcpResponse(WithProfile(t.Context(), ProfileSummary), []ClusterResources{
+		{Cluster: "local", Objects: []*unstructured.Unstructured{pod}},
+		{Cluster: "downstream", Error: assert.AnError},
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"llm":[
+		{"cluster":"local","apiVersion":"v1","kind":"Pod","metadata":{"name":"web","namespace":"default"},"spec":{"nodeName":"node-1"}},
+		{"cluster":"downstream","error":"`+assert.AnError.Error()+`"}
+	],"uiContext":[{"namespace":"default","kind":"Pod","cluster":"local","name":"web","type":"pod"}]}`, result)
+}
+
+func TestParseProfile(t *testing.T) {
+	profile, err := ParseProfile("summary")
+	require.NoError(t, err)
+	assert.Equal(t, ProfileSummary, profile)
+
+	_, err = ParseProfile("compact")
+	assert.EqualError(t, err, `invalid response profile "compact", must be full, summary or minimal`)
+}
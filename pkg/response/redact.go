@@ -0,0 +1,179 @@
+package response
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sensitiveField is a field whose values are redacted from the resources of a kind. If the field is an object,
+// the values of its keys are redacted and the keys are kept.
+type sensitiveField struct {
+	// kind is the lowercase kind of the resources, or * for all kinds.
+	kind string
+	path []string
+	// base64 is true if the values are base64 encoded, their decoded size is reported.
+	base64 bool
+}
+
+// secretFields are the fields of the Secrets, and the credentials of the Rancher AuthConfigs of the authentication
+// providers, that are always redacted. The last-applied-configuration annotation of the Secrets applied with kubectl
+// holds their whole data.
+var secretFields = []sensitiveField{
+	{kind: "secret", path: []string{"data"}, base64: true},
+	{kind: "secret", path: []string{"stringData"}},
+	{kind: "secret", path: []string{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"}},
+	{kind: "authconfig", path: []string{"serviceAccountPassword"}},
+	{kind: "authconfig", path: []string{"clientSecret"}},
+	{kind: "authconfig", path: []string{"applicationSecret"}},
+	{kind: "authconfig", path: []string{"privateKey"}},
+	{kind: "authconfig", path: []string{"spKey"}},
+	{kind: "authconfig", path: []string{"oauthCredential"}},
+	{kind: "authconfig", path: []string{"serviceAccountCredential"}},
+	{kind: "authconfig", path: []string{"openLdapConfig", "serviceAccountPassword"}},
+}
+
+var (
+	// showSensitiveValues disables the redaction of the sensitive fields.
+	showSensitiveValues = false
+	// sensitiveFields contains the fields redacted in addition to the Secret data.
+	sensitiveFields []sensitiveField
+)
+
+// SetShowSensitiveValues sets whether the values of the Secrets and the other sensitive fields are returned to the
+// LLM. They are redacted by default, keeping only the keys and the size of the values.
+func SetShowSensitiveValues(show bool) {
+	showSensitiveValues = show
+}
+
+// SetSensitiveFields sets the fields redacted in addition to the data of the Secrets. Each field has the format
+// <kind>:<path>, where kind is the kind of the resources or * for all kinds and path is the dot separated path of
+// the field (e.g. configmap:data.password or *:spec.token).
+func SetSensitiveFields(fields []string) error {
+	parsed := make([]sensitiveField, 0, len(fields))
+	for _, field := range fields {
+		kind, path, ok := strings.Cut(field, ":")
+		if !ok || kind == "" || path == "" {
+			return fmt.Errorf("invalid sensitive field %q, must be <kind>:<path>", field)
+		}
+		parsed = append(parsed, sensitiveField{kind: strings.ToLower(kind), path: strings.Split(path, ".")})
+	}
+	sensitiveFields = parsed
+
+	return nil
+}
+
+// Redact replaces the values of the sensitive fields of the object with their size, unless they are shown with
+// SetShowSensitiveValues. The responses created by this package are always redacted.
+func Redact(obj *unstructured.Unstructured) {
+	if showSensitiveValues {
+		return
+	}
+
+	for _, field := range objectSensitiveFields(obj.GetKind()) {
+		value, found, err := unstructured.NestedFieldNoCopy(obj.Object, field.path...)
+		if err != nil || !found || value == nil {
+			continue
+		}
+		if values, ok := value.(map[string]any); ok {
+			for key, v := range values {
+				values[key] = redactedValue(v, field.base64)
+			}
+			continue
+		}
+		_ = unstructured.SetNestedField(obj.Object, redactedValue(value, field.base64), field.path...)
+	}
+}
+
+// RedactField returns the value of the field at the given path of a resource of the given kind, or its redacted
+// value if the field is sensitive or is inside a sensitive field. The sensitive fields below the field are redacted
+// in a copy of its value.
+func RedactField(kind string, path []string, value any) any {
+	if showSensitiveValues || value == nil {
+		return value
+	}
+
+	for _, field := range objectSensitiveFields(kind) {
+		if len(path) < len(field.path) {
+			if values, ok := value.(map[string]any); ok && slices.Equal(path, field.path[:len(path)]) {
+				value = redactNested(values, field.path[len(path):], field.base64)
+			}
+			continue
+		}
+		if !slices.Equal(path[:len(field.path)], field.path) {
+			continue
+		}
+		if values, ok := value.(map[string]any); ok && len(path) == len(field.path) {
+			redacted := make(map[string]any, len(values))
+			for key, v := range values {
+				redacted[key] = redactedValue(v, field.base64)
+			}
+			return redacted
+		}
+		return redactedValue(value, field.base64 && len(path) == len(field.path)+1)
+	}
+
+	return value
+}
+
+// redactNested returns a copy of the values with the sensitive field at the given path below them redacted. Only the
+// maps on the path are copied, the other values are shared.
+func redactNested(values map[string]any, path []string, base64Encoded bool) map[string]any {
+	value, found := values[path[0]]
+	if !found || value == nil {
+		return values
+	}
+	redacted := maps.Clone(values)
+	nested, isMap := value.(map[string]any)
+	switch {
+	case len(path) > 1 && isMap:
+		redacted[path[0]] = redactNested(nested, path[1:], base64Encoded)
+	case len(path) > 1:
+		return values
+	case isMap:
+		redactedValues := make(map[string]any, len(nested))
+		for key, v := range nested {
+			redactedValues[key] = redactedValue(v, base64Encoded)
+		}
+		redacted[path[0]] = redactedValues
+	default:
+		redacted[path[0]] = redactedValue(value, base64Encoded)
+	}
+
+	return redacted
+}
+
+func objectSensitiveFields(kind string) []sensitiveField {
+	kind = strings.ToLower(kind)
+	var fields []sensitiveField
+	for _, field := range slices.Concat(secretFields, sensitiveFields) {
+		if field.kind == "*" || field.kind == kind {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// redactedValue returns the placeholder of a redacted value with its size.
+func redactedValue(value any, base64Encoded bool) string {
+	size := 0
+	switch v := value.(type) {
+	case string:
+		size = len(v)
+		if decoded, err := base64.StdEncoding.DecodeString(v); base64Encoded && err == nil {
+			size = len(decoded)
+		}
+	default:
+		if bytes, err := json.Marshal(v); err == nil {
+			size = len(bytes)
+		}
+	}
+
+	return fmt.Sprintf("<redacted, %d bytes>", size)
+}
@@ -1,8 +1,8 @@
 package response
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"maps"
 	"strings"
 
 	"github.com/rancher/rancher-ai-mcp/pkg/converter"
@@ -29,67 +29,116 @@ type MCPResponse struct {
 	LLM any `json:"llm"`
 	// UIContext contains a list of resources so the UI can generate links to them
 	UIContext []UIContext `json:"uiContext,omitempty"`
+	// Continue is the token to get the next page of a paginated list
+	Continue string `json:"continue,omitempty"`
+}
+
+// ClusterResources holds the resources found in a cluster, or the error returned when querying it.
+type ClusterResources struct {
+	Cluster string
+	Objects []*unstructured.Unstructured
+	Error   error
 }
 
 // CreateMcpResponse constructs an MCPResponse object. It takes a slice of unstructured Kubernetes objects, namespace, kind, cluster,
-// and optional additional information strings. It marshals the response into a JSON string.
-func CreateMcpResponse(objs []*unstructured.Unstructured, cluster string) (string, error) {
+// and optional additional information strings. It marshals the response into a JSON string, keeping the fields of the
+// response profile of the context and summarizing the resources when the response exceeds the size limit set with SetMaxBytes.
+func CreateMcpResponse(ctx context.Context, objs []*unstructured.Unstructured, cluster string) (string, error) {
+	return CreatePaginatedMcpResponse(ctx, objs, cluster, "")
+}
+
+// CreatePaginatedMcpResponse constructs an MCPResponse object like CreateMcpResponse, including the continue token
+// needed to get the next page of resources. The token is omitted when empty.
+func CreatePaginatedMcpResponse(ctx context.Context, objs []*unstructured.Unstructured, cluster string, continueToken string) (string, error) {
+	p := profileFrom(ctx)
+	var items []map[string]any
 	var uiContext []UIContext
 	for _, obj := range objs {
-		unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
-		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
-
-		gvk := obj.GetObjectKind().GroupVersionKind()
-		lowerKind := strings.ToLower(gvk.Kind)
-		if lowerKind == "" {
-			continue
+		removeNoisyFields(obj)
+		Redact(obj)
+		items = append(items, shape(obj.Object, p))
+		if ctx, ok := newUIContext(obj, cluster); ok {
+			uiContext = append(uiContext, ctx)
 		}
+	}
 
-		// use prefixes to differentiate duplicate kinds from different API groups
-		// (e.g. cluster.x-k8s.io.cluster vs provisioning.cattle.io.cluster)
-		lookupKind := lowerKind
-		steveType := lowerKind
-		switch gvk.Group {
-		case converter.CAPIGroup:
-			lookupKind = converter.CAPIKindPrefix + lookupKind
-		case converter.ProvisioningGroup:
-			lookupKind = converter.ProvisioningKindPrefix + lookupKind
-		case converter.ManagementGroup:
-			lookupKind = converter.ManagementKindPrefix + lookupKind
-		case converter.MachineConfigGroup:
-			// machine configs are dynamically generated from node drivers
-			// using their name, so we can't maintain a mapping for all of them.
-			// fortunately, its highly unlikely there will be a conflict across groups
-			// so we just use the group directly.
-			steveType = gvk.Group + "." + lowerKind
-		}
+	return marshalResponse(MCPResponse{
+		UIContext: uiContext,
+		Continue:  continueToken,
+	}, items)
+}
 
-		if gvr, ok := converter.K8sKindsToGVRs[lookupKind]; ok && gvr.Group != "" {
-			steveType = gvr.Group + "." + lowerKind
+// CreateMultiClusterMcpResponse constructs an MCPResponse object merging the resources of several clusters.
+// Each resource sent to the LLM has a "cluster" field with the cluster it belongs to, and clusters that
+// couldn't be queried are reported with their error instead of failing the whole response.
+func CreateMultiClusterMcpResponse(ctx context.Context, results []ClusterResources) (string, error) {
+	p := profileFrom(ctx)
+	var llm []map[string]any
+	var uiContext []UIContext
+	for _, result := range results {
+		if result.Error != nil {
+			llm = append(llm, map[string]any{"cluster": result.Cluster, "error": result.Error.Error()})
+			continue
+		}
+		for _, obj := range result.Objects {
+			removeNoisyFields(obj)
+			Redact(obj)
+			item := maps.Clone(obj.Object)
+			item["cluster"] = result.Cluster
+			llm = append(llm, shape(item, p))
+			if ctx, ok := newUIContext(obj, result.Cluster); ok {
+				uiContext = append(uiContext, ctx)
+			}
 		}
-
-		uiContext = append(uiContext, UIContext{
-			Namespace: obj.GetNamespace(),
-			Kind:      obj.GetKind(),
-			Cluster:   cluster,
-			Name:      obj.GetName(),
-			Type:      steveType,
-		})
 	}
 
-	resp := MCPResponse{
+	return marshalResponse(MCPResponse{
 		UIContext: uiContext,
+	}, llm)
+}
+
+// removeNoisyFields removes the fields that are of no use to the LLM.
+func removeNoisyFields(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+}
+
+// newUIContext returns the UIContext of the object. It returns false for objects without a kind, which the UI can't link to.
+func newUIContext(obj *unstructured.Unstructured, cluster string) (UIContext, bool) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	lowerKind := strings.ToLower(gvk.Kind)
+	if lowerKind == "" {
+		return UIContext{}, false
 	}
-	if len(objs) > 0 {
-		resp.LLM = objs
-	} else {
-		resp.LLM = "no resources found"
+
+	// use prefixes to differentiate duplicate kinds from different API groups
+	// (e.g. cluster.x-k8s.io.cluster vs provisioning.cattle.io.cluster)
+	lookupKind := lowerKind
+	steveType := lowerKind
+	switch gvk.Group {
+	case converter.CAPIGroup:
+		lookupKind = converter.CAPIKindPrefix + lookupKind
+	case converter.ProvisioningGroup:
+		lookupKind = converter.ProvisioningKindPrefix + lookupKind
+	case converter.ManagementGroup:
+		lookupKind = converter.ManagementKindPrefix + lookupKind
+	case converter.MachineConfigGroup:
+		// machine configs are dynamically generated from node drivers
+		// using their name, so we can't maintain a mapping for all of them.
+		// fortunately, its highly unlikely there will be a conflict across groups
+		// so we just use the group directly.
+		steveType = gvk.Group + "." + lowerKind
 	}
 
-	bytes, err := json.Marshal(resp)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal response: %w", err)
+	if gvr, ok := converter.K8sKindsToGVRs[lookupKind]; ok && gvr.Group != "" {
+		steveType = gvr.Group + "." + lowerKind
 	}
 
-	return string(bytes), nil
+	return UIContext{
+		Namespace: obj.GetNamespace(),
+		Kind:      obj.GetKind(),
+		Cluster:   cluster,
+		Name:      obj.GetName(),
+		Type:      steveType,
+	}, true
 }
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -111,7 +112,7 @@ func TestCreateMCPResponse(t *testing.T) {
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			resp, err := CreateMcpResponse(test.objs, test.cluster)
+			resp, err := CreateMcpResponse(t.Context(), test.objs, test.cluster)
 			if test.expectError {
 				assert.Error(t, err)
 			} else {
@@ -121,3 +122,72 @@ func TestCreateMCPResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateMultiClusterMcpResponse(t *testing.T) {
+	pod := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+					"managedFields": []interface{}{
+						map[string]interface{}{"manager": "kubectl"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		results  []ClusterResources
+		expected string
+	}{
+		"pods in two clusters": {
+			results: []ClusterResources{
+				{Cluster: "local", Objects: []*unstructured.Unstructured{pod("pod-1")}},
+				{Cluster: "c-abc12", Objects: []*unstructured.Unstructured{pod("pod-2")}},
+			},
+			expected: `{"llm":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-1","namespace":"default"},"cluster":"local"},{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-2","namespace":"default"},"cluster":"c-abc12"}],"uiContext":[{"namespace":"default","kind":"Pod","cluster":"local","name":"pod-1","type":"pod"},{"namespace":"default","kind":"Pod","cluster":"c-abc12","name":"pod-2","type":"pod"}]}`,
+		},
+		"cluster with error": {
+			results: []ClusterResources{
+				{Cluster: "local", Objects: []*unstructured.Unstructured{pod("pod-1")}},
+				{Cluster: "c-abc12", Error: errors.New("cluster 'c-abc12' not found")},
+			},
+			expected: `{"llm":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-1","namespace":"default"},"cluster":"local"},{"cluster":"c-abc12","error":"cluster 'c-abc12' not found"}],"uiContext":[{"namespace":"default","kind":"Pod","cluster":"local","name":"pod-1","type":"pod"}]}`,
+		},
+		"no resources": {
+			results:  []ClusterResources{{Cluster: "local"}, {Cluster: "c-abc12"}},
+			expected: `{"llm":"no resources found"}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := CreateMultiClusterMcpResponse(t.Context(), test.results)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.expected, resp)
+		})
+	}
+}
+
+func TestCreatePaginatedMcpResponse(t *testing.T) {
+	objs := []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata": map[string]interface{}{
+					"name":      "test-pod",
+					"namespace": "default",
+				},
+			},
+		},
+	}
+
+	resp, err := CreatePaginatedMcpResponse(t.Context(), objs, "local", "next-page")
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"llm":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"test-pod","namespace":"default"}}],"uiContext":[{"namespace":"default","kind":"Pod","cluster":"local","name":"test-pod","type":"pod"}],"continue":"next-page"}`, resp)
+}
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// defaultResourceSet is the ResourceSet installed by the rancher-backup chart with the Rancher resources except
+// the Secrets, which can only be backed up encrypted.
+const defaultResourceSet = "rancher-resource-set-basic"
+
+type createBackupParams struct {
+	Name                       string         `json:"name,omitempty" jsonschema:"the name of the Backup. Defaults to a generated name"`
+	ResourceSetName            string         `json:"resourceSetName,omitempty" jsonschema:"the ResourceSet with the resources to back up. Defaults to rancher-resource-set-basic"`
+	EncryptionConfigSecretName string         `json:"encryptionConfigSecretName,omitempty" jsonschema:"the Secret with the EncryptionConfiguration used to encrypt the backup"`
+	StorageLocation            map[string]any `json:"storageLocation,omitempty" jsonschema:"the S3 storage of the backup. Defaults to the storage of the rancher-backup chart"`
+}
+
+// createBackup creates a one-time Backup, which the rancher-backup operator runs right away.
+func (t *Tools) createBackup(ctx context.Context, toolReq *mcp.CallToolRequest, params createBackupParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createBackup called")
+
+	metadata := map[string]any{"generateName": "backup-"}
+	if params.Name != "" {
+		metadata = map[string]any{"name": params.Name}
+	}
+	spec := map[string]any{"resourceSetName": defaultResourceSet}
+	if params.ResourceSetName != "" {
+		spec["resourceSetName"] = params.ResourceSetName
+	}
+	if params.EncryptionConfigSecretName != "" {
+		spec["encryptionConfigSecretName"] = params.EncryptionConfigSecretName
+	}
+	if len(params.StorageLocation) > 0 {
+		spec["storageLocation"] = params.StorageLocation
+	}
+	backup := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "resources.cattle.io/v1",
+		"kind":       "Backup",
+		"metadata":   metadata,
+		"spec":       spec,
+	}}
+
+	return t.create(ctx, toolReq, backup, "backup", "createBackup")
+}
+
+// create creates a resource of the given kind of the rancher-backup operator in the local cluster.
+func (t *Tools) create(ctx context.Context, toolReq *mcp.CallToolRequest, obj *unstructured.Unstructured, kind string, tool string) (*mcp.CallToolResult, any, error) {
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), "", localCluster, converter.K8sKindsToGVRs[kind])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", tool), zap.Error(err))
+		return nil, nil, err
+	}
+	created, err := resourceInterface.Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create "+kind, zap.String("tool", tool), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{created}, localCluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", tool), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
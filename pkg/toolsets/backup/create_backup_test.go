@@ -0,0 +1,63 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBackup(t *testing.T) {
+	tests := map[string]struct {
+		params         createBackupParams
+		expectedResult string
+	}{
+		"default backup": {
+			expectedResult: `{
+				"llm": [{
+					"apiVersion": "resources.cattle.io/v1",
+					"kind": "Backup",
+					"metadata": {"generateName": "backup-"},
+					"spec": {"resourceSetName": "rancher-resource-set-basic"}
+				}],
+				"uiContext": [{"cluster": "local", "kind": "Backup", "name": "", "namespace": "", "type": "resources.cattle.io.backup"}]
+			}`,
+		},
+		"encrypted backup to s3": {
+			params: createBackupParams{
+				Name:                       "before-upgrade",
+				ResourceSetName:            "rancher-resource-set-full",
+				EncryptionConfigSecretName: "encryptionconfig",
+				StorageLocation:            map[string]any{"s3": map[string]any{"bucketName": "backups", "credentialSecretName": "s3-creds"}},
+			},
+			expectedResult: `{
+				"llm": [{
+					"apiVersion": "resources.cattle.io/v1",
+					"kind": "Backup",
+					"metadata": {"name": "before-upgrade"},
+					"spec": {
+						"resourceSetName": "rancher-resource-set-full",
+						"encryptionConfigSecretName": "encryptionconfig",
+						"storageLocation": {"s3": {"bucketName": "backups", "credentialSecretName": "s3-creds"}}
+					}
+				}],
+				"uiContext": [{"cluster": "local", "kind": "Backup", "name": "before-upgrade", "namespace": "", "type": "resources.cattle.io.backup"}]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newFakeClient()
+			tools := Tools{client: c}
+
+			result, _, err := tools.createBackup(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			assert.Equal(t, "create", fakeDynClient.Actions()[0].GetVerb())
+		})
+	}
+}
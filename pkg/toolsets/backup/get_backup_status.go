@@ -0,0 +1,98 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	stateCompleted  = "Completed"
+	stateFailed     = "Failed"
+	stateInProgress = "InProgress"
+)
+
+type getBackupStatusParams struct {
+	Name string `json:"name" jsonschema:"the name of the Backup"`
+}
+
+// backupStatus is the response of getBackupStatus.
+type backupStatus struct {
+	Name string `json:"name"`
+	// State is Completed, Failed or InProgress. Recurring backups are Completed after each successful snapshot.
+	State           string `json:"state"`
+	Message         string `json:"message,omitempty"`
+	BackupType      string `json:"backupType,omitempty"`
+	Filename        string `json:"filename,omitempty"`
+	StorageLocation string `json:"storageLocation,omitempty"`
+	LastSnapshot    string `json:"lastSnapshot,omitempty"`
+	NextSnapshot    string `json:"nextSnapshot,omitempty"`
+}
+
+// getBackupStatus returns whether a Backup has completed, from its Ready condition.
+func (t *Tools) getBackupStatus(ctx context.Context, toolReq *mcp.CallToolRequest, params getBackupStatusParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getBackupStatus called")
+
+	backup, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: localCluster,
+		Kind:    "backup",
+		Name:    params.Name,
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get backup", zap.String("tool", "getBackupStatus"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	status := backupStatus{Name: backup.GetName()}
+	status.State, status.Message = backupState(backup)
+	status.BackupType, _, _ = unstructured.NestedString(backup.Object, "status", "backupType")
+	status.Filename, _, _ = unstructured.NestedString(backup.Object, "status", "filename")
+	status.StorageLocation, _, _ = unstructured.NestedString(backup.Object, "status", "storageLocation")
+	status.LastSnapshot, _, _ = unstructured.NestedString(backup.Object, "status", "lastSnapshotTs")
+	status.NextSnapshot, _, _ = unstructured.NestedString(backup.Object, "status", "nextSnapshotAt")
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(data)}},
+	}, nil, nil
+}
+
+// backupState returns the state of a Backup and the message of the error if it failed. The rancher-backup
+// operator sets the Ready condition to True when a backup is uploaded, and to False with the Error reason if it fails.
+// Backups whose spec changed since they were processed are reported as in progress.
+func backupState(backup *unstructured.Unstructured) (string, string) {
+	observedGeneration, _, _ := unstructured.NestedInt64(backup.Object, "status", "observedGeneration")
+	if observedGeneration != backup.GetGeneration() {
+		return stateInProgress, ""
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(backup.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		message, _ := condition["message"].(string)
+		switch {
+		case condition["status"] == "True":
+			return stateCompleted, message
+		case condition["status"] == "False" && condition["reason"] == "Error":
+			return stateFailed, message
+		default:
+			return stateInProgress, message
+		}
+	}
+
+	return stateInProgress, ""
+}
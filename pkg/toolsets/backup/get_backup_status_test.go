@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBackupStatus(t *testing.T) {
+	tests := map[string]struct {
+		status         map[string]any
+		expectedResult string
+		expectedError  string
+	}{
+		"completed": {
+			status: map[string]any{
+				"observedGeneration": int64(1),
+				"backupType":         "One-time",
+				"filename":           "nightly-2024-05-01T00-00-00Z.tar.gz",
+				"storageLocation":    "PVC",
+				"lastSnapshotTs":     "2024-05-01T00:00:00Z",
+				"conditions": []any{
+					map[string]any{"type": "Uploaded", "status": "True"},
+					map[string]any{"type": "Ready", "status": "True"},
+				},
+			},
+			expectedResult: `{"name": "nightly", "state": "Completed", "backupType": "One-time", "filename": "nightly-2024-05-01T00-00-00Z.tar.gz", "storageLocation": "PVC", "lastSnapshot": "2024-05-01T00:00:00Z"}`,
+		},
+		"failed": {
+			status: map[string]any{
+				"observedGeneration": int64(1),
+				"conditions": []any{
+					map[string]any{"type": "Ready", "status": "False", "reason": "Error", "message": "failed to upload backup: bucket not found"},
+				},
+			},
+			expectedResult: `{"name": "nightly", "state": "Failed", "message": "failed to upload backup: bucket not found"}`,
+		},
+		"in progress": {
+			status: map[string]any{
+				"observedGeneration": int64(1),
+				"conditions": []any{
+					map[string]any{"type": "Ready", "status": "Unknown"},
+				},
+			},
+			expectedResult: `{"name": "nightly", "state": "InProgress"}`,
+		},
+		"not processed yet": {
+			expectedResult: `{"name": "nightly", "state": "InProgress"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(fakeBackup("nightly", test.status))
+			tools := Tools{client: c}
+
+			result, _, err := tools.getBackupStatus(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), getBackupStatusParams{Name: "nightly"})
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestGetBackupStatusNotFound(t *testing.T) {
+	c, _ := newFakeClient()
+	tools := Tools{client: c}
+
+	_, _, err := tools.getBackupStatus(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), getBackupStatusParams{Name: "nightly"})
+
+	assert.ErrorContains(t, err, `backups.resources.cattle.io "nightly" not found`)
+}
@@ -0,0 +1,48 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+)
+
+// listBackups retrieves the Backups of the local cluster.
+func (t *Tools) listBackups(ctx context.Context, toolReq *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listBackups called")
+
+	return t.list(ctx, toolReq, "backup", "listBackups")
+}
+
+// listRestores retrieves the Restores of the local cluster.
+func (t *Tools) listRestores(ctx context.Context, toolReq *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listRestores called")
+
+	return t.list(ctx, toolReq, "restore", "listRestores")
+}
+
+func (t *Tools) list(ctx context.Context, toolReq *mcp.CallToolRequest, kind string, tool string) (*mcp.CallToolResult, any, error) {
+	resources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: localCluster,
+		Kind:    kind,
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list "+kind+"s", zap.String("tool", tool), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, localCluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", tool), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
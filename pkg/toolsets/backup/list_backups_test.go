@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestListBackups(t *testing.T) {
+	c, _ := newFakeClient(fakeBackup("nightly", map[string]any{"filename": "nightly-2024-05-01T00-00-00Z.tar.gz"}))
+	tools := Tools{client: c}
+
+	result, _, err := tools.listBackups(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), struct{}{})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"llm": [{
+			"apiVersion": "resources.cattle.io/v1",
+			"kind": "Backup",
+			"metadata": {"name": "nightly", "generation": 1},
+			"spec": {"resourceSetName": "rancher-resource-set-basic"},
+			"status": {"filename": "nightly-2024-05-01T00-00-00Z.tar.gz"}
+		}],
+		"uiContext": [{"cluster": "local", "kind": "Backup", "name": "nightly", "namespace": "", "type": "resources.cattle.io.backup"}]
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestListRestores(t *testing.T) {
+	c, _ := newFakeClient(&unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "resources.cattle.io/v1",
+		"kind":       "Restore",
+		"metadata":   map[string]any{"name": "restore-abc12"},
+		"spec":       map[string]any{"backupFilename": "nightly-2024-05-01T00-00-00Z.tar.gz", "prune": true},
+	}})
+	tools := Tools{client: c}
+
+	result, _, err := tools.listRestores(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), struct{}{})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"llm": [{
+			"apiVersion": "resources.cattle.io/v1",
+			"kind": "Restore",
+			"metadata": {"name": "restore-abc12"},
+			"spec": {"backupFilename": "nightly-2024-05-01T00-00-00Z.tar.gz", "prune": true}
+		}],
+		"uiContext": [{"cluster": "local", "kind": "Restore", "name": "restore-abc12", "namespace": "", "type": "resources.cattle.io.restore"}]
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type restoreBackupParams struct {
+	BackupFilename             string         `json:"backupFilename" jsonschema:"the backup file to restore"`
+	Prune                      *bool          `json:"prune,omitempty" jsonschema:"delete the resources that aren't in the backup. Defaults to true"`
+	EncryptionConfigSecretName string         `json:"encryptionConfigSecretName,omitempty" jsonschema:"the Secret with the EncryptionConfiguration the backup was encrypted with"`
+	StorageLocation            map[string]any `json:"storageLocation,omitempty" jsonschema:"the S3 storage of the backup file. Defaults to the storage of the rancher-backup chart"`
+}
+
+// restoreBackup creates a Restore of a backup file, which the rancher-backup operator runs right away.
+func (t *Tools) restoreBackup(ctx context.Context, toolReq *mcp.CallToolRequest, params restoreBackupParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("restoreBackup called")
+
+	prune := true
+	if params.Prune != nil {
+		prune = *params.Prune
+	}
+	spec := map[string]any{
+		"backupFilename": params.BackupFilename,
+		"prune":          prune,
+	}
+	if params.EncryptionConfigSecretName != "" {
+		spec["encryptionConfigSecretName"] = params.EncryptionConfigSecretName
+	}
+	if len(params.StorageLocation) > 0 {
+		spec["storageLocation"] = params.StorageLocation
+	}
+	restore := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "resources.cattle.io/v1",
+		"kind":       "Restore",
+		"metadata":   map[string]any{"generateName": "restore-"},
+		"spec":       spec,
+	}}
+
+	return t.create(ctx, toolReq, restore, "restore", "restoreBackup")
+}
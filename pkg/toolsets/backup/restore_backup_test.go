@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreBackup(t *testing.T) {
+	noPrune := false
+
+	tests := map[string]struct {
+		params         restoreBackupParams
+		expectedResult string
+	}{
+		"restore with prune by default": {
+			params: restoreBackupParams{BackupFilename: "nightly-2024-05-01T00-00-00Z.tar.gz"},
+			expectedResult: `{
+				"llm": [{
+					"apiVersion": "resources.cattle.io/v1",
+					"kind": "Restore",
+					"metadata": {"generateName": "restore-"},
+					"spec": {"backupFilename": "nightly-2024-05-01T00-00-00Z.tar.gz", "prune": true}
+				}],
+				"uiContext": [{"cluster": "local", "kind": "Restore", "name": "", "namespace": "", "type": "resources.cattle.io.restore"}]
+			}`,
+		},
+		"encrypted restore from s3 without prune": {
+			params: restoreBackupParams{
+				BackupFilename:             "nightly-2024-05-01T00-00-00Z.tar.gz.enc",
+				Prune:                      &noPrune,
+				EncryptionConfigSecretName: "encryptionconfig",
+				StorageLocation:            map[string]any{"s3": map[string]any{"bucketName": "backups"}},
+			},
+			expectedResult: `{
+				"llm": [{
+					"apiVersion": "resources.cattle.io/v1",
+					"kind": "Restore",
+					"metadata": {"generateName": "restore-"},
+					"spec": {
+						"backupFilename": "nightly-2024-05-01T00-00-00Z.tar.gz.enc",
+						"prune": false,
+						"encryptionConfigSecretName": "encryptionconfig",
+						"storageLocation": {"s3": {"bucketName": "backups"}}
+					}
+				}],
+				"uiContext": [{"cluster": "local", "kind": "Restore", "name": "", "namespace": "", "type": "resources.cattle.io.restore"}]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient()
+			tools := Tools{client: c}
+
+			result, _, err := tools.restoreBackup(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
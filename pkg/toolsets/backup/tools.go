@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+)
+
+const (
+	toolsSet    = "backup"
+	toolsSetAnn = "toolset"
+	urlHeader   = client.URLHeader
+	// localCluster is the cluster of the Rancher management plane, where the rancher-backup operator runs.
+	localCluster = "local"
+)
+
+// Tools contains all tools for the MCP server
+type Tools struct {
+	client *client.Client
+	// ReadOnly disables the tools that create backups or restore them.
+	ReadOnly bool
+}
+
+// NewTools creates and returns a new Tools instance.
+func NewTools(client *client.Client) *Tools {
+	return &Tools{
+		client: client,
+	}
+}
+
+// Register adds the tools of the backup toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	tools.ReadOnly = deps.ReadOnly
+	tools.AddTools(mcpServer)
+}
+
+// AddTools registers all Rancher backup and restore tools with the provided MCP server.
+// Each tool is configured with metadata identifying it as part of the backup toolset.
+func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listBackups",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the Backups of the Rancher management plane made by the rancher-backup operator, with their schedule, ResourceSet, storage location and status.
+		It requires the rancher-backup chart to be installed in the local cluster.`},
+		response.WithStructuredErrors(t.listBackups),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listRestores",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the Restores of the Rancher management plane made by the rancher-backup operator, with the backup file they restore and their status.
+		It requires the rancher-backup chart to be installed in the local cluster.`},
+		response.WithStructuredErrors(t.listRestores),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getBackupStatus",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns whether a Backup has completed or failed, the backup file it created and where it's stored. Use it to check a backup created with createBackup.
+		Parameters:
+		name (string): The name of the Backup.`},
+		response.WithStructuredErrors(t.getBackupStatus),
+	)
+
+	if t.ReadOnly {
+		return
+	}
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createBackup",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Creates an on-demand Backup of the Rancher management plane with the rancher-backup operator. Use getBackupStatus to check when it completes.
+		Parameters:
+		name (string, optional): The name of the Backup. Defaults to a generated name.
+		resourceSetName (string, optional): The ResourceSet with the resources to back up. Defaults to rancher-resource-set-basic, use rancher-resource-set-full to include the Secrets.
+		encryptionConfigSecretName (string, optional): The Secret in the cattle-resources-system namespace with the EncryptionConfiguration used to encrypt the backup. Required to back up the Secrets.
+		storageLocation (object, optional): The S3 storage of the backup, e.g. {"s3": {"bucketName": "backups", "endpoint": "s3.us-west-2.amazonaws.com", "region": "us-west-2", "folder": "rancher", "credentialSecretName": "s3-creds", "credentialSecretNamespace": "default"}}. Defaults to the storage configured in the rancher-backup chart.
+
+		Returns:
+		The created Backup.`},
+		response.WithStructuredErrors(t.createBackup),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "restoreBackup",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Restores the Rancher management plane from a backup file with the rancher-backup operator. It overwrites the Rancher resources and, with prune, deletes the ones that aren't in the backup.
+		Always ask for confirmation before restoring a backup.
+		Parameters:
+		backupFilename (string): The backup file to restore, as returned by getBackupStatus or listBackups (e.g. 'nightly-2f2b3c4d-2024-05-01T00-00-00Z.tar.gz').
+		prune (boolean, optional): Delete the resources of the ResourceSet that aren't in the backup. Defaults to true.
+		encryptionConfigSecretName (string, optional): The Secret in the cattle-resources-system namespace with the EncryptionConfiguration the backup was encrypted with.
+		storageLocation (object, optional): The S3 storage of the backup file, with the same format as in createBackup. Defaults to the storage configured in the rancher-backup chart.
+
+		Returns:
+		The created Restore. Use listRestores to check when it completes.`},
+		response.WithStructuredErrors(t.restoreBackup),
+	)
+}
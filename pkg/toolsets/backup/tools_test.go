@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const fakeToken = "fakeToken"
+
+func newFakeClient(objects ...runtime.Object) (*client.Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "resources.cattle.io", Version: "v1", Resource: "backups"}:  "BackupList",
+		{Group: "resources.cattle.io", Version: "v1", Resource: "restores"}: "RestoreList",
+	}, objects...)
+
+	c := client.NewClient(true)
+	c.DynClientCreator = func(inConfig *rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+
+	return c, fakeDynClient
+}
+
+func newCallToolRequest() *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+	}
+}
+
+func fakeBackup(name string, status map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "resources.cattle.io/v1",
+		"kind":       "Backup",
+		"metadata":   map[string]any{"name": name, "generation": int64(1)},
+		"spec":       map[string]any{"resourceSetName": "rancher-resource-set-basic"},
+		"status":     status,
+	}}
+}
+
+func TestAddTools(t *testing.T) {
+	tests := map[string]struct {
+		readOnly      bool
+		expectedTools []string
+	}{
+		"all tools": {
+			expectedTools: []string{"createBackup", "getBackupStatus", "listBackups", "listRestores", "restoreBackup"},
+		},
+		"read-only": {
+			readOnly:      true,
+			expectedTools: []string{"getBackupStatus", "listBackups", "listRestores"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := NewTools(client.NewClient(true))
+			tools.ReadOnly = test.readOnly
+			mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+			tools.AddTools(mcpServer)
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+			require.NoError(t, err)
+			defer ss.Close()
+			cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+			require.NoError(t, err)
+			defer cs.Close()
+
+			toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+
+			require.NoError(t, err)
+			var names []string
+			for _, tool := range toolsResult.Tools {
+				names = append(names, tool.Name)
+				assert.Equal(t, toolsSet, tool.Meta[toolsSetAnn])
+			}
+			assert.Equal(t, test.expectedTools, names)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package builtin registers the toolsets of the Rancher MCP server in the toolsets registry. It's imported by the
+// server for its side effects, before the toolsets of downstream builds.
+package builtin
+
+import (
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/backup"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/catalog"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/core"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/fleet"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/harvester"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/project"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/provisioning"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/rbac"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/security"
+)
+
+func init() {
+	toolsets.Register(toolsets.Toolset{Name: "core", Register: core.Register})
+	toolsets.Register(toolsets.Toolset{Name: "fleet", Register: fleet.Register})
+	toolsets.Register(toolsets.Toolset{Name: "provisioning", Register: provisioning.Register})
+	toolsets.Register(toolsets.Toolset{Name: "project", Register: project.Register})
+	toolsets.Register(toolsets.Toolset{Name: "rbac", Register: rbac.Register})
+	toolsets.Register(toolsets.Toolset{Name: "catalog", Register: catalog.Register})
+	toolsets.Register(toolsets.Toolset{Name: "backup", Register: backup.Register})
+	toolsets.Register(toolsets.Toolset{Name: "security", Register: security.Register})
+	toolsets.Register(toolsets.Toolset{Name: "harvester", Register: harvester.Register})
+}
@@ -0,0 +1,182 @@
+package builtin
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinToolsets(t *testing.T) {
+	assert.Equal(t, []string{"core", "fleet", "provisioning", "project", "rbac", "catalog", "backup", "security", "harvester"}, toolsets.Names())
+}
+
+func TestAddAllToolsEnabledToolsets(t *testing.T) {
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+	toolsets.AddAllTools(mcpServer, []string{"catalog", "fleet"}, toolsets.Deps{Client: client.NewClient(true)})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	defer cs.Close()
+
+	toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+	require.NoError(t, err)
+	sets := map[any]bool{}
+	for _, tool := range toolsResult.Tools {
+		sets[tool.Meta["toolset"]] = true
+	}
+	assert.Equal(t, map[any]bool{"catalog": true, "fleet": true}, sets)
+}
+
+func TestOptionalNamespaces(t *testing.T) {
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+	toolsets.AddAllTools(mcpServer, []string{"core"}, toolsets.Deps{Client: client.NewClient(true)})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	defer cs.Close()
+
+	toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+	require.NoError(t, err)
+	// the session sets the required arguments, an empty namespace means all the namespaces or a cluster-scoped resource
+	optional := []string{"getKubernetesResource", "listKubernetesResources", "deleteKubernetesResource", "createKubernetesResource", "applyKubernetesResource", "patchKubernetesResource"}
+	for _, tool := range toolsResult.Tools {
+		if !slices.Contains(optional, tool.Name) {
+			continue
+		}
+		schema, ok := tool.InputSchema.(map[string]any)
+		require.True(t, ok)
+		required, _ := schema["required"].([]any)
+		assert.NotContains(t, required, "namespace", "the namespace of %s should be optional", tool.Name)
+		assert.Contains(t, required, "cluster", "the cluster of %s should be required", tool.Name)
+	}
+}
+
+// writeTools are the tools changing resources, removed in read-only mode.
+var writeTools = []string{
+	"patchKubernetesResource",
+	"createKubernetesResource",
+	"applyKubernetesResource",
+	"bulkLabelResources",
+	"cloneNamespace",
+	"createConfigSnapshot",
+	"execInPod",
+	"runDebugContainer",
+	"deleteKubernetesResource",
+	"restartWorkload",
+	"scaleWorkload",
+	"updateAutoscalerReplicas",
+	"pauseRollout",
+	"resumeRollout",
+	"rollbackDeployment",
+	"triggerCronJob",
+	"suspendCronJob",
+	"resumeCronJob",
+	"createSilence",
+	"undoLastChange",
+	"createK3kCluster",
+	"createProvisionedCluster",
+	"updateMachineConfig",
+	"createClusterFromTemplate",
+	"createUpgradePlan",
+	"pauseUpgradePlan",
+	"resumeUpgradePlan",
+	"rotateClusterRegistrationToken",
+	"createProject",
+	"moveNamespaceToProject",
+	"createNamespace",
+	"deleteNamespace",
+	"clearNamespaceFinalizers",
+	"installChart",
+	"createBackup",
+	"restoreBackup",
+	"setNamespacePodSecurity",
+	"createHarvesterCluster",
+}
+
+// unconfirmedWriteTools are the write tools run without a confirmation token by default, since they don't delete
+// anything nor disrupt the running workloads, or they're checked with a dry run or a confirm argument first.
+var unconfirmedWriteTools = []string{
+	"patchKubernetesResource",
+	"createKubernetesResource",
+	"applyKubernetesResource",
+	"createConfigSnapshot",
+	"execInPod",
+	"runDebugContainer",
+	"restartWorkload",
+	"updateAutoscalerReplicas",
+	"pauseRollout",
+	"resumeRollout",
+	"triggerCronJob",
+	"suspendCronJob",
+	"resumeCronJob",
+	"createSilence",
+	"createK3kCluster",
+	"createProvisionedCluster",
+	"updateMachineConfig",
+	"createClusterFromTemplate",
+	"createUpgradePlan",
+	"pauseUpgradePlan",
+	"resumeUpgradePlan",
+	"createProject",
+	"moveNamespaceToProject",
+	"createNamespace",
+	"installChart",
+	"createBackup",
+	"createHarvesterCluster",
+}
+
+func TestWriteToolsConfirmation(t *testing.T) {
+	for _, tool := range writeTools {
+		assert.True(t, slices.Contains(middleware.DefaultConfirmationTools, tool) != slices.Contains(unconfirmedWriteTools, tool),
+			"write tool %s must either require a confirmation by default or be exempted in unconfirmedWriteTools", tool)
+	}
+	for _, tool := range middleware.DefaultConfirmationTools {
+		assert.Contains(t, writeTools, tool, "tools requiring a confirmation should be write tools")
+	}
+}
+
+func TestAddAllToolsReadOnly(t *testing.T) {
+
+	for _, readOnly := range []bool{false, true} {
+		mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+		toolsets.AddAllTools(mcpServer, nil, toolsets.Deps{Client: client.NewClient(true), ReadOnly: readOnly})
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+		require.NoError(t, err)
+		cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+		require.NoError(t, err)
+
+		toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+		require.NoError(t, err)
+		var names []string
+		for _, tool := range toolsResult.Tools {
+			names = append(names, tool.Name)
+		}
+		for _, tool := range writeTools {
+			if readOnly {
+				assert.NotContains(t, names, tool, "write tools should not be added in read-only mode")
+			} else {
+				assert.Contains(t, names, tool)
+			}
+		}
+		assert.Contains(t, names, "getKubernetesResource", "read tools should always be added")
+
+		cs.Close()
+		ss.Close()
+	}
+}
@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+)
+
+const (
+	clusterReposPath = "catalog.cattle.io.clusterrepos"
+
+	// annotations of the charts in the Rancher chart repositories
+	hiddenAnn      = "catalog.cattle.io/hidden"
+	autoInstallAnn = "catalog.cattle.io/auto-install"
+	namespaceAnn   = "catalog.cattle.io/namespace"
+	releaseNameAnn = "catalog.cattle.io/release-name"
+)
+
+// chartIndex is the index of a Helm chart repository, as returned by the index link of a ClusterRepo.
+type chartIndex struct {
+	// Entries contains the versions of each chart, sorted from the newest to the oldest.
+	Entries map[string][]chartVersion `json:"entries"`
+}
+
+// chartVersion is a version of a chart in a chartIndex.
+type chartVersion struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	AppVersion  string            `json:"appVersion,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Deprecated  bool              `json:"deprecated,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// getIndex returns the index of the charts of a ClusterRepo.
+func (t *Tools) getIndex(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, repo string) (*chartIndex, error) {
+	body, err := t.client.SteveRequest(ctx, client.SteveParams{
+		Cluster: cluster,
+		Method:  http.MethodGet,
+		Path:    clusterReposPath + "/" + repo,
+		Query:   url.Values{"link": {"index"}},
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the index of ClusterRepo %s: %w", repo, err)
+	}
+
+	var index chartIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse the index of ClusterRepo %s: %w", repo, err)
+	}
+
+	return &index, nil
+}
+
+// findChartVersion returns the given version of the chart, or its latest version if version is empty.
+func findChartVersion(index *chartIndex, repo string, chart string, version string) (*chartVersion, error) {
+	versions, ok := index.Entries[chart]
+	if !ok || len(versions) == 0 {
+		return nil, fmt.Errorf("chart %s not found in ClusterRepo %s", chart, repo)
+	}
+	if version == "" {
+		return &versions[0], nil
+	}
+	for i := range versions {
+		if versions[i].Version == version {
+			return &versions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("version %s of chart %s not found in ClusterRepo %s", version, chart, repo)
+}
@@ -0,0 +1,200 @@
+package catalog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const fakeToken = "fakeToken"
+
+// fakeIndex is the index of the rancher-charts ClusterRepo served by the fake Steve server.
+var fakeIndex = chartIndex{Entries: map[string][]chartVersion{
+	"rancher-monitoring": {
+		{
+			Name:        "rancher-monitoring",
+			Version:     "105.1.0",
+			AppVersion:  "0.76.1",
+			Description: "Collects several related Helm charts that provide monitoring",
+			Annotations: map[string]string{
+				autoInstallAnn: "rancher-monitoring-crd=match",
+				namespaceAnn:   "cattle-monitoring-system",
+				releaseNameAnn: "rancher-monitoring",
+			},
+		},
+		{
+			Name:       "rancher-monitoring",
+			Version:    "105.0.0",
+			AppVersion: "0.76.0",
+			Annotations: map[string]string{
+				autoInstallAnn: "rancher-monitoring-crd=match",
+				namespaceAnn:   "cattle-monitoring-system",
+				releaseNameAnn: "rancher-monitoring",
+			},
+		},
+	},
+	"rancher-monitoring-crd": {
+		{
+			Name:        "rancher-monitoring-crd",
+			Version:     "105.1.0",
+			Annotations: map[string]string{hiddenAnn: "true", namespaceAnn: "cattle-monitoring-system"},
+		},
+		{
+			Name:        "rancher-monitoring-crd",
+			Version:     "105.0.0",
+			Annotations: map[string]string{hiddenAnn: "true", namespaceAnn: "cattle-monitoring-system"},
+		},
+	},
+	"rancher-backup": {
+		{Name: "rancher-backup", Version: "106.0.0", Description: "Provides ability to back up and restore the Rancher application"},
+	},
+}}
+
+// fakeSteveRequest is a request received by the fake Steve server.
+type fakeSteveRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// newFakeSteveServer starts a TLS server emulating the links and actions of the rancher-charts ClusterRepo of the local
+// cluster, and records the requests it receives.
+func newFakeSteveServer(t *testing.T, info map[string]any) (*httptest.Server, *[]fakeSteveRequest) {
+	var requests []fakeSteveRequest
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, fakeSteveRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/k8s/clusters/local/v1/catalog.cattle.io.clusterrepos/rancher-charts" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"type":"error","status":404,"code":"NotFound"}`))
+			return
+		}
+
+		var res any
+		switch {
+		case r.URL.Query().Get("link") == "index":
+			res = fakeIndex
+		case r.URL.Query().Get("link") == "info":
+			res = info
+		case r.Method == http.MethodPost:
+			res = operation{OperationName: "helm-operation-abc12", OperationNamespace: "cattle-system"}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func fakeClusterRepo(name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "catalog.cattle.io/v1",
+		"kind":       "ClusterRepo",
+		"metadata": map[string]any{
+			"name": name,
+		},
+		"spec": map[string]any{
+			"gitRepo":   "https://git.rancher.io/charts",
+			"gitBranch": "release-v2.12",
+		},
+	}}
+}
+
+func fakeApp(namespace string, name string, values map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "catalog.cattle.io/v1",
+		"kind":       "App",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]any{
+			"values": values,
+		},
+	}}
+}
+
+func newFakeClient(objects ...runtime.Object) *client.Client {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "catalog.cattle.io", Version: "v1", Resource: "clusterrepos"}: "ClusterRepoList",
+		{Group: "catalog.cattle.io", Version: "v1", Resource: "apps"}:         "AppList",
+	}, objects...)
+
+	c := client.NewClient(true)
+	c.DynClientCreator = func(inConfig *rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+
+	return c
+}
+
+func newCallToolRequest(url string) *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {url}}},
+	}
+}
+
+func TestFindChartVersion(t *testing.T) {
+	tests := map[string]struct {
+		chart           string
+		version         string
+		expectedVersion string
+		expectedError   string
+	}{
+		"latest version": {
+			chart:           "rancher-monitoring",
+			expectedVersion: "105.1.0",
+		},
+		"given version": {
+			chart:           "rancher-monitoring",
+			version:         "105.0.0",
+			expectedVersion: "105.0.0",
+		},
+		"unknown version": {
+			chart:         "rancher-monitoring",
+			version:       "1.0.0",
+			expectedError: "version 1.0.0 of chart rancher-monitoring not found in ClusterRepo rancher-charts",
+		},
+		"unknown chart": {
+			chart:         "unknown",
+			expectedError: "chart unknown not found in ClusterRepo rancher-charts",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			chart, err := findChartVersion(&fakeIndex, "rancher-charts", test.chart, test.version)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.chart, chart.Name)
+			assert.Equal(t, test.expectedVersion, chart.Version)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+type chartValuesParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the ClusterRepo"`
+	Repo    string `json:"repo" jsonschema:"the ClusterRepo of the chart"`
+	Chart   string `json:"chart" jsonschema:"the name of the chart"`
+	Version string `json:"version,omitempty" jsonschema:"the version of the chart. Defaults to the latest version"`
+	Section string `json:"section,omitempty" jsonschema:"only return this top level key of the values"`
+}
+
+// chartInfo is the chart information returned by the info link of a ClusterRepo.
+type chartInfo struct {
+	Values    map[string]any `json:"values"`
+	Questions map[string]any `json:"questions,omitempty"`
+}
+
+// chartValues is the response of getChartValues.
+type chartValues struct {
+	Repo      string         `json:"repo"`
+	Chart     string         `json:"chart"`
+	Version   string         `json:"version"`
+	Section   string         `json:"section,omitempty"`
+	Values    any            `json:"values"`
+	Questions map[string]any `json:"questions,omitempty"`
+}
+
+// getChartValues returns the default values and the Rancher UI questions of a chart version.
+func (t *Tools) getChartValues(ctx context.Context, toolReq *mcp.CallToolRequest, params chartValuesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getChartValues called")
+
+	version := params.Version
+	if version == "" {
+		index, err := t.getIndex(ctx, toolReq, params.Cluster, params.Repo)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get chart index", zap.String("tool", "getChartValues"), zap.Error(err))
+			return nil, nil, err
+		}
+		latest, err := findChartVersion(index, params.Repo, params.Chart, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		version = latest.Version
+	}
+
+	body, err := t.client.SteveRequest(ctx, client.SteveParams{
+		Cluster: params.Cluster,
+		Method:  http.MethodGet,
+		Path:    clusterReposPath + "/" + params.Repo,
+		Query:   url.Values{"link": {"info"}, "chartName": {params.Chart}, "version": {version}},
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get chart info", zap.String("tool", "getChartValues"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get chart %s %s: %w", params.Chart, version, err)
+	}
+	var info chartInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		middleware.Logger(ctx).Error("failed to parse chart info", zap.String("tool", "getChartValues"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to parse chart %s %s: %w", params.Chart, version, err)
+	}
+
+	result := chartValues{
+		Repo:      params.Repo,
+		Chart:     params.Chart,
+		Version:   version,
+		Section:   params.Section,
+		Values:    info.Values,
+		Questions: info.Questions,
+	}
+	if params.Section != "" {
+		section, ok := info.Values[params.Section]
+		if !ok {
+			return nil, nil, fmt.Errorf("section %s not found in the values of chart %s %s", params.Section, params.Chart, version)
+		}
+		result.Values = section
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getChartValues"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
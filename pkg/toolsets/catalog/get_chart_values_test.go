@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetChartValues(t *testing.T) {
+	info := map[string]any{
+		"values": map[string]any{
+			"prometheus": map[string]any{"retention": "10d"},
+			"grafana":    map[string]any{"enabled": true},
+		},
+		"questions": map[string]any{
+			"questions": []any{map[string]any{"variable": "prometheus.retention", "type": "string", "default": "10d"}},
+		},
+	}
+
+	tests := map[string]struct {
+		params          chartValuesParams
+		expectedResult  string
+		expectedError   string
+		expectedQueries []string
+	}{
+		"latest version": {
+			params: chartValuesParams{Cluster: "local", Repo: "rancher-charts", Chart: "rancher-monitoring"},
+			expectedResult: `{
+				"repo": "rancher-charts",
+				"chart": "rancher-monitoring",
+				"version": "105.1.0",
+				"values": {"grafana": {"enabled": true}, "prometheus": {"retention": "10d"}},
+				"questions": {"questions": [{"default": "10d", "type": "string", "variable": "prometheus.retention"}]}
+			}`,
+			expectedQueries: []string{"link=index", "chartName=rancher-monitoring&link=info&version=105.1.0"},
+		},
+		"section of a given version": {
+			params: chartValuesParams{Cluster: "local", Repo: "rancher-charts", Chart: "rancher-monitoring", Version: "105.0.0", Section: "prometheus"},
+			expectedResult: `{
+				"repo": "rancher-charts",
+				"chart": "rancher-monitoring",
+				"version": "105.0.0",
+				"section": "prometheus",
+				"values": {"retention": "10d"},
+				"questions": {"questions": [{"default": "10d", "type": "string", "variable": "prometheus.retention"}]}
+			}`,
+			expectedQueries: []string{"chartName=rancher-monitoring&link=info&version=105.0.0"},
+		},
+		"unknown section": {
+			params:          chartValuesParams{Cluster: "local", Repo: "rancher-charts", Chart: "rancher-monitoring", Version: "105.0.0", Section: "alertmanager"},
+			expectedError:   "section alertmanager not found in the values of chart rancher-monitoring 105.0.0",
+			expectedQueries: []string{"chartName=rancher-monitoring&link=info&version=105.0.0"},
+		},
+		"unknown chart": {
+			params:          chartValuesParams{Cluster: "local", Repo: "rancher-charts", Chart: "unknown"},
+			expectedError:   "chart unknown not found in ClusterRepo rancher-charts",
+			expectedQueries: []string{"link=index"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, requests := newFakeSteveServer(t, info)
+			tools := Tools{client: newFakeClient()}
+
+			result, _, err := tools.getChartValues(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(server.URL), test.params)
+
+			var queries []string
+			for _, req := range *requests {
+				queries = append(queries, req.Query)
+			}
+			assert.Equal(t, test.expectedQueries, queries)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
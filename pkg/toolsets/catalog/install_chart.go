@@ -0,0 +1,219 @@
+package catalog
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// helmOperationTimeout is the timeout of the Helm commands run by Rancher to install or upgrade charts.
+const helmOperationTimeout = "600s"
+
+type installChartParams struct {
+	Cluster     string         `json:"cluster" jsonschema:"the cluster where the chart is installed"`
+	Repo        string         `json:"repo" jsonschema:"the ClusterRepo of the chart"`
+	Chart       string         `json:"chart" jsonschema:"the name of the chart"`
+	Version     string         `json:"version,omitempty" jsonschema:"the version of the chart. Defaults to the latest version"`
+	Namespace   string         `json:"namespace,omitempty" jsonschema:"the namespace of the release. Defaults to the namespace recommended by the chart"`
+	ReleaseName string         `json:"releaseName,omitempty" jsonschema:"the name of the release. Defaults to the name recommended by the chart"`
+	Values      map[string]any `json:"values,omitempty" jsonschema:"values overriding the chart defaults"`
+}
+
+// chartAction is the body of the install and upgrade actions of a ClusterRepo.
+type chartAction struct {
+	Namespace string      `json:"namespace"`
+	Wait      bool        `json:"wait"`
+	Timeout   string      `json:"timeout"`
+	Charts    []chartItem `json:"charts"`
+}
+
+// chartItem is a chart installed or upgraded by a chartAction.
+type chartItem struct {
+	ChartName   string            `json:"chartName"`
+	Version     string            `json:"version"`
+	ReleaseName string            `json:"releaseName"`
+	Values      map[string]any    `json:"values,omitempty"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// operation is the response of the install and upgrade actions, identifying the Rancher operation running the Helm command.
+type operation struct {
+	OperationName      string `json:"operationName"`
+	OperationNamespace string `json:"operationNamespace"`
+}
+
+// installChartResult is the response of installChart.
+type installChartResult struct {
+	Action      string   `json:"action"`
+	Chart       string   `json:"chart"`
+	Version     string   `json:"version"`
+	Namespace   string   `json:"namespace"`
+	ReleaseName string   `json:"releaseName"`
+	Charts      []string `json:"charts"`
+	operation
+}
+
+// installChart installs a chart version from a ClusterRepo, or upgrades the release if it already exists. The charts
+// listed in the auto-install annotation, such as CRD charts, are installed or upgraded before the chart.
+func (t *Tools) installChart(ctx context.Context, toolReq *mcp.CallToolRequest, params installChartParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("installChart called")
+
+	index, err := t.getIndex(ctx, toolReq, params.Cluster, params.Repo)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get chart index", zap.String("tool", "installChart"), zap.Error(err))
+		return nil, nil, err
+	}
+	chart, err := findChartVersion(index, params.Repo, params.Chart, params.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespace := cmp.Or(params.Namespace, chart.Annotations[namespaceAnn])
+	if namespace == "" {
+		return nil, nil, fmt.Errorf("chart %s doesn't recommend a namespace, the namespace must be set", params.Chart)
+	}
+	releaseName := cmp.Or(params.ReleaseName, chart.Annotations[releaseNameAnn], chart.Name)
+
+	app, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      "app",
+		Namespace: namespace,
+		Name:      releaseName,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		middleware.Logger(ctx).Error("failed to get app", zap.String("tool", "installChart"), zap.Error(err))
+		return nil, nil, err
+	}
+	action, values := "install", params.Values
+	if err == nil {
+		action = "upgrade"
+		currentValues, _, _ := unstructured.NestedMap(app.Object, "spec", "values")
+		values = mergeValues(currentValues, params.Values)
+	}
+
+	annotations := map[string]string{
+		"catalog.cattle.io/ui-source-repo-type": "cluster",
+		"catalog.cattle.io/ui-source-repo":      params.Repo,
+	}
+	body := chartAction{
+		Namespace: namespace,
+		Wait:      true,
+		Timeout:   helmOperationTimeout,
+	}
+	dependencies, err := autoInstallCharts(index, params.Repo, chart)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, dependency := range dependencies {
+		body.Charts = append(body.Charts, chartItem{
+			ChartName:   dependency.Name,
+			Version:     dependency.Version,
+			ReleaseName: cmp.Or(dependency.Annotations[releaseNameAnn], dependency.Name),
+			Annotations: annotations,
+		})
+	}
+	body.Charts = append(body.Charts, chartItem{
+		ChartName:   chart.Name,
+		Version:     chart.Version,
+		ReleaseName: releaseName,
+		Values:      values,
+		Annotations: annotations,
+	})
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal %s action: %w", action, err)
+	}
+	resBody, err := t.client.SteveRequest(ctx, client.SteveParams{
+		Cluster: params.Cluster,
+		Method:  http.MethodPost,
+		Path:    clusterReposPath + "/" + params.Repo,
+		Query:   url.Values{"action": {action}},
+		Body:    bodyBytes,
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to install chart", zap.String("tool", "installChart"), zap.String("action", action), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to %s chart %s %s: %w", action, chart.Name, chart.Version, err)
+	}
+
+	result := installChartResult{
+		Action:      action,
+		Chart:       chart.Name,
+		Version:     chart.Version,
+		Namespace:   namespace,
+		ReleaseName: releaseName,
+	}
+	for _, item := range body.Charts {
+		result.Charts = append(result.Charts, item.ChartName)
+	}
+	if err := json.Unmarshal(resBody, &result.operation); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s response: %w", action, err)
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "installChart"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// autoInstallCharts returns the charts listed in the auto-install annotation of the chart (e.g. rancher-monitoring-crd=match),
+// where "match" means the same version as the chart.
+func autoInstallCharts(index *chartIndex, repo string, chart *chartVersion) ([]*chartVersion, error) {
+	var charts []*chartVersion
+	for dependency := range strings.SplitSeq(chart.Annotations[autoInstallAnn], ",") {
+		name, version, _ := strings.Cut(strings.TrimSpace(dependency), "=")
+		if name == "" {
+			continue
+		}
+		if version == "match" {
+			version = chart.Version
+		}
+		dependencyChart, err := findChartVersion(index, repo, name, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find chart %s required by %s: %w", name, chart.Name, err)
+		}
+		charts = append(charts, dependencyChart)
+	}
+
+	return charts, nil
+}
+
+// mergeValues returns the values merged with the overrides. Nested maps are merged recursively, other values are replaced.
+func mergeValues(values map[string]any, overrides map[string]any) map[string]any {
+	merged := maps.Clone(values)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	for key, override := range overrides {
+		current, currentIsMap := merged[key].(map[string]any)
+		overrideMap, overrideIsMap := override.(map[string]any)
+		if currentIsMap && overrideIsMap {
+			merged[key] = mergeValues(current, overrideMap)
+			continue
+		}
+		merged[key] = override
+	}
+
+	return merged
+}
@@ -0,0 +1,167 @@
+package catalog
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestInstallChart(t *testing.T) {
+	tests := map[string]struct {
+		objects        []runtime.Object
+		params         installChartParams
+		expectedQuery  string
+		expectedBody   string
+		expectedResult string
+		expectedError  string
+	}{
+		"install with the CRD chart": {
+			params: installChartParams{
+				Cluster: "local",
+				Repo:    "rancher-charts",
+				Chart:   "rancher-monitoring",
+				Values:  map[string]any{"prometheus": map[string]any{"retention": "30d"}},
+			},
+			expectedQuery: "action=install",
+			expectedBody: `{
+				"namespace": "cattle-monitoring-system",
+				"wait": true,
+				"timeout": "600s",
+				"charts": [
+					{"chartName": "rancher-monitoring-crd", "version": "105.1.0", "releaseName": "rancher-monitoring-crd", "annotations": {"catalog.cattle.io/ui-source-repo": "rancher-charts", "catalog.cattle.io/ui-source-repo-type": "cluster"}},
+					{"chartName": "rancher-monitoring", "version": "105.1.0", "releaseName": "rancher-monitoring", "values": {"prometheus": {"retention": "30d"}}, "annotations": {"catalog.cattle.io/ui-source-repo": "rancher-charts", "catalog.cattle.io/ui-source-repo-type": "cluster"}}
+				]
+			}`,
+			expectedResult: `{
+				"action": "install",
+				"chart": "rancher-monitoring",
+				"version": "105.1.0",
+				"namespace": "cattle-monitoring-system",
+				"releaseName": "rancher-monitoring",
+				"charts": ["rancher-monitoring-crd", "rancher-monitoring"],
+				"operationName": "helm-operation-abc12",
+				"operationNamespace": "cattle-system"
+			}`,
+		},
+		"upgrade merges the values of the release": {
+			objects: []runtime.Object{
+				fakeApp("cattle-monitoring-system", "rancher-monitoring", map[string]any{
+					"prometheus": map[string]any{"retention": "10d", "replicas": int64(2)},
+					"grafana":    map[string]any{"enabled": false},
+				}),
+			},
+			params: installChartParams{
+				Cluster: "local",
+				Repo:    "rancher-charts",
+				Chart:   "rancher-monitoring",
+				Version: "105.0.0",
+				Values:  map[string]any{"prometheus": map[string]any{"retention": "30d"}},
+			},
+			expectedQuery: "action=upgrade",
+			expectedBody: `{
+				"namespace": "cattle-monitoring-system",
+				"wait": true,
+				"timeout": "600s",
+				"charts": [
+					{"chartName": "rancher-monitoring-crd", "version": "105.0.0", "releaseName": "rancher-monitoring-crd", "annotations": {"catalog.cattle.io/ui-source-repo": "rancher-charts", "catalog.cattle.io/ui-source-repo-type": "cluster"}},
+					{"chartName": "rancher-monitoring", "version": "105.0.0", "releaseName": "rancher-monitoring", "values": {"grafana": {"enabled": false}, "prometheus": {"replicas": 2, "retention": "30d"}}, "annotations": {"catalog.cattle.io/ui-source-repo": "rancher-charts", "catalog.cattle.io/ui-source-repo-type": "cluster"}}
+				]
+			}`,
+			expectedResult: `{
+				"action": "upgrade",
+				"chart": "rancher-monitoring",
+				"version": "105.0.0",
+				"namespace": "cattle-monitoring-system",
+				"releaseName": "rancher-monitoring",
+				"charts": ["rancher-monitoring-crd", "rancher-monitoring"],
+				"operationName": "helm-operation-abc12",
+				"operationNamespace": "cattle-system"
+			}`,
+		},
+		"custom namespace and release name": {
+			params: installChartParams{
+				Cluster:     "local",
+				Repo:        "rancher-charts",
+				Chart:       "rancher-backup",
+				Namespace:   "backup",
+				ReleaseName: "backup",
+			},
+			expectedQuery: "action=install",
+			expectedBody: `{
+				"namespace": "backup",
+				"wait": true,
+				"timeout": "600s",
+				"charts": [
+					{"chartName": "rancher-backup", "version": "106.0.0", "releaseName": "backup", "annotations": {"catalog.cattle.io/ui-source-repo": "rancher-charts", "catalog.cattle.io/ui-source-repo-type": "cluster"}}
+				]
+			}`,
+			expectedResult: `{
+				"action": "install",
+				"chart": "rancher-backup",
+				"version": "106.0.0",
+				"namespace": "backup",
+				"releaseName": "backup",
+				"charts": ["rancher-backup"],
+				"operationName": "helm-operation-abc12",
+				"operationNamespace": "cattle-system"
+			}`,
+		},
+		"missing namespace": {
+			params:        installChartParams{Cluster: "local", Repo: "rancher-charts", Chart: "rancher-backup"},
+			expectedError: "chart rancher-backup doesn't recommend a namespace, the namespace must be set",
+		},
+		"unknown version": {
+			params:        installChartParams{Cluster: "local", Repo: "rancher-charts", Chart: "rancher-backup", Version: "1.0.0"},
+			expectedError: "version 1.0.0 of chart rancher-backup not found in ClusterRepo rancher-charts",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, requests := newFakeSteveServer(t, nil)
+			tools := Tools{client: newFakeClient(test.objects...)}
+
+			result, _, err := tools.installChart(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(server.URL), test.params)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				for _, req := range *requests {
+					assert.NotEqual(t, http.MethodPost, req.Method, "no chart should be installed")
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, *requests, 2)
+			action := (*requests)[1]
+			assert.Equal(t, http.MethodPost, action.Method)
+			assert.Equal(t, test.expectedQuery, action.Query)
+			assert.JSONEq(t, test.expectedBody, action.Body)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	values := map[string]any{
+		"image":   map[string]any{"repository": "rancher/app", "tag": "v1"},
+		"ingress": map[string]any{"enabled": true},
+	}
+
+	merged := mergeValues(values, map[string]any{
+		"image":    map[string]any{"tag": "v2"},
+		"ingress":  false,
+		"replicas": 3,
+	})
+
+	assert.Equal(t, map[string]any{
+		"image":    map[string]any{"repository": "rancher/app", "tag": "v2"},
+		"ingress":  false,
+		"replicas": 3,
+	}, merged)
+	assert.Equal(t, "v1", values["image"].(map[string]any)["tag"], "the values should not be modified")
+}
@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+// maxChartVersions is the maximum number of versions listed for each chart.
+const maxChartVersions = 10
+
+type listChartsParams struct {
+	Cluster       string `json:"cluster" jsonschema:"the cluster of the ClusterRepos"`
+	Repo          string `json:"repo,omitempty" jsonschema:"only list the charts of this ClusterRepo"`
+	Name          string `json:"name,omitempty" jsonschema:"only list the charts whose name contains this text"`
+	IncludeHidden bool   `json:"includeHidden,omitempty" jsonschema:"include the charts hidden in the Rancher UI"`
+}
+
+// chartSummary describes a chart available in a ClusterRepo.
+type chartSummary struct {
+	Repo          string `json:"repo"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	LatestVersion string `json:"latestVersion"`
+	AppVersion    string `json:"appVersion,omitempty"`
+	Deprecated    bool   `json:"deprecated,omitempty"`
+	// Versions contains the most recent versions of the chart, newest first.
+	Versions []string `json:"versions"`
+}
+
+// listChartsResult contains the charts found and the ClusterRepos whose index couldn't be read.
+type listChartsResult struct {
+	Charts []chartSummary    `json:"charts"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// listCharts lists the charts of one or all the ClusterRepos of a cluster.
+func (t *Tools) listCharts(ctx context.Context, toolReq *mcp.CallToolRequest, params listChartsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listCharts called")
+
+	repos := []string{params.Repo}
+	if params.Repo == "" {
+		clusterRepos, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster: params.Cluster,
+			Kind:    "clusterrepo",
+			URL:     toolReq.Extra.Header.Get(urlHeader),
+			Token:   middleware.Token(ctx),
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list clusterrepos", zap.String("tool", "listCharts"), zap.Error(err))
+			return nil, nil, err
+		}
+		repos = nil
+		for _, clusterRepo := range clusterRepos {
+			repos = append(repos, clusterRepo.GetName())
+		}
+	}
+
+	result := listChartsResult{Charts: []chartSummary{}}
+	for _, repo := range repos {
+		index, err := t.getIndex(ctx, toolReq, params.Cluster, repo)
+		if err != nil {
+			// a single ClusterRepo was requested, so there is nothing else to return
+			if params.Repo != "" {
+				middleware.Logger(ctx).Error("failed to get chart index", zap.String("tool", "listCharts"), zap.Error(err))
+				return nil, nil, err
+			}
+			if result.Errors == nil {
+				result.Errors = map[string]string{}
+			}
+			result.Errors[repo] = err.Error()
+			continue
+		}
+
+		for name, versions := range index.Entries {
+			if len(versions) == 0 || !strings.Contains(name, strings.ToLower(params.Name)) {
+				continue
+			}
+			latest := versions[0]
+			if !params.IncludeHidden && latest.Annotations[hiddenAnn] == "true" {
+				continue
+			}
+			summary := chartSummary{
+				Repo:          repo,
+				Name:          name,
+				Description:   latest.Description,
+				LatestVersion: latest.Version,
+				AppVersion:    latest.AppVersion,
+				Deprecated:    latest.Deprecated,
+			}
+			for _, version := range versions[:min(len(versions), maxChartVersions)] {
+				summary.Versions = append(summary.Versions, version.Version)
+			}
+			result.Charts = append(result.Charts, summary)
+		}
+	}
+	slices.SortFunc(result.Charts, func(a, b chartSummary) int {
+		return cmp.Or(strings.Compare(a.Repo, b.Repo), strings.Compare(a.Name, b.Name))
+	})
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listCharts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
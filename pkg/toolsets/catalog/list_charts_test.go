@@ -0,0 +1,64 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCharts(t *testing.T) {
+	tests := map[string]struct {
+		params         listChartsParams
+		expectedResult string
+		expectedError  string
+	}{
+		"charts of a repo": {
+			params: listChartsParams{Cluster: "local", Repo: "rancher-charts"},
+			expectedResult: `{"charts": [
+				{"repo": "rancher-charts", "name": "rancher-backup", "description": "Provides ability to back up and restore the Rancher application", "latestVersion": "106.0.0", "versions": ["106.0.0"]},
+				{"repo": "rancher-charts", "name": "rancher-monitoring", "description": "Collects several related Helm charts that provide monitoring", "latestVersion": "105.1.0", "appVersion": "0.76.1", "versions": ["105.1.0", "105.0.0"]}
+			]}`,
+		},
+		"name filter with hidden charts": {
+			params: listChartsParams{Cluster: "local", Repo: "rancher-charts", Name: "Monitoring", IncludeHidden: true},
+			expectedResult: `{"charts": [
+				{"repo": "rancher-charts", "name": "rancher-monitoring", "description": "Collects several related Helm charts that provide monitoring", "latestVersion": "105.1.0", "appVersion": "0.76.1", "versions": ["105.1.0", "105.0.0"]},
+				{"repo": "rancher-charts", "name": "rancher-monitoring-crd", "latestVersion": "105.1.0", "versions": ["105.1.0", "105.0.0"]}
+			]}`,
+		},
+		"all repos": {
+			params: listChartsParams{Cluster: "local", Name: "backup"},
+			expectedResult: `{
+				"charts": [
+					{"repo": "rancher-charts", "name": "rancher-backup", "description": "Provides ability to back up and restore the Rancher application", "latestVersion": "106.0.0", "versions": ["106.0.0"]}
+				],
+				"errors": {
+					"rancher-partner-charts": "failed to get the index of ClusterRepo rancher-partner-charts: GET catalog.cattle.io.clusterrepos/rancher-partner-charts failed with status 404 Not Found: {\"type\":\"error\",\"status\":404,\"code\":\"NotFound\"}"
+				}
+			}`,
+		},
+		"unknown repo": {
+			params:        listChartsParams{Cluster: "local", Repo: "unknown"},
+			expectedError: "failed to get the index of ClusterRepo unknown: GET catalog.cattle.io.clusterrepos/unknown failed with status 404 Not Found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newFakeSteveServer(t, nil)
+			tools := Tools{client: newFakeClient(fakeClusterRepo("rancher-charts"), fakeClusterRepo("rancher-partner-charts"))}
+
+			result, _, err := tools.listCharts(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(server.URL), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
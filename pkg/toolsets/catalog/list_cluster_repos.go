@@ -0,0 +1,42 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+)
+
+// clusterParams specifies the cluster of the catalog.
+type clusterParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the ClusterRepos"`
+}
+
+// listClusterRepos retrieves the ClusterRepos of a cluster.
+func (t *Tools) listClusterRepos(ctx context.Context, toolReq *mcp.CallToolRequest, params clusterParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listClusterRepos called")
+
+	repos, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: params.Cluster,
+		Kind:    "clusterrepo",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list clusterrepos", zap.String("tool", "listClusterRepos"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, repos, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listClusterRepos"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
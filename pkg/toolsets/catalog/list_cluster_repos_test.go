@@ -0,0 +1,28 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClusterRepos(t *testing.T) {
+	tools := Tools{client: newFakeClient(fakeClusterRepo("rancher-charts"), fakeClusterRepo("rancher-partner-charts"))}
+
+	result, _, err := tools.listClusterRepos(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest("https://localhost:8080"), clusterParams{Cluster: "local"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"llm": [
+			{"apiVersion": "catalog.cattle.io/v1", "kind": "ClusterRepo", "metadata": {"name": "rancher-charts"}, "spec": {"gitBranch": "release-v2.12", "gitRepo": "https://git.rancher.io/charts"}},
+			{"apiVersion": "catalog.cattle.io/v1", "kind": "ClusterRepo", "metadata": {"name": "rancher-partner-charts"}, "spec": {"gitBranch": "release-v2.12", "gitRepo": "https://git.rancher.io/charts"}}
+		],
+		"uiContext": [
+			{"cluster": "local", "kind": "ClusterRepo", "name": "rancher-charts", "namespace": "", "type": "catalog.cattle.io.clusterrepo"},
+			{"cluster": "local", "kind": "ClusterRepo", "name": "rancher-partner-charts", "namespace": "", "type": "catalog.cattle.io.clusterrepo"}
+		]
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
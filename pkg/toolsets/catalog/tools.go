@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+)
+
+const (
+	toolsSet    = "catalog"
+	toolsSetAnn = "toolset"
+	urlHeader   = client.URLHeader
+)
+
+// Tools contains all tools for the MCP server
+type Tools struct {
+	client *client.Client
+	// ReadOnly disables the tools that install or upgrade charts.
+	ReadOnly bool
+}
+
+// NewTools creates and returns a new Tools instance.
+func NewTools(client *client.Client) *Tools {
+	return &Tools{
+		client: client,
+	}
+}
+
+// Register adds the tools of the catalog toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	tools.ReadOnly = deps.ReadOnly
+	tools.AddTools(mcpServer)
+}
+
+// AddTools registers all Rancher App Catalog tools with the provided MCP server.
+// Each tool is configured with metadata identifying it as part of the catalog toolset.
+func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listClusterRepos",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the ClusterRepos of a cluster, the Helm chart repositories used by the Rancher App Catalog.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.`},
+		response.WithStructuredErrors(t.listClusterRepos),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listCharts",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the charts available in the ClusterRepos of a cluster with their description, latest version and most recent versions.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		repo (string, optional): Only list the charts of this ClusterRepo (e.g. 'rancher-charts'). Empty for all ClusterRepos.
+		name (string, optional): Only list the charts whose name contains this text (e.g. 'monitoring').
+		includeHidden (boolean, optional): Include the charts hidden in the Rancher UI, such as the CRD charts installed together with other charts. Defaults to false.`},
+		response.WithStructuredErrors(t.listCharts),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getChartValues",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the default values of a chart version and the questions used by the Rancher UI to configure it, which describe the most relevant values with their type, default and description.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		repo (string): The ClusterRepo of the chart (e.g. 'rancher-charts').
+		chart (string): The name of the chart (e.g. 'rancher-monitoring').
+		version (string, optional): The version of the chart. Defaults to the latest version.
+		section (string, optional): Only return this top level key of the values (e.g. 'prometheus'). Use it for charts with large values.`},
+		response.WithStructuredErrors(t.getChartValues),
+	)
+
+	if t.ReadOnly {
+		return
+	}
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "installChart",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Installs a chart from a ClusterRepo in a cluster the same way as the Rancher App Catalog, or upgrades it if the release already exists. The charts the chart depends on, such as its CRD chart, are installed or upgraded too.
+		Ask for confirmation before installing or upgrading a chart.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		repo (string): The ClusterRepo of the chart (e.g. 'rancher-charts').
+		chart (string): The name of the chart (e.g. 'rancher-monitoring').
+		version (string, optional): The version of the chart. Defaults to the latest version.
+		namespace (string, optional): The namespace of the release. Defaults to the namespace recommended by the chart.
+		releaseName (string, optional): The name of the release. Defaults to the name recommended by the chart.
+		values (object, optional): Values overriding the chart defaults. On upgrade, they are merged with the values of the current release.
+
+		Returns:
+		Whether the chart was installed or upgraded, and the Rancher operation running the Helm command.`},
+		response.WithStructuredErrors(t.installChart),
+	)
+}
@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddTools(t *testing.T) {
+	tests := map[string]struct {
+		readOnly      bool
+		expectedTools []string
+	}{
+		"all tools": {
+			expectedTools: []string{"getChartValues", "installChart", "listCharts", "listClusterRepos"},
+		},
+		"read-only": {
+			readOnly:      true,
+			expectedTools: []string{"getChartValues", "listCharts", "listClusterRepos"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := NewTools(client.NewClient(true))
+			tools.ReadOnly = test.readOnly
+			mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+			tools.AddTools(mcpServer)
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+			require.NoError(t, err)
+			defer ss.Close()
+			cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+			require.NoError(t, err)
+			defer cs.Close()
+
+			toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+
+			require.NoError(t, err)
+			var names []string
+			for _, tool := range toolsResult.Tools {
+				names = append(names, tool.Name)
+				assert.Equal(t, toolsSet, tool.Meta[toolsSetAnn])
+			}
+			assert.Equal(t, test.expectedTools, names)
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// crashLogTailLines is the number of lines of the logs of the previous instance of each crashing container returned.
+const crashLogTailLines int64 = 30
+
+// The causes of the hypotheses of analyzeCrashLoop.
+const (
+	causeOOM               = "oom"
+	causeLivenessProbe     = "liveness-probe"
+	causeBadConfig         = "bad-config"
+	causeFailingDependency = "failing-dependency"
+	causeProcessExits      = "process-exits"
+	causeApplicationError  = "application-error"
+)
+
+// crashLogHints are the causes of the known errors found in the logs of the previous instance of a container.
+var crashLogHints = []struct {
+	re    *regexp.Regexp
+	cause string
+	hint  string
+}{
+	{regexp.MustCompile(`(?i)connection refused|no such host|i/o timeout|no route to host|dial tcp|ECONNREFUSED|could not connect|connection reset|temporary failure in name resolution`), causeFailingDependency,
+		"the container can't reach a service it depends on at startup, check that the service is running, its address and the NetworkPolicies"},
+	{regexp.MustCompile(`(?i)no such file or directory|permission denied|unknown (flag|option|command)|invalid (argument|value|configuration)|(missing|required) (environment variable|config|argument|parameter)|failed to (load|parse|read) config|cannot parse`), causeBadConfig,
+		"the container fails to start with its configuration, check its command, arguments, environment variables and the ConfigMaps and Secrets it mounts"},
+	{regexp.MustCompile(`(?i)out of memory|OutOfMemoryError|cannot allocate memory|heap out of memory`), causeOOM,
+		"the process runs out of memory, raise the memory limit of the container or lower the memory used by the application (e.g. the heap size of the JVM)"},
+}
+
+type analyzeCrashLoopParams struct {
+	Name      string `json:"name" jsonschema:"the name of the pod"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the pod"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the pod"`
+	Container string `json:"container,omitempty" jsonschema:"the container to analyze. Empty for all the restarting containers"`
+}
+
+// crashLoopAnalysis explains why the containers of a Pod keep restarting, with the hypotheses sorted from the most
+// likely.
+type crashLoopAnalysis struct {
+	Pod        string              `json:"pod"`
+	Phase      string              `json:"phase"`
+	Containers []crashingContainer `json:"containers"`
+	Events     []relatedEvent      `json:"events"`
+	Hypotheses []crashHypothesis   `json:"hypotheses"`
+}
+
+// crashingContainer is a restarting container, with the termination of its previous instance and the last lines of
+// its logs.
+type crashingContainer struct {
+	Name            string            `json:"name"`
+	State           string            `json:"state"`
+	RestartCount    int32             `json:"restartCount"`
+	LastTermination *containerExit    `json:"lastTermination,omitempty"`
+	MemoryLimit     string            `json:"memoryLimit,omitempty"`
+	Probes          map[string]string `json:"probes,omitempty"`
+	PreviousLogs    string            `json:"previousLogs,omitempty"`
+	// LogsError is why the logs of the previous instance couldn't be read, e.g. the node already removed them.
+	LogsError string `json:"logsError,omitempty"`
+}
+
+// containerExit is how an instance of a container terminated.
+type containerExit struct {
+	ExitCode   int32  `json:"exitCode"`
+	Signal     int32  `json:"signal,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message,omitempty"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+	RanFor     string `json:"ranFor,omitempty"`
+}
+
+// crashHypothesis is a possible cause of the restarts of a container, with the evidence supporting it.
+type crashHypothesis struct {
+	Container  string `json:"container"`
+	Cause      string `json:"cause"`
+	Confidence string `json:"confidence"`
+	Evidence   string `json:"evidence"`
+	Suggestion string `json:"suggestion"`
+}
+
+// analyzeCrashLoop gathers what's needed to find why the containers of a Pod keep restarting in a single call: how
+// their previous instance terminated, its last log lines, the events of the Pod and the probes of the containers, and
+// returns the hypotheses about the cause drawn from them.
+func (t *Tools) analyzeCrashLoop(ctx context.Context, toolReq *mcp.CallToolRequest, params analyzeCrashLoopParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("analyzeCrashLoop called")
+
+	podResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      "pod",
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Pod", zap.String("tool", "analyzeCrashLoop"), zap.Error(err))
+		return nil, nil, err
+	}
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+		middleware.Logger(ctx).Error("failed to convert unstructured object to Pod", zap.String("tool", "analyzeCrashLoop"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+	}
+
+	events, err := t.fetchRelatedEvents(ctx, toolReq, params.Cluster, params.Namespace, []*unstructured.Unstructured{podResource})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get events", zap.String("tool", "analyzeCrashLoop"), zap.Error(err))
+		return nil, nil, err
+	}
+	analysis := crashLoopAnalysis{
+		Pod:        pod.Name,
+		Phase:      string(pod.Status.Phase),
+		Containers: []crashingContainer{},
+		Events:     events.Object["events"].([]relatedEvent),
+		Hypotheses: []crashHypothesis{},
+	}
+
+	specs := slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers)
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if params.Container != "" && status.Name != params.Container {
+			continue
+		}
+		if params.Container == "" && status.RestartCount == 0 && !crashLoopBackOff(status) {
+			continue
+		}
+		i := slices.IndexFunc(specs, func(c corev1.Container) bool { return c.Name == status.Name })
+		if i < 0 {
+			continue
+		}
+		container := newCrashingContainer(specs[i], status)
+		if status.LastTerminationState.Terminated != nil {
+			logs, err := t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), params.Cluster, middleware.Token(ctx), pod, getPodLogsParams{
+				Container: status.Name,
+				TailLines: crashLogTailLines,
+				Previous:  true,
+			})
+			if err != nil {
+				container.LogsError = err.Error()
+			} else {
+				container.PreviousLogs, _ = logs.Object["pod-logs"].(map[string]any)[status.Name].(string)
+			}
+		}
+		analysis.Containers = append(analysis.Containers, container)
+		analysis.Hypotheses = append(analysis.Hypotheses, crashHypotheses(pod, specs[i], status, container, analysis.Events)...)
+	}
+	if params.Container != "" && len(analysis.Containers) == 0 {
+		return nil, nil, fmt.Errorf("container %s not found in pod %s", params.Container, pod.Name)
+	}
+	confidences := []string{"high", "medium", "low"}
+	slices.SortStableFunc(analysis.Hypotheses, func(a, b crashHypothesis) int {
+		return slices.Index(confidences, a.Confidence) - slices.Index(confidences, b.Confidence)
+	})
+
+	response, err := json.Marshal(analysis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "analyzeCrashLoop"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+func crashLoopBackOff(status corev1.ContainerStatus) bool {
+	return status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff"
+}
+
+func newCrashingContainer(spec corev1.Container, status corev1.ContainerStatus) crashingContainer {
+	container := crashingContainer{
+		Name:            status.Name,
+		State:           describeContainerState(status.State),
+		RestartCount:    status.RestartCount,
+		LastTermination: newContainerExit(status.LastTerminationState.Terminated),
+	}
+	if limit, ok := spec.Resources.Limits[corev1.ResourceMemory]; ok {
+		container.MemoryLimit = limit.String()
+	}
+	for kind, probe := range map[string]*corev1.Probe{"liveness": spec.LivenessProbe, "readiness": spec.ReadinessProbe, "startup": spec.StartupProbe} {
+		if probe == nil {
+			continue
+		}
+		if container.Probes == nil {
+			container.Probes = map[string]string{}
+		}
+		container.Probes[kind] = describeProbe(probe)
+	}
+
+	return container
+}
+
+// describeContainerState returns the state of a container, e.g. "running" or "waiting: CrashLoopBackOff". It's empty
+// for the containers that haven't been created yet.
+func describeContainerState(state corev1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return "waiting: " + state.Waiting.Reason
+	case state.Terminated != nil:
+		return "terminated: " + state.Terminated.Reason
+	case state.Running != nil:
+		return "running"
+	}
+
+	return ""
+}
+
+// newContainerExit returns how a container terminated, or nil if it didn't.
+func newContainerExit(terminated *corev1.ContainerStateTerminated) *containerExit {
+	if terminated == nil {
+		return nil
+	}
+	exit := &containerExit{
+		ExitCode: terminated.ExitCode,
+		Signal:   terminated.Signal,
+		Reason:   terminated.Reason,
+		Message:  terminated.Message,
+	}
+	if !terminated.FinishedAt.IsZero() {
+		exit.FinishedAt = terminated.FinishedAt.UTC().Format(time.RFC3339)
+		if !terminated.StartedAt.IsZero() {
+			exit.RanFor = terminated.FinishedAt.Sub(terminated.StartedAt.Time).String()
+		}
+	}
+
+	return exit
+}
+
+// describeProbe returns the handler and the timings of a probe, e.g. "httpGet :8080/healthz, initialDelaySeconds=0,
+// periodSeconds=10, timeoutSeconds=1, failureThreshold=3".
+func describeProbe(probe *corev1.Probe) string {
+	var handler string
+	switch {
+	case probe.HTTPGet != nil:
+		handler = fmt.Sprintf("httpGet :%s%s", probe.HTTPGet.Port.String(), probe.HTTPGet.Path)
+	case probe.TCPSocket != nil:
+		handler = "tcpSocket :" + probe.TCPSocket.Port.String()
+	case probe.GRPC != nil:
+		handler = fmt.Sprintf("grpc :%d", probe.GRPC.Port)
+	case probe.Exec != nil:
+		handler = "exec " + strings.Join(probe.Exec.Command, " ")
+	}
+
+	// the defaults of the API server are used for the unset timings
+	return fmt.Sprintf("%s, initialDelaySeconds=%d, periodSeconds=%d, timeoutSeconds=%d, failureThreshold=%d", handler,
+		probe.InitialDelaySeconds, defaultInt32(probe.PeriodSeconds, 10), defaultInt32(probe.TimeoutSeconds, 1), defaultInt32(probe.FailureThreshold, 3))
+}
+
+func defaultInt32(v int32, defaultValue int32) int32 {
+	if v == 0 {
+		return defaultValue
+	}
+
+	return v
+}
+
+// crashHypotheses returns the possible causes of the restarts of a container, from the termination of its previous
+// instance, its logs and the events of the Pod.
+func crashHypotheses(pod corev1.Pod, spec corev1.Container, status corev1.ContainerStatus, container crashingContainer, events []relatedEvent) []crashHypothesis {
+	var hypotheses []crashHypothesis
+	add := func(cause string, confidence string, evidence string, suggestion string) {
+		if slices.ContainsFunc(hypotheses, func(h crashHypothesis) bool { return h.Cause == cause }) {
+			return
+		}
+		hypotheses = append(hypotheses, crashHypothesis{Container: status.Name, Cause: cause, Confidence: confidence, Evidence: evidence, Suggestion: suggestion})
+	}
+
+	if waiting := status.State.Waiting; waiting != nil && waiting.Reason == "CreateContainerConfigError" {
+		add(causeBadConfig, "high", waiting.Message, "create the ConfigMap or Secret or add the missing key referenced by the container")
+	}
+	livenessFailures := slices.ContainsFunc(events, func(e relatedEvent) bool {
+		return (e.Reason == "Unhealthy" && strings.HasPrefix(e.Message, "Liveness probe failed")) ||
+			(e.Reason == "Killing" && strings.Contains(e.Message, "failed liveness probe"))
+	})
+
+	terminated := status.LastTerminationState.Terminated
+	if terminated == nil {
+		if livenessFailures {
+			add(causeLivenessProbe, "medium", "the liveness probe failed: "+container.Probes["liveness"],
+				"check the endpoint of the liveness probe, or raise its initialDelaySeconds, timeoutSeconds or failureThreshold, or add a startup probe for slow starts")
+		}
+		return hypotheses
+	}
+
+	switch {
+	case terminated.Reason == "OOMKilled":
+		evidence := "the previous instance was OOMKilled"
+		if container.MemoryLimit != "" {
+			evidence += " with a memory limit of " + container.MemoryLimit
+		}
+		add(causeOOM, "high", evidence, "raise the memory limit of the container, or lower the memory used by the application")
+	case terminated.ExitCode == 137 && livenessFailures:
+		add(causeLivenessProbe, "high", "the previous instance was killed (exit code 137) after its liveness probe failed: "+container.Probes["liveness"],
+			"check the endpoint of the liveness probe, or raise its initialDelaySeconds, timeoutSeconds or failureThreshold, or add a startup probe for slow starts")
+	case terminated.ExitCode == 137 && container.MemoryLimit != "":
+		add(causeOOM, "medium", fmt.Sprintf("the previous instance was killed (exit code 137) with a memory limit of %s", container.MemoryLimit),
+			"check the memory used by the container with queryMetrics, and raise its memory limit if it's close to it")
+	case terminated.ExitCode == 126 || terminated.ExitCode == 127:
+		add(causeBadConfig, "high", fmt.Sprintf("the previous instance exited with code %d, the command isn't found or can't be run: %s", terminated.ExitCode, strings.Join(slices.Concat(spec.Command, spec.Args), " ")),
+			"check the command and arguments of the container, and that the image provides the executable")
+	case terminated.ExitCode == 0 && pod.Spec.RestartPolicy != corev1.RestartPolicyNever && pod.Spec.RestartPolicy != corev1.RestartPolicyOnFailure:
+		add(causeProcessExits, "high", "the previous instance exited successfully (exit code 0), but the restart policy of the Pod is Always",
+			"the main process of the container must keep running, check that its command doesn't run in the background or exit after a one-off task (use a Job for those)")
+	}
+
+	for _, line := range strings.Split(container.PreviousLogs, "\n") {
+		for _, h := range crashLogHints {
+			if h.re.MatchString(line) {
+				add(h.cause, "medium", "the previous logs contain: "+strings.TrimSpace(line), h.hint)
+			}
+		}
+	}
+
+	if len(hypotheses) == 0 && terminated.ExitCode != 0 {
+		add(causeApplicationError, "low", fmt.Sprintf("the previous instance exited with code %d (%s), no known error in its logs", terminated.ExitCode, terminated.Reason),
+			"read the previous logs of the container with getPodLogs and a larger tailLines to find the error of the application")
+	}
+
+	return hypotheses
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newCrashLoopPod(name string, lastTermination *corev1.ContainerStateTerminated) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "web",
+					Image: "web:1.0",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+					},
+					LivenessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(8080)}},
+					},
+				},
+				{Name: "sidecar", Image: "sidecar:1.0"},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "web",
+					RestartCount:         5,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: lastTermination},
+				},
+				{
+					Name:  "sidecar",
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+}
+
+func newCrashLoopEvent(name string, pod string, reason string, message string) *corev1.Event {
+	timestamp := metav1.NewTime(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+	return &corev1.Event{
+		TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "default"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          1,
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+	}
+}
+
+func TestAnalyzeCrashLoop(t *testing.T) {
+	started := metav1.NewTime(time.Date(2025, 10, 1, 11, 59, 0, 0, time.UTC))
+	finished := metav1.NewTime(time.Date(2025, 10, 1, 11, 59, 30, 0, time.UTC))
+	objects := []runtime.Object{
+		newCrashLoopPod("oom", &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, StartedAt: started, FinishedAt: finished}),
+		newCrashLoopPod("liveness", &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 137}),
+		newCrashLoopPod("exits", &corev1.ContainerStateTerminated{Reason: "Completed", ExitCode: 0}),
+		newCrashLoopEvent("oom-backoff", "oom", "BackOff", "Back-off restarting failed container web in pod oom"),
+		newCrashLoopEvent("liveness-killing", "liveness", "Killing", "Container web failed liveness probe, will be restarted"),
+	}
+	probe := "httpGet :8080/healthz, initialDelaySeconds=0, periodSeconds=10, timeoutSeconds=1, failureThreshold=3"
+
+	tests := map[string]struct {
+		params         analyzeCrashLoopParams
+		expectedResult string
+		expectedError  string
+	}{
+		"oom killed container": {
+			params: analyzeCrashLoopParams{Name: "oom", Namespace: "default", Cluster: "local"},
+			expectedResult: `{"pod":"oom","phase":"Running",
+				"containers":[{"name":"web","state":"waiting: CrashLoopBackOff","restartCount":5,
+					"lastTermination":{"exitCode":137,"reason":"OOMKilled","finishedAt":"2025-10-01T11:59:30Z","ranFor":"30s"},
+					"memoryLimit":"128Mi","probes":{"liveness":"` + probe + `"},"previousLogs":"fake logs"}],
+				"events":[{"kind":"Pod","name":"oom","type":"Warning","reason":"BackOff","message":"Back-off restarting failed container web in pod oom","count":1,"firstTimestamp":"2025-10-01T12:00:00Z","lastTimestamp":"2025-10-01T12:00:00Z"}],
+				"hypotheses":[{"container":"web","cause":"oom","confidence":"high","evidence":"the previous instance was OOMKilled with a memory limit of 128Mi","suggestion":"raise the memory limit of the container, or lower the memory used by the application"}]}`,
+		},
+		"failing liveness probe": {
+			params: analyzeCrashLoopParams{Name: "liveness", Namespace: "default", Cluster: "local", Container: "web"},
+			expectedResult: `{"pod":"liveness","phase":"Running",
+				"containers":[{"name":"web","state":"waiting: CrashLoopBackOff","restartCount":5,"lastTermination":{"exitCode":137,"reason":"Error"},
+					"memoryLimit":"128Mi","probes":{"liveness":"` + probe + `"},"previousLogs":"fake logs"}],
+				"events":[{"kind":"Pod","name":"liveness","type":"Warning","reason":"Killing","message":"Container web failed liveness probe, will be restarted","count":1,"firstTimestamp":"2025-10-01T12:00:00Z","lastTimestamp":"2025-10-01T12:00:00Z"}],
+				"hypotheses":[{"container":"web","cause":"liveness-probe","confidence":"high","evidence":"the previous instance was killed (exit code 137) after its liveness probe failed: ` + probe + `",
+					"suggestion":"check the endpoint of the liveness probe, or raise its initialDelaySeconds, timeoutSeconds or failureThreshold, or add a startup probe for slow starts"}]}`,
+		},
+		"process exiting": {
+			params: analyzeCrashLoopParams{Name: "exits", Namespace: "default", Cluster: "local"},
+			expectedResult: `{"pod":"exits","phase":"Running",
+				"containers":[{"name":"web","state":"waiting: CrashLoopBackOff","restartCount":5,"lastTermination":{"exitCode":0,"reason":"Completed"},
+					"memoryLimit":"128Mi","probes":{"liveness":"` + probe + `"},"previousLogs":"fake logs"}],
+				"events":[],
+				"hypotheses":[{"container":"web","cause":"process-exits","confidence":"high","evidence":"the previous instance exited successfully (exit code 0), but the restart policy of the Pod is Always",
+					"suggestion":"the main process of the container must keep running, check that its command doesn't run in the background or exit after a one-off task (use a Job for those)"}]}`,
+		},
+		"container not found": {
+			params:        analyzeCrashLoopParams{Name: "oom", Namespace: "default", Cluster: "local", Container: "missing"},
+			expectedError: "container missing not found in pod oom",
+		},
+		"pod not found": {
+			params:        analyzeCrashLoopParams{Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedError: `pods "missing" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme, objects...)
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return fake.NewClientset(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+			result, _, err := tools.analyzeCrashLoop(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestCrashHypothesesFromLogs(t *testing.T) {
+	spec := corev1.Container{Name: "api", Command: []string{"/app/api"}}
+	status := corev1.ContainerStatus{
+		Name:                 "api",
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+	}
+
+	tests := map[string]struct {
+		logs     string
+		expected []crashHypothesis
+	}{
+		"failing dependency and bad config": {
+			logs: "starting api\nfailed to load config /etc/api/config.yaml\nerror: dial tcp 10.43.0.5:5432: connect: connection refused\nretrying: connection refused",
+			expected: []crashHypothesis{
+				{Container: "api", Cause: causeBadConfig, Confidence: "medium", Evidence: "the previous logs contain: failed to load config /etc/api/config.yaml",
+					Suggestion: "the container fails to start with its configuration, check its command, arguments, environment variables and the ConfigMaps and Secrets it mounts"},
+				{Container: "api", Cause: causeFailingDependency, Confidence: "medium", Evidence: "the previous logs contain: error: dial tcp 10.43.0.5:5432: connect: connection refused",
+					Suggestion: "the container can't reach a service it depends on at startup, check that the service is running, its address and the NetworkPolicies"},
+			},
+		},
+		"unknown error": {
+			logs: "panic: something went wrong",
+			expected: []crashHypothesis{
+				{Container: "api", Cause: causeApplicationError, Confidence: "low", Evidence: "the previous instance exited with code 1 (Error), no known error in its logs",
+					Suggestion: "read the previous logs of the container with getPodLogs and a larger tailLines to find the error of the application"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			container := newCrashingContainer(spec, status)
+			container.PreviousLogs = test.logs
+
+			assert.Equal(t, test.expected, crashHypotheses(corev1.Pod{}, spec, status, container, nil))
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+const (
+	usageOK               = "ok"
+	usageOverProvisioned  = "overProvisioned"
+	usageUnderProvisioned = "underProvisioned"
+	usageNoRequest        = "noRequest"
+
+	defaultLowUtilization  = 30
+	defaultHighUtilization = 90
+	// headroomPercent is added to the peak usage to recommend the requests.
+	headroomPercent = 20
+	// defaultMemoryLimitRatio is the memory limit to request ratio recommended for containers without a memory limit.
+	defaultMemoryLimitRatio = 1.5
+
+	minCPURequestMilli  = 10
+	minMemoryRequest    = 16 * 1024 * 1024
+	memoryRoundingBytes = 1024 * 1024
+)
+
+// usageStatusPriority orders the usage statuses from the most to the least relevant one. The status of a workload
+// is the most relevant status of the resources of its containers.
+var usageStatusPriority = []string{usageUnderProvisioned, usageNoRequest, usageOverProvisioned, usageOK}
+
+type analyzeResourceUsageParams struct {
+	Namespace       string `json:"namespace,omitempty" jsonschema:"the namespace of the workloads. Empty for all namespaces"`
+	Cluster         string `json:"cluster" jsonschema:"the cluster of the workloads"`
+	LowUtilization  int64  `json:"lowUtilization,omitempty" jsonschema:"percentage of the requests below which a resource is over-provisioned. Defaults to 30"`
+	HighUtilization int64  `json:"highUtilization,omitempty" jsonschema:"percentage of the requests or limits above which a resource is under-provisioned. Defaults to 90"`
+}
+
+// resourceUsage compares the peak usage of a resource by a container with its request and limit.
+type resourceUsage struct {
+	// Usage is the highest usage of the container among the Pods of the workload.
+	Usage   string `json:"usage"`
+	Request string `json:"request,omitempty"`
+	Limit   string `json:"limit,omitempty"`
+	// Utilization is the usage as a percentage of the request.
+	Utilization *int64 `json:"utilization,omitempty"`
+	// Status is one of ok, overProvisioned, underProvisioned or noRequest.
+	Status             string `json:"status"`
+	RecommendedRequest string `json:"recommendedRequest"`
+	RecommendedLimit   string `json:"recommendedLimit,omitempty"`
+}
+
+// containerUsage holds the CPU and memory usage of a container of a workload.
+type containerUsage struct {
+	Name   string        `json:"name"`
+	CPU    resourceUsage `json:"cpu"`
+	Memory resourceUsage `json:"memory"`
+}
+
+// workloadUsage holds the resource usage of the containers of the running Pods of a workload.
+type workloadUsage struct {
+	Namespace  string           `json:"namespace"`
+	Kind       string           `json:"kind"`
+	Name       string           `json:"name"`
+	Pods       int              `json:"pods"`
+	Status     string           `json:"status"`
+	Containers []containerUsage `json:"containers"`
+}
+
+// resourceUsageAnalysis is the result of analyzeResourceUsage.
+type resourceUsageAnalysis struct {
+	Cluster          string          `json:"cluster"`
+	Namespace        string          `json:"namespace,omitempty"`
+	OverProvisioned  int             `json:"overProvisioned"`
+	UnderProvisioned int             `json:"underProvisioned"`
+	Workloads        []workloadUsage `json:"workloads"`
+}
+
+// containerSample holds the peak usage and the resources of a container among the Pods of a workload.
+type containerSample struct {
+	name                       string
+	cpuUsage, memoryUsage      resource.Quantity
+	cpuRequest, cpuLimit       *resource.Quantity
+	memoryRequest, memoryLimit *resource.Quantity
+}
+
+// analyzeResourceUsage compares the CPU and memory usage reported by the Metrics Server with the requests and limits
+// of the running Pods, grouped by workload, to find over- and under-provisioned workloads and recommend their requests
+// and limits.
+func (t *Tools) analyzeResourceUsage(ctx context.Context, toolReq *mcp.CallToolRequest, params analyzeResourceUsageParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("analyzeResourceUsage called")
+
+	low := cmp.Or(params.LowUtilization, defaultLowUtilization)
+	high := cmp.Or(params.HighUtilization, defaultHighUtilization)
+	if low < 0 || high <= low {
+		return nil, nil, fmt.Errorf("highUtilization (%d) must be greater than lowUtilization (%d)", high, low)
+	}
+
+	podResources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   params.Cluster,
+		Kind:      "pod",
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get pods", zap.String("tool", "analyzeResourceUsage"), zap.Error(err))
+		return nil, nil, err
+	}
+	metricsResources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   params.Cluster,
+		Kind:      "pod.metrics.k8s.io",
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get pod metrics", zap.String("tool", "analyzeResourceUsage"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get pod metrics, make sure the Metrics Server is installed in cluster %s: %w", params.Cluster, err)
+	}
+
+	pods := make([]corev1.Pod, 0, len(podResources))
+	for _, podResource := range podResources {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+	metrics := map[string]metricsv1beta1.PodMetrics{}
+	for _, metricsResource := range metricsResources {
+		var podMetrics metricsv1beta1.PodMetrics
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(metricsResource.Object, &podMetrics); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to PodMetrics: %w", err)
+		}
+		metrics[podMetrics.Namespace+"/"+podMetrics.Name] = podMetrics
+	}
+
+	analysis := resourceUsageAnalysis{
+		Cluster:   params.Cluster,
+		Namespace: params.Namespace,
+		Workloads: analyzeWorkloadsUsage(pods, metrics, low, high),
+	}
+	for _, workload := range analysis.Workloads {
+		switch workload.Status {
+		case usageOverProvisioned:
+			analysis.OverProvisioned++
+		case usageUnderProvisioned, usageNoRequest:
+			analysis.UnderProvisioned++
+		}
+	}
+
+	response, err := json.Marshal(analysis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "analyzeResourceUsage"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// analyzeWorkloadsUsage groups the running Pods with metrics by workload and analyzes the usage of their containers.
+// The result is sorted by namespace, kind and name.
+func analyzeWorkloadsUsage(pods []corev1.Pod, metrics map[string]metricsv1beta1.PodMetrics, low int64, high int64) []workloadUsage {
+	workloads := map[string]*workloadUsage{}
+	samples := map[string][]*containerSample{}
+	for _, pod := range pods {
+		podMetrics, ok := metrics[pod.Namespace+"/"+pod.Name]
+		if pod.Status.Phase != corev1.PodRunning || !ok {
+			continue
+		}
+		kind, name := utils.PodWorkload(pod)
+		key := pod.Namespace + "/" + kind + "/" + name
+		workload, ok := workloads[key]
+		if !ok {
+			workload = &workloadUsage{Namespace: pod.Namespace, Kind: kind, Name: name}
+			workloads[key] = workload
+		}
+		workload.Pods++
+
+		for _, container := range pod.Spec.Containers {
+			i := slices.IndexFunc(podMetrics.Containers, func(c metricsv1beta1.ContainerMetrics) bool {
+				return c.Name == container.Name
+			})
+			if i < 0 {
+				continue
+			}
+			usage := podMetrics.Containers[i].Usage
+			j := slices.IndexFunc(samples[key], func(s *containerSample) bool { return s.name == container.Name })
+			if j < 0 {
+				samples[key] = append(samples[key], newContainerSample(container, usage))
+				continue
+			}
+			sample := samples[key][j]
+			if usage.Cpu().Cmp(sample.cpuUsage) > 0 {
+				sample.cpuUsage = *usage.Cpu()
+			}
+			if usage.Memory().Cmp(sample.memoryUsage) > 0 {
+				sample.memoryUsage = *usage.Memory()
+			}
+		}
+	}
+
+	result := make([]workloadUsage, 0, len(workloads))
+	for key, workload := range workloads {
+		workload.Status = usageOK
+		workload.Containers = []containerUsage{}
+		for _, sample := range samples[key] {
+			container := containerUsage{
+				Name:   sample.name,
+				CPU:    analyzeUsage(sample.cpuUsage, sample.cpuRequest, sample.cpuLimit, low, high, cpuQuantity, 0),
+				Memory: analyzeUsage(sample.memoryUsage, sample.memoryRequest, sample.memoryLimit, low, high, memoryQuantity, defaultMemoryLimitRatio),
+			}
+			workload.Status = mostRelevantStatus(workload.Status, container.CPU.Status, container.Memory.Status)
+			workload.Containers = append(workload.Containers, container)
+		}
+		result = append(result, *workload)
+	}
+	slices.SortFunc(result, func(a, b workloadUsage) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	return result
+}
+
+func newContainerSample(container corev1.Container, usage corev1.ResourceList) *containerSample {
+	sample := &containerSample{
+		name:        container.Name,
+		cpuUsage:    *usage.Cpu(),
+		memoryUsage: *usage.Memory(),
+	}
+	if q, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+		sample.cpuRequest = &q
+	}
+	if q, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+		sample.cpuLimit = &q
+	}
+	if q, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+		sample.memoryRequest = &q
+	}
+	if q, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+		sample.memoryLimit = &q
+	}
+
+	return sample
+}
+
+// analyzeUsage compares the usage of a resource with its request and limit. The resource is over-provisioned when
+// the usage is below low percent of the request, and under-provisioned when it's above high percent of the request
+// or of the limit. The recommended request is the usage plus headroomPercent. The recommended limit keeps the
+// current limit to request ratio, or uses defaultLimitRatio for the containers without a limit if it isn't 0.
+func analyzeUsage(usage resource.Quantity, request *resource.Quantity, limit *resource.Quantity, low int64, high int64,
+	toQuantity func(int64) resource.Quantity, defaultLimitRatio float64) resourceUsage {
+	result := resourceUsage{Usage: usage.String()}
+	usageValue := usage.MilliValue()
+
+	recommendedRequest := toQuantity(usageValue * (100 + headroomPercent) / 100)
+	result.RecommendedRequest = recommendedRequest.String()
+	limitRatio := defaultLimitRatio
+
+	switch {
+	case request == nil || request.IsZero():
+		result.Status = usageNoRequest
+	default:
+		result.Request = request.String()
+		utilization := usageValue * 100 / request.MilliValue()
+		result.Utilization = &utilization
+		switch {
+		case utilization > high:
+			result.Status = usageUnderProvisioned
+		case utilization < low:
+			result.Status = usageOverProvisioned
+		default:
+			result.Status = usageOK
+		}
+	}
+
+	if limit != nil && !limit.IsZero() {
+		result.Limit = limit.String()
+		if usageValue*100/limit.MilliValue() > high {
+			result.Status = usageUnderProvisioned
+		}
+		limitRatio = 1
+		if request != nil && !request.IsZero() {
+			limitRatio = float64(limit.MilliValue()) / float64(request.MilliValue())
+		}
+	}
+	if limitRatio > 0 {
+		recommendedLimit := toQuantity(int64(float64(recommendedRequest.MilliValue()) * limitRatio))
+		result.RecommendedLimit = recommendedLimit.String()
+	}
+
+	return result
+}
+
+// cpuQuantity returns a CPU quantity of at least minCPURequestMilli millicores.
+func cpuQuantity(milli int64) resource.Quantity {
+	return *resource.NewMilliQuantity(max(milli, minCPURequestMilli), resource.DecimalSI)
+}
+
+// memoryQuantity returns a memory quantity of at least minMemoryRequest, rounded up to a whole number of Mi.
+// The value is given in thousandths of bytes, like the MilliValue of a Quantity.
+func memoryQuantity(milli int64) resource.Quantity {
+	bytes := max((milli/1000+memoryRoundingBytes-1)/memoryRoundingBytes*memoryRoundingBytes, minMemoryRequest)
+	return *resource.NewQuantity(bytes, resource.BinarySI)
+}
+
+// mostRelevantStatus returns the status that comes first in usageStatusPriority.
+func mostRelevantStatus(statuses ...string) string {
+	return slices.MinFunc(statuses, func(a, b string) int {
+		return cmp.Compare(slices.Index(usageStatusPriority, a), slices.Index(usageStatusPriority, b))
+	})
+}
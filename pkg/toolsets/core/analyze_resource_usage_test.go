@@ -0,0 +1,232 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"k8s.io/utils/ptr"
+)
+
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+func newUsagePod(name string, owner string, phase corev1.PodPhase, requests corev1.ResourceList, limits corev1.ResourceList) *corev1.Pod {
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	if owner != "" {
+		pod.Labels = map[string]string{"pod-template-hash": "abc12"}
+		pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owner + "-abc12", Controller: ptr.To(true)}}
+	}
+
+	return pod
+}
+
+func newPodMetrics(name string, cpu string, memory string) *metricsv1beta1.PodMetrics {
+	return &metricsv1beta1.PodMetrics{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "metrics.k8s.io/v1beta1", Kind: "PodMetrics"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name: "app",
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		}},
+	}
+}
+
+func resourceList(cpu string, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+func TestAnalyzeResourceUsage(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params           analyzeResourceUsageParams
+		pods             []runtime.Object
+		metrics          []*metricsv1beta1.PodMetrics
+		expectedAnalysis resourceUsageAnalysis
+		expectedError    string
+	}{
+		"over and under provisioned workloads": {
+			params: analyzeResourceUsageParams{Namespace: "default", Cluster: "local"},
+			pods: []runtime.Object{
+				newUsagePod("web-abc12-1", "web", corev1.PodRunning, resourceList("1", "1Gi"), resourceList("2", "2Gi")),
+				newUsagePod("web-abc12-2", "web", corev1.PodRunning, resourceList("1", "1Gi"), resourceList("2", "2Gi")),
+				newUsagePod("api-abc12-1", "api", corev1.PodRunning, resourceList("100m", "128Mi"), resourceList("", "128Mi")),
+				newUsagePod("batch", "", corev1.PodRunning, nil, nil),
+				newUsagePod("web-abc12-3", "web", corev1.PodPending, resourceList("1", "1Gi"), resourceList("2", "2Gi")),
+			},
+			metrics: []*metricsv1beta1.PodMetrics{
+				newPodMetrics("web-abc12-1", "100m", "200Mi"),
+				newPodMetrics("web-abc12-2", "150m", "100Mi"),
+				newPodMetrics("api-abc12-1", "50m", "120Mi"),
+				newPodMetrics("batch", "1m", "1Mi"),
+			},
+			expectedAnalysis: resourceUsageAnalysis{
+				Cluster:          "local",
+				Namespace:        "default",
+				OverProvisioned:  1,
+				UnderProvisioned: 2,
+				Workloads: []workloadUsage{
+					{
+						Namespace: "default", Kind: "Deployment", Name: "api", Pods: 1, Status: usageUnderProvisioned,
+						Containers: []containerUsage{{
+							Name: "app",
+							CPU: resourceUsage{
+								Usage: "50m", Request: "100m", Utilization: ptr.To(int64(50)), Status: usageOK, RecommendedRequest: "60m",
+							},
+							Memory: resourceUsage{
+								Usage: "120Mi", Request: "128Mi", Limit: "128Mi", Utilization: ptr.To(int64(93)), Status: usageUnderProvisioned,
+								RecommendedRequest: "144Mi", RecommendedLimit: "144Mi",
+							},
+						}},
+					},
+					{
+						Namespace: "default", Kind: "Deployment", Name: "web", Pods: 2, Status: usageOverProvisioned,
+						Containers: []containerUsage{{
+							Name: "app",
+							CPU: resourceUsage{
+								Usage: "150m", Request: "1", Limit: "2", Utilization: ptr.To(int64(15)), Status: usageOverProvisioned,
+								RecommendedRequest: "180m", RecommendedLimit: "360m",
+							},
+							Memory: resourceUsage{
+								Usage: "200Mi", Request: "1Gi", Limit: "2Gi", Utilization: ptr.To(int64(19)), Status: usageOverProvisioned,
+								RecommendedRequest: "240Mi", RecommendedLimit: "480Mi",
+							},
+						}},
+					},
+					{
+						Namespace: "default", Kind: "Pod", Name: "batch", Pods: 1, Status: usageNoRequest,
+						Containers: []containerUsage{{
+							Name:   "app",
+							CPU:    resourceUsage{Usage: "1m", Status: usageNoRequest, RecommendedRequest: "10m"},
+							Memory: resourceUsage{Usage: "1Mi", Status: usageNoRequest, RecommendedRequest: "16Mi", RecommendedLimit: "24Mi"},
+						}},
+					},
+				},
+			},
+		},
+		"custom thresholds": {
+			params: analyzeResourceUsageParams{Namespace: "default", Cluster: "local", LowUtilization: 10, HighUtilization: 40},
+			pods: []runtime.Object{
+				newUsagePod("web-abc12-1", "web", corev1.PodRunning, resourceList("100m", "100Mi"), nil),
+			},
+			metrics: []*metricsv1beta1.PodMetrics{
+				newPodMetrics("web-abc12-1", "50m", "20Mi"),
+			},
+			expectedAnalysis: resourceUsageAnalysis{
+				Cluster:          "local",
+				Namespace:        "default",
+				UnderProvisioned: 1,
+				Workloads: []workloadUsage{{
+					Namespace: "default", Kind: "Deployment", Name: "web", Pods: 1, Status: usageUnderProvisioned,
+					Containers: []containerUsage{{
+						Name: "app",
+						CPU: resourceUsage{
+							Usage: "50m", Request: "100m", Utilization: ptr.To(int64(50)), Status: usageUnderProvisioned, RecommendedRequest: "60m",
+						},
+						Memory: resourceUsage{
+							Usage: "20Mi", Request: "100Mi", Utilization: ptr.To(int64(20)), Status: usageOK,
+							RecommendedRequest: "24Mi", RecommendedLimit: "36Mi",
+						},
+					}},
+				}},
+			},
+		},
+		"no running pods": {
+			params: analyzeResourceUsageParams{Namespace: "default", Cluster: "local"},
+			expectedAnalysis: resourceUsageAnalysis{
+				Cluster:   "local",
+				Namespace: "default",
+				Workloads: []workloadUsage{},
+			},
+		},
+		"invalid thresholds": {
+			params:        analyzeResourceUsageParams{Namespace: "default", Cluster: "local", LowUtilization: 80, HighUtilization: 50},
+			expectedError: "highUtilization (50) must be greater than lowUtilization (80)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(nodeScheme(), map[schema.GroupVersionResource]string{
+				podMetricsGVR: "PodMetricsList",
+			}, test.pods...)
+			for _, podMetrics := range test.metrics {
+				require.NoError(t, fakeDynClient.Tracker().Create(podMetricsGVR, podMetrics, podMetrics.Namespace))
+			}
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.analyzeResourceUsage(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var analysis resourceUsageAnalysis
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &analysis))
+			assert.Equal(t, test.expectedAnalysis, analysis)
+		})
+	}
+}
+
+func TestMemoryQuantity(t *testing.T) {
+	tests := map[string]struct {
+		bytes    int64
+		expected string
+	}{
+		"rounded up to Mi": {bytes: 100*1024*1024 + 1, expected: "101Mi"},
+		"exact Mi":         {bytes: 256 * 1024 * 1024, expected: "256Mi"},
+		"minimum request":  {bytes: 1024, expected: "16Mi"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			quantity := memoryQuantity(test.bytes * 1000)
+			assert.Equal(t, test.expected, quantity.String())
+		})
+	}
+}
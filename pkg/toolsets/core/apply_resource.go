@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// applyFieldManager is the field manager that owns the fields set through server-side apply.
+const applyFieldManager = "rancher-ai-mcp"
+
+// applyKubernetesResourceParams defines the structure for applying a general Kubernetes resource.
+type applyKubernetesResourceParams struct {
+	Name      string `json:"name" jsonschema:"the name of k8s resource"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the resource, empty for the cluster-scoped resources"`
+	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
+	Resource  any    `json:"resource" jsonschema:"the desired state of the resource, only the fields to be managed must be set"`
+	Force     bool   `json:"force,omitempty" jsonschema:"take ownership of fields managed by other field managers"`
+	DryRun    bool   `json:"dryRun,omitempty" jsonschema:"validate the request in the server without modifying the resource"`
+}
+
+// applyKubernetesResource creates or updates a Kubernetes resource using server-side apply, and returns the applied
+// object with a diff against the previous one.
+func (t *Tools) applyKubernetesResource(ctx context.Context, toolReq *mcp.CallToolRequest, params applyKubernetesResourceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("applyKubernetesResource called")
+
+	gvr, err := t.client.ResolveGVR(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Cluster, params.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objBytes, err := json.Marshal(params.Resource)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to marshal resource", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+
+	unstructuredObj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(objBytes, unstructuredObj); err != nil {
+		middleware.Logger(ctx).Error("failed to create unstructured resource", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create unstructured object: %w", err)
+	}
+	if unstructuredObj.GetName() == "" {
+		unstructuredObj.SetName(params.Name)
+	}
+	if unstructuredObj.GetNamespace() == "" && params.Namespace != "" {
+		unstructuredObj.SetNamespace(params.Namespace)
+	}
+
+	applyOptions := metav1.ApplyOptions{FieldManager: applyFieldManager, Force: params.Force}
+	if params.DryRun {
+		applyOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	current, err := resourceInterface.Get(ctx, unstructuredObj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// the resource is created
+		current = nil
+	} else if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get resource %s: %w", params.Name, err)
+	}
+
+	obj, err := resourceInterface.Apply(ctx, unstructuredObj.GetName(), unstructuredObj, applyOptions)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to apply resource", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+		if apierrors.IsConflict(err) {
+			return nil, nil, applyConflictError(params.Name, err)
+		}
+		return nil, nil, fmt.Errorf("failed to apply resource %s: %w", params.Name, err)
+	}
+
+	if params.DryRun {
+		dryRun, err := dryRunResponse(current, obj)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create dry-run response", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: dryRun}},
+		}, nil, nil
+	}
+
+	diff, err := diffObject(current, obj)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create diff", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj, diff}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "applyKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// applyConflictError lists the fields that are owned by other field managers, so the LLM can decide whether to force the apply.
+func applyConflictError(name string, err error) error {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil || len(statusErr.Status().Details.Causes) == 0 {
+		return fmt.Errorf("failed to apply resource %s: %w. Set force to take ownership of the conflicting fields", name, err)
+	}
+
+	conflicts := make([]string, 0, len(statusErr.Status().Details.Causes))
+	for _, cause := range statusErr.Status().Details.Causes {
+		conflicts = append(conflicts, cause.Message)
+	}
+
+	return fmt.Errorf("failed to apply resource %s, fields are managed by other field managers: %s. Set force to take ownership of these fields", name, strings.Join(conflicts, "; "))
+}
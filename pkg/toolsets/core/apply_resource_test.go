@@ -0,0 +1,165 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var fakeConfigMapForApply = &corev1.ConfigMap{
+	TypeMeta: metav1.TypeMeta{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+	},
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-config",
+		Namespace: "default",
+	},
+	Data: map[string]string{
+		"key1": "value1",
+	},
+}
+
+func newApplyFakeDynClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(createResourceScheme(), map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+	}, objects...)
+}
+
+func TestApplyKubernetesResource(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	configMapResource := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data": map[string]any{
+			"key1": "updated-value",
+			"key2": "value2",
+		},
+	}
+
+	conflictingDynClient := newApplyFakeDynClient(fakeConfigMapForApply)
+	conflictingDynClient.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchAction).GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewApplyConflict([]metav1.StatusCause{
+			{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kubectl-client-side-apply": .data.key1`, Field: ".data.key1"},
+		}, "Apply failed with 1 conflict")
+	})
+
+	tests := map[string]struct {
+		params         applyKubernetesResourceParams
+		fakeDynClient  *dynamicfake.FakeDynamicClient
+		expectedResult string
+		expectedError  string
+	}{
+		"apply existing configmap": {
+			params: applyKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+				Resource:  configMapResource,
+			},
+			fakeDynClient: newApplyFakeDynClient(fakeConfigMapForApply),
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "v1",
+						"data": {"key1": "updated-value", "key2": "value2"},
+						"kind": "ConfigMap",
+						"metadata": {"name": "test-config", "namespace": "default"}
+					},
+					{"diff": [{"path": "/data/key1", "op": "replace", "oldValue": "value1", "newValue": "updated-value"}, {"path": "/data/key2", "op": "add", "newValue": "value2"}]}
+				],
+				"uiContext": [
+					{"namespace": "default", "kind": "ConfigMap", "cluster": "local", "name": "test-config", "type": "configmap"}
+				]
+			}`,
+		},
+		"apply existing configmap - dry run": {
+			params: applyKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+				Resource:  configMapResource,
+				DryRun:    true,
+			},
+			fakeDynClient: newApplyFakeDynClient(fakeConfigMapForApply),
+			expectedResult: `{
+				"dryRun": true,
+				"object": {
+					"apiVersion": "v1",
+					"data": {"key1": "updated-value", "key2": "value2"},
+					"kind": "ConfigMap",
+					"metadata": {"name": "test-config", "namespace": "default"}
+				},
+				"diff": [
+					{"path": "/data/key1", "op": "replace", "oldValue": "value1", "newValue": "updated-value"},
+					{"path": "/data/key2", "op": "add", "newValue": "value2"}
+				]
+			}`,
+		},
+		"apply configmap - conflict": {
+			params: applyKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+				Resource:  configMapResource,
+			},
+			fakeDynClient: conflictingDynClient,
+			expectedError: `fields are managed by other field managers: conflict with "kubectl-client-side-apply": .data.key1. Set force to take ownership of these fields`,
+		},
+		"apply configmap - invalid": {
+			params: applyKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+				Resource:  "invalid-resource-type",
+			},
+			fakeDynClient: newApplyFakeDynClient(),
+			expectedError: "failed to create unstructured object",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return test.fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.applyKubernetesResource(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
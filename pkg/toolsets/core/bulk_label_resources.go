@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxBulkLabelResources is the maximum number of resources bulkLabelResources modifies in a call.
+const maxBulkLabelResources = 200
+
+type bulkLabelResourcesParams struct {
+	Kind              string            `json:"kind" jsonschema:"the kind of the resources" validate:"required"`
+	Cluster           string            `json:"cluster" jsonschema:"the cluster of the resources"`
+	Namespace         string            `json:"namespace,omitempty" jsonschema:"the namespace of the resources. Empty for all namespaces or cluster-wide resources"`
+	LabelSelector     string            `json:"labelSelector,omitempty" jsonschema:"the label selector the resources must match, e.g. env=prod"`
+	AddLabels         map[string]string `json:"addLabels,omitempty" jsonschema:"the labels to set, by key"`
+	RemoveLabels      []string          `json:"removeLabels,omitempty" jsonschema:"the keys of the labels to remove"`
+	AddAnnotations    map[string]string `json:"addAnnotations,omitempty" jsonschema:"the annotations to set, by key"`
+	RemoveAnnotations []string          `json:"removeAnnotations,omitempty" jsonschema:"the keys of the annotations to remove"`
+	DryRun            bool              `json:"dryRun,omitempty" jsonschema:"list the resources that would change without modifying them"`
+}
+
+// bulkLabelResult lists the resources whose labels or annotations are changed by bulkLabelResources.
+type bulkLabelResult struct {
+	DryRun bool   `json:"dryRun,omitempty"`
+	Kind   string `json:"kind"`
+	// Matched is the number of resources matching the selector, Unchanged the ones that already have the labels.
+	Matched   int               `json:"matched"`
+	Unchanged int               `json:"unchanged"`
+	Changed   []labeledResource `json:"changed"`
+	Failed    []labeledResource `json:"failed,omitempty"`
+}
+
+// labeledResource is a resource changed by bulkLabelResources, with the changes of its labels and annotations.
+type labeledResource struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Diff      []fieldChange `json:"diff,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// bulkLabelResources adds and removes labels and annotations on all the resources of a kind matching a label
+// selector, with a merge patch for each resource that doesn't already have them. In dry-run mode the resources that
+// would change are listed without patching them.
+func (t *Tools) bulkLabelResources(ctx context.Context, toolReq *mcp.CallToolRequest, params bulkLabelResourcesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("bulkLabelResources called")
+
+	if err := validateBulkLabelParams(params); err != nil {
+		return nil, nil, err
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	resources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       params.Cluster,
+		Kind:          params.Kind,
+		Namespace:     params.Namespace,
+		URL:           url,
+		Token:         token,
+		LabelSelector: params.LabelSelector,
+		MetadataOnly:  true,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list resources", zap.String("tool", "bulkLabelResources"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := bulkLabelResult{DryRun: params.DryRun, Kind: params.Kind, Matched: len(resources), Changed: []labeledResource{}}
+	var pending []*unstructured.Unstructured
+	for _, resource := range resources {
+		if len(labelsDiff(resource, params)) == 0 {
+			result.Unchanged++
+			continue
+		}
+		pending = append(pending, resource)
+	}
+	if len(pending) > maxBulkLabelResources {
+		return nil, nil, fmt.Errorf("%d resources would change, more than the %d changed in a call. Use a label selector or a namespace to select less resources", len(pending), maxBulkLabelResources)
+	}
+
+	if params.DryRun {
+		for _, resource := range pending {
+			result.Changed = append(result.Changed, labeledResource{Name: resource.GetName(), Namespace: resource.GetNamespace(), Diff: labelsDiff(resource, params)})
+		}
+	} else {
+		gvr, err := t.client.ResolveGVR(ctx, token, url, params.Cluster, params.Kind)
+		if err != nil {
+			return nil, nil, err
+		}
+		patch, err := metadataPatch(params)
+		if err != nil {
+			return nil, nil, err
+		}
+		// a resource that can't be patched doesn't stop the others, it's reported with its error
+		for _, resource := range pending {
+			changed := labeledResource{Name: resource.GetName(), Namespace: resource.GetNamespace()}
+			patched, err := t.mergePatchResource(ctx, token, url, params.Cluster, gvr, resource, patch)
+			if err != nil {
+				middleware.Logger(ctx).Error("failed to patch resource", zap.String("tool", "bulkLabelResources"), zap.String("name", resource.GetName()), zap.Error(err))
+				changed.Error = err.Error()
+				result.Failed = append(result.Failed, changed)
+				continue
+			}
+			changed.Diff = objectDiff(labelsOf(resource.GetLabels(), resource.GetAnnotations()), labelsOf(patched.GetLabels(), patched.GetAnnotations()))
+			result.Changed = append(result.Changed, changed)
+		}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "bulkLabelResources"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// validateBulkLabelParams checks that there is something to change, and that the keys and values are valid.
+func validateBulkLabelParams(params bulkLabelResourcesParams) error {
+	if len(params.AddLabels)+len(params.RemoveLabels)+len(params.AddAnnotations)+len(params.RemoveAnnotations) == 0 {
+		return fmt.Errorf("at least one of addLabels, removeLabels, addAnnotations or removeAnnotations is required")
+	}
+
+	var errs []string
+	for key, value := range params.AddLabels {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Sprintf("label key %q: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, fmt.Sprintf("label value %q: %s", value, msg))
+		}
+	}
+	for key := range params.AddAnnotations {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Sprintf("annotation key %q: %s", key, msg))
+		}
+	}
+	for _, key := range params.RemoveLabels {
+		if _, ok := params.AddLabels[key]; ok {
+			errs = append(errs, fmt.Sprintf("label %q is both added and removed", key))
+		}
+	}
+	for _, key := range params.RemoveAnnotations {
+		if _, ok := params.AddAnnotations[key]; ok {
+			errs = append(errs, fmt.Sprintf("annotation %q is both added and removed", key))
+		}
+	}
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return fmt.Errorf("invalid labels or annotations: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// labelsDiff returns the changes that the params make to the labels and annotations of the resource.
+func labelsDiff(resource *unstructured.Unstructured, params bulkLabelResourcesParams) []fieldChange {
+	diff := objectDiff(labelsOf(resource.GetLabels(), resource.GetAnnotations()), labelsOf(
+		applyMetadataChanges(resource.GetLabels(), params.AddLabels, params.RemoveLabels),
+		applyMetadataChanges(resource.GetAnnotations(), params.AddAnnotations, params.RemoveAnnotations),
+	))
+	if len(diff) == 0 {
+		return nil
+	}
+
+	return diff
+}
+
+// labelsOf returns an object with only the labels and annotations, always set so the changes are diffed by key.
+func labelsOf(labels map[string]string, annotations map[string]string) *unstructured.Unstructured {
+	toMap := func(values map[string]string) map[string]any {
+		m := make(map[string]any, len(values))
+		for key, value := range values {
+			m[key] = value
+		}
+		return m
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"labels": toMap(labels), "annotations": toMap(annotations)},
+	}}
+}
+
+// applyMetadataChanges returns a copy of the labels or annotations with the keys added and removed.
+func applyMetadataChanges(current map[string]string, add map[string]string, remove []string) map[string]string {
+	result := maps.Clone(current)
+	if result == nil {
+		result = map[string]string{}
+	}
+	maps.Copy(result, add)
+	for _, key := range remove {
+		delete(result, key)
+	}
+
+	return result
+}
+
+// mergePatchResource applies a merge patch to the resource, in its namespace.
+func (t *Tools) mergePatchResource(ctx context.Context, token string, url string, cluster string, gvr schema.GroupVersionResource, resource *unstructured.Unstructured, patch []byte) (*unstructured.Unstructured, error) {
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, resource.GetNamespace(), cluster, gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceInterface.Patch(ctx, resource.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// metadataPatch returns the merge patch of the labels and annotations, where the removed keys are set to null.
+func metadataPatch(params bulkLabelResourcesParams) ([]byte, error) {
+	keys := func(add map[string]string, remove []string) map[string]any {
+		patch := map[string]any{}
+		for key, value := range add {
+			patch[key] = value
+		}
+		for _, key := range remove {
+			patch[key] = nil
+		}
+		return patch
+	}
+	metadata := map[string]any{}
+	if labels := keys(params.AddLabels, params.RemoveLabels); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if annotations := keys(params.AddAnnotations, params.RemoveAnnotations); len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+
+	patch, err := json.Marshal(map[string]any{"metadata": metadata})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newBulkLabelFakeDynClient() *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	deployment := func(namespace string, name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	}
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+	},
+		deployment("shop", "checkout", map[string]string{"env": "prod"}),
+		deployment("shop", "cart", map[string]string{"env": "prod", "team": "payments"}),
+		deployment("billing", "invoices", map[string]string{"env": "prod", "legacy": "true"}),
+		deployment("shop", "cart-staging", map[string]string{"env": "staging"}),
+	)
+}
+
+func TestBulkLabelResources(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params         bulkLabelResourcesParams
+		patchError     bool
+		expectedResult string
+		expectedLabels map[string]map[string]string
+		expectedError  string
+	}{
+		"dry run": {
+			params: bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", LabelSelector: "env=prod", AddLabels: map[string]string{"team": "payments"}, DryRun: true},
+			expectedResult: `{"dryRun": true, "kind": "deployment", "matched": 3, "unchanged": 1, "changed": [
+				{"name": "invoices", "namespace": "billing", "diff": [{"path": "/metadata/labels/team", "op": "add", "newValue": "payments"}]},
+				{"name": "checkout", "namespace": "shop", "diff": [{"path": "/metadata/labels/team", "op": "add", "newValue": "payments"}]}
+			]}`,
+			expectedLabels: map[string]map[string]string{
+				"billing/invoices": {"env": "prod", "legacy": "true"},
+				"shop/checkout":    {"env": "prod"},
+			},
+		},
+		"add and remove labels": {
+			params: bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", LabelSelector: "env=prod", AddLabels: map[string]string{"team": "payments"}, RemoveLabels: []string{"legacy"}},
+			expectedResult: `{"kind": "deployment", "matched": 3, "unchanged": 1, "changed": [
+				{"name": "invoices", "namespace": "billing", "diff": [
+					{"path": "/metadata/labels/legacy", "op": "remove", "oldValue": "true"},
+					{"path": "/metadata/labels/team", "op": "add", "newValue": "payments"}
+				]},
+				{"name": "checkout", "namespace": "shop", "diff": [{"path": "/metadata/labels/team", "op": "add", "newValue": "payments"}]}
+			]}`,
+			expectedLabels: map[string]map[string]string{
+				"billing/invoices": {"env": "prod", "team": "payments"},
+				"shop/checkout":    {"env": "prod", "team": "payments"},
+				"shop/cart":        {"env": "prod", "team": "payments"},
+			},
+		},
+		"annotations in a namespace": {
+			params: bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", Namespace: "shop", AddAnnotations: map[string]string{"owner": "payments@example.com"}},
+			expectedResult: `{"kind": "deployment", "matched": 3, "unchanged": 0, "changed": [
+				{"name": "cart", "namespace": "shop", "diff": [{"path": "/metadata/annotations/owner", "op": "add", "newValue": "payments@example.com"}]},
+				{"name": "cart-staging", "namespace": "shop", "diff": [{"path": "/metadata/annotations/owner", "op": "add", "newValue": "payments@example.com"}]},
+				{"name": "checkout", "namespace": "shop", "diff": [{"path": "/metadata/annotations/owner", "op": "add", "newValue": "payments@example.com"}]}
+			]}`,
+		},
+		"failed patch": {
+			params:     bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", Namespace: "billing", AddLabels: map[string]string{"team": "billing"}},
+			patchError: true,
+			expectedResult: `{"kind": "deployment", "matched": 1, "unchanged": 0, "changed": [], "failed": [
+				{"name": "invoices", "namespace": "billing", "error": "deployments.apps \"invoices\" is forbidden: assert.AnError general error for testing"}
+			]}`,
+		},
+		"nothing to change": {
+			params:        bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", LabelSelector: "env=prod"},
+			expectedError: "at least one of addLabels, removeLabels, addAnnotations or removeAnnotations is required",
+		},
+		"invalid label": {
+			params:        bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", AddLabels: map[string]string{"team": "pay ments"}},
+			expectedError: `invalid labels or annotations: label value "pay ments"`,
+		},
+		"label added and removed": {
+			params:        bulkLabelResourcesParams{Kind: "deployment", Cluster: "local", AddLabels: map[string]string{"team": "payments"}, RemoveLabels: []string{"team"}},
+			expectedError: `invalid labels or annotations: label "team" is both added and removed`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := newBulkLabelFakeDynClient()
+			if test.patchError {
+				fakeDynClient.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "invoices", assert.AnError)
+				})
+			}
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.bulkLabelResources(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			for key, labels := range test.expectedLabels {
+				namespace, name, _ := strings.Cut(key, "/")
+				obj, err := fakeDynClient.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).Namespace(namespace).Get(t.Context(), name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, labels, obj.GetLabels(), key)
+			}
+		})
+	}
+}
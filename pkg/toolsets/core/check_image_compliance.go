@@ -0,0 +1,175 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+)
+
+// The severities of the image compliance violations, from highest to lowest.
+const (
+	// complianceHigh is the severity of the images of a registry that isn't allowed.
+	complianceHigh = "HIGH"
+	// complianceMedium is the severity of the images of an allowed registry, from a repository that isn't allowed.
+	complianceMedium = "MEDIUM"
+	// complianceLow is the severity of the allowed images referenced by the latest tag without a digest, which can
+	// change to an image that wasn't reviewed.
+	complianceLow = "LOW"
+)
+
+// complianceSeverities are the severities of the violations, from highest to lowest.
+var complianceSeverities = []string{complianceHigh, complianceMedium, complianceLow}
+
+// imageAnySuffix is the suffix of the image allowlist entries allowing all the repositories with their prefix.
+const imageAnySuffix = "*"
+
+type checkImageComplianceParams struct {
+	Clusters    []string `json:"clusters" jsonschema:"the clusters where images are checked"`
+	Namespace   string   `json:"namespace,omitempty" jsonschema:"only check images used in this namespace"`
+	MinSeverity string   `json:"minSeverity,omitempty" jsonschema:"only list violations with this severity or higher (HIGH, MEDIUM, LOW). Defaults to LOW" validate:"enum=HIGH|MEDIUM|LOW"`
+}
+
+// imageViolation is an image used by a workload that doesn't comply with the image allowlist.
+type imageViolation struct {
+	containerImage
+	Severity string `json:"severity"`
+	Reason   string `json:"reason"`
+}
+
+// clusterCompliance is the compliance of the images of a cluster.
+type clusterCompliance struct {
+	// Compliant is the number of workload containers running allowed images, including the ones with a LOW violation.
+	Compliant int `json:"compliant"`
+	// Violations are sorted by severity, namespace and workload.
+	Violations []imageViolation `json:"violations"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// imageCompliance is the response of checkImageCompliance.
+type imageCompliance struct {
+	Allowlist []string                      `json:"allowlist"`
+	Clusters  map[string]*clusterCompliance `json:"clusters"`
+	// Summary contains the number of violations of each severity in all the clusters.
+	Summary map[string]int `json:"summary"`
+}
+
+// checkImageCompliance compares the images used across the specified clusters with the image allowlist of the
+// server, and reports the workloads running images that aren't allowed with a severity. If no clusters are provided,
+// it checks all available clusters.
+func (t *Tools) checkImageCompliance(ctx context.Context, toolReq *mcp.CallToolRequest, params checkImageComplianceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("checkImageCompliance called")
+
+	if len(t.ImageAllowlist) == 0 {
+		return nil, nil, fmt.Errorf("no image allowlist is configured in the server, it's set with --image-allowlist")
+	}
+	minSeverity := strings.ToUpper(cmp.Or(params.MinSeverity, complianceLow))
+	if !slices.Contains(complianceSeverities, minSeverity) {
+		return nil, nil, fmt.Errorf("invalid minSeverity %q, must be one of %s", params.MinSeverity, strings.Join(complianceSeverities, ", "))
+	}
+	listedSeverities := complianceSeverities[:slices.Index(complianceSeverities, minSeverity)+1]
+
+	result := imageCompliance{
+		Allowlist: t.ImageAllowlist,
+		Clusters:  map[string]*clusterCompliance{},
+		Summary:   map[string]int{},
+	}
+	for _, severity := range listedSeverities {
+		result.Summary[severity] = 0
+	}
+	failedClusters, err := t.collectClusterImages(ctx, toolReq, getClusterImagesParams{
+		Clusters:  params.Clusters,
+		Namespace: params.Namespace,
+	}, func(cluster string, images []containerImage) {
+		compliance := &clusterCompliance{Violations: []imageViolation{}}
+		for _, image := range images {
+			severity, reason := imageAllowed(t.ImageAllowlist, image)
+			if severity == "" {
+				compliance.Compliant++
+				continue
+			}
+			if severity == complianceLow {
+				// the image is allowed, the tag is only a risk
+				compliance.Compliant++
+			}
+			if !slices.Contains(listedSeverities, severity) {
+				continue
+			}
+			compliance.Violations = append(compliance.Violations, imageViolation{containerImage: image, Severity: severity, Reason: reason})
+			result.Summary[severity]++
+		}
+		slices.SortStableFunc(compliance.Violations, func(a, b imageViolation) int {
+			return cmp.Compare(slices.Index(complianceSeverities, a.Severity), slices.Index(complianceSeverities, b.Severity))
+		})
+		result.Clusters[cluster] = compliance
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to collect images", zap.String("tool", "checkImageCompliance"), zap.Error(err))
+		return nil, nil, err
+	}
+	for cluster, err := range failedClusters {
+		result.Clusters[cluster] = &clusterCompliance{Violations: []imageViolation{}, Error: err.Error()}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "checkImageCompliance"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// imageAllowed returns the severity of the violation of the image and its reason, or an empty severity if the image
+// complies with the allowlist. An entry without a "/" allows the images of the registry (e.g. registry.rancher.com),
+// an entry ending with "*" allows the repositories starting with it (e.g. docker.io/rancher/*), and the other entries
+// allow a single repository (e.g. docker.io/library/nginx, or docker.io/nginx for short).
+func imageAllowed(allowlist []string, image containerImage) (string, string) {
+	registryAllowed := false
+	for _, entry := range allowlist {
+		registry, repository, _ := strings.Cut(strings.TrimSpace(entry), "/")
+		if normalizeRegistry(registry) != image.Registry {
+			continue
+		}
+		registryAllowed = true
+		if !repositoryAllowed(registry, repository, image.Repository) {
+			continue
+		}
+		// the digest of the running image is known, but not the one the next pods will pull
+		if image.Tag == "latest" && !strings.Contains(image.Image, "@") {
+			return complianceLow, "the image is allowed, but the latest tag can change to an image that wasn't reviewed, use a version tag or a digest"
+		}
+		return "", ""
+	}
+
+	if registryAllowed {
+		return complianceMedium, fmt.Sprintf("the repository %s isn't allowed in the registry %s", image.Repository, image.Registry)
+	}
+
+	return complianceHigh, fmt.Sprintf("the registry %s isn't allowed", image.Registry)
+}
+
+// repositoryAllowed reports whether the repository of an allowlist entry of the registry allows the repository of an
+// image. An empty one allows all the repositories.
+func repositoryAllowed(registry string, allowed string, repository string) bool {
+	if allowed == "" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(allowed, imageAnySuffix); ok {
+		return strings.HasPrefix(repository, prefix)
+	}
+	// the official images of Docker Hub are in the library namespace, like in parseImageReference
+	if normalizeRegistry(registry) == defaultRegistry && !strings.Contains(allowed, "/") {
+		allowed = "library/" + allowed
+	}
+
+	return repository == allowed
+}
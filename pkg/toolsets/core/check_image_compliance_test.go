@@ -0,0 +1,116 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestCheckImageCompliance(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "pods"}: "PodList",
+	}
+
+	tests := map[string]struct {
+		allowlist      []string
+		params         checkImageComplianceParams
+		expectedResult string
+		expectedError  string
+	}{
+		"allowed repositories": {
+			allowlist: []string{"docker.io/nginx", "docker.io/library/busybox"},
+			params:    checkImageComplianceParams{Clusters: []string{"local"}},
+			expectedResult: `{
+				"allowlist": ["docker.io/nginx", "docker.io/library/busybox"],
+				"clusters": {
+					"local": {
+						"compliant": 2,
+						"violations": [
+							{
+								"image": "redis:alpine", "registry": "docker.io", "repository": "library/redis", "tag": "alpine", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "sidecar-container", "pods": 1,
+								"severity": "MEDIUM", "reason": "the repository library/redis isn't allowed in the registry docker.io"
+							},
+							{
+								"image": "busybox:latest", "registry": "docker.io", "repository": "library/busybox", "tag": "latest", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "init-container", "pods": 1,
+								"severity": "LOW", "reason": "the image is allowed, but the latest tag can change to an image that wasn't reviewed, use a version tag or a digest"
+							}
+						]
+					}
+				},
+				"summary": {"HIGH": 0, "MEDIUM": 1, "LOW": 1}
+			}`,
+		},
+		"registry not allowed": {
+			allowlist: []string{"registry.rancher.com"},
+			params:    checkImageComplianceParams{Clusters: []string{"local"}, MinSeverity: "high"},
+			expectedResult: `{
+				"allowlist": ["registry.rancher.com"],
+				"clusters": {
+					"local": {
+						"compliant": 0,
+						"violations": [
+							{
+								"image": "nginx:1.21", "registry": "docker.io", "repository": "library/nginx", "tag": "1.21", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "app-container", "pods": 1,
+								"severity": "HIGH", "reason": "the registry docker.io isn't allowed"
+							},
+							{
+								"image": "busybox:latest", "registry": "docker.io", "repository": "library/busybox", "tag": "latest", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "init-container", "pods": 1,
+								"severity": "HIGH", "reason": "the registry docker.io isn't allowed"
+							},
+							{
+								"image": "redis:alpine", "registry": "docker.io", "repository": "library/redis", "tag": "alpine", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "sidecar-container", "pods": 1,
+								"severity": "HIGH", "reason": "the registry docker.io isn't allowed"
+							}
+						]
+					}
+				},
+				"summary": {"HIGH": 3}
+			}`,
+		},
+		"repository prefix allowed": {
+			allowlist: []string{"index.docker.io/library/*"},
+			params:    checkImageComplianceParams{Clusters: []string{"local"}, MinSeverity: "MEDIUM"},
+			expectedResult: `{
+				"allowlist": ["index.docker.io/library/*"],
+				"clusters": {"local": {"compliant": 3, "violations": []}},
+				"summary": {"HIGH": 0, "MEDIUM": 0}
+			}`,
+		},
+		"no allowlist": {
+			params:        checkImageComplianceParams{Clusters: []string{"local"}},
+			expectedError: "no image allowlist is configured in the server",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), listKinds, fakePodWithImage), nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken), ImageAllowlist: test.allowlist}
+
+			result, _, err := tools.checkImageCompliance(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
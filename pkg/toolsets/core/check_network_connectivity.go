@@ -0,0 +1,462 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// projectNetworkPolicyNames are the names of the NetworkPolicies created by Rancher in the namespaces of the
+// Projects with network isolation: np-default allows the traffic between the namespaces of the Project, and hn-nodes
+// allows the traffic from the nodes.
+var projectNetworkPolicyNames = []string{"np-default", "hn-nodes"}
+
+type checkNetworkConnectivityParams struct {
+	Cluster              string `json:"cluster" jsonschema:"the cluster of the workloads"`
+	SourceKind           string `json:"sourceKind" jsonschema:"the kind of the source workload (e.g. Deployment, StatefulSet, Pod)"`
+	SourceName           string `json:"sourceName" jsonschema:"the name of the source workload"`
+	SourceNamespace      string `json:"sourceNamespace" jsonschema:"the namespace of the source workload"`
+	DestinationKind      string `json:"destinationKind" jsonschema:"the kind of the destination workload (e.g. Deployment, StatefulSet, Pod)"`
+	DestinationName      string `json:"destinationName" jsonschema:"the name of the destination workload"`
+	DestinationNamespace string `json:"destinationNamespace" jsonschema:"the namespace of the destination workload"`
+	Port                 string `json:"port,omitempty" jsonschema:"the destination port number or name. Empty for any port"`
+	Protocol             string `json:"protocol,omitempty" jsonschema:"the protocol: TCP, UDP or SCTP. Defaults to TCP" validate:"enum=TCP|UDP|SCTP"`
+}
+
+// connectivityEndpoint holds the labels of the Pods of a workload and of their namespace.
+type connectivityEndpoint struct {
+	Kind            string            `json:"kind"`
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	Labels          map[string]string `json:"labels"`
+	namespaceLabels map[string]string
+	containers      []corev1.Container
+}
+
+// connectivityPolicy is a NetworkPolicy selecting the Pods of a workload.
+type connectivityPolicy struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// ProjectNetworkPolicy is true for the policies created by Rancher for the Project network isolation.
+	ProjectNetworkPolicy bool `json:"projectNetworkPolicy,omitempty"`
+	// Allows is true if a rule of the policy allows the traffic.
+	Allows bool `json:"allows"`
+	// Ports are the ports allowed by the rules matching the peer, when no port is given. Empty means all ports.
+	Ports []string `json:"ports,omitempty"`
+}
+
+// connectivityDirection is the result of the evaluation of the egress policies of the source or the ingress
+// policies of the destination.
+type connectivityDirection struct {
+	// Isolated is true if at least one policy selects the Pods for this direction. Otherwise, all traffic is allowed.
+	Isolated bool                 `json:"isolated"`
+	Allowed  bool                 `json:"allowed"`
+	Policies []connectivityPolicy `json:"policies"`
+}
+
+// networkConnectivity is the result of checkNetworkConnectivity.
+type networkConnectivity struct {
+	Source      connectivityEndpoint  `json:"source"`
+	Destination connectivityEndpoint  `json:"destination"`
+	Port        string                `json:"port,omitempty"`
+	Protocol    string                `json:"protocol"`
+	Allowed     bool                  `json:"allowed"`
+	Egress      connectivityDirection `json:"egress"`
+	Ingress     connectivityDirection `json:"ingress"`
+	Notes       []string              `json:"notes,omitempty"`
+}
+
+// destinationPort is the port the traffic is sent to. Number is 0 if no port is given.
+type destinationPort struct {
+	number   int32
+	name     string
+	protocol corev1.Protocol
+}
+
+// checkNetworkConnectivity evaluates the NetworkPolicies selecting the Pods of a source and a destination workload
+// to determine whether the traffic from the source to the destination is allowed.
+func (t *Tools) checkNetworkConnectivity(ctx context.Context, toolReq *mcp.CallToolRequest, params checkNetworkConnectivityParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("checkNetworkConnectivity called")
+
+	protocol := corev1.Protocol(strings.ToUpper(params.Protocol))
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	if !slices.Contains([]corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP}, protocol) {
+		return nil, nil, fmt.Errorf("invalid protocol %q, must be TCP, UDP or SCTP", params.Protocol)
+	}
+
+	source, err := t.getConnectivityEndpoint(ctx, toolReq, params.Cluster, params.SourceKind, params.SourceName, params.SourceNamespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get source workload", zap.String("tool", "checkNetworkConnectivity"), zap.Error(err))
+		return nil, nil, err
+	}
+	destination, err := t.getConnectivityEndpoint(ctx, toolReq, params.Cluster, params.DestinationKind, params.DestinationName, params.DestinationNamespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get destination workload", zap.String("tool", "checkNetworkConnectivity"), zap.Error(err))
+		return nil, nil, err
+	}
+	port, err := resolveDestinationPort(params.Port, protocol, destination.containers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	egressPolicies, err := t.getNetworkPolicies(ctx, toolReq, params.Cluster, source.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get network policies", zap.String("tool", "checkNetworkConnectivity"), zap.Error(err))
+		return nil, nil, err
+	}
+	ingressPolicies, err := t.getNetworkPolicies(ctx, toolReq, params.Cluster, destination.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get network policies", zap.String("tool", "checkNetworkConnectivity"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := networkConnectivity{
+		Source:      *source,
+		Destination: *destination,
+		Port:        params.Port,
+		Protocol:    string(protocol),
+	}
+	result.Egress = evaluateNetworkPolicies(egressPolicies, networkingv1.PolicyTypeEgress, source, destination, port, &result.Notes)
+	result.Ingress = evaluateNetworkPolicies(ingressPolicies, networkingv1.PolicyTypeIngress, destination, source, port, &result.Notes)
+	result.Allowed = result.Egress.Allowed && result.Ingress.Allowed
+	if port.number == 0 {
+		result.Notes = append(result.Notes, "no port was given, the ports allowed by each policy are listed in its ports field")
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "checkNetworkConnectivity"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// getConnectivityEndpoint returns the labels of the Pods of a workload, taken from its Pod template, and the labels
+// of its namespace.
+func (t *Tools) getConnectivityEndpoint(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, kind string, name string, namespace string) (*connectivityEndpoint, error) {
+	obj, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      strings.ToLower(kind),
+		Namespace: namespace,
+		Name:      name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	template, err := podTemplate(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	ns, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: cluster,
+		Kind:    "namespace",
+		Name:    namespace,
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	namespaceLabels := ns.GetLabels()
+	if namespaceLabels == nil {
+		namespaceLabels = map[string]string{}
+	}
+	// set by the API server since Kubernetes 1.21, added in case it is missing
+	namespaceLabels[corev1.LabelMetadataName] = namespace
+
+	return &connectivityEndpoint{
+		Kind:            obj.GetKind(),
+		Name:            name,
+		Namespace:       namespace,
+		Labels:          template.Labels,
+		namespaceLabels: namespaceLabels,
+		containers:      template.Spec.Containers,
+	}, nil
+}
+
+// podTemplate returns the Pod template of a workload, or the Pod itself.
+func podTemplate(obj *unstructured.Unstructured) (*corev1.PodTemplateSpec, error) {
+	var path []string
+	switch obj.GetKind() {
+	case "Pod":
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template"}
+	default:
+		path = []string{"spec", "template"}
+	}
+
+	templateObj := obj.Object
+	if len(path) > 0 {
+		var found bool
+		var err error
+		templateObj, found, err = unstructured.NestedMap(obj.Object, path...)
+		if err != nil || !found {
+			return nil, fmt.Errorf("%s %s doesn't have a Pod template", obj.GetKind(), obj.GetName())
+		}
+	}
+	var template corev1.PodTemplateSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateObj, &template); err != nil {
+		return nil, fmt.Errorf("failed to convert unstructured object to PodTemplateSpec: %w", err)
+	}
+
+	return &template, nil
+}
+
+func (t *Tools) getNetworkPolicies(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string) ([]networkingv1.NetworkPolicy, error) {
+	resources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   cluster,
+		Kind:      "networkpolicy",
+		Namespace: namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network policies: %w", err)
+	}
+
+	policies := make([]networkingv1.NetworkPolicy, 0, len(resources))
+	for _, resource := range resources {
+		var policy networkingv1.NetworkPolicy
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, &policy); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to NetworkPolicy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	slices.SortFunc(policies, func(a, b networkingv1.NetworkPolicy) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return policies, nil
+}
+
+// resolveDestinationPort returns the number of the given port, looking up named ports in the containers of the
+// destination.
+func resolveDestinationPort(port string, protocol corev1.Protocol, containers []corev1.Container) (destinationPort, error) {
+	if port == "" {
+		return destinationPort{protocol: protocol}, nil
+	}
+
+	p := intstr.Parse(port)
+	if p.Type == intstr.Int {
+		return destinationPort{number: p.IntVal, protocol: protocol}, nil
+	}
+	number := namedPortNumber(p.StrVal, protocol, containers)
+	if number == 0 {
+		return destinationPort{}, fmt.Errorf("the destination doesn't have a %s port named %s", protocol, p.StrVal)
+	}
+
+	return destinationPort{number: number, name: p.StrVal, protocol: protocol}, nil
+}
+
+// namedPortNumber returns the number of a named container port, or 0 if it isn't found.
+func namedPortNumber(name string, protocol corev1.Protocol, containers []corev1.Container) int32 {
+	for _, container := range containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == name && defaultProtocol(containerPort.Protocol) == protocol {
+				return containerPort.ContainerPort
+			}
+		}
+	}
+
+	return 0
+}
+
+// defaultProtocol returns the protocol, or TCP if it isn't set.
+func defaultProtocol(protocol corev1.Protocol) corev1.Protocol {
+	if protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return protocol
+}
+
+// evaluateNetworkPolicies evaluates the policies of the given type selecting the Pods of target, which must be in
+// the namespace of the policies, to determine whether the traffic with peer is allowed. The ports of the rules are
+// compared with the port of the destination, whose containers are used to resolve named ports.
+func evaluateNetworkPolicies(policies []networkingv1.NetworkPolicy, policyType networkingv1.PolicyType, target *connectivityEndpoint,
+	peer *connectivityEndpoint, port destinationPort, notes *[]string) connectivityDirection {
+	destination := peer
+	if policyType == networkingv1.PolicyTypeIngress {
+		destination = target
+	}
+
+	direction := connectivityDirection{Policies: []connectivityPolicy{}}
+	for _, policy := range policies {
+		if !hasPolicyType(policy, policyType) || !selectorMatches(&policy.Spec.PodSelector, target.Labels) {
+			continue
+		}
+		direction.Isolated = true
+
+		result := connectivityPolicy{
+			Name:                 policy.Name,
+			Namespace:            policy.Namespace,
+			ProjectNetworkPolicy: slices.Contains(projectNetworkPolicyNames, policy.Name),
+		}
+		allPorts := false
+		for _, rule := range policyRules(policy, policyType) {
+			if !peersMatch(rule.peers, policy.Namespace, peer) {
+				note := fmt.Sprintf("the ipBlock peers of the %s policy %s/%s are not evaluated, they usually match traffic from or to outside the cluster",
+					strings.ToLower(string(policyType)), policy.Namespace, policy.Name)
+				if slices.ContainsFunc(rule.peers, func(p networkingv1.NetworkPolicyPeer) bool { return p.IPBlock != nil }) && !slices.Contains(*notes, note) {
+					*notes = append(*notes, note)
+				}
+				continue
+			}
+			if !portsMatch(rule.ports, port, destination.containers) {
+				continue
+			}
+			result.Allows = true
+			allPorts = allPorts || len(rule.ports) == 0
+			if port.number == 0 {
+				for _, p := range rule.ports {
+					result.Ports = append(result.Ports, formatPolicyPort(p))
+				}
+			}
+		}
+		if allPorts {
+			result.Ports = nil
+		}
+		direction.Policies = append(direction.Policies, result)
+	}
+
+	direction.Allowed = !direction.Isolated || slices.ContainsFunc(direction.Policies, func(p connectivityPolicy) bool { return p.Allows })
+
+	return direction
+}
+
+// hasPolicyType returns whether the policy applies to the given type of traffic. Policies without policyTypes
+// always apply to ingress traffic, and to egress traffic if they have egress rules.
+func hasPolicyType(policy networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	if len(policy.Spec.PolicyTypes) > 0 {
+		return slices.Contains(policy.Spec.PolicyTypes, policyType)
+	}
+
+	return policyType == networkingv1.PolicyTypeIngress || len(policy.Spec.Egress) > 0
+}
+
+// policyRule holds the peers and ports of an ingress or egress rule.
+type policyRule struct {
+	peers []networkingv1.NetworkPolicyPeer
+	ports []networkingv1.NetworkPolicyPort
+}
+
+func policyRules(policy networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) []policyRule {
+	var rules []policyRule
+	if policyType == networkingv1.PolicyTypeIngress {
+		for _, rule := range policy.Spec.Ingress {
+			rules = append(rules, policyRule{peers: rule.From, ports: rule.Ports})
+		}
+	} else {
+		for _, rule := range policy.Spec.Egress {
+			rules = append(rules, policyRule{peers: rule.To, ports: rule.Ports})
+		}
+	}
+
+	return rules
+}
+
+// peersMatch returns whether one of the peers of a rule of a policy in the given namespace matches the endpoint.
+// Empty peers match all endpoints. IP blocks are not evaluated, as the IPs of the Pods change.
+func peersMatch(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, endpoint *connectivityEndpoint) bool {
+	if len(peers) == 0 {
+		return true
+	}
+
+	for _, peer := range peers {
+		if peer.PodSelector == nil && peer.NamespaceSelector == nil {
+			continue
+		}
+		namespaceMatches := endpoint.Namespace == policyNamespace
+		if peer.NamespaceSelector != nil {
+			namespaceMatches = selectorMatches(peer.NamespaceSelector, endpoint.namespaceLabels)
+		}
+		podMatches := peer.PodSelector == nil || selectorMatches(peer.PodSelector, endpoint.Labels)
+		if namespaceMatches && podMatches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// portsMatch returns whether one of the ports of a rule matches the destination port. Empty ports match all ports,
+// and all rules' ports match when no destination port is given.
+func portsMatch(ports []networkingv1.NetworkPolicyPort, port destinationPort, containers []corev1.Container) bool {
+	if len(ports) == 0 || port.number == 0 {
+		return true
+	}
+
+	for _, p := range ports {
+		protocol := corev1.ProtocolTCP
+		if p.Protocol != nil {
+			protocol = *p.Protocol
+		}
+		if protocol != port.protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true
+		}
+		if p.Port.Type == intstr.String {
+			if p.Port.StrVal == port.name || namedPortNumber(p.Port.StrVal, protocol, containers) == port.number {
+				return true
+			}
+			continue
+		}
+		endPort := p.Port.IntVal
+		if p.EndPort != nil {
+			endPort = *p.EndPort
+		}
+		if port.number >= p.Port.IntVal && port.number <= endPort {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatPolicyPort returns a port of a rule like 8080/TCP, 8000-8999/TCP or all/UDP.
+func formatPolicyPort(p networkingv1.NetworkPolicyPort) string {
+	protocol := corev1.ProtocolTCP
+	if p.Protocol != nil {
+		protocol = *p.Protocol
+	}
+	switch {
+	case p.Port == nil:
+		return "all/" + string(protocol)
+	case p.EndPort != nil:
+		return fmt.Sprintf("%s-%d/%s", p.Port.String(), *p.EndPort, protocol)
+	default:
+		return p.Port.String() + "/" + string(protocol)
+	}
+}
+
+// selectorMatches returns whether the labels match the selector. An invalid selector doesn't match any labels.
+func selectorMatches(selector *metav1.LabelSelector, labels map[string]string) bool {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(k8slabels.Set(labels))
+}
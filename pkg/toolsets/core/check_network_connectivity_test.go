@@ -0,0 +1,258 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func networkScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+	return scheme
+}
+
+func newConnectivityNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}
+}
+
+func newConnectivityDeployment(name string, namespace string, ports ...corev1.ContainerPort) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Ports: ports}}},
+			},
+		},
+	}
+}
+
+func newNetworkPolicy(name string, namespace string, spec networkingv1.NetworkPolicySpec) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       spec,
+	}
+}
+
+func TestCheckNetworkConnectivity(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	workloads := []runtime.Object{
+		newConnectivityNamespace("frontend", map[string]string{"team": "web"}),
+		newConnectivityNamespace("backend", map[string]string{"field.cattle.io/projectId": "p-backend"}),
+		newConnectivityDeployment("web", "frontend"),
+		newConnectivityDeployment("api", "backend", corev1.ContainerPort{Name: "http", ContainerPort: 8080}),
+	}
+	denyIngress := newNetworkPolicy("deny-ingress", "backend", networkingv1.NetworkPolicySpec{
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	})
+	allowWeb := newNetworkPolicy("allow-web", "backend", networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			}},
+			Ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromString("http"))}},
+		}},
+	})
+	projectIsolation := newNetworkPolicy("np-default", "backend", networkingv1.NetworkPolicySpec{
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"field.cattle.io/projectId": "p-backend"}},
+			}},
+		}},
+	})
+	denyEgress := newNetworkPolicy("deny-egress", "frontend", networkingv1.NetworkPolicySpec{
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+	})
+	allowExternal := newNetworkPolicy("allow-external", "frontend", networkingv1.NetworkPolicySpec{
+		Egress: []networkingv1.NetworkPolicyEgressRule{{
+			To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0"}}},
+		}},
+	})
+
+	tests := map[string]struct {
+		params          checkNetworkConnectivityParams
+		policies        []runtime.Object
+		expectedAllowed bool
+		expectedEgress  connectivityDirection
+		expectedIngress connectivityDirection
+		expectedNotes   []string
+		expectedError   string
+	}{
+		"no policies": {
+			params:          checkNetworkConnectivityParams{Port: "8080"},
+			expectedAllowed: true,
+			expectedEgress:  connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedIngress: connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+		},
+		"ingress denied": {
+			params:         checkNetworkConnectivityParams{Port: "8080"},
+			policies:       []runtime.Object{denyIngress},
+			expectedEgress: connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedIngress: connectivityDirection{Isolated: true, Policies: []connectivityPolicy{
+				{Name: "deny-ingress", Namespace: "backend"},
+			}},
+		},
+		"ingress allowed on named port": {
+			params:          checkNetworkConnectivityParams{Port: "8080"},
+			policies:        []runtime.Object{denyIngress, allowWeb},
+			expectedAllowed: true,
+			expectedEgress:  connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedIngress: connectivityDirection{Isolated: true, Allowed: true, Policies: []connectivityPolicy{
+				{Name: "allow-web", Namespace: "backend", Allows: true},
+				{Name: "deny-ingress", Namespace: "backend"},
+			}},
+		},
+		"ingress denied on another port": {
+			params:         checkNetworkConnectivityParams{Port: "9090"},
+			policies:       []runtime.Object{denyIngress, allowWeb},
+			expectedEgress: connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedIngress: connectivityDirection{Isolated: true, Policies: []connectivityPolicy{
+				{Name: "allow-web", Namespace: "backend"},
+				{Name: "deny-ingress", Namespace: "backend"},
+			}},
+		},
+		"ingress allowed without port": {
+			policies:        []runtime.Object{allowWeb},
+			expectedAllowed: true,
+			expectedEgress:  connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedIngress: connectivityDirection{Isolated: true, Allowed: true, Policies: []connectivityPolicy{
+				{Name: "allow-web", Namespace: "backend", Allows: true, Ports: []string{"http/TCP"}},
+			}},
+			expectedNotes: []string{"no port was given, the ports allowed by each policy are listed in its ports field"},
+		},
+		"ingress denied by project network isolation": {
+			params:         checkNetworkConnectivityParams{Port: "http"},
+			policies:       []runtime.Object{projectIsolation},
+			expectedEgress: connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedIngress: connectivityDirection{Isolated: true, Policies: []connectivityPolicy{
+				{Name: "np-default", Namespace: "backend", ProjectNetworkPolicy: true},
+			}},
+		},
+		"egress denied": {
+			params:   checkNetworkConnectivityParams{Port: "8080"},
+			policies: []runtime.Object{denyEgress, allowExternal},
+			expectedEgress: connectivityDirection{Isolated: true, Policies: []connectivityPolicy{
+				{Name: "allow-external", Namespace: "frontend"},
+				{Name: "deny-egress", Namespace: "frontend"},
+			}},
+			expectedIngress: connectivityDirection{Allowed: true, Policies: []connectivityPolicy{}},
+			expectedNotes: []string{
+				"the ipBlock peers of the egress policy frontend/allow-external are not evaluated, they usually match traffic from or to outside the cluster",
+			},
+		},
+		"unknown named port": {
+			params:        checkNetworkConnectivityParams{Port: "grpc"},
+			expectedError: "the destination doesn't have a TCP port named grpc",
+		},
+		"invalid protocol": {
+			params:        checkNetworkConnectivityParams{Protocol: "ICMP"},
+			expectedError: `invalid protocol "ICMP", must be TCP, UDP or SCTP`,
+		},
+		"source not found": {
+			params:        checkNetworkConnectivityParams{SourceName: "missing"},
+			expectedError: "failed to get Deployment missing",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(networkScheme(), append(workloads, test.policies...)...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+			params := test.params
+			params.Cluster = "local"
+			params.SourceKind, params.SourceNamespace = "Deployment", "frontend"
+			if params.SourceName == "" {
+				params.SourceName = "web"
+			}
+			params.DestinationKind, params.DestinationName, params.DestinationNamespace = "Deployment", "api", "backend"
+
+			result, _, err := tools.checkNetworkConnectivity(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var connectivity networkConnectivity
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &connectivity))
+			assert.Equal(t, test.expectedAllowed, connectivity.Allowed)
+			assert.Equal(t, test.expectedEgress, connectivity.Egress)
+			assert.Equal(t, test.expectedIngress, connectivity.Ingress)
+			assert.Equal(t, test.expectedNotes, connectivity.Notes)
+			assert.Equal(t, connectivityEndpoint{Kind: "Deployment", Name: "web", Namespace: "frontend", Labels: map[string]string{"app": "web"}}, connectivity.Source)
+		})
+	}
+}
+
+func TestPortsMatch(t *testing.T) {
+	containers := []corev1.Container{{Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9100}}}}
+
+	tests := map[string]struct {
+		ports    []networkingv1.NetworkPolicyPort
+		port     destinationPort
+		expected bool
+	}{
+		"all ports": {
+			port:     destinationPort{number: 80, protocol: corev1.ProtocolTCP},
+			expected: true,
+		},
+		"port in range": {
+			ports:    []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromInt32(8000)), EndPort: ptr.To(int32(8999))}},
+			port:     destinationPort{number: 8443, protocol: corev1.ProtocolTCP},
+			expected: true,
+		},
+		"port out of range": {
+			ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromInt32(8000)), EndPort: ptr.To(int32(8999))}},
+			port:  destinationPort{number: 9000, protocol: corev1.ProtocolTCP},
+		},
+		"other protocol": {
+			ports: []networkingv1.NetworkPolicyPort{{Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(intstr.FromInt32(53))}},
+			port:  destinationPort{number: 53, protocol: corev1.ProtocolTCP},
+		},
+		"named port of the destination": {
+			ports:    []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromString("metrics"))}},
+			port:     destinationPort{number: 9100, protocol: corev1.ProtocolTCP},
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, portsMatch(test.ports, test.port, containers))
+		})
+	}
+}
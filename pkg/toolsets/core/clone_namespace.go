@@ -0,0 +1,346 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// maxClonedResources is the maximum number of resources created by cloneNamespace in a call.
+	maxClonedResources = 200
+	// secretsSkip, secretsCopy and secretsRedacted are the ways cloneNamespace handles the Secrets.
+	secretsSkip     = "skip"
+	secretsCopy     = "copy"
+	secretsRedacted = "redacted"
+)
+
+// defaultClonedKinds are the kinds cloned when no kinds are given, in the order they are created so the
+// configuration of the workloads exists before them.
+var defaultClonedKinds = []string{"configmap", "serviceaccount", "service", "deployment", "statefulset", "daemonset", "cronjob", "ingress"}
+
+type cloneNamespaceParams struct {
+	Cluster         string   `json:"cluster" jsonschema:"the cluster of the namespace cloned"`
+	Namespace       string   `json:"namespace" jsonschema:"the namespace cloned" validate:"required"`
+	TargetCluster   string   `json:"targetCluster,omitempty" jsonschema:"the cluster the namespace is cloned to. Defaults to the cluster of the namespace"`
+	TargetNamespace string   `json:"targetNamespace" jsonschema:"the namespace the resources are cloned to, created if it doesn't exist" validate:"required"`
+	Kinds           []string `json:"kinds,omitempty" jsonschema:"the kinds of the resources cloned"`
+	LabelSelector   string   `json:"labelSelector,omitempty" jsonschema:"the label selector the resources cloned must match"`
+	NameSuffix      string   `json:"nameSuffix,omitempty" jsonschema:"the suffix added to the names of the resources cloned, and to the references between them"`
+	Secrets         string   `json:"secrets,omitempty" jsonschema:"how the Secrets are cloned: skip, copy with their values, or redacted with their keys and empty values. Defaults to skip"`
+	DryRun          bool     `json:"dryRun,omitempty" jsonschema:"list the resources that would be created without creating them"`
+}
+
+// cloneResult lists the resources created in the target namespace by cloneNamespace, and the ones left out.
+type cloneResult struct {
+	DryRun           bool             `json:"dryRun,omitempty"`
+	Cluster          string           `json:"cluster"`
+	Namespace        string           `json:"namespace"`
+	TargetCluster    string           `json:"targetCluster"`
+	TargetNamespace  string           `json:"targetNamespace"`
+	NamespaceCreated bool             `json:"namespaceCreated"`
+	Cloned           []clonedObject   `json:"cloned"`
+	Skipped          []exportedObject `json:"skipped,omitempty"`
+	Failed           []clonedObject   `json:"failed,omitempty"`
+	Notes            []string         `json:"notes,omitempty"`
+}
+
+// clonedObject is a resource created in the target namespace, with the name of the resource it's a copy of.
+type clonedObject struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	SourceName string `json:"sourceName"`
+	Error      string `json:"error,omitempty"`
+}
+
+// cloneNamespace copies the workloads, ConfigMaps, Services and optionally the Secrets of a namespace to another
+// namespace of the same or another cluster, to spin up a copy of an environment. The resources are sanitized like
+// the ones of exportResources, and renamed with the suffix along with the references between them. The target
+// namespace is created if it doesn't exist, and a resource that can't be created doesn't stop the others.
+func (t *Tools) cloneNamespace(ctx context.Context, toolReq *mcp.CallToolRequest, params cloneNamespaceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("cloneNamespace called")
+
+	targetCluster := params.TargetCluster
+	if targetCluster == "" {
+		targetCluster = params.Cluster
+	}
+	if targetCluster == params.Cluster && params.TargetNamespace == params.Namespace {
+		return nil, nil, fmt.Errorf("the target namespace must be different from the namespace cloned, or in another cluster")
+	}
+	secrets := params.Secrets
+	if secrets == "" {
+		secrets = secretsSkip
+	}
+	if !slices.Contains([]string{secretsSkip, secretsCopy, secretsRedacted}, secrets) {
+		return nil, nil, fmt.Errorf("invalid secrets %q, must be skip, copy or redacted", params.Secrets)
+	}
+	kinds := params.Kinds
+	if len(kinds) == 0 {
+		kinds = defaultClonedKinds
+	}
+	kinds = slices.DeleteFunc(slices.Clone(kinds), func(kind string) bool { return strings.EqualFold(kind, "secret") })
+	if secrets != secretsSkip {
+		// the Secrets are created first, so they exist when the workloads mounting them start
+		kinds = append([]string{"secret"}, kinds...)
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	result := cloneResult{
+		DryRun:          params.DryRun,
+		Cluster:         params.Cluster,
+		Namespace:       params.Namespace,
+		TargetCluster:   targetCluster,
+		TargetNamespace: params.TargetNamespace,
+		Cloned:          []clonedObject{},
+	}
+	var objs []*unstructured.Unstructured
+	for _, kind := range kinds {
+		resources, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:       params.Cluster,
+			Kind:          strings.ToLower(kind),
+			Namespace:     params.Namespace,
+			URL:           url,
+			Token:         token,
+			LabelSelector: params.LabelSelector,
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list resources", zap.String("tool", "cloneNamespace"), zap.String("kind", kind), zap.Error(err))
+			return nil, nil, err
+		}
+		slices.SortFunc(resources, func(a, b *unstructured.Unstructured) int {
+			return strings.Compare(a.GetName(), b.GetName())
+		})
+		for _, obj := range resources {
+			if reason := exportSkipReason(obj); reason != "" {
+				result.Skipped = append(result.Skipped, exportedObject{Kind: obj.GetKind(), Name: obj.GetName(), Reason: reason})
+				continue
+			}
+			objs = append(objs, obj)
+		}
+	}
+	if len(objs) > maxClonedResources {
+		return nil, nil, fmt.Errorf("%d resources to clone, more than the %d cloned in a call. Use a label selector or less kinds to clone less resources", len(objs), maxClonedResources)
+	}
+
+	renamed := clonedNames(objs, params.NameSuffix)
+	var redactedSecrets []string
+	for _, obj := range objs {
+		sourceName := obj.GetName()
+		sanitizeExportedObject(obj)
+		rewriteReferences(obj, renamed)
+		obj.SetName(renamed.name(obj.GetKind(), sourceName))
+		obj.SetNamespace(params.TargetNamespace)
+		if obj.GetKind() == "Secret" && secrets == secretsRedacted {
+			emptySecretValues(obj)
+			redactedSecrets = append(redactedSecrets, obj.GetName())
+		}
+		result.Cloned = append(result.Cloned, clonedObject{Kind: obj.GetKind(), Name: obj.GetName(), SourceName: sourceName})
+	}
+	if len(redactedSecrets) > 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf("the values of the Secrets %s are empty, set them in the target namespace", strings.Join(redactedSecrets, ", ")))
+	}
+	if slices.ContainsFunc(objs, func(obj *unstructured.Unstructured) bool { return obj.GetKind() == "Ingress" }) {
+		result.Notes = append(result.Notes, "the Ingresses keep the hosts of the namespace cloned, change them so they don't route the same hosts")
+	}
+
+	if !params.DryRun {
+		created, err := t.ensureNamespace(ctx, token, url, targetCluster, params.TargetNamespace)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create namespace", zap.String("tool", "cloneNamespace"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to create namespace %s: %w", params.TargetNamespace, err)
+		}
+		result.NamespaceCreated = created
+		cloned := result.Cloned
+		result.Cloned = []clonedObject{}
+		for i, obj := range objs {
+			if err := t.createClonedObject(ctx, token, url, targetCluster, obj); err != nil {
+				middleware.Logger(ctx).Error("failed to create resource", zap.String("tool", "cloneNamespace"), zap.String("name", obj.GetName()), zap.Error(err))
+				cloned[i].Error = err.Error()
+				result.Failed = append(result.Failed, cloned[i])
+				continue
+			}
+			result.Cloned = append(result.Cloned, cloned[i])
+		}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "cloneNamespace"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// ensureNamespace creates the namespace if it doesn't exist, and returns true if it was created.
+func (t *Tools) ensureNamespace(ctx context.Context, token string, url string, cluster string, namespace string) (bool, error) {
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, "", cluster, converter.K8sKindsToGVRs["namespace"])
+	if err != nil {
+		return false, err
+	}
+	_, err = resourceInterface.Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	ns := &unstructured.Unstructured{Object: map[string]any{"apiVersion": "v1", "kind": "Namespace"}}
+	ns.SetName(namespace)
+	if _, err := resourceInterface.Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (t *Tools) createClonedObject(ctx context.Context, token string, url string, cluster string, obj *unstructured.Unstructured) error {
+	gvr, err := t.client.ResolveGVR(ctx, token, url, cluster, strings.ToLower(obj.GetKind()))
+	if err != nil {
+		return err
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, obj.GetNamespace(), cluster, gvr)
+	if err != nil {
+		return err
+	}
+	_, err = resourceInterface.Create(ctx, obj, metav1.CreateOptions{})
+
+	return err
+}
+
+// clonedNameMap contains the names of the cloned resources by kind, to rewrite the references between them.
+type clonedNameMap struct {
+	suffix string
+	names  map[string]map[string]bool
+}
+
+func clonedNames(objs []*unstructured.Unstructured, suffix string) clonedNameMap {
+	renamed := clonedNameMap{suffix: suffix, names: map[string]map[string]bool{}}
+	for _, obj := range objs {
+		if renamed.names[obj.GetKind()] == nil {
+			renamed.names[obj.GetKind()] = map[string]bool{}
+		}
+		renamed.names[obj.GetKind()][obj.GetName()] = true
+	}
+
+	return renamed
+}
+
+// name returns the name of the clone of the resource. The references to resources that aren't cloned are kept.
+func (m clonedNameMap) name(kind string, name string) string {
+	if m.suffix == "" || !m.names[kind][name] {
+		return name
+	}
+
+	return name + m.suffix
+}
+
+// rewriteReferences renames the ConfigMaps, Secrets, ServiceAccounts, PersistentVolumeClaims and Services referenced
+// by the pod template of a workload or by an Ingress, when they are cloned too.
+func rewriteReferences(obj *unstructured.Unstructured, renamed clonedNameMap) {
+	if renamed.suffix == "" {
+		return
+	}
+	rename := func(kind string, m map[string]any, fields ...string) {
+		if name, ok, _ := unstructured.NestedString(m, fields...); ok {
+			_ = unstructured.SetNestedField(m, renamed.name(kind, name), fields...)
+		}
+	}
+	eachMap := func(m map[string]any, fn func(map[string]any), fields ...string) {
+		items, _, _ := unstructured.NestedSlice(m, fields...)
+		for _, item := range items {
+			if itemMap, ok := item.(map[string]any); ok {
+				fn(itemMap)
+			}
+		}
+		if items != nil {
+			_ = unstructured.SetNestedSlice(m, items, fields...)
+		}
+	}
+
+	switch obj.GetKind() {
+	case "Ingress":
+		rename("Service", obj.Object, "spec", "defaultBackend", "service", "name")
+		eachMap(obj.Object, func(tls map[string]any) { rename("Secret", tls, "secretName") }, "spec", "tls")
+		eachMap(obj.Object, func(rule map[string]any) {
+			eachMap(rule, func(path map[string]any) { rename("Service", path, "backend", "service", "name") }, "http", "paths")
+		}, "spec", "rules")
+		return
+	case "StatefulSet":
+		rename("Service", obj.Object, "spec", "serviceName")
+	}
+
+	podSpecFields := podSpecPath(obj.GetKind())
+	if podSpecFields == nil {
+		return
+	}
+	podSpec, ok, _ := unstructured.NestedMap(obj.Object, podSpecFields...)
+	if !ok {
+		return
+	}
+	rename("ServiceAccount", podSpec, "serviceAccountName")
+	eachMap(podSpec, func(secret map[string]any) { rename("Secret", secret, "name") }, "imagePullSecrets")
+	eachMap(podSpec, func(volume map[string]any) {
+		rename("ConfigMap", volume, "configMap", "name")
+		rename("Secret", volume, "secret", "secretName")
+		rename("PersistentVolumeClaim", volume, "persistentVolumeClaim", "claimName")
+		eachMap(volume, func(source map[string]any) {
+			rename("ConfigMap", source, "configMap", "name")
+			rename("Secret", source, "secret", "name")
+		}, "projected", "sources")
+	}, "volumes")
+	for _, containers := range []string{"initContainers", "containers"} {
+		eachMap(podSpec, func(container map[string]any) {
+			eachMap(container, func(env map[string]any) {
+				rename("ConfigMap", env, "valueFrom", "configMapKeyRef", "name")
+				rename("Secret", env, "valueFrom", "secretKeyRef", "name")
+			}, "env")
+			eachMap(container, func(envFrom map[string]any) {
+				rename("ConfigMap", envFrom, "configMapRef", "name")
+				rename("Secret", envFrom, "secretRef", "name")
+			}, "envFrom")
+		}, containers)
+	}
+	_ = unstructured.SetNestedMap(obj.Object, podSpec, podSpecFields...)
+}
+
+// podSpecPath returns the path of the pod spec of the Pods and of the pod template of the workloads, or nil for the
+// other kinds.
+func podSpecPath(kind string) []string {
+	switch kind {
+	case "Pod":
+		return []string{"spec"}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return []string{"spec", "template", "spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+
+	return nil
+}
+
+// emptySecretValues keeps the keys of the Secret with empty values, so they can be set in the target namespace.
+func emptySecretValues(obj *unstructured.Unstructured) {
+	data, _, _ := unstructured.NestedMap(obj.Object, "data")
+	for key := range data {
+		data[key] = ""
+	}
+	if data != nil {
+		_ = unstructured.SetNestedMap(obj.Object, data, "data")
+	}
+	unstructured.RemoveNestedField(obj.Object, "stringData")
+}
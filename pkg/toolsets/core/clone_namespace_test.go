@@ -0,0 +1,252 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newCloneFakeDynClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	objects = append(objects,
+		&corev1.Namespace{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}, ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "shop", UID: "1234", ResourceVersion: "42"},
+			Data:       map[string]string{"mode": "production"},
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.43.0.10",
+				Selector:  map[string]string{"app": "web"},
+				Ports:     []corev1.ServicePort{{Port: 80}},
+			},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "web",
+							Image: "nginx",
+							EnvFrom: []corev1.EnvFromSource{
+								{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}}},
+								{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "external"}}},
+							},
+						}},
+						Volumes: []corev1.Volume{{
+							Name:         "settings",
+							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}}},
+						}},
+					},
+				},
+			},
+		},
+		&networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "shop.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:    "/",
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+						}},
+					}},
+				}},
+			},
+		},
+	)
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "namespaces"}:                 "NamespaceList",
+		{Group: "", Version: "v1", Resource: "configmaps"}:                 "ConfigMapList",
+		{Group: "", Version: "v1", Resource: "secrets"}:                    "SecretList",
+		{Group: "", Version: "v1", Resource: "services"}:                   "ServiceList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}:            "DeploymentList",
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}: "IngressList",
+	}, objects...)
+}
+
+func TestCloneNamespace(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	kinds := []string{"configmap", "service", "deployment", "ingress"}
+
+	tests := map[string]struct {
+		params          cloneNamespaceParams
+		existingObjects []runtime.Object
+		expectedResult  string
+		// expectedObjects are the fields of the resources expected in the target namespace, by kind and name
+		expectedObjects map[string]map[string]string
+		expectedError   string
+	}{
+		"dry run": {
+			params: cloneNamespaceParams{Cluster: "local", Namespace: "shop", TargetNamespace: "shop-test", Kinds: kinds, NameSuffix: "-test", Secrets: "redacted", DryRun: true},
+			expectedResult: `{
+				"dryRun": true, "cluster": "local", "namespace": "shop", "targetCluster": "local", "targetNamespace": "shop-test", "namespaceCreated": false,
+				"cloned": [
+					{"kind": "Secret", "name": "db-test", "sourceName": "db"},
+					{"kind": "ConfigMap", "name": "settings-test", "sourceName": "settings"},
+					{"kind": "Service", "name": "web-test", "sourceName": "web"},
+					{"kind": "Deployment", "name": "web-test", "sourceName": "web"},
+					{"kind": "Ingress", "name": "web-test", "sourceName": "web"}
+				],
+				"notes": [
+					"the values of the Secrets db-test are empty, set them in the target namespace",
+					"the Ingresses keep the hosts of the namespace cloned, change them so they don't route the same hosts"
+				]
+			}`,
+		},
+		"clone with renamed references": {
+			params: cloneNamespaceParams{Cluster: "local", Namespace: "shop", TargetNamespace: "shop-test", Kinds: kinds, NameSuffix: "-test", Secrets: "copy"},
+			expectedResult: `{
+				"cluster": "local", "namespace": "shop", "targetCluster": "local", "targetNamespace": "shop-test", "namespaceCreated": true,
+				"cloned": [
+					{"kind": "Secret", "name": "db-test", "sourceName": "db"},
+					{"kind": "ConfigMap", "name": "settings-test", "sourceName": "settings"},
+					{"kind": "Service", "name": "web-test", "sourceName": "web"},
+					{"kind": "Deployment", "name": "web-test", "sourceName": "web"},
+					{"kind": "Ingress", "name": "web-test", "sourceName": "web"}
+				],
+				"notes": ["the Ingresses keep the hosts of the namespace cloned, change them so they don't route the same hosts"]
+			}`,
+			expectedObjects: map[string]map[string]string{
+				"secrets/db-test":          {"data.password": "aHVudGVyMg=="},
+				"configmaps/settings-test": {"data.mode": "production", "metadata.uid": ""},
+				"services/web-test":        {"spec.clusterIP": ""},
+				"ingresses/web-test":       {"spec.rules.0.http.paths.0.backend.service.name": "web-test"},
+				"deployments/web-test":     {"spec.template.spec.volumes.0.configMap.name": "settings-test", "spec.template.spec.containers.0.envFrom.0.secretRef.name": "db-test", "spec.template.spec.containers.0.envFrom.1.secretRef.name": "external"},
+			},
+		},
+		"resources already in the target namespace": {
+			params: cloneNamespaceParams{Cluster: "local", Namespace: "shop", TargetNamespace: "staging", Kinds: []string{"configmap", "service"}},
+			existingObjects: []runtime.Object{
+				&corev1.Namespace{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}, ObjectMeta: metav1.ObjectMeta{Name: "staging"}},
+				&corev1.ConfigMap{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}, ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "staging"}},
+			},
+			expectedResult: `{
+				"cluster": "local", "namespace": "shop", "targetCluster": "local", "targetNamespace": "staging", "namespaceCreated": false,
+				"cloned": [{"kind": "Service", "name": "web", "sourceName": "web"}],
+				"failed": [{"kind": "ConfigMap", "name": "settings", "sourceName": "settings", "error": "configmaps \"settings\" already exists"}]
+			}`,
+			expectedObjects: map[string]map[string]string{
+				"services/web": {"spec.selector.app": "web"},
+			},
+		},
+		"same namespace": {
+			params:        cloneNamespaceParams{Cluster: "local", Namespace: "shop", TargetNamespace: "shop"},
+			expectedError: "the target namespace must be different from the namespace cloned, or in another cluster",
+		},
+		"invalid secrets": {
+			params:        cloneNamespaceParams{Cluster: "local", Namespace: "shop", TargetNamespace: "shop-test", Secrets: "all"},
+			expectedError: `invalid secrets "all", must be skip, copy or redacted`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := newCloneFakeDynClient(test.existingObjects...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.cloneNamespace(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			if test.params.DryRun {
+				_, err := fakeDynClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Get(t.Context(), test.params.TargetNamespace, metav1.GetOptions{})
+				assert.Error(t, err, "the target namespace should not be created in dry-run mode")
+			}
+			for key, fields := range test.expectedObjects {
+				obj := getClonedObject(t, fakeDynClient, test.params.TargetNamespace, key)
+				for path, expected := range fields {
+					value := fieldAt(obj.Object, path)
+					if expected == "" {
+						assert.Nil(t, value, "%s %s", key, path)
+						continue
+					}
+					assert.Equal(t, expected, value, "%s %s", key, path)
+				}
+			}
+		})
+	}
+}
+
+// getClonedObject returns the resource of the target namespace with the <resource>/<name> key.
+func getClonedObject(t *testing.T, fakeDynClient *dynamicfake.FakeDynamicClient, namespace string, key string) *unstructured.Unstructured {
+	t.Helper()
+	gvrs := map[string]schema.GroupVersionResource{
+		"configmaps":  {Version: "v1", Resource: "configmaps"},
+		"secrets":     {Version: "v1", Resource: "secrets"},
+		"services":    {Version: "v1", Resource: "services"},
+		"deployments": {Group: "apps", Version: "v1", Resource: "deployments"},
+		"ingresses":   {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	}
+	resource, name, _ := strings.Cut(key, "/")
+	obj, err := fakeDynClient.Resource(gvrs[resource]).Namespace(namespace).Get(t.Context(), name, metav1.GetOptions{})
+	require.NoError(t, err, key)
+
+	return obj
+}
+
+// fieldAt returns the value at the dot separated path of the object, where the items of the lists are selected by
+// their index, or nil if there is none.
+func fieldAt(obj any, path string) any {
+	for _, field := range strings.Split(path, ".") {
+		switch value := obj.(type) {
+		case map[string]any:
+			obj = value[field]
+		case []any:
+			i, err := strconv.Atoi(field)
+			if err != nil || i >= len(value) {
+				return nil
+			}
+			obj = value[i]
+		default:
+			return nil
+		}
+	}
+
+	return obj
+}
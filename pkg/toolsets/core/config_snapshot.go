@@ -0,0 +1,369 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// snapshotNamespace is the namespace of the ConfigMaps storing the configuration snapshots of each cluster, the
+	// one of the change history.
+	snapshotNamespace = client.DefaultChangeHistoryNamespace
+	// snapshotConfigMapPrefix is the prefix of the names of the ConfigMaps storing the snapshots.
+	snapshotConfigMapPrefix = "config-snapshot-"
+	// snapshotLabel is the label of the ConfigMaps storing the snapshots.
+	snapshotLabel = "ai.cattle.io/config-snapshot"
+	snapshotKey   = "snapshot"
+	// maxSnapshotObjects keeps the snapshots under the size limit of the ConfigMaps.
+	maxSnapshotObjects = 5000
+)
+
+type createConfigSnapshotParams struct {
+	Cluster       string   `json:"cluster" jsonschema:"the cluster of the resources"`
+	Namespace     string   `json:"namespace,omitempty" jsonschema:"the namespace of the resources. Empty for all namespaces"`
+	Kinds         []string `json:"kinds,omitempty" jsonschema:"the kinds of the resources in the snapshot"`
+	LabelSelector string   `json:"labelSelector,omitempty" jsonschema:"the label selector the resources must match"`
+	Name          string   `json:"name,omitempty" jsonschema:"the name of the snapshot. Defaults to the time it's taken"`
+}
+
+type listConfigSnapshotsParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the snapshots"`
+}
+
+type compareConfigSnapshotsParams struct {
+	Cluster         string `json:"cluster" jsonschema:"the cluster of the snapshot"`
+	Snapshot        string `json:"snapshot" jsonschema:"the name of the snapshot" validate:"required"`
+	TargetSnapshot  string `json:"targetSnapshot,omitempty" jsonschema:"the name of the snapshot compared to. Empty to compare to the current resources"`
+	TargetCluster   string `json:"targetCluster,omitempty" jsonschema:"the cluster of the target snapshot or of the current resources. Defaults to the cluster of the snapshot"`
+	TargetNamespace string `json:"targetNamespace,omitempty" jsonschema:"the namespace of the current resources. Defaults to the namespace of the snapshot"`
+}
+
+// configSnapshot is the hash of the configuration of each resource of a cluster or a namespace at a point in time.
+type configSnapshot struct {
+	Name          string           `json:"name"`
+	Cluster       string           `json:"cluster"`
+	Namespace     string           `json:"namespace,omitempty"`
+	Kinds         []string         `json:"kinds"`
+	LabelSelector string           `json:"labelSelector,omitempty"`
+	CreatedAt     string           `json:"createdAt,omitempty"`
+	ObjectCount   int              `json:"objectCount"`
+	Objects       []snapshotObject `json:"objects,omitempty"`
+}
+
+// snapshotObject is a resource of a snapshot, with the hash of its sanitized manifest.
+type snapshotObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Hash      string `json:"hash,omitempty"`
+}
+
+// snapshotComparison lists the resources added, removed and changed in the target since the snapshot.
+type snapshotComparison struct {
+	Snapshot  configSnapshot   `json:"snapshot"`
+	Target    configSnapshot   `json:"target"`
+	Added     []snapshotObject `json:"added"`
+	Removed   []snapshotObject `json:"removed"`
+	Changed   []snapshotObject `json:"changed"`
+	Unchanged int              `json:"unchanged"`
+}
+
+// createConfigSnapshot stores the hashes of the resources of the selected kinds of a cluster or a namespace in a
+// ConfigMap of the cluster, to find the resources that drifted since with compareConfigSnapshots. The resources are
+// sanitized like the ones of exportResources before they are hashed, so the fields set by the cluster don't count as
+// changes.
+func (t *Tools) createConfigSnapshot(ctx context.Context, toolReq *mcp.CallToolRequest, params createConfigSnapshotParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createConfigSnapshot called")
+
+	createdAt := now().UTC()
+	snapshot := configSnapshot{
+		Name:          params.Name,
+		Cluster:       params.Cluster,
+		Namespace:     params.Namespace,
+		Kinds:         params.Kinds,
+		LabelSelector: params.LabelSelector,
+		CreatedAt:     createdAt.Format(time.RFC3339),
+	}
+	if snapshot.Name == "" {
+		snapshot.Name = createdAt.Format("20060102-150405")
+	}
+	if msgs := validation.IsDNS1123Subdomain(snapshotConfigMapPrefix + snapshot.Name); len(msgs) > 0 {
+		return nil, nil, fmt.Errorf("invalid snapshot name %q: %s", snapshot.Name, strings.Join(msgs, ", "))
+	}
+	if len(snapshot.Kinds) == 0 {
+		snapshot.Kinds = defaultExportedKinds
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	objects, err := t.captureSnapshot(ctx, token, url, snapshot)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to capture snapshot", zap.String("tool", "createConfigSnapshot"), zap.Error(err))
+		return nil, nil, err
+	}
+	snapshot.Objects = objects
+	snapshot.ObjectCount = len(objects)
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if _, err := t.ensureNamespace(ctx, token, url, params.Cluster, snapshotNamespace); err != nil {
+		middleware.Logger(ctx).Error("failed to create namespace", zap.String("tool", "createConfigSnapshot"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create namespace %s: %w", snapshotNamespace, err)
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, snapshotNamespace, params.Cluster, converter.K8sKindsToGVRs["configmap"])
+	if err != nil {
+		return nil, nil, err
+	}
+	configMap := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      snapshotConfigMapPrefix + snapshot.Name,
+			"namespace": snapshotNamespace,
+			"labels":    map[string]any{snapshotLabel: "true"},
+		},
+		"data": map[string]any{snapshotKey: string(data)},
+	}}
+	if _, err := resourceInterface.Create(ctx, configMap, metav1.CreateOptions{}); apierrors.IsAlreadyExists(err) {
+		return nil, nil, fmt.Errorf("snapshot %s already exists in cluster %s", snapshot.Name, params.Cluster)
+	} else if err != nil {
+		middleware.Logger(ctx).Error("failed to store snapshot", zap.String("tool", "createConfigSnapshot"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to store snapshot %s: %w", snapshot.Name, err)
+	}
+
+	snapshot.Objects = nil
+	return marshalSnapshotResponse(ctx, "createConfigSnapshot", snapshot)
+}
+
+// listConfigSnapshots returns the snapshots stored in a cluster, newest first, without their resources.
+func (t *Tools) listConfigSnapshots(ctx context.Context, toolReq *mcp.CallToolRequest, params listConfigSnapshotsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listConfigSnapshots called")
+
+	configMaps, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       params.Cluster,
+		Kind:          "configmap",
+		Namespace:     snapshotNamespace,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+		LabelSelector: snapshotLabel + "=true",
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list snapshots", zap.String("tool", "listConfigSnapshots"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	snapshots := []configSnapshot{}
+	for _, configMap := range configMaps {
+		snapshot, err := snapshotFromConfigMap(configMap)
+		if err != nil {
+			middleware.Logger(ctx).Warn("invalid snapshot", zap.String("tool", "listConfigSnapshots"), zap.String("name", configMap.GetName()), zap.Error(err))
+			continue
+		}
+		snapshot.Objects = nil
+		snapshots = append(snapshots, snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b configSnapshot) int {
+		return strings.Compare(b.CreatedAt, a.CreatedAt)
+	})
+
+	return marshalSnapshotResponse(ctx, "listConfigSnapshots", snapshots)
+}
+
+// compareConfigSnapshots compares a snapshot to another snapshot, or to the current resources of the same or another
+// cluster, and returns the resources added, removed and changed in the target. The resources of two snapshots of
+// single namespaces are matched by kind and name, so different namespaces can be compared.
+func (t *Tools) compareConfigSnapshots(ctx context.Context, toolReq *mcp.CallToolRequest, params compareConfigSnapshotsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("compareConfigSnapshots called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	targetCluster := params.TargetCluster
+	if targetCluster == "" {
+		targetCluster = params.Cluster
+	}
+	snapshot, err := t.getSnapshot(ctx, token, url, params.Cluster, params.Snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var target configSnapshot
+	if params.TargetSnapshot != "" {
+		target, err = t.getSnapshot(ctx, token, url, targetCluster, params.TargetSnapshot)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		target = configSnapshot{
+			Cluster:       targetCluster,
+			Namespace:     snapshot.Namespace,
+			Kinds:         snapshot.Kinds,
+			LabelSelector: snapshot.LabelSelector,
+		}
+		if params.TargetNamespace != "" {
+			target.Namespace = params.TargetNamespace
+		}
+		target.Objects, err = t.captureSnapshot(ctx, token, url, target)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to capture snapshot", zap.String("tool", "compareConfigSnapshots"), zap.Error(err))
+			return nil, nil, err
+		}
+		target.ObjectCount = len(target.Objects)
+	}
+
+	comparison := compareSnapshots(snapshot, target)
+	comparison.Snapshot.Objects = nil
+	comparison.Target.Objects = nil
+
+	return marshalSnapshotResponse(ctx, "compareConfigSnapshots", comparison)
+}
+
+// captureSnapshot returns the resources of the kinds of the snapshot with the hashes of their sanitized manifests,
+// sorted by kind, namespace and name. The resources managed by a controller are left out, since they follow the
+// changes of their owner, and so are the ones of the namespace storing the snapshots and the change history.
+func (t *Tools) captureSnapshot(ctx context.Context, token string, url string, snapshot configSnapshot) ([]snapshotObject, error) {
+	objects := []snapshotObject{}
+	for _, kind := range snapshot.Kinds {
+		resources, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:       snapshot.Cluster,
+			Kind:          strings.ToLower(kind),
+			Namespace:     snapshot.Namespace,
+			URL:           url,
+			Token:         token,
+			LabelSelector: snapshot.LabelSelector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range resources {
+			if exportSkipReason(obj) != "" || obj.GetNamespace() == snapshotNamespace {
+				continue
+			}
+			object := snapshotObject{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+			sanitizeExportedObject(obj)
+			data, err := json.Marshal(obj.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal %s %s: %w", object.Kind, object.Name, err)
+			}
+			hash := sha256.Sum256(data)
+			object.Hash = hex.EncodeToString(hash[:])
+			objects = append(objects, object)
+		}
+		if len(objects) > maxSnapshotObjects {
+			return nil, fmt.Errorf("more than %d resources in the snapshot, use a namespace, a label selector or less kinds", maxSnapshotObjects)
+		}
+	}
+	slices.SortFunc(objects, func(a, b snapshotObject) int {
+		return strings.Compare(a.Kind+"/"+a.Namespace+"/"+a.Name, b.Kind+"/"+b.Namespace+"/"+b.Name)
+	})
+
+	return objects, nil
+}
+
+// getSnapshot returns the snapshot with the given name stored in the cluster.
+func (t *Tools) getSnapshot(ctx context.Context, token string, url string, cluster string, name string) (configSnapshot, error) {
+	configMap, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "configmap",
+		Namespace: snapshotNamespace,
+		Name:      snapshotConfigMapPrefix + name,
+		URL:       url,
+		Token:     token,
+	})
+	if apierrors.IsNotFound(err) {
+		return configSnapshot{}, fmt.Errorf("snapshot %s not found in cluster %s, use listConfigSnapshots to find the snapshots", name, cluster)
+	}
+	if err != nil {
+		return configSnapshot{}, err
+	}
+
+	return snapshotFromConfigMap(configMap)
+}
+
+func snapshotFromConfigMap(configMap *unstructured.Unstructured) (configSnapshot, error) {
+	data, _, _ := unstructured.NestedString(configMap.Object, "data", snapshotKey)
+	var snapshot configSnapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return configSnapshot{}, fmt.Errorf("invalid snapshot %s: %w", configMap.GetName(), err)
+	}
+
+	return snapshot, nil
+}
+
+// compareSnapshots returns the resources of the target that aren't in the snapshot, the ones of the snapshot missing
+// from the target, and the ones with a different hash.
+func compareSnapshots(snapshot configSnapshot, target configSnapshot) snapshotComparison {
+	// the namespaces are ignored when both snapshots are of a single namespace
+	byNamespace := snapshot.Namespace == "" || target.Namespace == ""
+	key := func(object snapshotObject) string {
+		if byNamespace {
+			return object.Kind + "/" + object.Namespace + "/" + object.Name
+		}
+		return object.Kind + "/" + object.Name
+	}
+	strip := func(object snapshotObject) snapshotObject {
+		object.Hash = ""
+		return object
+	}
+
+	comparison := snapshotComparison{
+		Snapshot: snapshot,
+		Target:   target,
+		Added:    []snapshotObject{},
+		Removed:  []snapshotObject{},
+		Changed:  []snapshotObject{},
+	}
+	hashes := map[string]string{}
+	for _, object := range snapshot.Objects {
+		hashes[key(object)] = object.Hash
+	}
+	seen := map[string]bool{}
+	for _, object := range target.Objects {
+		seen[key(object)] = true
+		hash, ok := hashes[key(object)]
+		switch {
+		case !ok:
+			comparison.Added = append(comparison.Added, strip(object))
+		case hash != object.Hash:
+			comparison.Changed = append(comparison.Changed, strip(object))
+		default:
+			comparison.Unchanged++
+		}
+	}
+	for _, object := range snapshot.Objects {
+		if !seen[key(object)] {
+			comparison.Removed = append(comparison.Removed, strip(object))
+		}
+	}
+
+	return comparison
+}
+
+func marshalSnapshotResponse(ctx context.Context, tool string, result any) (*mcp.CallToolResult, any, error) {
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", tool), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
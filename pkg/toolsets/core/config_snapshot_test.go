@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	snapshotConfigMapsGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	snapshotDeploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+// newSnapshotFakeDynClient returns a fake client with unstructured objects, since the snapshots are created as
+// unstructured ConfigMaps that the fake client can't list with typed ones.
+func newSnapshotFakeDynClient() *dynamicfake.FakeDynamicClient {
+	configMap := func(namespace string, name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID("uid-" + namespace + "-" + name)},
+			Data:       data,
+		}
+	}
+	deployment := func(namespace string, name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "7"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+		}
+	}
+
+	var objects []runtime.Object
+	for _, obj := range []runtime.Object{
+		configMap("shop", "settings", map[string]string{"mode": "production"}),
+		configMap("shop", "features", map[string]string{"checkout": "v2"}),
+		deployment("shop", "web", 2),
+		deployment("shop", "worker", 1),
+		configMap("staging", "settings", map[string]string{"mode": "staging"}),
+		configMap("staging", "features", map[string]string{"checkout": "v2"}),
+		deployment("staging", "web", 2),
+	} {
+		u, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		objects = append(objects, &unstructured.Unstructured{Object: u})
+	}
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "namespaces"}: "NamespaceList",
+		snapshotConfigMapsGVR:                              "ConfigMapList",
+		snapshotDeploymentsGVR:                             "DeploymentList",
+	}, objects...)
+}
+
+func newSnapshotTools(fakeDynClient *dynamicfake.FakeDynamicClient) Tools {
+	return Tools{client: &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}}
+}
+
+func snapshotToolRequest() *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+	}
+}
+
+func TestCreateConfigSnapshot(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tests := map[string]struct {
+		params         createConfigSnapshotParams
+		expectedResult string
+		expectedError  string
+	}{
+		"snapshot of a namespace": {
+			params: createConfigSnapshotParams{Cluster: "local", Namespace: "shop", Kinds: []string{"configmap", "deployment"}},
+			expectedResult: `{"name": "20260301-123000", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"],
+				"createdAt": "2026-03-01T12:30:00Z", "objectCount": 4}`,
+		},
+		"snapshot of a cluster with a name": {
+			params: createConfigSnapshotParams{Cluster: "local", Kinds: []string{"configmap", "deployment"}, Name: "before-upgrade"},
+			expectedResult: `{"name": "before-upgrade", "cluster": "local", "kinds": ["configmap", "deployment"],
+				"createdAt": "2026-03-01T12:30:00Z", "objectCount": 7}`,
+		},
+		"invalid name": {
+			params:        createConfigSnapshotParams{Cluster: "local", Kinds: []string{"deployment"}, Name: "Before Upgrade"},
+			expectedError: `invalid snapshot name "Before Upgrade"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := newSnapshotFakeDynClient()
+			tools := newSnapshotTools(fakeDynClient)
+			ctx := middleware.WithToken(t.Context(), "fakeToken")
+
+			result, _, err := tools.createConfigSnapshot(ctx, snapshotToolRequest(), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+
+			_, err = fakeDynClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Get(ctx, snapshotNamespace, metav1.GetOptions{})
+			assert.NoError(t, err, "the namespace of the snapshots should be created")
+			result, _, err = tools.listConfigSnapshots(ctx, snapshotToolRequest(), listConfigSnapshotsParams{Cluster: "local"})
+			require.NoError(t, err)
+			assert.JSONEq(t, "["+test.expectedResult+"]", result.Content[0].(*mcp.TextContent).Text)
+
+			_, _, err = tools.createConfigSnapshot(ctx, snapshotToolRequest(), test.params)
+			assert.ErrorContains(t, err, "already exists in cluster local")
+		})
+	}
+}
+
+func TestCompareConfigSnapshots(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tests := map[string]struct {
+		params         compareConfigSnapshotsParams
+		change         func(ctx context.Context, fakeDynClient *dynamicfake.FakeDynamicClient)
+		expectedResult string
+		expectedError  string
+	}{
+		"no drift": {
+			params: compareConfigSnapshotsParams{Cluster: "local", Snapshot: "base"},
+			change: func(ctx context.Context, fakeDynClient *dynamicfake.FakeDynamicClient) {
+				// the fields set by the cluster aren't configuration
+				deployment, _ := fakeDynClient.Resource(snapshotDeploymentsGVR).Namespace("shop").Get(ctx, "web", metav1.GetOptions{})
+				deployment.SetResourceVersion("8")
+				_, _ = fakeDynClient.Resource(snapshotDeploymentsGVR).Namespace("shop").Update(ctx, deployment, metav1.UpdateOptions{})
+			},
+			expectedResult: `{
+				"snapshot": {"name": "base", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "createdAt": "2026-03-01T12:30:00Z", "objectCount": 4},
+				"target": {"name": "", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "objectCount": 4},
+				"added": [], "removed": [], "changed": [], "unchanged": 4
+			}`,
+		},
+		"drift since the snapshot": {
+			params: compareConfigSnapshotsParams{Cluster: "local", Snapshot: "base"},
+			change: func(ctx context.Context, fakeDynClient *dynamicfake.FakeDynamicClient) {
+				settings, _ := fakeDynClient.Resource(snapshotConfigMapsGVR).Namespace("shop").Get(ctx, "settings", metav1.GetOptions{})
+				settings.Object["data"] = map[string]any{"mode": "maintenance"}
+				_, _ = fakeDynClient.Resource(snapshotConfigMapsGVR).Namespace("shop").Update(ctx, settings, metav1.UpdateOptions{})
+				_ = fakeDynClient.Resource(snapshotDeploymentsGVR).Namespace("shop").Delete(ctx, "worker", metav1.DeleteOptions{})
+				flags := settings.DeepCopy()
+				flags.SetName("flags")
+				_, _ = fakeDynClient.Resource(snapshotConfigMapsGVR).Namespace("shop").Create(ctx, flags, metav1.CreateOptions{})
+			},
+			expectedResult: `{
+				"snapshot": {"name": "base", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "createdAt": "2026-03-01T12:30:00Z", "objectCount": 4},
+				"target": {"name": "", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "objectCount": 4},
+				"added": [{"kind": "ConfigMap", "namespace": "shop", "name": "flags"}],
+				"removed": [{"kind": "Deployment", "namespace": "shop", "name": "worker"}],
+				"changed": [{"kind": "ConfigMap", "namespace": "shop", "name": "settings"}],
+				"unchanged": 2
+			}`,
+		},
+		"another namespace": {
+			params: compareConfigSnapshotsParams{Cluster: "local", Snapshot: "base", TargetNamespace: "staging"},
+			expectedResult: `{
+				"snapshot": {"name": "base", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "createdAt": "2026-03-01T12:30:00Z", "objectCount": 4},
+				"target": {"name": "", "cluster": "local", "namespace": "staging", "kinds": ["configmap", "deployment"], "objectCount": 3},
+				"added": [],
+				"removed": [{"kind": "Deployment", "namespace": "shop", "name": "worker"}],
+				"changed": [{"kind": "ConfigMap", "namespace": "staging", "name": "settings"}],
+				"unchanged": 2
+			}`,
+		},
+		"two snapshots": {
+			params: compareConfigSnapshotsParams{Cluster: "local", Snapshot: "base", TargetSnapshot: "after"},
+			change: func(ctx context.Context, fakeDynClient *dynamicfake.FakeDynamicClient) {
+				_ = fakeDynClient.Resource(snapshotConfigMapsGVR).Namespace("shop").Delete(ctx, "features", metav1.DeleteOptions{})
+				tools := newSnapshotTools(fakeDynClient)
+				_, _, _ = tools.createConfigSnapshot(ctx, snapshotToolRequest(), createConfigSnapshotParams{Cluster: "local", Namespace: "shop", Kinds: []string{"configmap", "deployment"}, Name: "after"})
+			},
+			expectedResult: `{
+				"snapshot": {"name": "base", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "createdAt": "2026-03-01T12:30:00Z", "objectCount": 4},
+				"target": {"name": "after", "cluster": "local", "namespace": "shop", "kinds": ["configmap", "deployment"], "createdAt": "2026-03-01T12:30:00Z", "objectCount": 3},
+				"added": [],
+				"removed": [{"kind": "ConfigMap", "namespace": "shop", "name": "features"}],
+				"changed": [],
+				"unchanged": 3
+			}`,
+		},
+		"unknown snapshot": {
+			params:        compareConfigSnapshotsParams{Cluster: "local", Snapshot: "last-week"},
+			expectedError: "snapshot last-week not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := newSnapshotFakeDynClient()
+			tools := newSnapshotTools(fakeDynClient)
+			ctx := middleware.WithToken(t.Context(), "fakeToken")
+			_, _, err := tools.createConfigSnapshot(ctx, snapshotToolRequest(), createConfigSnapshotParams{Cluster: "local", Namespace: "shop", Kinds: []string{"configmap", "deployment"}, Name: "base"})
+			require.NoError(t, err)
+			if test.change != nil {
+				test.change(ctx, fakeDynClient)
+			}
+
+			result, _, err := tools.compareConfigSnapshots(ctx, snapshotToolRequest(), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -4,11 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
-	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,48 +15,83 @@ import (
 )
 
 const (
-	urlHeader = "R_url"
+	urlHeader = client.URLHeader
 )
 
 // createKubernetesResourceParams defines the structure for creating a general Kubernetes resource.
 type createKubernetesResourceParams struct {
-	Name      string `json:"name" jsonschema:"the name of k8s resource"`
-	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
-	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
-	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
-	Resource  any    `json:"resource" jsonschema:"the resource to be created"`
+	Name        string `json:"name" jsonschema:"the name of k8s resource"`
+	Namespace   string `json:"namespace,omitempty" jsonschema:"the namespace of the resource, empty for the cluster-scoped resources"`
+	Kind        string `json:"kind" jsonschema:"the kind of the resource"`
+	Cluster     string `json:"cluster" jsonschema:"the cluster of the resource"`
+	Resource    any    `json:"resource" jsonschema:"the resource to be created"`
+	DryRun      bool   `json:"dryRun,omitempty" jsonschema:"validate the request in the server without creating the resource"`
+	WaitSeconds int64  `json:"waitSeconds,omitempty" jsonschema:"wait up to this many seconds, at most 300, for the resource to be ready, e.g. a Deployment Available or a Cluster Ready. 0 returns without waiting"`
 }
 
-// createKubernetesResource creates a new Kubernetes resource.
+// createKubernetesResource creates a new Kubernetes resource. In dry-run mode the server validates the resource without
+// persisting it, and the resulting object is returned together with the fields it would add. With waitSeconds, the
+// created resource is watched until it's ready and its last state is returned.
 func (t *Tools) createKubernetesResource(ctx context.Context, toolReq *mcp.CallToolRequest, params createKubernetesResourceParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("createKubernetesResource called")
+	middleware.Logger(ctx).Debug("createKubernetesResource called")
 
-	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs[strings.ToLower(params.Kind)])
+	if err := validateWaitSeconds(params.WaitSeconds); err != nil {
+		return nil, nil, err
+	}
+	gvr, err := t.client.ResolveGVR(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Cluster, params.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, gvr)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	objBytes, err := json.Marshal(params.Resource)
 	if err != nil {
-		zap.L().Error("failed to marshal resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to marshal resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to marshal resource: %w", err)
 	}
 
 	unstructuredObj := &unstructured.Unstructured{}
 	if err := json.Unmarshal(objBytes, unstructuredObj); err != nil {
-		zap.L().Error("failed to create unstructured resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create unstructured resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to create unstructured object: %w", err)
 	}
 
-	obj, err := resourceInterface.Create(ctx, unstructuredObj, metav1.CreateOptions{})
+	createOptions := metav1.CreateOptions{}
+	if params.DryRun {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	obj, err := resourceInterface.Create(ctx, unstructuredObj, createOptions)
 	if err != nil {
-		zap.L().Error("failed to create resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to create resource %s: %w", params.Name, err)
 	}
 
-	mcpResponse, err := response.CreateMcpResponse([]*unstructured.Unstructured{obj}, params.Cluster)
+	if params.DryRun {
+		dryRun, err := dryRunResponse(nil, obj)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create dry-run response", zap.String("tool", "createKubernetesResource"), zap.Error(err))
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: dryRun}},
+		}, nil, nil
+	}
+
+	objs := []*unstructured.Unstructured{obj}
+	if params.WaitSeconds > 0 {
+		last, wait, err := waitForResourceObjects(ctx, resourceInterface, obj, params.WaitSeconds)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to wait for resource", zap.String("tool", "createKubernetesResource"), zap.Error(err))
+			return nil, nil, err
+		}
+		objs = []*unstructured.Unstructured{last, wait}
+	}
+	mcpResponse, err := response.CreateMcpResponse(ctx, objs, params.Cluster)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "createKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "createKubernetesResource"), zap.Error(err))
 		return nil, nil, err
 	}
 
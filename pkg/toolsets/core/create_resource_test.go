@@ -70,6 +70,75 @@ func TestCreateKubernetesResource(t *testing.T) {
 				]
 			}`,
 		},
+		"create configmap - wait": {
+			params: createKubernetesResourceParams{
+				Name:        "test-config",
+				Namespace:   "default",
+				Kind:        "configmap",
+				Cluster:     "local",
+				Resource:    configMapResource,
+				WaitSeconds: 30,
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(createResourceScheme(), map[schema.GroupVersionResource]string{
+				{Group: "", Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+			}),
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "v1",
+						"data": {"key1": "value1", "key2": "value2"},
+						"kind": "ConfigMap",
+						"metadata": {"name": "test-config", "namespace": "default"}
+					},
+					{"wait": {"ready": true, "condition": "Ready", "message": "ConfigMap has no readiness condition, it's ready once created"}}
+				],
+				"uiContext": [
+					{"namespace": "default", "kind": "ConfigMap", "cluster": "local", "name": "test-config", "type": "configmap"}
+				]
+			}`,
+		},
+		"create configmap - invalid waitSeconds": {
+			params: createKubernetesResourceParams{
+				Name:        "test-config",
+				Namespace:   "default",
+				Kind:        "configmap",
+				Cluster:     "local",
+				Resource:    configMapResource,
+				WaitSeconds: 600,
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(createResourceScheme(), map[schema.GroupVersionResource]string{
+				{Group: "", Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+			}),
+			expectedError: "invalid waitSeconds 600, must be between 0 and 300",
+		},
+		"create configmap - dry run": {
+			params: createKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+				Resource:  configMapResource,
+				DryRun:    true,
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(createResourceScheme(), map[schema.GroupVersionResource]string{
+				{Group: "", Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+			}),
+			expectedResult: `{
+				"dryRun": true,
+				"object": {
+					"apiVersion": "v1",
+					"data": {"key1": "value1", "key2": "value2"},
+					"kind": "ConfigMap",
+					"metadata": {"name": "test-config", "namespace": "default"}
+				},
+				"diff": [
+					{"path": "/apiVersion", "op": "add", "newValue": "v1"},
+					{"path": "/data", "op": "add", "newValue": {"key1": "value1", "key2": "value2"}},
+					{"path": "/kind", "op": "add", "newValue": "ConfigMap"},
+					{"path": "/metadata", "op": "add", "newValue": {"name": "test-config", "namespace": "default"}}
+				]
+			}`,
+		},
 		"create configmap - marshal error": {
 			params: createKubernetesResourceParams{
 				Name:      "test-config",
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSilenceDuration and maxSilenceDuration keep the silences temporary, so alerts aren't muted forever.
+	defaultSilenceDuration = time.Hour
+	maxSilenceDuration     = 7 * 24 * time.Hour
+	defaultSilenceCreator  = "Rancher AI assistant"
+)
+
+type createSilenceParams struct {
+	Cluster   string            `json:"cluster" jsonschema:"the cluster of the Alertmanager"`
+	Matchers  map[string]string `json:"matchers" jsonschema:"the labels of the alerts to silence and their values (e.g. alertname: KubePodCrashLooping, namespace: default)"`
+	Duration  string            `json:"duration,omitempty" jsonschema:"how long the alerts are silenced (e.g. 2h). Defaults to 1h, at most 168h"`
+	Comment   string            `json:"comment" jsonschema:"why the alerts are silenced"`
+	CreatedBy string            `json:"createdBy,omitempty" jsonschema:"the user silencing the alerts"`
+}
+
+// silenceMatcher is a matcher of a silence of the v2 API of Alertmanager.
+type silenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// silence is a silence of the v2 API of Alertmanager.
+type silence struct {
+	Matchers  []silenceMatcher `json:"matchers"`
+	StartsAt  string           `json:"startsAt"`
+	EndsAt    string           `json:"endsAt"`
+	CreatedBy string           `json:"createdBy"`
+	Comment   string           `json:"comment"`
+}
+
+// createSilenceResult is the response of createSilence.
+type createSilenceResult struct {
+	SilenceID string `json:"silenceID"`
+	silence
+}
+
+// createSilence creates a temporary silence in the Alertmanager of rancher-monitoring. The matchers match the exact
+// values of the labels, so the silence can't mute more alerts than intended with a regular expression.
+func (t *Tools) createSilence(ctx context.Context, toolReq *mcp.CallToolRequest, params createSilenceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createSilence called")
+
+	if len(params.Matchers) == 0 {
+		return nil, nil, errors.New("at least one matcher is required")
+	}
+	if params.Comment == "" {
+		return nil, nil, errors.New("comment is required")
+	}
+	duration := defaultSilenceDuration
+	if params.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(params.Duration)
+		if err != nil || duration <= 0 {
+			return nil, nil, fmt.Errorf("invalid duration %q, must be a duration like 2h", params.Duration)
+		}
+		if duration > maxSilenceDuration {
+			return nil, nil, fmt.Errorf("duration %s is too long, silences can last at most %s", params.Duration, maxSilenceDuration)
+		}
+	}
+
+	now := time.Now().UTC()
+	s := silence{
+		StartsAt:  now.Format(time.RFC3339),
+		EndsAt:    now.Add(duration).Format(time.RFC3339),
+		CreatedBy: defaultSilenceCreator,
+		Comment:   params.Comment,
+	}
+	if params.CreatedBy != "" {
+		s.CreatedBy = params.CreatedBy
+	}
+	for name, value := range params.Matchers {
+		s.Matchers = append(s.Matchers, silenceMatcher{Name: name, Value: value, IsEqual: true})
+	}
+	body, err := json.Marshal(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	rancherURL := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	if err := t.checkMonitoringService(ctx, params.Cluster, rancherURL, token, alertmanagerService); err != nil {
+		middleware.Logger(ctx).Error("failed to get alertmanager service", zap.String("tool", "createSilence"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, alertmanagerTimeout)
+	defer cancel()
+	res, err := t.client.ProxyPost(ctx, client.ProxyGetParams{
+		Cluster:   params.Cluster,
+		Kind:      "service",
+		Namespace: monitoringNamespace,
+		Name:      alertmanagerService,
+		Scheme:    "http",
+		Port:      alertmanagerPort,
+		Path:      "/api/v2/silences",
+		URL:       rancherURL,
+		Token:     token,
+	}, body)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create silence", zap.String("tool", "createSilence"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create silence: %w", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read alertmanager response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("alertmanager request failed with status %s: %s", res.Status, truncate(string(resBody), probeMaxBodyBytes))
+	}
+	result := createSilenceResult{silence: s}
+	if err := json.Unmarshal(resBody, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse alertmanager response: %w", err)
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "createSilence"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCreateSilence(t *testing.T) {
+	fakeToken := "fakeToken"
+	silences := make(chan []byte, 1)
+	srv := newFakeAlertmanager(t, fakeToken, nil, silences)
+
+	tests := map[string]struct {
+		params           createSilenceParams
+		objects          []runtime.Object
+		expectedMatchers []silenceMatcher
+		expectedDuration time.Duration
+		expectedCreator  string
+		expectedError    string
+	}{
+		"default duration": {
+			params:           createSilenceParams{Cluster: "local", Matchers: map[string]string{"alertname": "KubePodCrashLooping"}, Comment: "investigating"},
+			objects:          []runtime.Object{fakeAlertmanagerService},
+			expectedMatchers: []silenceMatcher{{Name: "alertname", Value: "KubePodCrashLooping", IsEqual: true}},
+			expectedDuration: time.Hour,
+			expectedCreator:  defaultSilenceCreator,
+		},
+		"custom duration and creator": {
+			params:           createSilenceParams{Cluster: "local", Matchers: map[string]string{"namespace": "default"}, Duration: "2h", Comment: "maintenance", CreatedBy: "admin"},
+			objects:          []runtime.Object{fakeAlertmanagerService},
+			expectedMatchers: []silenceMatcher{{Name: "namespace", Value: "default", IsEqual: true}},
+			expectedDuration: 2 * time.Hour,
+			expectedCreator:  "admin",
+		},
+		"no matchers": {
+			params:        createSilenceParams{Cluster: "local", Comment: "investigating"},
+			expectedError: "at least one matcher is required",
+		},
+		"no comment": {
+			params:        createSilenceParams{Cluster: "local", Matchers: map[string]string{"alertname": "Watchdog"}},
+			expectedError: "comment is required",
+		},
+		"duration too long": {
+			params:        createSilenceParams{Cluster: "local", Matchers: map[string]string{"alertname": "Watchdog"}, Duration: "720h", Comment: "noisy"},
+			expectedError: "duration 720h is too long, silences can last at most 168h0m0s",
+		},
+		"invalid duration": {
+			params:        createSilenceParams{Cluster: "local", Matchers: map[string]string{"alertname": "Watchdog"}, Duration: "forever", Comment: "noisy"},
+			expectedError: `invalid duration "forever", must be a duration like 2h`,
+		},
+		"monitoring not installed": {
+			params:        createSilenceParams{Cluster: "local", Matchers: map[string]string{"alertname": "Watchdog"}, Comment: "noisy"},
+			expectedError: "rancher-monitoring is not installed in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newFakeProxyTools(fakeToken, test.objects...)
+
+			result, _, err := tools.createSilence(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var sent silence
+			require.NoError(t, json.Unmarshal(<-silences, &sent))
+			assert.Equal(t, test.expectedMatchers, sent.Matchers)
+			assert.Equal(t, test.expectedCreator, sent.CreatedBy)
+			assert.Equal(t, test.params.Comment, sent.Comment)
+			startsAt, err := time.Parse(time.RFC3339, sent.StartsAt)
+			require.NoError(t, err)
+			endsAt, err := time.Parse(time.RFC3339, sent.EndsAt)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDuration, endsAt.Sub(startsAt))
+
+			var res createSilenceResult
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &res))
+			assert.Equal(t, "7b7e3b4c-1f0a-4a3c-9d2e-5f1b2c3d4e5f", res.SilenceID)
+			assert.Equal(t, sent.EndsAt, res.EndsAt)
+		})
+	}
+}
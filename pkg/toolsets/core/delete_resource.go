@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// deleteKubernetesResourceParams defines the structure for deleting a general Kubernetes resource.
+type deleteKubernetesResourceParams struct {
+	Name      string `json:"name" jsonschema:"the name of k8s resource"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the resource, empty for the cluster-scoped resources"`
+	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
+	Force     bool   `json:"force,omitempty" jsonschema:"allow deleting cluster scoped resources that are protected by default, such as CRDs"`
+}
+
+// deleteKubernetesResource deletes a specific Kubernetes resource and returns its last known state.
+func (t *Tools) deleteKubernetesResource(ctx context.Context, toolReq *mcp.CallToolRequest, params deleteKubernetesResourceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("deleteKubernetesResource called")
+
+	gvr, err := t.client.ResolveGVR(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Cluster, params.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if gvr.Resource == "namespaces" && slices.Contains(utils.ProtectedNamespaces, params.Name) {
+		middleware.Logger(ctx).Warn("refusing to delete protected namespace", zap.String("tool", "deleteKubernetesResource"), zap.String("namespace", params.Name))
+		return nil, nil, fmt.Errorf("namespace %s is protected and can't be deleted", params.Name)
+	}
+
+	if gvr.Resource == "customresourcedefinitions" && !params.Force {
+		middleware.Logger(ctx).Warn("refusing to delete CRD without force", zap.String("tool", "deleteKubernetesResource"), zap.String("name", params.Name))
+		return nil, nil, fmt.Errorf("deleting CustomResourceDefinition %s removes all of its custom resources, set force to true to delete it", params.Name)
+	}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// fetch the resource first so its last state can be returned once deleted
+	obj, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource", zap.String("tool", "deleteKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	if err := resourceInterface.Delete(ctx, params.Name, metav1.DeleteOptions{}); err != nil {
+		middleware.Logger(ctx).Error("failed to delete resource", zap.String("tool", "deleteKubernetesResource"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to delete resource %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "deleteKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
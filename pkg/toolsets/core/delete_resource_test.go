@@ -0,0 +1,139 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+var fakeConfigMapForDelete = &corev1.ConfigMap{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-config",
+		Namespace: "default",
+	},
+	Data: map[string]string{
+		"key1": "value1",
+	},
+}
+
+func deleteResourceScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	return scheme
+}
+
+func TestDeleteKubernetesResource(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params         deleteKubernetesResourceParams
+		fakeDynClient  *dynamicfake.FakeDynamicClient
+		expectedResult string
+		expectedError  string
+	}{
+		"delete configmap": {
+			params: deleteKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClient(deleteResourceScheme(), fakeConfigMapForDelete),
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "v1",
+						"data": {"key1": "value1"},
+						"kind": "ConfigMap",
+						"metadata": {"name": "test-config", "namespace": "default"}
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "ConfigMap", "name": "test-config", "namespace": "default", "type": "configmap"}
+				]
+			}`,
+		},
+		"delete configmap - not found": {
+			params: deleteKubernetesResourceParams{
+				Name:      "missing-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClient(deleteResourceScheme()),
+			expectedError: `configmaps "missing-config" not found`,
+		},
+		"delete protected namespace": {
+			params: deleteKubernetesResourceParams{
+				Name:    "kube-system",
+				Kind:    "namespace",
+				Cluster: "local",
+				Force:   true,
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClient(deleteResourceScheme(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}}),
+			expectedError: "namespace kube-system is protected and can't be deleted",
+		},
+		"delete crd without force": {
+			params: deleteKubernetesResourceParams{
+				Name:    "clusters.provisioning.cattle.io",
+				Kind:    "crd",
+				Cluster: "local",
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClient(deleteResourceScheme()),
+			expectedError: "set force to true to delete it",
+		},
+		"delete unknown kind": {
+			params: deleteKubernetesResourceParams{
+				Name:    "foo",
+				Kind:    "unknown",
+				Cluster: "local",
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClient(deleteResourceScheme()),
+			expectedError: "unknown kind: unknown",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return test.fakeDynClient, nil
+				},
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return fake.NewClientset(), nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.deleteKubernetesResource(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+
+				// the resource must no longer exist after being deleted
+				_, err := test.fakeDynClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace(test.params.Namespace).Get(t.Context(), test.params.Name, metav1.GetOptions{})
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// crdSchemaMaxDepth is the maximum nesting level of the schema fields returned.
+	crdSchemaMaxDepth = 6
+	// crdSchemaMaxFields is the maximum number of schema fields returned.
+	crdSchemaMaxFields = 250
+	// crdSchemaMaxDescription is the maximum length of the descriptions of the schema fields.
+	crdSchemaMaxDescription = 200
+)
+
+type describeCRDParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the CRD"`
+	Name    string `json:"name" jsonschema:"the name of the CRD (e.g. certificates.cert-manager.io)"`
+	Version string `json:"version,omitempty" jsonschema:"the version whose schema is returned. Defaults to the storage version"`
+}
+
+// crdDescription describes a CRD, its versions and the schema of one of them.
+type crdDescription struct {
+	Name       string       `json:"name"`
+	Group      string       `json:"group"`
+	Kind       string       `json:"kind"`
+	Plural     string       `json:"plural"`
+	ShortNames []string     `json:"shortNames,omitempty"`
+	Scope      string       `json:"scope"`
+	Versions   []crdVersion `json:"versions"`
+	Schema     crdSchema    `json:"schema"`
+}
+
+// crdVersion describes a version of a CRD and the columns printed by kubectl for it.
+type crdVersion struct {
+	Name               string          `json:"name"`
+	Served             bool            `json:"served"`
+	Storage            bool            `json:"storage"`
+	Deprecated         bool            `json:"deprecated,omitempty"`
+	DeprecationWarning string          `json:"deprecationWarning,omitempty"`
+	PrinterColumns     []printerColumn `json:"printerColumns,omitempty"`
+}
+
+type printerColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	JSONPath    string `json:"jsonPath"`
+	Description string `json:"description,omitempty"`
+}
+
+// crdSchema is the validation schema of a CRD version flattened into a list of fields.
+type crdSchema struct {
+	Version string        `json:"version"`
+	Fields  []schemaField `json:"fields"`
+	// Truncated is true when the schema had more than crdSchemaMaxFields fields.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// schemaField is a field of a validation schema. Path uses dots for nested fields and [] for array items (e.g. spec.rules[].host).
+type schemaField struct {
+	Path        string   `json:"path"`
+	Type        string   `json:"type,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// describeCustomResourceDefinition returns the versions of a CRD, their printer columns and a summary of the validation schema of one of them.
+func (t *Tools) describeCustomResourceDefinition(ctx context.Context, toolReq *mcp.CallToolRequest, params describeCRDParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("describeCustomResourceDefinition called")
+
+	crd, err := t.getCRD(ctx, toolReq, params.Cluster, params.Name)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get CRD", zap.String("tool", "describeCustomResourceDefinition"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	description := crdDescription{
+		Name:       crd.Name,
+		Group:      crd.Spec.Group,
+		Kind:       crd.Spec.Names.Kind,
+		Plural:     crd.Spec.Names.Plural,
+		ShortNames: crd.Spec.Names.ShortNames,
+		Scope:      string(crd.Spec.Scope),
+	}
+	var schemaVersion *apiextensionsv1.CustomResourceDefinitionVersion
+	for i, version := range crd.Spec.Versions {
+		v := crdVersion{
+			Name:       version.Name,
+			Served:     version.Served,
+			Storage:    version.Storage,
+			Deprecated: version.Deprecated,
+		}
+		if version.DeprecationWarning != nil {
+			v.DeprecationWarning = *version.DeprecationWarning
+		}
+		for _, column := range version.AdditionalPrinterColumns {
+			v.PrinterColumns = append(v.PrinterColumns, printerColumn{
+				Name:        column.Name,
+				Type:        column.Type,
+				JSONPath:    column.JSONPath,
+				Description: column.Description,
+			})
+		}
+		description.Versions = append(description.Versions, v)
+
+		if version.Name == params.Version || (params.Version == "" && version.Storage) {
+			schemaVersion = &crd.Spec.Versions[i]
+		}
+	}
+	if schemaVersion == nil {
+		return nil, nil, fmt.Errorf("version %s not found in CRD %s", params.Version, params.Name)
+	}
+
+	description.Schema = crdSchema{Version: schemaVersion.Name, Fields: []schemaField{}}
+	if schemaVersion.Schema != nil && schemaVersion.Schema.OpenAPIV3Schema != nil {
+		description.Schema.Truncated = !summarizeSchema(schemaVersion.Schema.OpenAPIV3Schema, "", 0, &description.Schema.Fields)
+	}
+
+	response, err := json.Marshal(description)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "describeCustomResourceDefinition"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// getCRD returns the CRD with the given name.
+func (t *Tools) getCRD(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+	obj, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: cluster,
+		Kind:    "crd",
+		Name:    name,
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var crd apiextensionsv1.CustomResourceDefinition
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &crd); err != nil {
+		return nil, fmt.Errorf("failed to convert unstructured object to CRD: %w", err)
+	}
+
+	return &crd, nil
+}
+
+// summarizeSchema appends the properties of the schema to fields, walking nested objects and array items up to
+// crdSchemaMaxDepth. The apiVersion, kind and metadata fields common to all resources are skipped. It returns false
+// if fields reached crdSchemaMaxFields.
+func summarizeSchema(schema *apiextensionsv1.JSONSchemaProps, path string, depth int, fields *[]schemaField) bool {
+	if depth >= crdSchemaMaxDepth {
+		return true
+	}
+	if schema.Type == "array" && schema.Items != nil && schema.Items.Schema != nil {
+		return summarizeSchema(schema.Items.Schema, path+"[]", depth, fields)
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		if depth == 0 && (name == "apiVersion" || name == "kind" || name == "metadata") {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if len(*fields) >= crdSchemaMaxFields {
+			return false
+		}
+		property := schema.Properties[name]
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		field := schemaField{
+			Path:        fieldPath,
+			Type:        schemaType(&property),
+			Required:    slices.Contains(schema.Required, name),
+			Description: property.Description,
+		}
+		if len(field.Description) > crdSchemaMaxDescription {
+			field.Description = field.Description[:crdSchemaMaxDescription] + "..."
+		}
+		for _, value := range property.Enum {
+			var s string
+			if err := json.Unmarshal(value.Raw, &s); err != nil {
+				s = string(value.Raw)
+			}
+			field.Enum = append(field.Enum, s)
+		}
+		*fields = append(*fields, field)
+
+		if !summarizeSchema(&property, fieldPath, depth+1, fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// schemaType returns the type of the schema, using []type for arrays and int-or-string for IntOrString fields.
+func schemaType(schema *apiextensionsv1.JSONSchemaProps) string {
+	switch {
+	case schema.XIntOrString:
+		return "int-or-string"
+	case schema.Type == "array" && schema.Items != nil && schema.Items.Schema != nil:
+		return "[]" + schemaType(schema.Items.Schema)
+	}
+
+	return schema.Type
+}
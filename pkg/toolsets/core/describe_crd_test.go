@@ -0,0 +1,96 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestDescribeCustomResourceDefinition(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	versions := `[
+		{"name": "v1beta1", "served": true, "storage": false},
+		{"name": "v1", "served": true, "storage": true, "printerColumns": [{"name": "Ready", "type": "string", "jsonPath": ".status.conditions[?(@.type==\"Ready\")].status"}]}
+	]`
+
+	tests := map[string]struct {
+		params         describeCRDParams
+		expectedResult string
+		expectedError  string
+	}{
+		"storage version": {
+			params: describeCRDParams{Cluster: "local", Name: "certificates.cert-manager.io"},
+			expectedResult: `{
+				"name": "certificates.cert-manager.io", "group": "cert-manager.io", "kind": "Certificate", "plural": "certificates", "shortNames": ["cert"], "scope": "Namespaced",
+				"versions": ` + versions + `,
+				"schema": {
+					"version": "v1",
+					"fields": [
+						{"path": "spec", "type": "object"},
+						{"path": "spec.dnsNames", "type": "[]string"},
+						{"path": "spec.privateKey", "type": "object"},
+						{"path": "spec.privateKey.algorithm", "type": "string", "enum": ["RSA", "ECDSA"]},
+						{"path": "spec.privateKey.size", "type": "int-or-string"},
+						{"path": "spec.secretName", "type": "string", "required": true, "description": "Name of the Secret resource that will be created."}
+					]
+				}
+			}`,
+		},
+		"specific version": {
+			params: describeCRDParams{Cluster: "local", Name: "certificates.cert-manager.io", Version: "v1beta1"},
+			expectedResult: `{
+				"name": "certificates.cert-manager.io", "group": "cert-manager.io", "kind": "Certificate", "plural": "certificates", "shortNames": ["cert"], "scope": "Namespaced",
+				"versions": ` + versions + `,
+				"schema": {"version": "v1beta1", "fields": [{"path": "spec", "type": "object"}]}
+			}`,
+		},
+		"unknown version": {
+			params:        describeCRDParams{Cluster: "local", Name: "certificates.cert-manager.io", Version: "v2"},
+			expectedError: "version v2 not found in CRD certificates.cert-manager.io",
+		},
+		"unknown CRD": {
+			params:        describeCRDParams{Cluster: "local", Name: "gateways.networking.istio.io"},
+			expectedError: `customresourcedefinitions.apiextensions.k8s.io "gateways.networking.istio.io" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newCRDTools(newCRDFakeDynClient(t, []*apiextensionsv1.CustomResourceDefinition{fakeCertificateCRD}), fakeToken)
+
+			result, _, err := tools.describeCustomResourceDefinition(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestSummarizeSchemaLimits(t *testing.T) {
+	nested := apiextensionsv1.JSONSchemaProps{Type: "string"}
+	for range crdSchemaMaxDepth + 2 {
+		nested = apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{"a": nested}}
+	}
+	fields := []schemaField{}
+	assert.True(t, summarizeSchema(&nested, "", 0, &fields))
+	assert.Len(t, fields, crdSchemaMaxDepth, "fields deeper than crdSchemaMaxDepth are skipped")
+
+	wide := apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{}}
+	for i := range crdSchemaMaxFields + 1 {
+		wide.Properties[string(rune('a'+i%26))+string(rune('a'+i/26))] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+	}
+	fields = []schemaField{}
+	assert.False(t, summarizeSchema(&wide, "", 0, &fields))
+	assert.Len(t, fields, crdSchemaMaxFields)
+}
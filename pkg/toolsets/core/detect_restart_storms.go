@@ -0,0 +1,263 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultStormHours is the window of the restarts when no window is given.
+	defaultStormHours = 1
+	// defaultStormRestarts is the number of restarts from which a Pod is reported when no minimum is given.
+	defaultStormRestarts = 3
+	// maxStormPods is the number of restarting Pods returned, the groups by owner and node cover all of them.
+	maxStormPods = 50
+)
+
+// The scopes of a restart storm.
+const (
+	stormScopeNone  = "none"
+	stormScopeApp   = "app"
+	stormScopeNode  = "node"
+	stormScopeMixed = "mixed"
+)
+
+// pressureConditions are the node conditions reported when they're True.
+var pressureConditions = []corev1.NodeConditionType{corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure}
+
+type detectRestartStormsParams struct {
+	Cluster     string `json:"cluster" jsonschema:"the cluster to scan"`
+	Namespace   string `json:"namespace,omitempty" jsonschema:"the namespace of the pods. Empty for all the namespaces"`
+	Hours       int    `json:"hours,omitempty" jsonschema:"the window of the restarts in hours. Defaults to 1"`
+	MinRestarts int32  `json:"minRestarts,omitempty" jsonschema:"the number of restarts from which a pod is reported. Defaults to 3"`
+}
+
+// restartingPod is a Pod whose containers restarted in the window.
+type restartingPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Node      string `json:"node"`
+	Owner     string `json:"owner"`
+	// Restarts is the sum of the restart counts of the containers that restarted in the window, the kubelet doesn't
+	// keep the time of the earlier restarts.
+	Restarts    int32  `json:"restarts"`
+	LastRestart string `json:"lastRestart"`
+	// Reason is the reason of the last termination of the container that restarted last, e.g. OOMKilled or Error.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ownerRestarts groups the restarting Pods of a workload.
+type ownerRestarts struct {
+	Namespace string   `json:"namespace"`
+	Owner     string   `json:"owner"`
+	Pods      int      `json:"pods"`
+	Restarts  int32    `json:"restarts"`
+	Nodes     []string `json:"nodes"`
+	// Scope is node when all the restarting Pods of the workload run on nodes under pressure or not ready.
+	Scope string `json:"scope"`
+}
+
+// nodeRestarts groups the restarting Pods of a node.
+type nodeRestarts struct {
+	Node     string `json:"node"`
+	Pods     int    `json:"pods"`
+	Restarts int32  `json:"restarts"`
+	Owners   int    `json:"owners"`
+	// Conditions are the pressure conditions of the node that are True, and NotReady.
+	Conditions []string `json:"conditions"`
+}
+
+// restartStorm is the result of detectRestartStorms.
+type restartStorm struct {
+	Cluster string `json:"cluster"`
+	Since   string `json:"since"`
+	// Scope is app when the restarting Pods run on healthy nodes, node when they all run on nodes under pressure or
+	// not ready, mixed when both happen and none when no Pod restarted.
+	Scope     string          `json:"scope"`
+	Summary   string          `json:"summary"`
+	Owners    []ownerRestarts `json:"owners"`
+	Nodes     []nodeRestarts  `json:"nodes"`
+	Pods      []restartingPod `json:"pods"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// detectRestartStorms finds the Pods of a cluster whose containers restarted in the window, groups them by owner and
+// node, and correlates them with the conditions of the nodes to tell whether the restarts come from the applications
+// or from the nodes.
+func (t *Tools) detectRestartStorms(ctx context.Context, toolReq *mcp.CallToolRequest, params detectRestartStormsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("detectRestartStorms called")
+
+	hours := cmp.Or(params.Hours, defaultStormHours)
+	if hours < 1 || hours > maxDigestHours {
+		return nil, nil, fmt.Errorf("hours must be between 1 and %d", maxDigestHours)
+	}
+	minRestarts := cmp.Or(params.MinRestarts, defaultStormRestarts)
+	if minRestarts < 1 {
+		return nil, nil, fmt.Errorf("minRestarts must be positive")
+	}
+
+	nodes, pods, err := t.nodesAndPods(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get nodes and pods", zap.String("tool", "detectRestartStorms"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	since := now().Add(-time.Duration(hours) * time.Hour)
+	var restarting []restartingPod
+	for _, pod := range pods {
+		if params.Namespace != "" && pod.Namespace != params.Namespace {
+			continue
+		}
+		if p, ok := newRestartingPod(pod, since); ok && p.Restarts >= minRestarts {
+			restarting = append(restarting, p)
+		}
+	}
+
+	storm := newRestartStorm(params.Cluster, nodes, restarting)
+	storm.Since = since.UTC().Format(time.RFC3339)
+	response, err := json.Marshal(storm)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "detectRestartStorms"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newRestartingPod returns the restarts of the containers of the Pod whose last termination is after since. It returns
+// false if none restarted since then.
+func newRestartingPod(pod corev1.Pod, since time.Time) (restartingPod, bool) {
+	kind, name := utils.PodWorkload(pod)
+	p := restartingPod{Namespace: pod.Namespace, Name: pod.Name, Node: pod.Spec.NodeName, Owner: kind + "/" + name}
+	var last time.Time
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		terminated := status.LastTerminationState.Terminated
+		if status.RestartCount == 0 || terminated == nil || terminated.FinishedAt.Time.Before(since) {
+			continue
+		}
+		p.Restarts += status.RestartCount
+		if terminated.FinishedAt.After(last) {
+			last = terminated.FinishedAt.Time
+			p.Reason = terminated.Reason
+		}
+	}
+	if p.Restarts == 0 {
+		return restartingPod{}, false
+	}
+	p.LastRestart = last.UTC().Format(time.RFC3339)
+
+	return p, true
+}
+
+// newRestartStorm groups the restarting Pods by owner and node. The restarts of a workload are attributed to the nodes
+// when all its restarting Pods run on nodes under pressure or not ready, the nodes killing or evicting the containers
+// of every workload they run.
+func newRestartStorm(cluster string, nodes []corev1.Node, pods []restartingPod) restartStorm {
+	conditions := map[string][]string{}
+	for _, node := range nodes {
+		conditions[node.Name] = []string{}
+		for _, condition := range node.Status.Conditions {
+			switch {
+			case condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue:
+				conditions[node.Name] = append(conditions[node.Name], "NotReady")
+			case slices.Contains(pressureConditions, condition.Type) && condition.Status == corev1.ConditionTrue:
+				conditions[node.Name] = append(conditions[node.Name], string(condition.Type))
+			}
+		}
+	}
+
+	owners := map[string]*ownerRestarts{}
+	byNode := map[string]*nodeRestarts{}
+	ownersByNode := map[string]map[string]bool{}
+	for _, pod := range pods {
+		key := pod.Namespace + "/" + pod.Owner
+		if owners[key] == nil {
+			owners[key] = &ownerRestarts{Namespace: pod.Namespace, Owner: pod.Owner, Nodes: []string{}, Scope: stormScopeNode}
+		}
+		owner := owners[key]
+		owner.Pods++
+		owner.Restarts += pod.Restarts
+		if !slices.Contains(owner.Nodes, pod.Node) {
+			owner.Nodes = append(owner.Nodes, pod.Node)
+		}
+		if len(conditions[pod.Node]) == 0 {
+			owner.Scope = stormScopeApp
+		}
+
+		if byNode[pod.Node] == nil {
+			byNode[pod.Node] = &nodeRestarts{Node: pod.Node, Conditions: append([]string{}, conditions[pod.Node]...)}
+			ownersByNode[pod.Node] = map[string]bool{}
+		}
+		byNode[pod.Node].Pods++
+		byNode[pod.Node].Restarts += pod.Restarts
+		ownersByNode[pod.Node][key] = true
+	}
+
+	storm := restartStorm{Cluster: cluster, Scope: stormScopeNone, Owners: []ownerRestarts{}, Nodes: []nodeRestarts{}, Pods: []restartingPod{}}
+	appOwners, nodeOwners := 0, 0
+	for _, key := range slices.Sorted(maps.Keys(owners)) {
+		slices.Sort(owners[key].Nodes)
+		storm.Owners = append(storm.Owners, *owners[key])
+		if owners[key].Scope == stormScopeApp {
+			appOwners++
+		} else {
+			nodeOwners++
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(byNode)) {
+		byNode[name].Owners = len(ownersByNode[name])
+		storm.Nodes = append(storm.Nodes, *byNode[name])
+	}
+	slices.SortStableFunc(storm.Owners, func(a, b ownerRestarts) int {
+		return cmp.Compare(b.Restarts, a.Restarts)
+	})
+	slices.SortStableFunc(storm.Nodes, func(a, b nodeRestarts) int {
+		return cmp.Compare(b.Restarts, a.Restarts)
+	})
+	storm.Pods = append(storm.Pods, pods...)
+	slices.SortStableFunc(storm.Pods, func(a, b restartingPod) int {
+		return cmp.Or(cmp.Compare(b.Restarts, a.Restarts), cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	if len(storm.Pods) > maxStormPods {
+		storm.Pods = storm.Pods[:maxStormPods]
+		storm.Truncated = true
+	}
+
+	var unhealthy []string
+	for _, n := range storm.Nodes {
+		if len(n.Conditions) > 0 {
+			unhealthy = append(unhealthy, n.Node)
+		}
+	}
+	slices.Sort(unhealthy)
+	switch {
+	case len(pods) == 0:
+		storm.Summary = "no pod restarted in the window"
+	case appOwners == 0:
+		storm.Scope = stormScopeNode
+		storm.Summary = fmt.Sprintf("the %d restarting workloads only restart on nodes under pressure or not ready (%s), check these nodes first", nodeOwners, strings.Join(unhealthy, ", "))
+	case nodeOwners == 0:
+		storm.Scope = stormScopeApp
+		storm.Summary = fmt.Sprintf("the %d restarting workloads restart on healthy nodes, check the workloads restarting the most with analyzeCrashLoop", appOwners)
+	default:
+		storm.Scope = stormScopeMixed
+		storm.Summary = fmt.Sprintf("%d restarting workloads only restart on nodes under pressure or not ready (%s) and %d restart on healthy nodes, check these nodes and the other workloads with analyzeCrashLoop",
+			nodeOwners, strings.Join(unhealthy, ", "), appOwners)
+	}
+
+	return storm
+}
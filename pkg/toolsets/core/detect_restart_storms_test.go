@@ -0,0 +1,144 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func newRestartingTestPod(name string, namespace string, node string, owner string, restarts int32, reason string, finishedAt string) *corev1.Pod {
+	finished, _ := time.Parse(time.RFC3339, finishedAt)
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			Labels:          map[string]string{"pod-template-hash": "abc"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner, Controller: ptr.To(true)}},
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "app",
+				RestartCount:         restarts,
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, FinishedAt: metav1.NewTime(finished)}},
+			}},
+		},
+	}
+}
+
+func TestDetectRestartStorms(t *testing.T) {
+	now = func() time.Time { return time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	pressured := newCapacityNode("node-2", "4", "8Gi")
+	pressured.Status.Conditions = []corev1.NodeCondition{
+		{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+	}
+	objects := []runtime.Object{
+		newCapacityNode("node-1", "4", "8Gi"),
+		pressured,
+		newRestartingTestPod("web-abc-1", "shop", "node-1", "web-abc", 5, "Error", "2025-10-01T11:30:00Z"),
+		newRestartingTestPod("web-abc-2", "shop", "node-2", "web-abc", 4, "Error", "2025-10-01T11:40:00Z"),
+		newRestartingTestPod("cache-abc-1", "data", "node-2", "cache-abc", 3, "OOMKilled", "2025-10-01T11:50:00Z"),
+		newRestartingTestPod("old-abc-1", "shop", "node-1", "old-abc", 10, "Error", "2025-10-01T09:00:00Z"),
+		newRestartingTestPod("few-abc-1", "shop", "node-1", "few-abc", 1, "Error", "2025-10-01T11:55:00Z"),
+	}
+
+	tests := map[string]struct {
+		params         detectRestartStormsParams
+		expectedResult string
+		expectedError  string
+	}{
+		"all namespaces": {
+			params: detectRestartStormsParams{Cluster: "local"},
+			expectedResult: `{"cluster":"local","since":"2025-10-01T11:00:00Z","scope":"mixed",
+				"summary":"1 restarting workloads only restart on nodes under pressure or not ready (node-2) and 1 restart on healthy nodes, check these nodes and the other workloads with analyzeCrashLoop",
+				"owners":[
+					{"namespace":"shop","owner":"Deployment/web","pods":2,"restarts":9,"nodes":["node-1","node-2"],"scope":"app"},
+					{"namespace":"data","owner":"Deployment/cache","pods":1,"restarts":3,"nodes":["node-2"],"scope":"node"}],
+				"nodes":[
+					{"node":"node-2","pods":2,"restarts":7,"owners":2,"conditions":["MemoryPressure"]},
+					{"node":"node-1","pods":1,"restarts":5,"owners":1,"conditions":[]}],
+				"pods":[
+					{"namespace":"shop","name":"web-abc-1","node":"node-1","owner":"Deployment/web","restarts":5,"lastRestart":"2025-10-01T11:30:00Z","reason":"Error"},
+					{"namespace":"shop","name":"web-abc-2","node":"node-2","owner":"Deployment/web","restarts":4,"lastRestart":"2025-10-01T11:40:00Z","reason":"Error"},
+					{"namespace":"data","name":"cache-abc-1","node":"node-2","owner":"Deployment/cache","restarts":3,"lastRestart":"2025-10-01T11:50:00Z","reason":"OOMKilled"}]}`,
+		},
+		"node-level in a namespace": {
+			params: detectRestartStormsParams{Cluster: "local", Namespace: "data"},
+			expectedResult: `{"cluster":"local","since":"2025-10-01T11:00:00Z","scope":"node",
+				"summary":"the 1 restarting workloads only restart on nodes under pressure or not ready (node-2), check these nodes first",
+				"owners":[{"namespace":"data","owner":"Deployment/cache","pods":1,"restarts":3,"nodes":["node-2"],"scope":"node"}],
+				"nodes":[{"node":"node-2","pods":1,"restarts":3,"owners":1,"conditions":["MemoryPressure"]}],
+				"pods":[{"namespace":"data","name":"cache-abc-1","node":"node-2","owner":"Deployment/cache","restarts":3,"lastRestart":"2025-10-01T11:50:00Z","reason":"OOMKilled"}]}`,
+		},
+		"longer window and lower minimum": {
+			params: detectRestartStormsParams{Cluster: "local", Namespace: "shop", Hours: 4, MinRestarts: 1},
+			expectedResult: `{"cluster":"local","since":"2025-10-01T08:00:00Z","scope":"app",
+				"summary":"the 3 restarting workloads restart on healthy nodes, check the workloads restarting the most with analyzeCrashLoop",
+				"owners":[
+					{"namespace":"shop","owner":"Deployment/old","pods":1,"restarts":10,"nodes":["node-1"],"scope":"app"},
+					{"namespace":"shop","owner":"Deployment/web","pods":2,"restarts":9,"nodes":["node-1","node-2"],"scope":"app"},
+					{"namespace":"shop","owner":"Deployment/few","pods":1,"restarts":1,"nodes":["node-1"],"scope":"app"}],
+				"nodes":[
+					{"node":"node-1","pods":3,"restarts":16,"owners":3,"conditions":[]},
+					{"node":"node-2","pods":1,"restarts":4,"owners":1,"conditions":["MemoryPressure"]}],
+				"pods":[
+					{"namespace":"shop","name":"old-abc-1","node":"node-1","owner":"Deployment/old","restarts":10,"lastRestart":"2025-10-01T09:00:00Z","reason":"Error"},
+					{"namespace":"shop","name":"web-abc-1","node":"node-1","owner":"Deployment/web","restarts":5,"lastRestart":"2025-10-01T11:30:00Z","reason":"Error"},
+					{"namespace":"shop","name":"web-abc-2","node":"node-2","owner":"Deployment/web","restarts":4,"lastRestart":"2025-10-01T11:40:00Z","reason":"Error"},
+					{"namespace":"shop","name":"few-abc-1","node":"node-1","owner":"Deployment/few","restarts":1,"lastRestart":"2025-10-01T11:55:00Z","reason":"Error"}]}`,
+		},
+		"no restarts": {
+			params: detectRestartStormsParams{Cluster: "local", Namespace: "empty"},
+			expectedResult: `{"cluster":"local","since":"2025-10-01T11:00:00Z","scope":"none","summary":"no pod restarted in the window",
+				"owners":[],"nodes":[],"pods":[]}`,
+		},
+		"invalid hours": {
+			params:        detectRestartStormsParams{Cluster: "local", Hours: 1000},
+			expectedError: "hours must be between 1 and 168",
+		},
+		"invalid minimum": {
+			params:        detectRestartStormsParams{Cluster: "local", MinRestarts: -1},
+			expectedError: "minRestarts must be positive",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(nodeScheme(), objects...)
+			c := &client.Client{
+				DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+			result, _, err := tools.detectRestartStorms(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
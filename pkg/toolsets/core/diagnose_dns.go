@@ -0,0 +1,687 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	dnsNamespace = "kube-system"
+	// dnsSelector is the label of the CoreDNS Deployments and Services of RKE2, K3s and RKE1 clusters.
+	dnsSelector = "k8s-app=kube-dns"
+	// defaultDNSTestName is resolved when no name is given, it only depends on CoreDNS and the API server.
+	defaultDNSTestName = "kubernetes.default.svc.cluster.local"
+	// nodeLocalDNSAddress is the address of NodeLocal DNSCache, used by the Pods instead of the kube-dns Service.
+	nodeLocalDNSAddress = "169.254.20.10"
+	// dnsDebugPodImage is the image of the debug Pod, busybox has nslookup and a shell.
+	dnsDebugPodImage = "busybox:1.36"
+
+	// maxDNSLogPods is the maximum number of CoreDNS Pods whose logs are searched.
+	maxDNSLogPods = 2
+	// dnsLogTailLines is the number of error log lines returned for each CoreDNS Pod.
+	dnsLogTailLines int64 = 20
+	// dnsLogFilter matches the errors and warnings logged by CoreDNS, e.g. the timeouts of the upstream servers.
+	dnsLogFilter = `\[(ERROR|WARNING|FATAL)\]`
+)
+
+// dnsDebugPodPollInterval and dnsDebugPodTimeout bound the wait for the debug Pod to resolve the name. They're
+// variables to be shortened by the tests.
+var (
+	dnsDebugPodPollInterval = time.Second
+	dnsDebugPodTimeout      = 30 * time.Second
+)
+
+// defaultCorefilePlugins are the plugins of the Corefile shipped with Kubernetes distributions. The other plugins
+// are reported as customizations.
+var defaultCorefilePlugins = []string{"errors", "health", "ready", "kubernetes", "prometheus", "forward", "cache", "loop", "reload", "loadbalance"}
+
+// defaultCorefileDirectives are the other directives of the default Corefile of K3s, which reads the hosts of the
+// nodes and imports the coredns-custom ConfigMap.
+var defaultCorefileDirectives = []string{"hosts /etc/coredns/NodeHosts", "import /etc/coredns/custom/*.override"}
+
+type diagnoseDNSParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster to diagnose"`
+	Name      string `json:"name,omitempty" jsonschema:"the name resolved by the test, e.g. my-service.my-namespace or example.com. Defaults to kubernetes.default.svc.cluster.local"`
+	Pod       string `json:"pod,omitempty" jsonschema:"a Pod where the name is resolved with nslookup or getent, which must be allowed by the exec allowlist"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the Pod, or of the debug Pod. Defaults to default"`
+	Container string `json:"container,omitempty" jsonschema:"the container of the Pod where the name is resolved. Defaults to its first container"`
+	DebugPod  bool   `json:"debugPod,omitempty" jsonschema:"resolve the name from a short-lived busybox Pod, deleted after the test"`
+}
+
+// dnsDiagnosis is the health of the cluster DNS, its customizations and the result of the resolution of a name.
+type dnsDiagnosis struct {
+	Name        string          `json:"name"`
+	Deployments []dnsDeployment `json:"deployments"`
+	Services    []dnsService    `json:"services"`
+	Corefiles   []dnsCorefile   `json:"corefiles"`
+	Resolution  *dnsResolution  `json:"resolution,omitempty"`
+	Hints       []string        `json:"hints"`
+}
+
+// dnsDeployment is a CoreDNS Deployment with its Pods.
+type dnsDeployment struct {
+	Name              string   `json:"name"`
+	Replicas          int32    `json:"replicas"`
+	ReadyReplicas     int32    `json:"readyReplicas"`
+	AvailableReplicas int32    `json:"availableReplicas"`
+	Pods              []dnsPod `json:"pods"`
+	// Logs are the last errors and warnings logged by the Pods of the Deployment.
+	Logs       map[string]string `json:"logs,omitempty"`
+	configMaps []string
+}
+
+type dnsPod struct {
+	Name     string `json:"name"`
+	Node     string `json:"node,omitempty"`
+	Phase    string `json:"phase"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+}
+
+// dnsService is a Service of the cluster DNS, its ClusterIP is the nameserver of the Pods.
+type dnsService struct {
+	Name              string `json:"name"`
+	ClusterIP         string `json:"clusterIP"`
+	ReadyEndpoints    int    `json:"readyEndpoints"`
+	NotReadyEndpoints int    `json:"notReadyEndpoints"`
+}
+
+// dnsCorefile is a ConfigMap mounted in CoreDNS with the changes made to the Corefile shipped with the distribution.
+type dnsCorefile struct {
+	ConfigMap      string   `json:"configMap"`
+	Exists         bool     `json:"exists"`
+	Corefile       string   `json:"corefile,omitempty"`
+	Customizations []string `json:"customizations,omitempty"`
+	Issues         []string `json:"issues,omitempty"`
+}
+
+// dnsResolution is the result of the resolution of the name from a Pod.
+type dnsResolution struct {
+	Pod         string   `json:"pod"`
+	Namespace   string   `json:"namespace"`
+	Command     string   `json:"command"`
+	Resolved    bool     `json:"resolved"`
+	ExitCode    int      `json:"exitCode"`
+	Output      string   `json:"output"`
+	Nameservers []string `json:"nameservers,omitempty"`
+	Search      []string `json:"search,omitempty"`
+}
+
+// diagnoseDNS checks the CoreDNS Deployments, Services and ConfigMaps of a cluster, and resolves a name from a Pod
+// or a debug Pod, to find where the resolution breaks.
+func (t *Tools) diagnoseDNS(ctx context.Context, toolReq *mcp.CallToolRequest, params diagnoseDNSParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("diagnoseDNS called")
+
+	if params.Pod != "" && params.DebugPod {
+		return nil, nil, fmt.Errorf("pod and debugPod can't be used together")
+	}
+	if params.DebugPod && t.ReadOnly {
+		return nil, nil, fmt.Errorf("debugPod creates a Pod, it isn't allowed in read-only mode. Use pod to resolve the name from an existing Pod")
+	}
+	name := params.Name
+	if name == "" {
+		name = defaultDNSTestName
+	}
+	namespace := params.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	diagnosis := dnsDiagnosis{Name: name, Deployments: []dnsDeployment{}, Services: []dnsService{}, Corefiles: []dnsCorefile{}, Hints: []string{}}
+
+	deployments, err := t.dnsDeployments(ctx, toolReq, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get CoreDNS Deployments", zap.String("tool", "diagnoseDNS"), zap.Error(err))
+		return nil, nil, err
+	}
+	diagnosis.Deployments = deployments
+	services, err := t.dnsServices(ctx, toolReq, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get CoreDNS Services", zap.String("tool", "diagnoseDNS"), zap.Error(err))
+		return nil, nil, err
+	}
+	diagnosis.Services = services
+	for _, deployment := range deployments {
+		for _, configMap := range deployment.configMaps {
+			if slices.ContainsFunc(diagnosis.Corefiles, func(c dnsCorefile) bool { return c.ConfigMap == configMap }) {
+				continue
+			}
+			corefile, err := t.dnsCorefile(ctx, toolReq, params.Cluster, configMap)
+			if err != nil {
+				middleware.Logger(ctx).Error("failed to get CoreDNS ConfigMap", zap.String("tool", "diagnoseDNS"), zap.Error(err))
+				return nil, nil, err
+			}
+			diagnosis.Corefiles = append(diagnosis.Corefiles, corefile)
+		}
+	}
+
+	switch {
+	case params.Pod != "":
+		diagnosis.Resolution, err = t.resolveInPod(ctx, toolReq, params.Cluster, namespace, params.Pod, params.Container, name)
+	case params.DebugPod:
+		diagnosis.Resolution, err = t.resolveInDebugPod(ctx, toolReq, params.Cluster, namespace, name)
+	}
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to resolve name", zap.String("tool", "diagnoseDNS"), zap.Error(err))
+		return nil, nil, err
+	}
+	diagnosis.Hints = dnsHints(diagnosis)
+
+	response, err := json.Marshal(diagnosis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "diagnoseDNS"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// dnsDeployments returns the CoreDNS Deployments with their Pods, the error logs of the Pods and the ConfigMaps
+// mounted in them.
+func (t *Tools) dnsDeployments(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string) ([]dnsDeployment, error) {
+	listParams := client.ListParams{
+		Cluster:       cluster,
+		Kind:          "deployment",
+		Namespace:     dnsNamespace,
+		LabelSelector: dnsSelector,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+	}
+	unstructuredDeployments, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployments: %w", err)
+	}
+	listParams.Kind = "pod"
+	unstructuredPods, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods: %w", err)
+	}
+	pods := make([]corev1.Pod, 0, len(unstructuredPods))
+	for _, obj := range unstructuredPods {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+	slices.SortFunc(pods, func(a, b corev1.Pod) int { return strings.Compare(a.Name, b.Name) })
+
+	deployments := []dnsDeployment{}
+	for _, obj := range unstructuredDeployments {
+		var deployment appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Deployment: %w", err)
+		}
+		result := dnsDeployment{
+			Name:              deployment.Name,
+			Replicas:          ptr.Deref(deployment.Spec.Replicas, 1),
+			ReadyReplicas:     deployment.Status.ReadyReplicas,
+			AvailableReplicas: deployment.Status.AvailableReplicas,
+			Pods:              []dnsPod{},
+		}
+		for _, volume := range deployment.Spec.Template.Spec.Volumes {
+			if volume.ConfigMap != nil {
+				result.configMaps = append(result.configMaps, volume.ConfigMap.Name)
+			}
+		}
+
+		var deploymentPods []corev1.Pod
+		for _, pod := range pods {
+			if deployment.Spec.Selector == nil || !selectorMatches(deployment.Spec.Selector, pod.Labels) {
+				continue
+			}
+			deploymentPods = append(deploymentPods, pod)
+			p := dnsPod{Name: pod.Name, Node: pod.Spec.NodeName, Phase: string(pod.Status.Phase), Ready: isPodReady(pod)}
+			for _, status := range pod.Status.ContainerStatuses {
+				p.Restarts += status.RestartCount
+			}
+			result.Pods = append(result.Pods, p)
+		}
+		result.Logs, err = t.dnsLogs(ctx, toolReq, cluster, deploymentPods)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, result)
+	}
+	slices.SortFunc(deployments, func(a, b dnsDeployment) int { return strings.Compare(a.Name, b.Name) })
+
+	return deployments, nil
+}
+
+// dnsLogs returns the last errors and warnings logged by the first Pods of a CoreDNS Deployment, indexed by Pod name.
+func (t *Tools) dnsLogs(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, pods []corev1.Pod) (map[string]string, error) {
+	logs := map[string]string{}
+	for i, pod := range pods {
+		if i == maxDNSLogPods {
+			break
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		podLogs, err := t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), cluster, middleware.Token(ctx), pod, getPodLogsParams{
+			TailLines: dnsLogTailLines,
+			Filter:    dnsLogFilter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		containers, _ := podLogs.Object["pod-logs"].(map[string]any)
+		for _, container := range pod.Spec.Containers {
+			if l, _ := containers[container.Name].(string); l != "" {
+				lines = append(lines, l)
+			}
+		}
+		if len(lines) > 0 {
+			logs[pod.Name] = strings.Join(lines, "\n")
+		}
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	return logs, nil
+}
+
+// dnsServices returns the Services of the cluster DNS with the number of ready endpoints of their EndpointSlices.
+func (t *Tools) dnsServices(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string) ([]dnsService, error) {
+	unstructuredServices, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "service",
+		Namespace:     dnsNamespace,
+		LabelSelector: dnsSelector,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+
+	services := []dnsService{}
+	for _, obj := range unstructuredServices {
+		var service corev1.Service
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &service); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Service: %w", err)
+		}
+		result := dnsService{Name: service.Name, ClusterIP: service.Spec.ClusterIP}
+		endpointSlices, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:       cluster,
+			Kind:          "endpointslices",
+			Namespace:     dnsNamespace,
+			LabelSelector: discoveryv1.LabelServiceName + "=" + service.Name,
+			URL:           toolReq.Extra.Header.Get(urlHeader),
+			Token:         middleware.Token(ctx),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the endpointslices of Service %s: %w", service.Name, err)
+		}
+		for _, obj := range endpointSlices {
+			var endpointSlice discoveryv1.EndpointSlice
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &endpointSlice); err != nil {
+				return nil, fmt.Errorf("failed to convert unstructured object to EndpointSlice: %w", err)
+			}
+			for _, endpoint := range endpointSlice.Endpoints {
+				if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+					result.ReadyEndpoints++
+				} else {
+					result.NotReadyEndpoints++
+				}
+			}
+		}
+		services = append(services, result)
+	}
+	slices.SortFunc(services, func(a, b dnsService) int { return strings.Compare(a.Name, b.Name) })
+
+	return services, nil
+}
+
+// dnsCorefile returns a ConfigMap mounted in CoreDNS with the customizations of its Corefile. The other keys are
+// files imported by the Corefile, like the *.server and *.override keys of the coredns-custom ConfigMap of K3s.
+func (t *Tools) dnsCorefile(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, name string) (dnsCorefile, error) {
+	result := dnsCorefile{ConfigMap: name}
+	obj, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "configmap",
+		Namespace: dnsNamespace,
+		Name:      name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if apierrors.IsNotFound(err) {
+		// the ConfigMaps of the optional customizations don't exist until they're created
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to get ConfigMap %s: %w", name, err)
+	}
+	var configMap corev1.ConfigMap
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &configMap); err != nil {
+		return result, fmt.Errorf("failed to convert unstructured object to ConfigMap: %w", err)
+	}
+	result.Exists = true
+
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if key != "Corefile" {
+			result.Customizations = append(result.Customizations, fmt.Sprintf("%s: %s", key, strings.Join(strings.Fields(configMap.Data[key]), " ")))
+			continue
+		}
+		result.Corefile = configMap.Data[key]
+		customizations, issues := parseCorefile(result.Corefile)
+		result.Customizations = append(result.Customizations, customizations...)
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	return result, nil
+}
+
+// parseCorefile returns the plugins of a Corefile that aren't in the default Corefile, the servers of other zones (e.g.
+// stub domains) and the forwarding to other upstream servers than the nameservers of the nodes, and the issues
+// breaking the resolution of the cluster or external names.
+func parseCorefile(corefile string) ([]string, []string) {
+	var customizations, issues []string
+	var zones []string
+	rootPlugins := map[string]bool{}
+	depth := 0
+	scanner := bufio.NewScanner(strings.NewReader(corefile))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(line, "{"))
+		switch {
+		case depth == 0 && strings.HasSuffix(line, "{"):
+			zones = fields
+			if len(fields) > 0 && !slices.Contains(fields, ".") && !slices.Contains(fields, ".:53") {
+				customizations = append(customizations, fmt.Sprintf("server for %s", strings.Join(fields, " ")))
+			}
+		case depth == 1 && len(fields) > 0 && !strings.HasPrefix(line, "}"):
+			plugin := fields[0]
+			root := slices.Contains(zones, ".") || slices.Contains(zones, ".:53")
+			if root {
+				rootPlugins[plugin] = true
+			}
+			upstreams := fields[min(2, len(fields)):]
+			if !slices.Contains(defaultCorefileDirectives, strings.Join(fields, " ")) && (!root || !slices.Contains(defaultCorefilePlugins, plugin) ||
+				(plugin == "forward" && !slices.Equal(upstreams, []string{"/etc/resolv.conf"}))) {
+				customizations = append(customizations, fmt.Sprintf("%s: %s", strings.Join(zones, " "), strings.Join(fields, " ")))
+			}
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+	}
+
+	if len(zones) > 0 && len(rootPlugins) == 0 {
+		issues = append(issues, "the Corefile doesn't have a server for the root zone '.', only the names of its zones are resolved")
+		return customizations, issues
+	}
+	if len(rootPlugins) > 0 && !rootPlugins["kubernetes"] {
+		issues = append(issues, "the kubernetes plugin is missing, the names of the Services and Pods aren't resolved")
+	}
+	if len(rootPlugins) > 0 && !rootPlugins["forward"] {
+		issues = append(issues, "the forward plugin is missing, the names outside the cluster aren't resolved")
+	}
+
+	return customizations, issues
+}
+
+// resolveInPod resolves the name in a container of a Pod with the first allowed resolver command, and reads its
+// /etc/resolv.conf when cat is allowed. The containers of a Pod share its resolv.conf, so the first one is used
+// unless a container is given.
+func (t *Tools) resolveInPod(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string, name string, container string, host string) (*dnsResolution, error) {
+	var command []string
+	for _, c := range [][]string{{"nslookup", host}, {"getent", "hosts", host}} {
+		if commandAllowed(t.ExecAllowlist, c) {
+			command = c
+			break
+		}
+	}
+	if command == nil {
+		return nil, fmt.Errorf("resolving a name in a Pod requires 'nslookup *' or 'getent hosts *' in the exec allowlist, allowed commands are: %s", strings.Join(t.ExecAllowlist, ", "))
+	}
+
+	podResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "pod",
+		Namespace: namespace,
+		Name:      name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+		return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+	}
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+	if !slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == container }) {
+		return nil, fmt.Errorf("container %s not found in pod %s", container, pod.Name)
+	}
+
+	resolution := &dnsResolution{Pod: name, Namespace: namespace, Command: strings.Join(command, " ")}
+	output, exitCode, err := t.execDNSCommand(ctx, toolReq, cluster, namespace, name, container, command)
+	if err != nil {
+		return nil, err
+	}
+	resolution.Output, resolution.ExitCode, resolution.Resolved = output, exitCode, exitCode == 0
+
+	resolvConf := []string{"cat", "/etc/resolv.conf"}
+	if commandAllowed(t.ExecAllowlist, resolvConf) {
+		output, exitCode, err := t.execDNSCommand(ctx, toolReq, cluster, namespace, name, container, resolvConf)
+		if err != nil {
+			return nil, err
+		}
+		if exitCode == 0 {
+			resolution.Nameservers, resolution.Search = parseResolvConf(output)
+		}
+	}
+
+	return resolution, nil
+}
+
+// execDNSCommand runs a command in a container and returns its stdout and stderr, and its exit code.
+func (t *Tools) execDNSCommand(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string, name string, container string, command []string) (string, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: execMaxOutputBytes}
+	stderr := &limitedBuffer{limit: execMaxOutputBytes}
+	err := t.client.ExecInPod(ctx, client.ExecParams{
+		Cluster:   cluster,
+		Namespace: namespace,
+		Name:      name,
+		Container: container,
+		Command:   command,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}, stdout, stderr)
+	exitCode := 0
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitStatus()
+	} else if err != nil {
+		return "", 0, fmt.Errorf("failed to run %s: %w", strings.Join(command, " "), err)
+	}
+
+	return strings.TrimSpace(stdout.buf.String() + "\n" + stderr.buf.String()), exitCode, nil
+}
+
+// resolveInDebugPod resolves the name with nslookup in a busybox Pod, which prints its /etc/resolv.conf first. The
+// Pod is deleted once it terminates or the wait times out.
+func (t *Tools) resolveInDebugPod(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string, host string) (*dnsResolution, error) {
+	clientset, err := t.client.CreateClientSet(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	// the name is passed as $0 of the script, so it isn't interpreted by the shell
+	command := []string{"sh", "-c", `cat /etc/resolv.conf && echo --- && nslookup "$0"`, host}
+	pod, err := clientset.CoreV1().Pods(namespace).Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "diagnose-dns-",
+			Namespace:    namespace,
+			Labels:       map[string]string{"app.kubernetes.io/name": "diagnose-dns", "app.kubernetes.io/managed-by": "rancher-ai-mcp"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers:    []corev1.Container{{Name: "nslookup", Image: dnsDebugPodImage, Command: command}},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the debug Pod: %w", err)
+	}
+	defer func() {
+		// the Pod is deleted even if the request was canceled
+		if err := clientset.CoreV1().Pods(namespace).Delete(context.WithoutCancel(ctx), pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			middleware.Logger(ctx).Error("failed to delete the debug Pod", zap.String("tool", "diagnoseDNS"), zap.String("pod", pod.Name), zap.Error(err))
+		}
+	}()
+
+	err = wait.PollUntilContextTimeout(ctx, dnsDebugPodPollInterval, dnsDebugPodTimeout, true, func(ctx context.Context) (bool, error) {
+		pod, err = clientset.CoreV1().Pods(namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed, nil
+	})
+	if wait.Interrupted(err) {
+		return nil, fmt.Errorf("the debug Pod %s didn't terminate in %s, its image %s may not be pullable from the cluster", pod.Name, dnsDebugPodTimeout, dnsDebugPodImage)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the debug Pod %s: %w", pod.Name, err)
+	}
+
+	logs, err := t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), cluster, middleware.Token(ctx), *pod, getPodLogsParams{})
+	if err != nil {
+		return nil, err
+	}
+	containers, _ := logs.Object["pod-logs"].(map[string]any)
+	output, _ := containers["nslookup"].(string)
+	resolution := &dnsResolution{Pod: pod.Name, Namespace: namespace, Command: "nslookup " + host}
+	if resolvConf, lookup, ok := strings.Cut(output, "---\n"); ok {
+		resolution.Nameservers, resolution.Search = parseResolvConf(resolvConf)
+		output = lookup
+	}
+	resolution.Output = strings.TrimSpace(output)
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			resolution.ExitCode = int(status.State.Terminated.ExitCode)
+		}
+	}
+	resolution.Resolved = pod.Status.Phase == corev1.PodSucceeded && resolution.ExitCode == 0
+
+	return resolution, nil
+}
+
+// parseResolvConf returns the nameservers and the search domains of a resolv.conf file.
+func parseResolvConf(resolvConf string) ([]string, []string) {
+	var nameservers, search []string
+	for _, line := range strings.Split(resolvConf, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			nameservers = append(nameservers, fields[1])
+		case "search":
+			search = fields[1:]
+		}
+	}
+
+	return nameservers, search
+}
+
+// dnsHints explains where the resolution breaks from the health of CoreDNS, its Corefile and the resolution test.
+func dnsHints(diagnosis dnsDiagnosis) []string {
+	var hints []string
+	if len(diagnosis.Deployments) == 0 {
+		hints = append(hints, fmt.Sprintf("No CoreDNS Deployment with the label %s was found in %s, the cluster DNS may be provided by another server.", dnsSelector, dnsNamespace))
+	}
+	for _, deployment := range diagnosis.Deployments {
+		if deployment.ReadyReplicas == 0 {
+			hints = append(hints, fmt.Sprintf("The CoreDNS Deployment %s has no ready Pods, no name can be resolved in the cluster. Check its Pods with inspectPod.", deployment.Name))
+		} else if deployment.ReadyReplicas < deployment.Replicas {
+			hints = append(hints, fmt.Sprintf("The CoreDNS Deployment %s has %d of %d Pods ready, some queries may time out.", deployment.Name, deployment.ReadyReplicas, deployment.Replicas))
+		}
+		for _, pod := range deployment.Pods {
+			if pod.Restarts > 0 {
+				hints = append(hints, fmt.Sprintf("The CoreDNS Pod %s restarted %d times, a crash loop is often caused by a forwarding loop to itself detected by the loop plugin.", pod.Name, pod.Restarts))
+			}
+		}
+		if len(deployment.Logs) > 0 {
+			hints = append(hints, fmt.Sprintf("The CoreDNS Pods of %s logged errors, i/o timeouts mean the upstream servers aren't reachable from the Pods.", deployment.Name))
+		}
+	}
+	if len(diagnosis.Deployments) > 0 && len(diagnosis.Services) == 0 {
+		hints = append(hints, fmt.Sprintf("No Service with the label %s was found in %s, the nameserver of the Pods doesn't reach CoreDNS.", dnsSelector, dnsNamespace))
+	}
+	for _, service := range diagnosis.Services {
+		if service.ReadyEndpoints == 0 {
+			hints = append(hints, fmt.Sprintf("The Service %s has no ready endpoints, the queries sent to %s are dropped.", service.Name, service.ClusterIP))
+		}
+	}
+	for _, corefile := range diagnosis.Corefiles {
+		for _, issue := range corefile.Issues {
+			hints = append(hints, fmt.Sprintf("In the ConfigMap %s, %s.", corefile.ConfigMap, issue))
+		}
+		if len(corefile.Customizations) > 0 {
+			hints = append(hints, fmt.Sprintf("The ConfigMap %s customizes CoreDNS, check that the customizations don't change the resolution of the name.", corefile.ConfigMap))
+		}
+	}
+
+	resolution := diagnosis.Resolution
+	if resolution == nil {
+		return append(hints, "The resolution wasn't tested, set pod or debugPod to resolve the name from a Pod.")
+	}
+	if resolution.Resolved {
+		return append(hints, fmt.Sprintf("The name %s resolves from the Pod %s/%s.", diagnosis.Name, resolution.Namespace, resolution.Pod))
+	}
+	var clusterIPs []string
+	for _, service := range diagnosis.Services {
+		clusterIPs = append(clusterIPs, service.ClusterIP)
+	}
+	for _, nameserver := range resolution.Nameservers {
+		if len(clusterIPs) > 0 && !slices.Contains(clusterIPs, nameserver) && nameserver != nodeLocalDNSAddress {
+			hints = append(hints, fmt.Sprintf("The Pod uses the nameserver %s instead of the cluster DNS %s, check its dnsPolicy and dnsConfig and the cluster DNS address of the kubelet.",
+				nameserver, strings.Join(clusterIPs, ", ")))
+		}
+	}
+	if strings.Contains(diagnosis.Name, ".svc") || !strings.Contains(diagnosis.Name, ".") {
+		hints = append(hints, fmt.Sprintf("The cluster name %s isn't resolved, check that the Service exists in the namespace, or that the NetworkPolicies of the Pod allow egress to CoreDNS on port 53.", diagnosis.Name))
+	} else {
+		hints = append(hints, fmt.Sprintf("The name %s isn't resolved, if the cluster names resolve the queries forwarded by CoreDNS to the upstream servers fail.", diagnosis.Name))
+	}
+
+	return hints
+}
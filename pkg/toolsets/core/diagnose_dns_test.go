@@ -0,0 +1,338 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/utils/ptr"
+)
+
+const testCorefile = `.:53 {
+    errors
+    health
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+      pods insecure
+      fallthrough in-addr.arpa ip6.arpa
+    }
+    forward . 10.0.0.53
+    cache 30
+}
+corp.example.com:53 {
+    forward . 10.1.0.53
+}`
+
+// fakeDNSExecutor prints the resolv.conf of a Pod for cat, and the answer of nslookup for the other commands.
+type fakeDNSExecutor struct {
+	url        *url.URL
+	resolvConf string
+	resolved   bool
+}
+
+func (f *fakeDNSExecutor) Stream(options remotecommand.StreamOptions) error {
+	return f.StreamWithContext(context.Background(), options)
+}
+
+func (f *fakeDNSExecutor) StreamWithContext(_ context.Context, options remotecommand.StreamOptions) error {
+	command := f.url.Query()["command"]
+	switch {
+	case command[0] == "cat":
+		fmt.Fprint(options.Stdout, f.resolvConf)
+	case f.resolved:
+		fmt.Fprintf(options.Stdout, "Server:\t\t10.43.0.10\nName:\t%s\nAddress: 10.43.0.1", command[len(command)-1])
+	default:
+		fmt.Fprintf(options.Stdout, "** server can't find %s: NXDOMAIN", command[len(command)-1])
+		return utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 1"), Code: 1}
+	}
+
+	return nil
+}
+
+func newDNSTools(clientset *fake.Clientset, executor *fakeDNSExecutor, objects ...runtime.Object) *Tools {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = discoveryv1.AddToScheme(scheme)
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "apps", Version: "v1", Resource: "deployments"}:                "DeploymentList",
+		{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}: "EndpointSliceList",
+		{Group: "", Version: "v1", Resource: "pods"}:                           "PodList",
+		{Group: "", Version: "v1", Resource: "services"}:                       "ServiceList",
+	}, objects...)
+	c := &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return clientset, nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+		ExecutorCreator: func(inConfig *rest.Config, execURL *url.URL) (remotecommand.Executor, error) {
+			executor.url = execURL
+			return executor, nil
+		},
+	}
+
+	return &Tools{client: newFakeToolsClient(c, "fakeToken")}
+}
+
+func newDNSPod(name string, ready bool, restarts int32) *corev1.Pod {
+	pod := newIngressControllerPod("kube-system", name, map[string]string{"k8s-app": "kube-dns"}, ready)
+	pod.Spec.NodeName = "node-1"
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "controller", RestartCount: restarts}}
+	return pod
+}
+
+func dnsObjects() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system", Labels: map[string]string{"k8s-app": "kube-dns"}},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](2),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+					{Name: "config-volume", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "coredns"}}}},
+					{Name: "custom-config-volume", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "coredns-custom"}}}},
+				}}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1, AvailableReplicas: 1},
+		},
+		newDNSPod("coredns-1", true, 0),
+		newDNSPod("coredns-2", false, 3),
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+			Data:       map[string]string{"Corefile": testCorefile},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-dns", Namespace: "kube-system", Labels: map[string]string{"k8s-app": "kube-dns"}},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.43.0.10", Ports: []corev1.ServicePort{{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP}}},
+		},
+		&discoveryv1.EndpointSlice{
+			TypeMeta:    metav1.TypeMeta{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice"},
+			ObjectMeta:  metav1.ObjectMeta{Name: "kube-dns-abcde", Namespace: "kube-system", Labels: map[string]string{discoveryv1.LabelServiceName: "kube-dns"}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.42.0.5"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				{Addresses: []string{"10.42.0.6"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+			},
+		},
+		newIngressControllerPod("default", "app", nil, true),
+	}
+}
+
+func TestDiagnoseDNS(t *testing.T) {
+	pollInterval, timeout := dnsDebugPodPollInterval, dnsDebugPodTimeout
+	dnsDebugPodPollInterval, dnsDebugPodTimeout = time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() {
+		dnsDebugPodPollInterval, dnsDebugPodTimeout = pollInterval, timeout
+	})
+
+	coreDNS := `"deployments": [{"name": "coredns", "replicas": 2, "readyReplicas": 1, "availableReplicas": 1, "pods": [
+			{"name": "coredns-1", "node": "node-1", "phase": "Running", "ready": true, "restarts": 0},
+			{"name": "coredns-2", "node": "node-1", "phase": "Running", "ready": false, "restarts": 3}
+		]}],
+		"services": [{"name": "kube-dns", "clusterIP": "10.43.0.10", "readyEndpoints": 1, "notReadyEndpoints": 1}],
+		"corefiles": [
+			{"configMap": "coredns", "exists": true, "corefile": ` + strconv.Quote(testCorefile) + `, "customizations": [
+				".:53: forward . 10.0.0.53",
+				"server for corp.example.com:53",
+				"corp.example.com:53: forward . 10.1.0.53"
+			]},
+			{"configMap": "coredns-custom", "exists": false}
+		]`
+	coreDNSHints := `"The CoreDNS Deployment coredns has 1 of 2 Pods ready, some queries may time out.",
+		"The CoreDNS Pod coredns-2 restarted 3 times, a crash loop is often caused by a forwarding loop to itself detected by the loop plugin.",
+		"The ConfigMap coredns customizes CoreDNS, check that the customizations don't change the resolution of the name."`
+
+	tests := map[string]struct {
+		params         diagnoseDNSParams
+		objects        []runtime.Object
+		allowlist      []string
+		readOnly       bool
+		executor       fakeDNSExecutor
+		expectedResult string
+		expectedError  string
+	}{
+		"without resolution test": {
+			params: diagnoseDNSParams{Cluster: "local"},
+			expectedResult: `{"name": "kubernetes.default.svc.cluster.local", ` + coreDNS + `, "hints": [` + coreDNSHints + `,
+				"The resolution wasn't tested, set pod or debugPod to resolve the name from a Pod."
+			]}`,
+		},
+		"resolved in a pod": {
+			params:    diagnoseDNSParams{Cluster: "local", Name: "example.com", Pod: "app"},
+			allowlist: []string{"nslookup *", "cat *"},
+			executor:  fakeDNSExecutor{resolvConf: "search default.svc.cluster.local svc.cluster.local cluster.local\nnameserver 10.43.0.10\noptions ndots:5\n", resolved: true},
+			expectedResult: `{"name": "example.com", ` + coreDNS + `, "resolution": {
+				"pod": "app", "namespace": "default", "command": "nslookup example.com", "resolved": true, "exitCode": 0,
+				"output": "Server:\t\t10.43.0.10\nName:\texample.com\nAddress: 10.43.0.1",
+				"nameservers": ["10.43.0.10"], "search": ["default.svc.cluster.local", "svc.cluster.local", "cluster.local"]
+			}, "hints": [` + coreDNSHints + `,
+				"The name example.com resolves from the Pod default/app."
+			]}`,
+		},
+		"pod using another nameserver": {
+			params:    diagnoseDNSParams{Cluster: "local", Name: "web.default.svc.cluster.local", Pod: "app"},
+			allowlist: []string{"getent hosts *", "cat *"},
+			executor:  fakeDNSExecutor{resolvConf: "nameserver 8.8.8.8\n"},
+			expectedResult: `{"name": "web.default.svc.cluster.local", ` + coreDNS + `, "resolution": {
+				"pod": "app", "namespace": "default", "command": "getent hosts web.default.svc.cluster.local", "resolved": false, "exitCode": 1,
+				"output": "** server can't find web.default.svc.cluster.local: NXDOMAIN", "nameservers": ["8.8.8.8"]
+			}, "hints": [` + coreDNSHints + `,
+				"The Pod uses the nameserver 8.8.8.8 instead of the cluster DNS 10.43.0.10, check its dnsPolicy and dnsConfig and the cluster DNS address of the kubelet.",
+				"The cluster name web.default.svc.cluster.local isn't resolved, check that the Service exists in the namespace, or that the NetworkPolicies of the Pod allow egress to CoreDNS on port 53."
+			]}`,
+		},
+		"resolved in a debug pod": {
+			params: diagnoseDNSParams{Cluster: "local", Name: "example.com", DebugPod: true},
+			expectedResult: `{"name": "example.com", ` + coreDNS + `, "resolution": {
+				"pod": "diagnose-dns-abcde", "namespace": "default", "command": "nslookup example.com", "resolved": true, "exitCode": 0,
+				"output": "fake logs"
+			}, "hints": [` + coreDNSHints + `,
+				"The name example.com resolves from the Pod default/diagnose-dns-abcde."
+			]}`,
+		},
+		"without CoreDNS": {
+			params:  diagnoseDNSParams{Cluster: "local"},
+			objects: []runtime.Object{},
+			expectedResult: `{"name": "kubernetes.default.svc.cluster.local", "deployments": [], "services": [], "corefiles": [], "hints": [
+				"No CoreDNS Deployment with the label k8s-app=kube-dns was found in kube-system, the cluster DNS may be provided by another server.",
+				"The resolution wasn't tested, set pod or debugPod to resolve the name from a Pod."
+			]}`,
+		},
+		"resolver not allowed": {
+			params:        diagnoseDNSParams{Cluster: "local", Pod: "app"},
+			allowlist:     DefaultExecAllowlist,
+			expectedError: "resolving a name in a Pod requires 'nslookup *' or 'getent hosts *' in the exec allowlist",
+		},
+		"debug pod in read-only mode": {
+			params:        diagnoseDNSParams{Cluster: "local", DebugPod: true},
+			readOnly:      true,
+			expectedError: "debugPod creates a Pod, it isn't allowed in read-only mode",
+		},
+		"pod and debug pod": {
+			params:        diagnoseDNSParams{Cluster: "local", Pod: "app", DebugPod: true},
+			expectedError: "pod and debugPod can't be used together",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			// the kubelet runs the debug Pod, which terminates after resolving the name
+			clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+				pod.Name = pod.GenerateName + "abcde"
+				pod.Status.Phase = corev1.PodSucceeded
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "nslookup", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}}}
+				return false, nil, nil
+			})
+			objects := test.objects
+			if objects == nil {
+				objects = dnsObjects()
+			}
+			tools := newDNSTools(clientset, &test.executor, objects...)
+			tools.ExecAllowlist = test.allowlist
+			tools.ReadOnly = test.readOnly
+
+			result, _, err := tools.diagnoseDNS(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			pods, err := clientset.CoreV1().Pods("default").List(t.Context(), metav1.ListOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, pods.Items, "the debug Pod must be deleted")
+		})
+	}
+}
+
+func TestParseCorefile(t *testing.T) {
+	tests := map[string]struct {
+		corefile               string
+		expectedCustomizations []string
+		expectedIssues         []string
+	}{
+		"default K3s Corefile": {
+			corefile: strings.Join([]string{
+				".:53 {",
+				"    errors",
+				"    health",
+				"    ready",
+				"    kubernetes cluster.local in-addr.arpa ip6.arpa {",
+				"      pods insecure",
+				"      fallthrough in-addr.arpa ip6.arpa",
+				"    }",
+				"    hosts /etc/coredns/NodeHosts {",
+				"      ttl 60",
+				"      reload 15s",
+				"      fallthrough",
+				"    }",
+				"    prometheus :9153",
+				"    forward . /etc/resolv.conf",
+				"    cache 30",
+				"    loop",
+				"    reload",
+				"    loadbalance",
+				"    import /etc/coredns/custom/*.override",
+				"}",
+				"import /etc/coredns/custom/*.server",
+			}, "\n"),
+		},
+		"rewrite and static hosts": {
+			corefile: ".:53 {\n  kubernetes cluster.local\n  rewrite name api.example.com api.default.svc.cluster.local # internal API\n  hosts {\n    10.0.0.1 db.example.com\n  }\n  forward . /etc/resolv.conf\n}",
+			expectedCustomizations: []string{
+				".:53: rewrite name api.example.com api.default.svc.cluster.local",
+				".:53: hosts",
+			},
+		},
+		"missing kubernetes and forward plugins": {
+			corefile:       ". {\n  errors\n  cache\n}",
+			expectedIssues: []string{"the kubernetes plugin is missing, the names of the Services and Pods aren't resolved", "the forward plugin is missing, the names outside the cluster aren't resolved"},
+		},
+		"without root zone": {
+			corefile:               "example.com {\n  forward . 10.0.0.53\n}",
+			expectedCustomizations: []string{"server for example.com", "example.com: forward . 10.0.0.53"},
+			expectedIssues:         []string{"the Corefile doesn't have a server for the root zone '.', only the names of its zones are resolved"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			customizations, issues := parseCorefile(test.corefile)
+			assert.Equal(t, test.expectedCustomizations, customizations)
+			assert.Equal(t, test.expectedIssues, issues)
+		})
+	}
+}
@@ -0,0 +1,790 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	nginxController   = "nginx"
+	traefikController = "traefik"
+	istioController   = "istio"
+
+	// maxControllerLogPods is the maximum number of Pods of each ingress controller whose logs are searched.
+	maxControllerLogPods = 2
+	// controllerLogTailLines is the number of log lines returned for each Pod of an ingress controller.
+	controllerLogTailLines int64 = 20
+
+	defaultIngressClassAnn = "ingressclass.kubernetes.io/is-default-class"
+	legacyIngressClassAnn  = "kubernetes.io/ingress.class"
+)
+
+// ingressControllers are the supported ingress controllers, with the label selector of their Pods and the controller
+// of their IngressClasses.
+var ingressControllers = []struct {
+	name            string
+	selector        string
+	classController string
+}{
+	{nginxController, "app.kubernetes.io/name in (ingress-nginx,rke2-ingress-nginx),app.kubernetes.io/component=controller", "k8s.io/ingress-nginx"},
+	{traefikController, "app.kubernetes.io/name=traefik", "traefik.io/ingress-controller"},
+	{istioController, "istio=ingressgateway", "istio.io/ingress-controller"},
+}
+
+// traefikHostRegexp matches the Host rules of the Traefik routes, e.g. Host(`app.example.com`, `www.example.com`).
+var traefikHostRegexp = regexp.MustCompile("Host\\(([^)]*)\\)")
+
+type diagnoseIngressParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the hostname"`
+	Host    string `json:"host" jsonschema:"the hostname or the URL returning errors, e.g. app.example.com or https://app.example.com/api" validate:"required"`
+	Path    string `json:"path,omitempty" jsonschema:"the path of the failing URL, only the routes matching it are returned. Empty for all the routes of the host"`
+}
+
+// ingressDiagnosis is the ingress stack of a cluster, the routes of a hostname with the health of their backends and
+// the logs of the controllers mentioning the hostname.
+type ingressDiagnosis struct {
+	Host        string              `json:"host"`
+	Path        string              `json:"path,omitempty"`
+	Controllers []ingressController `json:"controllers"`
+	Routes      []ingressRoute      `json:"routes"`
+	Hints       []string            `json:"hints"`
+}
+
+// ingressController is an ingress controller installed in the cluster.
+type ingressController struct {
+	Type           string   `json:"type"`
+	Namespace      string   `json:"namespace"`
+	Pods           int      `json:"pods"`
+	ReadyPods      int      `json:"readyPods"`
+	IngressClasses []string `json:"ingressClasses,omitempty"`
+	// Logs are the last log lines of the Pods of the controller mentioning the hostname or its backends.
+	Logs map[string]string `json:"logs,omitempty"`
+}
+
+// ingressRoute is an Ingress, a Traefik IngressRoute, an Istio Gateway or an Istio VirtualService routing the hostname.
+type ingressRoute struct {
+	Kind       string           `json:"kind"`
+	Namespace  string           `json:"namespace"`
+	Name       string           `json:"name"`
+	Controller string           `json:"controller,omitempty"`
+	Hosts      []string         `json:"hosts"`
+	Backends   []ingressBackend `json:"backends"`
+	Issues     []string         `json:"issues"`
+}
+
+// ingressBackend is a Service receiving the traffic of a route, with its ready endpoints.
+type ingressBackend struct {
+	Match             string `json:"match,omitempty"`
+	Service           string `json:"service"`
+	Port              string `json:"port,omitempty"`
+	ReadyEndpoints    int    `json:"readyEndpoints"`
+	NotReadyEndpoints int    `json:"notReadyEndpoints"`
+	Issue             string `json:"issue,omitempty"`
+}
+
+// diagnoseIngress detects the ingress controllers of a cluster, finds the Ingresses, Traefik IngressRoutes and Istio
+// Gateways and VirtualServices routing a hostname, checks the endpoints of their backends and returns the logs of
+// the controllers mentioning the hostname, to explain why a URL returns 404 or 502.
+func (t *Tools) diagnoseIngress(ctx context.Context, toolReq *mcp.CallToolRequest, params diagnoseIngressParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("diagnoseIngress called")
+
+	host, path, err := parseIngressHost(params.Host, params.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	diagnosis := ingressDiagnosis{Host: host, Path: path, Controllers: []ingressController{}, Routes: []ingressRoute{}, Hints: []string{}}
+
+	classes, defaultClass, err := t.ingressClasses(ctx, toolReq, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list IngressClasses", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+		return nil, nil, err
+	}
+	controllerPods := map[string][]corev1.Pod{}
+	for _, controller := range ingressControllers {
+		pods, err := t.ingressControllerPods(ctx, toolReq, params.Cluster, controller.selector)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list ingress controller Pods", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+			return nil, nil, err
+		}
+		var classNames []string
+		for name, classController := range classes {
+			if classController == controller.classController {
+				classNames = append(classNames, name)
+			}
+		}
+		sort.Strings(classNames)
+		if len(pods) == 0 && len(classNames) == 0 {
+			continue
+		}
+		c := ingressController{Type: controller.name, Pods: len(pods), IngressClasses: classNames}
+		for _, pod := range pods {
+			c.Namespace = pod.Namespace
+			if isPodReady(pod) {
+				c.ReadyPods++
+			}
+		}
+		controllerPods[controller.name] = pods
+		diagnosis.Controllers = append(diagnosis.Controllers, c)
+	}
+
+	ingressRoutes, err := t.ingressRoutes(ctx, toolReq, params.Cluster, host, path, classes, defaultClass)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Ingresses", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+		return nil, nil, err
+	}
+	traefikRoutes, err := t.traefikRoutes(ctx, toolReq, params.Cluster, host)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Traefik IngressRoutes", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+		return nil, nil, err
+	}
+	istioRoutes, err := t.istioRoutes(ctx, toolReq, params.Cluster, host, path)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Istio routes", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+		return nil, nil, err
+	}
+	diagnosis.Routes = append(append(ingressRoutes, traefikRoutes...), istioRoutes...)
+
+	health := map[string]ingressBackend{}
+	for i := range diagnosis.Routes {
+		for j, backend := range diagnosis.Routes[i].Backends {
+			if backend.Issue != "" {
+				continue
+			}
+			checked, ok := health[backend.Service+":"+backend.Port]
+			if !ok {
+				checked, err = t.checkIngressBackend(ctx, toolReq, params.Cluster, backend)
+				if err != nil {
+					middleware.Logger(ctx).Error("failed to check backend", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+					return nil, nil, err
+				}
+				health[backend.Service+":"+backend.Port] = checked
+			}
+			checked.Match = backend.Match
+			diagnosis.Routes[i].Backends[j] = checked
+		}
+	}
+
+	for i, controller := range diagnosis.Controllers {
+		logs, err := t.ingressControllerLogs(ctx, toolReq, params.Cluster, controllerPods[controller.Type], host, diagnosis.Routes)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get ingress controller logs", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+			return nil, nil, err
+		}
+		diagnosis.Controllers[i].Logs = logs
+	}
+	diagnosis.Hints = ingressHints(diagnosis)
+
+	response, err := json.Marshal(diagnosis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "diagnoseIngress"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// parseIngressHost returns the hostname and the path of a hostname or a URL. The path of the URL is used unless a
+// path is given.
+func parseIngressHost(host string, path string) (string, string, error) {
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid URL %q: %w", host, err)
+		}
+		host = u.Host
+		if path == "" && u.Path != "/" {
+			path = u.Path
+		}
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if i := strings.LastIndex(host, ":"); i > 0 {
+		host = host[:i]
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("the host is required")
+	}
+
+	return strings.ToLower(host), path, nil
+}
+
+// ingressClasses returns the controllers of the IngressClasses indexed by their name, and the name of the default
+// IngressClass.
+func (t *Tools) ingressClasses(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string) (map[string]string, string, error) {
+	unstructuredClasses, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: cluster,
+		Kind:    "ingressclass",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list IngressClasses: %w", err)
+	}
+	classes := map[string]string{}
+	var defaultClass string
+	for _, obj := range unstructuredClasses {
+		controller, _, _ := unstructured.NestedString(obj.Object, "spec", "controller")
+		classes[obj.GetName()] = controller
+		if obj.GetAnnotations()[defaultIngressClassAnn] == "true" {
+			defaultClass = obj.GetName()
+		}
+	}
+
+	return classes, defaultClass, nil
+}
+
+// ingressControllerPods returns the Pods of an ingress controller in all the namespaces.
+func (t *Tools) ingressControllerPods(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, selector string) ([]corev1.Pod, error) {
+	unstructuredPods, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "pod",
+		LabelSelector: selector,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the Pods of the ingress controllers: %w", err)
+	}
+	pods := make([]corev1.Pod, len(unstructuredPods))
+	for i, unstructuredPod := range unstructuredPods {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredPod.Object, &pods[i]); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	return pods, nil
+}
+
+// ingressRoutes returns the Ingresses with rules for the hostname, with the backends of the paths matching the path.
+func (t *Tools) ingressRoutes(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, host string, path string, classes map[string]string, defaultClass string) ([]ingressRoute, error) {
+	unstructuredIngresses, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: cluster,
+		Kind:    "ingress",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Ingresses: %w", err)
+	}
+
+	routes := []ingressRoute{}
+	for _, obj := range unstructuredIngresses {
+		var ingress networkingv1.Ingress
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ingress); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Ingress: %w", err)
+		}
+		route := ingressRoute{Kind: "Ingress", Namespace: ingress.Namespace, Name: ingress.Name, Hosts: []string{}, Backends: []ingressBackend{}, Issues: []string{}}
+		for _, rule := range ingress.Spec.Rules {
+			if !hostMatches(rule.Host, host, true) || rule.HTTP == nil {
+				continue
+			}
+			route.Hosts = appendUnique(route.Hosts, ingressRuleHost(rule.Host))
+			for _, p := range rule.HTTP.Paths {
+				if !ingressPathMatches(p.PathType, p.Path, path) || p.Backend.Service == nil {
+					continue
+				}
+				route.Backends = append(route.Backends, ingressBackend{
+					Match:   p.Path,
+					Service: ingress.Namespace + "/" + p.Backend.Service.Name,
+					Port:    formatServiceBackendPort(p.Backend.Service.Port),
+				})
+			}
+		}
+		if len(route.Hosts) == 0 {
+			continue
+		}
+		if b := ingress.Spec.DefaultBackend; len(route.Backends) == 0 && b != nil && b.Service != nil {
+			route.Backends = append(route.Backends, ingressBackend{Service: ingress.Namespace + "/" + b.Service.Name, Port: formatServiceBackendPort(b.Service.Port)})
+		}
+
+		className := ingress.Annotations[legacyIngressClassAnn]
+		if ingress.Spec.IngressClassName != nil {
+			className = *ingress.Spec.IngressClassName
+		}
+		switch {
+		case className == "" && defaultClass == "":
+			route.Issues = append(route.Issues, "the Ingress doesn't set an ingressClassName and the cluster has no default IngressClass, the ingress controllers may ignore it")
+		case className == "":
+			className = defaultClass
+		case classes[className] == "":
+			route.Issues = append(route.Issues, fmt.Sprintf("the IngressClass %s doesn't exist, no ingress controller serves the Ingress", className))
+		}
+		route.Controller = ingressControllerName(classes[className])
+		if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+			route.Issues = append(route.Issues, "the Ingress has no address, its ingress controller hasn't admitted it")
+		}
+		if len(route.Backends) == 0 && path != "" {
+			route.Issues = append(route.Issues, fmt.Sprintf("no path of the Ingress matches %s", path))
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// traefikRoutes returns the Traefik IngressRoutes with Host rules matching the hostname. It returns no routes when
+// Traefik isn't installed.
+func (t *Tools) traefikRoutes(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, host string) ([]ingressRoute, error) {
+	unstructuredRoutes, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: cluster,
+		Kind:    "ingressroute",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IngressRoutes: %w", err)
+	}
+
+	var routes []ingressRoute
+	for _, obj := range unstructuredRoutes {
+		route := ingressRoute{Kind: "IngressRoute", Namespace: obj.GetNamespace(), Name: obj.GetName(), Controller: traefikController, Hosts: []string{}, Backends: []ingressBackend{}, Issues: []string{}}
+		rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "routes")
+		for _, item := range rules {
+			rule, _ := item.(map[string]any)
+			match, _ := rule["match"].(string)
+			hosts := traefikHosts(match)
+			if !slices.ContainsFunc(hosts, func(pattern string) bool { return hostMatches(pattern, host, false) }) {
+				continue
+			}
+			for _, h := range hosts {
+				route.Hosts = appendUnique(route.Hosts, h)
+			}
+			services, _ := rule["services"].([]any)
+			for _, s := range services {
+				service, _ := s.(map[string]any)
+				name, _ := service["name"].(string)
+				namespace, _ := service["namespace"].(string)
+				if namespace == "" {
+					namespace = obj.GetNamespace()
+				}
+				backend := ingressBackend{Match: match, Service: namespace + "/" + name, Port: fmt.Sprint(service["port"])}
+				if kind, _ := service["kind"].(string); kind != "" && kind != "Service" {
+					backend.Issue = fmt.Sprintf("the %s isn't checked, only Kubernetes Services are", kind)
+				}
+				if service["port"] == nil {
+					backend.Port = ""
+				}
+				route.Backends = append(route.Backends, backend)
+			}
+		}
+		if len(route.Hosts) > 0 {
+			routes = append(routes, route)
+		}
+	}
+
+	return routes, nil
+}
+
+// traefikHosts returns the hostnames of the Host rules of a Traefik match expression.
+func traefikHosts(match string) []string {
+	var hosts []string
+	for _, rule := range traefikHostRegexp.FindAllStringSubmatch(match, -1) {
+		for _, h := range strings.Split(rule[1], ",") {
+			if h = strings.Trim(strings.TrimSpace(h), "`\"'"); h != "" {
+				hosts = append(hosts, h)
+			}
+		}
+	}
+
+	return hosts
+}
+
+// istioRoutes returns the Istio Gateways serving the hostname and the VirtualServices routing it. It returns no
+// routes when Istio isn't installed.
+func (t *Tools) istioRoutes(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, host string, path string) ([]ingressRoute, error) {
+	listParams := client.ListParams{
+		Cluster: cluster,
+		Kind:    "istiogateway",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	}
+	gateways, err := t.client.GetResources(ctx, listParams)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Istio Gateways: %w", err)
+	}
+	listParams.Kind = "istiovirtualservice"
+	virtualServices, err := t.client.GetResources(ctx, listParams)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to list Istio VirtualServices: %w", err)
+	}
+
+	var routes []ingressRoute
+	servingGateways := map[string]bool{}
+	for _, gateway := range gateways {
+		route := ingressRoute{Kind: "Gateway", Namespace: gateway.GetNamespace(), Name: gateway.GetName(), Controller: istioController, Hosts: []string{}, Backends: []ingressBackend{}, Issues: []string{}}
+		servers, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "servers")
+		for _, item := range servers {
+			server, _ := item.(map[string]any)
+			hosts, _ := server["hosts"].([]any)
+			for _, h := range hosts {
+				// the hosts of a server can be restricted to the VirtualServices of a namespace, as namespace/host
+				pattern, _ := h.(string)
+				if i := strings.Index(pattern, "/"); i >= 0 {
+					pattern = pattern[i+1:]
+				}
+				if hostMatches(pattern, host, false) {
+					route.Hosts = appendUnique(route.Hosts, pattern)
+				}
+			}
+		}
+		if len(route.Hosts) > 0 {
+			servingGateways[gateway.GetNamespace()+"/"+gateway.GetName()] = true
+			routes = append(routes, route)
+		}
+	}
+
+	routedGateways := map[string]bool{}
+	for _, virtualService := range virtualServices {
+		route := ingressRoute{Kind: "VirtualService", Namespace: virtualService.GetNamespace(), Name: virtualService.GetName(), Controller: istioController, Hosts: []string{}, Backends: []ingressBackend{}, Issues: []string{}}
+		hosts, _, _ := unstructured.NestedStringSlice(virtualService.Object, "spec", "hosts")
+		for _, pattern := range hosts {
+			if hostMatches(pattern, host, false) {
+				route.Hosts = appendUnique(route.Hosts, pattern)
+			}
+		}
+		if len(route.Hosts) == 0 {
+			continue
+		}
+
+		// the VirtualServices without gateways only apply to the traffic of the mesh
+		gatewayRefs, _, _ := unstructured.NestedStringSlice(virtualService.Object, "spec", "gateways")
+		var ingressGateways []string
+		for _, ref := range gatewayRefs {
+			if ref == "mesh" {
+				continue
+			}
+			if !strings.Contains(ref, "/") {
+				ref = virtualService.GetNamespace() + "/" + ref
+			}
+			ingressGateways = append(ingressGateways, ref)
+			if servingGateways[ref] {
+				routedGateways[ref] = true
+			} else {
+				route.Issues = append(route.Issues, fmt.Sprintf("the gateway %s doesn't exist or doesn't serve host %s", ref, host))
+			}
+		}
+		if len(ingressGateways) == 0 {
+			route.Issues = append(route.Issues, "the VirtualService isn't bound to a gateway, it only routes the traffic of the mesh")
+		}
+
+		rules, _, _ := unstructured.NestedSlice(virtualService.Object, "spec", "http")
+		for _, item := range rules {
+			rule, _ := item.(map[string]any)
+			match, ok := istioPathMatch(rule, path)
+			if !ok {
+				continue
+			}
+			destinations, _ := rule["route"].([]any)
+			for _, d := range destinations {
+				destination, _, _ := unstructured.NestedMap(d.(map[string]any), "destination")
+				route.Backends = append(route.Backends, istioBackend(destination, virtualService.GetNamespace(), match))
+			}
+		}
+		if len(route.Backends) == 0 && path != "" {
+			route.Issues = append(route.Issues, fmt.Sprintf("no HTTP route of the VirtualService matches %s", path))
+		}
+		routes = append(routes, route)
+	}
+
+	for i, route := range routes {
+		if route.Kind == "Gateway" && !routedGateways[route.Namespace+"/"+route.Name] {
+			routes[i].Issues = append(routes[i].Issues, fmt.Sprintf("no VirtualService bound to the gateway routes host %s, the gateway returns 404", host))
+		}
+	}
+
+	return routes, nil
+}
+
+// istioPathMatch returns the URI match of an HTTP route of a VirtualService and whether it matches the path. The
+// routes without URI match accept any path.
+func istioPathMatch(rule map[string]any, path string) (string, bool) {
+	matches, _ := rule["match"].([]any)
+	if len(matches) == 0 {
+		return "", true
+	}
+	var uris []string
+	matched := path == ""
+	for _, item := range matches {
+		uri, _, _ := unstructured.NestedMap(item.(map[string]any), "uri")
+		if len(uri) == 0 {
+			return "", true
+		}
+		for kind, value := range uri {
+			v, _ := value.(string)
+			uris = append(uris, kind+" "+v)
+			switch kind {
+			case "exact":
+				matched = matched || path == v
+			case "prefix":
+				matched = matched || strings.HasPrefix(path, v)
+			case "regex":
+				re, err := regexp.Compile("^" + v + "$")
+				matched = matched || (err == nil && re.MatchString(path))
+			}
+		}
+	}
+	sort.Strings(uris)
+
+	return strings.Join(uris, ", "), matched
+}
+
+// istioBackend returns the Service of the destination of a VirtualService route. The hosts of the destinations are
+// short names resolved in the namespace of the VirtualService, or fully qualified names.
+func istioBackend(destination map[string]any, namespace string, match string) ingressBackend {
+	host, _ := destination["host"].(string)
+	backend := ingressBackend{Match: match, Service: host}
+	if number, ok, _ := unstructured.NestedFieldNoCopy(destination, "port", "number"); ok {
+		backend.Port = fmt.Sprint(number)
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(host, ".cluster.local"), ".svc"), ".")
+	switch len(parts) {
+	case 1:
+		backend.Service = namespace + "/" + parts[0]
+	case 2:
+		backend.Service = parts[1] + "/" + parts[0]
+	default:
+		backend.Issue = "the host isn't a Service of the cluster, it must be declared by a ServiceEntry"
+	}
+
+	return backend
+}
+
+// checkIngressBackend counts the endpoints of the Service of a backend and checks that the backend port is a port of
+// the Service.
+func (t *Tools) checkIngressBackend(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, backend ingressBackend) (ingressBackend, error) {
+	namespace, name, _ := strings.Cut(backend.Service, "/")
+	serviceResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "service",
+		Namespace: namespace,
+		Name:      name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if apierrors.IsNotFound(err) {
+		backend.Issue = fmt.Sprintf("the Service %s doesn't exist", backend.Service)
+		return backend, nil
+	}
+	if err != nil {
+		return backend, fmt.Errorf("failed to get Service %s: %w", backend.Service, err)
+	}
+	var service corev1.Service
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(serviceResource.Object, &service); err != nil {
+		return backend, fmt.Errorf("failed to convert unstructured object to Service: %w", err)
+	}
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		return backend, nil
+	}
+	if backend.Port != "" && !slices.ContainsFunc(service.Spec.Ports, func(p corev1.ServicePort) bool {
+		return p.Name == backend.Port || fmt.Sprint(p.Port) == backend.Port
+	}) {
+		backend.Issue = fmt.Sprintf("the port %s isn't a port of the Service %s", backend.Port, backend.Service)
+		return backend, nil
+	}
+
+	endpointSlices, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "endpointslices",
+		Namespace:     namespace,
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+	})
+	if err != nil {
+		return backend, fmt.Errorf("failed to get the endpointslices of Service %s: %w", backend.Service, err)
+	}
+	for _, obj := range endpointSlices {
+		var endpointSlice discoveryv1.EndpointSlice
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &endpointSlice); err != nil {
+			return backend, fmt.Errorf("failed to convert unstructured object to EndpointSlice: %w", err)
+		}
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				backend.ReadyEndpoints++
+			} else {
+				backend.NotReadyEndpoints++
+			}
+		}
+	}
+	if backend.ReadyEndpoints == 0 {
+		backend.Issue = fmt.Sprintf("the Service %s has no ready endpoints", backend.Service)
+	}
+
+	return backend, nil
+}
+
+// ingressControllerLogs returns the last log lines of the first Pods of an ingress controller mentioning the hostname
+// or the Services of its routes, indexed by Pod name.
+func (t *Tools) ingressControllerLogs(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, pods []corev1.Pod, host string, routes []ingressRoute) (map[string]string, error) {
+	patterns := []string{regexp.QuoteMeta(host)}
+	for _, route := range routes {
+		for _, backend := range route.Backends {
+			if namespace, name, ok := strings.Cut(backend.Service, "/"); ok {
+				// ingress-nginx and Traefik name the upstreams namespace-service-port, Envoy service.namespace.svc
+				patterns = appendUnique(patterns, regexp.QuoteMeta(namespace+"-"+name+"-"), regexp.QuoteMeta(name+"."+namespace+".svc"))
+			}
+		}
+	}
+
+	logs := map[string]string{}
+	for i, pod := range pods {
+		if i == maxControllerLogPods {
+			break
+		}
+		podLogs, err := t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), cluster, middleware.Token(ctx), pod, getPodLogsParams{
+			TailLines: controllerLogTailLines,
+			Filter:    strings.Join(patterns, "|"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		containers, _ := podLogs.Object["pod-logs"].(map[string]any)
+		for _, container := range pod.Spec.Containers {
+			if l, _ := containers[container.Name].(string); l != "" {
+				lines = append(lines, l)
+			}
+		}
+		if len(lines) > 0 {
+			logs[pod.Name] = strings.Join(lines, "\n")
+		}
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	return logs, nil
+}
+
+// ingressHints explains the 404 and 502 errors of the hostname from the controllers, the routes and the backends.
+func ingressHints(diagnosis ingressDiagnosis) []string {
+	var hints []string
+	if len(diagnosis.Controllers) == 0 {
+		hints = append(hints, "No ingress controller (ingress-nginx, Traefik or an Istio ingress gateway) was found in the cluster.")
+	}
+	for _, controller := range diagnosis.Controllers {
+		if controller.Pods > 0 && controller.ReadyPods == 0 {
+			hints = append(hints, fmt.Sprintf("The %s ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.", controller.Type))
+		}
+	}
+
+	if len(diagnosis.Routes) == 0 {
+		hints = append(hints, fmt.Sprintf("No Ingress, IngressRoute or VirtualService routes host %s, the ingress controllers return 404 for unknown hosts. Check the hostname of the URL and of the routes.", diagnosis.Host))
+		return hints
+	}
+	if !slices.ContainsFunc(diagnosis.Routes, func(route ingressRoute) bool { return len(route.Backends) > 0 }) {
+		if diagnosis.Path != "" {
+			hints = append(hints, fmt.Sprintf("No route of host %s matches the path %s, the ingress controllers return 404.", diagnosis.Host, diagnosis.Path))
+		} else {
+			hints = append(hints, fmt.Sprintf("The routes of host %s have no backends, the ingress controllers return 404.", diagnosis.Host))
+		}
+	}
+	for _, route := range diagnosis.Routes {
+		for _, issue := range route.Issues {
+			hints = append(hints, fmt.Sprintf("%s %s/%s: %s.", route.Kind, route.Namespace, route.Name, issue))
+		}
+		for _, backend := range route.Backends {
+			if backend.Issue == "" {
+				continue
+			}
+			hint := fmt.Sprintf("Backend %s: %s, the ingress controllers return 502 or 503 for this route. Check it with inspectService.", backend.Service, backend.Issue)
+			if !slices.Contains(hints, hint) {
+				hints = append(hints, hint)
+			}
+		}
+	}
+
+	return hints
+}
+
+// hostMatches returns whether a host pattern of a route matches the hostname. Wildcard patterns match a single
+// label, e.g. *.example.com matches app.example.com. Empty patterns match any host when emptyMatchesAll is set, as the
+// Ingress rules without host.
+func hostMatches(pattern string, host string, emptyMatchesAll bool) bool {
+	pattern = strings.ToLower(pattern)
+	switch {
+	case pattern == "":
+		return emptyMatchesAll
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*."):
+		prefix, ok := strings.CutSuffix(host, pattern[1:])
+		return ok && prefix != "" && !strings.Contains(prefix, ".")
+	}
+
+	return pattern == host
+}
+
+// ingressPathMatches returns whether a path of an Ingress rule matches the path of the request, any path matching
+// when the request path is empty.
+func ingressPathMatches(pathType *networkingv1.PathType, rulePath string, path string) bool {
+	if path == "" {
+		return true
+	}
+	if rulePath == "" {
+		rulePath = "/"
+	}
+	if pathType != nil && *pathType == networkingv1.PathTypeExact {
+		return path == rulePath
+	}
+	if pathType != nil && *pathType == networkingv1.PathTypePrefix {
+		// Prefix paths match element by element, /foo matches /foo/bar but not /foobar
+		prefix := strings.TrimSuffix(rulePath, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+
+	return strings.HasPrefix(path, rulePath)
+}
+
+// ingressRuleHost returns the host of an Ingress rule, * for the rules without host.
+func ingressRuleHost(host string) string {
+	if host == "" {
+		return "*"
+	}
+	return host
+}
+
+// ingressControllerName returns the type of the ingress controller of an IngressClass controller.
+func ingressControllerName(classController string) string {
+	for _, controller := range ingressControllers {
+		if controller.classController == classController {
+			return controller.name
+		}
+	}
+	return classController
+}
+
+// appendUnique appends the values missing from the slice.
+func appendUnique(values []string, added ...string) []string {
+	for _, value := range added {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
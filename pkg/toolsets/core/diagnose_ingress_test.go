@@ -0,0 +1,353 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func newIngressTools(istioInstalled bool, objects ...runtime.Object) *Tools {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = discoveryv1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "traefik.io", Version: "v1alpha1", Resource: "ingressroutes"}:           "IngressRouteList",
+		{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}:        "GatewayList",
+		{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}: "VirtualServiceList",
+		{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}:          "EndpointSliceList",
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingressclasses"}:         "IngressClassList",
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}:              "IngressList",
+		{Group: "", Version: "v1", Resource: "pods"}:                                    "PodList",
+		{Group: "", Version: "v1", Resource: "services"}:                                "ServiceList",
+	}, objects...)
+	// the fake client can't guess the resource of the Gateway kind, the Gateway is created with its resource
+	gatewaysGVR := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}
+	if istioInstalled {
+		_ = fakeDynClient.Tracker().Create(gatewaysGVR, istioGateway(), "istio-system")
+	} else {
+		fakeDynClient.PrependReactor("list", "gateways", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+		})
+	}
+	c := &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return fake.NewClientset(), nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+
+	return &Tools{client: newFakeToolsClient(c, "fakeToken")}
+}
+
+func newIngressControllerPod(namespace string, name string, labels map[string]string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "controller", Image: "controller:1"}}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func newBackendService(name string, port int32, ready ...bool) []runtime.Object {
+	endpoints := make([]discoveryv1.Endpoint, len(ready))
+	for i, r := range ready {
+		endpoints[i] = discoveryv1.Endpoint{Addresses: []string{"10.42.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(r)}}
+	}
+	return []runtime.Object{
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: port}}},
+		},
+		&discoveryv1.EndpointSlice{
+			TypeMeta:    metav1.TypeMeta{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice"},
+			ObjectMeta:  metav1.ObjectMeta{Name: name + "-abcde", Namespace: "default", Labels: map[string]string{discoveryv1.LabelServiceName: name}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   endpoints,
+		},
+	}
+}
+
+func newIngress(name string, className *string, address bool, host string, paths ...networkingv1.HTTPIngressPath) *networkingv1.Ingress {
+	ingress := &networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: className,
+			Rules: []networkingv1.IngressRule{{
+				Host:             host,
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths}},
+			}},
+		},
+	}
+	if address {
+		ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "192.168.1.10"}}
+	}
+	return ingress
+}
+
+func ingressPath(path string, service string, port int32) networkingv1.HTTPIngressPath {
+	return networkingv1.HTTPIngressPath{
+		Path:     path,
+		PathType: ptr.To(networkingv1.PathTypePrefix),
+		Backend: networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{Name: service, Port: networkingv1.ServiceBackendPort{Number: port}},
+		},
+	}
+}
+
+func istioGateway() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "networking.istio.io/v1beta1",
+		"kind":       "Gateway",
+		"metadata":   map[string]any{"name": "public", "namespace": "istio-system"},
+		"spec": map[string]any{"servers": []any{map[string]any{
+			"port":  map[string]any{"number": int64(80), "name": "http", "protocol": "HTTP"},
+			"hosts": []any{"*/*.mesh.example.com"},
+		}}},
+	}}
+}
+
+func ingressObjects() []runtime.Object {
+	objects := []runtime.Object{
+		&networkingv1.IngressClass{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "IngressClass"},
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Annotations: map[string]string{defaultIngressClassAnn: "true"}},
+			Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+		},
+		newIngressControllerPod("kube-system", "rke2-ingress-nginx-controller-abcde", map[string]string{
+			"app.kubernetes.io/name":      "rke2-ingress-nginx",
+			"app.kubernetes.io/component": "controller",
+		}, true),
+		newIngressControllerPod("kube-system", "rke2-ingress-nginx-admission-create-abcde", map[string]string{
+			"app.kubernetes.io/name":      "rke2-ingress-nginx",
+			"app.kubernetes.io/component": "admission-webhook",
+		}, false),
+		newIngressControllerPod("traefik", "traefik-abcde", map[string]string{"app.kubernetes.io/name": "traefik"}, false),
+		newIngress("web", nil, true, "app.example.com", ingressPath("/", "web", 80), ingressPath("/api", "api", 8080)),
+		newIngress("legacy", ptr.To("haproxy"), false, "legacy.example.com", ingressPath("/", "web", 8080)),
+		&unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "traefik.io/v1alpha1",
+			"kind":       "IngressRoute",
+			"metadata":   map[string]any{"name": "shop", "namespace": "default"},
+			"spec": map[string]any{"routes": []any{map[string]any{
+				"match":    "Host(`shop.example.com`) && PathPrefix(`/`)",
+				"services": []any{map[string]any{"name": "shop", "port": int64(80)}},
+			}}},
+		}},
+		&unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "networking.istio.io/v1beta1",
+			"kind":       "VirtualService",
+			"metadata":   map[string]any{"name": "reviews", "namespace": "default"},
+			"spec": map[string]any{
+				"hosts":    []any{"reviews.mesh.example.com"},
+				"gateways": []any{"istio-system/public"},
+				"http": []any{map[string]any{
+					"match": []any{map[string]any{"uri": map[string]any{"prefix": "/reviews"}}},
+					"route": []any{map[string]any{"destination": map[string]any{"host": "reviews.default.svc.cluster.local", "port": map[string]any{"number": int64(9080)}}}},
+				}},
+			},
+		}},
+	}
+	objects = append(objects, newBackendService("web", 80, true, true)...)
+	objects = append(objects, newBackendService("shop", 80, false)...)
+	objects = append(objects, newBackendService("reviews", 9080, true)...)
+
+	return objects
+}
+
+func TestDiagnoseIngress(t *testing.T) {
+	controllers := `[
+		{"type": "nginx", "namespace": "kube-system", "pods": 1, "readyPods": 1, "ingressClasses": ["nginx"]},
+		{"type": "traefik", "namespace": "traefik", "pods": 1, "readyPods": 0}
+	]`
+
+	tests := map[string]struct {
+		params         diagnoseIngressParams
+		istioInstalled bool
+		expectedResult string
+		expectedError  string
+	}{
+		"ingress with a missing backend": {
+			params: diagnoseIngressParams{Cluster: "local", Host: "App.example.com"},
+			expectedResult: `{
+				"host": "app.example.com",
+				"controllers": ` + controllers + `,
+				"routes": [{"kind": "Ingress", "namespace": "default", "name": "web", "controller": "nginx", "hosts": ["app.example.com"], "backends": [
+					{"match": "/", "service": "default/web", "port": "80", "readyEndpoints": 2, "notReadyEndpoints": 0},
+					{"match": "/api", "service": "default/api", "port": "8080", "readyEndpoints": 0, "notReadyEndpoints": 0, "issue": "the Service default/api doesn't exist"}
+				], "issues": []}],
+				"hints": [
+					"The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.",
+					"Backend default/api: the Service default/api doesn't exist, the ingress controllers return 502 or 503 for this route. Check it with inspectService."
+				]
+			}`,
+		},
+		"path of the URL": {
+			params: diagnoseIngressParams{Cluster: "local", Host: "https://app.example.com:443/api/users"},
+			expectedResult: `{
+				"host": "app.example.com",
+				"path": "/api/users",
+				"controllers": ` + controllers + `,
+				"routes": [{"kind": "Ingress", "namespace": "default", "name": "web", "controller": "nginx", "hosts": ["app.example.com"], "backends": [
+					{"match": "/", "service": "default/web", "port": "80", "readyEndpoints": 2, "notReadyEndpoints": 0},
+					{"match": "/api", "service": "default/api", "port": "8080", "readyEndpoints": 0, "notReadyEndpoints": 0, "issue": "the Service default/api doesn't exist"}
+				], "issues": []}],
+				"hints": [
+					"The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.",
+					"Backend default/api: the Service default/api doesn't exist, the ingress controllers return 502 or 503 for this route. Check it with inspectService."
+				]
+			}`,
+		},
+		"path not routed": {
+			params: diagnoseIngressParams{Cluster: "local", Host: "legacy.example.com", Path: "/admin"},
+			expectedResult: `{
+				"host": "legacy.example.com",
+				"path": "/admin",
+				"controllers": ` + controllers + `,
+				"routes": [{"kind": "Ingress", "namespace": "default", "name": "legacy", "hosts": ["legacy.example.com"], "backends": [
+					{"match": "/", "service": "default/web", "port": "8080", "readyEndpoints": 0, "notReadyEndpoints": 0, "issue": "the port 8080 isn't a port of the Service default/web"}
+				], "issues": [
+					"the IngressClass haproxy doesn't exist, no ingress controller serves the Ingress",
+					"the Ingress has no address, its ingress controller hasn't admitted it"
+				]}],
+				"hints": [
+					"The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.",
+					"Ingress default/legacy: the IngressClass haproxy doesn't exist, no ingress controller serves the Ingress.",
+					"Ingress default/legacy: the Ingress has no address, its ingress controller hasn't admitted it.",
+					"Backend default/web: the port 8080 isn't a port of the Service default/web, the ingress controllers return 502 or 503 for this route. Check it with inspectService."
+				]
+			}`,
+		},
+		"traefik route without ready endpoints": {
+			params: diagnoseIngressParams{Cluster: "local", Host: "shop.example.com"},
+			expectedResult: `{
+				"host": "shop.example.com",
+				"controllers": ` + controllers + `,
+				"routes": [{"kind": "IngressRoute", "namespace": "default", "name": "shop", "controller": "traefik", "hosts": ["shop.example.com"], "backends": [
+					{"match": "Host(` + "`shop.example.com`" + `) && PathPrefix(` + "`/`" + `)", "service": "default/shop", "port": "80", "readyEndpoints": 0, "notReadyEndpoints": 1, "issue": "the Service default/shop has no ready endpoints"}
+				], "issues": []}],
+				"hints": [
+					"The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.",
+					"Backend default/shop: the Service default/shop has no ready endpoints, the ingress controllers return 502 or 503 for this route. Check it with inspectService."
+				]
+			}`,
+		},
+		"istio gateway and virtual service": {
+			params:         diagnoseIngressParams{Cluster: "local", Host: "reviews.mesh.example.com", Path: "/reviews/1"},
+			istioInstalled: true,
+			expectedResult: `{
+				"host": "reviews.mesh.example.com",
+				"path": "/reviews/1",
+				"controllers": ` + controllers + `,
+				"routes": [
+					{"kind": "Gateway", "namespace": "istio-system", "name": "public", "controller": "istio", "hosts": ["*.mesh.example.com"], "backends": [], "issues": []},
+					{"kind": "VirtualService", "namespace": "default", "name": "reviews", "controller": "istio", "hosts": ["reviews.mesh.example.com"], "backends": [
+						{"match": "prefix /reviews", "service": "default/reviews", "port": "9080", "readyEndpoints": 1, "notReadyEndpoints": 0}
+					], "issues": []}
+				],
+				"hints": ["The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod."]
+			}`,
+		},
+		"istio gateway without virtual service": {
+			params:         diagnoseIngressParams{Cluster: "local", Host: "ratings.mesh.example.com"},
+			istioInstalled: true,
+			expectedResult: `{
+				"host": "ratings.mesh.example.com",
+				"controllers": ` + controllers + `,
+				"routes": [
+					{"kind": "Gateway", "namespace": "istio-system", "name": "public", "controller": "istio", "hosts": ["*.mesh.example.com"], "backends": [], "issues": [
+						"no VirtualService bound to the gateway routes host ratings.mesh.example.com, the gateway returns 404"
+					]}
+				],
+				"hints": [
+					"The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.",
+					"The routes of host ratings.mesh.example.com have no backends, the ingress controllers return 404.",
+					"Gateway istio-system/public: no VirtualService bound to the gateway routes host ratings.mesh.example.com, the gateway returns 404."
+				]
+			}`,
+		},
+		"unknown host": {
+			params: diagnoseIngressParams{Cluster: "local", Host: "unknown.example.com"},
+			expectedResult: `{
+				"host": "unknown.example.com",
+				"controllers": ` + controllers + `,
+				"routes": [],
+				"hints": [
+					"The traefik ingress controller has no ready Pods, it can't serve any request, check them with inspectPod.",
+					"No Ingress, IngressRoute or VirtualService routes host unknown.example.com, the ingress controllers return 404 for unknown hosts. Check the hostname of the URL and of the routes."
+				]
+			}`,
+		},
+		"invalid URL": {
+			params:        diagnoseIngressParams{Cluster: "local", Host: "https://"},
+			expectedError: "the host is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newIngressTools(test.istioInstalled, ingressObjects()...)
+
+			result, _, err := tools.diagnoseIngress(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestHostMatches(t *testing.T) {
+	tests := map[string]struct {
+		pattern         string
+		emptyMatchesAll bool
+		expected        bool
+	}{
+		"exact":                  {pattern: "app.example.com", expected: true},
+		"case insensitive":       {pattern: "App.Example.com", expected: true},
+		"other host":             {pattern: "www.example.com", expected: false},
+		"wildcard":               {pattern: "*.example.com", expected: true},
+		"wildcard of two labels": {pattern: "*.com", expected: false},
+		"any host":               {pattern: "*", expected: true},
+		"empty":                  {pattern: "", expected: false},
+		"empty matching all":     {pattern: "", emptyMatchesAll: true, expected: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, hostMatches(test.pattern, "app.example.com", test.emptyMatchesAll))
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// maxFailedPods is the maximum number of failed Pods of a Job returned with their logs.
+	maxFailedPods = 3
+	// failedContainerTailLines is the number of log lines returned for each failed container.
+	failedContainerTailLines int64 = 20
+)
+
+type diagnoseJobParams struct {
+	Kind      string `json:"kind" jsonschema:"the kind of the workload: Job or CronJob" validate:"required,enum=Job|CronJob"`
+	Name      string `json:"name" jsonschema:"the name of the Job or CronJob"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the Job or CronJob"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Job or CronJob"`
+}
+
+// jobDiagnosis explains why a Job, or the last run of a CronJob, failed.
+type jobDiagnosis struct {
+	CronJob    *cronJobSummary `json:"cronJob,omitempty"`
+	Job        *jobSummary     `json:"job,omitempty"`
+	FailedPods []failedPod     `json:"failedPods,omitempty"`
+	Hints      []string        `json:"hints"`
+}
+
+// failedPod is a Pod of a Job that failed or can't run, with the containers causing it.
+type failedPod struct {
+	Name       string             `json:"name"`
+	Phase      string             `json:"phase"`
+	Reason     string             `json:"reason,omitempty"`
+	Message    string             `json:"message,omitempty"`
+	Containers []containerFailure `json:"containers,omitempty"`
+}
+
+// containerFailure is a container that terminated with an error or is waiting to start. Logs are the last lines of
+// the terminated container.
+type containerFailure struct {
+	Name     string `json:"name"`
+	Reason   string `json:"reason,omitempty"`
+	ExitCode int32  `json:"exitCode,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Logs     string `json:"logs,omitempty"`
+}
+
+// diagnoseJob returns the status of a Job, or of the last run of a CronJob, with the failed Pods, the logs of their
+// failed containers and hints about the failure.
+func (t *Tools) diagnoseJob(ctx context.Context, toolReq *mcp.CallToolRequest, params diagnoseJobParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("diagnoseJob called")
+
+	kind := strings.ToLower(params.Kind)
+	if kind != "job" && kind != "cronjob" {
+		return nil, nil, fmt.Errorf("kind %s must be Job or CronJob", params.Kind)
+	}
+	cronJobs, jobs, err := t.batchWorkloads(ctx, toolReq, params.Cluster, params.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list Jobs", zap.String("tool", "diagnoseJob"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	diagnosis := jobDiagnosis{Hints: []string{}}
+	var job *batchv1.Job
+	if kind == "cronjob" {
+		i := slices.IndexFunc(cronJobs, func(cronJob batchv1.CronJob) bool { return cronJob.Name == params.Name })
+		if i < 0 {
+			return nil, nil, fmt.Errorf("no CronJob %s in namespace %s", params.Name, params.Namespace)
+		}
+		summary := newCronJobSummary(cronJobs[i], jobs)
+		diagnosis.CronJob = &summary
+		diagnosis.Hints = append(diagnosis.Hints, cronJobHints(cronJobs[i], summary)...)
+		job = lastCronJobRun(params.Name, jobs)
+	} else if i := slices.IndexFunc(jobs, func(job batchv1.Job) bool { return job.Name == params.Name }); i >= 0 {
+		job = &jobs[i]
+	} else {
+		return nil, nil, fmt.Errorf("no Job %s in namespace %s", params.Name, params.Namespace)
+	}
+
+	if job != nil {
+		summary := newJobSummary(*job)
+		diagnosis.Job = &summary
+		diagnosis.FailedPods, err = t.failedJobPods(ctx, toolReq, params.Cluster, job)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get the Pods of the Job", zap.String("tool", "diagnoseJob"), zap.Error(err))
+			return nil, nil, err
+		}
+		diagnosis.Hints = append(diagnosis.Hints, jobHints(job, summary, diagnosis.FailedPods)...)
+	}
+
+	response, err := json.Marshal(diagnosis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "diagnoseJob"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// lastCronJobRun returns the newest failed Job of the CronJob, or its newest Job if none failed, from jobs sorted from
+// the newest.
+func lastCronJobRun(cronJob string, jobs []batchv1.Job) *batchv1.Job {
+	var last *batchv1.Job
+	for i, job := range jobs {
+		if jobCronJob(job) != cronJob {
+			continue
+		}
+		if jobCondition(job, batchv1.JobFailed) != nil {
+			return &jobs[i]
+		}
+		if last == nil {
+			last = &jobs[i]
+		}
+	}
+
+	return last
+}
+
+// failedJobPods returns the newest Pods of the Job that failed or can't start, with the logs of their failed
+// containers.
+func (t *Tools) failedJobPods(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, job *batchv1.Job) ([]failedPod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of Job %s: %w", job.Name, err)
+	}
+	unstructuredPods, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "pod",
+		Namespace:     job.Namespace,
+		LabelSelector: selector.String(),
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the Pods of Job %s: %w", job.Name, err)
+	}
+	pods := make([]corev1.Pod, len(unstructuredPods))
+	for i, unstructuredPod := range unstructuredPods {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredPod.Object, &pods[i]); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+	}
+	slices.SortStableFunc(pods, func(a, b corev1.Pod) int {
+		return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+	})
+
+	var failed []failedPod
+	for _, pod := range pods {
+		if len(failed) == maxFailedPods {
+			break
+		}
+		f := failedPod{Name: pod.Name, Phase: string(pod.Status.Phase), Reason: pod.Status.Reason, Message: pod.Status.Message}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				f.Reason, f.Message = condition.Reason, condition.Message
+			}
+		}
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			failure, previous := newContainerFailure(status)
+			if failure == nil {
+				continue
+			}
+			if failure.ExitCode != 0 {
+				logs, err := t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), cluster, middleware.Token(ctx), pod, getPodLogsParams{
+					Container: status.Name,
+					TailLines: failedContainerTailLines,
+					Previous:  previous,
+				})
+				if err != nil {
+					return nil, err
+				}
+				failure.Logs, _ = logs.Object["pod-logs"].(map[string]any)[status.Name].(string)
+			}
+			f.Containers = append(f.Containers, *failure)
+		}
+		if pod.Status.Phase == corev1.PodFailed || f.Reason != "" || len(f.Containers) > 0 {
+			failed = append(failed, f)
+		}
+	}
+
+	return failed, nil
+}
+
+// newContainerFailure returns the failure of a container that terminated with an error or is waiting to start, or nil
+// when it didn't fail. previous is true when the failure is the one of the previous instance of the container.
+func newContainerFailure(status corev1.ContainerStatus) (*containerFailure, bool) {
+	switch {
+	case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+		terminated := status.State.Terminated
+		return &containerFailure{Name: status.Name, Reason: terminated.Reason, ExitCode: terminated.ExitCode, Message: terminated.Message}, false
+	case status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.ExitCode != 0:
+		terminated := status.LastTerminationState.Terminated
+		return &containerFailure{Name: status.Name, Reason: terminated.Reason, ExitCode: terminated.ExitCode, Message: terminated.Message}, true
+	case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+		return &containerFailure{Name: status.Name, Reason: status.State.Waiting.Reason, Message: status.State.Waiting.Message}, false
+	}
+
+	return nil, false
+}
+
+// cronJobHints explains why a CronJob doesn't create new Jobs.
+func cronJobHints(cronJob batchv1.CronJob, summary cronJobSummary) []string {
+	var hints []string
+	if summary.Suspended {
+		hints = append(hints, fmt.Sprintf("CronJob %s is suspended, it doesn't create new Jobs until it is resumed with resumeCronJob.", cronJob.Name))
+	}
+	if cronJob.Spec.ConcurrencyPolicy == batchv1.ForbidConcurrent && len(summary.Active) > 0 {
+		hints = append(hints, fmt.Sprintf("The concurrencyPolicy is Forbid, new runs are skipped while %s is active.", strings.Join(summary.Active, ", ")))
+	}
+	if summary.LastJob == nil {
+		if summary.LastScheduleTime == "" {
+			hints = append(hints, fmt.Sprintf("CronJob %s has never been scheduled.", cronJob.Name))
+		} else {
+			hints = append(hints, fmt.Sprintf("CronJob %s has no Jobs left, they were removed by its successfulJobsHistoryLimit and failedJobsHistoryLimit.", cronJob.Name))
+		}
+	}
+
+	return hints
+}
+
+// jobHints explains why a Job failed, from its Failed condition and the failures of its Pods.
+func jobHints(job *batchv1.Job, summary jobSummary, pods []failedPod) []string {
+	var hints []string
+	switch summary.Reason {
+	case "":
+	case batchv1.JobReasonBackoffLimitExceeded:
+		backoffLimit := int32(6)
+		if job.Spec.BackoffLimit != nil {
+			backoffLimit = *job.Spec.BackoffLimit
+		}
+		hints = append(hints, fmt.Sprintf("Job %s failed after reaching its backoffLimit of %d retries, the failures of its Pods are in failedPods.", job.Name, backoffLimit))
+	case batchv1.JobReasonDeadlineExceeded:
+		hints = append(hints, fmt.Sprintf("Job %s ran longer than its activeDeadlineSeconds and its Pods were stopped.", job.Name))
+	default:
+		hints = append(hints, fmt.Sprintf("Job %s failed (%s): %s", job.Name, summary.Reason, summary.Message))
+	}
+
+	for _, pod := range pods {
+		var hint string
+		if pod.Reason == corev1.PodReasonUnschedulable {
+			hint = "The Pods of the Job can't be scheduled: " + pod.Message
+		}
+		if hint != "" && !slices.Contains(hints, hint) {
+			hints = append(hints, hint)
+		}
+		for _, container := range pod.Containers {
+			switch {
+			case container.Reason == "OOMKilled":
+				hint = fmt.Sprintf("Container %s was OOMKilled, raise its memory limit.", container.Name)
+			case container.ExitCode != 0:
+				hint = fmt.Sprintf("Container %s exited with code %d, its logs are in failedPods.", container.Name, container.ExitCode)
+			case container.Reason == "ErrImagePull" || container.Reason == "ImagePullBackOff" || container.Reason == "InvalidImageName":
+				hint = fmt.Sprintf("The image of container %s can't be pulled: %s", container.Name, container.Message)
+			case container.Reason == "CreateContainerConfigError":
+				hint = fmt.Sprintf("Container %s can't be created, check the ConfigMaps and Secrets it uses: %s", container.Name, container.Message)
+			default:
+				hint = fmt.Sprintf("Container %s is waiting (%s): %s", container.Name, container.Reason, container.Message)
+			}
+			if !slices.Contains(hints, hint) {
+				hints = append(hints, hint)
+			}
+		}
+	}
+
+	return hints
+}
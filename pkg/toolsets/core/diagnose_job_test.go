@@ -0,0 +1,138 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newJobPod(name string, job string, minute int, status corev1.PodStatus) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{"batch.kubernetes.io/job-name": job},
+			CreationTimestamp: metav1.NewTime(time.Date(2026, 3, 1, 11, minute, 0, 0, time.UTC)),
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "backup:1"}}},
+		Status: status,
+	}
+}
+
+func terminatedStatus(reason string, exitCode int32) corev1.PodStatus {
+	return corev1.PodStatus{
+		Phase: corev1.PodFailed,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "backup",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}},
+		}},
+	}
+}
+
+func TestDiagnoseJob(t *testing.T) {
+	objects := []runtime.Object{
+		newCronJob("backup", false, "backup-3"),
+		newCronJob("report", true),
+		newJob("backup-1", "backup", 10, batchv1.JobComplete, ""),
+		newJob("backup-2", "backup", 11, batchv1.JobFailed, batchv1.JobReasonBackoffLimitExceeded),
+		newJob("backup-3", "backup", 12, "", ""),
+		newJobPod("backup-2-a", "backup-2", 1, terminatedStatus("Error", 1)),
+		newJobPod("backup-2-b", "backup-2", 2, terminatedStatus("OOMKilled", 137)),
+		newJobPod("backup-2-c", "backup-2", 3, terminatedStatus("Error", 1)),
+		newJobPod("backup-1-a", "backup-1", 0, corev1.PodStatus{Phase: corev1.PodSucceeded}),
+		newJobPod("backup-3-a", "backup-3", 4, corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient memory.",
+			}},
+		}),
+	}
+
+	tests := map[string]struct {
+		params         diagnoseJobParams
+		expectedResult string
+		expectedError  string
+	}{
+		"failed run of a cronjob": {
+			params: diagnoseJobParams{Kind: "CronJob", Name: "backup", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"cronJob": {"name": "backup", "namespace": "default", "schedule": "0 * * * *", "suspended": false, "concurrencyPolicy": "Forbid", "active": ["backup-3"], "lastScheduleTime": "2026-03-01T12:00:00Z",
+					"lastJob": {"name": "backup-3", "namespace": "default", "cronJob": "backup", "status": "Running", "completions": 1, "active": 1, "succeeded": 0, "failed": 0, "startTime": "2026-03-01T12:00:00Z"}},
+				"job": {"name": "backup-2", "namespace": "default", "cronJob": "backup", "status": "Failed", "reason": "BackoffLimitExceeded", "message": "BackoffLimitExceeded message", "completions": 1, "active": 0, "succeeded": 0, "failed": 3, "startTime": "2026-03-01T11:00:00Z"},
+				"failedPods": [
+					{"name": "backup-2-c", "phase": "Failed", "containers": [{"name": "backup", "reason": "Error", "exitCode": 1, "logs": "fake logs"}]},
+					{"name": "backup-2-b", "phase": "Failed", "containers": [{"name": "backup", "reason": "OOMKilled", "exitCode": 137, "logs": "fake logs"}]},
+					{"name": "backup-2-a", "phase": "Failed", "containers": [{"name": "backup", "reason": "Error", "exitCode": 1, "logs": "fake logs"}]}
+				],
+				"hints": [
+					"The concurrencyPolicy is Forbid, new runs are skipped while backup-3 is active.",
+					"Job backup-2 failed after reaching its backoffLimit of 2 retries, the failures of its Pods are in failedPods.",
+					"Container backup exited with code 1, its logs are in failedPods.",
+					"Container backup was OOMKilled, raise its memory limit."
+				]
+			}`,
+		},
+		"unschedulable job": {
+			params: diagnoseJobParams{Kind: "job", Name: "backup-3", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"job": {"name": "backup-3", "namespace": "default", "cronJob": "backup", "status": "Running", "completions": 1, "active": 1, "succeeded": 0, "failed": 0, "startTime": "2026-03-01T12:00:00Z"},
+				"failedPods": [{"name": "backup-3-a", "phase": "Pending", "reason": "Unschedulable", "message": "0/3 nodes are available: 3 Insufficient memory."}],
+				"hints": ["The Pods of the Job can't be scheduled: 0/3 nodes are available: 3 Insufficient memory."]
+			}`,
+		},
+		"completed job": {
+			params: diagnoseJobParams{Kind: "Job", Name: "backup-1", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"job": {"name": "backup-1", "namespace": "default", "cronJob": "backup", "status": "Complete", "completions": 1, "active": 0, "succeeded": 1, "failed": 0, "startTime": "2026-03-01T10:00:00Z", "completionTime": "2026-03-01T10:05:00Z"},
+				"hints": []
+			}`,
+		},
+		"suspended cronjob without jobs": {
+			params: diagnoseJobParams{Kind: "CronJob", Name: "report", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"cronJob": {"name": "report", "namespace": "default", "schedule": "0 * * * *", "suspended": true, "concurrencyPolicy": "Forbid", "lastScheduleTime": "2026-03-01T12:00:00Z"},
+				"hints": [
+					"CronJob report is suspended, it doesn't create new Jobs until it is resumed with resumeCronJob.",
+					"CronJob report has no Jobs left, they were removed by its successfulJobsHistoryLimit and failedJobsHistoryLimit."
+				]
+			}`,
+		},
+		"job not found": {
+			params:        diagnoseJobParams{Kind: "Job", Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedError: "no Job missing in namespace default",
+		},
+		"unsupported kind": {
+			params:        diagnoseJobParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedError: "kind Deployment must be Job or CronJob",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, _ := newBatchTools(objects...)
+
+			result, _, err := tools.diagnoseJob(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dryRunIgnoredMetadata contains the metadata fields set by the API server on every write, which are left out of the diff.
+var dryRunIgnoredMetadata = []string{"creationTimestamp", "generation", "managedFields", "resourceVersion", "uid"}
+
+// fieldChange is a single difference between the current and the resulting object.
+type fieldChange struct {
+	// Path is the JSON pointer of the field (e.g. /spec/replicas).
+	Path string `json:"path"`
+	// Op is add, remove or replace.
+	Op       string `json:"op"`
+	OldValue any    `json:"oldValue,omitempty"`
+	NewValue any    `json:"newValue,omitempty"`
+}
+
+// dryRunResult is the response of a create or patch run in dry-run mode.
+type dryRunResult struct {
+	DryRun bool           `json:"dryRun"`
+	Object map[string]any `json:"object"`
+	Diff   []fieldChange  `json:"diff"`
+}
+
+// dryRunResponse returns the object the server would store and the changes it would make compared to the current object.
+// current is nil when the object doesn't exist yet.
+func dryRunResponse(current *unstructured.Unstructured, result *unstructured.Unstructured) (string, error) {
+	obj := result.DeepCopy()
+	response.Redact(obj)
+
+	dryRun, err := json.Marshal(dryRunResult{
+		DryRun: true,
+		Object: obj.Object,
+		Diff:   objectDiff(current, result),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(dryRun), nil
+}
+
+// objectDiff returns the changes between the object before a write and the one stored by the server, or that would
+// be stored in dry-run mode. before is nil when the object didn't exist. Sensitive values are redacted after computing
+// the diff, so changes to them are still reported.
+func objectDiff(before *unstructured.Unstructured, after *unstructured.Unstructured) []fieldChange {
+	var beforeObj map[string]any
+	if before != nil {
+		beforeObj = withoutServerMetadata(before.Object)
+	}
+
+	diff := diffValues("", beforeObj, withoutServerMetadata(after.Object))
+	for i := range diff {
+		path := splitJSONPointer(diff[i].Path)
+		diff[i].OldValue = response.RedactField(after.GetKind(), path, diff[i].OldValue)
+		diff[i].NewValue = response.RedactField(after.GetKind(), path, diff[i].NewValue)
+	}
+	if diff == nil {
+		diff = []fieldChange{}
+	}
+
+	return diff
+}
+
+// diffObject returns the changes made by a write as an object without a kind, added to the response after the
+// written object so the LLM can report them without getting it again. The UI context of the response isn't changed.
+func diffObject(before *unstructured.Unstructured, after *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	// the changes are converted to JSON values, like the fields of the other objects of the response
+	data, err := json.Marshal(objectDiff(before, after))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal diff: %w", err)
+	}
+	var diff []any
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal diff: %w", err)
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{"diff": diff}}, nil
+}
+
+// withoutServerMetadata returns a copy of the object without the metadata fields the server updates on every write.
+func withoutServerMetadata(obj map[string]any) map[string]any {
+	u := (&unstructured.Unstructured{Object: obj}).DeepCopy()
+	for _, field := range dryRunIgnoredMetadata {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+
+	return u.Object
+}
+
+// diffValues returns the changes needed to go from the old to the new value. Maps and lists are compared field by field;
+// other values, or values of different types, are replaced as a whole.
+func diffValues(path string, oldValue any, newValue any) []fieldChange {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	switch newTyped := newValue.(type) {
+	case map[string]any:
+		if oldTyped, ok := oldValue.(map[string]any); ok || oldValue == nil {
+			return diffMaps(path, oldTyped, newTyped)
+		}
+	case []any:
+		if oldTyped, ok := oldValue.([]any); ok {
+			return diffSlices(path, oldTyped, newTyped)
+		}
+	}
+
+	switch {
+	case oldValue == nil:
+		return []fieldChange{{Path: path, Op: "add", NewValue: newValue}}
+	case newValue == nil:
+		return []fieldChange{{Path: path, Op: "remove", OldValue: oldValue}}
+	default:
+		return []fieldChange{{Path: path, Op: "replace", OldValue: oldValue, NewValue: newValue}}
+	}
+}
+
+func diffMaps(path string, oldMap map[string]any, newMap map[string]any) []fieldChange {
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for k := range oldMap {
+		keys = append(keys, k)
+	}
+	for k := range newMap {
+		if _, ok := oldMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []fieldChange
+	for _, k := range keys {
+		fieldPath := path + "/" + escapeJSONPointer(k)
+		oldValue, inOld := oldMap[k]
+		newValue, inNew := newMap[k]
+		switch {
+		case !inOld:
+			changes = append(changes, fieldChange{Path: fieldPath, Op: "add", NewValue: newValue})
+		case !inNew:
+			changes = append(changes, fieldChange{Path: fieldPath, Op: "remove", OldValue: oldValue})
+		default:
+			changes = append(changes, diffValues(fieldPath, oldValue, newValue)...)
+		}
+	}
+
+	return changes
+}
+
+func diffSlices(path string, oldSlice []any, newSlice []any) []fieldChange {
+	var changes []fieldChange
+	for i := range max(len(oldSlice), len(newSlice)) {
+		itemPath := path + "/" + strconv.Itoa(i)
+		switch {
+		case i >= len(oldSlice):
+			changes = append(changes, fieldChange{Path: itemPath, Op: "add", NewValue: newSlice[i]})
+		case i >= len(newSlice):
+			changes = append(changes, fieldChange{Path: itemPath, Op: "remove", OldValue: oldSlice[i]})
+		default:
+			changes = append(changes, diffValues(itemPath, oldSlice[i], newSlice[i])...)
+		}
+	}
+
+	return changes
+}
+
+// escapeJSONPointer escapes a key to be used in a JSON pointer as defined in RFC 6901.
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// splitJSONPointer returns the unescaped keys of a JSON pointer.
+func splitJSONPointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	keys := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, key := range keys {
+		keys[i] = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+	}
+
+	return keys
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffValues(t *testing.T) {
+	tests := map[string]struct {
+		oldValue any
+		newValue any
+		expected []fieldChange
+	}{
+		"no changes": {
+			oldValue: map[string]any{"spec": map[string]any{"replicas": int64(1)}},
+			newValue: map[string]any{"spec": map[string]any{"replicas": int64(1)}},
+		},
+		"nested fields": {
+			oldValue: map[string]any{"spec": map[string]any{"replicas": int64(1), "paused": true}},
+			newValue: map[string]any{"spec": map[string]any{"replicas": int64(3), "strategy": "Recreate"}},
+			expected: []fieldChange{
+				{Path: "/spec/paused", Op: "remove", OldValue: true},
+				{Path: "/spec/replicas", Op: "replace", OldValue: int64(1), NewValue: int64(3)},
+				{Path: "/spec/strategy", Op: "add", NewValue: "Recreate"},
+			},
+		},
+		"list items": {
+			oldValue: map[string]any{"args": []any{"--a", "--b", "--c"}},
+			newValue: map[string]any{"args": []any{"--a", "--x"}},
+			expected: []fieldChange{
+				{Path: "/args/1", Op: "replace", OldValue: "--b", NewValue: "--x"},
+				{Path: "/args/2", Op: "remove", OldValue: "--c"},
+			},
+		},
+		"escaped keys": {
+			oldValue: map[string]any{"labels": map[string]any{}},
+			newValue: map[string]any{"labels": map[string]any{"app.kubernetes.io/name": "nginx"}},
+			expected: []fieldChange{
+				{Path: "/labels/app.kubernetes.io~1name", Op: "add", NewValue: "nginx"},
+			},
+		},
+		"type change": {
+			oldValue: map[string]any{"value": "1"},
+			newValue: map[string]any{"value": []any{"1"}},
+			expected: []fieldChange{
+				{Path: "/value", Op: "replace", OldValue: "1", NewValue: []any{"1"}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, diffValues("", test.oldValue, test.newValue))
+		})
+	}
+}
+
+func TestDryRunResponseRedactsSecrets(t *testing.T) {
+	secret := func(password string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": "db", "namespace": "default"},
+			"data":       map[string]any{"password": password},
+		}}
+	}
+
+	dryRun, err := dryRunResponse(secret("b2xk"), secret("c2VjcmV0"))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"dryRun": true,
+		"object": {"apiVersion":"v1","kind":"Secret","metadata":{"name":"db","namespace":"default"},"data":{"password":"<redacted, 6 bytes>"}},
+		"diff": [{"path":"/data/password","op":"replace","oldValue":"<redacted, 3 bytes>","newValue":"<redacted, 6 bytes>"}]
+	}`, dryRun)
+
+	dryRun, err = dryRunResponse(nil, secret("c2VjcmV0"))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"dryRun": true,
+		"object": {"apiVersion":"v1","kind":"Secret","metadata":{"name":"db","namespace":"default"},"data":{"password":"<redacted, 6 bytes>"}},
+		"diff": [
+			{"path":"/apiVersion","op":"add","newValue":"v1"},
+			{"path":"/data","op":"add","newValue":{"password":"<redacted, 6 bytes>"}},
+			{"path":"/kind","op":"add","newValue":"Secret"},
+			{"path":"/metadata","op":"add","newValue":{"name":"db","namespace":"default"}}
+		]
+	}`, dryRun)
+}
+
+func TestDryRunResponseRedactsAppliedSecrets(t *testing.T) {
+	appliedSecret := func(password string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]any{"name": "db", "namespace": "default", "annotations": map[string]any{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"Secret","stringData":{"password":"` + password + `"}}`,
+			}},
+			"data": map[string]any{"password": base64.StdEncoding.EncodeToString([]byte(password))},
+		}}
+	}
+
+	dryRun, err := dryRunResponse(appliedSecret("old"), appliedSecret("secret"))
+
+	require.NoError(t, err)
+	assert.NotContains(t, dryRun, `\"password\":\"old\"`)
+	assert.NotContains(t, dryRun, `\"password\":\"secret\"`)
+	assert.JSONEq(t, `{
+		"dryRun": true,
+		"object": {"apiVersion":"v1","kind":"Secret","data":{"password":"<redacted, 6 bytes>"},
+			"metadata":{"name":"db","namespace":"default","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"<redacted, 70 bytes>"}}},
+		"diff": [
+			{"path":"/data/password","op":"replace","oldValue":"<redacted, 3 bytes>","newValue":"<redacted, 6 bytes>"},
+			{"path":"/metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration","op":"replace","oldValue":"<redacted, 67 bytes>","newValue":"<redacted, 70 bytes>"}
+		]
+	}`, dryRun)
+
+	dryRun, err = dryRunResponse(nil, appliedSecret("secret"))
+
+	require.NoError(t, err)
+	assert.NotContains(t, dryRun, `\"password\":\"secret\"`)
+}
+
+func TestDiffObject(t *testing.T) {
+	configMap := func(data map[string]any, resourceVersion string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": "config", "namespace": "default", "resourceVersion": resourceVersion},
+			"data":       data,
+		}}
+	}
+
+	diff, err := diffObject(configMap(map[string]any{"replicas": "1"}, "1"), configMap(map[string]any{"replicas": "2", "mode": "ha"}, "2"))
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"diff": []any{
+		map[string]any{"path": "/data/mode", "op": "add", "newValue": "ha"},
+		map[string]any{"path": "/data/replicas", "op": "replace", "oldValue": "1", "newValue": "2"},
+	}}, diff.Object)
+
+	diff, err = diffObject(configMap(map[string]any{"replicas": "1"}, "1"), configMap(map[string]any{"replicas": "1"}, "2"))
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"diff": []any{}}, diff.Object, "the metadata updated by the server isn't a change")
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+const (
+	// execTimeout is the maximum time a command is allowed to run.
+	execTimeout = 30 * time.Second
+	// execMaxOutputBytes limits the stdout and stderr returned to the LLM.
+	execMaxOutputBytes = 64 * 1024
+	// execAnyArgs at the end of an allowlist entry allows any additional arguments.
+	execAnyArgs = "*"
+	// execOneArg at the end of an allowlist entry allows a single additional argument that isn't an option, e.g. the
+	// URL of a curl command whose options are all fixed by the entry.
+	execOneArg = "?"
+)
+
+// DefaultExecAllowlist contains the read-only diagnostic commands allowed by default in execInPod. cat only reads the
+// DNS and hosts configuration, since the other files and the environment of the containers can hold the values of
+// Secrets, and curl only takes a URL and reports the HTTP status code, so no option can write or send files.
+var DefaultExecAllowlist = []string{"cat /etc/resolv.conf", "cat /etc/hosts", "ls *", "ps *", "curl -s -o /dev/null -w %{http_code} ?"}
+
+// deniedArgs are the substrings of the arguments never allowed, whatever the allowlist, e.g. in
+// "cat /var/run/secrets/kubernetes.io/serviceaccount/token". It only guards against reading the service account
+// token with a broad allowlist entry, the entries must still only allow read-only commands.
+var deniedArgs = []string{"serviceaccount"}
+
+type execInPodParams struct {
+	Name      string   `json:"name" jsonschema:"the name of the pod"`
+	Namespace string   `json:"namespace" jsonschema:"the namespace of the pod"`
+	Cluster   string   `json:"cluster" jsonschema:"the cluster of the pod"`
+	Container string   `json:"container,omitempty" jsonschema:"the container where the command is run. Optional for pods with a single container"`
+	Command   []string `json:"command" jsonschema:"the command and its arguments, e.g. [\"cat\", \"/etc/resolv.conf\"]. It isn't run in a shell"`
+}
+
+// execResult is the output of a command run in a container.
+type execResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exitCode"`
+	// Truncated is true when stdout or stderr exceeded execMaxOutputBytes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// limitedBuffer is a buffer that discards everything written after its limit is reached.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.buf.Write(p[:max(remaining, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// execInPod runs a diagnostic command from the allowlist in a container and returns its output.
+func (t *Tools) execInPod(ctx context.Context, toolReq *mcp.CallToolRequest, params execInPodParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("execInPod called")
+
+	if !commandAllowed(t.ExecAllowlist, params.Command) {
+		return nil, nil, fmt.Errorf("command %q is not allowed, allowed commands are: %s", strings.Join(params.Command, " "), strings.Join(t.ExecAllowlist, ", "))
+	}
+
+	podResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      "pod",
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Pod", zap.String("tool", "execInPod"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+		middleware.Logger(ctx).Error("failed to convert unstructured object to Pod", zap.String("tool", "execInPod"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+	}
+
+	container := params.Container
+	if container == "" {
+		if len(pod.Spec.Containers) != 1 {
+			return nil, nil, fmt.Errorf("pod %s has %d containers, the container must be specified", pod.Name, len(pod.Spec.Containers))
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+	if !slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == container }) {
+		return nil, nil, fmt.Errorf("container %s not found in pod %s", container, pod.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: execMaxOutputBytes}
+	stderr := &limitedBuffer{limit: execMaxOutputBytes}
+	result := execResult{}
+	err = t.client.ExecInPod(ctx, client.ExecParams{
+		Cluster:   params.Cluster,
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		Container: container,
+		Command:   params.Command,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}, stdout, stderr)
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		// the command ran but failed, its output is still useful to the LLM
+		result.ExitCode = exitErr.ExitStatus()
+	} else if err != nil {
+		middleware.Logger(ctx).Error("failed to exec in pod", zap.String("tool", "execInPod"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to run command: %w", err)
+	}
+	result.Stdout = stdout.buf.String()
+	result.Stderr = stderr.buf.String()
+	result.Truncated = stdout.truncated || stderr.truncated
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "execInPod"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// commandAllowed reports whether the command matches an allowlist entry. An entry matches a command with exactly
+// the same arguments, that starts with the same arguments when the entry ends with "*" (e.g. "ls *"), or that has
+// a single argument more, which isn't an option, when the entry ends with "?" (e.g. "curl -s -o /dev/null ?").
+// Commands with an argument containing one of the deniedArgs are never allowed.
+func commandAllowed(allowlist []string, command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+	for _, arg := range command {
+		for _, denied := range deniedArgs {
+			if strings.Contains(strings.ToLower(arg), denied) {
+				return false
+			}
+		}
+	}
+
+	for _, entry := range allowlist {
+		allowed := strings.Fields(entry)
+		if len(allowed) == 0 {
+			continue
+		}
+		if allowed[len(allowed)-1] == execAnyArgs {
+			prefix := allowed[:len(allowed)-1]
+			if len(prefix) > 0 && len(command) >= len(prefix) && slices.Equal(command[:len(prefix)], prefix) {
+				return true
+			}
+			continue
+		}
+		if allowed[len(allowed)-1] == execOneArg {
+			prefix := allowed[:len(allowed)-1]
+			if len(prefix) > 0 && len(command) == len(prefix)+1 && slices.Equal(command[:len(prefix)], prefix) && !strings.HasPrefix(command[len(prefix)], "-") {
+				return true
+			}
+			continue
+		}
+		if slices.Equal(command, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
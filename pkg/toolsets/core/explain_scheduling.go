@@ -0,0 +1,547 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// The reasons why a node can't run a Pod, with the messages of the scheduler.
+const (
+	reasonUnschedulable        = "node(s) were unschedulable"
+	reasonNodeAffinity         = "node(s) didn't match Pod's node affinity/selector"
+	reasonNodePorts            = "node(s) didn't have free ports for the requested pod ports"
+	reasonTooManyPods          = "Too many pods"
+	reasonPodAffinity          = "node(s) didn't match pod affinity rules"
+	reasonPodAntiAffinity      = "node(s) didn't match pod anti-affinity rules"
+	reasonExistingAntiAffinity = "node(s) didn't satisfy existing pods anti-affinity rules"
+)
+
+type explainSchedulingParams struct {
+	Name      string `json:"name" jsonschema:"the name of the Pod"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the Pod"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Pod"`
+}
+
+// schedulingExplanation explains on which nodes a Pod can be scheduled and why it can't be on the others.
+type schedulingExplanation struct {
+	Pod       string `json:"pod"`
+	Scheduled bool   `json:"scheduled"`
+	NodeName  string `json:"nodeName,omitempty"`
+	// SchedulerMessage is the message of the PodScheduled condition, the one of the last FailedScheduling event.
+	SchedulerMessage string `json:"schedulerMessage,omitempty"`
+	// Summary counts the nodes by the reason they were rejected for, in the format of the FailedScheduling events.
+	Summary       string           `json:"summary,omitempty"`
+	FeasibleNodes []string         `json:"feasibleNodes"`
+	Nodes         []nodeScheduling `json:"nodes,omitempty"`
+	Failures      []fetch.Failure  `json:"failures,omitempty"`
+}
+
+// nodeScheduling holds the reasons why a node can't run the Pod.
+type nodeScheduling struct {
+	Name    string   `json:"name"`
+	Fits    bool     `json:"fits"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// schedulingState holds the cluster state the scheduling predicates of a Pod are evaluated with.
+type schedulingState struct {
+	pod   corev1.Pod
+	nodes []corev1.Node
+	// pods are the Pods bound to a node that didn't terminate, without the Pod being scheduled.
+	pods            []corev1.Pod
+	nodeLabels      map[string]map[string]string
+	namespaceLabels map[string]map[string]string
+}
+
+// explainScheduling evaluates the scheduler predicates of a Pod against every node of its cluster: node
+// unschedulable, taints and tolerations, node selector and affinity, host ports, resource fit and inter-pod
+// affinity, and reports the reasons why each node can't run it.
+func (t *Tools) explainScheduling(ctx context.Context, toolReq *mcp.CallToolRequest, params explainSchedulingParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("explainScheduling called")
+
+	podResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      "pod",
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Pod", zap.String("tool", "explainScheduling"), zap.Error(err))
+		return nil, nil, err
+	}
+	state := schedulingState{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &state.pod); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+	}
+
+	explanation := schedulingExplanation{
+		Pod:           params.Namespace + "/" + params.Name,
+		Scheduled:     state.pod.Spec.NodeName != "",
+		NodeName:      state.pod.Spec.NodeName,
+		FeasibleNodes: []string{},
+	}
+	for _, condition := range state.pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			explanation.SchedulerMessage = condition.Message
+		}
+	}
+	if !explanation.Scheduled {
+		explanation.Failures, err = t.fetchSchedulingState(ctx, toolReq, params.Cluster, &state)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get the nodes and the Pods", zap.String("tool", "explainScheduling"), zap.Error(err))
+			return nil, nil, err
+		}
+		explanation.Nodes, explanation.Summary = state.explain()
+		for _, node := range explanation.Nodes {
+			if node.Fits {
+				explanation.FeasibleNodes = append(explanation.FeasibleNodes, node.Name)
+			}
+		}
+	}
+
+	response, err := json.Marshal(explanation)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "explainScheduling"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// fetchSchedulingState reads the nodes, the Pods and the namespaces of the cluster concurrently. The namespaces are
+// only used by the namespace selectors of the inter-pod affinity terms, so failing to read them isn't an error.
+func (t *Tools) fetchSchedulingState(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, state *schedulingState) ([]fetch.Failure, error) {
+	list := func(ctx context.Context, kind string) ([]*unstructured.Unstructured, error) {
+		return t.client.GetResources(ctx, client.ListParams{
+			Cluster: cluster,
+			Kind:    kind,
+			URL:     toolReq.Extra.Header.Get(urlHeader),
+			Token:   middleware.Token(ctx),
+		})
+	}
+
+	g := fetch.New(ctx, 0)
+	g.Go("nodes", func(ctx context.Context) error {
+		nodeResources, err := list(ctx, "node")
+		if err != nil {
+			return fmt.Errorf("failed to get nodes: %w", err)
+		}
+		state.nodes = make([]corev1.Node, len(nodeResources))
+		state.nodeLabels = map[string]map[string]string{}
+		for i, nodeResource := range nodeResources {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(nodeResource.Object, &state.nodes[i]); err != nil {
+				return fmt.Errorf("failed to convert unstructured object to Node: %w", err)
+			}
+			state.nodeLabels[state.nodes[i].Name] = state.nodes[i].Labels
+		}
+		slices.SortFunc(state.nodes, func(a, b corev1.Node) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+		return nil
+	})
+	g.Go("pods", func(ctx context.Context) error {
+		podResources, err := list(ctx, "pod")
+		if err != nil {
+			return fmt.Errorf("failed to get pods: %w", err)
+		}
+		for _, podResource := range podResources {
+			var pod corev1.Pod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+				return fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+			}
+			if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			if pod.Namespace == state.pod.Namespace && pod.Name == state.pod.Name {
+				continue
+			}
+			state.pods = append(state.pods, pod)
+		}
+		return nil
+	})
+	g.GoOptional("namespaces", func(ctx context.Context) error {
+		namespaceResources, err := list(ctx, "namespace")
+		if err != nil {
+			return err
+		}
+		state.namespaceLabels = map[string]map[string]string{}
+		for _, namespaceResource := range namespaceResources {
+			state.namespaceLabels[namespaceResource.GetName()] = namespaceResource.GetLabels()
+		}
+		return nil
+	})
+
+	return g.Wait()
+}
+
+// explain evaluates the predicates of the Pod against each node, in the order of the filters of the scheduler. The
+// nodes list all their reasons, while the summary only counts the ones of the first failed predicate of each node,
+// like the scheduler which stops at the first filter rejecting a node.
+func (s *schedulingState) explain() ([]nodeScheduling, string) {
+	podsByNode := map[string][]corev1.Pod{}
+	for _, pod := range s.pods {
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	nodes := make([]nodeScheduling, 0, len(s.nodes))
+	reasonCounts := map[string]int{}
+	available := 0
+	for _, node := range s.nodes {
+		n := nodeScheduling{Name: node.Name}
+		counted := false
+		for _, predicate := range []func() []string{
+			func() []string { return unschedulableReasons(s.pod, node) },
+			func() []string { return taintReasons(s.pod, node) },
+			func() []string { return nodeAffinityReasons(s.pod, node) },
+			func() []string { return nodePortsReasons(s.pod, podsByNode[node.Name]) },
+			func() []string { return resourceFitReasons(s.pod, node, podsByNode[node.Name]) },
+			func() []string { return s.podAffinityReasons(node) },
+		} {
+			reasons := predicate()
+			n.Reasons = append(n.Reasons, reasons...)
+			if len(reasons) > 0 && !counted {
+				for _, reason := range reasons {
+					reasonCounts[reason]++
+				}
+				counted = true
+			}
+		}
+		n.Fits = len(n.Reasons) == 0
+		if n.Fits {
+			available++
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, schedulingSummary(len(s.nodes), available, reasonCounts)
+}
+
+// schedulingSummary formats the reasons the nodes were rejected for like the FailedScheduling events, e.g.
+// "0/3 nodes are available: 1 Insufficient cpu, 2 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }."
+func schedulingSummary(nodes int, available int, reasonCounts map[string]int) string {
+	reasons := make([]string, 0, len(reasonCounts))
+	for reason, count := range reasonCounts {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	slices.Sort(reasons)
+	if len(reasons) == 0 {
+		return fmt.Sprintf("%d/%d nodes are available.", available, nodes)
+	}
+
+	return fmt.Sprintf("%d/%d nodes are available: %s.", available, nodes, strings.Join(reasons, ", "))
+}
+
+// unschedulableReasons rejects cordoned nodes, unless the Pod tolerates their unschedulable taint.
+func unschedulableReasons(pod corev1.Pod, node corev1.Node) []string {
+	if !node.Spec.Unschedulable {
+		return nil
+	}
+	taint := &corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+	if tolerated(pod, taint) {
+		return nil
+	}
+
+	return []string{reasonUnschedulable}
+}
+
+// taintReasons rejects the nodes with a NoSchedule or NoExecute taint not tolerated by the Pod. Like the scheduler,
+// only the first untolerated taint is reported.
+func taintReasons(pod corev1.Pod, node corev1.Node) []string {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if !tolerated(pod, taint) {
+			return []string{fmt.Sprintf("node(s) had untolerated taint {%s: %s}", taint.Key, taint.Value)}
+		}
+	}
+
+	return nil
+}
+
+func tolerated(pod corev1.Pod, taint *corev1.Taint) bool {
+	return slices.ContainsFunc(pod.Spec.Tolerations, func(toleration corev1.Toleration) bool {
+		return toleration.ToleratesTaint(taint)
+	})
+}
+
+// nodeAffinityReasons rejects the nodes not matching the node selector of the Pod or one of the terms of its
+// required node affinity.
+func nodeAffinityReasons(pod corev1.Pod, node corev1.Node) []string {
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return []string{reasonNodeAffinity}
+	}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil || pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if !slices.ContainsFunc(terms, func(term corev1.NodeSelectorTerm) bool { return nodeSelectorTermMatches(term, node) }) {
+		return []string{reasonNodeAffinity}
+	}
+
+	return nil
+}
+
+// nodeSelectorTermMatches returns whether the node matches all the expressions and fields of the term. A term
+// without any of them matches no node.
+func nodeSelectorTermMatches(term corev1.NodeSelectorTerm, node corev1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, requirement := range term.MatchExpressions {
+		value, ok := node.Labels[requirement.Key]
+		if !nodeSelectorRequirementMatches(requirement, value, ok) {
+			return false
+		}
+	}
+	for _, requirement := range term.MatchFields {
+		if requirement.Key != metav1.ObjectNameField || !nodeSelectorRequirementMatches(requirement, node.Name, true) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nodeSelectorRequirementMatches(requirement corev1.NodeSelectorRequirement, value string, ok bool) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return ok && slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !ok || !slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return ok
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !ok
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !ok || len(requirement.Values) != 1 {
+			return false
+		}
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		expected, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == corev1.NodeSelectorOpGt {
+			return actual > expected
+		}
+		return actual < expected
+	}
+
+	return false
+}
+
+// nodePortsReasons rejects the nodes where a Pod already uses one of the host ports of the Pod.
+func nodePortsReasons(pod corev1.Pod, nodePods []corev1.Pod) []string {
+	type hostPort struct {
+		protocol corev1.Protocol
+		port     int32
+	}
+	hostPorts := func(pod corev1.Pod) []hostPort {
+		var ports []hostPort
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			for _, port := range container.Ports {
+				if port.HostPort > 0 {
+					ports = append(ports, hostPort{protocol: cmp.Or(port.Protocol, corev1.ProtocolTCP), port: port.HostPort})
+				}
+			}
+		}
+		return ports
+	}
+
+	wanted := hostPorts(pod)
+	if len(wanted) == 0 {
+		return nil
+	}
+	for _, nodePod := range nodePods {
+		for _, used := range hostPorts(nodePod) {
+			if slices.Contains(wanted, used) {
+				return []string{reasonNodePorts}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resourceFitReasons rejects the nodes whose allocatable resources left by their Pods are lower than the requests of
+// the Pod, for CPU, memory, pods and the extended resources (e.g. nvidia.com/gpu).
+func resourceFitReasons(pod corev1.Pod, node corev1.Node, nodePods []corev1.Pod) []string {
+	var reasons []string
+	if int64(len(nodePods))+1 > node.Status.Allocatable.Pods().Value() {
+		reasons = append(reasons, reasonTooManyPods)
+	}
+
+	requested := map[corev1.ResourceName]int64{}
+	for _, nodePod := range nodePods {
+		for name, value := range schedulingRequests(nodePod) {
+			requested[name] += value
+		}
+	}
+	requests := schedulingRequests(pod)
+	names := make([]corev1.ResourceName, 0, len(requests))
+	for name := range requests {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if requests[name] == 0 {
+			continue
+		}
+		allocatable := node.Status.Allocatable[name]
+		if requests[name] > resourceValue(name, allocatable)-requested[name] {
+			reasons = append(reasons, "Insufficient "+string(name))
+		}
+	}
+
+	return reasons
+}
+
+// schedulingRequests returns the requests of a Pod by resource like podRequests, for all the resources: the
+// highest of the sum of the requests of its containers and the requests of each init container, plus the Pod
+// overhead. CPU is in millicores.
+func schedulingRequests(pod corev1.Pod) map[corev1.ResourceName]int64 {
+	requests := map[corev1.ResourceName]int64{}
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			requests[name] += resourceValue(name, quantity)
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			requests[name] = max(requests[name], resourceValue(name, quantity))
+		}
+	}
+	for name, quantity := range pod.Spec.Overhead {
+		requests[name] += resourceValue(name, quantity)
+	}
+
+	return requests
+}
+
+func resourceValue(name corev1.ResourceName, quantity resource.Quantity) int64 {
+	if name == corev1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+
+	return quantity.Value()
+}
+
+// podAffinityReasons rejects the nodes breaking the required pod affinity and anti-affinity terms of the Pod, or
+// the required anti-affinity terms of the Pods already running.
+func (s *schedulingState) podAffinityReasons(node corev1.Node) []string {
+	var reasons []string
+	for _, pod := range s.pods {
+		if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+			continue
+		}
+		for _, term := range pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if s.termMatchesPod(pod, term, s.pod) && s.sameTopology(term.TopologyKey, node.Name, pod.Spec.NodeName) {
+				reasons = append(reasons, reasonExistingAntiAffinity)
+				break
+			}
+		}
+		if len(reasons) > 0 {
+			break
+		}
+	}
+
+	affinity := s.pod.Spec.Affinity
+	if affinity != nil && affinity.PodAffinity != nil {
+		for _, term := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			matching := s.matchingPods(term)
+			// like the scheduler, the first Pod of a group matching its own affinity term can run anywhere
+			if len(matching) == 0 && s.termMatchesPod(s.pod, term, s.pod) {
+				continue
+			}
+			if !slices.ContainsFunc(matching, func(pod corev1.Pod) bool { return s.sameTopology(term.TopologyKey, node.Name, pod.Spec.NodeName) }) {
+				reasons = append(reasons, reasonPodAffinity)
+				break
+			}
+		}
+	}
+	if affinity != nil && affinity.PodAntiAffinity != nil {
+		for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if slices.ContainsFunc(s.matchingPods(term), func(pod corev1.Pod) bool { return s.sameTopology(term.TopologyKey, node.Name, pod.Spec.NodeName) }) {
+				reasons = append(reasons, reasonPodAntiAffinity)
+				break
+			}
+		}
+	}
+
+	return reasons
+}
+
+// matchingPods returns the running Pods matched by an affinity term of the Pod.
+func (s *schedulingState) matchingPods(term corev1.PodAffinityTerm) []corev1.Pod {
+	var matching []corev1.Pod
+	for _, pod := range s.pods {
+		if s.termMatchesPod(s.pod, term, pod) {
+			matching = append(matching, pod)
+		}
+	}
+
+	return matching
+}
+
+// termMatchesPod returns whether the affinity term of the owner Pod matches the labels and the namespace of a Pod.
+// Without namespaces and namespace selector, the term only matches the Pods of the namespace of its owner.
+func (s *schedulingState) termMatchesPod(owner corev1.Pod, term corev1.PodAffinityTerm, pod corev1.Pod) bool {
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil || term.LabelSelector == nil || !selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+
+	namespaces := sets.New(term.Namespaces...)
+	if len(namespaces) == 0 && term.NamespaceSelector == nil {
+		namespaces.Insert(owner.Namespace)
+	}
+	if namespaces.Has(pod.Namespace) {
+		return true
+	}
+	if term.NamespaceSelector == nil {
+		return false
+	}
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(term.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	namespaceLabels, ok := s.namespaceLabels[pod.Namespace]
+
+	return (ok || namespaceSelector.Empty()) && namespaceSelector.Matches(labels.Set(namespaceLabels))
+}
+
+// sameTopology returns whether two nodes are in the same topology domain, both having the same value of the
+// topology key label.
+func (s *schedulingState) sameTopology(topologyKey string, node string, otherNode string) bool {
+	value, ok := s.nodeLabels[node][topologyKey]
+	otherValue, otherOk := s.nodeLabels[otherNode][topologyKey]
+
+	return ok && otherOk && value == otherValue
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newSchedulingNode(name string, cpu string, nodeLabels map[string]string, spec corev1.NodeSpec) *corev1.Node {
+	node := newCapacityNode(name, cpu, "16Gi", spec.Taints...)
+	node.Labels = map[string]string{"kubernetes.io/hostname": name}
+	for key, value := range nodeLabels {
+		node.Labels[key] = value
+	}
+	node.Spec.Unschedulable = spec.Unschedulable
+
+	return node
+}
+
+func newPendingPod(spec corev1.PodSpec) *corev1.Pod {
+	pod := newCapacityPod("web-0", "", corev1.PodPending, []corev1.Container{requestsContainer("app", "1", "1Gi")})
+	pod.Labels = map[string]string{"app": "web"}
+	pod.Spec.NodeName = spec.NodeName
+	pod.Spec.NodeSelector = spec.NodeSelector
+	pod.Spec.Tolerations = spec.Tolerations
+	pod.Spec.Affinity = spec.Affinity
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/4 nodes are available: 1 Insufficient cpu.",
+	}}
+
+	return pod
+}
+
+func TestExplainScheduling(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "pods"}:       "PodList",
+		{Version: "v1", Resource: "nodes"}:      "NodeList",
+		{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+	}
+	controlPlaneTaint := corev1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}
+	runningWeb := newCapacityPod("web-1", "big", corev1.PodRunning, []corev1.Container{requestsContainer("app", "1", "1Gi")})
+	runningWeb.Labels = map[string]string{"app": "web"}
+	objects := []runtime.Object{
+		newSchedulingNode("control-plane", "4", nil, corev1.NodeSpec{Taints: []corev1.Taint{controlPlaneTaint}}),
+		newSchedulingNode("cordoned", "4", nil, corev1.NodeSpec{Unschedulable: true}),
+		newSchedulingNode("small", "1", map[string]string{"disktype": "ssd"}, corev1.NodeSpec{}),
+		newSchedulingNode("big", "8", nil, corev1.NodeSpec{}),
+		newCapacityPod("db-0", "small", corev1.PodRunning, []corev1.Container{requestsContainer("db", "500m", "1Gi")}),
+		runningWeb,
+	}
+	webAntiAffinity := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			TopologyKey:   "kubernetes.io/hostname",
+		}},
+	}}
+
+	tests := map[string]struct {
+		pod                 *corev1.Pod
+		expectedExplanation schedulingExplanation
+	}{
+		"nodes rejected by taints, cordon and resources": {
+			pod: newPendingPod(corev1.PodSpec{}),
+			expectedExplanation: schedulingExplanation{
+				Pod:              "default/web-0",
+				SchedulerMessage: "0/4 nodes are available: 1 Insufficient cpu.",
+				Summary:          "1/4 nodes are available: 1 Insufficient cpu, 1 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }, 1 node(s) were unschedulable.",
+				FeasibleNodes:    []string{"big"},
+				Nodes: []nodeScheduling{
+					{Name: "big", Fits: true},
+					{Name: "control-plane", Reasons: []string{"node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }"}},
+					{Name: "cordoned", Reasons: []string{"node(s) were unschedulable"}},
+					{Name: "small", Reasons: []string{"Insufficient cpu"}},
+				},
+			},
+		},
+		"summary counts the first failed predicate of each node": {
+			pod: newPendingPod(corev1.PodSpec{NodeSelector: map[string]string{"disktype": "ssd"}}),
+			expectedExplanation: schedulingExplanation{
+				Pod:              "default/web-0",
+				SchedulerMessage: "0/4 nodes are available: 1 Insufficient cpu.",
+				Summary:          "0/4 nodes are available: 1 Insufficient cpu, 1 node(s) didn't match Pod's node affinity/selector, 1 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }, 1 node(s) were unschedulable.",
+				FeasibleNodes:    []string{},
+				Nodes: []nodeScheduling{
+					{Name: "big", Reasons: []string{"node(s) didn't match Pod's node affinity/selector"}},
+					{Name: "control-plane", Reasons: []string{"node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }", "node(s) didn't match Pod's node affinity/selector"}},
+					{Name: "cordoned", Reasons: []string{"node(s) were unschedulable", "node(s) didn't match Pod's node affinity/selector"}},
+					{Name: "small", Reasons: []string{"Insufficient cpu"}},
+				},
+			},
+		},
+		"tolerated taint and pod anti-affinity": {
+			pod: newPendingPod(corev1.PodSpec{
+				Tolerations: []corev1.Toleration{{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists}},
+				Affinity:    webAntiAffinity,
+			}),
+			expectedExplanation: schedulingExplanation{
+				Pod:              "default/web-0",
+				SchedulerMessage: "0/4 nodes are available: 1 Insufficient cpu.",
+				Summary:          "1/4 nodes are available: 1 Insufficient cpu, 1 node(s) didn't match pod anti-affinity rules, 1 node(s) were unschedulable.",
+				FeasibleNodes:    []string{"control-plane"},
+				Nodes: []nodeScheduling{
+					{Name: "big", Reasons: []string{"node(s) didn't match pod anti-affinity rules"}},
+					{Name: "control-plane", Fits: true},
+					{Name: "cordoned", Reasons: []string{"node(s) were unschedulable"}},
+					{Name: "small", Reasons: []string{"Insufficient cpu"}},
+				},
+			},
+		},
+		"required node affinity": {
+			pod: newPendingPod(corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "disktype", Operator: corev1.NodeSelectorOpDoesNotExist}},
+					MatchFields:      []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"big"}}},
+				}}},
+			}}}),
+			expectedExplanation: schedulingExplanation{
+				Pod:              "default/web-0",
+				SchedulerMessage: "0/4 nodes are available: 1 Insufficient cpu.",
+				Summary:          "0/4 nodes are available: 1 node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }, 1 node(s) were unschedulable, 2 node(s) didn't match Pod's node affinity/selector.",
+				FeasibleNodes:    []string{},
+				Nodes: []nodeScheduling{
+					{Name: "big", Reasons: []string{"node(s) didn't match Pod's node affinity/selector"}},
+					{Name: "control-plane", Reasons: []string{"node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }"}},
+					{Name: "cordoned", Reasons: []string{"node(s) were unschedulable"}},
+					{Name: "small", Reasons: []string{"node(s) didn't match Pod's node affinity/selector", "Insufficient cpu"}},
+				},
+			},
+		},
+		"scheduled pod": {
+			pod: newPendingPod(corev1.PodSpec{NodeName: "big"}),
+			expectedExplanation: schedulingExplanation{
+				Pod:              "default/web-0",
+				Scheduled:        true,
+				NodeName:         "big",
+				SchedulerMessage: "0/4 nodes are available: 1 Insufficient cpu.",
+				FeasibleNodes:    []string{},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), listKinds, append(objects, test.pod)...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.explainScheduling(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, explainSchedulingParams{Cluster: "local", Namespace: "default", Name: "web-0"})
+
+			require.NoError(t, err)
+			var explanation schedulingExplanation
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &explanation))
+			assert.Equal(t, test.expectedExplanation, explanation)
+		})
+	}
+}
+
+func TestExplainSchedulingPodNotFound(t *testing.T) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(podScheme())
+	c := &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+	tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+	_, _, err := tools.explainScheduling(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+	}, explainSchedulingParams{Cluster: "local", Namespace: "default", Name: "web-0"})
+
+	assert.ErrorContains(t, err, `pods "web-0" not found`)
+}
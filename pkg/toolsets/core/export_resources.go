@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// maxExportResources is the maximum number of resources exported by exportResources in a call.
+const maxExportResources = 500
+
+// defaultExportedKinds are the kinds exported from the namespace when no kinds are given, the ones usually needed to
+// run its workloads in another cluster.
+var defaultExportedKinds = []string{"configmap", "secret", "serviceaccount", "persistentvolumeclaim", "service", "deployment", "statefulset", "daemonset", "cronjob", "ingress"}
+
+// exportedMetadataFields are the fields of the metadata set by the API server or only valid in the source cluster.
+var exportedMetadataFields = []string{"namespace", "uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+	"deletionGracePeriodSeconds", "selfLink", "managedFields", "ownerReferences"}
+
+// clusterAnnotationPrefixes are the prefixes of the annotations set by the controllers of the source cluster.
+var clusterAnnotationPrefixes = []string{lastAppliedAnnotation, "deployment.kubernetes.io/", "pv.kubernetes.io/", "volume.kubernetes.io/",
+	"volume.beta.kubernetes.io/", "field.cattle.io/publicEndpoints", "cattle.io/timestamp"}
+
+type exportResourcesParams struct {
+	Cluster       string   `json:"cluster" jsonschema:"the cluster of the resources"`
+	Namespace     string   `json:"namespace" jsonschema:"the namespace of the resources" validate:"required"`
+	Kinds         []string `json:"kinds,omitempty" jsonschema:"the kinds of the resources exported"`
+	LabelSelector string   `json:"labelSelector,omitempty" jsonschema:"the label selector the resources must match"`
+}
+
+// exportSummary lists the resources of the exported manifest, and the ones left out of it.
+type exportSummary struct {
+	Cluster   string           `json:"cluster"`
+	Namespace string           `json:"namespace"`
+	URI       string           `json:"uri"`
+	Exported  []exportedObject `json:"exported"`
+	Skipped   []exportedObject `json:"skipped,omitempty"`
+	Notes     []string         `json:"notes,omitempty"`
+}
+
+// exportedObject is a resource of the namespace. Reason explains why a skipped resource isn't exported.
+type exportedObject struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// exportResources returns the resources of the selected kinds of a namespace as a multi-document YAML manifest,
+// without their status and the fields set by the source cluster, so it can be applied in another namespace or
+// cluster. The manifest is returned as an embedded MCP resource, next to a summary of the exported resources.
+func (t *Tools) exportResources(ctx context.Context, toolReq *mcp.CallToolRequest, params exportResourcesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("exportResources called")
+
+	kinds := params.Kinds
+	if len(kinds) == 0 {
+		kinds = defaultExportedKinds
+	}
+	summary := exportSummary{
+		Cluster:   params.Cluster,
+		Namespace: params.Namespace,
+		URI:       fmt.Sprintf("export://%s/%s.yaml", params.Cluster, params.Namespace),
+		Exported:  []exportedObject{},
+	}
+	var documents []string
+	var redacted bool
+	for _, kind := range kinds {
+		objs, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:       params.Cluster,
+			Kind:          strings.ToLower(kind),
+			Namespace:     params.Namespace,
+			URL:           toolReq.Extra.Header.Get(urlHeader),
+			Token:         middleware.Token(ctx),
+			LabelSelector: params.LabelSelector,
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list resources", zap.String("tool", "exportResources"), zap.String("kind", kind), zap.Error(err))
+			return nil, nil, err
+		}
+		slices.SortFunc(objs, func(a, b *unstructured.Unstructured) int {
+			return strings.Compare(a.GetName(), b.GetName())
+		})
+		for _, obj := range objs {
+			if reason := exportSkipReason(obj); reason != "" {
+				summary.Skipped = append(summary.Skipped, exportedObject{Kind: obj.GetKind(), Name: obj.GetName(), Reason: reason})
+				continue
+			}
+			if len(summary.Exported) == maxExportResources {
+				return nil, nil, fmt.Errorf("more than %d resources to export, use a label selector or less kinds to export less resources", maxExportResources)
+			}
+			sanitizeExportedObject(obj)
+			if obj.GetKind() == "Secret" {
+				response.Redact(obj)
+				redacted = true
+			}
+			document, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to marshal %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			documents = append(documents, string(document))
+			summary.Exported = append(summary.Exported, exportedObject{Kind: obj.GetKind(), Name: obj.GetName()})
+		}
+	}
+	if redacted {
+		summary.Notes = append(summary.Notes, "the values of the Secrets are redacted unless the server shows sensitive values, set them before applying the manifest")
+	}
+	if len(summary.Exported) > 0 {
+		summary.Notes = append(summary.Notes, fmt.Sprintf("the resources have no namespace, apply the manifest with kubectl apply -n <namespace> -f %s.yaml", params.Namespace))
+	}
+
+	summaryResponse, err := json.Marshal(summary)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "exportResources"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(summaryResponse)},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI:      summary.URI,
+				MIMEType: "application/yaml",
+				Text:     strings.Join(documents, "---\n"),
+			}},
+		},
+	}, nil, nil
+}
+
+// exportSkipReason returns why the resource isn't exported, or an empty string if it is. The resources created by
+// controllers are recreated by them in the target namespace.
+func exportSkipReason(obj *unstructured.Unstructured) string {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Controller != nil && *owner.Controller {
+			return fmt.Sprintf("managed by %s %s", owner.Kind, owner.Name)
+		}
+	}
+	switch {
+	case obj.GetKind() == "ConfigMap" && obj.GetName() == "kube-root-ca.crt":
+		return "created in every namespace by Kubernetes"
+	case obj.GetKind() == "ServiceAccount" && obj.GetName() == "default":
+		return "created in every namespace by Kubernetes"
+	case obj.GetKind() == "Secret" && obj.Object["type"] == "kubernetes.io/service-account-token":
+		return "service account token generated by Kubernetes"
+	}
+
+	return ""
+}
+
+// sanitizeExportedObject removes the status of the resource, and the fields of its metadata and spec that are set
+// by the API server or the controllers of the source cluster and can't be applied in another cluster.
+func sanitizeExportedObject(obj *unstructured.Unstructured) {
+	delete(obj.Object, "status")
+	if metadata, ok := obj.Object["metadata"].(map[string]any); ok {
+		for _, field := range exportedMetadataFields {
+			delete(metadata, field)
+		}
+	}
+	annotations := obj.GetAnnotations()
+	for key := range annotations {
+		if slices.ContainsFunc(clusterAnnotationPrefixes, func(prefix string) bool { return strings.HasPrefix(key, prefix) }) {
+			delete(annotations, key)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	} else {
+		obj.SetAnnotations(annotations)
+	}
+
+	switch obj.GetKind() {
+	case "Service":
+		// the cluster IPs and node ports are allocated by the cluster
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+		unstructured.RemoveNestedField(obj.Object, "spec", "healthCheckNodePort")
+		ports, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+		for _, p := range ports {
+			if port, ok := p.(map[string]any); ok {
+				delete(port, "nodePort")
+			}
+		}
+		if ports != nil {
+			_ = unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+		}
+	case "PersistentVolumeClaim":
+		// the claim is bound to a new volume in the target cluster
+		unstructured.RemoveNestedField(obj.Object, "spec", "volumeName")
+	case "Pod":
+		unstructured.RemoveNestedField(obj.Object, "spec", "nodeName")
+	case "Job":
+		// the selector and the labels of the pods are generated from the uid of the Job
+		unstructured.RemoveNestedField(obj.Object, "spec", "selector")
+		for _, label := range []string{"controller-uid", "batch.kubernetes.io/controller-uid", "job-name", "batch.kubernetes.io/job-name"} {
+			unstructured.RemoveNestedField(obj.Object, "spec", "template", "metadata", "labels", label)
+		}
+	}
+}
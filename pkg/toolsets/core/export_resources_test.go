@@ -0,0 +1,202 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func newExportFakeDynClient() *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "configmaps"}:      "ConfigMapList",
+		{Group: "", Version: "v1", Resource: "secrets"}:         "SecretList",
+		{Group: "", Version: "v1", Resource: "services"}:        "ServiceList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+	},
+		&corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "settings",
+				Namespace:       "shop",
+				UID:             "1234",
+				ResourceVersion: "42",
+				Labels:          map[string]string{"app": "shop"},
+				Annotations:     map[string]string{lastAppliedAnnotation: "{}", "owner": "payments"},
+				ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}},
+			},
+			Data: map[string]string{"mode": "production"},
+		},
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "shop"},
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: corev1.ServiceSpec{
+				Type:       corev1.ServiceTypeNodePort,
+				ClusterIP:  "10.43.0.10",
+				ClusterIPs: []string{"10.43.0.10"},
+				Selector:   map[string]string{"app": "shop"},
+				Ports:      []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080), NodePort: 30080}},
+			},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{}},
+		},
+		&appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "shop",
+				Generation:  3,
+				Annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+			},
+			Spec:   appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&appsv1.ReplicaSet{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "web-5d9c7",
+				Namespace:       "shop",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: ptr.To(true)}},
+			},
+		},
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "billing"},
+		},
+	)
+}
+
+func TestExportResources(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params           exportResourcesParams
+		expectedSummary  string
+		expectedManifest string
+	}{
+		"export sanitized resources": {
+			params: exportResourcesParams{Cluster: "local", Namespace: "shop", Kinds: []string{"configmap", "secret", "service", "deployment", "replicaset"}},
+			expectedSummary: `{
+				"cluster": "local",
+				"namespace": "shop",
+				"uri": "export://local/shop.yaml",
+				"exported": [
+					{"kind": "ConfigMap", "name": "settings"},
+					{"kind": "Secret", "name": "db"},
+					{"kind": "Service", "name": "web"},
+					{"kind": "Deployment", "name": "web"}
+				],
+				"skipped": [
+					{"kind": "ConfigMap", "name": "kube-root-ca.crt", "reason": "created in every namespace by Kubernetes"},
+					{"kind": "ReplicaSet", "name": "web-5d9c7", "reason": "managed by Deployment web"}
+				],
+				"notes": [
+					"the values of the Secrets are redacted unless the server shows sensitive values, set them before applying the manifest",
+					"the resources have no namespace, apply the manifest with kubectl apply -n <namespace> -f shop.yaml"
+				]
+			}`,
+			expectedManifest: `apiVersion: v1
+data:
+  mode: production
+kind: ConfigMap
+metadata:
+  annotations:
+    owner: payments
+  labels:
+    app: shop
+  name: settings
+---
+apiVersion: v1
+data:
+  password: <redacted, 7 bytes>
+kind: Secret
+metadata:
+  name: db
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+    targetPort: 8080
+  selector:
+    app: shop
+  type: NodePort
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  selector: null
+  strategy: {}
+  template:
+    metadata: {}
+    spec:
+      containers: null
+`,
+		},
+		"no resources match": {
+			params: exportResourcesParams{Cluster: "local", Namespace: "shop", Kinds: []string{"configmap"}, LabelSelector: "app=billing"},
+			expectedSummary: `{
+				"cluster": "local",
+				"namespace": "shop",
+				"uri": "export://local/shop.yaml",
+				"exported": []
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return newExportFakeDynClient(), nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.exportResources(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			require.Len(t, result.Content, 2)
+			assert.JSONEq(t, test.expectedSummary, result.Content[0].(*mcp.TextContent).Text)
+			manifest := result.Content[1].(*mcp.EmbeddedResource).Resource
+			assert.Equal(t, "export://local/shop.yaml", manifest.URI)
+			assert.Equal(t, "application/yaml", manifest.MIMEType)
+			assert.Equal(t, test.expectedManifest, manifest.Text)
+		})
+	}
+}
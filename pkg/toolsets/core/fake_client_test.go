@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -58,6 +60,30 @@ func (f *fakeToolsClient) GetResources(ctx context.Context, params client.ListPa
 	return f.client.GetResources(ctx, params)
 }
 
+// GetResourcesPage validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) GetResourcesPage(ctx context.Context, params client.ListParams) ([]*unstructured.Unstructured, string, error) {
+	if err := f.validateToken(params.Token); err != nil {
+		return nil, "", err
+	}
+	return f.client.GetResourcesPage(ctx, params)
+}
+
+// ListWithSteve validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) ListWithSteve(ctx context.Context, params client.SteveListParams) ([]*unstructured.Unstructured, string, error) {
+	if err := f.validateToken(params.Token); err != nil {
+		return nil, "", err
+	}
+	return f.client.ListWithSteve(ctx, params)
+}
+
+// ResolveGVR validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) ResolveGVR(ctx context.Context, token string, url string, cluster string, kind string) (schema.GroupVersionResource, error) {
+	if err := f.validateToken(token); err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return f.client.ResolveGVR(ctx, token, url, cluster, kind)
+}
+
 // CreateClientSet validates the token and delegates to the wrapped client.
 func (f *fakeToolsClient) CreateClientSet(ctx context.Context, token string, url string, cluster string) (kubernetes.Interface, error) {
 	if err := f.validateToken(token); err != nil {
@@ -65,3 +91,51 @@ func (f *fakeToolsClient) CreateClientSet(ctx context.Context, token string, url
 	}
 	return f.client.CreateClientSet(ctx, token, url, cluster)
 }
+
+// ExecInPod validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) ExecInPod(ctx context.Context, params client.ExecParams, stdout io.Writer, stderr io.Writer) error {
+	if err := f.validateToken(params.Token); err != nil {
+		return err
+	}
+	return f.client.ExecInPod(ctx, params, stdout, stderr)
+}
+
+// ProxyGet validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) ProxyGet(ctx context.Context, params client.ProxyGetParams) (*http.Response, error) {
+	if err := f.validateToken(params.Token); err != nil {
+		return nil, err
+	}
+	return f.client.ProxyGet(ctx, params)
+}
+
+// ProxyPost validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) ProxyPost(ctx context.Context, params client.ProxyGetParams, body []byte) (*http.Response, error) {
+	if err := f.validateToken(params.Token); err != nil {
+		return nil, err
+	}
+	return f.client.ProxyPost(ctx, params, body)
+}
+
+// RawGet validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) RawGet(ctx context.Context, params client.RawGetParams) (*http.Response, error) {
+	if err := f.validateToken(params.Token); err != nil {
+		return nil, err
+	}
+	return f.client.RawGet(ctx, params)
+}
+
+// Changes validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) Changes(ctx context.Context, token string, url string, cluster string) ([]client.Change, error) {
+	if err := f.validateToken(token); err != nil {
+		return nil, err
+	}
+	return f.client.Changes(ctx, token, url, cluster)
+}
+
+// UndoChange validates the token and delegates to the wrapped client.
+func (f *fakeToolsClient) UndoChange(ctx context.Context, params client.UndoParams) (*unstructured.Unstructured, error) {
+	if err := f.validateToken(params.Token); err != nil {
+		return nil, err
+	}
+	return f.client.UndoChange(ctx, params)
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMaxFanOut is the default number of clusters queried at the same time by all the tool calls.
+const DefaultMaxFanOut = 50
+
+// goFanOut runs fn in the group once there is a free slot in the fan-out pool. maxConcurrentClusters bounds the
+// clusters queried by a single tool call, the pool bounds the ones queried by all the concurrent tool calls.
+func (t *Tools) goFanOut(ctx context.Context, g *errgroup.Group, fn func() error) {
+	g.Go(func() error {
+		if t.fanOut != nil {
+			if err := t.fanOut.Acquire(ctx, 1); err != nil {
+				return err
+			}
+			defer t.fanOut.Release(1)
+		}
+
+		return fn()
+	})
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+func TestGoFanOut(t *testing.T) {
+	tools := Tools{fanOut: semaphore.NewWeighted(2)}
+	var running, maxRunning atomic.Int32
+
+	var g errgroup.Group
+	for range 10 {
+		tools.goFanOut(t.Context(), &g, func() error {
+			current := running.Add(1)
+			for {
+				observed := maxRunning.Load()
+				if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+
+	assert.NoError(t, g.Wait())
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
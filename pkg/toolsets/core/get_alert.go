@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+)
+
+type getAlertParams struct {
+	Cluster     string `json:"cluster" jsonschema:"the cluster of the Alertmanager"`
+	Fingerprint string `json:"fingerprint" jsonschema:"the fingerprint of the alert, as returned by listAlerts"`
+}
+
+// getAlert returns an alert of the Alertmanager of rancher-monitoring with all its labels and annotations, the
+// receivers it was sent to and the silences and alerts suppressing it.
+func (t *Tools) getAlert(ctx context.Context, toolReq *mcp.CallToolRequest, params getAlertParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getAlert called")
+
+	// the v2 API can't get an alert by fingerprint, so all the active alerts are listed
+	alerts, err := t.getAlerts(ctx, toolReq, params.Cluster, url.Values{"active": {"true"}})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get alerts", zap.String("tool", "getAlert"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	for _, alert := range alerts {
+		if alert.Fingerprint != params.Fingerprint {
+			continue
+		}
+		response, err := json.Marshal(alert)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getAlert"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+		}, nil, nil
+	}
+
+	return nil, nil, fmt.Errorf("alert %s not found in cluster %s, it may have been resolved", params.Fingerprint, params.Cluster)
+}
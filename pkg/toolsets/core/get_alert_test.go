@@ -0,0 +1,36 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAlert(t *testing.T) {
+	fakeToken := "fakeToken"
+	queries := make(chan string, 1)
+	srv := newFakeAlertmanager(t, fakeToken, queries, nil)
+	tools := newFakeProxyTools(fakeToken, fakeAlertmanagerService)
+	toolReq := &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+	}
+
+	result, _, err := tools.getAlert(middleware.WithToken(t.Context(), fakeToken), toolReq, getAlertParams{Cluster: "local", Fingerprint: "b2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "active=true", <-queries)
+	var alert alertmanagerAlert
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &alert))
+	assert.Equal(t, "KubePodCrashLooping", alert.Labels["alertname"])
+	assert.Equal(t, "http://prometheus/graph", alert.GeneratorURL)
+	assert.Equal(t, "slack", alert.Receivers[0].Name)
+
+	_, _, err = tools.getAlert(middleware.WithToken(t.Context(), fakeToken), toolReq, getAlertParams{Cluster: "local", Fingerprint: "d4"})
+
+	<-queries
+	assert.ErrorContains(t, err, "alert d4 not found in cluster local, it may have been resolved")
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const defaultCapacityThreshold = 80
+
+type getClusterCapacityParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster to check"`
+	Threshold int64  `json:"threshold,omitempty" jsonschema:"percentage of the allocatable resources above which a node is highlighted. Defaults to 80"`
+	CPU       string `json:"cpu,omitempty" jsonschema:"the CPU request of one replica of the pod to fit (e.g. 500m)"`
+	Memory    string `json:"memory,omitempty" jsonschema:"the memory request of one replica of the pod to fit (e.g. 256Mi)"`
+}
+
+// resourceCapacity compares the allocatable amount of a resource with the sum of the requests of the Pods.
+type resourceCapacity struct {
+	Allocatable string `json:"allocatable"`
+	Requested   string `json:"requested"`
+	Available   string `json:"available"`
+	// Percent is the requested amount as a percentage of the allocatable amount.
+	Percent int64 `json:"percent"`
+}
+
+// nodeCapacity holds the allocatable and requested resources of a node.
+type nodeCapacity struct {
+	Name string `json:"name"`
+	// Schedulable is false for cordoned nodes and nodes with NoSchedule or NoExecute taints. They aren't taken into
+	// account to fit new replicas.
+	Schedulable    bool             `json:"schedulable"`
+	CPU            resourceCapacity `json:"cpu"`
+	Memory         resourceCapacity `json:"memory"`
+	Pods           resourceCapacity `json:"pods"`
+	AboveThreshold bool             `json:"aboveThreshold"`
+	// Fits is the number of replicas of the given pod that fit in the node.
+	Fits *int64 `json:"fits,omitempty"`
+}
+
+// clusterCapacity is the result of getClusterCapacity.
+type clusterCapacity struct {
+	Cluster             string           `json:"cluster"`
+	Threshold           int64            `json:"threshold"`
+	CPU                 resourceCapacity `json:"cpu"`
+	Memory              resourceCapacity `json:"memory"`
+	Pods                resourceCapacity `json:"pods"`
+	NodesAboveThreshold []string         `json:"nodesAboveThreshold"`
+	// Fits is the number of replicas of the given pod that fit in the schedulable nodes.
+	Fits  *int64         `json:"fits,omitempty"`
+	Nodes []nodeCapacity `json:"nodes"`
+}
+
+// resourceAmounts holds amounts of CPU in millicores, memory in bytes and Pods.
+type resourceAmounts struct {
+	cpu, memory, pods int64
+}
+
+func (a *resourceAmounts) add(b resourceAmounts) {
+	a.cpu += b.cpu
+	a.memory += b.memory
+	a.pods += b.pods
+}
+
+// getClusterCapacity aggregates the allocatable resources of the nodes of a cluster and the requests of the Pods
+// running on them, and estimates how many more replicas of a pod with the given requests would fit.
+func (t *Tools) getClusterCapacity(ctx context.Context, toolReq *mcp.CallToolRequest, params getClusterCapacityParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getClusterCapacity called")
+
+	threshold := cmp.Or(params.Threshold, defaultCapacityThreshold)
+	if threshold < 0 || threshold > 100 {
+		return nil, nil, fmt.Errorf("threshold must be between 0 and 100, got %d", threshold)
+	}
+	replica, err := replicaRequests(params.CPU, params.Memory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeResources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: params.Cluster,
+		Kind:    "node",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get nodes", zap.String("tool", "getClusterCapacity"), zap.Error(err))
+		return nil, nil, err
+	}
+	podResources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: params.Cluster,
+		Kind:    "pod",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get pods", zap.String("tool", "getClusterCapacity"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	requestedByNode := map[string]resourceAmounts{}
+	for _, podResource := range podResources {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requested := requestedByNode[pod.Spec.NodeName]
+		requested.add(podRequests(pod))
+		requestedByNode[pod.Spec.NodeName] = requested
+	}
+
+	capacity := clusterCapacity{
+		Cluster:             params.Cluster,
+		Threshold:           threshold,
+		NodesAboveThreshold: []string{},
+		Nodes:               make([]nodeCapacity, 0, len(nodeResources)),
+	}
+	if replica != nil {
+		capacity.Fits = new(int64)
+	}
+	var allocatable, requested resourceAmounts
+	for _, nodeResource := range nodeResources {
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(nodeResource.Object, &node); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Node: %w", err)
+		}
+		nodeAllocatable := resourceAmounts{
+			cpu:    node.Status.Allocatable.Cpu().MilliValue(),
+			memory: node.Status.Allocatable.Memory().Value(),
+			pods:   node.Status.Allocatable.Pods().Value(),
+		}
+		nodeRequested := requestedByNode[node.Name]
+		allocatable.add(nodeAllocatable)
+		requested.add(nodeRequested)
+
+		n := newNodeCapacity(node, nodeAllocatable, nodeRequested, threshold)
+		if replica != nil && n.Schedulable {
+			fits := replicasFit(nodeAllocatable, nodeRequested, *replica)
+			n.Fits = &fits
+			*capacity.Fits += fits
+		}
+		if n.AboveThreshold {
+			capacity.NodesAboveThreshold = append(capacity.NodesAboveThreshold, n.Name)
+		}
+		capacity.Nodes = append(capacity.Nodes, n)
+	}
+	capacity.CPU, capacity.Memory, capacity.Pods = newResourceCapacities(allocatable, requested)
+	slices.Sort(capacity.NodesAboveThreshold)
+	slices.SortFunc(capacity.Nodes, func(a, b nodeCapacity) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	response, err := json.Marshal(capacity)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getClusterCapacity"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// replicaRequests parses the requests of the pod to fit. It returns nil if none is given.
+func replicaRequests(cpu string, memory string) (*resourceAmounts, error) {
+	if cpu == "" && memory == "" {
+		return nil, nil
+	}
+
+	replica := &resourceAmounts{pods: 1}
+	if cpu != "" {
+		q, err := resource.ParseQuantity(cpu)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu %q: %w", cpu, err)
+		}
+		replica.cpu = q.MilliValue()
+	}
+	if memory != "" {
+		q, err := resource.ParseQuantity(memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory %q: %w", memory, err)
+		}
+		replica.memory = q.Value()
+	}
+
+	return replica, nil
+}
+
+// podRequests returns the resources requested by a Pod, like the scheduler computes them: the highest of the sum of
+// the requests of its containers and the requests of each init container, plus the Pod overhead.
+func podRequests(pod corev1.Pod) resourceAmounts {
+	var containers, initContainers resourceAmounts
+	for _, container := range pod.Spec.Containers {
+		containers.cpu += container.Resources.Requests.Cpu().MilliValue()
+		containers.memory += container.Resources.Requests.Memory().Value()
+	}
+	for _, container := range pod.Spec.InitContainers {
+		initContainers.cpu = max(initContainers.cpu, container.Resources.Requests.Cpu().MilliValue())
+		initContainers.memory = max(initContainers.memory, container.Resources.Requests.Memory().Value())
+	}
+
+	return resourceAmounts{
+		cpu:    max(containers.cpu, initContainers.cpu) + pod.Spec.Overhead.Cpu().MilliValue(),
+		memory: max(containers.memory, initContainers.memory) + pod.Spec.Overhead.Memory().Value(),
+		pods:   1,
+	}
+}
+
+func newNodeCapacity(node corev1.Node, allocatable resourceAmounts, requested resourceAmounts, threshold int64) nodeCapacity {
+	n := nodeCapacity{
+		Name:        node.Name,
+		Schedulable: !node.Spec.Unschedulable,
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
+			n.Schedulable = false
+		}
+	}
+	n.CPU, n.Memory, n.Pods = newResourceCapacities(allocatable, requested)
+	n.AboveThreshold = n.CPU.Percent > threshold || n.Memory.Percent > threshold || n.Pods.Percent > threshold
+
+	return n
+}
+
+func newResourceCapacities(allocatable resourceAmounts, requested resourceAmounts) (resourceCapacity, resourceCapacity, resourceCapacity) {
+	cpu := func(v int64) string { return resource.NewMilliQuantity(v, resource.DecimalSI).String() }
+	memory := func(v int64) string { return resource.NewQuantity(v, resource.BinarySI).String() }
+	pods := func(v int64) string { return resource.NewQuantity(v, resource.DecimalSI).String() }
+
+	return newResourceCapacity(allocatable.cpu, requested.cpu, cpu),
+		newResourceCapacity(allocatable.memory, requested.memory, memory),
+		newResourceCapacity(allocatable.pods, requested.pods, pods)
+}
+
+func newResourceCapacity(allocatable int64, requested int64, format func(int64) string) resourceCapacity {
+	c := resourceCapacity{
+		Allocatable: format(allocatable),
+		Requested:   format(requested),
+		Available:   format(max(allocatable-requested, 0)),
+	}
+	if allocatable > 0 {
+		c.Percent = requested * 100 / allocatable
+	}
+
+	return c
+}
+
+// replicasFit returns how many replicas with the given requests fit in the resources of a node left by its Pods.
+func replicasFit(allocatable resourceAmounts, requested resourceAmounts, replica resourceAmounts) int64 {
+	fits := max(allocatable.pods-requested.pods, 0)
+	if replica.cpu > 0 {
+		fits = min(fits, max(allocatable.cpu-requested.cpu, 0)/replica.cpu)
+	}
+	if replica.memory > 0 {
+		fits = min(fits, max(allocatable.memory-requested.memory, 0)/replica.memory)
+	}
+
+	return fits
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func newCapacityNode(name string, cpu string, memory string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func newCapacityPod(name string, node string, phase corev1.PodPhase, containers []corev1.Container, initContainers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName:       node,
+			Containers:     containers,
+			InitContainers: initContainers,
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func requestsContainer(name string, cpu string, memory string) corev1.Container {
+	return corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{
+			Requests: resourceList(cpu, memory),
+		},
+	}
+}
+
+func TestGetClusterCapacity(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	objects := []runtime.Object{
+		newCapacityNode("node-1", "8", "16Gi"),
+		newCapacityNode("node-2", "4", "8Gi", corev1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}),
+		// the init container requests more CPU than the sum of the containers
+		newCapacityPod("pod-a", "node-1", corev1.PodRunning,
+			[]corev1.Container{requestsContainer("app", "3", "4Gi"), requestsContainer("sidecar", "1", "1Gi")},
+			requestsContainer("init", "5", "1Gi")),
+		newCapacityPod("pod-b", "node-1", corev1.PodRunning, []corev1.Container{requestsContainer("app", "2", "2Gi")}),
+		newCapacityPod("pod-completed", "node-1", corev1.PodSucceeded, []corev1.Container{requestsContainer("app", "4", "4Gi")}),
+		newCapacityPod("pod-pending", "", corev1.PodPending, []corev1.Container{requestsContainer("app", "4", "4Gi")}),
+		newCapacityPod("pod-c", "node-2", corev1.PodRunning, []corev1.Container{requestsContainer("app", "1", "1Gi")}),
+	}
+	node1 := nodeCapacity{
+		Name:           "node-1",
+		Schedulable:    true,
+		CPU:            resourceCapacity{Allocatable: "8", Requested: "7", Available: "1", Percent: 87},
+		Memory:         resourceCapacity{Allocatable: "16Gi", Requested: "7Gi", Available: "9Gi", Percent: 43},
+		Pods:           resourceCapacity{Allocatable: "110", Requested: "2", Available: "108", Percent: 1},
+		AboveThreshold: true,
+	}
+	node2 := nodeCapacity{
+		Name:   "node-2",
+		CPU:    resourceCapacity{Allocatable: "4", Requested: "1", Available: "3", Percent: 25},
+		Memory: resourceCapacity{Allocatable: "8Gi", Requested: "1Gi", Available: "7Gi", Percent: 12},
+		Pods:   resourceCapacity{Allocatable: "110", Requested: "1", Available: "109", Percent: 0},
+	}
+	clusterCPU := resourceCapacity{Allocatable: "12", Requested: "8", Available: "4", Percent: 66}
+	clusterMemory := resourceCapacity{Allocatable: "24Gi", Requested: "8Gi", Available: "16Gi", Percent: 33}
+	clusterPods := resourceCapacity{Allocatable: "220", Requested: "3", Available: "217", Percent: 1}
+
+	fittingNode1 := node1
+	fittingNode1.Fits = ptr.To(int64(2))
+	relaxedNode1 := node1
+	relaxedNode1.AboveThreshold = false
+
+	tests := map[string]struct {
+		params           getClusterCapacityParams
+		expectedCapacity clusterCapacity
+		expectedError    string
+	}{
+		"capacity of the nodes": {
+			params: getClusterCapacityParams{Cluster: "local"},
+			expectedCapacity: clusterCapacity{
+				Cluster:             "local",
+				Threshold:           80,
+				CPU:                 clusterCPU,
+				Memory:              clusterMemory,
+				Pods:                clusterPods,
+				NodesAboveThreshold: []string{"node-1"},
+				Nodes:               []nodeCapacity{node1, node2},
+			},
+		},
+		"replicas that fit in the schedulable nodes": {
+			params: getClusterCapacityParams{Cluster: "local", CPU: "500m", Memory: "1Gi"},
+			expectedCapacity: clusterCapacity{
+				Cluster:             "local",
+				Threshold:           80,
+				CPU:                 clusterCPU,
+				Memory:              clusterMemory,
+				Pods:                clusterPods,
+				NodesAboveThreshold: []string{"node-1"},
+				Fits:                ptr.To(int64(2)),
+				Nodes:               []nodeCapacity{fittingNode1, node2},
+			},
+		},
+		"custom threshold": {
+			params: getClusterCapacityParams{Cluster: "local", Threshold: 90},
+			expectedCapacity: clusterCapacity{
+				Cluster:             "local",
+				Threshold:           90,
+				CPU:                 clusterCPU,
+				Memory:              clusterMemory,
+				Pods:                clusterPods,
+				NodesAboveThreshold: []string{},
+				Nodes:               []nodeCapacity{relaxedNode1, node2},
+			},
+		},
+		"invalid threshold": {
+			params:        getClusterCapacityParams{Cluster: "local", Threshold: 120},
+			expectedError: "threshold must be between 0 and 100, got 120",
+		},
+		"invalid cpu": {
+			params:        getClusterCapacityParams{Cluster: "local", CPU: "half"},
+			expectedError: `invalid cpu "half"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(nodeScheme(), objects...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getClusterCapacity(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var capacity clusterCapacity
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &capacity))
+			assert.Equal(t, test.expectedCapacity, capacity)
+		})
+	}
+}
+
+func TestReplicasFit(t *testing.T) {
+	allocatable := resourceAmounts{cpu: 4000, memory: 8 << 30, pods: 10}
+
+	tests := map[string]struct {
+		requested resourceAmounts
+		replica   resourceAmounts
+		expected  int64
+	}{
+		"limited by cpu": {
+			requested: resourceAmounts{cpu: 3000, memory: 1 << 30, pods: 2},
+			replica:   resourceAmounts{cpu: 250, memory: 256 << 20, pods: 1},
+			expected:  4,
+		},
+		"limited by memory": {
+			requested: resourceAmounts{cpu: 1000, memory: 7 << 30, pods: 2},
+			replica:   resourceAmounts{cpu: 100, memory: 512 << 20, pods: 1},
+			expected:  2,
+		},
+		"limited by pods": {
+			requested: resourceAmounts{pods: 9},
+			replica:   resourceAmounts{cpu: 100, pods: 1},
+			expected:  1,
+		},
+		"overcommitted node": {
+			requested: resourceAmounts{cpu: 5000, pods: 2},
+			replica:   resourceAmounts{cpu: 100, pods: 1},
+			expected:  0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, replicasFit(allocatable, test.requested, test.replica))
+		})
+	}
+}
@@ -1,87 +1,395 @@
 package core
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	// maxConcurrentClusters limits the number of clusters queried at the same time.
+	maxConcurrentClusters = 10
+	// podsPageSize is the number of pods listed at once, so the pods of a cluster aren't all held in memory.
+	podsPageSize    = 500
+	defaultRegistry = "docker.io"
+)
+
 type getClusterImagesParams struct {
-	Clusters []string `json:"clusters" jsonschema:"the clusters where images are returned"`
+	Clusters   []string `json:"clusters" jsonschema:"the clusters where images are returned"`
+	Namespace  string   `json:"namespace,omitempty" jsonschema:"only return images used in this namespace"`
+	Registry   string   `json:"registry,omitempty" jsonschema:"only return images from this registry (e.g. docker.io, registry.rancher.com)"`
+	TagPattern string   `json:"tagPattern,omitempty" jsonschema:"regular expression, only return images whose tag matches it"`
+	Distinct   bool     `json:"distinct,omitempty" jsonschema:"return each image once with the clusters using it, instead of the images of the workloads of each cluster"`
+}
+
+// containerImage describes a container image used by a workload.
+type containerImage struct {
+	Image        string `json:"image"`
+	Registry     string `json:"registry"`
+	Repository   string `json:"repository"`
+	Tag          string `json:"tag,omitempty"`
+	Digest       string `json:"digest,omitempty"`
+	Namespace    string `json:"namespace"`
+	WorkloadKind string `json:"workloadKind"`
+	WorkloadName string `json:"workloadName"`
+	Container    string `json:"container"`
+	PullPolicy   string `json:"pullPolicy,omitempty"`
+	// Pods is the number of pods of the workload running the image.
+	Pods int `json:"pods"`
+}
+
+// distinctImage describes a container image used in one or more clusters.
+type distinctImage struct {
+	Image      string   `json:"image"`
+	Registry   string   `json:"registry"`
+	Repository string   `json:"repository"`
+	Tag        string   `json:"tag,omitempty"`
+	Digest     string   `json:"digest,omitempty"`
+	Clusters   []string `json:"clusters"`
+	// Workloads is the number of workload containers running the image in all the clusters.
+	Workloads int `json:"workloads"`
+	// Pods is the number of pods running the image in all the clusters.
+	Pods int `json:"pods"`
+}
+
+// distinctImages is the response of getClusterImages when the images are deduplicated across clusters.
+type distinctImages struct {
+	Images []distinctImage `json:"images"`
+	// Errors contains the error of each cluster that couldn't be queried.
+	Errors map[string]string `json:"errors,omitempty"`
 }
 
 // getClusterImages retrieves all container images used across specified clusters.
 // If no clusters are provided, it fetches images from all available clusters.
-// Returns a JSON map of cluster names to lists of container images.
+// Returns a JSON map of cluster names to lists of container images attributed to their workloads, or the list of
+// distinct images with the clusters using them.
 func (t *Tools) getClusterImages(ctx context.Context, toolReq *mcp.CallToolRequest, params getClusterImagesParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("getClusterImages called")
-
-	var clusters []string
-	if len(params.Clusters) == 0 {
-		clusterList, err := t.client.GetResources(ctx, client.ListParams{
-			Cluster: "local",
-			Kind:    "managementcluster",
-			URL:     toolReq.Extra.Header.Get(urlHeader),
-			Token:   middleware.Token(ctx),
-		})
+	middleware.Logger(ctx).Debug("getClusterImages called")
 
-		if err != nil {
-			zap.L().Error("failed to get clusters", zap.String("tool", "getClusterImages"), zap.Error(err))
-			return nil, nil, fmt.Errorf("failed to get clusters: %w", err)
-		}
-		for _, cluster := range clusterList {
-			clusters = append(clusters, cluster.GetName())
+	result := map[string]any{}
+	distinct := map[string]*distinctImage{}
+	failedClusters, err := t.collectClusterImages(ctx, toolReq, params, func(cluster string, images []containerImage) {
+		if !params.Distinct {
+			result[cluster] = images
+			return
 		}
+		addDistinctImages(distinct, cluster, images)
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to collect images", zap.String("tool", "getClusterImages"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var body any = result
+	if params.Distinct {
+		body = newDistinctImages(distinct, failedClusters)
 	} else {
-		clusters = params.Clusters
+		for cluster, err := range failedClusters {
+			result[cluster] = map[string]string{"error": err.Error()}
+		}
+	}
+
+	response, err := json.Marshal(body)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getClusterImages"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marsha JSON: %w", err)
 	}
 
-	imagesInClusters := map[string][]string{}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
 
+// collectClusterImages builds the container image inventory of the requested clusters, querying the clusters concurrently
+// with a time limit each. The images of each cluster are passed to collect as soon as they are read, one cluster at a
+// time, so they can be aggregated without keeping the images of all the clusters. The clusters that couldn't be queried
+// are returned with their error instead of failing the others.
+func (t *Tools) collectClusterImages(ctx context.Context, toolReq *mcp.CallToolRequest, params getClusterImagesParams, collect func(cluster string, images []containerImage)) (map[string]error, error) {
+	var tagPattern *regexp.Regexp
+	if params.TagPattern != "" {
+		var err error
+		tagPattern, err = regexp.Compile(params.TagPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tagPattern %q: %w", params.TagPattern, err)
+		}
+	}
+
+	clusters, err := t.clustersOrAll(ctx, toolReq, params.Clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	failedClusters := map[string]error{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentClusters)
 	for _, cluster := range clusters {
-		images := []string{}
-		unstructuredPods, err := t.client.GetResources(ctx, client.ListParams{
-			Cluster: cluster,
-			Kind:    "pod",
-			URL:     toolReq.Extra.Header.Get(urlHeader),
-			Token:   middleware.Token(ctx),
+		t.goFanOut(gCtx, g, func() error {
+			images, err := t.clusterImages(gCtx, toolReq, cluster, params.Namespace, newImageAggregator(params.Registry, tagPattern))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failedClusters[cluster] = err
+				return nil
+			}
+			collect(cluster, images)
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return failedClusters, nil
+}
+
+// clusterImages returns the images of the pods of a cluster, stopping after fetch.DefaultTimeout. The pods are listed
+// in pages of podsPageSize and only their images are kept.
+func (t *Tools) clusterImages(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string, aggregator *imageAggregator) ([]containerImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetch.DefaultTimeout)
+	defer cancel()
+
+	continueToken := ""
+	for {
+		unstructuredPods, next, err := t.client.GetResourcesPage(ctx, client.ListParams{
+			Cluster:   cluster,
+			Kind:      "pod",
+			Namespace: namespace,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+			Limit:     podsPageSize,
+			Continue:  continueToken,
 		})
 		if err != nil {
-			zap.L().Error("failed to get pods", zap.String("tool", "getClusterImages"), zap.Error(err))
-			return nil, nil, fmt.Errorf("failed to get pods: %w", err)
+			return nil, fmt.Errorf("failed to get pods: %w", err)
 		}
 		for _, unstructuredPod := range unstructuredPods {
 			var pod corev1.Pod
 			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredPod.Object, &pod); err != nil {
-				zap.L().Error("failed convert unstructured object to Pod", zap.String("tool", "getClusterImages"), zap.Error(err))
-				return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
-			}
-			for _, container := range pod.Spec.InitContainers {
-				images = append(images, container.Image)
+				return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
 			}
-			for _, container := range pod.Spec.Containers {
-				images = append(images, container.Image)
+			aggregator.add(pod)
+		}
+		if next == "" {
+			return aggregator.result(), nil
+		}
+		continueToken = next
+	}
+}
+
+// addDistinctImages adds the images of a cluster to the distinct images, keyed by image and digest.
+func addDistinctImages(distinct map[string]*distinctImage, cluster string, images []containerImage) {
+	for _, image := range images {
+		key := image.Image + "@" + image.Digest
+		d, ok := distinct[key]
+		if !ok {
+			d = &distinctImage{
+				Image:      image.Image,
+				Registry:   image.Registry,
+				Repository: image.Repository,
+				Tag:        image.Tag,
+				Digest:     image.Digest,
 			}
+			distinct[key] = d
+		}
+		if !slices.Contains(d.Clusters, cluster) {
+			d.Clusters = append(d.Clusters, cluster)
+		}
+		d.Workloads++
+		d.Pods += image.Pods
+	}
+}
+
+// newDistinctImages returns the distinct images sorted by image and digest, with their clusters sorted.
+func newDistinctImages(distinct map[string]*distinctImage, failedClusters map[string]error) distinctImages {
+	result := distinctImages{Images: make([]distinctImage, 0, len(distinct))}
+	for _, image := range distinct {
+		slices.Sort(image.Clusters)
+		result.Images = append(result.Images, *image)
+	}
+	slices.SortFunc(result.Images, func(a, b distinctImage) int {
+		return cmp.Or(cmp.Compare(a.Image, b.Image), cmp.Compare(a.Digest, b.Digest))
+	})
+	if len(failedClusters) > 0 {
+		result.Errors = map[string]string{}
+		for cluster, err := range failedClusters {
+			result.Errors[cluster] = err.Error()
 		}
+	}
 
-		imagesInClusters[cluster] = images
+	return result
+}
+
+// clustersOrAll returns the provided clusters, or the IDs of all clusters managed by Rancher if none are provided.
+func (t *Tools) clustersOrAll(ctx context.Context, toolReq *mcp.CallToolRequest, clusters []string) ([]string, error) {
+	if len(clusters) > 0 {
+		return clusters, nil
 	}
 
-	response, err := json.Marshal(imagesInClusters)
+	clusterList, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: "local",
+		Kind:    "managementcluster",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+		// only the names are needed
+		MetadataOnly: true,
+	})
 	if err != nil {
-		zap.L().Error("failed to create response", zap.String("tool", "getClusterImages"), zap.Error(err))
-		return nil, nil, fmt.Errorf("failed to marsha JSON: %w", err)
+		return nil, fmt.Errorf("failed to get clusters: %w", err)
+	}
+	for _, cluster := range clusterList {
+		clusters = append(clusters, cluster.GetName())
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
-	}, nil, nil
+	return clusters, nil
+}
+
+// imageKey identifies the image of a container of a workload.
+type imageKey struct {
+	namespace, workloadKind, workloadName, container, image string
+}
+
+// imageAggregator groups the images of all init and regular containers of pods by workload and container, one
+// pod at a time, optionally filtered by registry and tag.
+type imageAggregator struct {
+	registry   string
+	tagPattern *regexp.Regexp
+	images     map[imageKey]*containerImage
+}
+
+func newImageAggregator(registry string, tagPattern *regexp.Regexp) *imageAggregator {
+	return &imageAggregator{
+		registry:   registry,
+		tagPattern: tagPattern,
+		images:     map[imageKey]*containerImage{},
+	}
+}
+
+// add adds the images of the containers of the pod.
+func (a *imageAggregator) add(pod corev1.Pod) {
+	workloadKind, workloadName := utils.PodWorkload(pod)
+	imageIDs := map[string]string{}
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		imageIDs[status.Name] = status.ImageID
+	}
+
+	for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+		ref := parseImageReference(container.Image)
+		if ref.Digest == "" {
+			ref.Digest = digestFromImageID(imageIDs[container.Name])
+		}
+		if a.registry != "" && ref.Registry != a.registry {
+			continue
+		}
+		if a.tagPattern != nil && !a.tagPattern.MatchString(ref.Tag) {
+			continue
+		}
+
+		key := imageKey{pod.Namespace, workloadKind, workloadName, container.Name, container.Image}
+		if image, ok := a.images[key]; ok {
+			image.Pods++
+			if image.Digest == "" {
+				image.Digest = ref.Digest
+			}
+			continue
+		}
+		a.images[key] = &containerImage{
+			Image:        container.Image,
+			Registry:     ref.Registry,
+			Repository:   ref.Repository,
+			Tag:          ref.Tag,
+			Digest:       ref.Digest,
+			Namespace:    pod.Namespace,
+			WorkloadKind: workloadKind,
+			WorkloadName: workloadName,
+			Container:    container.Name,
+			PullPolicy:   string(container.ImagePullPolicy),
+			Pods:         1,
+		}
+	}
+}
+
+// result returns the images added, sorted by namespace, workload and container.
+func (a *imageAggregator) result() []containerImage {
+	images := make([]containerImage, 0, len(a.images))
+	for _, image := range a.images {
+		images = append(images, *image)
+	}
+	slices.SortFunc(images, func(a, b containerImage) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.WorkloadKind, b.WorkloadKind),
+			cmp.Compare(a.WorkloadName, b.WorkloadName),
+			cmp.Compare(a.Container, b.Container),
+			cmp.Compare(a.Image, b.Image),
+		)
+	})
+
+	return images
+}
+
+// imageReference holds the parts of a container image reference.
+type imageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// parseImageReference splits an image reference (e.g. "registry.rancher.com/rancher/rancher:v2.12.0@sha256:...")
+// into its registry, repository, tag and digest, applying the Docker Hub defaults for short references.
+func parseImageReference(image string) imageReference {
+	var ref imageReference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	ref.Registry = defaultRegistry
+	if i := strings.Index(name, "/"); i >= 0 {
+		if first := name[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = first
+			name = name[i+1:]
+		}
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	return ref
+}
+
+// digestFromImageID extracts the digest from a container status imageID (e.g. "docker.io/library/nginx@sha256:...").
+func digestFromImageID(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
 
+	return ""
 }
@@ -1,6 +1,11 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -9,12 +14,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
 )
 
 var fakePodWithImage = &corev1.Pod{
@@ -42,6 +51,58 @@ var fakePodWithImage = &corev1.Pod{
 	},
 }
 
+var fakeDeploymentPods = []runtime.Object{
+	&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rancher-7d9c6b5f4-abcde",
+			Namespace: "cattle-system",
+			Labels:    map[string]string{"pod-template-hash": "7d9c6b5f4"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rancher-7d9c6b5f4", Controller: ptr.To(true)},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "rancher", Image: "registry.rancher.com/rancher/rancher:v2.12.0", ImagePullPolicy: corev1.PullIfNotPresent},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "rancher", ImageID: "registry.rancher.com/rancher/rancher@sha256:1234"},
+			},
+		},
+	},
+	&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rancher-7d9c6b5f4-fghij",
+			Namespace: "cattle-system",
+			Labels:    map[string]string{"pod-template-hash": "7d9c6b5f4"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rancher-7d9c6b5f4", Controller: ptr.To(true)},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "rancher", Image: "registry.rancher.com/rancher/rancher:v2.12.0", ImagePullPolicy: corev1.PullIfNotPresent},
+			},
+		},
+	},
+	&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fluentd-xyz",
+			Namespace: "logging",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "fluentd", Controller: ptr.To(true)},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "fluentd", Image: "fluent/fluentd@sha256:abcd", ImagePullPolicy: corev1.PullAlways},
+			},
+		},
+	},
+}
+
 func podScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -51,6 +112,9 @@ func podScheme() *runtime.Scheme {
 func TestGetClusterImages(t *testing.T) {
 	fakeUrl := "https://localhost:8080"
 	fakeToken := "fakeToken"
+	podListKinds := map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "pods"}: "PodList",
+	}
 
 	tests := map[string]struct {
 		params         getClusterImagesParams
@@ -59,23 +123,79 @@ func TestGetClusterImages(t *testing.T) {
 		expectedError  string
 	}{
 		"get images from single cluster": {
-			params: getClusterImagesParams{Clusters: []string{"local"}},
-			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), map[schema.GroupVersionResource]string{
-				{Group: "", Version: "v1", Resource: "pods"}: "PodList",
-			}, fakePodWithImage),
+			params:        getClusterImagesParams{Clusters: []string{"local"}},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds, fakePodWithImage),
 			expectedResult: `{
-				"local": ["busybox:latest", "nginx:1.21", "redis:alpine"]
+				"local": [
+					{"image": "nginx:1.21", "registry": "docker.io", "repository": "library/nginx", "tag": "1.21", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "app-container", "pods": 1},
+					{"image": "busybox:latest", "registry": "docker.io", "repository": "library/busybox", "tag": "latest", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "init-container", "pods": 1},
+					{"image": "redis:alpine", "registry": "docker.io", "repository": "library/redis", "tag": "alpine", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "sidecar-container", "pods": 1}
+				]
 			}`,
 		},
 		"get images from cluster with no pods": {
-			params: getClusterImagesParams{Clusters: []string{"local"}},
-			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), map[schema.GroupVersionResource]string{
-				{Group: "", Version: "v1", Resource: "pods"}: "PodList",
-			}),
+			params:        getClusterImagesParams{Clusters: []string{"local"}},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds),
 			expectedResult: `{
 				"local": []
 			}`,
 		},
+		"get images attributed to workloads": {
+			params:        getClusterImagesParams{Clusters: []string{"local"}},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds, fakeDeploymentPods...),
+			expectedResult: `{
+				"local": [
+					{"image": "registry.rancher.com/rancher/rancher:v2.12.0", "registry": "registry.rancher.com", "repository": "rancher/rancher", "tag": "v2.12.0", "digest": "sha256:1234", "namespace": "cattle-system", "workloadKind": "Deployment", "workloadName": "rancher", "container": "rancher", "pullPolicy": "IfNotPresent", "pods": 2},
+					{"image": "fluent/fluentd@sha256:abcd", "registry": "docker.io", "repository": "fluent/fluentd", "digest": "sha256:abcd", "namespace": "logging", "workloadKind": "DaemonSet", "workloadName": "fluentd", "container": "fluentd", "pullPolicy": "Always", "pods": 1}
+				]
+			}`,
+		},
+		"filter by registry": {
+			params:        getClusterImagesParams{Clusters: []string{"local"}, Registry: "registry.rancher.com"},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds, append(fakeDeploymentPods, fakePodWithImage)...),
+			expectedResult: `{
+				"local": [
+					{"image": "registry.rancher.com/rancher/rancher:v2.12.0", "registry": "registry.rancher.com", "repository": "rancher/rancher", "tag": "v2.12.0", "digest": "sha256:1234", "namespace": "cattle-system", "workloadKind": "Deployment", "workloadName": "rancher", "container": "rancher", "pullPolicy": "IfNotPresent", "pods": 2}
+				]
+			}`,
+		},
+		"filter by namespace": {
+			params:        getClusterImagesParams{Clusters: []string{"local"}, Namespace: "logging"},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds, append(fakeDeploymentPods, fakePodWithImage)...),
+			expectedResult: `{
+				"local": [
+					{"image": "fluent/fluentd@sha256:abcd", "registry": "docker.io", "repository": "fluent/fluentd", "digest": "sha256:abcd", "namespace": "logging", "workloadKind": "DaemonSet", "workloadName": "fluentd", "container": "fluentd", "pullPolicy": "Always", "pods": 1}
+				]
+			}`,
+		},
+		"filter by tag pattern": {
+			params:        getClusterImagesParams{Clusters: []string{"local"}, TagPattern: "^(latest|alpine)$"},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds, fakePodWithImage),
+			expectedResult: `{
+				"local": [
+					{"image": "busybox:latest", "registry": "docker.io", "repository": "library/busybox", "tag": "latest", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "init-container", "pods": 1},
+					{"image": "redis:alpine", "registry": "docker.io", "repository": "library/redis", "tag": "alpine", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "sidecar-container", "pods": 1}
+				]
+			}`,
+		},
+		"cluster that can't be queried is returned with its error": {
+			params: getClusterImagesParams{Clusters: []string{"local"}},
+			fakeDynClient: func() *dynamicfake.FakeDynamicClient {
+				fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds)
+				fakeDynClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("access denied"))
+				})
+				return fakeDynClient
+			}(),
+			expectedResult: `{
+				"local": {"error": "failed to get pods: pods is forbidden: access denied"}
+			}`,
+		},
+		"invalid tag pattern": {
+			params:        getClusterImagesParams{Clusters: []string{"local"}, TagPattern: "("},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), podListKinds),
+			expectedError: `invalid tagPattern "("`,
+		},
 	}
 
 	for name, test := range tests {
@@ -100,3 +220,128 @@ func TestGetClusterImages(t *testing.T) {
 		})
 	}
 }
+
+// pagedPodsClient returns the pods of a cluster in pages of one pod, and records the continue tokens of the requests.
+type pagedPodsClient struct {
+	*fakeToolsClient
+	pods           []runtime.Object
+	continueTokens []string
+}
+
+func (c *pagedPodsClient) GetResourcesPage(_ context.Context, params client.ListParams) ([]*unstructured.Unstructured, string, error) {
+	if params.Limit != podsPageSize {
+		return nil, "", fmt.Errorf("unexpected limit %d", params.Limit)
+	}
+	c.continueTokens = append(c.continueTokens, params.Continue)
+	page, _ := strconv.Atoi(params.Continue)
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(c.pods[page])
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if page+1 < len(c.pods) {
+		next = strconv.Itoa(page + 1)
+	}
+
+	return []*unstructured.Unstructured{{Object: obj}}, next, nil
+}
+
+func TestGetClusterImagesPagination(t *testing.T) {
+	pagedClient := &pagedPodsClient{fakeToolsClient: newFakeToolsClient(&client.Client{}, ""), pods: fakeDeploymentPods}
+	tools := Tools{client: pagedClient}
+
+	result, _, err := tools.getClusterImages(t.Context(), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{}},
+	}, getClusterImagesParams{Clusters: []string{"local"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "1", "2"}, pagedClient.continueTokens)
+	assert.JSONEq(t, `{
+		"local": [
+			{"image": "registry.rancher.com/rancher/rancher:v2.12.0", "registry": "registry.rancher.com", "repository": "rancher/rancher", "tag": "v2.12.0", "digest": "sha256:1234", "namespace": "cattle-system", "workloadKind": "Deployment", "workloadName": "rancher", "container": "rancher", "pullPolicy": "IfNotPresent", "pods": 2},
+			{"image": "fluent/fluentd@sha256:abcd", "registry": "docker.io", "repository": "fluent/fluentd", "digest": "sha256:abcd", "namespace": "logging", "workloadKind": "DaemonSet", "workloadName": "fluentd", "container": "fluentd", "pullPolicy": "Always", "pods": 1}
+		]
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestGetDistinctClusterImages(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	managementCluster := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "Cluster",
+			"metadata":   map[string]any{"name": name},
+		}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "pods"}:                         "PodList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}: "ClusterList",
+	}
+	localPods := []runtime.Object{managementCluster("local"), managementCluster("c-abc12"), managementCluster("c-def34"), fakePodWithImage}
+	dynClients := map[string]*dynamicfake.FakeDynamicClient{
+		"/k8s/clusters/local":   dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), listKinds, localPods...),
+		"/k8s/clusters/c-abc12": dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), listKinds, append(fakeDeploymentPods, fakePodWithImage)...),
+		"/k8s/clusters/c-def34": func() *dynamicfake.FakeDynamicClient {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), listKinds)
+			fakeDynClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("access denied"))
+			})
+			return fakeDynClient
+		}(),
+	}
+	c := &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return dynClients[strings.TrimPrefix(inConfig.Host, fakeUrl)], nil
+		},
+	}
+	tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+	result, _, err := tools.getClusterImages(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+	}, getClusterImagesParams{Distinct: true, TagPattern: "^(1.21|alpine|v2.12.0)$"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"images": [
+			{"image": "nginx:1.21", "registry": "docker.io", "repository": "library/nginx", "tag": "1.21", "clusters": ["c-abc12", "local"], "workloads": 2, "pods": 2},
+			{"image": "redis:alpine", "registry": "docker.io", "repository": "library/redis", "tag": "alpine", "clusters": ["c-abc12", "local"], "workloads": 2, "pods": 2},
+			{"image": "registry.rancher.com/rancher/rancher:v2.12.0", "registry": "registry.rancher.com", "repository": "rancher/rancher", "tag": "v2.12.0", "digest": "sha256:1234", "clusters": ["c-abc12"], "workloads": 1, "pods": 2}
+		],
+		"errors": {"c-def34": "failed to get pods: pods is forbidden: access denied"}
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := map[string]struct {
+		image    string
+		expected imageReference
+	}{
+		"short name": {
+			image:    "nginx",
+			expected: imageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+		},
+		"docker hub with tag": {
+			image:    "rancher/rancher:v2.12.0",
+			expected: imageReference{Registry: "docker.io", Repository: "rancher/rancher", Tag: "v2.12.0"},
+		},
+		"custom registry with port": {
+			image:    "my-registry:5000/team/app:1.0",
+			expected: imageReference{Registry: "my-registry:5000", Repository: "team/app", Tag: "1.0"},
+		},
+		"localhost registry": {
+			image:    "localhost/app",
+			expected: imageReference{Registry: "localhost", Repository: "app", Tag: "latest"},
+		},
+		"tag and digest": {
+			image:    "quay.io/jetstack/cert-manager-controller:v1.15.0@sha256:abcd",
+			expected: imageReference{Registry: "quay.io", Repository: "jetstack/cert-manager-controller", Tag: "v1.15.0", Digest: "sha256:abcd"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseImageReference(test.image))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
@@ -20,11 +21,13 @@ type specificResourceParams struct {
 	Name      string `json:"name" jsonschema:"the name of k8s resource"`
 	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
 	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
+	// IncludeEvents embeds the events of the resource and its related resources in the response.
+	IncludeEvents bool `json:"includeEvents,omitempty" jsonschema:"include the events of the resource and its related resources"`
 }
 
 // getDeploymentDetails retrieves details about a deployment and its associated pods.
 func (t *Tools) getDeploymentDetails(ctx context.Context, toolReq *mcp.CallToolRequest, params specificResourceParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("getDeploymentDetails called")
+	middleware.Logger(ctx).Debug("getDeploymentDetails called")
 
 	deploymentResource, err := t.client.GetResource(ctx, client.GetParams{
 		Cluster:   params.Cluster,
@@ -35,20 +38,20 @@ func (t *Tools) getDeploymentDetails(ctx context.Context, toolReq *mcp.CallToolR
 		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to get deployment", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get deployment", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
 		return nil, nil, err
 	}
 
 	var deployment appsv1.Deployment
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(deploymentResource.Object, &deployment); err != nil {
-		zap.L().Error("failed convert unstructured object to Deployment", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed convert unstructured object to Deployment", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
 	}
 
 	// find all pods for this deployment
 	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
-		zap.L().Error("failed create label selector", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed create label selector", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to convert label selector: %w", err)
 	}
 	pods, err := t.client.GetResources(ctx, client.ListParams{
@@ -61,13 +64,36 @@ func (t *Tools) getDeploymentDetails(ctx context.Context, toolReq *mcp.CallToolR
 		LabelSelector: selector.String(),
 	})
 	if err != nil {
-		zap.L().Error("failed to get pods", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get pods", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to get pods: %w", err)
 	}
 
-	mcpResponse, err := response.CreateMcpResponse(append([]*unstructured.Unstructured{deploymentResource}, pods...), params.Cluster)
+	resources := append([]*unstructured.Unstructured{deploymentResource}, pods...)
+	if params.IncludeEvents {
+		replicaSets, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:       params.Cluster,
+			Kind:          "replicaset",
+			Namespace:     params.Namespace,
+			URL:           toolReq.Extra.Header.Get(urlHeader),
+			Token:         middleware.Token(ctx),
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get replicasets", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to get replicasets: %w", err)
+		}
+
+		events, err := t.fetchRelatedEvents(ctx, toolReq, params.Cluster, params.Namespace, append(slices.Clone(resources), replicaSets...))
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get events", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+			return nil, nil, err
+		}
+		resources = append(resources, events)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, params.Cluster)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getDeploymentDetails"), zap.Error(err))
 		return nil, nil, err
 	}
 
@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
@@ -142,6 +143,60 @@ func TestGetDeploymentDetails(t *testing.T) {
 				]
 			}`,
 		},
+		"get deployment with events": {
+			params: specificResourceParams{
+				Name:          "nginx-deployment",
+				Namespace:     "default",
+				Cluster:       "local",
+				IncludeEvents: true,
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(deploymentScheme(), map[schema.GroupVersionResource]string{
+				{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+				{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+				{Group: "", Version: "v1", Resource: "pods"}:            "PodList",
+				{Group: "", Version: "v1", Resource: "events"}:          "EventList",
+			}, fakeDeployment, fakeDeploymentPod,
+				fakeEvent("pod-oom", "Pod", "nginx-deployment-abc123", "OOMKilling", "Memory cgroup out of memory", 2, time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))),
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "apps/v1",
+						"kind": "Deployment",
+						"metadata": {"name": "nginx-deployment", "namespace": "default"},
+						"spec": {
+							"replicas": 2,
+							"selector": {"matchLabels": {"app": "nginx"}},
+							"strategy": {},
+							"template": {
+								"metadata": {"labels": {"app": "nginx"}},
+								"spec": {
+									"containers": [
+										{"image": "nginx:1.21", "name": "nginx", "ports": [{"containerPort": 80, "protocol": "TCP"}], "resources": {}}
+									]
+								}
+							}
+						},
+						"status": {}
+					},
+					{
+						"apiVersion": "v1",
+						"kind": "Pod",
+						"metadata": {"labels": {"app": "nginx"}, "name": "nginx-deployment-abc123", "namespace": "default"},
+						"spec": {"containers": [{"image": "nginx:1.21", "name": "nginx", "resources": {}}]},
+						"status": {"phase": "Running"}
+					},
+					{
+						"events": [
+							{"kind": "Pod", "name": "nginx-deployment-abc123", "type": "Warning", "reason": "OOMKilling", "message": "Memory cgroup out of memory", "count": 2, "firstTimestamp": "2025-10-01T11:59:00Z", "lastTimestamp": "2025-10-01T12:00:00Z"}
+						]
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Deployment", "name": "nginx-deployment", "namespace": "default", "type": "apps.deployment"},
+					{"cluster": "local", "kind": "Pod", "name": "nginx-deployment-abc123", "namespace": "default", "type": "pod"}
+				]
+			}`,
+		},
 		"get deployment - not found": {
 			params: specificResourceParams{
 				Name:      "nonexistent-deployment",
@@ -0,0 +1,310 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// defaultDigestHours is the period covered by the digest when no period is given.
+	defaultDigestHours = 24
+	// maxDigestHours is the longest period covered by the digest, events are usually only kept for an hour anyway.
+	maxDigestHours = 7 * 24
+	// defaultDigestEntries is the number of entries of the digest returned when no limit is given.
+	defaultDigestEntries = 100
+)
+
+// The severities of the entries of the digest, from the most to the least severe.
+const (
+	severityCritical = "critical"
+	severityWarning  = "warning"
+	severityInfo     = "info"
+)
+
+// criticalEventReasons are the reasons of the Warning events about nodes and the loss of workloads, reported as
+// critical instead of warning.
+var criticalEventReasons = []string{"NodeNotReady", "NodeHasDiskPressure", "NodeHasInsufficientMemory", "NodeHasInsufficientPID",
+	"SystemOOM", "OOMKilling", "Evicted", "EvictionThresholdMet", "Rebooted", "FailedAttachVolume", "ContainerGCFailed", "ImageGCFailed"}
+
+// criticalConditions are the conditions of the Rancher clusters meaning the cluster can't be used when they aren't
+// True. The transitions of the other conditions to False are reported as warnings.
+var criticalConditions = []string{"Ready", "Connected"}
+
+type getEventDigestParams struct {
+	Clusters []string `json:"clusters,omitempty" jsonschema:"the clusters of the events. Empty for all the clusters"`
+	Hours    int      `json:"hours,omitempty" jsonschema:"the number of hours covered by the digest. Defaults to 24"`
+	Limit    int      `json:"limit,omitempty" jsonschema:"the maximum number of entries returned. Defaults to 100"`
+}
+
+// eventDigest is what happened in the clusters in the period, ordered by severity then by time, most recent first.
+type eventDigest struct {
+	Since string `json:"since"`
+	// Summary is the number of entries of each severity, including the ones left out by the limit.
+	Summary   map[string]int `json:"summary"`
+	Entries   []digestEntry  `json:"entries"`
+	Truncated bool           `json:"truncated,omitempty"`
+	// Errors contains the error of each cluster that couldn't be queried.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// digestEntry is a Warning event, deduplicated like in getRelatedEvents, or the transition of a condition of a
+// Rancher cluster.
+type digestEntry struct {
+	Cluster   string `json:"cluster"`
+	Severity  string `json:"severity"`
+	Source    string `json:"source"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Count     int32  `json:"count,omitempty"`
+	Time      string `json:"time"`
+	time      time.Time
+}
+
+// getEventDigest aggregates the Warning events of the clusters and the transitions of the conditions of the Rancher
+// clusters in the last hours into a single digest, ordered by severity.
+func (t *Tools) getEventDigest(ctx context.Context, toolReq *mcp.CallToolRequest, params getEventDigestParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getEventDigest called")
+
+	hours := cmp.Or(params.Hours, defaultDigestHours)
+	if hours < 0 || hours > maxDigestHours {
+		return nil, nil, fmt.Errorf("hours must be between 1 and %d", maxDigestHours)
+	}
+	limit := cmp.Or(params.Limit, defaultDigestEntries)
+	if limit < 0 {
+		return nil, nil, fmt.Errorf("limit must be positive")
+	}
+	since := now().Add(-time.Duration(hours) * time.Hour)
+
+	clusters, err := t.clustersOrAll(ctx, toolReq, params.Clusters)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get clusters", zap.String("tool", "getEventDigest"), zap.Error(err))
+		return nil, nil, err
+	}
+	entries, err := t.conditionTransitions(ctx, toolReq, clusters, since)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster conditions", zap.String("tool", "getEventDigest"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var mu sync.Mutex
+	failedClusters := map[string]string{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentClusters)
+	for _, cluster := range clusters {
+		t.goFanOut(gCtx, g, func() error {
+			events, err := t.warningEvents(gCtx, toolReq, cluster, since)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failedClusters[cluster] = err.Error()
+				return nil
+			}
+			entries = append(entries, events...)
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		middleware.Logger(ctx).Error("failed to get events", zap.String("tool", "getEventDigest"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	digest := newEventDigest(entries, since, limit)
+	if len(failedClusters) > 0 {
+		digest.Errors = failedClusters
+	}
+
+	response, err := json.Marshal(digest)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getEventDigest"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newEventDigest sorts the entries by severity then by time, most recent first, and keeps the first limit ones.
+func newEventDigest(entries []digestEntry, since time.Time, limit int) eventDigest {
+	digest := eventDigest{
+		Since:   since.UTC().Format(time.RFC3339),
+		Summary: map[string]int{severityCritical: 0, severityWarning: 0, severityInfo: 0},
+	}
+	for _, entry := range entries {
+		digest.Summary[entry.Severity]++
+	}
+	severities := []string{severityCritical, severityWarning, severityInfo}
+	slices.SortFunc(entries, func(a, b digestEntry) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(severities, a.Severity), slices.Index(severities, b.Severity)),
+			b.time.Compare(a.time),
+			cmp.Compare(a.Cluster, b.Cluster),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Reason, b.Reason),
+		)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+		digest.Truncated = true
+	}
+	digest.Entries = append([]digestEntry{}, entries...)
+
+	return digest
+}
+
+// warningEvents returns the Warning events of all the namespaces of a cluster that occurred since the given time,
+// deduplicated, stopping after fetch.DefaultTimeout.
+func (t *Tools) warningEvents(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, since time.Time) ([]digestEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetch.DefaultTimeout)
+	defer cancel()
+
+	unstructuredEvents, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "event",
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+		FieldSelector: "type=" + corev1.EventTypeWarning,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	type eventKey struct {
+		namespace, kind, name, reason, message string
+	}
+	merged := map[eventKey]*digestEntry{}
+	for _, unstructuredEvent := range unstructuredEvents {
+		var event corev1.Event
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredEvent.Object, &event); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Event: %w", err)
+		}
+		_, last := eventTimes(event)
+		if event.Type != corev1.EventTypeWarning || last.Before(since) {
+			continue
+		}
+		count := max(event.Count, 1)
+		if event.Series != nil {
+			count = max(count, event.Series.Count)
+		}
+
+		key := eventKey{event.InvolvedObject.Namespace, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason, event.Message}
+		if m, ok := merged[key]; ok {
+			m.Count += count
+			if last.After(m.time) {
+				m.time = last
+			}
+			continue
+		}
+		severity := severityWarning
+		if slices.Contains(criticalEventReasons, event.Reason) {
+			severity = severityCritical
+		}
+		merged[key] = &digestEntry{
+			Cluster:   cluster,
+			Severity:  severity,
+			Source:    "event",
+			Kind:      event.InvolvedObject.Kind,
+			Namespace: event.InvolvedObject.Namespace,
+			Name:      event.InvolvedObject.Name,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     count,
+			time:      last,
+		}
+	}
+
+	entries := make([]digestEntry, 0, len(merged))
+	for _, m := range merged {
+		m.Time = m.time.UTC().Format(time.RFC3339)
+		entries = append(entries, *m)
+	}
+
+	return entries, nil
+}
+
+// conditionTransitions returns the conditions of the Rancher clusters that changed since the given time. The critical
+// conditions becoming True again are reported as info, so the recoveries show in the digest too.
+func (t *Tools) conditionTransitions(ctx context.Context, toolReq *mcp.CallToolRequest, clusters []string, since time.Time) ([]digestEntry, error) {
+	managementClusters, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: "local",
+		Kind:    "managementcluster",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusters: %w", err)
+	}
+
+	var entries []digestEntry
+	for _, cluster := range managementClusters {
+		if !slices.Contains(clusters, cluster.GetName()) {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(cluster.Object, "status", "conditions")
+		for _, item := range conditions {
+			condition, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			conditionType, _ := condition["type"].(string)
+			status, _ := condition["status"].(string)
+			transitionTime, _ := condition["lastTransitionTime"].(string)
+			if transitionTime == "" {
+				transitionTime, _ = condition["lastUpdateTime"].(string)
+			}
+			transitioned, err := time.Parse(time.RFC3339, transitionTime)
+			if err != nil || transitioned.Before(since) {
+				continue
+			}
+
+			severity := severityInfo
+			switch {
+			case status == "True":
+				// the conditions becoming True are only worth reporting when they recover from a failure
+				if !slices.Contains(criticalConditions, conditionType) {
+					continue
+				}
+			case slices.Contains(criticalConditions, conditionType):
+				severity = severityCritical
+			default:
+				severity = severityWarning
+			}
+			reason, _ := condition["reason"].(string)
+			message, _ := condition["message"].(string)
+			entries = append(entries, digestEntry{
+				Cluster:  cluster.GetName(),
+				Severity: severity,
+				Source:   "condition",
+				Kind:     "Cluster",
+				Name:     cluster.GetName(),
+				Reason:   conditionType + "=" + status,
+				Message:  cmp.Or(message, reason),
+				Time:     transitioned.UTC().Format(time.RFC3339),
+				time:     transitioned,
+			})
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newDigestEvent(name string, eventType string, reason string, kind string, object string, count int64, lastTimestamp string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion":     "v1",
+		"kind":           "Event",
+		"metadata":       map[string]any{"name": name, "namespace": "default"},
+		"type":           eventType,
+		"reason":         reason,
+		"message":        reason + " " + object,
+		"count":          count,
+		"lastTimestamp":  lastTimestamp,
+		"involvedObject": map[string]any{"kind": kind, "name": object, "namespace": "default"},
+	}}
+}
+
+func newDigestCluster(name string, conditions ...any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "Cluster",
+		"metadata":   map[string]any{"name": name},
+		"status":     map[string]any{"conditions": conditions},
+	}}
+}
+
+func TestGetEventDigest(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	now = func() time.Time { return time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "events"}:                       "EventList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}: "ClusterList",
+	}
+	newDynClients := func() map[string]*dynamicfake.FakeDynamicClient {
+		return map[string]*dynamicfake.FakeDynamicClient{
+			"/k8s/clusters/local": dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+				newDigestCluster("local",
+					map[string]any{"type": "Ready", "status": "True", "lastUpdateTime": "2025-09-01T10:00:00Z"}),
+				newDigestCluster("c-abc12",
+					map[string]any{"type": "Ready", "status": "True", "lastUpdateTime": "2025-10-01T09:00:00Z"},
+					map[string]any{"type": "Updated", "status": "False", "reason": "Error", "message": "failed to upgrade the agent", "lastUpdateTime": "2025-10-01T08:00:00Z"},
+					map[string]any{"type": "Provisioned", "status": "True", "lastUpdateTime": "2025-10-01T08:00:00Z"}),
+				newDigestCluster("c-def34",
+					map[string]any{"type": "Connected", "status": "False", "message": "cluster agent disconnected", "lastTransitionTime": "2025-10-01T11:00:00Z"}),
+				newDigestEvent("old", "Warning", "BackOff", "Pod", "web-1", 3, "2025-09-30T10:00:00Z"),
+				newDigestEvent("normal", "Normal", "Pulled", "Pod", "web-1", 1, "2025-10-01T11:00:00Z"),
+			),
+			"/k8s/clusters/c-abc12": dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+				newDigestEvent("backoff-1", "Warning", "BackOff", "Pod", "web-1", 3, "2025-10-01T10:00:00Z"),
+				newDigestEvent("backoff-2", "Warning", "BackOff", "Pod", "web-1", 2, "2025-10-01T11:30:00Z"),
+				newDigestEvent("oom", "Warning", "OOMKilling", "Node", "node-1", 1, "2025-10-01T07:00:00Z"),
+			),
+			"/k8s/clusters/c-def34": func() *dynamicfake.FakeDynamicClient {
+				fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+				fakeDynClient.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "events"}, "", errors.New("access denied"))
+				})
+				return fakeDynClient
+			}(),
+		}
+	}
+
+	tests := map[string]struct {
+		params         getEventDigestParams
+		expectedResult string
+		expectedError  string
+	}{
+		"all clusters": {
+			expectedResult: `{"since":"2025-09-30T12:00:00Z","summary":{"critical":2,"warning":2,"info":1},"entries":[
+				{"cluster":"c-def34","severity":"critical","source":"condition","kind":"Cluster","name":"c-def34","reason":"Connected=False","message":"cluster agent disconnected","time":"2025-10-01T11:00:00Z"},
+				{"cluster":"c-abc12","severity":"critical","source":"event","kind":"Node","namespace":"default","name":"node-1","reason":"OOMKilling","message":"OOMKilling node-1","count":1,"time":"2025-10-01T07:00:00Z"},
+				{"cluster":"c-abc12","severity":"warning","source":"event","kind":"Pod","namespace":"default","name":"web-1","reason":"BackOff","message":"BackOff web-1","count":5,"time":"2025-10-01T11:30:00Z"},
+				{"cluster":"c-abc12","severity":"warning","source":"condition","kind":"Cluster","name":"c-abc12","reason":"Updated=False","message":"failed to upgrade the agent","time":"2025-10-01T08:00:00Z"},
+				{"cluster":"c-abc12","severity":"info","source":"condition","kind":"Cluster","name":"c-abc12","reason":"Ready=True","time":"2025-10-01T09:00:00Z"}],
+				"errors":{"c-def34":"failed to get events: events is forbidden: access denied"}}`,
+		},
+		"selected cluster with a limit": {
+			params: getEventDigestParams{Clusters: []string{"c-abc12"}, Hours: 2, Limit: 1},
+			expectedResult: `{"since":"2025-10-01T10:00:00Z","summary":{"critical":0,"warning":1,"info":0},"entries":[
+				{"cluster":"c-abc12","severity":"warning","source":"event","kind":"Pod","namespace":"default","name":"web-1","reason":"BackOff","message":"BackOff web-1","count":5,"time":"2025-10-01T11:30:00Z"}]}`,
+		},
+		"truncated": {
+			params: getEventDigestParams{Clusters: []string{"c-abc12"}, Limit: 1},
+			expectedResult: `{"since":"2025-09-30T12:00:00Z","summary":{"critical":1,"warning":2,"info":1},"entries":[
+				{"cluster":"c-abc12","severity":"critical","source":"event","kind":"Node","namespace":"default","name":"node-1","reason":"OOMKilling","message":"OOMKilling node-1","count":1,"time":"2025-10-01T07:00:00Z"}],
+				"truncated":true}`,
+		},
+		"invalid hours": {
+			params:        getEventDigestParams{Hours: 1000},
+			expectedError: "hours must be between 1 and 168",
+		},
+		"invalid limit": {
+			params:        getEventDigestParams{Limit: -1},
+			expectedError: "limit must be positive",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dynClients := newDynClients()
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return dynClients[strings.TrimPrefix(inConfig.Host, fakeUrl)], nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getEventDigest(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// severities are the vulnerability severities reported by Trivy, from highest to lowest.
+var severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}
+
+type getImageVulnerabilitiesParams struct {
+	Clusters    []string `json:"clusters" jsonschema:"the clusters where images are checked"`
+	Registry    string   `json:"registry,omitempty" jsonschema:"only check images from this registry (e.g. docker.io, registry.rancher.com)"`
+	TagPattern  string   `json:"tagPattern,omitempty" jsonschema:"regular expression, only check images whose tag matches it"`
+	MinSeverity string   `json:"minSeverity,omitempty" jsonschema:"only list vulnerabilities with this severity or higher (CRITICAL, HIGH, MEDIUM, LOW, UNKNOWN). Defaults to HIGH" validate:"enum=CRITICAL|HIGH|MEDIUM|LOW|UNKNOWN"`
+}
+
+// severityCounts holds the number of vulnerabilities of each severity.
+type severityCounts struct {
+	Critical int `json:"criticalCount"`
+	High     int `json:"highCount"`
+	Medium   int `json:"mediumCount"`
+	Low      int `json:"lowCount"`
+	Unknown  int `json:"unknownCount"`
+}
+
+// vulnerability is a known CVE affecting a package of an image.
+type vulnerability struct {
+	ID               string `json:"id"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installedVersion"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Title            string `json:"title,omitempty"`
+	Link             string `json:"link,omitempty"`
+}
+
+// imageVulnerabilities describes the known vulnerabilities of an image used by a workload.
+type imageVulnerabilities struct {
+	containerImage
+	// Scanned is false when there is no vulnerability report for the image.
+	Scanned bool           `json:"scanned"`
+	Summary severityCounts `json:"summary"`
+	// Vulnerabilities are grouped by severity.
+	Vulnerabilities map[string][]vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// vulnerabilityReport is the subset of a Trivy Operator VulnerabilityReport used to look up the CVEs of an image.
+// https://aquasecurity.github.io/trivy-operator/latest/docs/crds/vulnerability-report/
+type vulnerabilityReport struct {
+	Report struct {
+		Registry struct {
+			Server string `json:"server"`
+		} `json:"registry"`
+		Artifact struct {
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+			Digest     string `json:"digest"`
+		} `json:"artifact"`
+		Summary         severityCounts `json:"summary"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"vulnerabilityID"`
+			Resource         string `json:"resource"`
+			InstalledVersion string `json:"installedVersion"`
+			FixedVersion     string `json:"fixedVersion"`
+			Severity         string `json:"severity"`
+			Title            string `json:"title"`
+			PrimaryLink      string `json:"primaryLink"`
+		} `json:"vulnerabilities"`
+	} `json:"report"`
+}
+
+// getImageVulnerabilities cross-references the container images used across the specified clusters with the
+// vulnerability reports generated by the Trivy Operator in each cluster, and returns the known CVEs per image
+// grouped by severity. If no clusters are provided, it checks all available clusters.
+func (t *Tools) getImageVulnerabilities(ctx context.Context, toolReq *mcp.CallToolRequest, params getImageVulnerabilitiesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getImageVulnerabilities called")
+
+	minSeverity := strings.ToUpper(cmp.Or(params.MinSeverity, "HIGH"))
+	if !slices.Contains(severities, minSeverity) {
+		return nil, nil, fmt.Errorf("invalid minSeverity %q, must be one of %s", params.MinSeverity, strings.Join(severities, ", "))
+	}
+
+	imagesInClusters := map[string][]containerImage{}
+	failedClusters, err := t.collectClusterImages(ctx, toolReq, getClusterImagesParams{
+		Clusters:   params.Clusters,
+		Registry:   params.Registry,
+		TagPattern: params.TagPattern,
+	}, func(cluster string, images []containerImage) {
+		imagesInClusters[cluster] = images
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to collect images", zap.String("tool", "getImageVulnerabilities"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var mu sync.Mutex
+	vulnerabilitiesInClusters := map[string][]imageVulnerabilities{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentClusters)
+	for cluster, images := range imagesInClusters {
+		t.goFanOut(gCtx, g, func() error {
+			reports, err := t.vulnerabilityReports(gCtx, toolReq, cluster)
+			if err != nil {
+				return err
+			}
+
+			result := imagesVulnerabilities(images, reports, minSeverity)
+			mu.Lock()
+			vulnerabilitiesInClusters[cluster] = result
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		middleware.Logger(ctx).Error("failed to get vulnerability reports", zap.String("tool", "getImageVulnerabilities"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := map[string]any{}
+	for cluster, vulnerabilities := range vulnerabilitiesInClusters {
+		result[cluster] = vulnerabilities
+	}
+	for cluster, err := range failedClusters {
+		result[cluster] = map[string]string{"error": err.Error()}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getImageVulnerabilities"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// vulnerabilityReports returns the Trivy Operator vulnerability reports of all namespaces in the cluster.
+func (t *Tools) vulnerabilityReports(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string) ([]vulnerabilityReport, error) {
+	unstructuredReports, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: cluster,
+		Kind:    "vulnerabilityreport",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("no vulnerability reports found in cluster %s, make sure the Trivy Operator is installed: %w", cluster, err)
+		}
+		return nil, fmt.Errorf("failed to get vulnerability reports: %w", err)
+	}
+
+	reports := make([]vulnerabilityReport, 0, len(unstructuredReports))
+	for _, unstructuredReport := range unstructuredReports {
+		var report vulnerabilityReport
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredReport.Object, &report); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to VulnerabilityReport: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// imagesVulnerabilities matches each image with its vulnerability report, by digest when both are known and by
+// registry, repository and tag otherwise. Only vulnerabilities with minSeverity or higher are listed, while the
+// summary counts all of them. The result is sorted by the number of critical and high vulnerabilities.
+func imagesVulnerabilities(images []containerImage, reports []vulnerabilityReport, minSeverity string) []imageVulnerabilities {
+	reportsByImage := map[string]*vulnerabilityReport{}
+	for i, report := range reports {
+		registry := normalizeRegistry(report.Report.Registry.Server)
+		name := registry + "/" + report.Report.Artifact.Repository
+		if report.Report.Artifact.Digest != "" {
+			reportsByImage[name+"@"+report.Report.Artifact.Digest] = &reports[i]
+		}
+		if report.Report.Artifact.Tag != "" {
+			reportsByImage[name+":"+report.Report.Artifact.Tag] = &reports[i]
+		}
+	}
+
+	listedSeverities := severities[:slices.Index(severities, minSeverity)+1]
+	result := make([]imageVulnerabilities, 0, len(images))
+	for _, image := range images {
+		name := image.Registry + "/" + image.Repository
+		var report *vulnerabilityReport
+		ok := false
+		if image.Digest != "" {
+			report, ok = reportsByImage[name+"@"+image.Digest]
+		}
+		if !ok && image.Tag != "" {
+			report, ok = reportsByImage[name+":"+image.Tag]
+		}
+
+		vulns := imageVulnerabilities{containerImage: image, Scanned: ok}
+		if ok {
+			vulns.Summary = report.Report.Summary
+			for _, v := range report.Report.Vulnerabilities {
+				severity := strings.ToUpper(v.Severity)
+				if !slices.Contains(listedSeverities, severity) {
+					continue
+				}
+				if vulns.Vulnerabilities == nil {
+					vulns.Vulnerabilities = map[string][]vulnerability{}
+				}
+				vulns.Vulnerabilities[severity] = append(vulns.Vulnerabilities[severity], vulnerability{
+					ID:               v.VulnerabilityID,
+					Package:          v.Resource,
+					InstalledVersion: v.InstalledVersion,
+					FixedVersion:     v.FixedVersion,
+					Title:            v.Title,
+					Link:             v.PrimaryLink,
+				})
+			}
+		}
+		result = append(result, vulns)
+	}
+
+	slices.SortStableFunc(result, func(a, b imageVulnerabilities) int {
+		return cmp.Or(
+			cmp.Compare(b.Summary.Critical, a.Summary.Critical),
+			cmp.Compare(b.Summary.High, a.Summary.High),
+		)
+	})
+
+	return result
+}
+
+// normalizeRegistry maps the Docker Hub hostnames used by Trivy to the registry name used in the image inventory.
+func normalizeRegistry(registry string) string {
+	switch registry {
+	case "", "index.docker.io", "registry-1.docker.io":
+		return defaultRegistry
+	}
+
+	return registry
+}
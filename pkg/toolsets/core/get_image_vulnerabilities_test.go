@@ -0,0 +1,178 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+var fakeNginxVulnerabilityReport = &unstructured.Unstructured{
+	Object: map[string]any{
+		"apiVersion": "aquasecurity.github.io/v1alpha1",
+		"kind":       "VulnerabilityReport",
+		"metadata": map[string]any{
+			"name":      "pod-test-pod-app-container",
+			"namespace": "default",
+		},
+		"report": map[string]any{
+			"registry": map[string]any{"server": "index.docker.io"},
+			"artifact": map[string]any{"repository": "library/nginx", "tag": "1.21"},
+			"summary": map[string]any{
+				"criticalCount": int64(1),
+				"highCount":     int64(1),
+				"mediumCount":   int64(1),
+				"lowCount":      int64(0),
+				"unknownCount":  int64(0),
+			},
+			"vulnerabilities": []any{
+				map[string]any{
+					"vulnerabilityID":  "CVE-2023-0001",
+					"resource":         "openssl",
+					"installedVersion": "1.1.1k",
+					"fixedVersion":     "1.1.1t",
+					"severity":         "CRITICAL",
+					"title":            "openssl: remote code execution",
+					"primaryLink":      "https://avd.aquasec.com/nvd/cve-2023-0001",
+				},
+				map[string]any{
+					"vulnerabilityID":  "CVE-2023-0002",
+					"resource":         "curl",
+					"installedVersion": "7.74.0",
+					"severity":         "HIGH",
+				},
+				map[string]any{
+					"vulnerabilityID":  "CVE-2023-0003",
+					"resource":         "zlib",
+					"installedVersion": "1.2.11",
+					"severity":         "MEDIUM",
+				},
+			},
+		},
+	},
+}
+
+var fakeRedisVulnerabilityReport = &unstructured.Unstructured{
+	Object: map[string]any{
+		"apiVersion": "aquasecurity.github.io/v1alpha1",
+		"kind":       "VulnerabilityReport",
+		"metadata": map[string]any{
+			"name":      "pod-test-pod-sidecar-container",
+			"namespace": "default",
+		},
+		"report": map[string]any{
+			"registry": map[string]any{"server": "index.docker.io"},
+			"artifact": map[string]any{"repository": "library/redis", "tag": "alpine"},
+			"summary": map[string]any{
+				"criticalCount": int64(0),
+				"highCount":     int64(0),
+				"mediumCount":   int64(0),
+				"lowCount":      int64(1),
+				"unknownCount":  int64(0),
+			},
+			"vulnerabilities": []any{
+				map[string]any{
+					"vulnerabilityID":  "CVE-2023-0004",
+					"resource":         "busybox",
+					"installedVersion": "1.36.0",
+					"severity":         "LOW",
+				},
+			},
+		},
+	},
+}
+
+func TestGetImageVulnerabilities(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "pods"}:                                             "PodList",
+		{Group: "aquasecurity.github.io", Version: "v1alpha1", Resource: "vulnerabilityreports"}: "VulnerabilityReportList",
+	}
+
+	tests := map[string]struct {
+		params         getImageVulnerabilitiesParams
+		objects        []runtime.Object
+		expectedResult string
+		expectedError  string
+	}{
+		"default min severity": {
+			params:  getImageVulnerabilitiesParams{Clusters: []string{"local"}},
+			objects: []runtime.Object{fakePodWithImage, fakeNginxVulnerabilityReport, fakeRedisVulnerabilityReport},
+			expectedResult: `{
+				"local": [
+					{
+						"image": "nginx:1.21", "registry": "docker.io", "repository": "library/nginx", "tag": "1.21", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "app-container", "pods": 1,
+						"scanned": true,
+						"summary": {"criticalCount": 1, "highCount": 1, "mediumCount": 1, "lowCount": 0, "unknownCount": 0},
+						"vulnerabilities": {
+							"CRITICAL": [{"id": "CVE-2023-0001", "package": "openssl", "installedVersion": "1.1.1k", "fixedVersion": "1.1.1t", "title": "openssl: remote code execution", "link": "https://avd.aquasec.com/nvd/cve-2023-0001"}],
+							"HIGH": [{"id": "CVE-2023-0002", "package": "curl", "installedVersion": "7.74.0"}]
+						}
+					},
+					{
+						"image": "busybox:latest", "registry": "docker.io", "repository": "library/busybox", "tag": "latest", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "init-container", "pods": 1,
+						"scanned": false,
+						"summary": {"criticalCount": 0, "highCount": 0, "mediumCount": 0, "lowCount": 0, "unknownCount": 0}
+					},
+					{
+						"image": "redis:alpine", "registry": "docker.io", "repository": "library/redis", "tag": "alpine", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "sidecar-container", "pods": 1,
+						"scanned": true,
+						"summary": {"criticalCount": 0, "highCount": 0, "mediumCount": 0, "lowCount": 1, "unknownCount": 0}
+					}
+				]
+			}`,
+		},
+		"critical only": {
+			params:  getImageVulnerabilitiesParams{Clusters: []string{"local"}, TagPattern: "^1\\.21$", MinSeverity: "critical"},
+			objects: []runtime.Object{fakePodWithImage, fakeNginxVulnerabilityReport},
+			expectedResult: `{
+				"local": [
+					{
+						"image": "nginx:1.21", "registry": "docker.io", "repository": "library/nginx", "tag": "1.21", "namespace": "default", "workloadKind": "Pod", "workloadName": "test-pod", "container": "app-container", "pods": 1,
+						"scanned": true,
+						"summary": {"criticalCount": 1, "highCount": 1, "mediumCount": 1, "lowCount": 0, "unknownCount": 0},
+						"vulnerabilities": {
+							"CRITICAL": [{"id": "CVE-2023-0001", "package": "openssl", "installedVersion": "1.1.1k", "fixedVersion": "1.1.1t", "title": "openssl: remote code execution", "link": "https://avd.aquasec.com/nvd/cve-2023-0001"}]
+						}
+					}
+				]
+			}`,
+		},
+		"invalid min severity": {
+			params:        getImageVulnerabilitiesParams{Clusters: []string{"local"}, MinSeverity: "severe"},
+			expectedError: `invalid minSeverity "severe"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(podScheme(), listKinds, test.objects...), nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getImageVulnerabilities(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
@@ -17,7 +17,7 @@ type getNodesParams struct {
 
 // getNodes retrieves information and metrics for all nodes in a given cluster.
 func (t *Tools) getNodes(ctx context.Context, toolReq *mcp.CallToolRequest, params getNodesParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("getNodes called")
+	middleware.Logger(ctx).Debug("getNodes called")
 
 	nodeResource, err := t.client.GetResources(ctx, client.ListParams{
 		Cluster: params.Cluster,
@@ -26,7 +26,7 @@ func (t *Tools) getNodes(ctx context.Context, toolReq *mcp.CallToolRequest, para
 		Token:   middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to get nodes", zap.String("tool", "getNodes"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get nodes", zap.String("tool", "getNodes"), zap.Error(err))
 		return nil, nil, err
 	}
 
@@ -38,9 +38,9 @@ func (t *Tools) getNodes(ctx context.Context, toolReq *mcp.CallToolRequest, para
 		Token:   middleware.Token(ctx),
 	})
 
-	mcpResponse, err := response.CreateMcpResponse(append(nodeResource, nodeMetricsResource...), params.Cluster)
+	mcpResponse, err := response.CreateMcpResponse(ctx, append(nodeResource, nodeMetricsResource...), params.Cluster)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "getNodes"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getNodes"), zap.Error(err))
 		return nil, nil, err
 	}
 
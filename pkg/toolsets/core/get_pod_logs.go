@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	podLogsTailLines int64 = 50
+	// podLogsMaxScanLines is the number of lines fetched from the API server when a filter
+	// is applied, so matches can be found beyond the last lines returned to the LLM.
+	podLogsMaxScanLines int64 = 5000
+)
+
+// containerLogs holds logs for multiple containers.
+type containerLogs struct {
+	Logs map[string]any `json:"logs"`
+}
+
+// getPodLogsParams specifies the parameters needed to retrieve the logs of a pod.
+type getPodLogsParams struct {
+	Name         string `json:"name" jsonschema:"the name of the pod"`
+	Namespace    string `json:"namespace" jsonschema:"the namespace of the pod"`
+	Cluster      string `json:"cluster" jsonschema:"the cluster of the pod"`
+	Container    string `json:"container,omitempty" jsonschema:"the container to get logs from. Empty for all containers"`
+	SinceSeconds int64  `json:"sinceSeconds,omitempty" jsonschema:"only return logs newer than this number of seconds"`
+	SinceTime    string `json:"sinceTime,omitempty" jsonschema:"only return logs after this RFC3339 timestamp"`
+	TailLines    int64  `json:"tailLines,omitempty" jsonschema:"number of lines to return from the end of the logs"`
+	Previous     bool   `json:"previous,omitempty" jsonschema:"return the logs of the previous terminated container"`
+	Filter       string `json:"filter,omitempty" jsonschema:"regular expression, only matching log lines are returned"`
+}
+
+// getPodLogs retrieves the logs of a pod applying the requested options and filters.
+func (t *Tools) getPodLogs(ctx context.Context, toolReq *mcp.CallToolRequest, params getPodLogsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getPodLogs called")
+
+	podResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      "pod",
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Pod", zap.String("tool", "getPodLogs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
+		middleware.Logger(ctx).Error("failed to convert unstructured object to Pod", zap.String("tool", "getPodLogs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+	}
+
+	logs, err := t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), params.Cluster, middleware.Token(ctx), pod, params)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get pod logs", zap.String("tool", "getPodLogs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{logs}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getPodLogs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// fetchPodLogs retrieves the logs for the containers in a pod.
+// It returns the logs as an unstructured object with container names as keys.
+// Only the last 50 lines of logs are returned per container unless tailLines is set, to limit payload size.
+// When a filter is provided it is applied before the tail, so that only matching lines count towards the limit.
+func (t *Tools) fetchPodLogs(ctx context.Context, url string, cluster string, token string, pod corev1.Pod, params getPodLogsParams) (*unstructured.Unstructured, error) {
+	var filter *regexp.Regexp
+	if params.Filter != "" {
+		var err error
+		filter, err = regexp.Compile(params.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", params.Filter, err)
+		}
+	}
+
+	var containers []string
+	if params.Container != "" {
+		if !slices.ContainsFunc(append(pod.Spec.InitContainers, pod.Spec.Containers...), func(c corev1.Container) bool {
+			return c.Name == params.Container
+		}) && !slices.ContainsFunc(pod.Spec.EphemeralContainers, func(c corev1.EphemeralContainer) bool {
+			return c.Name == params.Container
+		}) {
+			return nil, fmt.Errorf("container %s not found in pod %s", params.Container, pod.Name)
+		}
+		containers = []string{params.Container}
+	} else {
+		containers = logContainers(pod)
+	}
+
+	tailLines := podLogsTailLines
+	if params.TailLines > 0 {
+		tailLines = params.TailLines
+	}
+	podLogOptions := corev1.PodLogOptions{
+		TailLines: ptr.To(tailLines),
+		Previous:  params.Previous,
+	}
+	if filter != nil {
+		podLogOptions.TailLines = ptr.To(max(tailLines, podLogsMaxScanLines))
+	}
+	if params.SinceSeconds > 0 {
+		podLogOptions.SinceSeconds = ptr.To(params.SinceSeconds)
+	} else if params.SinceTime != "" {
+		sinceTime, err := time.Parse(time.RFC3339, params.SinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime %q: %w", params.SinceTime, err)
+		}
+		podLogOptions.SinceTime = ptr.To(metav1.NewTime(sinceTime))
+	}
+
+	clientset, err := t.client.CreateClientSet(ctx, token, url, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	logs := containerLogs{
+		Logs: make(map[string]any),
+	}
+	for _, container := range containers {
+		podLogOptions.Container = container
+		req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOptions)
+		podLogs, err := req.Stream(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log stream: %v", err)
+		}
+		lines, err := readLogLines(podLogs, filter, int(tailLines))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log stream: %v", err)
+		}
+		logs.Logs[container] = strings.Join(lines, "\n")
+		if err := podLogs.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close pod logs stream: %v", err)
+		}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{"pod-logs": logs.Logs}}, nil
+}
+
+// logContainers returns the containers of the Pod that have logs: the init containers that started, the containers and
+// the ephemeral containers that started. The init containers and the ephemeral containers that haven't started yet
+// have no logs to read.
+func logContainers(pod corev1.Pod) []string {
+	started := map[string]bool{}
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.EphemeralContainerStatuses) {
+		started[status.Name] = status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil
+	}
+
+	var containers []string
+	for _, container := range pod.Spec.InitContainers {
+		if started[container.Name] {
+			containers = append(containers, container.Name)
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if started[container.Name] {
+			containers = append(containers, container.Name)
+		}
+	}
+
+	return containers
+}
+
+// readLogLines reads the log stream line by line, keeping only the lines that match the filter.
+// At most the last maxLines matching lines are returned.
+func readLogLines(r io.Reader, filter *regexp.Regexp, maxLines int) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if filter != nil && !filter.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) > maxLines {
+			lines = lines[1:]
+		}
+	}
+
+	return lines, scanner.Err()
+}
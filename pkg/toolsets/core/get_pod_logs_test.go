@@ -0,0 +1,130 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetPodLogs(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params         getPodLogsParams
+		expectedError  string
+		expectedResult string
+	}{
+		"all containers": {
+			params:         getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local"},
+			expectedResult: `{"llm":[{"pod-logs":{"nginx":"fake logs","sidecar":"fake logs"}}]}`,
+		},
+		"single container": {
+			params:         getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", Container: "sidecar", TailLines: 10, Previous: true},
+			expectedResult: `{"llm":[{"pod-logs":{"sidecar":"fake logs"}}]}`,
+		},
+		"filter matches": {
+			params:         getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", Container: "nginx", Filter: "fake"},
+			expectedResult: `{"llm":[{"pod-logs":{"nginx":"fake logs"}}]}`,
+		},
+		"filter does not match": {
+			params:         getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", Container: "nginx", Filter: "error"},
+			expectedResult: `{"llm":[{"pod-logs":{"nginx":""}}]}`,
+		},
+		"since time": {
+			params:         getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", Container: "nginx", SinceTime: "2025-01-01T00:00:00Z"},
+			expectedResult: `{"llm":[{"pod-logs":{"nginx":"fake logs"}}]}`,
+		},
+		"invalid since time": {
+			params:        getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", SinceTime: "yesterday"},
+			expectedError: `invalid sinceTime "yesterday"`,
+		},
+		"invalid filter": {
+			params:        getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", Filter: "("},
+			expectedError: `invalid filter "("`,
+		},
+		"unknown container": {
+			params:        getPodLogsParams{Name: "nginx-pod-abc123", Namespace: "default", Cluster: "local", Container: "missing"},
+			expectedError: "container missing not found in pod nginx-pod-abc123",
+		},
+		"pod not found": {
+			params:        getPodLogsParams{Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedError: `pods "missing" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return fake.NewSimpleClientset(fakePodForInspect), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return dynamicfake.NewSimpleDynamicClient(inspectPodScheme(), fakePodForInspect), nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getPodLogs(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
+
+func TestReadLogLines(t *testing.T) {
+	logs := "info: starting\nerror: failed to connect\ninfo: retrying\nerror: timeout\ninfo: done"
+
+	tests := map[string]struct {
+		filter   *regexp.Regexp
+		maxLines int
+		expected []string
+	}{
+		"no filter": {
+			maxLines: 10,
+			expected: []string{"info: starting", "error: failed to connect", "info: retrying", "error: timeout", "info: done"},
+		},
+		"no filter with tail": {
+			maxLines: 2,
+			expected: []string{"error: timeout", "info: done"},
+		},
+		"filter": {
+			filter:   regexp.MustCompile("^error"),
+			maxLines: 10,
+			expected: []string{"error: failed to connect", "error: timeout"},
+		},
+		"filter with tail": {
+			filter:   regexp.MustCompile("^error"),
+			maxLines: 1,
+			expected: []string{"error: timeout"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lines, err := readLogLines(strings.NewReader(logs), test.filter, test.maxLines)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, lines)
+		})
+	}
+}
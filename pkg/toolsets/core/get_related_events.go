@@ -0,0 +1,254 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maxOwnerChainDepth limits the number of owners followed from a resource (e.g. Pod → ReplicaSet → Deployment).
+const maxOwnerChainDepth = 5
+
+type getRelatedEventsParams struct {
+	Name      string `json:"name" jsonschema:"the name of k8s resource"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
+	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
+}
+
+// relatedEvent is a deduplicated Kubernetes Event. Events with the same involved object, type, reason and
+// message are merged, adding up their counts.
+type relatedEvent struct {
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message"`
+	Count          int32  `json:"count"`
+	FirstTimestamp string `json:"firstTimestamp,omitempty"`
+	LastTimestamp  string `json:"lastTimestamp,omitempty"`
+}
+
+// getRelatedEvents returns the events of a resource and of all resources in its owner chain, sorted by time.
+func (t *Tools) getRelatedEvents(ctx context.Context, toolReq *mcp.CallToolRequest, params getRelatedEventsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getRelatedEvents called")
+
+	resource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      strings.ToLower(params.Kind),
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource", zap.String("tool", "getRelatedEvents"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	chain, err := t.ownerChain(ctx, toolReq, params.Cluster, resource)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get owners", zap.String("tool", "getRelatedEvents"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	events, err := t.fetchRelatedEvents(ctx, toolReq, params.Cluster, params.Namespace, chain)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get events", zap.String("tool", "getRelatedEvents"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{events}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getRelatedEvents"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// ownerChain returns the resource followed by its controllers, walking the owner references up to maxOwnerChainDepth.
+// Owners of unknown kinds or that no longer exist end the chain.
+func (t *Tools) ownerChain(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, resource *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	chain := []*unstructured.Unstructured{resource}
+	current := resource
+	for range maxOwnerChainDepth {
+		var ownerKind, ownerName string
+		for _, or := range current.GetOwnerReferences() {
+			if or.Controller != nil && *or.Controller {
+				ownerKind, ownerName = strings.ToLower(or.Kind), or.Name
+				break
+			}
+		}
+		if ownerKind == "" {
+			break
+		}
+		if _, ok := converter.K8sKindsToGVRs[ownerKind]; !ok {
+			break
+		}
+
+		owner, err := t.client.GetResource(ctx, client.GetParams{
+			Cluster:   cluster,
+			Kind:      ownerKind,
+			Namespace: current.GetNamespace(),
+			Name:      ownerName,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		if apierrors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, owner)
+		current = owner
+	}
+
+	return chain, nil
+}
+
+// fetchRelatedEvents returns the deduplicated events of the resources in the namespace, sorted by their last occurrence.
+func (t *Tools) fetchRelatedEvents(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string, resources []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	type involvedObject struct {
+		kind, name string
+	}
+	involved := map[involvedObject]bool{}
+	for _, resource := range resources {
+		involved[involvedObject{resource.GetKind(), resource.GetName()}] = true
+	}
+
+	unstructuredEvents, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   cluster,
+		Kind:      "event",
+		Namespace: namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	var events []corev1.Event
+	for _, unstructuredEvent := range unstructuredEvents {
+		var event corev1.Event
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredEvent.Object, &event); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Event: %w", err)
+		}
+		if involved[involvedObject{event.InvolvedObject.Kind, event.InvolvedObject.Name}] {
+			events = append(events, event)
+		}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{"events": deduplicateEvents(events)}}, nil
+}
+
+// deduplicateEvents merges events with the same involved object, type, reason and message, and sorts them by
+// their last occurrence.
+func deduplicateEvents(events []corev1.Event) []relatedEvent {
+	type eventKey struct {
+		kind, name, eventType, reason, message string
+	}
+	type mergedEvent struct {
+		relatedEvent
+		first, last time.Time
+	}
+	merged := map[eventKey]*mergedEvent{}
+
+	for _, event := range events {
+		first, last := eventTimes(event)
+		count := max(event.Count, 1)
+		if event.Series != nil {
+			count = max(count, event.Series.Count)
+		}
+
+		key := eventKey{event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Type, event.Reason, event.Message}
+		if m, ok := merged[key]; ok {
+			m.Count += count
+			if first.Before(m.first) {
+				m.first = first
+			}
+			if last.After(m.last) {
+				m.last = last
+			}
+			continue
+		}
+		merged[key] = &mergedEvent{
+			relatedEvent: relatedEvent{
+				Kind:    event.InvolvedObject.Kind,
+				Name:    event.InvolvedObject.Name,
+				Type:    event.Type,
+				Reason:  event.Reason,
+				Message: event.Message,
+				Count:   count,
+			},
+			first: first,
+			last:  last,
+		}
+	}
+
+	mergedEvents := make([]*mergedEvent, 0, len(merged))
+	for _, m := range merged {
+		mergedEvents = append(mergedEvents, m)
+	}
+	slices.SortFunc(mergedEvents, func(a, b *mergedEvent) int {
+		return cmp.Or(
+			a.last.Compare(b.last),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Reason, b.Reason),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+
+	result := make([]relatedEvent, 0, len(mergedEvents))
+	for _, m := range mergedEvents {
+		if !m.first.IsZero() {
+			m.FirstTimestamp = m.first.UTC().Format(time.RFC3339)
+		}
+		if !m.last.IsZero() {
+			m.LastTimestamp = m.last.UTC().Format(time.RFC3339)
+		}
+		result = append(result, m.relatedEvent)
+	}
+
+	return result
+}
+
+// eventTimes returns when the event was first and last observed, falling back to the newer events.k8s.io fields
+// and to the creation time when the legacy timestamps aren't set.
+func eventTimes(event corev1.Event) (time.Time, time.Time) {
+	first := event.FirstTimestamp.Time
+	if first.IsZero() {
+		first = event.EventTime.Time
+	}
+	if first.IsZero() {
+		first = event.CreationTimestamp.Time
+	}
+
+	last := event.LastTimestamp.Time
+	if last.IsZero() && event.Series != nil {
+		last = event.Series.LastObservedTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+
+	return first, last
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func fakeEvent(name string, kind string, objectName string, reason string, message string, count int32, lastTimestamp time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      kind,
+			Name:      objectName,
+			Namespace: "default",
+		},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(lastTimestamp.Add(-time.Minute)),
+		LastTimestamp:  metav1.NewTime(lastTimestamp),
+	}
+}
+
+func TestGetRelatedEvents(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	fakeEvents := []*corev1.Event{
+		fakeEvent("pod-backoff-1", "Pod", "nginx-pod-abc123", "BackOff", "Back-off pulling image \"nginx:1.21\"", 3, now.Add(-2*time.Minute)),
+		fakeEvent("pod-backoff-2", "Pod", "nginx-pod-abc123", "BackOff", "Back-off pulling image \"nginx:1.21\"", 2, now),
+		fakeEvent("rs-failed-create", "ReplicaSet", "nginx-replicaset", "FailedCreate", "exceeded quota", 1, now.Add(-5*time.Minute)),
+		fakeEvent("deployment-scaled", "Deployment", "nginx-deployment", "ScalingReplicaSet", "Scaled up replica set nginx-replicaset to 1", 0, now.Add(-10*time.Minute)),
+		fakeEvent("other-pod", "Pod", "other-pod", "OOMKilling", "Memory cgroup out of memory", 1, now),
+	}
+
+	tests := map[string]struct {
+		params         getRelatedEventsParams
+		expectedResult string
+		expectedError  string
+	}{
+		"pod and its owners": {
+			params: getRelatedEventsParams{Name: "nginx-pod-abc123", Namespace: "default", Kind: "Pod", Cluster: "local"},
+			expectedResult: `{
+				"llm": [
+					{
+						"events": [
+							{"kind": "Deployment", "name": "nginx-deployment", "type": "Warning", "reason": "ScalingReplicaSet", "message": "Scaled up replica set nginx-replicaset to 1", "count": 1, "firstTimestamp": "2025-10-01T11:49:00Z", "lastTimestamp": "2025-10-01T11:50:00Z"},
+							{"kind": "ReplicaSet", "name": "nginx-replicaset", "type": "Warning", "reason": "FailedCreate", "message": "exceeded quota", "count": 1, "firstTimestamp": "2025-10-01T11:54:00Z", "lastTimestamp": "2025-10-01T11:55:00Z"},
+							{"kind": "Pod", "name": "nginx-pod-abc123", "type": "Warning", "reason": "BackOff", "message": "Back-off pulling image \"nginx:1.21\"", "count": 5, "firstTimestamp": "2025-10-01T11:57:00Z", "lastTimestamp": "2025-10-01T12:00:00Z"}
+						]
+					}
+				]
+			}`,
+		},
+		"resource without owners": {
+			params: getRelatedEventsParams{Name: "nginx-deployment", Namespace: "default", Kind: "Deployment", Cluster: "local"},
+			expectedResult: `{
+				"llm": [
+					{
+						"events": [
+							{"kind": "Deployment", "name": "nginx-deployment", "type": "Warning", "reason": "ScalingReplicaSet", "message": "Scaled up replica set nginx-replicaset to 1", "count": 1, "firstTimestamp": "2025-10-01T11:49:00Z", "lastTimestamp": "2025-10-01T11:50:00Z"}
+						]
+					}
+				]
+			}`,
+		},
+		"resource not found": {
+			params:        getRelatedEventsParams{Name: "missing-pod", Namespace: "default", Kind: "Pod", Cluster: "local"},
+			expectedError: `pods "missing-pod" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(inspectPodScheme(), fakePodForInspect, fakeReplicaSet, fakeDeploymentForInspect,
+				fakeEvents[0], fakeEvents[1], fakeEvents[2], fakeEvents[3], fakeEvents[4])
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getRelatedEvents(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
@@ -14,14 +14,14 @@ import (
 // resourceParams uniquely identifies a specific named resource within a cluster.
 type resourceParams struct {
 	Name      string `json:"name" jsonschema:"the name of k8s resource"`
-	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the resource, empty for the cluster-scoped resources"`
 	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
 	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
 }
 
 // getResource retrieves a specific Kubernetes resource based on the provided parameters.
 func (t *Tools) getResource(ctx context.Context, toolReq *mcp.CallToolRequest, params resourceParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("getKubernetesResource called")
+	middleware.Logger(ctx).Debug("getKubernetesResource called")
 
 	resource, err := t.client.GetResource(ctx, client.GetParams{
 		Cluster:   params.Cluster,
@@ -32,13 +32,13 @@ func (t *Tools) getResource(ctx context.Context, toolReq *mcp.CallToolRequest, p
 		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to get resource", zap.String("tool", "getKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get resource", zap.String("tool", "getKubernetesResource"), zap.Error(err))
 		return nil, nil, err
 	}
 
-	mcpResponse, err := response.CreateMcpResponse([]*unstructured.Unstructured{resource}, params.Cluster)
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{resource}, params.Cluster)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "listKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listKubernetesResource"), zap.Error(err))
 		return nil, nil, err
 	}
 
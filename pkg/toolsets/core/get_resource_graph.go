@@ -0,0 +1,322 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// defaultGraphDepth is the number of edges followed from the resource when depth isn't set.
+	defaultGraphDepth = 2
+	// maxGraphDepth is the largest depth accepted by getResourceGraph.
+	maxGraphDepth = 5
+)
+
+// The relations between the resources of a graph.
+const (
+	relationOwns       = "owns"
+	relationSelects    = "selects"
+	relationRoutesTo   = "routesTo"
+	relationMounts     = "mounts"
+	relationReferences = "references"
+	relationRunsAs     = "runsAs"
+	relationBoundTo    = "boundTo"
+)
+
+// graphKinds are the kinds listed in the namespace of the resource to find its relations.
+var graphKinds = []string{"pod", "replicaset", "deployment", "statefulset", "daemonset", "job", "cronjob", "service", "ingress", "persistentvolumeclaim"}
+
+type getResourceGraphParams struct {
+	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
+	Name      string `json:"name" jsonschema:"the name of the resource"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
+	Depth     int    `json:"depth,omitempty" jsonschema:"the number of edges followed from the resource, 2 by default and 5 at most"`
+}
+
+// graphEdge is a relation from a resource to another one, identified as Kind/name.
+type graphEdge struct {
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// resourceGraph is the adjacency list of the resources related to a resource. Edges point from the owner, selector or
+// referrer to the resource it owns, selects or references, so the resources reaching a node depend on it.
+type resourceGraph struct {
+	Root      string                 `json:"root"`
+	Namespace string                 `json:"namespace"`
+	Nodes     []string               `json:"nodes"`
+	Edges     map[string][]graphEdge `json:"edges"`
+}
+
+// getResourceGraph walks the owner references, the Service selectors, the Ingress backends and the volumes and
+// references of the Pods, up and down from a resource, and returns the graph of the resources reached.
+func (t *Tools) getResourceGraph(ctx context.Context, toolReq *mcp.CallToolRequest, params getResourceGraphParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getResourceGraph called")
+
+	resource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      strings.ToLower(params.Kind),
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource", zap.String("tool", "getResourceGraph"), zap.Error(err))
+		return nil, nil, err
+	}
+	depth := defaultGraphDepth
+	if params.Depth > 0 {
+		depth = min(params.Depth, maxGraphDepth)
+	}
+
+	var resources []*unstructured.Unstructured
+	for _, kind := range graphKinds {
+		list, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:   params.Cluster,
+			Kind:      kind,
+			Namespace: params.Namespace,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get resources", zap.String("tool", "getResourceGraph"), zap.String("kind", kind), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to get %s resources: %w", kind, err)
+		}
+		resources = append(resources, list...)
+	}
+
+	edges, err := resourceEdges(resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	graph := walkGraph(nodeID(resource.GetKind(), resource.GetName()), edges, depth)
+	graph.Namespace = params.Namespace
+
+	response, err := json.Marshal(graph)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getResourceGraph"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+func nodeID(kind string, name string) string {
+	return kind + "/" + name
+}
+
+// resourceEdges returns the edges between the resources, by the node ID of their source. The ConfigMaps, Secrets,
+// ServiceAccounts and PersistentVolumes referenced are added as nodes without being read.
+func resourceEdges(resources []*unstructured.Unstructured) (map[string][]graphEdge, error) {
+	edges := map[string][]graphEdge{}
+	addEdge := func(from string, to string, relation string) {
+		edge := graphEdge{To: to, Relation: relation}
+		if !slices.Contains(edges[from], edge) {
+			edges[from] = append(edges[from], edge)
+		}
+	}
+
+	var pods []corev1.Pod
+	for _, r := range resources {
+		id := nodeID(r.GetKind(), r.GetName())
+		for _, or := range r.GetOwnerReferences() {
+			addEdge(nodeID(or.Kind, or.Name), id, relationOwns)
+		}
+
+		switch r.GetKind() {
+		case "Pod":
+			var pod corev1.Pod
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &pod); err != nil {
+				return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+			}
+			pods = append(pods, pod)
+			for _, ref := range podReferences(pod.Spec) {
+				addEdge(id, ref.To, ref.Relation)
+			}
+		case "PersistentVolumeClaim":
+			var pvc corev1.PersistentVolumeClaim
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &pvc); err != nil {
+				return nil, fmt.Errorf("failed to convert unstructured object to PersistentVolumeClaim: %w", err)
+			}
+			if pvc.Spec.VolumeName != "" {
+				addEdge(id, nodeID("PersistentVolume", pvc.Spec.VolumeName), relationBoundTo)
+			}
+		case "Ingress":
+			var ingress networkingv1.Ingress
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &ingress); err != nil {
+				return nil, fmt.Errorf("failed to convert unstructured object to Ingress: %w", err)
+			}
+			for _, ref := range ingressReferences(ingress) {
+				addEdge(id, ref.To, ref.Relation)
+			}
+		}
+	}
+
+	for _, r := range resources {
+		if r.GetKind() != "Service" {
+			continue
+		}
+		var service corev1.Service
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &service); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Service: %w", err)
+		}
+		if len(service.Spec.Selector) == 0 {
+			continue
+		}
+		selector := k8slabels.SelectorFromSet(service.Spec.Selector)
+		for _, pod := range pods {
+			if selector.Matches(k8slabels.Set(pod.Labels)) {
+				addEdge(nodeID("Service", service.Name), nodeID("Pod", pod.Name), relationSelects)
+			}
+		}
+	}
+
+	return edges, nil
+}
+
+// podReferences returns the PersistentVolumeClaims, ConfigMaps and Secrets mounted by a Pod, the ConfigMaps and Secrets
+// referenced by its environment variables and image pull secrets, and its ServiceAccount.
+func podReferences(spec corev1.PodSpec) []graphEdge {
+	var refs []graphEdge
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			refs = append(refs, graphEdge{To: nodeID("PersistentVolumeClaim", volume.PersistentVolumeClaim.ClaimName), Relation: relationMounts})
+		case volume.ConfigMap != nil:
+			refs = append(refs, graphEdge{To: nodeID("ConfigMap", volume.ConfigMap.Name), Relation: relationMounts})
+		case volume.Secret != nil:
+			refs = append(refs, graphEdge{To: nodeID("Secret", volume.Secret.SecretName), Relation: relationMounts})
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					refs = append(refs, graphEdge{To: nodeID("ConfigMap", source.ConfigMap.Name), Relation: relationMounts})
+				}
+				if source.Secret != nil {
+					refs = append(refs, graphEdge{To: nodeID("Secret", source.Secret.Name), Relation: relationMounts})
+				}
+			}
+		}
+	}
+
+	for _, container := range slices.Concat(spec.InitContainers, spec.Containers) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				refs = append(refs, graphEdge{To: nodeID("ConfigMap", envFrom.ConfigMapRef.Name), Relation: relationReferences})
+			}
+			if envFrom.SecretRef != nil {
+				refs = append(refs, graphEdge{To: nodeID("Secret", envFrom.SecretRef.Name), Relation: relationReferences})
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				refs = append(refs, graphEdge{To: nodeID("ConfigMap", ref.Name), Relation: relationReferences})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				refs = append(refs, graphEdge{To: nodeID("Secret", ref.Name), Relation: relationReferences})
+			}
+		}
+	}
+
+	for _, secret := range spec.ImagePullSecrets {
+		refs = append(refs, graphEdge{To: nodeID("Secret", secret.Name), Relation: relationReferences})
+	}
+	if spec.ServiceAccountName != "" {
+		refs = append(refs, graphEdge{To: nodeID("ServiceAccount", spec.ServiceAccountName), Relation: relationRunsAs})
+	}
+
+	return refs
+}
+
+// ingressReferences returns the Services an Ingress routes traffic to and the Secrets of its TLS certificates.
+func ingressReferences(ingress networkingv1.Ingress) []graphEdge {
+	var refs []graphEdge
+	if b := ingress.Spec.DefaultBackend; b != nil && b.Service != nil {
+		refs = append(refs, graphEdge{To: nodeID("Service", b.Service.Name), Relation: relationRoutesTo})
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if b := path.Backend.Service; b != nil {
+				refs = append(refs, graphEdge{To: nodeID("Service", b.Name), Relation: relationRoutesTo})
+			}
+		}
+	}
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" {
+			refs = append(refs, graphEdge{To: nodeID("Secret", tls.SecretName), Relation: relationReferences})
+		}
+	}
+
+	return refs
+}
+
+// walkGraph follows the edges in both directions from the root, up to depth edges away, and returns the graph of the
+// nodes reached.
+func walkGraph(root string, edges map[string][]graphEdge, depth int) resourceGraph {
+	neighbours := map[string][]string{}
+	for from, fromEdges := range edges {
+		for _, edge := range fromEdges {
+			neighbours[from] = append(neighbours[from], edge.To)
+			neighbours[edge.To] = append(neighbours[edge.To], from)
+		}
+	}
+
+	reached := map[string]bool{root: true}
+	current := []string{root}
+	for range depth {
+		var next []string
+		for _, node := range current {
+			for _, neighbour := range neighbours[node] {
+				if !reached[neighbour] {
+					reached[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		current = next
+	}
+
+	graph := resourceGraph{Root: root, Nodes: make([]string, 0, len(reached)), Edges: map[string][]graphEdge{}}
+	for node := range reached {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	slices.Sort(graph.Nodes)
+	for from, fromEdges := range edges {
+		if !reached[from] {
+			continue
+		}
+		for _, edge := range fromEdges {
+			if reached[edge.To] {
+				graph.Edges[from] = append(graph.Edges[from], edge)
+			}
+		}
+		slices.SortFunc(graph.Edges[from], func(a, b graphEdge) int {
+			return cmp.Or(cmp.Compare(a.To, b.To), cmp.Compare(a.Relation, b.Relation))
+		})
+	}
+
+	return graph
+}
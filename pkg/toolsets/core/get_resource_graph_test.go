@@ -0,0 +1,216 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func graphObjects() []runtime.Object {
+	ownedBy := func(kind string, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: ptr.To(true)}}
+	}
+
+	return []runtime.Object{
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		},
+		&appsv1.ReplicaSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", OwnerReferences: ownedBy("Deployment", "web")},
+		},
+		&corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1-a", Namespace: "default", Labels: map[string]string{"app": "web"}, OwnerReferences: ownedBy("ReplicaSet", "web-1")},
+			Spec: corev1.PodSpec{
+				ServiceAccountName: "web",
+				Volumes: []corev1.Volume{
+					{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}},
+					{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+				},
+				Containers: []corev1.Container{{
+					Name: "web",
+					Env: []corev1.EnvVar{{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"},
+					}}},
+				}},
+			},
+		},
+		&corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default", Labels: map[string]string{"app": "worker"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:    "worker",
+					EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}},
+				}},
+			},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		},
+		&networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"web.example.com"}, SecretName: "web-tls"}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "web.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+						Path:    "/",
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+					}}}},
+				}},
+			},
+		},
+		&corev1.PersistentVolumeClaim{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		},
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "default"},
+		},
+	}
+}
+
+func TestGetResourceGraph(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params         getResourceGraphParams
+		expectedResult string
+		expectedError  string
+	}{
+		"deployment with default depth": {
+			params: getResourceGraphParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"root": "Deployment/web",
+				"namespace": "default",
+				"nodes": ["Deployment/web", "Pod/web-1-a", "ReplicaSet/web-1"],
+				"edges": {
+					"Deployment/web": [{"to": "ReplicaSet/web-1", "relation": "owns"}],
+					"ReplicaSet/web-1": [{"to": "Pod/web-1-a", "relation": "owns"}]
+				}
+			}`,
+		},
+		"pod": {
+			params: getResourceGraphParams{Kind: "Pod", Name: "web-1-a", Namespace: "default", Cluster: "local", Depth: 1},
+			expectedResult: `{
+				"root": "Pod/web-1-a",
+				"namespace": "default",
+				"nodes": ["ConfigMap/web-config", "PersistentVolumeClaim/data", "Pod/web-1-a", "ReplicaSet/web-1", "Secret/db", "Service/web", "ServiceAccount/web"],
+				"edges": {
+					"Pod/web-1-a": [
+						{"to": "ConfigMap/web-config", "relation": "mounts"},
+						{"to": "PersistentVolumeClaim/data", "relation": "mounts"},
+						{"to": "Secret/db", "relation": "references"},
+						{"to": "ServiceAccount/web", "relation": "runsAs"}
+					],
+					"ReplicaSet/web-1": [{"to": "Pod/web-1-a", "relation": "owns"}],
+					"Service/web": [{"to": "Pod/web-1-a", "relation": "selects"}]
+				}
+			}`,
+		},
+		"blast radius of a configmap": {
+			params: getResourceGraphParams{Kind: "ConfigMap", Name: "web-config", Namespace: "default", Cluster: "local", Depth: 3},
+			expectedResult: `{
+				"root": "ConfigMap/web-config",
+				"namespace": "default",
+				"nodes": ["ConfigMap/web-config", "Deployment/web", "Ingress/web", "PersistentVolume/pv-1", "PersistentVolumeClaim/data", "Pod/web-1-a", "Pod/worker", "ReplicaSet/web-1", "Secret/db", "Service/web", "ServiceAccount/web"],
+				"edges": {
+					"Deployment/web": [{"to": "ReplicaSet/web-1", "relation": "owns"}],
+					"Ingress/web": [{"to": "Service/web", "relation": "routesTo"}],
+					"PersistentVolumeClaim/data": [{"to": "PersistentVolume/pv-1", "relation": "boundTo"}],
+					"Pod/web-1-a": [
+						{"to": "ConfigMap/web-config", "relation": "mounts"},
+						{"to": "PersistentVolumeClaim/data", "relation": "mounts"},
+						{"to": "Secret/db", "relation": "references"},
+						{"to": "ServiceAccount/web", "relation": "runsAs"}
+					],
+					"Pod/worker": [{"to": "ConfigMap/web-config", "relation": "references"}],
+					"ReplicaSet/web-1": [{"to": "Pod/web-1-a", "relation": "owns"}],
+					"Service/web": [{"to": "Pod/web-1-a", "relation": "selects"}]
+				}
+			}`,
+		},
+		"ingress": {
+			params: getResourceGraphParams{Kind: "Ingress", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"root": "Ingress/web",
+				"namespace": "default",
+				"nodes": ["Ingress/web", "Pod/web-1-a", "Secret/web-tls", "Service/web"],
+				"edges": {
+					"Ingress/web": [
+						{"to": "Secret/web-tls", "relation": "references"},
+						{"to": "Service/web", "relation": "routesTo"}
+					],
+					"Service/web": [{"to": "Pod/web-1-a", "relation": "selects"}]
+				}
+			}`,
+		},
+		"resource not found": {
+			params:        getResourceGraphParams{Kind: "Deployment", Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedError: `deployments.apps "missing" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+			_ = batchv1.AddToScheme(scheme)
+			_ = networkingv1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+				{Group: "", Version: "v1", Resource: "pods"}:                       "PodList",
+				{Group: "apps", Version: "v1", Resource: "replicasets"}:            "ReplicaSetList",
+				{Group: "apps", Version: "v1", Resource: "deployments"}:            "DeploymentList",
+				{Group: "apps", Version: "v1", Resource: "statefulsets"}:           "StatefulSetList",
+				{Group: "apps", Version: "v1", Resource: "daemonsets"}:             "DaemonSetList",
+				{Group: "batch", Version: "v1", Resource: "jobs"}:                  "JobList",
+				{Group: "batch", Version: "v1", Resource: "cronjobs"}:              "CronJobList",
+				{Group: "", Version: "v1", Resource: "services"}:                   "ServiceList",
+				{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}: "IngressList",
+				{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}:     "PersistentVolumeClaimList",
+			}, graphObjects()...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getResourceGraph(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -81,3 +83,26 @@ func TestGetResource(t *testing.T) {
 		})
 	}
 }
+
+func TestGetResourceLogsTraceIDs(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+	c := &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return dynamicfake.NewSimpleDynamicClient(scheme()), nil
+		},
+	}
+	tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+	ctx := client.WithTrace(middleware.WithToken(t.Context(), "fakeToken"), client.Trace{RequestID: "req-1", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+
+	_, _, err := tools.getResource(ctx, &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+	}, resourceParams{Name: "rancher", Kind: "pod", Namespace: "default", Cluster: "local"})
+
+	require.Error(t, err)
+	entries := logs.FilterMessage("failed to get resource").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "req-1", fields["requestId"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields["traceId"])
+}
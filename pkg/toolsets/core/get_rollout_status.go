@@ -0,0 +1,207 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	rolloutComplete    = "complete"
+	rolloutProgressing = "progressing"
+	rolloutPaused      = "paused"
+	rolloutFailed      = "failed"
+)
+
+// rolloutStatus summarizes the progress of the rollout of a workload, like 'kubectl rollout status'.
+type rolloutStatus struct {
+	// Status is one of complete, progressing, paused or failed.
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Desired is the number of Pods the workload should run. Updated, Ready and Available are the number of those
+	// Pods that run the latest Pod template, are ready, and have been ready for minReadySeconds.
+	Desired   int32 `json:"desired"`
+	Updated   int32 `json:"updated"`
+	Ready     int32 `json:"ready"`
+	Available int32 `json:"available"`
+	// Observed is false while the controller hasn't processed the latest change of the workload.
+	Observed bool `json:"observed"`
+}
+
+// getRolloutStatus returns a workload and the status of its rollout.
+func (t *Tools) getRolloutStatus(ctx context.Context, toolReq *mcp.CallToolRequest, params workloadParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getRolloutStatus called")
+
+	resourceInterface, err := t.getWorkloadInterface(ctx, toolReq, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get workload", zap.String("tool", "getRolloutStatus"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get %s %s: %w", params.Kind, params.Name, err)
+	}
+
+	status, err := newRolloutStatus(obj)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get rollout status", zap.String("tool", "getRolloutStatus"), zap.Error(err))
+		return nil, nil, err
+	}
+	statusObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert rollout status: %w", err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj, {Object: map[string]any{"rolloutStatus": statusObj}}}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getRolloutStatus"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// newRolloutStatus returns the rollout status of a Deployment, StatefulSet or DaemonSet.
+func newRolloutStatus(obj *unstructured.Unstructured) (*rolloutStatus, error) {
+	switch obj.GetKind() {
+	case "Deployment":
+		var deployment appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to Deployment: %w", err)
+		}
+		return deploymentRolloutStatus(&deployment), nil
+	case "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &statefulSet); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to StatefulSet: %w", err)
+		}
+		return statefulSetRolloutStatus(&statefulSet), nil
+	case "DaemonSet":
+		var daemonSet appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &daemonSet); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to DaemonSet: %w", err)
+		}
+		return daemonSetRolloutStatus(&daemonSet), nil
+	default:
+		return nil, fmt.Errorf("kind %s doesn't support rollouts", obj.GetKind())
+	}
+}
+
+func deploymentRolloutStatus(deployment *appsv1.Deployment) *rolloutStatus {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := &rolloutStatus{
+		Desired:   desired,
+		Updated:   deployment.Status.UpdatedReplicas,
+		Ready:     deployment.Status.ReadyReplicas,
+		Available: deployment.Status.AvailableReplicas,
+		Observed:  deployment.Generation <= deployment.Status.ObservedGeneration,
+	}
+
+	switch {
+	case !status.Observed:
+		status.Status, status.Message = rolloutProgressing, "waiting for the deployment spec update to be observed"
+	case deployment.Spec.Paused:
+		status.Status, status.Message = rolloutPaused, "the rollout is paused, resume it to roll out the changes"
+	case progressDeadlineExceeded(deployment):
+		status.Status, status.Message = rolloutFailed, fmt.Sprintf("the rollout exceeded its progress deadline with %d out of %d new replicas updated", status.Updated, desired)
+	case status.Updated < desired:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d out of %d new replicas have been updated", status.Updated, desired)
+	case deployment.Status.Replicas > status.Updated:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d old replicas are pending termination", deployment.Status.Replicas-status.Updated)
+	case status.Available < status.Updated:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d of %d updated replicas are available", status.Available, status.Updated)
+	default:
+		status.Status, status.Message = rolloutComplete, "the deployment was successfully rolled out"
+	}
+
+	return status
+}
+
+// progressDeadlineExceeded returns whether the Progressing condition of the Deployment reports that the rollout
+// didn't progress within progressDeadlineSeconds.
+func progressDeadlineExceeded(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing {
+			return condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded"
+		}
+	}
+
+	return false
+}
+
+func statefulSetRolloutStatus(statefulSet *appsv1.StatefulSet) *rolloutStatus {
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	status := &rolloutStatus{
+		Desired:   desired,
+		Updated:   statefulSet.Status.UpdatedReplicas,
+		Ready:     statefulSet.Status.ReadyReplicas,
+		Available: statefulSet.Status.AvailableReplicas,
+		Observed:  statefulSet.Generation <= statefulSet.Status.ObservedGeneration,
+	}
+
+	var partition int32
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		partition = *rollingUpdate.Partition
+	}
+
+	switch {
+	case statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType:
+		status.Status, status.Message = rolloutPaused, "the OnDelete update strategy only updates the Pods when they are deleted"
+	case !status.Observed:
+		status.Status, status.Message = rolloutProgressing, "waiting for the statefulset spec update to be observed"
+	case status.Ready < desired:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d of %d Pods are ready", status.Ready, desired)
+	case partition > 0 && status.Updated < desired-partition:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d of %d Pods above the partition %d have been updated", status.Updated, desired-partition, partition)
+	case partition > 0:
+		status.Status, status.Message = rolloutComplete, fmt.Sprintf("the partitioned rollout is complete, the %d Pods below the partition %d run the previous revision", partition, partition)
+	case statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d of %d Pods have been updated to revision %s", status.Updated, desired, statefulSet.Status.UpdateRevision)
+	default:
+		status.Status, status.Message = rolloutComplete, "the statefulset was successfully rolled out"
+	}
+
+	return status
+}
+
+func daemonSetRolloutStatus(daemonSet *appsv1.DaemonSet) *rolloutStatus {
+	status := &rolloutStatus{
+		Desired:   daemonSet.Status.DesiredNumberScheduled,
+		Updated:   daemonSet.Status.UpdatedNumberScheduled,
+		Ready:     daemonSet.Status.NumberReady,
+		Available: daemonSet.Status.NumberAvailable,
+		Observed:  daemonSet.Generation <= daemonSet.Status.ObservedGeneration,
+	}
+
+	switch {
+	case daemonSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType:
+		status.Status, status.Message = rolloutPaused, "the OnDelete update strategy only updates the Pods when they are deleted"
+	case !status.Observed:
+		status.Status, status.Message = rolloutProgressing, "waiting for the daemonset spec update to be observed"
+	case status.Updated < status.Desired:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d out of %d new Pods have been updated", status.Updated, status.Desired)
+	case status.Available < status.Desired:
+		status.Status, status.Message = rolloutProgressing, fmt.Sprintf("%d of %d updated Pods are available", status.Available, status.Desired)
+	default:
+		status.Status, status.Message = rolloutComplete, "the daemonset was successfully rolled out"
+	}
+
+	return status
+}
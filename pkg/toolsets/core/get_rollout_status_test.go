@@ -0,0 +1,149 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestNewRolloutStatus(t *testing.T) {
+	deployment := func(status appsv1.DeploymentStatus, paused bool) runtime.Object {
+		d := newRolloutDeployment("2", "web:2", paused)
+		d.Generation = 2
+		d.Status = status
+		d.Status.ObservedGeneration = 2
+		return d
+	}
+	statefulSet := func(partition int32, status appsv1.StatefulSetStatus) runtime.Object {
+		s := &appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Generation: 1},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: ptr.To(int32(3)),
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: ptr.To(partition)},
+				},
+			},
+			Status: status,
+		}
+		s.Status.ObservedGeneration = 1
+		return s
+	}
+	daemonSet := func(status appsv1.DaemonSetStatus) runtime.Object {
+		d := &appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", Generation: 1},
+			Status:     status,
+		}
+		d.Status.ObservedGeneration = 1
+		return d
+	}
+
+	tests := map[string]struct {
+		obj             runtime.Object
+		expectedStatus  string
+		expectedMessage string
+	}{
+		"complete deployment": {
+			obj:             deployment(appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3}, false),
+			expectedStatus:  rolloutComplete,
+			expectedMessage: "the deployment was successfully rolled out",
+		},
+		"deployment updating replicas": {
+			obj:             deployment(appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, ReadyReplicas: 3, AvailableReplicas: 3}, false),
+			expectedStatus:  rolloutProgressing,
+			expectedMessage: "1 out of 3 new replicas have been updated",
+		},
+		"deployment terminating old replicas": {
+			obj:             deployment(appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3}, false),
+			expectedStatus:  rolloutProgressing,
+			expectedMessage: "1 old replicas are pending termination",
+		},
+		"deployment waiting for available replicas": {
+			obj:             deployment(appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 2, AvailableReplicas: 2}, false),
+			expectedStatus:  rolloutProgressing,
+			expectedMessage: "2 of 3 updated replicas are available",
+		},
+		"deployment exceeding its progress deadline": {
+			obj: deployment(appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3, Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			}}, false),
+			expectedStatus:  rolloutFailed,
+			expectedMessage: "the rollout exceeded its progress deadline with 1 out of 3 new replicas updated",
+		},
+		"paused deployment": {
+			obj:             deployment(appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 1}, true),
+			expectedStatus:  rolloutPaused,
+			expectedMessage: "the rollout is paused, resume it to roll out the changes",
+		},
+		"statefulset updating revision": {
+			obj:             statefulSet(0, appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			expectedStatus:  rolloutProgressing,
+			expectedMessage: "1 of 3 Pods have been updated to revision db-2",
+		},
+		"statefulset with a partition": {
+			obj:             statefulSet(2, appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			expectedStatus:  rolloutComplete,
+			expectedMessage: "the partitioned rollout is complete, the 2 Pods below the partition 2 run the previous revision",
+		},
+		"complete statefulset": {
+			obj:             statefulSet(0, appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "db-2", UpdateRevision: "db-2"}),
+			expectedStatus:  rolloutComplete,
+			expectedMessage: "the statefulset was successfully rolled out",
+		},
+		"daemonset waiting for available pods": {
+			obj:             daemonSet(appsv1.DaemonSetStatus{DesiredNumberScheduled: 5, UpdatedNumberScheduled: 5, NumberReady: 4, NumberAvailable: 4}),
+			expectedStatus:  rolloutProgressing,
+			expectedMessage: "4 of 5 updated Pods are available",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(test.obj)
+			require.NoError(t, err)
+
+			status, err := newRolloutStatus(&unstructured.Unstructured{Object: obj})
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, status.Status)
+			assert.Equal(t, test.expectedMessage, status.Message)
+		})
+	}
+}
+
+func TestGetRolloutStatus(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	deployment := newRolloutDeployment("1", "web:1", false)
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 2}
+	tools, _ := newRolloutTools(deployment)
+
+	result, _, err := tools.getRolloutStatus(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+	}, workloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local"})
+
+	require.NoError(t, err)
+	items := llmItems(t, result)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Deployment", items[0]["kind"])
+	assert.Equal(t, map[string]any{
+		"status":    rolloutProgressing,
+		"message":   "2 of 3 updated replicas are available",
+		"desired":   float64(3),
+		"updated":   float64(3),
+		"ready":     float64(3),
+		"available": float64(2),
+		"observed":  true,
+	}, items[1]["rolloutStatus"])
+}
@@ -0,0 +1,427 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// changeCauseAnn is the annotation recording the command or the reason of a change, copied to the ReplicaSets.
+	changeCauseAnn = "kubernetes.io/change-cause"
+	// defaultHistoryRevisions is the number of revisions returned by getWorkloadHistory when limit isn't set.
+	defaultHistoryRevisions = 5
+	// maxManagedFieldDepth is the depth at which the fields owned by a manager are summarized.
+	maxManagedFieldDepth = 6
+	// maxManagerFields is the number of fields listed per manager.
+	maxManagerFields = 20
+)
+
+// historyAnns are the annotations telling who or what created or changed a workload.
+var historyAnns = []string{
+	changeCauseAnn,
+	"field.cattle.io/creatorId",
+	"meta.helm.sh/release-name",
+	"meta.helm.sh/release-namespace",
+	"objectset.rio.cattle.io/id",
+}
+
+type getWorkloadHistoryParams struct {
+	Kind      string `json:"kind" jsonschema:"the kind of the workload: Deployment, StatefulSet or DaemonSet" validate:"required,enum=Deployment|StatefulSet|DaemonSet"`
+	Name      string `json:"name" jsonschema:"the name of the workload"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the workload"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the workload"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"the number of revisions returned, 5 by default"`
+}
+
+// workloadHistory is the recent change history of a workload.
+type workloadHistory struct {
+	Kind            string             `json:"kind"`
+	Name            string             `json:"name"`
+	Namespace       string             `json:"namespace"`
+	CurrentRevision int64              `json:"currentRevision"`
+	Revisions       []workloadRevision `json:"revisions"`
+	// LastModifiedBy is the manager of the latest change outside of the status.
+	LastModifiedBy string            `json:"lastModifiedBy,omitempty"`
+	Managers       []fieldManager    `json:"managers"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+}
+
+// workloadRevision is a Pod template of a workload, stored in a ReplicaSet for a Deployment or in a ControllerRevision
+// for a StatefulSet or a DaemonSet.
+type workloadRevision struct {
+	Revision    int64             `json:"revision"`
+	Name        string            `json:"name"`
+	Created     string            `json:"created"`
+	Current     bool              `json:"current"`
+	Replicas    *int32            `json:"replicas,omitempty"`
+	ChangeCause string            `json:"changeCause,omitempty"`
+	Images      map[string]string `json:"images"`
+	// Changes are the differences with the Pod template of the previous revision.
+	Changes []templateChange `json:"changes,omitempty"`
+}
+
+// templateChange is a field of a Pod template that changed between two revisions. From is empty when the field was
+// added and To when it was removed.
+type templateChange struct {
+	Path string `json:"path"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// fieldManager summarizes a managedFields entry of a workload: who changed which fields, and when.
+type fieldManager struct {
+	Manager     string   `json:"manager"`
+	Operation   string   `json:"operation"`
+	Subresource string   `json:"subresource,omitempty"`
+	Time        string   `json:"time,omitempty"`
+	Fields      []string `json:"fields"`
+	MoreFields  int      `json:"moreFields,omitempty"`
+}
+
+// historyRevision is a revision of a workload with its Pod template, before being summarized.
+type historyRevision struct {
+	workloadRevision
+	template corev1.PodTemplateSpec
+}
+
+// getWorkloadHistory reconstructs the recent changes of a Deployment, StatefulSet or DaemonSet from its previous
+// ReplicaSets or ControllerRevisions and its managedFields.
+func (t *Tools) getWorkloadHistory(ctx context.Context, toolReq *mcp.CallToolRequest, params getWorkloadHistoryParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getWorkloadHistory called")
+
+	resourceInterface, err := t.getWorkloadInterface(ctx, toolReq, workloadParams{Kind: params.Kind, Name: params.Name, Namespace: params.Namespace, Cluster: params.Cluster})
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get workload", zap.String("tool", "getWorkloadHistory"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get %s %s: %w", params.Kind, params.Name, err)
+	}
+
+	var revisions []historyRevision
+	var currentRevision int64
+	if obj.GetKind() == "Deployment" {
+		revisions, currentRevision, err = t.deploymentRevisions(ctx, toolReq, params.Cluster, obj)
+	} else {
+		revisions, currentRevision, err = t.controllerRevisions(ctx, toolReq, params.Cluster, obj)
+	}
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get revisions", zap.String("tool", "getWorkloadHistory"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	limit := defaultHistoryRevisions
+	if params.Limit > 0 {
+		limit = params.Limit
+	}
+	history, err := newWorkloadHistory(obj, revisions, currentRevision, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := json.Marshal(history)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getWorkloadHistory"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// deploymentRevisions returns the revisions stored in the ReplicaSets of a Deployment and its current revision.
+func (t *Tools) deploymentRevisions(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, obj *unstructured.Unstructured) ([]historyRevision, int64, error) {
+	var deployment appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+		return nil, 0, fmt.Errorf("failed to convert unstructured object to Deployment: %w", err)
+	}
+	replicaSets, err := t.getDeploymentReplicaSets(ctx, toolReq, cluster, &deployment)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	revisions := make([]historyRevision, 0, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		template := *replicaSet.Spec.Template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		revisions = append(revisions, historyRevision{
+			workloadRevision: workloadRevision{
+				Revision:    revision(replicaSet.Annotations),
+				Name:        replicaSet.Name,
+				Created:     replicaSet.CreationTimestamp.UTC().Format(time.RFC3339),
+				Replicas:    &replicaSet.Status.Replicas,
+				ChangeCause: replicaSet.Annotations[changeCauseAnn],
+			},
+			template: template,
+		})
+	}
+
+	return revisions, revision(deployment.Annotations), nil
+}
+
+// controllerRevisions returns the revisions stored in the ControllerRevisions of a StatefulSet or a DaemonSet and its
+// current revision: the update revision of a StatefulSet, or the latest revision of a DaemonSet.
+func (t *Tools) controllerRevisions(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, obj *unstructured.Unstructured) ([]historyRevision, int64, error) {
+	selectorObj, _, _ := unstructured.NestedMap(obj.Object, "spec", "selector")
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorObj, &labelSelector); err != nil {
+		return nil, 0, fmt.Errorf("failed to convert label selector: %w", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to convert label selector: %w", err)
+	}
+	resources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "controllerrevision",
+		Namespace:     obj.GetNamespace(),
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get controllerrevisions: %w", err)
+	}
+
+	updateRevision, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	var revisions []historyRevision
+	var currentRevision int64
+	for _, resource := range resources {
+		var controllerRevision appsv1.ControllerRevision
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, &controllerRevision); err != nil {
+			return nil, 0, fmt.Errorf("failed to convert unstructured object to ControllerRevision: %w", err)
+		}
+		owner := metav1.GetControllerOf(&controllerRevision)
+		if owner == nil || owner.Kind != obj.GetKind() || owner.Name != obj.GetName() {
+			continue
+		}
+		// The data of a ControllerRevision is a patch replacing the Pod template of the workload.
+		var data struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(controllerRevision.Data.Raw, &data); err != nil {
+			return nil, 0, fmt.Errorf("failed to read controllerrevision %s: %w", controllerRevision.Name, err)
+		}
+		delete(data.Spec.Template.Labels, appsv1.ControllerRevisionHashLabelKey)
+
+		revisions = append(revisions, historyRevision{
+			workloadRevision: workloadRevision{
+				Revision:    controllerRevision.Revision,
+				Name:        controllerRevision.Name,
+				Created:     controllerRevision.CreationTimestamp.UTC().Format(time.RFC3339),
+				ChangeCause: controllerRevision.Annotations[changeCauseAnn],
+			},
+			template: data.Spec.Template,
+		})
+		if controllerRevision.Name == updateRevision || (updateRevision == "" && controllerRevision.Revision > currentRevision) {
+			currentRevision = controllerRevision.Revision
+		}
+	}
+
+	return revisions, currentRevision, nil
+}
+
+// newWorkloadHistory returns the limit latest revisions, newest first, with the changes of their Pod template since
+// the previous revision, and the managers of the workload.
+func newWorkloadHistory(obj *unstructured.Unstructured, revisions []historyRevision, currentRevision int64, limit int) (workloadHistory, error) {
+	history := workloadHistory{
+		Kind:            obj.GetKind(),
+		Name:            obj.GetName(),
+		Namespace:       obj.GetNamespace(),
+		CurrentRevision: currentRevision,
+		Revisions:       []workloadRevision{},
+		Managers:        newFieldManagers(obj.GetManagedFields()),
+	}
+	for _, ann := range historyAnns {
+		if value, ok := obj.GetAnnotations()[ann]; ok {
+			if history.Annotations == nil {
+				history.Annotations = map[string]string{}
+			}
+			history.Annotations[ann] = value
+		}
+	}
+	for _, manager := range history.Managers {
+		if manager.Subresource == "" {
+			history.LastModifiedBy = manager.Manager
+			break
+		}
+	}
+
+	slices.SortFunc(revisions, func(a, b historyRevision) int {
+		return cmp.Compare(b.Revision, a.Revision)
+	})
+	for i, rev := range revisions[:min(limit, len(revisions))] {
+		rev.Current = rev.Revision == currentRevision
+		rev.Images = map[string]string{}
+		for _, container := range slices.Concat(rev.template.Spec.InitContainers, rev.template.Spec.Containers) {
+			rev.Images[container.Name] = container.Image
+		}
+		if i+1 < len(revisions) {
+			changes, err := templateChanges(revisions[i+1].template, rev.template)
+			if err != nil {
+				return workloadHistory{}, err
+			}
+			rev.Changes = changes
+		}
+		history.Revisions = append(history.Revisions, rev.workloadRevision)
+	}
+
+	return history, nil
+}
+
+// templateChanges returns the fields that differ between two Pod templates, sorted by path.
+func templateChanges(from corev1.PodTemplateSpec, to corev1.PodTemplateSpec) ([]templateChange, error) {
+	fromFields, err := templateFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := templateFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []templateChange
+	for path, value := range fromFields {
+		if toFields[path] != value {
+			changes = append(changes, templateChange{Path: path, From: value, To: toFields[path]})
+		}
+	}
+	for path, value := range toFields {
+		if _, ok := fromFields[path]; !ok {
+			changes = append(changes, templateChange{Path: path, To: value})
+		}
+	}
+	slices.SortFunc(changes, func(a, b templateChange) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	return changes, nil
+}
+
+// templateFields flattens a Pod template to the values of its fields by path, e.g.
+// spec.containers[web].image. The elements of the lists are identified by their name when they have one.
+func templateFields(template corev1.PodTemplateSpec) (map[string]string, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pod template: %w", err)
+	}
+	unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+
+	fields := map[string]string{}
+	var flatten func(path string, value any)
+	flatten = func(path string, value any) {
+		switch v := value.(type) {
+		case nil:
+		case map[string]any:
+			for key, child := range v {
+				flatten(strings.TrimPrefix(path+"."+key, "."), child)
+			}
+		case []any:
+			for i, child := range v {
+				key := fmt.Sprint(i)
+				if m, ok := child.(map[string]any); ok {
+					if name, ok := m["name"].(string); ok {
+						key = name
+					}
+				}
+				flatten(fmt.Sprintf("%s[%s]", path, key), child)
+			}
+		default:
+			fields[path] = fmt.Sprint(v)
+		}
+	}
+	flatten("", obj)
+
+	return fields, nil
+}
+
+// newFieldManagers summarizes the managedFields of a workload, newest first.
+func newFieldManagers(entries []metav1.ManagedFieldsEntry) []fieldManager {
+	managers := make([]fieldManager, 0, len(entries))
+	for _, entry := range entries {
+		manager := fieldManager{
+			Manager:     entry.Manager,
+			Operation:   string(entry.Operation),
+			Subresource: entry.Subresource,
+			Fields:      []string{},
+		}
+		if entry.Time != nil {
+			manager.Time = entry.Time.UTC().Format(time.RFC3339)
+		}
+		if entry.FieldsV1 != nil {
+			var fields map[string]any
+			if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err == nil {
+				manager.Fields = managedFieldPaths("", fields, 0)
+			}
+		}
+		if len(manager.Fields) > maxManagerFields {
+			manager.MoreFields = len(manager.Fields) - maxManagerFields
+			manager.Fields = manager.Fields[:maxManagerFields]
+		}
+		managers = append(managers, manager)
+	}
+	slices.SortStableFunc(managers, func(a, b fieldManager) int {
+		return cmp.Compare(b.Time, a.Time)
+	})
+
+	return managers
+}
+
+// managedFieldPaths returns the sorted paths of the fields in a FieldsV1 set, summarized at maxManagedFieldDepth. The
+// keys of the list elements are shown between brackets, e.g. spec.template.spec.containers[name=web].image.
+func managedFieldPaths(path string, fields map[string]any, depth int) []string {
+	var paths []string
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if key == "." {
+			continue
+		}
+		prefix, value, _ := strings.Cut(key, ":")
+		child := path
+		switch prefix {
+		case "f":
+			child = strings.TrimPrefix(path+"."+value, ".")
+		case "k":
+			var elementKeys map[string]any
+			if err := json.Unmarshal([]byte(value), &elementKeys); err != nil {
+				child = path + "[" + value + "]"
+				break
+			}
+			var keys []string
+			for _, name := range slices.Sorted(maps.Keys(elementKeys)) {
+				keys = append(keys, fmt.Sprintf("%s=%v", name, elementKeys[name]))
+			}
+			child = path + "[" + strings.Join(keys, ",") + "]"
+		default:
+			child = path + "[" + value + "]"
+		}
+
+		children, _ := fields[key].(map[string]any)
+		if depth+1 >= maxManagedFieldDepth || len(children) == 0 || (len(children) == 1 && children["."] != nil) {
+			paths = append(paths, child)
+			continue
+		}
+		paths = append(paths, managedFieldPaths(child, children, depth+1)...)
+	}
+
+	return slices.Compact(paths)
+}
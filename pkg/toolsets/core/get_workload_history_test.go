@@ -0,0 +1,222 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func historyTemplate(image string, env ...corev1.EnvVar) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image, Env: env}}},
+	}
+}
+
+func newHistoryReplicaSet(rev int, changeCause string, replicas int32, template corev1.PodTemplateSpec) *appsv1.ReplicaSet {
+	template.Labels = map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: fmt.Sprintf("hash%d", rev)}
+	replicaSet := &appsv1.ReplicaSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("web-%d", rev),
+			Namespace:         "default",
+			Labels:            template.Labels,
+			Annotations:       map[string]string{revisionAnn: fmt.Sprint(rev)},
+			CreationTimestamp: metav1.NewTime(time.Date(2026, 3, rev, 10, 0, 0, 0, time.UTC)),
+			OwnerReferences:   []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: ptr.To(true)}},
+		},
+		Spec:   appsv1.ReplicaSetSpec{Template: template},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+	}
+	if changeCause != "" {
+		replicaSet.Annotations[changeCauseAnn] = changeCause
+	}
+
+	return replicaSet
+}
+
+func newControllerRevision(t *testing.T, rev int64, template corev1.PodTemplateSpec) *appsv1.ControllerRevision {
+	template.Labels = map[string]string{"app": "web", appsv1.ControllerRevisionHashLabelKey: fmt.Sprintf("hash%d", rev)}
+	data, err := json.Marshal(map[string]any{"spec": map[string]any{"template": template}})
+	require.NoError(t, err)
+
+	return &appsv1.ControllerRevision{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ControllerRevision"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("db-hash%d", rev),
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "web"},
+			CreationTimestamp: metav1.NewTime(time.Date(2026, 3, int(rev), 10, 0, 0, 0, time.UTC)),
+			OwnerReferences:   []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", Controller: ptr.To(true)}},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: rev,
+	}
+}
+
+func TestGetWorkloadHistory(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	logLevel := corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"}
+
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Annotations: map[string]string{revisionAnn: "3", "meta.helm.sh/release-name": "web", "field.cattle.io/publicEndpoints": "[]"},
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{
+						Manager:   "helm",
+						Operation: metav1.ManagedFieldsOperationUpdate,
+						Time:      ptr.To(metav1.NewTime(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))),
+						FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{".":{},"f:meta.helm.sh/release-name":{}}},"f:spec":{"f:replicas":{}}}`)},
+					},
+					{
+						Manager:   "kubectl-set",
+						Operation: metav1.ManagedFieldsOperationUpdate,
+						Time:      ptr.To(metav1.NewTime(time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC))),
+						FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"web\"}":{".":{},"f:image":{}}}}}}}`)},
+					},
+					{
+						Manager:     "kube-controller-manager",
+						Operation:   metav1.ManagedFieldsOperationUpdate,
+						Subresource: "status",
+						Time:        ptr.To(metav1.NewTime(time.Date(2026, 3, 3, 10, 1, 0, 0, time.UTC))),
+						FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:conditions":{"k:{\"type\":\"Available\"}":{".":{},"f:status":{}}},"f:replicas":{}}}`)},
+					},
+				},
+			},
+			Spec: appsv1.DeploymentSpec{Selector: selector, Template: historyTemplate("web:3", logLevel)},
+		},
+		newHistoryReplicaSet(1, "", 0, historyTemplate("web:1")),
+		newHistoryReplicaSet(2, "kubectl set image deployment/web web=web:2", 0, historyTemplate("web:2")),
+		newHistoryReplicaSet(3, "", 2, historyTemplate("web:3", logLevel)),
+		&appsv1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: historyTemplate("db:2")},
+			Status:     appsv1.StatefulSetStatus{CurrentRevision: "db-hash1", UpdateRevision: "db-hash2"},
+		},
+		newControllerRevision(t, 1, historyTemplate("db:1")),
+		newControllerRevision(t, 2, historyTemplate("db:2")),
+	}
+
+	tests := map[string]struct {
+		params         getWorkloadHistoryParams
+		expectedResult string
+		expectedError  string
+	}{
+		"deployment": {
+			params: getWorkloadHistoryParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"kind": "Deployment", "name": "web", "namespace": "default", "currentRevision": 3,
+				"revisions": [
+					{"revision": 3, "name": "web-3", "created": "2026-03-03T10:00:00Z", "current": true, "replicas": 2, "images": {"web": "web:3"},
+					 "changes": [
+						{"path": "spec.containers[web].env[LOG_LEVEL].name", "to": "LOG_LEVEL"},
+						{"path": "spec.containers[web].env[LOG_LEVEL].value", "to": "debug"},
+						{"path": "spec.containers[web].image", "from": "web:2", "to": "web:3"}
+					 ]},
+					{"revision": 2, "name": "web-2", "created": "2026-03-02T10:00:00Z", "current": false, "replicas": 0, "changeCause": "kubectl set image deployment/web web=web:2", "images": {"web": "web:2"},
+					 "changes": [{"path": "spec.containers[web].image", "from": "web:1", "to": "web:2"}]},
+					{"revision": 1, "name": "web-1", "created": "2026-03-01T10:00:00Z", "current": false, "replicas": 0, "images": {"web": "web:1"}}
+				],
+				"lastModifiedBy": "kubectl-set",
+				"managers": [
+					{"manager": "kube-controller-manager", "operation": "Update", "subresource": "status", "time": "2026-03-03T10:01:00Z", "fields": ["status.conditions[type=Available].status", "status.replicas"]},
+					{"manager": "kubectl-set", "operation": "Update", "time": "2026-03-03T10:00:00Z", "fields": ["spec.template.spec.containers[name=web].image"]},
+					{"manager": "helm", "operation": "Update", "time": "2026-03-01T10:00:00Z", "fields": ["metadata.annotations.meta.helm.sh/release-name", "spec.replicas"]}
+				],
+				"annotations": {"meta.helm.sh/release-name": "web"}
+			}`,
+		},
+		"deployment with limit": {
+			params: getWorkloadHistoryParams{Kind: "deployment", Name: "web", Namespace: "default", Cluster: "local", Limit: 1},
+			expectedResult: `{
+				"kind": "Deployment", "name": "web", "namespace": "default", "currentRevision": 3,
+				"revisions": [
+					{"revision": 3, "name": "web-3", "created": "2026-03-03T10:00:00Z", "current": true, "replicas": 2, "images": {"web": "web:3"},
+					 "changes": [
+						{"path": "spec.containers[web].env[LOG_LEVEL].name", "to": "LOG_LEVEL"},
+						{"path": "spec.containers[web].env[LOG_LEVEL].value", "to": "debug"},
+						{"path": "spec.containers[web].image", "from": "web:2", "to": "web:3"}
+					 ]}
+				],
+				"lastModifiedBy": "kubectl-set",
+				"managers": [
+					{"manager": "kube-controller-manager", "operation": "Update", "subresource": "status", "time": "2026-03-03T10:01:00Z", "fields": ["status.conditions[type=Available].status", "status.replicas"]},
+					{"manager": "kubectl-set", "operation": "Update", "time": "2026-03-03T10:00:00Z", "fields": ["spec.template.spec.containers[name=web].image"]},
+					{"manager": "helm", "operation": "Update", "time": "2026-03-01T10:00:00Z", "fields": ["metadata.annotations.meta.helm.sh/release-name", "spec.replicas"]}
+				],
+				"annotations": {"meta.helm.sh/release-name": "web"}
+			}`,
+		},
+		"statefulset": {
+			params: getWorkloadHistoryParams{Kind: "StatefulSet", Name: "db", Namespace: "default", Cluster: "local"},
+			expectedResult: `{
+				"kind": "StatefulSet", "name": "db", "namespace": "default", "currentRevision": 2,
+				"revisions": [
+					{"revision": 2, "name": "db-hash2", "created": "2026-03-02T10:00:00Z", "current": true, "images": {"web": "db:2"},
+					 "changes": [{"path": "spec.containers[web].image", "from": "db:1", "to": "db:2"}]},
+					{"revision": 1, "name": "db-hash1", "created": "2026-03-01T10:00:00Z", "current": false, "images": {"web": "db:1"}}
+				],
+				"managers": []
+			}`,
+		},
+		"unsupported kind": {
+			params:        getWorkloadHistoryParams{Kind: "Job", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedError: "kind Job doesn't support rollouts, must be Deployment, StatefulSet or DaemonSet",
+		},
+		"workload not found": {
+			params:        getWorkloadHistoryParams{Kind: "DaemonSet", Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedError: `failed to get DaemonSet missing`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = appsv1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+				{Group: "apps", Version: "v1", Resource: "replicasets"}:         "ReplicaSetList",
+				{Group: "apps", Version: "v1", Resource: "controllerrevisions"}: "ControllerRevisionList",
+			}, objects...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.getWorkloadHistory(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
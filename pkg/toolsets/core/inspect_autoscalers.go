@@ -0,0 +1,389 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type inspectAutoscalersParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the autoscalers"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the autoscalers, all namespaces if empty"`
+	Target    string `json:"target,omitempty" jsonschema:"only inspect the autoscalers of the workload with this name"`
+}
+
+// autoscalers contains the HorizontalPodAutoscalers and the VerticalPodAutoscalers of a cluster.
+type autoscalers struct {
+	HorizontalPodAutoscalers []hpaStatus `json:"horizontalPodAutoscalers"`
+	// VPAInstalled is false when the VerticalPodAutoscaler CRD isn't installed in the cluster.
+	VPAInstalled           bool        `json:"vpaInstalled"`
+	VerticalPodAutoscalers []vpaStatus `json:"verticalPodAutoscalers,omitempty"`
+}
+
+// hpaStatus compares the current metrics and replicas of a HorizontalPodAutoscaler with its targets, and explains why
+// it isn't scaling.
+type hpaStatus struct {
+	Name            string                `json:"name"`
+	Namespace       string                `json:"namespace"`
+	Target          string                `json:"target"`
+	MinReplicas     int32                 `json:"minReplicas"`
+	MaxReplicas     int32                 `json:"maxReplicas"`
+	CurrentReplicas int32                 `json:"currentReplicas"`
+	DesiredReplicas int32                 `json:"desiredReplicas"`
+	Metrics         []hpaMetric           `json:"metrics"`
+	Conditions      []autoscalerCondition `json:"conditions,omitempty"`
+	Hints           []string              `json:"hints"`
+}
+
+// hpaMetric is a metric of a HorizontalPodAutoscaler with its current value, which is empty when the metric can't be
+// read.
+type hpaMetric struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	Current string `json:"current,omitempty"`
+}
+
+type autoscalerCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// vpaStatus contains the recommendations of a VerticalPodAutoscaler.
+type vpaStatus struct {
+	Name            string                `json:"name"`
+	Namespace       string                `json:"namespace"`
+	Target          string                `json:"target"`
+	UpdateMode      string                `json:"updateMode"`
+	Recommendations []vpaRecommendation   `json:"recommendations,omitempty"`
+	Conditions      []autoscalerCondition `json:"conditions,omitempty"`
+	Hints           []string              `json:"hints"`
+}
+
+type vpaRecommendation struct {
+	Container  string            `json:"container"`
+	Target     map[string]string `json:"target"`
+	LowerBound map[string]string `json:"lowerBound,omitempty"`
+	UpperBound map[string]string `json:"upperBound,omitempty"`
+}
+
+// verticalPodAutoscaler is the subset of a VerticalPodAutoscaler of the autoscaler project used to report its
+// recommendations.
+type verticalPodAutoscaler struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		TargetRef *autoscalingv2.CrossVersionObjectReference `json:"targetRef"`
+		// UpdatePolicy.UpdateMode is one of Off, Initial, Recreate, InPlaceOrRecreate or Auto, Auto when not set.
+		UpdatePolicy *struct {
+			UpdateMode string `json:"updateMode"`
+		} `json:"updatePolicy"`
+	} `json:"spec"`
+	Status struct {
+		Recommendation *struct {
+			ContainerRecommendations []struct {
+				ContainerName string              `json:"containerName"`
+				Target        corev1.ResourceList `json:"target"`
+				LowerBound    corev1.ResourceList `json:"lowerBound"`
+				UpperBound    corev1.ResourceList `json:"upperBound"`
+			} `json:"containerRecommendations"`
+		} `json:"recommendation"`
+		Conditions []autoscalerCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+// inspectAutoscalers returns the HorizontalPodAutoscalers of a cluster, with their current and target metrics and
+// hints about why they aren't scaling, and the recommendations of the VerticalPodAutoscalers when they are installed.
+func (t *Tools) inspectAutoscalers(ctx context.Context, toolReq *mcp.CallToolRequest, params inspectAutoscalersParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("inspectAutoscalers called")
+
+	listParams := client.ListParams{
+		Cluster:   params.Cluster,
+		Kind:      "horizontalpodautoscaler",
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}
+	unstructuredHPAs, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list HorizontalPodAutoscalers", zap.String("tool", "inspectAutoscalers"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list HorizontalPodAutoscalers: %w", err)
+	}
+	listParams.Kind = "vpa"
+	unstructuredVPAs, err := t.client.GetResources(ctx, listParams)
+	if err != nil && !apierrors.IsNotFound(err) {
+		middleware.Logger(ctx).Error("failed to list VerticalPodAutoscalers", zap.String("tool", "inspectAutoscalers"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list VerticalPodAutoscalers: %w", err)
+	}
+
+	result := autoscalers{HorizontalPodAutoscalers: []hpaStatus{}, VPAInstalled: err == nil}
+	var vpas []verticalPodAutoscaler
+	for _, unstructuredVPA := range unstructuredVPAs {
+		var vpa verticalPodAutoscaler
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredVPA.Object, &vpa); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to VerticalPodAutoscaler: %w", err)
+		}
+		if vpa.Spec.TargetRef == nil || (params.Target != "" && vpa.Spec.TargetRef.Name != params.Target) {
+			continue
+		}
+		vpas = append(vpas, vpa)
+		result.VerticalPodAutoscalers = append(result.VerticalPodAutoscalers, newVPAStatus(vpa))
+	}
+	for _, unstructuredHPA := range unstructuredHPAs {
+		var hpa autoscalingv2.HorizontalPodAutoscaler
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredHPA.Object, &hpa); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to HorizontalPodAutoscaler: %w", err)
+		}
+		if params.Target != "" && hpa.Spec.ScaleTargetRef.Name != params.Target {
+			continue
+		}
+		result.HorizontalPodAutoscalers = append(result.HorizontalPodAutoscalers, newHPAStatus(&hpa, vpas))
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "inspectAutoscalers"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newHPAStatus matches the metrics of the HorizontalPodAutoscaler with their current values and explains its
+// conditions. vpas are the VerticalPodAutoscalers of the cluster, which conflict with the HorizontalPodAutoscaler when
+// both act on the cpu or memory of the same workload.
+func newHPAStatus(hpa *autoscalingv2.HorizontalPodAutoscaler, vpas []verticalPodAutoscaler) hpaStatus {
+	status := hpaStatus{
+		Name:            hpa.Name,
+		Namespace:       hpa.Namespace,
+		Target:          hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Metrics:         []hpaMetric{},
+		Hints:           []string{},
+	}
+	if hpa.Spec.MinReplicas != nil {
+		status.MinReplicas = *hpa.Spec.MinReplicas
+	}
+
+	current := map[string]string{}
+	for _, metric := range hpa.Status.CurrentMetrics {
+		metricType, name, value := metricStatus(metric)
+		current[metricType+"/"+name] = value
+	}
+	var missing []string
+	usesResources := false
+	for _, metric := range hpa.Spec.Metrics {
+		m := metricSpec(metric)
+		m.Current = current[m.Type+"/"+m.Name]
+		if m.Current == "" {
+			missing = append(missing, m.Name)
+		}
+		if metric.Type == autoscalingv2.ResourceMetricSourceType || metric.Type == autoscalingv2.ContainerResourceMetricSourceType {
+			usesResources = true
+		}
+		status.Metrics = append(status.Metrics, m)
+	}
+
+	scalingActive := true
+	for _, condition := range hpa.Status.Conditions {
+		status.Conditions = append(status.Conditions, autoscalerCondition{
+			Type:    string(condition.Type),
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		})
+		if hint := conditionHint(condition, status); hint != "" {
+			status.Hints = append(status.Hints, hint)
+		}
+		if condition.Type == autoscalingv2.ScalingActive && condition.Status == corev1.ConditionFalse {
+			scalingActive = false
+		}
+	}
+	if len(hpa.Status.Conditions) == 0 {
+		status.Hints = append(status.Hints, "The HorizontalPodAutoscaler has no conditions, it hasn't been processed by the controller yet.")
+	} else if scalingActive && len(missing) > 0 {
+		status.Hints = append(status.Hints, fmt.Sprintf("The metrics %s have no current value, they are ignored until they can be read.", strings.Join(missing, ", ")))
+	}
+
+	if usesResources {
+		for _, vpa := range vpas {
+			if vpa.Namespace == hpa.Namespace && vpa.Spec.TargetRef.Kind == hpa.Spec.ScaleTargetRef.Kind && vpa.Spec.TargetRef.Name == hpa.Spec.ScaleTargetRef.Name && vpaUpdateMode(vpa) != "Off" {
+				status.Hints = append(status.Hints, fmt.Sprintf("VerticalPodAutoscaler %s also updates the resources of %s, both autoscalers shouldn't act on cpu or memory at the same time. "+
+					"Use custom or external metrics in the HorizontalPodAutoscaler, or set the updateMode of the VerticalPodAutoscaler to Off.", vpa.Name, status.Target))
+			}
+		}
+	}
+
+	return status
+}
+
+// conditionHint explains a condition of a HorizontalPodAutoscaler that prevents it from scaling, or returns an empty
+// string when it doesn't.
+func conditionHint(condition autoscalingv2.HorizontalPodAutoscalerCondition, status hpaStatus) string {
+	switch {
+	case condition.Type == autoscalingv2.AbleToScale && condition.Status == corev1.ConditionFalse:
+		if condition.Reason == "FailedGetScale" {
+			return fmt.Sprintf("The scale of %s can't be read, check that it exists and can be scaled: %s", status.Target, condition.Message)
+		}
+		return fmt.Sprintf("%s can't be scaled (%s): %s", status.Target, condition.Reason, condition.Message)
+	case condition.Type == autoscalingv2.ScalingActive && condition.Status == corev1.ConditionFalse:
+		switch condition.Reason {
+		case "ScalingDisabled":
+			return fmt.Sprintf("Autoscaling is disabled because %s has zero replicas, scale it up to enable it again.", status.Target)
+		case "FailedGetResourceMetric", "FailedGetContainerResourceMetric":
+			if strings.Contains(condition.Message, "missing request for") {
+				return fmt.Sprintf("Some containers of %s have no resource requests, they are required to compute the utilization of a resource: %s", status.Target, condition.Message)
+			}
+			return fmt.Sprintf("The resource metrics can't be read, check that metrics-server is installed and running: %s", condition.Message)
+		case "FailedGetPodsMetric", "FailedGetObjectMetric", "FailedGetExternalMetric":
+			return fmt.Sprintf("The custom or external metrics can't be read, check that a metrics adapter such as prometheus-adapter is installed and serves them: %s", condition.Message)
+		}
+		return fmt.Sprintf("Autoscaling is inactive (%s): %s", condition.Reason, condition.Message)
+	case condition.Type == autoscalingv2.ScalingLimited && condition.Status == corev1.ConditionTrue:
+		switch condition.Reason {
+		case "TooManyReplicas":
+			return fmt.Sprintf("The HorizontalPodAutoscaler is maxed out, its metrics ask for more than %d replicas. Raise maxReplicas if the cluster has enough capacity.", status.MaxReplicas)
+		case "TooFewReplicas":
+			return fmt.Sprintf("Its metrics ask for fewer than %d replicas, lower minReplicas to scale further down.", status.MinReplicas)
+		}
+		return fmt.Sprintf("Scaling is limited (%s): %s", condition.Reason, condition.Message)
+	}
+
+	return ""
+}
+
+// metricSpec returns the type, the name and the target of a metric of a HorizontalPodAutoscaler.
+func metricSpec(metric autoscalingv2.MetricSpec) hpaMetric {
+	m := hpaMetric{Type: string(metric.Type)}
+	switch {
+	case metric.Resource != nil:
+		m.Name, m.Target = string(metric.Resource.Name), metricTarget(metric.Resource.Target)
+	case metric.ContainerResource != nil:
+		m.Name, m.Target = metric.ContainerResource.Container+"/"+string(metric.ContainerResource.Name), metricTarget(metric.ContainerResource.Target)
+	case metric.Pods != nil:
+		m.Name, m.Target = metric.Pods.Metric.Name, metricTarget(metric.Pods.Target)
+	case metric.Object != nil:
+		m.Name, m.Target = metric.Object.DescribedObject.Kind+"/"+metric.Object.DescribedObject.Name+"/"+metric.Object.Metric.Name, metricTarget(metric.Object.Target)
+	case metric.External != nil:
+		m.Name, m.Target = metric.External.Metric.Name, metricTarget(metric.External.Target)
+	}
+
+	return m
+}
+
+// metricStatus returns the type, the name and the current value of a metric of a HorizontalPodAutoscaler, with the same
+// name as metricSpec.
+func metricStatus(metric autoscalingv2.MetricStatus) (string, string, string) {
+	switch {
+	case metric.Resource != nil:
+		return string(metric.Type), string(metric.Resource.Name), metricValue(metric.Resource.Current)
+	case metric.ContainerResource != nil:
+		return string(metric.Type), metric.ContainerResource.Container + "/" + string(metric.ContainerResource.Name), metricValue(metric.ContainerResource.Current)
+	case metric.Pods != nil:
+		return string(metric.Type), metric.Pods.Metric.Name, metricValue(metric.Pods.Current)
+	case metric.Object != nil:
+		return string(metric.Type), metric.Object.DescribedObject.Kind + "/" + metric.Object.DescribedObject.Name + "/" + metric.Object.Metric.Name, metricValue(metric.Object.Current)
+	case metric.External != nil:
+		return string(metric.Type), metric.External.Metric.Name, metricValue(metric.External.Current)
+	}
+
+	return string(metric.Type), "", ""
+}
+
+// metricTarget formats the target of a metric like 'kubectl get hpa': 80% for utilizations and 500m (average) for
+// average values.
+func metricTarget(target autoscalingv2.MetricTarget) string {
+	return formatMetric(target.AverageUtilization, target.AverageValue, target.Value)
+}
+
+func metricValue(current autoscalingv2.MetricValueStatus) string {
+	return formatMetric(current.AverageUtilization, current.AverageValue, current.Value)
+}
+
+func formatMetric(utilization *int32, averageValue *resource.Quantity, value *resource.Quantity) string {
+	switch {
+	case utilization != nil:
+		return fmt.Sprintf("%d%%", *utilization)
+	case averageValue != nil:
+		return averageValue.String() + " (average)"
+	case value != nil:
+		return value.String()
+	}
+
+	return ""
+}
+
+// newVPAStatus returns the recommendations of a VerticalPodAutoscaler, and hints when it has none or doesn't apply them.
+func newVPAStatus(vpa verticalPodAutoscaler) vpaStatus {
+	status := vpaStatus{
+		Name:       vpa.Name,
+		Namespace:  vpa.Namespace,
+		Target:     vpa.Spec.TargetRef.Kind + "/" + vpa.Spec.TargetRef.Name,
+		UpdateMode: vpaUpdateMode(vpa),
+		Conditions: vpa.Status.Conditions,
+		Hints:      []string{},
+	}
+	if vpa.Status.Recommendation != nil {
+		for _, recommendation := range vpa.Status.Recommendation.ContainerRecommendations {
+			status.Recommendations = append(status.Recommendations, vpaRecommendation{
+				Container:  recommendation.ContainerName,
+				Target:     formatResources(recommendation.Target),
+				LowerBound: formatResources(recommendation.LowerBound),
+				UpperBound: formatResources(recommendation.UpperBound),
+			})
+		}
+	}
+
+	if len(status.Recommendations) == 0 {
+		hint := "The VerticalPodAutoscaler has no recommendation yet, check that the vpa-recommender is running and that metrics-server serves the metrics of the Pods."
+		for _, condition := range vpa.Status.Conditions {
+			if condition.Type == "ConfigUnsupported" && condition.Status == string(corev1.ConditionTrue) {
+				hint = "The configuration of the VerticalPodAutoscaler isn't supported: " + condition.Message
+			}
+		}
+		status.Hints = append(status.Hints, hint)
+	} else if status.UpdateMode == "Off" {
+		status.Hints = append(status.Hints, fmt.Sprintf("The updateMode is Off, the recommendations aren't applied to %s.", status.Target))
+	}
+
+	return status
+}
+
+// vpaUpdateMode returns the update mode of a VerticalPodAutoscaler, with its default.
+func vpaUpdateMode(vpa verticalPodAutoscaler) string {
+	if vpa.Spec.UpdatePolicy == nil || vpa.Spec.UpdatePolicy.UpdateMode == "" {
+		return "Auto"
+	}
+
+	return vpa.Spec.UpdatePolicy.UpdateMode
+}
+
+func formatResources(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	formatted := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		formatted[string(name)] = quantity.String()
+	}
+
+	return formatted
+}
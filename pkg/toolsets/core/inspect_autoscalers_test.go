@@ -0,0 +1,262 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+// newAutoscalerTools returns Tools using a fake dynamic client with the given autoscalers. Listing
+// VerticalPodAutoscalers fails with NotFound when vpaInstalled is false, like in clusters without the CRD.
+func newAutoscalerTools(t *testing.T, vpaInstalled bool, objects ...runtime.Object) (*Tools, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}:      "HorizontalPodAutoscalerList",
+		{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}: "VerticalPodAutoscalerList",
+	}, objects...)
+	if !vpaInstalled {
+		fakeDynClient.PrependReactor("list", "verticalpodautoscalers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "autoscaling.k8s.io", Resource: "verticalpodautoscalers"}, "")
+		})
+	}
+	c := &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+
+	return &Tools{client: newFakeToolsClient(c, "fakeToken")}, fakeDynClient
+}
+
+// newHPA returns a HorizontalPodAutoscaler of the Deployment target, between 2 and 5 replicas, on a cpu utilization
+// of 80%, with the given current cpu utilization and conditions.
+func newHPA(t *testing.T, target string, currentReplicas int32, currentUtilization *int32, conditions ...autoscalingv2.HorizontalPodAutoscalerCondition) *unstructured.Unstructured {
+	t.Helper()
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		ObjectMeta: metav1.ObjectMeta{Name: target, Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: target},
+			MinReplicas:    ptr.To(int32(2)),
+			MaxReplicas:    5,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: ptr.To(int32(80))},
+				},
+			}},
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: currentReplicas,
+			DesiredReplicas: currentReplicas,
+			Conditions:      conditions,
+		},
+	}
+	if currentUtilization != nil {
+		hpa.Status.CurrentMetrics = []autoscalingv2.MetricStatus{{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricStatus{
+				Name:    corev1.ResourceCPU,
+				Current: autoscalingv2.MetricValueStatus{AverageUtilization: currentUtilization, AverageValue: ptr.To(resource.MustParse("400m"))},
+			},
+		}}
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(hpa)
+	require.NoError(t, err)
+
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func newVPA(target string, updateMode string, withRecommendation bool) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]any{"name": target, "namespace": "default"},
+		"spec": map[string]any{
+			"targetRef":    map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": target},
+			"updatePolicy": map[string]any{"updateMode": updateMode},
+		},
+	}}
+	if withRecommendation {
+		vpa.Object["status"] = map[string]any{
+			"conditions": []any{map[string]any{"type": "RecommendationProvided", "status": "True", "lastTransitionTime": "2026-01-01T00:00:00Z"}},
+			"recommendation": map[string]any{"containerRecommendations": []any{map[string]any{
+				"containerName": "web",
+				"target":        map[string]any{"cpu": "250m", "memory": "256Mi"},
+				"lowerBound":    map[string]any{"cpu": "100m", "memory": "128Mi"},
+				"upperBound":    map[string]any{"cpu": "1", "memory": "1Gi"},
+			}}},
+		}
+	}
+
+	return vpa
+}
+
+func hpaCondition(conditionType autoscalingv2.HorizontalPodAutoscalerConditionType, status corev1.ConditionStatus, reason string, message string) autoscalingv2.HorizontalPodAutoscalerCondition {
+	return autoscalingv2.HorizontalPodAutoscalerCondition{Type: conditionType, Status: status, Reason: reason, Message: message}
+}
+
+func TestInspectAutoscalers(t *testing.T) {
+	ableToScale := hpaCondition(autoscalingv2.AbleToScale, corev1.ConditionTrue, "ReadyForNewScale", "recommended size matches current size")
+
+	tests := map[string]struct {
+		params         inspectAutoscalersParams
+		vpaInstalled   bool
+		objects        []runtime.Object
+		expectedResult string
+	}{
+		"maxed out": {
+			params: inspectAutoscalersParams{Cluster: "local"},
+			objects: []runtime.Object{newHPA(t, "web", 5, ptr.To(int32(140)), ableToScale,
+				hpaCondition(autoscalingv2.ScalingActive, corev1.ConditionTrue, "ValidMetricFound", "the HPA was able to successfully calculate a replica count from cpu resource utilization"),
+				hpaCondition(autoscalingv2.ScalingLimited, corev1.ConditionTrue, "TooManyReplicas", "the desired replica count is more than the maximum replica count"))},
+			expectedResult: `{
+				"horizontalPodAutoscalers": [{
+					"name": "web", "namespace": "default", "target": "Deployment/web",
+					"minReplicas": 2, "maxReplicas": 5, "currentReplicas": 5, "desiredReplicas": 5,
+					"metrics": [{"type": "Resource", "name": "cpu", "target": "80%", "current": "140%"}],
+					"conditions": [
+						{"type": "AbleToScale", "status": "True", "reason": "ReadyForNewScale", "message": "recommended size matches current size"},
+						{"type": "ScalingActive", "status": "True", "reason": "ValidMetricFound", "message": "the HPA was able to successfully calculate a replica count from cpu resource utilization"},
+						{"type": "ScalingLimited", "status": "True", "reason": "TooManyReplicas", "message": "the desired replica count is more than the maximum replica count"}
+					],
+					"hints": ["The HorizontalPodAutoscaler is maxed out, its metrics ask for more than 5 replicas. Raise maxReplicas if the cluster has enough capacity."]
+				}],
+				"vpaInstalled": false
+			}`,
+		},
+		"metrics server missing": {
+			params: inspectAutoscalersParams{Cluster: "local", Namespace: "default"},
+			objects: []runtime.Object{newHPA(t, "web", 2, nil, ableToScale,
+				hpaCondition(autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetResourceMetric", "the HPA was unable to compute the replica count: failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API"))},
+			expectedResult: `{
+				"horizontalPodAutoscalers": [{
+					"name": "web", "namespace": "default", "target": "Deployment/web",
+					"minReplicas": 2, "maxReplicas": 5, "currentReplicas": 2, "desiredReplicas": 2,
+					"metrics": [{"type": "Resource", "name": "cpu", "target": "80%"}],
+					"conditions": [
+						{"type": "AbleToScale", "status": "True", "reason": "ReadyForNewScale", "message": "recommended size matches current size"},
+						{"type": "ScalingActive", "status": "False", "reason": "FailedGetResourceMetric", "message": "the HPA was unable to compute the replica count: failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API"}
+					],
+					"hints": ["The resource metrics can't be read, check that metrics-server is installed and running: the HPA was unable to compute the replica count: failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API"]
+				}],
+				"vpaInstalled": false
+			}`,
+		},
+		"missing resource requests": {
+			params: inspectAutoscalersParams{Cluster: "local"},
+			objects: []runtime.Object{newHPA(t, "web", 2, nil, ableToScale,
+				hpaCondition(autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetResourceMetric", "failed to get cpu utilization: missing request for cpu in container web of Pod web-1"))},
+			expectedResult: `{
+				"horizontalPodAutoscalers": [{
+					"name": "web", "namespace": "default", "target": "Deployment/web",
+					"minReplicas": 2, "maxReplicas": 5, "currentReplicas": 2, "desiredReplicas": 2,
+					"metrics": [{"type": "Resource", "name": "cpu", "target": "80%"}],
+					"conditions": [
+						{"type": "AbleToScale", "status": "True", "reason": "ReadyForNewScale", "message": "recommended size matches current size"},
+						{"type": "ScalingActive", "status": "False", "reason": "FailedGetResourceMetric", "message": "failed to get cpu utilization: missing request for cpu in container web of Pod web-1"}
+					],
+					"hints": ["Some containers of Deployment/web have no resource requests, they are required to compute the utilization of a resource: failed to get cpu utilization: missing request for cpu in container web of Pod web-1"]
+				}],
+				"vpaInstalled": false
+			}`,
+		},
+		"not processed yet": {
+			params:  inspectAutoscalersParams{Cluster: "local"},
+			objects: []runtime.Object{newHPA(t, "web", 0, nil)},
+			expectedResult: `{
+				"horizontalPodAutoscalers": [{
+					"name": "web", "namespace": "default", "target": "Deployment/web",
+					"minReplicas": 2, "maxReplicas": 5, "currentReplicas": 0, "desiredReplicas": 0,
+					"metrics": [{"type": "Resource", "name": "cpu", "target": "80%"}],
+					"hints": ["The HorizontalPodAutoscaler has no conditions, it hasn't been processed by the controller yet."]
+				}],
+				"vpaInstalled": false
+			}`,
+		},
+		"hpa and vpa on the same workload": {
+			params:       inspectAutoscalersParams{Cluster: "local", Target: "web"},
+			vpaInstalled: true,
+			objects: []runtime.Object{
+				newHPA(t, "web", 3, ptr.To(int32(60)), ableToScale),
+				newHPA(t, "api", 3, ptr.To(int32(60)), ableToScale),
+				newVPA("web", "Auto", true),
+			},
+			expectedResult: `{
+				"horizontalPodAutoscalers": [{
+					"name": "web", "namespace": "default", "target": "Deployment/web",
+					"minReplicas": 2, "maxReplicas": 5, "currentReplicas": 3, "desiredReplicas": 3,
+					"metrics": [{"type": "Resource", "name": "cpu", "target": "80%", "current": "60%"}],
+					"conditions": [{"type": "AbleToScale", "status": "True", "reason": "ReadyForNewScale", "message": "recommended size matches current size"}],
+					"hints": ["VerticalPodAutoscaler web also updates the resources of Deployment/web, both autoscalers shouldn't act on cpu or memory at the same time. Use custom or external metrics in the HorizontalPodAutoscaler, or set the updateMode of the VerticalPodAutoscaler to Off."]
+				}],
+				"vpaInstalled": true,
+				"verticalPodAutoscalers": [{
+					"name": "web", "namespace": "default", "target": "Deployment/web", "updateMode": "Auto",
+					"recommendations": [{
+						"container": "web",
+						"target": {"cpu": "250m", "memory": "256Mi"},
+						"lowerBound": {"cpu": "100m", "memory": "128Mi"},
+						"upperBound": {"cpu": "1", "memory": "1Gi"}
+					}],
+					"conditions": [{"type": "RecommendationProvided", "status": "True"}],
+					"hints": []
+				}]
+			}`,
+		},
+		"vpa recommendations": {
+			params:       inspectAutoscalersParams{Cluster: "local"},
+			vpaInstalled: true,
+			objects:      []runtime.Object{newVPA("web", "Off", true), newVPA("api", "Auto", false)},
+			expectedResult: `{
+				"horizontalPodAutoscalers": [],
+				"vpaInstalled": true,
+				"verticalPodAutoscalers": [{
+					"name": "api", "namespace": "default", "target": "Deployment/api", "updateMode": "Auto",
+					"hints": ["The VerticalPodAutoscaler has no recommendation yet, check that the vpa-recommender is running and that metrics-server serves the metrics of the Pods."]
+				}, {
+					"name": "web", "namespace": "default", "target": "Deployment/web", "updateMode": "Off",
+					"recommendations": [{
+						"container": "web",
+						"target": {"cpu": "250m", "memory": "256Mi"},
+						"lowerBound": {"cpu": "100m", "memory": "128Mi"},
+						"upperBound": {"cpu": "1", "memory": "1Gi"}
+					}],
+					"conditions": [{"type": "RecommendationProvided", "status": "True"}],
+					"hints": ["The updateMode is Off, the recommendations aren't applied to Deployment/web."]
+				}]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, _ := newAutoscalerTools(t, test.vpaInstalled, test.objects...)
+
+			result, _, err := tools.inspectAutoscalers(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
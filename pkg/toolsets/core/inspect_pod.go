@@ -1,35 +1,48 @@
 package core
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
+	"slices"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
 	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/utils/ptr"
 )
 
+// The types of the containers of a Pod.
 const (
-	podLogsTailLines int64 = 50
+	containerTypeInit      = "init"
+	containerTypeContainer = "container"
+	containerTypeEphemeral = "ephemeral"
 )
 
-// containerLogs holds logs for multiple containers.
-type containerLogs struct {
-	Logs map[string]any `json:"logs"`
+// podContainer is the status of a container of a Pod. The init and ephemeral containers are included, the failures
+// of the init containers keep the Pod pending while its containers are only waiting.
+type podContainer struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Image        string `json:"image"`
+	State        string `json:"state,omitempty"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+	// Exit is how the container terminated, e.g. an init container that completed or failed.
+	Exit            *containerExit `json:"exit,omitempty"`
+	LastTermination *containerExit `json:"lastTermination,omitempty"`
+	// TargetContainer is the container whose processes an ephemeral debug container can see.
+	TargetContainer string `json:"targetContainer,omitempty"`
 }
 
 // inspectPod retrieves detailed information about a specific pod, its owner, metrics, and logs.
 func (t *Tools) inspectPod(ctx context.Context, toolReq *mcp.CallToolRequest, params specificResourceParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("inspectPod called")
+	middleware.Logger(ctx).Debug("inspectPod called")
 
 	podResource, err := t.client.GetResource(ctx, client.GetParams{
 		Cluster:   params.Cluster,
@@ -40,16 +53,84 @@ func (t *Tools) inspectPod(ctx context.Context, toolReq *mcp.CallToolRequest, pa
 		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to get Pod", zap.String("tool", "inspectPod"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get Pod", zap.String("tool", "inspectPod"), zap.Error(err))
 		return nil, nil, err
 	}
 
 	var pod corev1.Pod
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pod); err != nil {
-		zap.L().Error("failed to convert unstructured object to Pod", zap.String("tool", "inspectPod"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to convert unstructured object to Pod", zap.String("tool", "inspectPod"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
 	}
 
+	// the owners, the metrics, the logs and the events of the pod are fetched concurrently
+	var replicaSetResource, parentResource, podMetrics, logs, events *unstructured.Unstructured
+	g := fetch.New(ctx, 0)
+	g.Go("owners", func(ctx context.Context) error {
+		var err error
+		replicaSetResource, parentResource, err = t.getPodOwners(ctx, toolReq, params, pod)
+		if err != nil || !params.IncludeEvents {
+			return err
+		}
+		g.GoOptional("events", func(ctx context.Context) error {
+			var err error
+			events, err = t.fetchRelatedEvents(ctx, toolReq, params.Cluster, params.Namespace, []*unstructured.Unstructured{podResource, replicaSetResource, parentResource})
+			return err
+		})
+		return nil
+	})
+	g.Go("metrics", func(ctx context.Context) error {
+		// ignore error as Metrics Server might not be installed in the cluster
+		podMetrics, _ = t.client.GetResource(ctx, client.GetParams{
+			Cluster:   params.Cluster,
+			Kind:      "pod.metrics.k8s.io",
+			Namespace: params.Namespace,
+			Name:      params.Name,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		return nil
+	})
+	g.GoOptional("logs", func(ctx context.Context) error {
+		var err error
+		logs, err = t.fetchPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), params.Cluster, middleware.Token(ctx), pod, getPodLogsParams{})
+		return err
+	})
+	failures, err := g.Wait()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	containers, err := podContainers(pod)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to convert the containers of the Pod", zap.String("tool", "inspectPod"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	resources := []*unstructured.Unstructured{podResource, parentResource, containers}
+	for _, resource := range []*unstructured.Unstructured{logs, podMetrics, events} {
+		if resource != nil {
+			resources = append(resources, resource)
+		}
+	}
+	for _, failure := range failures {
+		middleware.Logger(ctx).Warn("failed to fetch pod details", zap.String("tool", "inspectPod"), zap.String("fetch", failure.Fetch), zap.String("error", failure.Error))
+		resources = append(resources, failure.Unstructured())
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "inspectPod"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// getPodOwners returns the ReplicaSet owning the pod and the workload owning the ReplicaSet.
+func (t *Tools) getPodOwners(ctx context.Context, toolReq *mcp.CallToolRequest, params specificResourceParams, pod corev1.Pod) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
 	// find the parent of the pod
 	var replicaSetName string
 	for _, or := range pod.OwnerReferences {
@@ -67,14 +148,14 @@ func (t *Tools) inspectPod(ctx context.Context, toolReq *mcp.CallToolRequest, pa
 		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to get ReplicaSet", zap.String("tool", "inspectPod"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get ReplicaSet", zap.String("tool", "inspectPod"), zap.Error(err))
 		return nil, nil, err
 	}
 
 	var replicaSet appsv1.ReplicaSet
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(replicaSetResource.Object, &replicaSet); err != nil {
-		zap.L().Error("failed to convert unstructured object to ReplicaSet", zap.String("tool", "inspectPod"), zap.Error(err))
-		return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		middleware.Logger(ctx).Error("failed to convert unstructured object to ReplicaSet", zap.String("tool", "inspectPod"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to ReplicaSet: %w", err)
 	}
 
 	var parentName, parentKind string
@@ -94,73 +175,60 @@ func (t *Tools) inspectPod(ctx context.Context, toolReq *mcp.CallToolRequest, pa
 		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to get parent resource", zap.String("tool", "inspectPod"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get parent resource", zap.String("tool", "inspectPod"), zap.Error(err))
 		return nil, nil, err
 	}
 
-	// ignore error as Metrics Server might not be installed in the cluster
-	podMetrics, _ := t.client.GetResource(ctx, client.GetParams{
-		Cluster:   params.Cluster,
-		Kind:      "pod.metrics.k8s.io",
-		Namespace: params.Namespace,
-		Name:      params.Name,
-		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     middleware.Token(ctx),
-	})
-
-	logs, err := t.getPodLogs(ctx, toolReq.Extra.Header.Get(urlHeader), params.Cluster, middleware.Token(ctx), pod)
-	if err != nil {
-		zap.L().Error("failed to get pod logs", zap.String("tool", "inspectPod"), zap.Error(err))
-		return nil, nil, err
-	}
+	return replicaSetResource, parentResource, nil
+}
 
-	resources := []*unstructured.Unstructured{podResource, parentResource, logs}
-	if podMetrics != nil {
-		resources = append(resources, podMetrics)
+// podContainers returns the status of the init containers, the containers and the ephemeral containers of the Pod,
+// in the order they run.
+func podContainers(pod corev1.Pod) (*unstructured.Unstructured, error) {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses, pod.Status.EphemeralContainerStatuses) {
+		statuses[status.Name] = status
 	}
-
-	mcpResponse, err := response.CreateMcpResponse(resources, params.Cluster)
-	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "inspectPod"), zap.Error(err))
-		return nil, nil, err
+	newPodContainer := func(name string, containerType string, image string) podContainer {
+		status := statuses[name]
+		return podContainer{
+			Name:            name,
+			Type:            containerType,
+			Image:           image,
+			State:           describeContainerState(status.State),
+			Ready:           status.Ready,
+			RestartCount:    status.RestartCount,
+			Exit:            newContainerExit(status.State.Terminated),
+			LastTermination: newContainerExit(status.LastTerminationState.Terminated),
+		}
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
-	}, nil, nil
-}
-
-// getPodLogs retrieves the logs for all containers in a pod.
-// It returns the logs as an unstructured object with container names as keys.
-// Only the last 50 lines of logs are retrieved per container to limit payload size.
-func (t *Tools) getPodLogs(ctx context.Context, url string, cluster string, token string, pod corev1.Pod) (*unstructured.Unstructured, error) {
-	clientset, err := t.client.CreateClientSet(ctx, token, url, cluster)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	var containers []any
+	add := func(container podContainer) error {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&container)
+		if err != nil {
+			return fmt.Errorf("failed to convert container %s: %w", container.Name, err)
+		}
+		containers = append(containers, obj)
+		return nil
 	}
-	logs := containerLogs{
-		Logs: make(map[string]any),
+	for _, container := range pod.Spec.InitContainers {
+		if err := add(newPodContainer(container.Name, containerTypeInit, container.Image)); err != nil {
+			return nil, err
+		}
 	}
 	for _, container := range pod.Spec.Containers {
-		podLogOptions := corev1.PodLogOptions{
-			TailLines: ptr.To(podLogsTailLines),
-			Container: container.Name,
-		}
-		req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOptions)
-		podLogs, err := req.Stream(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open log stream: %v", err)
+		if err := add(newPodContainer(container.Name, containerTypeContainer, container.Image)); err != nil {
+			return nil, err
 		}
-		buf := new(bytes.Buffer)
-		_, err = io.Copy(buf, podLogs)
-		if err != nil {
-			return nil, fmt.Errorf("failed to copy log stream to buffer: %v", err)
-		}
-		logs.Logs[container.Name] = buf.String()
-		if err := podLogs.Close(); err != nil {
-			return nil, fmt.Errorf("failed to close pod logs stream: %v", err)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		c := newPodContainer(container.Name, containerTypeEphemeral, container.Image)
+		c.TargetContainer = container.TargetContainerName
+		if err := add(c); err != nil {
+			return nil, err
 		}
 	}
 
-	return &unstructured.Unstructured{Object: map[string]any{"pod-logs": logs.Logs}}, nil
+	return &unstructured.Unstructured{Object: map[string]any{"pod-containers": containers}}, nil
 }
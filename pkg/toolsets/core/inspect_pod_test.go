@@ -1,7 +1,9 @@
 package core
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
@@ -175,6 +177,12 @@ func TestInspectPod(t *testing.T) {
 						},
 						"status": {}
 					},
+					{
+						"pod-containers": [
+							{"name": "nginx", "type": "container", "image": "nginx:1.21", "ready": false, "restartCount": 0},
+							{"name": "sidecar", "type": "container", "image": "busybox:latest", "ready": false, "restartCount": 0}
+						]
+					},
 					{
 						"pod-logs": {
 							"nginx": "fake logs",
@@ -302,6 +310,11 @@ func TestInspectPod(t *testing.T) {
 							"replicas": 0
 						}
 					},
+					{
+						"pod-containers": [
+							{"name": "app", "type": "container", "image": "app:latest", "ready": false, "restartCount": 0}
+						]
+					},
 					{
 						"pod-logs": {
 							"app": "fake logs"
@@ -419,6 +432,11 @@ func TestInspectPod(t *testing.T) {
 							"numberReady": 0
 						}
 					},
+					{
+						"pod-containers": [
+							{"name": "daemon", "type": "container", "image": "daemon:latest", "ready": false, "restartCount": 0}
+						]
+					},
 					{
 						"pod-logs": {
 							"daemon": "fake logs"
@@ -471,3 +489,66 @@ func TestInspectPod(t *testing.T) {
 		})
 	}
 }
+
+func TestInspectPodInitAndEphemeralContainers(t *testing.T) {
+	finished := metav1.NewTime(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+	pod := fakePodForInspect.DeepCopy()
+	pod.Spec.InitContainers = []corev1.Container{
+		{Name: "migrate", Image: "migrate:1.0"},
+		{Name: "wait-db", Image: "busybox:latest"},
+	}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:latest"},
+		TargetContainerName:      "nginx",
+	}}
+	pod.Status = corev1.PodStatus{
+		Phase: corev1.PodPending,
+		InitContainerStatuses: []corev1.ContainerStatus{
+			{
+				Name:                 "migrate",
+				RestartCount:         2,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1, FinishedAt: finished}},
+			},
+			{Name: "wait-db", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "nginx", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+			{Name: "sidecar", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+		},
+		EphemeralContainerStatuses: []corev1.ContainerStatus{
+			{Name: "debugger", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed", FinishedAt: finished}}},
+		},
+	}
+	c := &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return fake.NewClientset(), nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return dynamicfake.NewSimpleDynamicClient(inspectPodScheme(), pod, fakeReplicaSet, fakeDeploymentForInspect), nil
+		},
+	}
+	tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+	result, _, err := tools.inspectPod(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+	}, specificResourceParams{Name: pod.Name, Namespace: pod.Namespace, Cluster: "local"})
+
+	require.NoError(t, err)
+	var mcpResponse struct {
+		LLM []map[string]json.RawMessage `json:"llm"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &mcpResponse))
+	require.Len(t, mcpResponse.LLM, 4)
+	assert.JSONEq(t, `[
+		{"name":"migrate","type":"init","image":"migrate:1.0","state":"waiting: CrashLoopBackOff","ready":false,"restartCount":2,
+			"lastTermination":{"exitCode":1,"reason":"Error","finishedAt":"2025-10-01T12:00:00Z"}},
+		{"name":"wait-db","type":"init","image":"busybox:latest","state":"waiting: PodInitializing","ready":false,"restartCount":0},
+		{"name":"nginx","type":"container","image":"nginx:1.21","state":"waiting: PodInitializing","ready":false,"restartCount":0},
+		{"name":"sidecar","type":"container","image":"busybox:latest","state":"waiting: PodInitializing","ready":false,"restartCount":0},
+		{"name":"debugger","type":"ephemeral","image":"busybox:latest","state":"terminated: Completed","ready":false,"restartCount":0,
+			"exit":{"exitCode":0,"reason":"Completed","finishedAt":"2025-10-01T12:00:00Z"},"targetContainer":"nginx"}]`,
+		string(mcpResponse.LLM[2]["pod-containers"]))
+	// the init containers that haven't started have no logs
+	assert.JSONEq(t, `{"migrate":"fake logs","nginx":"fake logs","sidecar":"fake logs","debugger":"fake logs"}`, string(mcpResponse.LLM[3]["pod-logs"]))
+}
@@ -0,0 +1,313 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// serviceSummary summarizes the endpoints, Pods and Ingresses of a Service and the problems found.
+type serviceSummary struct {
+	Type              string   `json:"type"`
+	Selector          string   `json:"selector,omitempty"`
+	ReadyEndpoints    int      `json:"readyEndpoints"`
+	NotReadyEndpoints int      `json:"notReadyEndpoints"`
+	MatchingPods      int      `json:"matchingPods"`
+	ReadyPods         int      `json:"readyPods"`
+	Ingresses         []string `json:"ingresses"`
+	Issues            []string `json:"issues"`
+}
+
+// inspectService retrieves a Service with its EndpointSlices, the Pods matching its selector and the Ingresses
+// routing traffic to it, and reports selector mismatches, unready endpoints and port mismatches.
+func (t *Tools) inspectService(ctx context.Context, toolReq *mcp.CallToolRequest, params specificResourceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("inspectService called")
+
+	serviceResource, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   params.Cluster,
+		Kind:      "service",
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get service", zap.String("tool", "inspectService"), zap.Error(err))
+		return nil, nil, err
+	}
+	var service corev1.Service
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(serviceResource.Object, &service); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to Service: %w", err)
+	}
+
+	endpointSliceResources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       params.Cluster,
+		Kind:          "endpointslices",
+		Namespace:     params.Namespace,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+		LabelSelector: discoveryv1.LabelServiceName + "=" + params.Name,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get endpointslices", zap.String("tool", "inspectService"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get endpointslices: %w", err)
+	}
+	endpointSlices := make([]discoveryv1.EndpointSlice, 0, len(endpointSliceResources))
+	for _, r := range endpointSliceResources {
+		var endpointSlice discoveryv1.EndpointSlice
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &endpointSlice); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to EndpointSlice: %w", err)
+		}
+		endpointSlices = append(endpointSlices, endpointSlice)
+	}
+
+	var podResources []*unstructured.Unstructured
+	if len(service.Spec.Selector) > 0 {
+		podResources, err = t.client.GetResources(ctx, client.ListParams{
+			Cluster:       params.Cluster,
+			Kind:          "pod",
+			Namespace:     params.Namespace,
+			URL:           toolReq.Extra.Header.Get(urlHeader),
+			Token:         middleware.Token(ctx),
+			LabelSelector: k8slabels.SelectorFromSet(service.Spec.Selector).String(),
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get pods", zap.String("tool", "inspectService"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to get pods: %w", err)
+		}
+	}
+	pods := make([]corev1.Pod, 0, len(podResources))
+	for _, r := range podResources {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+
+	ingressResources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   params.Cluster,
+		Kind:      "ingress",
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get ingresses", zap.String("tool", "inspectService"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get ingresses: %w", err)
+	}
+	var relatedIngressResources []*unstructured.Unstructured
+	var ingresses []networkingv1.Ingress
+	for _, r := range ingressResources {
+		var ingress networkingv1.Ingress
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Object, &ingress); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Ingress: %w", err)
+		}
+		if len(ingressServiceBackends(ingress, service.Name)) > 0 {
+			relatedIngressResources = append(relatedIngressResources, r)
+			ingresses = append(ingresses, ingress)
+		}
+	}
+
+	summary, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newServiceSummary(service, endpointSlices, pods, ingresses))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert service summary: %w", err)
+	}
+
+	resources := append([]*unstructured.Unstructured{serviceResource}, endpointSliceResources...)
+	resources = append(resources, podResources...)
+	resources = append(resources, relatedIngressResources...)
+	if params.IncludeEvents {
+		events, err := t.fetchRelatedEvents(ctx, toolReq, params.Cluster, params.Namespace, slices.Clone(resources))
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get events", zap.String("tool", "inspectService"), zap.Error(err))
+			return nil, nil, err
+		}
+		resources = append(resources, events)
+	}
+	resources = append(resources, &unstructured.Unstructured{Object: map[string]any{"serviceSummary": summary}})
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "inspectService"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// newServiceSummary counts the endpoints and Pods of a Service and lists the problems preventing it from serving
+// traffic.
+func newServiceSummary(service corev1.Service, endpointSlices []discoveryv1.EndpointSlice, pods []corev1.Pod, ingresses []networkingv1.Ingress) *serviceSummary {
+	summary := &serviceSummary{
+		Type:         string(service.Spec.Type),
+		MatchingPods: len(pods),
+		Ingresses:    []string{},
+		Issues:       []string{},
+	}
+	if summary.Type == "" {
+		summary.Type = string(corev1.ServiceTypeClusterIP)
+	}
+	if len(service.Spec.Selector) > 0 {
+		summary.Selector = k8slabels.SelectorFromSet(service.Spec.Selector).String()
+	}
+
+	var notReady []string
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				summary.ReadyEndpoints++
+				continue
+			}
+			summary.NotReadyEndpoints++
+			if endpoint.TargetRef != nil {
+				notReady = append(notReady, endpoint.TargetRef.Name)
+			} else if len(endpoint.Addresses) > 0 {
+				notReady = append(notReady, endpoint.Addresses[0])
+			}
+		}
+	}
+	for _, pod := range pods {
+		if isPodReady(pod) {
+			summary.ReadyPods++
+		}
+	}
+
+	switch {
+	case service.Spec.Type == corev1.ServiceTypeExternalName:
+		// ExternalName Services are resolved by DNS and don't have endpoints
+	case len(service.Spec.Selector) == 0:
+		if summary.ReadyEndpoints == 0 {
+			summary.Issues = append(summary.Issues, "the service doesn't have a selector and no ready endpoints, its EndpointSlices must be created manually")
+		}
+	case len(pods) == 0:
+		summary.Issues = append(summary.Issues, fmt.Sprintf("the selector %s doesn't match any Pod in namespace %s", summary.Selector, service.Namespace))
+	case summary.ReadyPods == 0:
+		summary.Issues = append(summary.Issues, fmt.Sprintf("none of the %d Pods matching the selector are ready", len(pods)))
+	case summary.ReadyEndpoints == 0:
+		summary.Issues = append(summary.Issues, "the service doesn't have ready endpoints although some of its Pods are ready, check the targetPorts")
+	}
+	if summary.NotReadyEndpoints > 0 {
+		summary.Issues = append(summary.Issues, fmt.Sprintf("%d endpoints are not ready: %s", summary.NotReadyEndpoints, strings.Join(notReady, ", ")))
+	}
+	summary.Issues = append(summary.Issues, servicePortIssues(service, pods)...)
+
+	for _, ingress := range ingresses {
+		summary.Ingresses = append(summary.Ingresses, ingress.Name)
+		for _, backend := range ingressServiceBackends(ingress, service.Name) {
+			if !slices.ContainsFunc(service.Spec.Ports, func(p corev1.ServicePort) bool {
+				return (backend.Port.Name != "" && p.Name == backend.Port.Name) || (backend.Port.Name == "" && p.Port == backend.Port.Number)
+			}) {
+				summary.Issues = append(summary.Issues, fmt.Sprintf("ingress %s routes traffic to port %s, which isn't a port of the service", ingress.Name, formatServiceBackendPort(backend.Port)))
+			}
+		}
+	}
+
+	return summary
+}
+
+// servicePortIssues returns the targetPorts of the Service that don't match the ports of the Pods. Numeric
+// targetPorts are only checked when the containers declare their ports, as declaring them is optional.
+func servicePortIssues(service corev1.Service, pods []corev1.Pod) []string {
+	var issues []string
+	for _, servicePort := range service.Spec.Ports {
+		targetPort := servicePort.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt32(servicePort.Port)
+		}
+		protocol := defaultProtocol(servicePort.Protocol)
+		portName := servicePortName(servicePort)
+
+		for _, pod := range pods {
+			var declared []string
+			found := false
+			for _, container := range pod.Spec.Containers {
+				for _, containerPort := range container.Ports {
+					if defaultProtocol(containerPort.Protocol) != protocol {
+						continue
+					}
+					declared = append(declared, fmt.Sprintf("%d/%s", containerPort.ContainerPort, protocol))
+					if (targetPort.Type == intstr.String && containerPort.Name == targetPort.StrVal) ||
+						(targetPort.Type == intstr.Int && containerPort.ContainerPort == targetPort.IntVal) {
+						found = true
+					}
+				}
+			}
+			switch {
+			case found:
+			case targetPort.Type == intstr.String:
+				issues = append(issues, fmt.Sprintf("the targetPort %s of port %s isn't a named port of Pod %s", targetPort.StrVal, portName, pod.Name))
+			case len(declared) > 0:
+				issues = append(issues, fmt.Sprintf("the targetPort %d of port %s isn't declared by Pod %s, which declares %s", targetPort.IntVal, portName, pod.Name, strings.Join(declared, ", ")))
+			default:
+				continue
+			}
+			// report each port once, all the Pods usually share the same template
+			break
+		}
+	}
+
+	return issues
+}
+
+// servicePortName returns the name of a Service port, or its number if it doesn't have a name.
+func servicePortName(port corev1.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return fmt.Sprintf("%d", port.Port)
+}
+
+// ingressServiceBackends returns the backends of an Ingress pointing to the given Service.
+func ingressServiceBackends(ingress networkingv1.Ingress, service string) []networkingv1.IngressServiceBackend {
+	var backends []networkingv1.IngressServiceBackend
+	if b := ingress.Spec.DefaultBackend; b != nil && b.Service != nil && b.Service.Name == service {
+		backends = append(backends, *b.Service)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if b := path.Backend.Service; b != nil && b.Name == service {
+				backends = append(backends, *b)
+			}
+		}
+	}
+
+	return backends
+}
+
+func formatServiceBackendPort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return fmt.Sprintf("%d", port.Number)
+}
+
+// isPodReady returns whether the Ready condition of the Pod is true.
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
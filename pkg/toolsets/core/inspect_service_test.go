@@ -0,0 +1,230 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func serviceScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = discoveryv1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+	return scheme
+}
+
+func newInspectedService(selector map[string]string, targetPort intstr.IntOrString) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: selector,
+			Ports:    []corev1.ServicePort{{Name: "web", Port: 80, TargetPort: targetPort}},
+		},
+	}
+}
+
+func newServicePod(name string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+		},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func newServiceEndpointSlice(pod string, ready bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		TypeMeta: metav1.TypeMeta{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{"10.42.0.10"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "default"},
+		}},
+	}
+}
+
+func newServiceIngress(name string, service string, port networkingv1.ServiceBackendPort) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: "web.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: ptr.To(networkingv1.PathTypePrefix),
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{Name: service, Port: port},
+						},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+func TestInspectService(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	webSelector := map[string]string{"app": "web"}
+
+	tests := map[string]struct {
+		objects         []runtime.Object
+		expectedKinds   []string
+		expectedSummary serviceSummary
+		expectedError   string
+	}{
+		"healthy service": {
+			objects: []runtime.Object{
+				newInspectedService(webSelector, intstr.FromString("http")),
+				newServicePod("web-1", true),
+				newServiceEndpointSlice("web-1", true),
+				newServiceIngress("web", "web", networkingv1.ServiceBackendPort{Name: "web"}),
+				newServiceIngress("other", "other", networkingv1.ServiceBackendPort{Number: 80}),
+			},
+			expectedKinds: []string{"Service", "EndpointSlice", "Pod", "Ingress", ""},
+			expectedSummary: serviceSummary{
+				Type:           "ClusterIP",
+				Selector:       "app=web",
+				ReadyEndpoints: 1,
+				MatchingPods:   1,
+				ReadyPods:      1,
+				Ingresses:      []string{"web"},
+				Issues:         []string{},
+			},
+		},
+		"selector not matching any pod": {
+			objects: []runtime.Object{
+				newInspectedService(map[string]string{"app": "webapp"}, intstr.FromString("http")),
+				newServicePod("web-1", true),
+			},
+			expectedKinds: []string{"Service", ""},
+			expectedSummary: serviceSummary{
+				Type:      "ClusterIP",
+				Selector:  "app=webapp",
+				Ingresses: []string{},
+				Issues:    []string{"the selector app=webapp doesn't match any Pod in namespace default"},
+			},
+		},
+		"unready pods and endpoints": {
+			objects: []runtime.Object{
+				newInspectedService(webSelector, intstr.FromString("http")),
+				newServicePod("web-1", false),
+				newServiceEndpointSlice("web-1", false),
+			},
+			expectedKinds: []string{"Service", "EndpointSlice", "Pod", ""},
+			expectedSummary: serviceSummary{
+				Type:              "ClusterIP",
+				Selector:          "app=web",
+				NotReadyEndpoints: 1,
+				MatchingPods:      1,
+				Ingresses:         []string{},
+				Issues: []string{
+					"none of the 1 Pods matching the selector are ready",
+					"1 endpoints are not ready: web-1",
+				},
+			},
+		},
+		"port mismatches": {
+			objects: []runtime.Object{
+				newInspectedService(webSelector, intstr.FromInt32(9090)),
+				newServicePod("web-1", true),
+				newServiceEndpointSlice("web-1", true),
+				newServiceIngress("web", "web", networkingv1.ServiceBackendPort{Number: 8080}),
+			},
+			expectedKinds: []string{"Service", "EndpointSlice", "Pod", "Ingress", ""},
+			expectedSummary: serviceSummary{
+				Type:           "ClusterIP",
+				Selector:       "app=web",
+				ReadyEndpoints: 1,
+				MatchingPods:   1,
+				ReadyPods:      1,
+				Ingresses:      []string{"web"},
+				Issues: []string{
+					"the targetPort 9090 of port web isn't declared by Pod web-1, which declares 8080/TCP",
+					"ingress web routes traffic to port 8080, which isn't a port of the service",
+				},
+			},
+		},
+		"service without selector": {
+			objects: []runtime.Object{
+				newInspectedService(nil, intstr.FromInt32(8080)),
+			},
+			expectedKinds: []string{"Service", ""},
+			expectedSummary: serviceSummary{
+				Type:      "ClusterIP",
+				Ingresses: []string{},
+				Issues:    []string{"the service doesn't have a selector and no ready endpoints, its EndpointSlices must be created manually"},
+			},
+		},
+		"service not found": {
+			expectedError: `services "web" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(serviceScheme(), test.objects...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.inspectService(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, specificResourceParams{Name: "web", Namespace: "default", Cluster: "local"})
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			items := llmItems(t, result)
+			var kinds []string
+			for _, item := range items {
+				kind, _ := item["kind"].(string)
+				kinds = append(kinds, kind)
+			}
+			assert.Equal(t, test.expectedKinds, kinds)
+
+			summaryJSON, err := json.Marshal(items[len(items)-1]["serviceSummary"])
+			require.NoError(t, err)
+			var summary serviceSummary
+			require.NoError(t, json.Unmarshal(summaryJSON, &summary))
+			assert.Equal(t, test.expectedSummary, summary)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The severities of the lint findings, from the highest to the lowest.
+const (
+	lintHigh   = "high"
+	lintMedium = "medium"
+	lintLow    = "low"
+)
+
+// The checks of lintWorkloads.
+const (
+	lintPrivileged       = "privileged"
+	lintNoRequests       = "no-requests"
+	lintNoLivenessProbe  = "no-liveness-probe"
+	lintNoReadinessProbe = "no-readiness-probe"
+	lintIdenticalProbes  = "identical-probes"
+	lintLatestTag        = "latest-tag"
+	lintNoPDB            = "no-pdb"
+)
+
+// defaultLintedKinds are the kinds of the workloads linted when no kinds are given.
+var defaultLintedKinds = []string{"deployment", "statefulset", "daemonset"}
+
+// longRunningKinds are the kinds of the workloads whose containers are expected to keep running, so they need probes.
+var longRunningKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"}
+
+// suggestedRequests are the requests set by the patches of the no-requests findings, to adjust to the usage of the
+// containers.
+var suggestedRequests = map[string]any{"cpu": "100m", "memory": "128Mi"}
+
+type lintWorkloadsParams struct {
+	Cluster   string   `json:"cluster" jsonschema:"the cluster of the workloads"`
+	Namespace string   `json:"namespace,omitempty" jsonschema:"the namespace of the workloads. Empty for all namespaces"`
+	Kinds     []string `json:"kinds,omitempty" jsonschema:"the kinds of the workloads linted. Defaults to deployment, statefulset and daemonset"`
+}
+
+// workloadLintResult is the response of lintWorkloads, with the workloads having findings.
+type workloadLintResult struct {
+	Cluster string `json:"cluster"`
+	// Linted is the number of workloads linted, including the ones without findings.
+	Linted int `json:"linted"`
+	// Summary is the number of findings of each severity.
+	Summary   map[string]int `json:"summary"`
+	Workloads []workloadLint `json:"workloads"`
+}
+
+// workloadLint is a workload with its findings, sorted by severity.
+type workloadLint struct {
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Findings  []lintFinding `json:"findings"`
+}
+
+// lintFinding is a misconfiguration of a workload. Patch is the JSON patch of the workload fixing it, for
+// patchKubernetesResource, and Resource the resource to create with createKubernetesResource when the fix is a new
+// resource. The values of the patches are starting points to adjust, e.g. the requests to the usage of the containers.
+type lintFinding struct {
+	Check     string         `json:"check"`
+	Severity  string         `json:"severity"`
+	Container string         `json:"container,omitempty"`
+	Message   string         `json:"message"`
+	Patch     []jsonPatch    `json:"patch,omitempty"`
+	Resource  map[string]any `json:"resource,omitempty"`
+}
+
+// lintWorkloads checks the pod templates of the workloads of a cluster for the common misconfigurations of their
+// probes, resources and security context, and the replicated workloads without a PodDisruptionBudget.
+func (t *Tools) lintWorkloads(ctx context.Context, toolReq *mcp.CallToolRequest, params lintWorkloadsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("lintWorkloads called")
+
+	kinds := params.Kinds
+	if len(kinds) == 0 {
+		kinds = defaultLintedKinds
+	}
+	listParams := client.ListParams{
+		Cluster:   params.Cluster,
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}
+	listParams.Kind = "poddisruptionbudget"
+	pdbResources, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list PodDisruptionBudgets", zap.String("tool", "lintWorkloads"), zap.Error(err))
+		return nil, nil, err
+	}
+	pdbs := make([]policyv1.PodDisruptionBudget, len(pdbResources))
+	for i, pdbResource := range pdbResources {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(pdbResource.Object, &pdbs[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to PodDisruptionBudget: %w", err)
+		}
+	}
+
+	result := workloadLintResult{
+		Cluster:   params.Cluster,
+		Summary:   map[string]int{lintHigh: 0, lintMedium: 0, lintLow: 0},
+		Workloads: []workloadLint{},
+	}
+	for _, kind := range kinds {
+		listParams.Kind = strings.ToLower(kind)
+		objs, err := t.client.GetResources(ctx, listParams)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list workloads", zap.String("tool", "lintWorkloads"), zap.String("kind", kind), zap.Error(err))
+			return nil, nil, err
+		}
+		for _, obj := range objs {
+			spec, ok := podSpecOf(obj)
+			if !ok || metav1.GetControllerOf(obj) != nil {
+				continue
+			}
+			result.Linted++
+			findings := lintPodSpec(obj.GetKind(), spec, "/"+strings.Join(podSpecPath(obj.GetKind()), "/"))
+			if finding := lintDisruptionBudget(obj, pdbs); finding != nil {
+				findings = append(findings, *finding)
+			}
+			if len(findings) == 0 {
+				continue
+			}
+			severities := []string{lintHigh, lintMedium, lintLow}
+			slices.SortStableFunc(findings, func(a, b lintFinding) int {
+				return slices.Index(severities, a.Severity) - slices.Index(severities, b.Severity)
+			})
+			for _, finding := range findings {
+				result.Summary[finding.Severity]++
+			}
+			result.Workloads = append(result.Workloads, workloadLint{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Findings: findings})
+		}
+	}
+	slices.SortFunc(result.Workloads, func(a, b workloadLint) int {
+		return strings.Compare(a.Namespace+"/"+a.Kind+"/"+a.Name, b.Namespace+"/"+b.Kind+"/"+b.Name)
+	})
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "lintWorkloads"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// lintPodSpec returns the findings of the containers of a pod template. specPath is the path of the pod spec in the
+// workload, the prefix of the paths of the patches.
+func lintPodSpec(kind string, spec corev1.PodSpec, specPath string) []lintFinding {
+	var findings []lintFinding
+	for i, container := range spec.Containers {
+		path := fmt.Sprintf("%s/containers/%d", specPath, i)
+		if sc := container.SecurityContext; sc != nil && sc.Privileged != nil && *sc.Privileged {
+			findings = append(findings, lintFinding{
+				Check: lintPrivileged, Severity: lintHigh, Container: container.Name,
+				Message: "the container is privileged, it has all the capabilities and the devices of the node, add only the capabilities it needs",
+				Patch:   []jsonPatch{{Op: "replace", Path: path + "/securityContext/privileged", Value: false}},
+			})
+		}
+		if len(container.Resources.Requests) == 0 && len(container.Resources.Limits) == 0 {
+			findings = append(findings, lintFinding{
+				Check: lintNoRequests, Severity: lintMedium, Container: container.Name,
+				Message: "the container has no CPU or memory requests, the scheduler can't reserve resources for it and it's the first evicted under node pressure, set them from its usage (see analyzeResourceUsage)",
+				Patch:   []jsonPatch{{Op: "add", Path: path + "/resources", Value: map[string]any{"requests": suggestedRequests}}},
+			})
+		}
+		if ref := parseImageReference(container.Image); ref.Tag == "latest" && ref.Digest == "" {
+			findings = append(findings, lintFinding{
+				Check: lintLatestTag, Severity: lintLow, Container: container.Name,
+				Message: fmt.Sprintf("the image %s uses the latest tag, the pods may run different versions of the image, pin a version or a digest", container.Image),
+			})
+		}
+		if !slices.Contains(longRunningKinds, kind) {
+			continue
+		}
+
+		switch {
+		case container.LivenessProbe == nil:
+			finding := lintFinding{
+				Check: lintNoLivenessProbe, Severity: lintMedium, Container: container.Name,
+				Message: "the container has no liveness probe, it isn't restarted when it hangs",
+			}
+			if probe := suggestedProbe(container); probe != nil {
+				finding.Patch = []jsonPatch{{Op: "add", Path: path + "/livenessProbe", Value: probe}}
+			}
+			findings = append(findings, finding)
+		case container.ReadinessProbe != nil && equality.Semantic.DeepEqual(container.LivenessProbe, container.ReadinessProbe):
+			failureThreshold := max(container.ReadinessProbe.FailureThreshold, 3) * 2
+			findings = append(findings, lintFinding{
+				Check: lintIdenticalProbes, Severity: lintMedium, Container: container.Name,
+				Message: "the liveness and readiness probes are identical, the container is restarted as soon as it isn't ready (e.g. while a dependency is down), the liveness probe should only check the process and fail later",
+				Patch:   []jsonPatch{{Op: "add", Path: path + "/livenessProbe/failureThreshold", Value: failureThreshold}},
+			})
+		}
+		if container.ReadinessProbe == nil {
+			finding := lintFinding{
+				Check: lintNoReadinessProbe, Severity: lintMedium, Container: container.Name,
+				Message: "the container has no readiness probe, it receives traffic from its Services as soon as it starts",
+			}
+			if probe := suggestedProbe(container); probe != nil {
+				finding.Patch = []jsonPatch{{Op: "add", Path: path + "/readinessProbe", Value: probe}}
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// suggestedProbe returns a TCP probe of the first port of the container, or nil if it has no ports.
+func suggestedProbe(container corev1.Container) map[string]any {
+	if len(container.Ports) == 0 {
+		return nil
+	}
+
+	return map[string]any{
+		"tcpSocket":           map[string]any{"port": container.Ports[0].ContainerPort},
+		"initialDelaySeconds": 10,
+		"periodSeconds":       10,
+	}
+}
+
+// lintDisruptionBudget returns a no-pdb finding for the Deployments and StatefulSets with more than one replica
+// without a PodDisruptionBudget selecting their pods, whose pods can all be evicted at once by a node drain.
+func lintDisruptionBudget(obj *unstructured.Unstructured, pdbs []policyv1.PodDisruptionBudget) *lintFinding {
+	if obj.GetKind() != "Deployment" && obj.GetKind() != "StatefulSet" {
+		return nil
+	}
+	replicas, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !ok || replicas < 2 {
+		return nil
+	}
+	templateLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "labels")
+	for _, pdb := range pdbs {
+		if pdb.Namespace != obj.GetNamespace() {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err == nil && !selector.Empty() && selector.Matches(labels.Set(templateLabels)) {
+			return nil
+		}
+	}
+
+	matchLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+	return &lintFinding{
+		Check:    lintNoPDB,
+		Severity: lintLow,
+		Message:  fmt.Sprintf("the %d replicas have no PodDisruptionBudget, a node drain can evict all of them at once", replicas),
+		Resource: map[string]any{
+			"apiVersion": "policy/v1",
+			"kind":       "PodDisruptionBudget",
+			"metadata":   map[string]any{"name": obj.GetName(), "namespace": obj.GetNamespace()},
+			"spec": map[string]any{
+				"maxUnavailable": 1,
+				"selector":       map[string]any{"matchLabels": matchLabels},
+			},
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func newLintDeployment(name string, namespace string, replicas int32, container corev1.Container) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+			},
+		},
+	}
+}
+
+func TestLintWorkloads(t *testing.T) {
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(8080)}}}
+	requests := corev1.ResourceRequirements{Requests: resourceList("100m", "64Mi")}
+	objects := []runtime.Object{
+		newLintDeployment("web", "shop", 3, corev1.Container{
+			Name:            "web",
+			Image:           "nginx",
+			Ports:           []corev1.ContainerPort{{ContainerPort: 8080}},
+			SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)},
+		}),
+		newLintDeployment("api", "shop", 2, corev1.Container{
+			Name:           "api",
+			Image:          "registry.example.com/api:1.2",
+			Resources:      requests,
+			LivenessProbe:  probe,
+			ReadinessProbe: probe,
+		}),
+		newLintDeployment("worker", "jobs", 1, corev1.Container{
+			Name:           "worker",
+			Image:          "worker:2.0",
+			Resources:      requests,
+			LivenessProbe:  &corev1.Probe{ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}},
+			ReadinessProbe: probe,
+		}),
+		&policyv1.PodDisruptionBudget{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "policy/v1", Kind: "PodDisruptionBudget"},
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+		},
+		&batchv1.CronJob{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "jobs"},
+			Spec: batchv1.CronJobSpec{
+				Schedule: "0 * * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "report", Image: "report:latest", Resources: requests}}},
+				}}},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		params         lintWorkloadsParams
+		expectedResult string
+	}{
+		"all namespaces": {
+			params: lintWorkloadsParams{Cluster: "local"},
+			expectedResult: `{"cluster":"local","linted":3,"summary":{"high":1,"medium":4,"low":2},"workloads":[
+				{"kind":"Deployment","namespace":"shop","name":"api","findings":[
+					{"check":"identical-probes","severity":"medium","container":"api","message":"the liveness and readiness probes are identical, the container is restarted as soon as it isn't ready (e.g. while a dependency is down), the liveness probe should only check the process and fail later",
+						"patch":[{"op":"add","path":"/spec/template/spec/containers/0/livenessProbe/failureThreshold","value":6}]}]},
+				{"kind":"Deployment","namespace":"shop","name":"web","findings":[
+					{"check":"privileged","severity":"high","container":"web","message":"the container is privileged, it has all the capabilities and the devices of the node, add only the capabilities it needs",
+						"patch":[{"op":"replace","path":"/spec/template/spec/containers/0/securityContext/privileged","value":false}]},
+					{"check":"no-requests","severity":"medium","container":"web","message":"the container has no CPU or memory requests, the scheduler can't reserve resources for it and it's the first evicted under node pressure, set them from its usage (see analyzeResourceUsage)",
+						"patch":[{"op":"add","path":"/spec/template/spec/containers/0/resources","value":{"requests":{"cpu":"100m","memory":"128Mi"}}}]},
+					{"check":"no-liveness-probe","severity":"medium","container":"web","message":"the container has no liveness probe, it isn't restarted when it hangs",
+						"patch":[{"op":"add","path":"/spec/template/spec/containers/0/livenessProbe","value":{"tcpSocket":{"port":8080},"initialDelaySeconds":10,"periodSeconds":10}}]},
+					{"check":"no-readiness-probe","severity":"medium","container":"web","message":"the container has no readiness probe, it receives traffic from its Services as soon as it starts",
+						"patch":[{"op":"add","path":"/spec/template/spec/containers/0/readinessProbe","value":{"tcpSocket":{"port":8080},"initialDelaySeconds":10,"periodSeconds":10}}]},
+					{"check":"latest-tag","severity":"low","container":"web","message":"the image nginx uses the latest tag, the pods may run different versions of the image, pin a version or a digest"},
+					{"check":"no-pdb","severity":"low","message":"the 3 replicas have no PodDisruptionBudget, a node drain can evict all of them at once",
+						"resource":{"apiVersion":"policy/v1","kind":"PodDisruptionBudget","metadata":{"name":"web","namespace":"shop"},"spec":{"maxUnavailable":1,"selector":{"matchLabels":{"app":"web"}}}}}]}]}`,
+		},
+		"cronjobs of a namespace": {
+			params: lintWorkloadsParams{Cluster: "local", Namespace: "jobs", Kinds: []string{"CronJob", "deployment"}},
+			expectedResult: `{"cluster":"local","linted":2,"summary":{"high":0,"medium":0,"low":1},"workloads":[
+				{"kind":"CronJob","namespace":"jobs","name":"report","findings":[
+					{"check":"latest-tag","severity":"low","container":"report","message":"the image report:latest uses the latest tag, the pods may run different versions of the image, pin a version or a digest"}]}]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+			_ = batchv1.AddToScheme(scheme)
+			_ = policyv1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme, objects...)
+			c := &client.Client{
+				DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+			result, _, err := tools.lintWorkloads(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
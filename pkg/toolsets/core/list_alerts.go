@@ -0,0 +1,164 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// alertmanagerService and alertmanagerPort identify the Alertmanager installed by the rancher-monitoring chart.
+	alertmanagerService = "rancher-monitoring-alertmanager"
+	alertmanagerPort    = "9093"
+	// alertmanagerTimeout is the maximum time allowed for an Alertmanager request.
+	alertmanagerTimeout = 30 * time.Second
+)
+
+// severityOrder sorts the alerts from the most to the least severe, unknown severities last.
+var severityOrder = []string{"critical", "high", "warning", "medium", "low", "info", "none"}
+
+type listAlertsParams struct {
+	Cluster         string `json:"cluster" jsonschema:"the cluster of the Alertmanager"`
+	Severity        string `json:"severity,omitempty" jsonschema:"only list the alerts with this severity label (e.g. critical, warning)"`
+	Namespace       string `json:"namespace,omitempty" jsonschema:"only list the alerts with this namespace label"`
+	IncludeSilenced bool   `json:"includeSilenced,omitempty" jsonschema:"include the silenced and inhibited alerts"`
+}
+
+// alertmanagerAlert is an alert returned by the v2 API of Alertmanager.
+type alertmanagerAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`
+	EndsAt       string            `json:"endsAt"`
+	UpdatedAt    string            `json:"updatedAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []struct {
+		Name string `json:"name"`
+	} `json:"receivers"`
+	Status struct {
+		State       string   `json:"state"`
+		SilencedBy  []string `json:"silencedBy"`
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+// alertSummary is an alert in the response of listAlerts.
+type alertSummary struct {
+	Fingerprint string `json:"fingerprint"`
+	Name        string `json:"name"`
+	Severity    string `json:"severity,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	State       string `json:"state"`
+	StartsAt    string `json:"startsAt"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+// listAlerts returns the alerts firing in the Alertmanager of rancher-monitoring, the most severe first.
+func (t *Tools) listAlerts(ctx context.Context, toolReq *mcp.CallToolRequest, params listAlertsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listAlerts called")
+
+	query := url.Values{
+		"active":    {"true"},
+		"silenced":  {strconv.FormatBool(params.IncludeSilenced)},
+		"inhibited": {strconv.FormatBool(params.IncludeSilenced)},
+	}
+	if params.Severity != "" {
+		query.Add("filter", "severity="+strconv.Quote(params.Severity))
+	}
+	if params.Namespace != "" {
+		query.Add("filter", "namespace="+strconv.Quote(params.Namespace))
+	}
+	alerts, err := t.getAlerts(ctx, toolReq, params.Cluster, query)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get alerts", zap.String("tool", "listAlerts"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	summaries := make([]alertSummary, 0, len(alerts))
+	for _, alert := range alerts {
+		summaries = append(summaries, alertSummary{
+			Fingerprint: alert.Fingerprint,
+			Name:        alert.Labels["alertname"],
+			Severity:    alert.Labels["severity"],
+			Namespace:   alert.Labels["namespace"],
+			State:       alert.Status.State,
+			StartsAt:    alert.StartsAt,
+			Summary:     cmp.Or(alert.Annotations["summary"], alert.Annotations["message"], alert.Annotations["description"]),
+		})
+	}
+	slices.SortStableFunc(summaries, func(a, b alertSummary) int {
+		return cmp.Or(cmp.Compare(severityRank(a.Severity), severityRank(b.Severity)), cmp.Compare(a.StartsAt, b.StartsAt))
+	})
+
+	response, err := json.Marshal(summaries)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listAlerts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// getAlerts returns the alerts of the Alertmanager of rancher-monitoring matching the query of the v2 API.
+func (t *Tools) getAlerts(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, query url.Values) ([]alertmanagerAlert, error) {
+	rancherURL := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	if err := t.checkMonitoringService(ctx, cluster, rancherURL, token, alertmanagerService); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, alertmanagerTimeout)
+	defer cancel()
+	res, err := t.client.ProxyGet(ctx, client.ProxyGetParams{
+		Cluster:   cluster,
+		Kind:      "service",
+		Namespace: monitoringNamespace,
+		Name:      alertmanagerService,
+		Scheme:    "http",
+		Port:      alertmanagerPort,
+		Path:      "/api/v2/alerts?" + query.Encode(),
+		URL:       rancherURL,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alertmanager response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("alertmanager request failed with status %s: %s", res.Status, truncate(string(body), probeMaxBodyBytes))
+	}
+	var alerts []alertmanagerAlert
+	if err := json.Unmarshal(body, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to parse alertmanager response: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func severityRank(severity string) int {
+	if i := slices.Index(severityOrder, severity); i >= 0 {
+		return i
+	}
+
+	return len(severityOrder)
+}
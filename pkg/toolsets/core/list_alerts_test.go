@@ -0,0 +1,127 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const fakeAlerts = `[
+	{"fingerprint":"a1","labels":{"alertname":"KubePodNotReady","severity":"warning","namespace":"default"},"annotations":{"description":"Pod default/nginx has been in a non-ready state for longer than 15 minutes."},"startsAt":"2024-05-01T10:00:00Z","receivers":[{"name":"null"}],"status":{"state":"active","silencedBy":[],"inhibitedBy":[]}},
+	{"fingerprint":"b2","labels":{"alertname":"KubePodCrashLooping","severity":"critical","namespace":"default"},"annotations":{"summary":"Pod is crash looping."},"startsAt":"2024-05-01T11:00:00Z","generatorURL":"http://prometheus/graph","receivers":[{"name":"slack"}],"status":{"state":"active","silencedBy":[],"inhibitedBy":[]}},
+	{"fingerprint":"c3","labels":{"alertname":"Watchdog","severity":"none"},"annotations":{"message":"This is an alert meant to ensure that the entire alerting pipeline is functional."},"startsAt":"2024-05-01T09:00:00Z","receivers":[{"name":"null"}],"status":{"state":"active","silencedBy":[],"inhibitedBy":[]}}
+]`
+
+var fakeAlertmanagerService = &corev1.Service{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      alertmanagerService,
+		Namespace: monitoringNamespace,
+	},
+}
+
+const alertmanagerProxyPath = "/k8s/clusters/local/api/v1/namespaces/cattle-monitoring-system/services/http:rancher-monitoring-alertmanager:9093/proxy"
+
+// newFakeAlertmanager returns a fake Rancher server proxying the requests to the Alertmanager of the local cluster.
+// The queries of the alerts requests and the bodies of the silences requests are sent to the channels.
+func newFakeAlertmanager(t *testing.T, fakeToken string, queries chan<- string, silences chan<- []byte) *httptest.Server {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == alertmanagerProxyPath+"/api/v2/alerts":
+			queries <- r.URL.RawQuery
+			w.Write([]byte(fakeAlerts))
+		case r.Method == http.MethodPost && r.URL.Path == alertmanagerProxyPath+"/api/v2/silences":
+			body, _ := io.ReadAll(r.Body)
+			silences <- body
+			w.Write([]byte(`{"silenceID":"7b7e3b4c-1f0a-4a3c-9d2e-5f1b2c3d4e5f"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newFakeProxyTools(fakeToken string, objects ...runtime.Object) Tools {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), objects...)
+	c := client.NewClient(true)
+	c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+
+	return Tools{client: newFakeToolsClient(c, fakeToken)}
+}
+
+func TestListAlerts(t *testing.T) {
+	fakeToken := "fakeToken"
+	queries := make(chan string, 1)
+	srv := newFakeAlertmanager(t, fakeToken, queries, nil)
+
+	tests := map[string]struct {
+		params         listAlertsParams
+		objects        []runtime.Object
+		expectedQuery  string
+		expectedResult string
+		expectedError  string
+	}{
+		"firing alerts sorted by severity": {
+			params:         listAlertsParams{Cluster: "local"},
+			objects:        []runtime.Object{fakeAlertmanagerService},
+			expectedQuery:  "active=true&inhibited=false&silenced=false",
+			expectedResult: `[{"fingerprint":"b2","name":"KubePodCrashLooping","severity":"critical","namespace":"default","state":"active","startsAt":"2024-05-01T11:00:00Z","summary":"Pod is crash looping."},{"fingerprint":"a1","name":"KubePodNotReady","severity":"warning","namespace":"default","state":"active","startsAt":"2024-05-01T10:00:00Z","summary":"Pod default/nginx has been in a non-ready state for longer than 15 minutes."},{"fingerprint":"c3","name":"Watchdog","severity":"none","state":"active","startsAt":"2024-05-01T09:00:00Z","summary":"This is an alert meant to ensure that the entire alerting pipeline is functional."}]`,
+		},
+		"filters": {
+			params:        listAlertsParams{Cluster: "local", Severity: "critical", Namespace: "default", IncludeSilenced: true},
+			objects:       []runtime.Object{fakeAlertmanagerService},
+			expectedQuery: "active=true&filter=severity%3D%22critical%22&filter=namespace%3D%22default%22&inhibited=true&silenced=true",
+		},
+		"monitoring not installed": {
+			params:        listAlertsParams{Cluster: "local"},
+			expectedError: "rancher-monitoring is not installed in cluster local, the cattle-monitoring-system/rancher-monitoring-alertmanager Service was not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newFakeProxyTools(fakeToken, test.objects...)
+
+			result, _, err := tools.listAlerts(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedQuery, <-queries)
+			if test.expectedResult != "" {
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
+
+func TestSeverityRank(t *testing.T) {
+	assert.Less(t, severityRank("critical"), severityRank("warning"))
+	assert.Less(t, severityRank("none"), severityRank("unknown"))
+	assert.Equal(t, severityRank(""), severityRank("unknown"))
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type listCRDsParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster where the CRDs are listed"`
+	Group   string `json:"group,omitempty" jsonschema:"only return the CRDs of this API group or its subgroups (e.g. cattle.io)"`
+}
+
+// crdSummary describes a CustomResourceDefinition installed in a cluster.
+type crdSummary struct {
+	Name       string   `json:"name"`
+	Group      string   `json:"group"`
+	Kind       string   `json:"kind"`
+	Plural     string   `json:"plural"`
+	ShortNames []string `json:"shortNames,omitempty"`
+	Scope      string   `json:"scope"`
+	// Versions are the served versions of the CRD.
+	Versions       []string `json:"versions"`
+	StorageVersion string   `json:"storageVersion"`
+}
+
+// listCustomResourceDefinitions lists the CRDs installed in a cluster with their group, kind, scope and served versions.
+func (t *Tools) listCustomResourceDefinitions(ctx context.Context, toolReq *mcp.CallToolRequest, params listCRDsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listCustomResourceDefinitions called")
+
+	objs, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: params.Cluster,
+		Kind:    "crd",
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list CRDs", zap.String("tool", "listCustomResourceDefinitions"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	crds := []crdSummary{}
+	for _, obj := range objs {
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &crd); err != nil {
+			middleware.Logger(ctx).Error("failed to convert unstructured object to CRD", zap.String("tool", "listCustomResourceDefinitions"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to CRD: %w", err)
+		}
+		if params.Group != "" && crd.Spec.Group != params.Group && !strings.HasSuffix(crd.Spec.Group, "."+params.Group) {
+			continue
+		}
+		crds = append(crds, newCRDSummary(&crd))
+	}
+	slices.SortFunc(crds, func(a, b crdSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	response, err := json.Marshal(crds)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listCustomResourceDefinitions"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+func newCRDSummary(crd *apiextensionsv1.CustomResourceDefinition) crdSummary {
+	summary := crdSummary{
+		Name:       crd.Name,
+		Group:      crd.Spec.Group,
+		Kind:       crd.Spec.Names.Kind,
+		Plural:     crd.Spec.Names.Plural,
+		ShortNames: crd.Spec.Names.ShortNames,
+		Scope:      string(crd.Spec.Scope),
+		Versions:   []string{},
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			summary.Versions = append(summary.Versions, version.Name)
+		}
+		if version.Storage {
+			summary.StorageVersion = version.Name
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+// fakeCertificateCRD is served at v1 and v1beta1, v1 being the storage version.
+var fakeCertificateCRD = &apiextensionsv1.CustomResourceDefinition{
+	TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+	ObjectMeta: metav1.ObjectMeta{Name: "certificates.cert-manager.io"},
+	Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+		Group: "cert-manager.io",
+		Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Certificate", Plural: "certificates", ShortNames: []string{"cert"}},
+		Scope: apiextensionsv1.NamespaceScoped,
+		Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+			{
+				Name:    "v1beta1",
+				Served:  true,
+				Storage: false,
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Type:       "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{"spec": {Type: "object"}},
+				}},
+			},
+			{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				AdditionalPrinterColumns: []apiextensionsv1.CustomResourceColumnDefinition{
+					{Name: "Ready", Type: "string", JSONPath: `.status.conditions[?(@.type=="Ready")].status`},
+				},
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"apiVersion": {Type: "string"},
+						"kind":       {Type: "string"},
+						"metadata":   {Type: "object"},
+						"spec": {
+							Type:     "object",
+							Required: []string{"secretName"},
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"secretName": {Type: "string", Description: "Name of the Secret resource that will be created."},
+								"dnsNames":   {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}}},
+								"privateKey": {
+									Type: "object",
+									Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										"algorithm": {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"RSA"`)}, {Raw: []byte(`"ECDSA"`)}}},
+										"size":      {XIntOrString: true},
+									},
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
+	},
+}
+
+var fakeFleetCRD = &apiextensionsv1.CustomResourceDefinition{
+	TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+	ObjectMeta: metav1.ObjectMeta{Name: "gitrepos.fleet.cattle.io"},
+	Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+		Group: "fleet.cattle.io",
+		Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "GitRepo", Plural: "gitrepos"},
+		Scope: apiextensionsv1.NamespaceScoped,
+		Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+			{Name: "v1alpha1", Served: true, Storage: true},
+		},
+	},
+}
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// newCRDFakeDynClient returns a fake dynamic client with the given CRDs and custom resources.
+func newCRDFakeDynClient(t *testing.T, crds []*apiextensionsv1.CustomResourceDefinition, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	for _, crd := range crds {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+		require.NoError(t, err)
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+	}
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdGVR:         "CustomResourceDefinitionList",
+		certificateGVR: "CertificateList",
+	}, objects...)
+}
+
+func newCRDTools(fakeDynClient dynamic.Interface, fakeToken string) Tools {
+	return Tools{client: newFakeToolsClient(&client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}, fakeToken)}
+}
+
+func TestListCustomResourceDefinitions(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	tests := map[string]struct {
+		params         listCRDsParams
+		expectedResult string
+	}{
+		"all CRDs": {
+			params: listCRDsParams{Cluster: "local"},
+			expectedResult: `[
+				{"name": "certificates.cert-manager.io", "group": "cert-manager.io", "kind": "Certificate", "plural": "certificates", "shortNames": ["cert"], "scope": "Namespaced", "versions": ["v1beta1", "v1"], "storageVersion": "v1"},
+				{"name": "gitrepos.fleet.cattle.io", "group": "fleet.cattle.io", "kind": "GitRepo", "plural": "gitrepos", "scope": "Namespaced", "versions": ["v1alpha1"], "storageVersion": "v1alpha1"}
+			]`,
+		},
+		"CRDs of a parent group": {
+			params: listCRDsParams{Cluster: "local", Group: "cattle.io"},
+			expectedResult: `[
+				{"name": "gitrepos.fleet.cattle.io", "group": "fleet.cattle.io", "kind": "GitRepo", "plural": "gitrepos", "scope": "Namespaced", "versions": ["v1alpha1"], "storageVersion": "v1alpha1"}
+			]`,
+		},
+		"no CRDs of the group": {
+			params:         listCRDsParams{Cluster: "local", Group: "istio.io"},
+			expectedResult: `[]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newCRDTools(newCRDFakeDynClient(t, []*apiextensionsv1.CustomResourceDefinition{fakeFleetCRD, fakeCertificateCRD}), fakeToken)
+
+			result, _, err := tools.listCustomResourceDefinitions(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
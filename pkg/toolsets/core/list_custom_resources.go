@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type listCustomResourcesParams struct {
+	Cluster       string `json:"cluster" jsonschema:"the cluster of the CRD"`
+	Name          string `json:"name" jsonschema:"the name of the CRD (e.g. certificates.cert-manager.io)"`
+	Namespace     string `json:"namespace,omitempty" jsonschema:"the namespace of the resources. Empty for all namespaces or cluster scoped CRDs"`
+	LabelSelector string `json:"labelSelector,omitempty" jsonschema:"only return resources matching this label selector"`
+}
+
+// listCustomResources lists the instances of a CRD, using the storage version of the CRD, or its first served version
+// if the storage version is no longer served.
+func (t *Tools) listCustomResources(ctx context.Context, toolReq *mcp.CallToolRequest, params listCustomResourcesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listCustomResources called")
+
+	crd, err := t.getCRD(ctx, toolReq, params.Cluster, params.Name)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get CRD", zap.String("tool", "listCustomResources"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var version string
+	for _, v := range crd.Spec.Versions {
+		if v.Served && (version == "" || v.Storage) {
+			version = v.Name
+		}
+	}
+	if version == "" {
+		return nil, nil, fmt.Errorf("CRD %s has no served versions", params.Name)
+	}
+
+	gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: version, Resource: crd.Spec.Names.Plural}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := resourceInterface.List(ctx, metav1.ListOptions{LabelSelector: params.LabelSelector})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list custom resources", zap.String("tool", "listCustomResources"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	objs := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		objs[i] = &list.Items[i]
+	}
+	mcpResponse, err := response.CreateMcpResponse(ctx, objs, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listCustomResources"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
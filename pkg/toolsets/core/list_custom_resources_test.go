@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func fakeCertificate(name string, namespace string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]any{"name": name, "namespace": namespace, "labels": map[string]any{"app": name}},
+		"spec":       map[string]any{"secretName": name + "-tls"},
+	}}
+}
+
+func TestListCustomResources(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	unservedCRD := fakeFleetCRD.DeepCopy()
+	unservedCRD.Spec.Versions[0].Served = false
+
+	tests := map[string]struct {
+		params         listCustomResourcesParams
+		crds           []*apiextensionsv1.CustomResourceDefinition
+		expectedResult string
+		expectedError  string
+	}{
+		"all namespaces": {
+			params: listCustomResourcesParams{Cluster: "local", Name: "certificates.cert-manager.io"},
+			crds:   []*apiextensionsv1.CustomResourceDefinition{fakeCertificateCRD},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "cert-manager.io/v1", "kind": "Certificate", "metadata": {"name": "api", "namespace": "default", "labels": {"app": "api"}}, "spec": {"secretName": "api-tls"}},
+					{"apiVersion": "cert-manager.io/v1", "kind": "Certificate", "metadata": {"name": "web", "namespace": "web", "labels": {"app": "web"}}, "spec": {"secretName": "web-tls"}}
+				],
+				"uiContext": [
+					{"namespace": "default", "kind": "Certificate", "cluster": "local", "name": "api", "type": "certificate"},
+					{"namespace": "web", "kind": "Certificate", "cluster": "local", "name": "web", "type": "certificate"}
+				]
+			}`,
+		},
+		"namespace and label selector": {
+			params: listCustomResourcesParams{Cluster: "local", Name: "certificates.cert-manager.io", Namespace: "web", LabelSelector: "app=web"},
+			crds:   []*apiextensionsv1.CustomResourceDefinition{fakeCertificateCRD},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "cert-manager.io/v1", "kind": "Certificate", "metadata": {"name": "web", "namespace": "web", "labels": {"app": "web"}}, "spec": {"secretName": "web-tls"}}
+				],
+				"uiContext": [
+					{"namespace": "web", "kind": "Certificate", "cluster": "local", "name": "web", "type": "certificate"}
+				]
+			}`,
+		},
+		"no served versions": {
+			params:        listCustomResourcesParams{Cluster: "local", Name: "gitrepos.fleet.cattle.io"},
+			crds:          []*apiextensionsv1.CustomResourceDefinition{unservedCRD},
+			expectedError: "CRD gitrepos.fleet.cattle.io has no served versions",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newCRDTools(newCRDFakeDynClient(t, test.crds, fakeCertificate("api", "default"), fakeCertificate("web", "web")), fakeToken)
+
+			result, _, err := tools.listCustomResources(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
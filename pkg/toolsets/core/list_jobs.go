@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	jobRunning   = "Running"
+	jobComplete  = "Complete"
+	jobFailed    = "Failed"
+	jobSuspended = "Suspended"
+	jobPending   = "Pending"
+)
+
+type listJobsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Jobs"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the Jobs, all namespaces if empty"`
+	CronJob   string `json:"cronJob,omitempty" jsonschema:"only list this CronJob and its Jobs"`
+}
+
+// batchWorkloads contains the CronJobs and the Jobs of a cluster.
+type batchWorkloads struct {
+	CronJobs []cronJobSummary `json:"cronJobs"`
+	Jobs     []jobSummary     `json:"jobs"`
+}
+
+// cronJobSummary describes the schedule of a CronJob and the status of its last run.
+type cronJobSummary struct {
+	Name               string      `json:"name"`
+	Namespace          string      `json:"namespace"`
+	Schedule           string      `json:"schedule"`
+	TimeZone           string      `json:"timeZone,omitempty"`
+	Suspended          bool        `json:"suspended"`
+	ConcurrencyPolicy  string      `json:"concurrencyPolicy"`
+	Active             []string    `json:"active,omitempty"`
+	LastScheduleTime   string      `json:"lastScheduleTime,omitempty"`
+	LastSuccessfulTime string      `json:"lastSuccessfulTime,omitempty"`
+	LastJob            *jobSummary `json:"lastJob,omitempty"`
+}
+
+// jobSummary describes the status of a Job. Status is one of Running, Complete, Failed, Suspended or Pending.
+type jobSummary struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	CronJob        string `json:"cronJob,omitempty"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason,omitempty"`
+	Message        string `json:"message,omitempty"`
+	Completions    int32  `json:"completions"`
+	Active         int32  `json:"active"`
+	Succeeded      int32  `json:"succeeded"`
+	Failed         int32  `json:"failed"`
+	StartTime      string `json:"startTime,omitempty"`
+	CompletionTime string `json:"completionTime,omitempty"`
+}
+
+// listJobs returns the CronJobs and the Jobs of a cluster with the status of their last run, the newest Jobs first.
+func (t *Tools) listJobs(ctx context.Context, toolReq *mcp.CallToolRequest, params listJobsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listJobs called")
+
+	cronJobs, jobs, err := t.batchWorkloads(ctx, toolReq, params.Cluster, params.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list Jobs", zap.String("tool", "listJobs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := batchWorkloads{CronJobs: []cronJobSummary{}, Jobs: []jobSummary{}}
+	for _, cronJob := range cronJobs {
+		if params.CronJob == "" || cronJob.Name == params.CronJob {
+			result.CronJobs = append(result.CronJobs, newCronJobSummary(cronJob, jobs))
+		}
+	}
+	for _, job := range jobs {
+		if params.CronJob == "" || jobCronJob(job) == params.CronJob {
+			result.Jobs = append(result.Jobs, newJobSummary(job))
+		}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listJobs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// batchWorkloads returns the CronJobs and the Jobs of a namespace, or of all namespaces if it's empty. The Jobs are
+// sorted from the newest to the oldest.
+func (t *Tools) batchWorkloads(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, namespace string) ([]batchv1.CronJob, []batchv1.Job, error) {
+	listParams := client.ListParams{
+		Cluster:   cluster,
+		Kind:      "cronjob",
+		Namespace: namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}
+	unstructuredCronJobs, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list CronJobs: %w", err)
+	}
+	listParams.Kind = "job"
+	unstructuredJobs, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list Jobs: %w", err)
+	}
+
+	cronJobs := make([]batchv1.CronJob, len(unstructuredCronJobs))
+	for i, unstructuredCronJob := range unstructuredCronJobs {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredCronJob.Object, &cronJobs[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to CronJob: %w", err)
+		}
+	}
+	jobs := make([]batchv1.Job, len(unstructuredJobs))
+	for i, unstructuredJob := range unstructuredJobs {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredJob.Object, &jobs[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Job: %w", err)
+		}
+	}
+	slices.SortStableFunc(jobs, func(a, b batchv1.Job) int {
+		return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+	})
+
+	return cronJobs, jobs, nil
+}
+
+// newCronJobSummary returns the schedule of a CronJob and its last Job, found in jobs sorted from the newest.
+func newCronJobSummary(cronJob batchv1.CronJob, jobs []batchv1.Job) cronJobSummary {
+	summary := cronJobSummary{
+		Name:               cronJob.Name,
+		Namespace:          cronJob.Namespace,
+		Schedule:           cronJob.Spec.Schedule,
+		Suspended:          cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		ConcurrencyPolicy:  string(cronJob.Spec.ConcurrencyPolicy),
+		LastScheduleTime:   formatTime(cronJob.Status.LastScheduleTime),
+		LastSuccessfulTime: formatTime(cronJob.Status.LastSuccessfulTime),
+	}
+	if summary.ConcurrencyPolicy == "" {
+		summary.ConcurrencyPolicy = string(batchv1.AllowConcurrent)
+	}
+	if cronJob.Spec.TimeZone != nil {
+		summary.TimeZone = *cronJob.Spec.TimeZone
+	}
+	for _, active := range cronJob.Status.Active {
+		summary.Active = append(summary.Active, active.Name)
+	}
+	for _, job := range jobs {
+		if job.Namespace == cronJob.Namespace && jobCronJob(job) == cronJob.Name {
+			lastJob := newJobSummary(job)
+			summary.LastJob = &lastJob
+			break
+		}
+	}
+
+	return summary
+}
+
+// newJobSummary returns the status of a Job, with the reason and the message of its Failed condition.
+func newJobSummary(job batchv1.Job) jobSummary {
+	summary := jobSummary{
+		Name:           job.Name,
+		Namespace:      job.Namespace,
+		CronJob:        jobCronJob(job),
+		Completions:    1,
+		Active:         job.Status.Active,
+		Succeeded:      job.Status.Succeeded,
+		Failed:         job.Status.Failed,
+		StartTime:      formatTime(job.Status.StartTime),
+		CompletionTime: formatTime(job.Status.CompletionTime),
+	}
+	if job.Spec.Completions != nil {
+		summary.Completions = *job.Spec.Completions
+	}
+
+	failed := jobCondition(job, batchv1.JobFailed)
+	switch {
+	case failed != nil:
+		summary.Status, summary.Reason, summary.Message = jobFailed, failed.Reason, failed.Message
+	case jobCondition(job, batchv1.JobComplete) != nil:
+		summary.Status = jobComplete
+	case job.Spec.Suspend != nil && *job.Spec.Suspend:
+		summary.Status = jobSuspended
+	case job.Status.Active > 0:
+		summary.Status = jobRunning
+	default:
+		summary.Status = jobPending
+	}
+
+	return summary
+}
+
+// jobCondition returns the condition of the Job with the given type when it's true, or nil.
+func jobCondition(job batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// jobCronJob returns the name of the CronJob that created the Job, or an empty string.
+func jobCronJob(job batchv1.Job) string {
+	if owner := metav1.GetControllerOfNoCopy(&job); owner != nil && owner.Kind == "CronJob" {
+		return owner.Name
+	}
+
+	return ""
+}
+
+func formatTime(t *metav1.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+// newBatchTools returns Tools using a fake dynamic client with the given CronJobs, Jobs and Pods, and a fake clientset
+// for their logs.
+func newBatchTools(objects ...runtime.Object) (*Tools, *dynamicfake.FakeDynamicClient) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		{Group: "batch", Version: "v1", Resource: "cronjobs"}: "CronJobList",
+		{Group: "batch", Version: "v1", Resource: "jobs"}:     "JobList",
+		{Group: "", Version: "v1", Resource: "pods"}:          "PodList",
+	}, objects...)
+	c := &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return fake.NewClientset(), nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+
+	return &Tools{client: newFakeToolsClient(c, "fakeToken")}, fakeDynClient
+}
+
+func newCronJob(name string, suspended bool, active ...string) *batchv1.CronJob {
+	cronJob := &batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: "cron-uid"},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          "0 * * * *",
+			Suspend:           ptr.To(suspended),
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}, Annotations: map[string]string{"team": "data"}},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To(int32(2)),
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers:    []corev1.Container{{Name: "backup", Image: "backup:1"}},
+					}},
+				},
+			},
+		},
+		Status: batchv1.CronJobStatus{LastScheduleTime: ptr.To(metav1.NewTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))},
+	}
+	for _, job := range active {
+		cronJob.Status.Active = append(cronJob.Status.Active, corev1.ObjectReference{Kind: "Job", Name: job, Namespace: "default"})
+	}
+
+	return cronJob
+}
+
+// newJob returns a Job of the CronJob, if it's not empty, created at the given hour with the given condition.
+func newJob(name string, cronJob string, hour int, condition batchv1.JobConditionType, reason string) *batchv1.Job {
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Date(2026, 3, 1, hour, 0, 0, 0, time.UTC)),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(2)),
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"batch.kubernetes.io/job-name": name}},
+		},
+		Status: batchv1.JobStatus{StartTime: ptr.To(metav1.NewTime(time.Date(2026, 3, 1, hour, 0, 0, 0, time.UTC)))},
+	}
+	if cronJob != "" {
+		job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: cronJob, Controller: ptr.To(true)}}
+	}
+	switch condition {
+	case batchv1.JobComplete:
+		job.Status.Succeeded = 1
+		job.Status.CompletionTime = ptr.To(metav1.NewTime(time.Date(2026, 3, 1, hour, 5, 0, 0, time.UTC)))
+	case batchv1.JobFailed:
+		job.Status.Failed = 3
+	default:
+		job.Status.Active = 1
+	}
+	if condition != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue, Reason: reason, Message: reason + " message"}}
+	}
+
+	return job
+}
+
+func TestListJobs(t *testing.T) {
+	objects := []runtime.Object{
+		newCronJob("backup", false, "backup-3"),
+		newCronJob("report", true),
+		newJob("backup-1", "backup", 10, batchv1.JobComplete, ""),
+		newJob("backup-2", "backup", 11, batchv1.JobFailed, batchv1.JobReasonBackoffLimitExceeded),
+		newJob("backup-3", "backup", 12, "", ""),
+		newJob("migrate", "", 9, batchv1.JobComplete, ""),
+	}
+
+	tests := map[string]struct {
+		params         listJobsParams
+		expectedResult string
+	}{
+		"all jobs": {
+			params: listJobsParams{Cluster: "local"},
+			expectedResult: `{
+				"cronJobs": [
+					{"name": "backup", "namespace": "default", "schedule": "0 * * * *", "suspended": false, "concurrencyPolicy": "Forbid", "active": ["backup-3"], "lastScheduleTime": "2026-03-01T12:00:00Z",
+					 "lastJob": {"name": "backup-3", "namespace": "default", "cronJob": "backup", "status": "Running", "completions": 1, "active": 1, "succeeded": 0, "failed": 0, "startTime": "2026-03-01T12:00:00Z"}},
+					{"name": "report", "namespace": "default", "schedule": "0 * * * *", "suspended": true, "concurrencyPolicy": "Forbid", "lastScheduleTime": "2026-03-01T12:00:00Z"}
+				],
+				"jobs": [
+					{"name": "backup-3", "namespace": "default", "cronJob": "backup", "status": "Running", "completions": 1, "active": 1, "succeeded": 0, "failed": 0, "startTime": "2026-03-01T12:00:00Z"},
+					{"name": "backup-2", "namespace": "default", "cronJob": "backup", "status": "Failed", "reason": "BackoffLimitExceeded", "message": "BackoffLimitExceeded message", "completions": 1, "active": 0, "succeeded": 0, "failed": 3, "startTime": "2026-03-01T11:00:00Z"},
+					{"name": "backup-1", "namespace": "default", "cronJob": "backup", "status": "Complete", "completions": 1, "active": 0, "succeeded": 1, "failed": 0, "startTime": "2026-03-01T10:00:00Z", "completionTime": "2026-03-01T10:05:00Z"},
+					{"name": "migrate", "namespace": "default", "status": "Complete", "completions": 1, "active": 0, "succeeded": 1, "failed": 0, "startTime": "2026-03-01T09:00:00Z", "completionTime": "2026-03-01T09:05:00Z"}
+				]
+			}`,
+		},
+		"jobs of a cronjob": {
+			params: listJobsParams{Cluster: "local", Namespace: "default", CronJob: "report"},
+			expectedResult: `{
+				"cronJobs": [
+					{"name": "report", "namespace": "default", "schedule": "0 * * * *", "suspended": true, "concurrencyPolicy": "Forbid", "lastScheduleTime": "2026-03-01T12:00:00Z"}
+				],
+				"jobs": []
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, _ := newBatchTools(objects...)
+
+			result, _, err := tools.listJobs(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -2,40 +2,64 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// allClusters can be used as cluster to list resources in all clusters managed by Rancher.
+const allClusters = "all"
+
 // listKubernetesResourcesParams specifies the parameters needed to list kubernetes resources.
 type listKubernetesResourcesParams struct {
-	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
-	Kind      string `json:"kind" jsonschema:"the kind of the resource"`
-	Cluster   string `json:"cluster" jsonschema:"the cluster of the resource"`
+	Namespace     string   `json:"namespace,omitempty" jsonschema:"the namespace of the resource, empty for the cluster-scoped resources"`
+	Kind          string   `json:"kind" jsonschema:"the kind of the resource"`
+	Cluster       string   `json:"cluster" jsonschema:"the cluster of the resource, or all for every cluster"`
+	Clusters      []string `json:"clusters,omitempty" jsonschema:"the clusters of the resource, used instead of cluster to list resources in several clusters"`
+	LabelSelector string   `json:"labelSelector,omitempty" jsonschema:"the label selector the resources must match"`
+	FieldSelector string   `json:"fieldSelector,omitempty" jsonschema:"the field selector the resources must match"`
+	Limit         int64    `json:"limit,omitempty" jsonschema:"the maximum number of resources returned"`
+	Continue      string   `json:"continue,omitempty" jsonschema:"the continue token returned by a previous call to get the next page"`
+	UseSteve      bool     `json:"useSteve,omitempty" jsonschema:"list the resources with the Rancher Steve API, which filters, sorts and paginates them in Rancher"`
+	Filter        string   `json:"filter,omitempty" jsonschema:"the Steve filter of the resources, comma separated <field path>=<value> matching the resources with any of the fields containing the value, e.g. metadata.name=nginx. Implies useSteve"`
+	Sort          string   `json:"sort,omitempty" jsonschema:"the comma separated field paths the resources are sorted by with Steve, descending if prefixed with -, e.g. -metadata.creationTimestamp. Implies useSteve"`
+}
+
+// useSteve returns true if the resources are listed with the Steve API.
+func (p listKubernetesResourcesParams) useSteve() bool {
+	return p.UseSteve || p.Filter != "" || p.Sort != ""
 }
 
 // listKubernetesResources lists Kubernetes resources of a specific kind and namespace.
 func (t *Tools) listKubernetesResources(ctx context.Context, toolReq *mcp.CallToolRequest, params listKubernetesResourcesParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("listKubernetesResource called")
-
-	resources, err := t.client.GetResources(ctx, client.ListParams{
-		Cluster:   params.Cluster,
-		Kind:      params.Kind,
-		Namespace: params.Namespace,
-		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     middleware.Token(ctx),
-	})
+	middleware.Logger(ctx).Debug("listKubernetesResource called")
+
+	if params.useSteve() && params.FieldSelector != "" {
+		return nil, nil, fmt.Errorf("fieldSelector can't be used with Steve, use filter instead")
+	}
+	if len(params.Clusters) > 0 || params.Cluster == allClusters {
+		if params.Limit > 0 || params.Continue != "" {
+			return nil, nil, fmt.Errorf("limit and continue can't be used to list resources in several clusters")
+		}
+		return t.listKubernetesResourcesInClusters(ctx, toolReq, params)
+	}
+
+	resources, continueToken, err := t.listResourcesPage(ctx, toolReq, params, params.Cluster)
 	if err != nil {
-		zap.L().Error("failed to list resources", zap.String("tool", "listKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to list resources", zap.String("tool", "listKubernetesResource"), zap.Error(err))
 		return nil, nil, err
 	}
 
-	mcpResponse, err := response.CreateMcpResponse(resources, params.Cluster)
+	mcpResponse, err := response.CreatePaginatedMcpResponse(ctx, resources, params.Cluster, continueToken)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "listKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listKubernetesResource"), zap.Error(err))
 		return nil, nil, err
 	}
 
@@ -43,3 +67,73 @@ func (t *Tools) listKubernetesResources(ctx context.Context, toolReq *mcp.CallTo
 		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
 	}, nil, nil
 }
+
+// listKubernetesResourcesInClusters lists the resources in several clusters concurrently. A cluster that can't be
+// queried doesn't fail the request, its error is reported next to the resources of the other clusters.
+func (t *Tools) listKubernetesResourcesInClusters(ctx context.Context, toolReq *mcp.CallToolRequest, params listKubernetesResourcesParams) (*mcp.CallToolResult, any, error) {
+	var clusters []string
+	if !slices.Contains(params.Clusters, allClusters) && params.Cluster != allClusters {
+		clusters = params.Clusters
+	}
+	clusters, err := t.clustersOrAll(ctx, toolReq, clusters)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get clusters", zap.String("tool", "listKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	results := make([]response.ClusterResources, len(clusters))
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentClusters)
+	for i, cluster := range clusters {
+		t.goFanOut(gCtx, g, func() error {
+			resources, _, err := t.listResourcesPage(gCtx, toolReq, params, cluster)
+			if err != nil {
+				middleware.Logger(ctx).Error("failed to list resources", zap.String("tool", "listKubernetesResource"), zap.String("cluster", cluster), zap.Error(err))
+			}
+			results[i] = response.ClusterResources{Cluster: cluster, Objects: resources, Error: err}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	mcpResponse, err := response.CreateMultiClusterMcpResponse(ctx, results)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// listResourcesPage lists a page of the resources of the cluster, all of them without a limit, with the Steve API if
+// the params use it.
+func (t *Tools) listResourcesPage(ctx context.Context, toolReq *mcp.CallToolRequest, params listKubernetesResourcesParams, cluster string) ([]*unstructured.Unstructured, string, error) {
+	if params.useSteve() {
+		return t.client.ListWithSteve(ctx, client.SteveListParams{
+			Cluster:       cluster,
+			Kind:          params.Kind,
+			Namespace:     params.Namespace,
+			URL:           toolReq.Extra.Header.Get(urlHeader),
+			Token:         middleware.Token(ctx),
+			LabelSelector: params.LabelSelector,
+			Filter:        params.Filter,
+			Sort:          params.Sort,
+			Limit:         params.Limit,
+			Continue:      params.Continue,
+		})
+	}
+
+	return t.client.GetResourcesPage(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          params.Kind,
+		Namespace:     params.Namespace,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+		LabelSelector: params.LabelSelector,
+		FieldSelector: params.FieldSelector,
+		Limit:         params.Limit,
+		Continue:      params.Continue,
+	})
+}
@@ -1,6 +1,11 @@
 package core
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -10,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -104,6 +110,41 @@ func TestListKubernetesResources(t *testing.T) {
 			}),
 			expectedResult: `{"llm": "no resources found"}`,
 		},
+		"list pods with a Steve filter and sort - Kubernetes API fallback": {
+			params: listKubernetesResourcesParams{
+				Kind:      "pod",
+				Namespace: "default",
+				Cluster:   "local",
+				Filter:    "status.phase=Pending,metadata.name=pod-",
+				Sort:      "-metadata.name",
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(listResourcesScheme(), map[schema.GroupVersionResource]string{
+				{Group: "", Version: "v1", Resource: "pods"}: "PodList",
+			}, fakePod1, fakePod2),
+			expectedResult: `{
+				"llm": [
+					{
+						"metadata": {"name": "pod-2", "namespace": "default"},
+						"spec": {"containers": [{"image": "redis:latest", "name": "redis", "resources": {}}]},
+						"status": {"phase": "Running"}
+					},
+					{
+						"metadata": {"name": "pod-1", "namespace": "default"},
+						"spec": {"containers": [{"image": "nginx:latest", "name": "nginx", "resources": {}}]},
+						"status": {"phase": "Running"}
+					}
+				]
+			}`,
+		},
+		"list pods with Steve and a field selector": {
+			params: listKubernetesResourcesParams{
+				Kind:          "pod",
+				Cluster:       "local",
+				UseSteve:      true,
+				FieldSelector: "status.phase=Running",
+			},
+			expectedError: "fieldSelector can't be used with Steve, use filter instead",
+		},
 	}
 
 	for name, test := range tests {
@@ -128,3 +169,162 @@ func TestListKubernetesResources(t *testing.T) {
 		})
 	}
 }
+
+func TestListKubernetesResourcesInClusters(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+
+	pod := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]any{"name": name, "namespace": "default"},
+		}}
+	}
+	managementCluster := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "Cluster",
+			"metadata":   map[string]any{"name": name},
+		}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "", Version: "v1", Resource: "pods"}:                         "PodList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}: "ClusterList",
+	}
+	dynClients := map[string]*dynamicfake.FakeDynamicClient{
+		"/k8s/clusters/local":   dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, managementCluster("local"), managementCluster("c-abc12"), pod("rancher")),
+		"/k8s/clusters/c-abc12": dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, pod("nginx")),
+	}
+
+	tests := map[string]struct {
+		params         listKubernetesResourcesParams
+		expectedResult string
+	}{
+		"list pods in several clusters": {
+			params: listKubernetesResourcesParams{Kind: "pod", Namespace: "default", Clusters: []string{"local", "c-abc12"}},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "rancher", "namespace": "default"}, "cluster": "local"},
+					{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "nginx", "namespace": "default"}, "cluster": "c-abc12"}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Pod", "name": "rancher", "namespace": "default", "type": "pod"},
+					{"cluster": "c-abc12", "kind": "Pod", "name": "nginx", "namespace": "default", "type": "pod"}
+				]
+			}`,
+		},
+		"list pods in all clusters": {
+			params: listKubernetesResourcesParams{Kind: "pod", Namespace: "default", Cluster: "all"},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "nginx", "namespace": "default"}, "cluster": "c-abc12"},
+					{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "rancher", "namespace": "default"}, "cluster": "local"}
+				],
+				"uiContext": [
+					{"cluster": "c-abc12", "kind": "Pod", "name": "nginx", "namespace": "default", "type": "pod"},
+					{"cluster": "local", "kind": "Pod", "name": "rancher", "namespace": "default", "type": "pod"}
+				]
+			}`,
+		},
+		"cluster not found": {
+			params: listKubernetesResourcesParams{Kind: "pod", Namespace: "default", Clusters: []string{"c-abc12", "missing"}},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "nginx", "namespace": "default"}, "cluster": "c-abc12"},
+					{"cluster": "missing", "error": "cluster 'missing' not found"}
+				],
+				"uiContext": [
+					{"cluster": "c-abc12", "kind": "Pod", "name": "nginx", "namespace": "default", "type": "pod"}
+				]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return dynClients[strings.TrimPrefix(inConfig.Host, fakeUrl)], nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.listKubernetesResources(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestListKubernetesResourcesWithSelectorsAndPagination(t *testing.T) {
+	fakeToken := "fakeToken"
+	// fake Rancher server, the fake dynamic client ignores field selectors and pagination
+	var query url.Values
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/clusters/local/api/v1/namespaces/default/pods" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.Query()
+		continueToken := ""
+		if query.Get("continue") == "" {
+			continueToken = "next-page"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"apiVersion": "v1", "kind": "PodList", "metadata": {"continue": %q}, "items": [
+			{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod-1", "namespace": "default"}}
+		]}`, continueToken)
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		params         listKubernetesResourcesParams
+		expectedQuery  url.Values
+		expectedResult string
+		expectedError  string
+	}{
+		"first page with selectors": {
+			params:        listKubernetesResourcesParams{Kind: "pod", Namespace: "default", Cluster: "local", LabelSelector: "app=nginx", FieldSelector: "status.phase!=Running", Limit: 1},
+			expectedQuery: url.Values{"labelSelector": {"app=nginx"}, "fieldSelector": {"status.phase!=Running"}, "limit": {"1"}},
+			expectedResult: `{
+				"llm": [{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod-1", "namespace": "default"}}],
+				"uiContext": [{"cluster": "local", "kind": "Pod", "name": "pod-1", "namespace": "default", "type": "pod"}],
+				"continue": "next-page"
+			}`,
+		},
+		"last page": {
+			params:        listKubernetesResourcesParams{Kind: "pod", Namespace: "default", Cluster: "local", Limit: 1, Continue: "next-page"},
+			expectedQuery: url.Values{"continue": {"next-page"}, "limit": {"1"}},
+			expectedResult: `{
+				"llm": [{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod-1", "namespace": "default"}}],
+				"uiContext": [{"cluster": "local", "kind": "Pod", "name": "pod-1", "namespace": "default", "type": "pod"}]
+			}`,
+		},
+		"pagination in several clusters": {
+			params:        listKubernetesResourcesParams{Kind: "pod", Namespace: "default", Cluster: "all", Limit: 10},
+			expectedError: "limit and continue can't be used to list resources in several clusters",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeToolsClient(client.NewClient(true), fakeToken)}
+
+			result, _, err := tools.listKubernetesResources(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedQuery, query)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
@@ -4,11 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/internal/middleware"
-	"github.com/rancher/rancher-ai-mcp/pkg/converter"
 	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,37 +25,84 @@ type jsonPatch struct {
 // updateKubernetesResourceParams defines the structure for updating a general Kubernetes resource.
 // It includes fields required to uniquely identify a resource within a cluster.
 type updateKubernetesResourceParams struct {
-	Name      string      `json:"name" jsonschema:"the name of k8s resource"`
-	Namespace string      `json:"namespace" jsonschema:"the namespace of the resource"`
-	Kind      string      `json:"kind" jsonschema:"the kind of the resource"`
-	Cluster   string      `json:"cluster" jsonschema:"the cluster of the resource"`
-	Patch     []jsonPatch `json:"patch" jsonschema:"the patch of the request"`
+	Name        string      `json:"name" jsonschema:"the name of k8s resource"`
+	Namespace   string      `json:"namespace,omitempty" jsonschema:"the namespace of the resource, empty for the cluster-scoped resources"`
+	Kind        string      `json:"kind" jsonschema:"the kind of the resource"`
+	Cluster     string      `json:"cluster" jsonschema:"the cluster of the resource"`
+	Patch       []jsonPatch `json:"patch" jsonschema:"the patch of the request"`
+	DryRun      bool        `json:"dryRun,omitempty" jsonschema:"validate the patch in the server without modifying the resource"`
+	WaitSeconds int64       `json:"waitSeconds,omitempty" jsonschema:"wait up to this many seconds, at most 300, for the resource to be ready, e.g. a Deployment Available or a Machine Running. 0 returns without waiting"`
 }
 
-// updateKubernetesResource updates a specific Kubernetes resource using a JSON patch.
+// updateKubernetesResource updates a specific Kubernetes resource using a JSON patch, and returns the patched object
+// with a diff against the previous one. In dry-run mode the patch is applied by the server without persisting it. With
+// waitSeconds, the patched resource is watched until it's ready and its last state is returned, the diff still being
+// the change made by the patch.
 func (t *Tools) updateKubernetesResource(ctx context.Context, toolReq *mcp.CallToolRequest, params updateKubernetesResourceParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("updateKubernetesResource called")
+	middleware.Logger(ctx).Debug("updateKubernetesResource called")
 
-	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs[strings.ToLower(params.Kind)])
+	if err := validateWaitSeconds(params.WaitSeconds); err != nil {
+		return nil, nil, err
+	}
+	gvr, err := t.client.ResolveGVR(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Cluster, params.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, gvr)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	patchBytes, err := json.Marshal(params.Patch)
 	if err != nil {
-		zap.L().Error("failed to create patch", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create patch", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
 	}
 
-	obj, err := resourceInterface.Patch(ctx, params.Name, types.JSONPatchType, patchBytes, metav1.PatchOptions{})
+	patchOptions := metav1.PatchOptions{}
+	if params.DryRun {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	current, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
 	if err != nil {
-		zap.L().Error("failed to apply patch", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get resource", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get resource %s: %w", params.Name, err)
+	}
+
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.JSONPatchType, patchBytes, patchOptions)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to apply patch", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to patch resource %s: %w", params.Name, err)
 	}
 
-	mcpResponse, err := response.CreateMcpResponse([]*unstructured.Unstructured{obj}, params.Cluster)
+	if params.DryRun {
+		dryRun, err := dryRunResponse(current, obj)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create dry-run response", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: dryRun}},
+		}, nil, nil
+	}
+
+	diff, err := diffObject(current, obj)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create diff", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+		return nil, nil, err
+	}
+	objs := []*unstructured.Unstructured{obj, diff}
+	if params.WaitSeconds > 0 {
+		last, wait, err := waitForResourceObjects(ctx, resourceInterface, obj, params.WaitSeconds)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to wait for resource", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+			return nil, nil, err
+		}
+		objs = []*unstructured.Unstructured{last, diff, wait}
+	}
+	mcpResponse, err := response.CreateMcpResponse(ctx, objs, params.Cluster)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "updateKubernetesResource"), zap.Error(err))
 		return nil, nil, err
 	}
 
@@ -68,7 +68,8 @@ func TestUpdateKubernetesResource(t *testing.T) {
 						"data": {"key1": "value1", "key2": "value2", "key3": "value3"},
 						"kind": "ConfigMap",
 						"metadata": {"name": "test-config", "namespace": "default"}
-					}
+					},
+					{"diff": [{"path": "/data/key3", "op": "add", "newValue": "value3"}]}
 				],
 				"uiContext": [
 					{"cluster": "local", "kind": "ConfigMap", "name": "test-config", "namespace": "default", "type": "configmap"}
@@ -99,7 +100,8 @@ func TestUpdateKubernetesResource(t *testing.T) {
 						"data": {"key1": "updated-value", "key2": "value2"},
 						"kind": "ConfigMap",
 						"metadata": {"name": "test-config", "namespace": "default"}
-					}
+					},
+					{"diff": [{"path": "/data/key1", "op": "replace", "oldValue": "value1", "newValue": "updated-value"}]}
 				],
 				"uiContext": [
 					{"cluster": "local", "kind": "ConfigMap", "name": "test-config", "namespace": "default", "type": "configmap"}
@@ -129,13 +131,50 @@ func TestUpdateKubernetesResource(t *testing.T) {
 						"data": {"key1": "value1"},
 						"kind": "ConfigMap",
 						"metadata": {"name": "test-config", "namespace": "default"}
-					}
+					},
+					{"diff": [{"path": "/data/key2", "op": "remove", "oldValue": "value2"}]}
 				],
 				"uiContext": [
 					{"cluster": "local", "kind": "ConfigMap", "name": "test-config", "namespace": "default", "type": "configmap"}
 				]
 			}`,
 		},
+		"update configmap - dry run": {
+			params: updateKubernetesResourceParams{
+				Name:      "test-config",
+				Namespace: "default",
+				Kind:      "configmap",
+				Cluster:   "local",
+				Patch: []jsonPatch{
+					{
+						Op:    "replace",
+						Path:  "/data/key1",
+						Value: "updated-value",
+					},
+					{
+						Op:   "remove",
+						Path: "/data/key2",
+					},
+				},
+				DryRun: true,
+			},
+			fakeDynClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(patchResourceScheme(), map[schema.GroupVersionResource]string{
+				{Group: "", Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+			}, fakeConfigMapForPatch),
+			expectedResult: `{
+				"dryRun": true,
+				"object": {
+					"apiVersion": "v1",
+					"data": {"key1": "updated-value"},
+					"kind": "ConfigMap",
+					"metadata": {"name": "test-config", "namespace": "default"}
+				},
+				"diff": [
+					{"path": "/data/key1", "op": "replace", "oldValue": "value1", "newValue": "updated-value"},
+					{"path": "/data/key2", "op": "remove", "oldValue": "value2"}
+				]
+			}`,
+		},
 		"update configmap - not found": {
 			params: updateKubernetesResourceParams{
 				Name:      "nonexistent-config",
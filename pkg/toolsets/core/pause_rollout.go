@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// deploymentParams identifies a Deployment within a cluster.
+type deploymentParams struct {
+	Name      string `json:"name" jsonschema:"the name of the Deployment"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the Deployment"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Deployment"`
+}
+
+// pauseRollout pauses the rollout of a Deployment. Changes to its Pod template aren't rolled out until it is resumed.
+func (t *Tools) pauseRollout(ctx context.Context, toolReq *mcp.CallToolRequest, params deploymentParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("pauseRollout called")
+
+	return t.setRolloutPaused(ctx, toolReq, params, true)
+}
+
+// resumeRollout resumes the paused rollout of a Deployment.
+func (t *Tools) resumeRollout(ctx context.Context, toolReq *mcp.CallToolRequest, params deploymentParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("resumeRollout called")
+
+	return t.setRolloutPaused(ctx, toolReq, params, false)
+}
+
+// setRolloutPaused sets the paused field of a Deployment.
+func (t *Tools) setRolloutPaused(ctx context.Context, toolReq *mcp.CallToolRequest, params deploymentParams, paused bool) (*mcp.CallToolResult, any, error) {
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs["deployment"])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"paused": paused,
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to patch deployment", zap.String("tool", "setRolloutPaused"), zap.Bool("paused", paused), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to update deployment %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "setRolloutPaused"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPauseAndResumeRollout(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	tools, _ := newRolloutTools(newRolloutDeployment("1", "web:1", false))
+	ctx := middleware.WithToken(t.Context(), fakeToken)
+	req := &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+	}
+	params := deploymentParams{Name: "web", Namespace: "default", Cluster: "local"}
+
+	result, _, err := tools.pauseRollout(ctx, req, params)
+	require.NoError(t, err)
+	paused, _, _ := unstructured.NestedBool(llmItems(t, result)[0], "spec", "paused")
+	assert.True(t, paused)
+
+	result, _, err = tools.resumeRollout(ctx, req, params)
+	require.NoError(t, err)
+	paused, _, _ = unstructured.NestedBool(llmItems(t, result)[0], "spec", "paused")
+	assert.False(t, paused)
+
+	_, _, err = tools.pauseRollout(ctx, req, deploymentParams{Name: "unknown", Namespace: "default", Cluster: "local"})
+	assert.ErrorContains(t, err, "failed to update deployment unknown")
+}
@@ -0,0 +1,606 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// registryTimeout is the maximum time allowed for the requests to an image registry.
+	registryTimeout = 10 * time.Second
+	// dockerHubRegistry is the host of the registry API of Docker Hub.
+	dockerHubRegistry = "registry-1.docker.io"
+	// maxPreflightObjects is the maximum number of documents of a manifest checked by preflightCheck.
+	maxPreflightObjects = 100
+)
+
+// The statuses of the preflight checks, from the best to the worst.
+const (
+	preflightPass = "pass"
+	preflightWarn = "warn"
+	preflightFail = "fail"
+)
+
+// The preflight checks.
+const (
+	checkSchema     = "schema"
+	checkReferences = "references"
+	checkImage      = "image"
+	checkCapacity   = "capacity"
+)
+
+// manifestMediaTypes are the media types of the image manifests and indexes accepted from the registries.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// clusterScopedKinds are the kinds of the usual cluster-scoped resources, that don't get the namespace of the
+// preflight check.
+var clusterScopedKinds = []string{"Namespace", "Node", "PersistentVolume", "StorageClass", "ClusterRole", "ClusterRoleBinding",
+	"CustomResourceDefinition", "PriorityClass", "IngressClass", "RuntimeClass", "CSIDriver", "APIService",
+	"ValidatingWebhookConfiguration", "MutatingWebhookConfiguration", "ValidatingAdmissionPolicy", "ValidatingAdmissionPolicyBinding"}
+
+type preflightCheckParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster the manifest is checked against"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the resources of the manifest without one. Defaults to default"`
+	Manifest  string `json:"manifest" jsonschema:"the YAML or JSON manifest to check, with one or more documents" validate:"required"`
+}
+
+// preflightReport is the result of the checks of a manifest. Status is the worst status of the checks.
+type preflightReport struct {
+	Cluster string           `json:"cluster"`
+	Status  string           `json:"status"`
+	Checks  []preflightCheck `json:"checks"`
+}
+
+// preflightCheck is the result of a check of a resource of the manifest, or of an image.
+type preflightCheck struct {
+	Check    string `json:"check"`
+	Status   string `json:"status"`
+	Resource string `json:"resource,omitempty"`
+	Image    string `json:"image,omitempty"`
+	Message  string `json:"message"`
+}
+
+// preflightCheck runs the checks of a manifest before it's created in a cluster: the validation of each resource by
+// the API server with a server-side dry run, the existence of the ConfigMaps, Secrets, ServiceAccounts and
+// PersistentVolumeClaims referenced by the workloads, the existence of their images in the registries, and whether
+// the requests of their replicas fit in the nodes of the cluster.
+func (t *Tools) preflightCheck(ctx context.Context, toolReq *mcp.CallToolRequest, params preflightCheckParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("preflightCheck called")
+
+	namespace := cmp.Or(params.Namespace, "default")
+	objs, err := parseManifest(params.Manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(objs) == 0 {
+		return nil, nil, fmt.Errorf("the manifest has no resources")
+	}
+	if len(objs) > maxPreflightObjects {
+		return nil, nil, fmt.Errorf("the manifest has %d resources, more than the %d checked in a call", len(objs), maxPreflightObjects)
+	}
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" && !slices.Contains(clusterScopedKinds, obj.GetKind()) {
+			obj.SetNamespace(namespace)
+		}
+	}
+
+	report := preflightReport{Cluster: params.Cluster, Checks: []preflightCheck{}}
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	report.Checks = append(report.Checks, t.checkReferences(ctx, token, url, params.Cluster, objs)...)
+	report.Checks = append(report.Checks, t.checkSchemas(ctx, token, url, params.Cluster, objs)...)
+	report.Checks = append(report.Checks, t.checkImages(ctx, objs)...)
+	capacityChecks, err := t.checkCapacity(ctx, token, url, params.Cluster, objs)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to check capacity", zap.String("tool", "preflightCheck"), zap.Error(err))
+		return nil, nil, err
+	}
+	report.Checks = append(report.Checks, capacityChecks...)
+
+	report.Status = preflightPass
+	for _, check := range report.Checks {
+		if statusRank(check.Status) > statusRank(report.Status) {
+			report.Status = check.Status
+		}
+	}
+
+	response, err := json.Marshal(report)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "preflightCheck"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// parseManifest returns the resources of the documents of the manifest, with the items of the List documents.
+func parseManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	for document := 1; ; document++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); errors.Is(err, io.EOF) {
+			return objs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid manifest document %d: %w", document, err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		// the unstructured decoder keeps the integers as int64, like the objects returned by the dynamic client
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("invalid manifest document %d: %w", document, err)
+		}
+		if !u.IsList() {
+			objs = append(objs, u)
+			continue
+		}
+		list, err := u.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest document %d: %w", document, err)
+		}
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+	}
+}
+
+func statusRank(status string) int {
+	return slices.Index([]string{preflightPass, preflightWarn, preflightFail}, status)
+}
+
+func resourceID(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetKind() + "/" + obj.GetName()
+	}
+
+	return obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// checkSchemas validates each resource with a server-side apply in dry-run mode with the strict field validation, so
+// the unknown fields are reported too. The resources of a namespace created by the manifest can't be validated,
+// since the namespace doesn't exist yet.
+func (t *Tools) checkSchemas(ctx context.Context, token string, url string, cluster string, objs []*unstructured.Unstructured) []preflightCheck {
+	var checks []preflightCheck
+	for _, obj := range objs {
+		check := preflightCheck{Check: checkSchema, Resource: resourceID(obj)}
+		err := t.dryRunApply(ctx, token, url, cluster, obj)
+		switch {
+		case err == nil:
+			check.Status, check.Message = preflightPass, "accepted by the API server"
+		case apierrors.IsNotFound(err) && createdByManifest(objs, "Namespace", "", obj.GetNamespace()):
+			check.Status, check.Message = preflightWarn, fmt.Sprintf("can't be validated before the namespace %s of the manifest is created", obj.GetNamespace())
+		default:
+			check.Status, check.Message = preflightFail, err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+func (t *Tools) dryRunApply(ctx context.Context, token string, url string, cluster string, obj *unstructured.Unstructured) error {
+	gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+	if err != nil {
+		return err
+	}
+	gvr, err := t.client.ResolveGVR(ctx, token, url, cluster, obj.GetKind())
+	if err == nil && gvr.Group != gv.Group {
+		// the kind is served by several groups, e.g. the Rancher and CAPI clusters
+		gvr, err = t.client.ResolveGVR(ctx, token, url, cluster, obj.GetKind()+"."+gv.Group)
+	}
+	if err != nil {
+		return err
+	}
+	gvr.Version = gv.Version
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, obj.GetNamespace(), cluster, gvr)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource: %w", err)
+	}
+	_, err = resourceInterface.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:          []string{metav1.DryRunAll},
+		FieldManager:    applyFieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+	})
+
+	return err
+}
+
+// checkReferences checks that the ConfigMaps, Secrets, ServiceAccounts and PersistentVolumeClaims referenced by the
+// workloads exist in the cluster or are created by the manifest.
+func (t *Tools) checkReferences(ctx context.Context, token string, url string, cluster string, objs []*unstructured.Unstructured) []preflightCheck {
+	var checks []preflightCheck
+	for _, obj := range objs {
+		spec, ok := podSpecOf(obj)
+		if !ok {
+			continue
+		}
+		var checked []string
+		for _, ref := range podReferences(spec) {
+			if slices.Contains(checked, ref.To) {
+				continue
+			}
+			checked = append(checked, ref.To)
+			kind, name, _ := strings.Cut(ref.To, "/")
+			check := preflightCheck{Check: checkReferences, Resource: resourceID(obj)}
+			switch {
+			case kind == "ServiceAccount" && name == "default":
+				continue
+			case createdByManifest(objs, kind, obj.GetNamespace(), name):
+				check.Status, check.Message = preflightPass, fmt.Sprintf("%s %s is created by the manifest", kind, name)
+			default:
+				_, err := t.client.GetResource(ctx, client.GetParams{
+					Cluster:   cluster,
+					Kind:      strings.ToLower(kind),
+					Namespace: obj.GetNamespace(),
+					Name:      name,
+					URL:       url,
+					Token:     token,
+				})
+				switch {
+				case err == nil:
+					check.Status, check.Message = preflightPass, fmt.Sprintf("%s %s exists", kind, name)
+				case apierrors.IsNotFound(err):
+					check.Status, check.Message = preflightFail, fmt.Sprintf("%s %s doesn't exist in namespace %s", kind, name, obj.GetNamespace())
+				default:
+					check.Status, check.Message = preflightWarn, fmt.Sprintf("%s %s can't be checked: %v", kind, name, err)
+				}
+			}
+			checks = append(checks, check)
+		}
+	}
+
+	return checks
+}
+
+func createdByManifest(objs []*unstructured.Unstructured, kind string, namespace string, name string) bool {
+	return slices.ContainsFunc(objs, func(obj *unstructured.Unstructured) bool {
+		return obj.GetKind() == kind && obj.GetNamespace() == namespace && obj.GetName() == name
+	})
+}
+
+// podSpecOf returns the pod spec of a Pod or the pod template of a workload.
+func podSpecOf(obj *unstructured.Unstructured) (corev1.PodSpec, bool) {
+	fields := podSpecPath(obj.GetKind())
+	if fields == nil {
+		return corev1.PodSpec{}, false
+	}
+	m, ok, _ := unstructured.NestedMap(obj.Object, fields...)
+	if !ok {
+		return corev1.PodSpec{}, false
+	}
+	var spec corev1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &spec); err != nil {
+		return corev1.PodSpec{}, false
+	}
+
+	return spec, true
+}
+
+// checkImages checks that the images of the workloads exist in their registries, with a HEAD request of their
+// manifest. The registries that can't be reached, or require credentials, are reported as warnings.
+func (t *Tools) checkImages(ctx context.Context, objs []*unstructured.Unstructured) []preflightCheck {
+	httpClient := t.registryClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: registryTimeout}
+	}
+
+	var checks []preflightCheck
+	var checked []string
+	for _, obj := range objs {
+		spec, ok := podSpecOf(obj)
+		if !ok {
+			continue
+		}
+		for _, container := range slices.Concat(spec.InitContainers, spec.Containers) {
+			if slices.Contains(checked, container.Image) {
+				continue
+			}
+			checked = append(checked, container.Image)
+			status, message := checkRegistryImage(ctx, httpClient, container.Image)
+			checks = append(checks, preflightCheck{Check: checkImage, Status: status, Resource: resourceID(obj), Image: container.Image, Message: message})
+		}
+	}
+
+	return checks
+}
+
+// checkRegistryImage sends a HEAD request for the manifest of the image to its registry, with an anonymous token if
+// the registry asks for one.
+func checkRegistryImage(ctx context.Context, httpClient *http.Client, image string) (string, string) {
+	ref := parseImageReference(image)
+	registry := ref.Registry
+	if registry == defaultRegistry {
+		registry = dockerHubRegistry
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, ref.Repository, cmp.Or(ref.Digest, ref.Tag))
+
+	res, err := headManifest(ctx, httpClient, manifestURL, "")
+	if err != nil {
+		return preflightWarn, fmt.Sprintf("the registry %s can't be reached from the server: %v", ref.Registry, err)
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		if token, err := anonymousRegistryToken(ctx, httpClient, res.Header.Get("WWW-Authenticate")); err == nil && token != "" {
+			res, err = headManifest(ctx, httpClient, manifestURL, token)
+			if err != nil {
+				return preflightWarn, fmt.Sprintf("the registry %s can't be reached from the server: %v", ref.Registry, err)
+			}
+		}
+	}
+
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return preflightPass, fmt.Sprintf("found in the registry %s", ref.Registry)
+	case res.StatusCode == http.StatusNotFound:
+		return preflightFail, fmt.Sprintf("not found in the registry %s", ref.Registry)
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		// the registries answer 401 for the private images and for the images that don't exist alike
+		return preflightWarn, fmt.Sprintf("the registry %s requires credentials, check that the image exists and the imagePullSecrets can pull it", ref.Registry)
+	default:
+		return preflightWarn, fmt.Sprintf("the registry %s returned %s", ref.Registry, res.Status)
+	}
+}
+
+func headManifest(ctx context.Context, httpClient *http.Client, manifestURL string, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	return res, nil
+}
+
+// anonymousRegistryToken returns the token of the Bearer challenge of a registry, requested without credentials.
+func anonymousRegistryToken(ctx context.Context, httpClient *http.Client, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication scheme %q", scheme)
+	}
+	values := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		values[key] = strings.Trim(value, `"`)
+	}
+	tokenURL, err := url.Parse(values["realm"])
+	if err != nil || values["realm"] == "" {
+		return "", fmt.Errorf("invalid realm %q", values["realm"])
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if values[key] != "" {
+			query.Set(key, values[key])
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed: %s", res.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+
+	return cmp.Or(token.Token, token.AccessToken), nil
+}
+
+// schedulableNode is a node Pods can be scheduled on, with the resources requested by the Pods running on it.
+type schedulableNode struct {
+	name        string
+	labels      map[string]string
+	allocatable resourceAmounts
+	requested   resourceAmounts
+}
+
+// placeReplica adds the requests of a replica to the node matching the node selector with the most CPU left among the
+// ones it fits in, like the scheduler would without taking the affinities and tolerations into account. It returns the
+// index of the node, or -1 if the replica fits in none.
+func placeReplica(nodes []schedulableNode, requests resourceAmounts, nodeSelector map[string]string) int {
+	best := -1
+	for i := range nodes {
+		if !labels.SelectorFromSet(nodeSelector).Matches(labels.Set(nodes[i].labels)) ||
+			replicasFit(nodes[i].allocatable, nodes[i].requested, requests) == 0 {
+			continue
+		}
+		if best < 0 || nodes[i].allocatable.cpu-nodes[i].requested.cpu > nodes[best].allocatable.cpu-nodes[best].requested.cpu {
+			best = i
+		}
+	}
+	if best >= 0 {
+		nodes[best].requested.add(requests)
+	}
+
+	return best
+}
+
+// checkCapacity places the replicas of the workloads of the manifest on the schedulable nodes with the resources left
+// by their Pods, like the scheduler would without taking the affinities and tolerations into account. Each replica
+// goes to the node with the most CPU left among the ones it fits in.
+func (t *Tools) checkCapacity(ctx context.Context, token string, url string, cluster string, objs []*unstructured.Unstructured) ([]preflightCheck, error) {
+	type workload struct {
+		obj          *unstructured.Unstructured
+		requests     resourceAmounts
+		nodeSelector map[string]string
+		replicas     int64
+	}
+	var workloads []workload
+	for _, obj := range objs {
+		spec, ok := podSpecOf(obj)
+		if !ok || obj.GetKind() == "CronJob" {
+			continue
+		}
+		replicas := int64(1)
+		switch obj.GetKind() {
+		case "Deployment", "StatefulSet", "ReplicaSet":
+			if r, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); ok {
+				replicas = r
+			}
+		case "Job":
+			if p, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "parallelism"); ok {
+				replicas = p
+			}
+		}
+		workloads = append(workloads, workload{obj: obj, requests: podRequests(corev1.Pod{Spec: spec}), nodeSelector: spec.NodeSelector, replicas: replicas})
+	}
+	if len(workloads) == 0 {
+		return nil, nil
+	}
+
+	clusterNodes, pods, err := t.nodesAndPods(ctx, token, url, cluster)
+	if err != nil {
+		return nil, err
+	}
+	nodes := newSchedulableNodes(clusterNodes, pods)
+	var checks []preflightCheck
+	for _, w := range workloads {
+		check := preflightCheck{Check: checkCapacity, Resource: resourceID(w.obj)}
+		placed, wanted := int64(0), w.replicas
+		if w.obj.GetKind() == "DaemonSet" {
+			// a DaemonSet has a replica on each node matching its node selector
+			wanted = 0
+			for i := range nodes {
+				if !labels.SelectorFromSet(w.nodeSelector).Matches(labels.Set(nodes[i].labels)) {
+					continue
+				}
+				wanted++
+				if placeReplica(nodes[i:i+1], w.requests, nil) == 0 {
+					placed++
+				}
+			}
+		} else {
+			for placed < wanted && placeReplica(nodes, w.requests, w.nodeSelector) >= 0 {
+				placed++
+			}
+		}
+
+		cpu, memory, _ := newResourceCapacities(resourceAmounts{cpu: w.requests.cpu, memory: w.requests.memory}, resourceAmounts{})
+		switch {
+		case placed < wanted:
+			check.Status = preflightFail
+			check.Message = fmt.Sprintf("only %d of the %d replicas requesting %s CPU and %s memory fit in the schedulable nodes", placed, wanted, cpu.Allocatable, memory.Allocatable)
+		case w.requests.cpu == 0 && w.requests.memory == 0:
+			check.Status = preflightWarn
+			check.Message = "the containers don't request CPU or memory, the scheduler can't reserve resources for them"
+		default:
+			check.Status = preflightPass
+			check.Message = fmt.Sprintf("the %d replicas requesting %s CPU and %s memory fit in the schedulable nodes", wanted, cpu.Allocatable, memory.Allocatable)
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// nodesAndPods returns the nodes of the cluster and its Pods.
+func (t *Tools) nodesAndPods(ctx context.Context, token string, url string, cluster string) ([]corev1.Node, []corev1.Pod, error) {
+	nodeResources, err := t.client.GetResources(ctx, client.ListParams{Cluster: cluster, Kind: "node", URL: url, Token: token})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	podResources, err := t.client.GetResources(ctx, client.ListParams{Cluster: cluster, Kind: "pod", URL: url, Token: token})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get pods: %w", err)
+	}
+
+	nodes := make([]corev1.Node, len(nodeResources))
+	for i, nodeResource := range nodeResources {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(nodeResource.Object, &nodes[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Node: %w", err)
+		}
+	}
+	pods := make([]corev1.Pod, len(podResources))
+	for i, podResource := range podResources {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podResource.Object, &pods[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+		}
+	}
+
+	return nodes, pods, nil
+}
+
+// newSchedulableNodes returns the nodes without NoSchedule or NoExecute taints that aren't cordoned, with the
+// resources requested by the Pods running on them, sorted by name.
+func newSchedulableNodes(nodes []corev1.Node, pods []corev1.Pod) []schedulableNode {
+	requestedByNode := map[string]resourceAmounts{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requested := requestedByNode[pod.Spec.NodeName]
+		requested.add(podRequests(pod))
+		requestedByNode[pod.Spec.NodeName] = requested
+	}
+
+	var schedulable []schedulableNode
+	for _, node := range nodes {
+		allocatable := resourceAmounts{
+			cpu:    node.Status.Allocatable.Cpu().MilliValue(),
+			memory: node.Status.Allocatable.Memory().Value(),
+			pods:   node.Status.Allocatable.Pods().Value(),
+		}
+		if newNodeCapacity(node, allocatable, requestedByNode[node.Name], 100).Schedulable {
+			schedulable = append(schedulable, schedulableNode{name: node.Name, labels: node.Labels, allocatable: allocatable, requested: requestedByNode[node.Name]})
+		}
+	}
+	slices.SortFunc(schedulable, func(a, b schedulableNode) int {
+		return cmp.Compare(a.name, b.name)
+	})
+
+	return schedulable
+}
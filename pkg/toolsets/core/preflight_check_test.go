@@ -0,0 +1,187 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeRegistry returns a registry serving the image shop/web:1.0 with an anonymous token, and asking for credentials
+// for the images of the private repository.
+func newFakeRegistry(t *testing.T) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token" && r.URL.Query().Get("scope") == "repository:shop/web:pull":
+			_, _ = w.Write([]byte(`{"token":"anonymous"}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/private/"):
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/shop/web/manifests/1.0" && r.Header.Get("Authorization") != "Bearer anonymous":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="https://`+r.Host+`/token",service="registry",scope="repository:shop/web:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/shop/web/manifests/1.0" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+const preflightDeployment = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  mode: production
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %NAME%
+spec:
+  replicas: %REPLICAS%
+  template:
+    spec:
+      containers:
+      - name: web
+        image: %REGISTRY%/shop/%IMAGE%:1.0
+        envFrom:
+        - configMapRef:
+            name: web-config
+        - secretRef:
+            name: %SECRET%
+        resources:
+          requests:
+            cpu: 250m
+            memory: 256Mi
+`
+
+func TestPreflightCheck(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	registry := newFakeRegistry(t)
+	registryHost := strings.TrimPrefix(registry.URL, "https://")
+	manifest := func(name string, replicas string, image string, secret string) string {
+		return strings.NewReplacer("%NAME%", name, "%REPLICAS%", replicas, "%REGISTRY%", registryHost, "%IMAGE%", image, "%SECRET%", secret).Replace(preflightDeployment)
+	}
+
+	tests := map[string]struct {
+		params         preflightCheckParams
+		expectedResult string
+		expectedError  string
+	}{
+		"all checks pass": {
+			params: preflightCheckParams{Cluster: "local", Namespace: "shop", Manifest: manifest("web", "2", "web", "db")},
+			expectedResult: `{"cluster":"local","status":"pass","checks":[
+				{"check":"references","status":"pass","resource":"Deployment/shop/web","message":"ConfigMap web-config is created by the manifest"},
+				{"check":"references","status":"pass","resource":"Deployment/shop/web","message":"Secret db exists"},
+				{"check":"schema","status":"pass","resource":"ConfigMap/shop/web-config","message":"accepted by the API server"},
+				{"check":"schema","status":"pass","resource":"Deployment/shop/web","message":"accepted by the API server"},
+				{"check":"image","status":"pass","resource":"Deployment/shop/web","image":"{{registry}}/shop/web:1.0","message":"found in the registry {{registry}}"},
+				{"check":"capacity","status":"pass","resource":"Deployment/shop/web","message":"the 2 replicas requesting 250m CPU and 256Mi memory fit in the schedulable nodes"}]}`,
+		},
+		"failed checks": {
+			params: preflightCheckParams{Cluster: "local", Namespace: "shop", Manifest: manifest("broken", "3", "missing", "external")},
+			expectedResult: `{"cluster":"local","status":"fail","checks":[
+				{"check":"references","status":"pass","resource":"Deployment/shop/broken","message":"ConfigMap web-config is created by the manifest"},
+				{"check":"references","status":"fail","resource":"Deployment/shop/broken","message":"Secret external doesn't exist in namespace shop"},
+				{"check":"schema","status":"pass","resource":"ConfigMap/shop/web-config","message":"accepted by the API server"},
+				{"check":"schema","status":"fail","resource":"Deployment/shop/broken","message":".spec.template.spec.containers[0].resources.requests: unknown field \"gpu\""},
+				{"check":"image","status":"fail","resource":"Deployment/shop/broken","image":"{{registry}}/shop/missing:1.0","message":"not found in the registry {{registry}}"},
+				{"check":"capacity","status":"fail","resource":"Deployment/shop/broken","message":"only 2 of the 3 replicas requesting 250m CPU and 256Mi memory fit in the schedulable nodes"}]}`,
+		},
+		"warnings": {
+			params: preflightCheckParams{Cluster: "local", Manifest: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: new
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: new
+spec:
+  containers:
+  - name: app
+    image: ` + registryHost + `/private/app:2.0
+`},
+			expectedResult: `{"cluster":"local","status":"warn","checks":[
+				{"check":"schema","status":"pass","resource":"Namespace/new","message":"accepted by the API server"},
+				{"check":"schema","status":"warn","resource":"Pod/new/app","message":"can't be validated before the namespace new of the manifest is created"},
+				{"check":"image","status":"warn","resource":"Pod/new/app","image":"{{registry}}/private/app:2.0","message":"the registry {{registry}} requires credentials, check that the image exists and the imagePullSecrets can pull it"},
+				{"check":"capacity","status":"warn","resource":"Pod/new/app","message":"the containers don't request CPU or memory, the scheduler can't reserve resources for them"}]}`,
+		},
+		"no resources": {
+			params:        preflightCheckParams{Cluster: "local", Manifest: "---\n"},
+			expectedError: "the manifest has no resources",
+		},
+		"invalid manifest": {
+			params:        preflightCheckParams{Cluster: "local", Manifest: "kind: [Deployment"},
+			expectedError: "invalid manifest document 1",
+		},
+		"missing kind": {
+			params:        preflightCheckParams{Cluster: "local", Manifest: "apiVersion: v1\nmetadata:\n  name: web\n"},
+			expectedError: "invalid manifest document 1: Object 'Kind' is missing",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(nodeScheme(),
+				newCapacityNode("node-1", "1", "4Gi"),
+				newCapacityNode("node-2", "2", "4Gi", corev1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}),
+				newCapacityPod("db-0", "node-1", corev1.PodRunning, []corev1.Container{requestsContainer("db", "500m", "1Gi")}),
+				&corev1.Secret{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}, ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"}},
+			)
+			fakeDynClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patch := action.(k8stesting.PatchAction)
+				switch {
+				case patch.GetNamespace() == "new":
+					return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "new")
+				case patch.GetName() == "broken":
+					return true, nil, apierrors.NewBadRequest(`.spec.template.spec.containers[0].resources.requests: unknown field "gpu"`)
+				}
+				return true, &unstructured.Unstructured{}, nil
+			})
+			c := &client.Client{
+				DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken), registryClient: registry.Client()}
+
+			result, _, err := tools.preflightCheck(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, strings.ReplaceAll(test.expectedResult, "{{registry}}", registryHost), result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// probeTimeout is the maximum time allowed for a probe request.
+	probeTimeout = 10 * time.Second
+	// probeMaxBodyBytes limits the response body returned to the LLM.
+	probeMaxBodyBytes = 4 * 1024
+)
+
+type probeHTTPEndpointParams struct {
+	Kind      string `json:"kind" jsonschema:"the kind of the target, Service or Pod"`
+	Name      string `json:"name" jsonschema:"the name of the Service or Pod"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the Service or Pod"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Service or Pod"`
+	Port      string `json:"port,omitempty" jsonschema:"the port name or number. Optional for Services with a single port, Pods default to port 80"`
+	Scheme    string `json:"scheme,omitempty" jsonschema:"http or https. Defaults to http"`
+	Path      string `json:"path,omitempty" jsonschema:"the path of the request, it may include a query string (e.g. /healthz)"`
+}
+
+// probeResult is the response to a probe request.
+type probeResult struct {
+	StatusCode int               `json:"statusCode"`
+	Status     string            `json:"status"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	// Truncated is true when the body exceeded probeMaxBodyBytes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// probeHTTPEndpoint sends a GET request to a Service or Pod through the API server proxy and returns
+// the status code, headers and the beginning of the body.
+func (t *Tools) probeHTTPEndpoint(ctx context.Context, toolReq *mcp.CallToolRequest, params probeHTTPEndpointParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("probeHttpEndpoint called")
+
+	kind := strings.ToLower(params.Kind)
+	if kind != "service" && kind != "pod" {
+		return nil, nil, fmt.Errorf("unsupported kind %s, must be Service or Pod", params.Kind)
+	}
+	if params.Scheme != "" && params.Scheme != "http" && params.Scheme != "https" {
+		return nil, nil, fmt.Errorf("unsupported scheme %s, must be http or https", params.Scheme)
+	}
+	// the path is appended to the proxy URL, so it must not escape it
+	if slices.Contains(strings.Split(params.Path, "/"), "..") {
+		return nil, nil, fmt.Errorf("invalid path %q", params.Path)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	res, err := t.client.ProxyGet(ctx, client.ProxyGetParams{
+		Cluster:   params.Cluster,
+		Kind:      kind,
+		Namespace: params.Namespace,
+		Name:      params.Name,
+		Scheme:    params.Scheme,
+		Port:      params.Port,
+		Path:      params.Path,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to send probe request", zap.String("tool", "probeHttpEndpoint"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, probeMaxBodyBytes+1))
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to read probe response", zap.String("tool", "probeHttpEndpoint"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	result := probeResult{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Headers:    map[string]string{},
+		Body:       string(body),
+	}
+	if len(body) > probeMaxBodyBytes {
+		result.Body = string(body[:probeMaxBodyBytes])
+		result.Truncated = true
+	}
+	for name, values := range res.Header {
+		result.Headers[name] = strings.Join(values, ", ")
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "probeHttpEndpoint"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeHTTPEndpoint(t *testing.T) {
+	fakeToken := "fakeToken"
+	// fake Rancher server proxying the requests to the local cluster
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/k8s/clusters/local/api/v1/namespaces/default/services/http:nginx:80/proxy/healthz":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("ok " + r.URL.RawQuery))
+		case "/k8s/clusters/local/api/v1/namespaces/default/pods/nginx-pod/proxy/":
+			w.Write([]byte(strings.Repeat("a", probeMaxBodyBytes+10)))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`no endpoints available for service "missing"`))
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		params             probeHTTPEndpointParams
+		expectedStatusCode int
+		expectedBody       string
+		expectedTruncated  bool
+		expectedHeaders    map[string]string
+		expectedError      string
+	}{
+		"probe service": {
+			params:             probeHTTPEndpointParams{Kind: "Service", Name: "nginx", Namespace: "default", Cluster: "local", Port: "80", Scheme: "http", Path: "/healthz?verbose=true"},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "ok verbose=true",
+			expectedHeaders:    map[string]string{"Content-Type": "text/plain"},
+		},
+		"probe pod with truncated body": {
+			params:             probeHTTPEndpointParams{Kind: "Pod", Name: "nginx-pod", Namespace: "default", Cluster: "local"},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       strings.Repeat("a", probeMaxBodyBytes),
+			expectedTruncated:  true,
+		},
+		"service unavailable": {
+			params:             probeHTTPEndpointParams{Kind: "Service", Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `no endpoints available for service "missing"`,
+		},
+		"unsupported kind": {
+			params:        probeHTTPEndpointParams{Kind: "Deployment", Name: "nginx", Namespace: "default", Cluster: "local"},
+			expectedError: "unsupported kind Deployment, must be Service or Pod",
+		},
+		"unsupported scheme": {
+			params:        probeHTTPEndpointParams{Kind: "Service", Name: "nginx", Namespace: "default", Cluster: "local", Scheme: "ftp"},
+			expectedError: "unsupported scheme ftp, must be http or https",
+		},
+		"path escaping the proxy": {
+			params:        probeHTTPEndpointParams{Kind: "Service", Name: "nginx", Namespace: "default", Cluster: "local", Path: "/../../secrets/admin"},
+			expectedError: `invalid path "/../../secrets/admin"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeToolsClient(client.NewClient(true), fakeToken)}
+
+			result, _, err := tools.probeHTTPEndpoint(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var probe probeResult
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &probe))
+			assert.Equal(t, test.expectedStatusCode, probe.StatusCode)
+			assert.Equal(t, test.expectedBody, probe.Body)
+			assert.Equal(t, test.expectedTruncated, probe.Truncated)
+			for header, value := range test.expectedHeaders {
+				assert.Equal(t, value, probe.Headers[header])
+			}
+		})
+	}
+}
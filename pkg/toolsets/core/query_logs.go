@@ -0,0 +1,372 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	lokiBackend          = "loki"
+	elasticsearchBackend = "elasticsearch"
+
+	// logsQueryTimeout is the maximum time allowed for a query of the logging backend.
+	logsQueryTimeout = 30 * time.Second
+	// defaultLogsSince and maxLogsSince are the default and the maximum time range of the queries.
+	defaultLogsSince = time.Hour
+	maxLogsSince     = 30 * 24 * time.Hour
+	// defaultLogsLimit and maxLogsLimit are the default and the maximum number of log lines returned.
+	defaultLogsLimit = 100
+	maxLogsLimit     = 500
+	// logsMaxLineBytes is the maximum size of each log line returned to the LLM.
+	logsMaxLineBytes = 2 * 1024
+)
+
+// logsBackendService is a Service of a logging backend storing the logs of the cluster.
+type logsBackendService struct {
+	backend   string
+	namespace string
+	name      string
+	port      string
+}
+
+// logsBackendServices contains the Services of the logging backends, in the order they are looked for. Loki is
+// installed by its chart or as an output of rancher-logging, Elasticsearch as an output of rancher-logging.
+var logsBackendServices = []logsBackendService{
+	{backend: lokiBackend, namespace: "cattle-logging-system", name: "loki", port: "3100"},
+	{backend: lokiBackend, namespace: "loki", name: "loki-gateway", port: "80"},
+	{backend: lokiBackend, namespace: "loki", name: "loki", port: "3100"},
+	{backend: elasticsearchBackend, namespace: "cattle-logging-system", name: "elasticsearch-master", port: "9200"},
+	{backend: elasticsearchBackend, namespace: "logging", name: "elasticsearch-master", port: "9200"},
+}
+
+type queryLogsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the workload"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the workload"`
+	Workload  string `json:"workload,omitempty" jsonschema:"the name of the workload or pod, the logs of the pods whose name starts with it are returned"`
+	Container string `json:"container,omitempty" jsonschema:"only return the logs of this container"`
+	Search    string `json:"search,omitempty" jsonschema:"only return the log lines containing this text"`
+	Since     string `json:"since,omitempty" jsonschema:"the time range of the query ending now (e.g. 6h). Defaults to 1h"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"the maximum number of log lines returned. Defaults to 100"`
+	Backend   string `json:"backend,omitempty" jsonschema:"the logging backend queried, loki or elasticsearch. Detected if empty"`
+}
+
+// logEntry is a log line in the response of queryLogs.
+type logEntry struct {
+	Time      string `json:"time"`
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+	Line      string `json:"line"`
+}
+
+// queryLogsResult is the response of queryLogs.
+type queryLogsResult struct {
+	Backend string     `json:"backend"`
+	Query   string     `json:"query"`
+	Entries []logEntry `json:"entries"`
+	// Truncated is true if the limit was reached, older lines may match the query.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// lokiQueryResponse is the response of the query_range API of Loki for log queries.
+type lokiQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// elasticsearchSearchResponse is the response of the search API of Elasticsearch for the logs shipped by fluentd.
+type elasticsearchSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source struct {
+				Timestamp  string `json:"@timestamp"`
+				Message    string `json:"message"`
+				Log        string `json:"log"`
+				Kubernetes struct {
+					PodName       string `json:"pod_name"`
+					ContainerName string `json:"container_name"`
+				} `json:"kubernetes"`
+			} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// queryLogs returns the historical logs of a workload from Loki or Elasticsearch. Unlike getPodLogs, it returns the
+// logs of deleted pods and of containers restarted more than once.
+func (t *Tools) queryLogs(ctx context.Context, toolReq *mcp.CallToolRequest, params queryLogsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("queryLogs called")
+
+	if params.Namespace == "" {
+		return nil, nil, errors.New("namespace is required")
+	}
+	if params.Backend != "" && params.Backend != lokiBackend && params.Backend != elasticsearchBackend {
+		return nil, nil, fmt.Errorf("unknown backend %q, must be %s or %s", params.Backend, lokiBackend, elasticsearchBackend)
+	}
+	since := defaultLogsSince
+	if params.Since != "" {
+		var err error
+		since, err = time.ParseDuration(params.Since)
+		if err != nil || since <= 0 {
+			return nil, nil, fmt.Errorf("invalid since %q, must be a duration like 6h", params.Since)
+		}
+		if since > maxLogsSince {
+			return nil, nil, fmt.Errorf("since %s is too long, logs can be queried over at most %s", params.Since, maxLogsSince)
+		}
+	}
+	limit := cmp.Or(params.Limit, defaultLogsLimit)
+	if limit < 0 || limit > maxLogsLimit {
+		return nil, nil, fmt.Errorf("invalid limit %d, must be between 1 and %d", params.Limit, maxLogsLimit)
+	}
+
+	rancherURL := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	service, err := t.logsBackendService(ctx, params.Cluster, rancherURL, token, params.Backend)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to find logging backend", zap.String("tool", "queryLogs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, logsQueryTimeout)
+	defer cancel()
+	proxyParams := client.ProxyGetParams{
+		Cluster:   params.Cluster,
+		Kind:      "service",
+		Namespace: service.namespace,
+		Name:      service.name,
+		Scheme:    "http",
+		Port:      service.port,
+		URL:       rancherURL,
+		Token:     token,
+	}
+	end := time.Now()
+	start := end.Add(-since)
+	result := queryLogsResult{Backend: service.backend}
+	if service.backend == lokiBackend {
+		result.Query = lokiQuery(params)
+		result.Entries, err = t.queryLoki(ctx, proxyParams, result.Query, start, end, limit)
+	} else {
+		var body []byte
+		body, err = elasticsearchQuery(params, start, end, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Query = string(body)
+		result.Entries, err = t.queryElasticsearch(ctx, proxyParams, body)
+	}
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to query logs", zap.String("tool", "queryLogs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	// the backends return the most recent lines first, so the limit keeps the lines closest to now
+	slices.SortStableFunc(result.Entries, func(a, b logEntry) int { return cmp.Compare(b.Time, a.Time) })
+	if len(result.Entries) >= limit {
+		result.Entries = result.Entries[:limit]
+		result.Truncated = true
+	}
+	slices.Reverse(result.Entries)
+	for i := range result.Entries {
+		result.Entries[i].Line = truncate(result.Entries[i].Line, logsMaxLineBytes)
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "queryLogs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// logsBackendService returns the first Service of a logging backend found in the cluster.
+func (t *Tools) logsBackendService(ctx context.Context, cluster string, url string, token string, backend string) (logsBackendService, error) {
+	var searched []string
+	for _, service := range logsBackendServices {
+		if backend != "" && service.backend != backend {
+			continue
+		}
+		_, err := t.client.GetResource(ctx, client.GetParams{
+			Cluster:   cluster,
+			Kind:      "service",
+			Namespace: service.namespace,
+			Name:      service.name,
+			URL:       url,
+			Token:     token,
+		})
+		if err == nil {
+			return service, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return logsBackendService{}, err
+		}
+		searched = append(searched, service.namespace+"/"+service.name)
+	}
+
+	return logsBackendService{}, fmt.Errorf("no logging backend found in cluster %s, install Loki or rancher-logging with an Elasticsearch output. The Services searched were: %s", cluster, strings.Join(searched, ", "))
+}
+
+// lokiQuery returns the LogQL query of the params. The user input is quoted, so it can't change the query.
+func lokiQuery(params queryLogsParams) string {
+	matchers := []string{"namespace=" + strconv.Quote(params.Namespace)}
+	if params.Workload != "" {
+		matchers = append(matchers, "pod=~"+strconv.Quote(regexp.QuoteMeta(params.Workload)+".*"))
+	}
+	if params.Container != "" {
+		matchers = append(matchers, "container="+strconv.Quote(params.Container))
+	}
+	query := "{" + strings.Join(matchers, ",") + "}"
+	if params.Search != "" {
+		query += " |= " + strconv.Quote(params.Search)
+	}
+
+	return query
+}
+
+func (t *Tools) queryLoki(ctx context.Context, params client.ProxyGetParams, query string, start, end time.Time, limit int) ([]logEntry, error) {
+	params.Path = "/loki/api/v1/query_range?" + url.Values{
+		"query":     {query},
+		"start":     {strconv.FormatInt(start.UnixNano(), 10)},
+		"end":       {strconv.FormatInt(end.UnixNano(), 10)},
+		"limit":     {strconv.Itoa(limit)},
+		"direction": {"backward"},
+	}.Encode()
+	body, err := t.logsBackendRequest(ctx, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	var lokiResponse lokiQueryResponse
+	if err := json.Unmarshal(body, &lokiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse loki response: %w", err)
+	}
+	if lokiResponse.Status != "success" {
+		return nil, fmt.Errorf("loki query failed: %s", lokiResponse.Error)
+	}
+
+	var entries []logEntry
+	for _, stream := range lokiResponse.Data.Result {
+		for _, value := range stream.Values {
+			timestamp, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse loki timestamp %q: %w", value[0], err)
+			}
+			entries = append(entries, logEntry{
+				Time:      time.Unix(0, timestamp).UTC().Format(time.RFC3339Nano),
+				Pod:       stream.Stream["pod"],
+				Container: stream.Stream["container"],
+				Line:      value[1],
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// elasticsearchQuery returns the search request of the params, matching the metadata added by the kubernetes
+// filter of fluentd.
+func elasticsearchQuery(params queryLogsParams, start, end time.Time, limit int) ([]byte, error) {
+	filters := []any{
+		map[string]any{"match_phrase": map[string]any{"kubernetes.namespace_name": params.Namespace}},
+		map[string]any{"range": map[string]any{"@timestamp": map[string]any{
+			"gte": start.UTC().Format(time.RFC3339),
+			"lte": end.UTC().Format(time.RFC3339),
+		}}},
+	}
+	if params.Workload != "" {
+		filters = append(filters, map[string]any{"prefix": map[string]any{"kubernetes.pod_name.keyword": params.Workload}})
+	}
+	if params.Container != "" {
+		filters = append(filters, map[string]any{"match_phrase": map[string]any{"kubernetes.container_name": params.Container}})
+	}
+	if params.Search != "" {
+		filters = append(filters, map[string]any{"multi_match": map[string]any{
+			"query":  params.Search,
+			"type":   "phrase",
+			"fields": []string{"message", "log"},
+		}})
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"size":  limit,
+		"sort":  []any{map[string]any{"@timestamp": "desc"}},
+		"query": map[string]any{"bool": map[string]any{"filter": filters}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return body, nil
+}
+
+func (t *Tools) queryElasticsearch(ctx context.Context, params client.ProxyGetParams, query []byte) ([]logEntry, error) {
+	params.Path = "/_search?ignore_unavailable=true"
+	body, err := t.logsBackendRequest(ctx, params, query)
+	if err != nil {
+		return nil, err
+	}
+	var searchResponse elasticsearchSearchResponse
+	if err := json.Unmarshal(body, &searchResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse elasticsearch response: %w", err)
+	}
+
+	var entries []logEntry
+	for _, hit := range searchResponse.Hits.Hits {
+		entries = append(entries, logEntry{
+			Time:      hit.Source.Timestamp,
+			Pod:       hit.Source.Kubernetes.PodName,
+			Container: hit.Source.Kubernetes.ContainerName,
+			Line:      strings.TrimSuffix(cmp.Or(hit.Source.Message, hit.Source.Log), "\n"),
+		})
+	}
+
+	return entries, nil
+}
+
+// logsBackendRequest sends a GET request to the logging backend, or a POST request if the body is not nil, and
+// returns the body of the response.
+func (t *Tools) logsBackendRequest(ctx context.Context, params client.ProxyGetParams, body []byte) ([]byte, error) {
+	var res *http.Response
+	var err error
+	if body == nil {
+		res, err = t.client.ProxyGet(ctx, params)
+	} else {
+		res, err = t.client.ProxyPost(ctx, params, body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logging backend response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("logs query failed with status %s: %s", res.Status, truncate(string(resBody), probeMaxBodyBytes))
+	}
+
+	return resBody, nil
+}
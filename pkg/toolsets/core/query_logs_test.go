@@ -0,0 +1,175 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	fakeLokiService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loki-gateway",
+			Namespace: "loki",
+		},
+	}
+	fakeElasticsearchService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-master",
+			Namespace: "cattle-logging-system",
+		},
+	}
+)
+
+func TestQueryLogs(t *testing.T) {
+	fakeToken := "fakeToken"
+	var requests []string
+	// fake Rancher server proxying the requests to the logging backends of the local cluster
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/k8s/clusters/local/api/v1/namespaces/loki/services/http:loki-gateway:80/proxy/loki/api/v1/query_range":
+			requests = append(requests, r.URL.Query().Get("query"))
+			if r.URL.Query().Get("direction") != "backward" || r.URL.Query().Get("limit") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"namespace":"default","pod":"nginx-abc12","container":"nginx"},"values":[["1714521720000000000","GET /missing 404"],["1714521600000000000","GET / 200"]]},
+				{"stream":{"namespace":"default","pod":"nginx-def34","container":"nginx"},"values":[["1714521660000000000","GET /api 500"]]}
+			]}}`))
+		case "/k8s/clusters/local/api/v1/namespaces/cattle-logging-system/services/http:elasticsearch-master:9200/proxy/_search":
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, string(body))
+			w.Write([]byte(`{"hits":{"hits":[
+				{"_source":{"@timestamp":"2024-05-01T00:02:00Z","log":"connection refused\n","kubernetes":{"pod_name":"api-abc12","container_name":"api"}}},
+				{"_source":{"@timestamp":"2024-05-01T00:01:00Z","message":"starting","kubernetes":{"pod_name":"api-abc12","container_name":"api"}}}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		params           queryLogsParams
+		objects          []runtime.Object
+		expectedRequests []string
+		expectedResult   string
+		expectedEntries  []logEntry
+		expectedError    string
+	}{
+		"loki": {
+			params:           queryLogsParams{Cluster: "local", Namespace: "default", Workload: "nginx", Search: "GET"},
+			objects:          []runtime.Object{fakeLokiService},
+			expectedRequests: []string{`{namespace="default",pod=~"nginx.*"} |= "GET"`},
+			expectedResult:   `{"backend":"loki","query":"{namespace=\"default\",pod=~\"nginx.*\"} |= \"GET\"","entries":[{"time":"2024-05-01T00:00:00Z","pod":"nginx-abc12","container":"nginx","line":"GET / 200"},{"time":"2024-05-01T00:01:00Z","pod":"nginx-def34","container":"nginx","line":"GET /api 500"},{"time":"2024-05-01T00:02:00Z","pod":"nginx-abc12","container":"nginx","line":"GET /missing 404"}]}`,
+		},
+		"loki input is escaped": {
+			params:           queryLogsParams{Cluster: "local", Namespace: `default"}`, Workload: "web.v2", Container: "app", Search: `"error"`},
+			objects:          []runtime.Object{fakeLokiService},
+			expectedRequests: []string{`{namespace="default\"}",pod=~"web\\.v2.*",container="app"} |= "\"error\""`},
+		},
+		"limit keeps the most recent lines": {
+			params:           queryLogsParams{Cluster: "local", Namespace: "default", Limit: 1},
+			objects:          []runtime.Object{fakeLokiService},
+			expectedRequests: []string{`{namespace="default"}`},
+			expectedResult:   `{"backend":"loki","query":"{namespace=\"default\"}","entries":[{"time":"2024-05-01T00:02:00Z","pod":"nginx-abc12","container":"nginx","line":"GET /missing 404"}],"truncated":true}`,
+		},
+		"elasticsearch": {
+			params:  queryLogsParams{Cluster: "local", Namespace: "default", Workload: "api"},
+			objects: []runtime.Object{fakeElasticsearchService},
+			expectedEntries: []logEntry{
+				{Time: "2024-05-01T00:01:00Z", Pod: "api-abc12", Container: "api", Line: "starting"},
+				{Time: "2024-05-01T00:02:00Z", Pod: "api-abc12", Container: "api", Line: "connection refused"},
+			},
+		},
+		"backend not installed": {
+			params:        queryLogsParams{Cluster: "local", Namespace: "default"},
+			expectedError: "no logging backend found in cluster local, install Loki or rancher-logging with an Elasticsearch output. The Services searched were: cattle-logging-system/loki, loki/loki-gateway, loki/loki, cattle-logging-system/elasticsearch-master, logging/elasticsearch-master",
+		},
+		"backend selected": {
+			params:        queryLogsParams{Cluster: "local", Namespace: "default", Backend: "elasticsearch"},
+			objects:       []runtime.Object{fakeLokiService},
+			expectedError: "The Services searched were: cattle-logging-system/elasticsearch-master, logging/elasticsearch-master",
+		},
+		"unknown backend": {
+			params:        queryLogsParams{Cluster: "local", Namespace: "default", Backend: "splunk"},
+			expectedError: `unknown backend "splunk", must be loki or elasticsearch`,
+		},
+		"missing namespace": {
+			params:        queryLogsParams{Cluster: "local"},
+			expectedError: "namespace is required",
+		},
+		"since too long": {
+			params:        queryLogsParams{Cluster: "local", Namespace: "default", Since: "2000h"},
+			expectedError: "since 2000h is too long, logs can be queried over at most 720h0m0s",
+		},
+		"invalid limit": {
+			params:        queryLogsParams{Cluster: "local", Namespace: "default", Limit: 1000},
+			expectedError: "invalid limit 1000, must be between 1 and 500",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests = nil
+			tools := newFakeProxyTools(fakeToken, test.objects...)
+
+			result, _, err := tools.queryLogs(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if test.expectedRequests != nil {
+				assert.Equal(t, test.expectedRequests, requests)
+			}
+			if test.expectedResult != "" {
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+			if test.expectedEntries != nil {
+				var logs queryLogsResult
+				require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &logs))
+				assert.Equal(t, test.expectedEntries, logs.Entries)
+			}
+		})
+	}
+}
+
+func TestElasticsearchQuery(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	body, err := elasticsearchQuery(queryLogsParams{Namespace: "default", Workload: "api", Container: "app", Search: "refused"}, start, start.Add(time.Hour), 10)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"size":10,"sort":[{"@timestamp":"desc"}],"query":{"bool":{"filter":[
+		{"match_phrase":{"kubernetes.namespace_name":"default"}},
+		{"range":{"@timestamp":{"gte":"2024-05-01T00:00:00Z","lte":"2024-05-01T01:00:00Z"}}},
+		{"prefix":{"kubernetes.pod_name.keyword":"api"}},
+		{"match_phrase":{"kubernetes.container_name":"app"}},
+		{"multi_match":{"query":"refused","type":"phrase","fields":["message","log"]}}
+	]}}}`, string(body))
+}
@@ -0,0 +1,347 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// monitoringNamespace, prometheusService and prometheusPort identify the Prometheus installed by the
+	// rancher-monitoring chart.
+	monitoringNamespace = "cattle-monitoring-system"
+	prometheusService   = "rancher-monitoring-prometheus"
+	prometheusPort      = "9090"
+
+	// metricsQueryTimeout is the maximum time allowed for a Prometheus query.
+	metricsQueryTimeout = 30 * time.Second
+	// metricsMaxSeries is the number of series returned to the LLM, the others are only counted.
+	metricsMaxSeries = 20
+	// metricsMaxPoints is the number of points of each series of a range query when the step is not set.
+	metricsMaxPoints = 60
+	// metricsMinStep is the minimum step of range queries, the default scrape interval of rancher-monitoring.
+	metricsMinStep = 30 * time.Second
+)
+
+// metricNameRegexp matches the valid Prometheus metric names.
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// metricsTemplate is a PromQL query of the template library. The selector of the workload is added to the query,
+// so the user input is never part of the PromQL expression.
+type metricsTemplate struct {
+	description string
+	// query returns the query with the selector of the series of the workload, e.g. {namespace="default",pod=~"nginx.*"}.
+	query func(selector string, metric string) string
+	// defaultMetric is the metric used by the templates that can query different metrics, such as latency histograms.
+	defaultMetric string
+}
+
+// metricsTemplates contains the PromQL queries of the common troubleshooting questions.
+var metricsTemplates = map[string]metricsTemplate{
+	"cpuUsage": {
+		description: "CPU cores used by each container",
+		query: func(selector string, _ string) string {
+			return fmt.Sprintf(`sum by (pod, container) (rate(container_cpu_usage_seconds_total%s[5m]))`, selector)
+		},
+	},
+	"memoryUsage": {
+		description: "working set memory bytes of each container",
+		query: func(selector string, _ string) string {
+			return fmt.Sprintf(`sum by (pod, container) (container_memory_working_set_bytes%s)`, selector)
+		},
+	},
+	"cpuThrottling": {
+		description: "ratio of CPU periods throttled by the CPU limit of each container",
+		query: func(selector string, _ string) string {
+			return fmt.Sprintf(`sum by (pod, container) (rate(container_cpu_cfs_throttled_periods_total%[1]s[5m])) / sum by (pod, container) (rate(container_cpu_cfs_periods_total%[1]s[5m]))`, selector)
+		},
+	},
+	"restartRate": {
+		description: "container restarts in the last hour",
+		query: func(selector string, _ string) string {
+			return fmt.Sprintf(`sum by (pod, container) (increase(kube_pod_container_status_restarts_total%s[1h]))`, selector)
+		},
+	},
+	"p95Latency": {
+		description: "95th percentile of the request duration histogram of each pod, in the units of the histogram",
+		query: func(selector string, metric string) string {
+			return fmt.Sprintf(`histogram_quantile(0.95, sum by (pod, le) (rate(%s_bucket%s[5m])))`, metric, selector)
+		},
+		defaultMetric: "http_request_duration_seconds",
+	},
+}
+
+type queryMetricsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Prometheus"`
+	Query     string `json:"query,omitempty" jsonschema:"the PromQL query, used instead of a template"`
+	Template  string `json:"template,omitempty" jsonschema:"the template of the query: cpuUsage, memoryUsage, cpuThrottling, restartRate or p95Latency" validate:"enum=cpuUsage|memoryUsage|cpuThrottling|restartRate|p95Latency"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the workload, required by the templates"`
+	Workload  string `json:"workload,omitempty" jsonschema:"the name of the workload or pod, the templates query the pods whose name starts with it"`
+	Metric    string `json:"metric,omitempty" jsonschema:"the histogram metric of the p95Latency template, without the _bucket suffix. Defaults to http_request_duration_seconds"`
+	Range     string `json:"range,omitempty" jsonschema:"the time range of the query (e.g. 1h, 24h). If empty, the current values are returned"`
+	Step      string `json:"step,omitempty" jsonschema:"the resolution of a range query (e.g. 5m). Defaults to a step returning 60 points"`
+}
+
+// prometheusResponse is the response of the query and query_range endpoints of the Prometheus HTTP API.
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+			Values [][]any           `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// metricsSeries is a series of the result of queryMetrics. Range queries have the values and a summary of them.
+type metricsSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  string            `json:"value,omitempty"`
+	Values [][]any           `json:"values,omitempty"`
+	Min    *float64          `json:"min,omitempty"`
+	Max    *float64          `json:"max,omitempty"`
+	Avg    *float64          `json:"avg,omitempty"`
+	Last   *float64          `json:"last,omitempty"`
+}
+
+// queryMetricsResult is the response of queryMetrics.
+type queryMetricsResult struct {
+	Query  string          `json:"query"`
+	Start  string          `json:"start,omitempty"`
+	End    string          `json:"end,omitempty"`
+	Step   string          `json:"step,omitempty"`
+	Series []metricsSeries `json:"series"`
+	// OmittedSeries is the number of series not returned because of metricsMaxSeries.
+	OmittedSeries int `json:"omittedSeries,omitempty"`
+}
+
+// queryMetrics runs a PromQL query, or a query of the template library, in the Prometheus of rancher-monitoring
+// through the API server proxy.
+func (t *Tools) queryMetrics(ctx context.Context, toolReq *mcp.CallToolRequest, params queryMetricsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("queryMetrics called")
+
+	query, err := metricsQuery(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	result := queryMetricsResult{Query: query}
+	if params.Range != "" {
+		queryRange, step, err := metricsRange(params.Range, params.Step)
+		if err != nil {
+			return nil, nil, err
+		}
+		end := time.Now().UTC().Truncate(time.Second)
+		start := end.Add(-queryRange)
+		result.Start, result.End, result.Step = start.Format(time.RFC3339), end.Format(time.RFC3339), step.String()
+		path = "/api/v1/query_range?" + url.Values{
+			"query": {query},
+			"start": {strconv.FormatInt(start.Unix(), 10)},
+			"end":   {strconv.FormatInt(end.Unix(), 10)},
+			"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+		}.Encode()
+	}
+
+	rancherURL := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	if err := t.checkMonitoringService(ctx, params.Cluster, rancherURL, token, prometheusService); err != nil {
+		middleware.Logger(ctx).Error("failed to get prometheus service", zap.String("tool", "queryMetrics"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metricsQueryTimeout)
+	defer cancel()
+	res, err := t.client.ProxyGet(ctx, client.ProxyGetParams{
+		Cluster:   params.Cluster,
+		Kind:      "service",
+		Namespace: monitoringNamespace,
+		Name:      prometheusService,
+		Scheme:    "http",
+		Port:      prometheusPort,
+		Path:      path,
+		URL:       rancherURL,
+		Token:     token,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to query prometheus", zap.String("tool", "queryMetrics"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read prometheus response: %w", err)
+	}
+	var promResponse prometheusResponse
+	if err := json.Unmarshal(body, &promResponse); err != nil {
+		return nil, nil, fmt.Errorf("unexpected prometheus response with status %s: %s", res.Status, truncate(string(body), probeMaxBodyBytes))
+	}
+	if promResponse.Status != "success" {
+		return nil, nil, fmt.Errorf("prometheus query failed: %s: %s", promResponse.ErrorType, promResponse.Error)
+	}
+
+	for i, r := range promResponse.Data.Result {
+		if i >= metricsMaxSeries {
+			result.OmittedSeries = len(promResponse.Data.Result) - metricsMaxSeries
+			break
+		}
+		series := metricsSeries{Metric: r.Metric}
+		if len(r.Value) == 2 {
+			series.Value = fmt.Sprint(r.Value[1])
+		}
+		if len(r.Values) > 0 {
+			series.Values = r.Values
+			summarizeSeries(&series)
+		}
+		result.Series = append(result.Series, series)
+	}
+	if result.Series == nil {
+		result.Series = []metricsSeries{}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "queryMetrics"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// checkMonitoringService checks that a Service of rancher-monitoring exists, so the LLM gets a clear error if
+// rancher-monitoring isn't installed instead of a proxy error.
+func (t *Tools) checkMonitoringService(ctx context.Context, cluster string, url string, token string, service string) error {
+	_, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "service",
+		Namespace: monitoringNamespace,
+		Name:      service,
+		URL:       url,
+		Token:     token,
+	})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("rancher-monitoring is not installed in cluster %s, the %s/%s Service was not found", cluster, monitoringNamespace, service)
+	}
+
+	return err
+}
+
+// metricsQuery returns the PromQL query of the params, either the raw query or the query of the template.
+func metricsQuery(params queryMetricsParams) (string, error) {
+	if params.Query != "" && params.Template != "" {
+		return "", errors.New("query and template can't be used together")
+	}
+	if params.Query != "" {
+		return params.Query, nil
+	}
+
+	template, ok := metricsTemplates[params.Template]
+	if !ok {
+		names := make([]string, 0, len(metricsTemplates))
+		for name, template := range metricsTemplates {
+			names = append(names, fmt.Sprintf("%s (%s)", name, template.description))
+		}
+		slices.Sort(names)
+		return "", fmt.Errorf("unknown template %q, use a query or one of the templates: %s", params.Template, strings.Join(names, ", "))
+	}
+	if params.Namespace == "" {
+		return "", fmt.Errorf("namespace is required by the %s template", params.Template)
+	}
+	metric := template.defaultMetric
+	if params.Metric != "" {
+		if !metricNameRegexp.MatchString(params.Metric) {
+			return "", fmt.Errorf("invalid metric name %q", params.Metric)
+		}
+		metric = params.Metric
+	}
+
+	// strconv.Quote escapes the values like PromQL string literals, and the workload is a literal prefix of the regexp
+	selector := "{namespace=" + strconv.Quote(params.Namespace)
+	if params.Workload != "" {
+		selector += ",pod=~" + strconv.Quote(regexp.QuoteMeta(params.Workload)+".*")
+	}
+	selector += "}"
+
+	return template.query(selector, metric), nil
+}
+
+// metricsRange parses the range and the step of a range query. The default step returns metricsMaxPoints points.
+func metricsRange(queryRange string, step string) (time.Duration, time.Duration, error) {
+	rangeDuration, err := time.ParseDuration(queryRange)
+	if err != nil || rangeDuration <= 0 {
+		return 0, 0, fmt.Errorf("invalid range %q, must be a duration like 1h", queryRange)
+	}
+	if step == "" {
+		return rangeDuration, max(rangeDuration/metricsMaxPoints, metricsMinStep).Round(time.Second), nil
+	}
+	stepDuration, err := time.ParseDuration(step)
+	if err != nil || stepDuration <= 0 {
+		return 0, 0, fmt.Errorf("invalid step %q, must be a duration like 5m", step)
+	}
+	if rangeDuration/stepDuration > 11000 {
+		// the maximum number of points of a series allowed by Prometheus
+		return 0, 0, fmt.Errorf("step %s is too small for range %s", step, queryRange)
+	}
+
+	return rangeDuration, stepDuration, nil
+}
+
+// summarizeSeries sets the minimum, maximum, average and last values of a series of a range query, so the LLM can
+// report the trend without reading every value.
+func summarizeSeries(series *metricsSeries) {
+	var values []float64
+	for _, point := range series.Values {
+		if len(point) != 2 {
+			continue
+		}
+		s, _ := point[1].(string)
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	minValue, maxValue, sum := values[0], values[0], 0.0
+	for _, value := range values {
+		minValue = min(minValue, value)
+		maxValue = max(maxValue, value)
+		sum += value
+	}
+	avg := sum / float64(len(values))
+	last := values[len(values)-1]
+	series.Min, series.Max, series.Avg, series.Last = &minValue, &maxValue, &avg, &last
+}
+
+// truncate returns the first n bytes of s.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+var fakePrometheusService = &corev1.Service{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      prometheusService,
+		Namespace: monitoringNamespace,
+	},
+}
+
+func TestQueryMetrics(t *testing.T) {
+	fakeToken := "fakeToken"
+	var queries []string
+	// fake Rancher server proxying the requests to the Prometheus of the local cluster
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case query == "up{":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected end of input"}`))
+		case r.URL.Path == "/k8s/clusters/local/api/v1/namespaces/cattle-monitoring-system/services/http:rancher-monitoring-prometheus:9090/proxy/api/v1/query":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"pod":"nginx-abc12","container":"nginx"},"value":[1714521600,"0.25"]}]}}`))
+		case r.URL.Path == "/k8s/clusters/local/api/v1/namespaces/cattle-monitoring-system/services/http:rancher-monitoring-prometheus:9090/proxy/api/v1/query_range":
+			if r.URL.Query().Get("step") != "60" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"pod":"nginx-abc12","container":"nginx"},"values":[[1714521600,"1"],[1714521660,"3"],[1714521720,"2"]]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		params          queryMetricsParams
+		objects         []runtime.Object
+		expectedResult  string
+		expectedQueries []string
+		expectedError   string
+	}{
+		"template": {
+			params:          queryMetricsParams{Cluster: "local", Template: "cpuThrottling", Namespace: "default", Workload: "nginx"},
+			objects:         []runtime.Object{fakePrometheusService},
+			expectedQueries: []string{`sum by (pod, container) (rate(container_cpu_cfs_throttled_periods_total{namespace="default",pod=~"nginx.*"}[5m])) / sum by (pod, container) (rate(container_cpu_cfs_periods_total{namespace="default",pod=~"nginx.*"}[5m]))`},
+			expectedResult:  `{"query":"sum by (pod, container) (rate(container_cpu_cfs_throttled_periods_total{namespace=\"default\",pod=~\"nginx.*\"}[5m])) / sum by (pod, container) (rate(container_cpu_cfs_periods_total{namespace=\"default\",pod=~\"nginx.*\"}[5m]))","series":[{"metric":{"container":"nginx","pod":"nginx-abc12"},"value":"0.25"}]}`,
+		},
+		"template input is escaped": {
+			params:          queryMetricsParams{Cluster: "local", Template: "restartRate", Namespace: `default"}`, Workload: "web.v2"},
+			objects:         []runtime.Object{fakePrometheusService},
+			expectedQueries: []string{`sum by (pod, container) (increase(kube_pod_container_status_restarts_total{namespace="default\"}",pod=~"web\\.v2.*"}[1h]))`},
+		},
+		"p95 latency with a custom metric": {
+			params:          queryMetricsParams{Cluster: "local", Template: "p95Latency", Namespace: "default", Metric: "nginx_request_duration_seconds"},
+			objects:         []runtime.Object{fakePrometheusService},
+			expectedQueries: []string{`histogram_quantile(0.95, sum by (pod, le) (rate(nginx_request_duration_seconds_bucket{namespace="default"}[5m])))`},
+		},
+		"range query": {
+			params:          queryMetricsParams{Cluster: "local", Query: "sum(rate(container_cpu_usage_seconds_total[5m]))", Range: "1h"},
+			objects:         []runtime.Object{fakePrometheusService},
+			expectedQueries: []string{"sum(rate(container_cpu_usage_seconds_total[5m]))"},
+		},
+		"prometheus error": {
+			params:        queryMetricsParams{Cluster: "local", Query: "up{"},
+			objects:       []runtime.Object{fakePrometheusService},
+			expectedError: "prometheus query failed: bad_data: unexpected end of input",
+		},
+		"monitoring not installed": {
+			params:        queryMetricsParams{Cluster: "local", Query: "up"},
+			expectedError: "rancher-monitoring is not installed in cluster local",
+		},
+		"unknown template": {
+			params:        queryMetricsParams{Cluster: "local", Template: "diskUsage", Namespace: "default"},
+			expectedError: `unknown template "diskUsage", use a query or one of the templates: cpuThrottling (ratio of CPU periods throttled`,
+		},
+		"template without namespace": {
+			params:        queryMetricsParams{Cluster: "local", Template: "cpuUsage"},
+			expectedError: "namespace is required by the cpuUsage template",
+		},
+		"invalid metric": {
+			params:        queryMetricsParams{Cluster: "local", Template: "p95Latency", Namespace: "default", Metric: "up) or vector(1"},
+			expectedError: `invalid metric name "up) or vector(1"`,
+		},
+		"query and template": {
+			params:        queryMetricsParams{Cluster: "local", Template: "cpuUsage", Query: "up"},
+			expectedError: "query and template can't be used together",
+		},
+		"invalid range": {
+			params:        queryMetricsParams{Cluster: "local", Query: "up", Range: "yesterday"},
+			expectedError: `invalid range "yesterday", must be a duration like 1h`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			queries = nil
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme(), test.objects...)
+			c := client.NewClient(true)
+			c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+				return fakeDynClient, nil
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.queryMetrics(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedQueries, queries)
+			if test.expectedResult != "" {
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
+
+func TestMetricsRange(t *testing.T) {
+	tests := map[string]struct {
+		queryRange    string
+		step          string
+		expectedRange string
+		expectedStep  string
+		expectedError string
+	}{
+		"default step": {
+			queryRange:    "24h",
+			expectedRange: "24h0m0s",
+			expectedStep:  "24m0s",
+		},
+		"minimum default step": {
+			queryRange:    "10m",
+			expectedRange: "10m0s",
+			expectedStep:  "30s",
+		},
+		"custom step": {
+			queryRange:    "6h",
+			step:          "5m",
+			expectedRange: "6h0m0s",
+			expectedStep:  "5m0s",
+		},
+		"too many points": {
+			queryRange:    "720h",
+			step:          "1s",
+			expectedError: "step 1s is too small for range 720h",
+		},
+		"invalid step": {
+			queryRange:    "1h",
+			step:          "-5m",
+			expectedError: `invalid step "-5m", must be a duration like 5m`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			queryRange, step, err := metricsRange(test.queryRange, test.step)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRange, queryRange.String())
+			assert.Equal(t, test.expectedStep, step.String())
+		})
+	}
+}
+
+func TestSummarizeSeries(t *testing.T) {
+	series := metricsSeries{Values: [][]any{{1714521600.0, "1"}, {1714521660.0, "NaN"}, {1714521720.0, "3"}, {1714521780.0, "2"}}}
+
+	summarizeSeries(&series)
+
+	require.NotNil(t, series.Min)
+	assert.Equal(t, 1.0, *series.Min)
+	assert.Equal(t, 3.0, *series.Max)
+	assert.Equal(t, 2.0, *series.Avg)
+	assert.Equal(t, 2.0, *series.Last)
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+)
+
+const (
+	// rawGetDefaultMaxBytes is the size of the response body returned by rawGet when maxBytes isn't set.
+	rawGetDefaultMaxBytes = 64 * 1024
+	// rawGetMaxBytes is the largest maxBytes accepted by rawGet.
+	rawGetMaxBytes = 1024 * 1024
+	// pathAnySuffix is the suffix of the allowlist entries matching all the paths starting with the entry.
+	pathAnySuffix = "*"
+)
+
+// DefaultRawGetAllowlist contains the API server paths allowed by default in rawGet: the version, the health checks,
+// the discovery of the API groups and the metrics of the API server. Resources are left out, so the Secrets can't be
+// read without the redaction of the structured tools.
+var DefaultRawGetAllowlist = []string{"/version", "/healthz*", "/livez*", "/readyz*", "/api", "/apis", "/metrics"}
+
+type rawGetParams struct {
+	Cluster  string `json:"cluster" jsonschema:"the cluster of the API server"`
+	Path     string `json:"path" jsonschema:"the path of the request, it may include a query string (e.g. /readyz?verbose)"`
+	Filter   string `json:"filter,omitempty" jsonschema:"regular expression, only matching lines of the response are returned"`
+	MaxBytes int    `json:"maxBytes,omitempty" jsonschema:"the maximum size of the response returned, 65536 by default and 1048576 at most"`
+}
+
+// rawGetResult is the response of the API server to a rawGet request.
+type rawGetResult struct {
+	Path        string `json:"path"`
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType,omitempty"`
+	// Truncated is true when the body was cut at maxBytes.
+	Truncated bool   `json:"truncated"`
+	Body      string `json:"body"`
+}
+
+// rawGet sends a GET request to an allowlisted path of the API server of a cluster, for the cases not covered by the
+// other tools. The status of the response is returned with its body, so the failed health checks can be explained.
+func (t *Tools) rawGet(ctx context.Context, toolReq *mcp.CallToolRequest, params rawGetParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("rawGet called")
+
+	requestPath, _, _ := strings.Cut(params.Path, "?")
+	if !pathAllowed(t.RawGetAllowlist, requestPath) {
+		return nil, nil, fmt.Errorf("path %q is not allowed, allowed paths are: %s", params.Path, strings.Join(t.RawGetAllowlist, ", "))
+	}
+	maxBytes := rawGetDefaultMaxBytes
+	if params.MaxBytes > 0 {
+		maxBytes = min(params.MaxBytes, rawGetMaxBytes)
+	}
+	var filter *regexp.Regexp
+	if params.Filter != "" {
+		var err error
+		filter, err = regexp.Compile(params.Filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid filter %q: %w", params.Filter, err)
+		}
+	}
+
+	resp, err := t.client.RawGet(ctx, client.RawGetParams{
+		Cluster: params.Cluster,
+		Path:    params.Path,
+		URL:     toolReq.Extra.Header.Get(urlHeader),
+		Token:   middleware.Token(ctx),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to send request", zap.String("tool", "rawGet"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get %s: %w", params.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, truncated, err := readRawBody(resp.Body, filter, maxBytes)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to read response", zap.String("tool", "rawGet"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to read the response of %s: %w", params.Path, err)
+	}
+
+	response, err := json.Marshal(rawGetResult{
+		Path:        params.Path,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Truncated:   truncated,
+		Body:        body,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "rawGet"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// pathAllowed reports whether the path matches an allowlist entry. An entry matches the same path, or all the paths
+// starting with it when it ends with "*" (e.g. "/readyz*"). Paths that aren't clean, such as the ones containing "..",
+// are never allowed.
+func pathAllowed(allowlist []string, requestPath string) bool {
+	if !strings.HasPrefix(requestPath, "/") || path.Clean(requestPath) != requestPath {
+		return false
+	}
+
+	for _, entry := range allowlist {
+		if prefix, ok := strings.CutSuffix(entry, pathAnySuffix); ok {
+			if prefix != "" && strings.HasPrefix(requestPath, prefix) {
+				return true
+			}
+			continue
+		}
+		if requestPath == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readRawBody reads at most maxBytes of the body, keeping only the lines that match the filter if it's not nil. It
+// returns true when the body was truncated.
+func readRawBody(r io.Reader, filter *regexp.Regexp, maxBytes int) (string, bool, error) {
+	if filter == nil {
+		body, err := io.ReadAll(io.LimitReader(r, int64(maxBytes)+1))
+		if err != nil {
+			return "", false, err
+		}
+		if len(body) > maxBytes {
+			return string(body[:maxBytes]), true, nil
+		}
+		return string(body), false, nil
+	}
+
+	var body strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !filter.MatchString(line) {
+			continue
+		}
+		if body.Len()+len(line)+1 > maxBytes {
+			return body.String(), true, nil
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+
+	return body.String(), false, scanner.Err()
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeAPIServerMetrics = `# HELP apiserver_request_total Counter of apiserver requests.
+# TYPE apiserver_request_total counter
+apiserver_request_total{code="200",verb="GET"} 1024
+apiserver_request_total{code="500",verb="GET"} 3
+etcd_request_duration_seconds_count{operation="get"} 42
+`
+
+// newFakeAPIServer returns a fake Rancher server proxying the requests to the API server of the local cluster.
+func newFakeAPIServer(t *testing.T, fakeToken string) *httptest.Server {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/k8s/clusters/local/version":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"major":"1","minor":"33","gitVersion":"v1.33.1+rke2r1"}`))
+		case "/k8s/clusters/local/readyz":
+			if r.URL.RawQuery != "verbose" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("[+]ping ok\n[-]etcd failed: reason withheld\nreadyz check failed\n"))
+		case "/k8s/clusters/local/metrics":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(fakeAPIServerMetrics))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestRawGet(t *testing.T) {
+	fakeToken := "fakeToken"
+	srv := newFakeAPIServer(t, fakeToken)
+
+	tests := map[string]struct {
+		params         rawGetParams
+		expectedResult string
+		expectedError  string
+	}{
+		"version": {
+			params:         rawGetParams{Cluster: "local", Path: "/version"},
+			expectedResult: `{"path":"/version","statusCode":200,"contentType":"application/json","truncated":false,"body":"{\"major\":\"1\",\"minor\":\"33\",\"gitVersion\":\"v1.33.1+rke2r1\"}"}`,
+		},
+		"failed readiness check": {
+			params:         rawGetParams{Cluster: "local", Path: "/readyz?verbose"},
+			expectedResult: `{"path":"/readyz?verbose","statusCode":500,"contentType":"text/plain","truncated":false,"body":"[+]ping ok\n[-]etcd failed: reason withheld\nreadyz check failed\n"}`,
+		},
+		"filtered metrics": {
+			params:         rawGetParams{Cluster: "local", Path: "/metrics", Filter: `^apiserver_request_total\{code="5`},
+			expectedResult: `{"path":"/metrics","statusCode":200,"contentType":"text/plain","truncated":false,"body":"apiserver_request_total{code=\"500\",verb=\"GET\"} 3\n"}`,
+		},
+		"truncated body": {
+			params:         rawGetParams{Cluster: "local", Path: "/metrics", MaxBytes: 20},
+			expectedResult: `{"path":"/metrics","statusCode":200,"contentType":"text/plain","truncated":true,"body":"# HELP apiserver_req"}`,
+		},
+		"truncated filtered body": {
+			params:         rawGetParams{Cluster: "local", Path: "/metrics", Filter: "^apiserver", MaxBytes: 60},
+			expectedResult: `{"path":"/metrics","statusCode":200,"contentType":"text/plain","truncated":true,"body":"apiserver_request_total{code=\"200\",verb=\"GET\"} 1024\n"}`,
+		},
+		"path not allowed": {
+			params:        rawGetParams{Cluster: "local", Path: "/api/v1/secrets"},
+			expectedError: `path "/api/v1/secrets" is not allowed, allowed paths are: /version, /healthz*, /livez*, /readyz*, /api, /apis, /metrics`,
+		},
+		"invalid filter": {
+			params:        rawGetParams{Cluster: "local", Path: "/metrics", Filter: "("},
+			expectedError: `invalid filter "("`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeToolsClient(client.NewClient(true), fakeToken), RawGetAllowlist: DefaultRawGetAllowlist}
+
+			result, _, err := tools.rawGet(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestPathAllowed(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected bool
+	}{
+		"exact path":             {path: "/version", expected: true},
+		"prefix entry":           {path: "/readyz/etcd", expected: true},
+		"prefix entry itself":    {path: "/livez", expected: true},
+		"longer exact path":      {path: "/apis/apps/v1", expected: false},
+		"relative path":          {path: "version", expected: false},
+		"path traversal":         {path: "/healthz/../api/v1/secrets", expected: false},
+		"trailing slash":         {path: "/version/", expected: false},
+		"double slash":           {path: "//version", expected: false},
+		"path not in allowlist":  {path: "/api/v1/namespaces/default/secrets", expected: false},
+		"empty path":             {path: "", expected: false},
+		"root path":              {path: "/", expected: false},
+		"wildcard-looking input": {path: "/healthz*", expected: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, pathAllowed(DefaultRawGetAllowlist, test.path))
+		})
+	}
+
+	assert.False(t, pathAllowed([]string{"*"}, "/api/v1/secrets"), "a bare '*' entry must not allow every path")
+}
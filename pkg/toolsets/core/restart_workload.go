@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// restartedAtAnn is the Pod template annotation set by 'kubectl rollout restart' to roll out the Pods again.
+const restartedAtAnn = "kubectl.kubernetes.io/restartedAt"
+
+// now returns the current time. It is replaced in tests.
+var now = time.Now
+
+// restartWorkload restarts the Pods of a workload the same way as 'kubectl rollout restart', by setting the
+// restartedAt annotation of its Pod template.
+func (t *Tools) restartWorkload(ctx context.Context, toolReq *mcp.CallToolRequest, params workloadParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("restartWorkload called")
+
+	resourceInterface, err := t.getWorkloadInterface(ctx, toolReq, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{
+						restartedAtAnn: now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to restart workload", zap.String("tool", "restartWorkload"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to restart %s %s: %w", params.Kind, params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "restartWorkload"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
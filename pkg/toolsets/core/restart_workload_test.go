@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRestartWorkload(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	daemonSet := &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+	}
+
+	tests := map[string]struct {
+		params        workloadParams
+		expectedError string
+	}{
+		"restart deployment": {
+			params: workloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local"},
+		},
+		"restart daemonset": {
+			params: workloadParams{Kind: "daemonset", Name: "web", Namespace: "default", Cluster: "local"},
+		},
+		"unsupported kind": {
+			params:        workloadParams{Kind: "Pod", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedError: "kind Pod doesn't support rollouts, must be Deployment, StatefulSet or DaemonSet",
+		},
+		"workload not found": {
+			params:        workloadParams{Kind: "StatefulSet", Name: "web", Namespace: "default", Cluster: "local"},
+			expectedError: "failed to restart StatefulSet web",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, _ := newRolloutTools(newRolloutDeployment("1", "web:1", false), daemonSet)
+
+			result, _, err := tools.restartWorkload(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			items := llmItems(t, result)
+			require.Len(t, items, 1)
+			restartedAt, _, _ := unstructured.NestedString(items[0], "spec", "template", "metadata", "annotations", restartedAtAnn)
+			assert.Equal(t, "2025-03-01T12:00:00Z", restartedAt)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// revisionAnn is the annotation of the Deployments and ReplicaSets holding the revision of the rollout.
+const revisionAnn = "deployment.kubernetes.io/revision"
+
+type rollbackDeploymentParams struct {
+	Name      string `json:"name" jsonschema:"the name of the Deployment"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the Deployment"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the Deployment"`
+	Revision  int64  `json:"revision,omitempty" jsonschema:"the revision to roll back to, defaults to the previous revision"`
+}
+
+// rollbackDeployment rolls a Deployment back to the Pod template of one of its previous ReplicaSets, like
+// 'kubectl rollout undo'.
+func (t *Tools) rollbackDeployment(ctx context.Context, toolReq *mcp.CallToolRequest, params rollbackDeploymentParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("rollbackDeployment called")
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs["deployment"])
+	if err != nil {
+		return nil, nil, err
+	}
+	deploymentResource, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get deployment", zap.String("tool", "rollbackDeployment"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get deployment %s: %w", params.Name, err)
+	}
+	var deployment appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(deploymentResource.Object, &deployment); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to Deployment: %w", err)
+	}
+	if deployment.Spec.Paused {
+		return nil, nil, fmt.Errorf("deployment %s is paused, resume it before rolling it back", params.Name)
+	}
+
+	replicaSets, err := t.getDeploymentReplicaSets(ctx, toolReq, params.Cluster, &deployment)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get replicasets", zap.String("tool", "rollbackDeployment"), zap.Error(err))
+		return nil, nil, err
+	}
+	currentRevision := revision(deployment.Annotations)
+	target, err := findRollbackReplicaSet(replicaSets, currentRevision, params.Revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	patch, err := json.Marshal([]jsonPatch{{Op: "replace", Path: "/spec/template", Value: template}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to roll back deployment", zap.String("tool", "rollbackDeployment"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to roll back deployment %s: %w", params.Name, err)
+	}
+
+	rollback := &unstructured.Unstructured{Object: map[string]any{
+		"rollback": map[string]any{
+			"fromRevision": currentRevision,
+			"toRevision":   revision(target.Annotations),
+			"replicaSet":   target.Name,
+		},
+	}}
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj, rollback}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "rollbackDeployment"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// getDeploymentReplicaSets returns the ReplicaSets controlled by a Deployment.
+func (t *Tools) getDeploymentReplicaSets(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, deployment *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert label selector: %w", err)
+	}
+	resources, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       cluster,
+		Kind:          "replicaset",
+		Namespace:     deployment.Namespace,
+		URL:           toolReq.Extra.Header.Get(urlHeader),
+		Token:         middleware.Token(ctx),
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replicasets: %w", err)
+	}
+
+	var replicaSets []appsv1.ReplicaSet
+	for _, resource := range resources {
+		var replicaSet appsv1.ReplicaSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, &replicaSet); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to ReplicaSet: %w", err)
+		}
+		owner := metav1.GetControllerOf(&replicaSet)
+		if owner != nil && owner.Kind == "Deployment" && owner.Name == deployment.Name {
+			replicaSets = append(replicaSets, replicaSet)
+		}
+	}
+
+	return replicaSets, nil
+}
+
+// findRollbackReplicaSet returns the ReplicaSet of the given revision, or of the latest revision before the current
+// one if toRevision is 0.
+func findRollbackReplicaSet(replicaSets []appsv1.ReplicaSet, currentRevision int64, toRevision int64) (*appsv1.ReplicaSet, error) {
+	if toRevision != 0 && toRevision == currentRevision {
+		return nil, fmt.Errorf("revision %d is the current revision of the deployment", toRevision)
+	}
+
+	var target *appsv1.ReplicaSet
+	var revisions []int64
+	for i := range replicaSets {
+		rev := revision(replicaSets[i].Annotations)
+		revisions = append(revisions, rev)
+		if toRevision != 0 {
+			if rev == toRevision {
+				target = &replicaSets[i]
+			}
+			continue
+		}
+		if rev < currentRevision && (target == nil || rev > revision(target.Annotations)) {
+			target = &replicaSets[i]
+		}
+	}
+	if target == nil {
+		slices.Sort(revisions)
+		if toRevision != 0 {
+			return nil, fmt.Errorf("revision %d not found, the available revisions are %v", toRevision, revisions)
+		}
+		return nil, fmt.Errorf("no previous revision found, the available revisions are %v", revisions)
+	}
+
+	return target, nil
+}
+
+// revision returns the rollout revision of the annotations of a Deployment or a ReplicaSet, or 0 if it isn't set.
+func revision(annotations map[string]string) int64 {
+	rev, _ := strconv.ParseInt(annotations[revisionAnn], 10, 64)
+	return rev
+}
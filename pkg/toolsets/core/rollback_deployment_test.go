@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRollbackDeployment(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	replicaSets := []runtime.Object{
+		newRolloutReplicaSet("web-1", "1", "web:1"),
+		newRolloutReplicaSet("web-2", "2", "web:2"),
+		newRolloutReplicaSet("web-3", "3", "web:3"),
+	}
+
+	tests := map[string]struct {
+		deployment       runtime.Object
+		revision         int64
+		expectedImage    string
+		expectedRollback map[string]any
+		expectedError    string
+	}{
+		"previous revision": {
+			deployment:       newRolloutDeployment("3", "web:3", false),
+			expectedImage:    "web:2",
+			expectedRollback: map[string]any{"fromRevision": float64(3), "toRevision": float64(2), "replicaSet": "web-2"},
+		},
+		"given revision": {
+			deployment:       newRolloutDeployment("3", "web:3", false),
+			revision:         1,
+			expectedImage:    "web:1",
+			expectedRollback: map[string]any{"fromRevision": float64(3), "toRevision": float64(1), "replicaSet": "web-1"},
+		},
+		"unknown revision": {
+			deployment:    newRolloutDeployment("3", "web:3", false),
+			revision:      7,
+			expectedError: "revision 7 not found, the available revisions are [1 2 3]",
+		},
+		"current revision": {
+			deployment:    newRolloutDeployment("3", "web:3", false),
+			revision:      3,
+			expectedError: "revision 3 is the current revision of the deployment",
+		},
+		"no previous revision": {
+			deployment:    newRolloutDeployment("1", "web:1", false),
+			expectedError: "no previous revision found, the available revisions are [1 2 3]",
+		},
+		"paused deployment": {
+			deployment:    newRolloutDeployment("3", "web:3", true),
+			expectedError: "deployment web is paused, resume it before rolling it back",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, _ := newRolloutTools(append([]runtime.Object{test.deployment}, replicaSets...)...)
+
+			result, _, err := tools.rollbackDeployment(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, rollbackDeploymentParams{Name: "web", Namespace: "default", Cluster: "local", Revision: test.revision})
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			items := llmItems(t, result)
+			require.Len(t, items, 2)
+			containers, _, _ := unstructured.NestedSlice(items[0], "spec", "template", "spec", "containers")
+			require.Len(t, containers, 1)
+			assert.Equal(t, test.expectedImage, containers[0].(map[string]any)["image"])
+			labels, _, _ := unstructured.NestedStringMap(items[0], "spec", "template", "metadata", "labels")
+			assert.Equal(t, map[string]string{"app": "web"}, labels, "the pod-template-hash label should be removed")
+			assert.Equal(t, test.expectedRollback, items[1]["rollback"])
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"k8s.io/client-go/dynamic"
+)
+
+// rolloutKinds contains the kinds of the workloads whose Pods are rolled out when their template changes.
+var rolloutKinds = []string{"deployment", "statefulset", "daemonset"}
+
+// workloadParams identifies a Deployment, StatefulSet or DaemonSet within a cluster.
+type workloadParams struct {
+	Kind      string `json:"kind" jsonschema:"the kind of the workload: Deployment, StatefulSet or DaemonSet" validate:"required,enum=Deployment|StatefulSet|DaemonSet"`
+	Name      string `json:"name" jsonschema:"the name of the workload"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the workload"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the workload"`
+}
+
+// getWorkloadInterface returns the resource interface of the kind of a workload, or an error if the kind doesn't
+// support rollouts.
+func (t *Tools) getWorkloadInterface(ctx context.Context, toolReq *mcp.CallToolRequest, params workloadParams) (dynamic.ResourceInterface, error) {
+	kind := strings.ToLower(params.Kind)
+	if !slices.Contains(rolloutKinds, kind) {
+		return nil, fmt.Errorf("kind %s doesn't support rollouts, must be Deployment, StatefulSet or DaemonSet", params.Kind)
+	}
+
+	return t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs[kind])
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// debugContainerPrefix is the prefix of the names of the ephemeral containers added by runDebugContainer.
+	debugContainerPrefix = "debugger-"
+	// debugLogLines is the number of lines of the output of the debug command returned.
+	debugLogLines = 1000
+)
+
+// DefaultDebugAllowlist contains the network and filesystem diagnostic commands allowed by default in runDebugContainer.
+// The filesystem of the target container is under /proc/1/root, its processes being shared with the debug container.
+// As in DefaultExecAllowlist, cat only reads the DNS and hosts configuration and curl only reports the status code.
+var DefaultDebugAllowlist = []string{"nslookup *", "dig *", "ping -c *", "nc -zv *", "curl -s -o /dev/null -w %{http_code} ?",
+	"netstat *", "ss *", "ip addr", "ip route", "ls *", "cat /etc/resolv.conf", "cat /etc/hosts", "ps *"}
+
+// debugPollInterval is how often the status of the debug container is read while its command runs.
+var debugPollInterval = time.Second
+
+type runDebugContainerParams struct {
+	Name      string   `json:"name" jsonschema:"the name of the pod"`
+	Namespace string   `json:"namespace" jsonschema:"the namespace of the pod"`
+	Cluster   string   `json:"cluster" jsonschema:"the cluster of the pod"`
+	Container string   `json:"container,omitempty" jsonschema:"the container whose processes are shared with the debug container. Optional for pods with a single container"`
+	Command   []string `json:"command" jsonschema:"the command and its arguments, e.g. [\"nslookup\", \"kubernetes.default\"]. It isn't run in a shell"`
+}
+
+// debugContainerResult is the output of a command run in an ephemeral debug container.
+type debugContainerResult struct {
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	// Output is the combined stdout and stderr of the command, read from the logs of the debug container.
+	Output   string `json:"output"`
+	ExitCode int32  `json:"exitCode"`
+	// Truncated is true when the output exceeded execMaxOutputBytes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// runDebugContainer adds an ephemeral container with the debug image of the server to a Pod, runs a command from the
+// debug allowlist in it and returns its output. It's meant for the containers without a shell or diagnostic tools,
+// e.g. distroless images, where execInPod can't be used. Ephemeral containers can't be removed, the debug container
+// stays terminated in the Pod until the Pod is deleted.
+func (t *Tools) runDebugContainer(ctx context.Context, toolReq *mcp.CallToolRequest, params runDebugContainerParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("runDebugContainer called")
+
+	if t.DebugImage == "" {
+		return nil, nil, fmt.Errorf("no debug image is configured in the server, it's set with --debug-image")
+	}
+	if !commandAllowed(t.DebugAllowlist, params.Command) {
+		return nil, nil, fmt.Errorf("command %q is not allowed, allowed commands are: %s", strings.Join(params.Command, " "), strings.Join(t.DebugAllowlist, ", "))
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, params.Namespace, params.Cluster, converter.K8sKindsToGVRs["pod"])
+	if err != nil {
+		return nil, nil, err
+	}
+	pod, err := getPod(ctx, resourceInterface, params.Name)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Pod", zap.String("tool", "runDebugContainer"), zap.Error(err))
+		return nil, nil, err
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, nil, fmt.Errorf("pod %s is %s, ephemeral containers only run in running pods", pod.Name, pod.Status.Phase)
+	}
+	target := params.Container
+	if target == "" {
+		if len(pod.Spec.Containers) != 1 {
+			return nil, nil, fmt.Errorf("pod %s has %d containers, the container must be specified", pod.Name, len(pod.Spec.Containers))
+		}
+		target = pod.Spec.Containers[0].Name
+	}
+	if !slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == target }) {
+		return nil, nil, fmt.Errorf("container %s not found in pod %s", target, pod.Name)
+	}
+
+	debugContainer := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     debugContainerPrefix + utilrand.String(5),
+			Image:                    t.DebugImage,
+			Command:                  params.Command,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		},
+		TargetContainerName: target,
+	}
+	// the whole list is replaced, the API server refuses the patches changing or removing the existing containers
+	patch, err := json.Marshal([]jsonPatch{{Op: "add", Path: "/spec/ephemeralContainers", Value: append(pod.Spec.EphemeralContainers, debugContainer)}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	if _, err := resourceInterface.Patch(ctx, pod.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: applyFieldManager}, "ephemeralcontainers"); err != nil {
+		middleware.Logger(ctx).Error("failed to add the debug container", zap.String("tool", "runDebugContainer"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to add the debug container to pod %s: %w", pod.Name, err)
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+	pod, terminated, err := waitForDebugContainer(pollCtx, resourceInterface, pod.Name, debugContainer.Name)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to wait for the debug container", zap.String("tool", "runDebugContainer"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to wait for the debug container %s, its output can be read later with getPodLogs: %w", debugContainer.Name, err)
+	}
+
+	logs, err := t.fetchPodLogs(ctx, url, params.Cluster, token, *pod, getPodLogsParams{Container: debugContainer.Name, TailLines: debugLogLines})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get the logs of the debug container", zap.String("tool", "runDebugContainer"), zap.Error(err))
+		return nil, nil, err
+	}
+	output, _ := logs.Object["pod-logs"].(map[string]any)[debugContainer.Name].(string)
+	result := debugContainerResult{
+		Container: debugContainer.Name,
+		Image:     t.DebugImage,
+		Output:    output,
+		ExitCode:  terminated.ExitCode,
+	}
+	if len(result.Output) > execMaxOutputBytes {
+		result.Output = result.Output[:execMaxOutputBytes]
+		result.Truncated = true
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "runDebugContainer"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// getPod reads a Pod from the API server, bypassing the cache of the client so its status is up to date.
+func getPod(ctx context.Context, resourceInterface dynamic.ResourceInterface, name string) (*corev1.Pod, error) {
+	obj, err := resourceInterface.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+		return nil, fmt.Errorf("failed to convert unstructured object to Pod: %w", err)
+	}
+
+	return &pod, nil
+}
+
+// waitForDebugContainer reads the Pod until its ephemeral container terminates, and returns the Pod and how the
+// container terminated.
+func waitForDebugContainer(ctx context.Context, resourceInterface dynamic.ResourceInterface, name string, container string) (*corev1.Pod, *corev1.ContainerStateTerminated, error) {
+	ticker := time.NewTicker(debugPollInterval)
+	defer ticker.Stop()
+	for {
+		pod, err := getPod(ctx, resourceInterface, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+			if status.State.Terminated != nil {
+				return pod, status.State.Terminated, nil
+			}
+			if waiting := status.State.Waiting; waiting != nil && (waiting.Reason == "ErrImagePull" || waiting.Reason == "ImagePullBackOff") {
+				return nil, nil, fmt.Errorf("the debug image can't be pulled: %s", waiting.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newDebugTargetPod(name string, phase corev1.PodPhase, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container, Image: "gcr.io/distroless/static"})
+	}
+	return pod
+}
+
+func TestRunDebugContainer(t *testing.T) {
+	tests := map[string]struct {
+		params         runDebugContainerParams
+		debugImage     string
+		state          corev1.ContainerState
+		expectedTarget string
+		expectedExit   int32
+		expectedError  string
+	}{
+		"command output": {
+			params:         runDebugContainerParams{Name: "app", Namespace: "default", Cluster: "local", Command: []string{"nslookup", "kubernetes.default"}},
+			debugImage:     "busybox:1.36",
+			state:          corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			expectedTarget: "app",
+			expectedExit:   1,
+		},
+		"target container of a multi-container pod": {
+			params:         runDebugContainerParams{Name: "multi", Namespace: "default", Cluster: "local", Container: "sidecar", Command: []string{"ls", "/proc/1/root/etc"}},
+			debugImage:     "busybox:1.36",
+			state:          corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			expectedTarget: "sidecar",
+		},
+		"image pull failure": {
+			params:        runDebugContainerParams{Name: "app", Namespace: "default", Cluster: "local", Command: []string{"ps", "aux"}},
+			debugImage:    "busybox:missing",
+			state:         corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "manifest unknown"}},
+			expectedError: "the debug image can't be pulled: manifest unknown",
+		},
+		"no debug image": {
+			params:        runDebugContainerParams{Name: "app", Namespace: "default", Cluster: "local", Command: []string{"ps", "aux"}},
+			expectedError: "no debug image is configured in the server, it's set with --debug-image",
+		},
+		"command not allowed": {
+			params:        runDebugContainerParams{Name: "app", Namespace: "default", Cluster: "local", Command: []string{"rm", "-rf", "/"}},
+			debugImage:    "busybox:1.36",
+			expectedError: `command "rm -rf /" is not allowed`,
+		},
+		"service account token not allowed": {
+			params:        runDebugContainerParams{Name: "app", Namespace: "default", Cluster: "local", Command: []string{"cat", "/proc/1/root/var/run/secrets/kubernetes.io/serviceaccount/token"}},
+			debugImage:    "busybox:1.36",
+			expectedError: `command "cat /proc/1/root/var/run/secrets/kubernetes.io/serviceaccount/token" is not allowed`,
+		},
+		"pod not running": {
+			params:        runDebugContainerParams{Name: "pending", Namespace: "default", Cluster: "local", Command: []string{"ps", "aux"}},
+			debugImage:    "busybox:1.36",
+			expectedError: "pod pending is Pending, ephemeral containers only run in running pods",
+		},
+		"container required": {
+			params:        runDebugContainerParams{Name: "multi", Namespace: "default", Cluster: "local", Command: []string{"ps", "aux"}},
+			debugImage:    "busybox:1.36",
+			expectedError: "pod multi has 2 containers, the container must be specified",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme,
+				newDebugTargetPod("app", corev1.PodRunning, "app"),
+				newDebugTargetPod("multi", corev1.PodRunning, "app", "sidecar"),
+				newDebugTargetPod("pending", corev1.PodPending, "app"))
+			// the kubelet runs the ephemeral containers added to the pods
+			fakeDynClient.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				obj, err := fakeDynClient.Tracker().Get(action.GetResource(), action.GetNamespace(), action.(k8stesting.GetAction).GetName())
+				if err != nil {
+					return true, nil, err
+				}
+				pod := obj.(*unstructured.Unstructured).DeepCopy()
+				containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "ephemeralContainers")
+				var statuses []any
+				for _, container := range containers {
+					status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&corev1.ContainerStatus{
+						Name:  container.(map[string]any)["name"].(string),
+						State: test.state,
+					})
+					require.NoError(t, err)
+					statuses = append(statuses, status)
+				}
+				require.NoError(t, unstructured.SetNestedSlice(pod.Object, statuses, "status", "ephemeralContainerStatuses"))
+				return true, pod, nil
+			})
+			var patch k8stesting.PatchAction
+			fakeDynClient.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patch = action.(k8stesting.PatchAction)
+				return false, nil, nil
+			})
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return fake.NewClientset(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, "fakeToken"), DebugImage: test.debugImage, DebugAllowlist: DefaultDebugAllowlist}
+
+			result, _, err := tools.runDebugContainer(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var debugResult debugContainerResult
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &debugResult))
+			assert.True(t, strings.HasPrefix(debugResult.Container, debugContainerPrefix))
+			assert.Equal(t, debugContainerResult{Container: debugResult.Container, Image: "busybox:1.36", Output: "fake logs", ExitCode: test.expectedExit}, debugResult)
+
+			require.NotNil(t, patch)
+			assert.Equal(t, "ephemeralcontainers", patch.GetSubresource())
+			var patches []struct {
+				Op    string                      `json:"op"`
+				Path  string                      `json:"path"`
+				Value []corev1.EphemeralContainer `json:"value"`
+			}
+			require.NoError(t, json.Unmarshal(patch.GetPatch(), &patches))
+			require.Len(t, patches, 1)
+			assert.Equal(t, "/spec/ephemeralContainers", patches[0].Path)
+			require.Len(t, patches[0].Value, 1)
+			container := patches[0].Value[0]
+			assert.Equal(t, "busybox:1.36", container.Image)
+			assert.Equal(t, test.params.Command, container.Command)
+			assert.Equal(t, test.expectedTarget, container.TargetContainerName)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// scaleKinds contains the kinds of the workloads that can be scaled with the scale subresource.
+var scaleKinds = []string{"deployment", "statefulset", "replicaset"}
+
+type scaleWorkloadParams struct {
+	Kind               string `json:"kind" jsonschema:"the kind of the workload: Deployment, StatefulSet or ReplicaSet" validate:"required,enum=Deployment|StatefulSet|ReplicaSet"`
+	Name               string `json:"name" jsonschema:"the name of the workload"`
+	Namespace          string `json:"namespace" jsonschema:"the namespace of the workload"`
+	Cluster            string `json:"cluster" jsonschema:"the cluster of the workload"`
+	Replicas           *int32 `json:"replicas,omitempty" jsonschema:"the desired number of replicas"`
+	Delta              int32  `json:"delta,omitempty" jsonschema:"the number of replicas to add, or to remove if negative, instead of a desired number"`
+	ConfirmScaleToZero bool   `json:"confirmScaleToZero,omitempty" jsonschema:"must be true to scale the workload to zero replicas"`
+	WaitSeconds        int64  `json:"waitSeconds,omitempty" jsonschema:"wait up to this many seconds, at most 300, for the replicas of the workload to be ready. 0 returns without waiting"`
+}
+
+// scaleResult is the result of scaleWorkload.
+type scaleResult struct {
+	Kind             string   `json:"kind"`
+	Name             string   `json:"name"`
+	Namespace        string   `json:"namespace"`
+	PreviousReplicas int64    `json:"previousReplicas"`
+	Replicas         int64    `json:"replicas"`
+	Warnings         []string `json:"warnings,omitempty"`
+	// Wait and Status are the outcome of the wait for the replicas and the last status of the workload, with
+	// waitSeconds.
+	Wait   *resourceWait  `json:"wait,omitempty"`
+	Status map[string]any `json:"status,omitempty"`
+}
+
+// scaleWorkload sets the replicas of a workload with its scale subresource, like 'kubectl scale', and warns about the
+// HorizontalPodAutoscalers and owners that will revert the change. With waitSeconds, the workload is watched until its
+// replicas are ready.
+func (t *Tools) scaleWorkload(ctx context.Context, toolReq *mcp.CallToolRequest, params scaleWorkloadParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("scaleWorkload called")
+
+	kind := strings.ToLower(params.Kind)
+	if !slices.Contains(scaleKinds, kind) {
+		return nil, nil, fmt.Errorf("kind %s can't be scaled, must be Deployment, StatefulSet or ReplicaSet", params.Kind)
+	}
+	if (params.Replicas == nil) == (params.Delta == 0) {
+		return nil, nil, fmt.Errorf("either replicas or delta is required")
+	}
+	if err := validateWaitSeconds(params.WaitSeconds); err != nil {
+		return nil, nil, err
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, params.Namespace, params.Cluster, converter.K8sKindsToGVRs[kind])
+	if err != nil {
+		return nil, nil, err
+	}
+	workload, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get workload", zap.String("tool", "scaleWorkload"), zap.Error(err))
+		return nil, nil, err
+	}
+	scale, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get scale", zap.String("tool", "scaleWorkload"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get the scale of %s %s: %w", params.Kind, params.Name, err)
+	}
+
+	previous, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	replicas := previous + int64(params.Delta)
+	if params.Replicas != nil {
+		replicas = int64(*params.Replicas)
+	}
+	if replicas < 0 {
+		return nil, nil, fmt.Errorf("%s %s has %d replicas, it can't be scaled to %d replicas", params.Kind, params.Name, previous, replicas)
+	}
+	if replicas == 0 && previous > 0 && !params.ConfirmScaleToZero {
+		return nil, nil, fmt.Errorf("scaling %s %s to zero replicas stops all its Pods, set confirmScaleToZero to true to scale it to zero", params.Kind, params.Name)
+	}
+
+	warnings, err := t.scaleWarnings(ctx, url, token, params, workload, replicas)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to check autoscalers", zap.String("tool", "scaleWorkload"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	patch, err := json.Marshal(map[string]any{"spec": map[string]any{"replicas": replicas}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	if _, err := resourceInterface.Patch(ctx, params.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "scale"); err != nil {
+		middleware.Logger(ctx).Error("failed to scale workload", zap.String("tool", "scaleWorkload"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to scale %s %s: %w", params.Kind, params.Name, err)
+	}
+
+	result := scaleResult{
+		Kind:             workload.GetKind(),
+		Name:             params.Name,
+		Namespace:        params.Namespace,
+		PreviousReplicas: previous,
+		Replicas:         replicas,
+		Warnings:         warnings,
+	}
+	if params.WaitSeconds > 0 {
+		// the workload is read again, the patch of the scale subresource returns the Scale
+		if workload, err = resourceInterface.Get(ctx, params.Name, metav1.GetOptions{}); err != nil {
+			middleware.Logger(ctx).Error("failed to get workload", zap.String("tool", "scaleWorkload"), zap.Error(err))
+			return nil, nil, err
+		}
+		last, wait, err := waitForResource(ctx, resourceInterface, workload, params.WaitSeconds)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to wait for workload", zap.String("tool", "scaleWorkload"), zap.Error(err))
+			return nil, nil, err
+		}
+		result.Wait = &wait
+		result.Status, _, _ = unstructured.NestedMap(last.Object, "status")
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "scaleWorkload"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// scaleWarnings returns the HorizontalPodAutoscalers targeting the workload, and the controller owning it, that
+// manage its replicas and revert a manual change.
+func (t *Tools) scaleWarnings(ctx context.Context, url string, token string, params scaleWorkloadParams, workload *unstructured.Unstructured, replicas int64) ([]string, error) {
+	var warnings []string
+	if owner := metav1.GetControllerOfNoCopy(workload); owner != nil {
+		warnings = append(warnings, fmt.Sprintf("%s %s is managed by %s %s, which sets its replicas back. Scale the %s instead.", workload.GetKind(), params.Name, owner.Kind, owner.Name, owner.Kind))
+	}
+
+	hpas, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   params.Cluster,
+		Kind:      "horizontalpodautoscaler",
+		Namespace: params.Namespace,
+		URL:       url,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, hpa := range hpas {
+		targetKind, _, _ := unstructured.NestedString(hpa.Object, "spec", "scaleTargetRef", "kind")
+		targetName, _, _ := unstructured.NestedString(hpa.Object, "spec", "scaleTargetRef", "name")
+		if !strings.EqualFold(targetKind, workload.GetKind()) || targetName != params.Name {
+			continue
+		}
+		minReplicas, found, _ := unstructured.NestedInt64(hpa.Object, "spec", "minReplicas")
+		if !found {
+			minReplicas = 1
+		}
+		maxReplicas, _, _ := unstructured.NestedInt64(hpa.Object, "spec", "maxReplicas")
+		switch {
+		case replicas == 0:
+			warnings = append(warnings, fmt.Sprintf("HorizontalPodAutoscaler %s stops scaling %s %s while it has zero replicas, until it's scaled up again.", hpa.GetName(), workload.GetKind(), params.Name))
+		case replicas < minReplicas || replicas > maxReplicas:
+			warnings = append(warnings, fmt.Sprintf("HorizontalPodAutoscaler %s keeps the replicas of %s %s between %d and %d, it will scale it back into this range. Change the minReplicas or maxReplicas of the HorizontalPodAutoscaler instead.",
+				hpa.GetName(), workload.GetKind(), params.Name, minReplicas, maxReplicas))
+		default:
+			warnings = append(warnings, fmt.Sprintf("HorizontalPodAutoscaler %s manages the replicas of %s %s between %d and %d, it may change them again based on its metrics.",
+				hpa.GetName(), workload.GetKind(), params.Name, minReplicas, maxReplicas))
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func newScaleHPA(target string, minReplicas int64, maxReplicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   map[string]any{"name": target, "namespace": "default"},
+		"spec": map[string]any{
+			"scaleTargetRef": map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": target},
+			"minReplicas":    minReplicas,
+			"maxReplicas":    maxReplicas,
+		},
+	}}
+}
+
+func TestScaleWorkload(t *testing.T) {
+	ownedReplicaSet := &appsv1.ReplicaSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-7d9c6b5f4",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: ptr.To(true)}},
+		},
+		Spec: appsv1.ReplicaSetSpec{Replicas: ptr.To(int32(3))},
+	}
+
+	tests := map[string]struct {
+		params           scaleWorkloadParams
+		objects          []runtime.Object
+		watchedStatus    map[string]any
+		expectedResult   string
+		expectedReplicas int64
+		expectedError    string
+	}{
+		"scale to replicas": {
+			params:           scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Replicas: ptr.To(int32(5))},
+			expectedResult:   `{"kind":"Deployment","name":"web","namespace":"default","previousReplicas":3,"replicas":5}`,
+			expectedReplicas: 5,
+		},
+		"remove replicas": {
+			params:           scaleWorkloadParams{Kind: "deployment", Name: "web", Namespace: "default", Cluster: "local", Delta: -2},
+			expectedResult:   `{"kind":"Deployment","name":"web","namespace":"default","previousReplicas":3,"replicas":1}`,
+			expectedReplicas: 1,
+		},
+		"hpa reverting the change": {
+			params:           scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Delta: 10},
+			objects:          []runtime.Object{newScaleHPA("web", 2, 6), newScaleHPA("api", 2, 6)},
+			expectedResult:   `{"kind":"Deployment","name":"web","namespace":"default","previousReplicas":3,"replicas":13,"warnings":["HorizontalPodAutoscaler web keeps the replicas of Deployment web between 2 and 6, it will scale it back into this range. Change the minReplicas or maxReplicas of the HorizontalPodAutoscaler instead."]}`,
+			expectedReplicas: 13,
+		},
+		"scale to zero confirmed": {
+			params:           scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Replicas: ptr.To(int32(0)), ConfirmScaleToZero: true},
+			objects:          []runtime.Object{newScaleHPA("web", 2, 6)},
+			expectedResult:   `{"kind":"Deployment","name":"web","namespace":"default","previousReplicas":3,"replicas":0,"warnings":["HorizontalPodAutoscaler web stops scaling Deployment web while it has zero replicas, until it's scaled up again."]}`,
+			expectedReplicas: 0,
+		},
+		"replicaset owned by a deployment": {
+			params:           scaleWorkloadParams{Kind: "ReplicaSet", Name: "web-7d9c6b5f4", Namespace: "default", Cluster: "local", Delta: 1},
+			objects:          []runtime.Object{ownedReplicaSet},
+			expectedResult:   `{"kind":"ReplicaSet","name":"web-7d9c6b5f4","namespace":"default","previousReplicas":3,"replicas":4,"warnings":["ReplicaSet web-7d9c6b5f4 is managed by Deployment web, which sets its replicas back. Scale the Deployment instead."]}`,
+			expectedReplicas: 4,
+		},
+		"scale and wait for the replicas": {
+			params:        scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Replicas: ptr.To(int32(5)), WaitSeconds: 10},
+			watchedStatus: map[string]any{"replicas": int64(5), "updatedReplicas": int64(5), "readyReplicas": int64(5), "availableReplicas": int64(5)},
+			expectedResult: `{"kind":"Deployment","name":"web","namespace":"default","previousReplicas":3,"replicas":5,
+				"wait":{"ready":true,"condition":"Available","message":"the deployment was successfully rolled out"},
+				"status":{"replicas":5,"updatedReplicas":5,"readyReplicas":5,"availableReplicas":5}}`,
+			expectedReplicas: 5,
+		},
+		"invalid waitSeconds": {
+			params:        scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Delta: 1, WaitSeconds: 600},
+			expectedError: "invalid waitSeconds 600, must be between 0 and 300",
+		},
+		"scale to zero not confirmed": {
+			params:        scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Delta: -3},
+			expectedError: "scaling Deployment web to zero replicas stops all its Pods, set confirmScaleToZero to true to scale it to zero",
+		},
+		"negative replicas": {
+			params:        scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Delta: -5},
+			expectedError: "Deployment web has 3 replicas, it can't be scaled to -2 replicas",
+		},
+		"replicas and delta": {
+			params:        scaleWorkloadParams{Kind: "Deployment", Name: "web", Namespace: "default", Cluster: "local", Replicas: ptr.To(int32(2)), Delta: 1},
+			expectedError: "either replicas or delta is required",
+		},
+		"unsupported kind": {
+			params:        scaleWorkloadParams{Kind: "DaemonSet", Name: "web", Namespace: "default", Cluster: "local", Delta: 1},
+			expectedError: "kind DaemonSet can't be scaled, must be Deployment, StatefulSet or ReplicaSet",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := append([]runtime.Object{newRolloutDeployment("1", "web:1", false)}, test.objects...)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(rolloutScheme(), map[schema.GroupVersionResource]string{
+				{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}: "HorizontalPodAutoscalerList",
+			}, objects...)
+			// the deployment controller rolls out the new replicas
+			fakeDynClient.PrependWatchReactor("deployments", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				obj, err := fakeDynClient.Tracker().Get(action.GetResource(), action.GetNamespace(), test.params.Name)
+				if err != nil {
+					return true, nil, err
+				}
+				deployment, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+				if err != nil {
+					return true, nil, err
+				}
+				deployment["status"] = test.watchedStatus
+				watcher := watch.NewFakeWithChanSize(1, false)
+				watcher.Modify(&unstructured.Unstructured{Object: deployment})
+				return true, watcher, nil
+			})
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+			result, _, err := tools.scaleWorkload(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+			if test.params.Kind == "ReplicaSet" {
+				gvr.Resource = "replicasets"
+			}
+			workload, err := fakeDynClient.Resource(gvr).Namespace("default").Get(t.Context(), test.params.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			replicas, _, _ := unstructured.NestedInt64(workload.Object, "spec", "replicas")
+			assert.Equal(t, test.expectedReplicas, replicas)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/deprecations"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// lastAppliedAnnotation contains the last configuration applied by kubectl apply, with the API version used.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// defaultScannedKinds are the kinds scanned in the clusters when no kinds are given, the ones whose API versions were
+// removed by the recent Kubernetes releases.
+var defaultScannedKinds = []string{"deployment", "statefulset", "daemonset", "ingress", "cronjob", "horizontalpodautoscaler", "poddisruptionbudget"}
+
+type scanDeprecatedAPIsParams struct {
+	TargetVersion string   `json:"targetVersion" jsonschema:"the Kubernetes version the APIs are checked against, e.g. v1.32.3"`
+	Manifest      string   `json:"manifest,omitempty" jsonschema:"the YAML or JSON manifest to scan, with one or more documents"`
+	Cluster       string   `json:"cluster,omitempty" jsonschema:"the cluster whose resources are scanned instead of a manifest"`
+	Namespace     string   `json:"namespace,omitempty" jsonschema:"the namespace of the resources scanned. Empty for all namespaces"`
+	Kinds         []string `json:"kinds,omitempty" jsonschema:"the kinds of the resources scanned in the cluster"`
+}
+
+// deprecationScan is the result of a scan, with the resources using APIs deprecated or removed in the target version.
+type deprecationScan struct {
+	TargetVersion string                 `json:"targetVersion"`
+	Scanned       int                    `json:"scanned"`
+	Findings      []deprecatedAPIFinding `json:"findings"`
+}
+
+// deprecatedAPIFinding is a deprecated API version used by a resource. Source is the manifest document, or the field of
+// the cluster resource recording the API version used by its clients.
+type deprecatedAPIFinding struct {
+	Source       string `json:"source"`
+	Kind         string `json:"kind"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	APIVersion   string `json:"apiVersion"`
+	Status       string `json:"status"`
+	DeprecatedIn string `json:"deprecatedIn"`
+	RemovedIn    string `json:"removedIn,omitempty"`
+	Replacement  string `json:"replacement,omitempty"`
+}
+
+// metaObject is the API version of a serialized object.
+type metaObject struct {
+	APIVersion string `json:"apiVersion"`
+}
+
+// scanDeprecatedAPIs reports the API versions deprecated or removed in the target Kubernetes version used by the
+// documents of a manifest, or by the clients managing the resources of a cluster.
+func (t *Tools) scanDeprecatedAPIs(ctx context.Context, toolReq *mcp.CallToolRequest, params scanDeprecatedAPIsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("scanDeprecatedAPIs called")
+
+	targetVersion, err := version.ParseGeneric(params.TargetVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid target version %q: %w", params.TargetVersion, err)
+	}
+	if (params.Manifest == "") == (params.Cluster == "") {
+		return nil, nil, fmt.Errorf("either a manifest or a cluster is required")
+	}
+
+	scan := deprecationScan{TargetVersion: params.TargetVersion, Findings: []deprecatedAPIFinding{}}
+	if params.Manifest != "" {
+		err = scanManifest(params.Manifest, targetVersion, &scan)
+	} else {
+		err = t.scanCluster(ctx, toolReq, params, targetVersion, &scan)
+	}
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to scan deprecated APIs", zap.String("tool", "scanDeprecatedAPIs"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	response, err := json.Marshal(scan)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "scanDeprecatedAPIs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// scanManifest checks the API versions of the documents of the manifest. The items of List documents are checked too.
+func scanManifest(manifest string, targetVersion *version.Version, scan *deprecationScan) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	for document := 1; ; document++ {
+		var obj map[string]any
+		if err := decoder.Decode(&obj); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid manifest document %d: %w", document, err)
+		}
+		if obj == nil {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		source := fmt.Sprintf("document %d", document)
+		if !u.IsList() {
+			scan.Scanned++
+			appendFinding(scan, source, u, u.GetAPIVersion(), targetVersion)
+			continue
+		}
+		list, err := u.ToList()
+		if err != nil {
+			return fmt.Errorf("invalid manifest document %d: %w", document, err)
+		}
+		for i := range list.Items {
+			scan.Scanned++
+			appendFinding(scan, source, &list.Items[i], list.Items[i].GetAPIVersion(), targetVersion)
+		}
+	}
+}
+
+// scanCluster checks the API versions used by the clients managing the resources of the cluster. The resources are
+// returned in the preferred version of the API server, so the versions used are taken from the managed fields and the
+// configuration applied by kubectl.
+func (t *Tools) scanCluster(ctx context.Context, toolReq *mcp.CallToolRequest, params scanDeprecatedAPIsParams, targetVersion *version.Version, scan *deprecationScan) error {
+	kinds := params.Kinds
+	if len(kinds) == 0 {
+		kinds = defaultScannedKinds
+	}
+	for _, kind := range kinds {
+		objs, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:   params.Cluster,
+			Kind:      strings.ToLower(kind),
+			Namespace: params.Namespace,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			scan.Scanned++
+			var checked []string
+			check := func(source string, apiVersion string) {
+				if apiVersion == "" || slices.Contains(checked, apiVersion) {
+					return
+				}
+				checked = append(checked, apiVersion)
+				appendFinding(scan, source, obj, apiVersion, targetVersion)
+			}
+			if lastApplied, ok := obj.GetAnnotations()[lastAppliedAnnotation]; ok {
+				var applied metaObject
+				if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil {
+					check(lastAppliedAnnotation, applied.APIVersion)
+				}
+			}
+			for _, entry := range obj.GetManagedFields() {
+				check("managedFields ("+entry.Manager+")", entry.APIVersion)
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendFinding adds a finding to the scan if the API version of the kind of the object is deprecated or removed in
+// the target version.
+func appendFinding(scan *deprecationScan, source string, obj *unstructured.Unstructured, apiVersion string, targetVersion *version.Version) {
+	deprecation, ok := deprecations.Lookup(apiVersion, obj.GetKind())
+	if !ok {
+		return
+	}
+	status := deprecation.Status(targetVersion)
+	if status == "" {
+		return
+	}
+	scan.Findings = append(scan.Findings, deprecatedAPIFinding{
+		Source:       source,
+		Kind:         obj.GetKind(),
+		Namespace:    obj.GetNamespace(),
+		Name:         obj.GetName(),
+		APIVersion:   apiVersion,
+		Status:       status,
+		DeprecatedIn: deprecation.DeprecatedIn,
+		RemovedIn:    deprecation.RemovedIn,
+		Replacement:  deprecation.Replacement,
+	})
+}
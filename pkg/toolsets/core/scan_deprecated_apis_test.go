@@ -0,0 +1,137 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const deprecatedManifest = `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+  namespace: default
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: default
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: autoscaling/v2beta2
+  kind: HorizontalPodAutoscaler
+  metadata:
+    name: nginx
+    namespace: default
+- apiVersion: v1
+  kind: Endpoints
+  metadata:
+    name: nginx
+    namespace: default
+`
+
+func TestScanDeprecatedAPIs(t *testing.T) {
+	fakeToken := "fakeToken"
+	cronJob := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata": map[string]any{
+			"name":      "backup",
+			"namespace": "default",
+			"annotations": map[string]any{
+				lastAppliedAnnotation: `{"apiVersion":"batch/v1beta1","kind":"CronJob","metadata":{"name":"backup","namespace":"default"}}`,
+			},
+			"managedFields": []any{
+				map[string]any{"manager": "kubectl-client-side-apply", "operation": "Update", "apiVersion": "batch/v1beta1"},
+				map[string]any{"manager": "kube-controller-manager", "operation": "Update", "apiVersion": "batch/v1"},
+			},
+		},
+	}}
+	hpa := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata": map[string]any{
+			"name":      "nginx",
+			"namespace": "default",
+			"managedFields": []any{
+				map[string]any{"manager": "helm", "operation": "Update", "apiVersion": "autoscaling/v2beta2"},
+			},
+		},
+	}}
+
+	tests := map[string]struct {
+		params         scanDeprecatedAPIsParams
+		objects        []runtime.Object
+		expectedResult string
+		expectedError  string
+	}{
+		"manifest removed and deprecated APIs": {
+			params:         scanDeprecatedAPIsParams{TargetVersion: "v1.33.1", Manifest: deprecatedManifest},
+			expectedResult: `{"targetVersion":"v1.33.1","scanned":4,"findings":[{"source":"document 1","kind":"CronJob","namespace":"default","name":"backup","apiVersion":"batch/v1beta1","status":"removed","deprecatedIn":"1.21","removedIn":"1.25","replacement":"batch/v1"},{"source":"document 3","kind":"HorizontalPodAutoscaler","namespace":"default","name":"nginx","apiVersion":"autoscaling/v2beta2","status":"removed","deprecatedIn":"1.23","removedIn":"1.26","replacement":"autoscaling/v2"},{"source":"document 3","kind":"Endpoints","namespace":"default","name":"nginx","apiVersion":"v1","status":"deprecated","deprecatedIn":"1.33","replacement":"discovery.k8s.io/v1 EndpointSlice"}]}`,
+		},
+		"manifest deprecated in the target version": {
+			params:         scanDeprecatedAPIsParams{TargetVersion: "v1.24.17+rke2r1", Manifest: deprecatedManifest},
+			expectedResult: `{"targetVersion":"v1.24.17+rke2r1","scanned":4,"findings":[{"source":"document 1","kind":"CronJob","namespace":"default","name":"backup","apiVersion":"batch/v1beta1","status":"deprecated","deprecatedIn":"1.21","removedIn":"1.25","replacement":"batch/v1"},{"source":"document 3","kind":"HorizontalPodAutoscaler","namespace":"default","name":"nginx","apiVersion":"autoscaling/v2beta2","status":"deprecated","deprecatedIn":"1.23","removedIn":"1.26","replacement":"autoscaling/v2"}]}`,
+		},
+		"cluster resources": {
+			params:         scanDeprecatedAPIsParams{TargetVersion: "v1.25.0", Cluster: "local", Namespace: "default", Kinds: []string{"cronjob", "HorizontalPodAutoscaler"}},
+			objects:        []runtime.Object{cronJob, hpa},
+			expectedResult: `{"targetVersion":"v1.25.0","scanned":2,"findings":[{"source":"kubectl.kubernetes.io/last-applied-configuration","kind":"CronJob","namespace":"default","name":"backup","apiVersion":"batch/v1beta1","status":"removed","deprecatedIn":"1.21","removedIn":"1.25","replacement":"batch/v1"},{"source":"managedFields (helm)","kind":"HorizontalPodAutoscaler","namespace":"default","name":"nginx","apiVersion":"autoscaling/v2beta2","status":"deprecated","deprecatedIn":"1.23","removedIn":"1.26","replacement":"autoscaling/v2"}]}`,
+		},
+		"no deprecated APIs": {
+			params:         scanDeprecatedAPIsParams{TargetVersion: "v1.25.0", Manifest: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"nginx"}}`},
+			expectedResult: `{"targetVersion":"v1.25.0","scanned":1,"findings":[]}`,
+		},
+		"invalid manifest": {
+			params:        scanDeprecatedAPIsParams{TargetVersion: "v1.25.0", Manifest: "kind: [Deployment"},
+			expectedError: "invalid manifest document 1",
+		},
+		"invalid target version": {
+			params:        scanDeprecatedAPIsParams{TargetVersion: "latest", Manifest: deprecatedManifest},
+			expectedError: `invalid target version "latest"`,
+		},
+		"manifest and cluster": {
+			params:        scanDeprecatedAPIsParams{TargetVersion: "v1.25.0", Manifest: deprecatedManifest, Cluster: "local"},
+			expectedError: "either a manifest or a cluster is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				{Group: "batch", Version: "v1", Resource: "cronjobs"}:                       "CronJobList",
+				{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}: "HorizontalPodAutoscalerList",
+			}, test.objects...)
+			c := &client.Client{
+				DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+
+			result, _, err := tools.scanDeprecatedAPIs(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
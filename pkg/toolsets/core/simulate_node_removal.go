@@ -0,0 +1,254 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// mirrorPodAnnotation is set by the kubelet on the mirror Pods of the static Pods of a node.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+type simulateNodeRemovalParams struct {
+	Cluster       string   `json:"cluster" jsonschema:"the cluster of the nodes"`
+	Nodes         []string `json:"nodes,omitempty" jsonschema:"the names of the nodes to remove"`
+	LabelSelector string   `json:"labelSelector,omitempty" jsonschema:"the label selector of the nodes to remove, e.g. the nodes of a node pool"`
+}
+
+// evictedPod is a Pod evicted by the drain of the removed nodes, and the node its replacement would be scheduled on.
+type evictedPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Node      string `json:"node"`
+	// Owner is the controller recreating the Pod, as Kind/name. The Pods without one aren't recreated.
+	Owner  string `json:"owner,omitempty"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+	Fits   bool   `json:"fits"`
+	// RescheduledOn is the remaining node the replacement of the Pod would be scheduled on.
+	RescheduledOn string `json:"rescheduledOn,omitempty"`
+}
+
+// blockingPDB is a PodDisruptionBudget preventing the eviction of Pods of the removed nodes.
+type blockingPDB struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	EvictedPods        int    `json:"evictedPods"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+	Message            string `json:"message"`
+}
+
+// nodeRemovalSimulation is the result of simulateNodeRemoval.
+type nodeRemovalSimulation struct {
+	Cluster string   `json:"cluster"`
+	Nodes   []string `json:"nodes"`
+	// Safe is true when the evicted Pods fit in the remaining nodes and no PodDisruptionBudget blocks the drain.
+	Safe        bool         `json:"safe"`
+	EvictedPods []evictedPod `json:"evictedPods"`
+	// DaemonSetPods is the number of DaemonSet Pods removed with the nodes, they aren't rescheduled.
+	DaemonSetPods int           `json:"daemonSetPods"`
+	BlockingPDBs  []blockingPDB `json:"blockingPodDisruptionBudgets"`
+	// RemainingCPU, RemainingMemory and RemainingPods are the resources of the remaining schedulable nodes once the
+	// evicted Pods fitting in them are rescheduled.
+	RemainingCPU    resourceCapacity `json:"remainingCpu"`
+	RemainingMemory resourceCapacity `json:"remainingMemory"`
+	RemainingPods   resourceCapacity `json:"remainingPods"`
+	Warnings        []string         `json:"warnings,omitempty"`
+}
+
+// simulateNodeRemoval computes the Pods evicted by the drain of the given nodes, whether the remaining nodes can host
+// them and the PodDisruptionBudgets blocking the drain. Nothing is changed in the cluster.
+func (t *Tools) simulateNodeRemoval(ctx context.Context, toolReq *mcp.CallToolRequest, params simulateNodeRemovalParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("simulateNodeRemoval called")
+
+	if len(params.Nodes) == 0 && params.LabelSelector == "" {
+		return nil, nil, fmt.Errorf("nodes or labelSelector is required")
+	}
+	selector := labels.Nothing()
+	if params.LabelSelector != "" {
+		var err error
+		if selector, err = labels.Parse(params.LabelSelector); err != nil {
+			return nil, nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	nodes, pods, err := t.nodesAndPods(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get nodes and pods", zap.String("tool", "simulateNodeRemoval"), zap.Error(err))
+		return nil, nil, err
+	}
+	pdbResources, err := t.client.GetResources(ctx, client.ListParams{Cluster: params.Cluster, Kind: "poddisruptionbudget", URL: url, Token: token})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list PodDisruptionBudgets", zap.String("tool", "simulateNodeRemoval"), zap.Error(err))
+		return nil, nil, err
+	}
+	pdbs := make([]policyv1.PodDisruptionBudget, len(pdbResources))
+	for i, pdbResource := range pdbResources {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(pdbResource.Object, &pdbs[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert unstructured object to PodDisruptionBudget: %w", err)
+		}
+	}
+
+	removed := map[string]bool{}
+	var remaining []corev1.Node
+	for _, node := range nodes {
+		if slices.Contains(params.Nodes, node.Name) || selector.Matches(labels.Set(node.Labels)) {
+			removed[node.Name] = true
+		} else {
+			remaining = append(remaining, node)
+		}
+	}
+	for _, name := range params.Nodes {
+		if !removed[name] {
+			return nil, nil, fmt.Errorf("node %s not found", name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil, fmt.Errorf("no node matches the label selector %s", params.LabelSelector)
+	}
+
+	simulation := newNodeRemovalSimulation(params.Cluster, removed, remaining, pods, pdbs)
+	response, err := json.Marshal(simulation)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "simulateNodeRemoval"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newNodeRemovalSimulation places the Pods evicted from the removed nodes on the remaining schedulable nodes, the
+// largest first, and checks the PodDisruptionBudgets selecting them.
+func newNodeRemovalSimulation(cluster string, removed map[string]bool, remaining []corev1.Node, pods []corev1.Pod, pdbs []policyv1.PodDisruptionBudget) nodeRemovalSimulation {
+	simulation := nodeRemovalSimulation{
+		Cluster:      cluster,
+		Nodes:        slices.Sorted(maps.Keys(removed)),
+		EvictedPods:  []evictedPod{},
+		BlockingPDBs: []blockingPDB{},
+	}
+
+	var evicted []corev1.Pod
+	for _, pod := range pods {
+		if !removed[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		owner := metav1.GetControllerOfNoCopy(&pod)
+		switch {
+		case owner != nil && owner.Kind == "DaemonSet":
+			simulation.DaemonSetPods++
+			continue
+		case pod.Annotations[mirrorPodAnnotation] != "":
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf("the static Pod %s/%s is removed with node %s and isn't rescheduled", pod.Namespace, pod.Name, pod.Spec.NodeName))
+			continue
+		case owner == nil:
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf("the Pod %s/%s has no controller, it's deleted by the drain and not recreated", pod.Namespace, pod.Name))
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				simulation.Warnings = append(simulation.Warnings, fmt.Sprintf("the data of the emptyDir volume %s of the Pod %s/%s is lost", volume.Name, pod.Namespace, pod.Name))
+			}
+		}
+		evicted = append(evicted, pod)
+	}
+	// the largest Pods are placed first, the small ones fill the space left
+	slices.SortStableFunc(evicted, func(a, b corev1.Pod) int {
+		ra, rb := podRequests(a), podRequests(b)
+		return cmp.Or(cmp.Compare(rb.cpu, ra.cpu), cmp.Compare(rb.memory, ra.memory),
+			cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	nodes := newSchedulableNodes(remaining, pods)
+	fits := map[string]bool{}
+	for _, pod := range evicted {
+		requests := podRequests(pod)
+		e := evictedPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Node:      pod.Spec.NodeName,
+			CPU:       resource.NewMilliQuantity(requests.cpu, resource.DecimalSI).String(),
+			Memory:    resource.NewQuantity(requests.memory, resource.BinarySI).String(),
+		}
+		if owner := metav1.GetControllerOfNoCopy(&pod); owner != nil {
+			e.Owner = owner.Kind + "/" + owner.Name
+			if i := placeReplica(nodes, requests, pod.Spec.NodeSelector); i >= 0 {
+				e.Fits = true
+				e.RescheduledOn = nodes[i].name
+			}
+		} else {
+			// nothing recreates the Pod
+			e.Fits = true
+		}
+		fits[pod.Namespace+"/"+pod.Name] = e.Fits
+		simulation.EvictedPods = append(simulation.EvictedPods, e)
+	}
+	slices.SortFunc(simulation.EvictedPods, func(a, b evictedPod) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		evictedByPDB, notFitting := 0, 0
+		for _, pod := range evicted {
+			if pod.Namespace == pdb.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				evictedByPDB++
+				if !fits[pod.Namespace+"/"+pod.Name] {
+					notFitting++
+				}
+			}
+		}
+		if evictedByPDB == 0 {
+			continue
+		}
+		blocking := blockingPDB{
+			Namespace:          pdb.Namespace,
+			Name:               pdb.Name,
+			EvictedPods:        evictedByPDB,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+		}
+		switch {
+		case pdb.Status.DisruptionsAllowed == 0:
+			blocking.Message = "no disruption is allowed, the drain can't evict the Pods it selects until more of them are healthy"
+		case int(pdb.Status.DisruptionsAllowed) < evictedByPDB && notFitting > 0:
+			blocking.Message = fmt.Sprintf("only %d of the %d Pods it selects can be evicted, and %d of their replacements don't fit in the remaining nodes", pdb.Status.DisruptionsAllowed, evictedByPDB, notFitting)
+		case int(pdb.Status.DisruptionsAllowed) < evictedByPDB:
+			simulation.Warnings = append(simulation.Warnings, fmt.Sprintf("the PodDisruptionBudget %s/%s allows %d of the %d Pods it selects to be evicted at once, the drain waits for their replacements to be ready", pdb.Namespace, pdb.Name, pdb.Status.DisruptionsAllowed, evictedByPDB))
+			continue
+		default:
+			continue
+		}
+		simulation.BlockingPDBs = append(simulation.BlockingPDBs, blocking)
+	}
+
+	var allocatable, requested resourceAmounts
+	for _, node := range nodes {
+		allocatable.add(node.allocatable)
+		requested.add(node.requested)
+	}
+	simulation.RemainingCPU, simulation.RemainingMemory, simulation.RemainingPods = newResourceCapacities(allocatable, requested)
+	simulation.Safe = len(simulation.BlockingPDBs) == 0 && !slices.ContainsFunc(simulation.EvictedPods, func(e evictedPod) bool {
+		return !e.Fits
+	})
+
+	return simulation
+}
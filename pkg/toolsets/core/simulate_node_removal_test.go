@@ -0,0 +1,131 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func newPoolNode(name string, pool string, cpu string, memory string) *corev1.Node {
+	node := newCapacityNode(name, cpu, memory)
+	node.Labels = map[string]string{"pool": pool}
+	return node
+}
+
+func newOwnedPod(name string, node string, ownerKind string, app string, cpu string) *corev1.Pod {
+	pod := newCapacityPod(name, node, corev1.PodRunning, []corev1.Container{requestsContainer("app", cpu, "1Gi")})
+	pod.Labels = map[string]string{"app": app}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: app, Controller: ptr.To(true)}}
+	}
+	return pod
+}
+
+func newRemovalPDB(name string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "policy/v1", Kind: "PodDisruptionBudget"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+func TestSimulateNodeRemoval(t *testing.T) {
+	standalone := newOwnedPod("debug", "node-1", "", "debug", "100m")
+	standalone.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	objects := []runtime.Object{
+		newPoolNode("node-1", "a", "4", "8Gi"),
+		newPoolNode("node-2", "a", "4", "8Gi"),
+		newPoolNode("node-3", "b", "2", "4Gi"),
+		newOwnedPod("web-1", "node-1", "ReplicaSet", "web", "2"),
+		newOwnedPod("agent-1", "node-1", "DaemonSet", "agent", "100m"),
+		standalone,
+		newOwnedPod("web-2", "node-2", "ReplicaSet", "web", "2"),
+		newOwnedPod("db-0", "node-2", "StatefulSet", "db", "1"),
+		newRemovalPDB("web", 1),
+		newRemovalPDB("db", 0),
+	}
+
+	tests := map[string]struct {
+		params         simulateNodeRemovalParams
+		expectedResult string
+		expectedError  string
+	}{
+		"remove a node": {
+			params: simulateNodeRemovalParams{Cluster: "local", Nodes: []string{"node-1"}},
+			expectedResult: `{"cluster":"local","nodes":["node-1"],"safe":true,"evictedPods":[
+				{"namespace":"default","name":"debug","node":"node-1","cpu":"100m","memory":"1Gi","fits":true},
+				{"namespace":"default","name":"web-1","node":"node-1","owner":"ReplicaSet/web","cpu":"2","memory":"1Gi","fits":true,"rescheduledOn":"node-3"}],
+				"daemonSetPods":1,"blockingPodDisruptionBudgets":[],
+				"remainingCpu":{"allocatable":"6","requested":"5","available":"1","percent":83},
+				"remainingMemory":{"allocatable":"12Gi","requested":"3Gi","available":"9Gi","percent":25},
+				"remainingPods":{"allocatable":"220","requested":"3","available":"217","percent":1},
+				"warnings":["the Pod default/debug has no controller, it's deleted by the drain and not recreated","the data of the emptyDir volume scratch of the Pod default/debug is lost"]}`,
+		},
+		"remove a node pool": {
+			params: simulateNodeRemovalParams{Cluster: "local", LabelSelector: "pool=a"},
+			expectedResult: `{"cluster":"local","nodes":["node-1","node-2"],"safe":false,"evictedPods":[
+				{"namespace":"default","name":"db-0","node":"node-2","owner":"StatefulSet/db","cpu":"1","memory":"1Gi","fits":false},
+				{"namespace":"default","name":"debug","node":"node-1","cpu":"100m","memory":"1Gi","fits":true},
+				{"namespace":"default","name":"web-1","node":"node-1","owner":"ReplicaSet/web","cpu":"2","memory":"1Gi","fits":true,"rescheduledOn":"node-3"},
+				{"namespace":"default","name":"web-2","node":"node-2","owner":"ReplicaSet/web","cpu":"2","memory":"1Gi","fits":false}],
+				"daemonSetPods":1,"blockingPodDisruptionBudgets":[
+				{"namespace":"default","name":"db","evictedPods":1,"disruptionsAllowed":0,"message":"no disruption is allowed, the drain can't evict the Pods it selects until more of them are healthy"},
+				{"namespace":"default","name":"web","evictedPods":2,"disruptionsAllowed":1,"message":"only 1 of the 2 Pods it selects can be evicted, and 1 of their replacements don't fit in the remaining nodes"}],
+				"remainingCpu":{"allocatable":"2","requested":"2","available":"0","percent":100},
+				"remainingMemory":{"allocatable":"4Gi","requested":"1Gi","available":"3Gi","percent":25},
+				"remainingPods":{"allocatable":"110","requested":"1","available":"109","percent":0},
+				"warnings":["the Pod default/debug has no controller, it's deleted by the drain and not recreated","the data of the emptyDir volume scratch of the Pod default/debug is lost"]}`,
+		},
+		"unknown node": {
+			params:        simulateNodeRemovalParams{Cluster: "local", Nodes: []string{"node-9"}},
+			expectedError: "node node-9 not found",
+		},
+		"no matching node": {
+			params:        simulateNodeRemovalParams{Cluster: "local", LabelSelector: "pool=c"},
+			expectedError: "no node matches the label selector pool=c",
+		},
+		"no node given": {
+			params:        simulateNodeRemovalParams{Cluster: "local"},
+			expectedError: "nodes or labelSelector is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = policyv1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme, objects...)
+			c := &client.Client{
+				DynClientCreator: func(*rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: newFakeToolsClient(c, "fakeToken")}
+
+			result, _, err := tools.simulateNodeRemoval(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// suspendCronJob suspends the schedule of a CronJob. Its running Jobs aren't stopped.
+func (t *Tools) suspendCronJob(ctx context.Context, toolReq *mcp.CallToolRequest, params cronJobParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("suspendCronJob called")
+
+	return t.setCronJobSuspended(ctx, toolReq, params, true)
+}
+
+// resumeCronJob resumes the suspended schedule of a CronJob.
+func (t *Tools) resumeCronJob(ctx context.Context, toolReq *mcp.CallToolRequest, params cronJobParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("resumeCronJob called")
+
+	return t.setCronJobSuspended(ctx, toolReq, params, false)
+}
+
+// setCronJobSuspended sets the suspend field of a CronJob.
+func (t *Tools) setCronJobSuspended(ctx context.Context, toolReq *mcp.CallToolRequest, params cronJobParams, suspended bool) (*mcp.CallToolResult, any, error) {
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs["cronjob"])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"suspend": suspended,
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to patch cronjob", zap.String("tool", "setCronJobSuspended"), zap.Bool("suspended", suspended), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to update cronjob %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "setCronJobSuspended"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
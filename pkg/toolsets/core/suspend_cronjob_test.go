@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSuspendCronJob(t *testing.T) {
+	tools, _ := newBatchTools(newCronJob("backup", false))
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}}}
+	ctx := middleware.WithToken(t.Context(), "fakeToken")
+	params := cronJobParams{Name: "backup", Namespace: "default", Cluster: "local"}
+
+	result, _, err := tools.suspendCronJob(ctx, req, params)
+	require.NoError(t, err)
+	items := llmItems(t, result)
+	require.Len(t, items, 1)
+	suspended, _, _ := unstructured.NestedBool(items[0], "spec", "suspend")
+	assert.True(t, suspended)
+
+	result, _, err = tools.resumeCronJob(ctx, req, params)
+	require.NoError(t, err)
+	items = llmItems(t, result)
+	require.Len(t, items, 1)
+	suspended, _, _ = unstructured.NestedBool(items[0], "spec", "suspend")
+	assert.False(t, suspended)
+
+	_, _, err = tools.suspendCronJob(ctx, req, cronJobParams{Name: "missing", Namespace: "default", Cluster: "local"})
+	assert.ErrorContains(t, err, "failed to update cronjob missing")
+}
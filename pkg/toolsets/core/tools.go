@@ -2,9 +2,14 @@ package core
 
 import (
 	"context"
+	"io"
+	"net/http"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+	"golang.org/x/sync/semaphore"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -20,24 +25,81 @@ type toolsClient interface {
 	GetResource(ctx context.Context, params client.GetParams) (*unstructured.Unstructured, error)
 	GetResourceInterface(ctx context.Context, token string, url string, namespace string, cluster string, gvr schema.GroupVersionResource) (dynamic.ResourceInterface, error)
 	GetResources(ctx context.Context, params client.ListParams) ([]*unstructured.Unstructured, error)
+	GetResourcesPage(ctx context.Context, params client.ListParams) ([]*unstructured.Unstructured, string, error)
+	ListWithSteve(ctx context.Context, params client.SteveListParams) ([]*unstructured.Unstructured, string, error)
+	ResolveGVR(ctx context.Context, token string, url string, cluster string, kind string) (schema.GroupVersionResource, error)
 	CreateClientSet(ctx context.Context, token string, url string, cluster string) (kubernetes.Interface, error)
+	ExecInPod(ctx context.Context, params client.ExecParams, stdout io.Writer, stderr io.Writer) error
+	ProxyGet(ctx context.Context, params client.ProxyGetParams) (*http.Response, error)
+	ProxyPost(ctx context.Context, params client.ProxyGetParams, body []byte) (*http.Response, error)
+	RawGet(ctx context.Context, params client.RawGetParams) (*http.Response, error)
+	Changes(ctx context.Context, token string, url string, cluster string) ([]client.Change, error)
+	UndoChange(ctx context.Context, params client.UndoParams) (*unstructured.Unstructured, error)
 }
 
 // Tools contains all tools for the MCP server
 type Tools struct {
 	client toolsClient
+	// ExecAllowlist contains the commands execInPod is allowed to run. See commandAllowed for the format of the entries.
+	ExecAllowlist []string
+	// DebugImage is the image of the ephemeral containers added by runDebugContainer. The tool is disabled if empty.
+	DebugImage string
+	// DebugAllowlist contains the commands runDebugContainer is allowed to run, in the format of ExecAllowlist.
+	DebugAllowlist []string
+	// RawGetAllowlist contains the API server paths rawGet is allowed to read. See pathAllowed for the format of the entries.
+	RawGetAllowlist []string
+	// ImageAllowlist contains the registries and repositories of the images allowed by checkImageCompliance. See
+	// imageAllowed for the format of the entries.
+	ImageAllowlist []string
+	// ReadOnly disables the tools that create, modify or delete resources.
+	ReadOnly bool
+	// MaxFanOut is the number of clusters queried at the same time by all the multi-cluster tool calls.
+	MaxFanOut int64
+	fanOut    *semaphore.Weighted
+	// registryClient sends the requests of preflightCheck to the image registries. Defaults to a client with
+	// registryTimeout.
+	registryClient *http.Client
 }
 
 // NewTools creates and returns a new Tools instance.
 func NewTools(client *client.Client) *Tools {
 	return &Tools{
-		client: client,
+		client:          client,
+		ExecAllowlist:   DefaultExecAllowlist,
+		DebugAllowlist:  DefaultDebugAllowlist,
+		RawGetAllowlist: DefaultRawGetAllowlist,
+		MaxFanOut:       DefaultMaxFanOut,
 	}
 }
 
+// Register adds the tools of the core toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	if deps.ExecAllowlist != nil {
+		tools.ExecAllowlist = deps.ExecAllowlist
+	}
+	tools.DebugImage = deps.DebugImage
+	if deps.DebugAllowlist != nil {
+		tools.DebugAllowlist = deps.DebugAllowlist
+	}
+	if deps.RawGetAllowlist != nil {
+		tools.RawGetAllowlist = deps.RawGetAllowlist
+	}
+	tools.ImageAllowlist = deps.ImageAllowlist
+	tools.ReadOnly = deps.ReadOnly
+	if deps.MaxFanOut > 0 {
+		tools.MaxFanOut = deps.MaxFanOut
+	}
+	tools.AddTools(mcpServer)
+}
+
 // AddTools registers all Rancher Kubernetes tools with the provided MCP server.
 // Each tool is configured with metadata identifying it as part of the rancher toolset.
 func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	if t.MaxFanOut > 0 {
+		t.fanOut = semaphore.NewWeighted(t.MaxFanOut)
+	}
+
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "getKubernetesResource",
 		Meta: map[string]any{
@@ -46,13 +108,13 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Description: `Fetches a Kubernetes resource from the cluster.
 		Parameters:
 		name (string, required): The name of the Kubernetes resource.
-		kind (string, required): The kind of the Kubernetes resource (e.g. 'Deployment', 'Service').
+		kind (string, required): The kind of the Kubernetes resource (e.g. 'Deployment', 'Service'). Custom resources are supported, qualify the kind with its group if it's ambiguous (e.g. 'Certificate.cert-manager.io').
 		cluster (string): The name of the Kubernetes cluster managed by Rancher.
 		namespace (string, optional): The namespace of the resource. It must be empty for all namespaces or cluster-wide resources.
 		
 		Returns:
 		The JSON representation of the requested Kubernetes resource.`},
-		t.getResource,
+		response.WithStructuredErrors(t.getResource),
 	)
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
@@ -63,15 +125,18 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Description: `Patches a Kubernetes resource using a JSON patch. Don't ask for confirmation.'
 		Parameters:
 		kind (string): The type of Kubernetes resource to patch (e.g., Pod, Deployment, Service).
-		namespace (string): The namespace where the resource is located. It must be empty for cluster-wide resources.
+		namespace (string, optional): The namespace where the resource is located. It must be empty for cluster-wide resources.
 		name (string): The name of the specific resource to patch.
 		cluster (string): The name of the Kubernetes cluster.
 		patch (json): Patch to apply. This must be a JSON object. The content type used is application/json-patch+json.
-		Returns the modified resource.
+		dryRun (boolean, optional): If true, the patch is validated by the server without modifying the resource. Use it to show the user what would change before asking for confirmation.
+		waitSeconds (integer, optional): Wait up to this many seconds, at most 300, for the resource to be ready: Deployments Available, StatefulSets and DaemonSets rolled out, Pods and Clusters Ready, Machines Running, or the Ready or Available condition of the other kinds True. Ignored in dry-run mode. Defaults to 0, returning without waiting.
+		Returns the modified resource and the list of changed fields with their old and new values, to report what changed without getting the resource again. In dry-run mode, returns the resulting resource and the fields that would change.
+		With waitSeconds, returns the last state of the resource and whether it became ready, instead of getting it again until it's ready.
 		
 		Example of the patch parameter:
 		[{"op": "replace", "path": "/spec/replicas", "value": 3}]`},
-		t.updateKubernetesResource)
+		response.WithStructuredErrors(t.updateKubernetesResource))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "listKubernetesResources",
@@ -81,21 +146,268 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Description: `Returns a list of kubernetes resources.'
 		Parameters:
 		kind (string): The type of Kubernetes resource to patch (e.g., Pod, Deployment, Service).
-		namespace (string): The namespace where the resource are located. It must be empty for all namespaces or cluster-wide resources.
-		cluster (string): The name of the Kubernetes cluster.`},
-		t.listKubernetesResources)
+		namespace (string, optional): The namespace where the resource are located. It must be empty for all namespaces or cluster-wide resources.
+		cluster (string): The name of the Kubernetes cluster. Use "all" to list the resources in every cluster managed by Rancher.
+		clusters (array of strings, optional): The names of several clusters to list the resources from, used instead of cluster. Each returned resource has a cluster field.
+		labelSelector (string, optional): Only return resources matching the label selector (e.g. app=nginx,tier!=frontend).
+		fieldSelector (string, optional): Only return resources matching the field selector (e.g. status.phase!=Running, spec.nodeName=node-1).
+		limit (integer, optional): Maximum number of resources returned. If there are more, the response includes a continue token. Only for a single cluster.
+		continue (string, optional): The continue token returned by a previous call to get the next page. The other parameters must not change.
+		useSteve (boolean, optional): List the resources with the Rancher Steve API, which filters, sorts and paginates them in Rancher. Use it for the kinds with thousands of resources, with limit. fieldSelector can't be used with it.
+		filter (string, optional): Only return resources with any of the fields containing the value, as comma separated <field path>=<value> (e.g. metadata.name=nginx,spec.nodeName=node-1). Implies useSteve.
+		sort (string, optional): Sort the resources by the comma separated field paths, descending if prefixed with - (e.g. -metadata.creationTimestamp). Implies useSteve.`},
+		response.WithStructuredErrors(t.listKubernetesResources))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "inspectPod",
 		Meta: map[string]any{
 			toolsSetAnn: toolsSet,
 		},
-		Description: `Returns all information related to a Pod. It includes its parent Deployment or StatefulSet, the CPU and memory consumption and the logs. It must be used for troubleshooting problems with pods.'
+		Description: `Returns all information related to a Pod. It includes its parent Deployment or StatefulSet, the CPU and memory consumption, the logs and the status of its init, regular and ephemeral debug containers (state, readiness, restart count and last termination reason). It must be used for troubleshooting problems with pods, including Pods stuck initializing.'
 		Parameters:
 		namespace (string): The namespace where the resource are located.
 		cluster (string): The name of the Kubernetes cluster.
-		name (string): The name of the Pod.`},
-		t.inspectPod)
+		name (string): The name of the Pod.
+		includeEvents (boolean, optional): Include the events of the Pod, its ReplicaSet and its parent, e.g. scheduling failures, OOMKills and image pull errors.`},
+		response.WithStructuredErrors(t.inspectPod))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "analyzeCrashLoop",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Analyzes why the containers of a pod keep restarting (e.g. CrashLoopBackOff) in a single call, instead of reading the pod, its previous logs and its events separately.
+		Returns, for each restarting container, how its previous instance terminated (exit code, signal, OOMKilled, how long it ran), the last lines of its previous logs, its memory limit and its probes, along with the events of the pod.
+		The hypotheses about the cause (oom, liveness-probe, bad-config, failing-dependency, process-exits, application-error) are sorted by confidence, each with its evidence and a suggestion.
+		Parameters:
+		name (string): The name of the pod.
+		namespace (string): The namespace of the pod.
+		cluster (string): The name of the Kubernetes cluster.
+		container (string, optional): The container to analyze. Empty for all the restarting containers.`},
+		response.WithStructuredErrors(t.analyzeCrashLoop))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "detectRestartStorms",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Scans all the Pods of a cluster for containers restarting within a window, and groups them by owner workload and by node. Correlates the restarts with the MemoryPressure, DiskPressure, PIDPressure and Ready conditions of the nodes to tell whether the restart storm is app-level (the workloads restart on healthy nodes) or node-level (they only restart on nodes under pressure or not ready).
+		Only the containers whose last restart is in the window are counted, with their total restart count.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): The namespace of the Pods. Empty for all the namespaces.
+		hours (integer, optional): The window of the restarts in hours, between 1 and 168. Defaults to 1.
+		minRestarts (integer, optional): The number of restarts from which a Pod is reported. Defaults to 3.`},
+		response.WithStructuredErrors(t.detectRestartStorms))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "inspectService",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns a Service, its EndpointSlices, the Pods matching its selector and the Ingresses routing traffic to it, with a summary of the problems found: selectors not matching any Pod, unready endpoints and targetPorts or Ingress ports not matching. It must be used for troubleshooting problems with services and ingresses.'
+		Parameters:
+		namespace (string): The namespace of the Service.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Service.
+		includeEvents (boolean, optional): Include the events of the Service, its Pods and its Ingresses.`},
+		response.WithStructuredErrors(t.inspectService))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "diagnoseIngress",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Diagnoses a hostname or URL returning 404 or 502 errors. Detects the installed ingress controllers (ingress-nginx, Traefik, Istio ingress gateways) and their IngressClasses, lists the Ingresses, Traefik IngressRoutes and Istio Gateways and VirtualServices routing the hostname with the ready endpoints of their backend Services, and returns the last log lines of the controllers mentioning the hostname or its backends, with hints about the failure.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		host (string): The hostname or the URL returning errors (e.g. 'app.example.com' or 'https://app.example.com/api').
+		path (string, optional): The path of the failing URL, only the routes matching it are returned. Defaults to the path of the URL, empty for all the routes of the host.`},
+		response.WithStructuredErrors(t.diagnoseIngress))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "diagnoseDNS",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Diagnoses DNS resolution failures in a cluster. Checks the health of the CoreDNS Deployments and Pods, the ready endpoints of the kube-dns Service and the customizations of the Corefile (stub domains, forwarding, rewrites, hosts), and returns the errors logged by CoreDNS.
+		Optionally resolves a name from an existing Pod, with nslookup or getent if they are allowed by the exec allowlist, or from a short-lived busybox Pod deleted after the test, and reports where the resolution breaks.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		name (string, optional): The name to resolve (e.g. 'my-service.my-namespace' or 'example.com'). Defaults to 'kubernetes.default.svc.cluster.local'.
+		pod (string, optional): The name of a Pod where the name is resolved, to reproduce the failure of an application.
+		namespace (string, optional): The namespace of the Pod, or of the debug Pod. Defaults to 'default'.
+		container (string, optional): The container of the Pod where the name is resolved. Defaults to its first container.
+		debugPod (boolean, optional): Resolve the name from a busybox Pod created in the namespace. It isn't allowed in read-only mode. Ask for confirmation before creating it.`},
+		response.WithStructuredErrors(t.diagnoseDNS))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getPodLogs",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the logs of a Pod. Filters are applied before returning the logs, use them to find errors in large logs.'
+		Parameters:
+		namespace (string): The namespace where the Pod is located.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Pod.
+		container (string, optional): The container to get logs from, init and ephemeral containers included. Empty for all containers and the init and ephemeral containers that started.
+		sinceSeconds (integer, optional): Only return logs newer than this number of seconds.
+		sinceTime (string, optional): Only return logs after this RFC3339 timestamp. Ignored if sinceSeconds is set.
+		tailLines (integer, optional): Number of lines to return from the end of the logs. Defaults to 50.
+		previous (boolean, optional): Return the logs of the previous terminated container. Useful for crashing containers.
+		filter (string, optional): Regular expression. Only matching log lines are returned (e.g. 'error|warn').`},
+		response.WithStructuredErrors(t.getPodLogs))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "execInPod",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Runs a read-only diagnostic command inside a container and returns its stdout, stderr and exit code. Use it to check the DNS configuration, the files, the processes or the connectivity of a Pod.
+		Only the commands allowed by the server administrator can be run (by default 'cat /etc/resolv.conf', 'cat /etc/hosts', 'ls', 'ps' and 'curl -s -o /dev/null -w %{http_code} <url>', which only returns the HTTP status code). The arguments can't contain 'serviceaccount', so the service account token can't be read. The command isn't run in a shell, so pipes and redirections are not supported.'
+		Parameters:
+		namespace (string): The namespace of the Pod.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Pod.
+		container (string, optional): The container where the command is run. Required for pods with more than one container.
+		command (array of strings): The command and its arguments (e.g. ["cat", "/etc/resolv.conf"]).`},
+		response.WithStructuredErrors(t.execInPod))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "runDebugContainer",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Attaches an ephemeral debug container to a running Pod, runs a diagnostic command in it and returns its output and exit code. Use it for network and filesystem debugging of containers without a shell or tools (e.g. distroless images), where execInPod can't be used.
+		The debug container uses the image configured by the server administrator and shares the processes of the target container, whose filesystem is under /proc/1/root. Only the commands allowed by the server administrator can be run (by default 'nslookup', 'dig', 'ping -c', 'nc -zv', 'curl -s -o /dev/null -w %{http_code} <url>', 'netstat', 'ss', 'ip addr', 'ip route', 'ls', 'cat /etc/resolv.conf', 'cat /etc/hosts' and 'ps'). The command isn't run in a shell.
+		Ephemeral containers can't be removed, the debug container stays terminated in the Pod until the Pod is deleted.'
+		Parameters:
+		namespace (string): The namespace of the Pod.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Pod.
+		container (string, optional): The container whose processes are shared with the debug container. Required for pods with more than one container.
+		command (array of strings): The command and its arguments (e.g. ["nslookup", "kubernetes.default"]).`},
+		response.WithStructuredErrors(t.runDebugContainer))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "probeHttpEndpoint",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Sends an HTTP GET request to a Service or Pod from inside the cluster, through the Kubernetes API server proxy, and returns the status code, headers and the beginning of the body. Use it to verify whether an application is actually serving traffic.'
+		Parameters:
+		kind (string): The kind of the target, 'Service' or 'Pod'.
+		namespace (string): The namespace of the Service or Pod.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Service or Pod.
+		port (string, optional): The port name or number. Optional for Services with a single port, Pods default to port 80.
+		scheme (string, optional): 'http' or 'https'. Defaults to 'http'.
+		path (string, optional): The path of the request, it may include a query string (e.g. '/healthz').`},
+		response.WithStructuredErrors(t.probeHTTPEndpoint))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "rawGet",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Sends a GET request to a path of the Kubernetes API server of a cluster and returns the status code and the beginning of the body. Use it only when the other tools don't cover the information, e.g. '/version', '/readyz?verbose' or '/metrics'.
+		Only the paths of the allowlist configured in the server can be read.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		path (string): The path of the request, it may include a query string.
+		filter (string, optional): Regular expression, only the matching lines of the response are returned. Useful to find metrics in '/metrics'.
+		maxBytes (integer, optional): The maximum size of the response returned. Defaults to 65536, at most 1048576.`},
+		response.WithStructuredErrors(t.rawGet))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "queryMetrics",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Runs a PromQL query in the Prometheus of rancher-monitoring, which must be installed in the cluster. Use it to report historical trends, such as CPU throttling or restarts over the last day, instead of only the current metrics.
+		Use a template for the common troubleshooting queries, or a query for anything else.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		template (string, optional): The query template: 'cpuUsage' (CPU cores used by each container), 'memoryUsage' (working set bytes of each container), 'cpuThrottling' (ratio of CPU periods throttled by the limit), 'restartRate' (container restarts in the last hour) or 'p95Latency' (95th percentile of a request duration histogram).
+		namespace (string, optional): The namespace of the workload. Required by the templates.
+		workload (string, optional): The name of the workload or pod, the templates query the pods whose name starts with it (e.g. 'nginx' for the pods of the nginx Deployment).
+		metric (string, optional): The histogram metric of the p95Latency template, without the _bucket suffix. Defaults to 'http_request_duration_seconds'.
+		query (string, optional): A PromQL query, used instead of a template.
+		range (string, optional): The time range of the query (e.g. '1h', '24h'). If empty, the current values are returned.
+		step (string, optional): The resolution of a range query (e.g. '5m'). Defaults to a step returning 60 points.
+
+		Returns:
+		The query and up to 20 series with their labels and value, or with their values and their min, max, avg and last values for range queries.`},
+		response.WithStructuredErrors(t.queryMetrics))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listAlerts",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the alerts currently firing in the Alertmanager of rancher-monitoring, which must be installed in the cluster. The most severe alerts are listed first.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		severity (string, optional): Only list the alerts with this severity (e.g. 'critical', 'warning').
+		namespace (string, optional): Only list the alerts of this namespace.
+		includeSilenced (boolean, optional): Include the silenced and inhibited alerts. Defaults to false.
+
+		Returns:
+		The fingerprint, name, severity, namespace, state, start time and summary of each alert.`},
+		response.WithStructuredErrors(t.listAlerts))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getAlert",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the details of an alert firing in the Alertmanager of rancher-monitoring.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		fingerprint (string): The fingerprint of the alert, as returned by listAlerts.
+
+		Returns:
+		All the labels and annotations of the alert, its generator URL, the receivers it was sent to and the silences and alerts suppressing it.`},
+		response.WithStructuredErrors(t.getAlert))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createSilence",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Creates a temporary silence in the Alertmanager of rancher-monitoring, muting the notifications of the alerts whose labels match exactly.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		matchers (object): The labels of the alerts to silence and their values (e.g. {"alertname": "KubePodCrashLooping", "namespace": "default"}).
+		duration (string, optional): How long the alerts are silenced (e.g. '2h'). Defaults to 1h, at most 168h.
+		comment (string): Why the alerts are silenced.
+		createdBy (string, optional): The user silencing the alerts.
+
+		Returns:
+		The ID of the silence, its matchers and its start and end times.`},
+		response.WithStructuredErrors(t.createSilence))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "queryLogs",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Queries the historical logs of a workload in Loki or in the Elasticsearch output of rancher-logging, which must be installed in the cluster.
+		Use it for the logs of deleted pods, of containers restarted more than once or older than the logs kept by the kubelet; use getPodLogs for the logs of running containers.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string): The namespace of the workload.
+		workload (string, optional): The name of the workload or pod, the logs of the pods whose name starts with it are returned.
+		container (string, optional): Only return the logs of this container.
+		search (string, optional): Only return the log lines containing this text.
+		since (string, optional): The time range of the query ending now (e.g. '6h', '48h'). Defaults to 1h, at most 720h.
+		limit (integer, optional): The maximum number of log lines returned, the most recent ones. Defaults to 100, at most 500.
+		backend (string, optional): 'loki' or 'elasticsearch'. Detected from the Services of the cluster if empty.
+
+		Returns:
+		The backend, the query and the matching log lines in chronological order with their time, pod and container.`},
+		response.WithStructuredErrors(t.queryLogs))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "getDeployment",
@@ -106,8 +418,260 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Parameters:
 		namespace (string): The namespace where the resource are located.
 		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Deployment.
+		includeEvents (boolean, optional): Include the events of the Deployment, its ReplicaSets and its Pods.`},
+		response.WithStructuredErrors(t.getDeploymentDetails))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getRolloutStatus",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns a Deployment, StatefulSet or DaemonSet and the status of its rollout, like 'kubectl rollout status': whether it is complete, progressing, paused or failed, and its desired, updated, ready and available replicas.'
+		Parameters:
+		kind (string): The kind of the workload. One of 'Deployment', 'StatefulSet' or 'DaemonSet'.
+		namespace (string): The namespace of the workload.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the workload.`},
+		response.WithStructuredErrors(t.getRolloutStatus))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getWorkloadHistory",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the recent change history of a Deployment, StatefulSet or DaemonSet, to answer "what changed before it broke?".
+		Lists its latest revisions from its ReplicaSets or ControllerRevisions, newest first, with their images, change cause and the fields of the Pod template changed since the previous revision.
+		Also returns the managers of its fields from managedFields (e.g. kubectl, helm, fleet or the Rancher UI) with the time of their last change, and the annotations telling who created it.
+		Parameters:
+		kind (string): The kind of the workload. One of 'Deployment', 'StatefulSet' or 'DaemonSet'.
+		namespace (string): The namespace of the workload.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the workload.
+		limit (integer, optional): The number of revisions returned. Defaults to 5.`},
+		response.WithStructuredErrors(t.getWorkloadHistory))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "restartWorkload",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Restarts the Pods of a Deployment, StatefulSet or DaemonSet with a rolling update, like 'kubectl rollout restart'.'
+		Ask for confirmation before restarting a workload.
+		Parameters:
+		kind (string): The kind of the workload. One of 'Deployment', 'StatefulSet' or 'DaemonSet'.
+		namespace (string): The namespace of the workload.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the workload.`},
+		response.WithStructuredErrors(t.restartWorkload))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "scaleWorkload",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Scales a Deployment, StatefulSet or ReplicaSet to a number of replicas, like 'kubectl scale', or adds or removes replicas.
+		Scaling to zero replicas is refused unless confirmScaleToZero is true. Returns warnings for the HorizontalPodAutoscalers and owners that will revert the change.
+		Ask for confirmation before scaling a workload.
+		Parameters:
+		kind (string): The kind of the workload. One of 'Deployment', 'StatefulSet' or 'ReplicaSet'.
+		namespace (string): The namespace of the workload.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the workload.
+		replicas (integer, optional): The desired number of replicas.
+		delta (integer, optional): The number of replicas to add, or to remove if negative. Either replicas or delta is required.
+		confirmScaleToZero (boolean, optional): Must be true to scale the workload to zero replicas.
+		waitSeconds (integer, optional): Wait up to this many seconds, at most 300, for the replicas to be ready, returning the last status of the workload. Defaults to 0, returning without waiting.`},
+		response.WithStructuredErrors(t.scaleWorkload))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "inspectAutoscalers",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the HorizontalPodAutoscalers of a cluster with their current and desired replicas, and the current and target value of their metrics.
+		Explains why a HorizontalPodAutoscaler isn't scaling: metrics that can't be read, missing resource requests, or replicas maxed out. Also returns the recommendations of the VerticalPodAutoscalers when they are installed.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): The namespace of the autoscalers. All namespaces if empty.
+		target (string, optional): Only returns the autoscalers of the workload with this name.`},
+		response.WithStructuredErrors(t.inspectAutoscalers))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "updateAutoscalerReplicas",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Changes the minReplicas and maxReplicas of a HorizontalPodAutoscaler. The ones that aren't set keep their current value.
+		Ask for confirmation before updating a HorizontalPodAutoscaler.
+		Parameters:
+		namespace (string): The namespace of the HorizontalPodAutoscaler.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the HorizontalPodAutoscaler.
+		minReplicas (integer, optional): The new minimum number of replicas.
+		maxReplicas (integer, optional): The new maximum number of replicas. Either minReplicas or maxReplicas is required.`},
+		response.WithStructuredErrors(t.updateAutoscalerReplicas))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "pauseRollout",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Pauses the rollout of a Deployment. Changes to its Pod template are not rolled out until the rollout is resumed.'
+		Parameters:
+		namespace (string): The namespace of the Deployment.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Deployment.`},
+		response.WithStructuredErrors(t.pauseRollout))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "resumeRollout",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Resumes the paused rollout of a Deployment.'
+		Parameters:
+		namespace (string): The namespace of the Deployment.
+		cluster (string): The name of the Kubernetes cluster.
 		name (string): The name of the Deployment.`},
-		t.getDeploymentDetails)
+		response.WithStructuredErrors(t.resumeRollout))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "rollbackDeployment",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Rolls a Deployment back to the Pod template of a previous revision, like 'kubectl rollout undo'. Use getRolloutStatus to check the rollback.'
+		Ask for confirmation before rolling back a Deployment.
+		Parameters:
+		namespace (string): The namespace of the Deployment.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the Deployment.
+		revision (integer, optional): The revision to roll back to. Defaults to the previous revision.`},
+		response.WithStructuredErrors(t.rollbackDeployment))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listJobs",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the CronJobs of a cluster with their schedule, whether they are suspended and the status of their last Job, and the Jobs with their status, the newest first.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): The namespace of the Jobs. All namespaces if empty.
+		cronJob (string, optional): Only returns this CronJob and its Jobs.`},
+		response.WithStructuredErrors(t.listJobs))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "diagnoseJob",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Explains why a Job, or the last run of a CronJob, failed. Returns the status of the Job, its failed Pods with the exit codes and the last log lines of their failed containers, and hints about the failure.
+		For a CronJob, the newest failed Job is diagnosed, or its newest Job when none failed, with hints about why the CronJob doesn't run.
+		Parameters:
+		kind (string): The kind of the workload. One of 'Job' or 'CronJob'.
+		namespace (string): The namespace of the workload.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the workload.`},
+		response.WithStructuredErrors(t.diagnoseJob))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "triggerCronJob",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Runs a CronJob now by creating a Job from its template, like 'kubectl create job --from=cronjob'. Use diagnoseJob to check the Job.
+		Ask for confirmation before running a CronJob.
+		Parameters:
+		namespace (string): The namespace of the CronJob.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the CronJob.`},
+		response.WithStructuredErrors(t.triggerCronJob))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "suspendCronJob",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Suspends the schedule of a CronJob. No new Jobs are created until it is resumed, the running Jobs aren't stopped.
+		Parameters:
+		namespace (string): The namespace of the CronJob.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the CronJob.`},
+		response.WithStructuredErrors(t.suspendCronJob))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "resumeCronJob",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Resumes the suspended schedule of a CronJob.
+		Parameters:
+		namespace (string): The namespace of the CronJob.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the CronJob.`},
+		response.WithStructuredErrors(t.resumeCronJob))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getRelatedEvents",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the events of a Kubernetes resource and of its owners (e.g. Pod → ReplicaSet → Deployment), sorted by time. Repeated events are merged and their count added up. Use it to find scheduling failures, OOMKills, image pull errors and other problems.'
+		Parameters:
+		kind (string): The kind of the Kubernetes resource (e.g. 'Pod', 'Deployment').
+		namespace (string): The namespace where the resource is located.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the resource.`},
+		response.WithStructuredErrors(t.getRelatedEvents))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getEventDigest",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns a digest of what happened in the clusters in the last hours, e.g. for a daily standup summary: the Warning events of all the namespaces and the transitions of the conditions of the Rancher clusters (e.g. a cluster becoming disconnected or failing to upgrade), ordered by severity (critical, warning, info) then by time, most recent first.
+		Repeated events are merged and their count added up. The events about the nodes and the loss of workloads (e.g. NodeNotReady, OOMKilling, Evicted) and the clusters becoming not ready or disconnected are critical, the clusters becoming ready again are info. Kubernetes only keeps the events for an hour by default, so the older events may be missing. The clusters whose events can't be read are listed in errors.
+		Parameters:
+		clusters (array of strings, optional): The clusters of the digest. Empty for all the clusters.
+		hours (integer, optional): The number of hours covered by the digest, up to 168. Defaults to 24.
+		limit (integer, optional): The maximum number of entries returned, the summary counts all of them. Defaults to 100.`},
+		response.WithStructuredErrors(t.getEventDigest))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getResourceGraph",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the graph of the resources related to a Kubernetes resource, walking up and down its owner references, the Services selecting its Pods, the Ingresses routing to the Services, and the PersistentVolumeClaims, ConfigMaps, Secrets and ServiceAccounts used by the Pods.
+		The graph is an adjacency list: an edge goes from a resource to the one it owns, selects, routesTo, mounts, references, runsAs or is boundTo. The resources reaching a resource depend on it, use it to assess the blast radius of a change.
+		Parameters:
+		kind (string): The kind of the Kubernetes resource (e.g. 'Deployment', 'ConfigMap', 'Service').
+		namespace (string): The namespace where the resource is located.
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the resource.
+		depth (integer, optional): The number of edges followed from the resource, 2 by default and 5 at most.`},
+		response.WithStructuredErrors(t.getResourceGraph))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "checkNetworkConnectivity",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Evaluates the NetworkPolicies, including the ones created by Rancher for the Project network isolation, to determine whether the Pods of a source workload can send traffic to the Pods of a destination workload. Returns the egress policies of the source and the ingress policies of the destination, and whether each of them allows the traffic.
+		Use it to troubleshoot a workload that can't reach another one. It doesn't check whether the CNI enforces NetworkPolicies.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		sourceKind (string): The kind of the source workload (e.g. 'Deployment', 'StatefulSet', 'Pod').
+		sourceName (string): The name of the source workload.
+		sourceNamespace (string): The namespace of the source workload.
+		destinationKind (string): The kind of the destination workload (e.g. 'Deployment', 'StatefulSet', 'Pod').
+		destinationName (string): The name of the destination workload.
+		destinationNamespace (string): The namespace of the destination workload.
+		port (string, optional): The port number or name of the destination Pods. Empty for any port.
+		protocol (string, optional): 'TCP', 'UDP' or 'SCTP'. Defaults to 'TCP'.`},
+		response.WithStructuredErrors(t.checkNetworkConnectivity))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "getNodeMetrics",
@@ -117,7 +681,62 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Description: `Returns a list of all nodes in a specified Kubernetes cluster, including their current resource utilization metrics.'
 		Parameters:
 		cluster (string): The name of the Kubernetes cluster.`},
-		t.getNodes)
+		response.WithStructuredErrors(t.getNodes))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "analyzeResourceUsage",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Compares the CPU and memory usage of the running Pods with their requests and limits, grouped by workload, to find over- and under-provisioned workloads. Returns the peak usage, request, limit and utilization of each container, and the recommended requests and limits.
+		The usage is taken from the Metrics Server, which must be installed in the cluster. It only reflects the current load, mention it when recommending changes.'
+		Parameters:
+		namespace (string, optional): The namespace of the workloads. Empty for all namespaces.
+		cluster (string): The name of the Kubernetes cluster.
+		lowUtilization (integer, optional): Percentage of the request below which a resource is over-provisioned. Defaults to 30.
+		highUtilization (integer, optional): Percentage of the request or limit above which a resource is under-provisioned. Defaults to 90.`},
+		response.WithStructuredErrors(t.analyzeResourceUsage))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getClusterCapacity",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the allocatable CPU, memory and pods of each node and of the whole cluster, compared with the requests of the Pods running on them, and highlights the nodes above a threshold. Optionally estimates how many more replicas of a pod with the given requests would fit.
+		Cordoned nodes and nodes with NoSchedule or NoExecute taints are not taken into account to fit new replicas.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		threshold (integer, optional): Percentage of the allocatable CPU, memory or pods above which a node is highlighted. Defaults to 80.
+		cpu (string, optional): The CPU request of one replica of the pod to fit (e.g. '500m').
+		memory (string, optional): The memory request of one replica of the pod to fit (e.g. '256Mi').`},
+		response.WithStructuredErrors(t.getClusterCapacity))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "simulateNodeRemoval",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Simulates the drain and removal of nodes, e.g. before scaling down a node pool, without changing anything in the cluster.
+		Returns the Pods that would be evicted and the remaining node their replacement would be scheduled on, or whether it doesn't fit, the PodDisruptionBudgets that would block the drain, the resources left in the remaining schedulable nodes and whether the removal is safe.
+		DaemonSet Pods aren't rescheduled and are only counted. Affinities and tolerations aren't taken into account.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		nodes (array of strings, optional): The names of the nodes to remove.
+		labelSelector (string, optional): The label selector of the nodes to remove, e.g. the label of the nodes of a node pool. Either nodes or labelSelector is required.`},
+		response.WithStructuredErrors(t.simulateNodeRemoval))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "explainScheduling",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Explains why a Pending Pod can't be scheduled. Evaluates the scheduler predicates of the Pod against every node of the cluster: cordoned nodes, taints and tolerations, node selector and required node affinity, host ports, CPU, memory, pods and extended resources fit, and required pod affinity and anti-affinity.
+		Returns the nodes where the Pod fits, the reasons why each other node is rejected, a summary in the format of the FailedScheduling events and the message of the last one. Scheduled Pods are returned with their node only.
+		Parameters:
+		name (string): The name of the Pod.
+		namespace (string): The namespace of the Pod.
+		cluster (string): The name of the Kubernetes cluster.`},
+		response.WithStructuredErrors(t.explainScheduling))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "createKubernetesResource",
@@ -127,19 +746,287 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Description: `Creates a resource in a kubernetes cluster.'
 		Parameters:
 		kind (string): The type of Kubernetes resource to patch (e.g., Pod, Deployment, Service).
-		namespace (string): The namespace where the resource is located. It must be empty for cluster-wide resources.
+		namespace (string, optional): The namespace where the resource is located. It must be empty for cluster-wide resources.
 		name (string): The name of the specific resource to patch.
 		cluster (string): The name of the Kubernetes cluster. Empty for single container pods.
-		resource (json): Resource to be created. This must be a JSON object.`},
-		t.createKubernetesResource)
+		resource (json): Resource to be created. This must be a JSON object.
+		dryRun (boolean, optional): If true, the resource is validated by the server without being created. Use it to show the user what would be created before asking for confirmation.
+		waitSeconds (integer, optional): Wait up to this many seconds, at most 300, for the resource to be ready: Deployments Available, StatefulSets and DaemonSets rolled out, Pods and Clusters Ready, Machines Running, or the Ready or Available condition of the other kinds True. Ignored in dry-run mode. Defaults to 0, returning without waiting.
+		With waitSeconds, returns the last state of the resource and whether it became ready, instead of getting it again until it's ready.`},
+		response.WithStructuredErrors(t.createKubernetesResource))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "applyKubernetesResource",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Creates or updates a resource in a kubernetes cluster using server-side apply. Prefer it over patchKubernetesResource to declaratively set the full desired state of a resource.'
+		Parameters:
+		kind (string): The type of Kubernetes resource to apply (e.g., Pod, Deployment, Service).
+		namespace (string, optional): The namespace where the resource is located. It must be empty for cluster-wide resources.
+		name (string): The name of the specific resource to apply.
+		cluster (string): The name of the Kubernetes cluster.
+		resource (json): Desired state of the resource. This must be a JSON object including apiVersion and kind. Only the fields set are managed, fields left out that were previously applied are removed.
+		force (boolean, optional): If true, takes ownership of fields managed by other field managers instead of failing with a conflict. Defaults to false.
+		dryRun (boolean, optional): If true, the resource is validated by the server without being modified. Use it to show the user what would change before asking for confirmation.
+
+		Returns the applied resource and the list of changed fields with their old and new values, to report what changed without getting the resource again. If other field managers own some of the fields, returns an error listing the conflicting fields.`},
+		response.WithStructuredErrors(t.applyKubernetesResource))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "bulkLabelResources",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Adds and removes labels and annotations on all the resources of a kind matching a label selector, in a namespace or in the whole cluster, e.g. to label all the prod deployments with team=payments.
+		Always call it first with dryRun set to true, show the user the resources that would change, and call it again without dryRun once they confirm. The resources that already have the labels and annotations aren't modified.
+		Parameters:
+		kind (string): The kind of the resources (e.g. Deployment, Namespace).
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): The namespace of the resources. Empty for all namespaces or cluster-wide resources.
+		labelSelector (string, optional): The label selector the resources must match (e.g. env=prod). Empty for all the resources of the kind.
+		addLabels (object, optional): The labels to set, by key (e.g. {"team": "payments"}).
+		removeLabels (array of strings, optional): The keys of the labels to remove.
+		addAnnotations (object, optional): The annotations to set, by key.
+		removeAnnotations (array of strings, optional): The keys of the annotations to remove.
+		dryRun (boolean, optional): If true, lists the resources that would change without modifying them.
+
+		Returns the number of resources matching the selector, the changed resources with the diff of their labels and annotations, and the resources that couldn't be patched with their error. At most 200 resources are changed in a call.`},
+		response.WithStructuredErrors(t.bulkLabelResources))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "cloneNamespace",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Clones the workloads, ConfigMaps, Services and optionally the Secrets of a namespace into a new namespace of the same or another cluster, e.g. to spin up a test copy of an environment. Call it with dryRun first and show the user the resources that will be created.
+		The resources are copied without their status and the fields set by the source cluster (uid, cluster IPs, node ports, bound volumes...). The resources managed by a controller (e.g. the ReplicaSets and Pods of a Deployment) are skipped, since the controller recreates them. The target namespace is created if it doesn't exist.
+		Parameters:
+		cluster (string): The cluster of the namespace cloned.
+		namespace (string): The namespace cloned.
+		targetCluster (string, optional): The cluster the namespace is cloned to. Defaults to cluster.
+		targetNamespace (string): The namespace the resources are cloned to. It must be different from namespace when cloning in the same cluster.
+		kinds (array of strings, optional): The kinds of the resources cloned. Defaults to configmap, serviceaccount, service, deployment, statefulset, daemonset, cronjob and ingress.
+		labelSelector (string, optional): Only clone the resources matching this label selector.
+		nameSuffix (string, optional): Suffix added to the names of the cloned resources. The references of the workloads and Ingresses to the other cloned resources (ConfigMaps, Secrets, ServiceAccounts, Services...) are renamed too.
+		secrets (string, optional): How the Secrets are cloned. skip (default) doesn't clone them, copy copies their values without returning them, redacted creates them with their keys and empty values to be set by the user.
+		dryRun (boolean, optional): If true, lists the resources that would be created without creating them.
+
+		Returns the cloned resources with the names of the resources they are a copy of, the skipped resources with the reason, and the resources that couldn't be created with their error. At most 200 resources are cloned in a call.`},
+		response.WithStructuredErrors(t.cloneNamespace))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "deleteKubernetesResource",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Deletes a resource in a kubernetes cluster. Protected system namespaces such as kube-system can't be deleted.'
+		Parameters:
+		kind (string): The type of Kubernetes resource to delete (e.g., Pod, Deployment, Service).
+		namespace (string, optional): The namespace where the resource is located. It must be empty for cluster-wide resources.
+		name (string): The name of the specific resource to delete.
+		cluster (string): The name of the Kubernetes cluster.
+		force (boolean, optional): Must be true to delete a CustomResourceDefinition. Defaults to false.
+
+		Returns the last state of the deleted resource.`},
+		response.WithStructuredErrors(t.deleteKubernetesResource))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "undoLastChange",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Undoes the last create, update or delete made by the tools for the user in a cluster, restoring the previous state of the resource from the change history. Secrets are never recorded, and the changes of resources with sensitive fields can only be undone for a create. Ask the user for confirmation before calling it.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		kind (string, optional): Only undo the last change of this type of Kubernetes resource (e.g., Deployment, ConfigMap).
+		namespace (string, optional): Only undo the last change in this namespace.
+		name (string, optional): Only undo the last change of the resources with this name.
+		force (boolean, optional): If true, undoes the change even if the resource was modified since, discarding the later modifications. Defaults to false.
+
+		Returns the restored resource, or the deleted one when undoing a create, and the undone change.`},
+		response.WithStructuredErrors(t.undoLastChange))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listCustomResourceDefinitions",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the CustomResourceDefinitions (CRDs) installed in a cluster with their group, kind, plural name, scope and served versions. Use it to find the resources provided by operators and other extensions.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		group (string, optional): Only return the CRDs of this API group or its subgroups (e.g. 'cert-manager.io', 'cattle.io').`},
+		response.WithStructuredErrors(t.listCustomResourceDefinitions))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "describeCustomResourceDefinition",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Describes a CustomResourceDefinition: its versions, the columns printed by kubectl for each version and the fields of the validation schema of one version, with their type, whether they are required, allowed values and description.
+		Use it before creating or patching a custom resource you don't know.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the CRD (e.g. 'certificates.cert-manager.io').
+		version (string, optional): The version whose schema is returned. Defaults to the storage version.`},
+		response.WithStructuredErrors(t.describeCustomResourceDefinition))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listCustomResources",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the custom resources of a CustomResourceDefinition.'
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		name (string): The name of the CRD (e.g. 'certificates.cert-manager.io').
+		namespace (string, optional): The namespace of the resources. Empty for all namespaces or cluster scoped CRDs.
+		labelSelector (string, optional): Only return resources matching the label selector (e.g. app=nginx).`},
+		response.WithStructuredErrors(t.listCustomResources))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "getClusterImages",
 		Meta: map[string]any{
 			toolsSetAnn: toolsSet,
 		},
-		Description: `Returns a list of all container images for the specified clusters.'
+		Description: `Returns a list of all container images for the specified clusters, including the image registry, repository, tag and digest, the workload (Deployment, DaemonSet, StatefulSet...) and namespace using it and its pull policy.'
+		Parameters:
+		clusters (array of strings): List of clusters to get images from. Empty for return images for all clusters.
+		namespace (string, optional): Only return images used in this namespace.
+		registry (string, optional): Only return images from this registry (e.g. 'docker.io', 'registry.rancher.com').
+		tagPattern (string, optional): Regular expression. Only return images whose tag matches it (e.g. '^v2\.12').
+		distinct (boolean, optional): Return each image once with the clusters using it and its number of workloads and pods, instead of the images of each cluster. Use it to get an inventory of many clusters.`},
+		response.WithStructuredErrors(t.getClusterImages))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getImageVulnerabilities",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the known vulnerabilities (CVEs) of the container images used in the specified clusters, grouped by severity, together with the workload and namespace using each image.
+		The vulnerabilities are taken from the VulnerabilityReports generated by the Trivy Operator, which must be installed in the clusters. Images without a report are returned with scanned set to false.
+		Parameters:
+		clusters (array of strings): List of clusters to check. Empty for checking all clusters.
+		registry (string, optional): Only check images from this registry (e.g. 'docker.io', 'registry.rancher.com').
+		tagPattern (string, optional): Regular expression. Only check images whose tag matches it (e.g. '^v2\.12').
+		minSeverity (string, optional): Only list vulnerabilities with this severity or higher. One of CRITICAL, HIGH, MEDIUM, LOW, UNKNOWN. Defaults to HIGH.`},
+		response.WithStructuredErrors(t.getImageVulnerabilities))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "checkImageCompliance",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Checks the container images used in the specified clusters against the allowlist of image registries and repositories configured in the server, and returns the violations per workload with their severity. Use it to answer compliance questions like "do we run images from unapproved registries?".
+		The severities are HIGH for the images of a registry that isn't allowed, MEDIUM for the images of an allowed registry from a repository that isn't allowed, and LOW for the allowed images referenced by the latest tag without a digest.
+		The response includes the allowlist, the number of compliant workload containers of each cluster and the number of violations of each severity.
 		Parameters:
-		clusters (array of strings): List of clusters to get images from. Empty for return images for all clusters.`},
-		t.getClusterImages)
+		clusters (array of strings): List of clusters to check. Empty for checking all clusters.
+		namespace (string, optional): Only check images used in this namespace.
+		minSeverity (string, optional): Only list violations with this severity or higher. One of HIGH, MEDIUM, LOW. Defaults to LOW.`},
+		response.WithStructuredErrors(t.checkImageCompliance))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "lintWorkloads",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lints the pod templates of the workloads of a cluster for common misconfigurations, and returns the workloads with findings sorted by severity (high, medium, low).
+		The checks are: privileged containers, containers without CPU or memory requests, images with the latest tag, Deployments, StatefulSets and DaemonSets whose containers have no liveness or readiness probe or identical ones, and Deployments and StatefulSets with several replicas without a PodDisruptionBudget.
+		Each finding has a suggested fix: a JSON patch of the workload for patchKubernetesResource, or a PodDisruptionBudget to create with createKubernetesResource. The suggested values (requests, probes) are starting points to adjust to the workload before applying them.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): The namespace of the workloads. Empty for all namespaces.
+		kinds (array of strings, optional): The kinds of the workloads linted. Defaults to deployment, statefulset and daemonset.`},
+		response.WithStructuredErrors(t.lintWorkloads))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "scanDeprecatedAPIs",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Reports the Kubernetes API versions deprecated or removed in a target Kubernetes version used by a manifest, or by the resources of a cluster. Use it before upgrading a cluster or deploying a manifest to a newer cluster.
+		The deprecations are taken from a database shipped with the server, so the target cluster doesn't need to run the target version. For cluster resources, the API versions used by the clients managing them are taken from their managed fields and the kubectl last-applied-configuration annotation.
+		Each finding has the status removed (the API isn't served by the target version) or deprecated, and the API version to migrate to.
+		Parameters:
+		targetVersion (string): The Kubernetes version to check against (e.g. 'v1.32.3').
+		manifest (string, optional): The YAML or JSON manifest to scan, with one or more documents. Either manifest or cluster is required.
+		cluster (string, optional): The cluster whose resources are scanned.
+		namespace (string, optional): The namespace of the resources scanned. Empty for all namespaces.
+		kinds (array of strings, optional): The kinds of the resources scanned. Defaults to deployment, statefulset, daemonset, ingress, cronjob, horizontalpodautoscaler and poddisruptionbudget.`},
+		response.WithStructuredErrors(t.scanDeprecatedAPIs))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "preflightCheck",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Runs the checks of a manifest before it's applied to a cluster, and returns a report with the status pass, warn or fail of each check and the worst status overall. Nothing is created in the cluster.
+		The checks are: schema (each resource is validated by the API server with a server-side dry run and strict field validation), references (the ConfigMaps, Secrets, ServiceAccounts and PersistentVolumeClaims referenced by the workloads exist in the cluster or in the manifest), image (the images of the workloads exist in their registries, the registries requiring credentials are reported as warnings) and capacity (the requests of the replicas of the workloads fit in the schedulable nodes).
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		manifest (string): The YAML or JSON manifest to check, with one or more documents.
+		namespace (string, optional): The namespace of the resources of the manifest without one. Defaults to default.`},
+		response.WithStructuredErrors(t.preflightCheck))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "exportResources",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Exports the resources of a namespace as a multi-document YAML manifest that can be applied in another namespace or cluster, e.g. to migrate or back up a namespace.
+		The resources are sanitized: their namespace, status, managed fields, owner references and the fields set by the source cluster (uid, resource version, cluster IPs, node ports, bound volumes...) are removed. The resources managed by a controller (e.g. the ReplicaSets of a Deployment) are skipped, since the controller recreates them. The values of the Secrets are redacted.
+		The manifest is returned as an embedded resource, after a summary of the exported and skipped resources.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string): The namespace of the resources exported.
+		kinds (array of strings, optional): The kinds of the resources exported. Defaults to configmap, secret, serviceaccount, persistentvolumeclaim, service, deployment, statefulset, daemonset, cronjob and ingress.
+		labelSelector (string, optional): Only export the resources matching this label selector.`},
+		response.WithStructuredErrors(t.exportResources))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createConfigSnapshot",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Takes a snapshot of the configuration of the resources of a cluster or a namespace, to find what drifted since with compareConfigSnapshots (e.g. "what changed since last week?").
+		The snapshot has the hash of the manifest of each resource, without its status and the fields set by the cluster. It's stored in a ConfigMap of the cattle-ai-agent-system namespace of the cluster. The resources managed by a controller (e.g. the ReplicaSets of a Deployment) are left out.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): The namespace of the resources. Empty for all namespaces.
+		kinds (array of strings, optional): The kinds of the resources in the snapshot. Defaults to configmap, secret, serviceaccount, persistentvolumeclaim, service, deployment, statefulset, daemonset, cronjob and ingress.
+		labelSelector (string, optional): Only include the resources matching this label selector.
+		name (string, optional): The name of the snapshot. Defaults to the time it's taken, e.g. 20250102-150405.`},
+		response.WithStructuredErrors(t.createConfigSnapshot))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listConfigSnapshots",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the configuration snapshots taken with createConfigSnapshot in a cluster, newest first, with their namespace, kinds, creation time and number of resources.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.`},
+		response.WithStructuredErrors(t.listConfigSnapshots))
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "compareConfigSnapshots",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Compares a configuration snapshot taken with createConfigSnapshot to another snapshot, or to the current resources of the same or another cluster, and returns the resources added, removed and changed since the snapshot.
+		The current resources are taken with the kinds and label selector of the snapshot. When both sides are a single namespace, the resources are matched by kind and name, so two namespaces (e.g. staging and production) can be compared. Use getKubernetesResource or getWorkloadHistory to find what changed in a changed resource.
+		Parameters:
+		cluster (string): The cluster of the snapshot.
+		snapshot (string): The name of the snapshot.
+		targetSnapshot (string, optional): The name of the snapshot compared to. Empty to compare to the current resources.
+		targetCluster (string, optional): The cluster of the target snapshot or of the current resources. Defaults to cluster.
+		targetNamespace (string, optional): The namespace of the current resources. Defaults to the namespace of the snapshot.`},
+		response.WithStructuredErrors(t.compareConfigSnapshots))
+
+	if t.ReadOnly {
+		mcpServer.RemoveTools("patchKubernetesResource", "createKubernetesResource", "applyKubernetesResource", "bulkLabelResources", "cloneNamespace",
+			"deleteKubernetesResource", "restartWorkload", "scaleWorkload", "updateAutoscalerReplicas", "pauseRollout", "resumeRollout", "rollbackDeployment",
+			"createConfigSnapshot", "execInPod", "runDebugContainer", "triggerCronJob", "suspendCronJob", "resumeCronJob", "createSilence", "undoLastChange")
+	}
 }
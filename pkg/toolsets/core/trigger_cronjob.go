@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// instantiateAnn is the annotation set by 'kubectl create job --from=cronjob' on the Jobs created by hand.
+	instantiateAnn = "cronjob.kubernetes.io/instantiate"
+	// maxCronJobPrefix is the maximum length of the CronJob name in the name of a manual Job, so the 18 characters of
+	// the -manual- suffix and the timestamp fit in the 63 characters of the job-name label.
+	maxCronJobPrefix = 45
+)
+
+// cronJobParams identifies a CronJob within a cluster.
+type cronJobParams struct {
+	Name      string `json:"name" jsonschema:"the name of the CronJob"`
+	Namespace string `json:"namespace" jsonschema:"the namespace of the CronJob"`
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the CronJob"`
+}
+
+// triggerCronJob runs a CronJob now, like 'kubectl create job --from=cronjob', by creating a Job from its template.
+// The Job is owned by the CronJob, so it's removed with its history.
+func (t *Tools) triggerCronJob(ctx context.Context, toolReq *mcp.CallToolRequest, params cronJobParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("triggerCronJob called")
+
+	token := middleware.Token(ctx)
+	url := toolReq.Extra.Header.Get(urlHeader)
+	cronJobInterface, err := t.client.GetResourceInterface(ctx, token, url, params.Namespace, params.Cluster, converter.K8sKindsToGVRs["cronjob"])
+	if err != nil {
+		return nil, nil, err
+	}
+	unstructuredCronJob, err := cronJobInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get CronJob", zap.String("tool", "triggerCronJob"), zap.Error(err))
+		return nil, nil, err
+	}
+	var cronJob batchv1.CronJob
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredCronJob.Object, &cronJob); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert unstructured object to CronJob: %w", err)
+	}
+
+	jobObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newManualJob(&cronJob))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert Job: %w", err)
+	}
+	jobInterface, err := t.client.GetResourceInterface(ctx, token, url, params.Namespace, params.Cluster, converter.K8sKindsToGVRs["job"])
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := jobInterface.Create(ctx, &unstructured.Unstructured{Object: jobObj}, metav1.CreateOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create Job", zap.String("tool", "triggerCronJob"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create a Job from CronJob %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{job}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "triggerCronJob"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// newManualJob returns a Job created from the template of the CronJob, the same way as 'kubectl create job --from'.
+func newManualJob(cronJob *batchv1.CronJob) *batchv1.Job {
+	prefix := cronJob.Name
+	if len(prefix) > maxCronJobPrefix {
+		prefix = prefix[:maxCronJobPrefix]
+	}
+	annotations := map[string]string{instantiateAnn: "manual"}
+	maps.Copy(annotations, cronJob.Spec.JobTemplate.Annotations)
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-manual-%d", prefix, now().Unix()),
+			Namespace:   cronJob.Namespace,
+			Labels:      cronJob.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1",
+				Kind:       "CronJob",
+				Name:       cronJob.Name,
+				UID:        cronJob.UID,
+				Controller: ptr.To(true),
+			}},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTriggerCronJob(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	longName := strings.Repeat("a", 60)
+
+	tests := map[string]struct {
+		params        cronJobParams
+		expectedName  string
+		expectedError string
+	}{
+		"trigger cronjob": {
+			params:       cronJobParams{Name: "backup", Namespace: "default", Cluster: "local"},
+			expectedName: "backup-manual-1772368200",
+		},
+		"long cronjob name": {
+			params:       cronJobParams{Name: longName, Namespace: "default", Cluster: "local"},
+			expectedName: longName[:maxCronJobPrefix] + "-manual-1772368200",
+		},
+		"cronjob not found": {
+			params:        cronJobParams{Name: "missing", Namespace: "default", Cluster: "local"},
+			expectedError: `cronjobs.batch "missing" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, fakeDynClient := newBatchTools(newCronJob("backup", false), newCronJob(longName, false))
+
+			result, _, err := tools.triggerCronJob(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			items := llmItems(t, result)
+			require.Len(t, items, 1)
+			assert.Equal(t, test.expectedName, items[0]["metadata"].(map[string]any)["name"])
+
+			obj, err := fakeDynClient.Resource(schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}).
+				Namespace("default").Get(t.Context(), test.expectedName, metav1.GetOptions{})
+			require.NoError(t, err)
+			var job batchv1.Job
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &job))
+			assert.Equal(t, map[string]string{"app": test.params.Name}, job.Labels)
+			assert.Equal(t, map[string]string{"team": "data", instantiateAnn: "manual"}, job.Annotations)
+			assert.Equal(t, test.params.Name, jobCronJob(job))
+			assert.Equal(t, "backup", job.Spec.Template.Spec.Containers[0].Name)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type undoLastChangeParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the change"`
+	Kind      string `json:"kind,omitempty" jsonschema:"only undo the last change of this kind of resource"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only undo the last change in this namespace"`
+	Name      string `json:"name,omitempty" jsonschema:"only undo the last change of the resources with this name"`
+	Force     bool   `json:"force,omitempty" jsonschema:"undo the change even if the resource changed since"`
+}
+
+// undoLastChange restores the state a resource had before the last change recorded in the change history of the
+// cluster, optionally only considering the changes of some resources.
+func (t *Tools) undoLastChange(ctx context.Context, toolReq *mcp.CallToolRequest, params undoLastChangeParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("undoLastChange called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	changes, err := t.client.Changes(ctx, middleware.Token(ctx), url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get changes", zap.String("tool", "undoLastChange"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var filter func(client.Change) bool
+	if params.Kind != "" {
+		gvr, err := t.client.ResolveGVR(ctx, middleware.Token(ctx), url, params.Cluster, params.Kind)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter = func(change client.Change) bool {
+			return change.Group == gvr.Group && change.Resource == gvr.Resource
+		}
+	}
+	var last *client.Change
+	for _, change := range changes {
+		if (filter == nil || filter(change)) &&
+			(params.Namespace == "" || change.Namespace == params.Namespace) &&
+			(params.Name == "" || change.Name == params.Name) {
+			last = &change
+			break
+		}
+	}
+	if last == nil {
+		return nil, nil, fmt.Errorf("no change matching the filters recorded in cluster %s", params.Cluster)
+	}
+
+	obj, err := t.client.UndoChange(ctx, client.UndoParams{
+		Cluster: params.Cluster,
+		URL:     url,
+		Token:   middleware.Token(ctx),
+		ID:      last.ID,
+		Force:   params.Force,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to undo change", zap.String("tool", "undoLastChange"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to undo change %s: %w", last.ID, err)
+	}
+
+	undo := &unstructured.Unstructured{Object: map[string]any{
+		"undo": map[string]any{
+			"id":        last.ID,
+			"time":      last.Time,
+			"operation": last.Operation,
+			"resource":  last.Resource,
+			"namespace": last.Namespace,
+			"name":      last.Name,
+		},
+	}}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj, undo}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "undoLastChange"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestUndoLastChange(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: "1"},
+			Data:       map[string]string{"key": "value"},
+		}
+	}
+
+	tests := map[string]struct {
+		params        undoLastChangeParams
+		changes       []string
+		expectedName  string
+		expectedUndo  map[string]any
+		expectedError string
+	}{
+		"last change": {
+			params:       undoLastChangeParams{Cluster: "local"},
+			changes:      []string{"first", "second"},
+			expectedName: "second",
+			expectedUndo: map[string]any{"operation": "update", "resource": "configmaps", "namespace": "default", "name": "second"},
+		},
+		"last change of a resource": {
+			params:       undoLastChangeParams{Cluster: "local", Kind: "ConfigMap", Namespace: "default", Name: "first"},
+			changes:      []string{"first", "second"},
+			expectedName: "first",
+			expectedUndo: map[string]any{"operation": "update", "resource": "configmaps", "namespace": "default", "name": "first"},
+		},
+		"no change of the kind": {
+			params:        undoLastChangeParams{Cluster: "local", Kind: "Deployment"},
+			changes:       []string{"first"},
+			expectedError: "no change matching the filters recorded in cluster local",
+		},
+		"no change": {
+			params:        undoLastChangeParams{Cluster: "local"},
+			expectedError: "no change matching the filters recorded in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeDynClient := dynamicfake.NewSimpleDynamicClient(scheme, configMap("first"), configMap("second"))
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			c.EnableChangeHistory(client.DefaultChangeHistoryNamespace, client.DefaultChangeHistorySize)
+			tools := Tools{client: newFakeToolsClient(c, fakeToken)}
+			ctx := middleware.WithToken(t.Context(), fakeToken)
+
+			configMaps, err := tools.client.GetResourceInterface(ctx, fakeToken, fakeUrl, "default", "local", converter.K8sKindsToGVRs["configmap"])
+			require.NoError(t, err)
+			for _, name := range test.changes {
+				_, err := configMaps.Patch(ctx, name, types.MergePatchType, []byte(`{"data":{"key":"changed"}}`), metav1.PatchOptions{})
+				require.NoError(t, err)
+			}
+
+			result, _, err := tools.undoLastChange(ctx, &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			items := llmItems(t, result)
+			require.Len(t, items, 2)
+			assert.Equal(t, test.expectedName, items[0]["metadata"].(map[string]any)["name"])
+			assert.Equal(t, map[string]any{"key": "value"}, items[0]["data"])
+			undo := items[1]["undo"].(map[string]any)
+			assert.NotEmpty(t, undo["id"])
+			delete(undo, "id")
+			delete(undo, "time")
+			assert.Equal(t, test.expectedUndo, undo)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type updateAutoscalerReplicasParams struct {
+	Name        string `json:"name" jsonschema:"the name of the HorizontalPodAutoscaler"`
+	Namespace   string `json:"namespace" jsonschema:"the namespace of the HorizontalPodAutoscaler"`
+	Cluster     string `json:"cluster" jsonschema:"the cluster of the HorizontalPodAutoscaler"`
+	MinReplicas *int32 `json:"minReplicas,omitempty" jsonschema:"the new minimum number of replicas"`
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" jsonschema:"the new maximum number of replicas"`
+}
+
+// updateAutoscalerReplicas changes the minReplicas and maxReplicas of a HorizontalPodAutoscaler, keeping the current
+// value of the ones that aren't set.
+func (t *Tools) updateAutoscalerReplicas(ctx context.Context, toolReq *mcp.CallToolRequest, params updateAutoscalerReplicasParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("updateAutoscalerReplicas called")
+
+	if params.MinReplicas == nil && params.MaxReplicas == nil {
+		return nil, nil, fmt.Errorf("minReplicas or maxReplicas is required")
+	}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.Cluster, converter.K8sKindsToGVRs["horizontalpodautoscaler"])
+	if err != nil {
+		return nil, nil, err
+	}
+	hpa, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get HorizontalPodAutoscaler", zap.String("tool", "updateAutoscalerReplicas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	minReplicas, found, _ := unstructured.NestedInt64(hpa.Object, "spec", "minReplicas")
+	if !found {
+		minReplicas = 1
+	}
+	maxReplicas, _, _ := unstructured.NestedInt64(hpa.Object, "spec", "maxReplicas")
+	spec := map[string]any{}
+	if params.MinReplicas != nil {
+		minReplicas = int64(*params.MinReplicas)
+		spec["minReplicas"] = minReplicas
+	}
+	if params.MaxReplicas != nil {
+		maxReplicas = int64(*params.MaxReplicas)
+		spec["maxReplicas"] = maxReplicas
+	}
+	if minReplicas < 1 {
+		return nil, nil, fmt.Errorf("minReplicas must be at least 1, scale the workload to zero with scaleWorkload instead")
+	}
+	if maxReplicas < minReplicas {
+		return nil, nil, fmt.Errorf("maxReplicas (%d) must be greater than or equal to minReplicas (%d)", maxReplicas, minReplicas)
+	}
+
+	patch, err := json.Marshal(map[string]any{"spec": spec})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to patch HorizontalPodAutoscaler", zap.String("tool", "updateAutoscalerReplicas"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to update HorizontalPodAutoscaler %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "updateAutoscalerReplicas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func TestUpdateAutoscalerReplicas(t *testing.T) {
+	tests := map[string]struct {
+		params      updateAutoscalerReplicasParams
+		expectedMin int64
+		expectedMax int64
+		expectedErr string
+	}{
+		"raise maxReplicas": {
+			params:      updateAutoscalerReplicasParams{Name: "web", Namespace: "default", Cluster: "local", MaxReplicas: ptr.To(int32(10))},
+			expectedMin: 2,
+			expectedMax: 10,
+		},
+		"set both": {
+			params:      updateAutoscalerReplicasParams{Name: "web", Namespace: "default", Cluster: "local", MinReplicas: ptr.To(int32(3)), MaxReplicas: ptr.To(int32(8))},
+			expectedMin: 3,
+			expectedMax: 8,
+		},
+		"minReplicas above current maxReplicas": {
+			params:      updateAutoscalerReplicasParams{Name: "web", Namespace: "default", Cluster: "local", MinReplicas: ptr.To(int32(6))},
+			expectedErr: "maxReplicas (5) must be greater than or equal to minReplicas (6)",
+		},
+		"minReplicas zero": {
+			params:      updateAutoscalerReplicasParams{Name: "web", Namespace: "default", Cluster: "local", MinReplicas: ptr.To(int32(0))},
+			expectedErr: "minReplicas must be at least 1",
+		},
+		"nothing to update": {
+			params:      updateAutoscalerReplicasParams{Name: "web", Namespace: "default", Cluster: "local"},
+			expectedErr: "minReplicas or maxReplicas is required",
+		},
+		"hpa not found": {
+			params:      updateAutoscalerReplicasParams{Name: "api", Namespace: "default", Cluster: "local", MaxReplicas: ptr.To(int32(10))},
+			expectedErr: `horizontalpodautoscalers.autoscaling "api" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools, fakeDynClient := newAutoscalerTools(t, false, newHPA(t, "web", 2, nil))
+
+			result, _, err := tools.updateAutoscalerReplicas(middleware.WithToken(t.Context(), "fakeToken"), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+			}, test.params)
+
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, `"maxReplicas":`)
+			hpa, err := fakeDynClient.Resource(schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}).
+				Namespace("default").Get(t.Context(), "web", metav1.GetOptions{})
+			require.NoError(t, err)
+			minReplicas, _, _ := unstructured.NestedInt64(hpa.Object, "spec", "minReplicas")
+			maxReplicas, _, _ := unstructured.NestedInt64(hpa.Object, "spec", "maxReplicas")
+			assert.Equal(t, test.expectedMin, minReplicas)
+			assert.Equal(t, test.expectedMax, maxReplicas)
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxResourceWaitSeconds is the longest wait for a modified resource to be ready. TimeoutMiddleware adds the wait to
+// the time limit of the call.
+const maxResourceWaitSeconds = middleware.MaxWaitSeconds
+
+// resourceWait is the outcome of waiting for a resource to be ready after a change.
+type resourceWait struct {
+	// Ready is true when the resource met the readiness condition of its kind before the timeout.
+	Ready bool `json:"ready"`
+	// Condition is the readiness condition waited for: Available for Deployments and ReplicaSets, Running for
+	// Machines, and Ready for the other kinds.
+	Condition string `json:"condition"`
+	Message   string `json:"message"`
+}
+
+// validateWaitSeconds checks the waitSeconds parameter of the tools modifying a resource.
+func validateWaitSeconds(waitSeconds int64) error {
+	if waitSeconds < 0 || waitSeconds > maxResourceWaitSeconds {
+		return fmt.Errorf("invalid waitSeconds %d, must be between 0 and %d", waitSeconds, maxResourceWaitSeconds)
+	}
+
+	return nil
+}
+
+// waitForResourceObjects waits for a resource to be ready like waitForResource, and returns its last state and the
+// outcome of the wait as items of the responses of the tools.
+func waitForResourceObjects(ctx context.Context, resourceInterface dynamic.ResourceInterface, obj *unstructured.Unstructured, waitSeconds int64) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	obj, wait, err := waitForResource(ctx, resourceInterface, obj, waitSeconds)
+	if err != nil {
+		return nil, nil, err
+	}
+	waitObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&wait)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert the wait: %w", err)
+	}
+
+	return obj, &unstructured.Unstructured{Object: map[string]any{"wait": waitObj}}, nil
+}
+
+// waitForResource watches a resource until it meets the readiness condition of its kind, can't meet it anymore without
+// another change, or waitSeconds expire, and returns its last state. Watches closed by the API server are restarted
+// after reading the resource again, in case changes were missed.
+func waitForResource(ctx context.Context, resourceInterface dynamic.ResourceInterface, obj *unstructured.Unstructured, waitSeconds int64) (*unstructured.Unstructured, resourceWait, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(waitSeconds)*time.Second)
+	defer cancel()
+
+	kind, name := obj.GetKind(), obj.GetName()
+	state, done := resourceReadiness(obj)
+	for !done && ctx.Err() == nil {
+		watcher, err := resourceInterface.Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: obj.GetResourceVersion(),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return nil, resourceWait{}, fmt.Errorf("failed to watch %s %s: %w", kind, name, err)
+		}
+		obj, err = watchUntilReady(ctx, watcher, obj)
+		watcher.Stop()
+		if err != nil {
+			return nil, resourceWait{}, err
+		}
+		if state, done = resourceReadiness(obj); done || ctx.Err() != nil {
+			break
+		}
+		if obj, err = resourceInterface.Get(ctx, name, metav1.GetOptions{}); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return nil, resourceWait{}, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+		}
+		state, done = resourceReadiness(obj)
+	}
+	if !done {
+		state.Message = fmt.Sprintf("%s %s isn't %s after %d seconds: %s", kind, name, state.Condition, waitSeconds, state.Message)
+	}
+
+	return obj, state, nil
+}
+
+// watchUntilReady returns the last state of the resource received by the watcher, once it's ready, the watch is
+// closed or the context is done.
+func watchUntilReady(ctx context.Context, watcher watch.Interface, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return obj, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok || event.Type == watch.Error {
+				return obj, nil
+			}
+			changed, isUnstructured := event.Object.(*unstructured.Unstructured)
+			if !isUnstructured || changed.GetName() != obj.GetName() {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				return nil, fmt.Errorf("%s %s was deleted while waiting for it to be ready", obj.GetKind(), obj.GetName())
+			}
+			obj = changed
+			if _, done := resourceReadiness(obj); done {
+				return obj, nil
+			}
+		}
+	}
+}
+
+// resourceReadiness returns whether the resource meets the readiness condition of its kind, and whether the wait is
+// over, because the resource is ready or can't become ready without another change, e.g. a failed rollout.
+//
+// Deployments, StatefulSets and DaemonSets are ready when their rollout is complete, ReplicaSets when all their
+// replicas are available, Pods when they're Ready or Succeeded, Machines when they're Running, and Clusters when their
+// Ready condition is True. The other kinds are ready when their Ready or Available condition is True, or once
+// they're created if they have neither.
+func resourceReadiness(obj *unstructured.Unstructured) (resourceWait, bool) {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		condition := "Ready"
+		if obj.GetKind() == "Deployment" {
+			condition = "Available"
+		}
+		status, err := newRolloutStatus(obj)
+		if err != nil {
+			return resourceWait{Condition: condition, Message: err.Error()}, true
+		}
+		done := status.Status != rolloutProgressing
+		return resourceWait{Ready: status.Status == rolloutComplete, Condition: condition, Message: status.Message}, done
+	case "ReplicaSet":
+		return replicaSetReadiness(obj)
+	case "Pod":
+		return podReadiness(obj)
+	case "Machine":
+		return machineReadiness(obj)
+	case "Cluster":
+		return conditionReadiness(obj, "Ready")
+	default:
+		if _, found := findCondition(obj, "Ready"); found {
+			return conditionReadiness(obj, "Ready")
+		}
+		if _, found := findCondition(obj, "Available"); found {
+			return conditionReadiness(obj, "Available")
+		}
+		return resourceWait{Ready: true, Condition: "Ready", Message: fmt.Sprintf("%s has no readiness condition, it's ready once created", obj.GetKind())}, true
+	}
+}
+
+// replicaSetReadiness returns whether all the replicas of a ReplicaSet are available. The wait is only over when
+// they're available, the ReplicaSet has no rollout that can fail.
+func replicaSetReadiness(obj *unstructured.Unstructured) (resourceWait, bool) {
+	desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		desired = 1
+	}
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	wait := resourceWait{Condition: "Available"}
+	switch {
+	case observed < obj.GetGeneration():
+		wait.Message = "waiting for the replicaset spec update to be observed"
+	case replicas != desired:
+		wait.Message = fmt.Sprintf("%d of %d replicas are created", replicas, desired)
+	case available < desired:
+		wait.Message = fmt.Sprintf("%d of %d replicas are available", available, desired)
+	default:
+		wait.Ready, wait.Message = true, fmt.Sprintf("the %d replicas are available", desired)
+	}
+
+	return wait, wait.Ready
+}
+
+// podReadiness returns whether a Pod is Ready, or Succeeded for the Pods running to completion.
+func podReadiness(obj *unstructured.Unstructured) (resourceWait, bool) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	wait := resourceWait{Condition: "Ready", Message: fmt.Sprintf("the pod is %s", phase)}
+	switch corev1.PodPhase(phase) {
+	case corev1.PodSucceeded:
+		wait.Ready = true
+		return wait, true
+	case corev1.PodFailed:
+		if reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason"); reason != "" {
+			wait.Message += ": " + reason
+		}
+		return wait, true
+	case corev1.PodRunning:
+		return conditionReadiness(obj, "Ready")
+	default:
+		return wait, false
+	}
+}
+
+// machineReadiness returns whether a Cluster API Machine is Running, its node having joined the cluster.
+func machineReadiness(obj *unstructured.Unstructured) (resourceWait, bool) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	wait := resourceWait{Condition: "Running", Message: fmt.Sprintf("the machine is %s", phase)}
+	if phase == "" {
+		wait.Message = "the machine has no phase yet"
+	}
+	switch phase {
+	case "Running":
+		wait.Ready = true
+		return wait, true
+	case "Failed":
+		if message, _, _ := unstructured.NestedString(obj.Object, "status", "failureMessage"); message != "" {
+			wait.Message += ": " + message
+		}
+		return wait, true
+	default:
+		return wait, false
+	}
+}
+
+// conditionReadiness returns whether the condition of a resource is True. The wait is only over when it's True, the
+// controllers setting it to False while they reconcile the resource.
+func conditionReadiness(obj *unstructured.Unstructured, conditionType string) (resourceWait, bool) {
+	wait := resourceWait{Condition: conditionType}
+	condition, found := findCondition(obj, conditionType)
+	if !found {
+		wait.Message = fmt.Sprintf("the %s condition isn't set yet", conditionType)
+		return wait, false
+	}
+	status, _, _ := unstructured.NestedString(condition, "status")
+	reason, _, _ := unstructured.NestedString(condition, "reason")
+	message, _, _ := unstructured.NestedString(condition, "message")
+	wait.Ready = status == string(metav1.ConditionTrue)
+	wait.Message = fmt.Sprintf("the %s condition is %s", conditionType, status)
+	if reason != "" {
+		wait.Message += ", " + reason
+	}
+	if message != "" {
+		wait.Message += ": " + message
+	}
+
+	return wait, wait.Ready
+}
+
+// findCondition returns the condition of the given type in the status of a resource.
+func findCondition(obj *unstructured.Unstructured, conditionType string) (map[string]any, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]any); ok && condition["type"] == conditionType {
+			return condition, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var machineGVR = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}
+
+func newWaitMachine(phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "cluster.x-k8s.io/v1beta1",
+		"kind":       "Machine",
+		"metadata":   map[string]any{"name": "pool1-abc", "namespace": "fleet-default", "resourceVersion": "1"},
+		"status":     map[string]any{"phase": phase},
+	}}
+}
+
+func newConditionObject(kind string, status map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.io/v1",
+		"kind":       kind,
+		"metadata":   map[string]any{"name": "test", "generation": int64(2)},
+	}}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+func TestResourceReadiness(t *testing.T) {
+	tests := map[string]struct {
+		obj          *unstructured.Unstructured
+		expectedWait resourceWait
+		expectedDone bool
+	}{
+		"deployment rolled out": {
+			obj:          newConditionObject("Deployment", map[string]any{"observedGeneration": int64(2), "replicas": int64(1), "updatedReplicas": int64(1), "readyReplicas": int64(1), "availableReplicas": int64(1)}),
+			expectedWait: resourceWait{Ready: true, Condition: "Available", Message: "the deployment was successfully rolled out"},
+			expectedDone: true,
+		},
+		"deployment not observed": {
+			obj:          newConditionObject("Deployment", map[string]any{"observedGeneration": int64(1)}),
+			expectedWait: resourceWait{Condition: "Available", Message: "waiting for the deployment spec update to be observed"},
+		},
+		"deployment exceeding its progress deadline": {
+			obj: newConditionObject("Deployment", map[string]any{"observedGeneration": int64(2), "conditions": []any{
+				map[string]any{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+			}}),
+			expectedWait: resourceWait{Condition: "Available", Message: "the rollout exceeded its progress deadline with 0 out of 1 new replicas updated"},
+			expectedDone: true,
+		},
+		"replicaset scaling up": {
+			obj:          newConditionObject("ReplicaSet", map[string]any{"observedGeneration": int64(2), "replicas": int64(1), "availableReplicas": int64(0)}),
+			expectedWait: resourceWait{Condition: "Available", Message: "0 of 1 replicas are available"},
+		},
+		"running pod not ready": {
+			obj: newConditionObject("Pod", map[string]any{"phase": "Running", "conditions": []any{
+				map[string]any{"type": "Ready", "status": "False", "reason": "ContainersNotReady", "message": "containers with unready status: [app]"},
+			}}),
+			expectedWait: resourceWait{Condition: "Ready", Message: "the Ready condition is False, ContainersNotReady: containers with unready status: [app]"},
+		},
+		"failed pod": {
+			obj:          newConditionObject("Pod", map[string]any{"phase": "Failed", "reason": "Evicted"}),
+			expectedWait: resourceWait{Condition: "Ready", Message: "the pod is Failed: Evicted"},
+			expectedDone: true,
+		},
+		"running machine": {
+			obj:          newWaitMachine("Running"),
+			expectedWait: resourceWait{Ready: true, Condition: "Running", Message: "the machine is Running"},
+			expectedDone: true,
+		},
+		"new machine": {
+			obj:          newWaitMachine(""),
+			expectedWait: resourceWait{Condition: "Running", Message: "the machine has no phase yet"},
+		},
+		"new cluster": {
+			obj:          newConditionObject("Cluster", nil),
+			expectedWait: resourceWait{Condition: "Ready", Message: "the Ready condition isn't set yet"},
+		},
+		"ready cluster": {
+			obj:          newConditionObject("Cluster", map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": "True"}}}),
+			expectedWait: resourceWait{Ready: true, Condition: "Ready", Message: "the Ready condition is True"},
+			expectedDone: true,
+		},
+		"available condition": {
+			obj:          newConditionObject("APIService", map[string]any{"conditions": []any{map[string]any{"type": "Available", "status": "False", "reason": "MissingEndpoints"}}}),
+			expectedWait: resourceWait{Condition: "Available", Message: "the Available condition is False, MissingEndpoints"},
+		},
+		"no readiness condition": {
+			obj:          newConditionObject("ConfigMap", nil),
+			expectedWait: resourceWait{Ready: true, Condition: "Ready", Message: "ConfigMap has no readiness condition, it's ready once created"},
+			expectedDone: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wait, done := resourceReadiness(test.obj)
+
+			assert.Equal(t, test.expectedWait, wait)
+			assert.Equal(t, test.expectedDone, done)
+		})
+	}
+}
+
+func TestWaitForResource(t *testing.T) {
+	tests := map[string]struct {
+		stored        *unstructured.Unstructured
+		events        []watch.Event
+		closed        bool
+		expectedPhase string
+		expectedWait  resourceWait
+		expectedError string
+	}{
+		"running after the watched changes": {
+			stored: newWaitMachine("Provisioning"),
+			events: []watch.Event{
+				{Type: watch.Modified, Object: newWaitMachine("Provisioned")},
+				{Type: watch.Modified, Object: newWaitMachine("Running")},
+			},
+			expectedPhase: "Running",
+			expectedWait:  resourceWait{Ready: true, Condition: "Running", Message: "the machine is Running"},
+		},
+		"watch closed by the server": {
+			stored:        newWaitMachine("Running"),
+			closed:        true,
+			expectedPhase: "Running",
+			expectedWait:  resourceWait{Ready: true, Condition: "Running", Message: "the machine is Running"},
+		},
+		"timeout": {
+			stored:        newWaitMachine("Provisioning"),
+			events:        []watch.Event{{Type: watch.Modified, Object: newWaitMachine("Provisioned")}},
+			expectedPhase: "Provisioned",
+			expectedWait:  resourceWait{Condition: "Running", Message: "Machine pool1-abc isn't Running after 1 seconds: the machine is Provisioned"},
+		},
+		"failed machine": {
+			stored: newWaitMachine("Provisioning"),
+			events: []watch.Event{{Type: watch.Modified, Object: func() runtime.Object {
+				machine := newWaitMachine("Failed")
+				_ = unstructured.SetNestedField(machine.Object, "the instance can't be created", "status", "failureMessage")
+				return machine
+			}()}},
+			expectedPhase: "Failed",
+			expectedWait:  resourceWait{Condition: "Running", Message: "the machine is Failed: the instance can't be created"},
+		},
+		"deleted": {
+			stored:        newWaitMachine("Provisioning"),
+			events:        []watch.Event{{Type: watch.Deleted, Object: newWaitMachine("Deleting")}},
+			expectedError: "Machine pool1-abc was deleted while waiting for it to be ready",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				machineGVR: "MachineList",
+			}, test.stored)
+			fakeDynClient.PrependWatchReactor("machines", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				watcher := watch.NewFakeWithChanSize(len(test.events), false)
+				for _, event := range test.events {
+					watcher.Action(event.Type, event.Object)
+				}
+				if test.closed {
+					watcher.Stop()
+				}
+				return true, watcher, nil
+			})
+
+			obj, wait, err := waitForResource(t.Context(), fakeDynClient.Resource(machineGVR).Namespace("fleet-default"), newWaitMachine("Provisioning"), 1)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+			assert.Equal(t, test.expectedPhase, phase)
+			assert.Equal(t, test.expectedWait, wait)
+		})
+	}
+}
+
+func TestValidateWaitSeconds(t *testing.T) {
+	assert.NoError(t, validateWaitSeconds(0))
+	assert.NoError(t, validateWaitSeconds(300))
+	assert.EqualError(t, validateWaitSeconds(301), "invalid waitSeconds 301, must be between 0 and 300")
+	assert.EqualError(t, validateWaitSeconds(-1), "invalid waitSeconds -1, must be between 0 and 300")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"go.uber.org/zap"
@@ -18,23 +19,23 @@ type listGitRepoParams struct {
 // The function queries the 'local' cluster where Fleet runs and returns all GitRepos
 // in the specified workspace namespace, along with UI context for building resource links.
 func (t *Tools) listGitRepos(ctx context.Context, toolReq *mcp.CallToolRequest, params listGitRepoParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("listGitRepos called")
+	middleware.Logger(ctx).Debug("listGitRepos called")
 
 	gitRepos, err := t.client.GetResources(ctx, client.ListParams{
 		Cluster:   "local",
 		Kind:      "gitrepo",
 		Namespace: params.Workspace,
 		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     toolReq.Extra.Header.Get(tokenHeader),
+		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
-		zap.L().Error("failed to list gitrepos", zap.String("tool", "listGitRepos"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to list gitrepos", zap.String("tool", "listGitRepos"), zap.Error(err))
 		return nil, nil, err
 	}
 
-	mcpResponse, err := response.CreateMcpResponse(gitRepos, "local")
+	mcpResponse, err := response.CreateMcpResponse(ctx, gitRepos, "local")
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "listGitRepos"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listGitRepos"), zap.Error(err))
 		return nil, nil, err
 	}
 
@@ -0,0 +1,150 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type listGlobalDNSEntriesParams struct {
+	FQDN string `json:"fqdn,omitempty" jsonschema:"the FQDN of the GlobalDNS entry. Empty for all entries"`
+}
+
+// globalDNSEntries are the GlobalDNS entries of Rancher 2.5 and earlier.
+type globalDNSEntries struct {
+	Entries []globalDNSEntry `json:"entries"`
+	Notes   []string         `json:"notes,omitempty"`
+}
+
+// globalDNSEntry is a GlobalDNS entry with the endpoints published for each of its target clusters.
+type globalDNSEntry struct {
+	Name            string `json:"name"`
+	FQDN            string `json:"fqdn"`
+	Provider        string `json:"provider"`
+	MultiClusterApp string `json:"multiClusterApp,omitempty"`
+	// Clusters are the clusters of the target projects or of the targets of the MultiClusterApp.
+	Clusters         []string            `json:"clusters"`
+	Endpoints        []string            `json:"endpoints"`
+	ClusterEndpoints map[string][]string `json:"clusterEndpoints,omitempty"`
+	// ClustersWithoutEndpoints are the target clusters without an ingress endpoint in the DNS records.
+	ClustersWithoutEndpoints []string `json:"clustersWithoutEndpoints,omitempty"`
+}
+
+// listGlobalDNSEntries returns the GlobalDNS entries with the clusters they target and the endpoints published for
+// each cluster, to find the clusters missing from the DNS records of an FQDN.
+func (t *Tools) listGlobalDNSEntries(ctx context.Context, toolReq *mcp.CallToolRequest, params listGlobalDNSEntriesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listGlobalDNSEntries called")
+
+	listParams := client.ListParams{
+		Cluster:   "local",
+		Kind:      "globaldns",
+		Namespace: globalDataNamespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}
+	result := globalDNSEntries{Entries: []globalDNSEntry{}}
+	globalDNSes, err := t.client.GetResources(ctx, listParams)
+	if apierrors.IsNotFound(err) {
+		result.Notes = append(result.Notes, "GlobalDNS isn't available, it was removed in Rancher 2.6. Use an external DNS controller, e.g. external-dns, deployed with Fleet in each cluster instead")
+		return marshalGlobalDNSEntries(ctx, result)
+	}
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list global DNS entries", zap.String("tool", "listGlobalDNSEntries"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	// the entries of a MultiClusterApp target the clusters of the app
+	listParams.Kind = "multiclusterapp"
+	apps, err := t.client.GetResources(ctx, listParams)
+	if err != nil && !apierrors.IsNotFound(err) {
+		middleware.Logger(ctx).Error("failed to list multi-cluster apps", zap.String("tool", "listGlobalDNSEntries"), zap.Error(err))
+		return nil, nil, err
+	}
+	appProjects := map[string][]string{}
+	for _, app := range apps {
+		targets, _, _ := unstructured.NestedSlice(app.Object, "spec", "targets")
+		for _, t := range targets {
+			if target, ok := t.(map[string]any); ok {
+				project, _, _ := unstructured.NestedString(target, "projectName")
+				appProjects[app.GetName()] = append(appProjects[app.GetName()], project)
+			}
+		}
+	}
+
+	for _, globalDNS := range globalDNSes {
+		fqdn, _, _ := unstructured.NestedString(globalDNS.Object, "spec", "fqdn")
+		if params.FQDN != "" && fqdn != params.FQDN {
+			continue
+		}
+		result.Entries = append(result.Entries, newGlobalDNSEntry(globalDNS, appProjects))
+	}
+	if params.FQDN != "" && len(result.Entries) == 0 {
+		return nil, nil, fmt.Errorf("GlobalDNS entry %s not found", params.FQDN)
+	}
+	if len(globalDNSes) > 0 {
+		result.Notes = append(result.Notes, "GlobalDNS is deprecated since Rancher 2.5 and removed in 2.6")
+	}
+
+	return marshalGlobalDNSEntries(ctx, result)
+}
+
+// newGlobalDNSEntry returns a GlobalDNS entry with the clusters of its target projects, or of the target projects
+// of its MultiClusterApp, that don't have endpoints.
+func newGlobalDNSEntry(globalDNS *unstructured.Unstructured, appProjects map[string][]string) globalDNSEntry {
+	entry := globalDNSEntry{Name: globalDNS.GetName(), Clusters: []string{}, Endpoints: []string{}}
+	entry.FQDN, _, _ = unstructured.NestedString(globalDNS.Object, "spec", "fqdn")
+	entry.Provider, _, _ = unstructured.NestedString(globalDNS.Object, "spec", "providerName")
+	entry.MultiClusterApp, _, _ = unstructured.NestedString(globalDNS.Object, "spec", "multiClusterAppName")
+	projects, _, _ := unstructured.NestedStringSlice(globalDNS.Object, "spec", "projectNames")
+	if entry.MultiClusterApp != "" {
+		// the MultiClusterApp names are <namespace>:<name>
+		parts := strings.Split(entry.MultiClusterApp, ":")
+		projects = appProjects[parts[len(parts)-1]]
+	}
+	for _, project := range projects {
+		// the project names are <cluster>:<project>
+		if cluster := strings.Split(project, ":")[0]; !slices.Contains(entry.Clusters, cluster) {
+			entry.Clusters = append(entry.Clusters, cluster)
+		}
+	}
+	slices.Sort(entry.Clusters)
+
+	if endpoints, _, _ := unstructured.NestedStringSlice(globalDNS.Object, "status", "endpoints"); endpoints != nil {
+		entry.Endpoints = endpoints
+	}
+	clusterEndpoints, _, _ := unstructured.NestedMap(globalDNS.Object, "status", "clusterEndpoints")
+	if len(clusterEndpoints) > 0 {
+		entry.ClusterEndpoints = map[string][]string{}
+	}
+	for cluster := range clusterEndpoints {
+		entry.ClusterEndpoints[cluster], _, _ = unstructured.NestedStringSlice(clusterEndpoints, cluster)
+	}
+	for _, cluster := range entry.Clusters {
+		if len(entry.ClusterEndpoints[cluster]) == 0 {
+			entry.ClustersWithoutEndpoints = append(entry.ClustersWithoutEndpoints, cluster)
+		}
+	}
+
+	return entry
+}
+
+func marshalGlobalDNSEntries(ctx context.Context, result globalDNSEntries) (*mcp.CallToolResult, any, error) {
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listGlobalDNSEntries"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
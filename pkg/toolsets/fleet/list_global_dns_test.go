@@ -0,0 +1,120 @@
+package fleet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func fakeGlobalDNS(name string, spec map[string]any, status map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "GlobalDNS",
+		"metadata":   map[string]any{"name": name, "namespace": globalDataNamespace},
+		"spec":       spec,
+		"status":     status,
+	}}
+}
+
+func fakeGlobalDNSObjects() []runtime.Object {
+	return []runtime.Object{
+		fakeGlobalDNS("gd-projects", map[string]any{
+			"fqdn":         "app.example.com",
+			"providerName": "cattle-global-data:route53",
+			"projectNames": []any{"c-abc:p-1", "c-def:p-2"},
+		}, map[string]any{
+			"endpoints":        []any{"1.1.1.1"},
+			"clusterEndpoints": map[string]any{"c-abc": []any{"1.1.1.1"}},
+		}),
+		fakeGlobalDNS("gd-app", map[string]any{
+			"fqdn":                "wordpress.example.com",
+			"providerName":        "cattle-global-data:cloudflare",
+			"multiClusterAppName": "cattle-global-data:wordpress",
+		}, map[string]any{
+			"endpoints":        []any{"1.1.1.1", "2.2.2.2"},
+			"clusterEndpoints": map[string]any{"c-abc": []any{"1.1.1.1"}, "c-def": []any{"2.2.2.2"}},
+		}),
+		fakeMultiClusterApp("wordpress",
+			map[string]any{"projectName": "c-abc:p-1", "healthState": "healthy"},
+			map[string]any{"projectName": "c-def:p-3", "healthState": "healthy"},
+		),
+	}
+}
+
+func TestListGlobalDNSEntries(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	appEntry := `{"name": "gd-app", "fqdn": "wordpress.example.com", "provider": "cattle-global-data:cloudflare",
+		"multiClusterApp": "cattle-global-data:wordpress", "clusters": ["c-abc", "c-def"], "endpoints": ["1.1.1.1", "2.2.2.2"],
+		"clusterEndpoints": {"c-abc": ["1.1.1.1"], "c-def": ["2.2.2.2"]}}`
+	projectsEntry := `{"name": "gd-projects", "fqdn": "app.example.com", "provider": "cattle-global-data:route53",
+		"clusters": ["c-abc", "c-def"], "endpoints": ["1.1.1.1"], "clusterEndpoints": {"c-abc": ["1.1.1.1"]},
+		"clustersWithoutEndpoints": ["c-def"]}`
+	deprecatedNote := `"notes": ["GlobalDNS is deprecated since Rancher 2.5 and removed in 2.6"]`
+
+	tests := map[string]struct {
+		params         listGlobalDNSEntriesParams
+		notFound       bool
+		expectedResult string
+		expectedError  string
+	}{
+		"all entries": {
+			params:         listGlobalDNSEntriesParams{},
+			expectedResult: `{"entries": [` + appEntry + `, ` + projectsEntry + `], ` + deprecatedNote + `}`,
+		},
+		"entry": {
+			params:         listGlobalDNSEntriesParams{FQDN: "app.example.com"},
+			expectedResult: `{"entries": [` + projectsEntry + `], ` + deprecatedNote + `}`,
+		},
+		"entry not found": {
+			params:        listGlobalDNSEntriesParams{FQDN: "unknown.example.com"},
+			expectedError: "GlobalDNS entry unknown.example.com not found",
+		},
+		"global DNS not installed": {
+			params:   listGlobalDNSEntriesParams{},
+			notFound: true,
+			expectedResult: `{"entries": [], "notes": ["GlobalDNS isn't available, it was removed in Rancher 2.6. ` +
+				`Use an external DNS controller, e.g. external-dns, deployed with Fleet in each cluster instead"]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := newMultiClusterFakeDynClient(fakeGlobalDNSObjects()...)
+			if test.notFound {
+				fakeDynClient.PrependReactor("list", "globaldnses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "management.cattle.io", Resource: "globaldnses"}, "")
+				})
+			}
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.listGlobalDNSEntries(middleware.WithToken(context.TODO(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
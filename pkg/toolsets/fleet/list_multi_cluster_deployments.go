@@ -0,0 +1,236 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// globalDataNamespace is the namespace of the MultiClusterApps and GlobalDNS entries.
+	globalDataNamespace = "cattle-global-data"
+
+	bundleNamespaceLabel = "fleet.cattle.io/bundle-namespace"
+	bundleNameLabel      = "fleet.cattle.io/bundle-name"
+	clusterLabel         = "fleet.cattle.io/cluster"
+	repoNameLabel        = "fleet.cattle.io/repo-name"
+
+	bundleReadyState = "Ready"
+)
+
+type listMultiClusterDeploymentsParams struct {
+	Workspace     string `json:"workspace,omitempty" jsonschema:"the Fleet workspace of the bundles, e.g. fleet-default. Empty for all workspaces"`
+	MinClusters   int    `json:"minClusters,omitempty" jsonschema:"only return the bundles and apps targeting at least this number of clusters"`
+	OutOfSyncOnly bool   `json:"outOfSyncOnly,omitempty" jsonschema:"only return the bundles and apps with clusters out of sync"`
+}
+
+// multiClusterDeployments are the Fleet bundles and the legacy MultiClusterApps deployed to several clusters.
+type multiClusterDeployments struct {
+	Bundles          []fleetBundle     `json:"bundles"`
+	MultiClusterApps []multiClusterApp `json:"multiClusterApps,omitempty"`
+	Notes            []string          `json:"notes,omitempty"`
+}
+
+// fleetBundle is a Fleet bundle with the clusters where its BundleDeployments are in sync and out of sync.
+type fleetBundle struct {
+	Name      string `json:"name"`
+	Workspace string `json:"workspace"`
+	GitRepo   string `json:"gitRepo,omitempty"`
+	Clusters  int    `json:"clusters"`
+	// InSync are the clusters where the last version of the bundle is deployed, ready and not modified.
+	InSync    []string       `json:"inSync"`
+	OutOfSync []clusterState `json:"outOfSync"`
+}
+
+// clusterState is the state of a bundle or an app in a cluster out of sync.
+type clusterState struct {
+	Cluster string `json:"cluster"`
+	State   string `json:"state"`
+	Message string `json:"message,omitempty"`
+}
+
+// multiClusterApp is a MultiClusterApp of Rancher 2.5 and earlier, with the health of its apps in the target projects.
+type multiClusterApp struct {
+	Name            string         `json:"name"`
+	TemplateVersion string         `json:"templateVersion"`
+	Clusters        int            `json:"clusters"`
+	InSync          []string       `json:"inSync"`
+	OutOfSync       []clusterState `json:"outOfSync"`
+}
+
+// listMultiClusterDeployments returns the Fleet bundles with the sync state of their BundleDeployments in each target
+// cluster, and the legacy MultiClusterApps with the health of their apps, to reason about cross-cluster deployments.
+func (t *Tools) listMultiClusterDeployments(ctx context.Context, toolReq *mcp.CallToolRequest, params listMultiClusterDeploymentsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listMultiClusterDeployments called")
+
+	listParams := client.ListParams{
+		Cluster:   "local",
+		Kind:      "bundle",
+		Namespace: params.Workspace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}
+	bundles, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list bundles", zap.String("tool", "listMultiClusterDeployments"), zap.Error(err))
+		return nil, nil, err
+	}
+	// the BundleDeployments are in the namespaces of the Fleet clusters
+	listParams.Kind = "bundledeployment"
+	listParams.Namespace = ""
+	bundleDeployments, err := t.client.GetResources(ctx, listParams)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list bundle deployments", zap.String("tool", "listMultiClusterDeployments"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := multiClusterDeployments{Bundles: []fleetBundle{}}
+	for _, bundle := range bundles {
+		b := fleetBundle{Name: bundle.GetName(), Workspace: bundle.GetNamespace(), GitRepo: bundle.GetLabels()[repoNameLabel], InSync: []string{}, OutOfSync: []clusterState{}}
+		for _, bundleDeployment := range bundleDeployments {
+			labels := bundleDeployment.GetLabels()
+			if labels[bundleNamespaceLabel] != b.Workspace || labels[bundleNameLabel] != b.Name {
+				continue
+			}
+			b.Clusters++
+			state := bundleDeploymentState(bundleDeployment)
+			if state.State == bundleReadyState {
+				b.InSync = append(b.InSync, state.Cluster)
+			} else {
+				b.OutOfSync = append(b.OutOfSync, state)
+			}
+		}
+		if b.Clusters < params.MinClusters || (params.OutOfSyncOnly && len(b.OutOfSync) == 0) {
+			continue
+		}
+		slices.Sort(b.InSync)
+		slices.SortFunc(b.OutOfSync, func(a, b clusterState) int { return strings.Compare(a.Cluster, b.Cluster) })
+		result.Bundles = append(result.Bundles, b)
+	}
+	slices.SortFunc(result.Bundles, func(a, b fleetBundle) int {
+		return strings.Compare(a.Workspace+"/"+a.Name, b.Workspace+"/"+b.Name)
+	})
+
+	apps, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   "local",
+		Kind:      "multiclusterapp",
+		Namespace: globalDataNamespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if apierrors.IsNotFound(err) {
+		// MultiClusterApps were removed in Rancher 2.6
+		apps = nil
+	} else if err != nil {
+		middleware.Logger(ctx).Error("failed to list multi-cluster apps", zap.String("tool", "listMultiClusterDeployments"), zap.Error(err))
+		return nil, nil, err
+	}
+	for _, app := range apps {
+		a := newMultiClusterApp(app)
+		if a.Clusters < params.MinClusters || (params.OutOfSyncOnly && len(a.OutOfSync) == 0) {
+			continue
+		}
+		result.MultiClusterApps = append(result.MultiClusterApps, a)
+	}
+	if len(apps) > 0 {
+		result.Notes = append(result.Notes, "MultiClusterApps are deprecated since Rancher 2.5 and removed in 2.6, they should be migrated to Fleet bundles")
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listMultiClusterDeployments"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// bundleDeploymentState returns the state of a BundleDeployment in its cluster. It is in sync when the agent applied
+// its last deployment ID and its resources are ready and not modified.
+func bundleDeploymentState(bundleDeployment *unstructured.Unstructured) clusterState {
+	state := clusterState{Cluster: bundleDeployment.GetLabels()[clusterLabel]}
+	deploymentID, _, _ := unstructured.NestedString(bundleDeployment.Object, "spec", "deploymentID")
+	appliedDeploymentID, _, _ := unstructured.NestedString(bundleDeployment.Object, "status", "appliedDeploymentID")
+	ready, _, _ := unstructured.NestedBool(bundleDeployment.Object, "status", "ready")
+	nonModified, _, _ := unstructured.NestedBool(bundleDeployment.Object, "status", "nonModified")
+	state.State, _, _ = unstructured.NestedString(bundleDeployment.Object, "status", "display", "state")
+
+	switch {
+	case appliedDeploymentID != deploymentID:
+		if state.State == "" || state.State == bundleReadyState {
+			state.State = "WaitApplied"
+		}
+	case ready && nonModified:
+		state.State = bundleReadyState
+	case state.State == "" || state.State == bundleReadyState:
+		state.State = "NotReady"
+		if !nonModified {
+			state.State = "Modified"
+		}
+	}
+	if state.State != bundleReadyState {
+		state.Message = falseConditionMessage(bundleDeployment)
+	}
+
+	return state
+}
+
+// falseConditionMessage returns the messages of the conditions of a resource whose status isn't True.
+func falseConditionMessage(obj *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	var messages []string
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(condition, "status")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		if status != "True" && message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// newMultiClusterApp returns the health of the apps of a MultiClusterApp in its target projects.
+func newMultiClusterApp(app *unstructured.Unstructured) multiClusterApp {
+	a := multiClusterApp{Name: app.GetName(), InSync: []string{}, OutOfSync: []clusterState{}}
+	a.TemplateVersion, _, _ = unstructured.NestedString(app.Object, "spec", "templateVersionName")
+	targets, _, _ := unstructured.NestedSlice(app.Object, "spec", "targets")
+	clusters := map[string]bool{}
+	for _, t := range targets {
+		target, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		project, _, _ := unstructured.NestedString(target, "projectName")
+		health, _, _ := unstructured.NestedString(target, "healthState")
+		state, _, _ := unstructured.NestedString(target, "state")
+		// the project names are <cluster>:<project>
+		clusters[strings.Split(project, ":")[0]] = true
+		if health == "healthy" {
+			a.InSync = append(a.InSync, project)
+			continue
+		}
+		if health == "" {
+			health = state
+		}
+		a.OutOfSync = append(a.OutOfSync, clusterState{Cluster: project, State: health})
+	}
+	a.Clusters = len(clusters)
+
+	return a
+}
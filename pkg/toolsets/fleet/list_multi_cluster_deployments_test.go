@@ -0,0 +1,179 @@
+package fleet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func fakeBundle(namespace string, name string, repo string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "fleet.cattle.io/v1alpha1",
+		"kind":       "Bundle",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]any{repoNameLabel: repo},
+		},
+	}}
+}
+
+func fakeBundleDeployment(bundleNamespace string, bundle string, cluster string, status map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "fleet.cattle.io/v1alpha1",
+		"kind":       "BundleDeployment",
+		"metadata": map[string]any{
+			"name":      bundle,
+			"namespace": "cluster-" + bundleNamespace + "-" + cluster,
+			"labels": map[string]any{
+				bundleNamespaceLabel: bundleNamespace,
+				bundleNameLabel:      bundle,
+				clusterLabel:         cluster,
+			},
+		},
+		"spec":   map[string]any{"deploymentID": "s-123:abc"},
+		"status": status,
+	}}
+}
+
+func fakeMultiClusterApp(name string, targets ...any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "MultiClusterApp",
+		"metadata":   map[string]any{"name": name, "namespace": globalDataNamespace},
+		"spec": map[string]any{
+			"templateVersionName": "cattle-global-data:library-wordpress-7.3.8",
+			"targets":             targets,
+		},
+	}}
+}
+
+var readyStatus = map[string]any{"appliedDeploymentID": "s-123:abc", "ready": true, "nonModified": true, "display": map[string]any{"state": "Ready"}}
+
+func fakeMultiClusterObjects() []runtime.Object {
+	return []runtime.Object{
+		fakeBundle("fleet-default", "monitoring", "monitoring-repo"),
+		fakeBundleDeployment("fleet-default", "monitoring", "downstream-1", readyStatus),
+		fakeBundleDeployment("fleet-default", "monitoring", "downstream-2", map[string]any{
+			"appliedDeploymentID": "s-123:abc",
+			"ready":               false,
+			"nonModified":         true,
+			"display":             map[string]any{"state": "NotReady"},
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "False", "message": "deployment.apps cattle-monitoring-system/grafana not ready"},
+				map[string]any{"type": "Deployed", "status": "True"},
+			},
+		}),
+		fakeBundleDeployment("fleet-default", "monitoring", "downstream-3", map[string]any{"appliedDeploymentID": "s-122:abc", "ready": true, "nonModified": true}),
+		fakeBundle("fleet-default", "logging", "logging-repo"),
+		fakeBundleDeployment("fleet-default", "logging", "downstream-1", readyStatus),
+		fakeBundleDeployment("fleet-default", "logging", "downstream-2", map[string]any{"appliedDeploymentID": "s-123:abc", "ready": true, "nonModified": false}),
+		fakeBundle("fleet-local", "fleet-agent-local", ""),
+		fakeBundleDeployment("fleet-local", "fleet-agent-local", "local", readyStatus),
+	}
+}
+
+func newMultiClusterFakeDynClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "fleet.cattle.io", Version: "v1alpha1", Resource: "bundles"}:           "BundleList",
+		{Group: "fleet.cattle.io", Version: "v1alpha1", Resource: "bundledeployments"}: "BundleDeploymentList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "multiclusterapps"}:   "MultiClusterAppList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "globaldnses"}:        "GlobalDNSList",
+	}, objects...)
+}
+
+func TestListMultiClusterDeployments(t *testing.T) {
+	fakeUrl := "https://localhost:8080"
+	fakeToken := "fakeToken"
+	monitoring := `{"name": "monitoring", "workspace": "fleet-default", "gitRepo": "monitoring-repo", "clusters": 3,
+		"inSync": ["downstream-1"],
+		"outOfSync": [
+			{"cluster": "downstream-2", "state": "NotReady", "message": "deployment.apps cattle-monitoring-system/grafana not ready"},
+			{"cluster": "downstream-3", "state": "WaitApplied"}
+		]}`
+	logging := `{"name": "logging", "workspace": "fleet-default", "gitRepo": "logging-repo", "clusters": 2,
+		"inSync": ["downstream-1"], "outOfSync": [{"cluster": "downstream-2", "state": "Modified"}]}`
+
+	tests := map[string]struct {
+		params         listMultiClusterDeploymentsParams
+		objects        []runtime.Object
+		mcaNotFound    bool
+		expectedResult string
+	}{
+		"all bundles": {
+			params:  listMultiClusterDeploymentsParams{},
+			objects: fakeMultiClusterObjects(),
+			expectedResult: `{"bundles": [` + logging + `, ` + monitoring + `,
+				{"name": "fleet-agent-local", "workspace": "fleet-local", "clusters": 1, "inSync": ["local"], "outOfSync": []}]}`,
+		},
+		"bundles of a workspace targeting several clusters": {
+			params:         listMultiClusterDeploymentsParams{Workspace: "fleet-default", MinClusters: 3},
+			objects:        fakeMultiClusterObjects(),
+			expectedResult: `{"bundles": [` + monitoring + `]}`,
+		},
+		"out of sync only": {
+			params:         listMultiClusterDeploymentsParams{OutOfSyncOnly: true},
+			objects:        fakeMultiClusterObjects(),
+			expectedResult: `{"bundles": [` + logging + `, ` + monitoring + `]}`,
+		},
+		"multi-cluster apps": {
+			params: listMultiClusterDeploymentsParams{Workspace: "fleet-local"},
+			objects: append(fakeMultiClusterObjects(), fakeMultiClusterApp("wordpress",
+				map[string]any{"projectName": "c-abc:p-1", "appName": "wordpress-1", "healthState": "healthy"},
+				map[string]any{"projectName": "c-abc:p-2", "appName": "wordpress-2", "healthState": "healthy"},
+				map[string]any{"projectName": "c-def:p-3", "appName": "wordpress-3", "state": "deploying"},
+			)),
+			expectedResult: `{"bundles": [{"name": "fleet-agent-local", "workspace": "fleet-local", "clusters": 1, "inSync": ["local"], "outOfSync": []}],
+				"multiClusterApps": [{"name": "wordpress", "templateVersion": "cattle-global-data:library-wordpress-7.3.8", "clusters": 2,
+					"inSync": ["c-abc:p-1", "c-abc:p-2"], "outOfSync": [{"cluster": "c-def:p-3", "state": "deploying"}]}],
+				"notes": ["MultiClusterApps are deprecated since Rancher 2.5 and removed in 2.6, they should be migrated to Fleet bundles"]}`,
+		},
+		"multi-cluster apps not installed": {
+			params:         listMultiClusterDeploymentsParams{Workspace: "fleet-local"},
+			objects:        fakeMultiClusterObjects(),
+			mcaNotFound:    true,
+			expectedResult: `{"bundles": [{"name": "fleet-agent-local", "workspace": "fleet-local", "clusters": 1, "inSync": ["local"], "outOfSync": []}]}`,
+		},
+		"no bundles": {
+			params:         listMultiClusterDeploymentsParams{Workspace: "fleet-default"},
+			expectedResult: `{"bundles": []}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := newMultiClusterFakeDynClient(test.objects...)
+			if test.mcaNotFound {
+				fakeDynClient.PrependReactor("list", "multiclusterapps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "management.cattle.io", Resource: "multiclusterapps"}, "")
+				})
+			}
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.listMultiClusterDeployments(middleware.WithToken(context.TODO(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -3,13 +3,14 @@ package fleet
 import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
 )
 
 const (
 	toolsSet    = "fleet"
 	toolsSetAnn = "toolset"
-	tokenHeader = "R_token"
-	urlHeader   = "R_url"
+	urlHeader   = client.URLHeader
 )
 
 // Tools contains all tools for the MCP server
@@ -24,6 +25,11 @@ func NewTools(client *client.Client) *Tools {
 	}
 }
 
+// Register adds the tools of the fleet toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	NewTools(deps.Client).AddTools(mcpServer)
+}
+
 // AddTools registers all Rancher Kubernetes tools with the provided MCP server.
 // Each tool is configured with metadata identifying it as part of the rancher toolset.
 func (t *Tools) AddTools(mcpServer *mcp.Server) {
@@ -38,6 +44,35 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		
 		Returns:
 		List of all GitRepos in the workspace.`},
-		t.listGitRepos,
+		response.WithStructuredErrors(t.listGitRepos),
+	)
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listMultiClusterDeployments",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `List the Fleet bundles and the legacy MultiClusterApps deployed to several clusters, with the clusters where they are in sync and out of sync.
+		A cluster is in sync when its BundleDeployment applied the last version of the bundle and its resources are ready and not modified.
+		Parameters:
+		workspace (string, optional): The Fleet workspace of the bundles, e.g. fleet-default. Empty for all workspaces.
+		minClusters (integer, optional): Only return the bundles and apps targeting at least this number of clusters.
+		outOfSyncOnly (boolean, optional): Only return the bundles and apps with clusters out of sync.
+
+		Returns:
+		The bundles with their GitRepo and the state and message of each cluster out of sync, and the MultiClusterApps with the health of their apps in each target project.`},
+		response.WithStructuredErrors(t.listMultiClusterDeployments),
+	)
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listGlobalDNSEntries",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `List the GlobalDNS entries of Rancher 2.5 and earlier, with the clusters they target and the endpoints published for each cluster.
+		Parameters:
+		fqdn (string, optional): The FQDN of the GlobalDNS entry. Empty for all entries.
+
+		Returns:
+		The entries with their provider, target clusters, endpoints and the target clusters without endpoints.`},
+		response.WithStructuredErrors(t.listGlobalDNSEntries),
 	)
 }
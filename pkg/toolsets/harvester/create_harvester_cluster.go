@@ -0,0 +1,285 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	defaultClusterNamespace = "fleet-default"
+	harvesterConfigKind     = "HarvesterConfig"
+
+	defaultCPUCount   = 2
+	defaultMemorySize = 4
+	defaultDiskSize   = 40
+
+	etcdRole         = "etcd"
+	controlPlaneRole = "controlplane"
+	workerRole       = "worker"
+)
+
+type harvesterMachinePoolParams struct {
+	Name       string   `json:"name" jsonschema:"the name of the machine pool" validate:"required,format=dns1123-label"`
+	Quantity   int32    `json:"quantity" jsonschema:"the number of VMs of the pool"`
+	Roles      []string `json:"roles" jsonschema:"the roles of the VMs: etcd, controlplane and/or worker" validate:"required,enum=etcd|controlplane|worker"`
+	CPUCount   int64    `json:"cpuCount,omitempty" jsonschema:"the number of CPUs of the VMs of the pool, overriding the one of the cluster"`
+	MemorySize int64    `json:"memorySize,omitempty" jsonschema:"the memory of the VMs of the pool in GiB, overriding the one of the cluster"`
+	DiskSize   int64    `json:"diskSize,omitempty" jsonschema:"the size of the root disk of the VMs of the pool in GiB, overriding the one of the cluster"`
+}
+
+type createHarvesterClusterParams struct {
+	Name              string                       `json:"name" jsonschema:"the name of the cluster" validate:"required,format=dns1123-label"`
+	Namespace         string                       `json:"namespace,omitempty" jsonschema:"the namespace of the cluster, defaults to fleet-default"`
+	KubernetesVersion string                       `json:"kubernetesVersion" jsonschema:"the RKE2 version of the cluster, e.g. v1.31.4+rke2r1" validate:"required,format=kubernetes-version"`
+	CloudCredential   string                       `json:"cloudCredential" jsonschema:"the ID or the name of the Harvester cloud credential" validate:"required"`
+	VMNamespace       string                       `json:"vmNamespace" jsonschema:"the namespace of the VMs in the Harvester cluster" validate:"required,format=dns1123-label"`
+	Image             string                       `json:"image" jsonschema:"the Harvester image of the VMs, as namespace/name" validate:"required"`
+	Network           string                       `json:"network" jsonschema:"the Harvester VM network of the VMs, as namespace/name" validate:"required"`
+	SSHUser           string                       `json:"sshUser" jsonschema:"the SSH user of the image, e.g. ubuntu" validate:"required"`
+	CPUCount          int64                        `json:"cpuCount,omitempty" jsonschema:"the number of CPUs of the VMs, defaults to 2"`
+	MemorySize        int64                        `json:"memorySize,omitempty" jsonschema:"the memory of the VMs in GiB, defaults to 4"`
+	DiskSize          int64                        `json:"diskSize,omitempty" jsonschema:"the size of the root disk of the VMs in GiB, defaults to 40"`
+	MachinePools      []harvesterMachinePoolParams `json:"machinePools" jsonschema:"the machine pools of the cluster" validate:"required"`
+}
+
+// createHarvesterCluster creates an RKE2 cluster whose machines are VMs provisioned by Rancher in a Harvester
+// cluster. It checks the image and the network in the Harvester cluster of the cloud credential, then creates a
+// Harvester machine config for each machine pool and the provisioning cluster referencing them.
+func (t *Tools) createHarvesterCluster(ctx context.Context, toolReq *mcp.CallToolRequest, params createHarvesterClusterParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createHarvesterCluster called")
+
+	if !strings.Contains(params.KubernetesVersion, "rke2") {
+		return nil, nil, fmt.Errorf("invalid Kubernetes version %q, Harvester clusters must be RKE2 clusters, e.g. v1.31.4+rke2r1", params.KubernetesVersion)
+	}
+	if err := validateMachinePools(params.MachinePools); err != nil {
+		return nil, nil, err
+	}
+	if params.Namespace == "" {
+		params.Namespace = defaultClusterNamespace
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	credential, err := t.getCloudCredential(ctx, url, token, params.CloudCredential)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cloud credential", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+		return nil, nil, err
+	}
+	harvesterID := credentialClusterID(credential)
+	if harvesterID == "" {
+		return nil, nil, fmt.Errorf("cloud credential %s is not a Harvester credential", params.CloudCredential)
+	}
+	for _, ref := range []struct{ kind, description, value string }{
+		{"virtualmachineimage", "image", params.Image},
+		{"networkattachmentdefinition", "network", params.Network},
+	} {
+		if err := t.checkHarvesterResource(ctx, url, token, harvesterID, ref.kind, ref.description, ref.value); err != nil {
+			middleware.Logger(ctx).Error("failed to check Harvester resource", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+			return nil, nil, err
+		}
+	}
+
+	configInterface, err := t.client.GetResourceInterface(ctx, token, url, params.Namespace, localCluster, converter.K8sKindsToGVRs["harvesterconfig"])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+		return nil, nil, err
+	}
+	var configs []*unstructured.Unstructured
+	// the machine configs are only used by this cluster, remove them if the cluster can't be created
+	cleanup := func() {
+		for _, config := range configs {
+			if err := configInterface.Delete(ctx, config.GetName(), metav1.DeleteOptions{}); err != nil {
+				middleware.Logger(ctx).Error("failed to delete machine config", zap.String("tool", "createHarvesterCluster"), zap.String("name", config.GetName()), zap.Error(err))
+			}
+		}
+	}
+
+	var machinePools []any
+	for _, pool := range params.MachinePools {
+		config, err := newHarvesterConfig(params, pool)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		created, err := configInterface.Create(ctx, config, metav1.CreateOptions{})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create machine config", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create the machine config of pool %s: %w", pool.Name, err)
+		}
+		configs = append(configs, created)
+
+		machinePools = append(machinePools, map[string]any{
+			"name":             pool.Name,
+			"quantity":         int64(pool.Quantity),
+			"etcdRole":         slices.Contains(pool.Roles, etcdRole),
+			"controlPlaneRole": slices.Contains(pool.Roles, controlPlaneRole),
+			"workerRole":       slices.Contains(pool.Roles, workerRole),
+			"machineConfigRef": map[string]any{
+				"kind": harvesterConfigKind,
+				"name": created.GetName(),
+			},
+		})
+	}
+
+	cluster := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": converter.ProvisioningGroup + "/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      params.Name,
+				"namespace": params.Namespace,
+			},
+			"spec": map[string]any{
+				"kubernetesVersion":         params.KubernetesVersion,
+				"cloudCredentialSecretName": credential.GetNamespace() + ":" + credential.GetName(),
+				"rkeConfig": map[string]any{
+					"machinePools": machinePools,
+				},
+			},
+		},
+	}
+	clusterInterface, err := t.client.GetResourceInterface(ctx, token, url, params.Namespace, localCluster, converter.K8sKindsToGVRs[converter.ProvisioningClusterResourceKind])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+		cleanup()
+		return nil, nil, err
+	}
+	created, err := clusterInterface.Create(ctx, cluster, metav1.CreateOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create provisioning cluster", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to create cluster %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, append([]*unstructured.Unstructured{created}, configs...), localCluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "createHarvesterCluster"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// checkHarvesterResource checks that a namespaced resource, given as namespace/name, exists in the Harvester cluster.
+func (t *Tools) checkHarvesterResource(ctx context.Context, url string, token string, harvesterID string, kind string, description string, value string) error {
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" {
+		return fmt.Errorf("invalid %s %q, must be namespace/name", description, value)
+	}
+	_, err := t.client.GetResource(ctx, client.GetParams{Cluster: harvesterID, Kind: kind, Namespace: namespace, Name: name, URL: url, Token: token})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("%s %s not found in Harvester cluster %s", description, value, harvesterID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s: %w", description, value, err)
+	}
+
+	return nil
+}
+
+// newHarvesterConfig returns the Harvester machine config of a pool, with the disk and the network of the VMs in the
+// JSON format of the Harvester node driver. Its name follows the pattern used by the Rancher UI.
+func newHarvesterConfig(params createHarvesterClusterParams, pool harvesterMachinePoolParams) (*unstructured.Unstructured, error) {
+	diskInfo, err := json.Marshal(map[string]any{
+		"disks": []any{map[string]any{
+			"imageName": params.Image,
+			"size":      poolValue(pool.DiskSize, params.DiskSize, defaultDiskSize),
+			"bootOrder": 1,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the disks of pool %s: %w", pool.Name, err)
+	}
+	networkInfo, err := json.Marshal(map[string]any{
+		"interfaces": []any{map[string]any{"networkName": params.Network}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the networks of pool %s: %w", pool.Name, err)
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": converter.MachineConfigGroup + "/v1",
+		"kind":       harvesterConfigKind,
+		"metadata": map[string]any{
+			"name":      fmt.Sprintf("nc-%s-%s", params.Name, pool.Name),
+			"namespace": params.Namespace,
+		},
+		"vmNamespace": params.VMNamespace,
+		"cpuCount":    strconv.FormatInt(poolValue(pool.CPUCount, params.CPUCount, defaultCPUCount), 10),
+		"memorySize":  strconv.FormatInt(poolValue(pool.MemorySize, params.MemorySize, defaultMemorySize), 10),
+		"diskInfo":    string(diskInfo),
+		"networkInfo": string(networkInfo),
+		"sshUser":     params.SSHUser,
+	}}, nil
+}
+
+// poolValue returns the value of a pool, else the one of the cluster, else the default value.
+func poolValue(pool int64, cluster int64, defaultValue int64) int64 {
+	if pool > 0 {
+		return pool
+	}
+	if cluster > 0 {
+		return cluster
+	}
+
+	return defaultValue
+}
+
+// validateMachinePools checks that every pool has a unique name, VMs and valid roles, and that the cluster has at
+// least one VM for each role.
+func validateMachinePools(pools []harvesterMachinePoolParams) error {
+	if len(pools) == 0 {
+		return fmt.Errorf("at least one machine pool is required")
+	}
+
+	names := map[string]bool{}
+	roles := map[string]bool{}
+	for _, pool := range pools {
+		if pool.Name == "" {
+			return fmt.Errorf("the name of the machine pools is required")
+		}
+		if names[pool.Name] {
+			return fmt.Errorf("duplicate machine pool %s", pool.Name)
+		}
+		names[pool.Name] = true
+		if pool.Quantity < 1 {
+			return fmt.Errorf("machine pool %s must have at least one VM", pool.Name)
+		}
+		if pool.CPUCount < 0 || pool.MemorySize < 0 || pool.DiskSize < 0 {
+			return fmt.Errorf("the CPUs, memory and disk size of machine pool %s must be positive", pool.Name)
+		}
+		if len(pool.Roles) == 0 {
+			return fmt.Errorf("machine pool %s must have at least one role", pool.Name)
+		}
+		for _, role := range pool.Roles {
+			if role != etcdRole && role != controlPlaneRole && role != workerRole {
+				return fmt.Errorf("invalid role %q of machine pool %s, must be one of etcd, controlplane or worker", role, pool.Name)
+			}
+			roles[role] = true
+		}
+	}
+	for _, role := range []string{etcdRole, controlPlaneRole, workerRole} {
+		if !roles[role] {
+			return fmt.Errorf("no machine pool has the %s role", role)
+		}
+	}
+
+	return nil
+}
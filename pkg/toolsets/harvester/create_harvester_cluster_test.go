@@ -0,0 +1,159 @@
+package harvester
+
+import (
+	"testing"
+
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var (
+	harvesterConfigsGVR             = schema.GroupVersionResource{Group: "rke-machine-config.cattle.io", Version: "v1", Resource: "harvesterconfigs"}
+	networkAttachmentDefinitionsGVR = schema.GroupVersionResource{Group: "k8s.cni.cncf.io", Version: "v1", Resource: "network-attachment-definitions"}
+	provisioningClustersGVR         = schema.GroupVersionResource{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}
+)
+
+func fakeHarvesterClusterObjects() []runtime.Object {
+	return []runtime.Object{
+		fakeManagementCluster("c-abc12", "harvester-1", harvesterProvider, nil),
+		fakeCloudCredential("cc-abc12", "harvester-1-creds", harvesterCredentialData("c-abc12")),
+		fakeCloudCredential("cc-xyz98", "aws-creds", map[string]any{"amazonec2credentialConfig-accessKey": "QUtJQQ=="}),
+		fakeObject("harvesterhci.io/v1beta1", "VirtualMachineImage", "default", "image-ubuntu", nil),
+	}
+}
+
+// newHarvesterClusterClient returns a fake client with the Harvester objects, the network being created with its
+// resource name that the fake client can't guess from its kind.
+func newHarvesterClusterClient(t *testing.T, objects ...runtime.Object) (*client.Client, *dynamicfake.FakeDynamicClient) {
+	c, fakeDynClient := newFakeClient(append(fakeHarvesterClusterObjects(), objects...)...)
+	network := fakeObject("k8s.cni.cncf.io/v1", "NetworkAttachmentDefinition", "default", "vlan1", nil)
+	require.NoError(t, fakeDynClient.Tracker().Create(networkAttachmentDefinitionsGVR, network, "default"))
+
+	return c, fakeDynClient
+}
+
+func newCreateHarvesterClusterParams() createHarvesterClusterParams {
+	return createHarvesterClusterParams{
+		Name:              "cluster1",
+		KubernetesVersion: "v1.31.4+rke2r1",
+		CloudCredential:   "harvester-1-creds",
+		VMNamespace:       "default",
+		Image:             "default/image-ubuntu",
+		Network:           "default/vlan1",
+		SSHUser:           "ubuntu",
+		MachinePools: []harvesterMachinePoolParams{
+			{Name: "cp", Quantity: 1, Roles: []string{etcdRole, controlPlaneRole}},
+			{Name: "workers", Quantity: 2, Roles: []string{workerRole}, CPUCount: 8, DiskSize: 100},
+		},
+	}
+}
+
+func TestCreateHarvesterCluster(t *testing.T) {
+	c, fakeDynClient := newHarvesterClusterClient(t)
+	tools := Tools{client: c}
+
+	_, _, err := tools.createHarvesterCluster(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), newCreateHarvesterClusterParams())
+
+	require.NoError(t, err)
+	cp, err := fakeDynClient.Resource(harvesterConfigsGVR).Namespace("fleet-default").Get(t.Context(), "nc-cluster1-cp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"apiVersion":  "rke-machine-config.cattle.io/v1",
+		"kind":        "HarvesterConfig",
+		"metadata":    map[string]any{"name": "nc-cluster1-cp", "namespace": "fleet-default"},
+		"vmNamespace": "default",
+		"cpuCount":    "2",
+		"memorySize":  "4",
+		"diskInfo":    `{"disks":[{"bootOrder":1,"imageName":"default/image-ubuntu","size":40}]}`,
+		"networkInfo": `{"interfaces":[{"networkName":"default/vlan1"}]}`,
+		"sshUser":     "ubuntu",
+	}, cp.Object)
+	workers, err := fakeDynClient.Resource(harvesterConfigsGVR).Namespace("fleet-default").Get(t.Context(), "nc-cluster1-workers", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "8", workers.Object["cpuCount"])
+	assert.Equal(t, `{"disks":[{"bootOrder":1,"imageName":"default/image-ubuntu","size":100}]}`, workers.Object["diskInfo"])
+
+	cluster, err := fakeDynClient.Resource(provisioningClustersGVR).Namespace("fleet-default").Get(t.Context(), "cluster1", metav1.GetOptions{})
+	require.NoError(t, err)
+	spec, _, _ := unstructured.NestedMap(cluster.Object, "spec")
+	assert.Equal(t, map[string]any{
+		"kubernetesVersion":         "v1.31.4+rke2r1",
+		"cloudCredentialSecretName": "cattle-global-data:cc-abc12",
+		"rkeConfig": map[string]any{"machinePools": []any{
+			map[string]any{"name": "cp", "quantity": int64(1), "etcdRole": true, "controlPlaneRole": true, "workerRole": false,
+				"machineConfigRef": map[string]any{"kind": "HarvesterConfig", "name": "nc-cluster1-cp"}},
+			map[string]any{"name": "workers", "quantity": int64(2), "etcdRole": false, "controlPlaneRole": false, "workerRole": true,
+				"machineConfigRef": map[string]any{"kind": "HarvesterConfig", "name": "nc-cluster1-workers"}},
+		}},
+	}, spec)
+}
+
+func TestCreateHarvesterClusterErrors(t *testing.T) {
+	tests := map[string]struct {
+		params        func(*createHarvesterClusterParams)
+		expectedError string
+	}{
+		"K3s version": {
+			params:        func(p *createHarvesterClusterParams) { p.KubernetesVersion = "v1.31.4+k3s1" },
+			expectedError: "Harvester clusters must be RKE2 clusters",
+		},
+		"missing role": {
+			params: func(p *createHarvesterClusterParams) {
+				p.MachinePools = []harvesterMachinePoolParams{{Name: "all", Quantity: 1, Roles: []string{etcdRole, workerRole}}}
+			},
+			expectedError: "no machine pool has the controlplane role",
+		},
+		"not a Harvester credential": {
+			params:        func(p *createHarvesterClusterParams) { p.CloudCredential = "cattle-global-data:cc-xyz98" },
+			expectedError: "cloud credential cattle-global-data:cc-xyz98 is not a Harvester credential",
+		},
+		"credential not found": {
+			params:        func(p *createHarvesterClusterParams) { p.CloudCredential = "unknown" },
+			expectedError: "cloud credential unknown not found",
+		},
+		"image not found": {
+			params:        func(p *createHarvesterClusterParams) { p.Image = "default/image-rocky" },
+			expectedError: "image default/image-rocky not found in Harvester cluster c-abc12",
+		},
+		"invalid network": {
+			params:        func(p *createHarvesterClusterParams) { p.Network = "vlan1" },
+			expectedError: `invalid network "vlan1", must be namespace/name`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newHarvesterClusterClient(t)
+			tools := Tools{client: c}
+			params := newCreateHarvesterClusterParams()
+			test.params(&params)
+
+			_, _, err := tools.createHarvesterCluster(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), params)
+
+			assert.ErrorContains(t, err, test.expectedError)
+			for _, action := range fakeDynClient.Actions() {
+				assert.NotEqual(t, "create", action.GetVerb())
+			}
+		})
+	}
+}
+
+func TestCreateHarvesterClusterCleanup(t *testing.T) {
+	cluster := fakeObject("provisioning.cattle.io/v1", "Cluster", "fleet-default", "cluster1", nil)
+	c, fakeDynClient := newHarvesterClusterClient(t, cluster)
+	tools := Tools{client: c}
+
+	_, _, err := tools.createHarvesterCluster(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), newCreateHarvesterClusterParams())
+
+	assert.ErrorContains(t, err, "failed to create cluster cluster1")
+	configs, err := fakeDynClient.Resource(harvesterConfigsGVR).Namespace("fleet-default").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configs.Items)
+}
@@ -0,0 +1,139 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maintainStatusAnn is the annotation of the Harvester hosts in maintenance mode, running while their VMs are
+// migrated and completed once the host is drained.
+const maintainStatusAnn = "harvesterhci.io/maintain-status"
+
+type getHarvesterHostsParams struct {
+	Cluster string `json:"cluster" jsonschema:"the ID or the name of the Harvester cluster"`
+}
+
+// harvesterHost is the status of a host of a Harvester cluster.
+type harvesterHost struct {
+	Name        string   `json:"name"`
+	Ready       bool     `json:"ready"`
+	Cordoned    bool     `json:"cordoned"`
+	Maintenance string   `json:"maintenance,omitempty"`
+	Roles       []string `json:"roles"`
+	// Problems are the pressure conditions of the host, and the message of its Ready condition if it isn't Ready.
+	Problems          []string `json:"problems"`
+	CPU               string   `json:"cpu,omitempty"`
+	Memory            string   `json:"memory,omitempty"`
+	AllocatableCPU    string   `json:"allocatableCPU,omitempty"`
+	AllocatableMemory string   `json:"allocatableMemory,omitempty"`
+	KubeletVersion    string   `json:"kubeletVersion,omitempty"`
+	VMs               int      `json:"vms"`
+}
+
+// getHarvesterHosts returns the status of the nodes of a Harvester cluster, with the number of VM instances running on
+// each.
+func (t *Tools) getHarvesterHosts(ctx context.Context, toolReq *mcp.CallToolRequest, params getHarvesterHostsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getHarvesterHosts called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.harvesterClusterID(ctx, url, token, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Harvester cluster", zap.String("tool", "getHarvesterHosts"), zap.Error(err))
+		return nil, nil, err
+	}
+	nodes, err := t.client.GetResources(ctx, client.ListParams{Cluster: clusterID, Kind: "node", URL: url, Token: token})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list nodes", zap.String("tool", "getHarvesterHosts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list the hosts of cluster %s: %w", params.Cluster, err)
+	}
+	instances, err := t.client.GetResources(ctx, client.ListParams{Cluster: clusterID, Kind: "virtualmachineinstance", URL: url, Token: token})
+	if err != nil && !apierrors.IsNotFound(err) {
+		middleware.Logger(ctx).Error("failed to list virtual machine instances", zap.String("tool", "getHarvesterHosts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list the VM instances of cluster %s: %w", params.Cluster, err)
+	}
+	vms := map[string]int{}
+	for _, instance := range instances {
+		if node, _, _ := unstructured.NestedString(instance.Object, "status", "nodeName"); node != "" {
+			vms[node]++
+		}
+	}
+
+	hosts := []harvesterHost{}
+	for _, obj := range nodes {
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &node); err != nil {
+			middleware.Logger(ctx).Error("failed to convert node", zap.String("tool", "getHarvesterHosts"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to convert node %s: %w", obj.GetName(), err)
+		}
+		host := newHarvesterHost(node)
+		host.VMs = vms[node.Name]
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+
+	response, err := json.Marshal(hosts)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getHarvesterHosts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newHarvesterHost returns the status of a Harvester node.
+func newHarvesterHost(node corev1.Node) harvesterHost {
+	host := harvesterHost{
+		Name:           node.Name,
+		Cordoned:       node.Spec.Unschedulable,
+		Maintenance:    node.Annotations[maintainStatusAnn],
+		Roles:          []string{},
+		Problems:       []string{},
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+	}
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/"); ok && role != "" {
+			host.Roles = append(host.Roles, role)
+		}
+	}
+	sort.Strings(host.Roles)
+	for _, condition := range node.Status.Conditions {
+		switch {
+		case condition.Type == corev1.NodeReady:
+			host.Ready = condition.Status == corev1.ConditionTrue
+			if !host.Ready {
+				host.Problems = append(host.Problems, fmt.Sprintf("not Ready: %s", condition.Message))
+			}
+		case condition.Status == corev1.ConditionTrue:
+			host.Problems = append(host.Problems, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+	if cpu, ok := node.Status.Capacity[corev1.ResourceCPU]; ok {
+		host.CPU = cpu.String()
+	}
+	if memory, ok := node.Status.Capacity[corev1.ResourceMemory]; ok {
+		host.Memory = memory.String()
+	}
+	if cpu, ok := node.Status.Allocatable[corev1.ResourceCPU]; ok {
+		host.AllocatableCPU = cpu.String()
+	}
+	if memory, ok := node.Status.Allocatable[corev1.ResourceMemory]; ok {
+		host.AllocatableMemory = memory.String()
+	}
+
+	return host
+}
@@ -0,0 +1,60 @@
+package harvester
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func fakeNode(name string, labels map[string]string, annotations map[string]string, fields map[string]any) *unstructured.Unstructured {
+	obj := fakeObject("v1", "Node", "", name, fields)
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+
+	return obj
+}
+
+func TestGetHarvesterHosts(t *testing.T) {
+	c, _ := newFakeClient(
+		fakeManagementCluster("c-abc12", "harvester-1", harvesterProvider, nil),
+		fakeNode("harvester-node-1", map[string]string{"node-role.kubernetes.io/control-plane": "true", "node-role.kubernetes.io/etcd": "true"}, nil, map[string]any{
+			"status": map[string]any{
+				"capacity":    map[string]any{"cpu": "16", "memory": "65536Mi"},
+				"allocatable": map[string]any{"cpu": "15", "memory": "64000Mi"},
+				"nodeInfo":    map[string]any{"kubeletVersion": "v1.29.9+rke2r1"},
+				"conditions": []any{
+					map[string]any{"type": "Ready", "status": "True"},
+					map[string]any{"type": "MemoryPressure", "status": "True", "message": "kubelet has insufficient memory available"},
+				},
+			},
+		}),
+		fakeNode("harvester-node-2", nil, map[string]string{maintainStatusAnn: "running"}, map[string]any{
+			"spec": map[string]any{"unschedulable": true},
+			"status": map[string]any{
+				"conditions": []any{map[string]any{"type": "Ready", "status": "False", "message": "kubelet stopped posting node status"}},
+			},
+		}),
+		fakeObject("kubevirt.io/v1", "VirtualMachineInstance", "default", "web", map[string]any{
+			"status": map[string]any{"nodeName": "harvester-node-1"},
+		}),
+		fakeObject("kubevirt.io/v1", "VirtualMachineInstance", "default", "db", map[string]any{
+			"status": map[string]any{"nodeName": "harvester-node-1"},
+		}),
+	)
+	tools := Tools{client: c}
+
+	result, _, err := tools.getHarvesterHosts(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), getHarvesterHostsParams{Cluster: "harvester-1"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "harvester-node-1", "ready": true, "cordoned": false, "roles": ["control-plane", "etcd"],
+			"problems": ["MemoryPressure: kubelet has insufficient memory available"],
+			"cpu": "16", "memory": "64Gi", "allocatableCPU": "15", "allocatableMemory": "64000Mi", "kubeletVersion": "v1.29.9+rke2r1", "vms": 2},
+		{"name": "harvester-node-2", "ready": false, "cordoned": true, "maintenance": "running", "roles": [],
+			"problems": ["not Ready: kubelet stopped posting node status"], "vms": 0}
+	]`, result.Content[0].(*mcp.TextContent).Text)
+}
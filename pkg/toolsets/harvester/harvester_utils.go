@@ -0,0 +1,131 @@
+package harvester
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	localCluster = "local"
+	// providerLabel is the label of the management clusters with their provider, harvester for the Harvester clusters.
+	providerLabel     = "provider.cattle.io"
+	harvesterProvider = "harvester"
+
+	cloudCredentialsNamespace = "cattle-global-data"
+	cloudCredentialNameAnn    = "field.cattle.io/name"
+	// credentialClusterIDKey is the key of the data of the Harvester cloud credentials with the ID of their cluster.
+	credentialClusterIDKey = "harvestercredentialConfig-clusterId"
+)
+
+// cloudCredential is a Rancher cloud credential, without its secret data.
+type cloudCredential struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// harvesterClusterID returns the ID of a Harvester cluster given its ID or its name. It fails for the clusters that
+// aren't Harvester clusters.
+func (t *Tools) harvesterClusterID(ctx context.Context, url string, token string, cluster string) (string, error) {
+	clusterID, err := t.client.GetClusterID(ctx, token, url, cluster)
+	if err != nil {
+		return "", err
+	}
+	managementCluster, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: localCluster,
+		Kind:    converter.ManagementClusterResourceKind,
+		Name:    clusterID,
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster %s: %w", cluster, err)
+	}
+	if managementCluster.GetLabels()[providerLabel] != harvesterProvider {
+		return "", fmt.Errorf("cluster %s is not a Harvester cluster", cluster)
+	}
+
+	return clusterID, nil
+}
+
+// harvesterCredentials returns the Harvester cloud credentials indexed by the ID of their Harvester cluster.
+func (t *Tools) harvesterCredentials(ctx context.Context, url string, token string) (map[string][]cloudCredential, error) {
+	secrets, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   localCluster,
+		Kind:      "secret",
+		Namespace: cloudCredentialsNamespace,
+		URL:       url,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the cloud credentials: %w", err)
+	}
+	credentials := map[string][]cloudCredential{}
+	for _, secret := range secrets {
+		clusterID := credentialClusterID(secret)
+		if clusterID == "" {
+			continue
+		}
+		credentials[clusterID] = append(credentials[clusterID], cloudCredential{
+			ID:   secret.GetNamespace() + ":" + secret.GetName(),
+			Name: secret.GetAnnotations()[cloudCredentialNameAnn],
+		})
+	}
+
+	return credentials, nil
+}
+
+// getCloudCredential returns the Secret of a cloud credential given its ID (e.g. 'cattle-global-data:cc-abc12' or
+// 'cc-abc12') or its name.
+func (t *Tools) getCloudCredential(ctx context.Context, url string, token string, credential string) (*unstructured.Unstructured, error) {
+	secret, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   localCluster,
+		Kind:      "secret",
+		Namespace: cloudCredentialsNamespace,
+		Name:      strings.TrimPrefix(credential, cloudCredentialsNamespace+":"),
+		URL:       url,
+		Token:     token,
+	})
+	if err == nil {
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	secrets, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   localCluster,
+		Kind:      "secret",
+		Namespace: cloudCredentialsNamespace,
+		URL:       url,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if secret.GetAnnotations()[cloudCredentialNameAnn] == credential {
+			return secret, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cloud credential %s not found", credential)
+}
+
+// credentialClusterID returns the ID of the Harvester cluster of a cloud credential, empty if it isn't a Harvester
+// cloud credential.
+func credentialClusterID(secret *unstructured.Unstructured) string {
+	value, _, _ := unstructured.NestedString(secret.Object, "data", credentialClusterIDKey)
+	clusterID, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+
+	return string(clusterID)
+}
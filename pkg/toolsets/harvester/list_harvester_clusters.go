@@ -0,0 +1,86 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type listHarvesterClustersParams struct{}
+
+// harvesterCluster is a Harvester cluster registered in Rancher, with the cloud credentials used to create clusters
+// in it.
+type harvesterCluster struct {
+	ID                string            `json:"id"`
+	DisplayName       string            `json:"displayName,omitempty"`
+	Ready             bool              `json:"ready"`
+	KubernetesVersion string            `json:"kubernetesVersion,omitempty"`
+	Hosts             int64             `json:"hosts"`
+	CloudCredentials  []cloudCredential `json:"cloudCredentials"`
+}
+
+// listHarvesterClusters lists the management clusters of the Harvester provider with their cloud credentials.
+func (t *Tools) listHarvesterClusters(ctx context.Context, toolReq *mcp.CallToolRequest, _ listHarvesterClustersParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listHarvesterClusters called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusters, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       localCluster,
+		Kind:          converter.ManagementClusterResourceKind,
+		LabelSelector: providerLabel + "=" + harvesterProvider,
+		URL:           url,
+		Token:         token,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list clusters", zap.String("tool", "listHarvesterClusters"), zap.Error(err))
+		return nil, nil, err
+	}
+	credentials, err := t.harvesterCredentials(ctx, url, token)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list cloud credentials", zap.String("tool", "listHarvesterClusters"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := []harvesterCluster{}
+	for _, obj := range clusters {
+		cluster := harvesterCluster{
+			ID:               obj.GetName(),
+			CloudCredentials: credentials[obj.GetName()],
+		}
+		if cluster.CloudCredentials == nil {
+			cluster.CloudCredentials = []cloudCredential{}
+		}
+		sort.Slice(cluster.CloudCredentials, func(i, j int) bool { return cluster.CloudCredentials[i].ID < cluster.CloudCredentials[j].ID })
+		cluster.DisplayName, _, _ = unstructured.NestedString(obj.Object, "spec", "displayName")
+		cluster.KubernetesVersion, _, _ = unstructured.NestedString(obj.Object, "status", "version", "gitVersion")
+		cluster.Hosts, _, _ = unstructured.NestedInt64(obj.Object, "status", "nodeCount")
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, item := range conditions {
+			condition, _ := item.(map[string]any)
+			if condition["type"] == "Ready" && condition["status"] == "True" {
+				cluster.Ready = true
+			}
+		}
+		result = append(result, cluster)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listHarvesterClusters"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
@@ -0,0 +1,36 @@
+package harvester
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListHarvesterClusters(t *testing.T) {
+	c, _ := newFakeClient(
+		fakeManagementCluster("c-abc12", "harvester-1", harvesterProvider, map[string]any{
+			"nodeCount":  int64(3),
+			"version":    map[string]any{"gitVersion": "v1.29.9+rke2r1"},
+			"conditions": []any{map[string]any{"type": "Ready", "status": "True"}},
+		}),
+		fakeManagementCluster("c-def34", "harvester-2", harvesterProvider, map[string]any{
+			"conditions": []any{map[string]any{"type": "Ready", "status": "False"}},
+		}),
+		fakeManagementCluster("c-m-ghi56", "downstream", "rke2", nil),
+		fakeCloudCredential("cc-abc12", "harvester-1-creds", harvesterCredentialData("c-abc12")),
+		fakeCloudCredential("cc-xyz98", "aws-creds", map[string]any{"amazonec2credentialConfig-accessKey": "QUtJQQ=="}),
+	)
+	tools := Tools{client: c}
+
+	result, _, err := tools.listHarvesterClusters(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), listHarvesterClustersParams{})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": "c-abc12", "displayName": "harvester-1", "ready": true, "kubernetesVersion": "v1.29.9+rke2r1", "hosts": 3,
+			"cloudCredentials": [{"id": "cattle-global-data:cc-abc12", "name": "harvester-1-creds"}]},
+		{"id": "c-def34", "displayName": "harvester-2", "ready": false, "hosts": 0, "cloudCredentials": []}
+	]`, result.Content[0].(*mcp.TextContent).Text)
+}
@@ -0,0 +1,128 @@
+package harvester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// creatorLabel is the label of the VMs with the tool that created them, the Harvester node driver for the
+	// machines provisioned by Rancher.
+	creatorLabel         = "harvesterhci.io/creator"
+	rancherMachineDriver = "docker-machine-driver-harvester"
+)
+
+type listHarvesterVMsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the ID or the name of the Harvester cluster"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only list the VMs of this namespace, all namespaces if not provided"`
+}
+
+// harvesterVM is a KubeVirt VirtualMachine of a Harvester cluster, with the host and the IP addresses of its running
+// instance.
+type harvesterVM struct {
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status,omitempty"`
+	Ready       bool     `json:"ready"`
+	CPUs        int64    `json:"cpus,omitempty"`
+	Memory      string   `json:"memory,omitempty"`
+	Host        string   `json:"host,omitempty"`
+	IPAddresses []string `json:"ipAddresses"`
+	// RancherMachine is set for the VMs provisioned by Rancher as machines of a cluster.
+	RancherMachine bool `json:"rancherMachine"`
+}
+
+// listHarvesterVMs lists the VirtualMachines of a Harvester cluster, completed by their VirtualMachineInstances.
+func (t *Tools) listHarvesterVMs(ctx context.Context, toolReq *mcp.CallToolRequest, params listHarvesterVMsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listHarvesterVMs called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.harvesterClusterID(ctx, url, token, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get Harvester cluster", zap.String("tool", "listHarvesterVMs"), zap.Error(err))
+		return nil, nil, err
+	}
+	vms, err := t.client.GetResources(ctx, client.ListParams{Cluster: clusterID, Kind: "virtualmachine", Namespace: params.Namespace, URL: url, Token: token})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list virtual machines", zap.String("tool", "listHarvesterVMs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list the VMs of cluster %s: %w", params.Cluster, err)
+	}
+	instances, err := t.client.GetResources(ctx, client.ListParams{Cluster: clusterID, Kind: "virtualmachineinstance", Namespace: params.Namespace, URL: url, Token: token})
+	if err != nil && !apierrors.IsNotFound(err) {
+		middleware.Logger(ctx).Error("failed to list virtual machine instances", zap.String("tool", "listHarvesterVMs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list the VM instances of cluster %s: %w", params.Cluster, err)
+	}
+	instancesByName := map[string]*unstructured.Unstructured{}
+	for _, instance := range instances {
+		instancesByName[instance.GetNamespace()+"/"+instance.GetName()] = instance
+	}
+
+	result := []harvesterVM{}
+	for _, vm := range vms {
+		result = append(result, newHarvesterVM(vm, instancesByName[vm.GetNamespace()+"/"+vm.GetName()]))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listHarvesterVMs"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newHarvesterVM returns the summary of a VM. The instance is nil if the VM isn't running.
+func newHarvesterVM(vm *unstructured.Unstructured, instance *unstructured.Unstructured) harvesterVM {
+	result := harvesterVM{
+		Namespace:      vm.GetNamespace(),
+		Name:           vm.GetName(),
+		IPAddresses:    []string{},
+		RancherMachine: vm.GetLabels()[creatorLabel] == rancherMachineDriver,
+	}
+	result.Status, _, _ = unstructured.NestedString(vm.Object, "status", "printableStatus")
+	result.Ready, _, _ = unstructured.NestedBool(vm.Object, "status", "ready")
+
+	// the CPUs are the product of the sockets, cores and threads, 1 by default
+	result.CPUs = 1
+	for _, field := range []string{"sockets", "cores", "threads"} {
+		if value, ok, _ := unstructured.NestedInt64(vm.Object, "spec", "template", "spec", "domain", "cpu", field); ok && value > 0 {
+			result.CPUs *= value
+		}
+	}
+	result.Memory, _, _ = unstructured.NestedString(vm.Object, "spec", "template", "spec", "domain", "memory", "guest")
+	if result.Memory == "" {
+		result.Memory, _, _ = unstructured.NestedString(vm.Object, "spec", "template", "spec", "domain", "resources", "limits", "memory")
+	}
+
+	if instance != nil {
+		result.Host, _, _ = unstructured.NestedString(instance.Object, "status", "nodeName")
+		interfaces, _, _ := unstructured.NestedSlice(instance.Object, "status", "interfaces")
+		for _, item := range interfaces {
+			if iface, ok := item.(map[string]any); ok {
+				if ip, _ := iface["ipAddress"].(string); ip != "" {
+					result.IPAddresses = append(result.IPAddresses, ip)
+				}
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,90 @@
+package harvester
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func fakeVM(namespace string, name string, labels map[string]string, fields map[string]any) *unstructured.Unstructured {
+	obj := fakeObject("kubevirt.io/v1", "VirtualMachine", namespace, name, fields)
+	obj.SetLabels(labels)
+
+	return obj
+}
+
+func fakeVMObjects() []runtime.Object {
+	return []runtime.Object{
+		fakeManagementCluster("c-abc12", "harvester-1", harvesterProvider, nil),
+		fakeManagementCluster("c-m-ghi56", "downstream", "rke2", nil),
+		fakeVM("default", "web", nil, map[string]any{
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"domain": map[string]any{
+				"cpu":    map[string]any{"sockets": int64(1), "cores": int64(2), "threads": int64(1)},
+				"memory": map[string]any{"guest": "3996Mi"},
+			}}}},
+			"status": map[string]any{"printableStatus": "Running", "ready": true},
+		}),
+		fakeVM("fleet", "cluster1-pool1-abcde", map[string]string{creatorLabel: rancherMachineDriver}, map[string]any{
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"domain": map[string]any{
+				"resources": map[string]any{"limits": map[string]any{"memory": "4Gi"}},
+			}}}},
+			"status": map[string]any{"printableStatus": "Stopped"},
+		}),
+		fakeObject("kubevirt.io/v1", "VirtualMachineInstance", "default", "web", map[string]any{
+			"status": map[string]any{
+				"nodeName":   "harvester-node-1",
+				"interfaces": []any{map[string]any{"ipAddress": "10.0.0.10"}, map[string]any{"name": "default"}},
+			},
+		}),
+	}
+}
+
+func TestListHarvesterVMs(t *testing.T) {
+	tests := map[string]struct {
+		params         listHarvesterVMsParams
+		expectedResult string
+		expectedError  string
+	}{
+		"all namespaces": {
+			params: listHarvesterVMsParams{Cluster: "harvester-1"},
+			expectedResult: `[
+				{"namespace": "default", "name": "web", "status": "Running", "ready": true, "cpus": 2, "memory": "3996Mi",
+					"host": "harvester-node-1", "ipAddresses": ["10.0.0.10"], "rancherMachine": false},
+				{"namespace": "fleet", "name": "cluster1-pool1-abcde", "status": "Stopped", "ready": false, "cpus": 1, "memory": "4Gi",
+					"ipAddresses": [], "rancherMachine": true}
+			]`,
+		},
+		"namespace": {
+			params: listHarvesterVMsParams{Cluster: "c-abc12", Namespace: "fleet"},
+			expectedResult: `[
+				{"namespace": "fleet", "name": "cluster1-pool1-abcde", "status": "Stopped", "ready": false, "cpus": 1, "memory": "4Gi",
+					"ipAddresses": [], "rancherMachine": true}
+			]`,
+		},
+		"not a Harvester cluster": {
+			params:        listHarvesterVMsParams{Cluster: "c-m-ghi56"},
+			expectedError: "cluster c-m-ghi56 is not a Harvester cluster",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(fakeVMObjects()...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.listHarvesterVMs(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package harvester
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+)
+
+const (
+	toolsSet    = "harvester"
+	toolsSetAnn = "toolset"
+	urlHeader   = client.URLHeader
+)
+
+// Tools contains all tools for the MCP server
+type Tools struct {
+	client *client.Client
+	// ReadOnly disables the tools that create clusters in Harvester.
+	ReadOnly bool
+}
+
+// NewTools creates and returns a new Tools instance.
+func NewTools(client *client.Client) *Tools {
+	return &Tools{
+		client: client,
+	}
+}
+
+// Register adds the tools of the harvester toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	tools.ReadOnly = deps.ReadOnly
+	tools.AddTools(mcpServer)
+}
+
+// AddTools registers all Harvester tools with the provided MCP server.
+// Each tool is configured with metadata identifying it as part of the harvester toolset.
+func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listHarvesterClusters",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the Harvester HCI clusters registered in Rancher, with their readiness, Kubernetes version and number of hosts,
+		and the Harvester cloud credentials that can be used to create RKE2 clusters in each.
+
+		Returns:
+		The Harvester clusters with their ID, display name and cloud credentials.`},
+		response.WithStructuredErrors(t.listHarvesterClusters),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listHarvesterVMs",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the virtual machines of a Harvester cluster with their status, CPUs, memory, the host running them and their IP addresses.
+		The VMs of the RKE2 clusters provisioned by Rancher are flagged.
+		Parameters:
+		cluster (string): The ID or the name of the Harvester cluster.
+		namespace (string, optional): Only list the VMs of this namespace. All namespaces if not provided.`},
+		response.WithStructuredErrors(t.listHarvesterVMs),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getHarvesterHosts",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the status of the hosts of a Harvester cluster: whether they are Ready, cordoned or in maintenance mode, their pressure conditions,
+		their CPU and memory capacity and the number of VMs they run.
+		Parameters:
+		cluster (string): The ID or the name of the Harvester cluster.`},
+		response.WithStructuredErrors(t.getHarvesterHosts),
+	)
+
+	if t.ReadOnly {
+		return
+	}
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createHarvesterCluster",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Creates an RKE2 cluster whose machines are VMs provisioned by Rancher in a Harvester cluster, with a Harvester machine config for each machine pool.
+		The image and the network of the VMs are checked in the Harvester cluster of the cloud credential. Use listHarvesterClusters to find the cloud credentials.
+		Ask for confirmation before creating the cluster, as the VMs use the resources of the Harvester hosts.
+		Parameters:
+		name (string): The name of the cluster.
+		namespace (string, optional): The namespace of the cluster. Defaults to 'fleet-default'.
+		kubernetesVersion (string): The RKE2 version of the cluster (e.g., 'v1.31.4+rke2r1').
+		cloudCredential (string): The ID (e.g., 'cattle-global-data:cc-abc12') or the name of the Harvester cloud credential.
+		vmNamespace (string): The namespace of the VMs in the Harvester cluster.
+		image (string): The Harvester image of the VMs, as 'namespace/name' (e.g., 'default/image-ubuntu').
+		network (string): The Harvester VM network of the VMs, as 'namespace/name' (e.g., 'default/vlan1').
+		sshUser (string): The SSH user of the image (e.g., 'ubuntu').
+		cpuCount (int, optional): The number of CPUs of the VMs. Defaults to 2.
+		memorySize (int, optional): The memory of the VMs in GiB. Defaults to 4.
+		diskSize (int, optional): The size of the root disk of the VMs in GiB. Defaults to 40.
+		machinePools (array): The machine pools, each with a name, a quantity, roles (etcd, controlplane and/or worker) and optional cpuCount, memorySize and diskSize overriding the ones of the cluster.
+
+		Returns:
+		The created cluster and machine configs.`},
+		response.WithStructuredErrors(t.createHarvesterCluster),
+	)
+}
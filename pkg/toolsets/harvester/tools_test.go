@@ -0,0 +1,122 @@
+package harvester
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const fakeToken = "fakeToken"
+
+func newFakeClient(objects ...runtime.Object) (*client.Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:                  "ClusterList",
+		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}:                "ClusterList",
+		{Group: "rke-machine-config.cattle.io", Version: "v1", Resource: "harvesterconfigs"}:  "HarvesterConfigList",
+		{Group: "", Version: "v1", Resource: "secrets"}:                                       "SecretList",
+		{Group: "", Version: "v1", Resource: "nodes"}:                                         "NodeList",
+		{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachines"}:                    "VirtualMachineList",
+		{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachineinstances"}:            "VirtualMachineInstanceList",
+		{Group: "harvesterhci.io", Version: "v1beta1", Resource: "virtualmachineimages"}:      "VirtualMachineImageList",
+		{Group: "k8s.cni.cncf.io", Version: "v1", Resource: "network-attachment-definitions"}: "NetworkAttachmentDefinitionList",
+	}, objects...)
+
+	c := client.NewClient(true)
+	c.DynClientCreator = func(inConfig *rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+
+	return c, fakeDynClient
+}
+
+func newCallToolRequest() *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {"https://localhost:8080"}}},
+	}
+}
+
+func fakeObject(apiVersion string, kind string, namespace string, name string, fields map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	for key, value := range fields {
+		obj.Object[key] = value
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+func fakeManagementCluster(id string, displayName string, provider string, status map[string]any) *unstructured.Unstructured {
+	obj := fakeObject("management.cattle.io/v3", "Cluster", "", id, map[string]any{
+		"spec":   map[string]any{"displayName": displayName},
+		"status": status,
+	})
+	obj.SetLabels(map[string]string{providerLabel: provider})
+
+	return obj
+}
+
+func fakeCloudCredential(name string, displayName string, data map[string]any) *unstructured.Unstructured {
+	obj := fakeObject("v1", "Secret", cloudCredentialsNamespace, name, map[string]any{"data": data})
+	obj.SetAnnotations(map[string]string{cloudCredentialNameAnn: displayName})
+
+	return obj
+}
+
+func harvesterCredentialData(clusterID string) map[string]any {
+	return map[string]any{credentialClusterIDKey: base64.StdEncoding.EncodeToString([]byte(clusterID))}
+}
+
+func TestAddTools(t *testing.T) {
+	tests := map[string]struct {
+		readOnly      bool
+		expectedTools []string
+	}{
+		"all tools": {
+			expectedTools: []string{"createHarvesterCluster", "getHarvesterHosts", "listHarvesterClusters", "listHarvesterVMs"},
+		},
+		"read-only": {
+			readOnly:      true,
+			expectedTools: []string{"getHarvesterHosts", "listHarvesterClusters", "listHarvesterVMs"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := NewTools(client.NewClient(true))
+			tools.ReadOnly = test.readOnly
+			mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+			tools.AddTools(mcpServer)
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+			require.NoError(t, err)
+			defer ss.Close()
+			cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+			require.NoError(t, err)
+			defer cs.Close()
+
+			toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+
+			require.NoError(t, err)
+			var names []string
+			for _, tool := range toolsResult.Tools {
+				names = append(names, tool.Name)
+				assert.Equal(t, toolsSet, tool.Meta[toolsSetAnn])
+			}
+			assert.Equal(t, test.expectedTools, names)
+		})
+	}
+}
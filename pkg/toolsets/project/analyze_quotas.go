@@ -0,0 +1,337 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+const defaultQuotaThreshold = 80
+
+var (
+	// exceededQuotaRE matches the error of a Pod creation refused because it exceeds a ResourceQuota, e.g.
+	// exceeded quota: compute, requested: limits.cpu=2, used: limits.cpu=3, limited: limits.cpu=4
+	exceededQuotaRE = regexp.MustCompile(`exceeded quota: ([^,]+), requested: (\S+), used: (\S+), limited: (\S+)`)
+	// missingQuotaLimitsRE matches the error of a Pod creation refused because it doesn't set the requests or limits
+	// constrained by a ResourceQuota, e.g. failed quota: compute: must specify limits.cpu for: nginx
+	missingQuotaLimitsRE = regexp.MustCompile(`failed quota: ([^:]+): must specify (.+)`)
+	// limitRangeRE matches the error of a Pod creation refused by a LimitRange, e.g.
+	// maximum cpu usage per Container is 1, but limit is 2
+	limitRangeRE = regexp.MustCompile(`(maximum|minimum) (\S+) usage per (\w+) is (\S+), but (\w+) is (\S+)`)
+)
+
+type analyzeQuotasParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster to check"`
+	Project   string `json:"project,omitempty" jsonschema:"the ID or display name of the project whose namespaces are checked"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace to check. Empty for all the namespaces of the project or the cluster"`
+	Threshold int64  `json:"threshold,omitempty" jsonschema:"percentage of a quota above which a namespace is flagged as near the quota. Defaults to 80"`
+}
+
+// quotaAnalysis is the result of analyzeQuotas.
+type quotaAnalysis struct {
+	Cluster   string `json:"cluster"`
+	Threshold int64  `json:"threshold"`
+	// Project is the usage of the quota of the whole Rancher Project, when the namespaces of a project are checked.
+	Project             *projectQuota     `json:"project,omitempty"`
+	NamespacesNearQuota []string          `json:"namespacesNearQuota"`
+	Namespaces          []namespaceQuotas `json:"namespaces"`
+}
+
+// projectQuota is the usage of the resource quota of a Rancher Project, shared by all its namespaces.
+type projectQuota struct {
+	ID          string          `json:"id"`
+	DisplayName string          `json:"displayName"`
+	Resources   []quotaResource `json:"resources,omitempty"`
+	// NamespaceDefault is the quota of each namespace of the project, unless overridden in the namespace.
+	NamespaceDefault map[string]string `json:"namespaceDefault,omitempty"`
+}
+
+// namespaceQuotas holds the ResourceQuotas and LimitRanges of a namespace, and the Pod creations they refused.
+type namespaceQuotas struct {
+	Namespace   string               `json:"namespace"`
+	Project     string               `json:"project,omitempty"`
+	NearQuota   bool                 `json:"nearQuota"`
+	Quotas      []resourceQuotaUsage `json:"quotas,omitempty"`
+	LimitRanges []limitRangeSummary  `json:"limitRanges,omitempty"`
+	Failures    []quotaFailure       `json:"failures,omitempty"`
+}
+
+type resourceQuotaUsage struct {
+	Name      string          `json:"name"`
+	Resources []quotaResource `json:"resources"`
+}
+
+// quotaResource is the usage of a resource limited by a quota.
+type quotaResource struct {
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	// Percent is the used amount as a percentage of the hard limit.
+	Percent   int64 `json:"percent"`
+	NearQuota bool  `json:"nearQuota,omitempty"`
+}
+
+// limitRangeSummary holds the constraints of a LimitRange for a type of object, e.g. Container.
+type limitRangeSummary struct {
+	Name                 string            `json:"name"`
+	Type                 string            `json:"type"`
+	Min                  map[string]string `json:"min,omitempty"`
+	Max                  map[string]string `json:"max,omitempty"`
+	Default              map[string]string `json:"default,omitempty"`
+	DefaultRequest       map[string]string `json:"defaultRequest,omitempty"`
+	MaxLimitRequestRatio map[string]string `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// quotaFailure is an event of an object that failed to create Pods because of a ResourceQuota or a LimitRange.
+type quotaFailure struct {
+	Object      string `json:"object"`
+	Message     string `json:"message"`
+	Count       int32  `json:"count,omitempty"`
+	LastSeen    string `json:"lastSeen,omitempty"`
+	Explanation string `json:"explanation"`
+}
+
+// analyzeQuotas reports the usage of the ResourceQuotas and the constraints of the LimitRanges of the namespaces of a
+// cluster or a Project, flags the namespaces near their quota and explains the Pod creations refused by them.
+func (t *Tools) analyzeQuotas(ctx context.Context, toolReq *mcp.CallToolRequest, params analyzeQuotasParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("analyzeQuotas called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	threshold := params.Threshold
+	if threshold <= 0 {
+		threshold = defaultQuotaThreshold
+	}
+	clientSet, err := t.client.CreateClientSet(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create clientset", zap.String("tool", "analyzeQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	analysis := quotaAnalysis{Cluster: params.Cluster, Threshold: threshold, NamespacesNearQuota: []string{}, Namespaces: []namespaceQuotas{}}
+	var projectID string
+	if params.Project != "" {
+		clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "analyzeQuotas"), zap.Error(err))
+			return nil, nil, err
+		}
+		project, err := t.findProject(ctx, url, token, clusterID, params.Project)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to find project", zap.String("tool", "analyzeQuotas"), zap.Error(err))
+			return nil, nil, err
+		}
+		projectID = project.GetName()
+		analysis.Project = newProjectQuota(project, threshold)
+	}
+	namespaces, err := quotaNamespaces(ctx, clientSet, params.Namespace, projectID)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get namespaces", zap.String("tool", "analyzeQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	quotas, limitRanges, events, err := listQuotaObjects(ctx, clientSet, params.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list quotas", zap.String("tool", "analyzeQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+	for _, namespace := range namespaces {
+		n := newNamespaceQuotas(&namespace, quotas, limitRanges, events, threshold)
+		// namespaces without any quota or limit range aren't constrained
+		if len(n.Quotas) == 0 && len(n.LimitRanges) == 0 && len(n.Failures) == 0 {
+			continue
+		}
+		if n.NearQuota {
+			analysis.NamespacesNearQuota = append(analysis.NamespacesNearQuota, n.Namespace)
+		}
+		analysis.Namespaces = append(analysis.Namespaces, n)
+	}
+
+	response, err := json.Marshal(analysis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "analyzeQuotas"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// quotaNamespaces returns the namespace, or all the namespaces of the project or the cluster if it's empty.
+func quotaNamespaces(ctx context.Context, clientSet kubernetes.Interface, namespace string, projectID string) ([]corev1.Namespace, error) {
+	if namespace != "" {
+		ns, err := clientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if projectID != "" && ns.Labels[projectIDKey] != projectID {
+			return nil, fmt.Errorf("namespace %s doesn't belong to project %s", namespace, projectID)
+		}
+		return []corev1.Namespace{*ns}, nil
+	}
+
+	listOptions := metav1.ListOptions{}
+	if projectID != "" {
+		listOptions.LabelSelector = projectIDKey + "=" + projectID
+	}
+	namespaces, err := clientSet.CoreV1().Namespaces().List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return namespaces.Items, nil
+}
+
+// listQuotaObjects returns the ResourceQuotas, the LimitRanges and the events of the namespace, or of all namespaces
+// if it's empty.
+func listQuotaObjects(ctx context.Context, clientSet kubernetes.Interface, namespace string) ([]corev1.ResourceQuota, []corev1.LimitRange, []corev1.Event, error) {
+	quotas, err := clientSet.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	limitRanges, err := clientSet.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+	events, err := clientSet.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=Warning"})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	return quotas.Items, limitRanges.Items, events.Items, nil
+}
+
+func newNamespaceQuotas(namespace *corev1.Namespace, quotas []corev1.ResourceQuota, limitRanges []corev1.LimitRange, events []corev1.Event, threshold int64) namespaceQuotas {
+	n := namespaceQuotas{Namespace: namespace.Name, Project: namespace.Labels[projectIDKey]}
+	for _, quota := range quotas {
+		if quota.Namespace != namespace.Name {
+			continue
+		}
+		usage := resourceQuotaUsage{Name: quota.Name, Resources: []quotaResource{}}
+		for _, name := range slices.Sorted(maps.Keys(quota.Status.Hard)) {
+			hard := quota.Status.Hard[name]
+			used := quota.Status.Used[name]
+			r := newQuotaResource(string(name), hard, used, threshold)
+			n.NearQuota = n.NearQuota || r.NearQuota
+			usage.Resources = append(usage.Resources, r)
+		}
+		n.Quotas = append(n.Quotas, usage)
+	}
+	for _, limitRange := range limitRanges {
+		if limitRange.Namespace != namespace.Name {
+			continue
+		}
+		for _, limit := range limitRange.Spec.Limits {
+			n.LimitRanges = append(n.LimitRanges, limitRangeSummary{
+				Name:                 limitRange.Name,
+				Type:                 string(limit.Type),
+				Min:                  resourceListToMap(limit.Min),
+				Max:                  resourceListToMap(limit.Max),
+				Default:              resourceListToMap(limit.Default),
+				DefaultRequest:       resourceListToMap(limit.DefaultRequest),
+				MaxLimitRequestRatio: resourceListToMap(limit.MaxLimitRequestRatio),
+			})
+		}
+	}
+	for _, event := range events {
+		if event.Namespace != namespace.Name {
+			continue
+		}
+		explanation := explainQuotaFailure(event.Message)
+		if explanation == "" {
+			continue
+		}
+		failure := quotaFailure{
+			Object:      strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+			Message:     event.Message,
+			Count:       event.Count,
+			Explanation: explanation,
+		}
+		if !event.LastTimestamp.IsZero() {
+			failure.LastSeen = event.LastTimestamp.UTC().Format(time.RFC3339)
+		}
+		n.Failures = append(n.Failures, failure)
+	}
+
+	return n
+}
+
+func newQuotaResource(name string, hard resource.Quantity, used resource.Quantity, threshold int64) quotaResource {
+	r := quotaResource{Resource: name, Hard: hard.String(), Used: used.String()}
+	if hard.MilliValue() > 0 {
+		r.Percent = used.MilliValue() * 100 / hard.MilliValue()
+	} else if used.MilliValue() > 0 {
+		r.Percent = 100
+	}
+	r.NearQuota = r.Percent >= threshold
+
+	return r
+}
+
+// newProjectQuota returns the usage of the resource quota of the Rancher Project. Rancher stores the limits of the
+// project in spec.resourceQuota.limit and the sum of the quotas of its namespaces in spec.resourceQuota.usedLimit.
+func newProjectQuota(project *unstructured.Unstructured, threshold int64) *projectQuota {
+	displayName, _, _ := unstructured.NestedString(project.Object, "spec", "displayName")
+	p := &projectQuota{ID: project.GetName(), DisplayName: displayName}
+	limits, _, _ := unstructured.NestedStringMap(project.Object, "spec", "resourceQuota", "limit")
+	usedLimits, _, _ := unstructured.NestedStringMap(project.Object, "spec", "resourceQuota", "usedLimit")
+	for _, name := range slices.Sorted(maps.Keys(limits)) {
+		hard, err := resource.ParseQuantity(limits[name])
+		if err != nil {
+			continue
+		}
+		// a limit not used by any namespace yet has no used limit
+		used, _ := resource.ParseQuantity(usedLimits[name])
+		p.Resources = append(p.Resources, newQuotaResource(name, hard, used, threshold))
+	}
+	p.NamespaceDefault, _, _ = unstructured.NestedStringMap(project.Object, "spec", "namespaceDefaultResourceQuota", "limit")
+
+	return p
+}
+
+// explainQuotaFailure returns why a Pod creation was refused by a ResourceQuota or a LimitRange, or an empty string if
+// the message isn't caused by them.
+func explainQuotaFailure(message string) string {
+	if match := exceededQuotaRE.FindStringSubmatch(message); match != nil {
+		return fmt.Sprintf("The ResourceQuota %s is exhausted: the Pod requested %s but %s is already used of %s. "+
+			"Free up resources in the namespace or raise the quota (for Rancher Projects, the namespace quota of the project).", match[1], match[2], match[3], match[4])
+	}
+	if match := missingQuotaLimitsRE.FindStringSubmatch(message); match != nil {
+		return fmt.Sprintf("The ResourceQuota %s limits resources the Pod doesn't set: it must specify %s. "+
+			"Set them in the containers, or add a LimitRange with default requests and limits to the namespace.", match[1], match[2])
+	}
+	if match := limitRangeRE.FindStringSubmatch(message); match != nil {
+		return fmt.Sprintf("A LimitRange of the namespace sets the %s %s of each %s to %s, but the %s is %s. "+
+			"Change the %s of the Pod or the LimitRange.", match[1], match[2], match[3], match[4], match[5], match[6], match[5])
+	}
+
+	return ""
+}
+
+// resourceListToMap returns the quantities of the resource list as strings.
+func resourceListToMap(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(list))
+	for name, quantity := range list {
+		m[string(name)] = quantity.String()
+	}
+
+	return m
+}
@@ -0,0 +1,165 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestAnalyzeQuotas(t *testing.T) {
+	quotaSpec := map[string]any{
+		"resourceQuota": map[string]any{
+			"limit":     map[string]any{"limitsCpu": "4000m", "pods": "50"},
+			"usedLimit": map[string]any{"limitsCpu": "3500m"},
+		},
+		"namespaceDefaultResourceQuota": map[string]any{
+			"limit": map[string]any{"limitsCpu": "1000m"},
+		},
+	}
+	computeQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "payments"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("1"), corev1.ResourcePods: resource.MustParse("10")},
+			Used: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("900m"), corev1.ResourcePods: resource.MustParse("3")},
+		},
+	}
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "payments"},
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type:    corev1.LimitTypeContainer,
+			Max:     corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			Default: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+		}}},
+	}
+	exceededEvent := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "api-7d9c6b5f4.1", Namespace: "payments"},
+		InvolvedObject: corev1.ObjectReference{Kind: "ReplicaSet", Name: "api-7d9c6b5f4"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "FailedCreate",
+		Message:        `Error creating: pods "api-7d9c6b5f4-x2x4k" is forbidden: exceeded quota: compute, requested: limits.cpu=500m, used: limits.cpu=900m, limited: limits.cpu=1`,
+		Count:          12,
+		LastTimestamp:  metav1.NewTime(time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)),
+	}
+	unrelatedEvent := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "api-7d9c6b5f4-abcde.1", Namespace: "payments"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "api-7d9c6b5f4-abcde"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+	}
+
+	tests := map[string]struct {
+		params         analyzeQuotasParams
+		objects        []runtime.Object
+		expectedResult string
+		expectedError  string
+	}{
+		"project namespaces": {
+			params:  analyzeQuotasParams{Cluster: "local", Project: "Default"},
+			objects: []runtime.Object{fakeNamespace("payments", "p-abc12"), fakeNamespace("orders", "p-abc12"), fakeNamespace("other", ""), computeQuota, limitRange, exceededEvent, unrelatedEvent},
+			expectedResult: `{
+				"cluster": "local",
+				"threshold": 80,
+				"project": {
+					"id": "p-abc12",
+					"displayName": "Default",
+					"resources": [
+						{"resource": "limitsCpu", "hard": "4", "used": "3500m", "percent": 87, "nearQuota": true},
+						{"resource": "pods", "hard": "50", "used": "0", "percent": 0}
+					],
+					"namespaceDefault": {"limitsCpu": "1000m"}
+				},
+				"namespacesNearQuota": ["payments"],
+				"namespaces": [{
+					"namespace": "payments",
+					"project": "p-abc12",
+					"nearQuota": true,
+					"quotas": [{"name": "compute", "resources": [
+						{"resource": "limits.cpu", "hard": "1", "used": "900m", "percent": 90, "nearQuota": true},
+						{"resource": "pods", "hard": "10", "used": "3", "percent": 30}
+					]}],
+					"limitRanges": [{"name": "defaults", "type": "Container", "max": {"cpu": "1"}, "default": {"cpu": "500m"}}],
+					"failures": [{
+						"object": "replicaset/api-7d9c6b5f4",
+						"message": "Error creating: pods \"api-7d9c6b5f4-x2x4k\" is forbidden: exceeded quota: compute, requested: limits.cpu=500m, used: limits.cpu=900m, limited: limits.cpu=1",
+						"count": 12,
+						"lastSeen": "2025-05-01T10:00:00Z",
+						"explanation": "The ResourceQuota compute is exhausted: the Pod requested limits.cpu=500m but limits.cpu=900m is already used of limits.cpu=1. Free up resources in the namespace or raise the quota (for Rancher Projects, the namespace quota of the project)."
+					}]
+				}]
+			}`,
+		},
+		"namespace with higher threshold": {
+			params:         analyzeQuotasParams{Cluster: "local", Namespace: "payments", Threshold: 95},
+			objects:        []runtime.Object{fakeNamespace("payments", ""), computeQuota},
+			expectedResult: `{"cluster": "local", "threshold": 95, "namespacesNearQuota": [], "namespaces": [{"namespace": "payments", "nearQuota": false, "quotas": [{"name": "compute", "resources": [{"resource": "limits.cpu", "hard": "1", "used": "900m", "percent": 90}, {"resource": "pods", "hard": "10", "used": "3", "percent": 30}]}]}]}`,
+		},
+		"namespace not in project": {
+			params:        analyzeQuotasParams{Cluster: "local", Project: "Default", Namespace: "other"},
+			objects:       []runtime.Object{fakeNamespace("other", "")},
+			expectedError: "namespace other doesn't belong to project p-abc12",
+		},
+		"project not found": {
+			params:        analyzeQuotasParams{Cluster: "local", Project: "missing"},
+			expectedError: "project missing not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(fakeProject("p-abc12", "Default", quotaSpec))
+			clientset := fake.NewClientset(test.objects...)
+			c.ClientSetCreator = func(inConfig *rest.Config) (kubernetes.Interface, error) {
+				return clientset, nil
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.analyzeQuotas(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestExplainQuotaFailure(t *testing.T) {
+	tests := map[string]struct {
+		message             string
+		expectedExplanation string
+	}{
+		"missing limits": {
+			message:             `Error creating: pods "api-x2x4k" is forbidden: failed quota: compute: must specify limits.cpu for: api`,
+			expectedExplanation: "The ResourceQuota compute limits resources the Pod doesn't set: it must specify limits.cpu for: api. Set them in the containers, or add a LimitRange with default requests and limits to the namespace.",
+		},
+		"limit range maximum": {
+			message:             `Error creating: pods "api-x2x4k" is forbidden: maximum cpu usage per Container is 1, but limit is 2`,
+			expectedExplanation: "A LimitRange of the namespace sets the maximum cpu of each Container to 1, but the limit is 2. Change the limit of the Pod or the LimitRange.",
+		},
+		"unrelated": {
+			message: "Back-off restarting failed container",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedExplanation, explainQuotaFailure(test.message))
+		})
+	}
+}
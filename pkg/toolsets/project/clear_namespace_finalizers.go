@@ -0,0 +1,92 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clearNamespaceFinalizersParams specifies the parameters needed to clear the finalizers of a namespace.
+type clearNamespaceFinalizersParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the namespace"`
+	Name    string `json:"name" jsonschema:"the name of the namespace"`
+	Confirm string `json:"confirm" jsonschema:"the name of the namespace again, to confirm the resources left in it can be orphaned"`
+}
+
+// clearedFinalizers is the result of clearing the finalizers of a namespace, with the resources orphaned by it.
+type clearedFinalizers struct {
+	Namespace         string              `json:"namespace"`
+	RemovedFinalizers []string            `json:"removedFinalizers"`
+	OrphanedResources []remainingResource `json:"orphanedResources,omitempty"`
+	DiscoveryFailures []string            `json:"discoveryFailures,omitempty"`
+}
+
+// clearNamespaceFinalizers removes the finalizers of a namespace stuck in Terminating, so it's deleted without waiting
+// for its resources to be cleaned up. Only namespaces already being deleted can be cleared, and the name of the
+// namespace must be repeated in confirm, since the resources left in it are orphaned in etcd.
+func (t *Tools) clearNamespaceFinalizers(ctx context.Context, toolReq *mcp.CallToolRequest, params clearNamespaceFinalizersParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("clearNamespaceFinalizers called")
+
+	if params.Confirm != params.Name {
+		return nil, nil, fmt.Errorf("set confirm to the name of the namespace (%s) to clear its finalizers, the resources left in it are orphaned", params.Name)
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clientSet, err := t.client.CreateClientSet(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create clientset", zap.String("tool", "clearNamespaceFinalizers"), zap.Error(err))
+		return nil, nil, err
+	}
+	namespaces := clientSet.CoreV1().Namespaces()
+	namespace, err := namespaces.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get namespace", zap.String("tool", "clearNamespaceFinalizers"), zap.Error(err))
+		return nil, nil, err
+	}
+	if namespace.DeletionTimestamp == nil {
+		return nil, nil, fmt.Errorf("namespace %s isn't being deleted, only the finalizers of Terminating namespaces can be cleared", params.Name)
+	}
+
+	result := clearedFinalizers{Namespace: params.Name, RemovedFinalizers: namespaceFinalizers(namespace)}
+	if len(result.RemovedFinalizers) == 0 {
+		return nil, nil, fmt.Errorf("namespace %s has no finalizers left, use diagnoseNamespaceTermination to find what blocks its deletion", params.Name)
+	}
+	result.OrphanedResources, result.DiscoveryFailures, err = t.remainingResources(ctx, url, token, params.Cluster, params.Name, clientSet)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list remaining resources", zap.String("tool", "clearNamespaceFinalizers"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	if len(namespace.Finalizers) > 0 {
+		namespace.Finalizers = nil
+		namespace, err = namespaces.Update(ctx, namespace, metav1.UpdateOptions{})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to update namespace", zap.String("tool", "clearNamespaceFinalizers"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to remove the metadata finalizers of namespace %s: %w", params.Name, err)
+		}
+	}
+	// the finalizers of the spec can only be removed with the finalize subresource
+	if len(namespace.Spec.Finalizers) > 0 {
+		namespace.Spec.Finalizers = nil
+		if _, err := namespaces.Finalize(ctx, namespace, metav1.UpdateOptions{}); err != nil {
+			middleware.Logger(ctx).Error("failed to finalize namespace", zap.String("tool", "clearNamespaceFinalizers"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to remove the spec finalizers of namespace %s: %w", params.Name, err)
+		}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "clearNamespaceFinalizers"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
@@ -0,0 +1,62 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestClearNamespaceFinalizers(t *testing.T) {
+	tests := map[string]struct {
+		params         clearNamespaceFinalizersParams
+		namespace      *corev1.Namespace
+		objects        []runtime.Object
+		expectedResult string
+		expectedError  string
+	}{
+		"clear finalizers": {
+			params:         clearNamespaceFinalizersParams{Cluster: "local", Name: "payments", Confirm: "payments"},
+			namespace:      fakeTerminatingNamespace("payments", "controller.cattle.io/namespace-auth"),
+			objects:        []runtime.Object{fakeWidget("payments", "blue", "widgets.example.com/cleanup")},
+			expectedResult: `{"namespace":"payments","removedFinalizers":["kubernetes","controller.cattle.io/namespace-auth"],"orphanedResources":[{"apiVersion":"example.com/v1","kind":"Widget","count":1,"names":["blue"],"finalizers":["widgets.example.com/cleanup"]}]}`,
+		},
+		"not confirmed": {
+			params:        clearNamespaceFinalizersParams{Cluster: "local", Name: "payments", Confirm: "yes"},
+			namespace:     fakeTerminatingNamespace("payments"),
+			expectedError: "set confirm to the name of the namespace (payments) to clear its finalizers",
+		},
+		"namespace not terminating": {
+			params:        clearNamespaceFinalizersParams{Cluster: "local", Name: "payments", Confirm: "payments"},
+			namespace:     &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+			expectedError: "namespace payments isn't being deleted, only the finalizers of Terminating namespaces can be cleared",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, clientset := newFakeNamespaceClient(test.namespace, test.objects...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.clearNamespaceFinalizers(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			namespace, err := clientset.CoreV1().Namespaces().Get(t.Context(), test.params.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, namespace.Finalizers)
+			assert.Empty(t, namespace.Spec.Finalizers)
+		})
+	}
+}
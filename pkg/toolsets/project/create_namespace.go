@@ -0,0 +1,74 @@
+package project
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// createNamespaceParams specifies the parameters needed to create a namespace.
+type createNamespaceParams struct {
+	Cluster string            `json:"cluster" jsonschema:"the cluster of the namespace"`
+	Name    string            `json:"name" jsonschema:"the name of the namespace"`
+	Project string            `json:"project,omitempty" jsonschema:"the ID or display name of the project of the namespace"`
+	Labels  map[string]string `json:"labels,omitempty" jsonschema:"the labels of the namespace"`
+}
+
+// createNamespace creates a namespace, assigned to a Project with the Rancher project annotation and label if one is provided.
+func (t *Tools) createNamespace(ctx context.Context, toolReq *mcp.CallToolRequest, params createNamespaceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createNamespace called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	metadata := map[string]any{"name": params.Name}
+	labels := toAnyMap(params.Labels)
+	if params.Project != "" {
+		clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "createNamespace"), zap.Error(err))
+			return nil, nil, err
+		}
+		project, err := t.findProject(ctx, url, token, clusterID, params.Project)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to find project", zap.String("tool", "createNamespace"), zap.Error(err))
+			return nil, nil, err
+		}
+		metadata["annotations"] = map[string]any{projectIDKey: clusterID + ":" + project.GetName()}
+		labels[projectIDKey] = project.GetName()
+	}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	namespace := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   metadata,
+	}}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, "", params.Cluster, converter.K8sKindsToGVRs["namespace"])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createNamespace"), zap.Error(err))
+		return nil, nil, err
+	}
+	created, err := resourceInterface.Create(ctx, namespace, metav1.CreateOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create namespace", zap.String("tool", "createNamespace"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{created}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "createNamespace"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
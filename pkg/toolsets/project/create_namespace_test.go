@@ -0,0 +1,63 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateNamespace(t *testing.T) {
+	tests := map[string]struct {
+		params              createNamespaceParams
+		expectedAnnotations map[string]string
+		expectedLabels      map[string]string
+		expectedError       string
+	}{
+		"create namespace": {
+			params:         createNamespaceParams{Cluster: "local", Name: "payments", Labels: map[string]string{"team": "payments"}},
+			expectedLabels: map[string]string{"team": "payments"},
+		},
+		"create namespace in project": {
+			params:              createNamespaceParams{Cluster: "local", Name: "payments", Project: "System", Labels: map[string]string{"team": "payments"}},
+			expectedAnnotations: map[string]string{projectIDKey: "local:p-def34"},
+			expectedLabels:      map[string]string{projectIDKey: "p-def34", "team": "payments"},
+		},
+		"project not found": {
+			params:        createNamespaceParams{Cluster: "local", Name: "payments", Project: "missing"},
+			expectedError: "project missing not found in cluster local",
+		},
+		"namespace already exists": {
+			params:        createNamespaceParams{Cluster: "local", Name: "default"},
+			expectedError: `namespaces "default" already exists`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newFakeClient(
+				fakeProject("p-def34", "System", nil),
+				fakeNamespace("default", "p-def34"),
+			)
+			tools := Tools{client: c}
+
+			_, _, err := tools.createNamespace(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			ns, err := fakeDynClient.Resource(converter.K8sKindsToGVRs["namespace"]).Get(t.Context(), test.params.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedAnnotations, ns.GetAnnotations())
+			assert.Equal(t, test.expectedLabels, ns.GetLabels())
+		})
+	}
+}
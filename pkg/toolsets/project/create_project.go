@@ -0,0 +1,89 @@
+package project
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// createProjectParams specifies the parameters needed to create a Project.
+type createProjectParams struct {
+	Cluster                       string            `json:"cluster" jsonschema:"the cluster of the project"`
+	DisplayName                   string            `json:"displayName" jsonschema:"the display name of the project"`
+	Description                   string            `json:"description,omitempty" jsonschema:"the description of the project"`
+	ResourceQuota                 map[string]string `json:"resourceQuota,omitempty" jsonschema:"the resource quota limits of the project (e.g. limitsCpu, limitsMemory, pods)"`
+	NamespaceDefaultResourceQuota map[string]string `json:"namespaceDefaultResourceQuota,omitempty" jsonschema:"the default resource quota limits of each namespace in the project"`
+}
+
+// createProject creates a Project in the namespace of the cluster in the local cluster, letting Rancher generate its ID.
+func (t *Tools) createProject(ctx context.Context, toolReq *mcp.CallToolRequest, params createProjectParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createProject called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "createProject"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	spec := map[string]any{
+		"clusterName": clusterID,
+		"displayName": params.DisplayName,
+	}
+	if params.Description != "" {
+		spec["description"] = params.Description
+	}
+	if len(params.ResourceQuota) > 0 {
+		spec["resourceQuota"] = map[string]any{"limit": toAnyMap(params.ResourceQuota)}
+	}
+	if len(params.NamespaceDefaultResourceQuota) > 0 {
+		spec["namespaceDefaultResourceQuota"] = map[string]any{"limit": toAnyMap(params.NamespaceDefaultResourceQuota)}
+	}
+	project := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "Project",
+		"metadata": map[string]any{
+			"generateName": "p-",
+			"namespace":    clusterID,
+		},
+		"spec": spec,
+	}}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, clusterID, "local", converter.K8sKindsToGVRs["project"])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createProject"), zap.Error(err))
+		return nil, nil, err
+	}
+	created, err := resourceInterface.Create(ctx, project, metav1.CreateOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create project", zap.String("tool", "createProject"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{created}, "local")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "createProject"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// toAnyMap converts the map so it can be stored in an unstructured object.
+func toAnyMap(m map[string]string) map[string]any {
+	result := make(map[string]any, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProject(t *testing.T) {
+	tests := map[string]struct {
+		params         createProjectParams
+		expectedResult string
+	}{
+		"create project": {
+			params: createProjectParams{Cluster: "local", DisplayName: "Team A", Description: "Team A workloads"},
+			expectedResult: `{
+				"llm": [{
+					"apiVersion": "management.cattle.io/v3",
+					"kind": "Project",
+					"metadata": {"generateName": "p-", "namespace": "local"},
+					"spec": {"clusterName": "local", "displayName": "Team A", "description": "Team A workloads"}
+				}],
+				"uiContext": [{"cluster": "local", "kind": "Project", "name": "", "namespace": "local", "type": "project"}]
+			}`,
+		},
+		"create project with quotas": {
+			params: createProjectParams{
+				Cluster:                       "local",
+				DisplayName:                   "Team B",
+				ResourceQuota:                 map[string]string{"limitsCpu": "4000m", "pods": "50"},
+				NamespaceDefaultResourceQuota: map[string]string{"limitsCpu": "1000m", "pods": "10"},
+			},
+			expectedResult: `{
+				"llm": [{
+					"apiVersion": "management.cattle.io/v3",
+					"kind": "Project",
+					"metadata": {"generateName": "p-", "namespace": "local"},
+					"spec": {
+						"clusterName": "local",
+						"displayName": "Team B",
+						"resourceQuota": {"limit": {"limitsCpu": "4000m", "pods": "50"}},
+						"namespaceDefaultResourceQuota": {"limit": {"limitsCpu": "1000m", "pods": "10"}}
+					}
+				}],
+				"uiContext": [{"cluster": "local", "kind": "Project", "name": "", "namespace": "local", "type": "project"}]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient()
+			tools := Tools{client: c}
+
+			result, _, err := tools.createProject(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// deleteNamespaceParams specifies the parameters needed to delete a namespace.
+type deleteNamespaceParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the namespace"`
+	Name    string `json:"name" jsonschema:"the name of the namespace"`
+}
+
+// deleteNamespace deletes a namespace and returns its state once the deletion started. Namespaces are Terminating
+// until all their resources are deleted, see diagnoseNamespaceTermination for the ones that stay Terminating.
+func (t *Tools) deleteNamespace(ctx context.Context, toolReq *mcp.CallToolRequest, params deleteNamespaceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("deleteNamespace called")
+
+	if slices.Contains(utils.ProtectedNamespaces, params.Name) {
+		middleware.Logger(ctx).Warn("refusing to delete protected namespace", zap.String("tool", "deleteNamespace"), zap.String("namespace", params.Name))
+		return nil, nil, fmt.Errorf("namespace %s is protected and can't be deleted", params.Name)
+	}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), "", params.Cluster, converter.K8sKindsToGVRs["namespace"])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "deleteNamespace"), zap.Error(err))
+		return nil, nil, err
+	}
+	if err := resourceInterface.Delete(ctx, params.Name, metav1.DeleteOptions{}); err != nil {
+		middleware.Logger(ctx).Error("failed to delete namespace", zap.String("tool", "deleteNamespace"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to delete namespace %s: %w", params.Name, err)
+	}
+	namespace, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// the namespace was empty and is already gone
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("namespace %s deleted", params.Name)}},
+		}, nil, nil
+	}
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get namespace", zap.String("tool", "deleteNamespace"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{namespace}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "deleteNamespace"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,54 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeleteNamespace(t *testing.T) {
+	tests := map[string]struct {
+		params         deleteNamespaceParams
+		expectedResult string
+		expectedError  string
+	}{
+		"delete namespace": {
+			params:         deleteNamespaceParams{Cluster: "local", Name: "payments"},
+			expectedResult: "namespace payments deleted",
+		},
+		"protected namespace": {
+			params:        deleteNamespaceParams{Cluster: "local", Name: "cattle-system"},
+			expectedError: "namespace cattle-system is protected and can't be deleted",
+		},
+		"namespace not found": {
+			params:        deleteNamespaceParams{Cluster: "local", Name: "missing"},
+			expectedError: "failed to delete namespace missing",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newFakeClient(fakeNamespace("payments", ""), fakeNamespace("cattle-system", ""))
+			tools := Tools{client: c}
+
+			result, _, err := tools.deleteNamespace(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			_, err = fakeDynClient.Resource(converter.K8sKindsToGVRs["namespace"]).Get(t.Context(), test.params.Name, metav1.GetOptions{})
+			assert.True(t, apierrors.IsNotFound(err))
+		})
+	}
+}
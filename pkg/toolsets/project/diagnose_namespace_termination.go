@@ -0,0 +1,222 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxRemainingNames is the maximum number of names returned for each kind of resource remaining in a namespace.
+const maxRemainingNames = 10
+
+// diagnoseNamespaceParams specifies the parameters needed to diagnose the termination of a namespace.
+type diagnoseNamespaceParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the namespace"`
+	Name    string `json:"name" jsonschema:"the name of the namespace"`
+}
+
+// namespaceTermination describes why a namespace is still Terminating.
+type namespaceTermination struct {
+	Namespace         string `json:"namespace"`
+	Phase             string `json:"phase"`
+	DeletionTimestamp string `json:"deletionTimestamp,omitempty"`
+	// Finalizers are the finalizers of the namespace, in its spec and in its metadata.
+	Finalizers []string             `json:"finalizers,omitempty"`
+	Conditions []namespaceCondition `json:"conditions,omitempty"`
+	// DiscoveryFailures are the API groups that can't be discovered, the namespace controller can't delete the
+	// resources of a namespace until all the groups are available.
+	DiscoveryFailures  []string            `json:"discoveryFailures,omitempty"`
+	RemainingResources []remainingResource `json:"remainingResources,omitempty"`
+	Hints              []string            `json:"hints"`
+}
+
+type namespaceCondition struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// remainingResource is a kind of resource still present in a namespace, with the finalizers blocking its deletion.
+type remainingResource struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Count      int      `json:"count"`
+	Names      []string `json:"names"`
+	Finalizers []string `json:"finalizers,omitempty"`
+}
+
+// diagnoseNamespaceTermination returns the finalizers, the conditions and the resources left of a namespace stuck in
+// Terminating, and hints about what blocks the deletion.
+func (t *Tools) diagnoseNamespaceTermination(ctx context.Context, toolReq *mcp.CallToolRequest, params diagnoseNamespaceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("diagnoseNamespaceTermination called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clientSet, err := t.client.CreateClientSet(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create clientset", zap.String("tool", "diagnoseNamespaceTermination"), zap.Error(err))
+		return nil, nil, err
+	}
+	namespace, err := clientSet.CoreV1().Namespaces().Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get namespace", zap.String("tool", "diagnoseNamespaceTermination"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	diagnosis := namespaceTermination{
+		Namespace:  namespace.Name,
+		Phase:      string(namespace.Status.Phase),
+		Finalizers: namespaceFinalizers(namespace),
+		Hints:      []string{},
+	}
+	if namespace.DeletionTimestamp == nil {
+		diagnosis.Hints = append(diagnosis.Hints, fmt.Sprintf("namespace %s isn't being deleted", namespace.Name))
+		return newNamespaceTerminationResult(ctx, diagnosis)
+	}
+	diagnosis.DeletionTimestamp = namespace.DeletionTimestamp.UTC().Format(time.RFC3339)
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			diagnosis.Conditions = append(diagnosis.Conditions, namespaceCondition{
+				Type:    string(condition.Type),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+		}
+	}
+
+	diagnosis.RemainingResources, diagnosis.DiscoveryFailures, err = t.remainingResources(ctx, url, token, params.Cluster, namespace.Name, clientSet)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list remaining resources", zap.String("tool", "diagnoseNamespaceTermination"), zap.Error(err))
+		return nil, nil, err
+	}
+	diagnosis.Hints = terminationHints(diagnosis)
+
+	return newNamespaceTerminationResult(ctx, diagnosis)
+}
+
+func newNamespaceTerminationResult(ctx context.Context, diagnosis namespaceTermination) (*mcp.CallToolResult, any, error) {
+	response, err := json.Marshal(diagnosis)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "diagnoseNamespaceTermination"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// terminationHints explains what blocks the deletion of the namespace: API groups that can't be discovered, resources
+// waiting for their finalizers, or only the finalizers of the namespace itself.
+func terminationHints(diagnosis namespaceTermination) []string {
+	hints := []string{}
+	if len(diagnosis.DiscoveryFailures) > 0 {
+		hints = append(hints, fmt.Sprintf("The API groups %s can't be discovered, usually because the APIService serving them is unavailable. "+
+			"The namespace controller doesn't delete the namespace until they are available again, or their APIService is deleted.", strings.Join(diagnosis.DiscoveryFailures, ", ")))
+	}
+	var finalizers []string
+	for _, resource := range diagnosis.RemainingResources {
+		for _, finalizer := range resource.Finalizers {
+			if !slices.Contains(finalizers, finalizer) {
+				finalizers = append(finalizers, finalizer)
+			}
+		}
+	}
+	switch {
+	case len(finalizers) > 0:
+		hints = append(hints, fmt.Sprintf("Resources are waiting for the finalizers %s, the controllers handling them must be running to remove them. "+
+			"Check that these controllers are installed and healthy before removing the finalizers of the resources by hand.", strings.Join(finalizers, ", ")))
+	case len(diagnosis.RemainingResources) > 0:
+		hints = append(hints, "Resources without finalizers are still being deleted, the namespace should be deleted once they are gone.")
+	case len(diagnosis.DiscoveryFailures) == 0 && len(diagnosis.Finalizers) > 0:
+		hints = append(hints, fmt.Sprintf("No resources are left, only the finalizers %s of the namespace block its deletion. "+
+			"They can be cleared with clearNamespaceFinalizers.", strings.Join(diagnosis.Finalizers, ", ")))
+	}
+
+	return hints
+}
+
+// namespaceFinalizers returns the finalizers of the namespace spec, removed by the namespace controller once all its
+// resources are deleted, and the finalizers of its metadata.
+func namespaceFinalizers(namespace *corev1.Namespace) []string {
+	var finalizers []string
+	for _, finalizer := range namespace.Spec.Finalizers {
+		finalizers = append(finalizers, string(finalizer))
+	}
+
+	return append(finalizers, namespace.Finalizers...)
+}
+
+// remainingResources returns the resources still present in the namespace, grouped by kind, and the API groups whose
+// resources can't be discovered.
+func (t *Tools) remainingResources(ctx context.Context, url string, token string, cluster string, namespace string, clientSet kubernetes.Interface) ([]remainingResource, []string, error) {
+	groups, resourceLists, err := clientSet.Discovery().ServerGroupsAndResources()
+	var discoveryFailures []string
+	if failed, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+		for gv := range failed.Groups {
+			discoveryFailures = append(discoveryFailures, gv.String())
+		}
+		slices.Sort(discoveryFailures)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	preferredVersions := map[string]string{}
+	for _, group := range groups {
+		preferredVersions[group.Name] = group.PreferredVersion.Version
+	}
+
+	remaining := []remainingResource{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil || gv.Version != preferredVersions[gv.Group] {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			// skip subresources such as pods/log
+			if !resource.Namespaced || strings.Contains(resource.Name, "/") || !slices.Contains(resource.Verbs, "list") {
+				continue
+			}
+			resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, namespace, cluster, gv.WithResource(resource.Name))
+			if err != nil {
+				return nil, nil, err
+			}
+			list, err := resourceInterface.List(ctx, metav1.ListOptions{})
+			if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list %s: %w", resource.Name, err)
+			}
+			if len(list.Items) == 0 {
+				continue
+			}
+			r := remainingResource{APIVersion: resourceList.GroupVersion, Kind: resource.Kind, Count: len(list.Items), Names: []string{}}
+			for _, item := range list.Items {
+				if len(r.Names) < maxRemainingNames {
+					r.Names = append(r.Names, item.GetName())
+				}
+				for _, finalizer := range item.GetFinalizers() {
+					if !slices.Contains(r.Finalizers, finalizer) {
+						r.Finalizers = append(r.Finalizers, finalizer)
+					}
+				}
+			}
+			remaining = append(remaining, r)
+		}
+	}
+
+	return remaining, discoveryFailures, nil
+}
@@ -0,0 +1,166 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+var deletionTime = metav1.NewTime(time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC))
+
+// fakeTerminatingNamespace returns a namespace being deleted, with the kubernetes finalizer in its spec.
+func fakeTerminatingNamespace(name string, finalizers ...string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			DeletionTimestamp: &deletionTime,
+			Finalizers:        finalizers,
+		},
+		Spec: corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionFalse, Reason: "ResourcesDiscovered"},
+				{Type: corev1.NamespaceFinalizersRemaining, Status: corev1.ConditionTrue, Reason: "SomeFinalizersRemain", Message: "Some content in the namespace has finalizers remaining: widgets.example.com/cleanup in 1 resource instances"},
+			},
+		},
+	}
+}
+
+func fakeWidget(namespace string, name string, finalizers ...string) *unstructured.Unstructured {
+	widget := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+	}}
+	widget.SetFinalizers(finalizers)
+
+	return widget
+}
+
+// newFakeNamespaceClient returns a client whose clientset has the namespace and discovers configmaps and widgets,
+// listed with a dynamic client with the objects.
+func newFakeNamespaceClient(namespace *corev1.Namespace, objects ...runtime.Object) (*client.Client, *fake.Clientset) {
+	clientset := fake.NewClientset(namespace)
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "delete"}},
+				{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"get", "list", "delete"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "delete"}},
+			},
+		},
+	}
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(projectScheme(), map[schema.GroupVersionResource]string{
+		{Group: "example.com", Version: "v1", Resource: "widgets"}: "WidgetList",
+	}, objects...)
+
+	return &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return clientset, nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}, clientset
+}
+
+func TestDiagnoseNamespaceTermination(t *testing.T) {
+	tests := map[string]struct {
+		namespace      *corev1.Namespace
+		objects        []runtime.Object
+		expectedResult string
+		expectedError  string
+	}{
+		"resources with finalizers": {
+			namespace: fakeTerminatingNamespace("payments"),
+			objects: []runtime.Object{
+				fakeWidget("payments", "blue", "widgets.example.com/cleanup"),
+				fakeWidget("payments", "green"),
+				fakeWidget("other", "red", "widgets.example.com/cleanup"),
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}},
+			},
+			expectedResult: `{
+				"namespace": "payments",
+				"phase": "Terminating",
+				"deletionTimestamp": "2025-05-01T10:00:00Z",
+				"finalizers": ["kubernetes"],
+				"conditions": [{"type": "NamespaceFinalizersRemaining", "reason": "SomeFinalizersRemain", "message": "Some content in the namespace has finalizers remaining: widgets.example.com/cleanup in 1 resource instances"}],
+				"remainingResources": [{"apiVersion": "example.com/v1", "kind": "Widget", "count": 2, "names": ["blue", "green"], "finalizers": ["widgets.example.com/cleanup"]}],
+				"hints": ["Resources are waiting for the finalizers widgets.example.com/cleanup, the controllers handling them must be running to remove them. Check that these controllers are installed and healthy before removing the finalizers of the resources by hand."]
+			}`,
+		},
+		"only namespace finalizers left": {
+			namespace: fakeTerminatingNamespace("payments", "controller.cattle.io/namespace-auth"),
+			expectedResult: `{
+				"namespace": "payments",
+				"phase": "Terminating",
+				"deletionTimestamp": "2025-05-01T10:00:00Z",
+				"finalizers": ["kubernetes", "controller.cattle.io/namespace-auth"],
+				"conditions": [{"type": "NamespaceFinalizersRemaining", "reason": "SomeFinalizersRemain", "message": "Some content in the namespace has finalizers remaining: widgets.example.com/cleanup in 1 resource instances"}],
+				"hints": ["No resources are left, only the finalizers kubernetes, controller.cattle.io/namespace-auth of the namespace block its deletion. They can be cleared with clearNamespaceFinalizers."]
+			}`,
+		},
+		"namespace not terminating": {
+			namespace:      &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+			expectedResult: `{"namespace": "payments", "phase": "Active", "hints": ["namespace payments isn't being deleted"]}`,
+		},
+		"namespace not found": {
+			namespace:     fakeTerminatingNamespace("other"),
+			expectedError: `namespaces "payments" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeNamespaceClient(test.namespace, test.objects...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.diagnoseNamespaceTermination(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, diagnoseNamespaceParams{Cluster: "local", Name: "payments"})
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestTerminationHints(t *testing.T) {
+	hints := terminationHints(namespaceTermination{
+		Finalizers:        []string{"kubernetes"},
+		DiscoveryFailures: []string{"metrics.k8s.io/v1beta1"},
+	})
+
+	assert.Equal(t, []string{"The API groups metrics.k8s.io/v1beta1 can't be discovered, usually because the APIService serving them is unavailable. " +
+		"The namespace controller doesn't delete the namespace until they are available again, or their APIService is deleted."}, hints)
+}
@@ -0,0 +1,93 @@
+package project
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// getProjectQuotasParams specifies the parameters needed to get the quotas of a Project.
+type getProjectQuotasParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the project"`
+	Project string `json:"project" jsonschema:"the ID or display name of the project"`
+}
+
+// getProjectQuotas retrieves the quotas configured in a Project and the ResourceQuotas Rancher created
+// in each of its namespaces.
+func (t *Tools) getProjectQuotas(ctx context.Context, toolReq *mcp.CallToolRequest, params getProjectQuotasParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getProjectQuotas called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "getProjectQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	project, err := t.findProject(ctx, url, token, clusterID, params.Project)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to find project", zap.String("tool", "getProjectQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	namespaces, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       params.Cluster,
+		Kind:          "namespace",
+		URL:           url,
+		Token:         token,
+		LabelSelector: projectIDKey + "=" + project.GetName(),
+		MetadataOnly:  true,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list namespaces", zap.String("tool", "getProjectQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+	projectNamespaces := map[string]bool{}
+	for _, ns := range namespaces {
+		projectNamespaces[ns.GetName()] = true
+	}
+
+	resourceQuotas, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: params.Cluster,
+		Kind:    "resourcequota",
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list resource quotas", zap.String("tool", "getProjectQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	displayName, _, _ := unstructured.NestedString(project.Object, "spec", "displayName")
+	summary := map[string]any{
+		"project":     project.GetName(),
+		"displayName": displayName,
+	}
+	for _, field := range []string{"resourceQuota", "namespaceDefaultResourceQuota", "containerDefaultResourceLimit"} {
+		if quota, found, _ := unstructured.NestedFieldNoCopy(project.Object, "spec", field); found {
+			summary[field] = quota
+		}
+	}
+	resources := []*unstructured.Unstructured{{Object: summary}}
+	for _, quota := range resourceQuotas {
+		if projectNamespaces[quota.GetNamespace()] {
+			resources = append(resources, quota)
+		}
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getProjectQuotas"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
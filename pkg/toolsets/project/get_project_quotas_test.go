@@ -0,0 +1,112 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func fakeResourceQuota(namespace string) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ResourceQuota",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default-quota",
+			Namespace: namespace,
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+		},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+			Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")},
+		},
+	}
+}
+
+func TestGetProjectQuotas(t *testing.T) {
+	quotaSpec := map[string]any{
+		"resourceQuota": map[string]any{
+			"limit":     map[string]any{"pods": "50"},
+			"usedLimit": map[string]any{"pods": "10"},
+		},
+		"namespaceDefaultResourceQuota": map[string]any{
+			"limit": map[string]any{"pods": "10"},
+		},
+		"containerDefaultResourceLimit": map[string]any{
+			"limitsCpu": "500m",
+		},
+	}
+
+	tests := map[string]struct {
+		params         getProjectQuotasParams
+		expectedResult string
+		expectedError  string
+	}{
+		"project with quotas": {
+			params: getProjectQuotasParams{Cluster: "local", Project: "Default"},
+			expectedResult: `{
+				"llm": [
+					{
+						"project": "p-abc12",
+						"displayName": "Default",
+						"resourceQuota": {"limit": {"pods": "50"}, "usedLimit": {"pods": "10"}},
+						"namespaceDefaultResourceQuota": {"limit": {"pods": "10"}},
+						"containerDefaultResourceLimit": {"limitsCpu": "500m"}
+					},
+					{
+						"apiVersion": "v1",
+						"kind": "ResourceQuota",
+						"metadata": {"name": "default-quota", "namespace": "default"},
+						"spec": {"hard": {"pods": "10"}},
+						"status": {"hard": {"pods": "10"}, "used": {"pods": "3"}}
+					}
+				],
+				"uiContext": [{"cluster": "local", "kind": "ResourceQuota", "name": "default-quota", "namespace": "default", "type": "resourcequota"}]
+			}`,
+		},
+		"project without quotas": {
+			params: getProjectQuotasParams{Cluster: "local", Project: "p-def34"},
+			expectedResult: `{
+				"llm": [{"project": "p-def34", "displayName": "System"}]
+			}`,
+		},
+		"project not found": {
+			params:        getProjectQuotasParams{Cluster: "local", Project: "missing"},
+			expectedError: "project missing not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(
+				fakeProject("p-abc12", "Default", quotaSpec),
+				fakeProject("p-def34", "System", nil),
+				fakeNamespace("default", "p-abc12"),
+				fakeNamespace("kube-system", "p-def34"),
+				fakeResourceQuota("default"),
+				fakeResourceQuota("orphan"),
+			)
+			tools := Tools{client: c}
+
+			result, _, err := tools.getProjectQuotas(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
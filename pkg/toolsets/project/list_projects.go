@@ -0,0 +1,96 @@
+package project
+
+import (
+	"context"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// listProjectsParams specifies the parameters needed to list the Projects of a cluster.
+type listProjectsParams struct {
+	Cluster string `json:"cluster" jsonschema:"the cluster of the projects"`
+}
+
+// projectNamespaces lists the namespaces that belong to a Project.
+type projectNamespaces struct {
+	Project     string   `json:"project"`
+	DisplayName string   `json:"displayName"`
+	Namespaces  []string `json:"namespaces"`
+}
+
+// listProjects retrieves all Projects of a cluster, together with the namespaces assigned to each of them
+// and the namespaces that don't belong to any Project.
+func (t *Tools) listProjects(ctx context.Context, toolReq *mcp.CallToolRequest, params listProjectsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listProjects called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "listProjects"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	projects, err := t.getProjects(ctx, url, token, clusterID)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list projects", zap.String("tool", "listProjects"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	namespaces, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: params.Cluster,
+		Kind:    "namespace",
+		URL:     url,
+		Token:   token,
+		// the projects of the namespaces are in their labels and annotations
+		MetadataOnly: true,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list namespaces", zap.String("tool", "listProjects"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	namespacesByProject := map[string][]string{}
+	unassigned := []string{}
+	for _, ns := range namespaces {
+		projectID := namespaceProjectID(ns)
+		if projectID == "" {
+			unassigned = append(unassigned, ns.GetName())
+			continue
+		}
+		namespacesByProject[projectID] = append(namespacesByProject[projectID], ns.GetName())
+	}
+
+	assigned := make([]projectNamespaces, 0, len(projects))
+	for _, p := range projects {
+		displayName, _, _ := unstructured.NestedString(p.Object, "spec", "displayName")
+		projectNs := namespacesByProject[p.GetName()]
+		slices.Sort(projectNs)
+		assigned = append(assigned, projectNamespaces{
+			Project:     p.GetName(),
+			DisplayName: displayName,
+			Namespaces:  append([]string{}, projectNs...),
+		})
+	}
+	slices.Sort(unassigned)
+
+	summary := &unstructured.Unstructured{Object: map[string]any{
+		"projectNamespaces":    assigned,
+		"unassignedNamespaces": unassigned,
+	}}
+	mcpResponse, err := response.CreateMcpResponse(ctx, append(projects, summary), "local")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listProjects"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
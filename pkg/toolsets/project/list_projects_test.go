@@ -0,0 +1,77 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestListProjects(t *testing.T) {
+	tests := map[string]struct {
+		objects        []runtime.Object
+		expectedResult string
+	}{
+		"projects with namespaces": {
+			objects: []runtime.Object{
+				fakeProject("p-abc12", "Default", nil),
+				fakeProject("p-def34", "System", nil),
+				fakeNamespace("default", "p-abc12"),
+				fakeNamespace("kube-system", "p-def34"),
+				fakeNamespace("cattle-system", "p-def34"),
+				fakeNamespace("orphan", ""),
+			},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "management.cattle.io/v3", "kind": "Project", "metadata": {"name": "p-abc12", "namespace": "local"}, "spec": {"clusterName": "local", "displayName": "Default"}},
+					{"apiVersion": "management.cattle.io/v3", "kind": "Project", "metadata": {"name": "p-def34", "namespace": "local"}, "spec": {"clusterName": "local", "displayName": "System"}},
+					{
+						"projectNamespaces": [
+							{"project": "p-abc12", "displayName": "Default", "namespaces": ["default"]},
+							{"project": "p-def34", "displayName": "System", "namespaces": ["cattle-system", "kube-system"]}
+						],
+						"unassignedNamespaces": ["orphan"]
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Project", "name": "p-abc12", "namespace": "local", "type": "project"},
+					{"cluster": "local", "kind": "Project", "name": "p-def34", "namespace": "local", "type": "project"}
+				]
+			}`,
+		},
+		"project without namespaces": {
+			objects: []runtime.Object{
+				fakeProject("p-abc12", "Default", nil),
+			},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "management.cattle.io/v3", "kind": "Project", "metadata": {"name": "p-abc12", "namespace": "local"}, "spec": {"clusterName": "local", "displayName": "Default"}},
+					{
+						"projectNamespaces": [{"project": "p-abc12", "displayName": "Default", "namespaces": []}],
+						"unassignedNamespaces": []
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Project", "name": "p-abc12", "namespace": "local", "type": "project"}
+				]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(test.objects...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.listProjects(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, listProjectsParams{Cluster: "local"})
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// moveNamespaceParams specifies the parameters needed to move a namespace to a Project.
+type moveNamespaceParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the namespace"`
+	Namespace string `json:"namespace" jsonschema:"the namespace to move"`
+	Project   string `json:"project" jsonschema:"the ID or display name of the target project. Empty to remove the namespace from its project"`
+}
+
+// moveNamespaceToProject assigns a namespace to a Project by setting the Rancher project annotation and label.
+// If no project is provided, the namespace is removed from its current Project.
+func (t *Tools) moveNamespaceToProject(ctx context.Context, toolReq *mcp.CallToolRequest, params moveNamespaceParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("moveNamespaceToProject called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "moveNamespaceToProject"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	// null removes the annotation and label
+	var annotation, label any
+	if params.Project != "" {
+		project, err := t.findProject(ctx, url, token, clusterID, params.Project)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to find project", zap.String("tool", "moveNamespaceToProject"), zap.Error(err))
+			return nil, nil, err
+		}
+		annotation = clusterID + ":" + project.GetName()
+		label = project.GetName()
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{projectIDKey: annotation},
+			"labels":      map[string]any{projectIDKey: label},
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, "", params.Cluster, converter.K8sKindsToGVRs["namespace"])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "moveNamespaceToProject"), zap.Error(err))
+		return nil, nil, err
+	}
+	namespace, err := resourceInterface.Patch(ctx, params.Namespace, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to patch namespace", zap.String("tool", "moveNamespaceToProject"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{namespace}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "moveNamespaceToProject"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,65 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMoveNamespaceToProject(t *testing.T) {
+	tests := map[string]struct {
+		params              moveNamespaceParams
+		expectedAnnotations map[string]string
+		expectedLabels      map[string]string
+		expectedError       string
+	}{
+		"move namespace to project": {
+			params:              moveNamespaceParams{Cluster: "local", Namespace: "orphan", Project: "p-abc12"},
+			expectedAnnotations: map[string]string{projectIDKey: "local:p-abc12"},
+			expectedLabels:      map[string]string{projectIDKey: "p-abc12"},
+		},
+		"move namespace to project by display name": {
+			params:              moveNamespaceParams{Cluster: "local", Namespace: "default", Project: "System"},
+			expectedAnnotations: map[string]string{projectIDKey: "local:p-def34"},
+			expectedLabels:      map[string]string{projectIDKey: "p-def34"},
+		},
+		"remove namespace from project": {
+			params: moveNamespaceParams{Cluster: "local", Namespace: "default"},
+		},
+		"project not found": {
+			params:        moveNamespaceParams{Cluster: "local", Namespace: "default", Project: "missing"},
+			expectedError: "project missing not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newFakeClient(
+				fakeProject("p-abc12", "Default", nil),
+				fakeProject("p-def34", "System", nil),
+				fakeNamespace("default", "p-abc12"),
+				fakeNamespace("orphan", ""),
+			)
+			tools := Tools{client: c}
+
+			_, _, err := tools.moveNamespaceToProject(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			ns, err := fakeDynClient.Resource(converter.K8sKindsToGVRs["namespace"]).Get(t.Context(), test.params.Namespace, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedAnnotations, ns.GetAnnotations())
+			assert.Equal(t, test.expectedLabels, ns.GetLabels())
+		})
+	}
+}
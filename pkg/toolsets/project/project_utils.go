@@ -0,0 +1,65 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// projectIDKey is the annotation and label Rancher uses to assign a namespace to a Project.
+// The annotation value is "<cluster ID>:<project ID>" and the label value is the project ID.
+const projectIDKey = "field.cattle.io/projectId"
+
+// getProjects returns all Projects of the cluster. Projects are stored in the local cluster, in the namespace named after the cluster ID.
+func (t *Tools) getProjects(ctx context.Context, url string, token string, clusterID string) ([]*unstructured.Unstructured, error) {
+	return t.client.GetResources(ctx, client.ListParams{
+		Cluster:   "local",
+		Kind:      "project",
+		Namespace: clusterID,
+		URL:       url,
+		Token:     token,
+	})
+}
+
+// findProject returns the Project of the cluster with the given ID or display name.
+func (t *Tools) findProject(ctx context.Context, url string, token string, clusterID string, project string) (*unstructured.Unstructured, error) {
+	projects, err := t.getProjects(ctx, url, token, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	var matches []*unstructured.Unstructured
+	for _, p := range projects {
+		if p.GetName() == project {
+			return p, nil
+		}
+		if displayName, _, _ := unstructured.NestedString(p.Object, "spec", "displayName"); displayName == project {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("project %s not found in cluster %s", project, clusterID)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("more than one project named %s found in cluster %s, use the project ID instead", project, clusterID)
+	}
+}
+
+// namespaceProjectID returns the ID of the Project the namespace belongs to, or an empty string if it isn't assigned to any.
+func namespaceProjectID(namespace *unstructured.Unstructured) string {
+	if annotation, ok := namespace.GetAnnotations()[projectIDKey]; ok && annotation != "" {
+		_, projectID, found := strings.Cut(annotation, ":")
+		if found {
+			return projectID
+		}
+		return annotation
+	}
+
+	return namespace.GetLabels()[projectIDKey]
+}
@@ -0,0 +1,143 @@
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	fakeUrl   = "https://localhost:8080"
+	fakeToken = "fakeToken"
+)
+
+func fakeProject(name string, displayName string, spec map[string]any) *unstructured.Unstructured {
+	projectSpec := map[string]any{
+		"clusterName": "local",
+		"displayName": displayName,
+	}
+	for k, v := range spec {
+		projectSpec[k] = v
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "Project",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "local",
+		},
+		"spec": projectSpec,
+	}}
+}
+
+func fakeNamespace(name string, projectID string) *corev1.Namespace {
+	ns := &corev1.Namespace{}
+	ns.Name = name
+	if projectID != "" {
+		ns.Annotations = map[string]string{projectIDKey: "local:" + projectID}
+		ns.Labels = map[string]string{projectIDKey: projectID}
+	}
+
+	return ns
+}
+
+func projectScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	return scheme
+}
+
+func projectCustomListKinds() map[schema.GroupVersionResource]string {
+	return map[schema.GroupVersionResource]string{
+		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}: "ProjectList",
+	}
+}
+
+func newFakeClient(objects ...runtime.Object) (*client.Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(projectScheme(), projectCustomListKinds(), objects...)
+
+	return &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}, fakeDynClient
+}
+
+func TestFindProject(t *testing.T) {
+	tests := map[string]struct {
+		project         string
+		expectedProject string
+		expectedError   string
+	}{
+		"find by ID": {
+			project:         "p-abc12",
+			expectedProject: "p-abc12",
+		},
+		"find by display name": {
+			project:         "Default",
+			expectedProject: "p-abc12",
+		},
+		"display name used by more than one project": {
+			project:       "Team",
+			expectedError: "more than one project named Team found in cluster local",
+		},
+		"project not found": {
+			project:       "missing",
+			expectedError: "project missing not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(
+				fakeProject("p-abc12", "Default", nil),
+				fakeProject("p-team1", "Team", nil),
+				fakeProject("p-team2", "Team", nil),
+			)
+			tools := Tools{client: c}
+
+			project, err := tools.findProject(context.TODO(), fakeUrl, fakeToken, "local", test.project)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedProject, project.GetName())
+			}
+		})
+	}
+}
+
+func TestNamespaceProjectID(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		labels      map[string]string
+		expected    string
+	}{
+		"annotation":           {annotations: map[string]string{projectIDKey: "c-abc12:p-abc12"}, expected: "p-abc12"},
+		"label only":           {labels: map[string]string{projectIDKey: "p-abc12"}, expected: "p-abc12"},
+		"annotation and label": {annotations: map[string]string{projectIDKey: "c-abc12:p-abc12"}, labels: map[string]string{projectIDKey: "p-other"}, expected: "p-abc12"},
+		"not assigned":         {expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ns := &unstructured.Unstructured{Object: map[string]any{}}
+			ns.SetAnnotations(test.annotations)
+			ns.SetLabels(test.labels)
+
+			assert.Equal(t, test.expected, namespaceProjectID(ns))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package project
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+)
+
+const (
+	toolsSet    = "project"
+	toolsSetAnn = "toolset"
+	urlHeader   = client.URLHeader
+)
+
+// Tools contains all tools for the MCP server
+type Tools struct {
+	client *client.Client
+	// ReadOnly disables the tools that create Projects, create, delete or move namespaces, or clear their finalizers.
+	ReadOnly bool
+}
+
+// NewTools creates and returns a new Tools instance.
+func NewTools(client *client.Client) *Tools {
+	return &Tools{
+		client: client,
+	}
+}
+
+// Register adds the tools of the project toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	tools.ReadOnly = deps.ReadOnly
+	tools.AddTools(mcpServer)
+}
+
+// AddTools registers all Rancher Project tools with the provided MCP server.
+// Each tool is configured with metadata identifying it as part of the project toolset.
+func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listProjects",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the Rancher Projects of a cluster, the namespaces that belong to each Project and the namespaces that are not assigned to any Project.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.`},
+		response.WithStructuredErrors(t.listProjects),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createProject",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Creates a Rancher Project in a cluster. Don't ask for confirmation.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		displayName (string): The display name of the Project.
+		description (string, optional): The description of the Project.
+		resourceQuota (object, optional): The resource quota limits of the whole Project (e.g. {"limitsCpu": "4000m", "limitsMemory": "8Gi", "pods": "50"}). If set, namespaceDefaultResourceQuota must be set too.
+		namespaceDefaultResourceQuota (object, optional): The default resource quota limits applied to each namespace of the Project.
+
+		Returns:
+		The created Project.`},
+		response.WithStructuredErrors(t.createProject),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "moveNamespaceToProject",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Moves a namespace to a Rancher Project, or removes it from its current Project. Don't ask for confirmation.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		namespace (string): The namespace to move.
+		project (string): The ID (e.g. 'p-abc12') or display name of the target Project. Empty to remove the namespace from its Project.
+
+		Returns:
+		The updated namespace.`},
+		response.WithStructuredErrors(t.moveNamespaceToProject),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getProjectQuotas",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the resource quotas of a Rancher Project: the Project limits and how much of them is used, the default limits of its namespaces and containers, and the ResourceQuotas of each namespace in the Project.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		project (string): The ID (e.g. 'p-abc12') or display name of the Project.`},
+		response.WithStructuredErrors(t.getProjectQuotas),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "analyzeQuotas",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Analyzes the ResourceQuotas and LimitRanges of the namespaces of a cluster or a Rancher Project: the usage of each quota, the namespaces near their quota, the LimitRange constraints and defaults, and the Pod creations refused by them (e.g. exceeded quota events) with an explanation.
+		Use it when Pods aren't created, e.g. a Deployment or a Job stuck with fewer replicas than desired.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		project (string, optional): The ID (e.g. 'p-abc12') or display name of a Project. Only its namespaces are checked and the usage of the Project quota is returned.
+		namespace (string, optional): The namespace to check. Empty for all the namespaces of the Project or the cluster.
+		threshold (integer, optional): Percentage of a quota above which a namespace is flagged as near the quota. Defaults to 80.`},
+		response.WithStructuredErrors(t.analyzeQuotas),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createNamespace",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Creates a namespace in a cluster, optionally in a Rancher Project. Don't ask for confirmation.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		name (string): The name of the namespace.
+		project (string, optional): The ID (e.g. 'p-abc12') or display name of the Project of the namespace.
+		labels (object, optional): The labels of the namespace (e.g. {"team": "payments"}).
+
+		Returns:
+		The created namespace.`},
+		response.WithStructuredErrors(t.createNamespace),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "deleteNamespace",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Deletes a namespace and all the resources in it. The namespaces required by Kubernetes or Rancher can't be deleted. Ask for confirmation before deleting.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		name (string): The name of the namespace.
+
+		Returns:
+		The namespace, Terminating until all its resources are deleted. Use diagnoseNamespaceTermination if it stays Terminating.`},
+		response.WithStructuredErrors(t.deleteNamespace),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "diagnoseNamespaceTermination",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Diagnoses a namespace stuck in Terminating: returns its remaining finalizers and conditions, the API groups that can't be discovered, the resources still present in it with their finalizers, and hints about what blocks the deletion.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		name (string): The name of the namespace.`},
+		response.WithStructuredErrors(t.diagnoseNamespaceTermination),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "clearNamespaceFinalizers",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Removes the finalizers of a namespace stuck in Terminating so it's deleted. It's a last resort: the resources left in the namespace are orphaned and the cleanup of their finalizers never runs.
+		Always run diagnoseNamespaceTermination first, fix what blocks the deletion when possible, and ask for confirmation before clearing the finalizers.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		name (string): The name of the Terminating namespace.
+		confirm (string): The name of the namespace again, to confirm the resources left in it can be orphaned.
+
+		Returns:
+		The finalizers removed and the resources orphaned.`},
+		response.WithStructuredErrors(t.clearNamespaceFinalizers),
+	)
+
+	if t.ReadOnly {
+		mcpServer.RemoveTools("createProject", "moveNamespaceToProject", "createNamespace", "deleteNamespace", "clearNamespaceFinalizers")
+	}
+}
@@ -0,0 +1,215 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// maxNodeWaitSeconds is the longest wait for a new node. TimeoutMiddleware adds the wait to the time limit of the
+// call, so the registration isn't reported as timed out while the node joins.
+const maxNodeWaitSeconds = middleware.MaxWaitSeconds
+
+// machinePollInterval is the interval between the checks of the machines of a cluster waiting for a new node. It's a
+// variable to be shortened by the tests.
+var machinePollInterval = 5 * time.Second
+
+// machineRoleLabels are the labels set by Rancher on the machines of a cluster with the roles of their node.
+var machineRoleLabels = map[string]string{
+	etcdRole:         "rke.cattle.io/etcd-role",
+	controlPlaneRole: "rke.cattle.io/control-plane-role",
+	workerRole:       "rke.cattle.io/worker-role",
+}
+
+// shellSafeRegexp matches the values used in a shell command without quotes.
+var shellSafeRegexp = regexp.MustCompile(`^[A-Za-z0-9._:/=@-]+$`)
+
+type addCustomClusterNodeParams struct {
+	Cluster         string            `json:"cluster" jsonschema:"the ID or the name of the custom cluster"`
+	Roles           []string          `json:"roles" jsonschema:"the roles of the node to add: etcd, controlplane and/or worker" validate:"required,enum=etcd|controlplane|worker"`
+	Insecure        bool              `json:"insecure,omitempty" jsonschema:"download the install script without verifying the certificate of Rancher, for a self-signed certificate not trusted by the node"`
+	NodeName        string            `json:"nodeName,omitempty" jsonschema:"the name of the node, its hostname if not provided" validate:"format=dns1123-subdomain"`
+	Address         string            `json:"address,omitempty" jsonschema:"the public address of the node"`
+	InternalAddress string            `json:"internalAddress,omitempty" jsonschema:"the internal address of the node"`
+	Labels          map[string]string `json:"labels,omitempty" jsonschema:"the labels of the node"`
+	Taints          []string          `json:"taints,omitempty" jsonschema:"the taints of the node, e.g. dedicated=gpu:NoSchedule"`
+	WaitSeconds     int64             `json:"waitSeconds,omitempty" jsonschema:"wait up to this many seconds, at most 300, for a machine registered since the since time to become Ready. 0 returns the command without waiting"`
+	Since           string            `json:"since,omitempty" jsonschema:"the since time returned by the call getting the command, the machines created since then are the new ones. Defaults to now"`
+}
+
+// customClusterNode is the registration command of a node with the machines registered since the command was
+// returned, to follow the addition of the node.
+type customClusterNode struct {
+	clusterRegistration
+	// Since is the time from which the machines of the cluster are new, passed back to wait for the node.
+	Since    string              `json:"since"`
+	Machines []registeredMachine `json:"machines"`
+	// Ready is set when a new machine is Ready, its node has joined the cluster.
+	Ready bool `json:"ready"`
+}
+
+// registeredMachine is a machine of a custom cluster, created when a node runs the registration command.
+type registeredMachine struct {
+	Name     string   `json:"name"`
+	NodeName string   `json:"nodeName,omitempty"`
+	Roles    []string `json:"roles"`
+	Phase    string   `json:"phase,omitempty"`
+	Created  string   `json:"created"`
+	Ready    bool     `json:"ready"`
+	// Pending are the unsatisfied conditions of the machine, why it's not Ready yet.
+	Pending []string `json:"pending,omitempty"`
+}
+
+// addCustomClusterNode returns the command adding a node with the given roles and options to a custom cluster. With
+// waitSeconds, it polls the machines of the cluster until a machine created since the given time becomes Ready.
+func (t *Tools) addCustomClusterNode(ctx context.Context, toolReq *mcp.CallToolRequest, params addCustomClusterNodeParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "addCustomClusterNode"), zap.String("cluster", params.Cluster))
+	log.Debug("addCustomClusterNode called")
+
+	if err := validateRegistrationRoles(params.Roles); err != nil {
+		return nil, nil, err
+	}
+	if params.WaitSeconds < 0 || params.WaitSeconds > maxNodeWaitSeconds {
+		return nil, nil, fmt.Errorf("invalid waitSeconds %d, must be between 0 and %d", params.WaitSeconds, maxNodeWaitSeconds)
+	}
+	since := time.Now().UTC().Truncate(time.Second)
+	if params.Since != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, params.Since); err != nil {
+			return nil, nil, fmt.Errorf("invalid since time %q, must be an RFC 3339 time like 2025-01-01T00:00:00Z: %w", params.Since, err)
+		}
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	cluster, err := t.customCluster(ctx, url, token, params.Cluster)
+	if err != nil {
+		log.Error("failed to get custom cluster", zap.Error(err))
+		return nil, nil, err
+	}
+	registration, err := t.clusterRegistration(ctx, url, token, cluster, params.Roles, params.Insecure, "")
+	if err != nil {
+		log.Error("failed to get the registration command", zap.Error(err))
+		return nil, nil, err
+	}
+	if registration.Command != "" {
+		registration.Command += nodeOptions(params)
+	}
+	node := customClusterNode{
+		clusterRegistration: registration,
+		Since:               since.UTC().Format(time.RFC3339),
+		Machines:            []registeredMachine{},
+	}
+	if params.WaitSeconds == 0 {
+		return registrationResponse(log, node)
+	}
+
+	clusterSelector := metav1.FormatLabelSelector(&metav1.LabelSelector{
+		MatchLabels: map[string]string{"cluster.x-k8s.io/cluster-name": cluster.Name},
+	})
+	err = wait.PollUntilContextTimeout(ctx, machinePollInterval, time.Duration(params.WaitSeconds)*time.Second, true, func(ctx context.Context) (bool, error) {
+		machines, err := t.provisioningMachines(ctx, url, token, cluster.Namespace, clusterSelector)
+		if err != nil {
+			return false, err
+		}
+		node.Machines = newMachines(machines, since, params.NodeName)
+		node.Ready = slices.ContainsFunc(node.Machines, func(machine registeredMachine) bool { return machine.Ready })
+		return node.Ready, nil
+	})
+	if err != nil && !wait.Interrupted(err) {
+		log.Error("failed to get the machines", zap.Error(err))
+		return nil, nil, err
+	}
+	switch {
+	case node.Ready:
+		node.Message = "the node has joined the cluster"
+	case len(node.Machines) == 0:
+		node.Message = fmt.Sprintf("no machine was registered since %s, check that the command ran on the node and that the node can reach Rancher", node.Since)
+	default:
+		node.Message = fmt.Sprintf("the node is registered but not Ready after %d seconds, wait again or check the logs of the rancher-system-agent service on the node", params.WaitSeconds)
+	}
+
+	return registrationResponse(log, node)
+}
+
+// nodeOptions returns the flags of the registration command setting the name, the addresses, the labels and the
+// taints of the node.
+func nodeOptions(params addCustomClusterNodeParams) string {
+	var options strings.Builder
+	if params.NodeName != "" {
+		options.WriteString(" --node-name " + shellQuote(params.NodeName))
+	}
+	if params.Address != "" {
+		options.WriteString(" --address " + shellQuote(params.Address))
+	}
+	if params.InternalAddress != "" {
+		options.WriteString(" --internal-address " + shellQuote(params.InternalAddress))
+	}
+	keys := make([]string, 0, len(params.Labels))
+	for key := range params.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		options.WriteString(" --label " + shellQuote(key+"="+params.Labels[key]))
+	}
+	for _, taint := range params.Taints {
+		options.WriteString(" --taints " + shellQuote(taint))
+	}
+
+	return options.String()
+}
+
+// shellQuote returns the value quoted for a shell, unchanged if it doesn't need quotes.
+func shellQuote(value string) string {
+	if shellSafeRegexp.MatchString(value) {
+		return value
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// newMachines returns the machines created since the given time, sorted by name. With a node name, the machines of
+// the other nodes are ignored, and the ones whose node isn't known yet are kept.
+func newMachines(machines []provisioningMachine, since time.Time, nodeName string) []registeredMachine {
+	registered := []registeredMachine{}
+	for _, m := range machines {
+		created := m.machine.GetCreationTimestamp()
+		if created.Time.Before(since) {
+			continue
+		}
+		machine := registeredMachine{Name: m.machine.GetName(), Roles: []string{}, Created: created.UTC().Format(time.RFC3339)}
+		machine.NodeName, _, _ = unstructured.NestedString(m.machine.Object, "status", "nodeRef", "name")
+		if nodeName != "" && machine.NodeName != "" && machine.NodeName != nodeName {
+			continue
+		}
+		machine.Phase, _, _ = unstructured.NestedString(m.machine.Object, "status", "phase")
+		for _, role := range registrationRoles {
+			if m.machine.GetLabels()[machineRoleLabels[role]] == "true" {
+				machine.Roles = append(machine.Roles, role)
+			}
+		}
+		for _, condition := range clusterConditions(m.machine) {
+			if condition.Type == "Ready" && condition.Status == string(metav1.ConditionTrue) {
+				machine.Ready = machine.NodeName != ""
+			}
+		}
+		if !machine.Ready {
+			machine.Pending = pendingConditions(m.machine)
+		}
+		registered = append(registered, machine)
+	}
+
+	return registered
+}
@@ -0,0 +1,166 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newCustomMachine(name string, created time.Time, nodeName string, phase string, conditions ...interface{}) *unstructured.Unstructured {
+	machine := newCAPIMachine(name, "fleet-default", "edge", phase, "")
+	machine.SetLabels(map[string]string{
+		"cluster.x-k8s.io/cluster-name": "edge",
+		"rke.cattle.io/worker-role":     "true",
+	})
+	machine.SetCreationTimestamp(metav1.NewTime(created))
+	status := map[string]interface{}{"phase": phase, "conditions": conditions}
+	if nodeName != "" {
+		status["nodeRef"] = map[string]interface{}{"kind": "Node", "name": nodeName}
+	}
+	machine.Object["status"] = status
+
+	return machine
+}
+
+func TestAddCustomClusterNode(t *testing.T) {
+	pollInterval := machinePollInterval
+	machinePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { machinePollInterval = pollInterval })
+
+	since := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	objects := append(registrationObjects(),
+		newCustomMachine("custom-old", since.Add(-time.Hour), "node-0", "Running", map[string]interface{}{"type": "Ready", "status": "True"}),
+		newCustomMachine("custom-new", since.Add(5*time.Minute), "node-1", "Running", map[string]interface{}{"type": "Ready", "status": "True"}),
+		newCustomMachine("custom-pending", since.Add(6*time.Minute), "", "Provisioning",
+			map[string]interface{}{"type": "Ready", "status": "False", "message": "waiting for the plan to be applied"}),
+	)
+
+	tests := map[string]struct {
+		params           addCustomClusterNodeParams
+		expectedCommand  string
+		expectedMachines []registeredMachine
+		expectedReady    bool
+		expectedMessage  string
+		expectedError    string
+	}{
+		"command with the node options": {
+			params: addCustomClusterNodeParams{
+				Cluster:  "edge",
+				Roles:    []string{workerRole},
+				NodeName: "node-2",
+				Address:  "203.0.113.10",
+				Labels:   map[string]string{"zone": "eu west", "disktype": "ssd"},
+				Taints:   []string{"dedicated=gpu:NoSchedule"},
+			},
+			expectedCommand:  testNodeCommand + " --worker --node-name node-2 --address 203.0.113.10 --label disktype=ssd --label 'zone=eu west' --taints dedicated=gpu:NoSchedule",
+			expectedMachines: []registeredMachine{},
+		},
+		"new machine Ready": {
+			params:          addCustomClusterNodeParams{Cluster: "edge", Roles: []string{workerRole}, Since: "2025-02-01T10:00:00Z", WaitSeconds: 1},
+			expectedCommand: testNodeCommand + " --worker",
+			expectedMachines: []registeredMachine{
+				{Name: "custom-new", NodeName: "node-1", Roles: []string{workerRole}, Phase: "Running", Created: "2025-02-01T10:05:00Z", Ready: true},
+				{Name: "custom-pending", Roles: []string{workerRole}, Phase: "Provisioning", Created: "2025-02-01T10:06:00Z",
+					Pending: []string{"condition Ready is False: waiting for the plan to be applied"}},
+			},
+			expectedReady:   true,
+			expectedMessage: "the node has joined the cluster",
+		},
+		"machine of the node not Ready": {
+			params:          addCustomClusterNodeParams{Cluster: "edge", Roles: []string{workerRole}, NodeName: "node-2", Since: "2025-02-01T10:00:00Z", WaitSeconds: 1},
+			expectedCommand: testNodeCommand + " --worker --node-name node-2",
+			expectedMachines: []registeredMachine{
+				{Name: "custom-pending", Roles: []string{workerRole}, Phase: "Provisioning", Created: "2025-02-01T10:06:00Z",
+					Pending: []string{"condition Ready is False: waiting for the plan to be applied"}},
+			},
+			expectedMessage: "the node is registered but not Ready after 1 seconds",
+		},
+		"no new machine": {
+			params:           addCustomClusterNodeParams{Cluster: "edge", Roles: []string{workerRole}, Since: "2025-03-01T00:00:00Z", WaitSeconds: 1},
+			expectedCommand:  testNodeCommand + " --worker",
+			expectedMachines: []registeredMachine{},
+			expectedMessage:  "no machine was registered since 2025-03-01T00:00:00Z",
+		},
+		"invalid since": {
+			params:        addCustomClusterNodeParams{Cluster: "edge", Roles: []string{workerRole}, Since: "yesterday", WaitSeconds: 1},
+			expectedError: `invalid since time "yesterday"`,
+		},
+		"wait too long": {
+			params:        addCustomClusterNodeParams{Cluster: "edge", Roles: []string{workerRole}, WaitSeconds: 600},
+			expectedError: "invalid waitSeconds 600, must be between 0 and 300",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newRegistrationClient(append([]runtime.Object{}, objects...)...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.addCustomClusterNode(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var node customClusterNode
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &node))
+			assert.Equal(t, test.expectedCommand, node.Command)
+			assert.Equal(t, test.expectedMachines, node.Machines)
+			assert.Equal(t, test.expectedReady, node.Ready)
+			assert.Contains(t, node.Message, test.expectedMessage)
+			if test.params.Since != "" {
+				assert.Equal(t, test.params.Since, node.Since)
+			} else {
+				assert.NotEmpty(t, node.Since)
+			}
+		})
+	}
+}
+
+func TestAddCustomClusterNodeWaitLongerThanTimeout(t *testing.T) {
+	pollInterval := machinePollInterval
+	machinePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { machinePollInterval = pollInterval })
+
+	c, _ := newRegistrationClient(append(registrationObjects(),
+		newCustomMachine("custom-pending", time.Date(2025, 2, 1, 10, 6, 0, 0, time.UTC), "", "Provisioning",
+			map[string]interface{}{"type": "Ready", "status": "False", "message": "waiting for the plan to be applied"}))...)
+	tools := Tools{client: c}
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		toolReq := req.(*mcp.CallToolRequest)
+		var params addCustomClusterNodeParams
+		if err := json.Unmarshal(toolReq.Params.Arguments, &params); err != nil {
+			return nil, err
+		}
+		result, _, err := tools.addCustomClusterNode(ctx, toolReq, params)
+		return result, err
+	}
+	// the wait of 1s is longer than the time limit of the tool
+	handler := middleware.TimeoutMiddleware(middleware.TimeoutConfig{Default: 100 * time.Millisecond})(next)
+
+	arguments, err := json.Marshal(addCustomClusterNodeParams{Cluster: "edge", Roles: []string{workerRole}, Since: "2025-02-01T10:00:00Z", WaitSeconds: 1})
+	require.NoError(t, err)
+	result, err := handler(middleware.WithToken(t.Context(), testToken), "tools/call", &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "addCustomClusterNode", Arguments: arguments},
+		Extra:  &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+	})
+
+	require.NoError(t, err)
+	toolResult := result.(*mcp.CallToolResult)
+	require.False(t, toolResult.IsError, "the wait shouldn't time out")
+	var node customClusterNode
+	require.NoError(t, json.Unmarshal([]byte(toolResult.Content[0].(*mcp.TextContent).Text), &node))
+	assert.Contains(t, node.Message, "the node is registered but not Ready after 1 seconds")
+}
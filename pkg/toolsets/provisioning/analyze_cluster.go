@@ -5,8 +5,10 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
 	"github.com/rancher/rancher-ai-mcp/pkg/response"
 	"github.com/rancher/rancher-ai-mcp/pkg/utils"
 	"go.uber.org/zap"
@@ -15,8 +17,9 @@ import (
 )
 
 type InspectClusterParams struct {
-	Cluster   string `json:"cluster" jsonschema:"the name of the provisioning cluster"`
-	Namespace string `json:"namespace" jsonschema:"the namespace of the resource"`
+	Cluster         string `json:"cluster" jsonschema:"the name of the provisioning cluster"`
+	Namespace       string `json:"namespace" jsonschema:"the namespace of the resource"`
+	FailureAnalysis bool   `json:"failureAnalysis,omitempty" jsonschema:"return the ranked root causes of the cluster being stuck in provisioning instead of its resources"`
 }
 
 // AnalyzeCluster returns a set of kubernetes resources that can be used to inspect the cluster for debugging and summary purposes.
@@ -29,7 +32,7 @@ func (t *Tools) AnalyzeCluster(ctx context.Context, toolReq *mcp.CallToolRequest
 		}
 	}
 
-	log := utils.NewChildLogger(toolReq, map[string]string{
+	log := utils.NewChildLogger(ctx, toolReq, map[string]string{
 		"cluster":   params.Cluster,
 		"namespace": ns,
 	})
@@ -52,109 +55,132 @@ func (t *Tools) AnalyzeCluster(ctx context.Context, toolReq *mcp.CallToolRequest
 		zap.String("provisioningCluster", provCluster.Name),
 		zap.String("clusterName", provCluster.Status.ClusterName))
 
-	var resources []*unstructured.Unstructured
-	resources = append(resources, provClusterResource)
+	if params.FailureAnalysis {
+		return t.analyzeClusterFailure(ctx, toolReq, log, provCluster)
+	}
+
+	// the resources related to the provisioning cluster are fetched concurrently, the ones that are not found are
+	// skipped and the ones that failed are reported with the others
+	var managementClusterResource, capiClusterResource *unstructured.Unstructured
+	var machineConfigs, machines, machineSets, machineDeployments []*unstructured.Unstructured
+	g := fetch.New(ctx, 0)
 
 	// get the management cluster, its status may be relevant.
 	// NB: Unlike the v1.Cluster object we can't directly import the v3.Cluster
 	// since it pulls in a lot of indirect dependencies (operators for aks, eks, gke, etc.)
-	log.Debug("fetching management cluster", zap.String("managementCluster", provCluster.Status.ClusterName))
-	managementClusterResource, err := t.client.GetResource(ctx, client.GetParams{
-		Cluster: LocalCluster,
-		Kind:    converter.ManagementClusterResourceKind,
-		// Unlike provisioning clusters, management cluster objects are cluster scoped.
-		Namespace: "",
-		Name:      provCluster.Status.ClusterName,
-		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     toolReq.Extra.Header.Get(tokenHeader),
-	})
-	if err != nil && !apierrors.IsNotFound(err) {
-		log.Error("failed to get management cluster",
-			zap.String("managementCluster", provCluster.Status.ClusterName),
-			zap.Error(err))
-		return nil, nil, err
-	}
-	if apierrors.IsNotFound(err) {
-		log.Debug("management cluster not found", zap.String("managementCluster", provCluster.Status.ClusterName))
-	}
-	if err == nil {
-		resources = append(resources, managementClusterResource)
+	g.GoOptional("managementCluster", func(ctx context.Context) error {
+		log.Debug("fetching management cluster", zap.String("managementCluster", provCluster.Status.ClusterName))
+		var err error
+		managementClusterResource, err = t.client.GetResource(ctx, client.GetParams{
+			Cluster: LocalCluster,
+			Kind:    converter.ManagementClusterResourceKind,
+			// Unlike provisioning clusters, management cluster objects are cluster scoped.
+			Namespace: "",
+			Name:      provCluster.Status.ClusterName,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		if apierrors.IsNotFound(err) {
+			log.Debug("management cluster not found", zap.String("managementCluster", provCluster.Status.ClusterName))
+			return nil
+		}
+		if err != nil {
+			log.Error("failed to get management cluster",
+				zap.String("managementCluster", provCluster.Status.ClusterName),
+				zap.Error(err))
+			return err
+		}
 		log.Debug("found management cluster", zap.String("managementCluster", provCluster.Status.ClusterName))
-	}
+		return nil
+	})
 
 	// get the CAPI cluster
-	log.Debug("fetching CAPI cluster", zap.String("capiCluster", provCluster.Name))
-	capiClusterResource, err := t.client.GetResourceAtAnyAPIVersion(ctx, client.GetParams{
-		Cluster:   LocalCluster,
-		Kind:      converter.CAPIClusterResourceKind,
-		Namespace: DefaultClusterResourcesNamespace,
-		Name:      provCluster.Name,
-		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     toolReq.Extra.Header.Get(tokenHeader),
-	})
-	if err != nil && !apierrors.IsNotFound(err) {
-		log.Error("failed to get CAPI cluster",
-			zap.String("capiCluster", provCluster.Name),
-			zap.Error(err))
-		return nil, nil, err
-	}
-	if apierrors.IsNotFound(err) {
-		log.Debug("CAPI cluster not found", zap.String("capiCluster", provCluster.Name))
-	}
-	if err == nil {
+	g.GoOptional("capiCluster", func(ctx context.Context) error {
+		log.Debug("fetching CAPI cluster", zap.String("capiCluster", provCluster.Name))
+		var err error
+		capiClusterResource, err = t.client.GetResourceAtAnyAPIVersion(ctx, client.GetParams{
+			Cluster:   LocalCluster,
+			Kind:      converter.CAPIClusterResourceKind,
+			Namespace: DefaultClusterResourcesNamespace,
+			Name:      provCluster.Name,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		if apierrors.IsNotFound(err) {
+			log.Debug("CAPI cluster not found", zap.String("capiCluster", provCluster.Name))
+			return nil
+		}
+		if err != nil {
+			log.Error("failed to get CAPI cluster",
+				zap.String("capiCluster", provCluster.Name),
+				zap.Error(err))
+			return err
+		}
 		log.Debug("found CAPI cluster", zap.String("capiCluster", provCluster.Name))
-		resources = append(resources, capiClusterResource)
-	}
+		return nil
+	})
 
 	// get all machine configs for node driver clusters.
-	log.Debug("fetching machine pool configs")
-	machineConfigs, err := t.getMachinePoolConfigs(ctx, toolReq, log, provCluster)
-	if err != nil && !apierrors.IsNotFound(err) {
-		log.Error("failed to get machine pool configs", zap.Error(err))
-		return nil, nil, err
-	}
-	if apierrors.IsNotFound(err) {
-		log.Debug("no machine pool configs found")
-	}
-	if err == nil && len(machineConfigs) > 0 {
-		log.Debug("found machine pool configs", zap.Int("count", len(machineConfigs)))
-		resources = append(resources, machineConfigs...)
-	}
+	g.GoOptional("machinePoolConfigs", func(ctx context.Context) error {
+		log.Debug("fetching machine pool configs")
+		configs, err := t.getMachinePoolConfigs(ctx, toolReq, log, provCluster)
+		if apierrors.IsNotFound(err) {
+			log.Debug("no machine pool configs found")
+			return nil
+		}
+		if err != nil {
+			log.Error("failed to get machine pool configs", zap.Error(err))
+			return err
+		}
+		log.Debug("found machine pool configs", zap.Int("count", len(configs)))
+		machineConfigs = configs
+		return nil
+	})
 
 	// get all the CAPI machine resources
-	log.Debug("fetching CAPI machine resources")
-	machines, machineSets, machineDeployments, err := t.getAllCAPIMachineResources(ctx, toolReq, log, getCAPIMachineResourcesParams{
-		namespace:     DefaultClusterResourcesNamespace,
-		targetCluster: params.Cluster,
-	})
-	if err != nil && !apierrors.IsNotFound(err) {
-		log.Error("failed to lookup CAPI machines", zap.Error(err))
-		return nil, nil, err
-	}
-	if apierrors.IsNotFound(err) {
-		log.Debug("CAPI machine resources not found")
-	}
-	if err == nil {
+	g.GoOptional("capiMachines", func(ctx context.Context) error {
+		log.Debug("fetching CAPI machine resources")
+		var err error
+		machines, machineSets, machineDeployments, err = t.getAllCAPIMachineResources(ctx, toolReq, log, getCAPIMachineResourcesParams{
+			namespace:     DefaultClusterResourcesNamespace,
+			targetCluster: params.Cluster,
+		})
+		if apierrors.IsNotFound(err) {
+			log.Debug("CAPI machine resources not found")
+			return nil
+		}
+		if err != nil {
+			log.Error("failed to lookup CAPI machines", zap.Error(err))
+			return err
+		}
 		log.Debug("found CAPI machine resources",
 			zap.Int("machines", len(machines)),
 			zap.Int("machineSets", len(machineSets)),
 			zap.Int("machineDeployments", len(machineDeployments)))
-	}
+		return nil
+	})
 
-	if machines != nil && len(machines) > 0 {
-		resources = append(resources, machines...)
+	failures, _ := g.Wait()
+
+	resources := []*unstructured.Unstructured{provClusterResource}
+	if managementClusterResource != nil {
+		resources = append(resources, managementClusterResource)
 	}
-	if machineSets != nil && len(machineSets) > 0 {
-		resources = append(resources, machineSets...)
+	if capiClusterResource != nil {
+		resources = append(resources, capiClusterResource)
 	}
-	if machineDeployments != nil && len(machineDeployments) > 0 {
-		resources = append(resources, machineDeployments...)
+	resources = append(resources, machineConfigs...)
+	resources = append(resources, machines...)
+	resources = append(resources, machineSets...)
+	resources = append(resources, machineDeployments...)
+	for _, failure := range failures {
+		resources = append(resources, failure.Unstructured())
 	}
 
 	log.Info("cluster analysis complete",
 		zap.Int("totalResources", len(resources)))
 
-	mcpResponse, err := response.CreateMcpResponse(resources, LocalCluster)
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, LocalCluster)
 	if err != nil {
 		log.Error("failed to create MCP response",
 			zap.Int("resourceCount", len(resources)),
@@ -0,0 +1,337 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	machineProvisionNamespace        = "cattle-system"
+	machineProvisionJobSuffix        = "-machine-provision"
+	machineProvisionLogsSinceMinutes = 24 * 60
+
+	infrastructureMachineSource = "infrastructureMachine"
+	machineProvisionJobSource   = "machineProvisionJob"
+	machineSource               = "machine"
+	rkeBootstrapSource          = "rkeBootstrap"
+	rkeControlPlaneSource       = "rkeControlPlane"
+)
+
+// provisionLogRE matches the lines of the logs of the machine-provision jobs reporting why the machine couldn't be
+// created, e.g. "Error creating machine: Error in driver during machine creation: ...".
+var provisionLogRE = regexp.MustCompile(`(?i)\b(error|failed|fatal)\b`)
+
+// rootCauseWeights ranks the sources of the root causes, the machines that can't be created by the infrastructure
+// provider are usually the reason the bootstrap and the control plane are waiting, so they come first.
+var rootCauseWeights = map[string]int{
+	infrastructureMachineSource: 0,
+	machineProvisionJobSource:   1,
+	machineSource:               2,
+	rkeBootstrapSource:          3,
+	rkeControlPlaneSource:       4,
+}
+
+type provisioningFailureAnalysis struct {
+	Cluster    string          `json:"cluster"`
+	Namespace  string          `json:"namespace"`
+	Ready      bool            `json:"ready"`
+	RootCauses []rootCause     `json:"rootCauses"`
+	Failures   []fetch.Failure `json:"failures,omitempty"`
+}
+
+type rootCause struct {
+	Rank     int      `json:"rank"`
+	Source   string   `json:"source"`
+	Cause    string   `json:"cause"`
+	Objects  []string `json:"objects"`
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+// provisioningMachine is a CAPI machine of the cluster with the infrastructure machine it references.
+type provisioningMachine struct {
+	machine        *unstructured.Unstructured
+	infrastructure *unstructured.Unstructured
+}
+
+// analyzeClusterFailure inspects the conditions of the RKEControlPlane and the RKEBootstraps of a provisioning
+// cluster, the errors of its machines and infrastructure machines and the logs of the failed machine-provision jobs
+// to return the likely root causes of the cluster being stuck in provisioning, the most likely first.
+func (t *Tools) analyzeClusterFailure(ctx context.Context, toolReq *mcp.CallToolRequest, log *zap.Logger, provCluster provisioningV1.Cluster) (*mcp.CallToolResult, any, error) {
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+
+	var controlPlane *unstructured.Unstructured
+	var bootstraps []*unstructured.Unstructured
+	var machines []provisioningMachine
+	var jobs []batchv1.Job
+	clusterSelector := metav1.FormatLabelSelector(&metav1.LabelSelector{
+		MatchLabels: map[string]string{"cluster.x-k8s.io/cluster-name": provCluster.Name},
+	})
+	g := fetch.New(ctx, 0)
+	g.GoOptional("rkeControlPlane", func(ctx context.Context) error {
+		var err error
+		controlPlane, err = t.client.GetResource(ctx, client.GetParams{
+			Cluster:   LocalCluster,
+			Kind:      "rkecontrolplane",
+			Namespace: provCluster.Namespace,
+			Name:      provCluster.Name,
+			URL:       url,
+			Token:     token,
+		})
+		if apierrors.IsNotFound(err) {
+			// imported and hosted clusters don't have a control plane managed by Rancher
+			return nil
+		}
+		return err
+	})
+	g.GoOptional("rkeBootstraps", func(ctx context.Context) error {
+		var err error
+		bootstraps, err = t.client.GetResources(ctx, client.ListParams{
+			Cluster:       LocalCluster,
+			Kind:          "rkebootstrap",
+			Namespace:     provCluster.Namespace,
+			LabelSelector: clusterSelector,
+			URL:           url,
+			Token:         token,
+		})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+	g.GoOptional("machines", func(ctx context.Context) error {
+		var err error
+		machines, err = t.provisioningMachines(ctx, url, token, provCluster.Namespace, clusterSelector)
+		return err
+	})
+	g.GoOptional("machineProvisionJobs", func(ctx context.Context) error {
+		return listTyped(ctx, t.client, client.ListParams{
+			Cluster:   LocalCluster,
+			Namespace: machineProvisionNamespace,
+			URL:       url,
+			Token:     token,
+		}, "job", &jobs)
+	})
+	failures, _ := g.Wait()
+
+	causes := map[string]*rootCause{}
+	addCause := func(source string, object string, cause string, evidence ...string) {
+		key := source + "/" + cause
+		if causes[key] == nil {
+			causes[key] = &rootCause{Source: source, Cause: cause}
+		}
+		causes[key].Objects = append(causes[key].Objects, object)
+		causes[key].Evidence = append(causes[key].Evidence, evidence...)
+	}
+
+	jobsByName := map[string]batchv1.Job{}
+	for _, job := range jobs {
+		jobsByName[job.Name] = job
+	}
+	var failedJobs []batchv1.Job
+	for _, machine := range machines {
+		if message, _, _ := unstructured.NestedString(machine.machine.Object, "status", "failureMessage"); message != "" {
+			addCause(machineSource, machine.machine.GetName(), message)
+		}
+		for _, condition := range pendingConditions(machine.machine) {
+			addCause(machineSource, machine.machine.GetName(), condition)
+		}
+		if machine.infrastructure == nil {
+			continue
+		}
+		infra := machine.infrastructure
+		object := infra.GetKind() + " " + infra.GetName()
+		reason, _, _ := unstructured.NestedString(infra.Object, "status", "failureReason")
+		message, _, _ := unstructured.NestedString(infra.Object, "status", "failureMessage")
+		if reason != "" || message != "" {
+			addCause(infrastructureMachineSource, object, strings.TrimPrefix(reason+": "+message, ": "))
+		}
+		for _, condition := range pendingConditions(infra) {
+			addCause(infrastructureMachineSource, object, condition)
+		}
+		jobName, _, _ := unstructured.NestedString(infra.Object, "status", "jobName")
+		if jobName == "" {
+			jobName = infra.GetName() + machineProvisionJobSuffix
+		}
+		if job, ok := jobsByName[jobName]; ok && jobFailed(job) {
+			failedJobs = append(failedJobs, job)
+		}
+	}
+
+	// the logs of the failed jobs have the errors of the machine driver, e.g. the missing permissions of the cloud
+	// credential or the exhausted quota, that the infrastructure machines only report as the job failing
+	logs := make([][]string, len(failedJobs))
+	logsGroup := fetch.New(ctx, 0)
+	for i, job := range failedJobs {
+		logsGroup.GoOptional("logs/"+job.Name, func(ctx context.Context) error {
+			var pods []corev1.Pod
+			if err := listTyped(ctx, t.client, client.ListParams{
+				Cluster:       LocalCluster,
+				Namespace:     job.Namespace,
+				LabelSelector: "job-name=" + job.Name,
+				URL:           url,
+				Token:         token,
+			}, "pod", &pods); err != nil {
+				return err
+			}
+			var err error
+			logs[i], err = t.matchingLogLines(ctx, url, token, LocalCluster, pods, machineProvisionLogsSinceMinutes, provisionLogRE)
+			return err
+		})
+	}
+	logsFailures, _ := logsGroup.Wait()
+	failures = append(failures, logsFailures...)
+	for i, job := range failedJobs {
+		addCause(machineProvisionJobSource, job.Name, "the machine-provision job failed: "+jobFailureMessage(job), logs[i]...)
+	}
+
+	for _, bootstrap := range bootstraps {
+		for _, condition := range pendingConditions(bootstrap) {
+			addCause(rkeBootstrapSource, bootstrap.GetName(), condition)
+		}
+	}
+	if controlPlane != nil {
+		for _, condition := range pendingConditions(controlPlane) {
+			addCause(rkeControlPlaneSource, controlPlane.GetName(), condition)
+		}
+	}
+
+	analysis := provisioningFailureAnalysis{
+		Cluster:    provCluster.Name,
+		Namespace:  provCluster.Namespace,
+		Ready:      provCluster.Status.Ready,
+		RootCauses: []rootCause{},
+		Failures:   failures,
+	}
+	for _, cause := range causes {
+		sort.Strings(cause.Objects)
+		analysis.RootCauses = append(analysis.RootCauses, *cause)
+	}
+	// the causes are ranked by their source, then by the number of objects they affect
+	sort.Slice(analysis.RootCauses, func(i, j int) bool {
+		a, b := analysis.RootCauses[i], analysis.RootCauses[j]
+		if rootCauseWeights[a.Source] != rootCauseWeights[b.Source] {
+			return rootCauseWeights[a.Source] < rootCauseWeights[b.Source]
+		}
+		if len(a.Objects) != len(b.Objects) {
+			return len(a.Objects) > len(b.Objects)
+		}
+		return a.Cause < b.Cause
+	})
+	for i := range analysis.RootCauses {
+		analysis.RootCauses[i].Rank = i + 1
+	}
+
+	log.Info("cluster failure analysis complete",
+		zap.Int("rootCauses", len(analysis.RootCauses)),
+		zap.Int("failures", len(analysis.Failures)))
+
+	response, err := json.Marshal(analysis)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// provisioningMachines returns the CAPI machines of the cluster with the infrastructure machines they reference. The
+// infrastructure machines that are not found yet are left empty.
+func (t *Tools) provisioningMachines(ctx context.Context, url string, token string, namespace string, clusterSelector string) ([]provisioningMachine, error) {
+	capiMachines, err := t.client.GetResourcesAtAnyAPIVersion(ctx, client.ListParams{
+		Cluster:       LocalCluster,
+		Kind:          converter.CAPIMachineResourceKind,
+		Namespace:     namespace,
+		LabelSelector: clusterSelector,
+		URL:           url,
+		Token:         token,
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+	sort.Slice(capiMachines, func(i, j int) bool { return capiMachines[i].GetName() < capiMachines[j].GetName() })
+
+	machines := make([]provisioningMachine, 0, len(capiMachines))
+	for _, capiMachine := range capiMachines {
+		machine := provisioningMachine{machine: capiMachine}
+		ref, _, _ := unstructured.NestedStringMap(capiMachine.Object, "spec", "infrastructureRef")
+		if ref["kind"] != "" && ref["name"] != "" {
+			gv, err := schema.ParseGroupVersion(ref["apiVersion"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid infrastructure reference of machine %s: %w", capiMachine.GetName(), err)
+			}
+			machine.infrastructure, err = t.client.GetResourceByGVR(ctx, client.GetParams{
+				Cluster:   LocalCluster,
+				Namespace: namespace,
+				Name:      ref["name"],
+				URL:       url,
+				Token:     token,
+			}, gv.WithResource(strings.ToLower(ref["kind"])+"s"))
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get %s %s: %w", ref["kind"], ref["name"], err)
+			}
+		}
+		machines = append(machines, machine)
+	}
+
+	return machines, nil
+}
+
+// pendingConditions returns the conditions of the object that aren't satisfied and have a message, which is why the
+// controllers are waiting.
+func pendingConditions(obj *unstructured.Unstructured) []string {
+	var pending []string
+	for _, condition := range clusterConditions(obj) {
+		if condition.Status == string(metav1.ConditionTrue) || condition.Message == "" {
+			continue
+		}
+		pending = append(pending, fmt.Sprintf("condition %s is %s: %s", condition.Type, condition.Status, condition.Message))
+	}
+
+	return pending
+}
+
+// jobFailed returns whether the job failed and won't be retried.
+func jobFailed(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return job.Status.Failed > 0 && job.Status.Active == 0
+}
+
+// jobFailureMessage returns the message of the Failed condition of the job.
+func jobFailureMessage(job batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Message != "" {
+			return condition.Message
+		}
+	}
+
+	return fmt.Sprintf("%d pods failed", job.Status.Failed)
+}
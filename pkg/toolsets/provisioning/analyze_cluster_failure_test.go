@@ -0,0 +1,165 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func newRKEObject(kind string, name string, clusterName string, conditions ...map[string]interface{}) *unstructured.Unstructured {
+	items := []interface{}{}
+	for _, condition := range conditions {
+		items = append(items, condition)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rke.cattle.io/v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "fleet-default",
+			"labels":    map[string]interface{}{"cluster.x-k8s.io/cluster-name": clusterName},
+		},
+		"status": map[string]interface{}{"conditions": items},
+	}}
+}
+
+func newInfrastructureMachine(name string, failureReason string, failureMessage string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rke-machine.cattle.io/v1",
+		"kind":       "Amazonec2Machine",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "fleet-default"},
+		"status": map[string]interface{}{
+			"jobName":        name + "-machine-provision",
+			"failureReason":  failureReason,
+			"failureMessage": failureMessage,
+		},
+	}}
+}
+
+func newProvisioningMachine(name string, clusterName string, infrastructureName string) *unstructured.Unstructured {
+	machine := newCAPIMachine(name, "fleet-default", clusterName, "Provisioning", "")
+	machine.Object["spec"].(map[string]interface{})["infrastructureRef"] = map[string]interface{}{
+		"apiVersion": "rke-machine.cattle.io/v1",
+		"kind":       "Amazonec2Machine",
+		"name":       infrastructureName,
+	}
+	return machine
+}
+
+func newMachineProvisionJob(t *testing.T, name string, failed bool) runtime.Object {
+	status := batchv1.JobStatus{Active: 1}
+	if failed {
+		status = batchv1.JobStatus{Failed: 3, Conditions: []batchv1.JobCondition{{
+			Type:    batchv1.JobFailed,
+			Status:  corev1.ConditionTrue,
+			Message: "Job has reached the specified backoff limit",
+		}}}
+	}
+	return toUnstructured(t, &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cattle-system"},
+		Status:     status,
+	}, "batch/v1", "Job")
+}
+
+func TestAnalyzeClusterFailure(t *testing.T) {
+	listKinds := capiCustomListKinds()
+	listKinds[schema.GroupVersionResource{Group: "rke.cattle.io", Version: "v1", Resource: "rkebootstraps"}] = "RKEBootstrapList"
+	listKinds[schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}] = "JobList"
+	listKinds[schema.GroupVersionResource{Version: "v1", Resource: "pods"}] = "PodList"
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), listKinds,
+		newProvisioningCluster("test-cluster", "fleet-default", "c-m-abc123"),
+		newRKEObject("RKEControlPlane", "test-cluster", "test-cluster",
+			map[string]interface{}{"type": "Ready", "status": "True"},
+			map[string]interface{}{"type": "Provisioned", "status": "Unknown", "message": "waiting for viable init node"},
+		),
+		newRKEObject("RKEBootstrap", "test-cluster-pool1-bootstrap-a", "test-cluster",
+			map[string]interface{}{"type": "Ready", "status": "False", "message": "waiting for machine to be provisioned"},
+		),
+		newRKEObject("RKEBootstrap", "other-cluster-pool1-bootstrap-a", "other-cluster",
+			map[string]interface{}{"type": "Ready", "status": "False", "message": "waiting for machine to be provisioned"},
+		),
+		newProvisioningMachine("test-cluster-pool1-a", "test-cluster", "test-cluster-pool1-a"),
+		newProvisioningMachine("test-cluster-pool1-b", "test-cluster", "test-cluster-pool1-b"),
+		newProvisioningMachine("test-cluster-pool2-a", "test-cluster", "test-cluster-pool2-a"),
+		newInfrastructureMachine("test-cluster-pool1-a", "CreateError", "failed to create machine: UnauthorizedOperation"),
+		newInfrastructureMachine("test-cluster-pool1-b", "CreateError", "failed to create machine: UnauthorizedOperation"),
+		newMachineProvisionJob(t, "test-cluster-pool1-a-machine-provision", true),
+		newMachineProvisionJob(t, "test-cluster-pool1-b-machine-provision", true),
+		newMachineProvisionJob(t, "other-cluster-pool1-a-machine-provision", true),
+	)
+	c := &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return newFakeClientsetWithCAPIDiscovery(), nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+	tools := Tools{client: c}
+
+	result, _, err := tools.AnalyzeCluster(middleware.WithToken(context.TODO(), testToken), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "analyze-cluster"},
+		Extra:  &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+	}, InspectClusterParams{Cluster: "test-cluster", Namespace: "fleet-default", FailureAnalysis: true})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"cluster": "test-cluster",
+		"namespace": "fleet-default",
+		"ready": true,
+		"rootCauses": [
+			{
+				"rank": 1,
+				"source": "infrastructureMachine",
+				"cause": "CreateError: failed to create machine: UnauthorizedOperation",
+				"objects": ["Amazonec2Machine test-cluster-pool1-a", "Amazonec2Machine test-cluster-pool1-b"]
+			},
+			{
+				"rank": 2,
+				"source": "machineProvisionJob",
+				"cause": "the machine-provision job failed: Job has reached the specified backoff limit",
+				"objects": ["test-cluster-pool1-a-machine-provision", "test-cluster-pool1-b-machine-provision"]
+			},
+			{
+				"rank": 3,
+				"source": "rkeBootstrap",
+				"cause": "condition Ready is False: waiting for machine to be provisioned",
+				"objects": ["test-cluster-pool1-bootstrap-a"]
+			},
+			{
+				"rank": 4,
+				"source": "rkeControlPlane",
+				"cause": "condition Provisioned is Unknown: waiting for viable init node",
+				"objects": ["test-cluster"]
+			}
+		]
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestProvisionLogRE(t *testing.T) {
+	tests := map[string]bool{
+		`Error creating machine: Error in driver during machine creation: UnauthorizedOperation: You are not authorized to perform this operation.`: true,
+		`failed to create instance: InsufficientInstanceCapacity`:                                                                                   true,
+		`Creating machine...`:                          false,
+		`(test-cluster-pool1-a) Launching instance...`: false,
+	}
+
+	for line, expected := range tests {
+		assert.Equal(t, expected, provisionLogRE.MatchString(line), line)
+	}
+}
@@ -23,7 +23,7 @@ func (t *Tools) AnalyzeClusterMachines(ctx context.Context, toolReq *mcp.CallToo
 		ns = "fleet-default"
 	}
 
-	log := utils.NewChildLogger(toolReq, map[string]string{
+	log := utils.NewChildLogger(ctx, toolReq, map[string]string{
 		"cluster":   params.Cluster,
 		"namespace": params.Namespace,
 	})
@@ -52,7 +52,7 @@ func (t *Tools) AnalyzeClusterMachines(ctx context.Context, toolReq *mcp.CallToo
 	}
 
 	// all CAPI resources exist in the local cluster only.
-	mcpResponse, err := response.CreateMcpResponse(resources, LocalCluster)
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, LocalCluster)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -482,11 +483,11 @@ func TestAnalyzeClusterMachines(t *testing.T) {
 			}
 			tools := Tools{client: c}
 
-			result, _, err := tools.AnalyzeClusterMachines(context.TODO(), &mcp.CallToolRequest{
+			result, _, err := tools.AnalyzeClusterMachines(middleware.WithToken(context.TODO(), fakeToken), &mcp.CallToolRequest{
 				Params: &mcp.CallToolParamsRaw{
 					Name: "analyze-cluster-machines",
 				},
-				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}, tokenHeader: {fakeToken}}},
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
 			}, test.params)
 
 			if test.expectedError != "" {
@@ -2,16 +2,23 @@ package provisioning
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestAnalyzeCluster(t *testing.T) {
@@ -1448,11 +1455,11 @@ func TestAnalyzeCluster(t *testing.T) {
 			}
 			tools := Tools{client: c}
 
-			result, _, err := tools.AnalyzeCluster(context.TODO(), &mcp.CallToolRequest{
+			result, _, err := tools.AnalyzeCluster(middleware.WithToken(context.TODO(), testToken), &mcp.CallToolRequest{
 				Params: &mcp.CallToolParamsRaw{
 					Name: "analyze-cluster",
 				},
-				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}, tokenHeader: {testToken}}},
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
 			}, test.params)
 
 			if test.expectedError != "" {
@@ -1469,3 +1476,46 @@ func TestAnalyzeCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeClusterPartialResult(t *testing.T) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(),
+		newProvisioningCluster("test-cluster", "fleet-default", "c-m-abc123"),
+		newCAPICluster("test-cluster", "fleet-default"),
+		newManagementCluster("c-m-abc123", true),
+	)
+	fakeDynClient.PrependReactor("get", "clusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Group != "management.cattle.io" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(action.GetResource().GroupResource(), "c-m-abc123", errors.New("access denied"))
+	})
+	c := &client.Client{
+		ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+			return newFakeClientsetWithCAPIDiscovery(), nil
+		},
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+	tools := Tools{client: c}
+
+	result, _, err := tools.AnalyzeCluster(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "analyze-cluster"},
+		Extra:  &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+	}, InspectClusterParams{Cluster: "test-cluster", Namespace: "fleet-default"})
+
+	require.NoError(t, err)
+	var resp struct {
+		LLM []map[string]any `json:"llm"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+	var kinds []any
+	for _, item := range resp.LLM {
+		kinds = append(kinds, item["kind"])
+	}
+	assert.Equal(t, []any{"Cluster", "Cluster", nil}, kinds, "the provisioning and CAPI clusters should be returned with the failure")
+	assert.Equal(t, map[string]any{
+		"fetch": "managementCluster",
+		"error": `clusters.management.cattle.io "c-m-abc123" is forbidden: access denied`,
+	}, resp.LLM[2])
+}
@@ -0,0 +1,339 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/deprecations"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	goDecision   = "go"
+	noGoDecision = "no-go"
+	// deprecatedAPIsMetric is the metric of the API server counting the requests to deprecated APIs since it started,
+	// with the release removing them.
+	deprecatedAPIsMetric = "apiserver_requested_deprecated_apis"
+)
+
+// metricLabelsRE matches the labels of a metric sample, e.g. group="batch".
+var metricLabelsRE = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+type analyzeUpgradeImpactParams struct {
+	Cluster       string `json:"cluster" jsonschema:"the ID or the name of the cluster to upgrade"`
+	TargetVersion string `json:"targetVersion" jsonschema:"the Kubernetes version of the upgrade, e.g. v1.31.4+rke2r1"`
+}
+
+type upgradeImpact struct {
+	Cluster        string `json:"cluster"`
+	CurrentVersion string `json:"currentVersion"`
+	TargetVersion  string `json:"targetVersion"`
+	// Decision is no-go if any blocker was found.
+	Decision          string             `json:"decision"`
+	Blockers          []string           `json:"blockers"`
+	Warnings          []string           `json:"warnings"`
+	RemovedAPIs       []removedAPIUsage  `json:"removedAPIs"`
+	CRDStoredVersions []crdStoredVersion `json:"crdStoredVersions"`
+	BlockingPDBs      []blockingPDB      `json:"blockingPDBs"`
+	PinnedWorkloads   []pinnedWorkload   `json:"pinnedWorkloads"`
+}
+
+// removedAPIUsage is a deprecated API requested since the API server started and removed by the upgrade.
+type removedAPIUsage struct {
+	Group          string `json:"group"`
+	Version        string `json:"version"`
+	Resource       string `json:"resource"`
+	RemovedRelease string `json:"removedRelease"`
+	Replacement    string `json:"replacement,omitempty"`
+}
+
+// crdStoredVersion is a CRD with objects stored at versions it no longer serves, which must be migrated before the
+// versions are removed from the CRD.
+type crdStoredVersion struct {
+	Name              string   `json:"name"`
+	StoredVersions    []string `json:"storedVersions"`
+	NotServedVersions []string `json:"notServedVersions"`
+}
+
+// blockingPDB is a PodDisruptionBudget that doesn't allow any disruption, which blocks the drain of its nodes.
+type blockingPDB struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	CurrentHealthy     int32  `json:"currentHealthy"`
+	DesiredHealthy     int32  `json:"desiredHealthy"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+}
+
+// pinnedWorkload is a workload whose pods can only run on the nodes with a given hostname or version.
+type pinnedWorkload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Selector  string `json:"selector"`
+}
+
+// analyzeUpgradeImpact checks whether a Kubernetes upgrade of a cluster can be done: the version skew, the removed
+// APIs still requested, the PodDisruptionBudgets blocking the drains and the workloads pinned to nodes.
+func (t *Tools) analyzeUpgradeImpact(ctx context.Context, toolReq *mcp.CallToolRequest, params analyzeUpgradeImpactParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "analyzeUpgradeImpact"), zap.String("cluster", params.Cluster))
+	log.Debug("analyzeUpgradeImpact called")
+
+	targetVersion, err := version.ParseGeneric(params.TargetVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid target version %q, must be a Kubernetes version like v1.31.4+rke2r1", params.TargetVersion)
+	}
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	managementCluster, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: LocalCluster,
+		Kind:    converter.ManagementClusterResourceKind,
+		Name:    clusterID,
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		log.Error("failed to get management cluster", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get cluster %s: %w", params.Cluster, err)
+	}
+	gitVersion, _, _ := unstructured.NestedString(managementCluster.Object, "status", "version", "gitVersion")
+	currentVersion, err := version.ParseGeneric(gitVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cluster %s doesn't report its Kubernetes version", params.Cluster)
+	}
+
+	impact := upgradeImpact{
+		Cluster:           params.Cluster,
+		CurrentVersion:    gitVersion,
+		TargetVersion:     params.TargetVersion,
+		Blockers:          []string{},
+		Warnings:          []string{},
+		RemovedAPIs:       []removedAPIUsage{},
+		CRDStoredVersions: []crdStoredVersion{},
+		BlockingPDBs:      []blockingPDB{},
+		PinnedWorkloads:   []pinnedWorkload{},
+	}
+	switch {
+	case targetVersion.Major() != currentVersion.Major() || targetVersion.Minor() > currentVersion.Minor()+1:
+		impact.Blockers = append(impact.Blockers, fmt.Sprintf("the upgrade from %s to %s skips minor versions, upgrade one minor version at a time", gitVersion, params.TargetVersion))
+	case targetVersion.LessThan(currentVersion):
+		impact.Blockers = append(impact.Blockers, fmt.Sprintf("%s is older than the current version %s, Kubernetes doesn't support downgrades", params.TargetVersion, gitVersion))
+	}
+
+	removedAPIs, err := t.removedAPIRequests(ctx, url, token, clusterID, currentVersion, targetVersion)
+	if err != nil {
+		log.Warn("failed to get the deprecated API requests", zap.Error(err))
+		impact.Warnings = append(impact.Warnings, fmt.Sprintf("the requests to deprecated APIs can't be read from the API server metrics: %v", err))
+	}
+	impact.RemovedAPIs = append(impact.RemovedAPIs, removedAPIs...)
+	for _, api := range removedAPIs {
+		impact.Blockers = append(impact.Blockers, fmt.Sprintf("%s/%s %s is removed in Kubernetes %s and is still requested", api.Group, api.Version, api.Resource, api.RemovedRelease))
+	}
+
+	listParams := client.ListParams{Cluster: clusterID, URL: url, Token: token}
+	var crds []apiextensionsv1.CustomResourceDefinition
+	if err := listTyped(ctx, t.client, listParams, "crd", &crds); err != nil {
+		log.Error("failed to list CRDs", zap.Error(err))
+		return nil, nil, err
+	}
+	for _, crd := range crds {
+		served := map[string]bool{}
+		for _, v := range crd.Spec.Versions {
+			served[v.Name] = v.Served
+		}
+		var notServed []string
+		for _, storedVersion := range crd.Status.StoredVersions {
+			if !served[storedVersion] {
+				notServed = append(notServed, storedVersion)
+			}
+		}
+		if len(notServed) > 0 {
+			impact.CRDStoredVersions = append(impact.CRDStoredVersions, crdStoredVersion{Name: crd.Name, StoredVersions: crd.Status.StoredVersions, NotServedVersions: notServed})
+			impact.Warnings = append(impact.Warnings, fmt.Sprintf("CRD %s has objects stored at versions it no longer serves: %s", crd.Name, strings.Join(notServed, ", ")))
+		}
+	}
+
+	var pdbs []policyv1.PodDisruptionBudget
+	if err := listTyped(ctx, t.client, listParams, "poddisruptionbudget", &pdbs); err != nil {
+		log.Error("failed to list PodDisruptionBudgets", zap.Error(err))
+		return nil, nil, err
+	}
+	for _, pdb := range pdbs {
+		if pdb.Status.ExpectedPods == 0 || pdb.Status.DisruptionsAllowed > 0 {
+			continue
+		}
+		impact.BlockingPDBs = append(impact.BlockingPDBs, blockingPDB{
+			Namespace:          pdb.Namespace,
+			Name:               pdb.Name,
+			CurrentHealthy:     pdb.Status.CurrentHealthy,
+			DesiredHealthy:     pdb.Status.DesiredHealthy,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+		})
+		impact.Blockers = append(impact.Blockers, fmt.Sprintf("PodDisruptionBudget %s/%s doesn't allow any disruption and blocks the drain of the nodes of its pods", pdb.Namespace, pdb.Name))
+	}
+
+	pinned, err := t.pinnedWorkloads(ctx, listParams)
+	if err != nil {
+		log.Error("failed to list workloads", zap.Error(err))
+		return nil, nil, err
+	}
+	impact.PinnedWorkloads = append(impact.PinnedWorkloads, pinned...)
+	for _, workload := range pinned {
+		impact.Warnings = append(impact.Warnings, fmt.Sprintf("%s %s/%s is pinned to nodes with %s, its pods can't be rescheduled on other nodes during the drains", workload.Kind, workload.Namespace, workload.Name, workload.Selector))
+	}
+
+	impact.Decision = goDecision
+	if len(impact.Blockers) > 0 {
+		impact.Decision = noGoDecision
+	}
+
+	response, err := json.Marshal(impact)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// removedAPIRequests returns the deprecated APIs requested since the API server started that are removed after the
+// current version and up to the target version.
+func (t *Tools) removedAPIRequests(ctx context.Context, url string, token string, clusterID string, currentVersion *version.Version, targetVersion *version.Version) ([]removedAPIUsage, error) {
+	metrics, err := t.client.RancherGet(ctx, client.RancherParams{
+		Path:  "/k8s/clusters/" + clusterID + "/metrics",
+		URL:   url,
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []removedAPIUsage
+	for _, line := range strings.Split(string(metrics), "\n") {
+		if !strings.HasPrefix(line, deprecatedAPIsMetric+"{") {
+			continue
+		}
+		labels := map[string]string{}
+		for _, match := range metricLabelsRE.FindAllStringSubmatch(line, -1) {
+			labels[match[1]] = match[2]
+		}
+		removedRelease, err := version.ParseGeneric(labels["removed_release"])
+		if err != nil || !currentVersion.LessThan(removedRelease) || !removedRelease.LessThan(nextMinor(targetVersion)) {
+			continue
+		}
+		api := removedAPIUsage{
+			Group:          labels["group"],
+			Version:        labels["version"],
+			Resource:       labels["resource"],
+			RemovedRelease: labels["removed_release"],
+		}
+		if deprecation, ok := deprecations.LookupResource(api.Group, api.Version, api.Resource); ok {
+			api.Replacement = deprecation.Replacement
+		}
+		if api.Group == "" {
+			api.Group = "core"
+		}
+		if !slices.Contains(removed, api) {
+			removed = append(removed, api)
+		}
+	}
+
+	return removed, nil
+}
+
+// pinnedWorkloads returns the deployments, statefulsets and daemonsets whose pods must run on nodes with a given
+// hostname or version, with a node selector or a required node affinity.
+func (t *Tools) pinnedWorkloads(ctx context.Context, listParams client.ListParams) ([]pinnedWorkload, error) {
+	var pinned []pinnedWorkload
+	check := func(kind string, namespace string, name string, spec corev1.PodSpec) {
+		for key, value := range spec.NodeSelector {
+			if isPinningLabel(key) {
+				pinned = append(pinned, pinnedWorkload{Kind: kind, Namespace: namespace, Name: name, Selector: key + "=" + value})
+			}
+		}
+		if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+			return
+		}
+		for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, expression := range term.MatchExpressions {
+				if isPinningLabel(expression.Key) && expression.Operator == corev1.NodeSelectorOpIn {
+					pinned = append(pinned, pinnedWorkload{Kind: kind, Namespace: namespace, Name: name, Selector: expression.Key + " in (" + strings.Join(expression.Values, ", ") + ")"})
+				}
+			}
+		}
+	}
+
+	var deployments []appsv1.Deployment
+	if err := listTyped(ctx, t.client, listParams, "deployment", &deployments); err != nil {
+		return nil, err
+	}
+	for _, d := range deployments {
+		check("Deployment", d.Namespace, d.Name, d.Spec.Template.Spec)
+	}
+	var statefulSets []appsv1.StatefulSet
+	if err := listTyped(ctx, t.client, listParams, "statefulset", &statefulSets); err != nil {
+		return nil, err
+	}
+	for _, s := range statefulSets {
+		check("StatefulSet", s.Namespace, s.Name, s.Spec.Template.Spec)
+	}
+	var daemonSets []appsv1.DaemonSet
+	if err := listTyped(ctx, t.client, listParams, "daemonset", &daemonSets); err != nil {
+		return nil, err
+	}
+	for _, d := range daemonSets {
+		check("DaemonSet", d.Namespace, d.Name, d.Spec.Template.Spec)
+	}
+
+	return pinned, nil
+}
+
+// isPinningLabel returns whether a node label identifies a single node or a Kubernetes version, which changes
+// during the upgrade.
+func isPinningLabel(key string) bool {
+	return key == corev1.LabelHostname || strings.Contains(strings.ToLower(key), "version")
+}
+
+// listTyped lists the resources of a kind in all the namespaces and converts them to the type of the items of list.
+func listTyped[T any](ctx context.Context, c *client.Client, params client.ListParams, kind string, list *[]T) error {
+	params.Kind = kind
+	objs, err := c.GetResources(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to list %ss: %w", kind, err)
+	}
+	for _, obj := range objs {
+		var item T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &item); err != nil {
+			return fmt.Errorf("failed to convert %s %s: %w", kind, obj.GetName(), err)
+		}
+		*list = append(*list, item)
+	}
+
+	return nil
+}
+
+// nextMinor returns the first version of the minor version following the one of v.
+func nextMinor(v *version.Version) *version.Version {
+	return version.MustParseGeneric(strconv.Itoa(int(v.Major())) + "." + strconv.Itoa(int(v.Minor())+1) + ".0")
+}
@@ -0,0 +1,200 @@
+package provisioning
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const fakeAPIServerMetrics = `# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested, broken out by API group, version, resource, subresource, and removed_release.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="flowschemas",subresource="",version="v1beta3"} 1
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="flowschemas",subresource="status",version="v1beta3"} 1
+apiserver_requested_deprecated_apis{group="storage.k8s.io",removed_release="1.35",resource="volumeattributesclasses",subresource="",version="v1beta1"} 1
+apiserver_request_total{code="200",verb="GET"} 42
+`
+
+func toUnstructured(t *testing.T, obj runtime.Object, apiVersion string, kind string) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	u := &unstructured.Unstructured{Object: content}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	return u
+}
+
+func TestAnalyzeUpgradeImpact(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/k8s/clusters/c-m-abc12/metrics" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(fakeAPIServerMetrics))
+	}))
+	defer srv.Close()
+
+	objects := []runtime.Object{
+		newVersionedCluster("c-m-abc12", "prod", "rke2", "v1.31.4+rke2r1"),
+		newVersionedCluster("c-m-def34", "dev", "rke2", "v1.31.4+rke2r1"),
+		toUnstructured(t, &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: false},
+				{Name: "v1", Served: true, Storage: true},
+			}},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1alpha1", "v1"}},
+		}, "apiextensions.k8s.io/v1", "CustomResourceDefinition"),
+		toUnstructured(t, &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: 1, CurrentHealthy: 1, DesiredHealthy: 1},
+		}, "policy/v1", "PodDisruptionBudget"),
+		toUnstructured(t, &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 3, DesiredHealthy: 2, DisruptionsAllowed: 1},
+		}, "policy/v1", "PodDisruptionBudget"),
+		toUnstructured(t, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+			}}},
+		}, "apps/v1", "Deployment"),
+		toUnstructured(t, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"kubernetes.io/hostname": "worker-1"},
+			}}},
+		}, "apps/v1", "StatefulSet"),
+		toUnstructured(t, &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "example.com/kubelet-version", Operator: corev1.NodeSelectorOpIn, Values: []string{"v1.31.4"}},
+					}}},
+				}}},
+			}}},
+		}, "apps/v1", "DaemonSet"),
+	}
+
+	tests := map[string]struct {
+		params         analyzeUpgradeImpactParams
+		expectedResult string
+		expectedError  string
+	}{
+		"no-go": {
+			params: analyzeUpgradeImpactParams{Cluster: "prod", TargetVersion: "v1.32.1+rke2r1"},
+			expectedResult: `{
+				"cluster": "prod",
+				"currentVersion": "v1.31.4+rke2r1",
+				"targetVersion": "v1.32.1+rke2r1",
+				"decision": "no-go",
+				"blockers": [
+					"flowcontrol.apiserver.k8s.io/v1beta3 flowschemas is removed in Kubernetes 1.32 and is still requested",
+					"PodDisruptionBudget default/db doesn't allow any disruption and blocks the drain of the nodes of its pods"
+				],
+				"warnings": [
+					"CRD widgets.example.com has objects stored at versions it no longer serves: v1alpha1",
+					"StatefulSet default/db is pinned to nodes with kubernetes.io/hostname=worker-1, its pods can't be rescheduled on other nodes during the drains",
+					"DaemonSet kube-system/agent is pinned to nodes with example.com/kubelet-version in (v1.31.4), its pods can't be rescheduled on other nodes during the drains"
+				],
+				"removedAPIs": [
+					{"group": "flowcontrol.apiserver.k8s.io", "version": "v1beta3", "resource": "flowschemas", "removedRelease": "1.32", "replacement": "flowcontrol.apiserver.k8s.io/v1"}
+				],
+				"crdStoredVersions": [
+					{"name": "widgets.example.com", "storedVersions": ["v1alpha1", "v1"], "notServedVersions": ["v1alpha1"]}
+				],
+				"blockingPDBs": [
+					{"namespace": "default", "name": "db", "currentHealthy": 1, "desiredHealthy": 1, "disruptionsAllowed": 0}
+				],
+				"pinnedWorkloads": [
+					{"kind": "StatefulSet", "namespace": "default", "name": "db", "selector": "kubernetes.io/hostname=worker-1"},
+					{"kind": "DaemonSet", "namespace": "kube-system", "name": "agent", "selector": "example.com/kubelet-version in (v1.31.4)"}
+				]
+			}`,
+		},
+		"skipped minor and metrics not readable": {
+			params: analyzeUpgradeImpactParams{Cluster: "c-m-def34", TargetVersion: "v1.33.0+rke2r1"},
+			expectedResult: `{
+				"cluster": "c-m-def34",
+				"currentVersion": "v1.31.4+rke2r1",
+				"targetVersion": "v1.33.0+rke2r1",
+				"decision": "no-go",
+				"blockers": [
+					"the upgrade from v1.31.4+rke2r1 to v1.33.0+rke2r1 skips minor versions, upgrade one minor version at a time",
+					"PodDisruptionBudget default/db doesn't allow any disruption and blocks the drain of the nodes of its pods"
+				],
+				"warnings": [
+					"the requests to deprecated APIs can't be read from the API server metrics: GET /k8s/clusters/c-m-def34/metrics failed with status 403 Forbidden: ",
+					"CRD widgets.example.com has objects stored at versions it no longer serves: v1alpha1",
+					"StatefulSet default/db is pinned to nodes with kubernetes.io/hostname=worker-1, its pods can't be rescheduled on other nodes during the drains",
+					"DaemonSet kube-system/agent is pinned to nodes with example.com/kubelet-version in (v1.31.4), its pods can't be rescheduled on other nodes during the drains"
+				],
+				"removedAPIs": [],
+				"crdStoredVersions": [
+					{"name": "widgets.example.com", "storedVersions": ["v1alpha1", "v1"], "notServedVersions": ["v1alpha1"]}
+				],
+				"blockingPDBs": [
+					{"namespace": "default", "name": "db", "currentHealthy": 1, "desiredHealthy": 1, "disruptionsAllowed": 0}
+				],
+				"pinnedWorkloads": [
+					{"kind": "StatefulSet", "namespace": "default", "name": "db", "selector": "kubernetes.io/hostname=worker-1"},
+					{"kind": "DaemonSet", "namespace": "kube-system", "name": "agent", "selector": "example.com/kubelet-version in (v1.31.4)"}
+				]
+			}`,
+		},
+		"invalid version": {
+			params:        analyzeUpgradeImpactParams{Cluster: "prod", TargetVersion: "latest"},
+			expectedError: `invalid target version "latest", must be a Kubernetes version like v1.31.4+rke2r1`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:                  "ClusterList",
+				{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}: "CustomResourceDefinitionList",
+				{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}:                    "PodDisruptionBudgetList",
+				{Group: "apps", Version: "v1", Resource: "deployments"}:                               "DeploymentList",
+				{Group: "apps", Version: "v1", Resource: "statefulsets"}:                              "StatefulSetList",
+				{Group: "apps", Version: "v1", Resource: "daemonsets"}:                                "DaemonSetList",
+			}, objects...)
+			c := client.NewClient(true)
+			c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+				return fakeDynClient, nil
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.analyzeUpgradeImpact(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
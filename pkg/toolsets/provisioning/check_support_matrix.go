@@ -0,0 +1,281 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	supportedStatus   = "supported"
+	unsupportedStatus = "unsupported"
+	unknownStatus     = "unknown"
+)
+
+// systemCharts are the charts of the components installed by Rancher in the local cluster.
+var systemCharts = []string{
+	"fleet",
+	"fleet-crd",
+	"rancher-webhook",
+	"rancher-provisioning-capi",
+	"rancher-turtles",
+	"system-upgrade-controller",
+	"rancher-aks-operator",
+	"rancher-eks-operator",
+	"rancher-gke-operator",
+}
+
+// kdmDistributions are the Kubernetes distributions provisioned by Rancher, with the path of their KDM releases
+// compatible with the Rancher server version.
+var kdmDistributions = map[string]string{
+	"rke2": "/v1-rke2-release/releases",
+	"k3s":  "/v1-k3s-release/releases",
+}
+
+type checkSupportMatrixParams struct {
+	Clusters []string `json:"clusters,omitempty" jsonschema:"the clusters to check, all the clusters if empty"`
+}
+
+type supportMatrix struct {
+	RancherVersion string        `json:"rancherVersion"`
+	SystemCharts   []systemChart `json:"systemCharts"`
+	// AvailableVersions contains the latest RKE2 and K3s release of each Kubernetes minor version available in KDM.
+	AvailableVersions map[string][]string `json:"availableVersions"`
+	// MinKubernetesVersion and MaxKubernetesVersion are the oldest and the newest Kubernetes minor versions available.
+	MinKubernetesVersion string           `json:"minKubernetesVersion,omitempty"`
+	MaxKubernetesVersion string           `json:"maxKubernetesVersion,omitempty"`
+	Clusters             []clusterSupport `json:"clusters"`
+}
+
+type systemChart struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
+	State      string `json:"state,omitempty"`
+}
+
+type clusterSupport struct {
+	Cluster           string `json:"cluster"`
+	DisplayName       string `json:"displayName,omitempty"`
+	Provider          string `json:"provider,omitempty"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Status is supported, unsupported or unknown if the Kubernetes version of the cluster isn't reported.
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type kdmReleases struct {
+	Data []struct {
+		Version string `json:"version"`
+	} `json:"data"`
+}
+
+// checkSupportMatrix reports the version of Rancher and of its system charts, and checks the Kubernetes versions of
+// the clusters against the RKE2 and K3s releases of KDM, which Rancher filters by the versions it supports.
+func (t *Tools) checkSupportMatrix(ctx context.Context, toolReq *mcp.CallToolRequest, params checkSupportMatrixParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "checkSupportMatrix"))
+	log.Debug("checkSupportMatrix called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	serverVersion, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: LocalCluster,
+		Kind:    "setting",
+		Name:    "server-version",
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		log.Error("failed to get the server version", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get the Rancher server version: %w", err)
+	}
+	matrix := supportMatrix{
+		SystemCharts:      []systemChart{},
+		AvailableVersions: map[string][]string{},
+		Clusters:          []clusterSupport{},
+	}
+	matrix.RancherVersion, _, _ = unstructured.NestedString(serverVersion.Object, "value")
+
+	apps, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: LocalCluster,
+		Kind:    "app",
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		log.Error("failed to list apps", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list the apps of the local cluster: %w", err)
+	}
+	for _, app := range apps {
+		chart, _, _ := unstructured.NestedString(app.Object, "spec", "chart", "metadata", "name")
+		if !slices.Contains(systemCharts, chart) {
+			continue
+		}
+		chartVersion, _, _ := unstructured.NestedString(app.Object, "spec", "chart", "metadata", "version")
+		appVersion, _, _ := unstructured.NestedString(app.Object, "spec", "chart", "metadata", "appVersion")
+		state, _, _ := unstructured.NestedString(app.Object, "status", "summary", "state")
+		matrix.SystemCharts = append(matrix.SystemCharts, systemChart{
+			Name:       chart,
+			Namespace:  app.GetNamespace(),
+			Version:    chartVersion,
+			AppVersion: appVersion,
+			State:      state,
+		})
+	}
+
+	releases := map[string][]*version.Version{}
+	for distribution, path := range kdmDistributions {
+		body, err := t.client.RancherGet(ctx, client.RancherParams{Path: path, URL: url, Token: token})
+		if err != nil {
+			log.Error("failed to get KDM releases", zap.String("distribution", distribution), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to get the %s releases: %w", distribution, err)
+		}
+		var kdm kdmReleases
+		if err := json.Unmarshal(body, &kdm); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the %s releases: %w", distribution, err)
+		}
+		for _, release := range kdm.Data {
+			if v, err := version.ParseSemantic(release.Version); err == nil {
+				releases[distribution] = append(releases[distribution], v)
+			}
+		}
+		slices.SortFunc(releases[distribution], compareVersions)
+		matrix.AvailableVersions[distribution] = latestPerMinor(releases[distribution])
+	}
+	minVersion, maxVersion := minorRange(releases)
+	if minVersion != nil {
+		matrix.MinKubernetesVersion = fmt.Sprintf("v%d.%d", minVersion.Major(), minVersion.Minor())
+		matrix.MaxKubernetesVersion = fmt.Sprintf("v%d.%d", maxVersion.Major(), maxVersion.Minor())
+	}
+
+	clusters, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: LocalCluster,
+		Kind:    converter.ManagementClusterResourceKind,
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		log.Error("failed to list clusters", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	for _, cluster := range clusters {
+		displayName, _, _ := unstructured.NestedString(cluster.Object, "spec", "displayName")
+		if len(params.Clusters) > 0 && !slices.Contains(params.Clusters, cluster.GetName()) && !slices.Contains(params.Clusters, displayName) {
+			continue
+		}
+		matrix.Clusters = append(matrix.Clusters, checkClusterSupport(cluster, displayName, releases, minVersion, maxVersion))
+	}
+
+	response, err := json.Marshal(matrix)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// checkClusterSupport checks the Kubernetes version of a management cluster. RKE2 and K3s clusters must run one of
+// the releases of KDM, the other clusters must run a minor version between the oldest and the newest ones of KDM.
+func checkClusterSupport(cluster *unstructured.Unstructured, displayName string, releases map[string][]*version.Version, minVersion *version.Version, maxVersion *version.Version) clusterSupport {
+	provider, _, _ := unstructured.NestedString(cluster.Object, "status", "provider")
+	if provider == "" {
+		provider, _, _ = unstructured.NestedString(cluster.Object, "status", "driver")
+	}
+	gitVersion, _, _ := unstructured.NestedString(cluster.Object, "status", "version", "gitVersion")
+	support := clusterSupport{
+		Cluster:           cluster.GetName(),
+		DisplayName:       displayName,
+		Provider:          provider,
+		KubernetesVersion: gitVersion,
+		Status:            supportedStatus,
+	}
+
+	clusterVersion, err := version.ParseGeneric(gitVersion)
+	if err != nil {
+		support.Status = unknownStatus
+		support.Reason = "the cluster doesn't report its Kubernetes version"
+		return support
+	}
+	if minVersion == nil {
+		support.Status = unknownStatus
+		support.Reason = "no Kubernetes version is available in KDM"
+		return support
+	}
+	minor := fmt.Sprintf("v%d.%d", clusterVersion.Major(), clusterVersion.Minor())
+	if clusterVersion.Major() < minVersion.Major() || (clusterVersion.Major() == minVersion.Major() && clusterVersion.Minor() < minVersion.Minor()) {
+		support.Status = unsupportedStatus
+		support.Reason = fmt.Sprintf("Kubernetes %s is older than the oldest version supported by Rancher, v%d.%d", minor, minVersion.Major(), minVersion.Minor())
+		return support
+	}
+	if clusterVersion.Major() > maxVersion.Major() || (clusterVersion.Major() == maxVersion.Major() && clusterVersion.Minor() > maxVersion.Minor()) {
+		support.Status = unsupportedStatus
+		support.Reason = fmt.Sprintf("Kubernetes %s is newer than the newest version supported by Rancher, v%d.%d", minor, maxVersion.Major(), maxVersion.Minor())
+		return support
+	}
+	if distributionReleases, ok := releases[strings.ToLower(provider)]; ok {
+		if !slices.ContainsFunc(distributionReleases, func(v *version.Version) bool { return v.String() == strings.TrimPrefix(gitVersion, "v") }) {
+			support.Status = unsupportedStatus
+			support.Reason = fmt.Sprintf("%s is not a %s release available for this Rancher version", gitVersion, provider)
+		}
+	}
+
+	return support
+}
+
+// compareVersions orders versions that are only different by their build metadata (e.g. +rke2r1 and +rke2r2) by the
+// metadata, which the semantic version ordering ignores.
+func compareVersions(a *version.Version, b *version.Version) int {
+	switch {
+	case a.LessThan(b):
+		return -1
+	case b.LessThan(a):
+		return 1
+	}
+
+	return strings.Compare(a.BuildMetadata(), b.BuildMetadata())
+}
+
+// latestPerMinor returns the latest version of each minor version of the sorted versions.
+func latestPerMinor(versions []*version.Version) []string {
+	latest := []string{}
+	for i, v := range versions {
+		if i+1 < len(versions) && versions[i+1].Major() == v.Major() && versions[i+1].Minor() == v.Minor() {
+			continue
+		}
+		latest = append(latest, "v"+v.String())
+	}
+
+	return latest
+}
+
+// minorRange returns the oldest and the newest versions of all the distributions, or nil if there is none.
+func minorRange(releases map[string][]*version.Version) (*version.Version, *version.Version) {
+	var minVersion, maxVersion *version.Version
+	for _, versions := range releases {
+		if len(versions) == 0 {
+			continue
+		}
+		if minVersion == nil || versions[0].LessThan(minVersion) {
+			minVersion = versions[0]
+		}
+		if maxVersion == nil || maxVersion.LessThan(versions[len(versions)-1]) {
+			maxVersion = versions[len(versions)-1]
+		}
+	}
+
+	return minVersion, maxVersion
+}
@@ -0,0 +1,133 @@
+package provisioning
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newVersionedCluster(name string, displayName string, provider string, gitVersion string) *unstructured.Unstructured {
+	cluster := newManagementCluster(name, true)
+	cluster.Object["spec"] = map[string]interface{}{"displayName": displayName}
+	cluster.Object["status"].(map[string]interface{})["provider"] = provider
+	if gitVersion != "" {
+		cluster.Object["status"].(map[string]interface{})["version"] = map[string]interface{}{"gitVersion": gitVersion}
+	}
+	return cluster
+}
+
+func newSystemApp(name string, namespace string, version string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "catalog.cattle.io/v1",
+		"kind":       "App",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"chart": map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name, "version": version, "appVersion": version},
+			},
+		},
+		"status": map[string]interface{}{"summary": map[string]interface{}{"state": "deployed"}},
+	}}
+}
+
+func TestCheckSupportMatrix(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1-rke2-release/releases":
+			w.Write([]byte(`{"type":"collection","data":[{"version":"v1.30.8+rke2r1"},{"version":"v1.31.4+rke2r2"},{"version":"v1.31.4+rke2r1"},{"version":"v1.31.2+rke2r1"}]}`))
+		case "/v1-k3s-release/releases":
+			w.Write([]byte(`{"type":"collection","data":[{"version":"v1.31.4+k3s1"},{"version":"v1.32.0+k3s1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	objects := []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "Setting",
+			"metadata":   map[string]interface{}{"name": "server-version"},
+			"value":      "v2.11.1",
+		}},
+		newSystemApp("fleet", "cattle-fleet-system", "106.1.0+up0.12.2"),
+		newSystemApp("rancher-webhook", "cattle-system", "106.0.1+up0.7.1"),
+		newSystemApp("nginx", "default", "1.0.0"),
+		newVersionedCluster("local", "local", "rke2", "v1.31.4+rke2r1"),
+		newVersionedCluster("c-m-abc12", "prod", "rke2", "v1.30.3+rke2r1"),
+		newVersionedCluster("c-m-def34", "legacy", "eks", "v1.28.9-eks-036c24b"),
+		newVersionedCluster("c-m-ghi56", "pending", "imported", ""),
+	}
+
+	tests := map[string]struct {
+		params           checkSupportMatrixParams
+		expectedClusters string
+	}{
+		"all clusters": {
+			expectedClusters: `[
+				{"cluster": "c-m-abc12", "displayName": "prod", "provider": "rke2", "kubernetesVersion": "v1.30.3+rke2r1", "status": "unsupported", "reason": "v1.30.3+rke2r1 is not a rke2 release available for this Rancher version"},
+				{"cluster": "c-m-def34", "displayName": "legacy", "provider": "eks", "kubernetesVersion": "v1.28.9-eks-036c24b", "status": "unsupported", "reason": "Kubernetes v1.28 is older than the oldest version supported by Rancher, v1.30"},
+				{"cluster": "c-m-ghi56", "displayName": "pending", "provider": "imported", "status": "unknown", "reason": "the cluster doesn't report its Kubernetes version"},
+				{"cluster": "local", "displayName": "local", "provider": "rke2", "kubernetesVersion": "v1.31.4+rke2r1", "status": "supported"}
+			]`,
+		},
+		"cluster by name": {
+			params: checkSupportMatrixParams{Clusters: []string{"prod"}},
+			expectedClusters: `[
+				{"cluster": "c-m-abc12", "displayName": "prod", "provider": "rke2", "kubernetesVersion": "v1.30.3+rke2r1", "status": "unsupported", "reason": "v1.30.3+rke2r1 is not a rke2 release available for this Rancher version"}
+			]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				{Group: "management.cattle.io", Version: "v3", Resource: "settings"}: "SettingList",
+				{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}: "ClusterList",
+				{Group: "catalog.cattle.io", Version: "v1", Resource: "apps"}:        "AppList",
+			}, objects...)
+			c := client.NewClient(true)
+			c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+				return fakeDynClient, nil
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.checkSupportMatrix(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {srv.URL}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, `{
+				"rancherVersion": "v2.11.1",
+				"systemCharts": [
+					{"name": "fleet", "namespace": "cattle-fleet-system", "version": "106.1.0+up0.12.2", "appVersion": "106.1.0+up0.12.2", "state": "deployed"},
+					{"name": "rancher-webhook", "namespace": "cattle-system", "version": "106.0.1+up0.7.1", "appVersion": "106.0.1+up0.7.1", "state": "deployed"}
+				],
+				"availableVersions": {
+					"rke2": ["v1.30.8+rke2r1", "v1.31.4+rke2r2"],
+					"k3s": ["v1.31.4+k3s1", "v1.32.0+k3s1"]
+				},
+				"minKubernetesVersion": "v1.30",
+				"maxKubernetesVersion": "v1.32",
+				"clusters": `+test.expectedClusters+`
+			}`, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
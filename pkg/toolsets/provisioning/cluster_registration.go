@@ -0,0 +1,342 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const registrationTokenKind = "clusterregistrationtoken"
+
+// registrationTokenPollInterval and registrationTokenTimeout bound the wait for Rancher to generate a new registration
+// token. They are variables to be shortened by the tests.
+var (
+	registrationTokenPollInterval = time.Second
+	registrationTokenTimeout      = 15 * time.Second
+)
+
+// registrationRoles are the roles of the nodes of a custom cluster, in the order of their flags in the command.
+var registrationRoles = []string{etcdRole, controlPlaneRole, workerRole}
+
+type clusterRegistrationParams struct {
+	Cluster  string   `json:"cluster" jsonschema:"the ID or the name of the custom cluster"`
+	Roles    []string `json:"roles" jsonschema:"the roles of the nodes to add: etcd, controlplane and/or worker" validate:"required,enum=etcd|controlplane|worker"`
+	Insecure bool     `json:"insecure,omitempty" jsonschema:"download the install script without verifying the certificate of Rancher, for a self-signed certificate not trusted by the nodes"`
+}
+
+type rotateClusterRegistrationTokenParams struct {
+	Cluster        string   `json:"cluster" jsonschema:"the ID or the name of the custom cluster"`
+	Roles          []string `json:"roles" jsonschema:"the roles of the nodes to add: etcd, controlplane and/or worker" validate:"required,enum=etcd|controlplane|worker"`
+	Insecure       bool     `json:"insecure,omitempty" jsonschema:"download the install script without verifying the certificate of Rancher, for a self-signed certificate not trusted by the nodes"`
+	DeletePrevious bool     `json:"deletePrevious,omitempty" jsonschema:"delete the other registration tokens of the cluster once the new one is generated, so their commands stop working"`
+}
+
+// clusterRegistration is the command registering nodes in a custom cluster, with the registration tokens of the
+// cluster. The values of the tokens are never returned outside of the command.
+type clusterRegistration struct {
+	Cluster   string   `json:"cluster"`
+	Namespace string   `json:"namespace"`
+	ClusterID string   `json:"clusterID"`
+	Roles     []string `json:"roles"`
+	// Command is the command to run as root on a node to register it in the cluster with the roles.
+	Command string `json:"command,omitempty"`
+	// Token is the name of the registration token used by the command.
+	Token   string              `json:"token,omitempty"`
+	Tokens  []registrationToken `json:"tokens"`
+	Message string              `json:"message,omitempty"`
+}
+
+type registrationToken struct {
+	Name      string `json:"name"`
+	Created   string `json:"created,omitempty"`
+	Generated bool   `json:"generated"`
+}
+
+// getClusterRegistrationCommand returns the command registering nodes with the given roles in a custom cluster, built
+// from the newest registration token of the cluster.
+func (t *Tools) getClusterRegistrationCommand(ctx context.Context, toolReq *mcp.CallToolRequest, params clusterRegistrationParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "getClusterRegistrationCommand"), zap.String("cluster", params.Cluster))
+	log.Debug("getClusterRegistrationCommand called")
+
+	if err := validateRegistrationRoles(params.Roles); err != nil {
+		return nil, nil, err
+	}
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	cluster, err := t.customCluster(ctx, url, token, params.Cluster)
+	if err != nil {
+		log.Error("failed to get custom cluster", zap.Error(err))
+		return nil, nil, err
+	}
+	registration, err := t.clusterRegistration(ctx, url, token, cluster, params.Roles, params.Insecure, "")
+	if err != nil {
+		log.Error("failed to get the registration command", zap.Error(err))
+		return nil, nil, err
+	}
+
+	return registrationResponse(log, registration)
+}
+
+// rotateClusterRegistrationToken creates a new registration token for a custom cluster and returns its command. The
+// other tokens stay valid unless deletePrevious is set. The nodes already registered don't use the registration
+// tokens, deleting them doesn't affect the nodes.
+func (t *Tools) rotateClusterRegistrationToken(ctx context.Context, toolReq *mcp.CallToolRequest, params rotateClusterRegistrationTokenParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "rotateClusterRegistrationToken"), zap.String("cluster", params.Cluster))
+	log.Debug("rotateClusterRegistrationToken called")
+
+	if err := validateRegistrationRoles(params.Roles); err != nil {
+		return nil, nil, err
+	}
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	cluster, err := t.customCluster(ctx, url, token, params.Cluster)
+	if err != nil {
+		log.Error("failed to get custom cluster", zap.Error(err))
+		return nil, nil, err
+	}
+	clusterID := cluster.Status.ClusterName
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, clusterID, LocalCluster, converter.K8sKindsToGVRs[registrationTokenKind])
+	if err != nil {
+		log.Error("failed to get resource interface", zap.Error(err))
+		return nil, nil, err
+	}
+	created, err := resourceInterface.Create(ctx, &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "ClusterRegistrationToken",
+		"metadata": map[string]any{
+			"name":      "crt-" + utilrand.String(5),
+			"namespace": clusterID,
+		},
+		"spec": map[string]any{"clusterName": clusterID},
+	}}, metav1.CreateOptions{})
+	if err != nil {
+		log.Error("failed to create registration token", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create a registration token for cluster %s: %w", params.Cluster, err)
+	}
+
+	// Rancher generates the value of the token asynchronously
+	err = wait.PollUntilContextTimeout(ctx, registrationTokenPollInterval, registrationTokenTimeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := resourceInterface.Get(ctx, created.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		value, _, _ := unstructured.NestedString(obj.Object, "status", "token")
+		return value != "", nil
+	})
+	generated := err == nil
+	if err != nil && !wait.Interrupted(err) {
+		log.Error("failed to get registration token", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get registration token %s: %w", created.GetName(), err)
+	}
+
+	if generated && params.DeletePrevious {
+		tokens, err := t.client.GetResources(ctx, client.ListParams{Cluster: LocalCluster, Kind: registrationTokenKind, Namespace: clusterID, URL: url, Token: token})
+		if err != nil {
+			log.Error("failed to list registration tokens", zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to list the registration tokens: %w", err)
+		}
+		for _, previous := range tokens {
+			if previous.GetName() == created.GetName() {
+				continue
+			}
+			if err := resourceInterface.Delete(ctx, previous.GetName(), metav1.DeleteOptions{}); err != nil {
+				log.Error("failed to delete registration token", zap.String("token", previous.GetName()), zap.Error(err))
+				return nil, nil, fmt.Errorf("failed to delete registration token %s: %w", previous.GetName(), err)
+			}
+		}
+	}
+
+	registration, err := t.clusterRegistration(ctx, url, token, cluster, params.Roles, params.Insecure, created.GetName())
+	if err != nil {
+		log.Error("failed to get the registration command", zap.Error(err))
+		return nil, nil, err
+	}
+	if !generated {
+		registration.Message = fmt.Sprintf("Rancher hasn't generated the registration token %s yet, get the registration command again in a few seconds", created.GetName())
+	}
+
+	return registrationResponse(log, registration)
+}
+
+// clusterRegistration returns the registration command of a custom cluster, built from the given token or from the
+// newest generated token of the cluster if tokenName is empty, with the registration tokens of the cluster.
+func (t *Tools) clusterRegistration(ctx context.Context, url string, token string, cluster provisioningV1.Cluster, roles []string, insecure bool, tokenName string) (clusterRegistration, error) {
+	registration := clusterRegistration{
+		Cluster:   cluster.Name,
+		Namespace: cluster.Namespace,
+		ClusterID: cluster.Status.ClusterName,
+		Roles:     sortedRoles(roles),
+		Tokens:    []registrationToken{},
+	}
+
+	tokens, err := t.client.GetResources(ctx, client.ListParams{Cluster: LocalCluster, Kind: registrationTokenKind, Namespace: registration.ClusterID, URL: url, Token: token})
+	if err != nil {
+		return clusterRegistration{}, fmt.Errorf("failed to list the registration tokens: %w", err)
+	}
+	// the newest token first
+	sort.Slice(tokens, func(i, j int) bool {
+		ti, tj := tokens[i].GetCreationTimestamp(), tokens[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return tokens[i].GetName() < tokens[j].GetName()
+	})
+	var selected *unstructured.Unstructured
+	for _, obj := range tokens {
+		value, _, _ := unstructured.NestedString(obj.Object, "status", "token")
+		item := registrationToken{Name: obj.GetName(), Generated: value != ""}
+		if created := obj.GetCreationTimestamp(); !created.IsZero() {
+			item.Created = created.UTC().Format(time.RFC3339)
+		}
+		registration.Tokens = append(registration.Tokens, item)
+		if selected == nil && item.Generated && (tokenName == "" || tokenName == obj.GetName()) {
+			selected = obj
+		}
+	}
+	if selected == nil {
+		if tokenName == "" {
+			registration.Message = fmt.Sprintf("cluster %s doesn't have a generated registration token, create one with rotateClusterRegistrationToken", cluster.Name)
+		}
+		return registration, nil
+	}
+
+	command, err := t.registrationCommand(ctx, url, token, selected, registration.Roles, insecure)
+	if err != nil {
+		return clusterRegistration{}, err
+	}
+	registration.Command = command
+	registration.Token = selected.GetName()
+
+	return registration, nil
+}
+
+// registrationCommand returns the command installing the rancher-system-agent on a node with the roles. It's the node
+// command computed by Rancher in the status of the token, with the flags of the roles. The command is built from the
+// server-url and cacerts settings if Rancher didn't compute it yet.
+func (t *Tools) registrationCommand(ctx context.Context, url string, token string, registrationToken *unstructured.Unstructured, roles []string, insecure bool) (string, error) {
+	field := "nodeCommand"
+	if insecure {
+		field = "insecureNodeCommand"
+	}
+	command, _, _ := unstructured.NestedString(registrationToken.Object, "status", field)
+	if command == "" {
+		values := map[string]string{}
+		for _, setting := range []string{"server-url", "cacerts"} {
+			obj, err := t.client.GetResource(ctx, client.GetParams{Cluster: LocalCluster, Kind: "setting", Name: setting, URL: url, Token: token})
+			if err != nil {
+				return "", fmt.Errorf("failed to get setting %s: %w", setting, err)
+			}
+			values[setting], _, _ = unstructured.NestedString(obj.Object, "value")
+		}
+		if values["server-url"] == "" {
+			return "", fmt.Errorf("the server-url setting of Rancher is empty, it must be set before registering nodes")
+		}
+		value, _, _ := unstructured.NestedString(registrationToken.Object, "status", "token")
+		command = nodeCommand(values["server-url"], value, caChecksum(values["cacerts"]), insecure)
+	}
+	for _, role := range roles {
+		command += " --" + role
+	}
+
+	return command, nil
+}
+
+// nodeCommand returns the command installing the rancher-system-agent on a Linux node, like the node command of the
+// registration tokens computed by Rancher.
+func nodeCommand(serverURL string, token string, checksum string, insecure bool) string {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	curl := "curl -fL"
+	if insecure {
+		curl = "curl --insecure -fL"
+	}
+	command := fmt.Sprintf("%s %s/system-agent-install.sh | sudo sh -s - --server %s --label 'cattle.io/os=linux' --token %s", curl, serverURL, serverURL, token)
+	if checksum != "" {
+		command += " --ca-checksum " + checksum
+	}
+
+	return command
+}
+
+// customCluster returns the provisioning cluster of a custom cluster, whose nodes are registered by running the
+// registration command on them. It fails for the clusters whose machines are provisioned by Rancher, and for the
+// imported and hosted clusters.
+func (t *Tools) customCluster(ctx context.Context, url string, token string, clusterName string) (provisioningV1.Cluster, error) {
+	clusterID, err := t.client.GetClusterID(ctx, token, url, clusterName)
+	if err != nil {
+		return provisioningV1.Cluster{}, err
+	}
+	if clusterID == LocalCluster {
+		return provisioningV1.Cluster{}, fmt.Errorf("nodes can't be added to the local cluster through Rancher")
+	}
+	var clusters []provisioningV1.Cluster
+	if err := listTyped(ctx, t.client, client.ListParams{Cluster: LocalCluster, URL: url, Token: token}, converter.ProvisioningClusterResourceKind, &clusters); err != nil {
+		return provisioningV1.Cluster{}, err
+	}
+	for _, cluster := range clusters {
+		if cluster.Status.ClusterName != clusterID {
+			continue
+		}
+		if cluster.Spec.RKEConfig == nil {
+			return provisioningV1.Cluster{}, fmt.Errorf("cluster %s is not an RKE2/K3s cluster provisioned by Rancher, imported clusters are registered with the command of the cattle-cluster-agent", clusterName)
+		}
+		if len(cluster.Spec.RKEConfig.MachinePools) > 0 {
+			return provisioningV1.Cluster{}, fmt.Errorf("cluster %s is not a custom cluster, its machines are provisioned by Rancher in its machine pools, scale a machine pool to add nodes", clusterName)
+		}
+		return cluster, nil
+	}
+
+	return provisioningV1.Cluster{}, fmt.Errorf("cluster %s is not an RKE2/K3s cluster provisioned by Rancher", clusterName)
+}
+
+// validateRegistrationRoles checks the roles of the nodes registered in a custom cluster.
+func validateRegistrationRoles(roles []string) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("at least one role must be provided: %s", strings.Join(registrationRoles, ", "))
+	}
+	for _, role := range roles {
+		if !slices.Contains(registrationRoles, role) {
+			return fmt.Errorf("invalid role %q, must be one of %s", role, strings.Join(registrationRoles, ", "))
+		}
+	}
+
+	return nil
+}
+
+// sortedRoles returns the roles without duplicates, in the order of registrationRoles.
+func sortedRoles(roles []string) []string {
+	var sorted []string
+	for _, role := range registrationRoles {
+		if slices.Contains(roles, role) {
+			sorted = append(sorted, role)
+		}
+	}
+
+	return sorted
+}
+
+func registrationResponse(log *zap.Logger, registration any) (*mcp.CallToolResult, any, error) {
+	response, err := json.Marshal(registration)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
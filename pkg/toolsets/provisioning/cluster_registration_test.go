@@ -0,0 +1,215 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testNodeCommand = "curl -fL https://rancher.example.com/system-agent-install.sh | sudo sh -s - --server https://rancher.example.com --label 'cattle.io/os=linux' --token abcdef --ca-checksum 0123"
+
+func newRegistrationToken(namespace string, name string, created time.Time, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "ClusterRegistrationToken",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       map[string]interface{}{"clusterName": namespace},
+	}}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	obj.SetCreationTimestamp(metav1.NewTime(created))
+
+	return obj
+}
+
+func newRegistrationClient(objects ...runtime.Object) (*client.Client, *dynamicfake.FakeDynamicClient) {
+	listKinds := capiCustomListKinds()
+	listKinds[schema.GroupVersionResource{Group: "management.cattle.io", Version: "v3", Resource: "clusterregistrationtokens"}] = "ClusterRegistrationTokenList"
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	c := client.NewClient(true)
+	c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+	c.ClientSetCreator = func(*rest.Config) (kubernetes.Interface, error) {
+		return newFakeClientsetWithCAPIDiscovery(), nil
+	}
+
+	return c, fakeDynClient
+}
+
+func registrationObjects() []runtime.Object {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []runtime.Object{
+		newAgentCluster("c-m-abc12", "edge", "True", "True", ""),
+		newAgentCluster("c-m-def34", "bare-metal", "True", "True", ""),
+		newAgentCluster("c-m-ghi56", "aws", "True", "True", ""),
+		newAgentCluster("c-m-jkl78", "imported", "True", "True", ""),
+		newProvisioningClusterWithRKEConfig("edge", "fleet-default", "c-m-abc12", nil),
+		newProvisioningClusterWithRKEConfig("bare-metal", "fleet-default", "c-m-def34", nil),
+		newProvisioningClusterWithRKEConfig("aws", "fleet-default", "c-m-ghi56", []provisioningV1.RKEMachinePool{{Name: "pool1"}}),
+		newProvisioningCluster("imported", "fleet-default", "c-m-jkl78"),
+		newSetting("server-url", "https://rancher.example.com/"),
+		newSetting("cacerts", testCACerts),
+		newRegistrationToken("c-m-abc12", "default-token", created, map[string]interface{}{
+			"token":               "abcdef",
+			"nodeCommand":         testNodeCommand,
+			"insecureNodeCommand": "curl --insecure -fL https://rancher.example.com/system-agent-install.sh | sudo sh -s - --server https://rancher.example.com --label 'cattle.io/os=linux' --token abcdef --ca-checksum 0123",
+		}),
+		newRegistrationToken("c-m-abc12", "pending-token", created.Add(time.Hour), nil),
+		newRegistrationToken("c-m-def34", "default-token", created, map[string]interface{}{"token": "ghijkl"}),
+	}
+}
+
+func TestGetClusterRegistrationCommand(t *testing.T) {
+	tests := map[string]struct {
+		params         clusterRegistrationParams
+		expectedResult string
+		expectedError  string
+	}{
+		"node command of the newest generated token": {
+			params: clusterRegistrationParams{Cluster: "edge", Roles: []string{workerRole, etcdRole, controlPlaneRole}},
+			expectedResult: `{"cluster":"edge","namespace":"fleet-default","clusterID":"c-m-abc12","roles":["etcd","controlplane","worker"],
+				"command":"` + testNodeCommand + ` --etcd --controlplane --worker","token":"default-token",
+				"tokens":[
+					{"name":"pending-token","created":"2025-01-01T01:00:00Z","generated":false},
+					{"name":"default-token","created":"2025-01-01T00:00:00Z","generated":true}
+				]}`,
+		},
+		"insecure": {
+			params: clusterRegistrationParams{Cluster: "c-m-abc12", Roles: []string{workerRole}, Insecure: true},
+			expectedResult: `{"cluster":"edge","namespace":"fleet-default","clusterID":"c-m-abc12","roles":["worker"],
+				"command":"curl --insecure -fL https://rancher.example.com/system-agent-install.sh | sudo sh -s - --server https://rancher.example.com --label 'cattle.io/os=linux' --token abcdef --ca-checksum 0123 --worker","token":"default-token",
+				"tokens":[
+					{"name":"pending-token","created":"2025-01-01T01:00:00Z","generated":false},
+					{"name":"default-token","created":"2025-01-01T00:00:00Z","generated":true}
+				]}`,
+		},
+		"command built from the settings": {
+			params: clusterRegistrationParams{Cluster: "bare-metal", Roles: []string{workerRole}},
+			expectedResult: `{"cluster":"bare-metal","namespace":"fleet-default","clusterID":"c-m-def34","roles":["worker"],
+				"command":"curl -fL https://rancher.example.com/system-agent-install.sh | sudo sh -s - --server https://rancher.example.com --label 'cattle.io/os=linux' --token ghijkl --ca-checksum ` + caChecksum(testCACerts) + ` --worker","token":"default-token",
+				"tokens":[{"name":"default-token","created":"2025-01-01T00:00:00Z","generated":true}]}`,
+		},
+		"cluster with machine pools": {
+			params:        clusterRegistrationParams{Cluster: "aws", Roles: []string{workerRole}},
+			expectedError: "cluster aws is not a custom cluster, its machines are provisioned by Rancher in its machine pools",
+		},
+		"imported cluster": {
+			params:        clusterRegistrationParams{Cluster: "imported", Roles: []string{workerRole}},
+			expectedError: "cluster imported is not an RKE2/K3s cluster provisioned by Rancher",
+		},
+		"invalid role": {
+			params:        clusterRegistrationParams{Cluster: "edge", Roles: []string{"master"}},
+			expectedError: `invalid role "master", must be one of etcd, controlplane, worker`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newRegistrationClient(registrationObjects()...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.getClusterRegistrationCommand(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestRotateClusterRegistrationToken(t *testing.T) {
+	pollInterval, timeout := registrationTokenPollInterval, registrationTokenTimeout
+	registrationTokenPollInterval, registrationTokenTimeout = time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() {
+		registrationTokenPollInterval, registrationTokenTimeout = pollInterval, timeout
+	})
+
+	tests := map[string]struct {
+		params          rotateClusterRegistrationTokenParams
+		generate        bool
+		expectedTokens  []string
+		expectedMessage string
+	}{
+		"keep the previous tokens": {
+			params:         rotateClusterRegistrationTokenParams{Cluster: "edge", Roles: []string{workerRole}},
+			generate:       true,
+			expectedTokens: []string{"pending-token", "default-token"},
+		},
+		"delete the previous tokens": {
+			params:         rotateClusterRegistrationTokenParams{Cluster: "edge", Roles: []string{workerRole}, DeletePrevious: true},
+			generate:       true,
+			expectedTokens: []string{},
+		},
+		"token not generated": {
+			params:          rotateClusterRegistrationTokenParams{Cluster: "edge", Roles: []string{workerRole}, DeletePrevious: true},
+			expectedTokens:  []string{"pending-token", "default-token"},
+			expectedMessage: "Rancher hasn't generated the registration token",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newRegistrationClient(registrationObjects()...)
+			if test.generate {
+				// Rancher generates the token and the commands of the new registration tokens
+				fakeDynClient.PrependReactor("create", "clusterregistrationtokens", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+					obj.Object["status"] = map[string]interface{}{"token": "mnopqr", "nodeCommand": "curl -fL https://rancher.example.com/system-agent-install.sh | sudo sh -s - --token mnopqr"}
+					return false, nil, nil
+				})
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.rotateClusterRegistrationToken(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			var registration clusterRegistration
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &registration))
+			var created string
+			previous := []string{}
+			for _, token := range registration.Tokens {
+				if strings.HasPrefix(token.Name, "crt-") {
+					created = token.Name
+				} else {
+					previous = append(previous, token.Name)
+				}
+			}
+			require.NotEmpty(t, created)
+			assert.ElementsMatch(t, test.expectedTokens, previous)
+			if test.expectedMessage != "" {
+				assert.Contains(t, registration.Message, test.expectedMessage)
+				assert.Empty(t, registration.Command)
+				return
+			}
+			assert.Empty(t, registration.Message)
+			assert.Equal(t, created, registration.Token)
+			assert.Equal(t, "curl -fL https://rancher.example.com/system-agent-install.sh | sudo sh -s - --token mnopqr --worker", registration.Command)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type compareClustersParams struct {
+	Cluster        string `json:"cluster" jsonschema:"the name of the first provisioning cluster"`
+	Namespace      string `json:"namespace,omitempty" jsonschema:"the namespace of the first cluster"`
+	OtherCluster   string `json:"otherCluster" jsonschema:"the name of the provisioning cluster compared to the first one"`
+	OtherNamespace string `json:"otherNamespace,omitempty" jsonschema:"the namespace of the other cluster"`
+}
+
+// clusterDifference is a field whose value differs between the compared clusters. A nil value means the field
+// isn't set in the cluster.
+type clusterDifference struct {
+	Field        string `json:"field"`
+	Cluster      any    `json:"cluster"`
+	OtherCluster any    `json:"otherCluster"`
+}
+
+// CompareClusters compares the configuration of two provisioning clusters: their Kubernetes version, CNI, machine pools
+// and machine configs, upgrade strategy and addons.
+func (t *Tools) CompareClusters(ctx context.Context, toolReq *mcp.CallToolRequest, params compareClustersParams) (*mcp.CallToolResult, any, error) {
+	log := utils.NewChildLogger(ctx, toolReq, map[string]string{
+		"cluster":      params.Cluster,
+		"otherCluster": params.OtherCluster,
+	})
+
+	log.Debug("Comparing clusters")
+
+	cluster, err := t.getClusterConfig(ctx, toolReq, log, clusterNamespace(params.Namespace, params.Cluster), params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	otherCluster, err := t.getClusterConfig(ctx, toolReq, log, clusterNamespace(params.OtherNamespace, params.OtherCluster), params.OtherCluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	differences := []clusterDifference{}
+	diffValues("", cluster.config, otherCluster.config, &differences)
+	slices.SortFunc(differences, func(a, b clusterDifference) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	log.Info("cluster comparison complete", zap.Int("differences", len(differences)))
+
+	summary := &unstructured.Unstructured{Object: map[string]any{
+		"cluster":      params.Cluster,
+		"otherCluster": params.OtherCluster,
+		"identical":    len(differences) == 0,
+		"differences":  differences,
+	}}
+	// only the reference of the clusters is sent, the UI uses them to link to both clusters
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{cluster.reference, otherCluster.reference, summary}, LocalCluster)
+	if err != nil {
+		log.Error("failed to create MCP response", zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// clusterConfig holds the compared configuration of a provisioning cluster.
+type clusterConfig struct {
+	reference *unstructured.Unstructured
+	config    map[string]any
+}
+
+// getClusterConfig returns the configuration of a provisioning cluster that is compared by CompareClusters. Machine
+// pools are indexed by name and include the fields of their machine config.
+func (t *Tools) getClusterConfig(ctx context.Context, toolReq *mcp.CallToolRequest, log *zap.Logger, ns, clusterName string) (*clusterConfig, error) {
+	provClusterResource, provCluster, err := t.getProvisioningCluster(ctx, toolReq, log, ns, clusterName)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("provisioning cluster %s not found in namespace %s", clusterName, ns)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	machineConfigs, err := t.getMachinePoolConfigs(ctx, toolReq, log, provCluster)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Error("failed to get machine pool configs", zap.Error(err))
+		return nil, err
+	}
+	machineConfigsByName := map[string]*unstructured.Unstructured{}
+	for _, machineConfig := range machineConfigs {
+		machineConfigsByName[machineConfig.GetName()] = machineConfig
+	}
+
+	rkeConfig, _, _ := unstructured.NestedMap(provClusterResource.Object, "spec", "rkeConfig")
+	machineGlobalConfig, _, _ := unstructured.NestedMap(rkeConfig, "machineGlobalConfig")
+	cni := machineGlobalConfig["cni"]
+	delete(machineGlobalConfig, "cni")
+
+	machinePools := map[string]any{}
+	pools, _, _ := unstructured.NestedSlice(rkeConfig, "machinePools")
+	for _, p := range pools {
+		pool, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := pool["name"].(string)
+		delete(pool, "name")
+		configName, _, _ := unstructured.NestedString(pool, "machineConfigRef", "name")
+		// machine config names are generated, only their content is compared
+		unstructured.RemoveNestedField(pool, "machineConfigRef", "name")
+		if machineConfig, ok := machineConfigsByName[configName]; ok {
+			pool["machineConfig"] = machineConfigFields(machineConfig)
+		}
+		machinePools[name] = pool
+	}
+
+	return &clusterConfig{
+		reference: &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": provClusterResource.GetAPIVersion(),
+			"kind":       provClusterResource.GetKind(),
+			"metadata": map[string]any{
+				"name":      provClusterResource.GetName(),
+				"namespace": provClusterResource.GetNamespace(),
+			},
+		}},
+		config: map[string]any{
+			"kubernetesVersion":   provCluster.Spec.KubernetesVersion,
+			"cni":                 cni,
+			"machineGlobalConfig": machineGlobalConfig,
+			"machinePools":        machinePools,
+			"upgradeStrategy":     rkeConfig["upgradeStrategy"],
+			"chartValues":         rkeConfig["chartValues"],
+			"additionalManifest":  rkeConfig["additionalManifest"],
+			"registries":          rkeConfig["registries"],
+		},
+	}, nil
+}
+
+// machineConfigFields returns the provider specific fields of a machine config (e.g. the region or the instance type).
+func machineConfigFields(machineConfig *unstructured.Unstructured) map[string]any {
+	fields := maps.Clone(machineConfig.Object)
+	for _, field := range []string{"apiVersion", "kind", "metadata", "status"} {
+		delete(fields, field)
+	}
+
+	return fields
+}
+
+// diffValues appends the differences between two values to differences. Maps are compared field by field, any
+// other values (including lists) are compared as a whole. Empty values are considered unset.
+func diffValues(field string, value any, otherValue any, differences *[]clusterDifference) {
+	value, otherValue = emptyToNil(value), emptyToNil(otherValue)
+	valueMap, valueIsMap := value.(map[string]any)
+	otherValueMap, otherValueIsMap := otherValue.(map[string]any)
+	if (valueIsMap || value == nil) && (otherValueIsMap || otherValue == nil) && (valueIsMap || otherValueIsMap) {
+		keys := slices.Collect(maps.Keys(valueMap))
+		for key := range otherValueMap {
+			if _, ok := valueMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		for _, key := range keys {
+			diffValues(joinField(field, key), valueMap[key], otherValueMap[key], differences)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(value, otherValue) {
+		*differences = append(*differences, clusterDifference{Field: field, Cluster: value, OtherCluster: otherValue})
+	}
+}
+
+// emptyToNil returns nil for the zero values of the fields of a cluster, so that unset and empty fields are equal.
+func emptyToNil(value any) any {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case bool:
+		if !v {
+			return nil
+		}
+	case map[string]any:
+		if len(v) == 0 {
+			return nil
+		}
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+
+	return value
+}
+
+func joinField(field string, key string) string {
+	if field == "" {
+		return key
+	}
+
+	return field + "." + key
+}
+
+// clusterNamespace returns the namespace of a provisioning cluster, defaulting to the namespace used by Rancher for
+// the local or the downstream clusters.
+func clusterNamespace(namespace string, cluster string) string {
+	if namespace != "" {
+		return namespace
+	}
+	if cluster == LocalCluster {
+		return "fleet-local"
+	}
+
+	return DefaultClusterResourcesNamespace
+}
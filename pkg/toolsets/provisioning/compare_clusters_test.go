@@ -0,0 +1,154 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+// newComparedCluster creates a provisioning cluster with a worker pool using the given machine config
+func newComparedCluster(name string, version string, cni string, quantity int64, machineConfig string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "provisioning.cattle.io/v1",
+		"kind":       "Cluster",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "fleet-default",
+		},
+		"spec": map[string]any{
+			"kubernetesVersion": version,
+			"rkeConfig": map[string]any{
+				"machineGlobalConfig": map[string]any{
+					"cni":                cni,
+					"disable-kube-proxy": false,
+				},
+				"upgradeStrategy": map[string]any{
+					"controlPlaneConcurrency": "1",
+					"workerConcurrency":       "1",
+				},
+				"machinePools": []any{
+					map[string]any{
+						"name":       "workers",
+						"quantity":   quantity,
+						"workerRole": true,
+						"machineConfigRef": map[string]any{
+							"kind": "Amazonec2Config",
+							"name": machineConfig,
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func newComparedMachineConfig(name string, instanceType string) *unstructured.Unstructured {
+	machineConfig := newMachineConfig(name, "fleet-default", "Amazonec2Config")
+	machineConfig.Object["region"] = "us-east-1"
+	machineConfig.Object["instanceType"] = instanceType
+	return machineConfig
+}
+
+func TestCompareClusters(t *testing.T) {
+	staging := newComparedCluster("staging", "v1.31.4+rke2r1", "calico", 2, "nc-staging-workers")
+	unstructured.SetNestedField(staging.Object, map[string]any{"rke2-ingress-nginx": map[string]any{"controller": map[string]any{"replicaCount": int64(1)}}}, "spec", "rkeConfig", "chartValues")
+
+	tests := map[string]struct {
+		objects        []runtime.Object
+		params         compareClustersParams
+		expectedError  string
+		expectedResult string
+	}{
+		"different clusters": {
+			objects: []runtime.Object{
+				staging,
+				newComparedCluster("prod", "v1.30.8+rke2r1", "cilium", 5, "nc-prod-workers"),
+				newComparedMachineConfig("nc-staging-workers", "t3.large"),
+				newComparedMachineConfig("nc-prod-workers", "m5.xlarge"),
+			},
+			params: compareClustersParams{Cluster: "staging", OtherCluster: "prod"},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "provisioning.cattle.io/v1", "kind": "Cluster", "metadata": {"name": "staging", "namespace": "fleet-default"}},
+					{"apiVersion": "provisioning.cattle.io/v1", "kind": "Cluster", "metadata": {"name": "prod", "namespace": "fleet-default"}},
+					{
+						"cluster": "staging",
+						"otherCluster": "prod",
+						"identical": false,
+						"differences": [
+							{"field": "chartValues.rke2-ingress-nginx.controller.replicaCount", "cluster": 1, "otherCluster": null},
+							{"field": "cni", "cluster": "calico", "otherCluster": "cilium"},
+							{"field": "kubernetesVersion", "cluster": "v1.31.4+rke2r1", "otherCluster": "v1.30.8+rke2r1"},
+							{"field": "machinePools.workers.machineConfig.instanceType", "cluster": "t3.large", "otherCluster": "m5.xlarge"},
+							{"field": "machinePools.workers.quantity", "cluster": 2, "otherCluster": 5}
+						]
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Cluster", "name": "staging", "namespace": "fleet-default", "type": "provisioning.cattle.io.cluster"},
+					{"cluster": "local", "kind": "Cluster", "name": "prod", "namespace": "fleet-default", "type": "provisioning.cattle.io.cluster"}
+				]
+			}`,
+		},
+		"identical clusters": {
+			objects: []runtime.Object{
+				newComparedCluster("prod-eu", "v1.31.4+rke2r1", "calico", 3, "nc-prod-eu-workers"),
+				newComparedCluster("prod-us", "v1.31.4+rke2r1", "calico", 3, "nc-prod-us-workers"),
+				newComparedMachineConfig("nc-prod-eu-workers", "t3.large"),
+				newComparedMachineConfig("nc-prod-us-workers", "t3.large"),
+			},
+			params: compareClustersParams{Cluster: "prod-eu", OtherCluster: "prod-us"},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "provisioning.cattle.io/v1", "kind": "Cluster", "metadata": {"name": "prod-eu", "namespace": "fleet-default"}},
+					{"apiVersion": "provisioning.cattle.io/v1", "kind": "Cluster", "metadata": {"name": "prod-us", "namespace": "fleet-default"}},
+					{"cluster": "prod-eu", "otherCluster": "prod-us", "identical": true, "differences": []}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Cluster", "name": "prod-eu", "namespace": "fleet-default", "type": "provisioning.cattle.io.cluster"},
+					{"cluster": "local", "kind": "Cluster", "name": "prod-us", "namespace": "fleet-default", "type": "provisioning.cattle.io.cluster"}
+				]
+			}`,
+		},
+		"cluster not found": {
+			objects:       []runtime.Object{staging},
+			params:        compareClustersParams{Cluster: "staging", OtherCluster: "prod"},
+			expectedError: "provisioning cluster prod not found in namespace fleet-default",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), test.objects...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.CompareClusters(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Name: "compare-clusters",
+				},
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// autoImportLabel makes Rancher Turtles import the Cluster API cluster in Rancher once it's provisioned.
+const autoImportLabel = "cluster-api.cattle.io/rancher-auto-import"
+
+type TemplateWorkerParams struct {
+	Class    string `json:"class" jsonschema:"the worker class of the ClusterClass" validate:"required"`
+	Name     string `json:"name" jsonschema:"the name of the machine deployment" validate:"required,format=dns1123-label"`
+	Replicas int64  `json:"replicas" jsonschema:"the number of machines of the machine deployment"`
+}
+
+type createClusterFromTemplateParams struct {
+	Name                 string                 `json:"name" jsonschema:"the name of the cluster" validate:"required,format=dns1123-label"`
+	Namespace            string                 `json:"namespace,omitempty" jsonschema:"the namespace of the cluster and of the ClusterClass, defaults to fleet-default"`
+	Template             string                 `json:"template" jsonschema:"the name of the ClusterClass" validate:"required"`
+	KubernetesVersion    string                 `json:"kubernetesVersion" jsonschema:"the Kubernetes version of the cluster, e.g. v1.31.4" validate:"required,format=kubernetes-version"`
+	ControlPlaneReplicas int64                  `json:"controlPlaneReplicas,omitempty" jsonschema:"the number of control plane machines, defaults to 1"`
+	Workers              []TemplateWorkerParams `json:"workers,omitempty" jsonschema:"the machine deployments of the workers"`
+	Variables            map[string]any         `json:"variables,omitempty" jsonschema:"the values of the variables of the ClusterClass"`
+}
+
+// createClusterFromTemplate creates a Cluster API cluster with a managed topology from a ClusterClass. The variables
+// and the worker classes are validated against the ClusterClass before creating the cluster, so mistakes are
+// reported at once instead of by the Cluster API webhooks one by one.
+func (t *Tools) createClusterFromTemplate(ctx context.Context, toolReq *mcp.CallToolRequest, params createClusterFromTemplateParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "createClusterFromTemplate"))
+	log.Debug("createClusterFromTemplate called")
+
+	if params.Name == "" || params.Template == "" || params.KubernetesVersion == "" {
+		return nil, nil, errors.New("name, template and kubernetesVersion are required")
+	}
+	if params.Namespace == "" {
+		params.Namespace = DefaultClusterResourcesNamespace
+	}
+	if params.ControlPlaneReplicas == 0 {
+		params.ControlPlaneReplicas = 1
+	}
+
+	clusterClass, err := t.getClusterClass(ctx, toolReq, params.Template, params.Namespace)
+	if err != nil {
+		log.Error("failed to get ClusterClass", zap.Error(err))
+		return nil, nil, err
+	}
+	template := newClusterTemplate(clusterClass)
+	if err := validateTemplateParams(template, params); err != nil {
+		return nil, nil, err
+	}
+
+	gv, err := schema.ParseGroupVersion(template.APIVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid apiVersion of ClusterClass %s: %w", template.Name, err)
+	}
+	cluster := newTopologyCluster(gv, params)
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, LocalCluster, gv.WithResource("clusters"))
+	if err != nil {
+		log.Error("failed to get resource interface", zap.Error(err))
+		return nil, nil, err
+	}
+	created, err := resourceInterface.Create(ctx, cluster, metav1.CreateOptions{})
+	if err != nil {
+		log.Error("failed to create cluster", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create cluster %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{created}, LocalCluster)
+	if err != nil {
+		log.Error("failed to create mcp response", zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// validateTemplateParams checks that the required variables are set, that all the variables are defined by the
+// ClusterClass and that the workers use its worker classes.
+func validateTemplateParams(template clusterTemplate, params createClusterFromTemplateParams) error {
+	var problems []string
+	for _, variable := range template.Variables {
+		if _, ok := params.Variables[variable.Name]; variable.Required && !ok && variable.Default == nil {
+			problems = append(problems, fmt.Sprintf("the required variable %s is not set", variable.Name))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(params.Variables)) {
+		if !slices.ContainsFunc(template.Variables, func(v clusterTemplateVariable) bool { return v.Name == name }) {
+			problems = append(problems, fmt.Sprintf("the variable %s is not defined by the template", name))
+		}
+	}
+	for _, worker := range params.Workers {
+		if !slices.Contains(template.WorkerClasses, worker.Class) {
+			problems = append(problems, fmt.Sprintf("the worker class %s of %s is not defined by the template, must be one of %s", worker.Class, worker.Name, strings.Join(template.WorkerClasses, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid parameters for template %s: %s", template.Name, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// newTopologyCluster returns the Cluster API cluster of the topology. The ClusterClass is referenced with the class
+// field in v1beta1 and with the classRef field in v1beta2.
+func newTopologyCluster(gv schema.GroupVersion, params createClusterFromTemplateParams) *unstructured.Unstructured {
+	machineDeployments := make([]any, 0, len(params.Workers))
+	for _, worker := range params.Workers {
+		machineDeployments = append(machineDeployments, map[string]any{
+			"class":    worker.Class,
+			"name":     worker.Name,
+			"replicas": worker.Replicas,
+		})
+	}
+	variables := make([]any, 0, len(params.Variables))
+	for _, name := range slices.Sorted(maps.Keys(params.Variables)) {
+		variables = append(variables, map[string]any{"name": name, "value": params.Variables[name]})
+	}
+
+	topology := map[string]any{
+		"version":      params.KubernetesVersion,
+		"controlPlane": map[string]any{"replicas": params.ControlPlaneReplicas},
+		"workers":      map[string]any{"machineDeployments": machineDeployments},
+		"variables":    variables,
+	}
+	if gv.Version == "v1beta1" {
+		topology["class"] = params.Template
+	} else {
+		topology["classRef"] = map[string]any{"name": params.Template}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": gv.String(),
+		"kind":       "Cluster",
+		"metadata": map[string]any{
+			"name":      params.Name,
+			"namespace": params.Namespace,
+			"labels":    map[string]any{autoImportLabel: "true"},
+		},
+		"spec": map[string]any{"topology": topology},
+	}}
+}
@@ -0,0 +1,152 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestCreateClusterFromTemplate(t *testing.T) {
+	tests := map[string]struct {
+		objects        []runtime.Object
+		params         createClusterFromTemplateParams
+		expectedError  string
+		expectedResult string
+	}{
+		"cluster with workers": {
+			objects: []runtime.Object{newClusterClass("aws-rke2", DefaultClusterResourcesNamespace)},
+			params: createClusterFromTemplateParams{
+				Name:                 "prod",
+				Template:             "aws-rke2",
+				KubernetesVersion:    "v1.31.4",
+				ControlPlaneReplicas: 3,
+				Workers:              []TemplateWorkerParams{{Class: "default-worker", Name: "md-0", Replicas: 2}},
+				Variables:            map[string]any{"region": "eu-west-1", "instanceType": "t3.xlarge"},
+			},
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "cluster.x-k8s.io/v1beta1",
+						"kind": "Cluster",
+						"metadata": {"name": "prod", "namespace": "fleet-default", "labels": {"cluster-api.cattle.io/rancher-auto-import": "true"}},
+						"spec": {
+							"topology": {
+								"class": "aws-rke2",
+								"version": "v1.31.4",
+								"controlPlane": {"replicas": 3},
+								"workers": {"machineDeployments": [{"class": "default-worker", "name": "md-0", "replicas": 2}]},
+								"variables": [{"name": "instanceType", "value": "t3.xlarge"}, {"name": "region", "value": "eu-west-1"}]
+							}
+						}
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Cluster", "name": "prod", "namespace": "fleet-default", "type": "cluster.x-k8s.io.cluster"}
+				]
+			}`,
+		},
+		"required variable with a default": {
+			objects: []runtime.Object{newClusterClass("aws-rke2", DefaultClusterResourcesNamespace)},
+			params: createClusterFromTemplateParams{
+				Name:              "prod",
+				Template:          "aws-rke2",
+				KubernetesVersion: "v1.31.4",
+				Variables:         map[string]any{"region": "eu-west-1"},
+			},
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "cluster.x-k8s.io/v1beta1",
+						"kind": "Cluster",
+						"metadata": {"name": "prod", "namespace": "fleet-default", "labels": {"cluster-api.cattle.io/rancher-auto-import": "true"}},
+						"spec": {
+							"topology": {
+								"class": "aws-rke2",
+								"version": "v1.31.4",
+								"controlPlane": {"replicas": 1},
+								"workers": {"machineDeployments": []},
+								"variables": [{"name": "region", "value": "eu-west-1"}]
+							}
+						}
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Cluster", "name": "prod", "namespace": "fleet-default", "type": "cluster.x-k8s.io.cluster"}
+				]
+			}`,
+		},
+		"invalid variables and worker class": {
+			objects: []runtime.Object{newClusterClass("aws-rke2", DefaultClusterResourcesNamespace)},
+			params: createClusterFromTemplateParams{
+				Name:              "prod",
+				Template:          "aws-rke2",
+				KubernetesVersion: "v1.31.4",
+				Workers:           []TemplateWorkerParams{{Class: "gpu-worker", Name: "md-0", Replicas: 1}},
+				Variables:         map[string]any{"zone": "a"},
+			},
+			expectedError: "invalid parameters for template aws-rke2: the required variable region is not set; the variable zone is not defined by the template; the worker class gpu-worker of md-0 is not defined by the template, must be one of default-worker",
+		},
+		"unknown template": {
+			params: createClusterFromTemplateParams{
+				Name:              "prod",
+				Template:          "aws-rke2",
+				KubernetesVersion: "v1.31.4",
+			},
+			expectedError: "ClusterClass fleet-default/aws-rke2 not found",
+		},
+		"missing kubernetes version": {
+			params:        createClusterFromTemplateParams{Name: "prod", Template: "aws-rke2"},
+			expectedError: "name, template and kubernetesVersion are required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), test.objects...)
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return newFakeClientsetWithCAPIDiscovery(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.createClusterFromTemplate(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestNewTopologyClusterV1Beta2(t *testing.T) {
+	cluster := newTopologyCluster(schema.GroupVersion{Group: "cluster.x-k8s.io", Version: "v1beta2"}, createClusterFromTemplateParams{
+		Name:              "prod",
+		Namespace:         DefaultClusterResourcesNamespace,
+		Template:          "aws-rke2",
+		KubernetesVersion: "v1.31.4",
+	})
+
+	assert.Equal(t, "cluster.x-k8s.io/v1beta2", cluster.GetAPIVersion())
+	topology := cluster.Object["spec"].(map[string]any)["topology"].(map[string]any)
+	assert.Equal(t, map[string]any{"name": "aws-rke2"}, topology["classRef"])
+	assert.NotContains(t, topology, "class")
+}
@@ -45,7 +45,7 @@ type createK3kClusterParams struct {
 
 // createK3kCluster creates a new K3k cluster using structured input parameters.
 func (t *Tools) createK3kCluster(ctx context.Context, toolReq *mcp.CallToolRequest, params createK3kClusterParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("createK3kCluster called")
+	middleware.Logger(ctx).Debug("createK3kCluster called")
 	spec := map[string]interface{}{}
 
 	if params.Version != "" {
@@ -134,19 +134,19 @@ func (t *Tools) createK3kCluster(ctx context.Context, toolReq *mcp.CallToolReque
 
 	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, params.TargetCluster, converter.K8sKindsToGVRs["k3kcluster"])
 	if err != nil {
-		zap.L().Error("failed to get resource interface", zap.String("tool", "createK3kCluster"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createK3kCluster"), zap.Error(err))
 		return nil, nil, err
 	}
 
 	obj, err := resourceInterface.Create(ctx, unstructuredObj, metav1.CreateOptions{})
 	if err != nil {
-		zap.L().Error("failed to create K3k cluster", zap.String("tool", "createK3kCluster"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create K3k cluster", zap.String("tool", "createK3kCluster"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to create K3k cluster %s: %w", params.Name, err)
 	}
 
-	mcpResponse, err := response.CreateMcpResponse([]*unstructured.Unstructured{obj}, params.TargetCluster)
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.TargetCluster)
 	if err != nil {
-		zap.L().Error("failed to create mcp response", zap.String("tool", "createK3kCluster"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "createK3kCluster"), zap.Error(err))
 		return nil, nil, err
 	}
 
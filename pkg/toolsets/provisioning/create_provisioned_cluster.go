@@ -0,0 +1,286 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// CloudCredentialsNamespace is the namespace of the Secrets holding the Rancher cloud credentials.
+	CloudCredentialsNamespace = "cattle-global-data"
+
+	cloudCredentialNameAnn = "field.cattle.io/name"
+
+	etcdRole         = "etcd"
+	controlPlaneRole = "controlplane"
+	workerRole       = "worker"
+)
+
+// machineProvider describes how the machines of a cloud provider are configured.
+type machineProvider struct {
+	// kind is the kind of the rke-machine-config.cattle.io object configuring the machines.
+	kind string
+	// regionField and instanceTypeField are the fields of the machine config holding the region and the instance type.
+	regionField       string
+	instanceTypeField string
+	// diskSizeField and imageField are the fields holding the size of the root disk in GB and the image (or AMI) of
+	// the machines. The disk size of DigitalOcean droplets is set by their size, so diskSizeField is empty.
+	diskSizeField string
+	imageField    string
+}
+
+// machineProviders contains the supported cloud providers, indexed by the name of their node driver.
+var machineProviders = map[string]machineProvider{
+	"amazonec2":    {kind: "Amazonec2Config", regionField: "region", instanceTypeField: "instanceType", diskSizeField: "rootSize", imageField: "ami"},
+	"azure":        {kind: "AzureConfig", regionField: "location", instanceTypeField: "size", diskSizeField: "diskSize", imageField: "image"},
+	"digitalocean": {kind: "DigitaloceanConfig", regionField: "region", instanceTypeField: "size", imageField: "image"},
+}
+
+type MachinePoolParams struct {
+	Name         string   `json:"name" jsonschema:"the name of the machine pool" validate:"required,format=dns1123-label"`
+	Quantity     int32    `json:"quantity" jsonschema:"the number of machines of the pool"`
+	Roles        []string `json:"roles" jsonschema:"the roles of the machines: etcd, controlplane and/or worker" validate:"required,enum=etcd|controlplane|worker"`
+	InstanceType string   `json:"instanceType,omitempty" jsonschema:"the instance type of the machines of the pool, overriding the instance type of the cluster"`
+}
+
+type createProvisionedClusterParams struct {
+	Name              string              `json:"name" jsonschema:"the name of the cluster" validate:"required,format=dns1123-label"`
+	Namespace         string              `json:"namespace,omitempty" jsonschema:"the namespace of the cluster, defaults to fleet-default"`
+	KubernetesVersion string              `json:"kubernetesVersion" jsonschema:"the RKE2 or K3s version of the cluster, e.g. v1.31.4+rke2r1" validate:"required,format=kubernetes-version"`
+	Provider          string              `json:"provider" jsonschema:"the cloud provider: amazonec2, azure or digitalocean" validate:"required,enum=amazonec2|azure|digitalocean"`
+	CloudCredential   string              `json:"cloudCredential" jsonschema:"the ID or the name of the cloud credential used to create the machines" validate:"required"`
+	Region            string              `json:"region" jsonschema:"the region (or Azure location) of the machines" validate:"required"`
+	InstanceType      string              `json:"instanceType" jsonschema:"the instance type (or Azure/DigitalOcean size) of the machines" validate:"required"`
+	MachinePools      []MachinePoolParams `json:"machinePools" jsonschema:"the machine pools of the cluster" validate:"required"`
+	MachineConfig     map[string]any      `json:"machineConfig,omitempty" jsonschema:"additional provider specific fields of the machine configs, e.g. vpcId or zone"`
+}
+
+// createProvisionedCluster creates an RKE2/K3s cluster whose machines are provisioned by Rancher in a cloud provider.
+// It creates a machine config for each machine pool and the provisioning cluster referencing them.
+func (t *Tools) createProvisionedCluster(ctx context.Context, toolReq *mcp.CallToolRequest, params createProvisionedClusterParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("createProvisionedCluster called")
+
+	provider, ok := machineProviders[params.Provider]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported provider %q, must be one of %s", params.Provider, strings.Join(slices.Sorted(maps.Keys(machineProviders)), ", "))
+	}
+	if err := validateMachinePools(params.MachinePools); err != nil {
+		return nil, nil, err
+	}
+	if params.Namespace == "" {
+		params.Namespace = DefaultClusterResourcesNamespace
+	}
+
+	credential, err := t.getCloudCredential(ctx, toolReq, params.CloudCredential)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cloud credential", zap.String("tool", "createProvisionedCluster"), zap.Error(err))
+		return nil, nil, err
+	}
+	if !isCloudCredentialOf(credential, params.Provider) {
+		return nil, nil, fmt.Errorf("cloud credential %s is not a %s credential", params.CloudCredential, params.Provider)
+	}
+	credentialID := credential.GetNamespace() + ":" + credential.GetName()
+
+	configGVR := converter.K8sKindsToGVRs[strings.ToLower(provider.kind)]
+	configInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, LocalCluster, configGVR)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createProvisionedCluster"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	var configs []*unstructured.Unstructured
+	// the machine configs are only used by this cluster, remove them if the cluster can't be created
+	cleanup := func() {
+		for _, config := range configs {
+			if err := configInterface.Delete(ctx, config.GetName(), metav1.DeleteOptions{}); err != nil {
+				middleware.Logger(ctx).Error("failed to delete machine config", zap.String("tool", "createProvisionedCluster"), zap.String("name", config.GetName()), zap.Error(err))
+			}
+		}
+	}
+
+	var machinePools []any
+	for _, pool := range params.MachinePools {
+		config := newPoolMachineConfig(provider, params, pool)
+		created, err := configInterface.Create(ctx, config, metav1.CreateOptions{})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to create machine config", zap.String("tool", "createProvisionedCluster"), zap.Error(err))
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create the machine config of pool %s: %w", pool.Name, err)
+		}
+		configs = append(configs, created)
+
+		machinePools = append(machinePools, map[string]any{
+			"name":             pool.Name,
+			"quantity":         int64(pool.Quantity),
+			"etcdRole":         slices.Contains(pool.Roles, etcdRole),
+			"controlPlaneRole": slices.Contains(pool.Roles, controlPlaneRole),
+			"workerRole":       slices.Contains(pool.Roles, workerRole),
+			"machineConfigRef": map[string]any{
+				"kind": provider.kind,
+				"name": created.GetName(),
+			},
+		})
+	}
+
+	cluster := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": converter.ProvisioningGroup + "/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      params.Name,
+				"namespace": params.Namespace,
+			},
+			"spec": map[string]any{
+				"kubernetesVersion":         params.KubernetesVersion,
+				"cloudCredentialSecretName": credentialID,
+				"rkeConfig": map[string]any{
+					"machinePools": machinePools,
+				},
+			},
+		},
+	}
+	clusterInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), params.Namespace, LocalCluster, converter.K8sKindsToGVRs[converter.ProvisioningClusterResourceKind])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "createProvisionedCluster"), zap.Error(err))
+		cleanup()
+		return nil, nil, err
+	}
+	created, err := clusterInterface.Create(ctx, cluster, metav1.CreateOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create provisioning cluster", zap.String("tool", "createProvisionedCluster"), zap.Error(err))
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to create cluster %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, append([]*unstructured.Unstructured{created}, configs...), LocalCluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "createProvisionedCluster"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// validateMachinePools checks that every pool has a name, machines and valid roles, and that the cluster has
+// at least one machine for each role.
+func validateMachinePools(pools []MachinePoolParams) error {
+	if len(pools) == 0 {
+		return fmt.Errorf("at least one machine pool is required")
+	}
+
+	names := map[string]bool{}
+	roles := map[string]bool{}
+	for _, pool := range pools {
+		if pool.Name == "" {
+			return fmt.Errorf("the name of the machine pools is required")
+		}
+		if names[pool.Name] {
+			return fmt.Errorf("duplicate machine pool %s", pool.Name)
+		}
+		names[pool.Name] = true
+		if pool.Quantity < 1 {
+			return fmt.Errorf("machine pool %s must have at least one machine", pool.Name)
+		}
+		if len(pool.Roles) == 0 {
+			return fmt.Errorf("machine pool %s must have at least one role", pool.Name)
+		}
+		for _, role := range pool.Roles {
+			if role != etcdRole && role != controlPlaneRole && role != workerRole {
+				return fmt.Errorf("invalid role %q of machine pool %s, must be one of etcd, controlplane or worker", role, pool.Name)
+			}
+			roles[role] = true
+		}
+	}
+	for _, role := range []string{etcdRole, controlPlaneRole, workerRole} {
+		if !roles[role] {
+			return fmt.Errorf("no machine pool has the %s role", role)
+		}
+	}
+
+	return nil
+}
+
+// newPoolMachineConfig returns the machine config of a pool. Its name follows the pattern used by the Rancher UI.
+func newPoolMachineConfig(provider machineProvider, params createProvisionedClusterParams, pool MachinePoolParams) *unstructured.Unstructured {
+	config := map[string]any{}
+	maps.Copy(config, params.MachineConfig)
+	config["apiVersion"] = converter.MachineConfigGroup + "/v1"
+	config["kind"] = provider.kind
+	config["metadata"] = map[string]any{
+		"name":      fmt.Sprintf("nc-%s-%s", params.Name, pool.Name),
+		"namespace": params.Namespace,
+	}
+	config[provider.regionField] = params.Region
+	config[provider.instanceTypeField] = params.InstanceType
+	if pool.InstanceType != "" {
+		config[provider.instanceTypeField] = pool.InstanceType
+	}
+
+	return &unstructured.Unstructured{Object: config}
+}
+
+// getCloudCredential returns the Secret of a cloud credential given its ID (e.g. 'cattle-global-data:cc-abc12' or
+// 'cc-abc12') or its name.
+func (t *Tools) getCloudCredential(ctx context.Context, toolReq *mcp.CallToolRequest, cloudCredential string) (*unstructured.Unstructured, error) {
+	name := strings.TrimPrefix(cloudCredential, CloudCredentialsNamespace+":")
+	secret, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   LocalCluster,
+		Kind:      "secret",
+		Namespace: CloudCredentialsNamespace,
+		Name:      name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err == nil {
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	secrets, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   LocalCluster,
+		Kind:      "secret",
+		Namespace: CloudCredentialsNamespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if secret.GetAnnotations()[cloudCredentialNameAnn] == cloudCredential {
+			return secret, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cloud credential %s not found", cloudCredential)
+}
+
+// isCloudCredentialOf returns whether the Secret of a cloud credential holds the credential of a provider, whose
+// fields are stored as '<provider>credentialConfig-<field>' keys.
+func isCloudCredentialOf(secret *unstructured.Unstructured, provider string) bool {
+	data, _, _ := unstructured.NestedMap(secret.Object, "data")
+	for key := range data {
+		if strings.HasPrefix(key, provider+"credentialConfig-") {
+			return true
+		}
+	}
+
+	return false
+}
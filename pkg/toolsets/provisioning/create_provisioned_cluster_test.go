@@ -0,0 +1,212 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newCloudCredential(name string, displayName string, provider string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   CloudCredentialsNamespace,
+			Annotations: map[string]string{cloudCredentialNameAnn: displayName},
+		},
+		Data: map[string][]byte{
+			provider + "credentialConfig-accessKey": []byte("key"),
+		},
+	}
+}
+
+func TestCreateProvisionedCluster(t *testing.T) {
+	pools := []MachinePoolParams{
+		{Name: "cp", Quantity: 3, Roles: []string{"etcd", "controlplane"}},
+		{Name: "workers", Quantity: 2, Roles: []string{"worker"}, InstanceType: "t3.xlarge"},
+	}
+
+	tests := map[string]struct {
+		objects        []runtime.Object
+		params         createProvisionedClusterParams
+		expectedError  string
+		expectedResult string
+	}{
+		"amazonec2 cluster": {
+			objects: []runtime.Object{newCloudCredential("cc-abc12", "aws", "amazonec2")},
+			params: createProvisionedClusterParams{
+				Name:              "prod",
+				KubernetesVersion: "v1.31.4+rke2r1",
+				Provider:          "amazonec2",
+				CloudCredential:   "cattle-global-data:cc-abc12",
+				Region:            "us-east-1",
+				InstanceType:      "t3.large",
+				MachinePools:      pools,
+				MachineConfig:     map[string]any{"zone": "a", "vpcId": "vpc-123"},
+			},
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "provisioning.cattle.io/v1",
+						"kind": "Cluster",
+						"metadata": {"name": "prod", "namespace": "fleet-default"},
+						"spec": {
+							"cloudCredentialSecretName": "cattle-global-data:cc-abc12",
+							"kubernetesVersion": "v1.31.4+rke2r1",
+							"rkeConfig": {
+								"machinePools": [
+									{"name": "cp", "quantity": 3, "etcdRole": true, "controlPlaneRole": true, "workerRole": false, "machineConfigRef": {"kind": "Amazonec2Config", "name": "nc-prod-cp"}},
+									{"name": "workers", "quantity": 2, "etcdRole": false, "controlPlaneRole": false, "workerRole": true, "machineConfigRef": {"kind": "Amazonec2Config", "name": "nc-prod-workers"}}
+								]
+							}
+						}
+					},
+					{"apiVersion": "rke-machine-config.cattle.io/v1", "kind": "Amazonec2Config", "metadata": {"name": "nc-prod-cp", "namespace": "fleet-default"}, "region": "us-east-1", "instanceType": "t3.large", "zone": "a", "vpcId": "vpc-123"},
+					{"apiVersion": "rke-machine-config.cattle.io/v1", "kind": "Amazonec2Config", "metadata": {"name": "nc-prod-workers", "namespace": "fleet-default"}, "region": "us-east-1", "instanceType": "t3.xlarge", "zone": "a", "vpcId": "vpc-123"}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Cluster", "name": "prod", "namespace": "fleet-default", "type": "provisioning.cattle.io.cluster"},
+					{"cluster": "local", "kind": "Amazonec2Config", "name": "nc-prod-cp", "namespace": "fleet-default", "type": "rke-machine-config.cattle.io.amazonec2config"},
+					{"cluster": "local", "kind": "Amazonec2Config", "name": "nc-prod-workers", "namespace": "fleet-default", "type": "rke-machine-config.cattle.io.amazonec2config"}
+				]
+			}`,
+		},
+		"digitalocean cluster with the credential name": {
+			objects: []runtime.Object{newCloudCredential("cc-def34", "my-do", "digitalocean")},
+			params: createProvisionedClusterParams{
+				Name:              "dev",
+				Namespace:         "clusters",
+				KubernetesVersion: "v1.31.4+k3s1",
+				Provider:          "digitalocean",
+				CloudCredential:   "my-do",
+				Region:            "fra1",
+				InstanceType:      "s-2vcpu-4gb",
+				MachinePools:      []MachinePoolParams{{Name: "all", Quantity: 1, Roles: []string{"etcd", "controlplane", "worker"}}},
+			},
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "provisioning.cattle.io/v1",
+						"kind": "Cluster",
+						"metadata": {"name": "dev", "namespace": "clusters"},
+						"spec": {
+							"cloudCredentialSecretName": "cattle-global-data:cc-def34",
+							"kubernetesVersion": "v1.31.4+k3s1",
+							"rkeConfig": {
+								"machinePools": [
+									{"name": "all", "quantity": 1, "etcdRole": true, "controlPlaneRole": true, "workerRole": true, "machineConfigRef": {"kind": "DigitaloceanConfig", "name": "nc-dev-all"}}
+								]
+							}
+						}
+					},
+					{"apiVersion": "rke-machine-config.cattle.io/v1", "kind": "DigitaloceanConfig", "metadata": {"name": "nc-dev-all", "namespace": "clusters"}, "region": "fra1", "size": "s-2vcpu-4gb"}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Cluster", "name": "dev", "namespace": "clusters", "type": "provisioning.cattle.io.cluster"},
+					{"cluster": "local", "kind": "DigitaloceanConfig", "name": "nc-dev-all", "namespace": "clusters", "type": "rke-machine-config.cattle.io.digitaloceanconfig"}
+				]
+			}`,
+		},
+		"credential of another provider": {
+			objects: []runtime.Object{newCloudCredential("cc-abc12", "aws", "amazonec2")},
+			params: createProvisionedClusterParams{
+				Name:              "prod",
+				KubernetesVersion: "v1.31.4+rke2r1",
+				Provider:          "azure",
+				CloudCredential:   "cc-abc12",
+				Region:            "westeurope",
+				InstanceType:      "Standard_D2s_v3",
+				MachinePools:      pools,
+			},
+			expectedError: "cloud credential cc-abc12 is not a azure credential",
+		},
+		"unknown credential": {
+			params: createProvisionedClusterParams{
+				Name:              "prod",
+				KubernetesVersion: "v1.31.4+rke2r1",
+				Provider:          "amazonec2",
+				CloudCredential:   "unknown",
+				MachinePools:      pools,
+			},
+			expectedError: "cloud credential unknown not found",
+		},
+		"unsupported provider": {
+			params: createProvisionedClusterParams{
+				Name:         "prod",
+				Provider:     "vsphere",
+				MachinePools: pools,
+			},
+			expectedError: `unsupported provider "vsphere", must be one of amazonec2, azure, digitalocean`,
+		},
+		"missing role": {
+			params: createProvisionedClusterParams{
+				Name:         "prod",
+				Provider:     "amazonec2",
+				MachinePools: []MachinePoolParams{{Name: "cp", Quantity: 1, Roles: []string{"etcd", "controlplane"}}},
+			},
+			expectedError: "no machine pool has the worker role",
+		},
+		"invalid role": {
+			params: createProvisionedClusterParams{
+				Name:         "prod",
+				Provider:     "amazonec2",
+				MachinePools: []MachinePoolParams{{Name: "cp", Quantity: 1, Roles: []string{"master"}}},
+			},
+			expectedError: `invalid role "master" of machine pool cp, must be one of etcd, controlplane or worker`,
+		},
+		"existing cluster removes the machine configs": {
+			objects: []runtime.Object{
+				newCloudCredential("cc-abc12", "aws", "amazonec2"),
+				newProvisioningCluster("prod", DefaultClusterResourcesNamespace, "c-abc12"),
+			},
+			params: createProvisionedClusterParams{
+				Name:              "prod",
+				KubernetesVersion: "v1.31.4+rke2r1",
+				Provider:          "amazonec2",
+				CloudCredential:   "cc-abc12",
+				Region:            "us-east-1",
+				InstanceType:      "t3.large",
+				MachinePools:      pools,
+			},
+			expectedError: "failed to create cluster prod",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), test.objects...)
+			c := &client.Client{
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.createProvisionedCluster(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				configs, err := fakeDynClient.Resource(schema.GroupVersionResource{Group: "rke-machine-config.cattle.io", Version: "v1", Resource: "amazonec2configs"}).
+					Namespace(DefaultClusterResourcesNamespace).List(t.Context(), metav1.ListOptions{})
+				require.NoError(t, err)
+				assert.Empty(t, configs.Items, "no machine config should be left")
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, result.Content)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
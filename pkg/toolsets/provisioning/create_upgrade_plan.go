@@ -0,0 +1,120 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// systemUpgradeServiceAccount is the service account of the System Upgrade Controller installed by Rancher, allowed
+// to cordon and drain the nodes.
+const systemUpgradeServiceAccount = "system-upgrade-controller"
+
+type createUpgradePlanParams struct {
+	Name               string            `json:"name" jsonschema:"the name of the plan" validate:"required,format=dns1123-subdomain"`
+	Namespace          string            `json:"namespace,omitempty" jsonschema:"the namespace of the plan, cattle-system if not provided"`
+	Cluster            string            `json:"cluster" jsonschema:"the ID or the name of the cluster whose nodes are upgraded"`
+	Image              string            `json:"image" jsonschema:"the image of the upgrade, e.g. rancher/k3s-upgrade"`
+	Version            string            `json:"version,omitempty" jsonschema:"the version of the upgrade, used as tag of the image"`
+	Channel            string            `json:"channel,omitempty" jsonschema:"the URL of a channel resolving the latest version of the upgrade"`
+	Concurrency        int64             `json:"concurrency,omitempty" jsonschema:"the number of nodes upgraded at the same time, 1 if not provided"`
+	NodeSelector       map[string]string `json:"nodeSelector,omitempty" jsonschema:"the labels of the nodes to upgrade, all the nodes if not provided"`
+	Cordon             bool              `json:"cordon,omitempty" jsonschema:"cordon the nodes before upgrading them"`
+	Drain              bool              `json:"drain,omitempty" jsonschema:"drain the nodes before upgrading them"`
+	ServiceAccountName string            `json:"serviceAccountName,omitempty" jsonschema:"the service account of the upgrade jobs, the one of the System Upgrade Controller if not provided"`
+}
+
+// createUpgradePlan creates a System Upgrade Controller plan upgrading the nodes of a cluster.
+func (t *Tools) createUpgradePlan(ctx context.Context, toolReq *mcp.CallToolRequest, params createUpgradePlanParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "createUpgradePlan"), zap.String("cluster", params.Cluster), zap.String("plan", params.Name))
+	log.Debug("createUpgradePlan called")
+
+	if (params.Version == "") == (params.Channel == "") {
+		return nil, nil, fmt.Errorf("exactly one of version and channel must be provided")
+	}
+	if params.Image == "" {
+		return nil, nil, fmt.Errorf("the image of the upgrade must be provided")
+	}
+	if params.Concurrency < 0 {
+		return nil, nil, fmt.Errorf("invalid concurrency %d, must be at least 1", params.Concurrency)
+	}
+	namespace := planNamespace(params.Namespace)
+	concurrency := params.Concurrency
+	if concurrency == 0 {
+		concurrency = 1
+	}
+	serviceAccountName := params.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = systemUpgradeServiceAccount
+	}
+
+	spec := map[string]any{
+		"concurrency":        concurrency,
+		"serviceAccountName": serviceAccountName,
+		"cordon":             params.Cordon,
+		"upgrade": map[string]any{
+			"image": params.Image,
+		},
+	}
+	if params.Version != "" {
+		spec["version"] = params.Version
+	}
+	if params.Channel != "" {
+		spec["channel"] = params.Channel
+	}
+	if len(params.NodeSelector) > 0 {
+		matchLabels := map[string]any{}
+		for key, value := range params.NodeSelector {
+			matchLabels[key] = value
+		}
+		spec["nodeSelector"] = map[string]any{"matchLabels": matchLabels}
+	}
+	if params.Drain {
+		spec["drain"] = map[string]any{
+			"force":              true,
+			"ignoreDaemonSets":   true,
+			"deleteEmptydirData": true,
+		}
+	}
+
+	plan := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "upgrade.cattle.io/v1",
+			"kind":       "Plan",
+			"metadata": map[string]any{
+				"name":      params.Name,
+				"namespace": namespace,
+			},
+			"spec": spec,
+		},
+	}
+
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), namespace, params.Cluster, converter.K8sKindsToGVRs["plan"])
+	if err != nil {
+		log.Error("failed to get resource interface", zap.Error(err))
+		return nil, nil, err
+	}
+
+	obj, err := resourceInterface.Create(ctx, plan, metav1.CreateOptions{})
+	if err != nil {
+		log.Error("failed to create upgrade plan", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to create plan %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		log.Error("failed to create mcp response", zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,115 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateUpgradePlan(t *testing.T) {
+	tests := map[string]struct {
+		params         createUpgradePlanParams
+		expectedError  string
+		expectedResult string
+	}{
+		"create plan with defaults": {
+			params: createUpgradePlanParams{
+				Name:    "server-plan",
+				Cluster: "local",
+				Image:   "rancher/k3s-upgrade",
+				Version: "v1.31.4+k3s1",
+			},
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "upgrade.cattle.io/v1",
+						"kind": "Plan",
+						"metadata": {"name": "server-plan", "namespace": "cattle-system"},
+						"spec": {
+							"concurrency": 1,
+							"serviceAccountName": "system-upgrade-controller",
+							"cordon": false,
+							"version": "v1.31.4+k3s1",
+							"upgrade": {"image": "rancher/k3s-upgrade"}
+						}
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Plan", "name": "server-plan", "namespace": "cattle-system", "type": "upgrade.cattle.io.plan"}
+				]
+			}`,
+		},
+		"create plan with channel, node selector and drain": {
+			params: createUpgradePlanParams{
+				Name:         "agent-plan",
+				Namespace:    "system-upgrade",
+				Cluster:      "local",
+				Image:        "rancher/k3s-upgrade",
+				Channel:      "https://update.k3s.io/v1-release/channels/stable",
+				Concurrency:  2,
+				NodeSelector: map[string]string{"node-role.kubernetes.io/worker": "true"},
+				Cordon:       true,
+				Drain:        true,
+			},
+			expectedResult: `{
+				"llm": [
+					{
+						"apiVersion": "upgrade.cattle.io/v1",
+						"kind": "Plan",
+						"metadata": {"name": "agent-plan", "namespace": "system-upgrade"},
+						"spec": {
+							"concurrency": 2,
+							"serviceAccountName": "system-upgrade-controller",
+							"cordon": true,
+							"channel": "https://update.k3s.io/v1-release/channels/stable",
+							"nodeSelector": {"matchLabels": {"node-role.kubernetes.io/worker": "true"}},
+							"drain": {"force": true, "ignoreDaemonSets": true, "deleteEmptydirData": true},
+							"upgrade": {"image": "rancher/k3s-upgrade"}
+						}
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Plan", "name": "agent-plan", "namespace": "system-upgrade", "type": "upgrade.cattle.io.plan"}
+				]
+			}`,
+		},
+		"version and channel": {
+			params: createUpgradePlanParams{
+				Name:    "server-plan",
+				Cluster: "local",
+				Image:   "rancher/k3s-upgrade",
+				Version: "v1.31.4+k3s1",
+				Channel: "https://update.k3s.io/v1-release/channels/stable",
+			},
+			expectedError: "exactly one of version and channel must be provided",
+		},
+		"missing image": {
+			params: createUpgradePlanParams{
+				Name:    "server-plan",
+				Cluster: "local",
+				Version: "v1.31.4+k3s1",
+			},
+			expectedError: "the image of the upgrade must be provided",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newUpgradePlanTools()
+
+			result, _, err := tools.createUpgradePlan(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package provisioning
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	clusterAgentNamespace = "cattle-system"
+	// caChecksumEnv and serverEnv are the environment variables of the cattle-cluster-agent with the checksum of the
+	// CA certificates of Rancher and its URL.
+	caChecksumEnv = "CATTLE_CA_CHECKSUM"
+	serverEnv     = "CATTLE_SERVER"
+)
+
+// agentLogHints are the causes of the known errors of the cattle-cluster-agent logs.
+var agentLogHints = []struct {
+	re   *regexp.Regexp
+	hint string
+}{
+	{regexp.MustCompile(`(?i)ca checksum|certificate chain|x509:`), "the agent doesn't trust the certificate of Rancher, its CA checksum or the cacerts setting of Rancher may be wrong"},
+	{regexp.MustCompile(`(?i)no such host|i/o timeout|connection refused|no route to host`), "the agent can't reach the Rancher server URL, check the DNS, the proxy and the firewall of the downstream cluster"},
+	{regexp.MustCompile(`(?i)401|unauthorized|forbidden`), "Rancher rejects the token of the agent, redeploy the agent with a new registration command"},
+}
+
+type diagnoseClusterAgentParams struct {
+	Cluster      string `json:"cluster" jsonschema:"the ID or the name of the cluster"`
+	SinceMinutes int64  `json:"sinceMinutes,omitempty" jsonschema:"the age in minutes of the logs returned, 60 if not provided"`
+}
+
+// clusterAgentDiagnosis explains why the cattle-cluster-agent of a downstream cluster isn't connected to Rancher.
+type clusterAgentDiagnosis struct {
+	Cluster     string             `json:"cluster"`
+	DisplayName string             `json:"displayName,omitempty"`
+	Conditions  []clusterCondition `json:"conditions"`
+	Tunnel      tunnelStatus       `json:"tunnel"`
+	Agent       agentDeployment    `json:"agent"`
+	// ServerURL and CAChecksum are the URL of Rancher and the checksum of its CA certificates expected by the agent.
+	ServerURL    string          `json:"serverURL"`
+	CAChecksum   string          `json:"caChecksum,omitempty"`
+	Causes       []string        `json:"causes"`
+	Remediations []string        `json:"remediations"`
+	Failures     []fetch.Failure `json:"failures,omitempty"`
+}
+
+type clusterCondition struct {
+	Type           string `json:"type"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason,omitempty"`
+	Message        string `json:"message,omitempty"`
+	LastUpdateTime string `json:"lastUpdateTime,omitempty"`
+}
+
+// tunnelStatus is the status of the tunnel session of the agent, with the lines of the Rancher logs about the cluster.
+type tunnelStatus struct {
+	Connected bool     `json:"connected"`
+	Since     string   `json:"since,omitempty"`
+	Logs      []string `json:"logs"`
+}
+
+// agentDeployment is the cattle-cluster-agent deployment of the downstream cluster, read through Rancher.
+type agentDeployment struct {
+	Reachable     bool           `json:"reachable"`
+	Error         string         `json:"error,omitempty"`
+	Image         string         `json:"image,omitempty"`
+	ServerURL     string         `json:"serverURL,omitempty"`
+	CAChecksum    string         `json:"caChecksum,omitempty"`
+	Replicas      int32          `json:"replicas"`
+	ReadyReplicas int32          `json:"readyReplicas"`
+	Pods          []componentPod `json:"pods"`
+	ErrorLogs     []string       `json:"errorLogs"`
+}
+
+// diagnoseClusterAgent checks the conditions of a management cluster, the tunnel session of its agent and the agent
+// deployment of the downstream cluster, and returns the causes of the disconnection with their remediation.
+func (t *Tools) diagnoseClusterAgent(ctx context.Context, toolReq *mcp.CallToolRequest, params diagnoseClusterAgentParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "diagnoseClusterAgent"), zap.String("cluster", params.Cluster))
+	log.Debug("diagnoseClusterAgent called")
+
+	sinceMinutes := params.SinceMinutes
+	if sinceMinutes <= 0 {
+		sinceMinutes = defaultErrorLogsSinceMinutes
+	}
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	if clusterID == LocalCluster {
+		return nil, nil, fmt.Errorf("the local cluster doesn't have a cattle-cluster-agent connected to Rancher, use diagnoseRancherHealth instead")
+	}
+	managementCluster, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: LocalCluster,
+		Kind:    converter.ManagementClusterResourceKind,
+		Name:    clusterID,
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		log.Error("failed to get management cluster", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get cluster %s: %w", params.Cluster, err)
+	}
+
+	diagnosis := clusterAgentDiagnosis{
+		Cluster:      clusterID,
+		Conditions:   clusterConditions(managementCluster),
+		Tunnel:       tunnelStatus{Logs: []string{}},
+		Agent:        agentDeployment{Pods: []componentPod{}, ErrorLogs: []string{}},
+		Causes:       []string{},
+		Remediations: []string{},
+	}
+	diagnosis.DisplayName, _, _ = unstructured.NestedString(managementCluster.Object, "spec", "displayName")
+	for _, condition := range diagnosis.Conditions {
+		if condition.Type == "Connected" {
+			diagnosis.Tunnel.Connected = condition.Status == "True"
+			diagnosis.Tunnel.Since = condition.LastUpdateTime
+		}
+	}
+
+	var registrationCommand string
+	g := fetch.New(ctx, 0)
+	g.GoOptional("settings", func(ctx context.Context) error {
+		values := map[string]string{}
+		for _, setting := range []string{"server-url", "cacerts"} {
+			obj, err := t.client.GetResource(ctx, client.GetParams{Cluster: LocalCluster, Kind: "setting", Name: setting, URL: url, Token: token})
+			if err != nil {
+				return fmt.Errorf("failed to get setting %s: %w", setting, err)
+			}
+			values[setting], _, _ = unstructured.NestedString(obj.Object, "value")
+		}
+		// both are set only if both settings were read, the server URL is never empty then
+		diagnosis.ServerURL = values["server-url"]
+		diagnosis.CAChecksum = caChecksum(values["cacerts"])
+		return nil
+	})
+	g.GoOptional("registrationToken", func(ctx context.Context) error {
+		tokens, err := t.client.GetResources(ctx, client.ListParams{Cluster: LocalCluster, Kind: "clusterregistrationtoken", Namespace: clusterID, URL: url, Token: token})
+		if err != nil {
+			return fmt.Errorf("failed to list the registration tokens: %w", err)
+		}
+		sort.Slice(tokens, func(i, j int) bool { return tokens[i].GetName() < tokens[j].GetName() })
+		for _, registrationToken := range tokens {
+			if command, _, _ := unstructured.NestedString(registrationToken.Object, "status", "command"); command != "" {
+				registrationCommand = command
+				break
+			}
+		}
+		return nil
+	})
+	g.GoOptional("tunnelLogs", func(ctx context.Context) error {
+		var pods []corev1.Pod
+		if err := listTyped(ctx, t.client, client.ListParams{Cluster: LocalCluster, Namespace: "cattle-system", URL: url, Token: token, LabelSelector: "app=rancher"}, "pod", &pods); err != nil {
+			return err
+		}
+		lines, err := t.matchingLogLines(ctx, url, token, LocalCluster, pods, sinceMinutes, regexp.MustCompile(regexp.QuoteMeta(clusterID)))
+		if err != nil {
+			return err
+		}
+		diagnosis.Tunnel.Logs = lines
+		return nil
+	})
+	g.GoOptional("agent", func(ctx context.Context) error {
+		diagnosis.Agent = t.agentDeployment(ctx, url, token, clusterID, sinceMinutes)
+		return nil
+	})
+	diagnosis.Failures, _ = g.Wait()
+
+	diagnosis.Causes, diagnosis.Remediations = agentCauses(diagnosis, registrationCommand)
+
+	response, err := json.Marshal(diagnosis)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// agentDeployment returns the cattle-cluster-agent deployment of a downstream cluster, with its pods and the error
+// lines of their logs. The downstream cluster is reached through the tunnel of the agent, so it's usually unreachable
+// when the agent is disconnected.
+func (t *Tools) agentDeployment(ctx context.Context, url string, token string, clusterID string, sinceMinutes int64) agentDeployment {
+	agent := agentDeployment{Pods: []componentPod{}, ErrorLogs: []string{}}
+	obj, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   clusterID,
+		Kind:      "deployment",
+		Namespace: clusterAgentNamespace,
+		Name:      clusterAgentComponent,
+		URL:       url,
+		Token:     token,
+	})
+	if err != nil {
+		agent.Error = err.Error()
+		return agent
+	}
+	agent.Reachable = true
+	var deployment appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+		agent.Error = err.Error()
+		return agent
+	}
+	agent.Replicas = ptr.Deref(deployment.Spec.Replicas, 1)
+	agent.ReadyReplicas = deployment.Status.ReadyReplicas
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != "cluster-register" {
+			continue
+		}
+		agent.Image = container.Image
+		for _, env := range container.Env {
+			switch env.Name {
+			case caChecksumEnv:
+				agent.CAChecksum = env.Value
+			case serverEnv:
+				agent.ServerURL = env.Value
+			}
+		}
+	}
+
+	var pods []corev1.Pod
+	if err := listTyped(ctx, t.client, client.ListParams{
+		Cluster:       clusterID,
+		Namespace:     clusterAgentNamespace,
+		URL:           url,
+		Token:         token,
+		LabelSelector: metav1.FormatLabelSelector(deployment.Spec.Selector),
+	}, "pod", &pods); err != nil {
+		agent.Error = err.Error()
+		return agent
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, pod := range pods {
+		agent.Pods = append(agent.Pods, newComponentPod(pod))
+	}
+	if lines, err := t.matchingLogLines(ctx, url, token, clusterID, pods, sinceMinutes, errorLogRE); err == nil {
+		agent.ErrorLogs = lines
+	}
+
+	return agent
+}
+
+// agentCauses returns the causes of the disconnection of an agent and their remediations.
+func agentCauses(diagnosis clusterAgentDiagnosis, registrationCommand string) ([]string, []string) {
+	causes := []string{}
+	remediations := []string{}
+	redeploy := false
+	if !diagnosis.Tunnel.Connected {
+		cause := "the tunnel session of the cattle-cluster-agent to Rancher is disconnected"
+		if diagnosis.Tunnel.Since != "" {
+			cause += " since " + diagnosis.Tunnel.Since
+		}
+		causes = append(causes, cause)
+	}
+	for _, condition := range diagnosis.Conditions {
+		if condition.Status == "False" && condition.Message != "" && condition.Type != "Connected" {
+			causes = append(causes, fmt.Sprintf("condition %s of the cluster is False: %s", condition.Type, condition.Message))
+		}
+	}
+
+	agent := diagnosis.Agent
+	switch {
+	case !agent.Reachable:
+		causes = append(causes, "the downstream cluster can't be reached through Rancher, the agent must be checked on the cluster itself")
+		remediations = append(remediations,
+			fmt.Sprintf("check the agent on the downstream cluster with 'kubectl -n %s get pods -l app=%s' and 'kubectl -n %s logs -l app=%s'", clusterAgentNamespace, clusterAgentComponent, clusterAgentNamespace, clusterAgentComponent))
+		redeploy = !diagnosis.Tunnel.Connected
+	case agent.ReadyReplicas == 0:
+		causes = append(causes, "no pod of the cattle-cluster-agent deployment is ready")
+		redeploy = true
+	case !diagnosis.Tunnel.Connected:
+		remediations = append(remediations, fmt.Sprintf("restart the agent with 'kubectl -n %s rollout restart deployment %s'", clusterAgentNamespace, clusterAgentComponent))
+	}
+	if agent.Reachable && diagnosis.ServerURL != "" && agent.CAChecksum != diagnosis.CAChecksum {
+		causes = append(causes, fmt.Sprintf("CA checksum mismatch: the agent expects %q but the cacerts setting of Rancher has the checksum %q, the agent refuses the certificate of Rancher", agent.CAChecksum, diagnosis.CAChecksum))
+		redeploy = true
+	}
+	if agent.Reachable && diagnosis.ServerURL != "" && agent.ServerURL != "" && strings.TrimSuffix(agent.ServerURL, "/") != strings.TrimSuffix(diagnosis.ServerURL, "/") {
+		causes = append(causes, fmt.Sprintf("the agent connects to %s but the server-url setting of Rancher is %s", agent.ServerURL, diagnosis.ServerURL))
+		redeploy = true
+	}
+	for _, hint := range agentLogHints {
+		for _, line := range agent.ErrorLogs {
+			if hint.re.MatchString(line) {
+				causes = append(causes, hint.hint)
+				break
+			}
+		}
+	}
+
+	if redeploy {
+		if registrationCommand != "" {
+			remediations = append(remediations, "redeploy the agent by running on the downstream cluster: "+registrationCommand)
+		} else {
+			remediations = append(remediations, "redeploy the agent with the registration command of the cluster, shown in the Rancher UI under Cluster Management")
+		}
+	}
+
+	return causes, remediations
+}
+
+// clusterConditions returns the conditions of a management cluster.
+func clusterConditions(cluster *unstructured.Unstructured) []clusterCondition {
+	conditions := []clusterCondition{}
+	items, _, _ := unstructured.NestedSlice(cluster.Object, "status", "conditions")
+	for _, item := range items {
+		c, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		condition := clusterCondition{}
+		condition.Type, _ = c["type"].(string)
+		condition.Status, _ = c["status"].(string)
+		condition.Reason, _ = c["reason"].(string)
+		condition.Message, _ = c["message"].(string)
+		condition.LastUpdateTime, _ = c["lastUpdateTime"].(string)
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
+
+// caChecksum returns the checksum of the CA certificates of Rancher, computed like Rancher does for the agents. It's
+// empty if Rancher uses a certificate signed by a public CA.
+func caChecksum(caCerts string) string {
+	if caCerts == "" {
+		return ""
+	}
+	if !strings.HasSuffix(caCerts, "\n") {
+		caCerts += "\n"
+	}
+	digest := sha256.Sum256([]byte(caCerts))
+
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,186 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+const testCACerts = "-----BEGIN CERTIFICATE-----\nMIIBdzCCAR2gAwIBAgIBADAKBggqhkjOPQQDAjA7\n-----END CERTIFICATE-----"
+
+func newSetting(name string, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "Setting",
+		"metadata":   map[string]interface{}{"name": name},
+		"value":      value,
+	}}
+}
+
+func newAgentDeployment(t *testing.T, caChecksum string, readyReplicas int32) runtime.Object {
+	return toUnstructured(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "cattle-cluster-agent", Namespace: "cattle-system"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cattle-cluster-agent"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "cluster-register",
+				Image: "rancher/rancher-agent:v2.12.2",
+				Env: []corev1.EnvVar{
+					{Name: "CATTLE_SERVER", Value: "https://rancher.example.com"},
+					{Name: "CATTLE_CA_CHECKSUM", Value: caChecksum},
+				},
+			}}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas},
+	}, "apps/v1", "Deployment")
+}
+
+func TestDiagnoseClusterAgent(t *testing.T) {
+	localObjects := []runtime.Object{
+		newManagementCluster("local", true),
+		newAgentCluster("c-m-abc12", "prod", "False", "False", "Cluster agent is not connected"),
+		newAgentCluster("c-m-def34", "dev", "False", "False", "Cluster agent is not connected"),
+		newSetting("server-url", "https://rancher.example.com"),
+		newSetting("cacerts", testCACerts),
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "ClusterRegistrationToken",
+			"metadata":   map[string]interface{}{"name": "default-token", "namespace": "c-m-def34"},
+			"status": map[string]interface{}{
+				"command": "kubectl apply -f https://rancher.example.com/v3/import/abcdef_c-m-def34.yaml",
+			},
+		}},
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:                  "ClusterList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusterregistrationtokens"}: "ClusterRegistrationTokenList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}:                               "DeploymentList",
+		{Version: "v1", Resource: "pods"}:                                                     "PodList",
+	}
+	tests := map[string]struct {
+		cluster            string
+		downstreamObjects  []runtime.Object
+		unreachable        bool
+		expectedCauses     []string
+		expectedRemedies   []string
+		expectedAgentError string
+		expectedError      string
+	}{
+		"agent not reachable": {
+			cluster:     "dev",
+			unreachable: true,
+			expectedCauses: []string{
+				"the tunnel session of the cattle-cluster-agent to Rancher is disconnected",
+				"condition Ready of the cluster is False: Cluster agent is not connected",
+				"the downstream cluster can't be reached through Rancher, the agent must be checked on the cluster itself",
+			},
+			expectedRemedies: []string{
+				"check the agent on the downstream cluster with 'kubectl -n cattle-system get pods -l app=cattle-cluster-agent' and 'kubectl -n cattle-system logs -l app=cattle-cluster-agent'",
+				"redeploy the agent by running on the downstream cluster: kubectl apply -f https://rancher.example.com/v3/import/abcdef_c-m-def34.yaml",
+			},
+			expectedAgentError: "cluster c-m-def34 is disconnected",
+		},
+		"CA checksum mismatch": {
+			cluster:           "c-m-abc12",
+			downstreamObjects: []runtime.Object{newAgentDeployment(t, "0123456789abcdef", 1)},
+			expectedCauses: []string{
+				"the tunnel session of the cattle-cluster-agent to Rancher is disconnected",
+				"condition Ready of the cluster is False: Cluster agent is not connected",
+				`CA checksum mismatch: the agent expects "0123456789abcdef" but the cacerts setting of Rancher has the checksum "` + caChecksum(testCACerts) + `", the agent refuses the certificate of Rancher`,
+			},
+			expectedRemedies: []string{
+				"restart the agent with 'kubectl -n cattle-system rollout restart deployment cattle-cluster-agent'",
+				"redeploy the agent with the registration command of the cluster, shown in the Rancher UI under Cluster Management",
+			},
+		},
+		"agent with the CA checksum of Rancher": {
+			cluster:           "c-m-abc12",
+			downstreamObjects: []runtime.Object{newAgentDeployment(t, caChecksum(testCACerts), 1)},
+			expectedCauses: []string{
+				"the tunnel session of the cattle-cluster-agent to Rancher is disconnected",
+				"condition Ready of the cluster is False: Cluster agent is not connected",
+			},
+			expectedRemedies: []string{
+				"restart the agent with 'kubectl -n cattle-system rollout restart deployment cattle-cluster-agent'",
+			},
+		},
+		"local cluster": {
+			cluster:       "local",
+			expectedError: "the local cluster doesn't have a cattle-cluster-agent connected to Rancher",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			localClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, localObjects...)
+			downstreamClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, test.downstreamObjects...)
+			if test.unreachable {
+				downstreamClient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewServiceUnavailable("cluster c-m-def34 is disconnected")
+				})
+			}
+			c := client.NewClient(true)
+			c.DynClientCreator = func(inConfig *rest.Config) (dynamic.Interface, error) {
+				if strings.HasSuffix(inConfig.Host, "/k8s/clusters/local") {
+					return localClient, nil
+				}
+				return downstreamClient, nil
+			}
+			c.ClientSetCreator = func(*rest.Config) (kubernetes.Interface, error) {
+				return fake.NewClientset(), nil
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.diagnoseClusterAgent(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, diagnoseClusterAgentParams{Cluster: test.cluster})
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var diagnosis clusterAgentDiagnosis
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &diagnosis))
+			assert.Equal(t, test.expectedCauses, diagnosis.Causes)
+			assert.Equal(t, test.expectedRemedies, diagnosis.Remediations)
+			assert.Equal(t, "https://rancher.example.com", diagnosis.ServerURL)
+			assert.False(t, diagnosis.Tunnel.Connected)
+			assert.Empty(t, diagnosis.Failures)
+			if test.expectedAgentError != "" {
+				assert.False(t, diagnosis.Agent.Reachable)
+				assert.Contains(t, diagnosis.Agent.Error, test.expectedAgentError)
+			} else {
+				assert.True(t, diagnosis.Agent.Reachable)
+				assert.Equal(t, "rancher/rancher-agent:v2.12.2", diagnosis.Agent.Image)
+			}
+		})
+	}
+}
+
+func TestCAChecksum(t *testing.T) {
+	assert.Empty(t, caChecksum(""))
+	assert.Equal(t, caChecksum(testCACerts), caChecksum(testCACerts+"\n"), "a trailing newline is added to the CA certificates")
+	assert.Len(t, caChecksum(testCACerts), 64)
+}
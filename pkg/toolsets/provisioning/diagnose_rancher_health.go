@@ -0,0 +1,380 @@
+package provisioning
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	criticalSeverity = "critical"
+	warningSeverity  = "warning"
+
+	// clusterAgentComponent is the component of the problems of the connection of the downstream clusters.
+	clusterAgentComponent = "cattle-cluster-agent"
+
+	// defaultErrorLogsSinceMinutes is the default age of the error logs returned.
+	defaultErrorLogsSinceMinutes = 60
+	// componentLogsTailLines is the number of log lines of each container scanned.
+	componentLogsTailLines int64 = 500
+	// maxLogLines is the maximum number of log lines returned per component.
+	maxLogLines = 10
+	// maxHealthyRestarts is the number of restarts of a container above which it's reported.
+	maxHealthyRestarts = 5
+)
+
+// errorLogRE matches the error lines of the logs of the Rancher components: logrus text and JSON, and klog.
+var errorLogRE = regexp.MustCompile(`\[(ERROR|FATAL)\]|level=(error|fatal)|"level":"(error|fatal)"|^[EF]\d{4} |^panic:`)
+
+// rancherComponent is a deployment of a Rancher component in the local cluster.
+type rancherComponent struct {
+	name      string
+	namespace string
+	// optional components are only installed in some setups, they aren't reported if missing.
+	optional bool
+	// impact explains the consequences of the component being down.
+	impact string
+}
+
+// rancherComponents are the components checked by diagnoseRancherHealth, by decreasing priority.
+var rancherComponents = []rancherComponent{
+	{name: "rancher", namespace: "cattle-system", impact: "the Rancher API and UI are unavailable"},
+	{name: "rancher-webhook", namespace: "cattle-system", impact: "the requests creating or updating Rancher resources are rejected"},
+	{name: "fleet-controller", namespace: "cattle-fleet-system", impact: "the GitRepos and the Rancher managed charts aren't deployed"},
+	{name: "capi-controller-manager", namespace: "cattle-provisioning-capi-system", optional: true, impact: "the clusters provisioned by Rancher aren't reconciled"},
+}
+
+type diagnoseRancherHealthParams struct {
+	SinceMinutes int64 `json:"sinceMinutes,omitempty" jsonschema:"the age in minutes of the error logs returned, 60 if not provided"`
+}
+
+// rancherHealth is the health of the Rancher components, with the problems found sorted by severity.
+type rancherHealth struct {
+	Healthy    bool                 `json:"healthy"`
+	Problems   []healthProblem      `json:"problems"`
+	Components []componentHealth    `json:"components"`
+	Clusters   []clusterAgentHealth `json:"clusters"`
+	Failures   []fetch.Failure      `json:"failures,omitempty"`
+}
+
+type healthProblem struct {
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+	Problem   string `json:"problem"`
+}
+
+// componentHealth is the health of the deployment of a Rancher component and of its pods.
+type componentHealth struct {
+	Name          string         `json:"name"`
+	Namespace     string         `json:"namespace"`
+	Installed     bool           `json:"installed"`
+	Replicas      int32          `json:"replicas"`
+	ReadyReplicas int32          `json:"readyReplicas"`
+	Pods          []componentPod `json:"pods"`
+	// ErrorLogs are the last error lines of the logs of the pods.
+	ErrorLogs []string `json:"errorLogs"`
+}
+
+type componentPod struct {
+	Name     string `json:"name"`
+	Node     string `json:"node,omitempty"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// clusterAgentHealth is the connection of the cattle-cluster-agent of a downstream cluster to Rancher.
+type clusterAgentHealth struct {
+	Cluster     string `json:"cluster"`
+	DisplayName string `json:"displayName,omitempty"`
+	Connected   bool   `json:"connected"`
+	Ready       bool   `json:"ready"`
+	Message     string `json:"message,omitempty"`
+}
+
+// diagnoseRancherHealth checks the Rancher components of the local cluster and the connection of the downstream
+// clusters, and returns the problems found from the most to the least severe.
+func (t *Tools) diagnoseRancherHealth(ctx context.Context, toolReq *mcp.CallToolRequest, params diagnoseRancherHealthParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "diagnoseRancherHealth"))
+	log.Debug("diagnoseRancherHealth called")
+
+	sinceMinutes := params.SinceMinutes
+	if sinceMinutes <= 0 {
+		sinceMinutes = defaultErrorLogsSinceMinutes
+	}
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+
+	health := rancherHealth{
+		Problems:   []healthProblem{},
+		Components: make([]componentHealth, len(rancherComponents)),
+		Clusters:   []clusterAgentHealth{},
+	}
+	var mu sync.Mutex
+	addProblems := func(problems ...healthProblem) {
+		mu.Lock()
+		defer mu.Unlock()
+		health.Problems = append(health.Problems, problems...)
+	}
+	g := fetch.New(ctx, 0)
+	for i, component := range rancherComponents {
+		g.GoOptional(component.name, func(ctx context.Context) error {
+			componentHealth, problems, err := t.componentHealth(ctx, url, token, component, sinceMinutes)
+			health.Components[i] = componentHealth
+			addProblems(problems...)
+			return err
+		})
+	}
+	g.GoOptional("clusters", func(ctx context.Context) error {
+		clusters, problems, err := t.clusterAgentsHealth(ctx, url, token)
+		if err != nil {
+			return err
+		}
+		health.Clusters = clusters
+		addProblems(problems...)
+		return nil
+	})
+	health.Failures, _ = g.Wait()
+
+	// the problems are sorted by severity, then in the order of the components
+	priority := map[string]int{}
+	for i, component := range rancherComponents {
+		priority[component.name] = i
+	}
+	priority[clusterAgentComponent] = len(rancherComponents)
+	sort.SliceStable(health.Problems, func(i, j int) bool {
+		if health.Problems[i].Severity != health.Problems[j].Severity {
+			return health.Problems[i].Severity == criticalSeverity
+		}
+		return priority[health.Problems[i].Component] < priority[health.Problems[j].Component]
+	})
+	health.Healthy = len(health.Problems) == 0 && len(health.Failures) == 0
+
+	response, err := json.Marshal(health)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// componentHealth returns the health of the deployment of a component and of its pods, with the error lines of their
+// logs, and the problems found.
+func (t *Tools) componentHealth(ctx context.Context, url string, token string, component rancherComponent, sinceMinutes int64) (componentHealth, []healthProblem, error) {
+	health := componentHealth{Name: component.name, Namespace: component.namespace, Pods: []componentPod{}, ErrorLogs: []string{}}
+	problem := func(severity string, format string, args ...any) healthProblem {
+		return healthProblem{Severity: severity, Component: component.name, Problem: fmt.Sprintf(format, args...)}
+	}
+
+	obj, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   LocalCluster,
+		Kind:      "deployment",
+		Namespace: component.namespace,
+		Name:      component.name,
+		URL:       url,
+		Token:     token,
+	})
+	if apierrors.IsNotFound(err) {
+		if component.optional {
+			return health, nil, nil
+		}
+		return health, []healthProblem{problem(criticalSeverity, "deployment %s/%s not found, %s", component.namespace, component.name, component.impact)}, nil
+	}
+	if err != nil {
+		return health, nil, err
+	}
+	var deployment appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+		return health, nil, fmt.Errorf("failed to convert deployment %s: %w", component.name, err)
+	}
+	health.Installed = true
+	health.Replicas = ptr.Deref(deployment.Spec.Replicas, 1)
+	health.ReadyReplicas = deployment.Status.ReadyReplicas
+
+	var problems []healthProblem
+	switch {
+	case health.Replicas == 0:
+		problems = append(problems, problem(criticalSeverity, "deployment %s/%s is scaled to 0, %s", component.namespace, component.name, component.impact))
+	case health.ReadyReplicas == 0:
+		problems = append(problems, problem(criticalSeverity, "no replica of %s is ready, %s", component.name, component.impact))
+	case health.ReadyReplicas < health.Replicas:
+		problems = append(problems, problem(warningSeverity, "%d/%d replicas of %s are ready", health.ReadyReplicas, health.Replicas, component.name))
+	}
+
+	var pods []corev1.Pod
+	if err := listTyped(ctx, t.client, client.ListParams{
+		Cluster:       LocalCluster,
+		Namespace:     component.namespace,
+		URL:           url,
+		Token:         token,
+		LabelSelector: metav1.FormatLabelSelector(deployment.Spec.Selector),
+	}, "pod", &pods); err != nil {
+		return health, problems, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, pod := range pods {
+		componentPod := newComponentPod(pod)
+		health.Pods = append(health.Pods, componentPod)
+		if !componentPod.Ready && componentPod.Reason != "" {
+			problems = append(problems, problem(warningSeverity, "pod %s is not ready: %s", pod.Name, componentPod.Reason))
+		}
+		if componentPod.Restarts > maxHealthyRestarts {
+			problems = append(problems, problem(warningSeverity, "pod %s restarted %d times", pod.Name, componentPod.Restarts))
+		}
+	}
+
+	errorLogs, err := t.matchingLogLines(ctx, url, token, LocalCluster, pods, sinceMinutes, errorLogRE)
+	if err != nil {
+		return health, problems, err
+	}
+	health.ErrorLogs = errorLogs
+	if len(health.ErrorLogs) > 0 {
+		problems = append(problems, problem(warningSeverity, "the logs of %s have errors in the last %d minutes", component.name, sinceMinutes))
+	}
+
+	return health, problems, nil
+}
+
+// newComponentPod returns the readiness and the restarts of a pod, with the reason its containers aren't running.
+func newComponentPod(pod corev1.Pod) componentPod {
+	componentPod := componentPod{Name: pod.Name, Node: pod.Spec.NodeName, Reason: pod.Status.Reason}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			componentPod.Ready = condition.Status == corev1.ConditionTrue
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		componentPod.Restarts += status.RestartCount
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+			componentPod.Reason = status.State.Waiting.Reason
+		case status.State.Terminated != nil && status.State.Terminated.Reason != "":
+			componentPod.Reason = status.State.Terminated.Reason
+		}
+	}
+	if componentPod.Reason == "" && !componentPod.Ready {
+		componentPod.Reason = string(pod.Status.Phase)
+	}
+
+	return componentPod
+}
+
+// matchingLogLines returns the last lines of the logs of the containers of the pods matching the regular expression,
+// prefixed by the name of their pod.
+func (t *Tools) matchingLogLines(ctx context.Context, url string, token string, cluster string, pods []corev1.Pod, sinceMinutes int64, re *regexp.Regexp) ([]string, error) {
+	clientset, err := t.client.CreateClientSet(ctx, token, url, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	lines := []string{}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container:    container.Name,
+				TailLines:    ptr.To(componentLogsTailLines),
+				SinceSeconds: ptr.To(sinceMinutes * 60),
+			}).Stream(ctx)
+			if err != nil {
+				// the container may not have started yet, its state is reported with the pod
+				continue
+			}
+			scanner := bufio.NewScanner(stream)
+			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				if re.MatchString(scanner.Text()) {
+					lines = append(lines, pod.Name+": "+scanner.Text())
+				}
+			}
+			stream.Close()
+		}
+	}
+	if len(lines) > maxLogLines {
+		lines = lines[len(lines)-maxLogLines:]
+	}
+
+	return lines, nil
+}
+
+// clusterAgentsHealth returns the connection of the cattle-cluster-agent of each downstream cluster, from the
+// conditions of the management clusters, and the problems found.
+func (t *Tools) clusterAgentsHealth(ctx context.Context, url string, token string) ([]clusterAgentHealth, []healthProblem, error) {
+	clusters, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster: LocalCluster,
+		Kind:    converter.ManagementClusterResourceKind,
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	agents := []clusterAgentHealth{}
+	for _, cluster := range clusters {
+		if cluster.GetName() == LocalCluster {
+			continue
+		}
+		agent := clusterAgentHealth{Cluster: cluster.GetName()}
+		agent.DisplayName, _, _ = unstructured.NestedString(cluster.Object, "spec", "displayName")
+		conditions, _, _ := unstructured.NestedSlice(cluster.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			status, _ := condition["status"].(string)
+			message, _ := condition["message"].(string)
+			switch condition["type"] {
+			case "Connected":
+				agent.Connected = status == "True"
+				if !agent.Connected && message != "" {
+					agent.Message = message
+				}
+			case "Ready":
+				agent.Ready = status == "True"
+				if !agent.Ready && agent.Message == "" {
+					agent.Message = message
+				}
+			}
+		}
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Cluster < agents[j].Cluster })
+
+	var problems []healthProblem
+	for _, agent := range agents {
+		name := agent.DisplayName
+		if name == "" {
+			name = agent.Cluster
+		}
+		switch {
+		case !agent.Connected:
+			problems = append(problems, healthProblem{Severity: criticalSeverity, Component: clusterAgentComponent, Problem: fmt.Sprintf("the cattle-cluster-agent of cluster %s isn't connected to Rancher, the cluster can't be managed", name)})
+		case !agent.Ready:
+			problems = append(problems, healthProblem{Severity: warningSeverity, Component: clusterAgentComponent, Problem: fmt.Sprintf("cluster %s isn't ready: %s", name, agent.Message)})
+		}
+	}
+
+	return agents, problems, nil
+}
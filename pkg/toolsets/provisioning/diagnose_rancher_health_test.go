@@ -0,0 +1,191 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+func newRancherDeployment(t *testing.T, name string, namespace string, replicas int32, readyReplicas int32) runtime.Object {
+	return toUnstructured(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas},
+	}, "apps/v1", "Deployment")
+}
+
+func newRancherPod(t *testing.T, name string, namespace string, app string, ready bool, restarts int32, waitingReason string) runtime.Object {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	containerStatus := corev1.ContainerStatus{Name: app, RestartCount: restarts}
+	if waitingReason != "" {
+		containerStatus.State.Waiting = &corev1.ContainerStateWaiting{Reason: waitingReason}
+	}
+	return toUnstructured(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{NodeName: "server-1", Containers: []corev1.Container{{Name: app}}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+			ContainerStatuses: []corev1.ContainerStatus{containerStatus},
+		},
+	}, "v1", "Pod")
+}
+
+func newAgentCluster(name string, displayName string, connected string, ready string, message string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{"displayName": displayName},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Connected", "status": connected},
+				map[string]interface{}{"type": "Ready", "status": ready, "message": message},
+			},
+		},
+	}}
+}
+
+func TestDiagnoseRancherHealth(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}: "ClusterList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}:              "DeploymentList",
+		{Version: "v1", Resource: "pods"}:                                    "PodList",
+	}
+
+	tests := map[string]struct {
+		objects        []runtime.Object
+		expectedResult string
+	}{
+		"problems sorted by severity": {
+			objects: []runtime.Object{
+				newManagementCluster("local", true),
+				newAgentCluster("c-m-abc12", "prod", "True", "True", ""),
+				newAgentCluster("c-m-def34", "dev", "False", "False", "Cluster agent is not connected"),
+				newAgentCluster("c-m-ghi56", "staging", "True", "False", "waiting for etcd"),
+				newRancherDeployment(t, "rancher", "cattle-system", 2, 2),
+				newRancherPod(t, "rancher-0", "cattle-system", "rancher", true, 7, ""),
+				newRancherPod(t, "rancher-1", "cattle-system", "rancher", true, 0, ""),
+				newRancherDeployment(t, "rancher-webhook", "cattle-system", 1, 0),
+				newRancherPod(t, "rancher-webhook-0", "cattle-system", "rancher-webhook", false, 3, "CrashLoopBackOff"),
+			},
+			expectedResult: `{
+				"healthy": false,
+				"problems": [
+					{"severity": "critical", "component": "rancher-webhook", "problem": "no replica of rancher-webhook is ready, the requests creating or updating Rancher resources are rejected"},
+					{"severity": "critical", "component": "fleet-controller", "problem": "deployment cattle-fleet-system/fleet-controller not found, the GitRepos and the Rancher managed charts aren't deployed"},
+					{"severity": "critical", "component": "cattle-cluster-agent", "problem": "the cattle-cluster-agent of cluster dev isn't connected to Rancher, the cluster can't be managed"},
+					{"severity": "warning", "component": "rancher", "problem": "pod rancher-0 restarted 7 times"},
+					{"severity": "warning", "component": "rancher-webhook", "problem": "pod rancher-webhook-0 is not ready: CrashLoopBackOff"},
+					{"severity": "warning", "component": "cattle-cluster-agent", "problem": "cluster staging isn't ready: waiting for etcd"}
+				],
+				"components": [
+					{
+						"name": "rancher", "namespace": "cattle-system", "installed": true, "replicas": 2, "readyReplicas": 2,
+						"pods": [
+							{"name": "rancher-0", "node": "server-1", "ready": true, "restarts": 7},
+							{"name": "rancher-1", "node": "server-1", "ready": true, "restarts": 0}
+						],
+						"errorLogs": []
+					},
+					{
+						"name": "rancher-webhook", "namespace": "cattle-system", "installed": true, "replicas": 1, "readyReplicas": 0,
+						"pods": [
+							{"name": "rancher-webhook-0", "node": "server-1", "ready": false, "restarts": 3, "reason": "CrashLoopBackOff"}
+						],
+						"errorLogs": []
+					},
+					{"name": "fleet-controller", "namespace": "cattle-fleet-system", "installed": false, "replicas": 0, "readyReplicas": 0, "pods": [], "errorLogs": []},
+					{"name": "capi-controller-manager", "namespace": "cattle-provisioning-capi-system", "installed": false, "replicas": 0, "readyReplicas": 0, "pods": [], "errorLogs": []}
+				],
+				"clusters": [
+					{"cluster": "c-m-abc12", "displayName": "prod", "connected": true, "ready": true},
+					{"cluster": "c-m-def34", "displayName": "dev", "connected": false, "ready": false, "message": "Cluster agent is not connected"},
+					{"cluster": "c-m-ghi56", "displayName": "staging", "connected": true, "ready": false, "message": "waiting for etcd"}
+				]
+			}`,
+		},
+		"healthy": {
+			objects: []runtime.Object{
+				newManagementCluster("local", true),
+				newAgentCluster("c-m-abc12", "prod", "True", "True", ""),
+				newRancherDeployment(t, "rancher", "cattle-system", 1, 1),
+				newRancherDeployment(t, "rancher-webhook", "cattle-system", 1, 1),
+				newRancherDeployment(t, "fleet-controller", "cattle-fleet-system", 1, 1),
+			},
+			expectedResult: `{
+				"healthy": true,
+				"problems": [],
+				"components": [
+					{"name": "rancher", "namespace": "cattle-system", "installed": true, "replicas": 1, "readyReplicas": 1, "pods": [], "errorLogs": []},
+					{"name": "rancher-webhook", "namespace": "cattle-system", "installed": true, "replicas": 1, "readyReplicas": 1, "pods": [], "errorLogs": []},
+					{"name": "fleet-controller", "namespace": "cattle-fleet-system", "installed": true, "replicas": 1, "readyReplicas": 1, "pods": [], "errorLogs": []},
+					{"name": "capi-controller-manager", "namespace": "cattle-provisioning-capi-system", "installed": false, "replicas": 0, "readyReplicas": 0, "pods": [], "errorLogs": []}
+				],
+				"clusters": [
+					{"cluster": "c-m-abc12", "displayName": "prod", "connected": true, "ready": true}
+				]
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, test.objects...)
+			c := client.NewClient(true)
+			c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+				return fakeDynClient, nil
+			}
+			c.ClientSetCreator = func(*rest.Config) (kubernetes.Interface, error) {
+				return fake.NewClientset(), nil
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.diagnoseRancherHealth(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, diagnoseRancherHealthParams{})
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestErrorLogRE(t *testing.T) {
+	tests := map[string]bool{
+		`2026/10/14 10:00:00 [ERROR] error syncing 'c-m-abc12': handler cluster-deploy: Get "https://10.43.0.1": dial tcp: i/o timeout`: true,
+		`2026/10/14 10:00:00 [INFO] Watching metadata for management.cattle.io/v3, Kind=Cluster`:                                        false,
+		`time="2026-10-14T10:00:00Z" level=error msg="error syncing 'fleet-default/prod'"`:                                              true,
+		`{"level":"error","ts":"2026-10-14T10:00:00Z","msg":"Reconciler error","controller":"gitjob"}`:                                  true,
+		`{"level":"info","ts":"2026-10-14T10:00:00Z","msg":"no error"}`:                                                                 false,
+		`E1014 10:00:00.000000       1 controller.go:324] "Reconciler error" err="machine not found"`:                                   true,
+		`I1014 10:00:00.000000       1 controller.go:100] "Starting workers"`:                                                           false,
+		`panic: runtime error: invalid memory address or nil pointer dereference`:                                                       true,
+	}
+
+	for line, expected := range tests {
+		assert.Equal(t, expected, errorLogRE.MatchString(line), line)
+	}
+}
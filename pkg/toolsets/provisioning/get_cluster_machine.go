@@ -18,7 +18,7 @@ type GetClusterMachineParams struct {
 
 // GetClusterMachine returns the cluster API machine for a given provisioning cluster and machine name.
 func (t *Tools) GetClusterMachine(ctx context.Context, toolReq *mcp.CallToolRequest, params GetClusterMachineParams) (*mcp.CallToolResult, any, error) {
-	log := utils.NewChildLogger(toolReq, map[string]string{
+	log := utils.NewChildLogger(ctx, toolReq, map[string]string{
 		"cluster":     params.Cluster,
 		"machineName": params.MachineName,
 	})
@@ -47,7 +47,7 @@ func (t *Tools) GetClusterMachine(ctx context.Context, toolReq *mcp.CallToolRequ
 	}
 
 	// all CAPI resources exist in the local cluster only.
-	mcpResponse, err := response.CreateMcpResponse(resources, "local")
+	mcpResponse, err := response.CreateMcpResponse(ctx, resources, "local")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/dynamic"
@@ -334,11 +335,11 @@ func TestGetClusterMachine(t *testing.T) {
 			}
 			tools := Tools{client: c}
 
-			result, _, err := tools.GetClusterMachine(context.TODO(), &mcp.CallToolRequest{
+			result, _, err := tools.GetClusterMachine(middleware.WithToken(context.TODO(), testToken), &mcp.CallToolRequest{
 				Params: &mcp.CallToolParamsRaw{
 					Name: "get-cluster-machine",
 				},
-				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}, tokenHeader: {testToken}}},
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
 			}, test.params)
 
 			if test.expectedError != "" {
@@ -0,0 +1,64 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type getClusterTemplateParams struct {
+	Name      string `json:"name" jsonschema:"the name of the ClusterClass"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the ClusterClass, defaults to fleet-default"`
+}
+
+// getClusterTemplate returns a ClusterClass of the local cluster with the schema of its variables.
+func (t *Tools) getClusterTemplate(ctx context.Context, toolReq *mcp.CallToolRequest, params getClusterTemplateParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "getClusterTemplate"))
+	log.Debug("getClusterTemplate called")
+
+	clusterClass, err := t.getClusterClass(ctx, toolReq, params.Name, params.Namespace)
+	if err != nil {
+		log.Error("failed to get ClusterClass", zap.Error(err))
+		return nil, nil, err
+	}
+
+	response, err := json.Marshal(newClusterTemplate(clusterClass))
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+func (t *Tools) getClusterClass(ctx context.Context, toolReq *mcp.CallToolRequest, name string, namespace string) (*unstructured.Unstructured, error) {
+	if namespace == "" {
+		namespace = DefaultClusterResourcesNamespace
+	}
+	clusterClass, err := t.client.GetResourceAtAnyAPIVersion(ctx, client.GetParams{
+		Cluster:   LocalCluster,
+		Kind:      converter.CAPIClusterClassResourceKind,
+		Namespace: namespace,
+		Name:      name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("ClusterClass %s/%s not found, use listClusterTemplates to list the available templates", namespace, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterClass %s/%s: %w", namespace, name, err)
+	}
+
+	return clusterClass, nil
+}
@@ -0,0 +1,69 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetClusterTemplate(t *testing.T) {
+	tests := map[string]struct {
+		params         getClusterTemplateParams
+		expectedError  string
+		expectedResult string
+	}{
+		"default namespace": {
+			params: getClusterTemplateParams{Name: "aws-rke2"},
+			expectedResult: `{
+				"name": "aws-rke2",
+				"namespace": "fleet-default",
+				"apiVersion": "cluster.x-k8s.io/v1beta1",
+				"infrastructure": "AWSClusterTemplate",
+				"controlPlane": "RKE2ControlPlaneTemplate",
+				"workerClasses": ["default-worker"],
+				"variables": [
+					{"name": "region", "required": true, "type": "string", "description": "The AWS region of the cluster", "example": "us-east-1"},
+					{"name": "instanceType", "required": true, "type": "string", "default": "t3.large", "enum": ["t3.large", "t3.xlarge"]}
+				]
+			}`,
+		},
+		"not found": {
+			params:        getClusterTemplateParams{Name: "aws-rke2", Namespace: "dev"},
+			expectedError: "ClusterClass dev/aws-rke2 not found, use listClusterTemplates to list the available templates",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), newClusterClass("aws-rke2", DefaultClusterResourcesNamespace))
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return newFakeClientsetWithCAPIDiscovery(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.getClusterTemplate(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -26,7 +26,7 @@ type K3kClusterDetails struct {
 // If no clusters are provided, it fetches K3k clusters from all available downstream clusters.
 // Returns a JSON map of downstream cluster names to lists of K3k cluster names.
 func (t *Tools) getK3kClusters(ctx context.Context, toolReq *mcp.CallToolRequest, params getK3kClustersParams) (*mcp.CallToolResult, any, error) {
-	zap.L().Debug("getK3kClusters called for clusters ", zap.Any("clusters", params.Clusters))
+	middleware.Logger(ctx).Debug("getK3kClusters called for clusters ", zap.Any("clusters", params.Clusters))
 
 	var clusters []string
 	if len(params.Clusters) == 0 {
@@ -56,7 +56,7 @@ func (t *Tools) getK3kClusters(ctx context.Context, toolReq *mcp.CallToolRequest
 		})
 
 		if err != nil {
-			zap.L().Warn("failed to get k3k clusters", zap.String("tool", "getK3kClusters"), zap.String("Downstream cluster", cluster))
+			middleware.Logger(ctx).Warn("failed to get k3k clusters", zap.String("tool", "getK3kClusters"), zap.String("Downstream cluster", cluster))
 		} else {
 			var clusterDetails []K3kClusterDetails
 			for _, k3kCluster := range k3kClusters {
@@ -74,7 +74,7 @@ func (t *Tools) getK3kClusters(ctx context.Context, toolReq *mcp.CallToolRequest
 
 	response, err := json.Marshal(k3kClustersMap)
 	if err != nil {
-		zap.L().Error("failed to create response", zap.String("tool", "getK3kClusters"), zap.Error(err))
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getK3kClusters"), zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
@@ -0,0 +1,213 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// machinePoolLabel is the label of the CAPI machines with the name of their machine pool.
+const machinePoolLabel = "rke.cattle.io/rke-machine-pool-name"
+
+type getMachineConfigsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the name of the provisioning cluster"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the cluster"`
+	Pool      string `json:"pool,omitempty" jsonschema:"the name of the machine pool, all the pools if empty"`
+}
+
+// poolMachineConfig is the machine config of a machine pool, with the fields that are usually changed and the
+// machines created from it.
+type poolMachineConfig struct {
+	Pool         string         `json:"pool"`
+	Quantity     int32          `json:"quantity"`
+	Roles        []string       `json:"roles"`
+	Kind         string         `json:"kind"`
+	Name         string         `json:"name"`
+	InstanceType any            `json:"instanceType,omitempty"`
+	DiskSize     any            `json:"diskSize,omitempty"`
+	Image        any            `json:"image,omitempty"`
+	Machines     []string       `json:"machines"`
+	Config       map[string]any `json:"config"`
+}
+
+// getMachineConfigs returns the machine configs of the machine pools of a provisioning cluster.
+func (t *Tools) getMachineConfigs(ctx context.Context, toolReq *mcp.CallToolRequest, params getMachineConfigsParams) (*mcp.CallToolResult, any, error) {
+	ns := clusterNamespace(params.Namespace, params.Cluster)
+	log := utils.NewChildLogger(ctx, toolReq, map[string]string{
+		"cluster":   params.Cluster,
+		"namespace": ns,
+	})
+	log.Debug("getMachineConfigs called")
+
+	_, provCluster, err := t.getProvisioningCluster(ctx, toolReq, log, ns, params.Cluster)
+	if apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("provisioning cluster %s not found in namespace %s", params.Cluster, ns)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pools, err := machinePools(provCluster, params.Pool)
+	if err != nil {
+		return nil, nil, err
+	}
+	machines, err := t.getPoolMachines(ctx, toolReq, log, ns, params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configs := []poolMachineConfig{}
+	for _, pool := range pools {
+		if pool.NodeConfig == nil {
+			// the machines of custom clusters aren't provisioned by Rancher
+			continue
+		}
+		config, err := t.getPoolMachineConfig(ctx, toolReq, ns, pool)
+		if err != nil {
+			log.Error("failed to get machine config", zap.String("pool", pool.Name), zap.Error(err))
+			return nil, nil, err
+		}
+		configs = append(configs, newPoolMachineConfigSummary(pool, config, machines[pool.Name]))
+	}
+
+	response, err := json.Marshal(configs)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// machinePools returns the machine pools of the cluster, or only the given pool if not empty.
+func machinePools(provCluster provisioningV1.Cluster, name string) ([]provisioningV1.RKEMachinePool, error) {
+	if provCluster.Spec.RKEConfig == nil || len(provCluster.Spec.RKEConfig.MachinePools) == 0 {
+		return nil, fmt.Errorf("cluster %s has no machine pools", provCluster.Name)
+	}
+	pools := provCluster.Spec.RKEConfig.MachinePools
+	if name == "" {
+		return pools, nil
+	}
+
+	var names []string
+	for _, pool := range pools {
+		if pool.Name == name {
+			return []provisioningV1.RKEMachinePool{pool}, nil
+		}
+		names = append(names, pool.Name)
+	}
+
+	return nil, fmt.Errorf("machine pool %s not found in cluster %s, must be one of %s", name, provCluster.Name, strings.Join(names, ", "))
+}
+
+func (t *Tools) getPoolMachineConfig(ctx context.Context, toolReq *mcp.CallToolRequest, namespace string, pool provisioningV1.RKEMachinePool) (*unstructured.Unstructured, error) {
+	config, err := t.client.GetResourceByGVR(ctx, client.GetParams{
+		Cluster:   LocalCluster,
+		Namespace: namespace,
+		Name:      pool.NodeConfig.Name,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}, machineConfigGVR(pool.NodeConfig.Kind))
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("machine config %s %s of pool %s not found", pool.NodeConfig.Kind, pool.NodeConfig.Name, pool.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the machine config of pool %s: %w", pool.Name, err)
+	}
+
+	return config, nil
+}
+
+// getPoolMachines returns the names of the CAPI machines of the cluster, indexed by machine pool.
+func (t *Tools) getPoolMachines(ctx context.Context, toolReq *mcp.CallToolRequest, log *zap.Logger, namespace string, cluster string) (map[string][]string, error) {
+	machines, _, _, err := t.getAllCAPIMachineResources(ctx, toolReq, log, getCAPIMachineResourcesParams{
+		namespace:     namespace,
+		targetCluster: cluster,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	poolMachines := map[string][]string{}
+	for _, machine := range machines {
+		pool := machine.GetLabels()[machinePoolLabel]
+		poolMachines[pool] = append(poolMachines[pool], machine.GetName())
+	}
+
+	return poolMachines, nil
+}
+
+func newPoolMachineConfigSummary(pool provisioningV1.RKEMachinePool, config *unstructured.Unstructured, machines []string) poolMachineConfig {
+	summary := poolMachineConfig{
+		Pool:     pool.Name,
+		Roles:    poolRoles(pool),
+		Kind:     config.GetKind(),
+		Name:     config.GetName(),
+		Machines: machines,
+		Config:   machineConfigFields(config),
+	}
+	if pool.Quantity != nil {
+		summary.Quantity = *pool.Quantity
+	}
+	if summary.Machines == nil {
+		summary.Machines = []string{}
+	}
+	if provider, ok := machineProviderOfKind(config.GetKind()); ok {
+		summary.InstanceType = config.Object[provider.instanceTypeField]
+		summary.Image = config.Object[provider.imageField]
+		if provider.diskSizeField != "" {
+			summary.DiskSize = config.Object[provider.diskSizeField]
+		}
+	}
+
+	return summary
+}
+
+func poolRoles(pool provisioningV1.RKEMachinePool) []string {
+	roles := []string{}
+	if pool.EtcdRole {
+		roles = append(roles, etcdRole)
+	}
+	if pool.ControlPlaneRole {
+		roles = append(roles, controlPlaneRole)
+	}
+	if pool.WorkerRole {
+		roles = append(roles, workerRole)
+	}
+
+	return roles
+}
+
+// machineProviderOfKind returns the supported cloud provider whose machines are configured by the kind.
+func machineProviderOfKind(kind string) (machineProvider, bool) {
+	for _, provider := range machineProviders {
+		if provider.kind == kind {
+			return provider, true
+		}
+	}
+
+	return machineProvider{}, false
+}
+
+// machineConfigGVR returns the resource of a machine config kind, which includes the configs of the node drivers
+// that aren't supported by createProvisionedCluster (e.g. VmwarevsphereConfig).
+func machineConfigGVR(kind string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    converter.MachineConfigGroup,
+		Version:  "v1",
+		Resource: strings.ToLower(kind) + "s",
+	}
+}
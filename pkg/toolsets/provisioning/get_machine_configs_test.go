@@ -0,0 +1,110 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// newPooledCluster creates a provisioning cluster with a control plane pool and a worker pool configured by
+// Amazonec2Configs, and the CAPI machines of the pools
+func newPooledCluster() []runtime.Object {
+	controlPlane := newMachinePool("cp", "nc-prod-cp", "Amazonec2Config", 1)
+	controlPlane.EtcdRole = true
+	controlPlane.ControlPlaneRole = true
+	workers := newMachinePool("workers", "nc-prod-workers", "Amazonec2Config", 2)
+	workers.WorkerRole = true
+
+	objects := []runtime.Object{
+		newProvisioningClusterWithRKEConfig("prod", DefaultClusterResourcesNamespace, "c-m-abc12", []provisioningV1.RKEMachinePool{controlPlane, workers}),
+		newPoolAmazonec2Config("nc-prod-cp", "t3.large"),
+		newPoolAmazonec2Config("nc-prod-workers", "t3.xlarge"),
+	}
+	for name, pool := range map[string]string{"prod-cp-abc12": "cp", "prod-workers-def34": "workers", "prod-workers-ghi56": "workers"} {
+		machine := newCAPIMachine(name, DefaultClusterResourcesNamespace, "prod", "Running", "")
+		machine.SetLabels(map[string]string{"cluster.x-k8s.io/cluster-name": "prod", machinePoolLabel: pool})
+		objects = append(objects, machine)
+	}
+
+	return objects
+}
+
+func newPoolAmazonec2Config(name string, instanceType string) *unstructured.Unstructured {
+	machineConfig := newMachineConfig(name, DefaultClusterResourcesNamespace, "Amazonec2Config")
+	delete(machineConfig.Object, "spec")
+	machineConfig.Object["region"] = "us-east-1"
+	machineConfig.Object["instanceType"] = instanceType
+	machineConfig.Object["rootSize"] = "16"
+	machineConfig.Object["ami"] = "ami-123"
+	return machineConfig
+}
+
+func TestGetMachineConfigs(t *testing.T) {
+	tests := map[string]struct {
+		params         getMachineConfigsParams
+		expectedError  string
+		expectedResult string
+	}{
+		"all pools": {
+			params: getMachineConfigsParams{Cluster: "prod"},
+			expectedResult: `[
+				{"pool": "cp", "quantity": 1, "roles": ["etcd", "controlplane"], "kind": "Amazonec2Config", "name": "nc-prod-cp", "instanceType": "t3.large", "diskSize": "16", "image": "ami-123", "machines": ["prod-cp-abc12"], "config": {"region": "us-east-1", "instanceType": "t3.large", "rootSize": "16", "ami": "ami-123"}},
+				{"pool": "workers", "quantity": 2, "roles": ["worker"], "kind": "Amazonec2Config", "name": "nc-prod-workers", "instanceType": "t3.xlarge", "diskSize": "16", "image": "ami-123", "machines": ["prod-workers-def34", "prod-workers-ghi56"], "config": {"region": "us-east-1", "instanceType": "t3.xlarge", "rootSize": "16", "ami": "ami-123"}}
+			]`,
+		},
+		"one pool": {
+			params: getMachineConfigsParams{Cluster: "prod", Pool: "cp"},
+			expectedResult: `[
+				{"pool": "cp", "quantity": 1, "roles": ["etcd", "controlplane"], "kind": "Amazonec2Config", "name": "nc-prod-cp", "instanceType": "t3.large", "diskSize": "16", "image": "ami-123", "machines": ["prod-cp-abc12"], "config": {"region": "us-east-1", "instanceType": "t3.large", "rootSize": "16", "ami": "ami-123"}}
+			]`,
+		},
+		"unknown pool": {
+			params:        getMachineConfigsParams{Cluster: "prod", Pool: "gpu"},
+			expectedError: "machine pool gpu not found in cluster prod, must be one of cp, workers",
+		},
+		"unknown cluster": {
+			params:        getMachineConfigsParams{Cluster: "staging"},
+			expectedError: "provisioning cluster staging not found in namespace fleet-default",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), newPooledCluster()...)
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return newFakeClientsetWithCAPIDiscovery(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.getMachineConfigs(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Name: "getMachineConfigs",
+				},
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type listClusterTemplatesParams struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the ClusterClasses, all namespaces if empty"`
+}
+
+// clusterTemplate summarizes a ClusterClass, the template of the Cluster API clusters with a managed topology.
+type clusterTemplate struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// APIVersion is the Cluster API version of the ClusterClass, used to create the clusters.
+	APIVersion string `json:"apiVersion"`
+	// Infrastructure and ControlPlane are the kinds of the templates of the infrastructure and of the control plane,
+	// e.g. AWSClusterTemplate and RKE2ControlPlaneTemplate.
+	Infrastructure string                    `json:"infrastructure,omitempty"`
+	ControlPlane   string                    `json:"controlPlane,omitempty"`
+	WorkerClasses  []string                  `json:"workerClasses,omitempty"`
+	Variables      []clusterTemplateVariable `json:"variables,omitempty"`
+}
+
+// clusterTemplateVariable is a variable of a ClusterClass, set in the topology of the clusters created from it.
+type clusterTemplateVariable struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Enum        []any  `json:"enum,omitempty"`
+	Example     any    `json:"example,omitempty"`
+}
+
+// listClusterTemplates lists the ClusterClasses of the local cluster, with the names of their variables.
+func (t *Tools) listClusterTemplates(ctx context.Context, toolReq *mcp.CallToolRequest, params listClusterTemplatesParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "listClusterTemplates"))
+	log.Debug("listClusterTemplates called")
+
+	clusterClasses, err := t.client.GetResourcesAtAnyAPIVersion(ctx, client.ListParams{
+		Cluster:   LocalCluster,
+		Kind:      converter.CAPIClusterClassResourceKind,
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Error("failed to list ClusterClasses", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list ClusterClasses: %w", err)
+	}
+
+	templates := make([]clusterTemplate, 0, len(clusterClasses))
+	for _, clusterClass := range clusterClasses {
+		template := newClusterTemplate(clusterClass)
+		// only the names and whether they are required are listed, getClusterTemplate returns their schema
+		for i, variable := range template.Variables {
+			template.Variables[i] = clusterTemplateVariable{Name: variable.Name, Required: variable.Required}
+		}
+		templates = append(templates, template)
+	}
+
+	response, err := json.Marshal(templates)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// newClusterTemplate returns the summary of a v1beta1 or v1beta2 ClusterClass. The variables are the ones defined
+// in the spec and the ones of the external patches, reported in the status.
+func newClusterTemplate(clusterClass *unstructured.Unstructured) clusterTemplate {
+	template := clusterTemplate{
+		Name:           clusterClass.GetName(),
+		Namespace:      clusterClass.GetNamespace(),
+		APIVersion:     clusterClass.GetAPIVersion(),
+		Infrastructure: templateRefKind(clusterClass.Object, "infrastructure"),
+		ControlPlane:   templateRefKind(clusterClass.Object, "controlPlane"),
+	}
+
+	machineDeployments, _, _ := unstructured.NestedSlice(clusterClass.Object, "spec", "workers", "machineDeployments")
+	for _, md := range machineDeployments {
+		if class, ok := md.(map[string]any)["class"].(string); ok {
+			template.WorkerClasses = append(template.WorkerClasses, class)
+		}
+	}
+
+	variables, _, _ := unstructured.NestedSlice(clusterClass.Object, "spec", "variables")
+	statusVariables, _, _ := unstructured.NestedSlice(clusterClass.Object, "status", "variables")
+	for _, statusVariable := range statusVariables {
+		// the status contains a definition of the variable per source, the first one is used
+		definitions, _, _ := unstructured.NestedSlice(statusVariable.(map[string]any), "definitions")
+		if len(definitions) == 0 {
+			continue
+		}
+		definition := definitions[0].(map[string]any)
+		definition["name"] = statusVariable.(map[string]any)["name"]
+		variables = append(variables, definition)
+	}
+	for _, v := range variables {
+		variable, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(variable, "name")
+		if slices.ContainsFunc(template.Variables, func(v clusterTemplateVariable) bool { return v.Name == name }) {
+			continue
+		}
+		required, _, _ := unstructured.NestedBool(variable, "required")
+		schema, _, _ := unstructured.NestedMap(variable, "schema", "openAPIV3Schema")
+		variableType, _, _ := unstructured.NestedString(schema, "type")
+		description, _, _ := unstructured.NestedString(schema, "description")
+		enum, _, _ := unstructured.NestedSlice(schema, "enum")
+		template.Variables = append(template.Variables, clusterTemplateVariable{
+			Name:        name,
+			Required:    required,
+			Type:        variableType,
+			Description: description,
+			Default:     schema["default"],
+			Enum:        enum,
+			Example:     schema["example"],
+		})
+	}
+
+	return template
+}
+
+// templateRefKind returns the kind of the template referenced by a field of the ClusterClass spec, with the ref of
+// v1beta1 or the templateRef of v1beta2.
+func templateRefKind(obj map[string]any, field string) string {
+	if kind, ok, _ := unstructured.NestedString(obj, "spec", field, "templateRef", "kind"); ok {
+		return kind
+	}
+	kind, _, _ := unstructured.NestedString(obj, "spec", field, "ref", "kind")
+
+	return kind
+}
@@ -0,0 +1,94 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestListClusterTemplates(t *testing.T) {
+	tests := map[string]struct {
+		objects        []runtime.Object
+		params         listClusterTemplatesParams
+		expectedResult string
+	}{
+		"all namespaces": {
+			objects: []runtime.Object{newClusterClass("aws-rke2", DefaultClusterResourcesNamespace), newClusterClass("aws-rke2-dev", "dev")},
+			expectedResult: `[
+				{"name": "aws-rke2-dev", "namespace": "dev", "apiVersion": "cluster.x-k8s.io/v1beta1", "infrastructure": "AWSClusterTemplate", "controlPlane": "RKE2ControlPlaneTemplate", "workerClasses": ["default-worker"], "variables": [{"name": "region", "required": true}, {"name": "instanceType", "required": true}]},
+				{"name": "aws-rke2", "namespace": "fleet-default", "apiVersion": "cluster.x-k8s.io/v1beta1", "infrastructure": "AWSClusterTemplate", "controlPlane": "RKE2ControlPlaneTemplate", "workerClasses": ["default-worker"], "variables": [{"name": "region", "required": true}, {"name": "instanceType", "required": true}]}
+			]`,
+		},
+		"namespace": {
+			objects: []runtime.Object{newClusterClass("aws-rke2", DefaultClusterResourcesNamespace), newClusterClass("aws-rke2-dev", "dev")},
+			params:  listClusterTemplatesParams{Namespace: "dev"},
+			expectedResult: `[
+				{"name": "aws-rke2-dev", "namespace": "dev", "apiVersion": "cluster.x-k8s.io/v1beta1", "infrastructure": "AWSClusterTemplate", "controlPlane": "RKE2ControlPlaneTemplate", "workerClasses": ["default-worker"], "variables": [{"name": "region", "required": true}, {"name": "instanceType", "required": true}]}
+			]`,
+		},
+		"no templates": {
+			expectedResult: `[]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), test.objects...)
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return newFakeClientsetWithCAPIDiscovery(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.listClusterTemplates(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestNewClusterTemplateV1Beta2(t *testing.T) {
+	clusterClass := newClusterClass("aws-rke2", DefaultClusterResourcesNamespace)
+	clusterClass.SetAPIVersion("cluster.x-k8s.io/v1beta2")
+	clusterClass.Object["spec"].(map[string]interface{})["infrastructure"] = map[string]interface{}{
+		"templateRef": map[string]interface{}{"kind": "DockerClusterTemplate", "name": "docker"},
+	}
+	clusterClass.Object["status"] = map[string]interface{}{
+		"variables": []interface{}{
+			map[string]interface{}{"name": "region"},
+			map[string]interface{}{
+				"name": "imageRepository",
+				"definitions": []interface{}{
+					map[string]interface{}{
+						"from":     "registry-patch",
+						"required": false,
+						"schema":   map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "string"}},
+					},
+				},
+			},
+		},
+	}
+
+	template := newClusterTemplate(clusterClass)
+
+	assert.Equal(t, "DockerClusterTemplate", template.Infrastructure)
+	assert.Equal(t, "RKE2ControlPlaneTemplate", template.ControlPlane)
+	require.Len(t, template.Variables, 3)
+	assert.Equal(t, clusterTemplateVariable{Name: "imageRepository", Type: "string"}, template.Variables[2])
+}
@@ -0,0 +1,90 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// pauseUpgradePlan pauses a plan. Plans don't have a paused field, so its concurrency is set to 0, which stops the
+// upgrade of new nodes, and the previous concurrency is kept in an annotation. The nodes being upgraded complete.
+func (t *Tools) pauseUpgradePlan(ctx context.Context, toolReq *mcp.CallToolRequest, params upgradePlanParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("pauseUpgradePlan called")
+
+	return t.setUpgradePlanPaused(ctx, toolReq, params, true)
+}
+
+// resumeUpgradePlan resumes a paused plan, restoring its concurrency.
+func (t *Tools) resumeUpgradePlan(ctx context.Context, toolReq *mcp.CallToolRequest, params upgradePlanParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("resumeUpgradePlan called")
+
+	return t.setUpgradePlanPaused(ctx, toolReq, params, false)
+}
+
+// setUpgradePlanPaused pauses or resumes a plan.
+func (t *Tools) setUpgradePlanPaused(ctx context.Context, toolReq *mcp.CallToolRequest, params upgradePlanParams, paused bool) (*mcp.CallToolResult, any, error) {
+	namespace := planNamespace(params.Namespace)
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), namespace, params.Cluster, converter.K8sKindsToGVRs["plan"])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := resourceInterface.Get(ctx, params.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get plan %s: %w", params.Name, err)
+	}
+	pausedConcurrency, isPaused := plan.GetAnnotations()[pausedConcurrencyAnn]
+
+	var patch map[string]any
+	switch {
+	case paused && isPaused:
+		return nil, nil, fmt.Errorf("plan %s is already paused", params.Name)
+	case paused:
+		concurrency, _, _ := unstructured.NestedInt64(plan.Object, "spec", "concurrency")
+		patch = map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{pausedConcurrencyAnn: strconv.FormatInt(concurrency, 10)}},
+			"spec":     map[string]any{"concurrency": 0},
+		}
+	case !isPaused:
+		return nil, nil, fmt.Errorf("plan %s isn't paused", params.Name)
+	default:
+		concurrency, err := strconv.ParseInt(pausedConcurrency, 10, 64)
+		if err != nil || concurrency < 1 {
+			concurrency = 1
+		}
+		patch = map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{pausedConcurrencyAnn: nil}},
+			"spec":     map[string]any{"concurrency": concurrency},
+		}
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	obj, err := resourceInterface.Patch(ctx, params.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to patch plan", zap.String("tool", "setUpgradePlanPaused"), zap.Bool("paused", paused), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to update plan %s: %w", params.Name, err)
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{obj}, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "setUpgradePlanPaused"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
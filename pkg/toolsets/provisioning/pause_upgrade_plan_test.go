@@ -0,0 +1,76 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetUpgradePlanPaused(t *testing.T) {
+	paused := newUpgradePlan("agent-plan", 0, nil)
+	paused.SetAnnotations(map[string]string{pausedConcurrencyAnn: "2"})
+
+	tests := map[string]struct {
+		plan                *unstructured.Unstructured
+		paused              bool
+		expectedConcurrency int64
+		expectedAnnotations map[string]string
+		expectedError       string
+	}{
+		"pause plan": {
+			plan:                newUpgradePlan("agent-plan", 3, nil),
+			paused:              true,
+			expectedConcurrency: 0,
+			expectedAnnotations: map[string]string{pausedConcurrencyAnn: "3"},
+		},
+		"resume plan": {
+			plan:                paused,
+			expectedConcurrency: 2,
+			expectedAnnotations: map[string]string{},
+		},
+		"pause paused plan": {
+			plan:          paused,
+			paused:        true,
+			expectedError: "plan agent-plan is already paused",
+		},
+		"resume plan not paused": {
+			plan:          newUpgradePlan("agent-plan", 3, nil),
+			expectedError: "plan agent-plan isn't paused",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newUpgradePlanTools(test.plan.DeepCopy())
+			ctx := middleware.WithToken(t.Context(), testToken)
+			toolReq := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}}}
+			params := upgradePlanParams{Cluster: "local", Name: "agent-plan"}
+
+			var err error
+			if test.paused {
+				_, _, err = tools.pauseUpgradePlan(ctx, toolReq, params)
+			} else {
+				_, _, err = tools.resumeUpgradePlan(ctx, toolReq, params)
+			}
+
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			resourceInterface, err := tools.client.GetResourceInterface(ctx, testToken, testURL, "cattle-system", "local", converter.K8sKindsToGVRs["plan"])
+			require.NoError(t, err)
+			plan, err := resourceInterface.Get(ctx, "agent-plan", metav1.GetOptions{})
+			require.NoError(t, err)
+			concurrency, _, _ := unstructured.NestedInt64(plan.Object, "spec", "concurrency")
+			assert.Equal(t, test.expectedConcurrency, concurrency)
+			assert.Equal(t, test.expectedAnnotations, plan.GetAnnotations())
+		})
+	}
+}
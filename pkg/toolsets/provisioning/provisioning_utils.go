@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"github.com/rancher/rancher-ai-mcp/pkg/converter"
 	provisioningV1 "github.com/rancher/rancher/pkg/apis/provisioning.cattle.io/v1"
@@ -47,7 +48,7 @@ func (t *Tools) getCAPIMachineResourcesByName(ctx context.Context, toolReq *mcp.
 		Namespace: params.namespace,
 		Name:      params.machineName,
 		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     toolReq.Extra.Header.Get(tokenHeader),
+		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -85,7 +86,7 @@ func (t *Tools) getCAPIMachineResourcesByName(ctx context.Context, toolReq *mcp.
 			Namespace: params.namespace,
 			Name:      ownerRef.Name,
 			URL:       toolReq.Extra.Header.Get(urlHeader),
-			Token:     toolReq.Extra.Header.Get(tokenHeader),
+			Token:     middleware.Token(ctx),
 		})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -127,7 +128,7 @@ func (t *Tools) getCAPIMachineResourcesByName(ctx context.Context, toolReq *mcp.
 			Namespace: params.namespace,
 			Name:      ownerRef.Name,
 			URL:       toolReq.Extra.Header.Get(urlHeader),
-			Token:     toolReq.Extra.Header.Get(tokenHeader),
+			Token:     middleware.Token(ctx),
 		})
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -193,7 +194,7 @@ func (t *Tools) getAllCAPIMachineResources(ctx context.Context, toolReq *mcp.Cal
 		Namespace:     params.namespace,
 		LabelSelector: clusterSelector.String(),
 		URL:           toolReq.Extra.Header.Get(urlHeader),
-		Token:         toolReq.Extra.Header.Get(tokenHeader),
+		Token:         middleware.Token(ctx),
 	})
 	if err != nil && !apierrors.IsNotFound(err) {
 		log.Error("failed to list CAPI machine deployments",
@@ -223,7 +224,7 @@ func (t *Tools) getAllCAPIMachineResources(ctx context.Context, toolReq *mcp.Cal
 		Namespace:     params.namespace,
 		LabelSelector: clusterSelector.String(),
 		URL:           toolReq.Extra.Header.Get(urlHeader),
-		Token:         toolReq.Extra.Header.Get(tokenHeader),
+		Token:         middleware.Token(ctx),
 	})
 	if err != nil && !apierrors.IsNotFound(err) {
 		log.Error("failed to list CAPI machine sets",
@@ -253,7 +254,7 @@ func (t *Tools) getAllCAPIMachineResources(ctx context.Context, toolReq *mcp.Cal
 		Namespace:     params.namespace,
 		LabelSelector: clusterSelector.String(),
 		URL:           toolReq.Extra.Header.Get(urlHeader),
-		Token:         toolReq.Extra.Header.Get(tokenHeader),
+		Token:         middleware.Token(ctx),
 	})
 	if err != nil && !apierrors.IsNotFound(err) {
 		log.Error("failed to list CAPI machines",
@@ -288,7 +289,7 @@ func (t *Tools) getProvisioningCluster(ctx context.Context, toolReq *mcp.CallToo
 		Namespace: ns,
 		Name:      clusterName,
 		URL:       toolReq.Extra.Header.Get(urlHeader),
-		Token:     toolReq.Extra.Header.Get(tokenHeader),
+		Token:     middleware.Token(ctx),
 	})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -361,7 +362,7 @@ func (t *Tools) getMachinePoolConfigs(ctx context.Context, toolReq *mcp.CallTool
 			Namespace: DefaultClusterResourcesNamespace,
 			Name:      configName,
 			URL:       toolReq.Extra.Header.Get(urlHeader),
-			Token:     toolReq.Extra.Header.Get(tokenHeader),
+			Token:     middleware.Token(ctx),
 		}, schema.GroupVersionResource{
 			Group:    "rke-machine-config.cattle.io",
 			Version:  "v1",
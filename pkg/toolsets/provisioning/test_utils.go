@@ -36,6 +36,7 @@ func capiCustomListKinds() map[schema.GroupVersionResource]string {
 		{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}:                "MachineList",
 		{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinesets"}:             "MachineSetList",
 		{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinedeployments"}:      "MachineDeploymentList",
+		{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "clusterclasses"}:          "ClusterClassList",
 		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:                 "ClusterList",
 		{Group: "rke-machine-config.cattle.io", Version: "v1", Resource: "amazonec2configs"}: "Amazonec2ConfigList",
 	}
@@ -378,3 +379,55 @@ func newK3kCluster(name string, mode string, version string, servers int, agents
 		},
 	}
 }
+
+// newClusterClass creates a test CAPI ClusterClass object with an AWS infrastructure, a RKE2 control plane and a
+// required region variable
+func newClusterClass(name, namespace string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cluster.x-k8s.io/v1beta1",
+			"kind":       "ClusterClass",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"infrastructure": map[string]interface{}{
+					"ref": map[string]interface{}{"kind": "AWSClusterTemplate", "name": name},
+				},
+				"controlPlane": map[string]interface{}{
+					"ref": map[string]interface{}{"kind": "RKE2ControlPlaneTemplate", "name": name},
+				},
+				"workers": map[string]interface{}{
+					"machineDeployments": []interface{}{
+						map[string]interface{}{"class": "default-worker"},
+					},
+				},
+				"variables": []interface{}{
+					map[string]interface{}{
+						"name":     "region",
+						"required": true,
+						"schema": map[string]interface{}{
+							"openAPIV3Schema": map[string]interface{}{
+								"type":        "string",
+								"description": "The AWS region of the cluster",
+								"example":     "us-east-1",
+							},
+						},
+					},
+					map[string]interface{}{
+						"name":     "instanceType",
+						"required": true,
+						"schema": map[string]interface{}{
+							"openAPIV3Schema": map[string]interface{}{
+								"type":    "string",
+								"default": "t3.large",
+								"enum":    []interface{}{"t3.large", "t3.xlarge"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
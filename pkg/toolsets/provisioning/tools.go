@@ -3,17 +3,20 @@ package provisioning
 import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
 )
 
 const (
 	toolsSet    = "provisioning"
 	toolsSetAnn = "toolset"
-	tokenHeader = "R_token"
-	urlHeader   = "R_url"
+	urlHeader   = client.URLHeader
 )
 
 type Tools struct {
 	client *client.Client
+	// ReadOnly disables the tools that create or update clusters and upgrade plans.
+	ReadOnly bool
 }
 
 func NewTools(client *client.Client) *Tools {
@@ -22,6 +25,13 @@ func NewTools(client *client.Client) *Tools {
 	}
 }
 
+// Register adds the tools of the provisioning toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	tools.ReadOnly = deps.ReadOnly
+	tools.AddTools(mcpServer)
+}
+
 func (t *Tools) AddTools(mcpServer *mcp.Server) {
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "analyzeCluster",
@@ -30,12 +40,15 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		},
 		Description: `Gets a cluster's complete configuration including provisioning and management clusters, the CAPI cluster, CAPI machines, and machine pool configs. 
 					  This should be used when a complete overview of the clusters current state and its configuration is required.'
+					  With failureAnalysis, it instead returns the likely root causes of a cluster stuck in provisioning, ranked from the most likely,
+					  found in the RKEControlPlane and RKEBootstrap conditions, the machine and infrastructure machine errors and the logs of the failed machine-provision jobs.
 
 		Parameters:
 		cluster (string): The name of the Kubernetes cluster
 		namespace (string): The namespace where the resource is located. The default namespace will be used if not provided.
+		failureAnalysis (bool, optional): Return the ranked root causes of the cluster being stuck in provisioning instead of its resources.
 		`},
-		t.AnalyzeCluster)
+		response.WithStructuredErrors(t.AnalyzeCluster))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "analyzeClusterMachines",
@@ -49,7 +62,7 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		cluster (string): The name of the Kubernetes cluster
 		namespace (string): The namespace where the resource is located. The default namespace will be used if not provided.
 		`},
-		t.AnalyzeClusterMachines)
+		response.WithStructuredErrors(t.AnalyzeClusterMachines))
 
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "getClusterMachine",
@@ -63,7 +76,154 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		cluster (string): The name of the Kubernetes cluster
 		machineName (string): The name of the machine to get
 		`},
-		t.GetClusterMachine)
+		response.WithStructuredErrors(t.GetClusterMachine))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "compareClusters",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Compares the configuration of two provisioning clusters and returns the fields that differ: Kubernetes version, CNI, machine global config, machine pools and their machine configs, upgrade strategy, chart values of the addons, additional manifest and registries.
+		This should be used to explain why two clusters behave differently (e.g. staging and production).
+
+		Parameters:
+		cluster (string): The name of the first cluster
+		namespace (string): Optional. The namespace of the first cluster. The default namespace will be used if not provided.
+		otherCluster (string): The name of the cluster compared to the first one
+		otherNamespace (string): Optional. The namespace of the other cluster. The default namespace will be used if not provided.
+		`},
+		response.WithStructuredErrors(t.CompareClusters))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "checkSupportMatrix",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Report the Rancher server version and the versions of the system charts installed by Rancher (Fleet, the Rancher webhook, the CAPI and hosted cluster operators),
+		the RKE2 and K3s versions available for this Rancher version, and check the Kubernetes version of the clusters against the Rancher support matrix.
+		This should be used before upgrading Rancher or a cluster, or when a cluster misbehaves after an upgrade.
+
+		Parameters:
+		clusters (array of strings): Optional. The IDs or the names of the clusters to check. All the clusters if not provided.
+		`},
+		response.WithStructuredErrors(t.checkSupportMatrix))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "diagnoseRancherHealth",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Check the health of the Rancher components of the local cluster: the rancher, rancher-webhook, fleet-controller and CAPI controller deployments,
+		their pods and the errors of their recent logs, and the connection of the cattle-cluster-agent of each downstream cluster.
+		It returns the problems found from the most to the least severe. This should be used when Rancher, its UI or the management of the clusters misbehaves.
+
+		Parameters:
+		sinceMinutes (int): Optional. The age in minutes of the error logs returned. Defaults to 60.
+		`},
+		response.WithStructuredErrors(t.diagnoseRancherHealth))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "diagnoseClusterAgent",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Troubleshoot a downstream cluster stuck Unavailable or Disconnected: check the conditions of the cluster, the tunnel session of its cattle-cluster-agent
+		in the Rancher logs and, if the cluster is reachable, the cattle-cluster-agent deployment, its pods and their error logs.
+		It detects a mismatch of the CA checksum or of the server URL of the agent, and suggests remediations such as the command redeploying the agent.
+
+		Parameters:
+		cluster (string): The ID or the name of the cluster.
+		sinceMinutes (int): Optional. The age in minutes of the logs returned. Defaults to 60.
+		`},
+		response.WithStructuredErrors(t.diagnoseClusterAgent))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "analyzeUpgradeImpact",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Analyze the impact of a Kubernetes upgrade of a cluster before doing it, and return a go/no-go decision with its blockers and warnings.
+		It checks the version skew, the deprecated APIs still requested that the target version removes, the CRDs with objects stored at versions they no longer serve,
+		the PodDisruptionBudgets that would block the drain of the nodes, and the workloads pinned to nodes by hostname or version.
+
+		Parameters:
+		cluster (string): The ID or the name of the cluster to upgrade.
+		targetVersion (string): The Kubernetes version of the upgrade (e.g., 'v1.31.4+rke2r1').
+		`},
+		response.WithStructuredErrors(t.analyzeUpgradeImpact))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listUpgradePlans",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `List the System Upgrade Controller plans of a cluster, used by Rancher to upgrade K3s and RKE2 on the nodes and to patch or upgrade their OS.
+		It returns the image, the version or channel, the concurrency and whether the plan is paused, with the number of the nodes it selects that are upgraded, being upgraded, pending or disabled.
+
+		Parameters:
+		cluster (string): The ID or the name of the cluster.
+		namespace (string): Optional. The namespace of the plans. All namespaces if not provided.
+		`},
+		response.WithStructuredErrors(t.listUpgradePlans))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getUpgradePlanProgress",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Get the upgrade progress of a System Upgrade Controller plan per node: whether each node it selects is upgraded, being upgraded, pending or disabled,
+		whether it's cordoned, its kubelet version, OS image and kernel version, and the status of its latest upgrade job with the message of its failure.
+		This should be used to follow an upgrade of the nodes or to find why it's stuck.
+
+		Parameters:
+		cluster (string): The ID or the name of the cluster.
+		namespace (string): Optional. The namespace of the plan. Defaults to 'cattle-system'.
+		name (string): The name of the plan.
+		`},
+		response.WithStructuredErrors(t.getUpgradePlanProgress))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createUpgradePlan",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Create a System Upgrade Controller plan upgrading the nodes of a cluster, e.g. K3s with the image 'rancher/k3s-upgrade' or an OS patch with a custom image.
+		The nodes selected by the plan are upgraded as soon as the plan is created, the concurrency nodes at a time.
+		Ask for confirmation before creating the plan, as nodes may be cordoned, drained and restarted.
+
+		Parameters:
+		name (string): The name of the plan.
+		namespace (string): Optional. The namespace of the plan, which must be the one of the System Upgrade Controller. Defaults to 'cattle-system'.
+		cluster (string): The ID or the name of the cluster whose nodes are upgraded.
+		image (string): The image of the upgrade (e.g., 'rancher/k3s-upgrade').
+		version (string): Optional. The version of the upgrade, used as tag of the image (e.g., 'v1.31.4+k3s1'). Exactly one of version and channel must be provided.
+		channel (string): Optional. The URL of a channel resolving the latest version (e.g., 'https://update.k3s.io/v1-release/channels/stable').
+		concurrency (int): Optional. The number of nodes upgraded at the same time. Defaults to 1.
+		nodeSelector (object): Optional. The labels of the nodes to upgrade (e.g., {"node-role.kubernetes.io/control-plane": "true"}). All the nodes if not provided.
+		cordon (boolean): Optional. Cordon the nodes before upgrading them.
+		drain (boolean): Optional. Drain the nodes before upgrading them.
+		serviceAccountName (string): Optional. The service account of the upgrade jobs. Defaults to 'system-upgrade-controller'.
+		`},
+		response.WithStructuredErrors(t.createUpgradePlan))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "pauseUpgradePlan",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Pause a System Upgrade Controller plan: no other node starts upgrading until it's resumed, the nodes being upgraded complete their upgrade.
+		The concurrency of the plan is set to 0, and restored when it's resumed.
+
+		Parameters:
+		cluster (string): The ID or the name of the cluster.
+		namespace (string): Optional. The namespace of the plan. Defaults to 'cattle-system'.
+		name (string): The name of the plan.
+		`},
+		response.WithStructuredErrors(t.pauseUpgradePlan))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "resumeUpgradePlan",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Resume a System Upgrade Controller plan paused with pauseUpgradePlan, restoring its concurrency.
+
+		Parameters:
+		cluster (string): The ID or the name of the cluster.
+		namespace (string): Optional. The namespace of the plan. Defaults to 'cattle-system'.
+		name (string): The name of the plan.
+		`},
+		response.WithStructuredErrors(t.resumeUpgradePlan))
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "listK3kClusters",
 		Meta: map[string]any{
@@ -74,7 +234,7 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		Parameters:
 		clusters (array of strings): List of clusters to get virtual clusters from. Empty for return virtual clusters for all clusters.
 		`},
-		t.getK3kClusters)
+		response.WithStructuredErrors(t.getK3kClusters))
 	mcp.AddTool(mcpServer, &mcp.Tool{
 		Name: "createK3kCluster",
 		Meta: map[string]any{
@@ -95,5 +255,162 @@ func (t *Tools) AddTools(mcpServer *mcp.Server) {
 		workerLimit (object): Optional. Resource constraints for worker nodes (contains 'cpu' and 'memory' strings).
 		persistence (object): Optional. Storage settings for etcd data (contains 'type' ('dynamic' or 'ephemeral'), 'storageClassName', 'storageRequest' strings).
 		`},
-		t.createK3kCluster)
+		response.WithStructuredErrors(t.createK3kCluster))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createProvisionedCluster",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Create a new RKE2 or K3s cluster whose machines are provisioned by Rancher in Amazon EC2, Azure or DigitalOcean.
+		It creates a machine config for each machine pool and the provisioning cluster. The cloud credential is validated before creating anything.
+		Ask for confirmation before creating the cluster, as it creates machines in the cloud provider.
+
+		Parameters:
+		name (string): The name of the cluster.
+		namespace (string): Optional. The namespace of the cluster. Defaults to 'fleet-default'.
+		kubernetesVersion (string): The RKE2 or K3s version of the cluster (e.g., 'v1.31.4+rke2r1').
+		provider (string): The cloud provider. One of 'amazonec2', 'azure' or 'digitalocean'.
+		cloudCredential (string): The ID (e.g., 'cattle-global-data:cc-abc12') or the name of the cloud credential of the provider.
+		region (string): The region of the machines (e.g., 'us-east-1'). For Azure, the location (e.g., 'westeurope').
+		instanceType (string): The instance type of the machines (e.g., 't3.large'). For Azure and DigitalOcean, the size (e.g., 'Standard_D2s_v3', 's-2vcpu-4gb').
+		machinePools (array of objects): The machine pools. Each pool has a 'name', a 'quantity', 'roles' (any of 'etcd', 'controlplane' and 'worker') and an optional 'instanceType' overriding the one of the cluster. Every role must be in at least one pool.
+		machineConfig (object): Optional. Additional provider specific fields of the machine configs (e.g., 'zone', 'vpcId' and 'subnetId' for Amazon EC2).
+		`},
+		response.WithStructuredErrors(t.createProvisionedCluster))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getMachineConfigs",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Get the machine configs of the machine pools of a cluster provisioned by Rancher, with their instance type, disk size and image (or AMI),
+		the roles and the machines of each pool.
+
+		Parameters:
+		cluster (string): The name of the provisioning cluster.
+		namespace (string): Optional. The namespace of the cluster. Defaults to 'fleet-default'.
+		pool (string): Optional. The name of the machine pool. All the pools if not provided.
+		`},
+		response.WithStructuredErrors(t.getMachineConfigs))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "updateMachineConfig",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Update the machine config of a machine pool, e.g. its instance type, disk size or image (or AMI).
+		Every machine of the pool is replaced one by one with a new machine using the updated config, the response lists the machines replaced.
+		Ask for confirmation before updating the machine config, and get it first to show the current values.
+
+		Parameters:
+		cluster (string): The name of the provisioning cluster.
+		namespace (string): Optional. The namespace of the cluster. Defaults to 'fleet-default'.
+		pool (string): The name of the machine pool.
+		instanceType (string): Optional. The instance type of the machines (e.g., 't3.large'). For Azure and DigitalOcean, the size (e.g., 'Standard_D2s_v3', 's-2vcpu-4gb').
+		diskSize (string): Optional. The size of the root disk of the machines in GB (e.g., '100'). Not supported for DigitalOcean.
+		image (string): Optional. The image of the machines (e.g., 'ami-0abcdef1234567890' for Amazon EC2).
+		fields (object): Optional. Other provider specific fields of the machine config to change (e.g., 'zone' or 'subnetId' for Amazon EC2).
+		`},
+		response.WithStructuredErrors(t.updateMachineConfig))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listClusterTemplates",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `List the cluster templates (Cluster API ClusterClasses) available to create clusters, with their infrastructure and control plane,
+		their worker classes and the names of their variables.
+
+		Parameters:
+		namespace (string): Optional. The namespace of the ClusterClasses. All namespaces if not provided.
+		`},
+		response.WithStructuredErrors(t.listClusterTemplates))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getClusterTemplate",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Get a cluster template (Cluster API ClusterClass) with the type, description, default value and allowed values of its variables.
+		This should be used before creating a cluster from the template to know the values to ask for.
+
+		Parameters:
+		name (string): The name of the ClusterClass.
+		namespace (string): Optional. The namespace of the ClusterClass. Defaults to 'fleet-default'.
+		`},
+		response.WithStructuredErrors(t.getClusterTemplate))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "createClusterFromTemplate",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Create a Cluster API cluster from a cluster template (ClusterClass), which is imported in Rancher once provisioned.
+		The variables and the worker classes are validated against the template before creating the cluster.
+		Ask for confirmation before creating the cluster, as it creates machines in the infrastructure provider.
+
+		Parameters:
+		name (string): The name of the cluster.
+		namespace (string): Optional. The namespace of the cluster, which must be the one of the ClusterClass. Defaults to 'fleet-default'.
+		template (string): The name of the ClusterClass.
+		kubernetesVersion (string): The Kubernetes version of the cluster (e.g., 'v1.31.4').
+		controlPlaneReplicas (int): Optional. The number of control plane machines. Defaults to 1.
+		workers (array of objects): Optional. The machine deployments of the workers. Each one has a 'class' (a worker class of the template), a 'name' and a number of 'replicas'.
+		variables (object): Optional. The values of the variables of the template, by name. Required variables without a default value must be set.
+		`},
+		response.WithStructuredErrors(t.createClusterFromTemplate))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getClusterRegistrationCommand",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Get the command adding nodes to an existing custom RKE2/K3s cluster, whose nodes are registered by running the command on them instead of being provisioned by Rancher.
+		The command installs the rancher-system-agent with the newest registration token of the cluster, the CA checksum of Rancher and the flags of the roles of the nodes.
+		It also returns the registration tokens of the cluster, without their values. Clusters with machine pools and imported clusters are not supported.
+
+		Parameters:
+		cluster (string): The ID or the name of the custom cluster.
+		roles (array of strings): The roles of the nodes to add: etcd, controlplane and/or worker.
+		insecure (boolean): Optional. Download the install script without verifying the certificate of Rancher, for a self-signed certificate not trusted by the nodes.
+		`},
+		response.WithStructuredErrors(t.getClusterRegistrationCommand))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "addCustomClusterNode",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Guide the addition of a Linux node to an existing custom RKE2/K3s cluster, in two calls.
+		The first call returns the exact curl command to run as root on the node, with the registration token, the CA checksum of Rancher, the flags of the roles and the options of the node,
+		and the since time. Once the command ran on the node, call it again with the since time and waitSeconds to wait for the new machine to appear and become Ready.
+		It returns the new machines with their roles, phase and the conditions they are waiting for. RKE1 clusters, registered with a docker command, are not supported.
+
+		Parameters:
+		cluster (string): The ID or the name of the custom cluster.
+		roles (array of strings): The roles of the node to add: etcd, controlplane and/or worker.
+		insecure (boolean): Optional. Download the install script without verifying the certificate of Rancher, for a self-signed certificate not trusted by the node.
+		nodeName (string): Optional. The name of the node. Defaults to its hostname.
+		address (string): Optional. The public address of the node.
+		internalAddress (string): Optional. The internal address of the node.
+		labels (object): Optional. The labels of the node (e.g., {"disktype": "ssd"}).
+		taints (array of strings): Optional. The taints of the node (e.g., ["dedicated=gpu:NoSchedule"]).
+		waitSeconds (int): Optional. Wait up to this many seconds, at most 300, for a machine registered since the since time to become Ready. Defaults to 0, returning the command without waiting.
+		since (string): Optional. The since time returned by the first call (e.g., '2025-01-01T00:00:00Z'). Defaults to now.
+		`},
+		response.WithStructuredErrors(t.addCustomClusterNode))
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "rotateClusterRegistrationToken",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Create a new registration token for a custom RKE2/K3s cluster and return the command adding nodes with it, e.g. when a previous command leaked.
+		The previous tokens stay valid until they are deleted. With deletePrevious, they are deleted once the new token is generated and their commands stop working,
+		the nodes already registered are not affected. Ask for confirmation before deleting the previous tokens.
+
+		Parameters:
+		cluster (string): The ID or the name of the custom cluster.
+		roles (array of strings): The roles of the nodes to add: etcd, controlplane and/or worker.
+		insecure (boolean): Optional. Download the install script without verifying the certificate of Rancher, for a self-signed certificate not trusted by the nodes.
+		deletePrevious (boolean): Optional. Delete the other registration tokens of the cluster once the new one is generated.
+		`},
+		response.WithStructuredErrors(t.rotateClusterRegistrationToken))
+
+	if t.ReadOnly {
+		mcpServer.RemoveTools("createK3kCluster", "createProvisionedCluster", "updateMachineConfig", "createClusterFromTemplate",
+			"createUpgradePlan", "pauseUpgradePlan", "resumeUpgradePlan", "rotateClusterRegistrationToken")
+	}
 }
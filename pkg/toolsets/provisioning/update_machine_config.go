@@ -0,0 +1,178 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type updateMachineConfigParams struct {
+	Cluster      string         `json:"cluster" jsonschema:"the name of the provisioning cluster"`
+	Namespace    string         `json:"namespace,omitempty" jsonschema:"the namespace of the cluster"`
+	Pool         string         `json:"pool" jsonschema:"the name of the machine pool"`
+	InstanceType string         `json:"instanceType,omitempty" jsonschema:"the instance type (or Azure/DigitalOcean size) of the machines"`
+	DiskSize     string         `json:"diskSize,omitempty" jsonschema:"the size of the root disk of the machines in GB"`
+	Image        string         `json:"image,omitempty" jsonschema:"the image (or AMI) of the machines"`
+	Fields       map[string]any `json:"fields,omitempty" jsonschema:"other provider specific fields of the machine config to change, e.g. zone or subnetId"`
+}
+
+// machineConfigChange is a field of a machine config changed by updateMachineConfig.
+type machineConfigChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"oldValue"`
+	NewValue any    `json:"newValue"`
+}
+
+// updateMachineConfig patches the machine config of a machine pool. Rancher creates a new machine template when the
+// machine config changes, so every machine of the pools using the config is replaced one by one: the change is only
+// applied if it modifies the config, and the machines that will be replaced are returned.
+func (t *Tools) updateMachineConfig(ctx context.Context, toolReq *mcp.CallToolRequest, params updateMachineConfigParams) (*mcp.CallToolResult, any, error) {
+	ns := clusterNamespace(params.Namespace, params.Cluster)
+	log := utils.NewChildLogger(ctx, toolReq, map[string]string{
+		"cluster":   params.Cluster,
+		"namespace": ns,
+		"pool":      params.Pool,
+	})
+	log.Debug("updateMachineConfig called")
+
+	if params.Cluster == "" || params.Pool == "" {
+		return nil, nil, errors.New("cluster and pool are required")
+	}
+	_, provCluster, err := t.getProvisioningCluster(ctx, toolReq, log, ns, params.Cluster)
+	if apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("provisioning cluster %s not found in namespace %s", params.Cluster, ns)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pools, err := machinePools(provCluster, params.Pool)
+	if err != nil {
+		return nil, nil, err
+	}
+	pool := pools[0]
+	if pool.NodeConfig == nil {
+		return nil, nil, fmt.Errorf("machine pool %s has no machine config, its machines aren't provisioned by Rancher", pool.Name)
+	}
+	config, err := t.getPoolMachineConfig(ctx, toolReq, ns, pool)
+	if err != nil {
+		log.Error("failed to get machine config", zap.Error(err))
+		return nil, nil, err
+	}
+
+	patch, err := machineConfigPatch(config.GetKind(), params)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes := []machineConfigChange{}
+	for _, field := range slices.Sorted(maps.Keys(patch)) {
+		if !reflect.DeepEqual(config.Object[field], patch[field]) {
+			changes = append(changes, machineConfigChange{Field: field, OldValue: config.Object[field], NewValue: patch[field]})
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil, fmt.Errorf("the machine config %s of pool %s already has these values, no machine would be replaced", config.GetName(), pool.Name)
+	}
+
+	// the machine configs created by Rancher are only used by one pool, but a config can be referenced by several
+	affectedPools := []string{}
+	for _, p := range provCluster.Spec.RKEConfig.MachinePools {
+		if p.NodeConfig != nil && p.NodeConfig.Kind == pool.NodeConfig.Kind && p.NodeConfig.Name == pool.NodeConfig.Name {
+			affectedPools = append(affectedPools, p.Name)
+		}
+	}
+	poolMachines, err := t.getPoolMachines(ctx, toolReq, log, ns, params.Cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	affectedMachines := []string{}
+	for _, p := range affectedPools {
+		affectedMachines = append(affectedMachines, poolMachines[p]...)
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, middleware.Token(ctx), toolReq.Extra.Header.Get(urlHeader), ns, LocalCluster, machineConfigGVR(config.GetKind()))
+	if err != nil {
+		log.Error("failed to get resource interface", zap.Error(err))
+		return nil, nil, err
+	}
+	updated, err := resourceInterface.Patch(ctx, config.GetName(), types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		log.Error("failed to patch machine config", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to update the machine config of pool %s: %w", pool.Name, err)
+	}
+
+	log.Info("machine config updated", zap.Int("changes", len(changes)), zap.Int("affectedMachines", len(affectedMachines)))
+
+	summary := &unstructured.Unstructured{Object: map[string]any{
+		"cluster":            params.Cluster,
+		"pool":               pool.Name,
+		"changes":            changes,
+		"rollingReplacement": true,
+		"affectedPools":      affectedPools,
+		"affectedMachines":   affectedMachines,
+	}}
+	mcpResponse, err := response.CreateMcpResponse(ctx, []*unstructured.Unstructured{updated, summary}, LocalCluster)
+	if err != nil {
+		log.Error("failed to create MCP response", zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
+
+// machineConfigPatch returns the fields of the machine config set by the parameters. The instance type, the disk size
+// and the image are mapped to the fields of the supported cloud providers, other node drivers can only be changed
+// with their own fields.
+func machineConfigPatch(kind string, params updateMachineConfigParams) (map[string]any, error) {
+	patch := map[string]any{}
+	for field, value := range params.Fields {
+		if slices.Contains([]string{"apiVersion", "kind", "metadata", "status"}, field) {
+			return nil, fmt.Errorf("field %s of the machine config can't be changed", field)
+		}
+		patch[field] = value
+	}
+
+	if params.InstanceType != "" || params.DiskSize != "" || params.Image != "" {
+		provider, ok := machineProviderOfKind(kind)
+		if !ok {
+			return nil, fmt.Errorf("instanceType, diskSize and image are only supported for the %s machine configs, use fields to change a %s", strings.Join(slices.Sorted(maps.Keys(machineProviders)), ", "), kind)
+		}
+		if params.InstanceType != "" {
+			patch[provider.instanceTypeField] = params.InstanceType
+		}
+		if params.Image != "" {
+			patch[provider.imageField] = params.Image
+		}
+		if params.DiskSize != "" {
+			if provider.diskSizeField == "" {
+				return nil, fmt.Errorf("the disk size of the %s machines is set by their size", kind)
+			}
+			patch[provider.diskSizeField] = params.DiskSize
+		}
+	}
+	if len(patch) == 0 {
+		return nil, errors.New("at least one of instanceType, diskSize, image or fields is required")
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,115 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestUpdateMachineConfig(t *testing.T) {
+	tests := map[string]struct {
+		params               updateMachineConfigParams
+		expectedError        string
+		expectedResult       string
+		expectedInstanceType string
+	}{
+		"instance type and disk size": {
+			params: updateMachineConfigParams{Cluster: "prod", Pool: "workers", InstanceType: "m5.xlarge", DiskSize: "16", Image: "ami-456"},
+			expectedResult: `{
+				"llm": [
+					{"apiVersion": "rke-machine-config.cattle.io/v1", "kind": "Amazonec2Config", "metadata": {"name": "nc-prod-workers", "namespace": "fleet-default"}, "region": "us-east-1", "instanceType": "m5.xlarge", "rootSize": "16", "ami": "ami-456"},
+					{
+						"cluster": "prod",
+						"pool": "workers",
+						"changes": [
+							{"field": "ami", "oldValue": "ami-123", "newValue": "ami-456"},
+							{"field": "instanceType", "oldValue": "t3.xlarge", "newValue": "m5.xlarge"}
+						],
+						"rollingReplacement": true,
+						"affectedPools": ["workers"],
+						"affectedMachines": ["prod-workers-def34", "prod-workers-ghi56"]
+					}
+				],
+				"uiContext": [
+					{"cluster": "local", "kind": "Amazonec2Config", "name": "nc-prod-workers", "namespace": "fleet-default", "type": "rke-machine-config.cattle.io.amazonec2config"}
+				]
+			}`,
+			expectedInstanceType: "m5.xlarge",
+		},
+		"provider specific fields": {
+			params:               updateMachineConfigParams{Cluster: "prod", Pool: "cp", Fields: map[string]any{"zone": "b"}},
+			expectedInstanceType: "t3.xlarge",
+		},
+		"no change": {
+			params:        updateMachineConfigParams{Cluster: "prod", Pool: "workers", InstanceType: "t3.xlarge"},
+			expectedError: "the machine config nc-prod-workers of pool workers already has these values, no machine would be replaced",
+		},
+		"metadata": {
+			params:        updateMachineConfigParams{Cluster: "prod", Pool: "workers", Fields: map[string]any{"metadata": map[string]any{}}},
+			expectedError: "field metadata of the machine config can't be changed",
+		},
+		"nothing to change": {
+			params:        updateMachineConfigParams{Cluster: "prod", Pool: "workers"},
+			expectedError: "at least one of instanceType, diskSize, image or fields is required",
+		},
+		"unknown pool": {
+			params:        updateMachineConfigParams{Cluster: "prod", Pool: "gpu", InstanceType: "p3.2xlarge"},
+			expectedError: "machine pool gpu not found in cluster prod",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(capiMachineScheme(), capiCustomListKinds(), newPooledCluster()...)
+			c := &client.Client{
+				ClientSetCreator: func(inConfig *rest.Config) (kubernetes.Interface, error) {
+					return newFakeClientsetWithCAPIDiscovery(), nil
+				},
+				DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+					return fakeDynClient, nil
+				},
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.updateMachineConfig(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{
+					Name: "updateMachineConfig",
+				},
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if test.expectedResult != "" {
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+			config, err := fakeDynClient.Resource(machineConfigGVR("Amazonec2Config")).Namespace(DefaultClusterResourcesNamespace).Get(t.Context(), "nc-prod-workers", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedInstanceType, config.Object["instanceType"])
+		})
+	}
+}
+
+func TestMachineConfigPatch(t *testing.T) {
+	_, err := machineConfigPatch("DigitaloceanConfig", updateMachineConfigParams{DiskSize: "100"})
+	assert.EqualError(t, err, "the disk size of the DigitaloceanConfig machines is set by their size")
+
+	_, err = machineConfigPatch("VmwarevsphereConfig", updateMachineConfigParams{InstanceType: "large"})
+	assert.EqualError(t, err, "instanceType, diskSize and image are only supported for the amazonec2, azure, digitalocean machine configs, use fields to change a VmwarevsphereConfig")
+
+	patch, err := machineConfigPatch("AzureConfig", updateMachineConfigParams{InstanceType: "Standard_D4s_v3", DiskSize: "64", Fields: map[string]any{"vnet": "prod"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"size": "Standard_D4s_v3", "diskSize": "64", "vnet": "prod"}, patch)
+}
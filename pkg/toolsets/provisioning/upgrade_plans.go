@@ -0,0 +1,343 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/fetch"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// planNodeLabelPrefix prefixes the label set by the System Upgrade Controller on the nodes upgraded by a plan,
+	// with the hash of the plan as value. The value disabled excludes a node from the plan.
+	planNodeLabelPrefix = "plan.upgrade.cattle.io/"
+	planNodeDisabled    = "disabled"
+	// jobPlanLabel and jobNodeLabel are the labels of the upgrade jobs with their plan and node.
+	jobPlanLabel = "upgrade.cattle.io/plan"
+	jobNodeLabel = "upgrade.cattle.io/node"
+	// pausedConcurrencyAnn is the annotation of a paused plan with the concurrency restored when it's resumed.
+	pausedConcurrencyAnn = "upgrade.cattle.io/paused-concurrency"
+	// systemUpgradeControllerNamespace is the namespace where Rancher installs the System Upgrade Controller, which
+	// only reconciles the plans of its namespace.
+	systemUpgradeControllerNamespace = "cattle-system"
+
+	nodeUpgradeComplete = "complete"
+	nodeUpgradeApplying = "applying"
+	nodeUpgradePending  = "pending"
+	nodeUpgradeDisabled = "disabled"
+)
+
+// upgradePlan is a System Upgrade Controller Plan, with the fields used by the tools.
+type upgradePlan struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              upgradePlanSpec   `json:"spec"`
+	Status            upgradePlanStatus `json:"status"`
+}
+
+type upgradePlanSpec struct {
+	Concurrency        int64                 `json:"concurrency"`
+	NodeSelector       *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	ServiceAccountName string                `json:"serviceAccountName,omitempty"`
+	Channel            string                `json:"channel,omitempty"`
+	Version            string                `json:"version,omitempty"`
+	Upgrade            *upgradePlanContainer `json:"upgrade,omitempty"`
+	Cordon             bool                  `json:"cordon,omitempty"`
+	Drain              map[string]any        `json:"drain,omitempty"`
+}
+
+type upgradePlanContainer struct {
+	Image string `json:"image,omitempty"`
+}
+
+type upgradePlanStatus struct {
+	Conditions    []planCondition `json:"conditions,omitempty"`
+	LatestVersion string          `json:"latestVersion,omitempty"`
+	LatestHash    string          `json:"latestHash,omitempty"`
+	Applying      []string        `json:"applying,omitempty"`
+}
+
+type planCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type listUpgradePlansParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the ID or the name of the cluster"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the plans, all namespaces if not provided"`
+}
+
+type upgradePlanParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the ID or the name of the cluster"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"the namespace of the plan, cattle-system if not provided"`
+	Name      string `json:"name" jsonschema:"the name of the plan"`
+}
+
+// upgradePlanSummary is a plan with the number of its nodes in each upgrade state.
+type upgradePlanSummary struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Image     string `json:"image"`
+	Version   string `json:"version,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+	// LatestVersion is the version resolved by the controller, from the channel if set.
+	LatestVersion string          `json:"latestVersion,omitempty"`
+	Concurrency   int64           `json:"concurrency"`
+	Paused        bool            `json:"paused"`
+	Cordon        bool            `json:"cordon"`
+	Drain         bool            `json:"drain"`
+	NodeSelector  string          `json:"nodeSelector"`
+	Nodes         int             `json:"nodes"`
+	Complete      int             `json:"complete"`
+	Applying      []string        `json:"applying"`
+	Pending       int             `json:"pending"`
+	Disabled      int             `json:"disabled"`
+	Conditions    []planCondition `json:"conditions"`
+}
+
+// upgradePlanProgress is a plan with the upgrade state of each of its nodes.
+type upgradePlanProgress struct {
+	upgradePlanSummary
+	NodeUpgrades []nodeUpgrade   `json:"nodeUpgrades"`
+	Failures     []fetch.Failure `json:"failures,omitempty"`
+}
+
+// nodeUpgrade is the upgrade state of a node selected by a plan, with the versions it runs and its latest upgrade job.
+type nodeUpgrade struct {
+	Name           string      `json:"name"`
+	State          string      `json:"state"`
+	Cordoned       bool        `json:"cordoned"`
+	KubeletVersion string      `json:"kubeletVersion"`
+	OSImage        string      `json:"osImage"`
+	KernelVersion  string      `json:"kernelVersion"`
+	Job            *upgradeJob `json:"job,omitempty"`
+}
+
+// upgradeJob is the status of the job upgrading a node.
+type upgradeJob struct {
+	Name      string `json:"name"`
+	Active    int32  `json:"active"`
+	Succeeded int32  `json:"succeeded"`
+	Failed    int32  `json:"failed"`
+	Message   string `json:"message,omitempty"`
+}
+
+// listUpgradePlans lists the System Upgrade Controller plans of a cluster with the number of nodes upgraded by each.
+func (t *Tools) listUpgradePlans(ctx context.Context, toolReq *mcp.CallToolRequest, params listUpgradePlansParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "listUpgradePlans"), zap.String("cluster", params.Cluster))
+	log.Debug("listUpgradePlans called")
+
+	listParams := client.ListParams{
+		Cluster:   params.Cluster,
+		Namespace: params.Namespace,
+		URL:       toolReq.Extra.Header.Get(urlHeader),
+		Token:     middleware.Token(ctx),
+	}
+	var plans []upgradePlan
+	var nodes []corev1.Node
+	g := fetch.New(ctx, 0)
+	g.Go("plans", func(ctx context.Context) error {
+		return listTyped(ctx, t.client, listParams, "plan", &plans)
+	})
+	g.Go("nodes", func(ctx context.Context) error {
+		return listTyped(ctx, t.client, client.ListParams{Cluster: listParams.Cluster, URL: listParams.URL, Token: listParams.Token}, "node", &nodes)
+	})
+	if _, err := g.Wait(); err != nil {
+		log.Error("failed to list the upgrade plans", zap.Error(err))
+		return nil, nil, err
+	}
+
+	summaries := make([]upgradePlanSummary, 0, len(plans))
+	for _, plan := range plans {
+		summary, _, err := planProgress(plan, nodes)
+		if err != nil {
+			return nil, nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	response, err := json.Marshal(summaries)
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// getUpgradePlanProgress returns the upgrade state of each node selected by a plan, with its upgrade job.
+func (t *Tools) getUpgradePlanProgress(ctx context.Context, toolReq *mcp.CallToolRequest, params upgradePlanParams) (*mcp.CallToolResult, any, error) {
+	log := middleware.Logger(ctx).With(zap.String("tool", "getUpgradePlanProgress"), zap.String("cluster", params.Cluster), zap.String("plan", params.Name))
+	log.Debug("getUpgradePlanProgress called")
+
+	namespace := planNamespace(params.Namespace)
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	var plan upgradePlan
+	var nodes []corev1.Node
+	var jobs []batchv1.Job
+	g := fetch.New(ctx, 0)
+	g.Go("plan", func(ctx context.Context) error {
+		obj, err := t.client.GetResource(ctx, client.GetParams{Cluster: params.Cluster, Kind: "plan", Namespace: namespace, Name: params.Name, URL: url, Token: token})
+		if err != nil {
+			return fmt.Errorf("failed to get plan %s: %w", params.Name, err)
+		}
+		return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &plan)
+	})
+	g.Go("nodes", func(ctx context.Context) error {
+		return listTyped(ctx, t.client, client.ListParams{Cluster: params.Cluster, URL: url, Token: token}, "node", &nodes)
+	})
+	g.GoOptional("jobs", func(ctx context.Context) error {
+		return listTyped(ctx, t.client, client.ListParams{
+			Cluster:       params.Cluster,
+			Namespace:     namespace,
+			URL:           url,
+			Token:         token,
+			LabelSelector: jobPlanLabel + "=" + params.Name,
+		}, "job", &jobs)
+	})
+	failures, err := g.Wait()
+	if err != nil {
+		log.Error("failed to get the upgrade plan", zap.Error(err))
+		return nil, nil, err
+	}
+
+	summary, nodeUpgrades, err := planProgress(plan, nodes)
+	if err != nil {
+		return nil, nil, err
+	}
+	latestJobs := map[string]batchv1.Job{}
+	for _, job := range jobs {
+		node := job.Labels[jobNodeLabel]
+		if latest, ok := latestJobs[node]; !ok || latest.CreationTimestamp.Before(&job.CreationTimestamp) {
+			latestJobs[node] = job
+		}
+	}
+	for i, node := range nodeUpgrades {
+		if job, ok := latestJobs[node.Name]; ok {
+			nodeUpgrades[i].Job = newUpgradeJob(job)
+		}
+	}
+
+	response, err := json.Marshal(upgradePlanProgress{upgradePlanSummary: summary, NodeUpgrades: nodeUpgrades, Failures: failures})
+	if err != nil {
+		log.Error("failed to create response", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// planProgress returns the summary of a plan and the upgrade state of the nodes it selects, sorted by name.
+func planProgress(plan upgradePlan, nodes []corev1.Node) (upgradePlanSummary, []nodeUpgrade, error) {
+	selector := labels.Everything()
+	if plan.Spec.NodeSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(plan.Spec.NodeSelector)
+		if err != nil {
+			return upgradePlanSummary{}, nil, fmt.Errorf("invalid node selector of plan %s: %w", plan.Name, err)
+		}
+	}
+
+	summary := upgradePlanSummary{
+		Namespace:     plan.Namespace,
+		Name:          plan.Name,
+		Version:       plan.Spec.Version,
+		Channel:       plan.Spec.Channel,
+		LatestVersion: plan.Status.LatestVersion,
+		Concurrency:   plan.Spec.Concurrency,
+		Paused:        plan.Annotations[pausedConcurrencyAnn] != "",
+		Cordon:        plan.Spec.Cordon,
+		Drain:         plan.Spec.Drain != nil,
+		NodeSelector:  selector.String(),
+		Applying:      []string{},
+		Conditions:    append([]planCondition{}, plan.Status.Conditions...),
+	}
+	if plan.Spec.Upgrade != nil {
+		summary.Image = plan.Spec.Upgrade.Image
+	}
+
+	nodeUpgrades := []nodeUpgrade{}
+	for _, node := range nodes {
+		if !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		nodeUpgrade := nodeUpgrade{
+			Name:           node.Name,
+			State:          nodeUpgradePending,
+			Cordoned:       node.Spec.Unschedulable,
+			KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+			OSImage:        node.Status.NodeInfo.OSImage,
+			KernelVersion:  node.Status.NodeInfo.KernelVersion,
+		}
+		switch hash := node.Labels[planNodeLabelPrefix+plan.Name]; {
+		case hash == planNodeDisabled:
+			nodeUpgrade.State = nodeUpgradeDisabled
+			summary.Disabled++
+		case slices.Contains(plan.Status.Applying, node.Name):
+			nodeUpgrade.State = nodeUpgradeApplying
+			summary.Applying = append(summary.Applying, node.Name)
+		case hash != "" && hash == plan.Status.LatestHash:
+			nodeUpgrade.State = nodeUpgradeComplete
+			summary.Complete++
+		default:
+			summary.Pending++
+		}
+		nodeUpgrades = append(nodeUpgrades, nodeUpgrade)
+	}
+	summary.Nodes = len(nodeUpgrades)
+	sort.Strings(summary.Applying)
+	sort.Slice(nodeUpgrades, func(i, j int) bool {
+		return nodeUpgrades[i].Name < nodeUpgrades[j].Name
+	})
+
+	return summary, nodeUpgrades, nil
+}
+
+// newUpgradeJob returns the status of an upgrade job, with the message of its failure if it failed.
+func newUpgradeJob(job batchv1.Job) *upgradeJob {
+	upgradeJob := &upgradeJob{
+		Name:      job.Name,
+		Active:    job.Status.Active,
+		Succeeded: job.Status.Succeeded,
+		Failed:    job.Status.Failed,
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			upgradeJob.Message = condition.Message
+		}
+	}
+
+	return upgradeJob
+}
+
+// planNamespace returns the namespace of a plan, the one of the System Upgrade Controller if not provided.
+func planNamespace(namespace string) string {
+	if namespace == "" {
+		return systemUpgradeControllerNamespace
+	}
+	return namespace
+}
@@ -0,0 +1,241 @@
+package provisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+func upgradePlanListKinds() map[schema.GroupVersionResource]string {
+	return map[schema.GroupVersionResource]string{
+		{Group: "upgrade.cattle.io", Version: "v1", Resource: "plans"}: "PlanList",
+		{Version: "v1", Resource: "nodes"}:                             "NodeList",
+		{Group: "batch", Version: "v1", Resource: "jobs"}:              "JobList",
+	}
+}
+
+func newUpgradePlan(name string, concurrency int64, nodeSelector map[string]interface{}, applying ...string) *unstructured.Unstructured {
+	applyingNodes := []interface{}{}
+	for _, node := range applying {
+		applyingNodes = append(applyingNodes, node)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "upgrade.cattle.io/v1",
+		"kind":       "Plan",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "cattle-system"},
+		"spec": map[string]interface{}{
+			"concurrency":        concurrency,
+			"nodeSelector":       nodeSelector,
+			"serviceAccountName": "system-upgrade-controller",
+			"version":            "v1.31.4+k3s1",
+			"cordon":             true,
+			"upgrade":            map[string]interface{}{"image": "rancher/k3s-upgrade"},
+		},
+		"status": map[string]interface{}{
+			"latestVersion": "v1.31.4-k3s1",
+			"latestHash":    name + "-hash",
+			"applying":      applyingNodes,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "LatestResolved", "status": "True", "reason": "Version"},
+			},
+		},
+	}}
+}
+
+func newUpgradeNode(t *testing.T, name string, kubeletVersion string, nodeLabels map[string]string) runtime.Object {
+	return toUnstructured(t, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+		Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{
+			KubeletVersion: kubeletVersion,
+			OSImage:        "SUSE Linux Enterprise Micro 6.0",
+			KernelVersion:  "6.4.0-17-default",
+		}},
+	}, "v1", "Node")
+}
+
+func newPlanJob(t *testing.T, name string, plan string, node string, created metav1.Time, status batchv1.JobStatus) runtime.Object {
+	return toUnstructured(t, &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "cattle-system",
+			CreationTimestamp: created,
+			Labels:            map[string]string{jobPlanLabel: plan, jobNodeLabel: node},
+		},
+		Status: status,
+	}, "batch/v1", "Job")
+}
+
+func upgradePlanObjects(t *testing.T) []runtime.Object {
+	controlPlane := map[string]interface{}{"matchLabels": map[string]interface{}{"node-role.kubernetes.io/control-plane": "true"}}
+	workers := map[string]interface{}{"matchExpressions": []interface{}{
+		map[string]interface{}{"key": "node-role.kubernetes.io/control-plane", "operator": "DoesNotExist"},
+	}}
+	paused := newUpgradePlan("agent-plan", 0, workers, "worker-1")
+	paused.SetAnnotations(map[string]string{pausedConcurrencyAnn: "2"})
+	earlier := metav1.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	later := metav1.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC)
+
+	return []runtime.Object{
+		newUpgradePlan("server-plan", 1, controlPlane),
+		paused,
+		newUpgradeNode(t, "server-1", "v1.31.4+k3s1", map[string]string{"node-role.kubernetes.io/control-plane": "true", "plan.upgrade.cattle.io/server-plan": "server-plan-hash"}),
+		newUpgradeNode(t, "worker-1", "v1.30.8+k3s1", map[string]string{"plan.upgrade.cattle.io/agent-plan": "previous-hash"}),
+		newUpgradeNode(t, "worker-2", "v1.31.4+k3s1", map[string]string{"plan.upgrade.cattle.io/agent-plan": "agent-plan-hash"}),
+		newUpgradeNode(t, "worker-3", "v1.30.8+k3s1", nil),
+		newUpgradeNode(t, "worker-4", "v1.30.8+k3s1", map[string]string{"plan.upgrade.cattle.io/agent-plan": "disabled"}),
+		newPlanJob(t, "apply-agent-plan-on-worker-1-old", "agent-plan", "worker-1", earlier, batchv1.JobStatus{
+			Failed: 1,
+			Conditions: []batchv1.JobCondition{{
+				Type:    batchv1.JobFailed,
+				Status:  corev1.ConditionTrue,
+				Message: "Job has reached the specified backoff limit",
+			}},
+		}),
+		newPlanJob(t, "apply-agent-plan-on-worker-1", "agent-plan", "worker-1", later, batchv1.JobStatus{Active: 1}),
+		newPlanJob(t, "apply-agent-plan-on-worker-2", "agent-plan", "worker-2", earlier, batchv1.JobStatus{Succeeded: 1}),
+		newPlanJob(t, "apply-server-plan-on-server-1", "server-plan", "server-1", earlier, batchv1.JobStatus{Succeeded: 1}),
+	}
+}
+
+func newUpgradePlanTools(objects ...runtime.Object) Tools {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), upgradePlanListKinds(), objects...)
+	c := client.NewClient(true)
+	c.DynClientCreator = func(*rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+
+	return Tools{client: c}
+}
+
+func TestListUpgradePlans(t *testing.T) {
+	tools := newUpgradePlanTools(upgradePlanObjects(t)...)
+
+	result, _, err := tools.listUpgradePlans(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+	}, listUpgradePlansParams{Cluster: "local"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"namespace": "cattle-system",
+			"name": "agent-plan",
+			"image": "rancher/k3s-upgrade",
+			"version": "v1.31.4+k3s1",
+			"latestVersion": "v1.31.4-k3s1",
+			"concurrency": 0,
+			"paused": true,
+			"cordon": true,
+			"drain": false,
+			"nodeSelector": "!node-role.kubernetes.io/control-plane",
+			"nodes": 4,
+			"complete": 1,
+			"applying": ["worker-1"],
+			"pending": 1,
+			"disabled": 1,
+			"conditions": [{"type": "LatestResolved", "status": "True", "reason": "Version"}]
+		},
+		{
+			"namespace": "cattle-system",
+			"name": "server-plan",
+			"image": "rancher/k3s-upgrade",
+			"version": "v1.31.4+k3s1",
+			"latestVersion": "v1.31.4-k3s1",
+			"concurrency": 1,
+			"paused": false,
+			"cordon": true,
+			"drain": false,
+			"nodeSelector": "node-role.kubernetes.io/control-plane=true",
+			"nodes": 1,
+			"complete": 1,
+			"applying": [],
+			"pending": 0,
+			"disabled": 0,
+			"conditions": [{"type": "LatestResolved", "status": "True", "reason": "Version"}]
+		}
+	]`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestGetUpgradePlanProgress(t *testing.T) {
+	tests := map[string]struct {
+		params         upgradePlanParams
+		expectedResult string
+		expectedError  string
+	}{
+		"nodes with their latest job": {
+			params: upgradePlanParams{Cluster: "local", Name: "agent-plan"},
+			expectedResult: `{
+				"namespace": "cattle-system",
+				"name": "agent-plan",
+				"image": "rancher/k3s-upgrade",
+				"version": "v1.31.4+k3s1",
+				"latestVersion": "v1.31.4-k3s1",
+				"concurrency": 0,
+				"paused": true,
+				"cordon": true,
+				"drain": false,
+				"nodeSelector": "!node-role.kubernetes.io/control-plane",
+				"nodes": 4,
+				"complete": 1,
+				"applying": ["worker-1"],
+				"pending": 1,
+				"disabled": 1,
+				"conditions": [{"type": "LatestResolved", "status": "True", "reason": "Version"}],
+				"nodeUpgrades": [
+					{
+						"name": "worker-1", "state": "applying", "cordoned": false, "kubeletVersion": "v1.30.8+k3s1",
+						"osImage": "SUSE Linux Enterprise Micro 6.0", "kernelVersion": "6.4.0-17-default",
+						"job": {"name": "apply-agent-plan-on-worker-1", "active": 1, "succeeded": 0, "failed": 0}
+					},
+					{
+						"name": "worker-2", "state": "complete", "cordoned": false, "kubeletVersion": "v1.31.4+k3s1",
+						"osImage": "SUSE Linux Enterprise Micro 6.0", "kernelVersion": "6.4.0-17-default",
+						"job": {"name": "apply-agent-plan-on-worker-2", "active": 0, "succeeded": 1, "failed": 0}
+					},
+					{
+						"name": "worker-3", "state": "pending", "cordoned": false, "kubeletVersion": "v1.30.8+k3s1",
+						"osImage": "SUSE Linux Enterprise Micro 6.0", "kernelVersion": "6.4.0-17-default"
+					},
+					{
+						"name": "worker-4", "state": "disabled", "cordoned": false, "kubeletVersion": "v1.30.8+k3s1",
+						"osImage": "SUSE Linux Enterprise Micro 6.0", "kernelVersion": "6.4.0-17-default"
+					}
+				]
+			}`,
+		},
+		"plan not found": {
+			params:        upgradePlanParams{Cluster: "local", Name: "os-patch"},
+			expectedError: `failed to get plan os-patch: plans.upgrade.cattle.io "os-patch" not found`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := newUpgradePlanTools(upgradePlanObjects(t)...)
+
+			result, _, err := tools.getUpgradePlanProgress(middleware.WithToken(t.Context(), testToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {testURL}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package rbac
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The risks of the ServiceAccount findings, from highest to lowest.
+const (
+	riskCritical = "critical"
+	riskHigh     = "high"
+	riskMedium   = "medium"
+)
+
+// risks are the risks of the findings, from highest to lowest.
+var risks = []string{riskCritical, riskHigh, riskMedium}
+
+// serviceAccountsGroupPrefix is the prefix of the groups of the ServiceAccounts, system:serviceaccounts for all of
+// them and system:serviceaccounts:<namespace> for the ones of a namespace.
+const serviceAccountsGroupPrefix = "system:serviceaccounts"
+
+// systemNamespacePrefixes are the prefixes of the namespaces of Kubernetes and Rancher, whose ServiceAccounts usually
+// need broad permissions.
+var systemNamespacePrefixes = []string{"kube-", "cattle-", "fleet-"}
+
+type auditServiceAccountsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster to audit"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only audit the ServiceAccounts of this namespace"`
+}
+
+// exposureFinding is a reason why a ServiceAccount is exposed.
+type exposureFinding struct {
+	Risk   string `json:"risk"`
+	Reason string `json:"reason"`
+}
+
+// serviceAccountExposure is a ServiceAccount with broad permissions or long-lived tokens.
+type serviceAccountExposure struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Risk is the highest risk of the findings.
+	Risk string `json:"risk"`
+	// System is set for the ServiceAccounts of the Kubernetes and Rancher namespaces, which usually need their
+	// permissions.
+	System   bool              `json:"system,omitempty"`
+	Findings []exposureFinding `json:"findings"`
+	// TokenSecrets are the Secrets holding a long-lived token of the ServiceAccount.
+	TokenSecrets []string `json:"tokenSecrets,omitempty"`
+	// Workloads are the running workloads mounting a token of the ServiceAccount, as <kind>/<name>.
+	Workloads []string `json:"workloads,omitempty"`
+}
+
+// serviceAccountAudit is the response of auditServiceAccounts.
+type serviceAccountAudit struct {
+	ServiceAccounts []serviceAccountExposure `json:"serviceAccounts"`
+	// Summary contains the number of ServiceAccounts of each risk.
+	Summary map[string]int `json:"summary"`
+}
+
+// auditServiceAccounts reports the ServiceAccounts of a cluster bound to cluster-admin or to roles with wildcard
+// permissions, their long-lived token Secrets and the workloads mounting their tokens, the riskiest first.
+func (t *Tools) auditServiceAccounts(ctx context.Context, toolReq *mcp.CallToolRequest, params auditServiceAccountsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("auditServiceAccounts called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	list := func(kind string, namespace string) ([]*unstructured.Unstructured, error) {
+		objs, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:   params.Cluster,
+			Kind:      kind,
+			Namespace: namespace,
+			URL:       url,
+			Token:     token,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+		}
+		return objs, nil
+	}
+
+	serviceAccounts, err := list("serviceaccount", params.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list service accounts", zap.String("tool", "auditServiceAccounts"), zap.Error(err))
+		return nil, nil, err
+	}
+	exposures := map[string]*serviceAccountExposure{}
+	automount := map[string]bool{}
+	for _, sa := range serviceAccounts {
+		key := sa.GetNamespace() + "/" + sa.GetName()
+		exposures[key] = &serviceAccountExposure{
+			Namespace: sa.GetNamespace(),
+			Name:      sa.GetName(),
+			System:    systemNamespace(sa.GetNamespace()),
+			Findings:  []exposureFinding{},
+		}
+		mount, found, _ := unstructured.NestedBool(sa.Object, "automountServiceAccountToken")
+		automount[key] = !found || mount
+	}
+
+	if err := addBindingFindings(params.Namespace, list, exposures); err != nil {
+		middleware.Logger(ctx).Error("failed to evaluate role bindings", zap.String("tool", "auditServiceAccounts"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	secrets, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       params.Cluster,
+		Kind:          "secret",
+		Namespace:     params.Namespace,
+		URL:           url,
+		Token:         token,
+		FieldSelector: "type=" + string(corev1.SecretTypeServiceAccountToken),
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list secrets", zap.String("tool", "auditServiceAccounts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	// the ServiceAccounts of the token Secrets, by <namespace>/<name> of the Secret, to find the ones mounted as volumes
+	tokenSecrets := map[string]string{}
+	for _, secret := range secrets {
+		secretType, _, _ := unstructured.NestedString(secret.Object, "type")
+		if secretType != string(corev1.SecretTypeServiceAccountToken) {
+			continue
+		}
+		key := secret.GetNamespace() + "/" + secret.GetAnnotations()[corev1.ServiceAccountNameKey]
+		exposure, ok := exposures[key]
+		if !ok {
+			continue
+		}
+		tokenSecrets[secret.GetNamespace()+"/"+secret.GetName()] = key
+		exposure.TokenSecrets = append(exposure.TokenSecrets, secret.GetName())
+		exposure.Findings = append(exposure.Findings, exposureFinding{
+			Risk:   riskMedium,
+			Reason: fmt.Sprintf("the Secret %s holds a long-lived token of the ServiceAccount, which doesn't expire until the Secret is deleted", secret.GetName()),
+		})
+	}
+
+	pods, err := list("pod", params.Namespace)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list pods", zap.String("tool", "auditServiceAccounts"), zap.Error(err))
+		return nil, nil, err
+	}
+	for _, obj := range pods {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert pod %s: %w", obj.GetName(), err)
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		kind, name := utils.PodWorkload(pod)
+		workload := kind + "/" + name
+		for _, key := range mountedServiceAccounts(pod, automount, tokenSecrets) {
+			if exposure, ok := exposures[key]; ok && !slices.Contains(exposure.Workloads, workload) {
+				exposure.Workloads = append(exposure.Workloads, workload)
+			}
+		}
+	}
+
+	result := serviceAccountAudit{ServiceAccounts: []serviceAccountExposure{}, Summary: map[string]int{}}
+	for _, risk := range risks {
+		result.Summary[risk] = 0
+	}
+	for _, exposure := range exposures {
+		if len(exposure.Findings) == 0 {
+			continue
+		}
+		slices.SortStableFunc(exposure.Findings, func(a, b exposureFinding) int {
+			return cmp.Compare(slices.Index(risks, a.Risk), slices.Index(risks, b.Risk))
+		})
+		exposure.Risk = exposure.Findings[0].Risk
+		slices.Sort(exposure.Workloads)
+		result.Summary[exposure.Risk]++
+		result.ServiceAccounts = append(result.ServiceAccounts, *exposure)
+	}
+	slices.SortFunc(result.ServiceAccounts, func(a, b serviceAccountExposure) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(risks, a.Risk), slices.Index(risks, b.Risk)),
+			// the tokens mounted by more workloads are more likely to leak
+			cmp.Compare(len(b.Workloads), len(a.Workloads)),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "auditServiceAccounts"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// addBindingFindings adds the findings of the ClusterRoleBindings and RoleBindings granting cluster-admin or wildcard
+// permissions to the ServiceAccounts, directly or through their groups.
+func addBindingFindings(namespace string, list func(kind string, namespace string) ([]*unstructured.Unstructured, error), exposures map[string]*serviceAccountExposure) error {
+	roles := map[string][]rbacv1.PolicyRule{}
+	for _, kind := range []string{"clusterrole", "role"} {
+		objs, err := list(kind, roleNamespace(kind, namespace))
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			rules, err := policyRules(obj.Object)
+			if err != nil {
+				return err
+			}
+			roles[obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName()] = rules
+		}
+	}
+
+	for _, kind := range []string{"clusterrolebinding", "rolebinding"} {
+		objs, err := list(kind, roleNamespace(kind, namespace))
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			var binding rbacv1.RoleBinding
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &binding); err != nil {
+				return fmt.Errorf("failed to convert %s %s: %w", kind, obj.GetName(), err)
+			}
+			roleNamespace := ""
+			if binding.RoleRef.Kind == "Role" {
+				roleNamespace = binding.Namespace
+			}
+			finding, ok := bindingFinding(obj.GetKind(), &binding, roles[binding.RoleRef.Kind+"/"+roleNamespace+"/"+binding.RoleRef.Name])
+			if !ok {
+				continue
+			}
+			for _, exposure := range exposures {
+				if boundToServiceAccount(&binding, exposure.Namespace, exposure.Name) {
+					exposure.Findings = append(exposure.Findings, finding)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// bindingFinding returns the finding of a binding granting cluster-admin or wildcard permissions with the rules of
+// its role. The permissions granted by a RoleBinding are limited to its namespace, so their risk is lower.
+func bindingFinding(kind string, binding *rbacv1.RoleBinding, rules []rbacv1.PolicyRule) (exposureFinding, bool) {
+	clusterWide := kind == "ClusterRoleBinding"
+	scope := fmt.Sprintf("by the ClusterRoleBinding %s", binding.Name)
+	if !clusterWide {
+		scope = fmt.Sprintf("in the namespace %s by the RoleBinding %s", binding.Namespace, binding.Name)
+	}
+
+	if binding.RoleRef.Kind == "ClusterRole" && binding.RoleRef.Name == "cluster-admin" {
+		finding := exposureFinding{Risk: riskHigh, Reason: "bound to the cluster-admin ClusterRole " + scope}
+		if clusterWide {
+			finding.Risk = riskCritical
+		}
+		return finding, true
+	}
+
+	var wildcards []string
+	allResources := false
+	for _, rule := range rules {
+		verbs, resources := slices.Contains(rule.Verbs, rbacv1.VerbAll), slices.Contains(rule.Resources, rbacv1.ResourceAll)
+		if !verbs && !resources {
+			continue
+		}
+		allResources = allResources || (verbs && resources)
+		wildcards = append(wildcards, fmt.Sprintf("apiGroups=%s resources=%s verbs=%s", formatList(rule.APIGroups), formatList(rule.Resources), formatList(rule.Verbs)))
+	}
+	if len(wildcards) == 0 {
+		return exposureFinding{}, false
+	}
+
+	risk := riskMedium
+	switch {
+	case clusterWide && allResources:
+		risk = riskCritical
+	case clusterWide || allResources:
+		risk = riskHigh
+	}
+
+	return exposureFinding{
+		Risk:   risk,
+		Reason: fmt.Sprintf("the %s %s bound %s grants wildcard permissions: %s", binding.RoleRef.Kind, binding.RoleRef.Name, scope, strings.Join(wildcards, "; ")),
+	}, true
+}
+
+// boundToServiceAccount reports whether a subject of the binding is the ServiceAccount or one of its groups.
+func boundToServiceAccount(binding *rbacv1.RoleBinding, namespace string, name string) bool {
+	return slices.ContainsFunc(binding.Subjects, func(subject rbacv1.Subject) bool {
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			return subject.Name == name && cmp.Or(subject.Namespace, binding.Namespace) == namespace
+		case rbacv1.GroupKind:
+			return subject.Name == serviceAccountsGroupPrefix || subject.Name == serviceAccountsGroupPrefix+":"+namespace
+		}
+		return false
+	})
+}
+
+// mountedServiceAccounts returns the ServiceAccounts whose tokens are mounted by the pod, as <namespace>/<name>: its
+// ServiceAccount unless the token isn't automounted, and the ones of the token Secrets of its volumes.
+func mountedServiceAccounts(pod corev1.Pod, automount map[string]bool, tokenSecrets map[string]string) []string {
+	var keys []string
+	key := pod.Namespace + "/" + cmp.Or(pod.Spec.ServiceAccountName, "default")
+	mount := automount[key]
+	if pod.Spec.AutomountServiceAccountToken != nil {
+		mount = *pod.Spec.AutomountServiceAccountToken
+	}
+	if mount {
+		keys = append(keys, key)
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret == nil {
+			continue
+		}
+		if key, ok := tokenSecrets[pod.Namespace+"/"+volume.Secret.SecretName]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// roleNamespace returns the namespace where the roles or bindings of the kind are listed, all of them for the
+// cluster-scoped kinds.
+func roleNamespace(kind string, namespace string) string {
+	if strings.HasPrefix(kind, "cluster") {
+		return ""
+	}
+
+	return namespace
+}
+
+func systemNamespace(namespace string) bool {
+	return slices.ContainsFunc(systemNamespacePrefixes, func(prefix string) bool {
+		return strings.HasPrefix(namespace, prefix)
+	})
+}
+
+func formatList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+
+	return "[" + strings.Join(quoted, ",") + "]"
+}
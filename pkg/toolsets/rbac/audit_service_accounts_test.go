@@ -0,0 +1,149 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func fakeK8sObject(apiVersion string, kind string, namespace string, name string, fields map[string]any) *unstructured.Unstructured {
+	obj := fakeObject(kind, namespace, name, fields)
+	obj.SetAPIVersion(apiVersion)
+
+	return obj
+}
+
+func fakeBinding(kind string, namespace string, name string, roleKind string, role string, subjects ...any) *unstructured.Unstructured {
+	return fakeK8sObject("rbac.authorization.k8s.io/v1", kind, namespace, name, map[string]any{
+		"roleRef":  map[string]any{"apiGroup": "rbac.authorization.k8s.io", "kind": roleKind, "name": role},
+		"subjects": subjects,
+	})
+}
+
+func serviceAccountSubject(namespace string, name string) map[string]any {
+	return map[string]any{"kind": "ServiceAccount", "namespace": namespace, "name": name}
+}
+
+func fakeServiceAccountObjects() []runtime.Object {
+	return []runtime.Object{
+		fakeK8sObject("v1", "ServiceAccount", "default", "deployer", nil),
+		fakeK8sObject("v1", "ServiceAccount", "kube-system", "helm", nil),
+		fakeK8sObject("v1", "ServiceAccount", "apps", "builder", nil),
+		fakeK8sObject("v1", "ServiceAccount", "apps", "viewer", map[string]any{"automountServiceAccountToken": false}),
+		fakeK8sObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "cluster-admin", map[string]any{
+			"rules": []any{rule([]any{"*"}, []any{"*"}, []any{"*"})},
+		}),
+		fakeK8sObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "helm", map[string]any{
+			"rules": []any{rule([]any{"*"}, []any{"*"}, []any{"*"})},
+		}),
+		fakeK8sObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "secrets-all", map[string]any{
+			"rules": []any{rule([]any{""}, []any{"secrets"}, []any{"*"})},
+		}),
+		fakeK8sObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "view", map[string]any{
+			"rules": []any{rule([]any{""}, []any{"pods"}, []any{"get", "list"})},
+		}),
+		fakeK8sObject("rbac.authorization.k8s.io/v1", "Role", "apps", "builder", map[string]any{
+			"rules": []any{rule([]any{""}, []any{"pods"}, []any{"*"})},
+		}),
+		fakeBinding("ClusterRoleBinding", "", "deployer-admin", "ClusterRole", "cluster-admin", serviceAccountSubject("default", "deployer")),
+		fakeBinding("ClusterRoleBinding", "", "helm", "ClusterRole", "helm", serviceAccountSubject("kube-system", "helm")),
+		fakeBinding("ClusterRoleBinding", "", "apps-secrets", "ClusterRole", "secrets-all", map[string]any{"kind": "Group", "name": "system:serviceaccounts:apps"}),
+		fakeBinding("RoleBinding", "apps", "builder", "Role", "builder", map[string]any{"kind": "ServiceAccount", "name": "builder"}),
+		fakeBinding("RoleBinding", "apps", "viewer", "ClusterRole", "view", serviceAccountSubject("apps", "viewer")),
+		fakeK8sObject("v1", "Secret", "default", "deployer-token", map[string]any{
+			"metadata": map[string]any{
+				"name":        "deployer-token",
+				"namespace":   "default",
+				"annotations": map[string]any{"kubernetes.io/service-account.name": "deployer"},
+			},
+			"type": "kubernetes.io/service-account-token",
+			"data": map[string]any{"token": "c2VjcmV0"},
+		}),
+		fakeK8sObject("v1", "Secret", "apps", "builder-config", map[string]any{"type": "Opaque"}),
+		fakeK8sObject("v1", "Pod", "default", "ci-5d4f8-abcde", map[string]any{
+			"metadata": map[string]any{
+				"name":            "ci-5d4f8-abcde",
+				"namespace":       "default",
+				"labels":          map[string]any{"pod-template-hash": "5d4f8"},
+				"ownerReferences": []any{map[string]any{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "ci-5d4f8", "uid": "1", "controller": true}},
+			},
+			"spec":   map[string]any{"serviceAccountName": "deployer"},
+			"status": map[string]any{"phase": "Running"},
+		}),
+		fakeK8sObject("v1", "Pod", "default", "legacy", map[string]any{
+			"spec": map[string]any{
+				"automountServiceAccountToken": false,
+				"volumes":                      []any{map[string]any{"name": "token", "secret": map[string]any{"secretName": "deployer-token"}}},
+			},
+			"status": map[string]any{"phase": "Running"},
+		}),
+		fakeK8sObject("v1", "Pod", "apps", "viewer", map[string]any{
+			"spec":   map[string]any{"serviceAccountName": "viewer"},
+			"status": map[string]any{"phase": "Running"},
+		}),
+	}
+}
+
+func TestAuditServiceAccounts(t *testing.T) {
+	tests := map[string]struct {
+		params         auditServiceAccountsParams
+		expectedResult string
+	}{
+		"all namespaces": {
+			params: auditServiceAccountsParams{Cluster: "local"},
+			expectedResult: `{
+				"serviceAccounts": [
+					{"namespace": "default", "name": "deployer", "risk": "critical", "findings": [
+						{"risk": "critical", "reason": "bound to the cluster-admin ClusterRole by the ClusterRoleBinding deployer-admin"},
+						{"risk": "medium", "reason": "the Secret deployer-token holds a long-lived token of the ServiceAccount, which doesn't expire until the Secret is deleted"}
+					], "tokenSecrets": ["deployer-token"], "workloads": ["Deployment/ci", "Pod/legacy"]},
+					{"namespace": "kube-system", "name": "helm", "risk": "critical", "system": true, "findings": [
+						{"risk": "critical", "reason": "the ClusterRole helm bound by the ClusterRoleBinding helm grants wildcard permissions: apiGroups=[\"*\"] resources=[\"*\"] verbs=[\"*\"]"}
+					]},
+					{"namespace": "apps", "name": "builder", "risk": "high", "findings": [
+						{"risk": "high", "reason": "the ClusterRole secrets-all bound by the ClusterRoleBinding apps-secrets grants wildcard permissions: apiGroups=[\"\"] resources=[\"secrets\"] verbs=[\"*\"]"},
+						{"risk": "medium", "reason": "the Role builder bound in the namespace apps by the RoleBinding builder grants wildcard permissions: apiGroups=[\"\"] resources=[\"pods\"] verbs=[\"*\"]"}
+					]},
+					{"namespace": "apps", "name": "viewer", "risk": "high", "findings": [
+						{"risk": "high", "reason": "the ClusterRole secrets-all bound by the ClusterRoleBinding apps-secrets grants wildcard permissions: apiGroups=[\"\"] resources=[\"secrets\"] verbs=[\"*\"]"}
+					]}
+				],
+				"summary": {"critical": 2, "high": 2, "medium": 0}
+			}`,
+		},
+		"namespace": {
+			params: auditServiceAccountsParams{Cluster: "local", Namespace: "apps"},
+			expectedResult: `{
+				"serviceAccounts": [
+					{"namespace": "apps", "name": "builder", "risk": "high", "findings": [
+						{"risk": "high", "reason": "the ClusterRole secrets-all bound by the ClusterRoleBinding apps-secrets grants wildcard permissions: apiGroups=[\"\"] resources=[\"secrets\"] verbs=[\"*\"]"},
+						{"risk": "medium", "reason": "the Role builder bound in the namespace apps by the RoleBinding builder grants wildcard permissions: apiGroups=[\"\"] resources=[\"pods\"] verbs=[\"*\"]"}
+					]},
+					{"namespace": "apps", "name": "viewer", "risk": "high", "findings": [
+						{"risk": "high", "reason": "the ClusterRole secrets-all bound by the ClusterRoleBinding apps-secrets grants wildcard permissions: apiGroups=[\"\"] resources=[\"secrets\"] verbs=[\"*\"]"}
+					]}
+				],
+				"summary": {"critical": 0, "high": 2, "medium": 0}
+			}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeClient(fakeServiceAccountObjects()...)}
+
+			result, _, err := tools.auditServiceAccounts(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			assert.NotContains(t, result.Content[0].(*mcp.TextContent).Text, "c2VjcmV0")
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// projectIDLabel is the label Rancher sets on the namespaces of a Project.
+const projectIDLabel = "field.cattle.io/projectId"
+
+// accessCheck is a set of verbs to check on a resource.
+type accessCheck struct {
+	APIGroup string   `json:"apiGroup,omitempty" jsonschema:"the API group of the resource, empty for the core group"`
+	Resource string   `json:"resource" jsonschema:"the plural name of the resource (e.g. pods, deployments)"`
+	Name     string   `json:"name,omitempty" jsonschema:"the name of a specific resource"`
+	Verbs    []string `json:"verbs" jsonschema:"the verbs to check (e.g. get, list, create, update, delete)"`
+}
+
+type checkUserAccessParams struct {
+	Cluster   string        `json:"cluster" jsonschema:"the cluster where the access is checked"`
+	Namespace string        `json:"namespace,omitempty" jsonschema:"the namespace where the access is checked. Empty for cluster-wide access"`
+	Project   string        `json:"project,omitempty" jsonschema:"the ID or display name of a project. The access is checked in every namespace of the project"`
+	User      string        `json:"user,omitempty" jsonschema:"the ID, username or display name of the user. Empty to check the access of the current user"`
+	Checks    []accessCheck `json:"checks" jsonschema:"the resources and verbs to check"`
+}
+
+// accessResult is the outcome of a single SubjectAccessReview.
+type accessResult struct {
+	Namespace string `json:"namespace,omitempty"`
+	APIGroup  string `json:"apiGroup"`
+	Resource  string `json:"resource"`
+	Name      string `json:"name,omitempty"`
+	Verb      string `json:"verb"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type userAccess struct {
+	Cluster string `json:"cluster"`
+	// User is empty when the access of the current user is checked.
+	User    string         `json:"user,omitempty"`
+	Allowed bool           `json:"allowed"`
+	Missing []accessResult `json:"missing"`
+	Granted []accessResult `json:"granted"`
+}
+
+// checkUserAccess runs a SubjectAccessReview in the target cluster for every verb, resource and namespace requested,
+// and reports which of them are granted and which are missing.
+func (t *Tools) checkUserAccess(ctx context.Context, toolReq *mcp.CallToolRequest, params checkUserAccessParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("checkUserAccess called")
+
+	if len(params.Checks) == 0 {
+		return nil, nil, fmt.Errorf("at least one check is required")
+	}
+	if params.Namespace != "" && params.Project != "" {
+		return nil, nil, fmt.Errorf("namespace and project can't be used together")
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "checkUserAccess"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := userAccess{Cluster: clusterID, Allowed: true, Missing: []accessResult{}, Granted: []accessResult{}}
+	if params.User != "" {
+		user, err := t.findUser(ctx, url, token, params.User)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to find user", zap.String("tool", "checkUserAccess"), zap.Error(err))
+			return nil, nil, err
+		}
+		// Rancher impersonates users in downstream clusters by their ID
+		result.User = user.GetName()
+	}
+
+	namespaces := []string{params.Namespace}
+	if params.Project != "" {
+		namespaces, err = t.projectNamespaces(ctx, url, token, clusterID, params.Project)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get project namespaces", zap.String("tool", "checkUserAccess"), zap.Error(err))
+			return nil, nil, err
+		}
+	}
+
+	clientset, err := t.client.CreateClientSet(ctx, token, url, clusterID)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create clientset", zap.String("tool", "checkUserAccess"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	for _, namespace := range namespaces {
+		for _, check := range params.Checks {
+			for _, verb := range check.Verbs {
+				attributes := &authorizationv1.ResourceAttributes{
+					Namespace: namespace,
+					Verb:      verb,
+					Group:     check.APIGroup,
+					Resource:  check.Resource,
+					Name:      check.Name,
+				}
+				allowed, reason, err := reviewAccess(ctx, clientset, result.User, attributes)
+				if err != nil {
+					middleware.Logger(ctx).Error("failed to review access", zap.String("tool", "checkUserAccess"), zap.Error(err))
+					return nil, nil, err
+				}
+
+				access := accessResult{Namespace: namespace, APIGroup: check.APIGroup, Resource: check.Resource, Name: check.Name, Verb: verb, Reason: reason}
+				if allowed {
+					result.Granted = append(result.Granted, access)
+				} else {
+					result.Missing = append(result.Missing, access)
+					result.Allowed = false
+				}
+			}
+		}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "checkUserAccess"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// reviewAccess checks the access of the user with a SubjectAccessReview, or the access of the current user
+// with a SelfSubjectAccessReview when no user is given.
+func reviewAccess(ctx context.Context, clientset kubernetes.Interface, user string, attributes *authorizationv1.ResourceAttributes) (bool, string, error) {
+	if user == "" {
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, "", fmt.Errorf("failed to create SelfSubjectAccessReview: %w", err)
+		}
+		return review.Status.Allowed, review.Status.Reason, nil
+	}
+
+	review, err := clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{User: user, ResourceAttributes: attributes},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to create SubjectAccessReview: %w", err)
+	}
+
+	return review.Status.Allowed, review.Status.Reason, nil
+}
+
+// projectNamespaces returns the namespaces of the Project with the given ID or display name.
+func (t *Tools) projectNamespaces(ctx context.Context, url string, token string, clusterID string, project string) ([]string, error) {
+	projects, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   "local",
+		Kind:      "project",
+		Namespace: clusterID,
+		URL:       url,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	projectID := ""
+	for _, p := range projects {
+		displayName, _, _ := unstructured.NestedString(p.Object, "spec", "displayName")
+		if p.GetName() == project || displayName == project {
+			projectID = p.GetName()
+			break
+		}
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("project %s not found in cluster %s", project, clusterID)
+	}
+
+	namespaces, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:       clusterID,
+		Kind:          "namespace",
+		URL:           url,
+		Token:         token,
+		LabelSelector: projectIDLabel + "=" + projectID,
+		MetadataOnly:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespaces: %w", err)
+	}
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("project %s has no namespaces", project)
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.GetName())
+	}
+
+	return names, nil
+}
@@ -0,0 +1,148 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func fakeNamespace(name string, projectID string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]any{"name": name},
+	}}
+	if projectID != "" {
+		ns.SetLabels(map[string]string{projectIDLabel: projectID})
+	}
+
+	return ns
+}
+
+// allowedAttributes returns true for the reviews the fake authorizer allows: u-alice can only read pods in the
+// "team-a" namespace, and the current user can read pods everywhere.
+func allowedAttributes(user string, attributes *authorizationv1.ResourceAttributes) bool {
+	readPods := attributes.Resource == "pods" && (attributes.Verb == "get" || attributes.Verb == "list")
+	if user == "" {
+		return readPods
+	}
+
+	return user == "u-alice" && readPods && attributes.Namespace == "team-a"
+}
+
+func fakeAuthorizerClientset() kubernetes.Interface {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = allowedAttributes(review.Spec.User, review.Spec.ResourceAttributes)
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowedAttributes("", review.Spec.ResourceAttributes)
+		return true, review, nil
+	})
+
+	return clientset
+}
+
+func TestCheckUserAccess(t *testing.T) {
+	readPods := accessCheck{Resource: "pods", Verbs: []string{"get", "list"}}
+	updateDeployments := accessCheck{APIGroup: "apps", Resource: "deployments", Verbs: []string{"update"}}
+
+	tests := map[string]struct {
+		params         checkUserAccessParams
+		expectedResult string
+		expectedError  string
+	}{
+		"user allowed in namespace": {
+			params: checkUserAccessParams{Cluster: "c-abc12", Namespace: "team-a", User: "alice", Checks: []accessCheck{readPods}},
+			expectedResult: `{
+				"cluster": "c-abc12",
+				"user": "u-alice",
+				"allowed": true,
+				"missing": [],
+				"granted": [
+					{"namespace": "team-a", "apiGroup": "", "resource": "pods", "verb": "get"},
+					{"namespace": "team-a", "apiGroup": "", "resource": "pods", "verb": "list"}
+				]
+			}`,
+		},
+		"user missing verbs in project namespaces": {
+			params: checkUserAccessParams{Cluster: "c-abc12", Project: "Team", User: "u-alice", Checks: []accessCheck{{Resource: "pods", Verbs: []string{"get"}}, updateDeployments}},
+			expectedResult: `{
+				"cluster": "c-abc12",
+				"user": "u-alice",
+				"allowed": false,
+				"missing": [
+					{"namespace": "team-a", "apiGroup": "apps", "resource": "deployments", "verb": "update"},
+					{"namespace": "team-b", "apiGroup": "", "resource": "pods", "verb": "get"},
+					{"namespace": "team-b", "apiGroup": "apps", "resource": "deployments", "verb": "update"}
+				],
+				"granted": [
+					{"namespace": "team-a", "apiGroup": "", "resource": "pods", "verb": "get"}
+				]
+			}`,
+		},
+		"current user cluster-wide": {
+			params: checkUserAccessParams{Cluster: "c-abc12", Checks: []accessCheck{{Resource: "pods", Verbs: []string{"list", "delete"}}}},
+			expectedResult: `{
+				"cluster": "c-abc12",
+				"allowed": false,
+				"missing": [{"apiGroup": "", "resource": "pods", "verb": "delete"}],
+				"granted": [{"apiGroup": "", "resource": "pods", "verb": "list"}]
+			}`,
+		},
+		"project not found": {
+			params:        checkUserAccessParams{Cluster: "c-abc12", Project: "missing", Checks: []accessCheck{readPods}},
+			expectedError: "project missing not found in cluster c-abc12",
+		},
+		"namespace and project": {
+			params:        checkUserAccessParams{Cluster: "c-abc12", Namespace: "team-a", Project: "Team", Checks: []accessCheck{readPods}},
+			expectedError: "namespace and project can't be used together",
+		},
+		"no checks": {
+			params:        checkUserAccessParams{Cluster: "c-abc12"},
+			expectedError: "at least one check is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := append(fakeRBACObjects(),
+				fakeObject("Cluster", "", "c-abc12", nil),
+				fakeObject("Project", "c-abc12", "p-team1", map[string]any{"spec": map[string]any{"displayName": "Team"}}),
+				fakeNamespace("team-a", "p-team1"),
+				fakeNamespace("team-b", "p-team1"),
+				fakeNamespace("other", ""),
+			)
+			c := newFakeClient(objects...)
+			c.ClientSetCreator = func(inConfig *rest.Config) (kubernetes.Interface, error) {
+				assert.Equal(t, fakeUrl+"/k8s/clusters/c-abc12", inConfig.Host)
+				return fakeAuthorizerClientset(), nil
+			}
+			tools := Tools{client: c}
+
+			result, _, err := tools.checkUserAccess(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	localProvider = "local"
+
+	// accessModeUnrestricted allows all the users of the provider, accessModeRestricted the allowed principals and
+	// the members of the clusters and projects, and accessModeRequired only the allowed principals.
+	accessModeUnrestricted = "unrestricted"
+	accessModeRestricted   = "restricted"
+	accessModeRequired     = "required"
+)
+
+// authConfigFields are the fields common to all the AuthConfigs, the other fields are the settings of the provider.
+var authConfigFields = []string{"apiVersion", "kind", "metadata", "type", "enabled", "accessMode", "allowedPrincipalIds", "status"}
+
+type getAuthProvidersParams struct {
+	Provider string `json:"provider,omitempty" jsonschema:"the name of the provider, e.g. activedirectory, openldap, github or keycloakoidc. Empty for all providers"`
+	User     string `json:"user,omitempty" jsonschema:"the ID, username or display name of a user whose login is checked against the enabled providers"`
+}
+
+// authProviders are the authentication providers of Rancher, and the login check of a user.
+type authProviders struct {
+	Providers []authProvider `json:"providers"`
+	User      *userLogin     `json:"user,omitempty"`
+}
+
+// authProvider is the AuthConfig of an authentication provider, with its credentials redacted.
+type authProvider struct {
+	Name                string          `json:"name"`
+	Type                string          `json:"type"`
+	Enabled             bool            `json:"enabled"`
+	AccessMode          string          `json:"accessMode,omitempty"`
+	AllowedPrincipalIDs []string        `json:"allowedPrincipalIds,omitempty"`
+	Conditions          []authCondition `json:"conditions,omitempty"`
+	// Settings are the settings of the provider, e.g. the servers and search bases of LDAP or the issuer of OIDC.
+	Settings map[string]any `json:"settings,omitempty"`
+}
+
+type authCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// userLogin tells whether the principals of a user are allowed to log in by the access mode of each enabled provider.
+type userLogin struct {
+	ID              string          `json:"id"`
+	Username        string          `json:"username,omitempty"`
+	Enabled         bool            `json:"enabled"`
+	PrincipalIDs    []string        `json:"principalIds"`
+	GroupPrincipals []string        `json:"groupPrincipals,omitempty"`
+	LastLogin       string          `json:"lastLogin,omitempty"`
+	LastRefresh     string          `json:"lastRefresh,omitempty"`
+	Providers       []providerLogin `json:"providers"`
+	// localPassword is true if the user has a password to log in with the local provider.
+	localPassword bool
+}
+
+type providerLogin struct {
+	Provider string `json:"provider"`
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason"`
+}
+
+// getAuthProviders returns the AuthConfigs of the authentication providers of Rancher with their access mode and
+// allowed principals, and checks whether a user is allowed to log in by each enabled provider.
+func (t *Tools) getAuthProviders(ctx context.Context, toolReq *mcp.CallToolRequest, params getAuthProvidersParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getAuthProviders called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	authConfigs, err := t.listLocal(ctx, url, token, "authconfig")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list auth configs", zap.String("tool", "getAuthProviders"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := authProviders{Providers: []authProvider{}}
+	for _, authConfig := range authConfigs {
+		if params.Provider != "" && authConfig.GetName() != params.Provider {
+			continue
+		}
+		result.Providers = append(result.Providers, newAuthProvider(authConfig))
+	}
+	if params.Provider != "" && len(result.Providers) == 0 {
+		return nil, nil, fmt.Errorf("auth provider %s not found", params.Provider)
+	}
+	// the enabled providers are listed first, only one external provider can be enabled at a time
+	slices.SortFunc(result.Providers, func(a, b authProvider) int {
+		if a.Enabled != b.Enabled {
+			if a.Enabled {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if params.User != "" {
+		result.User, err = t.checkUserLogin(ctx, url, token, params.User, result.Providers)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to check user login", zap.String("tool", "getAuthProviders"), zap.Error(err))
+			return nil, nil, err
+		}
+	}
+
+	resp, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getAuthProviders"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(resp)}},
+	}, nil, nil
+}
+
+// newAuthProvider returns the common fields of an AuthConfig, and its other fields as the settings of the provider
+// with the credentials redacted.
+func newAuthProvider(authConfig *unstructured.Unstructured) authProvider {
+	// the credentials are redacted before the settings are copied
+	response.Redact(authConfig)
+
+	provider := authProvider{Name: authConfig.GetName(), Settings: map[string]any{}}
+	provider.Type, _, _ = unstructured.NestedString(authConfig.Object, "type")
+	provider.Enabled, _, _ = unstructured.NestedBool(authConfig.Object, "enabled")
+	provider.AccessMode, _, _ = unstructured.NestedString(authConfig.Object, "accessMode")
+	provider.AllowedPrincipalIDs, _, _ = unstructured.NestedStringSlice(authConfig.Object, "allowedPrincipalIds")
+	conditions, _, _ := unstructured.NestedSlice(authConfig.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		provider.Conditions = append(provider.Conditions, authCondition{Type: conditionType, Status: status, Message: message})
+	}
+	for key, value := range authConfig.Object {
+		if !slices.Contains(authConfigFields, key) {
+			provider.Settings[key] = value
+		}
+	}
+
+	return provider
+}
+
+// checkUserLogin checks the principals of a user, and the group principals stored in its UserAttribute at its last
+// login, against the access mode and the allowed principals of the enabled providers.
+func (t *Tools) checkUserLogin(ctx context.Context, url string, token string, userName string, providers []authProvider) (*userLogin, error) {
+	user, err := t.findUser(ctx, url, token, userName)
+	if err != nil {
+		return nil, fmt.Errorf("%w. Rancher creates the users of the external providers at their first login", err)
+	}
+
+	login := &userLogin{ID: user.GetName(), Enabled: true, Providers: []providerLogin{}}
+	login.Username, _, _ = unstructured.NestedString(user.Object, "username")
+	if enabled, found, _ := unstructured.NestedBool(user.Object, "enabled"); found {
+		login.Enabled = enabled
+	}
+	login.PrincipalIDs, _, _ = unstructured.NestedStringSlice(user.Object, "principalIds")
+	password, _, _ := unstructured.NestedString(user.Object, "password")
+	login.localPassword = password != ""
+
+	attribute, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster: "local",
+		Kind:    "userattribute",
+		Name:    user.GetName(),
+		URL:     url,
+		Token:   token,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the user attributes of %s: %w", user.GetName(), err)
+	}
+	if attribute != nil {
+		login.LastLogin, _, _ = unstructured.NestedString(attribute.Object, "lastLogin")
+		login.LastRefresh, _, _ = unstructured.NestedString(attribute.Object, "lastRefresh")
+		groupPrincipals, _, _ := unstructured.NestedMap(attribute.Object, "groupPrincipals")
+		for _, principals := range groupPrincipals {
+			p, ok := principals.(map[string]any)
+			if !ok {
+				continue
+			}
+			items, _, _ := unstructured.NestedSlice(p, "items")
+			for _, item := range items {
+				i, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				if name, _, _ := unstructured.NestedString(i, "metadata", "name"); name != "" {
+					login.GroupPrincipals = append(login.GroupPrincipals, name)
+				}
+			}
+		}
+		slices.Sort(login.GroupPrincipals)
+	}
+
+	var member bool
+	for _, provider := range providers {
+		if !provider.Enabled {
+			continue
+		}
+		if provider.AccessMode == accessModeRestricted && !member {
+			bindings, err := t.getRoleBindings(ctx, url, token, user)
+			if err != nil {
+				return nil, err
+			}
+			member = len(bindings.clusterRoleTemplateBindings) > 0 || len(bindings.projectRoleTemplateBindings) > 0
+		}
+		login.Providers = append(login.Providers, providerLoginCheck(provider, login, member))
+	}
+
+	return login, nil
+}
+
+// providerLoginCheck returns whether the principals of the user are allowed to log in by the provider. member is
+// true when the user has cluster or project role bindings, which are allowed by the restricted access mode.
+func providerLoginCheck(provider authProvider, login *userLogin, member bool) providerLogin {
+	check := providerLogin{Provider: provider.Name}
+	if !login.Enabled {
+		check.Reason = "the user is deactivated, no provider allows its login"
+		return check
+	}
+	if provider.Name == localProvider {
+		// all the users have a local principal, only the local users have a password
+		check.Allowed = login.localPassword
+		if check.Allowed {
+			check.Reason = "local users log in with their password, the access mode of the external providers doesn't apply"
+		} else {
+			check.Reason = "the user doesn't have a local password, it logs in with an external provider"
+		}
+		return check
+	}
+
+	var principals []string
+	for _, principal := range slices.Concat(login.PrincipalIDs, login.GroupPrincipals) {
+		if strings.HasPrefix(principal, provider.Name+"_") {
+			principals = append(principals, principal)
+		}
+	}
+	if len(principals) == 0 {
+		check.Reason = fmt.Sprintf("the user doesn't have a principal of %s, it has never logged in with it", provider.Name)
+		return check
+	}
+	var allowed []string
+	for _, principal := range principals {
+		if slices.Contains(provider.AllowedPrincipalIDs, principal) {
+			allowed = append(allowed, principal)
+		}
+	}
+
+	switch {
+	case len(allowed) > 0:
+		check.Allowed = true
+		check.Reason = fmt.Sprintf("the principals %s are allowed", strings.Join(allowed, ", "))
+	case provider.AccessMode == accessModeUnrestricted:
+		check.Allowed = true
+		check.Reason = "all the users of the provider are allowed"
+	case provider.AccessMode == accessModeRestricted && member:
+		check.Allowed = true
+		check.Reason = "the user is a member of a cluster or a project"
+	case provider.AccessMode == accessModeRestricted:
+		check.Reason = "neither the user nor its groups are allowed principals, and the user isn't a member of any cluster or project"
+	default:
+		check.Reason = "neither the user nor its groups are allowed principals, the required access mode only allows them"
+	}
+
+	return check
+}
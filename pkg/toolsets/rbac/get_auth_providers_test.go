@@ -0,0 +1,144 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func fakeExternalUser(name string, principalID string, enabled bool) *unstructured.Unstructured {
+	return fakeObject("User", "", name, map[string]any{
+		"principalIds": []any{principalID, "local://" + name},
+		"enabled":      enabled,
+	})
+}
+
+func fakeAuthObjects() []runtime.Object {
+	return append(fakeRBACObjects(),
+		fakeObject("AuthConfig", "", "local", map[string]any{"type": "localConfig", "enabled": true}),
+		fakeObject("AuthConfig", "", "activedirectory", map[string]any{
+			"type":                   "activeDirectoryConfig",
+			"enabled":                true,
+			"accessMode":             "restricted",
+			"allowedPrincipalIds":    []any{"activedirectory_group://CN=admins,DC=example,DC=com"},
+			"servers":                []any{"ad.example.com"},
+			"serviceAccountUsername": "rancher",
+			"serviceAccountPassword": "cattle-global-data:activedirectoryconfig-serviceaccountpassword",
+			"status": map[string]any{"conditions": []any{
+				map[string]any{"type": "SecretsMigrated", "status": "True", "lastUpdateTime": "2025-01-01T00:00:00Z"},
+			}},
+		}),
+		fakeObject("AuthConfig", "", "github", map[string]any{
+			"type":         "githubConfig",
+			"enabled":      false,
+			"accessMode":   "unrestricted",
+			"clientId":     "0123",
+			"clientSecret": "abcdef",
+		}),
+		fakeExternalUser("u-carol", "activedirectory_user://CN=carol,DC=example,DC=com", true),
+		fakeExternalUser("u-dave", "activedirectory_user://CN=dave,DC=example,DC=com", true),
+		fakeExternalUser("u-erin", "activedirectory_user://CN=erin,DC=example,DC=com", false),
+		fakeObject("UserAttribute", "", "u-carol", map[string]any{
+			"lastLogin": "2025-01-02T00:00:00Z",
+			"groupPrincipals": map[string]any{"activedirectory": map[string]any{"items": []any{
+				map[string]any{"metadata": map[string]any{"name": "activedirectory_group://CN=admins,DC=example,DC=com"}},
+			}}},
+		}),
+	)
+}
+
+func TestGetAuthProviders(t *testing.T) {
+	activeDirectory := `{"name": "activedirectory", "type": "activeDirectoryConfig", "enabled": true, "accessMode": "restricted",
+		"allowedPrincipalIds": ["activedirectory_group://CN=admins,DC=example,DC=com"],
+		"conditions": [{"type": "SecretsMigrated", "status": "True"}],
+		"settings": {"servers": ["ad.example.com"], "serviceAccountUsername": "rancher", "serviceAccountPassword": "<redacted, 63 bytes>"}}`
+	enabledProviders := activeDirectory + `, {"name": "local", "type": "localConfig", "enabled": true}`
+
+	tests := map[string]struct {
+		params         getAuthProvidersParams
+		expectedResult string
+		expectedError  string
+	}{
+		"all providers": {
+			params: getAuthProvidersParams{},
+			expectedResult: `{"providers": [` + enabledProviders + `,
+				{"name": "github", "type": "githubConfig", "enabled": false, "accessMode": "unrestricted", "settings": {"clientId": "0123", "clientSecret": "<redacted, 6 bytes>"}}
+			]}`,
+		},
+		"provider": {
+			params:         getAuthProvidersParams{Provider: "activedirectory"},
+			expectedResult: `{"providers": [` + activeDirectory + `]}`,
+		},
+		"user allowed by a group": {
+			params: getAuthProvidersParams{User: "u-carol"},
+			expectedResult: `{"providers": [` + enabledProviders + `, {"name": "github", "type": "githubConfig", "enabled": false, "accessMode": "unrestricted", "settings": {"clientId": "0123", "clientSecret": "<redacted, 6 bytes>"}}],
+				"user": {"id": "u-carol", "enabled": true,
+					"principalIds": ["activedirectory_user://CN=carol,DC=example,DC=com", "local://u-carol"],
+					"groupPrincipals": ["activedirectory_group://CN=admins,DC=example,DC=com"],
+					"lastLogin": "2025-01-02T00:00:00Z",
+					"providers": [
+						{"provider": "activedirectory", "allowed": true, "reason": "the principals activedirectory_group://CN=admins,DC=example,DC=com are allowed"},
+						{"provider": "local", "allowed": false, "reason": "the user doesn't have a local password, it logs in with an external provider"}
+					]
+				}}`,
+		},
+		"user not allowed by the restricted access mode": {
+			params: getAuthProvidersParams{Provider: "activedirectory", User: "u-dave"},
+			expectedResult: `{"providers": [` + activeDirectory + `],
+				"user": {"id": "u-dave", "enabled": true,
+					"principalIds": ["activedirectory_user://CN=dave,DC=example,DC=com", "local://u-dave"],
+					"providers": [
+						{"provider": "activedirectory", "allowed": false, "reason": "neither the user nor its groups are allowed principals, and the user isn't a member of any cluster or project"}
+					]
+				}}`,
+		},
+		"deactivated user": {
+			params: getAuthProvidersParams{Provider: "activedirectory", User: "u-erin"},
+			expectedResult: `{"providers": [` + activeDirectory + `],
+				"user": {"id": "u-erin", "enabled": false,
+					"principalIds": ["activedirectory_user://CN=erin,DC=example,DC=com", "local://u-erin"],
+					"providers": [{"provider": "activedirectory", "allowed": false, "reason": "the user is deactivated, no provider allows its login"}]
+				}}`,
+		},
+		"local user": {
+			params: getAuthProvidersParams{User: "alice"},
+			expectedResult: `{"providers": [` + enabledProviders + `, {"name": "github", "type": "githubConfig", "enabled": false, "accessMode": "unrestricted", "settings": {"clientId": "0123", "clientSecret": "<redacted, 6 bytes>"}}],
+				"user": {"id": "u-alice", "username": "alice", "enabled": true, "principalIds": ["local://u-alice"],
+					"providers": [
+						{"provider": "activedirectory", "allowed": false, "reason": "the user doesn't have a principal of activedirectory, it has never logged in with it"},
+						{"provider": "local", "allowed": true, "reason": "local users log in with their password, the access mode of the external providers doesn't apply"}
+					]
+				}}`,
+		},
+		"provider not found": {
+			params:        getAuthProvidersParams{Provider: "okta"},
+			expectedError: "auth provider okta not found",
+		},
+		"user not found": {
+			params:        getAuthProvidersParams{User: "frank"},
+			expectedError: "user frank not found. Rancher creates the users of the external providers at their first login",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeClient(fakeAuthObjects()...)}
+
+			result, _, err := tools.getAuthProviders(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
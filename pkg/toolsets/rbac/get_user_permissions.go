@@ -0,0 +1,268 @@
+package rbac
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type getUserPermissionsParams struct {
+	User    string `json:"user" jsonschema:"the ID, username or display name of the user"`
+	Cluster string `json:"cluster" jsonschema:"the cluster where the permissions are evaluated"`
+}
+
+// permission is the set of verbs allowed on a resource.
+type permission struct {
+	APIGroup      string   `json:"apiGroup"`
+	Resource      string   `json:"resource"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+	Verbs         []string `json:"verbs"`
+}
+
+// scopePermissions are the permissions granted in a cluster or project by a set of RoleTemplates.
+type scopePermissions struct {
+	RoleTemplates []string     `json:"roleTemplates"`
+	Permissions   []permission `json:"permissions"`
+}
+
+type projectPermissions struct {
+	Project string `json:"project"`
+	scopePermissions
+}
+
+// userPermissions is the effective permission summary of a user in a cluster.
+type userPermissions struct {
+	User               string               `json:"user"`
+	Cluster            string               `json:"cluster"`
+	GlobalRoles        []string             `json:"globalRoles"`
+	GlobalPermissions  []permission         `json:"globalPermissions"`
+	ClusterPermissions scopePermissions     `json:"clusterPermissions"`
+	ProjectPermissions []projectPermissions `json:"projectPermissions"`
+	// ExternalRoleTemplates get their rules from a ClusterRole in the downstream cluster, so they aren't included in the permissions.
+	ExternalRoleTemplates []string `json:"externalRoleTemplates,omitempty"`
+}
+
+// roleTemplateResolver resolves RoleTemplates, including the RoleTemplates they inherit from, into their rules.
+type roleTemplateResolver struct {
+	roleTemplates map[string]*unstructured.Unstructured
+	external      []string
+}
+
+// getUserPermissions aggregates the GlobalRoles and RoleTemplates bound to a user into the permissions the user has in a cluster.
+func (t *Tools) getUserPermissions(ctx context.Context, toolReq *mcp.CallToolRequest, params getUserPermissionsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getUserPermissions called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	clusterID, err := t.client.GetClusterID(ctx, token, url, params.Cluster)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get cluster ID", zap.String("tool", "getUserPermissions"), zap.Error(err))
+		return nil, nil, err
+	}
+	user, err := t.findUser(ctx, url, token, params.User)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to find user", zap.String("tool", "getUserPermissions"), zap.Error(err))
+		return nil, nil, err
+	}
+	bindings, err := t.getRoleBindings(ctx, url, token, user)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get role bindings", zap.String("tool", "getUserPermissions"), zap.Error(err))
+		return nil, nil, err
+	}
+	roleTemplates, err := t.listLocal(ctx, url, token, "roletemplate")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list role templates", zap.String("tool", "getUserPermissions"), zap.Error(err))
+		return nil, nil, err
+	}
+	resolver := &roleTemplateResolver{roleTemplates: map[string]*unstructured.Unstructured{}}
+	for _, rt := range roleTemplates {
+		resolver.roleTemplates[rt.GetName()] = rt
+	}
+
+	result := userPermissions{
+		User:               user.GetName(),
+		Cluster:            clusterID,
+		GlobalRoles:        []string{},
+		ProjectPermissions: []projectPermissions{},
+	}
+
+	var globalRules []rbacv1.PolicyRule
+	var clusterRoleTemplates []string
+	for _, grb := range bindings.globalRoleBindings {
+		globalRoleName, _, _ := unstructured.NestedString(grb.Object, "globalRoleName")
+		globalRole, err := t.client.GetResource(ctx, client.GetParams{
+			Cluster: "local",
+			Kind:    "globalrole",
+			Name:    globalRoleName,
+			URL:     url,
+			Token:   token,
+		})
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to get global role", zap.String("tool", "getUserPermissions"), zap.Error(err))
+			return nil, nil, err
+		}
+		rules, err := policyRules(globalRole.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.GlobalRoles = append(result.GlobalRoles, globalRoleName)
+		globalRules = append(globalRules, rules...)
+		// inherited cluster roles are granted in every downstream cluster
+		if clusterID != "local" {
+			inherited, _, _ := unstructured.NestedStringSlice(globalRole.Object, "inheritedClusterRoles")
+			clusterRoleTemplates = append(clusterRoleTemplates, inherited...)
+		}
+	}
+	result.GlobalPermissions = aggregatePermissions(globalRules)
+
+	for _, crtb := range bindings.clusterRoleTemplateBindings {
+		clusterName, _, _ := unstructured.NestedString(crtb.Object, "clusterName")
+		if cmp.Or(clusterName, crtb.GetNamespace()) != clusterID {
+			continue
+		}
+		roleTemplateName, _, _ := unstructured.NestedString(crtb.Object, "roleTemplateName")
+		clusterRoleTemplates = append(clusterRoleTemplates, roleTemplateName)
+	}
+	result.ClusterPermissions, err = resolver.resolve(clusterRoleTemplates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projectRoleTemplates := map[string][]string{}
+	for _, prtb := range bindings.projectRoleTemplateBindings {
+		projectName, _, _ := unstructured.NestedString(prtb.Object, "projectName")
+		projectCluster, projectID, found := strings.Cut(projectName, ":")
+		if !found || projectCluster != clusterID {
+			continue
+		}
+		roleTemplateName, _, _ := unstructured.NestedString(prtb.Object, "roleTemplateName")
+		projectRoleTemplates[projectID] = append(projectRoleTemplates[projectID], roleTemplateName)
+	}
+	for _, projectID := range slices.Sorted(maps.Keys(projectRoleTemplates)) {
+		permissions, err := resolver.resolve(projectRoleTemplates[projectID])
+		if err != nil {
+			return nil, nil, err
+		}
+		result.ProjectPermissions = append(result.ProjectPermissions, projectPermissions{Project: projectID, scopePermissions: permissions})
+	}
+	slices.Sort(resolver.external)
+	result.ExternalRoleTemplates = slices.Compact(resolver.external)
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "getUserPermissions"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// resolve returns the permissions granted by the RoleTemplates and all the RoleTemplates they inherit from.
+// RoleTemplates that no longer exist are ignored.
+func (r *roleTemplateResolver) resolve(names []string) (scopePermissions, error) {
+	visited := map[string]bool{}
+	resolved := []string{}
+	var rules []rbacv1.PolicyRule
+	pending := slices.Clone(names)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+
+		rt, ok := r.roleTemplates[name]
+		if !ok {
+			continue
+		}
+		resolved = append(resolved, name)
+		if external, _, _ := unstructured.NestedBool(rt.Object, "external"); external {
+			r.external = append(r.external, name)
+		}
+		rtRules, err := policyRules(rt.Object)
+		if err != nil {
+			return scopePermissions{}, err
+		}
+		rules = append(rules, rtRules...)
+		inherited, _, _ := unstructured.NestedStringSlice(rt.Object, "roleTemplateNames")
+		pending = append(pending, inherited...)
+	}
+
+	slices.Sort(resolved)
+
+	return scopePermissions{
+		RoleTemplates: resolved,
+		Permissions:   aggregatePermissions(rules),
+	}, nil
+}
+
+// policyRules returns the rules of a GlobalRole or RoleTemplate.
+func policyRules(obj map[string]any) ([]rbacv1.PolicyRule, error) {
+	rawRules, _, _ := unstructured.NestedSlice(obj, "rules")
+	rules := make([]rbacv1.PolicyRule, 0, len(rawRules))
+	for _, rawRule := range rawRules {
+		ruleObj, ok := rawRule.(map[string]any)
+		if !ok {
+			continue
+		}
+		var rule rbacv1.PolicyRule
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(ruleObj, &rule); err != nil {
+			return nil, fmt.Errorf("failed to convert policy rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// aggregatePermissions merges the verbs of all rules that apply to the same resource. Rules for non-resource URLs are ignored.
+func aggregatePermissions(rules []rbacv1.PolicyRule) []permission {
+	permissions := map[string]*permission{}
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				key := group + "/" + resource + "/" + strings.Join(rule.ResourceNames, ",")
+				p, ok := permissions[key]
+				if !ok {
+					p = &permission{APIGroup: group, Resource: resource, ResourceNames: rule.ResourceNames, Verbs: []string{}}
+					permissions[key] = p
+				}
+				for _, verb := range rule.Verbs {
+					if !slices.Contains(p.Verbs, verb) {
+						p.Verbs = append(p.Verbs, verb)
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]permission, 0, len(permissions))
+	for _, p := range permissions {
+		slices.Sort(p.Verbs)
+		result = append(result, *p)
+	}
+	slices.SortFunc(result, func(a, b permission) int {
+		return cmp.Or(
+			cmp.Compare(a.APIGroup, b.APIGroup),
+			cmp.Compare(a.Resource, b.Resource),
+			slices.Compare(a.ResourceNames, b.ResourceNames),
+		)
+	})
+
+	return result
+}
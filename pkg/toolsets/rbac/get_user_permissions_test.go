@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserPermissions(t *testing.T) {
+	tests := map[string]struct {
+		params         getUserPermissionsParams
+		expectedResult string
+		expectedError  string
+	}{
+		"cluster owner": {
+			params: getUserPermissionsParams{User: "alice", Cluster: "c-abc12"},
+			expectedResult: `{
+				"user": "u-alice",
+				"cluster": "c-abc12",
+				"globalRoles": ["user"],
+				"globalPermissions": [{"apiGroup": "management.cattle.io", "resource": "nodedrivers", "verbs": ["get", "list"]}],
+				"clusterPermissions": {
+					"roleTemplates": ["cluster-member", "cluster-owner"],
+					"permissions": [
+						{"apiGroup": "", "resource": "nodes", "verbs": ["get", "list"]},
+						{"apiGroup": "*", "resource": "*", "verbs": ["*"]}
+					]
+				},
+				"projectPermissions": []
+			}`,
+		},
+		"project member with inherited role templates": {
+			params: getUserPermissionsParams{User: "u-alice", Cluster: "c-def34"},
+			expectedResult: `{
+				"user": "u-alice",
+				"cluster": "c-def34",
+				"globalRoles": ["user"],
+				"globalPermissions": [{"apiGroup": "management.cattle.io", "resource": "nodedrivers", "verbs": ["get", "list"]}],
+				"clusterPermissions": {
+					"roleTemplates": ["cluster-member"],
+					"permissions": [{"apiGroup": "", "resource": "nodes", "verbs": ["get", "list"]}]
+				},
+				"projectPermissions": [{
+					"project": "p-xyz98",
+					"roleTemplates": ["project-member", "view"],
+					"permissions": [
+						{"apiGroup": "", "resource": "pods", "verbs": ["get", "list"]},
+						{"apiGroup": "apps", "resource": "deployments", "verbs": ["get", "list", "update", "watch"]}
+					]
+				}],
+				"externalRoleTemplates": ["view"]
+			}`,
+		},
+		"user without bindings in the cluster": {
+			params: getUserPermissionsParams{User: "bob", Cluster: "local"},
+			expectedResult: `{
+				"user": "u-bob",
+				"cluster": "local",
+				"globalRoles": [],
+				"globalPermissions": [],
+				"clusterPermissions": {"roleTemplates": [], "permissions": []},
+				"projectPermissions": []
+			}`,
+		},
+		"user not found": {
+			params:        getUserPermissionsParams{User: "carol", Cluster: "local"},
+			expectedError: "user carol not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := append(fakeRBACObjects(),
+				fakeObject("Cluster", "", "c-abc12", nil),
+				fakeObject("Cluster", "", "c-def34", nil),
+			)
+			tools := Tools{client: newFakeClient(objects...)}
+
+			result, _, err := tools.getUserPermissions(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
+
+func TestAggregatePermissions(t *testing.T) {
+	rules, err := policyRules(map[string]any{"rules": []any{
+		rule([]any{"", "apps"}, []any{"pods", "deployments"}, []any{"get"}),
+		rule([]any{"apps"}, []any{"deployments"}, []any{"list", "get"}),
+		map[string]any{"nonResourceURLs": []any{"/healthz"}, "verbs": []any{"get"}},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []permission{
+		{APIGroup: "", Resource: "deployments", Verbs: []string{"get"}},
+		{APIGroup: "", Resource: "pods", Verbs: []string{"get"}},
+		{APIGroup: "apps", Resource: "deployments", Verbs: []string{"get", "list"}},
+		{APIGroup: "apps", Resource: "pods", Verbs: []string{"get"}},
+	}, aggregatePermissions(rules))
+}
@@ -0,0 +1,49 @@
+package rbac
+
+import (
+	"context"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+)
+
+// userParams specifies the parameters needed to identify a Rancher user.
+type userParams struct {
+	User string `json:"user" jsonschema:"the ID, username or display name of the user"`
+}
+
+// getUserRoleBindings retrieves the GlobalRoleBindings, ClusterRoleTemplateBindings and ProjectRoleTemplateBindings of a user.
+func (t *Tools) getUserRoleBindings(ctx context.Context, toolReq *mcp.CallToolRequest, params userParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("getUserRoleBindings called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	user, err := t.findUser(ctx, url, token, params.User)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to find user", zap.String("tool", "getUserRoleBindings"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	bindings, err := t.getRoleBindings(ctx, url, token, user)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get role bindings", zap.String("tool", "getUserRoleBindings"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	mcpResponse, err := response.CreateMcpResponse(ctx, slices.Concat(
+		bindings.globalRoleBindings,
+		bindings.clusterRoleTemplateBindings,
+		bindings.projectRoleTemplateBindings,
+	), "local")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "getUserRoleBindings"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
@@ -0,0 +1,59 @@
+package rbac
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserRoleBindings(t *testing.T) {
+	tests := map[string]struct {
+		params           userParams
+		expectedBindings []string
+		expectedError    string
+	}{
+		"user with global, cluster and project bindings": {
+			params:           userParams{User: "alice"},
+			expectedBindings: []string{"grb-alice", "crtb-alice", "prtb-alice"},
+		},
+		"user bound by principal": {
+			params:           userParams{User: "u-bob"},
+			expectedBindings: []string{"crtb-bob"},
+		},
+		"user not found": {
+			params:        userParams{User: "carol"},
+			expectedError: "user carol not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeClient(fakeRBACObjects()...)}
+
+			result, _, err := tools.getUserRoleBindings(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+				Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+			}, test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var mcpResponse struct {
+				UIContext []struct {
+					Name string `json:"name"`
+				} `json:"uiContext"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &mcpResponse))
+			var names []string
+			for _, ctx := range mcpResponse.UIContext {
+				names = append(names, ctx.Name)
+			}
+			assert.Equal(t, test.expectedBindings, names)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package rbac
+
+import (
+	"context"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type listUsersParams struct{}
+
+// listUsers retrieves all Rancher users and the group principals referenced by role bindings.
+// Rancher doesn't store groups as resources, they are only known through the bindings that use them.
+func (t *Tools) listUsers(ctx context.Context, toolReq *mcp.CallToolRequest, _ listUsersParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listUsers called")
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	users, err := t.listLocal(ctx, url, token, "user")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list users", zap.String("tool", "listUsers"), zap.Error(err))
+		return nil, nil, err
+	}
+	for _, user := range users {
+		// the password hash must never reach the LLM
+		unstructured.RemoveNestedField(user.Object, "password")
+	}
+
+	groups := []string{}
+	for _, kind := range []string{"globalrolebinding", "clusterroletemplatebinding", "projectroletemplatebinding"} {
+		bindings, err := t.listLocal(ctx, url, token, kind)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list role bindings", zap.String("tool", "listUsers"), zap.String("kind", kind), zap.Error(err))
+			return nil, nil, err
+		}
+		for _, binding := range bindings {
+			group, _, _ := unstructured.NestedString(binding.Object, "groupPrincipalName")
+			if group != "" && !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+	}
+	slices.Sort(groups)
+
+	summary := &unstructured.Unstructured{Object: map[string]any{"groups": groups}}
+	mcpResponse, err := response.CreateMcpResponse(ctx, append(users, summary), "local")
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create mcp response", zap.String("tool", "listUsers"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: mcpResponse}},
+	}, nil, nil
+}
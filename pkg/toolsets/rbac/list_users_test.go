@@ -0,0 +1,31 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListUsers(t *testing.T) {
+	tools := Tools{client: newFakeClient(fakeRBACObjects()...)}
+
+	result, _, err := tools.listUsers(middleware.WithToken(t.Context(), fakeToken), &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {fakeUrl}}},
+	}, listUsersParams{})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"llm": [
+			{"apiVersion": "management.cattle.io/v3", "kind": "User", "metadata": {"name": "u-alice"}, "username": "alice", "displayName": "Alice", "principalIds": ["local://u-alice"]},
+			{"apiVersion": "management.cattle.io/v3", "kind": "User", "metadata": {"name": "u-bob"}, "username": "bob", "displayName": "Bob", "principalIds": ["local://u-bob"]},
+			{"groups": ["github_team://devs", "github_team://ops"]}
+		],
+		"uiContext": [
+			{"cluster": "local", "kind": "User", "name": "u-alice", "namespace": "", "type": "user"},
+			{"cluster": "local", "kind": "User", "name": "u-bob", "namespace": "", "type": "user"}
+		]
+	}`, result.Content[0].(*mcp.TextContent).Text)
+}
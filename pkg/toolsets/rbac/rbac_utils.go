@@ -0,0 +1,86 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// roleBindings holds the Rancher role bindings of a user.
+type roleBindings struct {
+	globalRoleBindings          []*unstructured.Unstructured
+	clusterRoleTemplateBindings []*unstructured.Unstructured
+	projectRoleTemplateBindings []*unstructured.Unstructured
+}
+
+// listLocal lists resources of the given kind in all namespaces of the local cluster, where Rancher stores users and role bindings.
+func (t *Tools) listLocal(ctx context.Context, url string, token string, kind string) ([]*unstructured.Unstructured, error) {
+	return t.client.GetResources(ctx, client.ListParams{
+		Cluster: "local",
+		Kind:    kind,
+		URL:     url,
+		Token:   token,
+	})
+}
+
+// findUser returns the user with the given ID, username or display name.
+func (t *Tools) findUser(ctx context.Context, url string, token string, user string) (*unstructured.Unstructured, error) {
+	users, err := t.listLocal(ctx, url, token, "user")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	var matches []*unstructured.Unstructured
+	for _, u := range users {
+		if u.GetName() == user {
+			return u, nil
+		}
+		username, _, _ := unstructured.NestedString(u.Object, "username")
+		displayName, _, _ := unstructured.NestedString(u.Object, "displayName")
+		if username == user || displayName == user {
+			matches = append(matches, u)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("user %s not found", user)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("more than one user named %s found, use the user ID instead", user)
+	}
+}
+
+// getRoleBindings returns the GlobalRoleBindings, ClusterRoleTemplateBindings and ProjectRoleTemplateBindings of the user.
+// Bindings are matched by user ID or by any of the principals of the user.
+func (t *Tools) getRoleBindings(ctx context.Context, url string, token string, user *unstructured.Unstructured) (*roleBindings, error) {
+	principalIDs, _, _ := unstructured.NestedStringSlice(user.Object, "principalIds")
+	boundToUser := func(binding *unstructured.Unstructured) bool {
+		userName, _, _ := unstructured.NestedString(binding.Object, "userName")
+		userPrincipalName, _, _ := unstructured.NestedString(binding.Object, "userPrincipalName")
+		return userName == user.GetName() || (userPrincipalName != "" && slices.Contains(principalIDs, userPrincipalName))
+	}
+
+	bindings := &roleBindings{}
+	for kind, result := range map[string]*[]*unstructured.Unstructured{
+		"globalrolebinding":          &bindings.globalRoleBindings,
+		"clusterroletemplatebinding": &bindings.clusterRoleTemplateBindings,
+		"projectroletemplatebinding": &bindings.projectRoleTemplateBindings,
+	} {
+		objs, err := t.listLocal(ctx, url, token, kind)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+		}
+		for _, obj := range objs {
+			if boundToUser(obj) {
+				*result = append(*result, obj)
+			}
+		}
+	}
+
+	return bindings, nil
+}
@@ -0,0 +1,134 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	fakeUrl   = "https://localhost:8080"
+	fakeToken = "fakeToken"
+)
+
+func fakeUser(name string, username string, displayName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion":   "management.cattle.io/v3",
+		"kind":         "User",
+		"metadata":     map[string]any{"name": name},
+		"username":     username,
+		"displayName":  displayName,
+		"password":     "$2a$10$hash",
+		"principalIds": []any{"local://" + name},
+	}}
+}
+
+func fakeObject(kind string, namespace string, name string, fields map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	for k, v := range fields {
+		obj.Object[k] = v
+	}
+
+	return obj
+}
+
+func rule(apiGroups []any, resources []any, verbs []any) map[string]any {
+	return map[string]any{"apiGroups": apiGroups, "resources": resources, "verbs": verbs}
+}
+
+// fakeRBACObjects returns two users, their bindings and the roles they reference.
+func fakeRBACObjects() []runtime.Object {
+	return []runtime.Object{
+		fakeUser("u-alice", "alice", "Alice"),
+		fakeUser("u-bob", "bob", "Bob"),
+		fakeObject("GlobalRole", "", "user", map[string]any{
+			"rules":                 []any{rule([]any{"management.cattle.io"}, []any{"nodedrivers"}, []any{"get", "list"})},
+			"inheritedClusterRoles": []any{"cluster-member"},
+		}),
+		fakeObject("GlobalRoleBinding", "", "grb-alice", map[string]any{"userName": "u-alice", "globalRoleName": "user"}),
+		fakeObject("GlobalRoleBinding", "", "grb-devs", map[string]any{"groupPrincipalName": "github_team://devs", "globalRoleName": "user"}),
+		fakeObject("RoleTemplate", "", "cluster-member", map[string]any{
+			"rules": []any{rule([]any{""}, []any{"nodes"}, []any{"get", "list"})},
+		}),
+		fakeObject("RoleTemplate", "", "cluster-owner", map[string]any{
+			"rules": []any{rule([]any{"*"}, []any{"*"}, []any{"*"})},
+		}),
+		fakeObject("RoleTemplate", "", "project-member", map[string]any{
+			"rules":             []any{rule([]any{"apps"}, []any{"deployments"}, []any{"get", "list", "update"})},
+			"roleTemplateNames": []any{"view"},
+		}),
+		fakeObject("RoleTemplate", "", "view", map[string]any{
+			"external": true,
+			"rules":    []any{rule([]any{""}, []any{"pods"}, []any{"get", "list"}), rule([]any{"apps"}, []any{"deployments"}, []any{"watch"})},
+		}),
+		fakeObject("ClusterRoleTemplateBinding", "c-abc12", "crtb-alice", map[string]any{"clusterName": "c-abc12", "userName": "u-alice", "roleTemplateName": "cluster-owner"}),
+		fakeObject("ClusterRoleTemplateBinding", "c-def34", "crtb-bob", map[string]any{"clusterName": "c-def34", "userPrincipalName": "local://u-bob", "roleTemplateName": "cluster-owner"}),
+		fakeObject("ProjectRoleTemplateBinding", "p-xyz98", "prtb-alice", map[string]any{"projectName": "c-def34:p-xyz98", "userName": "u-alice", "roleTemplateName": "project-member"}),
+		fakeObject("ProjectRoleTemplateBinding", "p-xyz98", "prtb-ops", map[string]any{"projectName": "c-def34:p-xyz98", "groupPrincipalName": "github_team://ops", "roleTemplateName": "project-member"}),
+	}
+}
+
+func newFakeClient(objects ...runtime.Object) *client.Client {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, resource := range []string{"users", "globalroles", "globalrolebindings", "roletemplates", "clusterroletemplatebindings", "projectroletemplatebindings", "clusters", "projects", "authconfigs", "userattributes"} {
+		listKinds[schema.GroupVersionResource{Group: "management.cattle.io", Version: "v3", Resource: resource}] = "List"
+	}
+	listKinds[schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}] = "NamespaceList"
+	for resource, listKind := range map[string]string{"serviceaccounts": "ServiceAccountList", "secrets": "SecretList", "pods": "PodList"} {
+		listKinds[schema.GroupVersionResource{Version: "v1", Resource: resource}] = listKind
+	}
+	for _, resource := range []string{"clusterroles", "roles", "clusterrolebindings", "rolebindings"} {
+		listKinds[schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: resource}] = "List"
+	}
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	return &client.Client{
+		DynClientCreator: func(inConfig *rest.Config) (dynamic.Interface, error) {
+			return fakeDynClient, nil
+		},
+	}
+}
+
+func TestFindUser(t *testing.T) {
+	tests := map[string]struct {
+		user          string
+		expectedUser  string
+		expectedError string
+	}{
+		"find by ID":           {user: "u-alice", expectedUser: "u-alice"},
+		"find by username":     {user: "bob", expectedUser: "u-bob"},
+		"find by display name": {user: "Alice", expectedUser: "u-alice"},
+		"user not found":       {user: "carol", expectedError: "user carol not found"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := Tools{client: newFakeClient(fakeRBACObjects()...)}
+
+			user, err := tools.findUser(context.TODO(), fakeUrl, fakeToken, test.user)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedUser, user.GetName())
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package rbac
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+)
+
+const (
+	toolsSet    = "rbac"
+	toolsSetAnn = "toolset"
+	urlHeader   = client.URLHeader
+)
+
+// Tools contains all tools for the MCP server
+type Tools struct {
+	client *client.Client
+}
+
+// NewTools creates and returns a new Tools instance.
+func NewTools(client *client.Client) *Tools {
+	return &Tools{
+		client: client,
+	}
+}
+
+// Register adds the tools of the rbac toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	NewTools(deps.Client).AddTools(mcpServer)
+}
+
+// AddTools registers all Rancher user and RBAC tools with the provided MCP server.
+// Each tool is configured with metadata identifying it as part of the rbac toolset.
+func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listUsers",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the Rancher users and the group principals that have role bindings.
+		Parameters: none.`},
+		response.WithStructuredErrors(t.listUsers),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getUserRoleBindings",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the GlobalRoleBindings, ClusterRoleTemplateBindings and ProjectRoleTemplateBindings of a Rancher user.
+		Only bindings of the user itself are returned, bindings inherited through group membership are not included.
+		Parameters:
+		user (string): The ID (e.g. 'u-abc12'), username or display name of the user.`},
+		response.WithStructuredErrors(t.getUserRoleBindings),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getUserPermissions",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Answers "what can this user do in this cluster?". Aggregates the GlobalRoles, cluster RoleTemplates and project RoleTemplates bound to the user,
+		including inherited RoleTemplates, into an effective permission summary of verbs per resource.
+		Only bindings of the user itself are considered, bindings inherited through group membership are not included.
+		Parameters:
+		user (string): The ID (e.g. 'u-abc12'), username or display name of the user.
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.`},
+		response.WithStructuredErrors(t.getUserPermissions),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "checkUserAccess",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Checks whether a user is allowed to perform the given verbs on the given resources in a cluster, a namespace or all namespaces of a Rancher Project.
+		Each verb is checked with a SubjectAccessReview in the target cluster, and the verbs and resources that are missing are reported individually.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster managed by Rancher.
+		namespace (string, optional): The namespace where the access is checked. Empty for cluster-wide access.
+		project (string, optional): The ID or display name of a Project. The access is checked in every namespace of the Project. Can't be used with namespace.
+		user (string, optional): The ID, username or display name of the user. Empty to check the access of the current user.
+		checks (array): The resources and verbs to check, e.g. [{"apiGroup": "apps", "resource": "deployments", "verbs": ["get", "update"]}].
+
+		Returns:
+		Whether all checks are allowed, and the list of missing and granted verbs per resource and namespace.`},
+		response.WithStructuredErrors(t.checkUserAccess),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "auditServiceAccounts",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Audits the exposure of the ServiceAccounts of a cluster for security reviews: the ServiceAccounts bound to cluster-admin or to roles with wildcard verbs or resources, their long-lived token Secrets, and the running workloads mounting their tokens.
+		Bindings to the system:serviceaccounts groups are attributed to all the ServiceAccounts of the groups. The token values are never returned.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): Only audit the ServiceAccounts, Roles, RoleBindings, Secrets and Pods of this namespace. The ClusterRoleBindings are always evaluated.
+
+		Returns:
+		The exposed ServiceAccounts ranked by risk (critical, high or medium), then by the number of workloads mounting their tokens, with the reason of each finding, and the number of ServiceAccounts of each risk.
+		The ServiceAccounts of the Kubernetes and Rancher namespaces are marked as system, as they usually need their permissions.`},
+		response.WithStructuredErrors(t.auditServiceAccounts),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "getAuthProviders",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Returns the authentication providers configured in Rancher (local, Active Directory, OpenLDAP, FreeIPA, GitHub, Azure AD, OIDC, SAML...) from their AuthConfigs: whether they are enabled, their access mode, their allowed principals, their status conditions and their settings. The passwords, client secrets and private keys are redacted.
+		Use it to debug "why can't user X log in?": when a user is given, its principals and the groups of its last login are checked against the access mode and the allowed principals of each enabled provider.
+		Parameters:
+		provider (string, optional): The name of the provider (e.g. 'activedirectory', 'openldap', 'github', 'keycloakoidc'). Empty for all providers.
+		user (string, optional): The ID (e.g. 'u-abc12'), username or display name of the user whose login is checked. Users of external providers only exist after their first login.
+
+		Returns:
+		The providers, the enabled ones first, and for the user, whether each enabled provider allows its login and why.`},
+		response.WithStructuredErrors(t.getAuthProviders),
+	)
+}
@@ -0,0 +1,197 @@
+package security
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/utils"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type analyzePodSecurityParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the namespaces"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only analyze this namespace"`
+	Level     string `json:"level,omitempty" jsonschema:"the Pod Security Standards level the workloads are evaluated against (baseline or restricted). Defaults to restricted" validate:"enum=baseline|restricted"`
+}
+
+// workloadPodSecurity is a workload whose pods violate the Pod Security Standards level.
+type workloadPodSecurity struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Pods int    `json:"pods"`
+	// Violations are the failed checks of the pods of the workload, in the format of the Pod Security Admission
+	// warnings.
+	Violations []string `json:"violations"`
+}
+
+// namespacePodSecurity is the Pod Security Admission configuration of a namespace and the workloads violating the
+// level analyzed.
+type namespacePodSecurity struct {
+	Namespace string `json:"namespace"`
+	// Labels contains the levels and versions of the pod-security.kubernetes.io labels without their prefix, e.g.
+	// enforce=baseline and enforce-version=latest. The modes without a label use the defaults of the cluster.
+	Labels map[string]string `json:"labels"`
+	Pods   int               `json:"pods"`
+	// CompliantLevel is the most restrictive level all the running pods of the namespace comply with.
+	CompliantLevel string                `json:"compliantLevel"`
+	Workloads      []workloadPodSecurity `json:"workloads"`
+}
+
+// podSecurityAnalysis is the response of analyzePodSecurity.
+type podSecurityAnalysis struct {
+	Level      string                 `json:"level"`
+	Namespaces []namespacePodSecurity `json:"namespaces"`
+}
+
+// analyzePodSecurity reports the Pod Security Admission labels of the namespaces of a cluster, and the running
+// workloads that would violate the Pod Security Standards level if it were enforced in their namespace.
+func (t *Tools) analyzePodSecurity(ctx context.Context, toolReq *mcp.CallToolRequest, params analyzePodSecurityParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("analyzePodSecurity called")
+
+	level := strings.ToLower(cmp.Or(params.Level, levelRestricted))
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+
+	namespaces, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:      params.Cluster,
+		Kind:         "namespace",
+		URL:          url,
+		Token:        token,
+		MetadataOnly: true,
+	})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list namespaces", zap.String("tool", "analyzePodSecurity"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	workloads, err := t.podSecurityWorkloads(ctx, params.Cluster, url, token, params.Namespace, level)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to evaluate pods", zap.String("tool", "analyzePodSecurity"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := podSecurityAnalysis{Level: level, Namespaces: []namespacePodSecurity{}}
+	for _, namespace := range namespaces {
+		if params.Namespace != "" && namespace.GetName() != params.Namespace {
+			continue
+		}
+		analysis := workloads[namespace.GetName()]
+		if analysis == nil {
+			analysis = &namespacePodSecurity{CompliantLevel: levelRestricted, Workloads: []workloadPodSecurity{}}
+		}
+		analysis.Namespace = namespace.GetName()
+		analysis.Labels = podSecurityLabels(namespace.GetLabels())
+		result.Namespaces = append(result.Namespaces, *analysis)
+	}
+	if params.Namespace != "" && len(result.Namespaces) == 0 {
+		return nil, nil, fmt.Errorf("namespace %s not found in cluster %s", params.Namespace, params.Cluster)
+	}
+	slices.SortFunc(result.Namespaces, func(a, b namespacePodSecurity) int {
+		return cmp.Compare(a.Namespace, b.Namespace)
+	})
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "analyzePodSecurity"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// podSecurityWorkloads evaluates the running pods of the namespace, or of all the namespaces if empty, against the
+// Pod Security Standards level, and returns the workloads violating it, by namespace.
+func (t *Tools) podSecurityWorkloads(ctx context.Context, cluster string, url string, token string, namespace string, level string) (map[string]*namespacePodSecurity, error) {
+	pods, err := t.client.GetResources(ctx, client.ListParams{
+		Cluster:   cluster,
+		Kind:      "pod",
+		Namespace: namespace,
+		URL:       url,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	namespaces := map[string]*namespacePodSecurity{}
+	workloads := map[string]map[[2]string]*workloadPodSecurity{}
+	for _, obj := range pods {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, fmt.Errorf("failed to convert pod %s: %w", obj.GetName(), err)
+		}
+		// the pods that completed don't run again, the admission of their replacements is what matters
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		analysis, ok := namespaces[pod.Namespace]
+		if !ok {
+			analysis = &namespacePodSecurity{CompliantLevel: levelRestricted}
+			namespaces[pod.Namespace] = analysis
+			workloads[pod.Namespace] = map[[2]string]*workloadPodSecurity{}
+		}
+		analysis.Pods++
+		violations := checkPodSecurity(&pod, levelRestricted)
+		if podLevel := compliantLevel(violations); slices.Index(podSecurityLevels, podLevel) < slices.Index(podSecurityLevels, analysis.CompliantLevel) {
+			analysis.CompliantLevel = podLevel
+		}
+
+		var messages []string
+		for _, violation := range violations {
+			if slices.Index(podSecurityLevels, violation.Level) <= slices.Index(podSecurityLevels, level) {
+				messages = append(messages, violation.Message)
+			}
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		kind, name := utils.PodWorkload(pod)
+		workload, ok := workloads[pod.Namespace][[2]string{kind, name}]
+		if !ok {
+			workload = &workloadPodSecurity{Kind: kind, Name: name}
+			workloads[pod.Namespace][[2]string{kind, name}] = workload
+		}
+		workload.Pods++
+		for _, message := range messages {
+			// the pods of a workload share their spec, so they usually have the same violations
+			if !slices.Contains(workload.Violations, message) {
+				workload.Violations = append(workload.Violations, message)
+			}
+		}
+	}
+
+	for ns, analysis := range namespaces {
+		analysis.Workloads = []workloadPodSecurity{}
+		for _, workload := range workloads[ns] {
+			analysis.Workloads = append(analysis.Workloads, *workload)
+		}
+		slices.SortFunc(analysis.Workloads, func(a, b workloadPodSecurity) int {
+			return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+		})
+	}
+
+	return namespaces, nil
+}
+
+// podSecurityLabels returns the pod-security.kubernetes.io labels without their prefix.
+func podSecurityLabels(labels map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range labels {
+		if mode, ok := strings.CutPrefix(key, podSecurityLabelPrefix); ok {
+			result[mode] = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,204 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func fakeNamespace(name string, labels map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]any{"name": name, "labels": labels},
+	}}
+}
+
+func fakePod(t *testing.T, pod *corev1.Pod) *unstructured.Unstructured {
+	pod.APIVersion = "v1"
+	pod.Kind = "Pod"
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	require.NoError(t, err)
+
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func fakePodSecurityObjects(t *testing.T) []runtime.Object {
+	restricted := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		RunAsNonRoot:             ptr.To(true),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+
+	return []runtime.Object{
+		fakeNamespace("default", nil),
+		fakeNamespace("secure", map[string]any{"pod-security.kubernetes.io/enforce": "restricted", "pod-security.kubernetes.io/enforce-version": "latest"}),
+		fakeNamespace("empty", map[string]any{"pod-security.kubernetes.io/warn": "baseline", "kubernetes.io/metadata.name": "empty"}),
+		fakePod(t, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "nginx-5d4f8-abcde",
+				Namespace:       "default",
+				Labels:          map[string]string{"pod-template-hash": "5d4f8"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "nginx-5d4f8", Controller: ptr.To(true)}},
+			},
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}),
+		fakePod(t, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				HostNetwork: true,
+				Containers:  []corev1.Container{{Name: "debug", SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}),
+		fakePod(t, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "migration", SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)}}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}),
+		fakePod(t, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "secure"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", SecurityContext: restricted}},
+				Volumes:    []corev1.Volume{{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}),
+	}
+}
+
+func TestAnalyzePodSecurity(t *testing.T) {
+	tests := map[string]struct {
+		params         analyzePodSecurityParams
+		expectedResult string
+		expectedError  string
+	}{
+		"baseline": {
+			params: analyzePodSecurityParams{Cluster: "local", Level: "baseline"},
+			expectedResult: `{"level":"baseline","namespaces":[
+				{"namespace":"default","labels":{},"pods":2,"compliantLevel":"privileged","workloads":[
+					{"kind":"Pod","name":"debug","pods":1,"violations":[
+						"host namespaces (hostNetwork=true)",
+						"privileged (container \"debug\" must not set securityContext.privileged=true)"
+					]}
+				]},
+				{"namespace":"empty","labels":{"warn":"baseline"},"pods":0,"compliantLevel":"restricted","workloads":[]},
+				{"namespace":"secure","labels":{"enforce":"restricted","enforce-version":"latest"},"pods":1,"compliantLevel":"restricted","workloads":[]}
+			]}`,
+		},
+		"restricted in a namespace": {
+			params: analyzePodSecurityParams{Cluster: "local", Namespace: "default"},
+			expectedResult: `{"level":"restricted","namespaces":[
+				{"namespace":"default","labels":{},"pods":2,"compliantLevel":"privileged","workloads":[
+					{"kind":"Deployment","name":"nginx","pods":1,"violations":[
+						"allowPrivilegeEscalation != false (container \"nginx\" must set securityContext.allowPrivilegeEscalation=false)",
+						"unrestricted capabilities (container \"nginx\" must set securityContext.capabilities.drop=[\"ALL\"])",
+						"runAsNonRoot != true (container \"nginx\" must set securityContext.runAsNonRoot=true)",
+						"seccompProfile (container \"nginx\" must set securityContext.seccompProfile.type to \"RuntimeDefault\" or \"Localhost\")"
+					]},
+					{"kind":"Pod","name":"debug","pods":1,"violations":[
+						"host namespaces (hostNetwork=true)",
+						"privileged (container \"debug\" must not set securityContext.privileged=true)",
+						"allowPrivilegeEscalation != false (container \"debug\" must set securityContext.allowPrivilegeEscalation=false)",
+						"unrestricted capabilities (container \"debug\" must set securityContext.capabilities.drop=[\"ALL\"])",
+						"runAsNonRoot != true (container \"debug\" must set securityContext.runAsNonRoot=true)",
+						"seccompProfile (container \"debug\" must set securityContext.seccompProfile.type to \"RuntimeDefault\" or \"Localhost\")"
+					]}
+				]}
+			]}`,
+		},
+		"unknown namespace": {
+			params:        analyzePodSecurityParams{Cluster: "local", Namespace: "missing"},
+			expectedError: "namespace missing not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(fakePodSecurityObjects(t)...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.analyzePodSecurity(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest("https://localhost:8080"), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			}
+		})
+	}
+}
+
+func TestCheckPodSecurity(t *testing.T) {
+	tests := map[string]struct {
+		pod                corev1.Pod
+		level              string
+		expectedViolations []podSecurityViolation
+	}{
+		"baseline capabilities and volumes": {
+			pod: corev1.Pod{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:            "app",
+					Ports:           []corev1.ContainerPort{{ContainerPort: 80, HostPort: 8080}},
+					SecurityContext: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "CHOWN"}}},
+				}},
+				Volumes: []corev1.Volume{{Name: "logs", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log"}}}},
+			}},
+			level: levelBaseline,
+			expectedViolations: []podSecurityViolation{
+				{Level: levelBaseline, Message: `non-default capabilities (container "app" must not include "NET_ADMIN" in securityContext.capabilities.add)`},
+				{Level: levelBaseline, Message: `hostPath volumes (volume "logs")`},
+				{Level: levelBaseline, Message: `hostPort (container "app" uses hostPort 8080)`},
+			},
+		},
+		"restricted pod security context": {
+			pod: corev1.Pod{Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsNonRoot:   ptr.To(true),
+					RunAsUser:      ptr.To(int64(0)),
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					Sysctls:        []corev1.Sysctl{{Name: "net.ipv4.tcp_syncookies"}, {Name: "kernel.msgmax"}},
+				},
+				Containers: []corev1.Container{{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+						Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}, Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+					},
+				}},
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/data"}}}},
+			}},
+			level: levelRestricted,
+			expectedViolations: []podSecurityViolation{
+				{Level: levelBaseline, Message: "forbidden sysctls (kernel.msgmax)"},
+				{Level: levelRestricted, Message: `restricted volume types (volume "data" uses restricted volume type "nfs")`},
+				{Level: levelRestricted, Message: "runAsUser=0 (pod must not set runAsUser=0)"},
+			},
+		},
+		"windows pod": {
+			pod: corev1.Pod{Spec: corev1.PodSpec{
+				OS:              &corev1.PodOS{Name: corev1.Windows},
+				SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: ptr.To(true)},
+				Containers:      []corev1.Container{{Name: "app"}},
+			}},
+			level: levelRestricted,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expectedViolations, checkPodSecurity(&test.pod, test.level))
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// webhookDenialRegexp matches the errors of the admission webhooks rejecting a request.
+	webhookDenialRegexp = regexp.MustCompile(`(?s)admission webhook "([^"]+)" denied the request: (.*)`)
+	// gatekeeperViolationRegexp matches a violation of the Gatekeeper webhook, one per line: [<constraint>] <message>.
+	gatekeeperViolationRegexp = regexp.MustCompile(`^\[([^\]]+)\] (.*)$`)
+)
+
+type evaluateAdmissionPoliciesParams struct {
+	Cluster  string `json:"cluster" jsonschema:"the cluster where the resource would be created"`
+	Resource any    `json:"resource" jsonschema:"the manifest of the resource to evaluate"`
+}
+
+// policyRejection is an admission policy rejecting the resource.
+type policyRejection struct {
+	// Engine is kubewarden or gatekeeper, or empty for other admission webhooks.
+	Engine  string `json:"engine,omitempty"`
+	Webhook string `json:"webhook"`
+	Policy  string `json:"policy,omitempty"`
+	Message string `json:"message"`
+}
+
+// kubewardenPolicy is an installed Kubewarden policy.
+type kubewardenPolicy struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Module    string `json:"module,omitempty"`
+	// Mode is protect or monitor. Policies in monitor mode only log the requests they would reject.
+	Mode string `json:"mode,omitempty"`
+}
+
+// evaluateAdmissionPoliciesResult is the response of evaluateAdmissionPolicies.
+type evaluateAdmissionPoliciesResult struct {
+	Allowed bool `json:"allowed"`
+	// Operation is create, or update if the resource already exists.
+	Operation  string            `json:"operation"`
+	Rejections []policyRejection `json:"rejections,omitempty"`
+	// Error is set if the request was rejected by the API server instead of an admission webhook, e.g. if the
+	// resource is invalid.
+	Error              string             `json:"error,omitempty"`
+	KubewardenPolicies []kubewardenPolicy `json:"kubewardenPolicies,omitempty"`
+}
+
+// evaluateAdmissionPolicies sends the resource to the API server in dry-run mode, so the validating admission
+// webhooks of Kubewarden and Gatekeeper evaluate it without the resource being created, and reports the policies
+// rejecting it.
+func (t *Tools) evaluateAdmissionPolicies(ctx context.Context, toolReq *mcp.CallToolRequest, params evaluateAdmissionPoliciesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("evaluateAdmissionPolicies called")
+
+	objBytes, err := json.Marshal(params.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(objBytes, obj); err != nil {
+		return nil, nil, fmt.Errorf("failed to create unstructured object: %w", err)
+	}
+	if obj.GetKind() == "" || (obj.GetName() == "" && obj.GetGenerateName() == "") {
+		return nil, nil, errors.New("the resource must have a kind and a name")
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	gvr, err := t.client.ResolveGVR(ctx, token, url, params.Cluster, obj.GetKind())
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, obj.GetNamespace(), params.Cluster, gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := evaluateAdmissionPoliciesResult{Operation: "create"}
+	_, err = resourceInterface.Create(ctx, obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	if apierrors.IsAlreadyExists(err) {
+		// the policies validating the updates are evaluated against the current resource instead
+		result.Operation = "update"
+		var current *unstructured.Unstructured
+		current, err = resourceInterface.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err == nil {
+			obj.SetResourceVersion(current.GetResourceVersion())
+			_, err = resourceInterface.Update(ctx, obj, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+		}
+	}
+	result.Allowed = err == nil
+	if err != nil {
+		var statusErr *apierrors.StatusError
+		if !errors.As(err, &statusErr) {
+			middleware.Logger(ctx).Error("failed to evaluate resource", zap.String("tool", "evaluateAdmissionPolicies"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to evaluate resource %s: %w", obj.GetName(), err)
+		}
+		result.Rejections = policyRejections(statusErr.ErrStatus.Message)
+		if len(result.Rejections) == 0 {
+			result.Error = statusErr.ErrStatus.Message
+		}
+	}
+
+	result.KubewardenPolicies, err = t.kubewardenPolicies(ctx, params.Cluster, url, token, obj.GetNamespace())
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to list Kubewarden policies", zap.String("tool", "evaluateAdmissionPolicies"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "evaluateAdmissionPolicies"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// policyRejections returns the policies rejecting the request with the error message of the API server. The
+// Kubewarden webhooks are named after their policy, the Gatekeeper webhook reports a violation per constraint.
+func policyRejections(message string) []policyRejection {
+	match := webhookDenialRegexp.FindStringSubmatch(message)
+	if match == nil {
+		return nil
+	}
+	webhook, denial := match[1], strings.TrimSpace(match[2])
+
+	switch {
+	case strings.HasSuffix(webhook, ".kubewarden.admission"):
+		// clusterwide-<policy>.kubewarden.admission or namespaced-<namespace>-<policy>.kubewarden.admission
+		policy := strings.TrimSuffix(webhook, ".kubewarden.admission")
+		policy = strings.TrimPrefix(policy, "clusterwide-")
+		return []policyRejection{{Engine: "kubewarden", Webhook: webhook, Policy: policy, Message: denial}}
+	case strings.HasSuffix(webhook, ".gatekeeper.sh"):
+		var rejections []policyRejection
+		for _, line := range strings.Split(denial, "\n") {
+			if violation := gatekeeperViolationRegexp.FindStringSubmatch(strings.TrimSpace(line)); violation != nil {
+				rejections = append(rejections, policyRejection{Engine: "gatekeeper", Webhook: webhook, Policy: violation[1], Message: violation[2]})
+			}
+		}
+		if len(rejections) > 0 {
+			return rejections
+		}
+		return []policyRejection{{Engine: "gatekeeper", Webhook: webhook, Message: denial}}
+	}
+
+	return []policyRejection{{Webhook: webhook, Message: denial}}
+}
+
+// kubewardenPolicies returns the ClusterAdmissionPolicies and the AdmissionPolicies of the namespace, or nothing if
+// Kubewarden isn't installed.
+func (t *Tools) kubewardenPolicies(ctx context.Context, cluster string, url string, token string, namespace string) ([]kubewardenPolicy, error) {
+	lists := []client.ListParams{{Kind: "clusteradmissionpolicy"}}
+	if namespace != "" {
+		lists = append(lists, client.ListParams{Kind: "admissionpolicy", Namespace: namespace})
+	}
+
+	var policies []kubewardenPolicy
+	for _, list := range lists {
+		resources, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:   cluster,
+			Kind:      list.Kind,
+			Namespace: list.Namespace,
+			URL:       url,
+			Token:     token,
+		})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubewarden policies: %w", err)
+		}
+		for _, resource := range resources {
+			module, _, _ := unstructured.NestedString(resource.Object, "spec", "module")
+			mode, _, _ := unstructured.NestedString(resource.Object, "spec", "mode")
+			policies = append(policies, kubewardenPolicy{
+				Kind:      resource.GetKind(),
+				Namespace: resource.GetNamespace(),
+				Name:      resource.GetName(),
+				Module:    module,
+				Mode:      mode,
+			})
+		}
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,129 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var fakePrivilegedPolicy = &unstructured.Unstructured{Object: map[string]any{
+	"apiVersion": "policies.kubewarden.io/v1",
+	"kind":       "ClusterAdmissionPolicy",
+	"metadata":   map[string]any{"name": "no-privileged-pod"},
+	"spec":       map[string]any{"module": "ghcr.io/kubewarden/policies/pod-privileged:v0.3.2", "mode": "protect"},
+}}
+
+func fakeDeployment(privileged bool) map[string]any {
+	return map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "nginx", "namespace": "default"},
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"containers": []any{
+			map[string]any{"name": "nginx", "image": "nginx", "securityContext": map[string]any{"privileged": privileged}},
+		}}}},
+	}
+}
+
+func TestEvaluateAdmissionPolicies(t *testing.T) {
+	tests := map[string]struct {
+		resource       map[string]any
+		objects        []runtime.Object
+		denial         error
+		exists         bool
+		expectedResult string
+	}{
+		"allowed": {
+			resource:       fakeDeployment(false),
+			objects:        []runtime.Object{fakePrivilegedPolicy},
+			expectedResult: `{"allowed":true,"operation":"create","kubewardenPolicies":[{"kind":"ClusterAdmissionPolicy","name":"no-privileged-pod","module":"ghcr.io/kubewarden/policies/pod-privileged:v0.3.2","mode":"protect"}]}`,
+		},
+		"rejected by kubewarden": {
+			resource: fakeDeployment(true),
+			objects:  []runtime.Object{fakePrivilegedPolicy},
+			denial:   apierrors.NewBadRequest(`admission webhook "clusterwide-no-privileged-pod.kubewarden.admission" denied the request: Privileged container is not allowed`),
+			expectedResult: `{"allowed":false,"operation":"create","rejections":[{"engine":"kubewarden","webhook":"clusterwide-no-privileged-pod.kubewarden.admission","policy":"no-privileged-pod","message":"Privileged container is not allowed"}],
+				"kubewardenPolicies":[{"kind":"ClusterAdmissionPolicy","name":"no-privileged-pod","module":"ghcr.io/kubewarden/policies/pod-privileged:v0.3.2","mode":"protect"}]}`,
+		},
+		"rejected by gatekeeper": {
+			resource: fakeDeployment(true),
+			denial: apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx", &webhookError{`admission webhook "validation.gatekeeper.sh" denied the request: [psp-privileged-container] Privileged container is not allowed: nginx
+[required-labels] you must provide labels: {"owner"}`}),
+			expectedResult: `{"allowed":false,"operation":"create","rejections":[
+				{"engine":"gatekeeper","webhook":"validation.gatekeeper.sh","policy":"psp-privileged-container","message":"Privileged container is not allowed: nginx"},
+				{"engine":"gatekeeper","webhook":"validation.gatekeeper.sh","policy":"required-labels","message":"you must provide labels: {\"owner\"}"}
+			]}`,
+		},
+		"invalid resource": {
+			resource:       fakeDeployment(false),
+			denial:         apierrors.NewBadRequest("spec.selector: Required value"),
+			expectedResult: `{"allowed":false,"operation":"create","error":"spec.selector: Required value"}`,
+		},
+		"existing resource": {
+			resource: fakeDeployment(false),
+			objects: []runtime.Object{&unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx", "namespace": "default"},
+			}}},
+			exists:         true,
+			expectedResult: `{"allowed":true,"operation":"update"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newFakeClient(test.objects...)
+			fakeDynClient.PrependReactor("*", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetVerb() != "create" && action.GetVerb() != "update" {
+					return false, nil, nil
+				}
+				// the fake client doesn't support dry-run requests, so the resource must not be created
+				if test.denial != nil {
+					return true, nil, test.denial
+				}
+				if action.GetVerb() == "create" && test.exists {
+					return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx")
+				}
+				return true, &unstructured.Unstructured{Object: test.resource}, nil
+			})
+			tools := Tools{client: c}
+
+			result, _, err := tools.evaluateAdmissionPolicies(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest("https://localhost:8080"), evaluateAdmissionPoliciesParams{
+				Cluster:  "local",
+				Resource: test.resource,
+			})
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestEvaluateAdmissionPoliciesInvalidResource(t *testing.T) {
+	c, _ := newFakeClient()
+	tools := Tools{client: c}
+
+	_, _, err := tools.evaluateAdmissionPolicies(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest("https://localhost:8080"), evaluateAdmissionPoliciesParams{
+		Cluster:  "local",
+		Resource: map[string]any{"apiVersion": "v1", "kind": "Pod"},
+	})
+
+	assert.ErrorContains(t, err, "the resource must have a kind and a name")
+}
+
+// webhookError is the error of an admission webhook wrapped by apierrors.NewForbidden.
+type webhookError struct {
+	message string
+}
+
+func (e *webhookError) Error() string {
+	return e.message
+}
@@ -0,0 +1,163 @@
+package security
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// violationResults are the results of the PolicyReports listed as violations.
+var violationResults = []string{"fail", "error", "warn"}
+
+type listPolicyViolationsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the policy reports"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only list the violations of this namespace"`
+}
+
+// policyReport is the subset of a PolicyReport or ClusterPolicyReport used to list the violations.
+// https://github.com/kubernetes-sigs/wg-policy-prototypes/tree/master/policy-report
+type policyReport struct {
+	// Scope is the resource of the report, the Kubewarden audit scanner creates a report per resource.
+	Scope   *policyReportResource `json:"scope"`
+	Results []struct {
+		Source    string                 `json:"source"`
+		Policy    string                 `json:"policy"`
+		Rule      string                 `json:"rule"`
+		Category  string                 `json:"category"`
+		Severity  string                 `json:"severity"`
+		Result    string                 `json:"result"`
+		Message   string                 `json:"message"`
+		Resources []policyReportResource `json:"resources"`
+	} `json:"results"`
+}
+
+type policyReportResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// policyViolation is a failed policy of a resource.
+type policyViolation struct {
+	Policy   string `json:"policy"`
+	Rule     string `json:"rule,omitempty"`
+	Result   string `json:"result"`
+	Severity string `json:"severity,omitempty"`
+	Category string `json:"category,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// workloadViolations are the policy violations of a resource.
+type workloadViolations struct {
+	policyReportResource
+	Violations []policyViolation `json:"violations"`
+}
+
+// listPolicyViolations returns the failed results of the PolicyReports of a namespace or of all the namespaces, and
+// of the ClusterPolicyReports if no namespace is given, grouped by resource.
+func (t *Tools) listPolicyViolations(ctx context.Context, toolReq *mcp.CallToolRequest, params listPolicyViolationsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listPolicyViolations called")
+
+	kinds := []string{"policyreport"}
+	if params.Namespace == "" {
+		kinds = append(kinds, "clusterpolicyreport")
+	}
+	var reports []*unstructured.Unstructured
+	for _, kind := range kinds {
+		resources, err := t.client.GetResources(ctx, client.ListParams{
+			Cluster:   params.Cluster,
+			Kind:      kind,
+			Namespace: params.Namespace,
+			URL:       toolReq.Extra.Header.Get(urlHeader),
+			Token:     middleware.Token(ctx),
+		})
+		if apierrors.IsNotFound(err) {
+			middleware.Logger(ctx).Error("failed to list policy reports", zap.String("tool", "listPolicyViolations"), zap.Error(err))
+			return nil, nil, fmt.Errorf("no policy reports found in cluster %s, make sure Kubewarden is installed with the audit scanner enabled: %w", params.Cluster, err)
+		}
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to list policy reports", zap.String("tool", "listPolicyViolations"), zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to get policy reports: %w", err)
+		}
+		reports = append(reports, resources...)
+	}
+
+	violations, err := policyViolations(reports)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to convert policy reports", zap.String("tool", "listPolicyViolations"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	response, err := json.Marshal(violations)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listPolicyViolations"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// policyViolations groups the failed results of the reports by resource, the resources with the most violations
+// first.
+func policyViolations(reports []*unstructured.Unstructured) ([]workloadViolations, error) {
+	byResource := map[policyReportResource]*workloadViolations{}
+	for _, unstructuredReport := range reports {
+		var report policyReport
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredReport.Object, &report); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured object to PolicyReport: %w", err)
+		}
+		for _, result := range report.Results {
+			if !slices.Contains(violationResults, result.Result) {
+				continue
+			}
+			resources := result.Resources
+			if len(resources) == 0 && report.Scope != nil {
+				resources = []policyReportResource{*report.Scope}
+			}
+			for _, resource := range resources {
+				w, ok := byResource[resource]
+				if !ok {
+					w = &workloadViolations{policyReportResource: resource}
+					byResource[resource] = w
+				}
+				w.Violations = append(w.Violations, policyViolation{
+					Policy:   result.Policy,
+					Rule:     result.Rule,
+					Result:   result.Result,
+					Severity: result.Severity,
+					Category: result.Category,
+					Source:   result.Source,
+					Message:  result.Message,
+				})
+			}
+		}
+	}
+
+	violations := make([]workloadViolations, 0, len(byResource))
+	for _, w := range byResource {
+		violations = append(violations, *w)
+	}
+	slices.SortFunc(violations, func(a, b workloadViolations) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Violations), len(a.Violations)),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	return violations, nil
+}
@@ -0,0 +1,77 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func fakePolicyReport(kind string, namespace string, name string, scope map[string]any, results ...any) *unstructured.Unstructured {
+	metadata := map[string]any{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2",
+		"kind":       kind,
+		"metadata":   metadata,
+		"scope":      scope,
+		"results":    results,
+	}}
+}
+
+func TestListPolicyViolations(t *testing.T) {
+	objects := []runtime.Object{
+		// report of the Kubewarden audit scanner with the resource as scope
+		fakePolicyReport("PolicyReport", "default", "1b2c3d", map[string]any{"kind": "Deployment", "namespace": "default", "name": "nginx"},
+			map[string]any{"source": "kubewarden", "policy": "cap-no-privileged", "result": "fail", "severity": "high", "category": "PSP", "message": "privileged containers are not allowed"},
+			map[string]any{"source": "kubewarden", "policy": "no-latest-tag", "result": "warn", "message": "the latest tag is not allowed"},
+			map[string]any{"source": "kubewarden", "policy": "require-labels", "result": "pass"},
+		),
+		// report with the resources in the results
+		fakePolicyReport("PolicyReport", "web", "polr-ns-web", nil,
+			map[string]any{"source": "kubewarden", "policy": "cap-no-privileged", "result": "fail", "resources": []any{map[string]any{"kind": "Pod", "namespace": "web", "name": "debug"}}},
+		),
+		fakePolicyReport("ClusterPolicyReport", "", "4e5f6a", map[string]any{"kind": "Namespace", "name": "web"},
+			map[string]any{"source": "kubewarden", "policy": "namespace-psa-label", "result": "fail", "message": "the pod-security label is required"},
+		),
+	}
+
+	tests := map[string]struct {
+		params         listPolicyViolationsParams
+		expectedResult string
+	}{
+		"all namespaces": {
+			params: listPolicyViolationsParams{Cluster: "local"},
+			expectedResult: `[
+				{"kind":"Deployment","namespace":"default","name":"nginx","violations":[
+					{"policy":"cap-no-privileged","result":"fail","severity":"high","category":"PSP","source":"kubewarden","message":"privileged containers are not allowed"},
+					{"policy":"no-latest-tag","result":"warn","source":"kubewarden","message":"the latest tag is not allowed"}
+				]},
+				{"kind":"Namespace","name":"web","violations":[{"policy":"namespace-psa-label","result":"fail","source":"kubewarden","message":"the pod-security label is required"}]},
+				{"kind":"Pod","namespace":"web","name":"debug","violations":[{"policy":"cap-no-privileged","result":"fail","source":"kubewarden"}]}
+			]`,
+		},
+		"namespace": {
+			params:         listPolicyViolationsParams{Cluster: "local", Namespace: "web"},
+			expectedResult: `[{"kind":"Pod","namespace":"web","name":"debug","violations":[{"policy":"cap-no-privileged","result":"fail","source":"kubewarden"}]}]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(objects...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.listPolicyViolations(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest("https://localhost:8080"), test.params)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
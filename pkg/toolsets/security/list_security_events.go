@@ -0,0 +1,217 @@
+package security
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSecurityEventsLimit = 200
+	maxSecurityEventsLimit     = 1000
+	// maxRecentSecurityEvents is the number of most recent events returned for each workload.
+	maxRecentSecurityEvents = 5
+)
+
+type listSecurityEventsParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the workloads"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only list the events of the workloads of this namespace"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"the number of most recent events of each type fetched from NeuVector. Defaults to 200"`
+}
+
+// neuvectorIncident is the subset of an incident log of the NeuVector API, e.g. a process or file access violation.
+type neuvectorIncident struct {
+	Name           string `json:"name"`
+	Level          string `json:"level"`
+	Action         string `json:"action"`
+	ReportedAt     string `json:"reported_at"`
+	WorkloadName   string `json:"workload_name"`
+	WorkloadDomain string `json:"workload_domain"`
+	Message        string `json:"message"`
+	ProcName       string `json:"proc_name"`
+}
+
+// neuvectorThreat is the subset of a threat log of the NeuVector API, e.g. a network attack detected by the DLP or
+// the WAF sensors.
+type neuvectorThreat struct {
+	Name                 string `json:"name"`
+	Severity             string `json:"severity"`
+	Action               string `json:"action"`
+	ReportedAt           string `json:"reported_at"`
+	ClientWorkloadName   string `json:"client_workload_name"`
+	ClientWorkloadDomain string `json:"client_workload_domain"`
+	ServerWorkloadName   string `json:"server_workload_name"`
+	ServerWorkloadDomain string `json:"server_workload_domain"`
+	Message              string `json:"message"`
+}
+
+// neuvectorViolation is the subset of a network policy violation log of the NeuVector API.
+type neuvectorViolation struct {
+	Level        string `json:"level"`
+	PolicyAction string `json:"policy_action"`
+	ReportedAt   string `json:"reported_at"`
+	ClientName   string `json:"client_name"`
+	ClientDomain string `json:"client_domain"`
+	ServerName   string `json:"server_name"`
+	ServerDomain string `json:"server_domain"`
+	ServerPort   int    `json:"server_port"`
+}
+
+// securityEvent is a NeuVector incident, threat or violation of a workload.
+type securityEvent struct {
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Level      string `json:"level,omitempty"`
+	Action     string `json:"action,omitempty"`
+	ReportedAt string `json:"reportedAt"`
+	Message    string `json:"message,omitempty"`
+
+	namespace string
+	workload  string
+}
+
+// workloadSecurityEvents are the security events of a workload.
+type workloadSecurityEvents struct {
+	Namespace    string          `json:"namespace"`
+	Workload     string          `json:"workload"`
+	Incidents    int             `json:"incidents"`
+	Threats      int             `json:"threats"`
+	Violations   int             `json:"violations"`
+	RecentEvents []securityEvent `json:"recentEvents"`
+}
+
+// listSecurityEvents returns the most recent incidents, threats and violations of NeuVector grouped by workload.
+func (t *Tools) listSecurityEvents(ctx context.Context, toolReq *mcp.CallToolRequest, params listSecurityEventsParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listSecurityEvents called")
+
+	limit := cmp.Or(params.Limit, defaultSecurityEventsLimit)
+	if limit < 0 || limit > maxSecurityEventsLimit {
+		return nil, nil, fmt.Errorf("invalid limit %d, must be between 1 and %d", params.Limit, maxSecurityEventsLimit)
+	}
+	query := "?start=0&limit=" + strconv.Itoa(limit)
+
+	var incidents struct {
+		Incidents []neuvectorIncident `json:"incidents"`
+	}
+	var threats struct {
+		Threats []neuvectorThreat `json:"threats"`
+	}
+	var violations struct {
+		Violations []neuvectorViolation `json:"violations"`
+	}
+	for _, log := range []struct {
+		path string
+		v    any
+	}{{"/v1/log/incident", &incidents}, {"/v1/log/threat", &threats}, {"/v1/log/violation", &violations}} {
+		if err := t.neuvectorGet(ctx, toolReq, params.Cluster, log.path+query, log.v); err != nil {
+			middleware.Logger(ctx).Error("failed to get NeuVector events", zap.String("tool", "listSecurityEvents"), zap.Error(err))
+			return nil, nil, err
+		}
+	}
+
+	var events []securityEvent
+	for _, incident := range incidents.Incidents {
+		events = append(events, securityEvent{
+			Type:       "incident",
+			Name:       incident.Name,
+			Level:      incident.Level,
+			Action:     incident.Action,
+			ReportedAt: incident.ReportedAt,
+			Message:    cmp.Or(incident.Message, incident.ProcName),
+			namespace:  incident.WorkloadDomain,
+			workload:   incident.WorkloadName,
+		})
+	}
+	for _, threat := range threats.Threats {
+		// threats are reported for the workload under attack
+		namespace, workload := threat.ServerWorkloadDomain, threat.ServerWorkloadName
+		if workload == "" {
+			namespace, workload = threat.ClientWorkloadDomain, threat.ClientWorkloadName
+		}
+		events = append(events, securityEvent{
+			Type:       "threat",
+			Name:       threat.Name,
+			Level:      threat.Severity,
+			Action:     threat.Action,
+			ReportedAt: threat.ReportedAt,
+			Message:    threat.Message,
+			namespace:  namespace,
+			workload:   workload,
+		})
+	}
+	for _, violation := range violations.Violations {
+		// violations are reported for the workload opening the connection
+		events = append(events, securityEvent{
+			Type:       "violation",
+			Name:       "Network policy violation",
+			Level:      violation.Level,
+			Action:     violation.PolicyAction,
+			ReportedAt: violation.ReportedAt,
+			Message:    fmt.Sprintf("connection to %s/%s on port %d", violation.ServerDomain, violation.ServerName, violation.ServerPort),
+			namespace:  violation.ClientDomain,
+			workload:   violation.ClientName,
+		})
+	}
+
+	response, err := json.Marshal(workloadsSecurityEvents(events, params.Namespace))
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listSecurityEvents"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// workloadsSecurityEvents groups the events by workload, the workloads with the most events first. Only the most
+// recent events of each workload are kept, the others are only counted.
+func workloadsSecurityEvents(events []securityEvent, namespace string) []workloadSecurityEvents {
+	slices.SortStableFunc(events, func(a, b securityEvent) int { return cmp.Compare(b.ReportedAt, a.ReportedAt) })
+
+	type workloadKey struct{ namespace, workload string }
+	byWorkload := map[workloadKey]*workloadSecurityEvents{}
+	for _, event := range events {
+		if namespace != "" && event.namespace != namespace {
+			continue
+		}
+		key := workloadKey{namespace: event.namespace, workload: event.workload}
+		w, ok := byWorkload[key]
+		if !ok {
+			w = &workloadSecurityEvents{Namespace: event.namespace, Workload: event.workload}
+			byWorkload[key] = w
+		}
+		switch event.Type {
+		case "incident":
+			w.Incidents++
+		case "threat":
+			w.Threats++
+		case "violation":
+			w.Violations++
+		}
+		if len(w.RecentEvents) < maxRecentSecurityEvents {
+			w.RecentEvents = append(w.RecentEvents, event)
+		}
+	}
+
+	result := make([]workloadSecurityEvents, 0, len(byWorkload))
+	for _, w := range byWorkload {
+		result = append(result, *w)
+	}
+	slices.SortFunc(result, func(a, b workloadSecurityEvents) int {
+		return cmp.Or(
+			cmp.Compare(b.Incidents+b.Threats+b.Violations, a.Incidents+a.Threats+a.Violations),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Workload, b.Workload),
+		)
+	})
+
+	return result
+}
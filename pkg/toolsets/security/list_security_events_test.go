@@ -0,0 +1,54 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestListSecurityEvents(t *testing.T) {
+	srv := newFakeNeuVector(t, map[string]string{
+		neuvectorProxyPath + "/v1/log/incident": `{"incidents":[
+			{"name":"Process.Profile.Violation","level":"Warning","action":"violate","reported_at":"2024-05-01T00:03:00Z","workload_name":"nginx-abc12","workload_domain":"default","message":"Process profile violation: execution of /bin/sh"},
+			{"name":"Host.Package.Updated","level":"Warning","reported_at":"2024-05-01T00:01:00Z","workload_name":"api-abc12","workload_domain":"web","proc_name":"apt-get"}
+		]}`,
+		neuvectorProxyPath + "/v1/log/threat": `{"threats":[
+			{"name":"SQL.Injection","severity":"Critical","action":"deny","reported_at":"2024-05-01T00:02:00Z","client_workload_name":"external","server_workload_name":"nginx-abc12","server_workload_domain":"default"}
+		]}`,
+		neuvectorProxyPath + "/v1/log/violation": `{"violations":[
+			{"level":"Warning","policy_action":"violate","reported_at":"2024-05-01T00:00:00Z","client_name":"nginx-abc12","client_domain":"default","server_name":"redis-0","server_domain":"cache","server_port":6379}
+		]}`,
+	})
+	c, _ := newFakeClient(fakeNeuVectorService, fakeAPIKeySecret)
+	tools := Tools{client: c}
+
+	result, _, err := tools.listSecurityEvents(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(srv.URL), listSecurityEventsParams{Cluster: "local"})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"namespace":"default","workload":"nginx-abc12","incidents":1,"threats":1,"violations":1,"recentEvents":[
+			{"type":"incident","name":"Process.Profile.Violation","level":"Warning","action":"violate","reportedAt":"2024-05-01T00:03:00Z","message":"Process profile violation: execution of /bin/sh"},
+			{"type":"threat","name":"SQL.Injection","level":"Critical","action":"deny","reportedAt":"2024-05-01T00:02:00Z"},
+			{"type":"violation","name":"Network policy violation","level":"Warning","action":"violate","reportedAt":"2024-05-01T00:00:00Z","message":"connection to cache/redis-0 on port 6379"}
+		]},
+		{"namespace":"web","workload":"api-abc12","incidents":1,"threats":0,"violations":0,"recentEvents":[
+			{"type":"incident","name":"Host.Package.Updated","level":"Warning","reportedAt":"2024-05-01T00:01:00Z","message":"apt-get"}
+		]}
+	]`, result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestListSecurityEventsInvalidAPIKey(t *testing.T) {
+	srv := newFakeNeuVector(t, nil)
+	secret := fakeAPIKeySecret.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(secret.Object, "d3Jvbmc6a2V5", "data", neuvectorAPIKeyKey))
+	c, _ := newFakeClient(fakeNeuVectorService, secret)
+	tools := Tools{client: c}
+
+	_, _, err := tools.listSecurityEvents(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(srv.URL), listSecurityEventsParams{Cluster: "local"})
+
+	assert.ErrorContains(t, err, `NeuVector request failed with status 401 Unauthorized: {"code":1,"error":"Authentication failed"}`)
+}
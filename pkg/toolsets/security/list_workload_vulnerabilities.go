@@ -0,0 +1,131 @@
+package security
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"go.uber.org/zap"
+)
+
+type listWorkloadVulnerabilitiesParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the workloads"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"only list the workloads of this namespace"`
+}
+
+// neuvectorWorkload is the subset of a workload of the NeuVector API used to list the vulnerabilities. The pods are
+// returned with their containers as children.
+type neuvectorWorkload struct {
+	Name         string `json:"name"`
+	Domain       string `json:"domain"`
+	Service      string `json:"service"`
+	Image        string `json:"image"`
+	PlatformRole string `json:"platform_role"`
+	ScanSummary  *struct {
+		Status    string `json:"status"`
+		High      int    `json:"high"`
+		Medium    int    `json:"medium"`
+		ScannedAt string `json:"scanned_at"`
+	} `json:"scan_summary"`
+	Children []neuvectorWorkload `json:"children"`
+}
+
+// imageScan is the scan result of an image of a workload.
+type imageScan struct {
+	Image     string `json:"image"`
+	Status    string `json:"status"`
+	High      int    `json:"high"`
+	Medium    int    `json:"medium"`
+	ScannedAt string `json:"scannedAt,omitempty"`
+}
+
+// workloadVulnerabilities are the vulnerabilities of the images of a workload.
+type workloadVulnerabilities struct {
+	Namespace string      `json:"namespace"`
+	Workload  string      `json:"workload"`
+	High      int         `json:"high"`
+	Medium    int         `json:"medium"`
+	Images    []imageScan `json:"images"`
+}
+
+// listWorkloadVulnerabilities returns the scan results of the NeuVector workloads, grouped by Kubernetes workload.
+// The images of the pods of a workload are counted once, so replicas don't multiply the vulnerabilities.
+func (t *Tools) listWorkloadVulnerabilities(ctx context.Context, toolReq *mcp.CallToolRequest, params listWorkloadVulnerabilitiesParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("listWorkloadVulnerabilities called")
+
+	var workloads struct {
+		Workloads []neuvectorWorkload `json:"workloads"`
+	}
+	if err := t.neuvectorGet(ctx, toolReq, params.Cluster, "/v1/workload", &workloads); err != nil {
+		middleware.Logger(ctx).Error("failed to get NeuVector workloads", zap.String("tool", "listWorkloadVulnerabilities"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	response, err := json.Marshal(workloadsVulnerabilities(workloads.Workloads, params.Namespace))
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "listWorkloadVulnerabilities"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
+
+// workloadsVulnerabilities groups the scanned containers by namespace and service, the NeuVector name of the
+// Kubernetes workload. The system containers of NeuVector and of the platform are ignored.
+func workloadsVulnerabilities(workloads []neuvectorWorkload, namespace string) []workloadVulnerabilities {
+	type workloadKey struct{ namespace, workload string }
+	byWorkload := map[workloadKey]*workloadVulnerabilities{}
+	var add func(w neuvectorWorkload)
+	add = func(w neuvectorWorkload) {
+		for _, child := range w.Children {
+			add(child)
+		}
+		if w.ScanSummary == nil || w.PlatformRole != "" || (namespace != "" && w.Domain != namespace) {
+			return
+		}
+		// the service of a workload is <name>.<namespace>
+		key := workloadKey{namespace: w.Domain, workload: strings.TrimSuffix(cmp.Or(w.Service, w.Name), "."+w.Domain)}
+		vulns, ok := byWorkload[key]
+		if !ok {
+			vulns = &workloadVulnerabilities{Namespace: key.namespace, Workload: key.workload}
+			byWorkload[key] = vulns
+		}
+		if slices.ContainsFunc(vulns.Images, func(i imageScan) bool { return i.Image == w.Image }) {
+			return
+		}
+		vulns.Images = append(vulns.Images, imageScan{
+			Image:     w.Image,
+			Status:    w.ScanSummary.Status,
+			High:      w.ScanSummary.High,
+			Medium:    w.ScanSummary.Medium,
+			ScannedAt: w.ScanSummary.ScannedAt,
+		})
+		vulns.High += w.ScanSummary.High
+		vulns.Medium += w.ScanSummary.Medium
+	}
+	for _, w := range workloads {
+		add(w)
+	}
+
+	result := make([]workloadVulnerabilities, 0, len(byWorkload))
+	for _, vulns := range byWorkload {
+		result = append(result, *vulns)
+	}
+	slices.SortFunc(result, func(a, b workloadVulnerabilities) int {
+		return cmp.Or(
+			cmp.Compare(b.High, a.High),
+			cmp.Compare(b.Medium, a.Medium),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Workload, b.Workload),
+		)
+	})
+
+	return result
+}
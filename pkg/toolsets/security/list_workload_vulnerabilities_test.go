@@ -0,0 +1,78 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const fakeNeuVectorWorkloads = `{"workloads":[
+	{"name":"nginx-abc12","domain":"default","service":"nginx.default","children":[
+		{"name":"nginx","domain":"default","service":"nginx.default","image":"nginx:1.25","scan_summary":{"status":"finished","high":3,"medium":10,"scanned_at":"2024-05-01T00:00:00Z"}},
+		{"name":"sidecar","domain":"default","service":"nginx.default","image":"envoy:1.30","scan_summary":{"status":"finished","high":1,"medium":2,"scanned_at":"2024-05-01T00:00:00Z"}}
+	]},
+	{"name":"nginx-def34","domain":"default","service":"nginx.default","children":[
+		{"name":"nginx","domain":"default","service":"nginx.default","image":"nginx:1.25","scan_summary":{"status":"finished","high":3,"medium":10,"scanned_at":"2024-05-01T00:00:00Z"}}
+	]},
+	{"name":"api-abc12","domain":"web","service":"api.web","children":[
+		{"name":"api","domain":"web","service":"api.web","image":"api:2.0","scan_summary":{"status":"scheduled","high":0,"medium":0}}
+	]},
+	{"name":"neuvector-enforcer-pod-abc12","domain":"cattle-neuvector-system","platform_role":"System","scan_summary":{"status":"finished","high":1,"medium":1}}
+]}`
+
+func TestListWorkloadVulnerabilities(t *testing.T) {
+	srv := newFakeNeuVector(t, map[string]string{neuvectorProxyPath + "/v1/workload": fakeNeuVectorWorkloads})
+
+	tests := map[string]struct {
+		params         listWorkloadVulnerabilitiesParams
+		objects        []runtime.Object
+		expectedResult string
+		expectedError  string
+	}{
+		"all namespaces": {
+			params:  listWorkloadVulnerabilitiesParams{Cluster: "local"},
+			objects: []runtime.Object{fakeNeuVectorService, fakeAPIKeySecret},
+			expectedResult: `[
+				{"namespace":"default","workload":"nginx","high":4,"medium":12,"images":[
+					{"image":"nginx:1.25","status":"finished","high":3,"medium":10,"scannedAt":"2024-05-01T00:00:00Z"},
+					{"image":"envoy:1.30","status":"finished","high":1,"medium":2,"scannedAt":"2024-05-01T00:00:00Z"}
+				]},
+				{"namespace":"web","workload":"api","high":0,"medium":0,"images":[{"image":"api:2.0","status":"scheduled","high":0,"medium":0}]}
+			]`,
+		},
+		"namespace": {
+			params:         listWorkloadVulnerabilitiesParams{Cluster: "local", Namespace: "web"},
+			objects:        []runtime.Object{fakeNeuVectorService, fakeAPIKeySecret},
+			expectedResult: `[{"namespace":"web","workload":"api","high":0,"medium":0,"images":[{"image":"api:2.0","status":"scheduled","high":0,"medium":0}]}]`,
+		},
+		"neuvector not installed": {
+			params:        listWorkloadVulnerabilitiesParams{Cluster: "local"},
+			expectedError: "NeuVector is not installed in cluster local, the cattle-neuvector-system/neuvector-svc-controller-api Service was not found",
+		},
+		"no api key": {
+			params:        listWorkloadVulnerabilitiesParams{Cluster: "local"},
+			objects:       []runtime.Object{fakeNeuVectorService},
+			expectedError: "the cattle-neuvector-system/neuvector-api-key Secret was not found in cluster local",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeClient(test.objects...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.listWorkloadVulnerabilities(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest(srv.URL), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// neuvectorNamespace, neuvectorAPIService and neuvectorAPIPort identify the REST API of the NeuVector controller
+	// installed by the NeuVector chart of Rancher.
+	neuvectorNamespace  = "cattle-neuvector-system"
+	neuvectorAPIService = "neuvector-svc-controller-api"
+	neuvectorAPIPort    = "10443"
+	// neuvectorAPIKeySecret is the Secret with the NeuVector API key used by the tools, as <name>:<secret> in its
+	// apiKey key. It's read with the credentials of the user, so only the users allowed to read it can query NeuVector.
+	neuvectorAPIKeySecret = "neuvector-api-key"
+	neuvectorAPIKeyKey    = "apiKey"
+	// neuvectorTimeout is the maximum time allowed for a request to the NeuVector API.
+	neuvectorTimeout = 30 * time.Second
+	// neuvectorMaxErrorBytes is the maximum size of the body of a failed response included in the errors.
+	neuvectorMaxErrorBytes = 4 * 1024
+)
+
+// neuvectorGet sends a GET request to the REST API of the NeuVector controller of the cluster and decodes the JSON
+// response into v.
+func (t *Tools) neuvectorGet(ctx context.Context, toolReq *mcp.CallToolRequest, cluster string, path string, v any) error {
+	rancherURL := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+
+	_, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "service",
+		Namespace: neuvectorNamespace,
+		Name:      neuvectorAPIService,
+		URL:       rancherURL,
+		Token:     token,
+	})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("NeuVector is not installed in cluster %s, the %s/%s Service was not found", cluster, neuvectorNamespace, neuvectorAPIService)
+	}
+	if err != nil {
+		return err
+	}
+	apiKey, err := t.neuvectorAPIKey(ctx, cluster, rancherURL, token)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, neuvectorTimeout)
+	defer cancel()
+	res, err := t.client.ProxyGet(ctx, client.ProxyGetParams{
+		Cluster:   cluster,
+		Kind:      "service",
+		Namespace: neuvectorNamespace,
+		Name:      neuvectorAPIService,
+		Scheme:    "https",
+		Port:      neuvectorAPIPort,
+		Path:      path,
+		URL:       rancherURL,
+		Token:     token,
+		Header:    http.Header{"X-Auth-Apikey": {apiKey}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query NeuVector: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read NeuVector response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		if len(body) > neuvectorMaxErrorBytes {
+			body = body[:neuvectorMaxErrorBytes]
+		}
+		return fmt.Errorf("NeuVector request failed with status %s: %s", res.Status, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse NeuVector response: %w", err)
+	}
+
+	return nil
+}
+
+func (t *Tools) neuvectorAPIKey(ctx context.Context, cluster string, url string, token string) (string, error) {
+	secret, err := t.client.GetResource(ctx, client.GetParams{
+		Cluster:   cluster,
+		Kind:      "secret",
+		Namespace: neuvectorNamespace,
+		Name:      neuvectorAPIKeySecret,
+		URL:       url,
+		Token:     token,
+	})
+	if apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("the %s/%s Secret was not found in cluster %s, create it with a read-only NeuVector API key in the %s key, as <name>:<secret>", neuvectorNamespace, neuvectorAPIKeySecret, cluster, neuvectorAPIKeyKey)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	encoded, _, _ := unstructured.NestedString(secret.Object, "data", neuvectorAPIKeyKey)
+	apiKey, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(apiKey) == 0 {
+		return "", fmt.Errorf("the %s/%s Secret has no valid %s key", neuvectorNamespace, neuvectorAPIKeySecret, neuvectorAPIKeyKey)
+	}
+
+	return string(apiKey), nil
+}
@@ -0,0 +1,429 @@
+package security
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The levels of the Pod Security Standards, from the least to the most restrictive.
+const (
+	levelPrivileged = "privileged"
+	levelBaseline   = "baseline"
+	levelRestricted = "restricted"
+)
+
+// podSecurityLevels are the levels of the Pod Security Standards, from the least to the most restrictive.
+var podSecurityLevels = []string{levelPrivileged, levelBaseline, levelRestricted}
+
+// podSecurityLabelPrefix is the prefix of the namespace labels setting the levels of the Pod Security Admission modes,
+// e.g. pod-security.kubernetes.io/enforce=restricted and pod-security.kubernetes.io/enforce-version=v1.31.
+const podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+
+// appArmorAnnotationPrefix is the prefix of the deprecated pod annotations setting the AppArmor profile of a container.
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+var (
+	// baselineCapabilities are the capabilities containers can add in the baseline level.
+	baselineCapabilities = []corev1.Capability{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+		"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	// baselineSELinuxTypes are the SELinux types pods and containers can set in the baseline level.
+	baselineSELinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+	// safeSysctls are the sysctls pods can set in the baseline level.
+	safeSysctls = []string{"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports", "net.ipv4.tcp_keepalive_time",
+		"net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl", "net.ipv4.tcp_keepalive_probes"}
+	// restrictedVolumes are the types of volumes pods can use in the restricted level.
+	restrictedVolumes = []string{"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret"}
+)
+
+// podSecurityCheck is a check of the Pod Security Standards, which returns why a pod fails it.
+type podSecurityCheck struct {
+	name  string
+	level string
+	// linuxOnly checks aren't run for the Windows pods.
+	linuxOnly bool
+	check     func(pod *corev1.Pod) []string
+}
+
+// podSecurityViolation is a check of the Pod Security Standards failed by a pod.
+type podSecurityViolation struct {
+	Level   string
+	Message string
+}
+
+// podSecurityChecks are the checks of the latest version of the Pod Security Standards, like the ones of the Pod
+// Security Admission controller. The restricted level includes the checks of the baseline level.
+var podSecurityChecks = []podSecurityCheck{
+	{name: "hostProcess", level: levelBaseline, check: checkHostProcess},
+	{name: "host namespaces", level: levelBaseline, check: checkHostNamespaces},
+	{name: "privileged", level: levelBaseline, check: checkPrivileged},
+	{name: "non-default capabilities", level: levelBaseline, check: checkBaselineCapabilities},
+	{name: "hostPath volumes", level: levelBaseline, check: checkHostPathVolumes},
+	{name: "hostPort", level: levelBaseline, check: checkHostPorts},
+	{name: "appArmorProfile", level: levelBaseline, check: checkAppArmor},
+	{name: "seLinuxOptions", level: levelBaseline, check: checkSELinux},
+	{name: "procMount", level: levelBaseline, check: checkProcMount},
+	{name: "seccompProfile", level: levelBaseline, check: checkBaselineSeccomp},
+	{name: "forbidden sysctls", level: levelBaseline, check: checkSysctls},
+	{name: "restricted volume types", level: levelRestricted, check: checkRestrictedVolumes},
+	{name: "allowPrivilegeEscalation != false", level: levelRestricted, linuxOnly: true, check: checkPrivilegeEscalation},
+	{name: "unrestricted capabilities", level: levelRestricted, linuxOnly: true, check: checkRestrictedCapabilities},
+	{name: "runAsNonRoot != true", level: levelRestricted, check: checkRunAsNonRoot},
+	{name: "runAsUser=0", level: levelRestricted, check: checkRunAsUser},
+	{name: "seccompProfile", level: levelRestricted, linuxOnly: true, check: checkRestrictedSeccomp},
+}
+
+// checkPodSecurity returns the checks of the Pod Security Standards failed by the pod, up to the level, with the
+// reasons in the format of the Pod Security Admission controller, e.g. privileged (container "app" must not set
+// securityContext.privileged=true).
+func checkPodSecurity(pod *corev1.Pod, level string) []podSecurityViolation {
+	maxLevel := slices.Index(podSecurityLevels, level)
+	windows := pod.Spec.OS != nil && pod.Spec.OS.Name == corev1.Windows
+
+	var violations []podSecurityViolation
+	for _, check := range podSecurityChecks {
+		if slices.Index(podSecurityLevels, check.level) > maxLevel || (check.linuxOnly && windows) {
+			continue
+		}
+		if reasons := check.check(pod); len(reasons) > 0 {
+			violations = append(violations, podSecurityViolation{
+				Level:   check.level,
+				Message: fmt.Sprintf("%s (%s)", check.name, strings.Join(reasons, ", ")),
+			})
+		}
+	}
+
+	return violations
+}
+
+// compliantLevel returns the most restrictive level with no violations, given the violations of the restricted level.
+func compliantLevel(violations []podSecurityViolation) string {
+	level := levelRestricted
+	for _, violation := range violations {
+		if violation.Level == levelBaseline {
+			return levelPrivileged
+		}
+		level = levelBaseline
+	}
+
+	return level
+}
+
+// podContainer is a container, init container or ephemeral container of a pod.
+type podContainer struct {
+	name            string
+	securityContext *corev1.SecurityContext
+	ports           []corev1.ContainerPort
+}
+
+func podContainers(pod *corev1.Pod) []podContainer {
+	var containers []podContainer
+	for _, c := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+		containers = append(containers, podContainer{name: c.Name, securityContext: c.SecurityContext, ports: c.Ports})
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		containers = append(containers, podContainer{name: c.Name, securityContext: c.SecurityContext, ports: c.Ports})
+	}
+
+	return containers
+}
+
+// containerReasons returns the reasons of the containers failing a check.
+func containerReasons(pod *corev1.Pod, reason func(c podContainer) string) []string {
+	var reasons []string
+	for _, c := range podContainers(pod) {
+		if r := reason(c); r != "" {
+			reasons = append(reasons, fmt.Sprintf("container %q %s", c.name, r))
+		}
+	}
+
+	return reasons
+}
+
+func checkHostProcess(pod *corev1.Pod) []string {
+	var reasons []string
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.WindowsOptions != nil && ptrTrue(sc.WindowsOptions.HostProcess) {
+		reasons = append(reasons, "pod must not set securityContext.windowsOptions.hostProcess=true")
+	}
+
+	return append(reasons, containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.WindowsOptions != nil && ptrTrue(c.securityContext.WindowsOptions.HostProcess) {
+			return "must not set securityContext.windowsOptions.hostProcess=true"
+		}
+		return ""
+	})...)
+}
+
+func checkHostNamespaces(pod *corev1.Pod) []string {
+	var reasons []string
+	if pod.Spec.HostNetwork {
+		reasons = append(reasons, "hostNetwork=true")
+	}
+	if pod.Spec.HostPID {
+		reasons = append(reasons, "hostPID=true")
+	}
+	if pod.Spec.HostIPC {
+		reasons = append(reasons, "hostIPC=true")
+	}
+
+	return reasons
+}
+
+func checkPrivileged(pod *corev1.Pod) []string {
+	return containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && ptrTrue(c.securityContext.Privileged) {
+			return "must not set securityContext.privileged=true"
+		}
+		return ""
+	})
+}
+
+func checkBaselineCapabilities(pod *corev1.Pod) []string {
+	return containerReasons(pod, func(c podContainer) string {
+		if c.securityContext == nil || c.securityContext.Capabilities == nil {
+			return ""
+		}
+		return forbiddenCapabilities(c.securityContext.Capabilities.Add, baselineCapabilities)
+	})
+}
+
+func checkHostPathVolumes(pod *corev1.Pod) []string {
+	var reasons []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath != nil {
+			reasons = append(reasons, fmt.Sprintf("volume %q", volume.Name))
+		}
+	}
+
+	return reasons
+}
+
+func checkHostPorts(pod *corev1.Pod) []string {
+	return containerReasons(pod, func(c podContainer) string {
+		var ports []string
+		for _, port := range c.ports {
+			if port.HostPort != 0 {
+				ports = append(ports, fmt.Sprint(port.HostPort))
+			}
+		}
+		if len(ports) > 0 {
+			return "uses hostPort " + strings.Join(ports, ", ")
+		}
+		return ""
+	})
+}
+
+func checkAppArmor(pod *corev1.Pod) []string {
+	var reasons []string
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.AppArmorProfile != nil && sc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+		reasons = append(reasons, `pod must not set securityContext.appArmorProfile.type to "Unconfined"`)
+	}
+	reasons = append(reasons, containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.AppArmorProfile != nil && c.securityContext.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+			return `must not set securityContext.appArmorProfile.type to "Unconfined"`
+		}
+		return ""
+	})...)
+	var annotations []string
+	for key, value := range pod.Annotations {
+		if strings.HasPrefix(key, appArmorAnnotationPrefix) && value != "" && value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+			annotations = append(annotations, fmt.Sprintf("annotation %s=%q", key, value))
+		}
+	}
+	slices.Sort(annotations)
+
+	return append(reasons, annotations...)
+}
+
+func checkSELinux(pod *corev1.Pod) []string {
+	var reasons []string
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.SELinuxOptions != nil {
+		if reason := forbiddenSELinuxOptions(sc.SELinuxOptions); reason != "" {
+			reasons = append(reasons, "pod "+reason)
+		}
+	}
+
+	return append(reasons, containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.SELinuxOptions != nil {
+			return forbiddenSELinuxOptions(c.securityContext.SELinuxOptions)
+		}
+		return ""
+	})...)
+}
+
+func forbiddenSELinuxOptions(options *corev1.SELinuxOptions) string {
+	var fields []string
+	if !slices.Contains(baselineSELinuxTypes, options.Type) {
+		fields = append(fields, fmt.Sprintf("type %q", options.Type))
+	}
+	if options.User != "" {
+		fields = append(fields, "user")
+	}
+	if options.Role != "" {
+		fields = append(fields, "role")
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return "must not set securityContext.seLinuxOptions " + strings.Join(fields, ", ")
+}
+
+func checkProcMount(pod *corev1.Pod) []string {
+	return containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.ProcMount != nil && *c.securityContext.ProcMount != corev1.DefaultProcMount {
+			return fmt.Sprintf("must not set securityContext.procMount=%q", *c.securityContext.ProcMount)
+		}
+		return ""
+	})
+}
+
+func checkBaselineSeccomp(pod *corev1.Pod) []string {
+	var reasons []string
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		reasons = append(reasons, `pod must not set securityContext.seccompProfile.type to "Unconfined"`)
+	}
+
+	return append(reasons, containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.SeccompProfile != nil && c.securityContext.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			return `must not set securityContext.seccompProfile.type to "Unconfined"`
+		}
+		return ""
+	})...)
+}
+
+func checkSysctls(pod *corev1.Pod) []string {
+	var reasons []string
+	if pod.Spec.SecurityContext == nil {
+		return nil
+	}
+	for _, sysctl := range pod.Spec.SecurityContext.Sysctls {
+		if !slices.Contains(safeSysctls, sysctl.Name) {
+			reasons = append(reasons, sysctl.Name)
+		}
+	}
+
+	return reasons
+}
+
+func checkRestrictedVolumes(pod *corev1.Pod) []string {
+	var reasons []string
+	for _, volume := range pod.Spec.Volumes {
+		if volumeType := volumeSourceType(volume.VolumeSource); volumeType != "" && !slices.Contains(restrictedVolumes, volumeType) {
+			reasons = append(reasons, fmt.Sprintf("volume %q uses restricted volume type %q", volume.Name, volumeType))
+		}
+	}
+
+	return reasons
+}
+
+// volumeSourceType returns the JSON name of the field set in the volume source, e.g. hostPath.
+func volumeSourceType(source corev1.VolumeSource) string {
+	value := reflect.ValueOf(source)
+	for i := range value.NumField() {
+		if f := value.Field(i); f.Kind() == reflect.Pointer && !f.IsNil() {
+			name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+			return name
+		}
+	}
+
+	return ""
+}
+
+func checkPrivilegeEscalation(pod *corev1.Pod) []string {
+	return containerReasons(pod, func(c podContainer) string {
+		if c.securityContext == nil || c.securityContext.AllowPrivilegeEscalation == nil || *c.securityContext.AllowPrivilegeEscalation {
+			return "must set securityContext.allowPrivilegeEscalation=false"
+		}
+		return ""
+	})
+}
+
+func checkRestrictedCapabilities(pod *corev1.Pod) []string {
+	return containerReasons(pod, func(c podContainer) string {
+		var capabilities *corev1.Capabilities
+		if c.securityContext != nil {
+			capabilities = c.securityContext.Capabilities
+		}
+		if capabilities == nil || !slices.Contains(capabilities.Drop, "ALL") {
+			return `must set securityContext.capabilities.drop=["ALL"]`
+		}
+		return forbiddenCapabilities(capabilities.Add, []corev1.Capability{"NET_BIND_SERVICE"})
+	})
+}
+
+// forbiddenCapabilities returns why the added capabilities aren't allowed, or nothing if they are.
+func forbiddenCapabilities(added []corev1.Capability, allowed []corev1.Capability) string {
+	var forbidden []string
+	for _, capability := range added {
+		if !slices.Contains(allowed, capability) {
+			forbidden = append(forbidden, fmt.Sprintf("%q", capability))
+		}
+	}
+	if len(forbidden) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("must not include %s in securityContext.capabilities.add", strings.Join(forbidden, ", "))
+}
+
+func checkRunAsNonRoot(pod *corev1.Pod) []string {
+	podRunAsNonRoot := pod.Spec.SecurityContext != nil && ptrTrue(pod.Spec.SecurityContext.RunAsNonRoot)
+	if pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.RunAsNonRoot != nil && !podRunAsNonRoot {
+		return []string{"pod must not set securityContext.runAsNonRoot=false"}
+	}
+
+	return containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.RunAsNonRoot != nil {
+			if !*c.securityContext.RunAsNonRoot {
+				return "must not set securityContext.runAsNonRoot=false"
+			}
+			return ""
+		}
+		if !podRunAsNonRoot {
+			return "must set securityContext.runAsNonRoot=true"
+		}
+		return ""
+	})
+}
+
+func checkRunAsUser(pod *corev1.Pod) []string {
+	var reasons []string
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		reasons = append(reasons, "pod must not set runAsUser=0")
+	}
+
+	return append(reasons, containerReasons(pod, func(c podContainer) string {
+		if c.securityContext != nil && c.securityContext.RunAsUser != nil && *c.securityContext.RunAsUser == 0 {
+			return "must not set runAsUser=0"
+		}
+		return ""
+	})...)
+}
+
+func checkRestrictedSeccomp(pod *corev1.Pod) []string {
+	var podProfile *corev1.SeccompProfile
+	if pod.Spec.SecurityContext != nil {
+		podProfile = pod.Spec.SecurityContext.SeccompProfile
+	}
+
+	return containerReasons(pod, func(c podContainer) string {
+		profile := podProfile
+		if c.securityContext != nil && c.securityContext.SeccompProfile != nil {
+			profile = c.securityContext.SeccompProfile
+		}
+		// Unconfined is already reported by the baseline check
+		if profile == nil || (profile.Type != corev1.SeccompProfileTypeRuntimeDefault && profile.Type != corev1.SeccompProfileTypeLocalhost && profile.Type != corev1.SeccompProfileTypeUnconfined) {
+			return `must set securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"`
+		}
+		return ""
+	})
+}
+
+func ptrTrue(b *bool) bool {
+	return b != nil && *b
+}
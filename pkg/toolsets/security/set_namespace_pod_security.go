@@ -0,0 +1,115 @@
+package security
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/rancher/rancher-ai-mcp/pkg/converter"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// podSecurityVersionRegexp matches the versions of the Pod Security Standards of the version labels.
+var podSecurityVersionRegexp = regexp.MustCompile(`^(latest|v[0-9]+\.[0-9]+)$`)
+
+type setNamespacePodSecurityParams struct {
+	Cluster   string `json:"cluster" jsonschema:"the cluster of the namespace"`
+	Namespace string `json:"namespace" jsonschema:"the namespace to label" validate:"required"`
+	Mode      string `json:"mode,omitempty" jsonschema:"the Pod Security Admission mode (enforce, audit or warn). Defaults to enforce" validate:"enum=enforce|audit|warn"`
+	Level     string `json:"level" jsonschema:"the Pod Security Standards level (privileged, baseline or restricted)" validate:"required,enum=privileged|baseline|restricted"`
+	Version   string `json:"version,omitempty" jsonschema:"the version of the standards, latest or a Kubernetes minor version like v1.31. If empty, the current version label is kept"`
+	DryRun    bool   `json:"dryRun,omitempty" jsonschema:"preview the labels and the workloads violating the level without changing the namespace"`
+}
+
+// namespacePodSecurityChange is the response of setNamespacePodSecurity.
+type namespacePodSecurityChange struct {
+	DryRun    bool   `json:"dryRun"`
+	Namespace string `json:"namespace"`
+	// PreviousLabels and Labels contain the pod-security.kubernetes.io labels without their prefix, before and after
+	// the change.
+	PreviousLabels map[string]string `json:"previousLabels"`
+	Labels         map[string]string `json:"labels"`
+	// Workloads are the running workloads of the namespace violating the level. The Pod Security Admission controller
+	// doesn't evict them, but their new pods are rejected in the enforce mode.
+	Workloads []workloadPodSecurity `json:"workloads"`
+}
+
+// setNamespacePodSecurity sets the level of a Pod Security Admission mode of a namespace, with its
+// pod-security.kubernetes.io labels, and reports the running workloads violating it. In dry-run mode, the patch is
+// sent to the API server without being persisted.
+func (t *Tools) setNamespacePodSecurity(ctx context.Context, toolReq *mcp.CallToolRequest, params setNamespacePodSecurityParams) (*mcp.CallToolResult, any, error) {
+	middleware.Logger(ctx).Debug("setNamespacePodSecurity called")
+
+	mode := strings.ToLower(cmp.Or(params.Mode, "enforce"))
+	level := strings.ToLower(params.Level)
+	if params.Version != "" && !podSecurityVersionRegexp.MatchString(params.Version) {
+		return nil, nil, fmt.Errorf("invalid version %q, must be latest or a Kubernetes minor version like v1.31", params.Version)
+	}
+	labels := map[string]any{podSecurityLabelPrefix + mode: level}
+	if params.Version != "" {
+		labels[podSecurityLabelPrefix+mode+"-version"] = params.Version
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"labels": labels},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	url := toolReq.Extra.Header.Get(urlHeader)
+	token := middleware.Token(ctx)
+	resourceInterface, err := t.client.GetResourceInterface(ctx, token, url, "", params.Cluster, converter.K8sKindsToGVRs["namespace"])
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get resource interface", zap.String("tool", "setNamespacePodSecurity"), zap.Error(err))
+		return nil, nil, err
+	}
+	current, err := resourceInterface.Get(ctx, params.Namespace, metav1.GetOptions{})
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to get namespace", zap.String("tool", "setNamespacePodSecurity"), zap.Error(err))
+		return nil, nil, err
+	}
+	opts := metav1.PatchOptions{}
+	if params.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	namespace, err := resourceInterface.Patch(ctx, params.Namespace, types.MergePatchType, patch, opts)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to patch namespace", zap.String("tool", "setNamespacePodSecurity"), zap.Error(err))
+		return nil, nil, err
+	}
+
+	result := namespacePodSecurityChange{
+		DryRun:         params.DryRun,
+		Namespace:      params.Namespace,
+		PreviousLabels: podSecurityLabels(current.GetLabels()),
+		Labels:         podSecurityLabels(namespace.GetLabels()),
+		Workloads:      []workloadPodSecurity{},
+	}
+	if level != levelPrivileged {
+		workloads, err := t.podSecurityWorkloads(ctx, params.Cluster, url, token, params.Namespace, level)
+		if err != nil {
+			middleware.Logger(ctx).Error("failed to evaluate pods", zap.String("tool", "setNamespacePodSecurity"), zap.Error(err))
+			return nil, nil, err
+		}
+		if analysis, ok := workloads[params.Namespace]; ok {
+			result.Workloads = analysis.Workloads
+		}
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		middleware.Logger(ctx).Error("failed to create response", zap.String("tool", "setNamespacePodSecurity"), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(response)}},
+	}, nil, nil
+}
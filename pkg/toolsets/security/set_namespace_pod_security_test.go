@@ -0,0 +1,66 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestSetNamespacePodSecurity(t *testing.T) {
+	tests := map[string]struct {
+		params         setNamespacePodSecurityParams
+		expectedPatch  string
+		expectedResult string
+		expectedError  string
+	}{
+		"dry run": {
+			params:        setNamespacePodSecurityParams{Cluster: "local", Namespace: "default", Level: "baseline", Version: "v1.31", DryRun: true},
+			expectedPatch: `{"metadata":{"labels":{"pod-security.kubernetes.io/enforce":"baseline","pod-security.kubernetes.io/enforce-version":"v1.31"}}}`,
+			expectedResult: `{"dryRun":true,"namespace":"default","previousLabels":{},"labels":{"enforce":"baseline","enforce-version":"v1.31"},"workloads":[
+				{"kind":"Pod","name":"debug","pods":1,"violations":[
+					"host namespaces (hostNetwork=true)",
+					"privileged (container \"debug\" must not set securityContext.privileged=true)"
+				]}
+			]}`,
+		},
+		"warn mode": {
+			params:         setNamespacePodSecurityParams{Cluster: "local", Namespace: "secure", Mode: "warn", Level: "restricted"},
+			expectedPatch:  `{"metadata":{"labels":{"pod-security.kubernetes.io/warn":"restricted"}}}`,
+			expectedResult: `{"dryRun":false,"namespace":"secure","previousLabels":{"enforce":"restricted","enforce-version":"latest"},"labels":{"enforce":"restricted","enforce-version":"latest","warn":"restricted"},"workloads":[]}`,
+		},
+		"invalid version": {
+			params:        setNamespacePodSecurityParams{Cluster: "local", Namespace: "default", Level: "restricted", Version: "1.31.2"},
+			expectedError: `invalid version "1.31.2"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, fakeDynClient := newFakeClient(fakePodSecurityObjects(t)...)
+			tools := Tools{client: c}
+
+			result, _, err := tools.setNamespacePodSecurity(middleware.WithToken(t.Context(), fakeToken), newCallToolRequest("https://localhost:8080"), test.params)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedResult, result.Content[0].(*mcp.TextContent).Text)
+			var patch clienttesting.PatchAction
+			for _, action := range fakeDynClient.Actions() {
+				if p, ok := action.(clienttesting.PatchAction); ok {
+					patch = p
+				}
+			}
+			require.NotNil(t, patch)
+			assert.Equal(t, schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, patch.GetResource())
+			assert.JSONEq(t, test.expectedPatch, string(patch.GetPatch()))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package security
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/rancher/rancher-ai-mcp/pkg/response"
+	"github.com/rancher/rancher-ai-mcp/pkg/toolsets"
+)
+
+const (
+	toolsSet    = "security"
+	toolsSetAnn = "toolset"
+	urlHeader   = client.URLHeader
+)
+
+// Tools contains all tools for the MCP server
+type Tools struct {
+	client *client.Client
+	// ReadOnly disables the tools that change the Pod Security Admission labels of the namespaces.
+	ReadOnly bool
+}
+
+// NewTools creates and returns a new Tools instance.
+func NewTools(client *client.Client) *Tools {
+	return &Tools{
+		client: client,
+	}
+}
+
+// Register adds the tools of the security toolset to the MCP server with the given deps.
+func Register(mcpServer *mcp.Server, deps toolsets.Deps) {
+	tools := NewTools(deps.Client)
+	tools.ReadOnly = deps.ReadOnly
+	tools.AddTools(mcpServer)
+}
+
+// AddTools registers all security posture tools with the provided MCP server.
+// Each tool is configured with metadata identifying it as part of the security toolset.
+func (t *Tools) AddTools(mcpServer *mcp.Server) {
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listPolicyViolations",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the resources violating the Kubewarden admission policies of a cluster, grouped per workload, from the PolicyReports of the Kubewarden audit scanner.
+		The audit scanner must be enabled. Resources created before a policy, or allowed by a policy in monitor mode, are reported too.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): Only list the violations of this namespace. If empty, the violations of the cluster-scoped resources are listed too.
+
+		Returns:
+		The resources with failed policies, the most violations first, with the policy, rule, result, severity and message of each violation.`},
+		response.WithStructuredErrors(t.listPolicyViolations),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "evaluateAdmissionPolicies",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Evaluates a resource manifest against the admission policies of the cluster (Kubewarden policies or Gatekeeper constraints) without creating it, by sending it to the API server in dry-run mode.
+		Use it before creating or updating a resource in a cluster with admission policies, to fix the manifest instead of failing the real request.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		resource (object): The manifest of the resource, with its apiVersion, kind, metadata and spec. It's evaluated as an update if it already exists.
+
+		Returns:
+		Whether the resource would be allowed, the policies rejecting it and why, the API server error if it's invalid, and the installed Kubewarden policies with their mode.
+		Policies in monitor mode or Gatekeeper constraints with the warn or dryrun enforcement action don't reject resources.`},
+		response.WithStructuredErrors(t.evaluateAdmissionPolicies),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listWorkloadVulnerabilities",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the vulnerabilities found by the NeuVector scanner in the images of the running workloads, grouped per workload.
+		NeuVector must be installed in the cluster, with a read-only NeuVector API key in the neuvector-api-key Secret of the cattle-neuvector-system namespace.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): Only list the workloads of this namespace.
+
+		Returns:
+		The workloads with the number of high and medium vulnerabilities of each image, the most vulnerable first.`},
+		response.WithStructuredErrors(t.listWorkloadVulnerabilities),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "listSecurityEvents",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Lists the recent runtime security events detected by NeuVector, grouped per workload: incidents (e.g. suspicious processes or file access), network threats and network policy violations.
+		NeuVector must be installed in the cluster, with a read-only NeuVector API key in the neuvector-api-key Secret of the cattle-neuvector-system namespace.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): Only list the events of the workloads of this namespace.
+		limit (integer, optional): The number of most recent events of each type fetched from NeuVector. Defaults to 200.
+
+		Returns:
+		The workloads with the number of incidents, threats and violations, the most events first, and their most recent events.`},
+		response.WithStructuredErrors(t.listSecurityEvents),
+	)
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "analyzePodSecurity",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Analyzes the Pod Security Admission (PSA) configuration of the namespaces of a cluster: their pod-security.kubernetes.io labels, and the running workloads that would violate a stricter Pod Security Standards level.
+		Use it to plan the migration from PodSecurityPolicies or to tighten the level of a namespace, before enforcing the level with setNamespacePodSecurity.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string, optional): Only analyze this namespace.
+		level (string, optional): The level the workloads are evaluated against, baseline or restricted. Defaults to restricted.
+
+		Returns:
+		The namespaces with their enforce, audit and warn levels and versions, their number of running pods, the most restrictive level all their pods comply with, and the workloads violating the level with the failed checks.
+		The checks are the ones of the latest version of the standards. The modes without a label use the defaults of the cluster, privileged unless the admission controller is configured otherwise.`},
+		response.WithStructuredErrors(t.analyzePodSecurity),
+	)
+
+	if t.ReadOnly {
+		return
+	}
+
+	mcp.AddTool(mcpServer, &mcp.Tool{
+		Name: "setNamespacePodSecurity",
+		Meta: map[string]any{
+			toolsSetAnn: toolsSet,
+		},
+		Description: `Sets the Pod Security Standards level of a Pod Security Admission mode of a namespace, by patching its pod-security.kubernetes.io labels, and reports the running workloads violating the level.
+		Run it with dryRun first to preview the labels and the violating workloads, and ask for confirmation before enforcing a level, as the new pods violating it are rejected.
+		Parameters:
+		cluster (string): The name of the Kubernetes cluster.
+		namespace (string): The namespace to label.
+		mode (string, optional): enforce rejects the violating pods, audit adds them to the audit log, warn returns a warning to the user. Defaults to enforce.
+		level (string): The level, privileged, baseline or restricted.
+		version (string, optional): The version of the standards, latest or a Kubernetes minor version like v1.31. If empty, the current version label is kept.
+		dryRun (boolean, optional): Preview the change without persisting it.
+
+		Returns:
+		The pod-security labels of the namespace before and after the change, and the running workloads violating the level. The running pods aren't evicted, only their replacements are rejected.`},
+		response.WithStructuredErrors(t.setNamespacePodSecurity),
+	)
+}
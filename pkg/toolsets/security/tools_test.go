@@ -0,0 +1,136 @@
+package security
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	fakeToken  = "fakeToken"
+	fakeAPIKey = "mcp:s3cr3t"
+	// neuvectorProxyPath is the path of the API server proxy to the NeuVector API of the local cluster.
+	neuvectorProxyPath = "/k8s/clusters/local/api/v1/namespaces/cattle-neuvector-system/services/https:neuvector-svc-controller-api:10443/proxy"
+)
+
+var (
+	fakeNeuVectorService = &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": neuvectorAPIService, "namespace": neuvectorNamespace},
+	}}
+	fakeAPIKeySecret = &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": neuvectorAPIKeySecret, "namespace": neuvectorNamespace},
+		"data":       map[string]any{neuvectorAPIKeyKey: base64.StdEncoding.EncodeToString([]byte(fakeAPIKey))},
+	}}
+)
+
+func newFakeClient(objects ...runtime.Object) (*client.Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}:              "PolicyReportList",
+		{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"}:       "ClusterPolicyReportList",
+		{Version: "v1", Resource: "services"}:                                                  "ServiceList",
+		{Version: "v1", Resource: "secrets"}:                                                   "SecretList",
+		{Version: "v1", Resource: "namespaces"}:                                                "NamespaceList",
+		{Version: "v1", Resource: "pods"}:                                                      "PodList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}:                                "DeploymentList",
+		{Group: "policies.kubewarden.io", Version: "v1", Resource: "clusteradmissionpolicies"}: "ClusterAdmissionPolicyList",
+		{Group: "policies.kubewarden.io", Version: "v1", Resource: "admissionpolicies"}:        "AdmissionPolicyList",
+	}, objects...)
+
+	c := client.NewClient(true)
+	c.DynClientCreator = func(inConfig *rest.Config) (dynamic.Interface, error) {
+		return fakeDynClient, nil
+	}
+	// the metadata-only lists use the fake dynamic client too
+	c.MetadataClientCreator = nil
+
+	return c, fakeDynClient
+}
+
+func newCallToolRequest(url string) *mcp.CallToolRequest {
+	return &mcp.CallToolRequest{
+		Extra: &mcp.RequestExtra{Header: map[string][]string{urlHeader: {url}}},
+	}
+}
+
+// newFakeNeuVector returns a fake Rancher server proxying the requests to the NeuVector API of the local cluster,
+// which returns the responses of the paths.
+func newFakeNeuVector(t *testing.T, responses map[string]string) *httptest.Server {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Auth-Apikey") != fakeAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":1,"error":"Authentication failed"}`))
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestAddTools(t *testing.T) {
+	tests := map[string]struct {
+		readOnly      bool
+		expectedTools []string
+	}{
+		"all tools": {
+			expectedTools: []string{"analyzePodSecurity", "evaluateAdmissionPolicies", "listPolicyViolations", "listSecurityEvents", "listWorkloadVulnerabilities", "setNamespacePodSecurity"},
+		},
+		"read-only": {
+			readOnly:      true,
+			expectedTools: []string{"analyzePodSecurity", "evaluateAdmissionPolicies", "listPolicyViolations", "listSecurityEvents", "listWorkloadVulnerabilities"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tools := NewTools(client.NewClient(true))
+			tools.ReadOnly = test.readOnly
+			mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v1.0.0"}, nil)
+			tools.AddTools(mcpServer)
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			ss, err := mcpServer.Connect(t.Context(), serverTransport, nil)
+			require.NoError(t, err)
+			defer ss.Close()
+			cs, err := mcp.NewClient(&mcp.Implementation{Name: "mcp-client", Version: "v1.0.0"}, nil).Connect(t.Context(), clientTransport, nil)
+			require.NoError(t, err)
+			defer cs.Close()
+
+			toolsResult, err := cs.ListTools(t.Context(), &mcp.ListToolsParams{})
+			require.NoError(t, err)
+			var names []string
+			for _, tool := range toolsResult.Tools {
+				names = append(names, tool.Name)
+				assert.Equal(t, toolsSet, tool.Meta[toolsSetAnn])
+			}
+			assert.ElementsMatch(t, test.expectedTools, names)
+		})
+	}
+}
@@ -1,29 +1,115 @@
+// Package toolsets is the registry of the toolsets added to the MCP server.
+//
+// Each toolset package exports a Register function adding its tools to the MCP server, which is registered in the
+// registry with a name. The built-in toolsets are registered by the builtin package, and downstream builds can add
+// their own toolsets by registering them in the init function of a package imported by the server:
+//
+//	func init() {
+//		toolsets.Register(toolsets.Toolset{Name: "custom", Register: Register})
+//	}
 package toolsets
 
 import (
+	"fmt"
+	"slices"
+	"sync"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rancher/rancher-ai-mcp/pkg/client"
-	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/core"
-	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/fleet"
-	"github.com/rancher/rancher-ai-mcp/pkg/toolsets/provisioning"
 )
 
-// toolsAdder is an interface for types that can add tools to an MCP server.
-type toolsAdder interface {
-	AddTools(mcpServer *mcp.Server)
+// Deps contains the dependencies and the settings passed to the Register function of the toolsets.
+type Deps struct {
+	Client *client.Client
+	// ExecAllowlist contains the commands the execInPod tool is allowed to run. If nil, core.DefaultExecAllowlist is used.
+	ExecAllowlist []string
+	// DebugImage is the image of the ephemeral containers added by the runDebugContainer tool, disabled if empty.
+	DebugImage string
+	// DebugAllowlist contains the commands the runDebugContainer tool is allowed to run. If nil, core.DefaultDebugAllowlist is used.
+	DebugAllowlist []string
+	// RawGetAllowlist contains the API server paths the rawGet tool is allowed to read. If nil, core.DefaultRawGetAllowlist is used.
+	RawGetAllowlist []string
+	// ImageAllowlist contains the registries and repositories of the images allowed by the checkImageCompliance tool.
+	ImageAllowlist []string
+	// ReadOnly only adds the tools that don't create, modify or delete resources.
+	ReadOnly bool
+	// MaxFanOut is the number of clusters queried at the same time by all the tool calls. If 0, core.DefaultMaxFanOut is used.
+	MaxFanOut int64
+	// Features contains the names of the feature flags enabled, used by the toolsets to add experimental tools.
+	Features []string
+}
+
+// FeatureEnabled returns true if the feature flag is enabled.
+func (d Deps) FeatureEnabled(name string) bool {
+	return slices.Contains(d.Features, name)
+}
+
+// Toolset is a set of tools registered together.
+type Toolset struct {
+	// Name is the name used to enable the toolset, it must be unique.
+	Name string
+	// Register adds the tools of the toolset to the MCP server.
+	Register func(mcpServer *mcp.Server, deps Deps)
+	// Enabled returns whether the toolset is added with the given deps, e.g. only if a feature flag is enabled.
+	// If nil, the toolset is always added.
+	Enabled func(deps Deps) bool
 }
 
-// AddAllTools adds all available tools to the MCP server.
-func AddAllTools(client *client.Client, mcpServer *mcp.Server) {
-	for _, ta := range allToolSets(client) {
-		ta.AddTools(mcpServer)
+var (
+	mu       sync.Mutex
+	registry []Toolset
+)
+
+// Register adds a toolset to the registry. Toolsets are added to the MCP server in the order they are registered.
+// Register panics if the name is empty or a toolset with the same name is already registered.
+func Register(toolset Toolset) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if toolset.Name == "" || toolset.Register == nil {
+		panic("toolsets: Register called with an unnamed toolset or a nil Register function")
 	}
+	if slices.ContainsFunc(registry, func(t Toolset) bool { return t.Name == toolset.Name }) {
+		panic(fmt.Sprintf("toolsets: Register called twice for toolset %q", toolset.Name))
+	}
+	registry = append(registry, toolset)
 }
 
-func allToolSets(client *client.Client) []toolsAdder {
-	return []toolsAdder{
-		core.NewTools(client),
-		fleet.NewTools(client),
-		provisioning.NewTools(client),
+// Names returns the names of the registered toolsets, in the order they are added to the MCP server.
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := make([]string, len(registry))
+	for i, toolset := range registry {
+		names[i] = toolset.Name
 	}
+
+	return names
+}
+
+// AddAllTools adds the tools of the registered toolsets to the MCP server. If enabled is not empty, only the toolsets
+// with these names are added.
+func AddAllTools(mcpServer *mcp.Server, enabled []string, deps Deps) {
+	for _, toolset := range enabledToolsets(enabled, deps) {
+		toolset.Register(mcpServer, deps)
+	}
+}
+
+func enabledToolsets(enabled []string, deps Deps) []Toolset {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var toolsets []Toolset
+	for _, toolset := range registry {
+		if len(enabled) > 0 && !slices.Contains(enabled, toolset.Name) {
+			continue
+		}
+		if toolset.Enabled != nil && !toolset.Enabled(deps) {
+			continue
+		}
+		toolsets = append(toolsets, toolset)
+	}
+
+	return toolsets
 }
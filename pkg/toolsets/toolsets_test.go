@@ -3,14 +3,65 @@ package toolsets
 import (
 	"testing"
 
-	"github.com/rancher/rancher-ai-mcp/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestAllToolSets(t *testing.T) {
-	client := client.NewClient(true)
-	toolsets := allToolSets(client)
+func TestAddAllTools(t *testing.T) {
+	defer func(saved []Toolset) { registry = saved }(registry)
+	registry = nil
 
-	assert.NotNil(t, toolsets)
-	assert.Len(t, toolsets, 3, "should have exactly 2 toolsets (core and fleet)")
+	var registered []string
+	register := func(name string) func(*mcp.Server, Deps) {
+		return func(*mcp.Server, Deps) { registered = append(registered, name) }
+	}
+	Register(Toolset{Name: "core", Register: register("core")})
+	Register(Toolset{Name: "fleet", Register: register("fleet")})
+	Register(Toolset{Name: "experimental", Register: register("experimental"), Enabled: func(deps Deps) bool {
+		return deps.FeatureEnabled("experimental")
+	}})
+
+	tests := map[string]struct {
+		enabled            []string
+		deps               Deps
+		expectedRegistered []string
+	}{
+		"all toolsets": {
+			expectedRegistered: []string{"core", "fleet"},
+		},
+		"enabled toolsets": {
+			enabled:            []string{"fleet"},
+			expectedRegistered: []string{"fleet"},
+		},
+		"toolset enabled by a feature flag": {
+			deps:               Deps{Features: []string{"experimental"}},
+			expectedRegistered: []string{"core", "fleet", "experimental"},
+		},
+		"toolset enabled by name without its feature flag": {
+			enabled: []string{"experimental"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			registered = nil
+
+			AddAllTools(mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil), test.enabled, test.deps)
+
+			assert.Equal(t, test.expectedRegistered, registered)
+		})
+	}
+
+	assert.Equal(t, []string{"core", "fleet", "experimental"}, Names())
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func(saved []Toolset) { registry = saved }(registry)
+	registry = nil
+
+	Register(Toolset{Name: "core", Register: func(*mcp.Server, Deps) {}})
+
+	assert.PanicsWithValue(t, `toolsets: Register called twice for toolset "core"`, func() {
+		Register(Toolset{Name: "core", Register: func(*mcp.Server, Deps) {}})
+	})
 }
@@ -1,14 +1,17 @@
 package utils
 
 import (
+	"context"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rancher/rancher-ai-mcp/pkg/client"
 	"go.uber.org/zap"
 )
 
-func NewChildLogger(toolReq *mcp.CallToolRequest, extras map[string]string) *zap.Logger {
-	args := []zap.Field{
+func NewChildLogger(ctx context.Context, toolReq *mcp.CallToolRequest, extras map[string]string) *zap.Logger {
+	args := append([]zap.Field{
 		zap.String("tool-name", toolReq.Params.Name),
-	}
+	}, client.TraceFields(ctx)...)
 	if toolReq.Session != nil && toolReq.Session.ID() != "" {
 		args = append(args, zap.String("mcp-request-id", toolReq.Session.ID()))
 	}
@@ -0,0 +1,16 @@
+package utils
+
+// ProtectedNamespaces lists namespaces that are required by Kubernetes or Rancher
+// and must never be deleted through the MCP server.
+var ProtectedNamespaces = []string{
+	"default",
+	"kube-system",
+	"kube-public",
+	"kube-node-lease",
+	"cattle-system",
+	"cattle-fleet-system",
+	"cattle-fleet-local-system",
+	"cattle-ai-agent-system",
+	"fleet-default",
+	"fleet-local",
+}
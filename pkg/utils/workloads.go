@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodWorkload returns the kind and name of the workload that owns the pod. Pods owned by a ReplicaSet
+// are attributed to its Deployment, whose name is the ReplicaSet name without the pod-template-hash suffix.
+// Pods without a controller are attributed to themselves.
+func PodWorkload(pod corev1.Pod) (string, string) {
+	for _, or := range pod.OwnerReferences {
+		if or.Controller != nil && !*or.Controller {
+			continue
+		}
+		if or.Kind == "ReplicaSet" {
+			if hash, ok := pod.Labels["pod-template-hash"]; ok && strings.HasSuffix(or.Name, "-"+hash) {
+				return "Deployment", strings.TrimSuffix(or.Name, "-"+hash)
+			}
+		}
+		return or.Kind, or.Name
+	}
+
+	return "Pod", pod.Name
+}
@@ -0,0 +1,248 @@
+// Package validation checks the parameters of the tool calls before they are run, so an invalid value is reported
+// to the LLM with the values it can use instead of failing deep inside a Kubernetes call.
+//
+// The rules are set on the fields of the parameters with the validate tag, a comma separated list of:
+//   - required: the value must be set, e.g. a non-empty string or slice
+//   - enum=a|b|c: the value must be one of the listed values, compared case-insensitively since the tools normalize
+//     them. The elements of a slice are checked one by one
+//   - format=name: the value must have the format, one of the Formats
+//
+// The fields of nested structs, and of the structs in slices, are checked too:
+//
+//	type params struct {
+//		Name     string   `json:"name" validate:"required,format=dns1123-subdomain"`
+//		Protocol string   `json:"protocol,omitempty" validate:"enum=TCP|UDP|SCTP"`
+//		Roles    []string `json:"roles" validate:"required,enum=etcd|controlplane|worker"`
+//	}
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	apivalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// kubernetesVersionRE matches the Kubernetes versions, with the optional build metadata of the RKE2 and K3s versions.
+var kubernetesVersionRE = regexp.MustCompile(`^v?[0-9]+\.[0-9]+(\.[0-9]+)?(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Format checks the format of a value, returning why it's invalid or nothing if it's valid.
+type Format struct {
+	// Description describes the format in the error messages, e.g. "a DNS-1123 label".
+	Description string
+	Validate    func(value string) []string
+}
+
+// Formats contains the formats of the format rule, by name.
+var Formats = map[string]Format{
+	"dns1123-label": {
+		Description: "a DNS-1123 label, e.g. my-name",
+		Validate:    apivalidation.IsDNS1123Label,
+	},
+	"dns1123-subdomain": {
+		Description: "a DNS-1123 subdomain, e.g. my-name or example.com",
+		Validate:    apivalidation.IsDNS1123Subdomain,
+	},
+	"kubernetes-version": {
+		Description: "a Kubernetes version, e.g. v1.31.4 or v1.31.4+rke2r1",
+		Validate: func(value string) []string {
+			if !kubernetesVersionRE.MatchString(value) {
+				return []string{"must be a version like v1.31.4"}
+			}
+			return nil
+		},
+	},
+}
+
+// rules are the rules of a field, parsed from its validate tag.
+type rules struct {
+	required bool
+	enum     []string
+	format   string
+}
+
+// Validate checks the parameters of a tool call, a struct or a pointer to a struct, with the rules of the validate
+// tags of its fields. The paths of the errors are the JSON names of the fields.
+func Validate(params any) field.ErrorList {
+	value := reflect.ValueOf(params)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return validateStruct(value, nil)
+}
+
+// Error returns the message of the errors of Validate, listing what must be changed.
+func Error(tool string, errs field.ErrorList) string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("invalid arguments of %s, fix them and call it again: %s", tool, strings.Join(messages, "; "))
+}
+
+func validateStruct(value reflect.Value, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	structType := value.Type()
+	for i := range structType.NumField() {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		name := jsonName(structField)
+		if name == "-" {
+			continue
+		}
+		fieldPath := field.NewPath(name)
+		if path != nil {
+			fieldPath = path.Child(name)
+		}
+		fieldValue := value.Field(i)
+
+		tag, ok := structField.Tag.Lookup("validate")
+		if ok {
+			fieldRules, err := parseRules(tag)
+			if err != nil {
+				// the rules are set by the tools, an invalid one is a bug
+				errs = append(errs, field.InternalError(fieldPath, fmt.Errorf("invalid validate tag of %s.%s: %w", structType.Name(), structField.Name, err)))
+				continue
+			}
+			errs = append(errs, fieldRules.validate(fieldValue, fieldPath)...)
+		}
+		errs = append(errs, validateNested(fieldValue, fieldPath)...)
+	}
+
+	return errs
+}
+
+// validateNested checks the structs of a field, the field itself or the elements of a slice.
+func validateNested(value reflect.Value, path *field.Path) field.ErrorList {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return validateNested(value.Elem(), path)
+	case reflect.Struct:
+		return validateStruct(value, path)
+	case reflect.Slice, reflect.Array:
+		var errs field.ErrorList
+		for i := range value.Len() {
+			errs = append(errs, validateNested(value.Index(i), path.Index(i))...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
+func (r rules) validate(value reflect.Value, path *field.Path) field.ErrorList {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if r.required {
+				return field.ErrorList{field.Required(path, "")}
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+	if r.required && value.IsZero() {
+		return field.ErrorList{field.Required(path, r.hint())}
+	}
+	if (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Type().Elem().Kind() == reflect.String {
+		if r.required && value.Len() == 0 {
+			return field.ErrorList{field.Required(path, r.hint())}
+		}
+		var errs field.ErrorList
+		for i := range value.Len() {
+			errs = append(errs, r.validateString(value.Index(i).String(), path.Index(i))...)
+		}
+		return errs
+	}
+	if value.Kind() == reflect.String {
+		return r.validateString(value.String(), path)
+	}
+
+	return nil
+}
+
+func (r rules) validateString(value string, path *field.Path) field.ErrorList {
+	// the optional values that aren't set use their defaults
+	if value == "" {
+		return nil
+	}
+	if len(r.enum) > 0 && !containsFold(r.enum, value) {
+		return field.ErrorList{field.NotSupported(path, value, r.enum)}
+	}
+	if r.format != "" {
+		format := Formats[r.format]
+		if problems := format.Validate(value); len(problems) > 0 {
+			return field.ErrorList{field.Invalid(path, value, fmt.Sprintf("must be %s: %s", format.Description, strings.Join(problems, ", ")))}
+		}
+	}
+
+	return nil
+}
+
+// hint describes the values of a required field.
+func (r rules) hint() string {
+	if len(r.enum) > 0 {
+		return "must be one of " + strings.Join(r.enum, ", ")
+	}
+	if r.format != "" {
+		return "must be " + Formats[r.format].Description
+	}
+
+	return ""
+}
+
+func parseRules(tag string) (rules, error) {
+	var r rules
+	for _, rule := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			r.required = true
+		case "enum":
+			if value == "" {
+				return r, fmt.Errorf("enum without values")
+			}
+			r.enum = strings.Split(value, "|")
+		case "format":
+			if _, ok := Formats[value]; !ok {
+				return r, fmt.Errorf("unknown format %q, must be one of %s", value, strings.Join(slices.Sorted(maps.Keys(Formats)), ", "))
+			}
+			r.format = value
+		default:
+			return r, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	return r, nil
+}
+
+// jsonName returns the name of the field in the JSON arguments of the tool.
+func jsonName(structField reflect.StructField) string {
+	name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+	if name == "" {
+		return structField.Name
+	}
+
+	return name
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
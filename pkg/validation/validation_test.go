@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPoolParams struct {
+	Name  string   `json:"name" validate:"required,format=dns1123-label"`
+	Roles []string `json:"roles" validate:"required,enum=etcd|controlplane|worker"`
+}
+
+type testClusterParams struct {
+	Name              string           `json:"name" validate:"required,format=dns1123-label"`
+	KubernetesVersion string           `json:"kubernetesVersion" validate:"format=kubernetes-version"`
+	Distro            string           `json:"distro,omitempty" validate:"enum=rke2|k3s"`
+	Pools             []testPoolParams `json:"pools" validate:"required"`
+	Description       string           `json:"description,omitempty"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		params         any
+		expectedErrors []string
+	}{
+		"valid params": {
+			params: testClusterParams{
+				Name:              "prod",
+				KubernetesVersion: "v1.31.4+rke2r1",
+				Distro:            "RKE2",
+				Pools:             []testPoolParams{{Name: "pool1", Roles: []string{"etcd", "controlplane"}}},
+			},
+		},
+		"pointer to valid params": {
+			params: &testClusterParams{Name: "prod", Pools: []testPoolParams{{Name: "pool1", Roles: []string{"worker"}}}},
+		},
+		"missing required params": {
+			params: testClusterParams{},
+			expectedErrors: []string{
+				"name: Required value: must be a DNS-1123 label, e.g. my-name",
+				"pools: Required value",
+			},
+		},
+		"unsupported values": {
+			params: testClusterParams{
+				Name:   "prod",
+				Distro: "rke1",
+				Pools:  []testPoolParams{{Name: "pool1", Roles: []string{"etcd", "master"}}},
+			},
+			expectedErrors: []string{
+				`distro: Unsupported value: "rke1": supported values: "rke2", "k3s"`,
+				`pools[0].roles[1]: Unsupported value: "master": supported values: "etcd", "controlplane", "worker"`,
+			},
+		},
+		"invalid formats": {
+			params: testClusterParams{
+				Name:              "Prod_Cluster",
+				KubernetesVersion: "1.31",
+				Pools:             []testPoolParams{{Name: "pool.1"}},
+			},
+			expectedErrors: []string{
+				`name: Invalid value: "Prod_Cluster": must be a DNS-1123 label, e.g. my-name: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+				`pools[0].name: Invalid value: "pool.1": must be a DNS-1123 label, e.g. my-name: must not contain dots`,
+				"pools[0].roles: Required value: must be one of etcd, controlplane, worker",
+			},
+		},
+		"params without rules": {
+			params: struct {
+				Name string `json:"name"`
+			}{},
+		},
+		"invalid rule": {
+			params: struct {
+				Name string `json:"name" validate:"format=uuid"`
+			}{},
+			expectedErrors: []string{
+				`name: Internal error: invalid validate tag of .Name: unknown format "uuid", must be one of dns1123-label, dns1123-subdomain, kubernetes-version`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := Validate(test.params)
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, test.expectedErrors, messages)
+		})
+	}
+}
+
+func TestKubernetesVersionFormat(t *testing.T) {
+	tests := map[string]bool{
+		"v1.31.4":        true,
+		"v1.31.4+rke2r1": true,
+		"v1.31.4+k3s1":   true,
+		"1.31":           true,
+		"v1.32.0-rc.1":   true,
+		"latest":         false,
+		"v1":             false,
+		"v1.31.4 rke2":   false,
+	}
+
+	for version, expected := range tests {
+		assert.Equal(t, expected, len(Formats["kubernetes-version"].Validate(version)) == 0, version)
+	}
+}